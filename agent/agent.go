@@ -3,15 +3,19 @@ package agent
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/shirou/gopsutil/host"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/trento-project/trento/agent/discovery"
 	"github.com/trento-project/trento/agent/discovery/collector"
 	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/version"
 )
 
 const trentoAgentCheckId = "trentoAgent"
@@ -20,6 +24,8 @@ type Agent struct {
 	config          *Config
 	collectorClient collector.Client
 	discoveries     []discovery.Discovery
+	logsShipper     logsShipper
+	metricsBuffer   metricsBuffer
 	ctx             context.Context
 	ctxCancel       context.CancelFunc
 }
@@ -31,7 +37,7 @@ type Config struct {
 
 // NewAgent returns a new instance of Agent with the given configuration
 func NewAgent(config *Config) (*Agent, error) {
-	collectorClient, err := collector.NewCollectorClient(config.DiscoveriesConfig.CollectorConfig)
+	collectorClient, err := collector.NewClient(config.DiscoveriesConfig.CollectorConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create a collector client")
 	}
@@ -42,6 +48,7 @@ func NewAgent(config *Config) (*Agent, error) {
 		discovery.NewCloudDiscovery(collectorClient, *config.DiscoveriesConfig),
 		discovery.NewSubscriptionDiscovery(collectorClient, *config.DiscoveriesConfig),
 		discovery.NewHostDiscovery(collectorClient, *config.DiscoveriesConfig),
+		discovery.NewKubernetesDiscovery(collectorClient, *config.DiscoveriesConfig),
 	}
 
 	ctx, ctxCancel := context.WithCancel(context.Background())
@@ -60,6 +67,11 @@ func NewAgent(config *Config) (*Agent, error) {
 func (a *Agent) Start() error {
 	var wg sync.WaitGroup
 
+	// the discoveries run anyway, the registration is informative
+	if err := a.collectorClient.Register(a.registration()); err != nil {
+		log.Errorf("Error while registering the agent: %s", err)
+	}
+
 	for _, d := range a.discoveries {
 		wg.Add(1)
 		go func(wg *sync.WaitGroup, d discovery.Discovery) {
@@ -78,6 +90,26 @@ func (a *Agent) Start() error {
 		log.Info("heartbeat loop stopped.")
 	}(&wg)
 
+	wg.Add(1)
+	go func(wg *sync.WaitGroup) {
+		log.Info("Starting channel loop...")
+		defer wg.Done()
+		a.startChannelLoop()
+		log.Info("channel loop stopped.")
+	}(&wg)
+
+	wg.Add(2)
+	go func(wg *sync.WaitGroup) {
+		defer wg.Done()
+		internal.Repeat("agent.metrics.sample", a.metricsBuffer.sample, metricsSampleInterval, a.ctx)
+	}(&wg)
+	go func(wg *sync.WaitGroup) {
+		log.Info("Starting metrics loop...")
+		defer wg.Done()
+		internal.Repeat("agent.metrics.push", func() { a.metricsBuffer.push(a.collectorClient.PushMetrics) }, metricsPushInterval, a.ctx)
+		log.Info("metrics loop stopped.")
+	}(&wg)
+
 	wg.Wait()
 
 	return nil
@@ -104,11 +136,44 @@ func (a *Agent) startDiscoverTicker(d discovery.Discovery) {
 
 func (a *Agent) startHeartbeatTicker() {
 	tick := func() {
-		err := a.collectorClient.Heartbeat()
+		logsRequested, err := a.collectorClient.Heartbeat()
 		if err != nil {
 			log.Errorf("Error while sending the heartbeat to the server: %s", err)
+			return
+		}
+
+		if logsRequested {
+			a.logsShipper.ship(a.collectorClient.ShipLogs)
 		}
 	}
 
 	internal.Repeat("agent.heartbeat", tick, internal.HeartbeatInterval, a.ctx)
 }
+
+// registration reports the version of the agent, its discoveries and the OS it runs on
+func (a *Agent) registration() *hosts.AgentRegistration {
+	registration := &hosts.AgentRegistration{
+		Version:     version.Version,
+		Discoveries: []string{},
+		Arch:        runtime.GOARCH,
+	}
+
+	for _, d := range a.discoveries {
+		registration.Discoveries = append(registration.Discoveries, d.GetId())
+	}
+
+	info, err := host.Info()
+	if err != nil {
+		log.Errorf("Error while getting host info: %s", err)
+		return registration
+	}
+
+	registration.OSName = info.Platform
+	registration.OSVersion = info.PlatformVersion
+	registration.KernelVersion = info.KernelVersion
+	if info.KernelArch != "" {
+		registration.Arch = info.KernelArch
+	}
+
+	return registration
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/internal/hosts"
+)
+
+// channelReconnectInterval is the period of time the agent waits before opening its channel again once closed
+const channelReconnectInterval = 30 * time.Second
+
+func (a *Agent) startChannelLoop() {
+	internal.Repeat("agent.channel", a.listenChannel, channelReconnectInterval, a.ctx)
+}
+
+// listenChannel handles the messages the server pushes to the agent until the channel is closed
+func (a *Agent) listenChannel() {
+	channel, err := a.collectorClient.OpenChannel()
+	if err != nil {
+		log.Errorf("Error while opening the channel with the server: %s", err)
+		return
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-a.ctx.Done():
+		case <-stop:
+		}
+		channel.Close()
+	}()
+
+	log.Info("Channel with the server opened")
+
+	for {
+		message, err := channel.Receive()
+		if err != nil {
+			if a.ctx.Err() == nil {
+				log.Warnf("Channel with the server closed: %s", err)
+			}
+			return
+		}
+
+		go func() {
+			if err := channel.Ack(message.ID, a.handleMessage(message)); err != nil {
+				log.Errorf("Error while acknowledging the message %s: %s", message.ID, err)
+			}
+		}()
+	}
+}
+
+func (a *Agent) handleMessage(message *hosts.AgentMessage) error {
+	log.Debugf("Message %s received from the server: %s", message.ID, message.Type)
+
+	switch message.Type {
+	case hosts.AgentMessageConfigChanged:
+		// the configuration is read from the local settings for now
+		return nil
+	case hosts.AgentMessageDiscoveryRequested:
+		var request hosts.DiscoveryRequest
+		if err := json.Unmarshal(message.Payload, &request); err != nil {
+			return err
+		}
+		return a.runDiscovery(request.DiscoveryType)
+	default:
+		return fmt.Errorf("message %s is not supported by the agent", message.Type)
+	}
+}
+
+// runDiscovery runs the discovery requested by the server right away, besides its loop
+func (a *Agent) runDiscovery(discoveryType string) error {
+	var requested discovery.Discovery
+	for _, d := range a.discoveries {
+		if d.GetId() == discoveryType {
+			requested = d
+		}
+	}
+
+	if requested == nil {
+		return fmt.Errorf("discovery %s is not run by the agent", discoveryType)
+	}
+
+	result, err := requested.Discover()
+	if err != nil {
+		return err
+	}
+	log.Infof("%s discovery requested by the server output: %s", discoveryType, result)
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/trento-project/trento/internal/hosts"
+)
+
+// Channel is the WebSocket channel the server pushes its messages to the agent with
+type Channel interface {
+	// Receive blocks until the next message of the server, returning an error once the channel is closed
+	Receive() (*hosts.AgentMessage, error)
+	// Ack tells the server the message is handled, with the error if it failed
+	Ack(messageID string, err error) error
+	Close() error
+}
+
+type channel struct {
+	conn *websocket.Conn
+	// writeMutex serializes the acknowledgements of the messages handled concurrently
+	writeMutex sync.Mutex
+}
+
+// OpenChannel connects to the channel of the agent, authenticated as the HTTP requests to the collector
+func (c *client) OpenChannel() (Channel, error) {
+	header := http.Header{}
+	if c.config.EnableJWT {
+		token, err := c.getToken()
+		if err != nil {
+			return nil, err
+		}
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+	}
+	if transport, ok := c.httpClient.Transport.(*http.Transport); ok {
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	}
+
+	channelURL := fmt.Sprintf(
+		"%s/api/agent/ws?agent_id=%s", strings.Replace(c.getBaseURL(), "http", "ws", 1), url.QueryEscape(c.agentID))
+	conn, resp, err := dialer.Dial(channelURL, header)
+	if err != nil {
+		if resp != nil {
+			if resp.StatusCode == http.StatusUnauthorized {
+				c.resetToken()
+			}
+			return nil, fmt.Errorf("server responded with status code %d while opening the channel", resp.StatusCode)
+		}
+		return nil, err
+	}
+
+	return &channel{conn: conn}, nil
+}
+
+func (c *channel) Receive() (*hosts.AgentMessage, error) {
+	var message hosts.AgentMessage
+	if err := c.conn.ReadJSON(&message); err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+func (c *channel) Ack(messageID string, err error) error {
+	ack := &hosts.AgentMessage{ID: messageID, Type: hosts.AgentMessageAck}
+	if err != nil {
+		ack.Error = err.Error()
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	return c.conn.WriteJSON(ack)
+}
+
+func (c *channel) Close() error {
+	return c.conn.Close()
+}
@@ -7,40 +7,106 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/internal/signing"
 
 	"github.com/spf13/afero"
 )
 
 type Client interface {
 	Publish(discoveryType string, payload interface{}) error
-	Heartbeat() error
+	// Heartbeat returns whether the server asks the agent to ship its logs
+	Heartbeat() (logsRequested bool, err error)
+	// Register reports the version, discoveries and OS of the agent
+	Register(registration *hosts.AgentRegistration) error
+	// ShipLogs sends an excerpt of the recent logs of the agent, once requested
+	ShipLogs(logs string) error
+	// PushMetrics sends the resources usage samples taken since the last push
+	PushMetrics(samples []*hosts.MetricsSample) error
+	// OpenChannel connects to the channel the server pushes its requests to the agent with
+	OpenChannel() (Channel, error)
 }
 
 type client struct {
 	config     *Config
 	agentID    string
 	httpClient *http.Client
+
+	tokenMutex     sync.Mutex
+	token          string
+	tokenExpiresAt time.Time
+	agentSecret    string
+
+	backoffMutex sync.Mutex
+	backoffUntil time.Time
+}
+
+// BackpressureError is returned while the collector, saturated, asks the agent to send its data again later
+type BackpressureError struct {
+	RetryAt time.Time
+}
+
+func (e *BackpressureError) Error() string {
+	return fmt.Sprintf("the collector is saturated, not sending data until %s", e.RetryAt.Format(time.RFC3339))
 }
 
 type Config struct {
-	CollectorHost string
-	CollectorPort int
-	EnablemTLS    bool
-	Cert          string
-	Key           string
-	CA            string
+	CollectorHost   string
+	CollectorPort   int
+	EnablemTLS      bool
+	Cert            string
+	Key             string
+	CA              string
+	EnableJWT       bool
+	EnrollmentToken string
+	// AgentSecretFile keeps the secret issued at the first enrollment, required to enroll again. Only kept in memory if empty
+	AgentSecretFile string
+	// SigningSecret the payloads are signed with, as required by the server, not signed if empty
+	SigningSecret string
+	// CollectorGRPCPort the data is streamed to with gRPC in place of HTTP, it requires mTLS. HTTP is used if 0
+	CollectorGRPCPort int
+}
+
+type enrollmentToken struct {
+	Token       string    `json:"token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	AgentSecret string    `json:"agent_secret"`
 }
 
 const machineIdPath = "/etc/machine-id"
 
+// protocolVersion of the collected data, the collector tells the versions it accepts at /api/protocol
+const protocolVersion = 1
+
+// tokenRenewalMargin is how long before its expiration the JWT is renewed
+const tokenRenewalMargin = time.Minute
+
+// defaultBackoff is the time the agent stops sending data when the collector does not tell how long to wait
+const defaultBackoff = 30 * time.Second
+
 var fileSystem = afero.NewOsFs()
 
+// NewClient returns the gRPC collector client if its port is configured, the HTTP one otherwise
+func NewClient(config *Config) (Client, error) {
+	if config.CollectorGRPCPort > 0 {
+		return NewCollectorGRPCClient(config)
+	}
+
+	return NewCollectorClient(config)
+}
+
 func NewCollectorClient(config *Config) (*client, error) {
 	var tlsConfig *tls.Config
 	var err error
@@ -78,22 +144,26 @@ func NewCollectorClient(config *Config) (*client, error) {
 func (c *client) Publish(discoveryType string, payload interface{}) error {
 	log.Debugf("Sending %s to data collector", discoveryType)
 
-	requestBody, err := json.Marshal(map[string]interface{}{
-		"agent_id":       c.agentID,
-		"discovery_type": discoveryType,
-		"payload":        payload,
-	})
+	if err := c.checkBackoff(); err != nil {
+		return err
+	}
+
+	requestBody, err := c.marshalEvent(discoveryType, payload)
 	if err != nil {
 		return err
 	}
 
 	url := fmt.Sprintf("%s/api/collect", c.getBaseURL())
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(requestBody))
+	resp, err := c.post(url, requestBody)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return c.backOff(resp.Header.Get("Retry-After"))
+	}
+
 	if resp.StatusCode != http.StatusAccepted {
 		return fmt.Errorf(
 			"something wrong happened while publishing data to the collector. Status: %d, Agent: %s, discovery: %s",
@@ -103,27 +173,259 @@ func (c *client) Publish(discoveryType string, payload interface{}) error {
 	return nil
 }
 
-func (c *client) Heartbeat() error {
+// marshalEvent returns the JSON document of the discovered data the collector receives
+func (c *client) marshalEvent(discoveryType string, payload interface{}) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"agent_id":         c.agentID,
+		"discovery_type":   discoveryType,
+		"payload":          payload,
+		"protocol_version": protocolVersion,
+	})
+}
+
+func (c *client) Heartbeat() (bool, error) {
 	url := fmt.Sprintf("%s/api/hosts/%s/heartbeat", c.getBaseURL(), c.agentID)
-	resp, err := c.httpClient.Post(url, "application/json", nil)
+	resp, err := c.post(url, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return false, fmt.Errorf("server responded with status code %d while sending heartbeat", resp.StatusCode)
+	}
+
+	return resp.Header.Get(hosts.LogsRequestedHeader) == "true", nil
+}
+
+func (c *client) Register(registration *hosts.AgentRegistration) error {
+	body, err := json.Marshal(registration)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/hosts/%s/register", c.getBaseURL(), c.agentID)
+	resp, err := c.post(url, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("server responded with status code %d while registering the agent", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (c *client) ShipLogs(logs string) error {
+	body, err := json.Marshal(&hosts.AgentLogs{Logs: logs})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/hosts/%s/logs", c.getBaseURL(), c.agentID)
+	resp, err := c.post(url, body)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("server responded with status code %d while sending heartbeat", resp.StatusCode)
+		return fmt.Errorf("server responded with status code %d while shipping the logs", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (c *client) PushMetrics(samples []*hosts.MetricsSample) error {
+	body, err := json.Marshal(samples)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/hosts/%s/metrics", c.getBaseURL(), c.agentID)
+	resp, err := c.post(url, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// the samples of the agents pending approval are discarded
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("server responded with status code %d while pushing the metrics", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// backOff stops sending data for the seconds the collector tells in the Retry-After header, defaultBackoff if missing
+func (c *client) backOff(retryAfter string) error {
+	delay := defaultBackoff
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+		delay = time.Duration(seconds) * time.Second
+	}
+
+	c.backoffMutex.Lock()
+	defer c.backoffMutex.Unlock()
+
+	c.backoffUntil = time.Now().Add(delay)
+	log.Warnf("The collector is saturated, backing off for %s", delay)
+
+	return &BackpressureError{RetryAt: c.backoffUntil}
+}
+
+// checkBackoff returns a BackpressureError while backing off
+func (c *client) checkBackoff() error {
+	c.backoffMutex.Lock()
+	defer c.backoffMutex.Unlock()
+
+	if time.Now().Before(c.backoffUntil) {
+		return &BackpressureError{RetryAt: c.backoffUntil}
 	}
 
 	return nil
 }
 
+// post sends the request to the collector, authenticated with the JWT if enabled
+func (c *client) post(url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.config.SigningSecret != "" {
+		timestamp, signature := signing.Sign(c.config.SigningSecret, time.Now(), body)
+		req.Header.Set(signing.TimestampHeader, timestamp)
+		req.Header.Set(signing.SignatureHeader, signature)
+	}
+
+	if !c.config.EnableJWT {
+		return c.httpClient.Do(req)
+	}
+
+	token, err := c.getToken()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// the server may have rotated its secret, enroll again on the next request
+		c.resetToken()
+	}
+
+	return resp, nil
+}
+
+// getToken returns the JWT issued to the agent, enrolling if there is none or it is about to expire
+func (c *client) getToken() (string, error) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if c.token != "" && time.Now().Add(tokenRenewalMargin).Before(c.tokenExpiresAt) {
+		return c.token, nil
+	}
+
+	agentSecret, err := c.loadAgentSecret()
+	if err != nil {
+		return "", err
+	}
+
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"agent_id":         c.agentID,
+		"enrollment_token": c.config.EnrollmentToken,
+		"agent_secret":     agentSecret,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/api/enroll", c.getBaseURL())
+	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("server responded with status code %d while enrolling the agent", resp.StatusCode)
+	}
+
+	var enrollment enrollmentToken
+	err = json.NewDecoder(resp.Body).Decode(&enrollment)
+	if err != nil {
+		return "", err
+	}
+
+	// the secret is only issued at the first enrollment
+	if enrollment.AgentSecret != "" {
+		c.storeAgentSecret(enrollment.AgentSecret)
+	}
+
+	log.Debugf("Agent enrolled, token valid until %s", enrollment.ExpiresAt)
+	c.token = enrollment.Token
+	c.tokenExpiresAt = enrollment.ExpiresAt
+
+	return c.token, nil
+}
+
+// loadAgentSecret returns the secret issued at the first enrollment of the agent, empty if not enrolled yet
+func (c *client) loadAgentSecret() (string, error) {
+	if c.agentSecret != "" || c.config.AgentSecretFile == "" {
+		return c.agentSecret, nil
+	}
+
+	secret, err := afero.ReadFile(fileSystem, c.config.AgentSecretFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read the agent secret: %w", err)
+	}
+
+	c.agentSecret = strings.TrimSpace(string(secret))
+
+	return c.agentSecret, nil
+}
+
+// storeAgentSecret keeps the secret in memory anyway, so that the agent can enroll again until it is restarted
+func (c *client) storeAgentSecret(secret string) {
+	c.agentSecret = secret
+
+	if c.config.AgentSecretFile == "" {
+		return
+	}
+
+	err := fileSystem.MkdirAll(filepath.Dir(c.config.AgentSecretFile), 0700)
+	if err == nil {
+		err = afero.WriteFile(fileSystem, c.config.AgentSecretFile, []byte(secret), 0600)
+	}
+	if err != nil {
+		log.Errorf("Could not store the agent secret in %s, an admin has to reset the enrollment of the agent once restarted: %s",
+			c.config.AgentSecretFile, err)
+	}
+}
+
+func (c *client) resetToken() {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	c.token = ""
+}
+
 func (c *client) getBaseURL() string {
 	protocol := "http"
 	if c.config.EnablemTLS {
 		protocol = "https"
 	}
-	return fmt.Sprintf("%s://%s:%d", protocol, c.config.CollectorHost, c.config.CollectorPort)
+	return fmt.Sprintf("%s://%s", protocol, net.JoinHostPort(c.config.CollectorHost, strconv.Itoa(c.config.CollectorPort)))
 }
 
 func getTLSConfig(cert, key, ca string) (*tls.Config, error) {
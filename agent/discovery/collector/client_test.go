@@ -1,15 +1,23 @@
 package collector
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/internal/signing"
 	_ "github.com/trento-project/trento/test"
 	"github.com/trento-project/trento/test/helpers"
 )
@@ -89,9 +97,10 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingSuccess() {
 
 	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
 		requestBody, _ := json.Marshal(map[string]interface{}{
-			"agent_id":       DummyAgentID,
-			"discovery_type": discoveryType,
-			"payload":        discoveredDataPayload,
+			"agent_id":         DummyAgentID,
+			"discovery_type":   discoveryType,
+			"payload":          discoveredDataPayload,
+			"protocol_version": 1,
 		})
 
 		bodyBytes, _ := ioutil.ReadAll(req.Body)
@@ -130,6 +139,59 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingFailure() {
 	suite.Error(err)
 }
 
+func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingBackpressure() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    false,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+
+	requests := 0
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		requests++
+		return &http.Response{
+			StatusCode: 429,
+			Header:     http.Header{"Retry-After": []string{"60"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"error":"saturated"}`)),
+		}
+	})
+
+	err = collectorClient.Publish("some_discovery_type", struct{}{})
+
+	var backpressureErr *BackpressureError
+	suite.ErrorAs(err, &backpressureErr)
+	suite.WithinDuration(time.Now().Add(time.Minute), backpressureErr.RetryAt, 5*time.Second)
+
+	// the agent backs off without sending the data
+	err = collectorClient.Publish("some_discovery_type", struct{}{})
+
+	suite.ErrorAs(err, &backpressureErr)
+	suite.Equal(1, requests)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingIPv6() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    false,
+		CollectorHost: "2001:db8::1",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal("http://[2001:db8::1]:8081/api/collect", req.URL.String())
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	err = collectorClient.Publish("some_discovery_type", struct{}{})
+
+	suite.NoError(err)
+}
+
 func (suite *CollectorClientTestSuite) TestCollectorClient_Heartbeat() {
 	collectorClient, err := NewCollectorClient(&Config{
 		EnablemTLS:    true,
@@ -146,9 +208,297 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_Heartbeat() {
 		suite.Equal(req.URL.String(), fmt.Sprintf("https://localhost:8081/api/hosts/%s/heartbeat", DummyAgentID))
 		return &http.Response{
 			StatusCode: 204,
+			Header:     http.Header{"X-Trento-Logs-Requested": []string{"true"}},
 		}
 	})
-	err = collectorClient.Heartbeat()
+	logsRequested, err := collectorClient.Heartbeat()
 
 	suite.NoError(err)
+	suite.True(logsRequested)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_ShipLogs() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    true,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		Cert:          "./test/certs/client-cert.pem",
+		Key:           "./test/certs/client-key.pem",
+		CA:            "./test/certs/ca-cert.pem",
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal(req.URL.String(), fmt.Sprintf("https://localhost:8081/api/hosts/%s/logs", DummyAgentID))
+
+		body, _ := ioutil.ReadAll(req.Body)
+		suite.JSONEq(`{"logs": "line1\nline2\n"}`, string(body))
+
+		return &http.Response{
+			StatusCode: 204,
+		}
+	})
+
+	suite.NoError(collectorClient.ShipLogs("line1\nline2\n"))
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_PushMetrics() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    true,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		Cert:          "./test/certs/client-cert.pem",
+		Key:           "./test/certs/client-key.pem",
+		CA:            "./test/certs/ca-cert.pem",
+	})
+
+	suite.NoError(err)
+
+	statusCode := 202
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal(req.URL.String(), fmt.Sprintf("https://localhost:8081/api/hosts/%s/metrics", DummyAgentID))
+
+		body, _ := ioutil.ReadAll(req.Body)
+		suite.JSONEq(`[{"time": "2022-03-10T08:30:00Z", "cpu_percent": 12.5, "memory_percent": 50, "disk_percent": 71}]`, string(body))
+
+		return &http.Response{
+			StatusCode: statusCode,
+		}
+	})
+
+	samples := []*hosts.MetricsSample{{
+		Time:            time.Date(2022, 3, 10, 8, 30, 0, 0, time.UTC),
+		HostUtilization: hosts.HostUtilization{CPUPercent: 12.5, MemoryPercent: 50, DiskPercent: 71},
+	}}
+	suite.NoError(collectorClient.PushMetrics(samples))
+
+	statusCode = 500
+	suite.Error(collectorClient.PushMetrics(samples))
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_Register() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    true,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		Cert:          "./test/certs/client-cert.pem",
+		Key:           "./test/certs/client-key.pem",
+		CA:            "./test/certs/ca-cert.pem",
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal(req.URL.String(), fmt.Sprintf("https://localhost:8081/api/hosts/%s/register", DummyAgentID))
+
+		body, _ := ioutil.ReadAll(req.Body)
+		suite.JSONEq(`{
+			"version": "1.1.0",
+			"discoveries": ["host_discovery"],
+			"os_name": "sles",
+			"os_version": "15.4",
+			"kernel_version": "5.14.21",
+			"arch": "x86_64"
+		}`, string(body))
+
+		return &http.Response{
+			StatusCode: 204,
+		}
+	})
+	err = collectorClient.Register(&hosts.AgentRegistration{
+		Version:       "1.1.0",
+		Discoveries:   []string{"host_discovery"},
+		OSName:        "sles",
+		OSVersion:     "15.4",
+		KernelVersion: "5.14.21",
+		Arch:          "x86_64",
+	})
+
+	suite.NoError(err)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingWithJWT() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost:   "localhost",
+		CollectorPort:   8081,
+		EnableJWT:       true,
+		EnrollmentToken: "some-enrollment-token",
+	})
+
+	suite.NoError(err)
+
+	enrollments := 0
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		if req.URL.String() == "http://localhost:8081/api/enroll" {
+			enrollments++
+
+			requestBody, _ := json.Marshal(map[string]interface{}{
+				"agent_id":         DummyAgentID,
+				"enrollment_token": "some-enrollment-token",
+				"agent_secret":     "",
+			})
+			bodyBytes, _ := ioutil.ReadAll(req.Body)
+			suite.EqualValues(requestBody, bodyBytes)
+
+			responseBody, _ := json.Marshal(map[string]interface{}{
+				"token":      "some-jwt",
+				"expires_at": time.Now().Add(time.Hour),
+			})
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(responseBody)),
+			}
+		}
+
+		suite.Equal("Bearer some-jwt", req.Header.Get("Authorization"))
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	suite.NoError(collectorClient.Publish("some_discovery_type", struct{}{}))
+	suite.NoError(collectorClient.Publish("some_discovery_type", struct{}{}))
+	suite.Equal(1, enrollments)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_EnrollmentWithAgentSecret() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost:   "localhost",
+		CollectorPort:   8081,
+		EnableJWT:       true,
+		EnrollmentToken: "some-enrollment-token",
+		AgentSecretFile: "/var/lib/trento/agent-secret",
+	})
+
+	suite.NoError(err)
+
+	var agentSecrets []string
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		if req.URL.String() == "http://localhost:8081/api/enroll" {
+			var enrollment map[string]string
+			json.NewDecoder(req.Body).Decode(&enrollment)
+			agentSecrets = append(agentSecrets, enrollment["agent_secret"])
+
+			// the secret is only issued at the first enrollment
+			response := map[string]interface{}{"token": "some-jwt", "expires_at": time.Now().Add(time.Hour)}
+			if enrollment["agent_secret"] == "" {
+				response["agent_secret"] = "some-agent-secret"
+			}
+			responseBody, _ := json.Marshal(response)
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(responseBody)),
+			}
+		}
+
+		return &http.Response{
+			StatusCode: 204,
+		}
+	})
+
+	_, err = collectorClient.Heartbeat()
+	suite.NoError(err)
+
+	stored, err := afero.ReadFile(fileSystem, "/var/lib/trento/agent-secret")
+	suite.NoError(err)
+	suite.Equal("some-agent-secret", string(stored))
+
+	// a restarted agent enrolls again with the stored secret
+	restartedClient, _ := NewCollectorClient(collectorClient.config)
+	restartedClient.httpClient.Transport = collectorClient.httpClient.Transport
+
+	_, err = restartedClient.Heartbeat()
+	suite.NoError(err)
+
+	suite.Equal([]string{"", "some-agent-secret"}, agentSecrets)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingSigned() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		SigningSecret: "some-secret",
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+
+		suite.NoError(signing.Verify("some-secret", req.Header.Get(signing.TimestampHeader),
+			req.Header.Get(signing.SignatureHeader), bodyBytes, time.Now()))
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	suite.NoError(collectorClient.Publish("some_discovery_type", struct{}{}))
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_EnrollmentFailure() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost:   "localhost",
+		CollectorPort:   8081,
+		EnableJWT:       true,
+		EnrollmentToken: "wrong-enrollment-token",
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal("http://localhost:8081/api/enroll", req.URL.String())
+		return &http.Response{
+			StatusCode: 401,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("{}")),
+		}
+	})
+
+	_, err = collectorClient.Heartbeat()
+	suite.Error(err)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_OpenChannel() {
+	acks := make(chan *hosts.AgentMessage)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		suite.Equal("/api/agent/ws", req.URL.Path)
+		suite.Equal(DummyAgentID, req.URL.Query().Get("agent_id"))
+
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, req, nil)
+		suite.NoError(err)
+		defer conn.Close()
+
+		conn.WriteJSON(&hosts.AgentMessage{
+			ID:      "1",
+			Type:    hosts.AgentMessageDiscoveryRequested,
+			Payload: json.RawMessage(`{"discovery_type": "host_discovery"}`),
+		})
+
+		var ack hosts.AgentMessage
+		suite.NoError(conn.ReadJSON(&ack))
+		acks <- &ack
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: serverURL.Hostname(),
+		CollectorPort: port,
+	})
+	suite.NoError(err)
+
+	channel, err := collectorClient.OpenChannel()
+	suite.NoError(err)
+	defer channel.Close()
+
+	message, err := channel.Receive()
+	suite.NoError(err)
+	suite.Equal("1", message.ID)
+	suite.Equal(hosts.AgentMessageDiscoveryRequested, message.Type)
+	suite.JSONEq(`{"discovery_type": "host_discovery"}`, string(message.Payload))
+
+	suite.NoError(channel.Ack(message.ID, fmt.Errorf("discovery failed")))
+	suite.Equal(&hosts.AgentMessage{ID: "1", Type: hosts.AgentMessageAck, Error: "discovery failed"}, <-acks)
 }
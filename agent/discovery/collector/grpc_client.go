@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/trento-project/trento/internal/collectorpb"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/internal/signing"
+)
+
+// grpcClient streams the discovered data to the gRPC collector over a single connection. The agent still
+// enrolls over HTTP to get its JWT, if enabled
+type grpcClient struct {
+	*client
+	conn      *grpc.ClientConn
+	collector collectorpb.CollectorClient
+
+	streamMutex sync.Mutex
+	stream      collectorpb.Collector_CollectClient
+}
+
+func NewCollectorGRPCClient(config *Config) (*grpcClient, error) {
+	if !config.EnablemTLS {
+		return nil, errors.New("the gRPC collector requires mTLS to be enabled")
+	}
+
+	httpClient, err := NewCollectorClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := getTLSConfig(config.Cert, config.Key, config.CA)
+	if err != nil {
+		return nil, err
+	}
+
+	address := net.JoinHostPort(config.CollectorHost, strconv.Itoa(config.CollectorGRPCPort))
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	if err != nil {
+		return nil, err
+	}
+
+	return newGRPCClient(httpClient, conn), nil
+}
+
+func newGRPCClient(httpClient *client, conn *grpc.ClientConn) *grpcClient {
+	return &grpcClient{
+		client:    httpClient,
+		conn:      conn,
+		collector: collectorpb.NewCollectorClient(conn),
+	}
+}
+
+// Publish sends the data on the stream, opened on the first call, and waits for the collector to acknowledge it
+func (c *grpcClient) Publish(discoveryType string, payload interface{}) error {
+	log.Debugf("Streaming %s to data collector", discoveryType)
+
+	if err := c.checkBackoff(); err != nil {
+		return err
+	}
+
+	event, err := c.marshalEvent(discoveryType, payload)
+	if err != nil {
+		return err
+	}
+
+	req := &collectorpb.CollectRequest{Event: event}
+	if c.config.SigningSecret != "" {
+		req.Timestamp, req.Signature = signing.Sign(c.config.SigningSecret, time.Now(), event)
+	}
+
+	c.streamMutex.Lock()
+	defer c.streamMutex.Unlock()
+
+	if c.stream == nil {
+		ctx, err := c.outgoingContext(context.Background())
+		if err != nil {
+			return err
+		}
+
+		c.stream, err = c.collector.Collect(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.stream.Send(req)
+	if err == nil {
+		var resp *collectorpb.CollectResponse
+		resp, err = c.stream.Recv()
+		if err == nil {
+			return c.checkCollectResponse(resp, discoveryType)
+		}
+	}
+
+	// the stream is opened again on the next call
+	c.stream = nil
+	c.checkStatus(err)
+
+	return err
+}
+
+func (c *grpcClient) checkCollectResponse(resp *collectorpb.CollectResponse, discoveryType string) error {
+	if resp.Status == http.StatusAccepted {
+		return nil
+	}
+
+	if resp.Status == http.StatusTooManyRequests {
+		// the stream does not carry the Retry-After header
+		return c.backOff("")
+	}
+
+	if resp.Status == http.StatusUnauthorized && c.config.EnableJWT {
+		// the JWT the stream was opened with may have expired, open it with a new one on the next call
+		_ = c.stream.CloseSend()
+		c.stream = nil
+		c.resetToken()
+	}
+
+	return fmt.Errorf(
+		"something wrong happened while streaming data to the collector. Status: %d, Error: %s, Agent: %s, discovery: %s",
+		resp.Status, resp.Error, c.agentID, discoveryType)
+}
+
+// Heartbeat tells whether the logs are requested from the header of the response, the logs are shipped over HTTP
+func (c *grpcClient) Heartbeat() (bool, error) {
+	ctx, err := c.outgoingContext(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	var header metadata.MD
+	_, err = c.collector.Heartbeat(ctx, &collectorpb.HeartbeatRequest{AgentId: c.agentID}, grpc.Header(&header))
+	c.checkStatus(err)
+	if err != nil {
+		return false, err
+	}
+
+	return len(header.Get(hosts.LogsRequestedHeader)) > 0, nil
+}
+
+// checkStatus enrolls again on the next call if the server refused the JWT, as it may have rotated its secret
+func (c *grpcClient) checkStatus(err error) {
+	if c.config.EnableJWT && status.Code(err) == codes.Unauthenticated {
+		c.resetToken()
+	}
+}
+
+// outgoingContext authenticates the calls with the JWT if enabled
+func (c *grpcClient) outgoingContext(ctx context.Context) (context.Context, error) {
+	if !c.config.EnableJWT {
+		return ctx, nil
+	}
+
+	token, err := c.getToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token), nil
+}
@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/trento-project/trento/internal/collectorpb"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/internal/signing"
+)
+
+type fakeCollectorServer struct {
+	collectorpb.UnimplementedCollectorServer
+	status     uint32
+	events     []map[string]interface{}
+	heartbeats []string
+	verify     func(req *collectorpb.CollectRequest) error
+	// logsRequested is answered in the header of the heartbeat response
+	logsRequested bool
+}
+
+func (s *fakeCollectorServer) Collect(stream collectorpb.Collector_CollectServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		status := s.status
+		if s.verify != nil && s.verify(req) != nil {
+			status = 401
+		}
+
+		var event map[string]interface{}
+		_ = json.Unmarshal(req.Event, &event)
+		s.events = append(s.events, event)
+
+		if err := stream.Send(&collectorpb.CollectResponse{Status: status}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *fakeCollectorServer) Heartbeat(ctx context.Context, req *collectorpb.HeartbeatRequest) (*collectorpb.HeartbeatResponse, error) {
+	s.heartbeats = append(s.heartbeats, req.AgentId)
+	if s.logsRequested {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(hosts.LogsRequestedHeader, "true"))
+	}
+	return &collectorpb.HeartbeatResponse{}, nil
+}
+
+func (suite *CollectorClientTestSuite) newFakeGRPCClient(config *Config, server *fakeCollectorServer) *grpcClient {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	collectorpb.RegisterCollectorServer(grpcServer, server)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	suite.T().Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	suite.NoError(err)
+
+	httpClient, err := NewCollectorClient(config)
+	suite.NoError(err)
+
+	return newGRPCClient(httpClient, conn)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorGRPCClient_NewClientWithoutTLS() {
+	_, err := NewCollectorGRPCClient(&Config{
+		CollectorHost:     "localhost",
+		CollectorGRPCPort: 8082,
+	})
+
+	suite.Error(err)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorGRPCClient_Publishing() {
+	server := &fakeCollectorServer{status: 202}
+	collectorClient := suite.newFakeGRPCClient(&Config{}, server)
+
+	suite.NoError(collectorClient.Publish("some_discovery_type", struct{ Key string }{"value"}))
+	suite.NoError(collectorClient.Publish("some_other_discovery_type", struct{}{}))
+
+	suite.Len(server.events, 2)
+	suite.Equal(DummyAgentID, server.events[0]["agent_id"])
+	suite.Equal("some_discovery_type", server.events[0]["discovery_type"])
+	suite.Equal(map[string]interface{}{"Key": "value"}, server.events[0]["payload"])
+	suite.Equal(float64(1), server.events[0]["protocol_version"])
+	suite.Equal("some_other_discovery_type", server.events[1]["discovery_type"])
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorGRPCClient_PublishingFailure() {
+	collectorClient := suite.newFakeGRPCClient(&Config{}, &fakeCollectorServer{status: 400})
+
+	suite.Error(collectorClient.Publish("some_discovery_type", struct{}{}))
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorGRPCClient_PublishingSigned() {
+	server := &fakeCollectorServer{status: 202, verify: func(req *collectorpb.CollectRequest) error {
+		return signing.Verify("some-secret", req.Timestamp, req.Signature, req.Event, time.Now())
+	}}
+	collectorClient := suite.newFakeGRPCClient(&Config{SigningSecret: "some-secret"}, server)
+
+	suite.NoError(collectorClient.Publish("some_discovery_type", struct{}{}))
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorGRPCClient_Heartbeat() {
+	server := &fakeCollectorServer{}
+	collectorClient := suite.newFakeGRPCClient(&Config{}, server)
+
+	logsRequested, err := collectorClient.Heartbeat()
+	suite.NoError(err)
+	suite.False(logsRequested)
+	suite.Equal([]string{DummyAgentID}, server.heartbeats)
+
+	server.logsRequested = true
+	logsRequested, err = collectorClient.Heartbeat()
+	suite.NoError(err)
+	suite.True(logsRequested)
+}
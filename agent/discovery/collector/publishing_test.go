@@ -79,6 +79,15 @@ func (suite *PublishingTestSuite) TestCollectorClient_PublishingSubscriptionDisc
 	})
 }
 
+func (suite *PublishingTestSuite) TestCollectorClient_PublishingKubernetesDiscovery() {
+	discoveryType := "kubernetes_discovery"
+	discoveredWorkloads := mocks.NewDiscoveredKubernetesMock()
+
+	suite.runDiscoveryScenario(discoveryType, discoveredWorkloads, func(requestBodyAgainstCollector string) {
+		suite.assertJsonMatchesJsonFileContent("./test/fixtures/discovery/kubernetes/expected_published_kubernetes_discovery.json", requestBodyAgainstCollector)
+	})
+}
+
 func (suite *PublishingTestSuite) TestCollectorClient_PublishingSAPSystemDatabaseDiscovery() {
 	discoveryType := "sap_system_discovery"
 	discoveredSAPSystem := mocks.NewDiscoveredSAPSystemDatabaseMock()
@@ -104,9 +113,10 @@ func (suite *PublishingTestSuite) runDiscoveryScenario(discoveryType string, pay
 
 	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
 		requestBody, _ := json.Marshal(map[string]interface{}{
-			"agent_id":       DummyAgentID,
-			"discovery_type": discoveryType,
-			"payload":        payload,
+			"agent_id":         DummyAgentID,
+			"discovery_type":   discoveryType,
+			"payload":          payload,
+			"protocol_version": 1,
 		})
 
 		outgoingRequestBody, _ := ioutil.ReadAll(req.Body)
@@ -12,10 +12,13 @@ type DiscoveriesPeriodConfig struct {
 	Cloud        time.Duration
 	Host         time.Duration
 	Subscription time.Duration
+	Kubernetes   time.Duration
 }
 
 type DiscoveriesConfig struct {
 	SSHAddress               string
+	Ephemeral                bool
+	ProvisioningFile         string
 	DiscoveriesPeriodsConfig *DiscoveriesPeriodConfig
 	CollectorConfig          *collector.Config
 }
@@ -1,13 +1,16 @@
 package discovery
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
 	"github.com/shirou/gopsutil/host"
 	"github.com/shirou/gopsutil/mem"
 	log "github.com/sirupsen/logrus"
@@ -19,12 +22,17 @@ import (
 const HostDiscoveryId string = "host_discovery"
 const HostDiscoveryMinPeriod time.Duration = 1 * time.Second
 
+// cloudInitBootFinishedPath is written by cloud-init once it has initialized the host
+const cloudInitBootFinishedPath string = "/var/lib/cloud/instance/boot-finished"
+
 type HostDiscovery struct {
-	id              string
-	sshAddress      string
-	collectorClient collector.Client
-	host            string
-	interval        time.Duration
+	id               string
+	sshAddress       string
+	ephemeral        bool
+	provisioningFile string
+	collectorClient  collector.Client
+	host             string
+	interval         time.Duration
 }
 
 func NewHostDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
@@ -34,6 +42,8 @@ func NewHostDiscovery(collectorClient collector.Client, config DiscoveriesConfig
 	d.host, _ = os.Hostname()
 	d.interval = config.DiscoveriesPeriodsConfig.Host
 	d.sshAddress = config.SSHAddress
+	d.ephemeral = config.Ephemeral
+	d.provisioningFile = config.ProvisioningFile
 	return d
 }
 
@@ -59,8 +69,13 @@ func (d HostDiscovery) Discover() (string, error) {
 		HostName:        d.host,
 		CPUCount:        getLogicalCPUs(),
 		SocketCount:     getCPUSocketCount(),
+		CoreCount:       getPhysicalCores(),
 		TotalMemoryMB:   getTotalMemoryMB(),
+		Hypervisor:      getHypervisor(),
 		AgentVersion:    version.Version,
+		Utilization:     GetUtilization(),
+		Ephemeral:       d.ephemeral,
+		Provisioning:    getProvisioning(d.provisioningFile),
 	}
 
 	err = d.collectorClient.Publish(d.id, host)
@@ -119,6 +134,28 @@ func getLogicalCPUs() int {
 	return logical
 }
 
+func getPhysicalCores() int {
+	physical, err := cpu.Counts(false)
+	if err != nil {
+		log.Errorf("Error while getting physical CPU count: %s", err)
+	}
+	return physical
+}
+
+// getHypervisor returns the virtualization system of a guest host, empty on bare metal or on hypervisor hosts
+func getHypervisor() string {
+	infoStat, err := host.Info()
+	if err != nil {
+		log.Errorf("Error while getting host info: %s", err)
+		return ""
+	}
+
+	if infoStat.VirtualizationRole != "guest" {
+		return ""
+	}
+	return infoStat.VirtualizationSystem
+}
+
 func getCPUSocketCount() int {
 	info, err := cpu.Info()
 
@@ -140,3 +177,74 @@ func getCPUSocketCount() int {
 	// Increase by one as physicalIDs start in zero
 	return physicalID + 1
 }
+
+// GetUtilization returns the resources usage of the host, nil if it cannot be read
+func GetUtilization() *hosts.HostUtilization {
+	// Without an interval, the usage since the previous call, e.g. since the previous discovery, is returned
+	cpuPercent, err := cpu.Percent(0, false)
+	if err != nil || len(cpuPercent) == 0 {
+		log.Errorf("Error while getting CPU utilization: %v", err)
+		return nil
+	}
+
+	v, err := mem.VirtualMemory()
+	if err != nil {
+		log.Errorf("Error while getting memory utilization: %s", err)
+		return nil
+	}
+
+	return &hosts.HostUtilization{
+		CPUPercent:    cpuPercent[0],
+		MemoryPercent: v.UsedPercent,
+		DiskPercent:   getDiskPercent(),
+	}
+}
+
+// getDiskPercent returns the usage of the fullest local filesystem
+func getDiskPercent() float64 {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		log.Errorf("Error while getting disk partitions: %s", err)
+		return 0
+	}
+
+	var diskPercent float64
+	for _, partition := range partitions {
+		usage, err := disk.Usage(partition.Mountpoint)
+		if err != nil {
+			log.Debugf("Error while getting the usage of %s: %s", partition.Mountpoint, err)
+			continue
+		}
+
+		if usage.UsedPercent > diskPercent {
+			diskPercent = usage.UsedPercent
+		}
+	}
+
+	return diskPercent
+}
+
+// getProvisioning reads the provisioning metadata written by the deployment automation,
+// the hosts initialized by cloud-init without any metadata are reported as such
+func getProvisioning(provisioningFile string) *hosts.Provisioning {
+	data, err := ioutil.ReadFile(provisioningFile)
+	if err == nil {
+		var provisioning hosts.Provisioning
+		if err := json.Unmarshal(data, &provisioning); err != nil {
+			log.Errorf("Error while parsing the provisioning file %s: %s", provisioningFile, err)
+			return nil
+		}
+		return &provisioning
+	}
+
+	if !os.IsNotExist(err) {
+		log.Errorf("Error while reading the provisioning file %s: %s", provisioningFile, err)
+		return nil
+	}
+
+	if _, err := os.Stat(cloudInitBootFinishedPath); err == nil {
+		return &hosts.Provisioning{Tool: "cloud-init"}
+	}
+
+	return nil
+}
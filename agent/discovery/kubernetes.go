@@ -0,0 +1,55 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/internal/kubernetes"
+)
+
+const KubernetesDiscoveryId string = "kubernetes_discovery"
+const KubernetesDiscoveryMinPeriod time.Duration = 10 * time.Second
+
+type KubernetesDiscovery struct {
+	id              string
+	collectorClient collector.Client
+	host            string
+	interval        time.Duration
+}
+
+func NewKubernetesDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
+	d := KubernetesDiscovery{}
+	d.id = KubernetesDiscoveryId
+	d.collectorClient = collectorClient
+	d.host, _ = os.Hostname()
+	d.interval = config.DiscoveriesPeriodsConfig.Kubernetes
+
+	return d
+}
+
+func (d KubernetesDiscovery) GetId() string {
+	return d.id
+}
+
+func (d KubernetesDiscovery) GetInterval() time.Duration {
+	return d.interval
+}
+
+// Discover publishes the SAP workloads scheduled on the Kubernetes node named after the host
+func (d KubernetesDiscovery) Discover() (string, error) {
+	workloads, err := kubernetes.NewWorkloads(d.host)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.collectorClient.Publish(d.id, workloads)
+	if err != nil {
+		log.Debugf("Error while sending kubernetes discovery to data collector: %s", err)
+		return "", err
+	}
+
+	return fmt.Sprintf("Kubernetes SAP workloads (%d pods) discovered", len(workloads)), nil
+}
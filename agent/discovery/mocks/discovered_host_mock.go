@@ -10,7 +10,18 @@ func NewDiscoveredHostMock() hosts.DiscoveredHost {
 		HostName:        "thehostnamewherethediscoveryhappened",
 		CPUCount:        2,
 		SocketCount:     1,
+		CoreCount:       2,
 		TotalMemoryMB:   4096,
+		Hypervisor:      "kvm",
 		AgentVersion:    "trento-agent-version",
+		Utilization: &hosts.HostUtilization{
+			CPUPercent:    12.5,
+			MemoryPercent: 40.2,
+			DiskPercent:   71.3,
+		},
+		Provisioning: &hosts.Provisioning{
+			Tool:            "terraform",
+			TemplateVersion: "1.2.0",
+		},
 	}
 }
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"github.com/trento-project/trento/internal/kubernetes"
+)
+
+func NewDiscoveredKubernetesMock() kubernetes.Workloads {
+	return kubernetes.Workloads{
+		&kubernetes.Workload{
+			Namespace:      "sap-ha1",
+			Pod:            "ha1-ascs-0",
+			Node:           "vmhana01",
+			SID:            "HA1",
+			InstanceNumber: "00",
+			Type:           "application",
+			Component:      "ASCS",
+			Image:          "registry.example.com/sap/ascs:7.53",
+			Phase:          "Running",
+		},
+		&kubernetes.Workload{
+			Namespace:      "sap-ha1",
+			Pod:            "ha1-di-0",
+			Node:           "vmhana01",
+			SID:            "HA1",
+			InstanceNumber: "01",
+			Type:           "application",
+			Component:      "DIALOG",
+			Image:          "registry.example.com/sap/di:7.53",
+			Phase:          "Pending",
+		},
+	}
+}
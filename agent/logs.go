@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"os/exec"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// agentUnit is the systemd unit of the agent, whose logs are collected by journald
+	agentUnit = "trento-agent"
+	// shippedLogsLines are the latest lines of the logs shipped when requested
+	shippedLogsLines = 1000
+)
+
+var journalctlExecCommand = exec.Command
+
+// logsShipper ships the recent logs of the agent when the server requests them, one shipment at a time
+type logsShipper struct {
+	shipping int32
+}
+
+func (s *logsShipper) ship(shipLogs func(logs string) error) {
+	if !atomic.CompareAndSwapInt32(&s.shipping, 0, 1) {
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&s.shipping, 0)
+
+		logs, err := recentLogs()
+		if err != nil {
+			log.Errorf("Error while reading the agent logs: %s", err)
+			return
+		}
+
+		if err := shipLogs(logs); err != nil {
+			log.Errorf("Error while shipping the agent logs: %s", err)
+			return
+		}
+
+		log.Info("Agent logs shipped as requested by the server")
+	}()
+}
+
+// recentLogs returns the latest lines of the agent logs, the error of journalctl included if it fails
+func recentLogs() (string, error) {
+	output, err := journalctlExecCommand(
+		"journalctl", "--unit", agentUnit, "--lines", strconv.Itoa(shippedLogsLines), "--no-pager", "--output", "short-iso",
+	).CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "journalctl failed: %s", output)
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/internal/hosts"
+)
+
+const (
+	// metricsSampleInterval is the period of time the resources usage samples are taken at
+	metricsSampleInterval = 15 * time.Second
+	// metricsPushInterval is the period of time the samples are pushed to the collector at
+	metricsPushInterval = time.Minute
+	// maxBufferedMetricsSamples bounds the samples kept while the collector is unreachable, the oldest ones dropped
+	maxBufferedMetricsSamples = 360
+)
+
+// metricsBuffer keeps the samples taken until they are pushed
+type metricsBuffer struct {
+	mutex   sync.Mutex
+	samples []*hosts.MetricsSample
+}
+
+func (b *metricsBuffer) sample() {
+	utilization := discovery.GetUtilization()
+	if utilization == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.samples = append(b.samples, &hosts.MetricsSample{Time: time.Now().UTC(), HostUtilization: *utilization})
+	if len(b.samples) > maxBufferedMetricsSamples {
+		b.samples = b.samples[len(b.samples)-maxBufferedMetricsSamples:]
+	}
+}
+
+// push sends the samples taken meanwhile, keeping them for the next push if it fails
+func (b *metricsBuffer) push(pushMetrics func(samples []*hosts.MetricsSample) error) {
+	b.mutex.Lock()
+	samples := b.samples
+	b.samples = nil
+	b.mutex.Unlock()
+
+	if len(samples) == 0 {
+		return
+	}
+
+	if err := pushMetrics(samples); err != nil {
+		log.Errorf("Error while pushing the metrics to the server: %s", err)
+
+		b.mutex.Lock()
+		b.samples = append(samples, b.samples...)
+		if len(b.samples) > maxBufferedMetricsSamples {
+			b.samples = b.samples[len(b.samples)-maxBufferedMetricsSamples:]
+		}
+		b.mutex.Unlock()
+	}
+}
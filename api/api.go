@@ -1,13 +1,18 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 
 	log "github.com/sirupsen/logrus"
 
 	webApi "github.com/trento-project/trento/web"
+	"github.com/trento-project/trento/web/models"
 )
 
 //go:generate mockery --all
@@ -15,27 +20,47 @@ import (
 type TrentoApiService interface {
 	IsWebServerUp() bool
 	GetClustersSettings() (webApi.ClustersSettingsResponse, error)
+	GetRunnerSettings() (*models.RunnerSettings, error)
+	UpdateRunsQueue(runs []*models.QueuedRun) error
 }
 
 type trentoApiService struct {
 	apiHost    string
 	apiPort    int
+	apiKey     string
 	httpClient *http.Client
 }
 
-func NewTrentoApiService(apiHost string, apiPort int) *trentoApiService {
+// NewTrentoApiService returns a client of the web API, authenticated with the given API key if not empty
+func NewTrentoApiService(apiHost string, apiPort int, apiKey string) *trentoApiService {
 	client := &http.Client{}
-	return &trentoApiService{apiHost: apiHost, apiPort: apiPort, httpClient: client}
+	return &trentoApiService{apiHost: apiHost, apiPort: apiPort, apiKey: apiKey, httpClient: client}
 }
 
 func (t *trentoApiService) composeQuery(resource string) string {
-	return fmt.Sprintf("http://%s:%d/api/%s", t.apiHost, t.apiPort, resource)
+	return fmt.Sprintf("http://%s/api/%s", net.JoinHostPort(t.apiHost, strconv.Itoa(t.apiPort)), resource)
+}
+
+func (t *trentoApiService) newRequest(method string, query string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, t.composeQuery(query), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	return req, nil
 }
 
 func (t *trentoApiService) getJson(query string) ([]byte, int, error) {
-	var err error
+	req, err := t.newRequest(http.MethodGet, query, nil)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	resp, err := t.httpClient.Get(t.composeQuery(query))
+	resp, err := t.httpClient.Do(req)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -49,6 +74,27 @@ func (t *trentoApiService) getJson(query string) ([]byte, int, error) {
 	return body, resp.StatusCode, nil
 }
 
+func (t *trentoApiService) putJson(query string, payload interface{}) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := t.newRequest(http.MethodPut, query, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
 func (t *trentoApiService) IsWebServerUp() bool {
 	host := t.composeQuery("ping")
 	log.Debugf("Looking for the Trento server state at: %s", host)
@@ -10,7 +10,7 @@ import (
 )
 
 func TestIsWebServerUp(t *testing.T) {
-	trentoApi := NewTrentoApiService("192.168.1.10", 8000)
+	trentoApi := NewTrentoApiService("192.168.1.10", 8000, "")
 
 	trentoApi.httpClient = &http.Client{Transport: helpers.RoundTripFunc(func(req *http.Request) *http.Response {
 		assert.Equal(t, req.URL.String(), "http://192.168.1.10:8000/api/ping")
@@ -36,3 +36,22 @@ func TestIsWebServerUp(t *testing.T) {
 
 	assert.Equal(t, false, result)
 }
+
+func TestApiKeyAuthorization(t *testing.T) {
+	trentoApi := NewTrentoApiService("192.168.1.10", 8000, "some-api-key")
+
+	trentoApi.httpClient = &http.Client{Transport: helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		assert.Equal(t, "Bearer some-api-key", req.Header.Get("Authorization"))
+
+		return &http.Response{
+			StatusCode: 204,
+			Body:       http.NoBody,
+		}
+	})}
+
+	_, _, err := trentoApi.getJson("runner/settings")
+	assert.NoError(t, err)
+
+	_, err = trentoApi.putJson("runs/queue", []string{})
+	assert.NoError(t, err)
+}
@@ -61,7 +61,7 @@ func TestClusterSettingsApiTestCase(t *testing.T) {
 }
 
 func (suite *ClusterSettingsApiTestCase) SetupSuite() {
-	suite.trentoApi = NewTrentoApiService("192.168.1.10", 8000)
+	suite.trentoApi = NewTrentoApiService("192.168.1.10", 8000, "")
 }
 
 func (suite *ClusterSettingsApiTestCase) Test_AnErrorOccursInCommunication() {
@@ -4,6 +4,10 @@ package mocks
 
 import (
 	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+
+	testing "testing"
+
 	web "github.com/trento-project/trento/web"
 )
 
@@ -35,6 +39,29 @@ func (_m *TrentoApiService) GetClustersSettings() (web.ClustersSettingsResponse,
 	return r0, r1
 }
 
+// GetRunnerSettings provides a mock function with given fields:
+func (_m *TrentoApiService) GetRunnerSettings() (*models.RunnerSettings, error) {
+	ret := _m.Called()
+
+	var r0 *models.RunnerSettings
+	if rf, ok := ret.Get(0).(func() *models.RunnerSettings); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RunnerSettings)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // IsWebServerUp provides a mock function with given fields:
 func (_m *TrentoApiService) IsWebServerUp() bool {
 	ret := _m.Called()
@@ -48,3 +75,27 @@ func (_m *TrentoApiService) IsWebServerUp() bool {
 
 	return r0
 }
+
+// UpdateRunsQueue provides a mock function with given fields: runs
+func (_m *TrentoApiService) UpdateRunsQueue(runs []*models.QueuedRun) error {
+	ret := _m.Called(runs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*models.QueuedRun) error); ok {
+		r0 = rf(runs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewTrentoApiService creates a new instance of TrentoApiService. It also registers the testing.TB interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTrentoApiService(t testing.TB) *TrentoApiService {
+	mock := &TrentoApiService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+func (t *trentoApiService) GetRunnerSettings() (*models.RunnerSettings, error) {
+	body, statusCode, err := t.getJson("runner/settings")
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("error during the request with status code %d", statusCode)
+	}
+
+	var runnerSettings models.RunnerSettings
+
+	err = json.Unmarshal(body, &runnerSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &runnerSettings, nil
+}
+
+func (t *trentoApiService) UpdateRunsQueue(runs []*models.QueuedRun) error {
+	statusCode, err := t.putJson("runs/queue", runs)
+	if err != nil {
+		return err
+	}
+
+	if statusCode != http.StatusNoContent {
+		return fmt.Errorf("error during the request with status code %d", statusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/models"
+)
+
+type RunnerApiTestCase struct {
+	suite.Suite
+	trentoApi *trentoApiService
+}
+
+func TestRunnerApiTestCase(t *testing.T) {
+	suite.Run(t, new(RunnerApiTestCase))
+}
+
+func (suite *RunnerApiTestCase) SetupTest() {
+	suite.trentoApi = NewTrentoApiService("192.168.1.10", 8000, "")
+}
+
+func (suite *RunnerApiTestCase) Test_GetRunnerSettings() {
+	suite.trentoApi.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal("http://192.168.1.10:8000/api/runner/settings", req.URL.String())
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"max_concurrent_runs": 4, "max_runs_per_target": 1}`)),
+		}
+	})
+
+	runnerSettings, err := suite.trentoApi.GetRunnerSettings()
+
+	suite.NoError(err)
+	suite.Equal(&models.RunnerSettings{MaxConcurrentRuns: 4, MaxRunsPerTarget: 1}, runnerSettings)
+}
+
+func (suite *RunnerApiTestCase) Test_GetRunnerSettingsNotSuccessful() {
+	suite.trentoApi.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 500,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}
+	})
+
+	_, err := suite.trentoApi.GetRunnerSettings()
+
+	suite.EqualError(err, "error during the request with status code 500")
+}
+
+func (suite *RunnerApiTestCase) Test_UpdateRunsQueue() {
+	suite.trentoApi.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal("http://192.168.1.10:8000/api/runs/queue", req.URL.String())
+		suite.Equal(http.MethodPut, req.Method)
+
+		body, _ := io.ReadAll(req.Body)
+		suite.JSONEq(`[{
+			"cluster_id": "cluster1",
+			"targets": ["10.1.2.10"],
+			"state": "queued",
+			"queued_at": "2022-03-01T10:00:00Z"
+		}]`, string(body))
+
+		return &http.Response{
+			StatusCode: 204,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}
+	})
+
+	err := suite.trentoApi.UpdateRunsQueue([]*models.QueuedRun{
+		{
+			ClusterID: "cluster1",
+			Targets:   []string{"10.1.2.10"},
+			State:     models.QueuedRunStateQueued,
+			QueuedAt:  time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC),
+		},
+	})
+
+	suite.NoError(err)
+}
+
+func (suite *RunnerApiTestCase) Test_UpdateRunsQueueError() {
+	suite.trentoApi.httpClient.Transport = helpers.ErroringRoundTripFunc(func() error {
+		return fmt.Errorf("some error")
+	})
+
+	err := suite.trentoApi.UpdateRunsQueue([]*models.QueuedRun{})
+
+	suite.Error(err)
+}
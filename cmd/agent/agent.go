@@ -18,21 +18,30 @@ import (
 
 func NewAgentCmd() *cobra.Command {
 	var sshAddress string
+	var ephemeral bool
+	var provisioningFile string
 
 	var clusterDiscoveryPeriod time.Duration
 	var sapSystemDiscoveryPeriod time.Duration
 	var cloudDiscoveryPeriod time.Duration
 	var hostDiscoveryPeriod time.Duration
 	var subscriptionDiscoveryPeriod time.Duration
+	var kubernetesDiscoveryPeriod time.Duration
 
 	var collectorHost string
 	var collectorPort int
+	var collectorGRPCPort int
 
 	var enablemTLS bool
 	var cert string
 	var key string
 	var ca string
 
+	var enableJWT bool
+	var enrollmentToken string
+	var agentSecretFile string
+	var signingSecret string
+
 	agentCmd := &cobra.Command{
 		Use:   "agent",
 		Short: "Command tree related to the agent component",
@@ -52,6 +61,8 @@ func NewAgentCmd() *cobra.Command {
 	}
 
 	startCmd.Flags().StringVar(&sshAddress, "ssh-address", "", "The address to which the trento-agent should be reachable for ssh connection by the runner for check execution.")
+	startCmd.Flags().BoolVar(&ephemeral, "ephemeral", false, "Mark the host as ephemeral, like an auto-scaled application server. Ephemeral hosts are removed after a shorter silence window, without raising alerts")
+	startCmd.Flags().StringVar(&provisioningFile, "provisioning-file", "/etc/trento/provisioning.json", "JSON file with the provisioning metadata of the host, like {\"tool\": \"terraform\", \"template_version\": \"1.2.0\"}, written by the deployment automation")
 
 	startCmd.Flags().DurationVarP(&clusterDiscoveryPeriod, "cluster-discovery-period", "", 10*time.Second, "Cluster discovery mechanism loop period in seconds")
 	startCmd.Flags().DurationVarP(&sapSystemDiscoveryPeriod, "sapsystem-discovery-period", "", 10*time.Second, "SAP systems discovery mechanism loop period in seconds")
@@ -59,16 +70,24 @@ func NewAgentCmd() *cobra.Command {
 	startCmd.Flags().DurationVarP(&hostDiscoveryPeriod, "host-discovery-period", "", 10*time.Second, "Host discovery mechanism loop period in seconds")
 	startCmd.Flags().DurationVarP(&subscriptionDiscoveryPeriod, "subscription-discovery-period", "", 900*time.Second, "Subscription discovery mechanism loop period in seconds")
 
+	startCmd.Flags().DurationVarP(&kubernetesDiscoveryPeriod, "kubernetes-discovery-period", "", 60*time.Second, "Kubernetes SAP workloads discovery mechanism loop period in seconds")
+
 	startCmd.Flags().MarkHidden("subscription-discovery-period")
 
 	startCmd.Flags().StringVar(&collectorHost, "collector-host", "localhost", "Data Collector host")
 	startCmd.Flags().IntVar(&collectorPort, "collector-port", 8081, "Data Collector port")
+	startCmd.Flags().IntVar(&collectorGRPCPort, "collector-grpc-port", 0, "Port of the gRPC Data Collector the data is streamed to in place of HTTP, it requires mTLS. HTTP is used if 0")
 
 	startCmd.Flags().BoolVar(&enablemTLS, "enable-mtls", false, "Enable mTLS authentication between server and agent")
 	startCmd.Flags().StringVar(&cert, "cert", "", "mTLS client certificate")
 	startCmd.Flags().StringVar(&key, "key", "", "mTLS client key")
 	startCmd.Flags().StringVar(&ca, "ca", "", "mTLS Certificate Authority")
 
+	startCmd.Flags().BoolVar(&enableJWT, "enable-jwt", false, "Enable JWT authentication between server and agent, an alternative to mTLS")
+	startCmd.Flags().StringVar(&enrollmentToken, "enrollment-token", "", "Token the agent enrolls with to get its JWT")
+	startCmd.Flags().StringVar(&agentSecretFile, "agent-secret-file", "/var/lib/trento/agent-secret", "File the secret issued at the first enrollment of the agent is kept in, required to enroll it again")
+	startCmd.Flags().StringVar(&signingSecret, "signing-secret", "", "Secret the payloads are signed with, generated for the agent by the server")
+
 	agentCmd.AddCommand(startCmd)
 
 	return agentCmd
@@ -26,6 +26,8 @@ func LoadConfig() (*agent.Config, error) {
 	cert := viper.GetString("cert")
 	key := viper.GetString("key")
 	ca := viper.GetString("ca")
+	enableJWT := viper.GetBool("enable-jwt")
+	enrollmentToken := viper.GetString("enrollment-token")
 
 	minPeriodValues := map[string]time.Duration{
 		"cluster-discovery-period":      discovery.ClusterDiscoveryMinPeriod,
@@ -33,6 +35,7 @@ func LoadConfig() (*agent.Config, error) {
 		"cloud-discovery-period":        discovery.CloudDiscoveryMinPeriod,
 		"host-discovery-period":         discovery.HostDiscoveryMinPeriod,
 		"subscription-discovery-period": discovery.SubscriptionDiscoveryMinPeriod,
+		"kubernetes-discovery-period":   discovery.KubernetesDiscoveryMinPeriod,
 	}
 
 	for flagName, minPeriodValue := range minPeriodValues {
@@ -59,6 +62,10 @@ func LoadConfig() (*agent.Config, error) {
 		}
 	}
 
+	if enableJWT && enrollmentToken == "" {
+		return nil, errors.New("you must provide an enrollment token to enable JWT authentication")
+	}
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not read the hostname")
@@ -70,12 +77,18 @@ func LoadConfig() (*agent.Config, error) {
 	}
 
 	collectorConfig := &collector.Config{
-		CollectorHost: viper.GetString("collector-host"),
-		CollectorPort: viper.GetInt("collector-port"),
-		EnablemTLS:    enablemTLS,
-		Cert:          cert,
-		Key:           key,
-		CA:            ca,
+		CollectorHost:   viper.GetString("collector-host"),
+		CollectorPort:   viper.GetInt("collector-port"),
+		EnablemTLS:      enablemTLS,
+		Cert:            cert,
+		Key:             key,
+		CA:              ca,
+		EnableJWT:       enableJWT,
+		EnrollmentToken: enrollmentToken,
+		AgentSecretFile: viper.GetString("agent-secret-file"),
+		SigningSecret:   viper.GetString("signing-secret"),
+
+		CollectorGRPCPort: viper.GetInt("collector-grpc-port"),
 	}
 
 	discoveryPeriodsConfig := &discovery.DiscoveriesPeriodConfig{
@@ -84,10 +97,13 @@ func LoadConfig() (*agent.Config, error) {
 		Cloud:        viper.GetDuration("cloud-discovery-period"),
 		Host:         viper.GetDuration("host-discovery-period"),
 		Subscription: viper.GetDuration("subscription-discovery-period"),
+		Kubernetes:   viper.GetDuration("kubernetes-discovery-period"),
 	}
 
 	discoveriesConfig := &discovery.DiscoveriesConfig{
 		SSHAddress:               sshAddress,
+		Ephemeral:                viper.GetBool("ephemeral"),
+		ProvisioningFile:         viper.GetString("provisioning-file"),
 		CollectorConfig:          collectorConfig,
 		DiscoveriesPeriodsConfig: discoveryPeriodsConfig,
 	}
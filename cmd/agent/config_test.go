@@ -47,21 +47,30 @@ func (suite *AgentCmdTestSuite) TearDownTest() {
 	expectedConfig := &agent.Config{
 		InstanceName: "some-hostname",
 		DiscoveriesConfig: &discovery.DiscoveriesConfig{
-			SSHAddress: "some-ssh-address",
+			SSHAddress:       "some-ssh-address",
+			Ephemeral:        true,
+			ProvisioningFile: "/etc/provisioning.json",
 			DiscoveriesPeriodsConfig: &discovery.DiscoveriesPeriodConfig{
 				Cluster:      10 * time.Second,
 				SAPSystem:    10 * time.Second,
 				Cloud:        10 * time.Second,
 				Host:         10 * time.Second,
 				Subscription: 900 * time.Second,
+				Kubernetes:   60 * time.Second,
 			},
 			CollectorConfig: &collector.Config{
-				CollectorHost: "localhost",
-				CollectorPort: 1337,
-				EnablemTLS:    true,
-				Cert:          "some-cert",
-				Key:           "some-key",
-				CA:            "some-ca",
+				CollectorHost:   "localhost",
+				CollectorPort:   1337,
+				EnablemTLS:      true,
+				Cert:            "some-cert",
+				Key:             "some-key",
+				CA:              "some-ca",
+				EnableJWT:       true,
+				EnrollmentToken: "some-enrollment-token",
+				AgentSecretFile: "/var/lib/agent-secret",
+				SigningSecret:   "some-signing-secret",
+
+				CollectorGRPCPort: 1338,
 			},
 		},
 	}
@@ -77,33 +86,49 @@ func (suite *AgentCmdTestSuite) TestConfigFromFlags() {
 	suite.cmd.SetArgs([]string{
 		"start",
 		"--ssh-address=some-ssh-address",
+		"--ephemeral",
+		"--provisioning-file=/etc/provisioning.json",
 		"--cloud-discovery-period=10s",
 		"--cluster-discovery-period=10s",
 		"--sapsystem-discovery-period=10s",
 		"--host-discovery-period=10s",
 		"--subscription-discovery-period=900s",
+		"--kubernetes-discovery-period=60s",
 		"--collector-host=localhost",
 		"--collector-port=1337",
 		"--enable-mtls",
 		"--cert=some-cert",
 		"--key=some-key",
 		"--ca=some-ca",
+		"--enable-jwt",
+		"--enrollment-token=some-enrollment-token",
+		"--agent-secret-file=/var/lib/agent-secret",
+		"--signing-secret=some-signing-secret",
+		"--collector-grpc-port=1338",
 	})
 }
 
 func (suite *AgentCmdTestSuite) TestConfigFromEnv() {
 	os.Setenv("TRENTO_SSH_ADDRESS", "some-ssh-address")
+	os.Setenv("TRENTO_EPHEMERAL", "true")
+	os.Setenv("TRENTO_PROVISIONING_FILE", "/etc/provisioning.json")
 	os.Setenv("TRENTO_CLOUD_DISCOVERY_PERIOD", "10s")
 	os.Setenv("TRENTO_CLUSTER_DISCOVERY_PERIOD", "10s")
 	os.Setenv("TRENTO_SAPSYSTEM_DISCOVERY_PERIOD", "10s")
 	os.Setenv("TRENTO_HOST_DISCOVERY_PERIOD", "10s")
 	os.Setenv("TRENTO_SUBSCRIPTION_DISCOVERY_PERIOD", "900s")
+	os.Setenv("TRENTO_KUBERNETES_DISCOVERY_PERIOD", "60s")
 	os.Setenv("TRENTO_COLLECTOR_HOST", "localhost")
 	os.Setenv("TRENTO_COLLECTOR_PORT", "1337")
 	os.Setenv("TRENTO_ENABLE_MTLS", "true")
 	os.Setenv("TRENTO_CERT", "some-cert")
 	os.Setenv("TRENTO_KEY", "some-key")
 	os.Setenv("TRENTO_CA", "some-ca")
+	os.Setenv("TRENTO_ENABLE_JWT", "true")
+	os.Setenv("TRENTO_ENROLLMENT_TOKEN", "some-enrollment-token")
+	os.Setenv("TRENTO_AGENT_SECRET_FILE", "/var/lib/agent-secret")
+	os.Setenv("TRENTO_SIGNING_SECRET", "some-signing-secret")
+	os.Setenv("TRENTO_COLLECTOR_GRPC_PORT", "1338")
 }
 
 func (suite *AgentCmdTestSuite) TestConfigFromFile() {
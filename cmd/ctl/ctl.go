@@ -22,6 +22,7 @@ import (
 
 	"github.com/trento-project/trento/web/datapipeline"
 	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/services"
 	"gorm.io/gorm"
 )
 
@@ -46,6 +47,7 @@ func NewCtlCmd() *cobra.Command {
 	addPruneChecksResultsCmd(ctlCmd)
 	addDBResetCmd(ctlCmd)
 	addDumpScenarioCmd(ctlCmd)
+	addRequeueDeadLettersCmd(ctlCmd)
 
 	return ctlCmd
 }
@@ -55,7 +57,7 @@ func addPruneEventsCmd(ctlCmd *cobra.Command) {
 
 	pruneCmd := &cobra.Command{
 		Use:   "prune-events",
-		Short: "Prune events older than",
+		Short: "Prune events older than, but the latest one of every agent discovery",
 		Run: func(*cobra.Command, []string) {
 			ctx := getContext()
 			db := initDB(ctx)
@@ -130,6 +132,26 @@ func addDumpScenarioCmd(ctlCmd *cobra.Command) {
 	ctlCmd.AddCommand(dumpScenarioCmd)
 }
 
+func addRequeueDeadLettersCmd(ctlCmd *cobra.Command) {
+	requeueCmd := &cobra.Command{
+		Use:   "requeue-dead-letters",
+		Short: "Project again the events failed to be projected",
+		Run: func(*cobra.Command, []string) {
+			ctx := getContext()
+			db := initDB(ctx)
+			projectorID := viper.GetString("projector")
+
+			requeueDeadLetters(db, projectorID)
+		},
+	}
+
+	var projectorID string
+
+	requeueCmd.Flags().StringVar(&projectorID, "projector", "", "Requeue only the dead letters of the projector.")
+
+	ctlCmd.AddCommand(requeueCmd)
+}
+
 func getContext() context.Context {
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
@@ -156,16 +178,38 @@ func initDB(ctx context.Context) *gorm.DB {
 	return db
 }
 
+// pruneEvents keeps the latest event of every agent discovery, whatever its age
 func pruneEvents(db *gorm.DB, olderThan time.Duration) {
-	log.Infof("Pruning events older than %d days.", olderThan)
+	log.Infof("Pruning events older than %s.", olderThan)
 
-	result := db.Delete(datapipeline.DataCollectedEvent{}, "created_at < ?", time.Now().Add(-olderThan))
-	log.Debugf("Pruned %d events", result.RowsAffected)
+	collectorService := services.NewCollectorService(db, nil, services.IngestionQuota{})
+	pruned, err := collectorService.PruneEvents(olderThan)
+	log.Debugf("Pruned %d events", pruned)
 
-	if result.Error != nil {
-		log.Fatalf("Error while pruning older events: %s", result.Error)
+	if err != nil {
+		log.Fatalf("Error while pruning older events: %s", err)
+	}
+	log.Infof("Events older than %s pruned.", olderThan)
+}
+
+// requeueDeadLetters projects again the dead letters of the projector, of all the projectors if empty
+func requeueDeadLetters(db *gorm.DB, projectorID string) {
+	log.Infof("Requeuing the dead letters.")
+
+	projectorsManager := datapipeline.NewProjectorsManager(db, datapipeline.InitProjectorsRegistry(db))
+	if err := projectorsManager.Load(); err != nil {
+		log.Fatalf("Error while loading the projectors: %s", err)
+	}
+
+	result, err := projectorsManager.RequeueDeadLetters(projectorID)
+	if err != nil {
+		log.Fatalf("Error while requeuing the dead letters: %s", err)
+	}
+
+	for _, failed := range result.Failed {
+		log.Warnf("Dead letter %d of the projector %s failed again: %s", failed.ID, failed.ProjectorID, failed.Error)
 	}
-	log.Infof("Events older than %d days pruned.", olderThan)
+	log.Infof("%d dead letters requeued, %d failed.", result.Requeued, len(result.Failed))
 }
 
 func pruneChecksResults(db *gorm.DB, olderThan time.Duration) {
@@ -59,16 +59,25 @@ func (suite *CtlTestSuite) TestPruneEvents() {
 			Payload:       []byte("{}"),
 			CreatedAt:     time.Now().Add(-24 * 6 * time.Hour),
 		},
+		{
+			ID:            4,
+			AgentID:       "agent_id",
+			DiscoveryType: "another_discovery_type",
+			Payload:       []byte("{}"),
+			CreatedAt:     time.Now().Add(-24 * 15 * time.Hour),
+		},
 	}
 	suite.tx.Create(events)
 
 	pruneEvents(suite.tx, 24*10*time.Hour)
 
 	var prunedEvents []datapipeline.DataCollectedEvent
-	suite.tx.Find(&prunedEvents)
+	suite.tx.Order("id").Find(&prunedEvents)
 
-	suite.Equal(1, len(prunedEvents))
+	// the latest event of every agent discovery is kept
+	suite.Equal(2, len(prunedEvents))
 	suite.Equal(int64(3), prunedEvents[0].ID)
+	suite.Equal(int64(4), prunedEvents[1].ID)
 }
 
 func (suite *CtlTestSuite) TestPruneChecksResults() {
@@ -6,11 +6,25 @@ import (
 )
 
 func LoadConfig() *db.Config {
-	return &db.Config{
-		Host:     viper.GetString("db-host"),
-		Port:     viper.GetInt("db-port"),
-		User:     viper.GetString("db-user"),
-		Password: viper.GetString("db-password"),
-		DBName:   viper.GetString("db-name"),
+	config := &db.Config{
+		Host:                    viper.GetString("db-host"),
+		Port:                    viper.GetInt("db-port"),
+		User:                    viper.GetString("db-user"),
+		Password:                viper.GetString("db-password"),
+		DBName:                  viper.GetString("db-name"),
+		Schema:                  viper.GetString("db-schema"),
+		PasswordFile:            viper.GetString("db-password-file"),
+		PasswordRefreshInterval: viper.GetDuration("db-password-refresh-interval"),
 	}
+
+	if vaultPath := viper.GetString("db-password-vault-path"); vaultPath != "" {
+		config.Vault = &db.VaultConfig{
+			Address: viper.GetString("vault-address"),
+			Token:   viper.GetString("vault-token"),
+			Path:    vaultPath,
+			Key:     viper.GetString("db-password-vault-key"),
+		}
+	}
+
+	return config
 }
@@ -1,7 +1,11 @@
 package db
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
+
+	"github.com/trento-project/trento/internal/db"
 )
 
 func AddDBFlags(cmd *cobra.Command) {
@@ -10,10 +14,24 @@ func AddDBFlags(cmd *cobra.Command) {
 	var dbUser string
 	var dbPassword string
 	var dbName string
+	var dbSchema string
+	var dbPasswordFile string
+	var dbPasswordVaultPath string
+	var dbPasswordVaultKey string
+	var dbPasswordRefreshInterval time.Duration
+	var vaultAddress string
+	var vaultToken string
 
 	cmd.PersistentFlags().StringVar(&dbHost, "db-host", "localhost", "The database host")
 	cmd.PersistentFlags().IntVar(&dbPort, "db-port", 5432, "The database port to connect to")
 	cmd.PersistentFlags().StringVar(&dbUser, "db-user", "postgres", "The database user")
 	cmd.PersistentFlags().StringVar(&dbPassword, "db-password", "postgres", "The database password")
 	cmd.PersistentFlags().StringVar(&dbName, "db-name", "trento", "The database name that the application will use")
+	cmd.PersistentFlags().StringVar(&dbPasswordFile, "db-password-file", "", "The file the database password is read from instead, e.g. a mounted Kubernetes secret")
+	cmd.PersistentFlags().StringVar(&dbPasswordVaultPath, "db-password-vault-path", "", "The path of the Vault secret the database password is read from instead, e.g. secret/data/trento")
+	cmd.PersistentFlags().StringVar(&dbPasswordVaultKey, "db-password-vault-key", "password", "The key of the database password in the Vault secret")
+	cmd.PersistentFlags().DurationVar(&dbPasswordRefreshInterval, "db-password-refresh-interval", db.DefaultPasswordRefreshInterval, "The interval at which the database password is read again from the file or Vault, to pick up the rotated one")
+	cmd.PersistentFlags().StringVar(&vaultAddress, "vault-address", "http://127.0.0.1:8200", "The address of the Vault server")
+	cmd.PersistentFlags().StringVar(&vaultToken, "vault-token", "", "The token to authenticate to the Vault server")
+	cmd.PersistentFlags().StringVar(&dbSchema, "db-schema", "public", "The schema of the database the application tables are created in, it is created if missing. It is shared by all the organizations, multiple instances can share a database with a schema each")
 }
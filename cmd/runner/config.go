@@ -11,6 +11,7 @@ func LoadConfig() *runner.Config {
 	return &runner.Config{
 		ApiHost:       viper.GetString("api-host"),
 		ApiPort:       viper.GetInt("api-port"),
+		ApiKey:        viper.GetString("api-key"),
 		Interval:      time.Duration(viper.GetInt("interval")) * time.Minute,
 		AnsibleFolder: viper.GetString("ansible-folder"),
 	}
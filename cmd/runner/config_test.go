@@ -48,6 +48,7 @@ func (suite *RunnerCmdTestSuite) TearDownTest() {
 	expectedConfig := &runner.Config{
 		ApiHost:       "some-api-host",
 		ApiPort:       1337,
+		ApiKey:        "some-api-key",
 		Interval:      1 * time.Minute,
 		AnsibleFolder: "path/to/ansible",
 	}
@@ -61,6 +62,7 @@ func (suite *RunnerCmdTestSuite) TestConfigFromFlags() {
 		"start",
 		"--api-host=some-api-host",
 		"--api-port=1337",
+		"--api-key=some-api-key",
 		"--interval=1",
 		"--ansible-folder=path/to/ansible",
 	})
@@ -69,6 +71,7 @@ func (suite *RunnerCmdTestSuite) TestConfigFromFlags() {
 func (suite *RunnerCmdTestSuite) TestConfigFromEnv() {
 	os.Setenv("TRENTO_API_HOST", "some-api-host")
 	os.Setenv("TRENTO_API_PORT", "1337")
+	os.Setenv("TRENTO_API_KEY", "some-api-key")
 	os.Setenv("TRENTO_INTERVAL", "1")
 	os.Setenv("TRENTO_ANSIBLE_FOLDER", "path/to/ansible")
 }
@@ -18,6 +18,7 @@ import (
 func NewRunnerCmd() *cobra.Command {
 	var apiHost string
 	var apiPort int
+	var apiKey string
 	var interval int
 	var ansibleFolder string
 
@@ -41,6 +42,7 @@ func NewRunnerCmd() *cobra.Command {
 
 	startCmd.Flags().StringVar(&apiHost, "api-host", "0.0.0.0", "Trento web server API host")
 	startCmd.Flags().IntVar(&apiPort, "api-port", 8080, "Trento web server API port")
+	startCmd.Flags().StringVar(&apiKey, "api-key", "", "Trento web server API key, it must have the write scope")
 	startCmd.Flags().IntVarP(&interval, "interval", "i", 5, "Interval in minutes to run the checks")
 	startCmd.Flags().StringVar(&ansibleFolder, "ansible-folder", "/tmp/trento", "Folder where the ansible file structure will be created")
 
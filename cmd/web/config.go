@@ -1,13 +1,23 @@
 package web
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	dbCmd "github.com/trento-project/trento/cmd/db"
+	"github.com/trento-project/trento/internal/db"
 	"github.com/trento-project/trento/internal/grafana"
+	"github.com/trento-project/trento/internal/proxy"
 	"github.com/trento-project/trento/web"
+	"github.com/trento-project/trento/web/chaos"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+	"github.com/trento-project/trento/web/supportbundle"
 )
 
 func LoadConfig() (*web.Config, error) {
@@ -16,6 +26,111 @@ func LoadConfig() (*web.Config, error) {
 	key := viper.GetString("key")
 	ca := viper.GetString("ca")
 
+	chaosConfig := &chaos.Config{
+		DBErrorRate:              viper.GetFloat64("chaos-db-error-rate"),
+		ChecksResultsTimeoutRate: viper.GetFloat64("chaos-checks-results-timeout-rate"),
+		ChecksResultsTimeout:     viper.GetDuration("chaos-checks-results-timeout"),
+		ProjectionDelay:          viper.GetDuration("chaos-projection-delay"),
+	}
+
+	for name, rate := range map[string]float64{
+		"chaos-db-error-rate":               chaosConfig.DBErrorRate,
+		"chaos-checks-results-timeout-rate": chaosConfig.ChecksResultsTimeoutRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+
+	rateLimitConfig := &web.RateLimitConfig{
+		CollectorRate:  viper.GetFloat64("collector-rate-limit"),
+		CollectorBurst: viper.GetInt("collector-rate-burst"),
+		APIRate:        viper.GetFloat64("api-rate-limit"),
+		APIBurst:       viper.GetInt("api-rate-burst"),
+	}
+
+	if rateLimitConfig.CollectorRate < 0 || rateLimitConfig.APIRate < 0 {
+		return nil, fmt.Errorf("the rate limits cannot be negative")
+	}
+
+	collectorMaxBodySize := viper.GetInt64("collector-max-body-size")
+	if collectorMaxBodySize <= 0 {
+		return nil, fmt.Errorf("the collector max body size must be positive")
+	}
+
+	ingestionQuota := services.IngestionQuota{
+		DailyEvents: viper.GetInt64("agent-daily-events-quota"),
+		DailyBytes:  viper.GetInt64("agent-daily-bytes-quota"),
+	}
+	if ingestionQuota.DailyEvents < 0 || ingestionQuota.DailyBytes < 0 {
+		return nil, fmt.Errorf("the agent daily quotas cannot be negative")
+	}
+
+	projectionRetryPolicy := datapipeline.RetryPolicy{
+		Attempts:   viper.GetInt("projection-retry-attempts"),
+		Backoff:    viper.GetDuration("projection-retry-backoff"),
+		MaxBackoff: viper.GetDuration("projection-retry-max-backoff"),
+	}
+	if projectionRetryPolicy.Attempts < 0 || projectionRetryPolicy.Backoff < 0 || projectionRetryPolicy.MaxBackoff < 0 {
+		return nil, fmt.Errorf("the projection retry attempts and backoff cannot be negative")
+	}
+
+	collectorAllowlist := viper.GetStringSlice("collector-allowlist")
+	if _, err := web.ParseCIDRAllowlist(collectorAllowlist); err != nil {
+		return nil, err
+	}
+
+	proxyConfig := &proxy.Config{
+		URL:      viper.GetString("proxy-url"),
+		NoProxy:  viper.GetString("no-proxy"),
+		Username: viper.GetString("proxy-user"),
+		Password: viper.GetString("proxy-password"),
+		Overrides: map[string]string{
+			proxy.Telemetry:  viper.GetString("telemetry-proxy-url"),
+			proxy.Grafana:    viper.GetString("grafana-proxy-url"),
+			proxy.Prometheus: viper.GetString("prometheus-proxy-url"),
+		},
+	}
+
+	if err := proxyConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	entitlementsEnforcement := viper.GetString("entitlements-enforcement")
+	if !models.IsValidEntitlementsEnforcement(entitlementsEnforcement) {
+		return nil, fmt.Errorf("invalid entitlements enforcement %s, it must be soft or hard", entitlementsEnforcement)
+	}
+
+	enableJWT := viper.GetBool("enable-jwt")
+	jwtSecret := viper.GetString("jwt-secret")
+	enrollmentToken := viper.GetString("enrollment-token")
+	organizationEnrollmentTokens := viper.GetStringMapString("organization-enrollment-tokens")
+
+	if enableJWT {
+		if enablemTLS {
+			return nil, fmt.Errorf("mTLS and JWT authentication cannot be enabled at the same time")
+		}
+		if jwtSecret == "" || (enrollmentToken == "" && len(organizationEnrollmentTokens) == 0) {
+			return nil, fmt.Errorf("you must provide a JWT secret and an enrollment token to enable JWT authentication")
+		}
+	}
+
+	credentialsKey, err := loadCredentialsKey()
+	if err != nil {
+		return nil, err
+	}
+
+	healthStrategy, err := loadHealthStrategy()
+	if err != nil {
+		return nil, err
+	}
+
+	staleHostWarningThreshold := viper.GetDuration("stale-host-warning-threshold")
+	staleHostCriticalThreshold := viper.GetDuration("stale-host-critical-threshold")
+	if staleHostWarningThreshold <= 0 || staleHostCriticalThreshold <= staleHostWarningThreshold {
+		return nil, fmt.Errorf("the stale host warning threshold must be positive, and lower than the critical one")
+	}
+
 	if enablemTLS {
 		var err error
 
@@ -34,20 +149,147 @@ func LoadConfig() (*web.Config, error) {
 	}
 
 	return &web.Config{
-		Host:          viper.GetString("host"),
-		Port:          viper.GetInt("port"),
-		CollectorPort: viper.GetInt("collector-port"),
-		EnablemTLS:    enablemTLS,
-		Cert:          cert,
-		Key:           key,
-		CA:            ca,
-		DBConfig:      dbCmd.LoadConfig(),
+		Host:                         viper.GetString("host"),
+		Port:                         viper.GetInt("port"),
+		CollectorPort:                viper.GetInt("collector-port"),
+		EnablemTLS:                   enablemTLS,
+		Cert:                         cert,
+		Key:                          key,
+		CA:                           ca,
+		CRL:                          viper.GetString("crl"),
+		MTLSVerifyAgentID:            viper.GetBool("mtls-verify-agent-id"),
+		EnableJWT:                    enableJWT,
+		JWTSecret:                    jwtSecret,
+		JWTTTL:                       viper.GetDuration("jwt-ttl"),
+		EnrollmentToken:              enrollmentToken,
+		OrganizationEnrollmentTokens: organizationEnrollmentTokens,
+		RequireAgentApproval:         viper.GetBool("require-agent-approval"),
+		RejectUnknownAgents:          !viper.GetBool("agent-auto-registration"),
+		SessionConfig: &web.SessionConfig{
+			Secrets:       viper.GetStringSlice("session-secrets"),
+			RedisAddress:  viper.GetString("session-redis-address"),
+			RedisPassword: viper.GetString("session-redis-password"),
+		},
+		DBConfig: dbCmd.LoadConfig(),
 		GrafanaConfig: &grafana.Config{
 			PublicURL: viper.GetString("grafana-public-url"),
 			ApiURL:    viper.GetString("grafana-api-url"),
 			User:      viper.GetString("grafana-user"),
 			Password:  viper.GetString("grafana-password"),
 		},
-		PrometheusURL: viper.GetString("prometheus-url"),
+		PrometheusURL:              viper.GetString("prometheus-url"),
+		DBMaintenanceAutoVacuum:    viper.GetBool("db-maintenance-auto-vacuum"),
+		ChaosConfig:                chaosConfig,
+		DevMode:                    viper.GetBool("dev-mode"),
+		DevWebDir:                  viper.GetString("dev-web-dir"),
+		TemplatesOverrideDir:       viper.GetString("templates-override-dir"),
+		AdminUser:                  viper.GetString("admin-user"),
+		AdminPassword:              viper.GetString("admin-password"),
+		EphemeralHostsTag:          viper.GetString("ephemeral-hosts-tag"),
+		EphemeralHostsTTL:          viper.GetDuration("ephemeral-hosts-ttl"),
+		StaleSAPSystemsTTL:         viper.GetDuration("stale-sap-systems-ttl"),
+		HeartbeatPeriodsRetention:  viper.GetDuration("heartbeat-periods-retention"),
+		EventsRetention:            viper.GetDuration("events-retention"),
+		CollectorQueueThreshold:    viper.GetInt("collector-queue-threshold"),
+		CollectorMaxBodySize:       collectorMaxBodySize,
+		IngestionQuota:             ingestionQuota,
+		ProjectionRetryPolicy:      projectionRetryPolicy,
+		StaleHostWarningThreshold:  staleHostWarningThreshold,
+		StaleHostCriticalThreshold: staleHostCriticalThreshold,
+		RateLimitConfig:            rateLimitConfig,
+		CollectorAllowlist:         collectorAllowlist,
+		ProxyConfig:                proxyConfig,
+		LicenseFile:                viper.GetString("license-file"),
+		EntitlementsEnforcement:    entitlementsEnforcement,
+		ContentSecurityPolicy:      viper.GetString("content-security-policy"),
+		HSTSMaxAge:                 viper.GetDuration("hsts-max-age"),
+		LoginMaxFailures:           viper.GetInt("login-max-failures"),
+		LoginBackoff:               viper.GetDuration("login-backoff"),
+		LoginLockoutDuration:       viper.GetDuration("login-lockout-duration"),
+		CredentialsKey:             credentialsKey,
+		RequirePayloadSignature:    viper.GetBool("require-payload-signature"),
+		CollectorSpoolDir:          viper.GetString("collector-spool-dir"),
+		CollectorGRPCPort:          viper.GetInt("collector-grpc-port"),
+		CollectorNatsURL:           viper.GetString("collector-nats-url"),
+		CollectorNatsSubject:       viper.GetString("collector-nats-subject"),
+		CollectorNatsConfig: &web.CollectorNatsConfig{
+			CredsFile:    viper.GetString("collector-nats-creds"),
+			NkeySeedFile: viper.GetString("collector-nats-nkey-seed"),
+			CA:           viper.GetString("collector-nats-ca"),
+			Cert:         viper.GetString("collector-nats-cert"),
+			Key:          viper.GetString("collector-nats-key"),
+		},
+		HealthStrategy: healthStrategy,
 	}, nil
 }
+
+func loadHealthStrategy() (services.HealthStrategy, error) {
+	switch strategy := viper.GetString("health-strategy"); strategy {
+	case services.HealthStrategyDefault:
+		return services.DefaultHealthStrategy{}, nil
+	case services.HealthStrategyWeighted:
+		weighted := services.WeightedHealthStrategy{
+			CriticalWeight:    viper.GetInt("health-critical-weight"),
+			WarningWeight:     viper.GetInt("health-warning-weight"),
+			CriticalThreshold: viper.GetInt("health-critical-threshold"),
+			WarningThreshold:  viper.GetInt("health-warning-threshold"),
+		}
+
+		return weighted, weighted.Validate()
+	default:
+		return nil, fmt.Errorf("invalid health strategy %s, it must be default or weighted", strategy)
+	}
+}
+
+// loadCredentialsKey reads the key encrypting the stored credentials from Vault, a file or the configuration,
+// returning nil if none is given
+func loadCredentialsKey() ([]byte, error) {
+	encoded := viper.GetString("credentials-key")
+
+	var err error
+	switch {
+	case viper.GetString("credentials-key-vault-path") != "":
+		encoded, err = db.ReadVaultSecret(context.Background(), &db.VaultConfig{
+			Address: viper.GetString("vault-address"),
+			Token:   viper.GetString("vault-token"),
+			Path:    viper.GetString("credentials-key-vault-path"),
+			Key:     viper.GetString("credentials-key-vault-key"),
+		})
+	case viper.GetString("credentials-key-file") != "":
+		encoded, err = db.ReadSecretFile(viper.GetString("credentials-key-file"))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the credentials key")
+	}
+
+	if encoded == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != services.CredentialsKeySize {
+		return nil, fmt.Errorf("the credentials key must be %d bytes, base64 encoded", services.CredentialsKeySize)
+	}
+
+	return key, nil
+}
+
+func LoadSupportBundleOptions() supportbundle.Options {
+	options := supportbundle.Options{
+		Settings: viper.AllSettings(),
+		LogFile:  viper.GetString("log-file"),
+		LogUnit:  viper.GetString("log-unit"),
+		LogLines: viper.GetInt("log-lines"),
+	}
+
+	anonymizerConfig := supportbundle.AnonymizerConfig{
+		Hostnames:   viper.GetBool("anonymize-hostnames"),
+		IPAddresses: viper.GetBool("anonymize-ip-addresses"),
+		Terms:       viper.GetStringSlice("anonymize-terms"),
+	}
+	if anonymizerConfig.Hostnames || anonymizerConfig.IPAddresses || len(anonymizerConfig.Terms) > 0 {
+		options.Anonymizer = supportbundle.NewAnonymizer(anonymizerConfig)
+	}
+
+	return options
+}
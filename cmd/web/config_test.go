@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/internal/db"
 	"github.com/trento-project/trento/internal/grafana"
+	"github.com/trento-project/trento/internal/proxy"
 	"github.com/trento-project/trento/web"
+	"github.com/trento-project/trento/web/chaos"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
 )
 
 type WebCmdTestSuite struct {
@@ -26,7 +31,8 @@ func (suite *WebCmdTestSuite) SetupTest() {
 
 	cmd := NewWebCmd()
 
-	cmd.Commands()[0].Run = func(cmd *cobra.Command, args []string) {
+	serveCmd, _, _ := cmd.Find([]string{"serve"})
+	serveCmd.Run = func(cmd *cobra.Command, args []string) {
 		// do nothing
 	}
 
@@ -44,19 +50,41 @@ func (suite *WebCmdTestSuite) TearDownTest() {
 	suite.cmd.Execute()
 
 	expectedConfig := &web.Config{
-		Host:          "some-host",
-		Port:          1337,
-		CollectorPort: 1338,
-		EnablemTLS:    true,
-		Cert:          "some-cert",
-		Key:           "some-key",
-		CA:            "some-ca",
+		Host:                         "some-host",
+		Port:                         1337,
+		CollectorPort:                1338,
+		EnablemTLS:                   true,
+		Cert:                         "some-cert",
+		Key:                          "some-key",
+		CA:                           "some-ca",
+		CRL:                          "some-crl",
+		MTLSVerifyAgentID:            false,
+		JWTSecret:                    "some-jwt-secret",
+		JWTTTL:                       12 * time.Hour,
+		EnrollmentToken:              "some-enrollment-token",
+		OrganizationEnrollmentTokens: map[string]string{"acme": "acme-enrollment-token"},
+		RequireAgentApproval:         true,
+		RejectUnknownAgents:          true,
+		SessionConfig: &web.SessionConfig{
+			Secrets:       []string{"new-secret", "old-secret"},
+			RedisAddress:  "redis-host:6379",
+			RedisPassword: "redis-password",
+		},
 		DBConfig: &db.Config{
-			Host:     "some-db-host",
-			Port:     6543,
-			User:     "postgres",
-			Password: "password",
-			DBName:   "trento",
+			Host:                    "some-db-host",
+			Port:                    6543,
+			User:                    "postgres",
+			Password:                "password",
+			DBName:                  "trento",
+			Schema:                  "trento_schema",
+			PasswordFile:            "/run/secrets/db-password",
+			PasswordRefreshInterval: 5 * time.Minute,
+			Vault: &db.VaultConfig{
+				Address: "https://vault:8200",
+				Token:   "some-vault-token",
+				Path:    "secret/data/trento",
+				Key:     "db-password",
+			},
 		},
 		GrafanaConfig: &grafana.Config{
 			PublicURL: "http://grafana:3000",
@@ -64,7 +92,74 @@ func (suite *WebCmdTestSuite) TearDownTest() {
 			User:      "adminuser",
 			Password:  "password",
 		},
-		PrometheusURL: "http://prometheus-host:9090",
+		PrometheusURL:           "http://prometheus-host:9090",
+		DBMaintenanceAutoVacuum: true,
+		ChaosConfig: &chaos.Config{
+			DBErrorRate:              0.1,
+			ChecksResultsTimeoutRate: 0.5,
+			ChecksResultsTimeout:     10 * time.Second,
+			ProjectionDelay:          2 * time.Second,
+		},
+		DevMode:                    true,
+		DevWebDir:                  "/src/trento/web",
+		TemplatesOverrideDir:       "/etc/trento/templates",
+		AdminUser:                  "root",
+		AdminPassword:              "secret",
+		EphemeralHostsTag:          "autoscaled",
+		EphemeralHostsTTL:          10 * time.Minute,
+		StaleSAPSystemsTTL:         7 * 24 * time.Hour,
+		HeartbeatPeriodsRetention:  14 * 24 * time.Hour,
+		EventsRetention:            60 * 24 * time.Hour,
+		CollectorQueueThreshold:    500,
+		CollectorMaxBodySize:       8 << 20,
+		IngestionQuota:             services.IngestionQuota{DailyEvents: 10000, DailyBytes: 1 << 30},
+		ProjectionRetryPolicy:      datapipeline.RetryPolicy{Attempts: 5, Backoff: 200 * time.Millisecond, MaxBackoff: 10 * time.Second},
+		StaleHostWarningThreshold:  30 * time.Second,
+		StaleHostCriticalThreshold: 5 * time.Minute,
+		RateLimitConfig: &web.RateLimitConfig{
+			CollectorRate:  2.5,
+			CollectorBurst: 5,
+			APIRate:        1,
+			APIBurst:       3,
+		},
+		CollectorAllowlist: []string{"10.0.0.0/16", "2001:db8::/32"},
+		ProxyConfig: &proxy.Config{
+			URL:      "http://proxy:3128",
+			NoProxy:  "localhost,.example.com",
+			Username: "proxyuser",
+			Password: "proxypassword",
+			Overrides: map[string]string{
+				proxy.Telemetry:  "http://telemetry-proxy:3128",
+				proxy.Grafana:    "direct",
+				proxy.Prometheus: "",
+			},
+		},
+		LicenseFile:             "/etc/trento/license.json",
+		EntitlementsEnforcement: "hard",
+		ContentSecurityPolicy:   "default-src 'self'",
+		HSTSMaxAge:              time.Hour,
+		LoginMaxFailures:        5,
+		LoginBackoff:            2 * time.Second,
+		LoginLockoutDuration:    time.Hour,
+		CredentialsKey:          []byte("0123456789abcdef0123456789abcdef"),
+		RequirePayloadSignature: true,
+		CollectorSpoolDir:       "/var/lib/trento/spool",
+		CollectorGRPCPort:       8082,
+		CollectorNatsURL:        "nats://nats:4222",
+		CollectorNatsSubject:    "agents.collect",
+		CollectorNatsConfig: &web.CollectorNatsConfig{
+			CredsFile:    "/etc/trento/nats.creds",
+			NkeySeedFile: "/etc/trento/nats.nk",
+			CA:           "/etc/trento/nats-ca.pem",
+			Cert:         "/etc/trento/nats-cert.pem",
+			Key:          "/etc/trento/nats-key.pem",
+		},
+		HealthStrategy: services.WeightedHealthStrategy{
+			CriticalWeight:    5,
+			WarningWeight:     2,
+			CriticalThreshold: 5,
+			WarningThreshold:  2,
+		},
 	}
 	config, err := LoadConfig()
 	suite.NoError(err)
@@ -82,16 +177,92 @@ func (suite *WebCmdTestSuite) TestConfigFromFlags() {
 		"--cert=some-cert",
 		"--key=some-key",
 		"--ca=some-ca",
+		"--crl=some-crl",
+		"--mtls-verify-agent-id=false",
+		"--jwt-secret=some-jwt-secret",
+		"--jwt-ttl=12h",
+		"--enrollment-token=some-enrollment-token",
+		"--organization-enrollment-tokens=acme=acme-enrollment-token",
+		"--require-agent-approval",
+		"--agent-auto-registration=false",
+		"--require-payload-signature",
+		"--collector-spool-dir=/var/lib/trento/spool",
+		"--collector-grpc-port=8082",
+		"--collector-nats-url=nats://nats:4222",
+		"--collector-nats-subject=agents.collect",
+		"--collector-nats-creds=/etc/trento/nats.creds",
+		"--collector-nats-nkey-seed=/etc/trento/nats.nk",
+		"--collector-nats-ca=/etc/trento/nats-ca.pem",
+		"--collector-nats-cert=/etc/trento/nats-cert.pem",
+		"--collector-nats-key=/etc/trento/nats-key.pem",
+		"--health-strategy=weighted",
+		"--health-critical-weight=5",
+		"--health-warning-weight=2",
+		"--health-critical-threshold=5",
+		"--health-warning-threshold=2",
+		"--session-secrets=new-secret,old-secret",
+		"--session-redis-address=redis-host:6379",
+		"--session-redis-password=redis-password",
 		"--db-host=some-db-host",
 		"--db-port=6543",
 		"--db-user=postgres",
 		"--db-password=password",
 		"--db-name=trento",
+		"--db-schema=trento_schema",
+		"--db-password-file=/run/secrets/db-password",
+		"--db-password-refresh-interval=5m",
+		"--db-password-vault-path=secret/data/trento",
+		"--db-password-vault-key=db-password",
+		"--vault-address=https://vault:8200",
+		"--vault-token=some-vault-token",
 		"--grafana-api-url=http://grafana:3000",
 		"--grafana-public-url=http://grafana:3000",
 		"--grafana-user=adminuser",
 		"--grafana-password=password",
 		"--prometheus-url=http://prometheus-host:9090",
+		"--db-maintenance-auto-vacuum",
+		"--chaos-db-error-rate=0.1",
+		"--chaos-checks-results-timeout-rate=0.5",
+		"--chaos-checks-results-timeout=10s",
+		"--chaos-projection-delay=2s",
+		"--dev-mode",
+		"--dev-web-dir=/src/trento/web",
+		"--templates-override-dir=/etc/trento/templates",
+		"--admin-user=root",
+		"--admin-password=secret",
+		"--ephemeral-hosts-tag=autoscaled",
+		"--ephemeral-hosts-ttl=10m",
+		"--stale-sap-systems-ttl=168h",
+		"--heartbeat-periods-retention=336h",
+		"--events-retention=1440h",
+		"--collector-queue-threshold=500",
+		"--collector-max-body-size=8388608",
+		"--agent-daily-events-quota=10000",
+		"--agent-daily-bytes-quota=1073741824",
+		"--projection-retry-attempts=5",
+		"--projection-retry-backoff=200ms",
+		"--projection-retry-max-backoff=10s",
+		"--stale-host-warning-threshold=30s",
+		"--stale-host-critical-threshold=5m",
+		"--collector-rate-limit=2.5",
+		"--collector-rate-burst=5",
+		"--api-rate-limit=1",
+		"--api-rate-burst=3",
+		"--collector-allowlist=10.0.0.0/16,2001:db8::/32",
+		"--proxy-url=http://proxy:3128",
+		"--no-proxy=localhost,.example.com",
+		"--proxy-user=proxyuser",
+		"--proxy-password=proxypassword",
+		"--telemetry-proxy-url=http://telemetry-proxy:3128",
+		"--grafana-proxy-url=direct",
+		"--license-file=/etc/trento/license.json",
+		"--entitlements-enforcement=hard",
+		"--content-security-policy=default-src 'self'",
+		"--hsts-max-age=1h",
+		"--login-max-failures=5",
+		"--login-backoff=2s",
+		"--login-lockout-duration=1h",
+		"--credentials-key=MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
 	})
 }
 
@@ -103,16 +274,92 @@ func (suite *WebCmdTestSuite) TestConfigFromEnv() {
 	os.Setenv("TRENTO_CERT", "some-cert")
 	os.Setenv("TRENTO_KEY", "some-key")
 	os.Setenv("TRENTO_CA", "some-ca")
+	os.Setenv("TRENTO_CRL", "some-crl")
+	os.Setenv("TRENTO_MTLS_VERIFY_AGENT_ID", "false")
+	os.Setenv("TRENTO_JWT_SECRET", "some-jwt-secret")
+	os.Setenv("TRENTO_JWT_TTL", "12h")
+	os.Setenv("TRENTO_ENROLLMENT_TOKEN", "some-enrollment-token")
+	os.Setenv("TRENTO_ORGANIZATION_ENROLLMENT_TOKENS", `{"acme": "acme-enrollment-token"}`)
+	os.Setenv("TRENTO_REQUIRE_AGENT_APPROVAL", "true")
+	os.Setenv("TRENTO_AGENT_AUTO_REGISTRATION", "false")
+	os.Setenv("TRENTO_REQUIRE_PAYLOAD_SIGNATURE", "true")
+	os.Setenv("TRENTO_COLLECTOR_SPOOL_DIR", "/var/lib/trento/spool")
+	os.Setenv("TRENTO_COLLECTOR_GRPC_PORT", "8082")
+	os.Setenv("TRENTO_COLLECTOR_NATS_URL", "nats://nats:4222")
+	os.Setenv("TRENTO_COLLECTOR_NATS_SUBJECT", "agents.collect")
+	os.Setenv("TRENTO_COLLECTOR_NATS_CREDS", "/etc/trento/nats.creds")
+	os.Setenv("TRENTO_COLLECTOR_NATS_NKEY_SEED", "/etc/trento/nats.nk")
+	os.Setenv("TRENTO_COLLECTOR_NATS_CA", "/etc/trento/nats-ca.pem")
+	os.Setenv("TRENTO_COLLECTOR_NATS_CERT", "/etc/trento/nats-cert.pem")
+	os.Setenv("TRENTO_COLLECTOR_NATS_KEY", "/etc/trento/nats-key.pem")
+	os.Setenv("TRENTO_HEALTH_STRATEGY", "weighted")
+	os.Setenv("TRENTO_HEALTH_CRITICAL_WEIGHT", "5")
+	os.Setenv("TRENTO_HEALTH_WARNING_WEIGHT", "2")
+	os.Setenv("TRENTO_HEALTH_CRITICAL_THRESHOLD", "5")
+	os.Setenv("TRENTO_HEALTH_WARNING_THRESHOLD", "2")
+	os.Setenv("TRENTO_SESSION_SECRETS", "new-secret old-secret")
+	os.Setenv("TRENTO_SESSION_REDIS_ADDRESS", "redis-host:6379")
+	os.Setenv("TRENTO_SESSION_REDIS_PASSWORD", "redis-password")
 	os.Setenv("TRENTO_DB_HOST", "some-db-host")
 	os.Setenv("TRENTO_DB_PORT", "6543")
 	os.Setenv("TRENTO_DB_USER", "postgres")
 	os.Setenv("TRENTO_DB_PASSWORD", "password")
 	os.Setenv("TRENTO_DB_NAME", "trento")
+	os.Setenv("TRENTO_DB_SCHEMA", "trento_schema")
+	os.Setenv("TRENTO_DB_PASSWORD_FILE", "/run/secrets/db-password")
+	os.Setenv("TRENTO_DB_PASSWORD_REFRESH_INTERVAL", "5m")
+	os.Setenv("TRENTO_DB_PASSWORD_VAULT_PATH", "secret/data/trento")
+	os.Setenv("TRENTO_DB_PASSWORD_VAULT_KEY", "db-password")
+	os.Setenv("TRENTO_VAULT_ADDRESS", "https://vault:8200")
+	os.Setenv("TRENTO_VAULT_TOKEN", "some-vault-token")
 	os.Setenv("TRENTO_GRAFANA_PUBLIC_URL", "http://grafana:3000")
 	os.Setenv("TRENTO_GRAFANA_API_URL", "http://grafana:3000")
 	os.Setenv("TRENTO_GRAFANA_USER", "adminuser")
 	os.Setenv("TRENTO_GRAFANA_PASSWORD", "password")
 	os.Setenv("TRENTO_PROMETHEUS_URL", "http://prometheus-host:9090")
+	os.Setenv("TRENTO_DB_MAINTENANCE_AUTO_VACUUM", "true")
+	os.Setenv("TRENTO_CHAOS_DB_ERROR_RATE", "0.1")
+	os.Setenv("TRENTO_CHAOS_CHECKS_RESULTS_TIMEOUT_RATE", "0.5")
+	os.Setenv("TRENTO_CHAOS_CHECKS_RESULTS_TIMEOUT", "10s")
+	os.Setenv("TRENTO_CHAOS_PROJECTION_DELAY", "2s")
+	os.Setenv("TRENTO_DEV_MODE", "true")
+	os.Setenv("TRENTO_DEV_WEB_DIR", "/src/trento/web")
+	os.Setenv("TRENTO_TEMPLATES_OVERRIDE_DIR", "/etc/trento/templates")
+	os.Setenv("TRENTO_ADMIN_USER", "root")
+	os.Setenv("TRENTO_ADMIN_PASSWORD", "secret")
+	os.Setenv("TRENTO_EPHEMERAL_HOSTS_TAG", "autoscaled")
+	os.Setenv("TRENTO_EPHEMERAL_HOSTS_TTL", "10m")
+	os.Setenv("TRENTO_STALE_SAP_SYSTEMS_TTL", "168h")
+	os.Setenv("TRENTO_HEARTBEAT_PERIODS_RETENTION", "336h")
+	os.Setenv("TRENTO_EVENTS_RETENTION", "1440h")
+	os.Setenv("TRENTO_COLLECTOR_QUEUE_THRESHOLD", "500")
+	os.Setenv("TRENTO_COLLECTOR_MAX_BODY_SIZE", "8388608")
+	os.Setenv("TRENTO_AGENT_DAILY_EVENTS_QUOTA", "10000")
+	os.Setenv("TRENTO_AGENT_DAILY_BYTES_QUOTA", "1073741824")
+	os.Setenv("TRENTO_PROJECTION_RETRY_ATTEMPTS", "5")
+	os.Setenv("TRENTO_PROJECTION_RETRY_BACKOFF", "200ms")
+	os.Setenv("TRENTO_PROJECTION_RETRY_MAX_BACKOFF", "10s")
+	os.Setenv("TRENTO_STALE_HOST_WARNING_THRESHOLD", "30s")
+	os.Setenv("TRENTO_STALE_HOST_CRITICAL_THRESHOLD", "5m")
+	os.Setenv("TRENTO_COLLECTOR_RATE_LIMIT", "2.5")
+	os.Setenv("TRENTO_COLLECTOR_RATE_BURST", "5")
+	os.Setenv("TRENTO_API_RATE_LIMIT", "1")
+	os.Setenv("TRENTO_API_RATE_BURST", "3")
+	os.Setenv("TRENTO_COLLECTOR_ALLOWLIST", "10.0.0.0/16 2001:db8::/32")
+	os.Setenv("TRENTO_PROXY_URL", "http://proxy:3128")
+	os.Setenv("TRENTO_NO_PROXY", "localhost,.example.com")
+	os.Setenv("TRENTO_PROXY_USER", "proxyuser")
+	os.Setenv("TRENTO_PROXY_PASSWORD", "proxypassword")
+	os.Setenv("TRENTO_TELEMETRY_PROXY_URL", "http://telemetry-proxy:3128")
+	os.Setenv("TRENTO_GRAFANA_PROXY_URL", "direct")
+	os.Setenv("TRENTO_LICENSE_FILE", "/etc/trento/license.json")
+	os.Setenv("TRENTO_ENTITLEMENTS_ENFORCEMENT", "hard")
+	os.Setenv("TRENTO_CONTENT_SECURITY_POLICY", "default-src 'self'")
+	os.Setenv("TRENTO_HSTS_MAX_AGE", "1h")
+	os.Setenv("TRENTO_LOGIN_MAX_FAILURES", "5")
+	os.Setenv("TRENTO_LOGIN_BACKOFF", "2s")
+	os.Setenv("TRENTO_LOGIN_LOCKOUT_DURATION", "1h")
+	os.Setenv("TRENTO_CREDENTIALS_KEY", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
 }
 
 func (suite *WebCmdTestSuite) TestConfigFromFile() {
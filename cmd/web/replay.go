@@ -0,0 +1,45 @@
+package web
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	dbCmd "github.com/trento-project/trento/cmd/db"
+	"github.com/trento-project/trento/internal/db"
+	"github.com/trento-project/trento/web/datapipeline"
+)
+
+func addReplayCmd(webCmd *cobra.Command) {
+	var projectors []string
+
+	replayCmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Rebuilds the read models from the stored events, e.g. once their schema changed. Stop the web server first",
+		Long: "Clears the read models of the projectors and projects again all the stored events, " +
+			"without waiting for the agents to send their data again. " +
+			"The history projected from the events pruned already, like the host utilization, is lost",
+		Run: replay,
+	}
+
+	replayCmd.Flags().StringSliceVar(&projectors, "projector", nil, "Comma-separated projectors whose read models are rebuilt, e.g. hosts,clusters. All of them if not provided")
+
+	webCmd.AddCommand(replayCmd)
+}
+
+func replay(*cobra.Command, []string) {
+	ctx := context.Background()
+
+	conn, err := db.InitDB(ctx, dbCmd.LoadConfig())
+	if err != nil {
+		log.Fatal("Failed to connect to the database: ", err)
+	}
+
+	result, err := datapipeline.ReplayEvents(ctx, conn, datapipeline.InitProjectorsRegistry(conn), viper.GetStringSlice("projector"))
+	if err != nil {
+		log.Fatal("Failed to replay the events: ", err)
+	}
+
+	log.Infof("Replayed %d events through the projectors %v, %d projections failed and recorded as dead letters", result.Events, result.Projectors, result.Failed)
+}
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	dbCmd "github.com/trento-project/trento/cmd/db"
+	"github.com/trento-project/trento/internal/db"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
+	"github.com/trento-project/trento/web/supportbundle"
+)
+
+func addSupportBundleCmd(webCmd *cobra.Command) {
+	var output string
+	var logFile string
+	var logUnit string
+	var logLines int
+	var anonymizeHostnames bool
+	var anonymizeIPAddresses bool
+	var anonymizeTerms []string
+
+	supportBundleCmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Collects the configuration, recent logs and database status of the web application into an archive to attach to support cases",
+		Run:   supportBundle,
+	}
+
+	supportBundleCmd.Flags().StringVarP(&output, "output", "o", "", "Path of the archive to create (default \"trento-support-bundle-<timestamp>.tar.gz\")")
+	supportBundleCmd.Flags().StringVar(&logFile, "log-file", "", "File to read the recent logs from, if not provided they are read from the journal")
+	supportBundleCmd.Flags().StringVar(&logUnit, "log-unit", "trento-web", "Systemd unit to read the recent logs of")
+	supportBundleCmd.Flags().IntVar(&logLines, "log-lines", 1000, "Number of recent log lines to include")
+	supportBundleCmd.Flags().BoolVar(&anonymizeHostnames, "anonymize-hostnames", true, "Replace the hostnames of the discovered hosts with pseudonyms")
+	supportBundleCmd.Flags().BoolVar(&anonymizeIPAddresses, "anonymize-ip-addresses", true, "Replace the IP addresses with pseudonyms")
+	supportBundleCmd.Flags().StringSliceVar(&anonymizeTerms, "anonymize-terms", nil, "Additional comma-separated terms to replace with pseudonyms, like domains, cluster names or SIDs")
+
+	webCmd.AddCommand(supportBundleCmd)
+}
+
+func supportBundle(*cobra.Command, []string) {
+	output := viper.GetString("output")
+	if output == "" {
+		output = fmt.Sprintf("trento-support-bundle-%s.tar.gz", time.Now().Format("20060102150405"))
+	}
+
+	conn, err := db.InitDB(context.Background(), dbCmd.LoadConfig())
+	if err != nil {
+		log.Fatal("Failed to connect to the database: ", err)
+	}
+
+	collector := supportbundle.NewCollector(
+		// The events are never stored, hence the channel is not needed
+		services.NewCollectorService(conn, make(chan *datapipeline.DataCollectedEvent), services.IngestionQuota{}),
+		services.NewDBMaintenanceService(conn),
+		services.NewHostsRepository(conn),
+		LoadSupportBundleOptions(),
+	)
+
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Fatal("Failed to create the support bundle: ", err)
+	}
+	defer file.Close()
+
+	if err := collector.Collect(file); err != nil {
+		log.Fatal("Failed to collect the support bundle: ", err)
+	}
+
+	log.Infof("Support bundle written to %s", output)
+}
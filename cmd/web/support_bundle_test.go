@@ -0,0 +1,54 @@
+package web
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func executeSupportBundleCmd(t *testing.T, args ...string) {
+	os.Clearenv()
+	os.Setenv("HOME", t.TempDir())
+
+	cmd := NewWebCmd()
+	for _, c := range cmd.Commands() {
+		if c.Name() == "support-bundle" {
+			c.Run = func(cmd *cobra.Command, args []string) {
+				// do nothing
+			}
+		}
+	}
+
+	cmd.SetArgs(append([]string{"support-bundle"}, args...))
+	assert.NoError(t, cmd.Execute())
+}
+
+func TestLoadSupportBundleOptions(t *testing.T) {
+	executeSupportBundleCmd(t,
+		"--db-password=secret",
+		"--log-file=/var/log/trento-web.log",
+		"--log-lines=50",
+		"--anonymize-hostnames=false",
+		"--anonymize-terms=acme,hana_cluster",
+	)
+
+	options := LoadSupportBundleOptions()
+
+	assert.Equal(t, "/var/log/trento-web.log", options.LogFile)
+	assert.Equal(t, "trento-web", options.LogUnit)
+	assert.Equal(t, 50, options.LogLines)
+	assert.Equal(t, "secret", options.Settings["db-password"])
+	assert.NotNil(t, options.Anonymizer)
+	assert.Equal(t, "vmhana01 ip-1 term-1", options.Anonymizer.Anonymize("vmhana01 10.1.1.4 acme"))
+}
+
+func TestLoadSupportBundleOptionsNoAnonymization(t *testing.T) {
+	executeSupportBundleCmd(t,
+		"--anonymize-hostnames=false",
+		"--anonymize-ip-addresses=false",
+	)
+
+	assert.Nil(t, LoadSupportBundleOptions().Anonymizer)
+}
@@ -2,9 +2,11 @@ package web
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -13,6 +15,8 @@ import (
 	"github.com/trento-project/trento/cmd/db"
 	"github.com/trento-project/trento/internal"
 	"github.com/trento-project/trento/web"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
 )
 
 func NewWebCmd() *cobra.Command {
@@ -32,6 +36,8 @@ func NewWebCmd() *cobra.Command {
 
 	db.AddDBFlags(webCmd)
 	addServeCmd(webCmd)
+	addSupportBundleCmd(webCmd)
+	addReplayCmd(webCmd)
 
 	return webCmd
 }
@@ -45,6 +51,30 @@ func addServeCmd(webCmd *cobra.Command) {
 	var cert string
 	var key string
 	var ca string
+	var crl string
+	var mTLSVerifyAgentID bool
+
+	var enableJWT bool
+	var jwtSecret string
+	var jwtTTL time.Duration
+	var enrollmentToken string
+	var organizationEnrollmentTokens map[string]string
+	var requireAgentApproval bool
+	var agentAutoRegistration bool
+	var requirePayloadSignature bool
+	var collectorSpoolDir string
+	var collectorGRPCPort int
+	var collectorNatsURL string
+	var collectorNatsSubject string
+	var collectorNatsCreds string
+	var collectorNatsNkeySeed string
+	var collectorNatsCA string
+	var collectorNatsCert string
+	var collectorNatsKey string
+
+	var sessionSecrets []string
+	var sessionRedisAddress string
+	var sessionRedisPassword string
 
 	var grafanaPublicURL string
 	var grafanaApiURL string
@@ -53,13 +83,78 @@ func addServeCmd(webCmd *cobra.Command) {
 
 	var prometheusURL string
 
+	var dbMaintenanceAutoVacuum bool
+
+	var devMode bool
+	var devWebDir string
+	var templatesOverrideDir string
+
+	var adminUser string
+	var adminPassword string
+
+	var ephemeralHostsTag string
+	var ephemeralHostsTTL time.Duration
+	var staleSAPSystemsTTL time.Duration
+	var heartbeatPeriodsRetention time.Duration
+	var eventsRetention time.Duration
+	var collectorQueueThreshold int
+	var collectorMaxBodySize int64
+	var agentDailyEventsQuota int64
+	var agentDailyBytesQuota int64
+	var projectionRetryAttempts int
+	var projectionRetryBackoff time.Duration
+	var projectionRetryMaxBackoff time.Duration
+	var staleHostWarningThreshold time.Duration
+	var staleHostCriticalThreshold time.Duration
+
+	var licenseFile string
+	var entitlementsEnforcement string
+
+	var contentSecurityPolicy string
+	var hstsMaxAge time.Duration
+
+	var loginMaxFailures int
+	var loginBackoff time.Duration
+	var loginLockoutDuration time.Duration
+
+	var credentialsKey string
+	var credentialsKeyFile string
+	var credentialsKeyVaultPath string
+	var credentialsKeyVaultKey string
+
+	var collectorAllowlist []string
+
+	var healthStrategy string
+	var healthCriticalWeight int
+	var healthWarningWeight int
+	var healthCriticalThreshold int
+	var healthWarningThreshold int
+
+	var proxyURL string
+	var noProxy string
+	var proxyUser string
+	var proxyPassword string
+	var telemetryProxyURL string
+	var grafanaProxyURL string
+	var prometheusProxyURL string
+
+	var collectorRateLimit float64
+	var collectorRateBurst int
+	var apiRateLimit float64
+	var apiRateBurst int
+
+	var chaosDBErrorRate float64
+	var chaosChecksResultsTimeoutRate float64
+	var chaosChecksResultsTimeout time.Duration
+	var chaosProjectionDelay time.Duration
+
 	serveCmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Starts the web application",
 		Run:   serve,
 	}
 
-	serveCmd.Flags().StringVar(&host, "host", "0.0.0.0", "The host to bind the HTTP service to")
+	serveCmd.Flags().StringVar(&host, "host", "0.0.0.0", "The host to bind the HTTP services to, an IPv4 or IPv6 address. Use :: to listen on both IPv4 and IPv6")
 	serveCmd.Flags().IntVarP(&port, "port", "p", 8080, "The port for the HTTP service to listen on")
 
 	serveCmd.Flags().IntVar(&collectorPort, "collector-port", 8081, "The port for the data collector service to listen on")
@@ -67,6 +162,21 @@ func addServeCmd(webCmd *cobra.Command) {
 	serveCmd.Flags().StringVar(&cert, "cert", "", "mTLS server certificate")
 	serveCmd.Flags().StringVar(&key, "key", "", "mTLS server key")
 	serveCmd.Flags().StringVar(&ca, "ca", "", "mTLS Certificate Authority")
+	serveCmd.Flags().StringVar(&crl, "crl", "", "mTLS Certificate Revocation List of the agent certificates, PEM or DER encoded and signed by the CA. It is refreshed when the file changes")
+	serveCmd.Flags().BoolVar(&mTLSVerifyAgentID, "mtls-verify-agent-id", true, "Reject the agent requests whose agent ID does not match the CN, or a DNS SAN, of their mTLS client certificate. Disable it if the agents share a certificate")
+
+	serveCmd.Flags().BoolVar(&enableJWT, "enable-jwt", false, "Enable JWT authentication between server and agents, an alternative to mTLS")
+	serveCmd.Flags().StringVar(&jwtSecret, "jwt-secret", "", "Secret the JWTs issued to the agents are signed with")
+	serveCmd.Flags().DurationVar(&jwtTTL, "jwt-ttl", 24*time.Hour, "Validity of the JWTs issued to the agents, they enroll again once expired")
+	serveCmd.Flags().StringVar(&enrollmentToken, "enrollment-token", "", "Token the agents present to enroll and get their JWT")
+	serveCmd.Flags().StringToStringVar(&organizationEnrollmentTokens, "organization-enrollment-tokens", nil, "Comma-separated organization=token pairs, the data of the agents enrolling with the token of an organization is visible to the users of the organization only")
+	serveCmd.Flags().BoolVar(&requireAgentApproval, "require-agent-approval", false, "Hold back the data of the new agents until an admin approves them. The agents of the hosts known already are approved")
+	serveCmd.Flags().BoolVar(&agentAutoRegistration, "agent-auto-registration", true, "Accept the data of the agents never seen before. If disabled, their data is refused until an admin approves them. The agents of the hosts known already are approved")
+	serveCmd.Flags().BoolVar(&requirePayloadSignature, "require-payload-signature", false, "Refuse the collected data not signed by the agents. Otherwise only the data of the agents with a signing secret must be signed")
+
+	serveCmd.Flags().StringSliceVar(&sessionSecrets, "session-secrets", nil, "Comma-separated secrets the user sessions are signed with. The first one signs the new sessions, the others are kept to rotate the secret without logging out the users")
+	serveCmd.Flags().StringVar(&sessionRedisAddress, "session-redis-address", "", "Address of the Redis server to store the user sessions in, shared by multiple instances of the server. The sessions are stored in cookies if empty")
+	serveCmd.Flags().StringVar(&sessionRedisPassword, "session-redis-password", "", "Password of the Redis server the user sessions are stored in")
 
 	serveCmd.Flags().StringVar(&grafanaPublicURL, "grafana-public-url", "", "Browsable Grafana URL, if not provided, the API url will be used. This is the base url for iframes embedding.")
 	serveCmd.Flags().StringVar(&grafanaApiURL, "grafana-api-url", "http://localhost:3000", "Grafana API URL")
@@ -75,6 +185,84 @@ func addServeCmd(webCmd *cobra.Command) {
 
 	serveCmd.Flags().StringVar(&prometheusURL, "prometheus-url", "http://localhost:9090", "Prometheus server URL")
 
+	serveCmd.Flags().BoolVar(&dbMaintenanceAutoVacuum, "db-maintenance-auto-vacuum", false, "Automatically vacuum and analyze the tables the database maintenance advisor reports as bloated")
+
+	serveCmd.Flags().BoolVar(&devMode, "dev-mode", false, "Serve templates and static assets from the sources on disk, reloading templates on every request. Meant for development only")
+	serveCmd.Flags().StringVar(&devWebDir, "dev-web-dir", "web", "Path to the web sources directory used in development mode")
+	serveCmd.Flags().StringVar(&templatesOverrideDir, "templates-override-dir", "", "Directory whose templates take precedence over the default ones, laid out like the web sources, e.g. templates/blocks/footer.html.tmpl")
+
+	serveCmd.Flags().StringVar(&adminUser, "admin-user", "admin", "Username of the user created at startup when there are no users yet")
+	serveCmd.Flags().StringVar(&adminPassword, "admin-password", "", "Password of the user created at startup when there are no users yet, no user is created if empty")
+
+	serveCmd.Flags().StringVar(&ephemeralHostsTag, "ephemeral-hosts-tag", "ephemeral", "Tag marking the hosts as ephemeral, like auto-scaled application servers, in addition to the agents started with the ephemeral flag")
+	serveCmd.Flags().DurationVar(&ephemeralHostsTTL, "ephemeral-hosts-ttl", 30*time.Minute, "Time after which the ephemeral hosts not sending heartbeats are removed, 0 to never remove them")
+	serveCmd.Flags().DurationVar(&staleSAPSystemsTTL, "stale-sap-systems-ttl", 0, "Time after which the SAP systems and databases whose hosts all stopped sending heartbeats are removed, 0 to never remove them")
+	serveCmd.Flags().DurationVar(&heartbeatPeriodsRetention, "heartbeat-periods-retention", 30*24*time.Hour, "Time the heartbeat periods are kept once rolled up in the availability aggregates, at least 7 days, 0 to never prune them")
+	serveCmd.Flags().DurationVar(&eventsRetention, "events-retention", 30*24*time.Hour, "Time the collected events are kept, but the latest one of every agent discovery, 0 to never prune them")
+
+	serveCmd.Flags().Float64Var(&collectorRateLimit, "collector-rate-limit", 10, "Requests per second allowed to every agent on the data collector service, 0 to disable the limit")
+	serveCmd.Flags().IntVar(&collectorRateBurst, "collector-rate-burst", 50, "Requests allowed to every agent in a burst above the collector rate limit")
+	serveCmd.Flags().IntVar(&collectorQueueThreshold, "collector-queue-threshold", 800, fmt.Sprintf("Events waiting to be projected, out of %d, beyond which the collected data is refused until the queue drains, 0 to disable the backpressure", datapipeline.ProjectorsQueueSize))
+	serveCmd.Flags().Int64Var(&collectorMaxBodySize, "collector-max-body-size", web.DefaultCollectorMaxBodySize, "Size in bytes the bodies of the collected data can have, once decompressed, the larger ones being refused")
+	serveCmd.Flags().Int64Var(&agentDailyEventsQuota, "agent-daily-events-quota", 0, "Events stored every day for each agent, the data being refused until the next day once exceeded, 0 to disable the quota")
+	serveCmd.Flags().Int64Var(&agentDailyBytesQuota, "agent-daily-bytes-quota", 0, "Payload bytes stored every day for each agent, the data being refused until the next day once exceeded, 0 to disable the quota")
+	serveCmd.Flags().IntVar(&projectionRetryAttempts, "projection-retry-attempts", datapipeline.DefaultRetryAttempts, "Retries of the projections failing, e.g. because of transient database errors, before recording them as dead letters, 0 to disable the retries")
+	serveCmd.Flags().DurationVar(&projectionRetryBackoff, "projection-retry-backoff", datapipeline.DefaultRetryBackoff, "Backoff before the first retry of a failing projection, doubled at every retry, with a random jitter")
+	serveCmd.Flags().DurationVar(&projectionRetryMaxBackoff, "projection-retry-max-backoff", datapipeline.DefaultRetryMaxBackoff, "Backoff the retries of a failing projection wait at most")
+	serveCmd.Flags().DurationVar(&staleHostWarningThreshold, "stale-host-warning-threshold", services.DefaultStaleHostWarningThreshold, "Time without heartbeats after which a host is marked as degraded")
+	serveCmd.Flags().DurationVar(&staleHostCriticalThreshold, "stale-host-critical-threshold", services.DefaultStaleHostCriticalThreshold, "Time without heartbeats after which a host is marked as critical, greater than the warning threshold")
+	serveCmd.Flags().Float64Var(&apiRateLimit, "api-rate-limit", 20, "Requests per second allowed to every user, API key or address on the API, 0 to disable the limit")
+	serveCmd.Flags().IntVar(&apiRateBurst, "api-rate-burst", 100, "Requests allowed to every API client in a burst above the API rate limit")
+
+	serveCmd.Flags().IntVar(&collectorGRPCPort, "collector-grpc-port", 0, "Port of the gRPC data collector service, alongside the HTTP one, the agents can stream their data to. It requires mTLS, disabled if 0")
+	serveCmd.Flags().StringVar(&collectorNatsURL, "collector-nats-url", "", "URL of the NATS server the agents can publish their data to, alongside the HTTP data collector service. Disabled if empty")
+	serveCmd.Flags().StringVar(&collectorNatsSubject, "collector-nats-subject", web.DefaultCollectorNatsSubject, "NATS subject the agents publish their data to")
+	serveCmd.Flags().StringVar(&collectorNatsCreds, "collector-nats-creds", "", "File of the user JWT and nkey seed the server authenticates to the NATS server with")
+	serveCmd.Flags().StringVar(&collectorNatsNkeySeed, "collector-nats-nkey-seed", "", "File of the nkey seed the server authenticates to the NATS server with")
+	serveCmd.Flags().StringVar(&collectorNatsCA, "collector-nats-ca", "", "CA certificate verifying the NATS server, the system ones are used if empty")
+	serveCmd.Flags().StringVar(&collectorNatsCert, "collector-nats-cert", "", "Client certificate the server authenticates to the NATS server with, over TLS")
+	serveCmd.Flags().StringVar(&collectorNatsKey, "collector-nats-key", "", "Key of the client certificate of the NATS server")
+	serveCmd.Flags().StringVar(&collectorSpoolDir, "collector-spool-dir", "", "Directory the collected data is spooled in while the database is migrated at startup, to be replayed once the server is started. The data collector service is not available meanwhile if empty")
+	serveCmd.Flags().StringSliceVar(&collectorAllowlist, "collector-allowlist", nil, "Comma-separated subnets, in CIDR notation, or addresses allowed to reach the data collector service, e.g. the agents subnets. All are allowed if empty")
+
+	serveCmd.Flags().StringVar(&proxyURL, "proxy-url", "", "URL of the proxy of the outbound HTTP requests, e.g. http://proxy:3128. The HTTP_PROXY and HTTPS_PROXY environment variables are honored if empty")
+	serveCmd.Flags().StringVar(&noProxy, "no-proxy", "", "Comma-separated hosts, domains and subnets reached without the proxy, the NO_PROXY environment variable is honored if empty")
+	serveCmd.Flags().StringVar(&proxyUser, "proxy-user", "", "User to authenticate to the proxy")
+	serveCmd.Flags().StringVar(&proxyPassword, "proxy-password", "", "Password to authenticate to the proxy")
+	serveCmd.Flags().StringVar(&telemetryProxyURL, "telemetry-proxy-url", "", "URL of the proxy of the requests to the telemetry service, in place of the default one, or direct to bypass it")
+	serveCmd.Flags().StringVar(&grafanaProxyURL, "grafana-proxy-url", "", "URL of the proxy of the requests to Grafana, in place of the default one, or direct to bypass it")
+	serveCmd.Flags().StringVar(&prometheusProxyURL, "prometheus-proxy-url", "", "URL of the proxy of the requests to Prometheus, in place of the default one, or direct to bypass it")
+
+	serveCmd.Flags().StringVar(&licenseFile, "license-file", "", "JSON file with the entitlements of the license: customer, expires_at, hosts_limit and premium_checks. Without it the premium checks follow the subscriptions of the hosts, with no limits")
+	serveCmd.Flags().StringVar(&entitlementsEnforcement, "entitlements-enforcement", "soft", "What happens once the hosts limit of the license is reached: soft only warns, hard also refuses the agents of new hosts")
+
+	serveCmd.Flags().StringVar(&contentSecurityPolicy, "content-security-policy", "", "Content-Security-Policy header of the web pages, replacing the default one allowing the embedded frontend assets and the Grafana panels")
+	serveCmd.Flags().DurationVar(&hstsMaxAge, "hsts-max-age", web.DefaultHSTSMaxAge, "Max age of the Strict-Transport-Security header, the time the browsers keep reaching the web service over HTTPS only, 0 to not send it")
+
+	serveCmd.Flags().IntVar(&loginMaxFailures, "login-max-failures", 10, "Failed logins in a row locking a user, or address, out, 0 to disable the login throttling")
+	serveCmd.Flags().DurationVar(&loginBackoff, "login-backoff", time.Second, "Time the logins are held back after the first failure, doubling at every following one")
+	serveCmd.Flags().DurationVar(&loginLockoutDuration, "login-lockout-duration", 15*time.Minute, "Time a user, or address, is locked out for, after which the failed logins are forgotten")
+
+	serveCmd.Flags().StringVar(&healthStrategy, "health-strategy", services.HealthStrategyDefault, "How the health is computed from the checks results and aggregated: default is as bad as the worst result, weighted scores the critical and warning results by their weight against thresholds")
+	serveCmd.Flags().IntVar(&healthCriticalWeight, "health-critical-weight", 10, "Score of a critical check result, or resource, with the weighted health strategy")
+	serveCmd.Flags().IntVar(&healthWarningWeight, "health-warning-weight", 1, "Score of a warning check result, or resource, with the weighted health strategy")
+	serveCmd.Flags().IntVar(&healthCriticalThreshold, "health-critical-threshold", 10, "Score from which the health is critical with the weighted health strategy")
+	serveCmd.Flags().IntVar(&healthWarningThreshold, "health-warning-threshold", 1, "Score from which the health is warning with the weighted health strategy")
+
+	serveCmd.Flags().StringVar(&credentialsKey, "credentials-key", "", "Base64 encoded 32 bytes key encrypting the connection settings of the checks stored in the database, stored in plaintext without it")
+	serveCmd.Flags().StringVar(&credentialsKeyFile, "credentials-key-file", "", "File the credentials key is read from instead, e.g. a mounted Kubernetes secret")
+	serveCmd.Flags().StringVar(&credentialsKeyVaultPath, "credentials-key-vault-path", "", "The path of the Vault secret the credentials key is read from instead, e.g. secret/data/trento")
+	serveCmd.Flags().StringVar(&credentialsKeyVaultKey, "credentials-key-vault-key", "key", "The key of the credentials key in the Vault secret")
+
+	// Fault injection is meant for resilience testing only, hence the flags are hidden
+	serveCmd.Flags().Float64Var(&chaosDBErrorRate, "chaos-db-error-rate", 0, "Probability, between 0 and 1, of a database operation to fail")
+	serveCmd.Flags().Float64Var(&chaosChecksResultsTimeoutRate, "chaos-checks-results-timeout-rate", 0, "Probability, between 0 and 1, of a checks results submission to time out")
+	serveCmd.Flags().DurationVar(&chaosChecksResultsTimeout, "chaos-checks-results-timeout", 30*time.Second, "Time to wait before failing a checks results submission")
+	serveCmd.Flags().DurationVar(&chaosProjectionDelay, "chaos-projection-delay", 0, "Time every projection is delayed by")
+	for _, flag := range []string{"chaos-db-error-rate", "chaos-checks-results-timeout-rate", "chaos-checks-results-timeout", "chaos-projection-delay"} {
+		_ = serveCmd.Flags().MarkHidden(flag)
+	}
+
 	webCmd.AddCommand(serveCmd)
 }
 
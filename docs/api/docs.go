@@ -1,17 +1,10 @@
-// Package api GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
+// Package api GENERATED BY SWAG; DO NOT EDIT
 // This file was generated by swaggo/swag
 package api
 
-import (
-	"bytes"
-	"encoding/json"
-	"strings"
-	"text/template"
+import "github.com/swaggo/swag"
 
-	"github.com/swaggo/swag"
-)
-
-var doc = `{
+const docTemplate = `{
     "schemes": {{ marshal .Schemes }},
     "swagger": "2.0",
     "info": {
@@ -31,94 +24,79 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/checks/catalog": {
+        "/agent-upgrades": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get the whole checks' catalog",
+                "summary": "Retrieve the latest upgrade requested to every agent, with its status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/web.JSONChecksGroup"
+                                "$ref": "#/definitions/models.AgentUpgrade"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
+                "description": "The upgrade is sent over the channel of the connected agents, the other ones get it once they connect",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create/Updates the checks catalog",
+                "summary": "Mark the hosts for the upgrade of their agent to a recorded release",
                 "parameters": [
                     {
-                        "description": "Checks catalog",
+                        "description": "The version to upgrade to and the hosts to upgrade",
                         "name": "Body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/web.JSONCheck"
-                            }
+                            "$ref": "#/definitions/web.JSONAgentUpgradeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/web.JSONCheck"
+                                "$ref": "#/definitions/models.AgentUpgrade"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/checks/{id}/results": {
-            "post": {
-                "produces": [
-                    "application/json"
-                ],
-                "summary": "Create a checks result entry",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Resource Id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     },
-                    {
-                        "description": "Checks result",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/web.JSONChecksResult"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/web.JSONChecksResult"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
@@ -133,33 +111,24 @@ var doc = `{
                 }
             }
         },
-        "/checks/{id}/settings": {
+        "/agent-versions": {
             "get": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get the check settings",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Resource id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Retrieve the releases of the agent the hosts can be upgraded to, the latest recorded first",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.JSONChecksSettings"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AgentVersion"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -176,22 +145,15 @@ var doc = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create the check settings",
+                "summary": "Record a release of the agent the hosts can be upgraded to, replacing the one with the same version",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Resource id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Checks settings",
+                        "description": "The version, the URL the agents download it from and its SHA-256 checksum",
                         "name": "Body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/web.JSONChecksSettings"
+                            "$ref": "#/definitions/models.AgentVersion"
                         }
                     }
                 ],
@@ -199,7 +161,16 @@ var doc = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/web.JSONChecksSettings"
+                            "$ref": "#/definitions/models.AgentVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
@@ -214,22 +185,41 @@ var doc = `{
                 }
             }
         },
-        "/clusters/settings": {
+        "/agents": {
             "get": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Retrieve Settings for all the clusters. Cluster's Selected checks and Hosts connection settings",
+                "summary": "Retrieve the agents enrolled since the approval is required, and their approval status",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "Status of the agents",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.ClusterSettings"
+                                "$ref": "#/definitions/models.Agent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -245,27 +235,20 @@ var doc = `{
                 }
             }
         },
-        "/clusters/{cluster_id}/results": {
+        "/agents/overview": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get a specific cluster's check results",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Cluster Id",
-                        "name": "cluster_id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Retrieve the activity of the agents of the hosts: last heartbeat, version and payloads by discovery type",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AgentOverview"
+                            }
                         }
                     },
                     "500": {
@@ -280,47 +263,26 @@ var doc = `{
                 }
             }
         },
-        "/clusters/{id}/tags": {
+        "/agents/{id}/approve": {
             "post": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Add tag to Cluster",
+                "summary": "Approve an agent, the data it collected so far is projected",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Cluster id",
+                        "description": "Agent id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "The tag to create",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Agent"
                         }
                     },
                     "404": {
@@ -344,29 +306,20 @@ var doc = `{
                 }
             }
         },
-        "/clusters/{id}/tags/{tag}": {
+        "/agents/{id}/enrollment": {
             "delete": {
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Until then the enrollments of its agent ID without the secret are refused",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete a specific tag that belongs to a cluster",
+                "summary": "Forget the secret an agent was issued at its first enrollment, to enroll it again from a new installation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Cluster id",
+                        "description": "Agent id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Tag",
-                        "name": "tag",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -376,51 +329,48 @@ var doc = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/databases/{id}/tags": {
+        "/agents/{id}/reject": {
             "post": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Add tag to a HANA database",
+                "summary": "Reject an agent, the collector refuses its requests",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Database id",
+                        "description": "Agent id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "The tag to create",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Agent"
                         }
                     },
                     "404": {
@@ -444,83 +394,70 @@ var doc = `{
                 }
             }
         },
-        "/databases/{id}/tags/{tag}": {
-            "delete": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/agents/{id}/secret": {
+            "put": {
+                "description": "The secret is only returned once, the agent must be configured with it right away\nas its payloads are refused unless signed with it",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete a specific tag that belongs to a HANA database",
+                "summary": "Generate the secret an agent signs its payloads with, replacing the previous one",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Database id",
+                        "description": "Agent id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Tag",
-                        "name": "tag",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.AgentSigningSecret"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/hosts/{id}/tags": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "unless the signature is required for every agent",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Add tag to host",
+                "summary": "Delete the signing secret of an agent, its payloads are not required to be signed anymore",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Host id",
+                        "description": "Agent id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "The tag to create",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "404": {
@@ -544,77 +481,91 @@ var doc = `{
                 }
             }
         },
-        "/hosts/{id}/tags/{tag}": {
-            "delete": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/audit": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete a specific tag that belongs to a host",
+                "summary": "List the changes made by the users, the most recent first",
                 "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "description": "Filter by user",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "description": "Filter by action",
+                        "name": "action",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Host id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by type of the changed resource",
+                        "name": "resource_type",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Tag",
-                        "name": "tag",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                        "description": "Filter by ID of the changed resource",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page",
+                        "name": "per_page",
+                        "in": "query"
                     }
-                }
-            }
-        },
-        "/prometheus/targets": {
-            "get": {
-                "produces": [
-                    "application/json"
                 ],
-                "summary": "Get prometheus HTTP SD targets",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/web.Targets"
+                                "$ref": "#/definitions/models.AuditEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/sapsystems/health": {
+        "/baselines": {
             "get": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Retrieve SAP Systems Health Summary",
+                "summary": "Retrieve the golden hosts of the roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.SAPSystemHealthSummary"
+                                "$ref": "#/definitions/models.HostBaseline"
                             }
                         }
                     },
@@ -630,38 +581,38 @@ var doc = `{
                 }
             }
         },
-        "/sapsystems/{id}/tags": {
-            "post": {
+        "/baselines/{role}": {
+            "put": {
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Add tag to SAPSystem",
+                "summary": "Make a host the golden one of the role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "SAPSystem id",
-                        "name": "id",
+                        "description": "Role, the tag of the hosts",
+                        "name": "role",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "The tag to create",
+                        "description": "The golden host",
                         "name": "Body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
+                            "$ref": "#/definitions/web.JSONHostBaseline"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
+                            "$ref": "#/definitions/models.HostBaseline"
                         }
                     },
                     "400": {
@@ -692,59 +643,155 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/sapsystems/{id}/tags/{tag}": {
+            },
             "delete": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete a specific tag that belongs to a SAPSystem",
+                "summary": "Stop comparing the hosts of the role against a golden host",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "SAPSystem id",
-                        "name": "id",
+                        "description": "Role, the tag of the hosts",
+                        "name": "role",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/baselines/{role}/report": {
+            "get": {
+                "description": "The host discovery, the subscriptions and the profiles of the SAP systems last collected are compared,\nleaving out the settings identifying the hosts, like the hostname and the IP addresses",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Compare the configuration of the hosts tagged with the role against its golden host",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Tag",
-                        "name": "tag",
+                        "description": "Role, the tag of the hosts",
+                        "name": "role",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaselineReport"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/tags": {
+        "/capacity": {
             "get": {
-                "consumes": [
+                "description": "The hosts of the organization of the user only, if any",
+                "produces": [
                     "application/json"
                 ],
+                "summary": "Retrieve the memory allocated by the HANA databases compared to the physical memory, per host and per SAP system",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CapacityOverview"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/checks/catalog": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "List all the tags in the system",
+                "summary": "Get the whole checks' catalog",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/web.JSONChecksGroup"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create/Updates the checks catalog",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Filter by resource type",
-                        "name": "resource_type",
-                        "in": "query"
+                        "description": "Checks catalog",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/web.JSONCheck"
+                            }
+                        }
                     }
                 ],
                 "responses": {
@@ -753,7 +800,7 @@ var doc = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "string"
+                                "$ref": "#/definitions/web.JSONCheck"
                             }
                         }
                     },
@@ -768,101 +815,6084 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Check": {
+        },
+        "/checks/{id}/results": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a checks result entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Checks result",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONChecksResult"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONChecksResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/checks/{id}/settings": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the check settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONChecksSettings"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The last save wins. When the version the settings are based on is given,\nthe changes made since then by others, and overwritten, are returned as conflicts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create the check settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Checks settings",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONChecksSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONChecksSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/settings": {
+            "get": {
+                "description": "The clusters of the organization of the user only, if any",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve Settings for all the clusters. Cluster's Selected checks and Hosts connection settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ClusterSettings"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{cluster_id}/facts": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the facts discovered on a specific cluster's hosts by the last checks execution",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Id",
+                        "name": "cluster_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DiscoveredFact"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{cluster_id}/results": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a specific cluster's check results",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Id",
+                        "name": "cluster_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{cluster_id}/results/diff": {
+            "get": {
+                "description": "If the runs are not given, the last run is compared with the one before it",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Compare two check runs of a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Id",
+                        "name": "cluster_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Previous run id",
+                        "name": "previous",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Current run id",
+                        "name": "current",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChecksResultDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{cluster_id}/results/runs": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the check runs of a cluster, the most recent first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Id",
+                        "name": "cluster_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ChecksRun"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{id}/health": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the health of a host, cluster, SAP system or database, as it was at the given time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp, now by default",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{id}/history": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the changes made by the users to the tags and settings of a host, cluster, SAP system or database, the most recent first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{id}/tags": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add tag to Cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The tag to create",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{id}/tags/{tag}": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a specific tag that belongs to a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/conflicts": {
+            "get": {
+                "description": "The conflicts among the resources of the organization of the user only, if any",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the IP addresses and hostnames claimed by more than one host, cluster or SAP system",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AddressConflict"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/alerts": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the hosts and clusters currently in critical or warning state",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ResourceAlert"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/layout": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the landing page layout of the current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DashboardLayout"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Arrange the landing page widgets of the current user",
+                "parameters": [
+                    {
+                        "description": "The ordered list of widgets to display",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DashboardLayout"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DashboardLayout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/pipeline": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the status of the data pipeline",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PipelineStatus"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/subscriptions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the subscriptions expiring within the next 30 days",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExpiringSubscription"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/widgets": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the widgets available for the landing page",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DashboardWidget"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/database/maintenance": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the latest maintenance recommendations for the Trento database",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DBMaintenanceReport"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/database/maintenance/inspect": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Inspect the Trento database and refresh the maintenance recommendations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DBMaintenanceReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/database/maintenance/tables/{table}": {
+            "post": {
+                "summary": "Run the recommended maintenance on a table of the Trento database",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Table name",
+                        "name": "table",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/databases/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Remove a HANA database, with its instances, tags and favorites, leaving its hosts untouched",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Database id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/databases/{id}/health": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the health of a host, cluster, SAP system or database, as it was at the given time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp, now by default",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/databases/{id}/history": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the changes made by the users to the tags and settings of a host, cluster, SAP system or database, the most recent first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/databases/{id}/tags": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add tag to a HANA database",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Database id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The tag to create",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/databases/{id}/tags/{tag}": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a specific tag that belongs to a HANA database",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Database id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/discovery/settings": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the discovery settings pulled by the agents with their configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AgentSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "The periods are in seconds by discovery type, the agents keep their own period if not set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update the discovery settings pulled by the agents with their configuration",
+                "parameters": [
+                    {
+                        "description": "The discovery periods and the disabled discoveries",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AgentSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AgentSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/entitlements": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the entitlements of the installation, their usage and the warnings about them",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Entitlements"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/favorites": {
+            "get": {
+                "description": "The resources of the organization of the user only, if any",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the favorite resources of the current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Favorite"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add a resource to the favorites of the current user",
+                "parameters": [
+                    {
+                        "description": "The resource to pin",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Favorite"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Favorite"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/favorites/{resource_type}/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Remove a resource from the favorites of the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource_type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}": {
+            "delete": {
+                "description": "A first request without the confirmation token is refused, returning the token to confirm the decommission with",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Decommission a retired host, removing it with all the data of its agent, the collected events included",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token returned by the request without it",
+                        "name": "confirmation_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/availability": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the availability percentage of a host, based on its heartbeats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Periods of time, in days, to compute the availability over (7, 30 or 90)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Availability"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/checks/run": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ask the agent of a host to run the checks selected for its cluster right away, over its channel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONAgentMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/discoveries/{discovery_type}": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ask the agent of a host to run a discovery right away, over its channel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Discovery type, e.g. ha_cluster_discovery",
+                        "name": "discovery_type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONAgentMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/health": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the health of a host, cluster, SAP system or database, as it was at the given time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp, now by default",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/history": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the changes made by the users to the tags and settings of a host, cluster, SAP system or database, the most recent first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/logs": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the logs shipped by the agent of a host, or the pending request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AgentLogs"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/logs/request": {
+            "post": {
+                "description": "The agent ships its logs once told so in the response to its next heartbeat",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ask the agent of a host to ship its recent logs, replacing the ones shipped before",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.AgentLogs"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/metrics": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the recent CPU, memory and disk usage of a host as sparklines, from the samples pushed by its agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Period of time, in minutes, to get the usage of (1 to 1440, default 60)",
+                        "name": "minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of points of the sparklines (1 to 360, default 60)",
+                        "name": "points",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HostMetricsSparkline"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/tags": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add tag to host",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The tag to create",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/tags/{tag}": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a specific tag that belongs to a host",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/timeline": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the heartbeat transitions, checks results changes, configuration changes and fencing events of a host, the most recent first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Period of time, in days, to get the events of (1 to 90, default 7)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TimelineEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/utilization": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the CPU, memory and disk utilization snapshots of a host, aggregated hourly",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Period of time, in days, to get the snapshots of (1 to 30, default 7)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.HostUtilizationSnapshot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/impersonation": {
+            "delete": {
+                "summary": "Stop the impersonation in progress, going back to act as the impersonator",
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/keys": {
+            "get": {
+                "description": "The keys of the organization of the logged in user only, if any",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the API keys, revoked ones included",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ApiKey"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The key is only returned in this response, it cannot be retrieved afterwards.\nThe key is restricted to the organization of the logged in user, if any",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create an API key, to be sent as a bearer token",
+                "parameters": [
+                    {
+                        "description": "The API key",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONApiKeyCreation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/keys/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiKey"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/landscape/graph": {
+            "get": {
+                "description": "The landscape of the organization of the user, if any",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the landscape topology as a graph of SAP systems, databases, clusters and hosts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LandscapeGraph"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/lockouts": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the users and addresses locked out after too many failed logins",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LoginThrottle"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/lockouts/{type}/{subject}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Unlock a user, or address, forgetting its failed logins",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user or address",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username or address",
+                        "name": "subject",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginThrottle"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/logging/sampling": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the sampling rules of the request logs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LogSamplingRule"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Only the given share of the successful requests to the routes is logged, the failed ones always are.\nThe requests left out are counted in a summary line every minute",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replace the sampling rules of the request logs, taking effect right away",
+                "parameters": [
+                    {
+                        "description": "The sampling rules",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LogSamplingRule"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LogSamplingRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the maintenance mode of the console",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceMode"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "In maintenance mode the changes are refused with 503 Service Unavailable,\nand the collected data as well if the collection is paused",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Put the console in maintenance mode, or take it out of it",
+                "parameters": [
+                    {
+                        "description": "The maintenance mode",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONMaintenanceMode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceMode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the logged in user, or API key, and the actions it is allowed to perform",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPermissions"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/tokens": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the personal access tokens of the logged in user, revoked and expired ones included",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PersonalAccessToken"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The token acts as the user, on the routes covered by its scopes only, until it expires.\nThe token is only returned in this response, it cannot be retrieved afterwards",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a personal access token of the logged in user, to be sent as a bearer token",
+                "parameters": [
+                    {
+                        "description": "The personal access token",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONPersonalAccessTokenCreation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.PersonalAccessToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/tokens/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Revoke a personal access token of the logged in user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Personal access token id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PersonalAccessToken"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Get the metrics of the web server, in the Prometheus text format",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/backlog": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the progress of the projection of the events collected before the startup",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProjectionBacklog"
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/capture": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the settings of the raw payload capture",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PayloadCaptureSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Start capturing the raw collected payloads, of a sampled percentage of them and/or of a specific agent",
+                "parameters": [
+                    {
+                        "description": "What to capture and for how long",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONPayloadCapture"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PayloadCaptureSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Stop capturing the raw collected payloads, the captured ones are kept until they expire",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PayloadCaptureSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/captures": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the captured raw payloads, the most recent first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by agent",
+                        "name": "agent_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CapturedPayload"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/captures/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve a captured raw payload",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Captured payload id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CapturedPayload"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/dead-letters": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the events the projectors failed to project, the oldest first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by projector",
+                        "name": "projector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DeadLetter"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/dead-letters/requeue": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Project again the events the projectors failed to project, once the cause is fixed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Requeue the dead letters of this projector only",
+                        "name": "projector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeadLetterRequeue"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/dead-letters/{id}/requeue": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Project again an event a projector failed to project, once the cause is fixed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dead letter id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeadLetterRequeue"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/inconsistencies": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the inconsistencies found in the projected read models by the last check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Inconsistency"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/inconsistencies/{kind}/{resource_id}/fix": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Fix an inconsistency projecting again the latest events of the agents involved, returns the inconsistencies left",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kind of the inconsistency",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the missing resource",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Inconsistency"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/projectors": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the registered projectors, and whether they are enabled",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RegisteredProjector"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/projectors/{id}/disable": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Disable a projector, the events are still stored and projected once it is enabled again",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Projector id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisteredProjector"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/projectors/{id}/enable": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Enable a projector, which catches up on the latest events collected while it was disabled",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Projector id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisteredProjector"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipeline/rejected": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the payloads rejected as not valid against the JSON schema of their discovery, the most recent first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by agent",
+                        "name": "agent_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RejectedPayload"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/prometheus/targets": {
+            "get": {
+                "description": "The hosts of the organization of the user only, if any",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get prometheus HTTP SD targets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/web.Targets"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/read-only": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the read-only mode of the console",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadOnlyMode"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "In read-only mode the changes are refused with 403 Forbidden, the data collection goes on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Put the console in read-only mode, or take it out of it",
+                "parameters": [
+                    {
+                        "description": "The read-only mode",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONReadOnlyMode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadOnlyMode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/costs": {
+            "get": {
+                "description": "The resources are grouped by the value of their tags of the form \u003ckey\u003e:\u003cvalue\u003e, those without any in an empty valued group.\nThe period defaults to the previous month, the to date being excluded.\nThe resources of the organization of the user only, if any",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "summary": "Report the monitored hosts and HANA instances, and their uptime, grouped by the values of the tags of a key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag key, e.g. cost-center",
+                        "name": "tag_key",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day of the period, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day after the period, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or csv, json by default",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CostReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/restrictions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the permissions restricted on specific resources",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ResourceRestriction"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/restrictions/{resource_type}/{id}/{permission}": {
+            "put": {
+                "description": "The tags:write permission can be restricted on hosts, clusters, sapsystems and databases,\nthe checks:write one on clusters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Restrict a permission on a resource to the users with the given role, or listed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource_type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Permission",
+                        "name": "permission",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The users allowed",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONResourceRestriction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResourceRestriction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Lift the restriction of a permission on a resource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource_type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Permission",
+                        "name": "permission",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/runner/settings": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the concurrency limits of the checks executions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RunnerSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update the concurrency limits of the checks executions, 0 means no limit",
+                "parameters": [
+                    {
+                        "description": "Maximum number of executions, globally and per target host",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RunnerSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RunnerSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/runs/queue": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the checks executions running or waiting for a runner slot",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QueuedRun"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace the queue of checks executions, reported by the runner on every change",
+                "parameters": [
+                    {
+                        "description": "Running and queued checks executions",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QueuedRun"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sapsystems/health": {
+            "get": {
+                "description": "With the at parameter, the health is the one the SAP systems had at the time.\nThe SAP systems of the organization of the user only, if any",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve SAP Systems Health Summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SAPSystemHealthSummary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sapsystems/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Remove a SAP system, with its instances, tags and favorites, leaving its hosts untouched",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SAP system id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sapsystems/{id}/health": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the health of a host, cluster, SAP system or database, as it was at the given time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp, now by default",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sapsystems/{id}/history": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the changes made by the users to the tags and settings of a host, cluster, SAP system or database, the most recent first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sapsystems/{id}/tags": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add tag to SAPSystem",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SAPSystem id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The tag to create",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sapsystems/{id}/tags/{tag}": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a specific tag that belongs to a SAPSystem",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SAPSystem id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "Matches the names, SIDs, IP addresses and tags starting with each of the words of the query, the best matches first.\nThe resources of the organization of the user only, if any",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Search the hosts, clusters, SAP systems and databases",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Words to search",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results, up to 100, 20 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List all the tags in the system",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by resource type",
+                        "name": "resource_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the personal access tokens of every user, revoked and expired ones included",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PersonalAccessToken"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Revoke the personal access token of any user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Personal access token id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PersonalAccessToken"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/usage": {
+            "get": {
+                "description": "Counted only while the usage analytics are enabled, the counts of the last minute may not be included yet",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve how many times the pages and the API routes of the console were used",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Period of time, in days, up to 90, 30 by default",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UsageReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/usage/settings": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve whether the usage of the console is counted",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UsageAnalyticsSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Disabling the usage analytics stops the counting, the usage counted so far is kept for 90 days",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Opt in, or out, to count the usage of the console",
+                "parameters": [
+                    {
+                        "description": "The usage analytics settings",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UsageAnalyticsSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UsageAnalyticsSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "The users of the organization of the logged in user only, if any",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the users and their roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a user with the given role",
+                "parameters": [
+                    {
+                        "description": "The user",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONUserCreation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "delete": {
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/impersonate": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Impersonate a user, acting with its role and preferences for a limited time",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Impersonation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/organization": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Restrict a user to the hosts, clusters and SAP systems of an organization, or lift the restriction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The organization, empty to lift the restriction",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONUserOrganization"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Change the role of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The role",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONUserRole"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AddressConflict": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "detected_at": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "owners": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AddressConflictOwner"
+                    }
+                }
+            }
+        },
+        "models.AddressConflictOwner": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Agent": {
+            "type": "object",
+            "properties": {
+                "enrolled_at": {
+                    "description": "EnrolledAt is the first time the agent contacted the collector",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "description": "ReviewedAt and ReviewedBy are empty until an admin approves or rejects the agent",
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AgentLogs": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "logs": {
+                    "type": "string"
+                },
+                "received_at": {
+                    "description": "ReceivedAt is nil while the agent has not shipped its logs yet",
+                    "type": "string"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "truncated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.AgentOverview": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors are the payloads recently rejected as invalid by discovery type",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "heartbeat": {
+                    "description": "Heartbeat is the health of the host according to the heartbeats of its agent",
+                    "type": "string"
+                },
+                "hostname": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "description": "LastSeenAt is when the last heartbeat of the agent was received, nil if none",
+                    "type": "string"
+                },
+                "payloads": {
+                    "description": "Payloads are the payloads stored by discovery type",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "version": {
+                    "description": "Version is empty until the agent registers",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AgentSettings": {
+            "type": "object",
+            "properties": {
+                "disabled_discoveries": {
+                    "description": "DisabledDiscoveries are not run by the agents",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "discovery_periods": {
+                    "description": "DiscoveryPeriods in seconds by discovery type, the agents keep their own period if not set",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.AgentSigningSecret": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret is only returned when generated",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AgentUpgrade": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AgentVersion": {
+            "type": "object",
+            "required": [
+                "checksum",
+                "url",
+                "version"
+            ],
+            "properties": {
+                "checksum": {
+                    "description": "Checksum is the SHA-256 of the release, hex encoded, the agent verifies once downloaded",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL the agent downloads the release from",
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApiKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Key is only returned once, when the key is created",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization": {
+                    "description": "Organization the key is restricted to, the one of the user who created it, none if empty",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix is the beginning of the key, to tell the keys apart without disclosing them",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "after": {},
+                "before": {},
+                "id": {
+                    "type": "integer"
+                },
+                "impersonated_user": {
+                    "description": "ImpersonatedUser the actor was impersonating when making the change, if any",
+                    "type": "string"
+                },
+                "organization": {
+                    "description": "Organization of the actor, the entries being visible to the users of the organization only",
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Availability": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "percentage": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.BaselineReport": {
+            "type": "object",
+            "properties": {
+                "baseline": {
+                    "$ref": "#/definitions/models.HostBaseline"
+                },
+                "golden_hostname": {
+                    "type": "string"
+                },
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HostDrift"
+                    }
+                }
+            }
+        },
+        "models.CapacityOverview": {
+            "type": "object",
+            "properties": {
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HostCapacity"
+                    }
+                },
+                "sap_systems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SAPSystemCapacity"
+                    }
+                }
+            }
+        },
+        "models.CapturedPayload": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "Size of the payload as received, before the truncation",
+                    "type": "integer"
+                },
+                "truncated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Check": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "fact_values": {
+                    "description": "FactValues are the values of the facts discovered on a host, keyed by the fact name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "facts": {
+                    "description": "Facts are the values the check declares to discover, reported by the runner alongside the result",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CheckFact"
+                    }
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "implementation": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "string"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "premium": {
+                    "type": "boolean"
+                },
+                "remediation": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "selected": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.CheckFact": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CheckResultChange": {
+            "type": "object",
+            "properties": {
+                "check_id": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string"
+                },
+                "previous": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChecksResultDiff": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "$ref": "#/definitions/models.ChecksRun"
+                },
+                "newly_failing": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CheckResultChange"
+                    }
+                },
+                "newly_muted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CheckResultChange"
+                    }
+                },
+                "newly_passing": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CheckResultChange"
+                    }
+                },
+                "previous": {
+                    "$ref": "#/definitions/models.ChecksRun"
+                }
+            }
+        },
+        "models.ChecksRun": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ClusterSettings": {
+            "type": "object",
+            "properties": {
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HostConnection"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "selected_checks": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ConfigurationDifference": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "string"
+                },
+                "discovery_type": {
+                    "type": "string"
+                },
+                "expected": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CostReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CostReportGroup"
+                    }
+                },
+                "tag_key": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CostReportGroup": {
+            "type": "object",
+            "properties": {
+                "hana_instance_uptime_hours": {
+                    "type": "number"
+                },
+                "hana_instances": {
+                    "description": "HANAInstances running on the hosts sending heartbeats during the period, grouped by the tags of their database",
+                    "type": "integer"
+                },
+                "host_uptime_hours": {
+                    "type": "number"
+                },
+                "hosts": {
+                    "description": "Hosts sending heartbeats during the period",
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DBMaintenanceReport": {
+            "type": "object",
+            "properties": {
+                "inspected_at": {
+                    "type": "string"
+                },
+                "recommendations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DBRecommendation"
+                    }
+                }
+            }
+        },
+        "models.DBRecommendation": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "runnable": {
+                    "type": "boolean"
+                },
+                "target": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DashboardLayout": {
+            "type": "object",
+            "required": [
+                "widgets"
+            ],
+            "properties": {
+                "widgets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.DashboardWidget": {
+            "type": "object",
+            "properties": {
+                "data_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "discovery_type": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "projector_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DeadLetterRequeue": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Failed are the dead letters failing again, or whose event was pruned",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DeadLetter"
+                    }
+                },
+                "requeued": {
+                    "description": "Requeued are the dead letters projected, and removed",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DiscoveredFact": {
+            "type": "object",
+            "properties": {
+                "check_id": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Entitlements": {
+            "type": "object",
+            "properties": {
+                "customer": {
+                    "type": "string"
+                },
+                "enforcement": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "hosts_limit": {
+                    "description": "HostsLimit is 0 when the number of monitored hosts is not limited",
+                    "type": "integer"
+                },
+                "monitored_hosts": {
+                    "type": "integer"
+                },
+                "premium_checks": {
+                    "type": "boolean"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "warnings": {
+                    "description": "Warnings are shown in a banner, empty when the entitlements are in good standing",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ExpiringSubscription": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "host_id": {
+                    "type": "string"
+                },
+                "host_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Favorite": {
+            "type": "object",
+            "required": [
+                "resource_id",
+                "resource_type"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HealthRecord": {
+            "type": "object",
+            "properties": {
+                "health": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HostBaseline": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HostCapacity": {
+            "type": "object",
+            "properties": {
+                "allocated_memory_mb": {
+                    "type": "integer"
+                },
+                "allocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MemoryAllocation"
+                    }
+                },
+                "cores": {
+                    "type": "integer"
+                },
+                "hypervisor": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "over_committed": {
+                    "type": "boolean"
+                },
+                "physical_memory_mb": {
+                    "type": "integer"
+                },
+                "sockets": {
+                    "type": "integer"
+                },
+                "threads": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.HostConnection": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "cloud_provider": {
+                    "description": "CloudProvider the host runs on, empty if none was detected",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HostDrift": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "differences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ConfigurationDifference"
+                    }
+                },
+                "hostname": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HostMetricsSparkline": {
+            "type": "object",
+            "properties": {
+                "cpu": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "disk": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "memory": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "since": {
+                    "type": "string"
+                },
+                "step_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.HostUtilizationSnapshot": {
+            "type": "object",
+            "properties": {
+                "cpu_percent_avg": {
+                    "type": "number"
+                },
+                "cpu_percent_max": {
+                    "type": "number"
+                },
+                "disk_percent_max": {
+                    "type": "number"
+                },
+                "memory_percent_avg": {
+                    "type": "number"
+                },
+                "memory_percent_max": {
+                    "type": "number"
+                },
+                "samples_count": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Impersonation": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "impersonator": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Inconsistency": {
+            "type": "object",
+            "properties": {
+                "agent_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "detected_at": {
+                    "type": "string"
+                },
+                "fixable": {
+                    "description": "Fixable tells whether events of the agents are stored, to be projected again",
+                    "type": "boolean"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "referenced_by": {
+                    "description": "ReferencedBy are the types of the resources referring to the missing one",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "resource_id": {
+                    "description": "ResourceID is the ID of the missing cluster or host",
+                    "type": "string"
+                }
+            }
+        },
+        "models.LandscapeEdge": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LandscapeGraph": {
+            "type": "object",
+            "properties": {
+                "edges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LandscapeEdge"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LandscapeNode"
+                    }
+                }
+            }
+        },
+        "models.LandscapeNode": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "health": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LogSamplingRule": {
+            "type": "object",
+            "required": [
+                "method",
+                "route"
+            ],
+            "properties": {
+                "method": {
+                    "type": "string"
+                },
+                "rate": {
+                    "description": "Rate is the share of the successful requests logged, 0 suppressing them",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "route": {
+                    "description": "Route as registered, e.g. /api/hosts/:id/heartbeat",
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginThrottle": {
+            "type": "object",
+            "properties": {
+                "blocked_until": {
+                    "description": "BlockedUntil is the time before which the logins are rejected without checking the credentials",
+                    "type": "string"
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "last_failure_at": {
+                    "type": "string"
+                },
+                "locked": {
+                    "description": "Locked is set once the failures reach the limit, the logins being blocked for the whole lockout duration",
+                    "type": "boolean"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is either LoginThrottleUser or LoginThrottleAddress, Subject being the username or the address",
+                    "type": "string"
+                }
+            }
+        },
+        "models.MaintenanceMode": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "enabled_by": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pause_collection": {
+                    "type": "boolean"
+                },
+                "since": {
+                    "description": "Since and EnabledBy are set when the mode is enabled",
+                    "type": "string"
+                }
+            }
+        },
+        "models.MemoryAllocation": {
+            "type": "object",
+            "properties": {
+                "allocated_memory_mb": {
+                    "type": "integer"
+                },
+                "default": {
+                    "description": "Default is true when no global allocation limit is configured and the HANA default one applies",
+                    "type": "boolean"
+                },
+                "instance_number": {
+                    "type": "string"
+                },
+                "sap_system_id": {
+                    "type": "string"
+                },
+                "sid": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PayloadCaptureSettings": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "description": "AgentID whose payloads are all captured, regardless of the sampling",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "max_size_bytes": {
+                    "description": "MaxSizeBytes of a captured payload, longer payloads are truncated",
+                    "type": "integer"
+                },
+                "sampling_percentage": {
+                    "description": "SamplingPercentage of the payloads of all the agents to capture",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PayloadValidationIssue": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "description": "Location of the invalid value in the payload, as a JSON pointer",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PersonalAccessToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix is the beginning of the token, to tell the tokens apart without disclosing them",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "description": "Token is only returned once, when the token is created",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PipelineStatus": {
+            "type": "object",
+            "properties": {
+                "events_count": {
+                    "type": "integer"
+                },
+                "last_collected_at": {
+                    "type": "string"
+                },
+                "last_projected_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProjectionBacklog": {
+            "type": "object",
+            "properties": {
+                "finished_at": {
+                    "type": "string"
+                },
+                "in_progress": {
+                    "type": "boolean"
+                },
+                "projected": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "superseded": {
+                    "description": "Superseded events are skipped, a later event of the same agent and discovery type replacing their state",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total events to project, the latest of every agent and discovery type",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.QueuedRun": {
+            "type": "object",
+            "required": [
+                "cluster_id",
+                "state"
+            ],
+            "properties": {
+                "cluster_id": {
+                    "type": "string"
+                },
+                "queued_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "queued",
+                        "running"
+                    ]
+                },
+                "targets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ReadOnlyMode": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "enabled_by": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "since": {
+                    "description": "Since and EnabledBy are set when the mode is enabled",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RegisteredProjector": {
+            "type": "object",
+            "properties": {
+                "disabled_at": {
+                    "type": "string"
+                },
+                "disabled_by": {
+                    "type": "string"
+                },
+                "discovery_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "pending_events": {
+                    "description": "PendingEvents collected while the projector is disabled, the latest of which are projected once enabled again",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RejectedPayload": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "discovery_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PayloadValidationIssue"
+                    }
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "truncated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ResourceAlert": {
+            "type": "object",
+            "properties": {
+                "health": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResourceRestriction": {
+            "type": "object",
+            "properties": {
+                "permission": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "usernames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.RouteUsage": {
             "type": "object",
             "properties": {
-                "description": {
+                "count": {
+                    "type": "integer"
+                },
+                "last_used_on": {
                     "type": "string"
                 },
-                "group": {
+                "route": {
+                    "description": "Route as registered, e.g. GET /hosts/:id",
                     "type": "string"
+                }
+            }
+        },
+        "models.RunnerSettings": {
+            "type": "object",
+            "properties": {
+                "max_concurrent_runs": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "max_runs_per_target": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.SAPSystemCapacity": {
+            "type": "object",
+            "properties": {
+                "allocated_memory_mb": {
+                    "type": "integer"
+                },
+                "hosts_count": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "string"
                 },
-                "implementation": {
+                "over_committed": {
+                    "type": "boolean"
+                },
+                "physical_memory_mb": {
+                    "type": "integer"
+                },
+                "sid": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SAPSystemHealthSummary": {
+            "type": "object",
+            "properties": {
+                "clusters_health": {
                     "type": "string"
                 },
-                "labels": {
+                "database_health": {
                     "type": "string"
                 },
-                "msg": {
+                "hosts_health": {
                     "type": "string"
                 },
-                "name": {
+                "id": {
                     "type": "string"
                 },
-                "premium": {
-                    "type": "boolean"
+                "sapsystem_health": {
+                    "type": "string"
                 },
-                "remediation": {
+                "sid": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SearchResult": {
+            "type": "object",
+            "properties": {
+                "id": {
                     "type": "string"
                 },
-                "result": {
+                "name": {
                     "type": "string"
                 },
-                "selected": {
-                    "type": "boolean"
+                "rank": {
+                    "type": "number"
+                },
+                "resource_type": {
+                    "description": "ResourceType is one of hosts, clusters, sapsystems or databases",
+                    "type": "string"
                 }
             }
         },
-        "models.ClusterSettings": {
+        "models.TimelineEvent": {
             "type": "object",
             "properties": {
-                "hosts": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.HostConnection"
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
                     }
                 },
-                "id": {
+                "kind": {
                     "type": "string"
                 },
-                "selected_checks": {
+                "severity": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UsageAnalyticsSettings": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.UsageReport": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "routes": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.RouteUsage"
                     }
+                },
+                "since": {
+                    "type": "string"
                 }
             }
         },
-        "models.HostConnection": {
+        "models.User": {
             "type": "object",
             "properties": {
-                "address": {
+                "created_at": {
                     "type": "string"
                 },
-                "name": {
+                "id": {
+                    "type": "integer"
+                },
+                "organization": {
+                    "description": "Organization the user is restricted to, the users of no organization see all of them",
                     "type": "string"
                 },
-                "user": {
+                "role": {
+                    "type": "string"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "models.SAPSystemHealthSummary": {
+        "models.UserPermissions": {
             "type": "object",
             "properties": {
-                "clusters_health": {
-                    "type": "string"
+                "impersonation": {
+                    "description": "Impersonation in progress, the username and role are the ones of the impersonated user",
+                    "$ref": "#/definitions/models.Impersonation"
                 },
-                "database_health": {
+                "organization": {
+                    "description": "Organization the resources are restricted to, if any",
                     "type": "string"
                 },
-                "hosts_health": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
                     "type": "string"
                 },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "web.JSONAgentMessage": {
+            "type": "object",
+            "properties": {
                 "id": {
                     "type": "string"
                 },
-                "sapsystem_health": {
+                "payload": {},
+                "type": {
                     "type": "string"
+                }
+            }
+        },
+        "web.JSONAgentUpgradeRequest": {
+            "type": "object",
+            "required": [
+                "host_ids",
+                "version"
+            ],
+            "properties": {
+                "host_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "sid": {
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "web.JSONApiKeyCreation": {
+            "type": "object",
+            "required": [
+                "name",
+                "scope"
+            ],
+            "properties": {
+                "name": {
                     "type": "string"
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "read",
+                        "write"
+                    ]
                 }
             }
         },
@@ -877,6 +6907,13 @@ var doc = `{
                 "description": {
                     "type": "string"
                 },
+                "facts": {
+                    "description": "Facts are the values the check discovers on the hosts, reported alongside its result",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/web.JSONCheckFact"
+                    }
+                },
                 "group": {
                     "type": "string"
                 },
@@ -900,6 +6937,17 @@ var doc = `{
                 }
             }
         },
+        "web.JSONCheckFact": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "web.JSONCheckResult": {
             "type": "object",
             "properties": {
@@ -962,6 +7010,13 @@ var doc = `{
                 "selected_checks"
             ],
             "properties": {
+                "conflicts": {
+                    "description": "Conflicts are the changes made since the version the saved settings are based on, which they overwrite",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEntry"
+                    }
+                },
                 "connection_settings": {
                     "type": "object",
                     "additionalProperties": {
@@ -979,12 +7034,34 @@ var doc = `{
                     "items": {
                         "type": "string"
                     }
+                },
+                "version": {
+                    "description": "Version identifies the last change of the settings, the one the saved settings are based on",
+                    "type": "integer"
+                }
+            }
+        },
+        "web.JSONHostBaseline": {
+            "type": "object",
+            "required": [
+                "agent_id"
+            ],
+            "properties": {
+                "agent_id": {
+                    "type": "string"
                 }
             }
         },
         "web.JSONHosts": {
             "type": "object",
             "properties": {
+                "fact_values": {
+                    "description": "FactValues are the values of the facts the check discovered on the host",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "msg": {
                     "type": "string"
                 },
@@ -996,6 +7073,102 @@ var doc = `{
                 }
             }
         },
+        "web.JSONMaintenanceMode": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pause_collection": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "web.JSONPayloadCapture": {
+            "type": "object",
+            "required": [
+                "duration_minutes"
+            ],
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "duration_minutes": {
+                    "description": "DurationMinutes after which the capture stops by itself",
+                    "type": "integer",
+                    "maximum": 1440,
+                    "minimum": 1
+                },
+                "max_size_bytes": {
+                    "type": "integer",
+                    "maximum": 1048576,
+                    "minimum": 0
+                },
+                "sampling_percentage": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                }
+            }
+        },
+        "web.JSONPersonalAccessTokenCreation": {
+            "type": "object",
+            "required": [
+                "expires_in_days",
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "web.JSONReadOnlyMode": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "web.JSONResourceRestriction": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "operator",
+                        "viewer"
+                    ]
+                },
+                "usernames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "web.JSONTag": {
             "type": "object",
             "required": [
@@ -1007,6 +7180,59 @@ var doc = `{
                 }
             }
         },
+        "web.JSONUserCreation": {
+            "type": "object",
+            "required": [
+                "password",
+                "role",
+                "username"
+            ],
+            "properties": {
+                "organization": {
+                    "description": "Organization the user is restricted to, none if empty. The users created by the users of an organization\nare restricted to it",
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "operator",
+                        "viewer"
+                    ]
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "web.JSONUserOrganization": {
+            "type": "object",
+            "properties": {
+                "organization": {
+                    "description": "Organization the user is restricted to, none if empty",
+                    "type": "string"
+                }
+            }
+        },
+        "web.JSONUserRole": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "operator",
+                        "viewer"
+                    ]
+                }
+            }
+        },
         "web.Targets": {
             "type": "object",
             "properties": {
@@ -1027,56 +7253,18 @@ var doc = `{
     }
 }`
 
-type swaggerInfo struct {
-	Version     string
-	Host        string
-	BasePath    string
-	Schemes     []string
-	Title       string
-	Description string
-}
-
 // SwaggerInfo holds exported Swagger Info so clients can modify it
-var SwaggerInfo = swaggerInfo{
-	Version:     "1.0",
-	Host:        "",
-	BasePath:    "/api",
-	Schemes:     []string{"http"},
-	Title:       "Trento API",
-	Description: "Trento API",
-}
-
-type s struct{}
-
-func (s *s) ReadDoc() string {
-	sInfo := SwaggerInfo
-	sInfo.Description = strings.Replace(sInfo.Description, "\n", "\\n", -1)
-
-	t, err := template.New("swagger_info").Funcs(template.FuncMap{
-		"marshal": func(v interface{}) string {
-			a, _ := json.Marshal(v)
-			return string(a)
-		},
-		"escape": func(v interface{}) string {
-			// escape tabs
-			str := strings.Replace(v.(string), "\t", "\\t", -1)
-			// replace " with \", and if that results in \\", replace that with \\\"
-			str = strings.Replace(str, "\"", "\\\"", -1)
-			return strings.Replace(str, "\\\\\"", "\\\\\\\"", -1)
-		},
-	}).Parse(doc)
-	if err != nil {
-		return doc
-	}
-
-	var tpl bytes.Buffer
-	if err := t.Execute(&tpl, sInfo); err != nil {
-		return doc
-	}
-
-	return tpl.String()
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "",
+	BasePath:         "/api",
+	Schemes:          []string{"http"},
+	Title:            "Trento API",
+	Description:      "Trento API",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
 }
 
 func init() {
-	swag.Register("swagger", &s{})
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
 }
@@ -0,0 +1,116 @@
+/*
+Based on https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/instancedata-data-retrieval.html
+*/
+
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	awsApiAddress = "169.254.169.254"
+	awsTokenTTL   = "60"
+)
+
+var errAwsMetadataNotFound = errors.New("aws metadata not found")
+
+type AwsMetadata struct {
+	InstanceId       string `json:"instanceId,omitempty" mapstructure:"instanceid,omitempty"`
+	InstanceType     string `json:"instanceType,omitempty" mapstructure:"instancetype,omitempty"`
+	AvailabilityZone string `json:"availabilityZone,omitempty" mapstructure:"availabilityzone,omitempty"`
+	// Tags of the instance, available only if the access to the tags is allowed in the instance metadata options
+	Tags map[string]string `json:"tags,omitempty" mapstructure:"tags,omitempty"`
+}
+
+// NewAwsMetadata gets the metadata of the instance with the IMDSv2 session token
+func NewAwsMetadata() (*AwsMetadata, error) {
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s/latest/api/token", awsApiAddress), nil)
+	req.Header.Add("X-aws-ec2-metadata-token-ttl-seconds", awsTokenTTL)
+
+	log.Debug("Requesting Aws metadata...")
+
+	token, err := getAwsMetadata(req)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	m := &AwsMetadata{}
+	for path, field := range map[string]*string{
+		"instance-id":                 &m.InstanceId,
+		"instance-type":               &m.InstanceType,
+		"placement/availability-zone": &m.AvailabilityZone,
+	} {
+		*field, err = getAwsMetadataPath(token, path)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+	}
+
+	m.Tags, err = getAwsTags(token)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// getAwsTags returns no tag if their access is not allowed, the metadata service responds with 404
+func getAwsTags(token string) (map[string]string, error) {
+	keys, err := getAwsMetadataPath(token, "tags/instance")
+	if err == errAwsMetadataNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	for _, key := range strings.Fields(keys) {
+		tags[key], err = getAwsMetadataPath(token, "tags/instance/"+key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tags, nil
+}
+
+func getAwsMetadataPath(token string, path string) (string, error) {
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/latest/meta-data/%s", awsApiAddress, path), nil)
+	req.Header.Add("X-aws-ec2-metadata-token", token)
+
+	return getAwsMetadata(req)
+}
+
+func getAwsMetadata(req *http.Request) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errAwsMetadataNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("aws metadata service responded with status code %d to %s", resp.StatusCode, req.URL.Path)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,83 @@
+package cloud
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/internal/cloud/mocks"
+)
+
+func mockAwsMetadataService(paths map[string]string) *mocks.HTTPClient {
+	clientMock := new(mocks.HTTPClient)
+
+	clientMock.On("Do", mock.AnythingOfType("*http.Request")).Return(
+		func(req *http.Request) *http.Response {
+			if req.URL.Path != "/latest/api/token" && req.Header.Get("X-aws-ec2-metadata-token") != "token" {
+				return &http.Response{StatusCode: 401, Body: ioutil.NopCloser(bytes.NewReader(nil))}
+			}
+
+			body, ok := paths[req.URL.Path]
+			if !ok {
+				return &http.Response{StatusCode: 404, Body: ioutil.NopCloser(bytes.NewReader(nil))}
+			}
+
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+		}, nil,
+	)
+
+	return clientMock
+}
+
+func TestNewAwsMetadata(t *testing.T) {
+	client = mockAwsMetadataService(map[string]string{
+		"/latest/api/token":                             "token",
+		"/latest/meta-data/instance-id":                 "i-0123456789abcdef0",
+		"/latest/meta-data/instance-type":               "r5.4xlarge",
+		"/latest/meta-data/placement/availability-zone": "eu-central-1a",
+		"/latest/meta-data/tags/instance":               "CostCenter\nEnvironment\n",
+		"/latest/meta-data/tags/instance/CostCenter":    "sap-4711",
+		"/latest/meta-data/tags/instance/Environment":   "production",
+	})
+
+	m, err := NewAwsMetadata()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &AwsMetadata{
+		InstanceId:       "i-0123456789abcdef0",
+		InstanceType:     "r5.4xlarge",
+		AvailabilityZone: "eu-central-1a",
+		Tags: map[string]string{
+			"CostCenter":  "sap-4711",
+			"Environment": "production",
+		},
+	}, m)
+}
+
+func TestNewAwsMetadataTagsNotAllowed(t *testing.T) {
+	client = mockAwsMetadataService(map[string]string{
+		"/latest/api/token":                             "token",
+		"/latest/meta-data/instance-id":                 "i-0123456789abcdef0",
+		"/latest/meta-data/instance-type":               "r5.4xlarge",
+		"/latest/meta-data/placement/availability-zone": "eu-central-1a",
+	})
+
+	m, err := NewAwsMetadata()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "i-0123456789abcdef0", m.InstanceId)
+	assert.Nil(t, m.Tags)
+}
+
+func TestNewAwsMetadataError(t *testing.T) {
+	client = mockAwsMetadataService(map[string]string{
+		"/latest/api/token": "token",
+	})
+
+	_, err := NewAwsMetadata()
+
+	assert.Error(t, err)
+}
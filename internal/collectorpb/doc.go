@@ -0,0 +1,4 @@
+// Package collectorpb holds the gRPC service the agents stream their discovered data to, alongside the HTTP collector
+package collectorpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative collector.proto
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultPasswordRefreshInterval at which the password is read again from its source,
+// so that the rotated credentials are used by the new connections
+const DefaultPasswordRefreshInterval = time.Minute
+
+// VaultConfig locates a secret in HashiCorp Vault, both the KV version 1 and 2 engines are supported
+type VaultConfig struct {
+	Address string
+	Token   string
+	// Path of the secret, e.g. secret/data/trento for the KV version 2 engine mounted at secret
+	Path string
+	// Key of the password in the secret
+	Key string
+}
+
+// passwordSource reads the current password, e.g. from a file or from Vault
+type passwordSource func(ctx context.Context) (string, error)
+
+// filePassword reads the password from a file, e.g. a mounted Kubernetes secret
+func filePassword(path string) passwordSource {
+	return func(_ context.Context) (string, error) {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+}
+
+// vaultPassword reads the password from a Vault secret
+func vaultPassword(config *VaultConfig) passwordSource {
+	return func(ctx context.Context) (string, error) {
+		url := fmt.Sprintf("%s/v1/%s", strings.TrimRight(config.Address, "/"), strings.TrimLeft(config.Path, "/"))
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Add("X-Vault-Token", config.Token)
+
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("could not read the secret %s from Vault: %s", config.Path, resp.Status)
+		}
+
+		var secret struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+			return "", err
+		}
+
+		// the KV version 2 engine nests the secret in data, along with its metadata
+		data := secret.Data
+		if nested, ok := data["data"].(map[string]interface{}); ok {
+			if _, hasMetadata := data["metadata"]; hasMetadata {
+				data = nested
+			}
+		}
+
+		password, ok := data[config.Key].(string)
+		if !ok {
+			return "", fmt.Errorf("the secret %s in Vault has no %s key", config.Path, config.Key)
+		}
+
+		return password, nil
+	}
+}
+
+// passwordSource returns where the password is read from, if not given in the configuration
+func (c *Config) passwordSource() passwordSource {
+	switch {
+	case c.Vault != nil:
+		return vaultPassword(c.Vault)
+	case c.PasswordFile != "":
+		return filePassword(c.PasswordFile)
+	default:
+		return nil
+	}
+}
+
+// rotatingPassword keeps the last password read from a source, reading it again periodically
+type rotatingPassword struct {
+	source   passwordSource
+	mutex    sync.RWMutex
+	password string
+}
+
+func newRotatingPassword(ctx context.Context, source passwordSource) (*rotatingPassword, error) {
+	password, err := source(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rotatingPassword{source: source, password: password}, nil
+}
+
+func (r *rotatingPassword) Get() string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.password
+}
+
+// Refresh reads the password again, keeping the previous one on error
+func (r *rotatingPassword) Refresh(ctx context.Context) error {
+	password, err := r.source(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if password != r.password {
+		log.Info("The database password changed, the new connections will use it")
+	}
+	r.password = password
+
+	return nil
+}
+
+// Start refreshes the password at every interval until the context is done
+func (r *rotatingPassword) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				log.Errorf("Could not read the database password again, the previous one is kept: %s", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ReadSecretFile reads a secret from a file, the trailing newlines being trimmed
+func ReadSecretFile(path string) (string, error) {
+	return filePassword(path)(context.Background())
+}
+
+// ReadVaultSecret reads the key of a Vault secret
+func ReadVaultSecret(ctx context.Context, config *VaultConfig) (string, error) {
+	return vaultPassword(config)(ctx)
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilePassword(t *testing.T) {
+	file := path.Join(t.TempDir(), "password")
+	ioutil.WriteFile(file, []byte("secret\n"), 0600)
+
+	password, err := filePassword(file)(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", password)
+}
+
+func TestFilePasswordMissing(t *testing.T) {
+	_, err := filePassword(path.Join(t.TempDir(), "missing"))(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestVaultPassword(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "some-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/trento":
+			w.Write([]byte(`{"data":{"data":{"password":"kv2-secret"},"metadata":{"version":3}}}`))
+		case "/v1/kv/trento":
+			w.Write([]byte(`{"data":{"password":"kv1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	password, err := vaultPassword(&VaultConfig{
+		Address: server.URL, Token: "some-token", Path: "secret/data/trento", Key: "password",
+	})(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "kv2-secret", password)
+
+	password, err = vaultPassword(&VaultConfig{
+		Address: server.URL, Token: "some-token", Path: "kv/trento", Key: "password",
+	})(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "kv1-secret", password)
+
+	_, err = vaultPassword(&VaultConfig{
+		Address: server.URL, Token: "some-token", Path: "kv/trento", Key: "other",
+	})(context.Background())
+	assert.EqualError(t, err, "the secret kv/trento in Vault has no other key")
+
+	_, err = vaultPassword(&VaultConfig{
+		Address: server.URL, Token: "wrong-token", Path: "kv/trento", Key: "password",
+	})(context.Background())
+	assert.EqualError(t, err, "could not read the secret kv/trento from Vault: 403 Forbidden")
+}
+
+func TestRotatingPassword(t *testing.T) {
+	file := path.Join(t.TempDir(), "password")
+	ioutil.WriteFile(file, []byte("secret"), 0600)
+
+	password, err := newRotatingPassword(context.Background(), filePassword(file))
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", password.Get())
+
+	ioutil.WriteFile(file, []byte("rotated"), 0600)
+	assert.NoError(t, password.Refresh(context.Background()))
+	assert.Equal(t, "rotated", password.Get())
+
+	os.Remove(file)
+	assert.Error(t, password.Refresh(context.Background()))
+	assert.Equal(t, "rotated", password.Get())
+}
+
+func TestPasswordSource(t *testing.T) {
+	assert.Nil(t, (&Config{Password: "secret"}).passwordSource())
+	assert.NotNil(t, (&Config{PasswordFile: "/run/secrets/password"}).passwordSource())
+	assert.NotNil(t, (&Config{Vault: &VaultConfig{Path: "secret/data/trento"}}).passwordSource())
+}
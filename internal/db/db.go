@@ -6,26 +6,69 @@ import (
 	"time"
 
 	"github.com/avast/retry-go/v4"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
+const DefaultSchema = "public"
+
 type Config struct {
 	Host     string
 	Port     int
 	User     string
 	Password string
 	DBName   string
+	// Schema the Trento tables live in, so that they can coexist with the ones of other applications
+	// in the same database. It is one schema for the whole deployment, the organizations are not mapped to schemas
+	// of their own: multiple Trento instances can share a database, each one with its schema.
+	// The tables of the models are qualified with it, the raw queries rely on the search path
+	Schema string
+	// PasswordFile the password is read from instead, e.g. a mounted Kubernetes secret
+	PasswordFile string
+	// Vault secret the password is read from instead, if set
+	Vault *VaultConfig
+	// PasswordRefreshInterval at which the password is read again from the file or Vault
+	PasswordRefreshInterval time.Duration
+}
+
+func (c *Config) schema() string {
+	if c.Schema == "" {
+		return DefaultSchema
+	}
+
+	return c.Schema
 }
 
 func InitDB(ctx context.Context, config *Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+	dsn := fmt.Sprintf("host=%s port=%d user=%s dbname=%s search_path=%s sslmode=disable",
 		config.Host,
 		config.Port,
 		config.User,
-		config.Password,
-		config.DBName)
+		config.DBName,
+		config.schema())
+
+	// the password read from a file or Vault is resolved at every new connection, so that it can be rotated
+	var password *rotatingPassword
+	if source := config.passwordSource(); source != nil {
+		var err error
+		password, err = newRotatingPassword(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the database password: %w", err)
+		}
+	} else {
+		dsn = fmt.Sprintf("%s password=%s", dsn, config.Password)
+	}
+
+	// the tables of the models are qualified with the schema, the search path covers the raw queries
+	namingStrategy := schema.NamingStrategy{}
+	if config.schema() != DefaultSchema {
+		namingStrategy.TablePrefix = config.schema() + "."
+	}
 
 	var db *gorm.DB
 	var err error
@@ -33,17 +76,28 @@ func InitDB(ctx context.Context, config *Config) (*gorm.DB, error) {
 	err = retry.Do(
 		func() error {
 			log.Info("Connecting to the database")
+			var dialector gorm.Dialector
+			dialector, err = openDialector(dsn, password)
+			if err != nil {
+				return err
+			}
+
 			// TODO: since we are dealing with eventual consistency, we can't enforce foreign key constraints in our projected models.
 			// This disables foreign key constraints enforcement at global level.
 			// In a future we will enable this on a per-model basis via dedicated migrations and disabling the automigration feature.
-			db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+			db, err = gorm.Open(dialector, &gorm.Config{
 				DisableForeignKeyConstraintWhenMigrating: true,
+				NamingStrategy:                           namingStrategy,
 			})
 			if err != nil {
 				return err
 			}
 
-			return nil
+			if config.schema() == DefaultSchema {
+				return nil
+			}
+
+			return db.Exec("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(config.schema())).Error
 		},
 		retry.OnRetry(func(_ uint, err error) {
 			log.Error(err)
@@ -54,6 +108,35 @@ func InitDB(ctx context.Context, config *Config) (*gorm.DB, error) {
 		retry.LastErrorOnly(true),
 		retry.Context(ctx),
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if password != nil {
+		interval := config.PasswordRefreshInterval
+		if interval <= 0 {
+			interval = DefaultPasswordRefreshInterval
+		}
+		go password.Start(ctx, interval)
+	}
+
+	return db, nil
+}
+
+func openDialector(dsn string, password *rotatingPassword) (gorm.Dialector, error) {
+	if password == nil {
+		return postgres.Open(dsn), nil
+	}
+
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := stdlib.OpenDB(*connConfig, stdlib.OptionBeforeConnect(func(_ context.Context, c *pgx.ConnConfig) error {
+		c.Password = password.Get()
+		return nil
+	}))
 
-	return db, err
+	return postgres.New(postgres.Config{Conn: conn}), nil
 }
@@ -0,0 +1,76 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"gorm.io/gorm"
+)
+
+const testSchema = "trento_schema_test"
+
+type schemaTestHost struct {
+	AgentID string `gorm:"primaryKey"`
+	Name    string
+}
+
+type schemaTestTag struct {
+	ID      int64
+	AgentID string
+	Value   string
+}
+
+type SchemaTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+}
+
+func TestSchemaTestSuite(t *testing.T) {
+	suite.Run(t, new(SchemaTestSuite))
+}
+
+func (suite *SchemaTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabaseInSchema(suite.T(), testSchema)
+
+	suite.Require().NoError(suite.db.AutoMigrate(&schemaTestHost{}, &schemaTestTag{}))
+}
+
+func (suite *SchemaTestSuite) TearDownSuite() {
+	suite.db.Exec("DROP SCHEMA " + testSchema + " CASCADE")
+}
+
+func (suite *SchemaTestSuite) TestTablesInSchema() {
+	var tables []string
+	suite.NoError(suite.db.Raw(
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = ? ORDER BY table_name", testSchema,
+	).Scan(&tables).Error)
+	suite.Equal([]string{"schema_test_hosts", "schema_test_tags"}, tables)
+
+	var inPublic int64
+	suite.NoError(suite.db.Raw(
+		"SELECT count(*) FROM information_schema.tables WHERE table_schema = 'public' AND table_name IN ?",
+		[]string{"schema_test_hosts", "schema_test_tags"},
+	).Scan(&inPublic).Error)
+	suite.Equal(int64(0), inPublic)
+}
+
+func (suite *SchemaTestSuite) TestQueriesInSchema() {
+	tx := suite.db.Begin()
+	defer tx.Rollback()
+
+	suite.NoError(tx.Create(&schemaTestHost{AgentID: "agent1", Name: "host1"}).Error)
+	suite.NoError(tx.Create(&schemaTestTag{AgentID: "agent1", Value: "prod"}).Error)
+
+	var host schemaTestHost
+	suite.NoError(tx.First(&host, "agent_id = ?", "agent1").Error)
+	suite.Equal("host1", host.Name)
+
+	// the raw table names are resolved through the search path
+	var names []string
+	suite.NoError(tx.Table("schema_test_hosts").
+		Joins("JOIN schema_test_tags ON schema_test_tags.agent_id = schema_test_hosts.agent_id").
+		Where("schema_test_tags.value = ?", "prod").
+		Pluck("schema_test_hosts.name", &names).Error)
+	suite.Equal([]string{"host1"}, names)
+}
@@ -0,0 +1,143 @@
+// Package delta computes and applies the differential payloads the agents send in place of the full discovered data,
+// when only a small part of a big discovery changed. A delta is a JSON merge patch (RFC 7386) of the last payload
+// the collector stored, identified by its Hash
+package delta
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// ErrNotRepresentable is returned when the changes cannot be expressed as a merge patch,
+// as a JSON merge patch removes the members set to null. The full payload is to be sent instead
+var ErrNotRepresentable = errors.New("the changes cannot be represented as a merge patch")
+
+// Hash returns the SHA-256 of the payload, once its JSON keys are sorted, or as it is if it is not JSON
+func Hash(payload []byte) string {
+	var document interface{}
+
+	if err := decode(payload, &document); err == nil {
+		if canonical, err := json.Marshal(document); err == nil {
+			payload = canonical
+		}
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Diff returns the merge patch turning the base payload into the current one
+func Diff(base []byte, current []byte) ([]byte, error) {
+	var baseDocument, currentDocument interface{}
+	if err := decode(base, &baseDocument); err != nil {
+		return nil, err
+	}
+	if err := decode(current, &currentDocument); err != nil {
+		return nil, err
+	}
+
+	patch, err := diff(baseDocument, currentDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(patch)
+}
+
+// Apply returns the payload the merge patch turns the base payload into
+func Apply(base []byte, patch []byte) ([]byte, error) {
+	var baseDocument, patchDocument interface{}
+	if err := decode(base, &baseDocument); err != nil {
+		return nil, err
+	}
+	if err := decode(patch, &patchDocument); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(apply(baseDocument, patchDocument))
+}
+
+func apply(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = apply(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+func diff(base interface{}, current interface{}) (interface{}, error) {
+	baseObject, baseIsObject := base.(map[string]interface{})
+	currentObject, currentIsObject := current.(map[string]interface{})
+	if !baseIsObject || !currentIsObject {
+		if containsNull(current) {
+			return nil, ErrNotRepresentable
+		}
+		return current, nil
+	}
+
+	patch := map[string]interface{}{}
+	for key := range baseObject {
+		if _, ok := currentObject[key]; !ok {
+			patch[key] = nil
+		}
+	}
+
+	for key, value := range currentObject {
+		baseValue, ok := baseObject[key]
+		if ok && reflect.DeepEqual(baseValue, value) {
+			continue
+		}
+
+		if !ok {
+			baseValue = nil
+		}
+		changed, err := diff(baseValue, value)
+		if err != nil {
+			return nil, err
+		}
+		patch[key] = changed
+	}
+
+	return patch, nil
+}
+
+// containsNull tells whether a value replaced as a whole holds null members, which the merge patch would drop
+func containsNull(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		for _, member := range v {
+			if containsNull(member) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decode(payload []byte, document *interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	return decoder.Decode(document)
+}
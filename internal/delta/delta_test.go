@@ -0,0 +1,71 @@
+package delta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, Hash([]byte(`{"a":1,"b":[1,2]}`)), Hash([]byte(`{ "b": [1, 2], "a": 1 }`)))
+	assert.NotEqual(t, Hash([]byte(`{"a":1,"b":[1,2]}`)), Hash([]byte(`{"a":1,"b":[2,1]}`)))
+	assert.Equal(t, Hash([]byte(`{"a":12345678901234567890}`)), Hash([]byte(`{"a":12345678901234567890}`)))
+	assert.NotEqual(t, Hash([]byte(`{"a":12345678901234567890}`)), Hash([]byte(`{"a":12345678901234567891}`)))
+	assert.NotEqual(t, Hash([]byte("not json")), Hash([]byte("not json either")))
+}
+
+func TestApply(t *testing.T) {
+	// the examples of RFC 7386
+	for _, example := range []struct{ base, patch, expected string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		patched, err := Apply([]byte(example.base), []byte(example.patch))
+		assert.NoError(t, err)
+		assert.JSONEq(t, example.expected, string(patched))
+	}
+
+	_, err := Apply([]byte(`{"a":1}`), []byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	base := `{"cib":{"nodes":[{"id":"1"},{"id":"2"}],"resources":{"rsc1":{"role":"Started"},"rsc2":{"role":"Started"}}},"name":"cluster1","dc":true}`
+	current := `{"cib":{"nodes":[{"id":"1"},{"id":"2"}],"resources":{"rsc1":{"role":"Stopped"},"rsc3":{"role":"Started"}}},"name":"cluster1"}`
+
+	patch, err := Diff([]byte(base), []byte(current))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cib":{"resources":{"rsc1":{"role":"Stopped"},"rsc2":null,"rsc3":{"role":"Started"}}},"dc":null}`, string(patch))
+
+	patched, err := Apply([]byte(base), patch)
+	assert.NoError(t, err)
+	assert.JSONEq(t, current, string(patched))
+	assert.Equal(t, Hash([]byte(current)), Hash(patched))
+
+	patch, err = Diff([]byte(base), []byte(base))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(patch))
+
+	_, err = Diff([]byte(`{"a":1}`), []byte(`{"a":null}`))
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+	_, err = Diff([]byte(`{"a":1}`), []byte(`{"a":{"b":null}}`))
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+
+	// the arrays are replaced as a whole, their null items are kept
+	patch, err = Diff([]byte(`{"a":[1]}`), []byte(`{"a":[null,{"b":null}]}`))
+	assert.NoError(t, err)
+	patched, err = Apply([]byte(`{"a":[1]}`), patch)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":[null,{"b":null}]}`, string(patched))
+}
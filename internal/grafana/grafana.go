@@ -15,6 +15,8 @@ import (
 	"github.com/avast/retry-go/v4"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal/proxy"
 )
 
 //go:embed node_exporter.json
@@ -34,11 +36,13 @@ func (config Config) BaseUrl() string {
 	return config.ApiURL
 }
 
-func InitGrafana(ctx context.Context, config *Config) error {
+func InitGrafana(ctx context.Context, config *Config, proxyConfig *proxy.Config) error {
+	client := proxy.NewHTTPClient(proxyConfig, 0)
+
 	return retry.Do(
 		func() error {
 			log.Info("Initializing Grafana Dashboards")
-			token, err := createToken(config)
+			token, err := createToken(client, config)
 			if err != nil {
 				log.Error("Failed to create Grafana token")
 				return err
@@ -53,7 +57,6 @@ func InitGrafana(ctx context.Context, config *Config) error {
 			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
 			req.Header.Add("Content-Type", "application/json")
 
-			client := &http.Client{}
 			resp, err := client.Do(req)
 
 			if resp.StatusCode != http.StatusOK {
@@ -74,7 +77,7 @@ func InitGrafana(ctx context.Context, config *Config) error {
 	)
 }
 
-func createToken(config *Config) (string, error) {
+func createToken(client *http.Client, config *Config) (string, error) {
 	tokenName, err := uuid.NewUUID()
 	if err != nil {
 		return "", err
@@ -97,7 +100,6 @@ func createToken(config *Config) (string, error) {
 	}
 
 	req.Header.Add("Content-Type", "application/json")
-	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
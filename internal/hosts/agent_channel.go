@@ -0,0 +1,42 @@
+package hosts
+
+import "encoding/json"
+
+// The messages exchanged over the channel between the agents and the server, at /api/agent/ws on the collector
+const (
+	// AgentMessageConfigChanged tells the agent to pull its configuration again
+	AgentMessageConfigChanged = "config_changed"
+	// AgentMessageDiscoveryRequested asks the agent to run a discovery right away
+	AgentMessageDiscoveryRequested = "discovery_requested"
+	// AgentMessageChecksRunRequested asks the agent to run the checks selected for its cluster
+	AgentMessageChecksRunRequested = "checks_run_requested"
+	// AgentMessageUpgradeRequested asks the agent to upgrade itself to the given release
+	AgentMessageUpgradeRequested = "upgrade_requested"
+	// AgentMessageAck is sent by the agent once a message is handled, with the error if it failed
+	AgentMessageAck = "ack"
+)
+
+// AgentMessage is a message of the channel, the payload depending on its type
+type AgentMessage struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// Error of the handling of the message acknowledged, empty if it succeeded
+	Error string `json:"error,omitempty"`
+}
+
+type DiscoveryRequest struct {
+	DiscoveryType string `json:"discovery_type"`
+}
+
+type ChecksRunRequest struct {
+	ClusterID string   `json:"cluster_id"`
+	Checks    []string `json:"checks"`
+}
+
+type UpgradeRequest struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	// Checksum is the SHA-256 of the release, hex encoded
+	Checksum string `json:"checksum"`
+}
@@ -0,0 +1,9 @@
+package hosts
+
+// LogsRequestedHeader is set in the response to the heartbeat when the agent is asked to ship its logs
+const LogsRequestedHeader = "X-Trento-Logs-Requested"
+
+// AgentLogs is an excerpt of the recent logs shipped by the agents on demand, at /api/hosts/:id/logs
+type AgentLogs struct {
+	Logs string `json:"logs"`
+}
@@ -0,0 +1,12 @@
+package hosts
+
+// AgentRegistration is reported by the agents when they start, at /api/hosts/:id/register
+type AgentRegistration struct {
+	Version string `json:"version"`
+	// Discoveries the agent runs
+	Discoveries   []string `json:"discoveries"`
+	OSName        string   `json:"os_name"`
+	OSVersion     string   `json:"os_version"`
+	KernelVersion string   `json:"kernel_version"`
+	Arch          string   `json:"arch"`
+}
@@ -1,5 +1,9 @@
 package hosts
 
+// AgentProfileBasic agents only report the heartbeats and the basic host information,
+// to track the availability of hosts not running SAP workloads
+const AgentProfileBasic = "basic"
+
 type DiscoveredHost struct {
 	SSHAddress      string   `json:"ssh_address"`
 	OSVersion       string   `json:"os_version"`
@@ -7,6 +11,31 @@ type DiscoveredHost struct {
 	HostName        string   `json:"hostname"`
 	CPUCount        int      `json:"cpu_count"`
 	SocketCount     int      `json:"socket_count"`
+	CoreCount       int      `json:"core_count"`
 	TotalMemoryMB   int      `json:"total_memory_mb"`
-	AgentVersion    string   `json:"agent_version"`
+	// Virtualization system the host runs on, empty on bare metal
+	Hypervisor   string `json:"hypervisor"`
+	AgentVersion string `json:"agent_version"`
+	// Ephemeral hosts are expected to go away, like auto-scaled application servers
+	Ephemeral bool `json:"ephemeral"`
+	// Profile of the agent, empty when it runs the full discovery
+	Profile string `json:"profile,omitempty"`
+	// Utilization is nil when the agent does not report it
+	Utilization *HostUtilization `json:"utilization,omitempty"`
+	// Provisioning is nil when the host was not deployed by a known automation
+	Provisioning *Provisioning `json:"provisioning,omitempty"`
+}
+
+// Provisioning describes the automation that deployed the host
+type Provisioning struct {
+	Tool            string `json:"tool"`
+	TemplateVersion string `json:"template_version"`
+}
+
+// HostUtilization is a snapshot of the resources usage of the host, at discovery time
+type HostUtilization struct {
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryPercent float64 `json:"memory_percent"`
+	// Usage of the fullest local filesystem
+	DiskPercent float64 `json:"disk_percent"`
 }
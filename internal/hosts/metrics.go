@@ -0,0 +1,10 @@
+package hosts
+
+import "time"
+
+// MetricsSample is a resources usage sample of the host, pushed by the agent along with the other ones
+// taken since its last push
+type MetricsSample struct {
+	Time time.Time `json:"time"`
+	HostUtilization
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"net"
+	"strings"
+)
+
+// ParseIPAddress parses an IPv4 or IPv6 address, the latter optionally enclosed in brackets
+// and with a zone ID, e.g. [fe80::1%eth0]. The zone ID is returned apart, as net.ParseIP rejects it
+func ParseIPAddress(address string) (net.IP, string) {
+	address = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(address), "["), "]")
+
+	var zone string
+	if i := strings.LastIndex(address, "%"); i >= 0 && strings.Contains(address, ":") {
+		address, zone = address[:i], address[i+1:]
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, ""
+	}
+
+	return ip, zone
+}
+
+// NormalizeIPAddress returns the canonical form of an IP address, so that the same address
+// reported in different forms compares equal, e.g. 2001:db8::1 for 2001:DB8:0:0::1.
+// The zone ID is kept, an empty string is returned if the address is not valid
+func NormalizeIPAddress(address string) string {
+	ip, zone := ParseIPAddress(address)
+	if ip == nil {
+		return ""
+	}
+
+	if zone != "" {
+		return ip.String() + "%" + zone
+	}
+
+	return ip.String()
+}
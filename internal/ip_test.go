@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIPAddress(t *testing.T) {
+	ip, zone := ParseIPAddress("fe80::1%eth0")
+	assert.Equal(t, "fe80::1", ip.String())
+	assert.Equal(t, "eth0", zone)
+
+	ip, zone = ParseIPAddress("[2001:db8::1]")
+	assert.Equal(t, "2001:db8::1", ip.String())
+	assert.Equal(t, "", zone)
+
+	ip, _ = ParseIPAddress("10.1.1.1")
+	assert.Equal(t, "10.1.1.1", ip.String())
+
+	ip, _ = ParseIPAddress("10.1.1.1%eth0")
+	assert.Nil(t, ip)
+
+	ip, _ = ParseIPAddress("not_valid")
+	assert.Nil(t, ip)
+}
+
+func TestNormalizeIPAddress(t *testing.T) {
+	assert.Equal(t, "2001:db8::1", NormalizeIPAddress("2001:DB8:0:0::1"))
+	assert.Equal(t, "fe80::1%eth0", NormalizeIPAddress("FE80:0::1%eth0"))
+	assert.Equal(t, "10.1.1.1", NormalizeIPAddress("::ffff:10.1.1.1"))
+	assert.Equal(t, "10.1.1.1", NormalizeIPAddress(" 10.1.1.1 "))
+	assert.Equal(t, "", NormalizeIPAddress("not_valid"))
+}
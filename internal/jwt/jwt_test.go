@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	secret := []byte("secret")
+
+	token, err := Sign(&Claims{
+		Subject:   "agent1",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	}, secret)
+	assert.NoError(t, err)
+
+	claims, err := Verify(token, secret, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, "agent1", claims.Subject)
+	assert.Equal(t, now.Add(time.Hour), claims.ExpirationTime().UTC())
+
+	_, err = Verify(token, secret, now.Add(time.Hour))
+	assert.Equal(t, ErrExpiredToken, err)
+
+	_, err = Verify(token, []byte("other secret"), now)
+	assert.Equal(t, ErrInvalidToken, err)
+}
+
+func TestVerifyTampered(t *testing.T) {
+	now := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	secret := []byte("secret")
+
+	token, _ := Sign(&Claims{Subject: "agent1", ExpiresAt: now.Add(time.Hour).Unix()}, secret)
+	other, _ := Sign(&Claims{Subject: "agent2", ExpiresAt: now.Add(time.Hour).Unix()}, []byte("other secret"))
+
+	parts := strings.Split(token, ".")
+	otherParts := strings.Split(other, ".")
+
+	for _, tampered := range []string{
+		parts[0] + "." + otherParts[1] + "." + parts[2],
+		"eyJhbGciOiJub25lIn0." + parts[1] + ".",
+		parts[0] + "." + parts[1],
+		"",
+	} {
+		_, err := Verify(tampered, secret, now)
+		assert.Equal(t, ErrInvalidToken, err, tampered)
+	}
+}
@@ -0,0 +1,109 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"os/exec"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:generate mockery --all
+
+const (
+	// Pods running SAP components are identified by these labels
+	SIDLabel            = "trento.io/sap-sid"
+	InstanceNumberLabel = "trento.io/sap-instance-number"
+	TypeLabel           = "trento.io/sap-type"
+	ComponentLabel      = "trento.io/sap-component"
+
+	WorkloadTypeApplication = "application"
+	WorkloadTypeDatabase    = "database"
+)
+
+type Workloads []*Workload
+
+// Workload is a pod running a SAP component on the Kubernetes node of the discovering host
+type Workload struct {
+	Namespace      string `json:"namespace"`
+	Pod            string `json:"pod"`
+	Node           string `json:"node"`
+	SID            string `json:"sid"`
+	InstanceNumber string `json:"instance_number"`
+	Type           string `json:"type"`
+	Component      string `json:"component"`
+	Image          string `json:"image"`
+	Phase          string `json:"phase"`
+}
+
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string            `json:"name"`
+			Namespace string            `json:"namespace"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			NodeName   string `json:"nodeName"`
+			Containers []struct {
+				Image string `json:"image"`
+			} `json:"containers"`
+		} `json:"spec"`
+		Status struct {
+			Phase string `json:"phase"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type CustomCommand func(name string, arg ...string) *exec.Cmd
+
+var customExecCommand CustomCommand = exec.Command
+
+// NewWorkloads lists the SAP workloads scheduled on the given Kubernetes node,
+// an empty list is returned if kubectl is not available on the host
+func NewWorkloads(node string) (Workloads, error) {
+	workloads := Workloads{}
+
+	cmd := customExecCommand("kubectl", "get", "pods", "--all-namespaces", "-l", SIDLabel,
+		"--field-selector", "spec.nodeName="+node, "-o", "json")
+	if errors.Is(cmd.Err, exec.ErrNotFound) {
+		log.Debugf("kubectl not found, no Kubernetes workloads discovered")
+		return workloads, nil
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "error while listing the Kubernetes pods")
+	}
+
+	var pods podList
+	err = json.Unmarshal(output, &pods)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while decoding the Kubernetes pods")
+	}
+
+	for _, pod := range pods.Items {
+		workload := &Workload{
+			Namespace:      pod.Metadata.Namespace,
+			Pod:            pod.Metadata.Name,
+			Node:           pod.Spec.NodeName,
+			SID:            pod.Metadata.Labels[SIDLabel],
+			InstanceNumber: pod.Metadata.Labels[InstanceNumberLabel],
+			Type:           pod.Metadata.Labels[TypeLabel],
+			Component:      pod.Metadata.Labels[ComponentLabel],
+			Phase:          pod.Status.Phase,
+		}
+
+		if workload.Type != WorkloadTypeDatabase {
+			workload.Type = WorkloadTypeApplication
+		}
+
+		if len(pod.Spec.Containers) > 0 {
+			workload.Image = pod.Spec.Containers[0].Image
+		}
+
+		workloads = append(workloads, workload)
+	}
+
+	return workloads, nil
+}
@@ -0,0 +1,99 @@
+package kubernetes
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/internal/kubernetes/mocks"
+)
+
+func mockKubectl() *exec.Cmd {
+	return exec.Command("echo", `{"items":[
+    {"metadata":{"name":"prd-ascs-0","namespace":"sap-prd","labels":{"trento.io/sap-sid":"PRD","trento.io/sap-instance-number":"00","trento.io/sap-component":"ASCS"}},
+    "spec":{"nodeName":"node1","containers":[{"image":"registry.example.com/sap/ascs:7.53"},{"image":"sidecar"}]},
+    "status":{"phase":"Running"}},
+    {"metadata":{"name":"hdb-0","namespace":"sap-prd","labels":{"trento.io/sap-sid":"HDB","trento.io/sap-instance-number":"10","trento.io/sap-type":"database"}},
+    "spec":{"nodeName":"node1","containers":[{"image":"registry.example.com/sap/hana:2.0"}]},
+    "status":{"phase":"Pending"}}]}`)
+}
+
+func mockKubectlNotFound() *exec.Cmd {
+	return exec.Command("kubectl-not-installed")
+}
+
+func mockKubectlErr() *exec.Cmd {
+	return exec.Command("false")
+}
+
+func TestNewWorkloads(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "kubectl", "get", "pods", "--all-namespaces", "-l", "trento.io/sap-sid",
+		"--field-selector", "spec.nodeName=node1", "-o", "json").Return(
+		mockKubectl(),
+	)
+
+	workloads, err := NewWorkloads("node1")
+
+	expectedWorkloads := Workloads{
+		&Workload{
+			Namespace:      "sap-prd",
+			Pod:            "prd-ascs-0",
+			Node:           "node1",
+			SID:            "PRD",
+			InstanceNumber: "00",
+			Type:           "application",
+			Component:      "ASCS",
+			Image:          "registry.example.com/sap/ascs:7.53",
+			Phase:          "Running",
+		},
+		&Workload{
+			Namespace:      "sap-prd",
+			Pod:            "hdb-0",
+			Node:           "node1",
+			SID:            "HDB",
+			InstanceNumber: "10",
+			Type:           "database",
+			Image:          "registry.example.com/sap/hana:2.0",
+			Phase:          "Pending",
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedWorkloads, workloads)
+}
+
+func TestNewWorkloadsKubectlNotFound(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "kubectl", "get", "pods", "--all-namespaces", "-l", "trento.io/sap-sid",
+		"--field-selector", "spec.nodeName=node1", "-o", "json").Return(
+		mockKubectlNotFound(),
+	)
+
+	workloads, err := NewWorkloads("node1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, Workloads{}, workloads)
+}
+
+func TestNewWorkloadsErr(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "kubectl", "get", "pods", "--all-namespaces", "-l", "trento.io/sap-sid",
+		"--field-selector", "spec.nodeName=node1", "-o", "json").Return(
+		mockKubectlErr(),
+	)
+
+	workloads, err := NewWorkloads("node1")
+
+	assert.Nil(t, workloads)
+	assert.EqualError(t, err, "error while listing the Kubernetes pods: exit status 1")
+}
@@ -11,13 +11,17 @@ import (
 	"github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal/proxy"
 )
 
 //go:generate mockery --name=PrometheusAPI --inpackage --filename=prometheus_mock.go
 
-func InitPrometheus(ctx context.Context, url string) (PrometheusAPI, error) {
+func InitPrometheus(ctx context.Context, url string, proxyConfig *proxy.Config) (PrometheusAPI, error) {
+	transport := proxy.NewTransport(proxyConfig)
 	client, err := api.NewClient(api.Config{
-		Address: url,
+		Address:      url,
+		RoundTripper: transport,
 	})
 	if err != nil {
 		log.Errorf("Error creating client: %v\n", err)
@@ -28,9 +32,10 @@ func InitPrometheus(ctx context.Context, url string) (PrometheusAPI, error) {
 	promApi := NewPrometheusAPI(v1api)
 
 	healthyURL := fmt.Sprintf("%s/-/healthy", url)
+	httpClient := &http.Client{Transport: transport}
 	err = retry.Do(
 		func() error {
-			resp, err := http.Get(healthyURL)
+			resp, err := httpClient.Get(healthyURL)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+const (
+	// Direct, as the proxy URL, bypasses the proxy, even the one set in the environment
+	Direct = "direct"
+
+	Telemetry  = "telemetry"
+	Grafana    = "grafana"
+	Prometheus = "prometheus"
+)
+
+// Config of the proxy the outbound HTTP requests go through.
+// Without URL the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are honored
+type Config struct {
+	URL string
+	// NoProxy lists the comma-separated hosts, domains and subnets reached directly
+	NoProxy string
+	// Username and Password authenticate to the proxy, in place of the ones in the URL, if any
+	Username string
+	Password string
+	// Overrides are the proxy URLs of the integrations not going through the default one, by integration
+	Overrides map[string]string
+}
+
+// For returns the configuration of the proxy of an integration, applying its override if any
+func (c *Config) For(integration string) *Config {
+	if c == nil {
+		return nil
+	}
+
+	config := *c
+	if override, ok := c.Overrides[integration]; ok && override != "" {
+		config.URL = override
+	}
+	config.Overrides = nil
+
+	return &config
+}
+
+// Validate checks that the proxy URLs are either absolute or Direct
+func (c *Config) Validate() error {
+	urls := map[string]string{"proxy": c.URL}
+	for integration, override := range c.Overrides {
+		urls[integration+" proxy"] = override
+	}
+
+	for name, proxyURL := range urls {
+		if proxyURL == "" || proxyURL == Direct {
+			continue
+		}
+
+		parsed, err := url.Parse(proxyURL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("invalid %s URL %s", name, proxyURL)
+		}
+	}
+
+	return nil
+}
+
+// ProxyFunc returns the proxy of every request, as expected by http.Transport
+func (c *Config) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	if c == nil {
+		return http.ProxyFromEnvironment
+	}
+
+	if c.URL == Direct {
+		return nil
+	}
+
+	proxyConfig := httpproxy.FromEnvironment()
+	if c.URL != "" {
+		proxyConfig.HTTPProxy = c.URL
+		proxyConfig.HTTPSProxy = c.URL
+	}
+	if c.NoProxy != "" {
+		proxyConfig.NoProxy = c.NoProxy
+	}
+	proxyFunc := proxyConfig.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxyFunc(req.URL)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+
+		if c.Username != "" {
+			proxyURL.User = url.UserPassword(c.Username, c.Password)
+		}
+
+		return proxyURL, nil
+	}
+}
+
+// NewTransport returns a transport with the default settings going through the proxy
+func NewTransport(config *Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = config.ProxyFunc()
+
+	return transport
+}
+
+// NewHTTPClient returns a client going through the proxy, a timeout of 0 means no timeout
+func NewHTTPClient(config *Config, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: NewTransport(config),
+		Timeout:   timeout,
+	}
+}
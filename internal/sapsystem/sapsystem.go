@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -64,6 +65,8 @@ type SAPSystem struct {
 	Instances map[string]*SAPInstance `mapstructure:"instances,omitempty"`
 	// Only for Database type
 	Databases []*DatabaseData `mapstructure:"databases,omitempty"`
+	// Only for Database type, 0 when HANA uses its default allocation limit
+	GlobalAllocationLimitMB int64 `mapstructure:"global_allocation_limit_mb,omitempty"`
 	// Only for Application type
 	DBAddress string `mapstructure:"db_address,omitempty"`
 }
@@ -213,6 +216,13 @@ func NewSAPSystem(fs afero.Fs, sysPath string) (*SAPSystem, error) {
 		} else {
 			system.Databases = databaseList
 		}
+
+		limit, err := getGlobalAllocationLimit(fs, system.SID)
+		if err != nil {
+			log.Printf("Error getting the global allocation limit: %s", err)
+		} else {
+			system.GlobalAllocationLimitMB = limit
+		}
 	case Application:
 		addr, err := getDBAddress(system)
 		if err != nil {
@@ -313,16 +323,20 @@ func getDBAddress(system *SAPSystem) (string, error) {
 		return "", fmt.Errorf("could not resolve \"%s\" hostname", sapdbhost)
 	}
 
-	// Get 1st IPv4 address
+	// Get 1st IPv4 address, the 1st IPv6 one on IPv6 only networks
 	for _, addr := range addrList {
-		addrStr := addr.String()
-		ip := net.ParseIP(addrStr)
-		if ip.To4() != nil {
-			return addrStr, nil
+		if addr.To4() != nil {
+			return addr.String(), nil
 		}
 	}
 
-	return "", fmt.Errorf("could not get any IPv4 address")
+	for _, addr := range addrList {
+		if !addr.IsLinkLocalUnicast() {
+			return addr.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("could not get any IP address")
 }
 
 func setSystemId(fs afero.Fs, system *SAPSystem) (*SAPSystem, error) {
@@ -441,6 +455,34 @@ func getDatabases(fs afero.Fs, sid string) ([]*DatabaseData, error) {
 	return databaseList, nil
 }
 
+// getGlobalAllocationLimit reads the memory limit, in MB, set in the memorymanager section of the global.ini file.
+// It returns 0 if the limit is not customized
+func getGlobalAllocationLimit(fs afero.Fs, sid string) (int64, error) {
+	globalConfigPath := fmt.Sprintf(
+		"/usr/sap/%s/SYS/global/hdb/custom/config/global.ini", sid)
+
+	globalConfigRaw, err := afero.ReadFile(fs, globalConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("could not read the global configuration file %s", err)
+	}
+
+	configMap := internal.FindMatches(`([\w\/]+)\s=\s(.+)`, globalConfigRaw)
+	limit, found := configMap["global_allocation_limit"]
+	if !found {
+		return 0, nil
+	}
+
+	limitMB, err := strconv.ParseInt(strings.TrimSpace(fmt.Sprint(limit)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid global allocation limit %v", limit)
+	}
+
+	return limitMB, nil
+}
+
 func NewSAPInstance(w sapcontrol.WebService) (*SAPInstance, error) {
 	host, _ := os.Hostname()
 	var sapInstance = &SAPInstance{
@@ -273,6 +273,43 @@ ERR:::
 	assert.ElementsMatch(t, expectedDbs, dbs)
 }
 
+func TestGetGlobalAllocationLimit(t *testing.T) {
+	appFS := afero.NewMemMapFs()
+	appFS.MkdirAll("/usr/sap/PRD/SYS/global/hdb/custom/config/", 0755)
+
+	globalContent := []byte(`
+[persistence]
+basepath_datavolumes = /hana/data/PRD
+
+[memorymanager]
+global_allocation_limit = 65536
+`)
+
+	afero.WriteFile(
+		appFS, "/usr/sap/PRD/SYS/global/hdb/custom/config/global.ini",
+		globalContent, 0644)
+
+	limit, err := getGlobalAllocationLimit(appFS, "PRD")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 65536, limit)
+}
+
+func TestGetGlobalAllocationLimitDefault(t *testing.T) {
+	appFS := afero.NewMemMapFs()
+
+	limit, err := getGlobalAllocationLimit(appFS, "PRD")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, limit)
+
+	appFS.MkdirAll("/usr/sap/PRD/SYS/global/hdb/custom/config/", 0755)
+	afero.WriteFile(
+		appFS, "/usr/sap/PRD/SYS/global/hdb/custom/config/global.ini",
+		[]byte("[memorymanager]\nglobal_allocation_limit = lots\n"), 0644)
+
+	_, err = getGlobalAllocationLimit(appFS, "PRD")
+	assert.EqualError(t, err, "invalid global allocation limit lots")
+}
+
 func TestGetDBAddress(t *testing.T) {
 	s := &SAPSystem{Profile: SAPProfile{"SAPDBHOST": "localhost"}}
 	addr, err := getDBAddress(s)
@@ -0,0 +1,69 @@
+// Package signing signs and verifies the payloads the agents send to the collector,
+// with an HMAC-SHA256 of the request timestamp and body computed with the secret shared by the agent and the server
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the signature, as sha256=<hex encoded HMAC>
+	SignatureHeader = "X-Trento-Signature"
+	// TimestampHeader carries the Unix time the request was signed at, covered by the signature
+	TimestampHeader = "X-Trento-Timestamp"
+	// MaxClockSkew between the agents and the server, the requests signed earlier are refused as replays
+	MaxClockSkew = 5 * time.Minute
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpiredSignature = errors.New("signature expired")
+)
+
+// Sign returns the signature of the body sent at the timestamp
+func Sign(secret string, timestamp time.Time, body []byte) (string, string) {
+	unixTimestamp := strconv.FormatInt(timestamp.Unix(), 10)
+
+	return unixTimestamp, signaturePrefix + hex.EncodeToString(mac(secret, unixTimestamp, body))
+}
+
+// Verify checks the signature of the body, and that it was signed within MaxClockSkew of now
+func Verify(secret string, timestamp string, signature string, body []byte, now time.Time) error {
+	unixTimestamp, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+
+	decoded, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil || !hmac.Equal(decoded, mac(secret, timestamp, body)) {
+		return ErrInvalidSignature
+	}
+
+	skew := now.Sub(time.Unix(unixTimestamp, 0))
+	if skew > MaxClockSkew || skew < -MaxClockSkew {
+		return ErrExpiredSignature
+	}
+
+	return nil
+}
+
+func mac(secret string, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,35 @@
+package signing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"agent_id": "agent1"}`)
+
+	timestamp, signature := Sign("secret", now, body)
+	assert.Equal(t, "1647079200", timestamp)
+
+	assert.NoError(t, Verify("secret", timestamp, signature, body, now.Add(time.Minute)))
+	assert.Equal(t, ErrExpiredSignature, Verify("secret", timestamp, signature, body, now.Add(time.Hour)))
+	assert.Equal(t, ErrExpiredSignature, Verify("secret", timestamp, signature, body, now.Add(-time.Hour)))
+	assert.Equal(t, ErrInvalidSignature, Verify("other secret", timestamp, signature, body, now))
+}
+
+func TestVerifyTampered(t *testing.T) {
+	now := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"agent_id": "agent1"}`)
+
+	timestamp, signature := Sign("secret", now, body)
+
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", timestamp, signature, []byte(`{"agent_id": "agent2"}`), now))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", "1647079201", signature, body, now))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", "", signature, body, now))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", timestamp, signature[len("sha256="):], body, now))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", timestamp, "sha256=zz", body, now))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", timestamp, "", body, now))
+}
@@ -45,6 +45,9 @@ class Results(object):
                         "host1": {
                             "result": "passing",
                             "msg": "",
+                            "fact_values": {
+                                "corosync_token": "30000"
+                            }
                         }
                     }
                 }
@@ -64,9 +67,9 @@ class Results(object):
             self.results["results"][group]["hosts"] = {}
             self.results["results"][group]["checks"] = {}
 
-    def add_result(self, group, test, host, result, msg=""):
+    def add_result(self, group, test, host, result, msg="", fact_values=None):
         """
-        Add new result
+        Add new result, with the values of the facts discovered by the check
         """
         # Add the group just in case it doesn't exist
         if group not in self.results["results"]:
@@ -84,6 +87,9 @@ class Results(object):
 
         hosts[host]["result"] = result
         hosts[host]["msg"] = msg
+        if fact_values:
+            hosts[host]["fact_values"] = {
+                str(name): str(value) for name, value in fact_values.items()}
 
     def result_exist(self, group, test, host):
         """
@@ -127,6 +133,10 @@ class CallbackModule(CallbackBase):
         host = os.getenv('TRENTO_WEB_API_HOST')
         port = os.getenv('TRENTO_WEB_API_PORT')
         self._trento_api_url = "http://{}:{}".format(host, port)
+        self._trento_api_headers = {}
+        api_key = os.getenv('TRENTO_WEB_API_KEY')
+        if api_key:
+            self._trento_api_headers["Authorization"] = "Bearer {}".format(api_key)
 
     def v2_playbook_on_start(self, playbook):
         """
@@ -157,11 +167,13 @@ class CallbackModule(CallbackBase):
         task_vars = self._all_vars(host=result._host, task=result._task)
 
         test_result = result._task_fields["args"]["test_result"]
+        test_fact_values = result._task_fields["args"].get("test_fact_values")
         for group in task_vars["group_names"]:
             self.results.set_host_state(group, host, True)
             if self.results.result_exist(group, task_vars[CHECK_ID], host):
                 continue
-            self.results.add_result(group, task_vars[CHECK_ID], host, test_result)
+            self.results.add_result(
+                group, task_vars[CHECK_ID], host, test_result, fact_values=test_fact_values)
 
     def v2_runner_on_failed(self, result, ignore_errors):
         """
@@ -284,6 +296,6 @@ class CallbackModule(CallbackBase):
         """
         for key, group in results["results"].items():
             url = "{}/api/checks/{}/results".format(self._trento_api_url, key)
-            response = requests.post(url, json=group)
+            response = requests.post(url, json=group, headers=self._trento_api_headers)
             self._display.banner(
                 "Results of {} published. Return code is: {}".format(key, response.status_code))
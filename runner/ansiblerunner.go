@@ -12,6 +12,7 @@ import (
 const (
 	TrentoWebApiHost     = "TRENTO_WEB_API_HOST"
 	TrentoWebApiPort     = "TRENTO_WEB_API_PORT"
+	TrentoWebApiKey      = "TRENTO_WEB_API_KEY"
 	AnsibleConfigFileEnv = "ANSIBLE_CONFIG"
 )
 
@@ -64,9 +65,10 @@ func (a *AnsibleRunner) SetConfigFile(confFile string) {
 	a.setEnv(AnsibleConfigFileEnv, confFile)
 }
 
-func (a *AnsibleRunner) SetTrentoApiData(host string, port int) {
+func (a *AnsibleRunner) SetTrentoApiData(host string, port int, apiKey string) {
 	a.setEnv(TrentoWebApiHost, host)
 	a.setEnv(TrentoWebApiPort, fmt.Sprintf("%d", port))
+	a.setEnv(TrentoWebApiKey, apiKey)
 }
 
 func (a *AnsibleRunner) RunPlaybook() error {
@@ -2,7 +2,9 @@ package runner
 
 import (
 	"encoding/json"
+	"io/fs"
 	"os"
+	"path"
 	"text/template"
 
 	log "github.com/sirupsen/logrus"
@@ -40,6 +42,9 @@ const (
 `
 	DefaultUser           string = "root"
 	clusterSelectedChecks string = "cluster_selected_checks"
+	cloudProvider         string = "cloud_provider"
+	// checksEnvironment selects the expected values of the checks, in ansible/vars
+	checksEnvironment string = "env"
 )
 
 func CreateInventory(destination string, content *InventoryContent) error {
@@ -84,6 +89,12 @@ func NewClusterInventoryContent(trentoApi api.TrentoApiService) (*InventoryConte
 			}
 
 			node.Variables[clusterSelectedChecks] = string(jsonSelectedChecks)
+			if host.CloudProvider != "" {
+				node.Variables[cloudProvider] = host.CloudProvider
+				if hasChecksEnvironment(host.CloudProvider) {
+					node.Variables[checksEnvironment] = host.CloudProvider
+				}
+			}
 
 			nodes = append(nodes, node)
 		}
@@ -94,3 +105,10 @@ func NewClusterInventoryContent(trentoApi api.TrentoApiService) (*InventoryConte
 
 	return content, nil
 }
+
+// hasChecksEnvironment tells whether the checks have expected values specific to the environment,
+// the default ones being used otherwise
+func hasChecksEnvironment(env string) bool {
+	info, err := fs.Stat(ansibleFS, path.Join("ansible/vars", env))
+	return err == nil && info.IsDir()
+}
@@ -131,6 +131,8 @@ func (suite *InventoryTestSuite) Test_NewClusterInventoryContent() {
 						Name: "node3",
 						Variables: map[string]interface{}{
 							"cluster_selected_checks": "[\"check3\",\"check4\"]",
+							"cloud_provider":          "azure",
+							"env":                     "azure",
 						},
 						AnsibleHost: "192.168.10.3",
 						AnsibleUser: "clouduser",
@@ -139,6 +141,7 @@ func (suite *InventoryTestSuite) Test_NewClusterInventoryContent() {
 						Name: "node4",
 						Variables: map[string]interface{}{
 							"cluster_selected_checks": "[\"check3\",\"check4\"]",
+							"cloud_provider":          "aws",
 						},
 						AnsibleHost: "",
 						AnsibleUser: "root",
@@ -176,14 +179,16 @@ func mockedClustersSettings() webApi.ClustersSettingsResponse {
 			SelectedChecks: []string{"check3", "check4"},
 			Hosts: []*models.HostConnection{
 				{
-					Name:    "node3",
-					Address: "192.168.10.3",
-					User:    "clouduser",
+					Name:          "node3",
+					Address:       "192.168.10.3",
+					User:          "clouduser",
+					CloudProvider: "azure",
 				},
 				{
-					Name:    "node4",
-					Address: "",
-					User:    "root",
+					Name:          "node4",
+					Address:       "",
+					User:          "root",
+					CloudProvider: "aws",
 				},
 			},
 		},
@@ -21,10 +21,11 @@ import (
 var ansibleFS embed.FS
 
 const (
-	AnsibleMain       = "ansible/check.yml"
-	AnsibleMeta       = "ansible/meta.yml"
-	AnsibleConfigFile = "ansible/ansible.cfg"
-	AnsibleHostFile   = "ansible/ansible_hosts"
+	AnsibleMain        = "ansible/check.yml"
+	AnsibleMeta        = "ansible/meta.yml"
+	AnsibleConfigFile  = "ansible/ansible.cfg"
+	AnsibleHostFile    = "ansible/ansible_hosts"
+	AnsibleInventories = "ansible/inventories"
 )
 
 type Runner struct {
@@ -32,11 +33,13 @@ type Runner struct {
 	ctx       context.Context
 	ctxCancel context.CancelFunc
 	trentoApi api.TrentoApiService
+	scheduler *Scheduler
 }
 
 type Config struct {
 	ApiHost       string
 	ApiPort       int
+	ApiKey        string
 	Interval      time.Duration
 	AnsibleFolder string
 }
@@ -48,6 +51,7 @@ func NewRunner(config *Config) (*Runner, error) {
 		config:    config,
 		ctx:       ctx,
 		ctxCancel: ctxCancel,
+		scheduler: NewScheduler(DefaultMaxConcurrentRuns, DefaultMaxRunsPerTarget),
 	}
 
 	return runner, nil
@@ -63,7 +67,7 @@ func (c *Runner) Start() error {
 	var trentoApi api.TrentoApiService
 	err := retryGo.Do(
 		func() error {
-			trentoApi = api.NewTrentoApiService(c.config.ApiHost, c.config.ApiPort)
+			trentoApi = api.NewTrentoApiService(c.config.ApiHost, c.config.ApiPort, c.config.ApiKey)
 			if !trentoApi.IsWebServerUp() {
 				return fmt.Errorf("Trento server api not available")
 			}
@@ -84,11 +88,26 @@ func (c *Runner) Start() error {
 
 	c.trentoApi = trentoApi
 
+	queueChanged := make(chan struct{}, 1)
+	c.scheduler.OnChange(func() {
+		select {
+		case queueChanged <- struct{}{}:
+		default:
+		}
+	})
+
+	wg.Add(1)
+	go func(wg *sync.WaitGroup) {
+		defer wg.Done()
+		c.reportRunsQueue(queueChanged)
+	}(&wg)
+
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
 		log.Println("Starting the runner loop...")
 		defer wg.Done()
 		c.startCheckRunnerTicker()
+		c.scheduler.Drain()
 		log.Println("Runner loop stopped.")
 	}(&wg)
 
@@ -160,7 +179,7 @@ func NewAnsibleMetaRunner(config *Config) (*AnsibleRunner, error) {
 
 	configFile := path.Join(config.AnsibleFolder, AnsibleConfigFile)
 	ansibleRunner.SetConfigFile(configFile)
-	ansibleRunner.SetTrentoApiData(config.ApiHost, config.ApiPort)
+	ansibleRunner.SetTrentoApiData(config.ApiHost, config.ApiPort, config.ApiKey)
 
 	return ansibleRunner, nil
 }
@@ -177,23 +196,20 @@ func NewAnsibleCheckRunner(config *Config) (*AnsibleRunner, error) {
 	ansibleRunner.Check = true
 	configFile := path.Join(config.AnsibleFolder, AnsibleConfigFile)
 	ansibleRunner.SetConfigFile(configFile)
-	ansibleRunner.SetTrentoApiData(config.ApiHost, config.ApiPort)
+	ansibleRunner.SetTrentoApiData(config.ApiHost, config.ApiPort, config.ApiKey)
 
 	return ansibleRunner, nil
 }
 
 func (c *Runner) startCheckRunnerTicker() {
-	checkRunner, err := NewAnsibleCheckRunner(c.config)
-	if err != nil {
-		return
-	}
-
 	metaRunner, err := NewAnsibleMetaRunner(c.config)
 	if err != nil {
 		return
 	}
 
 	tick := func() {
+		c.updateSchedulerLimits()
+
 		if err = metaRunner.RunPlaybook(); err != nil {
 			log.Errorf("Error running the catalog meta-playbook")
 			return
@@ -205,21 +221,76 @@ func (c *Runner) startCheckRunnerTicker() {
 			return
 		}
 
-		inventoryFile := path.Join(c.config.AnsibleFolder, AnsibleHostFile)
-		err = CreateInventory(inventoryFile, content)
+		for _, group := range content.Groups {
+			c.scheduleChecksRun(group)
+		}
+	}
+
+	interval := c.config.Interval
+	internal.Repeat("runner.ansible_playbook", tick, interval, c.ctx)
+}
+
+// updateSchedulerLimits keeps the current limits if the settings cannot be retrieved
+func (c *Runner) updateSchedulerLimits() {
+	runnerSettings, err := c.trentoApi.GetRunnerSettings()
+	if err != nil {
+		log.Errorf("Error retrieving the runner settings, keeping the current limits: %s", err)
+		return
+	}
+
+	c.scheduler.SetLimits(runnerSettings.MaxConcurrentRuns, runnerSettings.MaxRunsPerTarget)
+}
+
+// scheduleChecksRun queues the checks execution of a cluster, using its own inventory file
+// so it can run concurrently with the other clusters ones
+func (c *Runner) scheduleChecksRun(group *Group) {
+	targets := []string{}
+	for _, node := range group.Nodes {
+		targets = append(targets, node.AnsibleHost)
+	}
+
+	scheduled := c.scheduler.Submit(group.Name, targets, func() {
+		inventoriesFolder := path.Join(c.config.AnsibleFolder, AnsibleInventories)
+		if err := os.MkdirAll(inventoriesFolder, 0755); err != nil {
+			log.Errorf("Error creating the ansible inventories folder: %s", err)
+			return
+		}
+
+		inventoryFile := path.Join(inventoriesFolder, group.Name)
+		err := CreateInventory(inventoryFile, &InventoryContent{Groups: []*Group{group}})
+		if err != nil {
+			log.Errorf("Error creating the ansible inventory file of cluster %s", group.Name)
+			return
+		}
+
+		checkRunner, err := NewAnsibleCheckRunner(c.config)
 		if err != nil {
-			log.Errorf("Error creating the ansible inventory file")
 			return
 		}
 
 		if err = checkRunner.SetInventory(inventoryFile); err != nil {
-			log.Errorf("Error setting the ansible inventory file")
+			log.Errorf("Error setting the ansible inventory file of cluster %s", group.Name)
 			return
 		}
 
 		checkRunner.RunPlaybook()
+	})
+
+	if !scheduled {
+		log.Debugf("Checks execution of cluster %s still queued or running, skipping", group.Name)
 	}
+}
 
-	interval := c.config.Interval
-	internal.Repeat("runner.ansible_playbook", tick, interval, c.ctx)
+// reportRunsQueue sends the queue to the Trento server every time it changes
+func (c *Runner) reportRunsQueue(queueChanged <-chan struct{}) {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-queueChanged:
+			if err := c.trentoApi.UpdateRunsQueue(c.scheduler.Status()); err != nil {
+				log.Errorf("Error reporting the checks executions queue: %s", err)
+			}
+		}
+	}
 }
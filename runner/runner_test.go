@@ -30,6 +30,7 @@ func TestNewAnsibleMetaRunner(t *testing.T) {
 	cfg := &Config{
 		ApiHost:       "127.0.0.1",
 		ApiPort:       8000,
+		ApiKey:        "some-api-key",
 		AnsibleFolder: TestAnsibleFolder,
 	}
 
@@ -41,6 +42,7 @@ func TestNewAnsibleMetaRunner(t *testing.T) {
 			"ANSIBLE_CONFIG":      path.Join(TestAnsibleFolder, "ansible/ansible.cfg"),
 			"TRENTO_WEB_API_HOST": "127.0.0.1",
 			"TRENTO_WEB_API_PORT": "8000",
+			"TRENTO_WEB_API_KEY":  "some-api-key",
 		},
 		Check: false,
 	}
@@ -54,6 +56,7 @@ func TestNewAnsibleCheckRunner(t *testing.T) {
 	cfg := &Config{
 		ApiHost:       "127.0.0.1",
 		ApiPort:       8000,
+		ApiKey:        "some-api-key",
 		AnsibleFolder: TestAnsibleFolder,
 	}
 
@@ -65,6 +68,7 @@ func TestNewAnsibleCheckRunner(t *testing.T) {
 			"ANSIBLE_CONFIG":      path.Join(TestAnsibleFolder, "ansible/ansible.cfg"),
 			"TRENTO_WEB_API_HOST": "127.0.0.1",
 			"TRENTO_WEB_API_PORT": "8000",
+			"TRENTO_WEB_API_KEY":  "some-api-key",
 		},
 		Check: true,
 	}
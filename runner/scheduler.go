@@ -0,0 +1,232 @@
+package runner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	DefaultMaxConcurrentRuns int = 4
+	DefaultMaxRunsPerTarget  int = 1
+)
+
+type scheduledRun struct {
+	clusterID string
+	targets   []string
+	run       func()
+	queuedAt  time.Time
+	startedAt *time.Time
+}
+
+// Scheduler bounds the checks executions running at the same time, globally and per target host.
+// Runs wait in a FIFO queue: a run is started as soon as there is a free global slot and none of
+// its targets is saturated, so it can overtake older runs blocked on other targets.
+// A limit equal to 0 means no limit.
+type Scheduler struct {
+	mu                sync.Mutex
+	wg                sync.WaitGroup
+	maxConcurrentRuns int
+	maxRunsPerTarget  int
+	queue             []*scheduledRun
+	running           []*scheduledRun
+	runningByTarget   map[string]int
+	onChange          func()
+}
+
+func NewScheduler(maxConcurrentRuns, maxRunsPerTarget int) *Scheduler {
+	return &Scheduler{
+		maxConcurrentRuns: maxConcurrentRuns,
+		maxRunsPerTarget:  maxRunsPerTarget,
+		runningByTarget:   make(map[string]int),
+		onChange:          func() {},
+	}
+}
+
+// OnChange registers a callback invoked every time a run is queued, started or finished
+func (s *Scheduler) OnChange(onChange func()) {
+	s.mu.Lock()
+	s.onChange = onChange
+	s.mu.Unlock()
+}
+
+// SetLimits updates the limits, queued runs are started right away if the new ones allow it
+func (s *Scheduler) SetLimits(maxConcurrentRuns, maxRunsPerTarget int) {
+	s.mu.Lock()
+	s.maxConcurrentRuns = maxConcurrentRuns
+	s.maxRunsPerTarget = maxRunsPerTarget
+	changed := s.dispatch()
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	if changed {
+		onChange()
+	}
+}
+
+// Submit queues a run of the cluster checks on the given targets.
+// It returns false if a run for the same cluster is already queued or running.
+func (s *Scheduler) Submit(clusterID string, targets []string, run func()) bool {
+	s.mu.Lock()
+	if s.isScheduled(clusterID) {
+		s.mu.Unlock()
+		return false
+	}
+
+	s.queue = append(s.queue, &scheduledRun{
+		clusterID: clusterID,
+		targets:   uniqueTargets(targets),
+		run:       run,
+		queuedAt:  time.Now(),
+	})
+	s.dispatch()
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	onChange()
+
+	return true
+}
+
+// Status returns the running runs, ordered by start time, followed by the queued ones
+func (s *Scheduler) Status() []*models.QueuedRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := []*models.QueuedRun{}
+	for _, r := range s.running {
+		status = append(status, &models.QueuedRun{
+			ClusterID: r.clusterID,
+			Targets:   r.targets,
+			State:     models.QueuedRunStateRunning,
+			QueuedAt:  r.queuedAt,
+			StartedAt: r.startedAt,
+		})
+	}
+
+	for _, r := range s.queue {
+		status = append(status, &models.QueuedRun{
+			ClusterID: r.clusterID,
+			Targets:   r.targets,
+			State:     models.QueuedRunStateQueued,
+			QueuedAt:  r.queuedAt,
+		})
+	}
+
+	return status
+}
+
+// Drain drops the queued runs and waits for the running ones to finish
+func (s *Scheduler) Drain() {
+	s.mu.Lock()
+	s.queue = nil
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	onChange()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) isScheduled(clusterID string) bool {
+	for _, runs := range [][]*scheduledRun{s.running, s.queue} {
+		for _, r := range runs {
+			if r.clusterID == clusterID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// dispatch starts the queued runs allowed by the limits, it must be called holding the lock
+func (s *Scheduler) dispatch() bool {
+	started := false
+	queue := []*scheduledRun{}
+
+	for _, r := range s.queue {
+		if !s.canStart(r) {
+			queue = append(queue, r)
+			continue
+		}
+
+		s.start(r)
+		started = true
+	}
+
+	s.queue = queue
+
+	return started
+}
+
+func (s *Scheduler) canStart(r *scheduledRun) bool {
+	if s.maxConcurrentRuns > 0 && len(s.running) >= s.maxConcurrentRuns {
+		return false
+	}
+
+	if s.maxRunsPerTarget > 0 {
+		for _, target := range r.targets {
+			if s.runningByTarget[target] >= s.maxRunsPerTarget {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (s *Scheduler) start(r *scheduledRun) {
+	now := time.Now()
+	r.startedAt = &now
+
+	s.running = append(s.running, r)
+	for _, target := range r.targets {
+		s.runningByTarget[target]++
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		r.run()
+		s.finish(r)
+	}()
+}
+
+func (s *Scheduler) finish(r *scheduledRun) {
+	s.mu.Lock()
+	for i, running := range s.running {
+		if running == r {
+			s.running = append(s.running[:i], s.running[i+1:]...)
+			break
+		}
+	}
+
+	for _, target := range r.targets {
+		s.runningByTarget[target]--
+		if s.runningByTarget[target] == 0 {
+			delete(s.runningByTarget, target)
+		}
+	}
+
+	s.dispatch()
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	onChange()
+}
+
+func uniqueTargets(targets []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+
+	for _, target := range targets {
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		unique = append(unique, target)
+	}
+
+	return unique
+}
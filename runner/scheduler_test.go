@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+func statesByCluster(runs []*models.QueuedRun) map[string]string {
+	states := make(map[string]string)
+	for _, r := range runs {
+		states[r.ClusterID] = r.State
+	}
+	return states
+}
+
+func blockingRun(started chan<- string, release <-chan struct{}, clusterID string) func() {
+	return func() {
+		started <- clusterID
+		<-release
+	}
+}
+
+func TestSchedulerGlobalLimit(t *testing.T) {
+	scheduler := NewScheduler(2, 0)
+	started := make(chan string, 3)
+	release := make(chan struct{})
+
+	scheduler.Submit("cluster1", []string{"10.0.0.1"}, blockingRun(started, release, "cluster1"))
+	scheduler.Submit("cluster2", []string{"10.0.0.2"}, blockingRun(started, release, "cluster2"))
+	scheduler.Submit("cluster3", []string{"10.0.0.3"}, blockingRun(started, release, "cluster3"))
+
+	<-started
+	<-started
+
+	assert.Equal(t, map[string]string{
+		"cluster1": models.QueuedRunStateRunning,
+		"cluster2": models.QueuedRunStateRunning,
+		"cluster3": models.QueuedRunStateQueued,
+	}, statesByCluster(scheduler.Status()))
+
+	release <- struct{}{}
+	assert.Equal(t, "cluster3", <-started)
+
+	close(release)
+	scheduler.Drain()
+
+	assert.Empty(t, scheduler.Status())
+}
+
+func TestSchedulerPerTargetLimit(t *testing.T) {
+	scheduler := NewScheduler(0, 1)
+	started := make(chan string, 3)
+	release := make(chan struct{})
+
+	scheduler.Submit("cluster1", []string{"10.0.0.1", "10.0.0.2"}, blockingRun(started, release, "cluster1"))
+	scheduler.Submit("cluster2", []string{"10.0.0.2", "10.0.0.3"}, blockingRun(started, release, "cluster2"))
+	scheduler.Submit("cluster3", []string{"10.0.0.4"}, blockingRun(started, release, "cluster3"))
+
+	<-started
+	<-started
+
+	status := scheduler.Status()
+	assert.Equal(t, map[string]string{
+		"cluster1": models.QueuedRunStateRunning,
+		"cluster2": models.QueuedRunStateQueued,
+		"cluster3": models.QueuedRunStateRunning,
+	}, statesByCluster(status))
+	assert.Equal(t, models.QueuedRunStateQueued, status[2].State)
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3"}, status[2].Targets)
+	assert.Nil(t, status[2].StartedAt)
+
+	close(release)
+	scheduler.Drain()
+}
+
+func TestSchedulerSubmitAlreadyScheduled(t *testing.T) {
+	scheduler := NewScheduler(1, 0)
+	started := make(chan string, 1)
+	release := make(chan struct{})
+
+	assert.True(t, scheduler.Submit("cluster1", []string{"10.0.0.1", "10.0.0.1"}, blockingRun(started, release, "cluster1")))
+	<-started
+
+	assert.False(t, scheduler.Submit("cluster1", []string{"10.0.0.1"}, func() {}))
+	assert.Equal(t, []string{"10.0.0.1"}, scheduler.Status()[0].Targets)
+
+	close(release)
+	scheduler.Drain()
+}
+
+func TestSchedulerSetLimits(t *testing.T) {
+	scheduler := NewScheduler(1, 0)
+	started := make(chan string, 2)
+	release := make(chan struct{})
+
+	var changes int32
+	scheduler.OnChange(func() {
+		atomic.AddInt32(&changes, 1)
+	})
+
+	scheduler.Submit("cluster1", []string{"10.0.0.1"}, blockingRun(started, release, "cluster1"))
+	scheduler.Submit("cluster2", []string{"10.0.0.2"}, blockingRun(started, release, "cluster2"))
+	<-started
+
+	assert.Equal(t, models.QueuedRunStateQueued, statesByCluster(scheduler.Status())["cluster2"])
+
+	scheduler.SetLimits(0, 0)
+	assert.Equal(t, "cluster2", <-started)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&changes))
+
+	close(release)
+	scheduler.Drain()
+}
@@ -13,6 +13,8 @@ func init() {
 	viper.SetDefault("db-user", "postgres")
 	viper.SetDefault("db-password", "postgres")
 	viper.SetDefault("db-name", "trento_test")
+	viper.SetDefault("smoke-tests", false)
+	viper.SetDefault("smoke-tests-postgres-image", "postgres:14")
 
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
 	viper.SetEnvPrefix("TRENTO")
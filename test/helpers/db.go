@@ -13,6 +13,11 @@ import (
 )
 
 func SetupTestDatabase(t *testing.T) *gorm.DB {
+	return SetupTestDatabaseInSchema(t, viper.GetString("db-schema"))
+}
+
+// SetupTestDatabaseInSchema connects to the test database with the tables in the given schema
+func SetupTestDatabaseInSchema(t *testing.T, schema string) *gorm.DB {
 	testEnabled := viper.GetBool("db-integration-tests")
 	if !testEnabled {
 		t.SkipNow()
@@ -24,6 +29,7 @@ func SetupTestDatabase(t *testing.T) *gorm.DB {
 		User:     viper.GetString("db-user"),
 		Password: viper.GetString("db-password"),
 		DBName:   viper.GetString("db-name"),
+		Schema:   schema,
 	}
 
 	ctx := context.Background()
@@ -0,0 +1,77 @@
+package helpers
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/trento-project/trento/internal/db"
+	"gorm.io/gorm"
+)
+
+// SetupSmokeTestDatabase runs a disposable PostgreSQL container through the docker CLI
+// and returns the configuration to connect to it, along with an open connection.
+// The container is removed when the test ends.
+// Smoke tests need a docker daemon, so they are skipped unless TRENTO_SMOKE_TESTS is enabled.
+func SetupSmokeTestDatabase(t *testing.T) (*db.Config, *gorm.DB) {
+	if !viper.GetBool("smoke-tests") {
+		t.SkipNow()
+	}
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker is not available")
+	}
+
+	dbConfig := &db.Config{
+		Host:     "127.0.0.1",
+		User:     "postgres",
+		Password: "postgres",
+		DBName:   "trento_smoke",
+	}
+
+	out, err := exec.Command(
+		"docker", "run", "--detach", "--rm",
+		"--env", "POSTGRES_USER="+dbConfig.User,
+		"--env", "POSTGRES_PASSWORD="+dbConfig.Password,
+		"--env", "POSTGRES_DB="+dbConfig.DBName,
+		"--publish", dbConfig.Host+"::5432",
+		viper.GetString("smoke-tests-postgres-image"),
+	).Output()
+	if err != nil {
+		t.Fatalf("could not start the postgres container: %s", err)
+	}
+
+	containerID := strings.TrimSpace(string(out))
+	t.Cleanup(func() {
+		_ = exec.Command("docker", "rm", "--force", containerID).Run()
+	})
+
+	out, err = exec.Command("docker", "port", containerID, "5432/tcp").Output()
+	if err != nil {
+		t.Fatalf("could not get the postgres container port: %s", err)
+	}
+
+	// docker port may list one binding per line, e.g. 127.0.0.1:49153
+	binding := strings.Split(strings.TrimSpace(string(out)), "\n")[0]
+	_, port, err := net.SplitHostPort(binding)
+	if err != nil {
+		t.Fatalf("unexpected postgres container port binding %s: %s", binding, err)
+	}
+
+	dbConfig.Port, err = strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("unexpected postgres container port %s: %s", port, err)
+	}
+
+	// InitDB retries until the container accepts connections
+	conn, err := db.InitDB(context.Background(), dbConfig)
+	if err != nil {
+		t.Fatalf("could not connect to the postgres container: %s", err)
+	}
+
+	return dbConfig, conn
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/services"
+)
+
+var addressConflictsInterval = 5 * time.Minute
+
+// AddressConflictsAnalyzer periodically looks for IP addresses and hostnames claimed by more than
+// one host, cluster or SAP system, as they cause hard to diagnose SAP logon group issues
+type AddressConflictsAnalyzer struct {
+	addressConflictsService services.AddressConflictsService
+}
+
+func NewAddressConflictsAnalyzer(addressConflictsService services.AddressConflictsService) *AddressConflictsAnalyzer {
+	return &AddressConflictsAnalyzer{addressConflictsService: addressConflictsService}
+}
+
+func (a *AddressConflictsAnalyzer) Start(ctx context.Context) {
+	log.Infof("Starting address conflicts analyzer")
+
+	internal.Repeat("web.address_conflicts_analyzer", a.analyze, addressConflictsInterval, ctx)
+}
+
+func (a *AddressConflictsAnalyzer) analyze() {
+	conflicts, err := a.addressConflictsService.Analyze()
+	if err != nil {
+		log.Errorf("Error while analyzing the address conflicts: %s", err)
+		return
+	}
+
+	for _, c := range conflicts {
+		log.Warnf("Address conflict detected: %s %s is claimed by %d resources", c.Kind, c.Address, len(c.Owners))
+	}
+}
+
+// ApiListAddressConflictsHandler godoc
+// @Summary List the IP addresses and hostnames claimed by more than one host, cluster or SAP system
+// @Description The conflicts among the resources of the organization of the user only, if any
+// @Produce json
+// @Success 200 {object} []models.AddressConflict
+// @Failure 500 {object} map[string]string
+// @Router /conflicts [get]
+func ApiListAddressConflictsHandler(addressConflictsService services.AddressConflictsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conflicts, err := addressConflictsService.GetAll(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, conflicts)
+	}
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestAddressConflictsAnalyzer(t *testing.T) {
+	addressConflictsService := new(services.MockAddressConflictsService)
+	addressConflictsService.On("Analyze").Return([]*models.AddressConflict{}, nil)
+
+	NewAddressConflictsAnalyzer(addressConflictsService).analyze()
+
+	addressConflictsService.AssertExpectations(t)
+}
+
+func TestApiListAddressConflictsHandler(t *testing.T) {
+	addressConflictsService := new(services.MockAddressConflictsService)
+	addressConflictsService.On("GetAll", "").Return([]*models.AddressConflict{
+		{
+			Kind:    models.AddressConflictIPAddress,
+			Address: "10.0.0.100",
+			Owners: []*models.AddressConflictOwner{
+				{ResourceType: models.TagClusterResourceType, ID: "cluster1", Name: "hana_cluster"},
+				{ResourceType: models.TagHostResourceType, ID: "3", Name: "app01"},
+			},
+			DetectedAt: time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.addressConflictsService = addressConflictsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/conflicts", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"kind": "ip_address",
+		"address": "10.0.0.100",
+		"owners": [
+			{"resource_type": "clusters", "id": "cluster1", "name": "hana_cluster"},
+			{"resource_type": "hosts", "id": "3", "name": "app01"}
+		],
+		"detected_at": "2022-03-01T10:00:00Z"
+	}]`, resp.Body.String())
+}
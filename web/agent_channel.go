@@ -0,0 +1,372 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
+)
+
+const (
+	// agentChannelPingInterval keeps the channels open through the proxies, the agents answering with a pong
+	agentChannelPingInterval = 30 * time.Second
+	// agentChannelPongWait is how long the server waits for any message of the agent before closing the channel
+	agentChannelPongWait  = 2 * agentChannelPingInterval
+	agentChannelWriteWait = 10 * time.Second
+	// maxAgentChannelMessage bounds the messages sent by the agents, the acknowledgements only
+	maxAgentChannelMessage = 64 * 1024
+	// agentChannelSendBuffer are the messages queued for an agent before the new ones are refused
+	agentChannelSendBuffer = 16
+)
+
+var agentChannelUpgrader = websocket.Upgrader{}
+
+// JSONAgentMessage is the message sent to the agent on the request of a user, as returned by the API
+type JSONAgentMessage struct {
+	ID      string      `json:"id"`
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// AgentChannelHub keeps the channels opened by the agents, so that the server pushes them the configuration changes
+// and the requests of the users instead of waiting for them to poll
+type AgentChannelHub struct {
+	mutex    sync.RWMutex
+	channels map[string]*agentChannel
+}
+
+type agentChannel struct {
+	send chan *hosts.AgentMessage
+	done chan struct{}
+}
+
+func NewAgentChannelHub() *AgentChannelHub {
+	return &AgentChannelHub{channels: make(map[string]*agentChannel)}
+}
+
+// Send queues the message for the agent, returning false if the agent is not connected or too far behind
+func (h *AgentChannelHub) Send(agentID string, message *hosts.AgentMessage) bool {
+	h.mutex.RLock()
+	channel, ok := h.channels[agentID]
+	h.mutex.RUnlock()
+
+	if !ok {
+		return false
+	}
+
+	select {
+	case channel.send <- message:
+		return true
+	case <-channel.done:
+		return false
+	default:
+		log.Warnf("Agent %s is not reading its channel, message %s dropped", agentID, message.Type)
+		return false
+	}
+}
+
+// Broadcast sends the message to every connected agent, returning the number of agents it was sent to
+func (h *AgentChannelHub) Broadcast(message *hosts.AgentMessage) int {
+	h.mutex.RLock()
+	agentIDs := make([]string, 0, len(h.channels))
+	for agentID := range h.channels {
+		agentIDs = append(agentIDs, agentID)
+	}
+	h.mutex.RUnlock()
+
+	sent := 0
+	for _, agentID := range agentIDs {
+		if h.Send(agentID, message) {
+			sent++
+		}
+	}
+
+	return sent
+}
+
+func (h *AgentChannelHub) IsConnected(agentID string) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	_, ok := h.channels[agentID]
+	return ok
+}
+
+// register opens the channel of the agent, closing the previous one if the agent reconnected meanwhile
+func (h *AgentChannelHub) register(agentID string) *agentChannel {
+	channel := &agentChannel{
+		send: make(chan *hosts.AgentMessage, agentChannelSendBuffer),
+		done: make(chan struct{}),
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if previous, ok := h.channels[agentID]; ok {
+		close(previous.done)
+	}
+	h.channels[agentID] = channel
+
+	return channel
+}
+
+func (h *AgentChannelHub) unregister(agentID string, channel *agentChannel) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.channels[agentID] == channel {
+		delete(h.channels, agentID)
+		close(channel.done)
+	}
+}
+
+// newAgentMessage returns a message of the given type, identified so that the agent acknowledges it
+func newAgentMessage(messageType string, payload interface{}) (*hosts.AgentMessage, error) {
+	message := &hosts.AgentMessage{ID: uuid.New().String(), Type: messageType}
+	if payload == nil {
+		return message, nil
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	message.Payload = encoded
+
+	return message, nil
+}
+
+// ApiAgentChannelHandler upgrades the request of the agent to a WebSocket channel, kept open until either side closes it
+func ApiAgentChannelHandler(hub *AgentChannelHub, agentsService services.AgentsService, entitlementsService services.EntitlementsService, agentUpgradesService services.AgentUpgradesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agentID := c.Query("agent_id")
+
+		if err := validateText("agent ID", agentID, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !checkAgentID(c, agentID) {
+			return
+		}
+
+		if _, ok := admitAgent(c, agentsService, entitlementsService, agentID); !ok {
+			return
+		}
+
+		conn, err := agentChannelUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// the upgrader already responded with the error
+			log.Warnf("Could not open the channel of agent %s: %s", agentID, err)
+			return
+		}
+		defer conn.Close()
+
+		channel := hub.register(agentID)
+		defer hub.unregister(agentID, channel)
+
+		log.Infof("Agent %s opened its channel", agentID)
+
+		go writeAgentChannel(conn, channel)
+		deliverPendingAgentUpgrade(hub, agentUpgradesService, agentID)
+		readAgentChannel(conn, agentID, agentUpgradesService)
+
+		log.Infof("Agent %s closed its channel", agentID)
+	}
+}
+
+func writeAgentChannel(conn *websocket.Conn, channel *agentChannel) {
+	ticker := time.NewTicker(agentChannelPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message := <-channel.send:
+			conn.SetWriteDeadline(time.Now().Add(agentChannelWriteWait))
+			if err := conn.WriteJSON(message); err != nil {
+				conn.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(agentChannelWriteWait)); err != nil {
+				conn.Close()
+				return
+			}
+		case <-channel.done:
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(agentChannelWriteWait))
+			conn.Close()
+			return
+		}
+	}
+}
+
+// readAgentChannel reads the acknowledgements of the agent until the channel is closed
+func readAgentChannel(conn *websocket.Conn, agentID string, agentUpgradesService services.AgentUpgradesService) {
+	conn.SetReadLimit(maxAgentChannelMessage)
+	conn.SetReadDeadline(time.Now().Add(agentChannelPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(agentChannelPongWait))
+	})
+
+	for {
+		var message hosts.AgentMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Warnf("Channel of agent %s closed: %s", agentID, err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(agentChannelPongWait))
+
+		if message.Type != hosts.AgentMessageAck {
+			log.Warnf("Agent %s sent an unexpected %s message", agentID, message.Type)
+			continue
+		}
+
+		if err := agentUpgradesService.Acknowledge(agentID, message.ID, message.Error); err != nil {
+			log.Errorf("Error while recording the acknowledgement of message %s by agent %s: %s", message.ID, agentID, err)
+		}
+
+		if message.Error != "" {
+			log.Warnf("Agent %s failed to handle message %s: %s", agentID, message.ID, message.Error)
+			continue
+		}
+
+		log.Debugf("Agent %s handled message %s", agentID, message.ID)
+	}
+}
+
+// ApiRequestDiscoveryHandler godoc
+// @Summary Ask the agent of a host to run a discovery right away, over its channel
+// @Produce json
+// @Param id path string true "Host id"
+// @Param discovery_type path string true "Discovery type, e.g. ha_cluster_discovery"
+// @Success 202 {object} JSONAgentMessage
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /hosts/{id}/discoveries/{discovery_type} [post]
+func ApiRequestDiscoveryHandler(hostsService services.HostsService, hub *AgentChannelHub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		discoveryType := c.Param("discovery_type")
+
+		if !isDiscoveryType(discoveryType) {
+			_ = c.Error(BadRequestError(fmt.Sprintf("unknown discovery type %q", discoveryType)))
+			return
+		}
+
+		if !requireHost(c, hostsService, id) {
+			return
+		}
+
+		message, err := newAgentMessage(hosts.AgentMessageDiscoveryRequested, &hosts.DiscoveryRequest{DiscoveryType: discoveryType})
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		sendAgentMessage(c, hub, id, message)
+	}
+}
+
+// ApiRequestChecksRunHandler godoc
+// @Summary Ask the agent of a host to run the checks selected for its cluster right away, over its channel
+// @Produce json
+// @Param id path string true "Host id"
+// @Success 202 {object} JSONAgentMessage
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /hosts/{id}/checks/run [post]
+func ApiRequestChecksRunHandler(hostsService services.HostsService, checksService services.ChecksService, hub *AgentChannelHub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		host, err := hostsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		if host.ClusterID == "" {
+			_ = c.Error(ConflictError("the host is not part of a cluster, there are no checks to run"))
+			return
+		}
+
+		selectedChecks, err := checksService.GetSelectedChecksById(host.ClusterID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		message, err := newAgentMessage(hosts.AgentMessageChecksRunRequested, &hosts.ChecksRunRequest{
+			ClusterID: host.ClusterID,
+			Checks:    append([]string{}, selectedChecks.SelectedChecks...),
+		})
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		sendAgentMessage(c, hub, id, message)
+	}
+}
+
+func sendAgentMessage(c *gin.Context, hub *AgentChannelHub, agentID string, message *hosts.AgentMessage) {
+	if !hub.Send(agentID, message) {
+		_ = c.Error(ConflictError("the agent is not connected, or not reading its channel"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, &JSONAgentMessage{ID: message.ID, Type: message.Type, Payload: message.Payload})
+}
+
+func requireHost(c *gin.Context, hostsService services.HostsService, id string) bool {
+	host, err := hostsService.GetByID(id)
+	if err != nil {
+		_ = c.Error(err)
+		return false
+	}
+
+	if host == nil {
+		_ = c.Error(NotFoundError("could not find host"))
+		return false
+	}
+
+	return true
+}
+
+func isDiscoveryType(discoveryType string) bool {
+	for _, t := range datapipeline.DiscoveryTypes {
+		if t == discoveryType {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyConfigChanged tells the connected agents to pull their configuration again
+func notifyConfigChanged(hub *AgentChannelHub) {
+	message, err := newAgentMessage(hosts.AgentMessageConfigChanged, nil)
+	if err != nil {
+		log.Errorf("Could not notify the agents of the configuration change: %s", err)
+		return
+	}
+
+	log.Debugf("Configuration change notified to %d agents", hub.Broadcast(message))
+}
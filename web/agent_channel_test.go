@@ -0,0 +1,163 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func dialAgentChannel(t *testing.T, server *httptest.Server, agentID string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/agent/ws?agent_id=" + agentID
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func waitForAgentChannel(hub *AgentChannelHub, agentID string, connected bool) bool {
+	for i := 0; i < 100; i++ {
+		if hub.IsConnected(agentID) == connected {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestApiAgentChannelHandler(t *testing.T) {
+	hub := NewAgentChannelHub()
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.GET("/agent/ws", ApiAgentChannelHandler(hub, newMockedAgentsService(), newMockedEntitlementsService(), newMockedAgentUpgradesService()))
+
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/agent/ws", nil)
+	engine.ServeHTTP(resp, req)
+	assert.Equal(t, 400, resp.Code)
+
+	assert.False(t, hub.Send("agent1", &hosts.AgentMessage{ID: "1", Type: hosts.AgentMessageConfigChanged}))
+
+	conn := dialAgentChannel(t, server, "agent1")
+	assert.True(t, waitForAgentChannel(hub, "agent1", true))
+
+	message, err := newAgentMessage(hosts.AgentMessageDiscoveryRequested, &hosts.DiscoveryRequest{DiscoveryType: "host_discovery"})
+	assert.NoError(t, err)
+	assert.True(t, hub.Send("agent1", message))
+
+	var received hosts.AgentMessage
+	assert.NoError(t, conn.ReadJSON(&received))
+	assert.Equal(t, message.ID, received.ID)
+	assert.Equal(t, hosts.AgentMessageDiscoveryRequested, received.Type)
+
+	var request hosts.DiscoveryRequest
+	assert.NoError(t, json.Unmarshal(received.Payload, &request))
+	assert.Equal(t, "host_discovery", request.DiscoveryType)
+
+	assert.NoError(t, conn.WriteJSON(&hosts.AgentMessage{ID: received.ID, Type: hosts.AgentMessageAck}))
+
+	// the channel of the agent reconnecting replaces the previous one
+	reconnected := dialAgentChannel(t, server, "agent1")
+	defer reconnected.Close()
+
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+
+	assert.Equal(t, 1, hub.Broadcast(&hosts.AgentMessage{ID: "2", Type: hosts.AgentMessageConfigChanged}))
+	assert.NoError(t, reconnected.ReadJSON(&received))
+	assert.Equal(t, "2", received.ID)
+
+	reconnected.Close()
+	assert.True(t, waitForAgentChannel(hub, "agent1", false))
+}
+
+func TestApiRequestDiscoveryHandler(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "1").Return(&models.Host{ID: "1", Name: "host1"}, nil)
+	hostsService.On("GetByID", "unknown").Return(nil, nil)
+
+	hub := NewAgentChannelHub()
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/hosts/:id/discoveries/:discovery_type", ApiRequestDiscoveryHandler(hostsService, hub))
+
+	for url, code := range map[string]int{
+		"/hosts/1/discoveries/unknown_discovery":    400,
+		"/hosts/unknown/discoveries/host_discovery": 404,
+		"/hosts/1/discoveries/host_discovery":       409,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, nil)
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, code, resp.Code, url)
+	}
+
+	channel := hub.register("1")
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/hosts/1/discoveries/ha_cluster_discovery", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+
+	sent := <-channel.send
+	assert.Equal(t, hosts.AgentMessageDiscoveryRequested, sent.Type)
+	assert.JSONEq(t, `{"discovery_type": "ha_cluster_discovery"}`, string(sent.Payload))
+
+	var body JSONAgentMessage
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, sent.ID, body.ID)
+	assert.Equal(t, map[string]interface{}{"discovery_type": "ha_cluster_discovery"}, body.Payload)
+}
+
+func TestApiRequestChecksRunHandler(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "1").Return(&models.Host{ID: "1", ClusterID: "cluster1"}, nil)
+	hostsService.On("GetByID", "2").Return(&models.Host{ID: "2"}, nil)
+
+	checksService := new(services.MockChecksService)
+	checksService.On("GetSelectedChecksById", "cluster1").Return(models.SelectedChecks{
+		ID:             "cluster1",
+		SelectedChecks: []string{"ABCDEF"},
+	}, nil)
+
+	hub := NewAgentChannelHub()
+	channel := hub.register("1")
+	hub.register("2")
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/hosts/:id/checks/run", ApiRequestChecksRunHandler(hostsService, checksService, hub))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/hosts/2/checks/run", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 409, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/hosts/1/checks/run", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+
+	sent := <-channel.send
+	assert.Equal(t, hosts.AgentMessageChecksRunRequested, sent.Type)
+	assert.JSONEq(t, `{"cluster_id": "cluster1", "checks": ["ABCDEF"]}`, string(sent.Payload))
+}
@@ -0,0 +1,143 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// ApiGetAgentConfigurationHandler lets the agents pull the configuration they are desired to run with,
+// when told it changed over their channel or periodically.
+// The revision of the configuration is sent as ETag, so that the agents polling it get a 304 until it changes
+func ApiGetAgentConfigurationHandler(agentsService services.AgentsService, entitlementsService services.EntitlementsService, agentConfigurationService services.AgentConfigurationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agentID := c.Param("id")
+
+		if err := validateText("agent ID", agentID, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !checkAgentID(c, agentID) {
+			return
+		}
+
+		if _, ok := admitAgent(c, agentsService, entitlementsService, agentID); !ok {
+			return
+		}
+
+		configuration, err := agentConfigurationService.GetByAgentID(agentID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		etag := fmt.Sprintf("%q", configuration.Revision)
+		c.Header("ETag", etag)
+
+		if c.GetHeader("If-None-Match") == etag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
+		c.JSON(http.StatusOK, configuration)
+	}
+}
+
+// ApiGetAgentSettingsHandler godoc
+// @Summary Retrieve the discovery settings pulled by the agents with their configuration
+// @Produce json
+// @Success 200 {object} models.AgentSettings
+// @Failure 500 {object} map[string]string
+// @Router /discovery/settings [get]
+func ApiGetAgentSettingsHandler(settingsService services.SettingsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agentSettings, err := settingsService.GetAgentSettings()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, agentSettings)
+	}
+}
+
+// ApiUpdateAgentSettingsHandler godoc
+// @Summary Update the discovery settings pulled by the agents with their configuration
+// @Description The periods are in seconds by discovery type, the agents keep their own period if not set
+// @Accept json
+// @Produce json
+// @Param Body body models.AgentSettings true "The discovery periods and the disabled discoveries"
+// @Success 200 {object} models.AgentSettings
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /discovery/settings [put]
+func ApiUpdateAgentSettingsHandler(settingsService services.SettingsService, auditService services.AuditService, agentChannelHub *AgentChannelHub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var agentSettings models.AgentSettings
+
+		err := c.BindJSON(&agentSettings)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if err := validateAgentSettings(&agentSettings); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		previousSettings, err := settingsService.GetAgentSettings()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		err = settingsService.SaveAgentSettings(&agentSettings)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionAgentSettingsSaved, models.AuditResourceSettings, "agent",
+			previousSettings, &agentSettings)
+
+		notifyConfigChanged(agentChannelHub)
+
+		c.JSON(http.StatusOK, &agentSettings)
+	}
+}
+
+func validateAgentSettings(agentSettings *models.AgentSettings) error {
+	known := make(map[string]bool)
+	for _, discoveryType := range datapipeline.DiscoveryTypes {
+		known[discoveryType] = true
+	}
+
+	if agentSettings.DiscoveryPeriods == nil {
+		agentSettings.DiscoveryPeriods = map[string]int{}
+	}
+	for discoveryType, period := range agentSettings.DiscoveryPeriods {
+		if !known[discoveryType] {
+			return BadRequestError(fmt.Sprintf("unknown discovery type %q", discoveryType))
+		}
+		if period <= 0 {
+			return BadRequestError(fmt.Sprintf("the period of %s must be positive", discoveryType))
+		}
+	}
+
+	if agentSettings.DisabledDiscoveries == nil {
+		agentSettings.DisabledDiscoveries = []string{}
+	}
+	for _, discoveryType := range agentSettings.DisabledDiscoveries {
+		if !known[discoveryType] {
+			return BadRequestError(fmt.Sprintf("unknown discovery type %q", discoveryType))
+		}
+	}
+
+	return nil
+}
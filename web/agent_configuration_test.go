@@ -0,0 +1,91 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiGetAgentConfigurationHandler(t *testing.T) {
+	agentConfigService := new(services.MockAgentConfigurationService)
+	agentConfigService.On("GetByAgentID", "agent1").Return(&models.AgentConfiguration{
+		AgentID:            "agent1",
+		DiscoveryPeriods:   map[string]int{"ha_cluster_discovery": 30},
+		EnabledDiscoveries: []string{"host_discovery", "ha_cluster_discovery"},
+		ClusterID:          "cluster1",
+		SelectedChecks:     []string{"ABCDEF"},
+		Revision:           "1a2b3c",
+	}, nil)
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.GET("/hosts/:id/config", ApiGetAgentConfigurationHandler(newMockedAgentsService(), newMockedEntitlementsService(), agentConfigService))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts/agent1/config", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, `"1a2b3c"`, resp.Header().Get("ETag"))
+	assert.JSONEq(t, `{
+		"agent_id": "agent1",
+		"discovery_periods": {"ha_cluster_discovery": 30},
+		"enabled_discoveries": ["host_discovery", "ha_cluster_discovery"],
+		"cluster_id": "cluster1",
+		"selected_checks": ["ABCDEF"],
+		"revision": "1a2b3c"
+	}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/hosts/agent1/config", nil)
+	req.Header.Set("If-None-Match", `"1a2b3c"`)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 304, resp.Code)
+	assert.Empty(t, resp.Body.String())
+}
+
+func TestApiUpdateAgentSettingsHandler(t *testing.T) {
+	settingsService := new(services.MockSettingsService)
+	settingsService.On("GetAgentSettings").Return(&models.AgentSettings{}, nil)
+	settingsService.On("SaveAgentSettings", &models.AgentSettings{
+		DiscoveryPeriods:    map[string]int{"ha_cluster_discovery": 30},
+		DisabledDiscoveries: []string{},
+	}).Return(nil).Once()
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionAgentSettingsSaved
+	})).Return(nil).Once()
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.PUT("/discovery/settings", ApiUpdateAgentSettingsHandler(settingsService, auditService, NewAgentChannelHub()))
+
+	for _, body := range []string{
+		`{"discovery_periods": {"unknown_discovery": 30}}`,
+		`{"discovery_periods": {"ha_cluster_discovery": 0}}`,
+		`{"disabled_discoveries": ["unknown_discovery"]}`,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/discovery/settings", bytes.NewBufferString(body))
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/discovery/settings", bytes.NewBufferString(`{"discovery_periods": {"ha_cluster_discovery": 30}}`))
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"discovery_periods": {"ha_cluster_discovery": 30}, "disabled_discoveries": []}`, resp.Body.String())
+	settingsService.AssertExpectations(t)
+	auditService.AssertExpectations(t)
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// maxAgentLogsBody bounds the logs shipped by an agent, the ones stored are truncated further
+const maxAgentLogsBody = 4 * services.MaxAgentLogsSizeBytes
+
+// ApiRequestAgentLogsHandler godoc
+// @Summary Ask the agent of a host to ship its recent logs, replacing the ones shipped before
+// @Description The agent ships its logs once told so in the response to its next heartbeat
+// @Produce json
+// @Param id path string true "Host id"
+// @Success 202 {object} models.AgentLogs
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/logs/request [post]
+func ApiRequestAgentLogsHandler(hostsService services.HostsService, agentLogsService services.AgentLogsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		host, err := hostsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		logs, err := agentLogsService.Request(id, requestActor(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionAgentLogsRequested, models.TagHostResourceType, id, nil, nil)
+
+		c.JSON(http.StatusAccepted, logs)
+	}
+}
+
+// ApiGetAgentLogsHandler godoc
+// @Summary Retrieve the logs shipped by the agent of a host, or the pending request
+// @Produce json
+// @Param id path string true "Host id"
+// @Success 200 {object} models.AgentLogs
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/logs [get]
+func ApiGetAgentLogsHandler(agentLogsService services.AgentLogsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logs, err := agentLogsService.Get(c.Param("id"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if logs == nil {
+			_ = c.Error(NotFoundError("no logs were requested to the agent"))
+			return
+		}
+
+		c.JSON(http.StatusOK, logs)
+	}
+}
+
+// ApiShipAgentLogsHandler receives the logs the agents ship when requested
+func ApiShipAgentLogsHandler(agentLogsService services.AgentLogsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agentID := c.Param("id")
+
+		if err := validateText("agent ID", agentID, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !checkAgentID(c, agentID) {
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAgentLogsBody+1))
+		if err != nil {
+			_ = c.Error(BadRequestError(err.Error()))
+			return
+		}
+		if len(body) > maxAgentLogsBody {
+			_ = c.Error(PayloadTooLargeError(fmt.Sprintf("the logs must be at most %d bytes", maxAgentLogsBody)))
+			return
+		}
+
+		var agentLogs hosts.AgentLogs
+		if err := json.Unmarshal(body, &agentLogs); err != nil {
+			_ = c.Error(BadRequestError(err.Error()))
+			return
+		}
+
+		stored, err := agentLogsService.Store(agentID, []byte(agentLogs.Logs))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if !stored {
+			_ = c.Error(NotFoundError("no logs were requested to the agent"))
+			return
+		}
+
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
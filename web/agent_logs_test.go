@@ -0,0 +1,120 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiRequestAgentLogsHandler(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "1").Return(&models.Host{ID: "1", Name: "host1"}, nil)
+	hostsService.On("GetByID", "unknown").Return(nil, nil)
+
+	requestedAt := time.Date(2022, 3, 10, 8, 30, 0, 0, time.UTC)
+	agentLogsService := new(services.MockAgentLogsService)
+	agentLogsService.On("Request", "1", mock.Anything).Return(&models.AgentLogs{
+		AgentID:     "1",
+		RequestedAt: requestedAt,
+		RequestedBy: "admin",
+	}, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionAgentLogsRequested && e.ResourceID == "1"
+	})).Return(nil).Once()
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/hosts/:id/logs/request", ApiRequestAgentLogsHandler(hostsService, agentLogsService, auditService))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/hosts/unknown/logs/request", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/hosts/1/logs/request", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	assert.JSONEq(t, `{
+		"agent_id": "1",
+		"requested_at": "2022-03-10T08:30:00Z",
+		"requested_by": "admin",
+		"received_at": null,
+		"truncated": false,
+		"logs": ""
+	}`, resp.Body.String())
+	agentLogsService.AssertNotCalled(t, "Request", "unknown", mock.Anything)
+	auditService.AssertExpectations(t)
+}
+
+func TestApiGetAgentLogsHandler(t *testing.T) {
+	receivedAt := time.Date(2022, 3, 10, 8, 31, 0, 0, time.UTC)
+	agentLogsService := new(services.MockAgentLogsService)
+	agentLogsService.On("Get", "1").Return(&models.AgentLogs{
+		AgentID:     "1",
+		RequestedAt: time.Date(2022, 3, 10, 8, 30, 0, 0, time.UTC),
+		RequestedBy: "admin",
+		ReceivedAt:  &receivedAt,
+		Logs:        "line1\nline2\n",
+	}, nil)
+	agentLogsService.On("Get", "2").Return(nil, nil)
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.GET("/hosts/:id/logs", ApiGetAgentLogsHandler(agentLogsService))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts/1/logs", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"agent_id": "1",
+		"requested_at": "2022-03-10T08:30:00Z",
+		"requested_by": "admin",
+		"received_at": "2022-03-10T08:31:00Z",
+		"truncated": false,
+		"logs": "line1\nline2\n"
+	}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/hosts/2/logs", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiShipAgentLogsHandler(t *testing.T) {
+	agentLogsService := new(services.MockAgentLogsService)
+	agentLogsService.On("Store", "agent1", []byte("line1\nline2\n")).Return(true, nil)
+	agentLogsService.On("Store", "agent2", mock.Anything).Return(false, nil)
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/api/hosts/:id/logs", ApiShipAgentLogsHandler(agentLogsService))
+
+	serve := func(url string, body string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, bytes.NewBufferString(body))
+		engine.ServeHTTP(resp, req)
+		return resp
+	}
+
+	assert.Equal(t, 204, serve("/api/hosts/agent1/logs", `{"logs": "line1\nline2\n"}`).Code)
+	assert.Equal(t, 404, serve("/api/hosts/agent2/logs", `{"logs": "not requested"}`).Code)
+	assert.Equal(t, 400, serve("/api/hosts/agent1/logs", `not json`).Code)
+	assert.Equal(t, 413, serve("/api/hosts/agent1/logs", `{"logs": "`+strings.Repeat("x", maxAgentLogsBody)+`"}`).Code)
+	agentLogsService.AssertNumberOfCalls(t, "Store", 2)
+}
@@ -0,0 +1,247 @@
+package web
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// maxAgentUpgradeHosts bounds the hosts marked for upgrade at once
+const maxAgentUpgradeHosts = 500
+
+type JSONAgentUpgradeRequest struct {
+	Version string   `json:"version" binding:"required"`
+	HostIDs []string `json:"host_ids" binding:"required"`
+}
+
+// ApiListAgentVersionsHandler godoc
+// @Summary Retrieve the releases of the agent the hosts can be upgraded to, the latest recorded first
+// @Produce json
+// @Success 200 {object} []models.AgentVersion
+// @Failure 500 {object} map[string]string
+// @Router /agent-versions [get]
+func ApiListAgentVersionsHandler(agentUpgradesService services.AgentUpgradesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		versions, err := agentUpgradesService.GetVersions()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, versions)
+	}
+}
+
+// ApiSaveAgentVersionHandler godoc
+// @Summary Record a release of the agent the hosts can be upgraded to, replacing the one with the same version
+// @Accept json
+// @Produce json
+// @Param Body body models.AgentVersion true "The version, the URL the agents download it from and its SHA-256 checksum"
+// @Success 201 {object} models.AgentVersion
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agent-versions [post]
+func ApiSaveAgentVersionHandler(agentUpgradesService services.AgentUpgradesService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var agentVersion models.AgentVersion
+
+		err := c.BindJSON(&agentVersion)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if err := validateAgentVersion(&agentVersion); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		previousVersion, err := agentUpgradesService.GetVersion(agentVersion.Version)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		agentVersion.CreatedBy = requestActor(c)
+		if err := agentUpgradesService.SaveVersion(&agentVersion); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionAgentVersionSaved, models.AuditResourceAgentVersion, agentVersion.Version,
+			previousVersion, &agentVersion)
+
+		c.JSON(http.StatusCreated, &agentVersion)
+	}
+}
+
+func validateAgentVersion(agentVersion *models.AgentVersion) error {
+	if err := validateText("version", agentVersion.Version, maxIdentifierLength); err != nil {
+		return err
+	}
+
+	releaseURL, err := url.Parse(agentVersion.URL)
+	if err != nil || (releaseURL.Scheme != "http" && releaseURL.Scheme != "https") || releaseURL.Host == "" {
+		return BadRequestError("the URL must be an absolute HTTP or HTTPS one")
+	}
+
+	if checksum, err := hex.DecodeString(agentVersion.Checksum); err != nil || len(checksum) != 32 {
+		return BadRequestError("the checksum must be a hex encoded SHA-256")
+	}
+
+	return nil
+}
+
+// ApiListAgentUpgradesHandler godoc
+// @Summary Retrieve the latest upgrade requested to every agent, with its status
+// @Produce json
+// @Success 200 {object} []models.AgentUpgrade
+// @Failure 500 {object} map[string]string
+// @Router /agent-upgrades [get]
+func ApiListAgentUpgradesHandler(agentUpgradesService services.AgentUpgradesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		upgrades, err := agentUpgradesService.GetAll()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, upgrades)
+	}
+}
+
+// ApiRequestAgentUpgradesHandler godoc
+// @Summary Mark the hosts for the upgrade of their agent to a recorded release
+// @Description The upgrade is sent over the channel of the connected agents, the other ones get it once they connect
+// @Accept json
+// @Produce json
+// @Param Body body JSONAgentUpgradeRequest true "The version to upgrade to and the hosts to upgrade"
+// @Success 202 {object} []models.AgentUpgrade
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agent-upgrades [post]
+func ApiRequestAgentUpgradesHandler(
+	agentUpgradesService services.AgentUpgradesService,
+	hostsService services.HostsService,
+	auditService services.AuditService,
+	agentChannelHub *AgentChannelHub,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request JSONAgentUpgradeRequest
+
+		err := c.BindJSON(&request)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if len(request.HostIDs) == 0 || len(request.HostIDs) > maxAgentUpgradeHosts {
+			_ = c.Error(BadRequestError(fmt.Sprintf("between 1 and %d hosts must be upgraded at once", maxAgentUpgradeHosts)))
+			return
+		}
+
+		agentVersion, err := agentUpgradesService.GetVersion(request.Version)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if agentVersion == nil {
+			_ = c.Error(NotFoundError(fmt.Sprintf("could not find the agent version %q", request.Version)))
+			return
+		}
+
+		for _, id := range request.HostIDs {
+			host, err := hostsService.GetByID(id)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+
+			if host == nil {
+				_ = c.Error(NotFoundError(fmt.Sprintf("could not find host %q", id)))
+				return
+			}
+		}
+
+		upgrades, err := agentUpgradesService.Request(request.HostIDs, agentVersion.Version, requestActor(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		for _, upgrade := range upgrades {
+			recordAudit(c, auditService, models.AuditActionAgentUpgradeRequested, models.TagHostResourceType, upgrade.AgentID,
+				nil, upgrade)
+
+			if !agentChannelHub.IsConnected(upgrade.AgentID) {
+				continue
+			}
+
+			if deliverAgentUpgrade(agentChannelHub, agentUpgradesService, upgrade.AgentID, agentVersion) {
+				upgrade.Status = models.AgentUpgradeStatusSent
+			}
+		}
+
+		c.JSON(http.StatusAccepted, upgrades)
+	}
+}
+
+// deliverAgentUpgrade sends the pending upgrade to the agent, returning false if it is left pending
+func deliverAgentUpgrade(hub *AgentChannelHub, agentUpgradesService services.AgentUpgradesService, agentID string, agentVersion *models.AgentVersion) bool {
+	message, err := newAgentMessage(hosts.AgentMessageUpgradeRequested, &hosts.UpgradeRequest{
+		Version:  agentVersion.Version,
+		URL:      agentVersion.URL,
+		Checksum: agentVersion.Checksum,
+	})
+	if err != nil {
+		log.Errorf("Error while preparing the upgrade of agent %s: %s", agentID, err)
+		return false
+	}
+
+	// recorded before sending the message, for the acknowledgement not to race with it
+	if err := agentUpgradesService.MarkSent(agentID, message.ID); err != nil {
+		log.Errorf("Error while marking the upgrade of agent %s as sent: %s", agentID, err)
+		return false
+	}
+
+	if hub.Send(agentID, message) {
+		return true
+	}
+
+	if err := agentUpgradesService.MarkPending(agentID, message.ID); err != nil {
+		log.Errorf("Error while marking the upgrade of agent %s as pending: %s", agentID, err)
+	}
+
+	return false
+}
+
+// deliverPendingAgentUpgrade sends the agent connecting the upgrade requested while it was disconnected
+func deliverPendingAgentUpgrade(hub *AgentChannelHub, agentUpgradesService services.AgentUpgradesService, agentID string) {
+	upgrade, err := agentUpgradesService.GetPending(agentID)
+	if err != nil {
+		log.Errorf("Error while getting the pending upgrade of agent %s: %s", agentID, err)
+		return
+	}
+
+	if upgrade == nil {
+		return
+	}
+
+	agentVersion, err := agentUpgradesService.GetVersion(upgrade.Version)
+	if err != nil || agentVersion == nil {
+		log.Errorf("Error while getting the agent version %s to upgrade agent %s to: %v", upgrade.Version, agentID, err)
+		return
+	}
+
+	deliverAgentUpgrade(hub, agentUpgradesService, agentID, agentVersion)
+}
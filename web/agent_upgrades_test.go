@@ -0,0 +1,120 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+const dummyChecksum = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestApiSaveAgentVersionHandler(t *testing.T) {
+	agentUpgradesService := new(services.MockAgentUpgradesService)
+	agentUpgradesService.On("GetVersion", "1.1.0").Return(nil, nil)
+	agentUpgradesService.On("SaveVersion", mock.Anything).Return(nil)
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/agent-versions", ApiSaveAgentVersionHandler(agentUpgradesService, newMockedAuditService()))
+
+	for body, code := range map[string]int{
+		`{"version": "1.1.0", "url": "https://example.com/trento-agent", "checksum": "` + dummyChecksum + `"}`: 201,
+		`{"version": "1.1.0", "url": "example.com/trento-agent", "checksum": "` + dummyChecksum + `"}`:         400,
+		`{"version": "1.1.0", "url": "https://example.com/trento-agent", "checksum": "abc"}`:                   400,
+		`{"url": "https://example.com/trento-agent", "checksum": "` + dummyChecksum + `"}`:                     400,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/agent-versions", bytes.NewBufferString(body))
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, code, resp.Code, body)
+	}
+
+	agentUpgradesService.AssertNumberOfCalls(t, "SaveVersion", 1)
+}
+
+func TestApiRequestAgentUpgradesHandler(t *testing.T) {
+	agentVersion := &models.AgentVersion{Version: "1.1.0", URL: "https://example.com/trento-agent", Checksum: dummyChecksum}
+
+	agentUpgradesService := new(services.MockAgentUpgradesService)
+	agentUpgradesService.On("GetVersion", "1.1.0").Return(agentVersion, nil)
+	agentUpgradesService.On("GetVersion", "2.0.0").Return(nil, nil)
+	agentUpgradesService.On("Request", []string{"1", "2"}, "1.1.0", mock.Anything).Return([]*models.AgentUpgrade{
+		{AgentID: "1", Version: "1.1.0", Status: models.AgentUpgradeStatusPending},
+		{AgentID: "2", Version: "1.1.0", Status: models.AgentUpgradeStatusPending},
+	}, nil)
+	agentUpgradesService.On("MarkSent", "1", mock.Anything).Return(nil)
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "1").Return(&models.Host{ID: "1"}, nil)
+	hostsService.On("GetByID", "2").Return(&models.Host{ID: "2"}, nil)
+	hostsService.On("GetByID", "unknown").Return(nil, nil)
+
+	hub := NewAgentChannelHub()
+	channel := hub.register("1")
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/agent-upgrades", ApiRequestAgentUpgradesHandler(agentUpgradesService, hostsService, newMockedAuditService(), hub))
+
+	for body, code := range map[string]int{
+		`{"version": "2.0.0", "host_ids": ["1"]}`:       404,
+		`{"version": "1.1.0", "host_ids": ["unknown"]}`: 404,
+		`{"version": "1.1.0", "host_ids": []}`:          400,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/agent-upgrades", bytes.NewBufferString(body))
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, code, resp.Code, body)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/agent-upgrades", bytes.NewBufferString(`{"version": "1.1.0", "host_ids": ["1", "2"]}`))
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+
+	var upgrades []*models.AgentUpgrade
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &upgrades))
+	assert.Equal(t, models.AgentUpgradeStatusSent, upgrades[0].Status)
+	// not connected, the upgrade is delivered once the agent connects
+	assert.Equal(t, models.AgentUpgradeStatusPending, upgrades[1].Status)
+
+	sent := <-channel.send
+	assert.Equal(t, hosts.AgentMessageUpgradeRequested, sent.Type)
+	assert.JSONEq(t, `{"version": "1.1.0", "url": "https://example.com/trento-agent", "checksum": "`+dummyChecksum+`"}`, string(sent.Payload))
+	agentUpgradesService.AssertCalled(t, "MarkSent", "1", sent.ID)
+	agentUpgradesService.AssertNotCalled(t, "MarkSent", "2", mock.Anything)
+}
+
+func TestDeliverPendingAgentUpgrade(t *testing.T) {
+	agentVersion := &models.AgentVersion{Version: "1.1.0", URL: "https://example.com/trento-agent", Checksum: dummyChecksum}
+
+	agentUpgradesService := new(services.MockAgentUpgradesService)
+	agentUpgradesService.On("GetPending", "1").Return(&models.AgentUpgrade{AgentID: "1", Version: "1.1.0"}, nil)
+	agentUpgradesService.On("GetVersion", "1.1.0").Return(agentVersion, nil)
+	agentUpgradesService.On("MarkSent", "1", mock.Anything).Return(nil)
+	agentUpgradesService.On("MarkPending", "1", mock.Anything).Return(nil)
+
+	hub := NewAgentChannelHub()
+
+	// disconnected meanwhile
+	deliverPendingAgentUpgrade(hub, agentUpgradesService, "1")
+	agentUpgradesService.AssertNumberOfCalls(t, "MarkPending", 1)
+
+	channel := hub.register("1")
+	deliverPendingAgentUpgrade(hub, agentUpgradesService, "1")
+
+	sent := <-channel.send
+	assert.Equal(t, hosts.AgentMessageUpgradeRequested, sent.Type)
+	agentUpgradesService.AssertNumberOfCalls(t, "MarkPending", 1)
+}
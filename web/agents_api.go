@@ -0,0 +1,215 @@
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// agentBacklogProjector projects the events held back while the agent was pending approval
+type agentBacklogProjector interface {
+	ProjectAgent(ctx context.Context, agentID string) error
+}
+
+// ApiListAgentsHandler godoc
+// @Summary Retrieve the agents enrolled since the approval is required, and their approval status
+// @Produce json
+// @Param status query string false "Status of the agents" Enums(pending, approved, rejected)
+// @Success 200 {array} models.Agent
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agents [get]
+func ApiListAgentsHandler(agentsService services.AgentsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := c.Query("status")
+		if status != "" && !models.IsValidAgentStatus(status) {
+			_ = c.Error(BadRequestError("invalid status: " + status))
+			return
+		}
+
+		agents, err := agentsService.GetAll(status, userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, agents)
+	}
+}
+
+// ApiGetAgentsOverviewHandler godoc
+// @Summary Retrieve the activity of the agents of the hosts: last heartbeat, version and payloads by discovery type
+// @Produce json
+// @Success 200 {array} models.AgentOverview
+// @Failure 500 {object} map[string]string
+// @Router /agents/overview [get]
+func ApiGetAgentsOverviewHandler(hostsService services.HostsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		overviews, err := hostsService.GetAgentsOverview(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, overviews)
+	}
+}
+
+// ApiApproveAgentHandler godoc
+// @Summary Approve an agent, the data it collected so far is projected
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 200 {object} models.Agent
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agents/{id}/approve [post]
+func ApiApproveAgentHandler(agentsService services.AgentsService, backlogProjector agentBacklogProjector, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		agent, err := agentsService.Approve(id, requestActor(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if agent == nil {
+			_ = c.Error(NotFoundError("agent not found"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionAgentApproved, models.AuditResourceAgent, id, nil, agent)
+
+		// the approval is not undone, the next discoveries of the agent are projected anyway
+		if err := backlogProjector.ProjectAgent(context.Background(), id); err != nil {
+			log.Errorf("Could not project the events of the approved agent %s: %s", id, err)
+		}
+
+		c.JSON(http.StatusOK, agent)
+	}
+}
+
+// ApiRejectAgentHandler godoc
+// @Summary Reject an agent, the collector refuses its requests
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 200 {object} models.Agent
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agents/{id}/reject [post]
+func ApiRejectAgentHandler(agentsService services.AgentsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		agent, err := agentsService.Reject(id, requestActor(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if agent == nil {
+			_ = c.Error(NotFoundError("agent not found"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionAgentRejected, models.AuditResourceAgent, id, nil, agent)
+
+		c.JSON(http.StatusOK, agent)
+	}
+}
+
+// ApiRotateAgentSigningSecretHandler godoc
+// @Summary Generate the secret an agent signs its payloads with, replacing the previous one
+// @Description The secret is only returned once, the agent must be configured with it right away
+// @Description as its payloads are refused unless signed with it
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 200 {object} models.AgentSigningSecret
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agents/{id}/secret [put]
+func ApiRotateAgentSigningSecretHandler(payloadSignaturesService services.PayloadSignaturesService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		if err := validateText("id", id, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		signingSecret, err := payloadSignaturesService.Rotate(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionAgentSecretRotated, models.AuditResourceAgent, id, nil, nil)
+
+		c.JSON(http.StatusOK, signingSecret)
+	}
+}
+
+// ApiDeleteAgentSigningSecretHandler godoc
+// @Summary Delete the signing secret of an agent, its payloads are not required to be signed anymore
+// @Description unless the signature is required for every agent
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agents/{id}/secret [delete]
+func ApiDeleteAgentSigningSecretHandler(payloadSignaturesService services.PayloadSignaturesService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		signingSecret, err := payloadSignaturesService.Delete(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if signingSecret == nil {
+			_ = c.Error(NotFoundError("the agent has no signing secret"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionAgentSecretDeleted, models.AuditResourceAgent, id, signingSecret, nil)
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// ApiResetAgentEnrollmentHandler godoc
+// @Summary Forget the secret an agent was issued at its first enrollment, to enroll it again from a new installation
+// @Description Until then the enrollments of its agent ID without the secret are refused
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agents/{id}/enrollment [delete]
+func ApiResetAgentEnrollmentHandler(agentEnrollmentsService services.AgentEnrollmentsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		reset, err := agentEnrollmentsService.Reset(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !reset {
+			_ = c.Error(NotFoundError("the agent is not enrolled"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionAgentEnrollmentReset, models.AuditResourceAgent, id, nil, nil)
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,247 @@
+package web
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type agentBacklogProjectorStub struct {
+	projected []string
+}
+
+func (p *agentBacklogProjectorStub) ProjectAgent(_ context.Context, agentID string) error {
+	p.projected = append(p.projected, agentID)
+	return nil
+}
+
+func TestApiListAgentsHandler(t *testing.T) {
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("GetAll", models.AgentStatusPending, "").Return([]*models.Agent{
+		{ID: "agent1", Status: models.AgentStatusPending, EnrolledAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.agentsService = agentsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/agents?status=pending", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"id": "agent1",
+		"status": "pending",
+		"enrolled_at": "2022-01-01T00:00:00Z",
+		"reviewed_at": null,
+		"reviewed_by": ""
+	}]`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/agents?status=unknown", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestApiGetAgentsOverviewHandler(t *testing.T) {
+	lastSeenAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetAgentsOverview", "").Return([]*models.AgentOverview{
+		{
+			AgentID:    "agent1",
+			Hostname:   "host1",
+			Version:    "1.0.0",
+			LastSeenAt: &lastSeenAt,
+			Heartbeat:  models.HostHealthPassing,
+			Payloads:   map[string]int64{"host_discovery": 3},
+			Errors:     map[string]int64{"cloud_discovery": 1},
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = hostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/agents/overview", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"agent_id": "agent1",
+		"hostname": "host1",
+		"version": "1.0.0",
+		"last_seen_at": "2022-01-01T00:00:00Z",
+		"heartbeat": "passing",
+		"payloads": {"host_discovery": 3},
+		"errors": {"cloud_discovery": 1}
+	}]`, resp.Body.String())
+	hostsService.AssertExpectations(t)
+}
+
+func TestApiApproveAgentHandler(t *testing.T) {
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("Approve", "agent1", mock.Anything).Return(&models.Agent{ID: "agent1", Status: models.AgentStatusApproved}, nil)
+	agentsService.On("Approve", "unknown", mock.Anything).Return(nil, nil)
+	projector := &agentBacklogProjectorStub{}
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/agents/:id/approve", ApiApproveAgentHandler(agentsService, projector, newMockedAuditService()))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/agents/agent1/approve", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, []string{"agent1"}, projector.projected)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/agents/unknown/approve", nil)
+	req.Header.Set("Accept", "application/json")
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+	assert.Equal(t, []string{"agent1"}, projector.projected)
+}
+
+func TestApiRejectAgentHandler(t *testing.T) {
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("Reject", "agent1", testUser).Return(&models.Agent{ID: "agent1", Status: models.AgentStatusRejected}, nil)
+
+	deps := setupTestDependencies()
+	deps.agentsService = agentsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/agents/agent1/reject", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	agentsService.AssertExpectations(t)
+}
+
+func TestApiRotateAgentSigningSecretHandler(t *testing.T) {
+	createdAt := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	payloadSignaturesService := new(services.MockPayloadSignaturesService)
+	payloadSignaturesService.On("Rotate", "agent1").Return(&models.AgentSigningSecret{
+		AgentID:   "agent1",
+		Secret:    "some-secret",
+		CreatedAt: createdAt,
+	}, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		// the secret is not recorded
+		return e.Action == models.AuditActionAgentSecretRotated && e.ResourceID == "agent1" && e.After == nil
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.signaturesService = payloadSignaturesService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/api/agents/agent1/secret", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"agent_id": "agent1", "secret": "some-secret", "created_at": "2022-03-01T10:00:00Z"}`, resp.Body.String())
+	auditService.AssertExpectations(t)
+}
+
+func TestApiDeleteAgentSigningSecretHandler(t *testing.T) {
+	payloadSignaturesService := new(services.MockPayloadSignaturesService)
+	payloadSignaturesService.On("Delete", "agent1").Return(&models.AgentSigningSecret{AgentID: "agent1"}, nil)
+	payloadSignaturesService.On("Delete", "agent2").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.signaturesService = payloadSignaturesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/agents/agent1/secret", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("DELETE", "/api/agents/agent2/secret", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiResetAgentEnrollmentHandler(t *testing.T) {
+	agentEnrollmentsService := new(services.MockAgentEnrollmentsService)
+	agentEnrollmentsService.On("Reset", "agent1").Return(true, nil)
+	agentEnrollmentsService.On("Reset", "agent2").Return(false, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionAgentEnrollmentReset && e.ResourceID == "agent1"
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.enrollmentsService = agentEnrollmentsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/agents/agent1/enrollment", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	auditService.AssertExpectations(t)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("DELETE", "/api/agents/agent2/enrollment", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
@@ -0,0 +1,194 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+
+	apiDocs "github.com/trento-project/trento/docs/api"
+)
+
+const apiDocsAssetsPrefix = "/docs/api/assets"
+
+// The docs are served under the version of the API they describe, so clients can pin the links
+var apiDocsVersion = "v" + apiDocs.SwaggerInfo.Version
+
+type APIExample struct {
+	Method  string
+	Path    string
+	Summary string
+	Curl    string
+}
+
+// Only the subset of the OpenAPI 2.0 spec needed to build the examples
+type swaggerSpec struct {
+	BasePath    string                                 `json:"basePath"`
+	Paths       map[string]map[string]swaggerOperation `json:"paths"`
+	Definitions map[string]*swaggerSchema              `json:"definitions"`
+}
+
+type swaggerOperation struct {
+	Summary    string             `json:"summary"`
+	Parameters []swaggerParameter `json:"parameters"`
+}
+
+type swaggerParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *swaggerSchema `json:"schema"`
+}
+
+type swaggerSchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       string                    `json:"type"`
+	Items      *swaggerSchema            `json:"items"`
+	Properties map[string]*swaggerSchema `json:"properties"`
+}
+
+func DocsRedirectHandler(c *gin.Context) {
+	c.Redirect(http.StatusFound, "/docs/api/"+apiDocsVersion)
+}
+
+func DocsHandler(c *gin.Context) {
+	if c.Param("version") != apiDocsVersion {
+		_ = c.Error(NotFoundError("unknown API version"))
+		return
+	}
+
+	examples, err := buildCurlExamples([]byte(apiDocs.SwaggerInfo.ReadDoc()), requestBaseURL(c))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.HTML(http.StatusOK, "api_docs.html.tmpl", gin.H{
+		"Version":  apiDocsVersion,
+		"SpecURL":  fmt.Sprintf("/docs/api/%s/swagger.json", apiDocsVersion),
+		"Examples": examples,
+	})
+}
+
+func DocsSpecHandler(c *gin.Context) {
+	if c.Param("version") != apiDocsVersion {
+		_ = c.Error(NotFoundError("unknown API version"))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(apiDocs.SwaggerInfo.ReadDoc()))
+}
+
+// DocsAssetsHandler serves the swagger UI static files
+func DocsAssetsHandler() gin.HandlerFunc {
+	return gin.WrapH(http.StripPrefix(apiDocsAssetsPrefix, swaggerFiles.Handler))
+}
+
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, c.Request.Host)
+}
+
+// buildCurlExamples generates a curl command line for every operation of the spec
+func buildCurlExamples(rawSpec []byte, baseURL string) ([]*APIExample, error) {
+	var spec swaggerSpec
+
+	err := json.Unmarshal(rawSpec, &spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var examples []*APIExample
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			examples = append(examples, &APIExample{
+				Method:  strings.ToUpper(method),
+				Path:    spec.BasePath + path,
+				Summary: operation.Summary,
+				Curl:    buildCurl(&spec, baseURL, path, method, &operation),
+			})
+		}
+	}
+
+	sort.Slice(examples, func(i, j int) bool {
+		if examples[i].Path != examples[j].Path {
+			return examples[i].Path < examples[j].Path
+		}
+		return examples[i].Method < examples[j].Method
+	})
+
+	return examples, nil
+}
+
+func buildCurl(spec *swaggerSpec, baseURL string, path string, method string, operation *swaggerOperation) string {
+	var query []string
+	var body interface{}
+
+	for _, p := range operation.Parameters {
+		switch p.In {
+		case "query":
+			if p.Required {
+				query = append(query, fmt.Sprintf("%s={%s}", p.Name, p.Name))
+			}
+		case "body":
+			body = exampleValue(spec, p.Schema, 0)
+		}
+	}
+
+	url := baseURL + spec.BasePath + path
+	if len(query) > 0 {
+		url += "?" + strings.Join(query, "&")
+	}
+
+	lines := []string{
+		fmt.Sprintf("curl -X %s \"%s\"", strings.ToUpper(method), url),
+		"-H \"Authorization: Bearer $TRENTO_API_TOKEN\"",
+	}
+
+	if body != nil {
+		jsonBody, _ := json.Marshal(body)
+		lines = append(lines, "-H \"Content-Type: application/json\"", fmt.Sprintf("-d '%s'", jsonBody))
+	}
+
+	return strings.Join(lines, " \\\n  ")
+}
+
+// exampleValue builds a placeholder value matching the schema
+func exampleValue(spec *swaggerSpec, schema *swaggerSchema, depth int) interface{} {
+	if schema == nil || depth > 5 {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		return exampleValue(spec, spec.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")], depth+1)
+	}
+
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		item := exampleValue(spec, schema.Items, depth+1)
+		if item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{item}
+	default:
+		object := make(map[string]interface{})
+		for name, property := range schema.Properties {
+			object[name] = exampleValue(spec, property, depth+1)
+		}
+		return object
+	}
+}
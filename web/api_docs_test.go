@@ -0,0 +1,114 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocsHandler(t *testing.T) {
+	app, err := NewAppWithDeps(setupTestConfig(), setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/docs/api/"+apiDocsVersion, nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), "data-spec-url=\"/docs/api/"+apiDocsVersion+"/swagger.json\"")
+	assert.Contains(t, resp.Body.String(), "curl -X GET &#34;http://example.com/api/checks/catalog&#34;")
+}
+
+func TestDocsHandlerUnknownVersion(t *testing.T) {
+	app, err := NewAppWithDeps(setupTestConfig(), setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/docs/api/v0.1", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestDocsSpecHandler(t *testing.T) {
+	app, err := NewAppWithDeps(setupTestConfig(), setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/docs/api/"+apiDocsVersion+"/swagger.json", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), "\"/hosts/{id}/availability\"")
+}
+
+func TestDocsAssetsHandler(t *testing.T) {
+	app, err := NewAppWithDeps(setupTestConfig(), setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/docs/api/assets/swagger-ui.css", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+}
+
+func TestBuildCurlExamples(t *testing.T) {
+	spec := `{
+		"basePath": "/api",
+		"paths": {
+			"/hosts/{id}/tags": {
+				"post": {
+					"summary": "Add tag to host",
+					"parameters": [
+						{"name": "id", "in": "path", "required": true},
+						{"name": "Body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/web.JSONTag"}}
+					]
+				}
+			},
+			"/tags": {
+				"get": {
+					"summary": "Retrieves the tags list",
+					"parameters": [
+						{"name": "resource_type", "in": "query", "required": true},
+						{"name": "other", "in": "query"}
+					]
+				}
+			}
+		},
+		"definitions": {
+			"web.JSONTag": {"type": "object", "properties": {"tag": {"type": "string"}}}
+		}
+	}`
+
+	examples, err := buildCurlExamples([]byte(spec), "https://trento.local")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*APIExample{
+		{
+			Method:  "POST",
+			Path:    "/api/hosts/{id}/tags",
+			Summary: "Add tag to host",
+			Curl: "curl -X POST \"https://trento.local/api/hosts/{id}/tags\" \\\n" +
+				"  -H \"Authorization: Bearer $TRENTO_API_TOKEN\" \\\n" +
+				"  -H \"Content-Type: application/json\" \\\n" +
+				"  -d '{\"tag\":\"string\"}'",
+		},
+		{
+			Method:  "GET",
+			Path:    "/api/tags",
+			Summary: "Retrieves the tags list",
+			Curl: "curl -X GET \"https://trento.local/api/tags?resource_type={resource_type}\" \\\n" +
+				"  -H \"Authorization: Bearer $TRENTO_API_TOKEN\"",
+		},
+	}, examples)
+}
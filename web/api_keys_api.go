@@ -0,0 +1,101 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type JSONApiKeyCreation struct {
+	Name  string `json:"name" binding:"required"`
+	Scope string `json:"scope" binding:"required,oneof=read write"`
+}
+
+// ApiListApiKeysHandler godoc
+// @Summary Retrieve the API keys, revoked ones included
+// @Description The keys of the organization of the logged in user only, if any
+// @Produce json
+// @Success 200 {array} models.ApiKey
+// @Failure 500 {object} map[string]string
+// @Router /keys [get]
+func ApiListApiKeysHandler(apiKeysService services.ApiKeysService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKeys, err := apiKeysService.GetAll(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, apiKeys)
+	}
+}
+
+// ApiCreateApiKeyHandler godoc
+// @Summary Create an API key, to be sent as a bearer token
+// @Description The key is only returned in this response, it cannot be retrieved afterwards.
+// @Description The key is restricted to the organization of the logged in user, if any
+// @Accept json
+// @Produce json
+// @Param Body body JSONApiKeyCreation true "The API key"
+// @Success 201 {object} models.ApiKey
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /keys [post]
+func ApiCreateApiKeyHandler(apiKeysService services.ApiKeysService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONApiKeyCreation
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		apiKey, err := apiKeysService.Create(r.Name, r.Scope, userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		// the key itself is never recorded
+		recordAudit(c, auditService, models.AuditActionApiKeyCreated, models.AuditResourceApiKey, strconv.FormatInt(apiKey.ID, 10), nil, &r)
+
+		c.JSON(http.StatusCreated, apiKey)
+	}
+}
+
+// ApiRevokeApiKeyHandler godoc
+// @Summary Revoke an API key
+// @Produce json
+// @Param id path int true "API key id"
+// @Success 200 {object} models.ApiKey
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /keys/{id} [delete]
+func ApiRevokeApiKeyHandler(apiKeysService services.ApiKeysService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			_ = c.Error(NotFoundError("API key not found"))
+			return
+		}
+
+		apiKey, err := apiKeysService.Revoke(id, userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if apiKey == nil {
+			_ = c.Error(NotFoundError("API key not found"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionApiKeyRevoked, models.AuditResourceApiKey, c.Param("id"), nil, nil)
+
+		c.JSON(http.StatusOK, apiKey)
+	}
+}
@@ -0,0 +1,112 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func setupApiKeysApiTestApp(t *testing.T, apiKeysService services.ApiKeysService) *App {
+	deps := setupTestDependencies()
+	deps.apiKeysService = apiKeysService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func TestApiListApiKeysHandler(t *testing.T) {
+	lastUsedAt := time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	apiKeysService := new(services.MockApiKeysService)
+	apiKeysService.On("GetAll", "").Return([]*models.ApiKey{
+		{
+			ID:         1,
+			Name:       "runner",
+			Prefix:     "trento_abcdef",
+			Scope:      models.ApiKeyScopeWrite,
+			CreatedAt:  time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			LastUsedAt: &lastUsedAt,
+		},
+	}, nil)
+
+	app := setupApiKeysApiTestApp(t, apiKeysService)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/keys", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"id": 1,
+		"name": "runner",
+		"prefix": "trento_abcdef",
+		"scope": "write",
+		"created_at": "2022-01-01T00:00:00Z",
+		"last_used_at": "2022-01-02T00:00:00Z",
+		"revoked_at": null
+	}]`, resp.Body.String())
+}
+
+func TestApiCreateApiKeyHandler(t *testing.T) {
+	apiKeysService := new(services.MockApiKeysService)
+	apiKeysService.On("Create", "dashboard", models.ApiKeyScopeRead, "").Return(&models.ApiKey{
+		ID: 2, Name: "dashboard", Prefix: "trento_abcdef", Scope: models.ApiKeyScopeRead, Key: "trento_abcdefghij",
+	}, nil)
+
+	app := setupApiKeysApiTestApp(t, apiKeysService)
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"name": "dashboard", "scope": "read"}`)
+	req := httptest.NewRequest("POST", "/api/keys", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 201, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"key":"trento_abcdefghij"`)
+	apiKeysService.AssertExpectations(t)
+
+	resp = httptest.NewRecorder()
+	body = bytes.NewBufferString(`{"name": "dashboard", "scope": "admin"}`)
+	req = httptest.NewRequest("POST", "/api/keys", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestApiRevokeApiKeyHandler(t *testing.T) {
+	revokedAt := time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	apiKeysService := new(services.MockApiKeysService)
+	apiKeysService.On("Revoke", int64(1), "").Return(&models.ApiKey{ID: 1, Name: "runner", RevokedAt: &revokedAt}, nil)
+	apiKeysService.On("Revoke", int64(2), "").Return(nil, nil)
+
+	app := setupApiKeysApiTestApp(t, apiKeysService)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/keys/1", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"revoked_at":"2022-01-03T00:00:00Z"`)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("DELETE", "/api/keys/2", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
@@ -31,11 +31,12 @@ func TestApiDocsRouteTest(t *testing.T) {
 	req := httptest.NewRequest("GET", "/api/docs/index.html", nil)
 	app.webEngine.ServeHTTP(resp, req)
 
-	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, 302, resp.Code)
+	assert.Equal(t, "/docs/api/"+apiDocsVersion, resp.Header().Get("Location"))
 
 	resp = httptest.NewRecorder()
 	req = httptest.NewRequest("GET", "/api/docs/doc.json", nil)
 	app.webEngine.ServeHTTP(resp, req)
 
-	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, 302, resp.Code)
 }
@@ -3,16 +3,17 @@ package web
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"embed"
-	"fmt"
-	"io/ioutil"
+	"errors"
+	"io/fs"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
@@ -22,16 +23,14 @@ import (
 	trentoDB "github.com/trento-project/trento/internal/db"
 	"github.com/trento-project/trento/internal/grafana"
 	trentoPrometheus "github.com/trento-project/trento/internal/prometheus"
+	"github.com/trento-project/trento/internal/proxy"
 	"github.com/trento-project/trento/version"
+	"github.com/trento-project/trento/web/chaos"
 	"github.com/trento-project/trento/web/datapipeline"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 	"github.com/trento-project/trento/web/telemetry"
-
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
-	_ "github.com/trento-project/trento/docs/api" // docs is generated by Swag CLI, you have to import it.
 )
 
 //go:embed frontend/assets
@@ -44,14 +43,27 @@ var DBTables = []interface{}{
 	&entities.Settings{}, &models.Tag{}, &models.SelectedChecks{}, &models.ConnectionSettings{},
 	&entities.Check{}, &datapipeline.DataCollectedEvent{}, &datapipeline.Subscription{},
 	&entities.HostTelemetry{}, &entities.Cluster{}, &entities.Host{}, &entities.HostHeartbeat{},
+	&entities.HostHeartbeatPeriod{}, &entities.HostAvailabilityRollup{},
 	&entities.SlesSubscription{}, &entities.SAPSystemInstance{}, &entities.ChecksResult{},
-	&entities.HealthState{},
+	&entities.HealthState{}, &entities.UserPreferences{}, &entities.Favorite{},
+	&entities.DBMaintenanceReport{}, &entities.HostUtilizationSnapshot{},
+	&entities.KubernetesWorkload{}, &entities.AddressConflict{},
+	&entities.QueuedRun{}, &entities.User{}, &entities.ApiKey{}, &entities.HealthHistoryRecord{},
+	&entities.AuditEntry{}, &entities.CapturedPayload{}, &entities.Agent{},
+	&entities.LoginThrottle{}, &entities.Inconsistency{}, &entities.ResourceRestriction{},
+	&entities.HostBaseline{}, &entities.PersonalAccessToken{}, &entities.AgentSigningSecret{}, &entities.AgentEnrollment{},
+	&entities.UsageCounter{}, &entities.SearchDocument{}, &entities.RejectedPayload{}, &entities.AgentInfo{}, &entities.PayloadDigest{},
+	&datapipeline.DisabledProjector{}, &entities.AgentLogs{}, &entities.HostMetricSample{},
+	&entities.AgentVersion{}, &entities.AgentUpgrade{}, &entities.IngestionUsage{},
+	&datapipeline.ProjectedDiscovery{}, &datapipeline.DeadLetter{},
 }
 
 type App struct {
 	InstallationID uuid.UUID
 	config         *Config
 	Dependencies
+	collectorSpool    *CollectorSpool
+	spoolReplayEngine *gin.Engine
 }
 
 type Config struct {
@@ -62,16 +74,112 @@ type Config struct {
 	Cert          string
 	Key           string
 	CA            string
-	DBConfig      *trentoDB.Config
-	GrafanaConfig *grafana.Config
-	PrometheusURL string
+	// CRL lists the revoked agent certificates, it is optional
+	CRL string
+	// MTLSVerifyAgentID binds the agents to their client certificate, the agent ID matching its CN or a DNS SAN
+	MTLSVerifyAgentID bool
+	// EnableJWT makes the agents authenticate to the collector with a JWT, an alternative to mTLS.
+	// The JWTs are signed with JWTSecret and issued to the agents presenting the EnrollmentToken
+	EnableJWT       bool
+	JWTSecret       string
+	JWTTTL          time.Duration
+	EnrollmentToken string
+	// OrganizationEnrollmentTokens by organization, the JWTs of the agents enrolling with them
+	// bind the data they collect to the organization
+	OrganizationEnrollmentTokens map[string]string
+	// RequireAgentApproval holds back the data of the new agents until an admin approves them
+	RequireAgentApproval bool
+	// RejectUnknownAgents disables the auto-registration of the agents: the data of the agents never seen before
+	// is refused, the agents being listed as pending until an admin approves them
+	RejectUnknownAgents bool
+	SessionConfig       *SessionConfig
+	DBConfig            *trentoDB.Config
+	GrafanaConfig       *grafana.Config
+	PrometheusURL       string
+	// Run the safe maintenance operations recommended by the database maintenance advisor
+	DBMaintenanceAutoVacuum bool
+	ChaosConfig             *chaos.Config
+	// Serve templates and static assets from the web sources directory instead of the embedded ones
+	DevMode   bool
+	DevWebDir string
+	// Directory whose templates take precedence over the embedded ones, e.g. templates/blocks/footer.html.tmpl,
+	// the new ones being added
+	TemplatesOverrideDir string
+	// User created at startup when there are no users yet, skipped if the password is empty
+	AdminUser     string
+	AdminPassword string
+	// Hosts tagged with EphemeralHostsTag, or whose agent is flagged as ephemeral,
+	// are removed after EphemeralHostsTTL without heartbeats
+	EphemeralHostsTag string
+	EphemeralHostsTTL time.Duration
+	// SAP systems whose instances all run on hosts not sending heartbeats for StaleSAPSystemsTTL are removed,
+	// 0 to never remove them
+	StaleSAPSystemsTTL time.Duration
+	// HeartbeatPeriodsRetention is the time the heartbeat periods rolled up in the availability aggregates are kept,
+	// at least services.MinHeartbeatPeriodsRetention. They are never pruned if 0
+	HeartbeatPeriodsRetention time.Duration
+	// EventsRetention is the time the collected events are kept, but the latest one of every agent discovery.
+	// They are never pruned if 0
+	EventsRetention time.Duration
+	// CollectorQueueThreshold of the events waiting to be projected, out of datapipeline.ProjectorsQueueSize,
+	// beyond which the collected data is refused with 429 Too Many Requests. Disabled if 0
+	CollectorQueueThreshold int
+	// CollectorMaxBodySize is the size in bytes the bodies of the collected data can have, once decompressed,
+	// the larger ones being refused with 413 Request Entity Too Large. DefaultCollectorMaxBodySize if 0
+	CollectorMaxBodySize int64
+	// IngestionQuota of every agent, the data exceeding it being refused with 429 Too Many Requests until the next day
+	IngestionQuota services.IngestionQuota
+	// ProjectionRetryPolicy of the projections failing in the worker pool, before being recorded as dead letters
+	ProjectionRetryPolicy datapipeline.RetryPolicy
+	// StaleHostWarningThreshold and StaleHostCriticalThreshold are the time without heartbeats after which
+	// the hosts are marked as degraded, and then critical. The services defaults are used if 0
+	StaleHostWarningThreshold  time.Duration
+	StaleHostCriticalThreshold time.Duration
+	RateLimitConfig            *RateLimitConfig
+	// ProxyConfig of the outbound HTTP requests, to Grafana, Prometheus and the telemetry service
+	ProxyConfig *proxy.Config
+	// CollectorAllowlist are the subnets, in CIDR notation, allowed to reach the collector, all if empty
+	CollectorAllowlist []string
+	// LicenseFile holds the entitlements, they are detected from the subscriptions without it
+	LicenseFile string
+	// EntitlementsEnforcement tells what happens once the limits are exceeded, see models.EntitlementsEnforcementSoft
+	EntitlementsEnforcement string
+	// ContentSecurityPolicy of the web pages, DefaultContentSecurityPolicy if empty
+	ContentSecurityPolicy string
+	// HSTSMaxAge of the Strict-Transport-Security header, not sent if 0
+	HSTSMaxAge time.Duration
+	// LoginMaxFailures in a row lock a user, or address, out for LoginLockoutDuration, with a backoff
+	// starting at LoginBackoff before. The throttling is disabled if 0
+	LoginMaxFailures     int
+	LoginBackoff         time.Duration
+	LoginLockoutDuration time.Duration
+	// CredentialsKey encrypts the connection settings of the checks and the signing secrets of the agents
+	// stored in the database, they are stored in plaintext if empty
+	CredentialsKey []byte
+	// RequirePayloadSignature refuses the collected data not signed by the agents, see the signing package.
+	// Otherwise only the data of the agents with a signing secret must be signed
+	RequirePayloadSignature bool
+	// CollectorSpoolDir enables the upgrade window: the collected data is spooled in the directory while
+	// the database is migrated, and replayed once the console is started
+	CollectorSpoolDir string
+	// CollectorGRPCPort the gRPC collector listens on, with mTLS, alongside the HTTP collector. Disabled if 0
+	CollectorGRPCPort int
+	// CollectorNatsURL of the NATS server the collected data is consumed from, alongside the HTTP collector. Disabled if empty
+	CollectorNatsURL string
+	// CollectorNatsSubject the agents publish their collected data to, DefaultCollectorNatsSubject if empty
+	CollectorNatsSubject string
+	// CollectorNatsConfig authenticates the console to the NATS server, anonymously and in plaintext if nil
+	CollectorNatsConfig *CollectorNatsConfig
+	// HealthStrategy computes the health from the checks results and aggregates it, services.DefaultHealthStrategy if nil
+	HealthStrategy services.HealthStrategy
 }
 
 type Dependencies struct {
 	webEngine               *gin.Engine
 	collectorEngine         *gin.Engine
-	store                   cookie.Store
+	store                   sessions.Store
 	projectorWorkersPool    *datapipeline.ProjectorsWorkerPool
+	backlogProjector        *datapipeline.BacklogProjector
 	checksService           services.ChecksService
 	subscriptionsService    services.SubscriptionsService
 	tagsService             services.TagsService
@@ -85,16 +193,44 @@ type Dependencies struct {
 	telemetryPublisher      telemetry.Publisher
 	premiumDetectionService services.PremiumDetectionService
 	prometheusService       services.PrometheusService
+	preferencesService      services.PreferencesService
+	favoritesService        services.FavoritesService
+	availabilityService     services.AvailabilityService
+	dbMaintenanceService    services.DBMaintenanceService
+	hostUtilizationService  services.HostUtilizationService
+	capacityService         services.CapacityService
+	addressConflictsService services.AddressConflictsService
+	runsQueueService        services.RunsQueueService
+	usersService            services.UsersService
+	landscapeService        services.LandscapeService
+	timelineService         services.TimelineService
+	apiKeysService          services.ApiKeysService
+	healthHistoryService    services.HealthHistoryService
+	auditService            services.AuditService
+	payloadCaptureService   services.PayloadCaptureService
+	agentsService           services.AgentsService
+	entitlementsService     services.EntitlementsService
+	loginThrottlingService  services.LoginThrottlingService
+	consistencyService      services.ConsistencyService
+	restrictionsService     services.ResourceRestrictionsService
+	baselinesService        services.BaselinesService
+	tokensService           services.PersonalAccessTokensService
+	costReportService       services.CostReportService
+	signaturesService       services.PayloadSignaturesService
+	logSampler              *LogSampler
+	usageService            services.UsageAnalyticsService
+	searchService           services.SearchService
+	projectorsManager       *datapipeline.ProjectorsManager
+	agentLogsService        services.AgentLogsService
+	agentConfigService      services.AgentConfigurationService
+	hostMetricsService      services.HostMetricsService
+	agentChannelHub         *AgentChannelHub
+	agentUpgradesService    services.AgentUpgradesService
+	organizationsService    services.OrganizationsService
+	enrollmentsService      services.AgentEnrollmentsService
 }
 
 func DefaultDependencies(ctx context.Context, config *Config) Dependencies {
-	webEngine := NewNamedEngine("public")
-	collectorEngine := NewNamedEngine("internal")
-	store := cookie.NewStore([]byte("secret"))
-	mode := os.Getenv(gin.EnvGinMode)
-
-	gin.SetMode(mode)
-
 	db, err := trentoDB.InitDB(ctx, config.DBConfig)
 	if err != nil {
 		log.Fatalf("failed initialazing the database: %s", err)
@@ -104,43 +240,149 @@ func DefaultDependencies(ctx context.Context, config *Config) Dependencies {
 		log.Fatalf("failed to migrate database: %s", err)
 	}
 
-	if err := grafana.InitGrafana(ctx, config.GrafanaConfig); err != nil {
+	chaosInjector := chaos.NewInjector(config.ChaosConfig)
+	if err := chaosInjector.RegisterDBFaults(db); err != nil {
+		log.Fatalf("failed to register the database faults: %s", err)
+	}
+
+	if err := grafana.InitGrafana(ctx, config.GrafanaConfig, config.ProxyConfig.For(proxy.Grafana)); err != nil {
 		log.Warnf("failed initialazing grafana: %s", err)
 	}
 
-	prom, err := trentoPrometheus.InitPrometheus(ctx, config.PrometheusURL)
+	prom, err := trentoPrometheus.InitPrometheus(ctx, config.PrometheusURL, config.ProxyConfig.For(proxy.Prometheus))
 	if err != nil {
 		log.Warnf("failed to create prometheus client: %s", err)
 	}
 
-	projectorRegistry := datapipeline.InitProjectorsRegistry(db)
-	projectorWorkersPool := datapipeline.NewProjectorsWorkerPool(projectorRegistry)
+	return NewDependencies(config, db, prom)
+}
+
+// NewDependencies wires the engines and the services on top of an already initialized and migrated database
+func NewDependencies(config *Config, db *gorm.DB, prom trentoPrometheus.PrometheusAPI) Dependencies {
+	logSampler := NewLogSampler()
+	webEngine := NewNamedEngine("public", logSampler)
+	collectorEngine := NewNamedEngine("internal", logSampler)
+	store, err := NewSessionStore(config.SessionConfig)
+	if err != nil {
+		log.Fatalf("failed to create the session store: %s", err)
+	}
+	mode := os.Getenv(gin.EnvGinMode)
+
+	gin.SetMode(mode)
+
+	chaosInjector := chaos.NewInjector(config.ChaosConfig)
+	projectorsManager := datapipeline.NewProjectorsManager(db, datapipeline.InitProjectorsRegistry(db))
+	if err := projectorsManager.Load(); err != nil {
+		log.Errorf("failed to load the disabled projectors: %s", err)
+	}
+	projectorRegistry := chaosInjector.WrapProjectors(projectorsManager.Registry())
+	projectorWorkersPool := datapipeline.NewProjectorsWorkerPool(projectorRegistry, config.ProjectionRetryPolicy)
+	backlogProjector := datapipeline.NewBacklogProjector(db, projectorRegistry)
+
+	healthStrategy := config.HealthStrategy
+	if healthStrategy == nil {
+		healthStrategy = services.DefaultHealthStrategy{}
+	}
 
 	prometheusService := services.NewPrometheusService(db, prom)
 	settingsService := services.NewSettingsService(db)
-	tagsService := services.NewTagsService(db)
+	tagsService := services.NewTagsService(services.NewTagsRepository(db))
 	subscriptionsService := services.NewSubscriptionsService(db)
-	hostsService := services.NewHostsService(db, prometheusService)
-	sapSystemsService := services.NewSAPSystemsService(db)
+	hostsService := services.NewHostsService(services.NewHostsRepository(db), prometheusService, services.EphemeralHostsPolicy{
+		Tag: config.EphemeralHostsTag,
+		TTL: config.EphemeralHostsTTL,
+	}, services.StaleHostsPolicy{
+		WarningThreshold:  config.StaleHostWarningThreshold,
+		CriticalThreshold: config.StaleHostCriticalThreshold,
+	})
+	sapSystemsService := services.NewSAPSystemsService(db, healthStrategy)
 	premiumDetection := services.NewPremiumDetectionService(version.Flavor, subscriptionsService, settingsService)
-	checksService := services.NewChecksService(db, premiumDetection)
-	clustersService := services.NewClustersService(db, checksService)
-	collectorService := services.NewCollectorService(db, projectorWorkersPool.GetChannel())
+	entitlementsService := services.NewEntitlementsService(services.EntitlementsPolicy{
+		LicenseFile: config.LicenseFile,
+		Enforcement: config.EntitlementsEnforcement,
+	}, hostsService, premiumDetection)
+	var credentialsCipher services.CredentialsCipher
+	if len(config.CredentialsKey) > 0 {
+		envelopeCipher, err := services.NewEnvelopeCipher(config.CredentialsKey)
+		if err != nil {
+			log.Fatalf("failed to create the credentials cipher: %s", err)
+		}
+		credentialsCipher = envelopeCipher
+	} else {
+		log.Warn("No credentials key configured, the connection settings of the checks are stored in plaintext")
+	}
+	checksService := services.NewChecksService(services.NewChecksRepository(db), entitlementsService, credentialsCipher, healthStrategy)
+	if encrypted, err := checksService.EncryptConnectionSettings(); err != nil {
+		log.Fatalf("failed to encrypt the connection settings: %s", err)
+	} else if encrypted > 0 {
+		log.Infof("Encrypted %d connection settings stored in plaintext", encrypted)
+	}
+	clustersService := services.NewClustersService(services.NewClustersRepository(db), checksService, healthStrategy)
+	collectorService := services.NewCollectorService(db, projectorWorkersPool.GetChannel(), config.IngestionQuota)
 	telemetryRegistry := telemetry.NewTelemetryRegistry(db)
-	telemetryPublisher := telemetry.NewTelemetryPublisher()
-	healthSummaryService := services.NewHealthSummaryService(sapSystemsService, clustersService, hostsService)
+	telemetryPublisher := telemetry.NewTelemetryPublisher(config.ProxyConfig.For(proxy.Telemetry))
+	healthHistoryService := services.NewHealthHistoryService(db, sapSystemsService, clustersService, hostsService)
+	healthSummaryService := services.NewHealthSummaryService(sapSystemsService, clustersService, hostsService, healthHistoryService, healthStrategy)
+	preferencesService := services.NewPreferencesService(db)
+	favoritesService := services.NewFavoritesService(db)
+	availabilityService := services.NewAvailabilityService(db)
+	dbMaintenanceService := services.NewDBMaintenanceService(db)
+	hostUtilizationService := services.NewHostUtilizationService(db)
+	capacityService := services.NewCapacityService(services.NewHostsRepository(db))
+	addressConflictsService := services.NewAddressConflictsService(db)
+	runsQueueService := services.NewRunsQueueService(db)
+	usersService := services.NewUsersService(db)
+	landscapeService := services.NewLandscapeService(sapSystemsService, clustersService, hostsService)
+	timelineService := services.NewTimelineService(db)
+	apiKeysService := services.NewApiKeysService(db)
+	auditService := services.NewAuditService(db)
+	payloadCaptureService := services.NewPayloadCaptureService(db)
+	agentsService := services.NewAgentsService(db, config.RequireAgentApproval, config.RejectUnknownAgents)
+	loginThrottlingService := services.NewLoginThrottlingService(db, services.LoginThrottlingPolicy{
+		MaxFailures:     config.LoginMaxFailures,
+		Backoff:         config.LoginBackoff,
+		LockoutDuration: config.LoginLockoutDuration,
+	})
+	consistencyService := services.NewConsistencyService(db)
+	restrictionsService := services.NewResourceRestrictionsService(db)
+	baselinesService := services.NewBaselinesService(db)
+	tokensService := services.NewPersonalAccessTokensService(db)
+	costReportService := services.NewCostReportService(db)
+	signaturesService := services.NewPayloadSignaturesService(db, config.RequirePayloadSignature, credentialsCipher)
+	usageService := services.NewUsageAnalyticsService(db)
+	searchService := services.NewSearchService(db)
+	agentLogsService := services.NewAgentLogsService(db)
+	agentConfigService := services.NewAgentConfigurationService(settingsService, hostsService, checksService)
+	hostMetricsService := services.NewHostMetricsService(db)
+	agentChannelHub := NewAgentChannelHub()
+	agentUpgradesService := services.NewAgentUpgradesService(db)
+	organizationsService := services.NewOrganizationsService(db)
+	enrollmentsService := services.NewAgentEnrollmentsService(db)
+	if indexed, err := searchService.IndexMissing(); err != nil {
+		log.Errorf("failed to index the resources for the search: %s", err)
+	} else if indexed > 0 {
+		log.Infof("Indexed %d resources for the search", indexed)
+	}
 
 	return Dependencies{
-		webEngine, collectorEngine, store, projectorWorkersPool,
+		webEngine, collectorEngine, store, projectorWorkersPool, backlogProjector,
 		checksService, subscriptionsService, tagsService,
 		collectorService, sapSystemsService, clustersService, hostsService, settingsService, healthSummaryService,
-		telemetryRegistry, telemetryPublisher, premiumDetection, prometheusService,
+		telemetryRegistry, telemetryPublisher, premiumDetection, prometheusService, preferencesService,
+		favoritesService, availabilityService, dbMaintenanceService, hostUtilizationService,
+		capacityService, addressConflictsService, runsQueueService, usersService,
+		landscapeService, timelineService, apiKeysService, healthHistoryService,
+		auditService, payloadCaptureService, agentsService, entitlementsService,
+		loginThrottlingService, consistencyService, restrictionsService, baselinesService, tokensService,
+		costReportService, signaturesService, logSampler, usageService, searchService,
+		projectorsManager, agentLogsService, agentConfigService, hostMetricsService, agentChannelHub,
+		agentUpgradesService, organizationsService, enrollmentsService,
 	}
 }
 
-func NewNamedEngine(instance string) *gin.Engine {
+func NewNamedEngine(instance string, logSampler *LogSampler) *gin.Engine {
 	engine := gin.New()
-	engine.Use(NewLogHandler(instance, log.StandardLogger()))
+	engine.Use(NewLogHandler(instance, log.StandardLogger(), logSampler))
 	engine.Use(gin.Recovery())
 	return engine
 }
@@ -157,7 +399,32 @@ func MigrateDB(db *gorm.DB) error {
 
 // shortcut to use default dependencies
 func NewApp(ctx context.Context, config *Config) (*App, error) {
-	return NewAppWithDeps(config, DefaultDependencies(ctx, config))
+	if config.CollectorSpoolDir == "" {
+		return NewAppWithDeps(config, DefaultDependencies(ctx, config))
+	}
+
+	spool, err := NewCollectorSpool(config.CollectorSpoolDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// the agents keep sending their data while the database is migrated, it is spooled meanwhile
+	upgradeWindow, err := StartUpgradeWindow(config, spool)
+	if err != nil {
+		return nil, err
+	}
+	deps := DefaultDependencies(ctx, config)
+	if err := upgradeWindow.Close(); err != nil {
+		return nil, err
+	}
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		return nil, err
+	}
+	app.collectorSpool = spool
+
+	return app, nil
 }
 
 func NewAppWithDeps(config *Config, deps Dependencies) (*App, error) {
@@ -174,62 +441,295 @@ func NewAppWithDeps(config *Config, deps Dependencies) (*App, error) {
 
 	app.InstallationID = installationID
 
+	logSamplingRules, err := deps.settingsService.GetLogSamplingRules()
+	if err != nil {
+		log.Errorf("failed to load the log sampling rules: %s", err)
+		return nil, err
+	}
+	deps.logSampler.SetRules(logSamplingRules)
+
+	if config.AdminPassword != "" {
+		if err := deps.usersService.Bootstrap(config.AdminUser, config.AdminPassword); err != nil {
+			log.Errorf("failed to create the admin user: %s", err)
+			return nil, err
+		}
+	}
+
+	chaosInjector := chaos.NewInjector(config.ChaosConfig)
+	if chaosInjector.Enabled() {
+		log.Warn("Chaos fault injection is enabled, do not use this instance in production")
+	}
+
 	InitAlerts()
+	widgetRegistry := InitDashboardWidgetRegistry()
+	metricsRegistry := NewMetricsRegistry(deps.backlogProjector, deps.projectorWorkersPool)
 	webEngine := deps.webEngine
-	webEngine.HTMLRender = NewLayoutRender(templatesFS, "templates/*.tmpl")
+	var templates fs.FS = templatesFS
+	if config.DevMode {
+		log.Warnf("Development mode enabled, templates and assets are served from %s", config.DevWebDir)
+		templates = os.DirFS(config.DevWebDir)
+	}
+	if config.TemplatesOverrideDir != "" {
+		log.Infof("The templates in %s override the default ones", config.TemplatesOverrideDir)
+		templates = newOverlayFS(os.DirFS(config.TemplatesOverrideDir), templates)
+	}
+	var layoutRender *LayoutRender
+	if config.DevMode {
+		layoutRender = NewHotReloadLayoutRender(templates, "templates/*.tmpl")
+	} else {
+		layoutRender = NewLayoutRender(templates, "templates/*.tmpl")
+	}
+	layoutRender.data.MenuItems = extensionsMenuItems()
+	webEngine.HTMLRender = layoutRender
+	contentSecurityPolicy := config.ContentSecurityPolicy
+	if contentSecurityPolicy == "" {
+		grafanaURL := ""
+		if config.GrafanaConfig != nil {
+			grafanaURL = config.GrafanaConfig.BaseUrl()
+		}
+		contentSecurityPolicy = DefaultContentSecurityPolicy(grafanaURL)
+	}
+	webEngine.Use(SecurityHeadersMiddleware(contentSecurityPolicy, config.HSTSMaxAge))
 	webEngine.Use(ErrorHandler)
 	webEngine.Use(sessions.Sessions("session", deps.store))
-	webEngine.StaticFS("/static", http.FS(assetsFS))
+	if config.DevMode {
+		webEngine.Static("/static/frontend/assets", filepath.Join(config.DevWebDir, "frontend", "assets"))
+	} else {
+		webEngine.StaticFS("/static", http.FS(assetsFS))
+	}
+	webEngine.Use(AuthMiddleware(deps.usersService, deps.apiKeysService, deps.tokensService))
+	webEngine.Use(ImpersonationMiddleware(deps.usersService, deps.auditService))
+	webEngine.Use(OrganizationScopeMiddleware(deps.organizationsService))
+	webEngine.Use(CSRFMiddleware)
+	webEngine.Use(ReadOnlyModeMiddleware(deps.settingsService))
+	webEngine.Use(UsageAnalyticsMiddleware(deps.usageService))
+	webEngine.Use(LayoutUserMiddleware)
+	webEngine.GET("/login", LoginShowHandler)
+	webEngine.POST("/login", LoginHandler(deps.usersService, deps.loginThrottlingService, deps.auditService))
+	webEngine.POST("/logout", LogoutHandler)
 	webEngine.Use(EulaMiddleware(deps.premiumDetectionService))
 	webEngine.GET("/", HomeHandler)
 	webEngine.GET("/about", NewAboutHandler(deps.subscriptionsService))
 	webEngine.GET("/eula", EulaShowHandler())
-	webEngine.POST("/accept-eula", EulaAcceptHandler(deps.settingsService))
-	webEngine.GET("/hosts", NewHostListHandler(deps.hostsService))
-	webEngine.GET("/hosts/:id", NewHostHandler(deps.hostsService, deps.subscriptionsService, config.GrafanaConfig.BaseUrl()))
+	webEngine.POST("/accept-eula", RequireRole(models.UserRoleAdmin), EulaAcceptHandler(deps.settingsService, deps.auditService))
+	webEngine.GET("/hosts", NewHostListHandler(deps.hostsService, deps.favoritesService))
+	webEngine.GET("/hosts/:id", NewHostHandler(deps.hostsService, deps.subscriptionsService, deps.availabilityService, deps.hostUtilizationService, deps.addressConflictsService, deps.timelineService, deps.agentLogsService, deps.hostMetricsService, config.GrafanaConfig.BaseUrl()))
 	webEngine.GET("/catalog", NewChecksCatalogHandler(deps.checksService))
-	webEngine.GET("/clusters", NewClusterListHandler(deps.clustersService))
-	webEngine.GET("/clusters/:id", NewClusterHandler(deps.clustersService))
-	webEngine.GET("/sapsystems", NewSAPSystemListHandler(deps.sapSystemsService))
-	webEngine.GET("/sapsystems/:id", NewSAPResourceHandler(deps.hostsService, deps.sapSystemsService))
-	webEngine.GET("/databases", NewHANADatabaseListHandler(deps.sapSystemsService))
-	webEngine.GET("/databases/:id", NewSAPResourceHandler(deps.hostsService, deps.sapSystemsService))
+	webEngine.GET("/docs/api", DocsRedirectHandler)
+	webEngine.GET("/docs/api/assets/*any", DocsAssetsHandler())
+	webEngine.GET("/docs/api/:version", DocsHandler)
+	webEngine.GET("/docs/api/:version/swagger.json", DocsSpecHandler)
+	webEngine.GET("/clusters", NewClusterListHandler(deps.clustersService, deps.favoritesService))
+	webEngine.GET("/clusters/:id", NewClusterHandler(deps.clustersService, deps.checksService))
+	webEngine.GET("/clusters/:id/checks/diff", NewClusterChecksDiffHandler(deps.clustersService, deps.checksService))
+	webEngine.GET("/sapsystems", NewSAPSystemListHandler(deps.sapSystemsService, deps.favoritesService))
+	webEngine.GET("/sapsystems/:id", NewSAPResourceHandler(deps.hostsService, deps.sapSystemsService, deps.availabilityService))
+	webEngine.GET("/databases", NewHANADatabaseListHandler(deps.sapSystemsService, deps.favoritesService))
+	webEngine.GET("/databases/:id", NewSAPResourceHandler(deps.hostsService, deps.sapSystemsService, deps.availabilityService))
+	webEngine.GET("/database", NewDBMaintenanceHandler(deps.dbMaintenanceService))
+	webEngine.GET("/capacity", NewCapacityHandler(deps.capacityService))
+	webEngine.GET("/baselines", NewBaselinesHandler(deps.baselinesService, deps.hostsService))
+	webEngine.GET("/audit", RequireRole(models.UserRoleAdmin), NewAuditHandler(deps.auditService))
+	webEngine.GET("/pipeline", RequireRole(models.UserRoleAdmin), NewPipelineHandler(deps.collectorService, deps.payloadCaptureService, deps.agentsService, deps.consistencyService, deps.projectorsManager))
 
 	apiGroup := webEngine.Group("/api")
+	if config.RateLimitConfig != nil && config.RateLimitConfig.APIRate > 0 {
+		apiGroup.Use(RateLimitMiddleware(
+			NewRateLimiter(config.RateLimitConfig.APIRate, config.RateLimitConfig.APIBurst), apiClientKey))
+	}
+	apiGroup.Use(MaintenanceModeMiddleware(deps.settingsService))
 	{
-		apiGroup.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+		// Read only endpoints, available to every role
+		apiGroup.GET("/docs/*any", DocsRedirectHandler)
 		apiGroup.GET("/ping", ApiPingHandler)
+		apiGroup.GET("/me", ApiMeHandler)
 		apiGroup.GET("/tags", ApiListTag(deps.tagsService))
-		apiGroup.POST("/hosts/:id/tags", ApiHostCreateTagHandler(deps.hostsService, deps.tagsService))
-		apiGroup.DELETE("/hosts/:id/tags/:tag", ApiHostDeleteTagHandler(deps.hostsService, deps.tagsService))
-		apiGroup.POST("/clusters/:id/tags", ApiClusterCreateTagHandler(deps.clustersService, deps.tagsService))
-		apiGroup.DELETE("/clusters/:id/tags/:tag", ApiClusterDeleteTagHandler(deps.clustersService, deps.tagsService))
+		apiGroup.GET("/hosts/:id/availability", ApiHostAvailabilityHandler(deps.hostsService, deps.availabilityService))
+		apiGroup.GET("/hosts/:id/utilization", ApiHostUtilizationHandler(deps.hostsService, deps.hostUtilizationService))
+		apiGroup.GET("/hosts/:id/metrics", ApiHostMetricsHandler(deps.hostsService, deps.hostMetricsService))
+		apiGroup.GET("/hosts/:id/timeline", ApiHostTimelineHandler(deps.hostsService, deps.timelineService))
 		apiGroup.GET("/clusters/:cluster_id/results", ApiClusterCheckResultsHandler(deps.checksService))
+		apiGroup.GET("/clusters/:cluster_id/results/runs", ApiClusterChecksRunsHandler(deps.checksService))
+		apiGroup.GET("/clusters/:cluster_id/results/diff", ApiClusterChecksResultDiffHandler(deps.checksService))
+		apiGroup.GET("/clusters/:cluster_id/facts", ApiClusterFactsHandler(deps.checksService))
 		apiGroup.GET("/clusters/settings", ApiGetClustersSettingsHandler(deps.clustersService))
-		apiGroup.POST("/sapsystems/:id/tags", ApiSAPSystemCreateTagHandler(deps.sapSystemsService, deps.tagsService))
-		apiGroup.DELETE("/sapsystems/:id/tags/:tag", ApiSAPSystemDeleteTagHandler(deps.sapSystemsService, deps.tagsService))
+		apiGroup.GET("/runner/settings", ApiGetRunnerSettingsHandler(deps.settingsService))
+		apiGroup.GET("/runs/queue", ApiGetRunsQueueHandler(deps.runsQueueService))
 		apiGroup.GET("/sapsystems/health", ApiSAPSystemsHealthSummaryHandler(deps.healthSummaryService))
-		apiGroup.POST("/databases/:id/tags", ApiDatabaseCreateTagHandler(deps.sapSystemsService, deps.tagsService))
-		apiGroup.DELETE("/databases/:id/tags/:tag", ApiDatabaseDeleteTagHandler(deps.sapSystemsService, deps.tagsService))
-		apiGroup.GET("/checks/:id/settings", ApiCheckGetSettingsByIdHandler(deps.clustersService))
-		apiGroup.POST("/checks/:id/settings", ApiCheckCreateSettingsByIdHandler(deps.checksService))
-		apiGroup.PUT("/checks/catalog", ApiCreateChecksCatalogHandler(deps.checksService))
+		apiGroup.GET("/hosts/:id/health", ApiResourceHealthHandler(models.HealthResourceHost, "id", deps.healthHistoryService))
+		apiGroup.GET("/clusters/:cluster_id/health", ApiResourceHealthHandler(models.HealthResourceCluster, "cluster_id", deps.healthHistoryService))
+		apiGroup.GET("/sapsystems/:id/health", ApiResourceHealthHandler(models.HealthResourceSAPSystem, "id", deps.healthHistoryService))
+		apiGroup.GET("/databases/:id/health", ApiResourceHealthHandler(models.HealthResourceDatabase, "id", deps.healthHistoryService))
+		apiGroup.GET("/hosts/:id/history", ApiResourceHistoryHandler(models.TagHostResourceType, "id", deps.auditService))
+		apiGroup.GET("/clusters/:cluster_id/history", ApiResourceHistoryHandler(models.TagClusterResourceType, "cluster_id", deps.auditService))
+		apiGroup.GET("/sapsystems/:id/history", ApiResourceHistoryHandler(models.TagSAPSystemResourceType, "id", deps.auditService))
+		apiGroup.GET("/databases/:id/history", ApiResourceHistoryHandler(models.TagDatabaseResourceType, "id", deps.auditService))
+		apiGroup.GET("/landscape/graph", ApiLandscapeGraphHandler(deps.landscapeService))
+		apiGroup.GET("/checks/:id/settings", ApiCheckGetSettingsByIdHandler(deps.clustersService, deps.auditService))
 		apiGroup.GET("/checks/catalog", ApiChecksCatalogHandler(deps.checksService))
-		apiGroup.POST("/checks/:id/results", ApiCreateChecksResultHandler(deps.checksService))
 		apiGroup.GET("/prometheus/targets", ApiGetPrometheusHttpSdTargets(deps.prometheusService))
+		apiGroup.GET("/dashboard/widgets", ApiDashboardWidgetsHandler(widgetRegistry))
+		apiGroup.GET("/dashboard/layout", ApiGetDashboardLayoutHandler(widgetRegistry, deps.preferencesService))
+		apiGroup.GET("/dashboard/alerts", ApiDashboardAlertsHandler(deps.hostsService, deps.clustersService))
+		apiGroup.GET("/dashboard/subscriptions", ApiDashboardSubscriptionsHandler(deps.subscriptionsService))
+		apiGroup.GET("/dashboard/pipeline", ApiDashboardPipelineHandler(deps.collectorService))
+		apiGroup.GET("/pipeline/backlog", ApiGetProjectionBacklogHandler(deps.backlogProjector))
+		apiGroup.GET("/metrics", ApiMetricsHandler(metricsRegistry))
+		apiGroup.GET("/favorites", ApiListFavoritesHandler(deps.favoritesService))
+		apiGroup.GET("/database/maintenance", ApiGetDBMaintenanceReportHandler(deps.dbMaintenanceService))
+		apiGroup.GET("/capacity", ApiGetCapacityOverviewHandler(deps.capacityService))
+		apiGroup.GET("/baselines", ApiListBaselinesHandler(deps.baselinesService))
+		apiGroup.GET("/baselines/:role/report", ApiGetBaselineReportHandler(deps.baselinesService))
+		apiGroup.GET("/reports/costs", ApiGetCostReportHandler(deps.costReportService))
+		apiGroup.GET("/conflicts", ApiListAddressConflictsHandler(deps.addressConflictsService))
+		apiGroup.GET("/entitlements", ApiGetEntitlementsHandler(deps.entitlementsService))
+		apiGroup.GET("/maintenance", ApiGetMaintenanceModeHandler(deps.settingsService))
+		apiGroup.GET("/read-only", ApiGetReadOnlyModeHandler(deps.settingsService))
+		apiGroup.GET("/search", ApiSearchHandler(deps.searchService))
+		apiGroup.GET("/agent-versions", ApiListAgentVersionsHandler(deps.agentUpgradesService))
+		apiGroup.GET("/agent-upgrades", ApiListAgentUpgradesHandler(deps.agentUpgradesService))
+		apiGroup.GET("/agents/overview", ApiGetAgentsOverviewHandler(deps.hostsService))
+
+		// The viewers can only change their own preferences
+		apiGroup.PUT("/dashboard/layout", ApiUpdateDashboardLayoutHandler(widgetRegistry, deps.preferencesService))
+		apiGroup.POST("/favorites", ApiCreateFavoriteHandler(deps.favoritesService, deps.hostsService, deps.clustersService, deps.sapSystemsService, deps.organizationsService))
+		apiGroup.DELETE("/favorites/:resource_type/:id", ApiDeleteFavoriteHandler(deps.favoritesService))
+
+		// Every user manages its own personal access tokens
+		apiGroup.GET("/me/tokens", ApiListOwnPersonalAccessTokensHandler(deps.tokensService))
+		apiGroup.POST("/me/tokens", ApiCreatePersonalAccessTokenHandler(deps.tokensService, deps.auditService))
+		apiGroup.DELETE("/me/tokens/:id", ApiRevokeOwnPersonalAccessTokenHandler(deps.tokensService, deps.auditService))
+
+		// The impersonated user might not be an admin, the impersonation is checked by the handler
+		apiGroup.DELETE("/impersonation", ApiStopImpersonationHandler(deps.auditService))
+	}
+
+	operatorGroup := apiGroup.Group("", RequireRole(models.UserRoleOperator))
+	{
+		operatorGroup.POST("/hosts/:id/tags", RequireResourcePermission(deps.restrictionsService, models.PermissionTagsWrite, models.TagHostResourceType), ApiHostCreateTagHandler(deps.hostsService, deps.tagsService, deps.auditService))
+		operatorGroup.DELETE("/hosts/:id/tags/:tag", RequireResourcePermission(deps.restrictionsService, models.PermissionTagsWrite, models.TagHostResourceType), ApiHostDeleteTagHandler(deps.hostsService, deps.tagsService, deps.auditService))
+		operatorGroup.POST("/clusters/:id/tags", RequireResourcePermission(deps.restrictionsService, models.PermissionTagsWrite, models.TagClusterResourceType), ApiClusterCreateTagHandler(deps.clustersService, deps.tagsService, deps.auditService))
+		operatorGroup.DELETE("/clusters/:id/tags/:tag", RequireResourcePermission(deps.restrictionsService, models.PermissionTagsWrite, models.TagClusterResourceType), ApiClusterDeleteTagHandler(deps.clustersService, deps.tagsService, deps.auditService))
+		operatorGroup.PUT("/runs/queue", ApiUpdateRunsQueueHandler(deps.runsQueueService))
+		operatorGroup.POST("/sapsystems/:id/tags", RequireResourcePermission(deps.restrictionsService, models.PermissionTagsWrite, models.TagSAPSystemResourceType), ApiSAPSystemCreateTagHandler(deps.sapSystemsService, deps.tagsService, deps.auditService))
+		operatorGroup.DELETE("/sapsystems/:id/tags/:tag", RequireResourcePermission(deps.restrictionsService, models.PermissionTagsWrite, models.TagSAPSystemResourceType), ApiSAPSystemDeleteTagHandler(deps.sapSystemsService, deps.tagsService, deps.auditService))
+		operatorGroup.POST("/databases/:id/tags", RequireResourcePermission(deps.restrictionsService, models.PermissionTagsWrite, models.TagDatabaseResourceType), ApiDatabaseCreateTagHandler(deps.sapSystemsService, deps.tagsService, deps.auditService))
+		operatorGroup.DELETE("/databases/:id/tags/:tag", RequireResourcePermission(deps.restrictionsService, models.PermissionTagsWrite, models.TagDatabaseResourceType), ApiDatabaseDeleteTagHandler(deps.sapSystemsService, deps.tagsService, deps.auditService))
+		operatorGroup.POST("/checks/:id/settings", RequireResourcePermission(deps.restrictionsService, models.PermissionChecksWrite, models.TagClusterResourceType), ApiCheckCreateSettingsByIdHandler(deps.checksService, deps.auditService, deps.agentChannelHub))
+		operatorGroup.PUT("/checks/catalog", ApiCreateChecksCatalogHandler(deps.checksService, deps.auditService))
+		operatorGroup.POST("/checks/:id/results", ChaosTimeoutMiddleware(chaosInjector), ApiCreateChecksResultHandler(deps.checksService))
+		operatorGroup.POST("/hosts/:id/discoveries/:discovery_type", ApiRequestDiscoveryHandler(deps.hostsService, deps.agentChannelHub))
+		operatorGroup.POST("/hosts/:id/checks/run", ApiRequestChecksRunHandler(deps.hostsService, deps.checksService, deps.agentChannelHub))
+	}
+
+	adminGroup := apiGroup.Group("", RequireRole(models.UserRoleAdmin))
+	{
+		adminGroup.PUT("/runner/settings", ApiUpdateRunnerSettingsHandler(deps.settingsService, deps.auditService))
+		adminGroup.GET("/discovery/settings", ApiGetAgentSettingsHandler(deps.settingsService))
+		adminGroup.PUT("/discovery/settings", ApiUpdateAgentSettingsHandler(deps.settingsService, deps.auditService, deps.agentChannelHub))
+		adminGroup.PUT("/maintenance", ApiUpdateMaintenanceModeHandler(deps.settingsService, deps.auditService))
+		adminGroup.PUT("/read-only", ApiUpdateReadOnlyModeHandler(deps.settingsService, deps.auditService))
+		adminGroup.GET("/logging/sampling", ApiGetLogSamplingRulesHandler(deps.logSampler))
+		adminGroup.GET("/usage", ApiGetUsageReportHandler(deps.usageService))
+		adminGroup.GET("/usage/settings", ApiGetUsageAnalyticsSettingsHandler(deps.usageService))
+		adminGroup.PUT("/usage/settings", ApiUpdateUsageAnalyticsSettingsHandler(deps.usageService, deps.auditService))
+		adminGroup.PUT("/logging/sampling", ApiUpdateLogSamplingRulesHandler(deps.logSampler, deps.settingsService, deps.auditService))
+		adminGroup.POST("/database/maintenance/inspect", ApiInspectDBHandler(deps.dbMaintenanceService))
+		adminGroup.POST("/database/maintenance/tables/:table", ApiRunDBMaintenanceHandler(deps.dbMaintenanceService))
+		adminGroup.GET("/users", ApiListUsersHandler(deps.usersService))
+		adminGroup.POST("/users", ApiCreateUserHandler(deps.usersService, deps.auditService))
+		adminGroup.PUT("/users/:id/role", ApiUpdateUserRoleHandler(deps.usersService, deps.auditService))
+		adminGroup.PUT("/users/:id/organization", ApiUpdateUserOrganizationHandler(deps.usersService, deps.auditService))
+		adminGroup.DELETE("/users/:id", ApiDeleteUserHandler(deps.usersService, deps.auditService))
+		adminGroup.POST("/users/:id/impersonate", ApiStartImpersonationHandler(deps.usersService, deps.auditService))
+		adminGroup.GET("/keys", ApiListApiKeysHandler(deps.apiKeysService))
+		adminGroup.POST("/keys", ApiCreateApiKeyHandler(deps.apiKeysService, deps.auditService))
+		adminGroup.DELETE("/keys/:id", ApiRevokeApiKeyHandler(deps.apiKeysService, deps.auditService))
+		adminGroup.GET("/tokens", ApiListPersonalAccessTokensHandler(deps.tokensService))
+		adminGroup.DELETE("/tokens/:id", ApiRevokePersonalAccessTokenHandler(deps.tokensService, deps.auditService))
+		adminGroup.GET("/audit", ApiListAuditEntriesHandler(deps.auditService))
+		adminGroup.GET("/pipeline/capture", ApiGetPayloadCaptureHandler(deps.payloadCaptureService))
+		adminGroup.PUT("/pipeline/capture", ApiStartPayloadCaptureHandler(deps.payloadCaptureService, deps.auditService))
+		adminGroup.DELETE("/pipeline/capture", ApiStopPayloadCaptureHandler(deps.payloadCaptureService, deps.auditService))
+		adminGroup.GET("/pipeline/captures", ApiListCapturedPayloadsHandler(deps.payloadCaptureService))
+		adminGroup.GET("/pipeline/captures/:id", ApiGetCapturedPayloadHandler(deps.payloadCaptureService))
+		adminGroup.GET("/pipeline/rejected", ApiListRejectedPayloadsHandler(deps.collectorService))
+		adminGroup.GET("/pipeline/projectors", ApiListProjectorsHandler(deps.projectorsManager))
+		adminGroup.POST("/pipeline/projectors/:id/disable", ApiDisableProjectorHandler(deps.projectorsManager, deps.auditService))
+		adminGroup.POST("/pipeline/projectors/:id/enable", ApiEnableProjectorHandler(deps.projectorsManager, deps.auditService))
+		adminGroup.GET("/pipeline/dead-letters", ApiListDeadLettersHandler(deps.projectorsManager))
+		adminGroup.POST("/pipeline/dead-letters/requeue", ApiRequeueDeadLettersHandler(deps.projectorsManager, deps.auditService))
+		adminGroup.POST("/pipeline/dead-letters/:id/requeue", ApiRequeueDeadLetterHandler(deps.projectorsManager, deps.auditService))
+		adminGroup.GET("/agents", ApiListAgentsHandler(deps.agentsService))
+		adminGroup.POST("/agents/:id/approve", ApiApproveAgentHandler(deps.agentsService, deps.backlogProjector, deps.auditService))
+		adminGroup.POST("/agents/:id/reject", ApiRejectAgentHandler(deps.agentsService, deps.auditService))
+		adminGroup.PUT("/agents/:id/secret", ApiRotateAgentSigningSecretHandler(deps.signaturesService, deps.auditService))
+		adminGroup.DELETE("/agents/:id/secret", ApiDeleteAgentSigningSecretHandler(deps.signaturesService, deps.auditService))
+		adminGroup.DELETE("/agents/:id/enrollment", ApiResetAgentEnrollmentHandler(deps.enrollmentsService, deps.auditService))
+		adminGroup.POST("/agent-versions", ApiSaveAgentVersionHandler(deps.agentUpgradesService, deps.auditService))
+		adminGroup.POST("/agent-upgrades", ApiRequestAgentUpgradesHandler(deps.agentUpgradesService, deps.hostsService, deps.auditService, deps.agentChannelHub))
+		adminGroup.GET("/lockouts", ApiListLockoutsHandler(deps.loginThrottlingService))
+		adminGroup.DELETE("/lockouts/:type/:subject", ApiUnlockHandler(deps.loginThrottlingService, deps.auditService))
+		adminGroup.GET("/pipeline/inconsistencies", ApiListInconsistenciesHandler(deps.consistencyService))
+		adminGroup.POST("/pipeline/inconsistencies/:kind/:resource_id/fix", ApiFixInconsistencyHandler(deps.consistencyService, deps.backlogProjector, deps.auditService))
+		adminGroup.PUT("/baselines/:role", ApiSaveBaselineHandler(deps.baselinesService, deps.hostsService, deps.auditService))
+		adminGroup.DELETE("/baselines/:role", ApiDeleteBaselineHandler(deps.baselinesService, deps.auditService))
+		adminGroup.DELETE("/hosts/:id", ApiDecommissionHostHandler(deps.hostsService, deps.auditService))
+		adminGroup.GET("/hosts/:id/logs", ApiGetAgentLogsHandler(deps.agentLogsService))
+		adminGroup.POST("/hosts/:id/logs/request", ApiRequestAgentLogsHandler(deps.hostsService, deps.agentLogsService, deps.auditService))
+		adminGroup.DELETE("/sapsystems/:id", ApiDeleteSAPSystemHandler(deps.sapSystemsService, deps.auditService))
+		adminGroup.DELETE("/databases/:id", ApiDeleteDatabaseHandler(deps.sapSystemsService, deps.auditService))
+		adminGroup.GET("/restrictions", ApiListResourceRestrictionsHandler(deps.restrictionsService))
+		adminGroup.PUT("/restrictions/:resource_type/:id/:permission", ApiSaveResourceRestrictionHandler(deps.restrictionsService, deps.auditService))
+		adminGroup.DELETE("/restrictions/:resource_type/:id/:permission", ApiDeleteResourceRestrictionHandler(deps.restrictionsService, deps.auditService))
+	}
+
+	registerExtensionsRoutes(webEngine, apiGroup)
+
+	collectorAllowlist, err := ParseCIDRAllowlist(config.CollectorAllowlist)
+	if err != nil {
+		return nil, err
 	}
 
 	collectorEngine := deps.collectorEngine
-	collectorEngine.POST("/api/collect", ApiCollectDataHandler(deps.collectorService))
-	collectorEngine.POST("/api/hosts/:id/heartbeat", ApiHostHeartbeatHandler(deps.hostsService))
+	collectorEngine.Use(ErrorHandler)
+	collectorEngine.Use(CollectorAllowlistMiddleware(collectorAllowlist))
+	collectorGroup := collectorEngine.Group("/api")
+	collectorRateLimit := func(c *gin.Context) { c.Next() }
+	if config.RateLimitConfig != nil && config.RateLimitConfig.CollectorRate > 0 {
+		collectorRateLimit = RateLimitMiddleware(
+			NewRateLimiter(config.RateLimitConfig.CollectorRate, config.RateLimitConfig.CollectorBurst), collectorClientKey)
+	}
+	// not authenticated, as the enrollment, the agents negotiate the protocol before sending their data
+	collectorEngine.GET("/api/protocol", collectorRateLimit, ApiCollectorProtocolHandler(config))
+	if config.EnableJWT {
+		collectorEngine.POST("/api/enroll", collectorRateLimit, ApiEnrollAgentHandler(config, deps.agentsService, deps.entitlementsService, deps.enrollmentsService))
+		collectorGroup.Use(CollectorJWTMiddleware(config.JWTSecret))
+	}
+	if config.EnablemTLS {
+		collectorGroup.Use(CollectorCertificateMiddleware(config.MTLSVerifyAgentID))
+	}
+	// after the authentication, to tell the agents apart by their identity
+	collectorGroup.Use(collectorRateLimit)
+	collectorGroup.POST("/collect", CollectorMaintenanceModeMiddleware(deps.settingsService), CollectorBackpressureMiddleware(deps.collectorService, config.CollectorQueueThreshold), CollectorBodyLimitMiddleware(collectorMaxBodySize(config)), CollectorDecompressionMiddleware(collectorMaxBodySize(config)), ApiCollectDataHandler(deps.collectorService, deps.payloadCaptureService, deps.agentsService, deps.entitlementsService, deps.signaturesService))
+	collectorGroup.POST("/collect/batch", CollectorMaintenanceModeMiddleware(deps.settingsService), CollectorBackpressureMiddleware(deps.collectorService, config.CollectorQueueThreshold), CollectorBodyLimitMiddleware(collectorMaxBodySize(config)), CollectorDecompressionMiddleware(collectorMaxBodySize(config)), ApiCollectBatchDataHandler(deps.collectorService, deps.payloadCaptureService, deps.agentsService, deps.entitlementsService, deps.signaturesService))
+	collectorGroup.POST("/collect/backfill", CollectorMaintenanceModeMiddleware(deps.settingsService), CollectorBackpressureMiddleware(deps.collectorService, config.CollectorQueueThreshold), CollectorBodyLimitMiddleware(collectorMaxBodySize(config)), CollectorDecompressionMiddleware(collectorMaxBodySize(config)), ApiCollectBackfillHandler(deps.collectorService, deps.payloadCaptureService, deps.agentsService, deps.entitlementsService, deps.signaturesService))
+	collectorGroup.POST("/hosts/:id/heartbeat", ApiHostHeartbeatHandler(deps.hostsService, deps.agentsService, deps.entitlementsService, deps.agentLogsService))
+	collectorGroup.POST("/hosts/:id/logs", ApiShipAgentLogsHandler(deps.agentLogsService))
+	collectorGroup.POST("/hosts/:id/metrics", ApiPushHostMetricsHandler(deps.agentsService, deps.entitlementsService, deps.hostMetricsService))
+	collectorGroup.POST("/hosts/:id/register", ApiHostRegisterHandler(deps.agentsService, deps.entitlementsService, deps.agentUpgradesService))
+	collectorGroup.GET("/hosts/:id/config", ApiGetAgentConfigurationHandler(deps.agentsService, deps.entitlementsService, deps.agentConfigService))
+	collectorGroup.GET("/agent/ws", ApiAgentChannelHandler(deps.agentChannelHub, deps.agentsService, deps.entitlementsService, deps.agentUpgradesService))
 	collectorEngine.GET("/api/ping", ApiPingHandler)
 
+	app.spoolReplayEngine = newSpoolReplayEngine(CollectorMaintenanceModeMiddleware(deps.settingsService), ApiCollectDataHandler(deps.collectorService, deps.payloadCaptureService, deps.agentsService, deps.entitlementsService, deps.signaturesService))
+
 	return app, nil
 }
 
 func (a *App) Start(ctx context.Context) error {
 	webServer := &http.Server{
-		Addr:           fmt.Sprintf("%s:%d", a.config.Host, a.config.Port),
+		Addr:           net.JoinHostPort(a.config.Host, strconv.Itoa(a.config.Port)),
 		Handler:        a.webEngine,
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
@@ -237,17 +737,19 @@ func (a *App) Start(ctx context.Context) error {
 	}
 
 	var tlsConfig *tls.Config
+	var certificateReloader *CertificateReloader
 	var err error
 
 	if a.config.EnablemTLS {
-		tlsConfig, err = getTLSConfig(a.config.Cert, a.config.Key, a.config.CA)
+		certificateReloader, err = NewCertificateReloader(a.config.Cert, a.config.Key, a.config.CA, a.config.CRL)
 		if err != nil {
 			return err
 		}
+		tlsConfig = certificateReloader.TLSConfig()
 	}
 
 	collectorServer := &http.Server{
-		Addr:           fmt.Sprintf("%s:%d", a.config.Host, a.config.CollectorPort),
+		Addr:           net.JoinHostPort(a.config.Host, strconv.Itoa(a.config.CollectorPort)),
 		Handler:        a.collectorEngine,
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
@@ -280,11 +782,64 @@ func (a *App) Start(ctx context.Context) error {
 		return nil
 	})
 
+	if certificateReloader != nil {
+		g.Go(func() error {
+			certificateReloader.Start(ctx)
+			return nil
+		})
+	}
+
+	if a.config.CollectorGRPCPort > 0 {
+		if tlsConfig == nil {
+			return errors.New("the gRPC collector requires mTLS to be enabled")
+		}
+
+		grpcListener, err := net.Listen("tcp", net.JoinHostPort(a.config.Host, strconv.Itoa(a.config.CollectorGRPCPort)))
+		if err != nil {
+			return err
+		}
+		grpcServer := NewCollectorGRPCServer(a.collectorEngine, tlsConfig)
+
+		log.Info("Starting gRPC collector server")
+		g.Go(func() error {
+			return grpcServer.Serve(grpcListener)
+		})
+		g.Go(func() error {
+			<-ctx.Done()
+			log.Info("gRPC collector server is shutting down.")
+			grpcServer.GracefulStop()
+			return nil
+		})
+	}
+
+	if a.config.CollectorNatsURL != "" {
+		// the messages carry no client certificate, the agents authenticate them with their JWT
+		if !a.config.EnableJWT {
+			return errors.New("the NATS collector requires the JWT authentication to be enabled")
+		}
+
+		subject := a.config.CollectorNatsSubject
+		if subject == "" {
+			subject = DefaultCollectorNatsSubject
+		}
+
+		log.Info("Starting NATS collector subscriber")
+		g.Go(func() error {
+			return RunCollectorNatsSubscriber(ctx, a.config.CollectorNatsURL, subject, a.config.CollectorNatsConfig, a.collectorEngine)
+		})
+	}
+
 	g.Go(func() error {
 		a.projectorWorkersPool.Run(ctx)
 		return nil
 	})
 
+	// The backlog is projected along with the events collected meanwhile, the pages show its progress
+	g.Go(func() error {
+		_ = a.backlogProjector.Run(ctx)
+		return nil
+	})
+
 	telemetryEngine := telemetry.NewEngine(
 		a.InstallationID,
 		a.Dependencies.telemetryPublisher,
@@ -297,6 +852,91 @@ func (a *App) Start(ctx context.Context) error {
 		return nil
 	})
 
+	dbMaintenanceAdvisor := NewDBMaintenanceAdvisor(a.dbMaintenanceService, a.config.DBMaintenanceAutoVacuum)
+
+	g.Go(func() error {
+		dbMaintenanceAdvisor.Start(ctx)
+		return nil
+	})
+
+	addressConflictsAnalyzer := NewAddressConflictsAnalyzer(a.addressConflictsService)
+
+	g.Go(func() error {
+		addressConflictsAnalyzer.Start(ctx)
+		return nil
+	})
+
+	consistencyChecker := NewConsistencyChecker(a.consistencyService)
+
+	g.Go(func() error {
+		consistencyChecker.Start(ctx)
+		return nil
+	})
+
+	healthHistoryRecorder := NewHealthHistoryRecorder(a.healthHistoryService)
+
+	g.Go(func() error {
+		healthHistoryRecorder.Start(ctx)
+		return nil
+	})
+
+	usageAnalyticsFlusher := NewUsageAnalyticsFlusher(a.usageService)
+
+	g.Go(func() error {
+		usageAnalyticsFlusher.Start(ctx)
+		return nil
+	})
+
+	if a.collectorSpool != nil {
+		g.Go(func() error {
+			ReplayCollectorSpool(ctx, a.collectorSpool, a.spoolReplayEngine)
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		a.logSampler.Run(ctx, LogSamplingSummaryInterval, log.StandardLogger())
+		return nil
+	})
+
+	if a.config.EphemeralHostsTTL > 0 {
+		ephemeralHostsReaper := NewEphemeralHostsReaper(a.hostsService)
+
+		g.Go(func() error {
+			ephemeralHostsReaper.Start(ctx)
+			return nil
+		})
+	}
+
+	if a.config.StaleSAPSystemsTTL > 0 {
+		staleSAPSystemsReaper := NewStaleSAPSystemsReaper(a.sapSystemsService, a.config.StaleSAPSystemsTTL)
+
+		g.Go(func() error {
+			staleSAPSystemsReaper.Start(ctx)
+			return nil
+		})
+	}
+
+	staleHostsDetector := NewStaleHostsDetector(a.hostsService)
+	g.Go(func() error {
+		staleHostsDetector.Start(ctx)
+		return nil
+	})
+
+	availabilityRollupsJob := NewAvailabilityRollupsJob(a.availabilityService, a.config.HeartbeatPeriodsRetention)
+	g.Go(func() error {
+		availabilityRollupsJob.Start(ctx)
+		return nil
+	})
+
+	if a.config.EventsRetention > 0 {
+		eventsPruningJob := NewEventsPruningJob(a.collectorService, a.config.EventsRetention)
+		g.Go(func() error {
+			eventsPruningJob.Start(ctx)
+			return nil
+		})
+	}
+
 	go func() {
 		<-ctx.Done()
 		log.Info("Web server is shutting down.")
@@ -307,23 +947,3 @@ func (a *App) Start(ctx context.Context) error {
 
 	return g.Wait()
 }
-
-func getTLSConfig(cert string, key string, ca string) (*tls.Config, error) {
-	caCert, err := ioutil.ReadFile(ca)
-	if err != nil {
-		return nil, err
-	}
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
-
-	certificate, err := tls.LoadX509KeyPair(cert, key)
-	if err != nil {
-		return nil, err
-	}
-
-	return &tls.Config{
-		ClientCAs:    caCertPool,
-		ClientAuth:   tls.RequireAndVerifyClientCert,
-		Certificates: []tls.Certificate{certificate},
-	}, nil
-}
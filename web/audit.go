@@ -0,0 +1,174 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// auditActions are the actions offered in the audit log filters
+var auditActions = []string{
+	models.AuditActionTagCreated,
+	models.AuditActionTagDeleted,
+	models.AuditActionChecksSettingsSaved,
+	models.AuditActionChecksCatalogSaved,
+	models.AuditActionRunnerSettingsSaved,
+	models.AuditActionEulaAccepted,
+	models.AuditActionUserCreated,
+	models.AuditActionUserRoleChanged,
+	models.AuditActionUserOrgChanged,
+	models.AuditActionUserDeleted,
+	models.AuditActionApiKeyCreated,
+	models.AuditActionApiKeyRevoked,
+	models.AuditActionPayloadCaptureSaved,
+	models.AuditActionImpersonationStarted,
+	models.AuditActionImpersonationStopped,
+	models.AuditActionLoginLockedOut,
+	models.AuditActionLoginUnlocked,
+	models.AuditActionInconsistencyFixed,
+	models.AuditActionRestrictionSaved,
+	models.AuditActionRestrictionDeleted,
+	models.AuditActionBaselineSaved,
+	models.AuditActionBaselineDeleted,
+	models.AuditActionPersonalAccessTokenCreated,
+	models.AuditActionPersonalAccessTokenRevoked,
+	models.AuditActionMaintenanceModeSaved,
+	models.AuditActionAgentSecretRotated,
+	models.AuditActionAgentSecretDeleted,
+	models.AuditActionAgentEnrollmentReset,
+	models.AuditActionLogSamplingSaved,
+	models.AuditActionReadOnlyModeSaved,
+	models.AuditActionUsageAnalyticsSaved,
+}
+
+// recordAudit records a change made by the user of the request.
+// The change has already been applied, so a failure to record it is only logged
+func recordAudit(c *gin.Context, auditService services.AuditService, action string, resourceType string, resourceID string, before interface{}, after interface{}) {
+	entry := &models.AuditEntry{
+		Actor:            requestActor(c),
+		ImpersonatedUser: impersonatedUser(c),
+		Action:           action,
+		ResourceType:     resourceType,
+		ResourceID:       resourceID,
+		Before:           before,
+		After:            after,
+		Organization:     userOrganization(c),
+	}
+
+	if err := auditService.Record(entry); err != nil {
+		log.Errorf("Could not record the audit entry of %s on %s %s by %s: %s", action, resourceType, resourceID, entry.Actor, err)
+	}
+}
+
+// requestActor returns the name of the user, or API key, the request is authenticated with.
+// The admins impersonating a user remain accountable for the changes they make
+func requestActor(c *gin.Context) string {
+	if impersonation := requestImpersonation(c); impersonation != nil {
+		return impersonation.Impersonator
+	}
+
+	value, _ := c.Get(ContextUserKey)
+	if user, ok := value.(*models.User); ok {
+		return user.Username
+	}
+
+	return anonymousUser
+}
+
+func impersonatedUser(c *gin.Context) string {
+	if impersonation := requestImpersonation(c); impersonation != nil {
+		return impersonation.Username
+	}
+
+	return ""
+}
+
+func auditFilterFromQuery(c *gin.Context) *services.AuditFilter {
+	query := c.Request.URL.Query()
+
+	return &services.AuditFilter{
+		Actors:       query["actor"],
+		Actions:      query["action"],
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		Organization: userOrganization(c),
+	}
+}
+
+func auditPageFromQuery(c *gin.Context) *services.Page {
+	pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		pageNumber = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("per_page", "25"))
+	if err != nil {
+		pageSize = 25
+	}
+
+	return &services.Page{
+		Number: pageNumber,
+		Size:   pageSize,
+	}
+}
+
+// ApiListAuditEntriesHandler godoc
+// @Summary List the changes made by the users, the most recent first
+// @Produce json
+// @Param actor query []string false "Filter by user"
+// @Param action query []string false "Filter by action"
+// @Param resource_type query string false "Filter by type of the changed resource"
+// @Param resource_id query string false "Filter by ID of the changed resource"
+// @Param page query int false "Page number"
+// @Param per_page query int false "Entries per page"
+// @Success 200 {object} []models.AuditEntry
+// @Failure 500 {object} map[string]string
+// @Router /audit [get]
+func ApiListAuditEntriesHandler(auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := auditService.GetAll(auditFilterFromQuery(c), auditPageFromQuery(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, entries)
+	}
+}
+
+func NewAuditHandler(auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := auditFilterFromQuery(c)
+		page := auditPageFromQuery(c)
+
+		entries, err := auditService.GetAll(filter, page)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		count, err := auditService.GetCount(filter)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		actors, err := auditService.GetAllActors(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.HTML(http.StatusOK, "audit.html.tmpl", gin.H{
+			"Entries":        entries,
+			"AppliedFilters": c.Request.URL.Query(),
+			"FilterActors":   actors,
+			"FilterActions":  auditActions,
+			"Pagination":     NewPagination(count, page.Number, page.Size),
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/html"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func auditEntriesFixture() []*models.AuditEntry {
+	return []*models.AuditEntry{
+		{
+			ID:           2,
+			Time:         time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC),
+			Actor:        "admin",
+			Action:       models.AuditActionTagDeleted,
+			ResourceType: models.TagHostResourceType,
+			ResourceID:   "host1",
+			Before:       map[string]interface{}{"tag": "production"},
+		},
+		{
+			ID:           1,
+			Time:         time.Date(2022, time.February, 28, 9, 30, 0, 0, time.UTC),
+			Actor:        "operator",
+			Action:       models.AuditActionRunnerSettingsSaved,
+			ResourceType: models.AuditResourceSettings,
+			ResourceID:   "runner",
+			Before:       map[string]interface{}{"max_concurrent_runs": 5},
+			After:        map[string]interface{}{"max_concurrent_runs": 10},
+		},
+	}
+}
+
+func TestAuditHandler(t *testing.T) {
+	expectedFilter := &services.AuditFilter{Actors: []string{"admin"}}
+	expectedPage := &services.Page{Number: 1, Size: 25}
+
+	auditService := new(services.MockAuditService)
+	auditService.On("GetAll", expectedFilter, expectedPage).Return(auditEntriesFixture(), nil)
+	auditService.On("GetCount", expectedFilter).Return(2, nil)
+	auditService.On("GetAllActors", "").Return([]string{"admin", "operator"}, nil)
+
+	deps := setupTestDependencies()
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/audit?actor=admin", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	auditService.AssertExpectations(t)
+	assert.Contains(t, minified, "Audit log")
+	assert.Regexp(t, regexp.MustCompile(`<option value=admin>admin</option><option value=operator>operator</option>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>Mar 01, 2022 10:00:00 UTC</td><td>admin</td><td><span class="badge badge-pill badge-secondary">tag_deleted</span></td><td>hosts host1</td><td><code>{"tag":"production"}</code></td><td>-</td>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>Feb 28, 2022 09:30:00 UTC</td><td>operator</td>.*<td>settings runner</td><td><code>{"max_concurrent_runs":5}</code></td><td><code>{"max_concurrent_runs":10}</code></td>`), minified)
+}
+
+func TestApiListAuditEntriesHandler(t *testing.T) {
+	auditService := new(services.MockAuditService)
+	auditService.On("GetAll", &services.AuditFilter{
+		Actions:      []string{models.AuditActionTagDeleted},
+		ResourceType: models.TagHostResourceType,
+		ResourceID:   "host1",
+	}, &services.Page{Number: 2, Size: 1}).Return(auditEntriesFixture()[:1], nil)
+
+	deps := setupTestDependencies()
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/audit?action=tag_deleted&resource_type=hosts&resource_id=host1&page=2&per_page=1", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	var entries []*models.AuditEntry
+	err = json.Unmarshal(resp.Body.Bytes(), &entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "admin", entries[0].Actor)
+	assert.Equal(t, map[string]interface{}{"tag": "production"}, entries[0].Before)
+}
+
+func TestAuditRecordedOnChange(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "host1").Return(&models.Host{ID: "host1"}, nil)
+
+	tagsService := new(services.MockTagsService)
+	tagsService.On("Create", "production", models.TagHostResourceType, "host1").Return(nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = hostsService
+	deps.tagsService = tagsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&JSONTag{"production"})
+	req := httptest.NewRequest("POST", "/api/hosts/host1/tags", bytes.NewBuffer(body))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 201, resp.Code)
+	auditService.AssertCalled(t, "Record", &models.AuditEntry{
+		Actor:        testUser,
+		Action:       models.AuditActionTagCreated,
+		ResourceType: models.TagHostResourceType,
+		ResourceID:   "host1",
+		After:        &JSONTag{"production"},
+	})
+}
@@ -0,0 +1,331 @@
+package web
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+const (
+	// ContextUserKey is the gin context key holding the logged in *models.User
+	ContextUserKey string = "user"
+	// ContextApiKeyKey is the gin context key holding the *models.ApiKey of the API requests
+	// authenticated with a bearer token
+	ContextApiKeyKey string = "api_key"
+	// ContextPersonalAccessTokenKey is the gin context key holding the *models.PersonalAccessToken
+	// of the API requests authenticated with one
+	ContextPersonalAccessTokenKey string = "personal_access_token"
+)
+
+// tokenRouteScopes are the API routes the personal access tokens can reach, with the scope they require.
+// The tokens are rejected by any other route
+var tokenRouteScopes = map[string]string{
+	"GET /api/hosts/:id/availability":            models.TokenScopeHostsRead,
+	"GET /api/hosts/:id/utilization":             models.TokenScopeHostsRead,
+	"GET /api/hosts/:id/timeline":                models.TokenScopeHostsRead,
+	"GET /api/hosts/:id/health":                  models.TokenScopeHostsRead,
+	"GET /api/hosts/:id/history":                 models.TokenScopeHostsRead,
+	"GET /api/clusters/:cluster_id/results":      models.TokenScopeClustersRead,
+	"GET /api/clusters/:cluster_id/results/runs": models.TokenScopeClustersRead,
+	"GET /api/clusters/:cluster_id/results/diff": models.TokenScopeClustersRead,
+	"GET /api/clusters/settings":                 models.TokenScopeClustersRead,
+	"GET /api/clusters/:cluster_id/health":       models.TokenScopeClustersRead,
+	"GET /api/clusters/:cluster_id/history":      models.TokenScopeClustersRead,
+	"GET /api/sapsystems/health":                 models.TokenScopeSAPSystemsRead,
+	"GET /api/sapsystems/:id/health":             models.TokenScopeSAPSystemsRead,
+	"GET /api/sapsystems/:id/history":            models.TokenScopeSAPSystemsRead,
+	"GET /api/databases/:id/health":              models.TokenScopeSAPSystemsRead,
+	"GET /api/databases/:id/history":             models.TokenScopeSAPSystemsRead,
+	"GET /api/tags":                              models.TokenScopeTagsRead,
+	"POST /api/hosts/:id/tags":                   models.TokenScopeTagsWrite,
+	"DELETE /api/hosts/:id/tags/:tag":            models.TokenScopeTagsWrite,
+	"POST /api/clusters/:id/tags":                models.TokenScopeTagsWrite,
+	"DELETE /api/clusters/:id/tags/:tag":         models.TokenScopeTagsWrite,
+	"POST /api/sapsystems/:id/tags":              models.TokenScopeTagsWrite,
+	"DELETE /api/sapsystems/:id/tags/:tag":       models.TokenScopeTagsWrite,
+	"POST /api/databases/:id/tags":               models.TokenScopeTagsWrite,
+	"DELETE /api/databases/:id/tags/:tag":        models.TokenScopeTagsWrite,
+	"GET /api/checks/catalog":                    models.TokenScopeCatalogRead,
+	"PUT /api/checks/catalog":                    models.TokenScopeCatalogWrite,
+}
+
+// publicPaths can be reached without logging in
+var publicPaths = []string{"/login", "/api/ping"}
+
+func LoginShowHandler(c *gin.Context) {
+	c.HTML(http.StatusOK, "login.html.tmpl", gin.H{
+		"Redirect": safeRedirect(c.Query("redirect")),
+	})
+}
+
+// LoginHandler holds back the logins after failed attempts, see services.LoginThrottlingPolicy.
+// The blocked logins are rejected before checking the credentials, so that guessing goes on failing
+func LoginHandler(usersService services.UsersService, loginThrottlingService services.LoginThrottlingService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.PostForm("username")
+		redirect := safeRedirect(c.PostForm("redirect"))
+		address := remoteAddress(c)
+
+		blockedUntil, err := loginThrottlingService.BlockedUntil(username, address)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if wait := time.Until(blockedUntil); wait > 0 {
+			seconds := int(math.Ceil(wait.Seconds()))
+			log.Warnf("Login of user %s from %s rejected, too many failed attempts", username, address)
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.HTML(http.StatusTooManyRequests, "login.html.tmpl", gin.H{
+				"Error":    fmt.Sprintf("Too many failed login attempts, retry in %s", time.Duration(seconds)*time.Second),
+				"Username": username,
+				"Redirect": redirect,
+			})
+			return
+		}
+
+		user, err := usersService.Authenticate(username, c.PostForm("password"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if user == nil {
+			log.Warnf("Failed login attempt for user %s from %s", username, address)
+			recordLoginFailure(c, loginThrottlingService, auditService, username, address)
+			c.HTML(http.StatusUnauthorized, "login.html.tmpl", gin.H{
+				"Error":    "Invalid username or password",
+				"Username": username,
+				"Redirect": redirect,
+			})
+			return
+		}
+
+		if err := loginThrottlingService.RecordSuccess(username); err != nil {
+			log.Errorf("Could not reset the failed logins of user %s: %s", username, err)
+		}
+
+		// a new session, and CSRF token, is issued for the new login
+		session := sessions.Default(c)
+		renewSession(session)
+		session.Set(SessionUserKey, user.Username)
+		if err := session.Save(); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Redirect(http.StatusFound, redirect)
+	}
+}
+
+// remoteAddress returns the address of the peer, rather than the forwarded ones which the clients can forge
+// to escape the limits put on their address
+func remoteAddress(c *gin.Context) string {
+	if ip, _ := c.RemoteIP(); ip != nil {
+		return ip.String()
+	}
+
+	return c.Request.RemoteAddr
+}
+
+// recordLoginFailure records the lockouts in the audit log as they start, the failures being recorded
+// only when the logins are not blocked. The failure is still reported to the user if it cannot be recorded
+func recordLoginFailure(c *gin.Context, loginThrottlingService services.LoginThrottlingService, auditService services.AuditService, username string, address string) {
+	throttles, err := loginThrottlingService.RecordFailure(username, address)
+	if err != nil {
+		log.Errorf("Could not record the failed login of user %s from %s: %s", username, address, err)
+		return
+	}
+
+	for _, throttle := range throttles {
+		if throttle.Locked {
+			log.Warnf("Logins of %s %s locked out until %s", throttle.Type, throttle.Subject, throttle.BlockedUntil)
+			recordAudit(c, auditService, models.AuditActionLoginLockedOut, models.AuditResourceLoginThrottle, throttle.Subject, nil, throttle)
+		}
+	}
+}
+
+func LogoutHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Delete(SessionUserKey)
+	session.Delete(SessionCSRFTokenKey)
+	if err := session.Save(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/login")
+}
+
+// AuthMiddleware rejects the requests of sessions not bound to an existing user,
+// otherwise the user is stored in the context for the permissions checks.
+// API requests can also be authenticated with an API key, or a personal access token, sent as a bearer token.
+// API requests get a 401, pages are redirected to the login form.
+func AuthMiddleware(usersService services.UsersService, apiKeysService services.ApiKeysService, personalAccessTokensService services.PersonalAccessTokensService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+
+		for _, p := range publicPaths {
+			if path == p {
+				c.Next()
+				return
+			}
+		}
+
+		if token, ok := bearerToken(c); ok && strings.HasPrefix(path, "/api/") {
+			if strings.HasPrefix(token, services.PersonalAccessTokenPrefix) {
+				authenticatePersonalAccessToken(c, personalAccessTokensService, token)
+				return
+			}
+
+			authenticateApiKey(c, apiKeysService, token)
+			return
+		}
+
+		if username, ok := sessions.Default(c).Get(SessionUserKey).(string); ok && username != "" {
+			user, err := usersService.GetByUsername(username)
+			if err != nil {
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+
+			// The user might have been deleted while logged in
+			if user != nil {
+				c.Set(ContextUserKey, user)
+				c.Next()
+				return
+			}
+		}
+
+		if strings.HasPrefix(path, "/api/") {
+			_ = c.Error(UnauthorizedError("authentication required"))
+			c.Abort()
+			return
+		}
+
+		c.Redirect(http.StatusFound, "/login?redirect="+url.QueryEscape(c.Request.URL.RequestURI()))
+		c.Abort()
+	}
+}
+
+// authenticateApiKey lets the API key act as a user with the role granted by its scope
+func authenticateApiKey(c *gin.Context, apiKeysService services.ApiKeysService, token string) {
+	apiKey, err := apiKeysService.Authenticate(token)
+	if err != nil {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+
+	if apiKey == nil {
+		log.Warnf("Invalid API key used from %s", c.ClientIP())
+		_ = c.Error(UnauthorizedError("invalid API key"))
+		c.Abort()
+		return
+	}
+
+	if !apiKey.Allows(c.Request.Method) {
+		_ = c.Error(ForbiddenError(fmt.Sprintf("the API key %s is read-only", apiKey.Name)))
+		c.Abort()
+		return
+	}
+
+	c.Set(ContextApiKeyKey, apiKey)
+	c.Set(ContextUserKey, &models.User{
+		Username:     "api-key:" + apiKey.Name,
+		Role:         apiKey.Role(),
+		Organization: apiKey.Organization,
+	})
+	c.Next()
+}
+
+// authenticatePersonalAccessToken lets the token act as its user, on the routes covered by its scopes only
+func authenticatePersonalAccessToken(c *gin.Context, personalAccessTokensService services.PersonalAccessTokensService, token string) {
+	personalAccessToken, user, err := personalAccessTokensService.Authenticate(token)
+	if err != nil {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+
+	if personalAccessToken == nil {
+		log.Warnf("Invalid personal access token used from %s", c.ClientIP())
+		_ = c.Error(UnauthorizedError("invalid, expired or revoked personal access token"))
+		c.Abort()
+		return
+	}
+
+	scope, ok := tokenRouteScopes[c.Request.Method+" "+c.FullPath()]
+	if !ok {
+		_ = c.Error(ForbiddenError("personal access tokens cannot be used on this route"))
+		c.Abort()
+		return
+	}
+
+	if !personalAccessToken.HasScope(scope) {
+		_ = c.Error(ForbiddenError(fmt.Sprintf("the %s scope is required", scope)))
+		c.Abort()
+		return
+	}
+
+	c.Set(ContextPersonalAccessTokenKey, personalAccessToken)
+	c.Set(ContextUserKey, user)
+	c.Next()
+}
+
+// bearerAuthenticated tells whether the request is authenticated with an API key or a personal access token,
+// instead of a session
+func bearerAuthenticated(c *gin.Context) bool {
+	_, isApiKey := c.Get(ContextApiKeyKey)
+	_, isPersonalAccessToken := c.Get(ContextPersonalAccessTokenKey)
+
+	return isApiKey || isPersonalAccessToken
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	authorization := c.GetHeader("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return "", false
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+
+	return token, token != ""
+}
+
+// RequireRole rejects with a 403 the requests of the users not granted the given role,
+// it must be used after the AuthMiddleware
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get(ContextUserKey)
+		user, ok := value.(*models.User)
+		if !ok || !user.HasRole(role) {
+			_ = c.Error(ForbiddenError(fmt.Sprintf("the %s role is required", role)))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// safeRedirect only allows redirections to paths of this same server
+func safeRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return "/"
+	}
+
+	return redirect
+}
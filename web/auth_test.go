@@ -0,0 +1,419 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions/cookie"
+	gorillaSessions "github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func setupAuthTestApp(t *testing.T, usersService services.UsersService) *App {
+	subscriptionsService := new(services.MockSubscriptionsService)
+	subscriptionsService.On("GetPremiumData").Return(&models.PremiumData{}, nil)
+
+	deps := setupTestDependencies()
+	deps.store = cookie.NewStore([]byte("secret"))
+	deps.usersService = usersService
+	deps.subscriptionsService = subscriptionsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func postLoginForm(app *App, username string, password string, redirect string) *httptest.ResponseRecorder {
+	form := url.Values{}
+	form.Set("username", username)
+	form.Set("password", password)
+	form.Set("redirect", redirect)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	app.webEngine.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func TestAuthMiddlewareUnauthenticated(t *testing.T) {
+	app := setupAuthTestApp(t, new(services.MockUsersService))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/about?tab=1", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 302, resp.Code)
+	assert.Equal(t, "/login?redirect=%2Fabout%3Ftab%3D1", resp.Header().Get("Location"))
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/tags", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 401, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/ping", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/login?redirect=/about", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), `name="redirect" value="/about"`)
+}
+
+func TestLoginHandlerInvalidCredentials(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("Authenticate", "admin", "wrong").Return(nil, nil)
+
+	app := setupAuthTestApp(t, usersService)
+
+	resp := postLoginForm(app, "admin", "wrong", "/about")
+
+	assert.Equal(t, 401, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Invalid username or password")
+	assert.Empty(t, resp.Header().Get("Set-Cookie"))
+	usersService.AssertExpectations(t)
+}
+
+func TestLoginAndLogout(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("Authenticate", "admin", "secret").Return(&models.User{ID: 1, Username: "admin"}, nil)
+	usersService.On("GetByUsername", "admin").Return(&models.User{ID: 1, Username: "admin", Role: models.UserRoleViewer}, nil)
+
+	app := setupAuthTestApp(t, usersService)
+
+	resp := postLoginForm(app, "admin", "secret", "/about")
+
+	assert.Equal(t, 302, resp.Code)
+	assert.Equal(t, "/about", resp.Header().Get("Location"))
+	sessionCookie := resp.Header().Get("Set-Cookie")
+	assert.NotEmpty(t, sessionCookie)
+
+	resp = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/about", nil)
+	req.Header.Set("Cookie", sessionCookie)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	csrfToken := resp.Header().Get(CSRFTokenHeader)
+	assert.NotEmpty(t, csrfToken)
+	sessionCookie = resp.Header().Get("Set-Cookie")
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/logout", nil)
+	req.Header.Set("Cookie", sessionCookie)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/logout", strings.NewReader(url.Values{CSRFTokenFormField: {csrfToken}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Cookie", sessionCookie)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 302, resp.Code)
+	assert.Equal(t, "/login", resp.Header().Get("Location"))
+	sessionCookie = resp.Header().Get("Set-Cookie")
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/about", nil)
+	req.Header.Set("Cookie", sessionCookie)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 302, resp.Code)
+}
+
+func TestLoginHandlerUnsafeRedirect(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("Authenticate", "admin", "secret").Return(&models.User{ID: 1, Username: "admin"}, nil)
+
+	app := setupAuthTestApp(t, usersService)
+
+	for _, redirect := range []string{"", "https://evil.example.com", "//evil.example.com", "/\\evil.example.com"} {
+		resp := postLoginForm(app, "admin", "secret", redirect)
+
+		assert.Equal(t, 302, resp.Code)
+		assert.Equal(t, "/", resp.Header().Get("Location"))
+	}
+}
+
+func TestAuthMiddlewareDeletedUser(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("Authenticate", "admin", "secret").Return(&models.User{ID: 1, Username: "admin"}, nil)
+	usersService.On("GetByUsername", "admin").Return(nil, nil)
+
+	app := setupAuthTestApp(t, usersService)
+
+	sessionCookie := postLoginForm(app, "admin", "secret", "/about").Header().Get("Set-Cookie")
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/about", nil)
+	req.Header.Set("Cookie", sessionCookie)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 302, resp.Code)
+	assert.Equal(t, "/login?redirect=%2Fabout", resp.Header().Get("Location"))
+}
+
+func TestRequireRole(t *testing.T) {
+	for _, tc := range []struct {
+		role     string
+		method   string
+		path     string
+		expected int
+	}{
+		{models.UserRoleViewer, "GET", "/api/users", 403},
+		{models.UserRoleViewer, "POST", "/api/hosts/host1/tags", 403},
+		{models.UserRoleViewer, "PUT", "/api/runner/settings", 403},
+		{models.UserRoleOperator, "POST", "/api/hosts/host1/tags", 404},
+		{models.UserRoleOperator, "PUT", "/api/runner/settings", 403},
+		{models.UserRoleOperator, "DELETE", "/api/users/2", 403},
+		{models.UserRoleAdmin, "DELETE", "/api/users/unknown", 404},
+	} {
+		hostsService := new(services.MockHostsService)
+		hostsService.On("GetByID", "host1").Return(nil, nil)
+
+		deps := setupTestDependencies()
+		deps.usersService = newMockedUsersService(tc.role)
+		deps.hostsService = hostsService
+
+		app, err := NewAppWithDeps(setupTestConfig(), deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader("{}"))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, "%s %s %s", tc.role, tc.method, tc.path)
+	}
+}
+
+func TestAuthMiddlewareApiKey(t *testing.T) {
+	for _, tc := range []struct {
+		key      string
+		method   string
+		path     string
+		expected int
+	}{
+		{"read-key", "GET", "/api/hosts/host1/timeline", 404},
+		{"read-key", "POST", "/api/hosts/host1/tags", 403},
+		{"write-key", "POST", "/api/hosts/host1/tags", 404},
+		{"write-key", "GET", "/api/users", 403},
+		{"revoked-key", "GET", "/api/hosts/host1/timeline", 401},
+		{"write-key", "GET", "/about", 302},
+	} {
+		apiKeysService := new(services.MockApiKeysService)
+		apiKeysService.On("Authenticate", "read-key").Return(&models.ApiKey{ID: 1, Name: "dashboard", Scope: models.ApiKeyScopeRead}, nil)
+		apiKeysService.On("Authenticate", "write-key").Return(&models.ApiKey{ID: 2, Name: "runner", Scope: models.ApiKeyScopeWrite}, nil)
+		apiKeysService.On("Authenticate", "revoked-key").Return(nil, nil)
+
+		hostsService := new(services.MockHostsService)
+		hostsService.On("GetByID", "host1").Return(nil, nil)
+
+		deps := setupTestDependencies()
+		deps.store = cookie.NewStore([]byte("secret"))
+		deps.apiKeysService = apiKeysService
+		deps.hostsService = hostsService
+
+		app, err := NewAppWithDeps(setupTestConfig(), deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader("{}"))
+		req.Header.Set("Authorization", "Bearer "+tc.key)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, "%s %s %s", tc.key, tc.method, tc.path)
+	}
+}
+
+func TestAuthMiddlewareApiKeyOrganization(t *testing.T) {
+	apiKeysService := new(services.MockApiKeysService)
+	apiKeysService.On("Authenticate", "acme-key").Return(&models.ApiKey{ID: 1, Name: "dashboard", Scope: models.ApiKeyScopeRead, Organization: "acme"}, nil)
+
+	deps := setupTestDependencies()
+	deps.store = cookie.NewStore([]byte("secret"))
+	deps.apiKeysService = apiKeysService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer acme-key")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"organization":"acme"`)
+}
+
+func TestLoginHandlerForwardedAddress(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("Authenticate", "admin", "wrong").Return(nil, nil)
+
+	loginThrottlingService := new(services.MockLoginThrottlingService)
+	loginThrottlingService.On("BlockedUntil", "admin", "192.0.2.1").Return(time.Time{}, nil)
+	loginThrottlingService.On("RecordFailure", "admin", "192.0.2.1").Return([]*models.LoginThrottle{}, nil)
+
+	deps := setupTestDependencies()
+	deps.store = cookie.NewStore([]byte("secret"))
+	deps.usersService = usersService
+	deps.loginThrottlingService = loginThrottlingService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{}
+	form.Set("username", "admin")
+	form.Set("password", "wrong")
+
+	// the forged address is ignored, the failures being recorded against the peer
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 401, resp.Code)
+	loginThrottlingService.AssertExpectations(t)
+}
+
+func TestLoginHandlerThrottled(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("Authenticate", "admin", "wrong").Return(nil, nil)
+
+	lockout := &models.LoginThrottle{
+		Type:         models.LoginThrottleAddress,
+		Subject:      "192.0.2.1",
+		Failures:     10,
+		BlockedUntil: time.Now().Add(15 * time.Minute),
+		Locked:       true,
+	}
+
+	loginThrottlingService := new(services.MockLoginThrottlingService)
+	loginThrottlingService.On("BlockedUntil", "admin", "192.0.2.1").Return(time.Time{}, nil).Once()
+	loginThrottlingService.On("RecordFailure", "admin", "192.0.2.1").Return([]*models.LoginThrottle{
+		{Type: models.LoginThrottleUser, Subject: "admin", Failures: 3, BlockedUntil: time.Now().Add(4 * time.Second)},
+		lockout,
+	}, nil)
+	loginThrottlingService.On("BlockedUntil", "admin", "192.0.2.1").Return(lockout.BlockedUntil, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	subscriptionsService := new(services.MockSubscriptionsService)
+	subscriptionsService.On("GetPremiumData").Return(&models.PremiumData{}, nil)
+
+	deps := setupTestDependencies()
+	deps.store = cookie.NewStore([]byte("secret"))
+	deps.usersService = usersService
+	deps.subscriptionsService = subscriptionsService
+	deps.loginThrottlingService = loginThrottlingService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := postLoginForm(app, "admin", "wrong", "/about")
+	assert.Equal(t, 401, resp.Code)
+
+	auditService.AssertNumberOfCalls(t, "Record", 1)
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionLoginLockedOut && entry.ResourceID == "192.0.2.1"
+	}))
+
+	// the credentials are not checked anymore
+	resp = postLoginForm(app, "admin", "secret", "/about")
+	assert.Equal(t, 429, resp.Code)
+	assert.Equal(t, "900", resp.Header().Get("Retry-After"))
+	assert.Contains(t, resp.Body.String(), "Too many failed login attempts, retry in 15m0s")
+	assert.Empty(t, resp.Header().Get("Set-Cookie"))
+
+	usersService.AssertNumberOfCalls(t, "Authenticate", 1)
+}
+
+// plantedSessionStore hands out the session an attacker would plant in the browser before the login,
+// recording the ID of the session saved by the login
+type plantedSessionStore struct {
+	cookie.Store
+	savedID *string
+}
+
+func (s plantedSessionStore) Get(r *http.Request, name string) (*gorillaSessions.Session, error) {
+	return gorillaSessions.GetRegistry(r).Get(s, name)
+}
+
+func (s plantedSessionStore) New(r *http.Request, name string) (*gorillaSessions.Session, error) {
+	session, err := s.Store.New(r, name)
+	session.ID = "planted-id"
+	session.Values[SessionImpersonatedUserKey] = "victim"
+	session.Values[SessionCSRFTokenKey] = "planted-csrf-token"
+
+	return session, err
+}
+
+func (s plantedSessionStore) Save(r *http.Request, w http.ResponseWriter, session *gorillaSessions.Session) error {
+	*s.savedID = session.ID
+	return s.Store.Save(r, w, session)
+}
+
+func TestLoginHandlerRenewsSession(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("Authenticate", "admin", "secret").Return(&models.User{ID: 1, Username: "admin"}, nil)
+
+	var savedID string
+	deps := setupTestDependencies()
+	deps.store = plantedSessionStore{cookie.NewStore([]byte("secret")), &savedID}
+	deps.usersService = usersService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := postLoginForm(app, "admin", "secret", "/")
+	assert.Equal(t, 302, resp.Code)
+
+	// the stores keeping the sessions server side issue a new ID
+	assert.Empty(t, savedID)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Cookie", resp.Header().Get("Set-Cookie"))
+	session, err := cookie.NewStore([]byte("secret")).Get(req, "session")
+	assert.NoError(t, err)
+	assert.Equal(t, map[interface{}]interface{}{SessionUserKey: "admin"}, session.Values)
+}
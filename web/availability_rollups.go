@@ -0,0 +1,51 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/services"
+)
+
+var availabilityRollupsInterval = 10 * time.Minute
+
+// AvailabilityRollupsJob periodically rolls up the heartbeat periods of the hosts in hourly and daily availability
+// aggregates, pruning the periods rolled up once older than the retention
+type AvailabilityRollupsJob struct {
+	availabilityService services.AvailabilityService
+	retention           time.Duration
+}
+
+func NewAvailabilityRollupsJob(availabilityService services.AvailabilityService, retention time.Duration) *AvailabilityRollupsJob {
+	return &AvailabilityRollupsJob{availabilityService: availabilityService, retention: retention}
+}
+
+func (j *AvailabilityRollupsJob) Start(ctx context.Context) {
+	log.Infof("Starting availability rollups job")
+
+	internal.Repeat("web.availability_rollups", j.rollup, availabilityRollupsInterval, ctx)
+}
+
+func (j *AvailabilityRollupsJob) rollup() {
+	if err := j.availabilityService.Rollup(); err != nil {
+		log.Errorf("Error while rolling up the heartbeats: %s", err)
+		return
+	}
+
+	if j.retention <= 0 {
+		return
+	}
+
+	pruned, err := j.availabilityService.Prune(j.retention)
+	if err != nil {
+		log.Errorf("Error while pruning the heartbeat periods: %s", err)
+		return
+	}
+
+	if pruned > 0 {
+		log.Infof("%d heartbeat periods rolled up pruned", pruned)
+	}
+}
@@ -0,0 +1,38 @@
+package web
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestAvailabilityRollupsJob(t *testing.T) {
+	availabilityService := new(services.MockAvailabilityService)
+	availabilityService.On("Rollup").Return(nil)
+	availabilityService.On("Prune", 30*24*time.Hour).Return(int64(3), nil)
+
+	NewAvailabilityRollupsJob(availabilityService, 30*24*time.Hour).rollup()
+
+	availabilityService.AssertExpectations(t)
+}
+
+func TestAvailabilityRollupsJobNoRetention(t *testing.T) {
+	availabilityService := new(services.MockAvailabilityService)
+	availabilityService.On("Rollup").Return(nil)
+
+	NewAvailabilityRollupsJob(availabilityService, 0).rollup()
+
+	availabilityService.AssertExpectations(t)
+	availabilityService.AssertNotCalled(t, "Prune")
+}
+
+func TestAvailabilityRollupsJobRollupError(t *testing.T) {
+	availabilityService := new(services.MockAvailabilityService)
+	availabilityService.On("Rollup").Return(errors.New("kaboom"))
+
+	NewAvailabilityRollupsJob(availabilityService, 30*24*time.Hour).rollup()
+
+	availabilityService.AssertNotCalled(t, "Prune")
+}
@@ -0,0 +1,184 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type JSONHostBaseline struct {
+	AgentID string `json:"agent_id" binding:"required"`
+}
+
+func NewBaselinesHandler(baselinesService services.BaselinesService, hostsService services.HostsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		baselines, err := baselinesService.GetAll()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		roles, err := hostsService.GetAllTags()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		role := c.Query("role")
+		if role == "" && len(baselines) > 0 {
+			role = baselines[0].Role
+		}
+
+		var report *models.BaselineReport
+		var hosts models.HostList
+		if role != "" {
+			report, err = baselinesService.GetReport(role)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+
+			hosts, err = hostsService.GetAll(&services.HostsFilter{Tags: []string{role}, Organization: userOrganization(c)}, nil)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+
+		c.HTML(http.StatusOK, "baselines.html.tmpl", gin.H{
+			"Baselines": baselines,
+			"Roles":     roles,
+			"Role":      role,
+			"Hosts":     hosts,
+			"Report":    report,
+		})
+	}
+}
+
+// ApiListBaselinesHandler godoc
+// @Summary Retrieve the golden hosts of the roles
+// @Produce json
+// @Success 200 {array} models.HostBaseline
+// @Failure 500 {object} map[string]string
+// @Router /baselines [get]
+func ApiListBaselinesHandler(baselinesService services.BaselinesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		baselines, err := baselinesService.GetAll()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, baselines)
+	}
+}
+
+// ApiGetBaselineReportHandler godoc
+// @Summary Compare the configuration of the hosts tagged with the role against its golden host
+// @Description The host discovery, the subscriptions and the profiles of the SAP systems last collected are compared,
+// @Description leaving out the settings identifying the hosts, like the hostname and the IP addresses
+// @Produce json
+// @Param role path string true "Role, the tag of the hosts"
+// @Success 200 {object} models.BaselineReport
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /baselines/{role}/report [get]
+func ApiGetBaselineReportHandler(baselinesService services.BaselinesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := baselinesService.GetReport(c.Param("role"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if report == nil {
+			_ = c.Error(NotFoundError("the role has no golden host"))
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// ApiSaveBaselineHandler godoc
+// @Summary Make a host the golden one of the role
+// @Accept json
+// @Produce json
+// @Param role path string true "Role, the tag of the hosts"
+// @Param Body body JSONHostBaseline true "The golden host"
+// @Success 200 {object} models.HostBaseline
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /baselines/{role} [put]
+func ApiSaveBaselineHandler(baselinesService services.BaselinesService, hostsService services.HostsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.Param("role")
+
+		if err := validateText("role", role, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		var r JSONHostBaseline
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		host, err := hostsService.GetByID(r.AgentID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		baseline, err := baselinesService.Save(role, r.AgentID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionBaselineSaved, models.AuditResourceBaseline, role, nil, baseline)
+
+		c.JSON(http.StatusOK, baseline)
+	}
+}
+
+// ApiDeleteBaselineHandler godoc
+// @Summary Stop comparing the hosts of the role against a golden host
+// @Produce json
+// @Param role path string true "Role, the tag of the hosts"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /baselines/{role} [delete]
+func ApiDeleteBaselineHandler(baselinesService services.BaselinesService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.Param("role")
+
+		baseline, err := baselinesService.Delete(role)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if baseline == nil {
+			_ = c.Error(NotFoundError("the role has no golden host"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionBaselineDeleted, models.AuditResourceBaseline, role, baseline, nil)
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,222 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/html"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func baselineReportFixture() *models.BaselineReport {
+	return &models.BaselineReport{
+		Baseline: &models.HostBaseline{
+			Role:      "app-servers",
+			AgentID:   "host1",
+			UpdatedAt: time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC),
+		},
+		GoldenHostName: "netweaver01",
+		Hosts: []*models.HostDrift{
+			{
+				AgentID:  "host2",
+				HostName: "netweaver02",
+				Differences: []*models.ConfigurationDifference{
+					{
+						DiscoveryType: "sap_system_discovery",
+						Path:          "NWP/Profile/rdisp/wp_no_dia",
+						Kind:          models.DifferenceChanged,
+						Expected:      `"10"`,
+						Actual:        `"8"`,
+					},
+				},
+			},
+			{
+				AgentID:     "host3",
+				HostName:    "netweaver03",
+				Differences: []*models.ConfigurationDifference{},
+			},
+		},
+	}
+}
+
+func TestBaselinesHandler(t *testing.T) {
+	report := baselineReportFixture()
+
+	baselinesService := new(services.MockBaselinesService)
+	baselinesService.On("GetAll").Return([]*models.HostBaseline{report.Baseline}, nil)
+	baselinesService.On("GetReport", "app-servers").Return(report, nil)
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetAllTags").Return([]string{"app-servers", "databases"}, nil)
+	hostsService.On("GetAll", &services.HostsFilter{Tags: []string{"app-servers"}}, (*services.Page)(nil)).Return(models.HostList{
+		{ID: "host1", Name: "netweaver01"},
+		{ID: "host2", Name: "netweaver02"},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.baselinesService = baselinesService
+	deps.hostsService = hostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/baselines", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Regexp(t, regexp.MustCompile(`<td><a href="/baselines\?role=app-servers">app-servers</a></td><td><a href=/hosts/host1>host1</a></td>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<option value=host1 selected>netweaver01</option><option value=host2>netweaver02</option>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td><a href=/hosts/host2>netweaver02</a></td><td>sap_system_discovery</td><td><code>NWP/Profile/rdisp/wp_no_dia</code></td><td>changed</td><td><code>"10"</code></td><td><code>"8"</code></td>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td><a href=/hosts/host3>netweaver03</a></td><td colspan=5><span class="badge badge-pill badge-success">no drift</span></td>`), minified)
+}
+
+func TestApiGetBaselineReportHandler(t *testing.T) {
+	baselinesService := new(services.MockBaselinesService)
+	baselinesService.On("GetReport", "app-servers").Return(baselineReportFixture(), nil)
+	baselinesService.On("GetReport", "databases").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.baselinesService = baselinesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/baselines/app-servers/report", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"baseline": {"role": "app-servers", "agent_id": "host1", "updated_at": "2022-03-12T10:00:00Z"},
+		"golden_hostname": "netweaver01",
+		"hosts": [
+			{
+				"agent_id": "host2",
+				"hostname": "netweaver02",
+				"differences": [
+					{"discovery_type": "sap_system_discovery", "path": "NWP/Profile/rdisp/wp_no_dia", "kind": "changed", "expected": "\"10\"", "actual": "\"8\""}
+				]
+			},
+			{"agent_id": "host3", "hostname": "netweaver03", "differences": []}
+		]
+	}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/baselines/databases/report", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiBaselinesHandlers(t *testing.T) {
+	baseline := baselineReportFixture().Baseline
+
+	baselinesService := new(services.MockBaselinesService)
+	baselinesService.On("Save", "app-servers", "host1").Return(baseline, nil)
+	baselinesService.On("Delete", "app-servers").Return(baseline, nil)
+	baselinesService.On("Delete", "databases").Return(nil, nil)
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "host1").Return(&models.Host{ID: "host1"}, nil)
+	hostsService.On("GetByID", "host4").Return(nil, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.baselinesService = baselinesService
+	deps.hostsService = hostsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		url      string
+		body     string
+		expected int
+	}{
+		{"/api/baselines/app-servers", `{"agent_id": "host1"}`, 200},
+		{"/api/baselines/app-servers", `{"agent_id": "host4"}`, 404},
+		{"/api/baselines/app-servers", `{}`, 400},
+		{"/api/baselines/app%00servers", `{"agent_id": "host1"}`, 400},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", tc.url, bytes.NewBufferString(tc.body))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, tc.url+" "+tc.body)
+	}
+
+	for url, expected := range map[string]int{
+		"/api/baselines/app-servers": 204,
+		"/api/baselines/databases":   404,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("DELETE", url, nil)
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code, url)
+	}
+
+	auditService.AssertNumberOfCalls(t, "Record", 2)
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionBaselineSaved && entry.ResourceID == "app-servers"
+	}))
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionBaselineDeleted && entry.ResourceID == "app-servers"
+	}))
+}
+
+func TestApiBaselinesHandlersForbidden(t *testing.T) {
+	baselinesService := new(services.MockBaselinesService)
+
+	deps := setupTestDependencies()
+	deps.usersService = newMockedUsersService(models.UserRoleOperator)
+	deps.baselinesService = baselinesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/api/baselines/app-servers", bytes.NewBufferString(`{"agent_id": "host1"}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+	baselinesService.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
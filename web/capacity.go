@@ -0,0 +1,42 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+func NewCapacityHandler(capacityService services.CapacityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		overview, err := capacityService.GetCapacityOverview(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.HTML(http.StatusOK, "capacity.html.tmpl", gin.H{
+			"Overview": overview,
+		})
+	}
+}
+
+// ApiGetCapacityOverviewHandler godoc
+// @Summary Retrieve the memory allocated by the HANA databases compared to the physical memory, per host and per SAP system
+// @Description The hosts of the organization of the user only, if any
+// @Produce json
+// @Success 200 {object} models.CapacityOverview
+// @Failure 500 {object} map[string]string
+// @Router /capacity [get]
+func ApiGetCapacityOverviewHandler(capacityService services.CapacityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		overview, err := capacityService.GetCapacityOverview(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, overview)
+	}
+}
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/html"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func capacityOverviewFixture() *models.CapacityOverview {
+	return &models.CapacityOverview{
+		Hosts: []*models.HostCapacity{
+			{
+				ID:                "host1",
+				Name:              "hana01",
+				Hypervisor:        "kvm",
+				Sockets:           1,
+				Cores:             4,
+				Threads:           8,
+				PhysicalMemoryMB:  65536,
+				AllocatedMemoryMB: 81920,
+				Allocations: []*models.MemoryAllocation{
+					{SAPSystemID: "sys1", SID: "PRD", InstanceNumber: "00", AllocatedMemoryMB: 81920},
+				},
+				OverCommitted: true,
+			},
+			{
+				ID:                "host2",
+				Name:              "hana02",
+				Sockets:           2,
+				Cores:             8,
+				Threads:           16,
+				PhysicalMemoryMB:  65536,
+				AllocatedMemoryMB: 58982,
+				Allocations: []*models.MemoryAllocation{
+					{SAPSystemID: "sys2", SID: "QAS", InstanceNumber: "10", AllocatedMemoryMB: 58982, Default: true},
+				},
+			},
+		},
+		SAPSystems: []*models.SAPSystemCapacity{
+			{ID: "sys1", SID: "PRD", HostsCount: 1, PhysicalMemoryMB: 65536, AllocatedMemoryMB: 81920, OverCommitted: true},
+			{ID: "sys2", SID: "QAS", HostsCount: 1, PhysicalMemoryMB: 65536, AllocatedMemoryMB: 58982},
+		},
+	}
+}
+
+func TestCapacityHandler(t *testing.T) {
+	capacityService := new(services.MockCapacityService)
+	capacityService.On("GetCapacityOverview", "").Return(capacityOverviewFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.capacityService = capacityService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/capacity", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Regexp(t, regexp.MustCompile(`<td><a href=/hosts/host1>hana01</a></td><td>kvm</td><td>1</td><td>4</td><td>8</td><td>65536 MB</td><td>81920 MB \(125%\)\s*<span class="badge badge-pill badge-danger">over-committed</span></td><td><a href=/databases/sys1>PRD</a> 00: 81920 MB<br></td>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td><a href=/hosts/host2>hana02</a></td><td>-</td>.*<td>58982 MB \(90%\)</td><td><a href=/databases/sys2>QAS</a> 10: 58982 MB \(default\)<br></td>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td><a href=/databases/sys1>PRD</a></td><td>1</td><td>65536 MB</td><td>81920 MB \(125%\)\s*<span class="badge badge-pill badge-danger">over-committed</span></td>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td><a href=/databases/sys2>QAS</a></td><td>1</td><td>65536 MB</td><td>58982 MB \(90%\)</td>`), minified)
+}
+
+func TestApiGetCapacityOverviewHandler(t *testing.T) {
+	capacityService := new(services.MockCapacityService)
+	capacityService.On("GetCapacityOverview", "").Return(capacityOverviewFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.capacityService = capacityService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/capacity", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"hosts": [
+			{
+				"id": "host1", "name": "hana01", "hypervisor": "kvm", "sockets": 1, "cores": 4, "threads": 8,
+				"physical_memory_mb": 65536, "allocated_memory_mb": 81920, "over_committed": true,
+				"allocations": [{"sap_system_id": "sys1", "sid": "PRD", "instance_number": "00", "allocated_memory_mb": 81920, "default": false}]
+			},
+			{
+				"id": "host2", "name": "hana02", "hypervisor": "", "sockets": 2, "cores": 8, "threads": 16,
+				"physical_memory_mb": 65536, "allocated_memory_mb": 58982, "over_committed": false,
+				"allocations": [{"sap_system_id": "sys2", "sid": "QAS", "instance_number": "10", "allocated_memory_mb": 58982, "default": true}]
+			}
+		],
+		"sap_systems": [
+			{"id": "sys1", "sid": "PRD", "hosts_count": 1, "physical_memory_mb": 65536, "allocated_memory_mb": 81920, "over_committed": true},
+			{"id": "sys2", "sid": "QAS", "hosts_count": 1, "physical_memory_mb": 65536, "allocated_memory_mb": 58982, "over_committed": false}
+		]
+	}`, resp.Body.String())
+}
@@ -0,0 +1,265 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// certificateReloadInterval at which the certificate files are checked for changes.
+// The files are polled rather than watched, as the renewed ones are often swapped with symlinks
+var certificateReloadInterval = time.Minute
+
+// CertificateReloader serves the collector server certificate, the agents CA and the list of the revoked
+// agent certificates, reloading them when the files change, so that renewed ones take effect without a restart
+type CertificateReloader struct {
+	cert        string
+	key         string
+	ca          string
+	crl         string
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	revocations *revocationList
+	contents    [][]byte
+}
+
+// revocationList holds the serial numbers of the certificates revoked by an issuer
+type revocationList struct {
+	issuer     string
+	serials    map[string]bool
+	nextUpdate time.Time
+}
+
+// NewCertificateReloader loads the certificates, the CRL is optional
+func NewCertificateReloader(cert string, key string, ca string, crl string) (*CertificateReloader, error) {
+	r := &CertificateReloader{cert: cert, key: key, ca: ca, crl: crl}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload loads the certificates again if the files changed, returning whether they did.
+// On error the certificates loaded before are kept
+func (r *CertificateReloader) Reload() (bool, error) {
+	files := []string{r.cert, r.key, r.ca}
+	if r.crl != "" {
+		files = append(files, r.crl)
+	}
+
+	var contents [][]byte
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return false, err
+		}
+		contents = append(contents, content)
+	}
+
+	if r.unchanged(contents) {
+		return false, nil
+	}
+
+	certificate, err := tls.X509KeyPair(contents[0], contents[1])
+	if err != nil {
+		return false, err
+	}
+
+	caCertificates, err := parseCertificates(contents[2])
+	if err != nil || len(caCertificates) == 0 {
+		return false, fmt.Errorf("no valid certificate found in the CA file %s", r.ca)
+	}
+
+	clientCAs := x509.NewCertPool()
+	for _, caCertificate := range caCertificates {
+		clientCAs.AddCert(caCertificate)
+	}
+
+	var revocations *revocationList
+	if r.crl != "" {
+		revocations, err = parseRevocationList(contents[3], caCertificates)
+		if err != nil {
+			return false, fmt.Errorf("invalid CRL file %s: %s", r.crl, err)
+		}
+		if time.Now().After(revocations.nextUpdate) {
+			log.Warnf("The CRL %s is past its next update, %s, it should be refreshed", r.crl, revocations.nextUpdate)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.revocations = revocations
+	r.contents = contents
+
+	return true, nil
+}
+
+func parseCertificates(content []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			return certificates, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+}
+
+// parseRevocationList parses a PEM or DER encoded CRL, which must be signed by one of the CAs
+func parseRevocationList(content []byte, caCertificates []*x509.Certificate) (*revocationList, error) {
+	if block, _ := pem.Decode(content); block != nil {
+		if block.Type != "X509 CRL" {
+			return nil, fmt.Errorf("unexpected PEM block %s", block.Type)
+		}
+		content = block.Bytes
+	}
+
+	crl, err := x509.ParseRevocationList(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// the revocations are only applied once the CRL is known to be issued by one of the CAs
+	signed := false
+	for _, caCertificate := range caCertificates {
+		if bytes.Equal(caCertificate.RawSubject, crl.RawIssuer) && crl.CheckSignatureFrom(caCertificate) == nil {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		return nil, fmt.Errorf("not signed by the CA")
+	}
+
+	revocations := &revocationList{
+		issuer:     crl.Issuer.String(),
+		serials:    make(map[string]bool),
+		nextUpdate: crl.NextUpdate,
+	}
+	for _, revoked := range crl.RevokedCertificateEntries {
+		revocations.serials[revoked.SerialNumber.String()] = true
+	}
+
+	return revocations, nil
+}
+
+func (r *CertificateReloader) unchanged(contents [][]byte) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if len(r.contents) != len(contents) {
+		return false
+	}
+
+	for i := range contents {
+		if !bytes.Equal(r.contents[i], contents[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Start checks the files for changes until the context is done
+func (r *CertificateReloader) Start(ctx context.Context) {
+	ticker := time.NewTicker(certificateReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				log.Errorf("Could not reload the collector certificates, the previous ones are kept: %s", err)
+				continue
+			}
+			if reloaded {
+				log.Info("Collector certificates reloaded")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// TLSConfig returns the TLS configuration of the collector server, resolving the certificates at every handshake
+func (r *CertificateReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.Certificate(), nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				ClientCAs:             r.ClientCAs(),
+				ClientAuth:            tls.RequireAndVerifyClientCert,
+				Certificates:          []tls.Certificate{*r.Certificate()},
+				VerifyPeerCertificate: r.verifyNotRevoked,
+			}, nil
+		},
+	}
+}
+
+// verifyNotRevoked rejects the agent certificates in the CRL, after they have been verified against the CA
+func (r *CertificateReloader) verifyNotRevoked(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+	r.mutex.RLock()
+	revocations := r.revocations
+	r.mutex.RUnlock()
+
+	if revocations == nil {
+		return nil
+	}
+
+	for _, chain := range verifiedChains {
+		if len(chain) == 0 {
+			continue
+		}
+
+		certificate := chain[0]
+		if certificate.Issuer.String() == revocations.issuer && revocations.serials[certificate.SerialNumber.String()] {
+			rejectedAgentCertificates.WithLabelValues(certificateRevoked).Inc()
+			log.Warnf("Rejected the revoked agent certificate %s, serial number %s",
+				certificate.Subject.CommonName, certificate.SerialNumber)
+			return fmt.Errorf("certificate %s revoked", certificate.SerialNumber)
+		}
+	}
+
+	return nil
+}
+
+func (r *CertificateReloader) Certificate() *tls.Certificate {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.certificate
+}
+
+func (r *CertificateReloader) ClientCAs() *x509.CertPool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.clientCAs
+}
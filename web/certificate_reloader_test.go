@@ -0,0 +1,208 @@
+package web
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	_ "github.com/trento-project/trento/test"
+)
+
+func copyCertificateFile(t *testing.T, src string, dst string) {
+	content, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(dst, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+	ca := filepath.Join(dir, "ca.pem")
+
+	copyCertificateFile(t, "./test/certs/server-cert.pem", cert)
+	copyCertificateFile(t, "./test/certs/server-key.pem", key)
+	copyCertificateFile(t, "./test/certs/ca-cert.pem", ca)
+
+	reloader, err := NewCertificateReloader(cert, key, ca, "")
+	assert.NoError(t, err)
+
+	tlsConfig := reloader.TLSConfig()
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+
+	serverCertificate, err := tlsConfig.GetCertificate(nil)
+	assert.NoError(t, err)
+
+	reloaded, err := reloader.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// a renewed certificate
+	copyCertificateFile(t, "./test/certs/client-cert.pem", cert)
+	copyCertificateFile(t, "./test/certs/client-key.pem", key)
+
+	reloaded, err = reloader.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+
+	clientConfig, err := tlsConfig.GetConfigForClient(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(clientConfig.Certificates))
+	assert.NotEqual(t, serverCertificate.Certificate[0], clientConfig.Certificates[0].Certificate[0])
+	assert.Equal(t, reloader.ClientCAs(), clientConfig.ClientCAs)
+
+	// a certificate not matching its key is not picked up
+	copyCertificateFile(t, "./test/certs/server-cert.pem", cert)
+
+	reloaded, err = reloader.Reload()
+	assert.Error(t, err)
+	assert.False(t, reloaded)
+
+	certificate, err := tlsConfig.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, clientConfig.Certificates[0].Certificate[0], certificate.Certificate[0])
+}
+
+func TestNewCertificateReloaderMissingFiles(t *testing.T) {
+	_, err := NewCertificateReloader("./test/certs/server-cert.pem", "./test/certs/server-key.pem", "./test/certs/missing.pem", "")
+	assert.Error(t, err)
+}
+
+func readTestCertificate(t *testing.T, file string) *x509.Certificate {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(content)
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return certificate
+}
+
+// writeTestCRL writes a CRL revoking the given serial numbers, signed with the key
+func writeTestCRL(t *testing.T, file string, signer crypto.Signer, serials ...int64) {
+	ca := readTestCertificate(t, "./test/certs/ca-cert.pem")
+	// the test CA has no extensions, which are required to issue a CRL
+	ca.KeyUsage = x509.KeyUsageCRLSign
+	ca.SubjectKeyId = []byte{1}
+
+	var revoked []pkix.RevokedCertificate
+	for _, serial := range serials {
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:              big.NewInt(1),
+		ThisUpdate:          time.Now(),
+		NextUpdate:          time.Now().Add(time.Hour),
+		RevokedCertificates: revoked,
+	}, ca, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestCAKey(t *testing.T) crypto.Signer {
+	content, err := ioutil.ReadFile("./test/certs/ca-key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(content)
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestCertificateReloaderRevocationList(t *testing.T) {
+	crl := filepath.Join(t.TempDir(), "crl.pem")
+	writeTestCRL(t, crl, readTestCAKey(t), 2)
+
+	reloader, err := NewCertificateReloader("./test/certs/server-cert.pem", "./test/certs/server-key.pem", "./test/certs/ca-cert.pem", crl)
+	assert.NoError(t, err)
+
+	clientConfig, err := reloader.TLSConfig().GetConfigForClient(nil)
+	assert.NoError(t, err)
+
+	agentCertificate := readTestCertificate(t, "./test/certs/client-cert.pem")
+	chains := [][]*x509.Certificate{{agentCertificate}}
+
+	assert.NoError(t, clientConfig.VerifyPeerCertificate(nil, chains))
+
+	// the agent certificate is revoked
+	writeTestCRL(t, crl, readTestCAKey(t), 1, 2)
+	reloaded, err := reloader.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+
+	rejected := testutil.ToFloat64(rejectedAgentCertificates.WithLabelValues(certificateRevoked))
+	assert.Error(t, clientConfig.VerifyPeerCertificate(nil, chains))
+	assert.Equal(t, rejected+1, testutil.ToFloat64(rejectedAgentCertificates.WithLabelValues(certificateRevoked)))
+
+	// a CRL not signed by the CA is not picked up
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestCRL(t, crl, otherKey)
+
+	_, err = reloader.Reload()
+	assert.Error(t, err)
+	assert.Error(t, clientConfig.VerifyPeerCertificate(nil, chains))
+}
+
+func TestParseRevocationList(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "crl.pem")
+	writeTestCRL(t, file, readTestCAKey(t), 1, 2)
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(content)
+
+	ca := readTestCertificate(t, "./test/certs/ca-cert.pem")
+
+	// the DER encoded CRLs are accepted as well
+	for _, encoded := range [][]byte{content, block.Bytes} {
+		revocations, err := parseRevocationList(encoded, []*x509.Certificate{ca})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"1": true, "2": true}, revocations.serials)
+		assert.Equal(t, ca.Subject.String(), revocations.issuer)
+	}
+
+	// the revocations of a CRL not verified against the CAs are not applied
+	otherCA := readTestCertificate(t, "./test/certs/client-cert.pem")
+	_, err = parseRevocationList(content, []*x509.Certificate{otherCA})
+	assert.Error(t, err)
+
+	_, err = parseRevocationList(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), []*x509.Certificate{ca})
+	assert.Error(t, err)
+}
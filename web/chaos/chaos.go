@@ -0,0 +1,138 @@
+// Package chaos provides fault injection hooks to verify how Trento copes with failures
+// (retries, degraded mode, ...) without changing the code. It must never be enabled in production.
+package chaos
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/web/datapipeline"
+	"gorm.io/gorm"
+)
+
+var ErrInjectedFault = errors.New("chaos: injected fault")
+
+var randFloat = rand.Float64
+
+type Config struct {
+	// Probability, between 0 and 1, of a database operation to fail
+	DBErrorRate float64
+	// Probability, between 0 and 1, of a checks results submission to time out
+	ChecksResultsTimeoutRate float64
+	// Time to wait before failing a checks results submission
+	ChecksResultsTimeout time.Duration
+	// Time every projection is delayed by
+	ProjectionDelay time.Duration
+}
+
+type Injector struct {
+	config Config
+}
+
+// NewInjector returns an injector for the given configuration, a nil configuration disables all the faults
+func NewInjector(config *Config) *Injector {
+	injector := &Injector{}
+	if config != nil {
+		injector.config = *config
+	}
+
+	return injector
+}
+
+func (i *Injector) Enabled() bool {
+	return i.config.DBErrorRate > 0 || i.config.ChecksResultsTimeoutRate > 0 || i.config.ProjectionDelay > 0
+}
+
+// ShouldTimeoutChecksResults tells whether the current checks results submission has to time out
+func (i *Injector) ShouldTimeoutChecksResults() bool {
+	return fault(i.config.ChecksResultsTimeoutRate)
+}
+
+func (i *Injector) ChecksResultsTimeout() time.Duration {
+	return i.config.ChecksResultsTimeout
+}
+
+// RegisterDBFaults makes the database operations randomly fail with ErrInjectedFault
+func (i *Injector) RegisterDBFaults(db *gorm.DB) error {
+	if i.config.DBErrorRate <= 0 {
+		return nil
+	}
+
+	log.Warnf("Chaos: database operations will fail with a rate of %.2f", i.config.DBErrorRate)
+
+	inject := func(tx *gorm.DB) {
+		if fault(i.config.DBErrorRate) {
+			log.Debugf("Chaos: injecting a database fault")
+			_ = tx.AddError(ErrInjectedFault)
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("chaos:create", inject); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("chaos:query", inject); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("chaos:update", inject); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("chaos:delete", inject); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("chaos:row", inject); err != nil {
+		return err
+	}
+
+	return callbacks.Raw().Before("gorm:raw").Register("chaos:raw", inject)
+}
+
+// WrapProjectors delays every projection of the registered projectors
+func (i *Injector) WrapProjectors(registry datapipeline.ProjectorRegistry) datapipeline.ProjectorRegistry {
+	if i.config.ProjectionDelay <= 0 {
+		return registry
+	}
+
+	log.Warnf("Chaos: projections will be delayed by %s", i.config.ProjectionDelay)
+
+	wrapped := datapipeline.ProjectorRegistry{}
+	for _, projector := range registry {
+		wrapped = append(wrapped, &delayedProjector{
+			projector: projector,
+			delay:     i.config.ProjectionDelay,
+		})
+	}
+
+	return wrapped
+}
+
+type delayedProjector struct {
+	projector datapipeline.Projector
+	delay     time.Duration
+}
+
+func (p *delayedProjector) Project(dataCollectedEvent *datapipeline.DataCollectedEvent) error {
+	time.Sleep(p.delay)
+
+	return p.projector.Project(dataCollectedEvent)
+}
+
+func (p *delayedProjector) TryProject(dataCollectedEvent *datapipeline.DataCollectedEvent) error {
+	time.Sleep(p.delay)
+
+	return datapipeline.TryProjectEvent(p.projector, dataCollectedEvent)
+}
+
+func (p *delayedProjector) Unwrap() datapipeline.Projector {
+	return p.projector
+}
+
+func (p *delayedProjector) DeadLetter(dataCollectedEvent *datapipeline.DataCollectedEvent, projectionErr error) {
+	datapipeline.DeadLetterEvent(p.projector, dataCollectedEvent, projectionErr)
+}
+
+func fault(rate float64) bool {
+	return rate > 0 && randFloat() < rate
+}
@@ -0,0 +1,55 @@
+package chaos
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/web/datapipeline"
+)
+
+func mockRandFloat(t *testing.T, value float64) {
+	randFloat = func() float64 { return value }
+	t.Cleanup(func() {
+		randFloat = rand.Float64
+	})
+}
+
+func TestNewInjectorDisabled(t *testing.T) {
+	injector := NewInjector(nil)
+
+	assert.False(t, injector.Enabled())
+	assert.False(t, injector.ShouldTimeoutChecksResults())
+}
+
+func TestShouldTimeoutChecksResults(t *testing.T) {
+	injector := NewInjector(&Config{ChecksResultsTimeoutRate: 0.5, ChecksResultsTimeout: time.Second})
+	assert.True(t, injector.Enabled())
+	assert.Equal(t, time.Second, injector.ChecksResultsTimeout())
+
+	mockRandFloat(t, 0.4)
+	assert.True(t, injector.ShouldTimeoutChecksResults())
+
+	mockRandFloat(t, 0.6)
+	assert.False(t, injector.ShouldTimeoutChecksResults())
+}
+
+func TestWrapProjectors(t *testing.T) {
+	projector := new(datapipeline.MockProjector)
+	projector.On("Project", mock.Anything).Return(nil)
+	registry := datapipeline.ProjectorRegistry{projector}
+
+	assert.Equal(t, registry, NewInjector(&Config{}).WrapProjectors(registry))
+
+	wrapped := NewInjector(&Config{ProjectionDelay: 10 * time.Millisecond}).WrapProjectors(registry)
+	assert.Len(t, wrapped, 1)
+
+	start := time.Now()
+	err := wrapped[0].Project(&datapipeline.DataCollectedEvent{})
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	projector.AssertExpectations(t)
+}
@@ -1,7 +1,11 @@
 package web
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mitchellh/mapstructure"
@@ -14,6 +18,10 @@ type JSONChecksSettings struct {
 	SelectedChecks     []string          `json:"selected_checks" binding:"required"`
 	ConnectionSettings map[string]string `json:"connection_settings" binding:"required"`
 	Hostnames          []string          `json:"hostnames"`
+	// Version identifies the last change of the settings, the one the saved settings are based on
+	Version *int64 `json:"version,omitempty"`
+	// Conflicts are the changes made since the version the saved settings are based on, which they overwrite
+	Conflicts []*models.AuditEntry `json:"conflicts,omitempty"`
 }
 
 type JSONChecksCatalog []*JSONCheck
@@ -27,6 +35,67 @@ type JSONCheck struct {
 	Implementation string `json:"implementation,omitempty"`
 	Labels         string `json:"labels,omitempty"`
 	Premium        bool   `json:"premium,omitempty"`
+	// Facts are the values the check discovers on the hosts, reported alongside its result
+	Facts []*JSONCheckFact `json:"facts,omitempty"`
+}
+
+type JSONCheckFact struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// UnmarshalJSON rejects the null checks, the binding validation panicking on them
+func (r *JSONChecksCatalog) UnmarshalJSON(data []byte) error {
+	var checks []*JSONCheck
+	if err := json.Unmarshal(data, &checks); err != nil {
+		return err
+	}
+
+	for _, check := range checks {
+		if check == nil {
+			return errors.New("the checks cannot be null")
+		}
+	}
+
+	*r = checks
+
+	return nil
+}
+
+func (r JSONChecksCatalog) validate() error {
+	for _, check := range r {
+		for _, field := range []struct {
+			name      string
+			value     string
+			maxLength int
+		}{
+			{"id", check.ID, maxIdentifierLength},
+			{"name", check.Name, 0},
+			{"group", check.Group, 0},
+			{"description", check.Description, 0},
+			{"remediation", check.Remediation, 0},
+			{"implementation", check.Implementation, 0},
+			{"labels", check.Labels, 0},
+		} {
+			if err := validateText(field.name, field.value, field.maxLength); err != nil {
+				return err
+			}
+		}
+
+		for _, fact := range check.Facts {
+			if fact == nil || fact.Name == "" {
+				return BadRequestError("the facts must have a name")
+			}
+			if err := validateText("fact name", fact.Name, maxIdentifierLength); err != nil {
+				return err
+			}
+			if err := validateText("fact description", fact.Description, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
 
 type JSONChecksGroup struct {
@@ -45,6 +114,8 @@ type JSONHosts struct {
 	Result    string `json:"result,omitempty"`
 	Reachable bool   `json:"reachable,omitempty"`
 	Msg       string `json:"msg,omitempty"`
+	// FactValues are the values of the facts the check discovered on the host
+	FactValues map[string]string `json:"fact_values,omitempty"`
 }
 
 type JSONCheckResult struct {
@@ -86,7 +157,7 @@ func ApiChecksCatalogHandler(s services.ChecksService) gin.HandlerFunc {
 // @Success 200 {object} JSONChecksCatalog
 // @Failure 500 {object} map[string]string
 // @Router /checks/catalog [put]
-func ApiCreateChecksCatalogHandler(s services.ChecksService) gin.HandlerFunc {
+func ApiCreateChecksCatalogHandler(s services.ChecksService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		var r JSONChecksCatalog
@@ -97,6 +168,11 @@ func ApiCreateChecksCatalogHandler(s services.ChecksService) gin.HandlerFunc {
 			return
 		}
 
+		if err := r.validate(); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		var catalog models.ChecksCatalog
 
 		for _, checkData := range r {
@@ -110,15 +186,27 @@ func ApiCreateChecksCatalogHandler(s services.ChecksService) gin.HandlerFunc {
 				Labels:         checkData.Labels,
 				Premium:        checkData.Premium,
 			}
+			for _, fact := range checkData.Facts {
+				newCheck.Facts = append(newCheck.Facts, &models.CheckFact{Name: fact.Name, Description: fact.Description})
+			}
 			catalog = append(catalog, newCheck)
 		}
 
+		previousCatalog, err := s.GetChecksCatalog()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		err = s.CreateChecksCatalog(catalog)
 		if err != nil {
 			c.Error(err)
 			return
 		}
 
+		recordAudit(c, auditService, models.AuditActionChecksCatalogSaved, models.AuditResourceChecksCatalog, "",
+			checkIDs(previousCatalog), checkIDs(catalog))
+
 		c.JSON(http.StatusOK, &r)
 	}
 }
@@ -144,6 +232,27 @@ func ApiClusterCheckResultsHandler(s services.ChecksService) gin.HandlerFunc {
 	}
 }
 
+// ApiClusterFactsHandler godoc
+// @Summary Get the facts discovered on a specific cluster's hosts by the last checks execution
+// @Produce json
+// @Param cluster_id path string true "Cluster Id"
+// @Success 200 {array} models.DiscoveredFact
+// @Failure 500 {object} map[string]string
+// @Router /clusters/{cluster_id}/facts [get]
+func ApiClusterFactsHandler(s services.ChecksService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterID := c.Param("cluster_id")
+
+		facts, err := s.GetClusterFacts(clusterID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, facts)
+	}
+}
+
 // ApiCreateChecksResultHandler godoc
 // @Summary Create a checks result entry
 // @Produce json
@@ -187,7 +296,7 @@ func ApiCreateChecksResultHandler(s services.ChecksService) gin.HandlerFunc {
 // @Success 200 {object} JSONChecksSettings
 // @Failure 404 {object} map[string]string
 // @Router /checks/{id}/settings [get]
-func ApiCheckGetSettingsByIdHandler(s services.ClustersService) gin.HandlerFunc {
+func ApiCheckGetSettingsByIdHandler(s services.ClustersService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		resourceId := c.Param("id")
 
@@ -212,12 +321,21 @@ func ApiCheckGetSettingsByIdHandler(s services.ClustersService) gin.HandlerFunc
 			resp.Hostnames = append(resp.Hostnames, host.Name)
 		}
 
+		version, err := lastChangeID(auditService, models.AuditActionChecksSettingsSaved, models.TagClusterResourceType, resourceId)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		resp.Version = &version
+
 		c.JSON(http.StatusOK, resp)
 	}
 }
 
 // ApiCheckCreateSettingsByIdHandler godoc
 // @Summary Create the check settings
+// @Description The last save wins. When the version the settings are based on is given,
+// @Description the changes made since then by others, and overwritten, are returned as conflicts
 // @Accept json
 // @Produce json
 // @Param id path string true "Resource id"
@@ -225,7 +343,7 @@ func ApiCheckGetSettingsByIdHandler(s services.ClustersService) gin.HandlerFunc
 // @Success 201 {object} JSONChecksSettings
 // @Failure 500 {object} map[string]string
 // @Router /checks/{id}/settings [post]
-func ApiCheckCreateSettingsByIdHandler(s services.ChecksService) gin.HandlerFunc {
+func ApiCheckCreateSettingsByIdHandler(s services.ChecksService, auditService services.AuditService, agentChannelHub *AgentChannelHub) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		resourceId := c.Param("id")
 
@@ -237,6 +355,21 @@ func ApiCheckCreateSettingsByIdHandler(s services.ChecksService) gin.HandlerFunc
 			return
 		}
 
+		previousSettings, err := storedChecksSettings(s, resourceId)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		var conflicts []*models.AuditEntry
+		if r.Version != nil {
+			conflicts, err = changesSince(auditService, models.AuditActionChecksSettingsSaved, models.TagClusterResourceType, resourceId, *r.Version)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+
 		err = s.CreateSelectedChecks(resourceId, r.SelectedChecks)
 		if err != nil {
 			_ = c.Error(err)
@@ -251,6 +384,131 @@ func ApiCheckCreateSettingsByIdHandler(s services.ChecksService) gin.HandlerFunc
 			}
 		}
 
+		recordAudit(c, auditService, models.AuditActionChecksSettingsSaved, models.TagClusterResourceType, resourceId,
+			previousSettings, &JSONChecksSettings{SelectedChecks: r.SelectedChecks, ConnectionSettings: r.ConnectionSettings})
+
+		// the selected checks are part of the configuration of the agents of the cluster
+		notifyConfigChanged(agentChannelHub)
+
+		version, err := lastChangeID(auditService, models.AuditActionChecksSettingsSaved, models.TagClusterResourceType, resourceId)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		r.Version = &version
+		r.Conflicts = conflicts
+
 		c.JSON(http.StatusCreated, &r)
 	}
 }
+
+// ApiClusterChecksRunsHandler godoc
+// @Summary Get the check runs of a cluster, the most recent first
+// @Produce json
+// @Param cluster_id path string true "Cluster Id"
+// @Success 200 {object} []models.ChecksRun
+// @Failure 500 {object} map[string]string
+// @Router /clusters/{cluster_id}/results/runs [get]
+func ApiClusterChecksRunsHandler(s services.ChecksService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runs, err := s.GetChecksRunsByCluster(c.Param("cluster_id"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if runs == nil {
+			runs = []*models.ChecksRun{}
+		}
+
+		c.JSON(http.StatusOK, runs)
+	}
+}
+
+// ApiClusterChecksResultDiffHandler godoc
+// @Summary Compare two check runs of a cluster
+// @Description If the runs are not given, the last run is compared with the one before it
+// @Produce json
+// @Param cluster_id path string true "Cluster Id"
+// @Param previous query int false "Previous run id"
+// @Param current query int false "Current run id"
+// @Success 200 {object} models.ChecksResultDiff
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /clusters/{cluster_id}/results/diff [get]
+func ApiClusterChecksResultDiffHandler(s services.ChecksService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		previousRunId, currentRunId, err := parseChecksRunsQuery(c)
+		if err != nil {
+			_ = c.Error(BadRequestError(err.Error()))
+			return
+		}
+
+		diff, err := s.GetChecksResultDiffByCluster(c.Param("cluster_id"), previousRunId, currentRunId)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if diff == nil {
+			_ = c.Error(NotFoundError("could not find the check runs"))
+			return
+		}
+
+		c.JSON(http.StatusOK, diff)
+	}
+}
+
+func parseChecksRunsQuery(c *gin.Context) (int64, int64, error) {
+	var previous, current int64
+	var err error
+
+	if p := c.Query("previous"); p != "" {
+		previous, err = strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid previous run id: %s", p)
+		}
+	}
+
+	if q := c.Query("current"); q != "" {
+		current, err = strconv.ParseInt(q, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid current run id: %s", q)
+		}
+	}
+
+	return previous, current, nil
+}
+
+// storedChecksSettings returns the check settings of a resource as stored before a change
+func storedChecksSettings(s services.ChecksService, resourceId string) (*JSONChecksSettings, error) {
+	selectedChecks, err := s.GetSelectedChecksById(resourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionSettings, err := s.GetConnectionSettingsById(resourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &JSONChecksSettings{
+		SelectedChecks:     selectedChecks.SelectedChecks,
+		ConnectionSettings: make(map[string]string),
+	}
+	for node, connection := range connectionSettings {
+		settings.ConnectionSettings[node] = connection.User
+	}
+
+	return settings, nil
+}
+
+func checkIDs(catalog models.ChecksCatalog) []string {
+	ids := []string{}
+	for _, check := range catalog {
+		ids = append(ids, check.ID)
+	}
+
+	return ids
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +18,9 @@ import (
 )
 
 func TestApiClusterCheckResultsHandler(t *testing.T) {
+	updatedAt := time.Date(2022, time.May, 10, 8, 30, 0, 0, time.UTC)
 	results := &models.ChecksResultAsList{
+		UpdatedAt: &updatedAt,
 		Hosts: map[string]*models.HostState{
 			"host1": &models.HostState{
 				Reachable: true,
@@ -80,6 +83,7 @@ func TestApiClusterCheckResultsHandler(t *testing.T) {
 	app.webEngine.ServeHTTP(resp, req)
 
 	expectedBody, _ := json.Marshal(gin.H{
+		"updated_at": "2022-05-10T08:30:00Z",
 		"hosts": gin.H{
 			"host1": gin.H{
 				"reachable": true,
@@ -170,7 +174,8 @@ func TestApiCreateChecksResultHandler(t *testing.T) {
 			"check1": &models.ChecksByHost{
 				Hosts: map[string]*models.Check{
 					"host1": &models.Check{
-						Result: "passing",
+						Result:     "passing",
+						FactValues: map[string]string{"corosync_token": "30000"},
 					},
 					"host2": &models.Check{
 						Result: "passing",
@@ -218,7 +223,8 @@ func TestApiCreateChecksResultHandler(t *testing.T) {
 			"check1": &JSONCheckResult{
 				Hosts: map[string]*JSONHosts{
 					"host1": &JSONHosts{
-						Result: "passing",
+						Result:     "passing",
+						FactValues: map[string]string{"corosync_token": "30000"},
 					},
 					"host2": &JSONHosts{
 						Result: "passing",
@@ -241,6 +247,7 @@ func TestApiCreateChecksResultHandler(t *testing.T) {
 	resp := httptest.NewRecorder()
 	body, _ := json.Marshal(&sendData)
 	req := httptest.NewRequest("POST", "/api/checks/47d1190ffb4f781974c8356d7f863b03/results", bytes.NewBuffer(body))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 	app.webEngine.ServeHTTP(resp, req)
 
@@ -284,6 +291,7 @@ func TestTestApiCreateChecksResultHandler500(t *testing.T) {
 	resp := httptest.NewRecorder()
 	body, _ := json.Marshal(&sendData)
 	req := httptest.NewRequest("POST", "/api/checks/other/results", bytes.NewBuffer(body))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 	app.webEngine.ServeHTTP(resp, req)
 
@@ -303,6 +311,7 @@ func TestApiCreateChecksCatalogHandler(t *testing.T) {
 			Implementation: "implementation1",
 			Labels:         "labels1",
 			Premium:        true,
+			Facts:          []*models.CheckFact{{Name: "corosync_token", Description: "Corosync token"}},
 		},
 		&models.Check{
 			ID:             "id2",
@@ -315,6 +324,7 @@ func TestApiCreateChecksCatalogHandler(t *testing.T) {
 		},
 	}
 	mockChecksService := new(services.MockChecksService)
+	mockChecksService.On("GetChecksCatalog").Return(models.ChecksCatalog{}, nil)
 	mockChecksService.On("CreateChecksCatalog", expectedCatalog).Return(nil)
 	mockChecksService.On("CreateChecksCatalog", models.ChecksCatalog(nil)).Return(fmt.Errorf("error"))
 
@@ -339,6 +349,7 @@ func TestApiCreateChecksCatalogHandler(t *testing.T) {
 			Implementation: "implementation1",
 			Labels:         "labels1",
 			Premium:        true,
+			Facts:          []*JSONCheckFact{{Name: "corosync_token", Description: "Corosync token"}},
 		},
 		&JSONCheck{
 			ID:             "id2",
@@ -354,6 +365,7 @@ func TestApiCreateChecksCatalogHandler(t *testing.T) {
 	resp := httptest.NewRecorder()
 	body, _ := json.Marshal(&sendData)
 	req := httptest.NewRequest("PUT", "/api/checks/catalog", bytes.NewBuffer(body))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 	app.webEngine.ServeHTTP(resp, req)
 
@@ -365,6 +377,7 @@ func TestApiCreateChecksCatalogHandler(t *testing.T) {
 	sendData = JSONChecksCatalog{}
 	body, _ = json.Marshal(&sendData)
 	req = httptest.NewRequest("PUT", "/api/checks/catalog", bytes.NewBuffer(body))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 	app.webEngine.ServeHTTP(resp, req)
 
@@ -373,6 +386,69 @@ func TestApiCreateChecksCatalogHandler(t *testing.T) {
 	mockChecksService.AssertExpectations(t)
 }
 
+func TestApiCreateChecksCatalogHandlerUnnamedFact(t *testing.T) {
+	mockChecksService := new(services.MockChecksService)
+
+	deps := setupTestDependencies()
+	deps.checksService = mockChecksService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sendData := JSONChecksCatalog{
+		&JSONCheck{
+			ID:    "id1",
+			Name:  "name1",
+			Group: "group1",
+			Facts: []*JSONCheckFact{{Description: "Corosync token"}},
+		},
+	}
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&sendData)
+	req := httptest.NewRequest("PUT", "/api/checks/catalog", bytes.NewBuffer(body))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+	mockChecksService.AssertNotCalled(t, "CreateChecksCatalog", mock.Anything)
+}
+
+func TestApiClusterFactsHandler(t *testing.T) {
+	mockChecksService := new(services.MockChecksService)
+	mockChecksService.On("GetClusterFacts", "cluster1").Return([]*models.DiscoveredFact{
+		{CheckID: "53D035", Name: "corosync_token", Description: "Corosync token", Host: "host1", Value: "30000"},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.checksService = mockChecksService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/clusters/cluster1/facts", nil)
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"check_id": "53D035",
+		"name": "corosync_token",
+		"description": "Corosync token",
+		"host": "host1",
+		"value": "30000"
+	}]`, resp.Body.String())
+	mockChecksService.AssertExpectations(t)
+}
+
 func TestApiCheckGetSettingsByIdHandler(t *testing.T) {
 	mockClustersService := new(services.MockClustersService)
 	mockClustersService.On("GetClusterSettingsByID", "cluster_id").Return(&models.ClusterSettings{
@@ -412,6 +488,7 @@ func TestApiCheckGetSettingsByIdHandler(t *testing.T) {
 		"host2": "user2",
 	}, settings.ConnectionSettings)
 	assert.Equal(t, []string{"host1", "host2"}, settings.Hostnames)
+	assert.Equal(t, int64(0), *settings.Version)
 }
 
 func TestApiCheckGetSettingsByIdHandler404(t *testing.T) {
@@ -437,6 +514,9 @@ func TestApiCheckGetSettingsByIdHandler404(t *testing.T) {
 func TestApiCheckCreateConnectionByIdHandler(t *testing.T) {
 	mockChecksService := new(services.MockChecksService)
 
+	mockChecksService.On("GetSelectedChecksById", mock.Anything).Return(models.SelectedChecks{}, nil)
+	mockChecksService.On("GetConnectionSettingsById", mock.Anything).Return(map[string]models.ConnectionSettings{}, nil)
+
 	mockChecksService.On(
 		"CreateSelectedChecks", "group1", []string{"ABCDEF", "123456"}).Return(nil)
 	mockChecksService.On(
@@ -468,6 +548,7 @@ func TestApiCheckCreateConnectionByIdHandler(t *testing.T) {
 	resp := httptest.NewRecorder()
 	body, _ := json.Marshal(&sendData)
 	req := httptest.NewRequest("POST", "/api/checks/group1/settings", bytes.NewBuffer(body))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 	app.webEngine.ServeHTTP(resp, req)
 
@@ -475,12 +556,16 @@ func TestApiCheckCreateConnectionByIdHandler(t *testing.T) {
 	json.Unmarshal(resp.Body.Bytes(), &connData)
 
 	assert.Equal(t, 201, resp.Code)
-	assert.Equal(t, sendData, connData)
+	assert.Equal(t, sendData.SelectedChecks, connData.SelectedChecks)
+	assert.Equal(t, sendData.ConnectionSettings, connData.ConnectionSettings)
+	assert.Equal(t, int64(0), *connData.Version)
+	assert.Empty(t, connData.Conflicts)
 
 	// 500 scenario
 	resp = httptest.NewRecorder()
 
 	req = httptest.NewRequest("POST", "/api/checks/otherId/settings", bytes.NewBuffer(body))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 	app.webEngine.ServeHTTP(resp, req)
 
@@ -488,3 +573,72 @@ func TestApiCheckCreateConnectionByIdHandler(t *testing.T) {
 
 	mockChecksService.AssertExpectations(t)
 }
+
+func TestApiClusterChecksResultDiffHandler(t *testing.T) {
+	mockChecksService := new(services.MockChecksService)
+	mockChecksService.On("GetChecksResultDiffByCluster", "cluster1", int64(1), int64(2)).Return(
+		&models.ChecksResultDiff{
+			Previous: &models.ChecksRun{ID: 1},
+			Current:  &models.ChecksRun{ID: 2},
+			NewlyFailing: []*models.CheckResultChange{
+				{CheckID: "check1", Host: "host1", Previous: "passing", Current: "critical"},
+			},
+			NewlyPassing: []*models.CheckResultChange{},
+			NewlyMuted:   []*models.CheckResultChange{},
+		}, nil)
+
+	deps := setupTestDependencies()
+	deps.checksService = mockChecksService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/clusters/cluster1/results/diff?previous=1&current=2", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"previous":{"id":1,"created_at":"0001-01-01T00:00:00Z"},
+		"current":{"id":2,"created_at":"0001-01-01T00:00:00Z"},
+		"newly_failing":[{"check_id":"check1","host":"host1","previous":"passing","current":"critical"}],
+		"newly_passing":[],
+		"newly_muted":[]
+	}`, resp.Body.String())
+}
+
+func TestApiClusterChecksResultDiffHandlerBadRequest(t *testing.T) {
+	deps := setupTestDependencies()
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/clusters/cluster1/results/diff?previous=first", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestApiClusterChecksResultDiffHandler404(t *testing.T) {
+	mockChecksService := new(services.MockChecksService)
+	mockChecksService.On("GetChecksResultDiffByCluster", "cluster1", int64(0), int64(0)).Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.checksService = mockChecksService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/clusters/cluster1/results/diff", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
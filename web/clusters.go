@@ -25,16 +25,25 @@ func NewClustersHealthContainer(clusterList models.ClusterList) *HealthContainer
 	return h
 }
 
-func NewClusterListHandler(clustersService services.ClustersService) gin.HandlerFunc {
+func NewClusterListHandler(clustersService services.ClustersService, favoritesService services.FavoritesService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		query := c.Request.URL.Query()
 
+		favorites, err := getFavorites(c, favoritesService, models.TagClusterResourceType)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		clustersFilter := &services.ClustersFilter{
-			Name:        query["name"],
-			SIDs:        query["sids"],
-			ClusterType: query["cluster_type"],
-			Health:      query["health"],
-			Tags:        query["tags"],
+			Name:             query["name"],
+			SIDs:             query["sids"],
+			ClusterType:      query["cluster_type"],
+			Health:           query["health"],
+			Tags:             query["tags"],
+			TemplateVersions: query["template_version"],
+			Pinned:           favorites,
+			Organization:     userOrganization(c),
 		}
 
 		pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -56,6 +65,7 @@ func NewClusterListHandler(clustersService services.ClustersService) gin.Handler
 			_ = c.Error(err)
 			return
 		}
+		markFavoriteClusters(paginatedClusterList, favorites)
 
 		clusterList, err := clustersService.GetAll(clustersFilter, nil)
 		if err != nil {
@@ -87,25 +97,32 @@ func NewClusterListHandler(clustersService services.ClustersService) gin.Handler
 			return
 		}
 
+		filterTemplateVersions, err := clustersService.GetAllTemplateVersions()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		healthContainer := NewClustersHealthContainer(clusterList)
 		healthContainer.Layout = "horizontal"
 
 		pagination := NewPagination(len(clusterList), pageNumber, pageSize)
 
 		c.HTML(http.StatusOK, "clusters.html.tmpl", gin.H{
-			"ClustersTable":      paginatedClusterList,
-			"AppliedFilters":     query,
-			"filterClusterNames": filterClusterNames,
-			"FilterClusterTypes": filterClusterTypes,
-			"FilterSIDs":         filterSIDs,
-			"FilterTags":         filterTags,
-			"Pagination":         pagination,
-			"HealthContainer":    healthContainer,
+			"ClustersTable":          paginatedClusterList,
+			"AppliedFilters":         query,
+			"filterClusterNames":     filterClusterNames,
+			"FilterClusterTypes":     filterClusterTypes,
+			"FilterSIDs":             filterSIDs,
+			"FilterTags":             filterTags,
+			"FilterTemplateVersions": filterTemplateVersions,
+			"Pagination":             pagination,
+			"HealthContainer":        healthContainer,
 		})
 	}
 }
 
-func NewClusterHandler(clusterService services.ClustersService) gin.HandlerFunc {
+func NewClusterHandler(clusterService services.ClustersService, checksService services.ChecksService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clusterID := c.Param("id")
 
@@ -131,10 +148,58 @@ func NewClusterHandler(clusterService services.ClustersService) gin.HandlerFunc
 			Layout:        "vertical",
 		}
 
+		facts, err := checksService.GetClusterFacts(clusterID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		c.HTML(http.StatusOK, "cluster_hana.html.tmpl", gin.H{
 			"Cluster":         cluster,
 			"HealthContainer": hContainer,
+			"Facts":           facts,
 			"Alerts":          GetAlerts(c),
 		})
 	}
 }
+
+func NewClusterChecksDiffHandler(clusterService services.ClustersService, checksService services.ChecksService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterID := c.Param("id")
+
+		cluster, err := clusterService.GetByID(clusterID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if cluster == nil {
+			_ = c.Error(NotFoundError("could not find cluster"))
+			return
+		}
+
+		previousRunId, currentRunId, err := parseChecksRunsQuery(c)
+		if err != nil {
+			_ = c.Error(BadRequestError(err.Error()))
+			return
+		}
+
+		runs, err := checksService.GetChecksRunsByCluster(clusterID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		diff, err := checksService.GetChecksResultDiffByCluster(clusterID, previousRunId, currentRunId)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.HTML(http.StatusOK, "cluster_checks_diff.html.tmpl", gin.H{
+			"Cluster": cluster,
+			"Runs":    runs,
+			"Diff":    diff,
+		})
+	}
+}
@@ -12,6 +12,7 @@ type ClustersSettingsResponse models.ClustersSettings
 
 // ApiGetClustersSettingsHandler godoc
 // @Summary Retrieve Settings for all the clusters. Cluster's Selected checks and Hosts connection settings
+// @Description The clusters of the organization of the user only, if any
 // @Accept json
 // @Produce json
 // @Success 200 {object} ClustersSettingsResponse
@@ -19,7 +20,7 @@ type ClustersSettingsResponse models.ClustersSettings
 // @Router /clusters/settings [get]
 func ApiGetClustersSettingsHandler(clusters services.ClustersService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		clustersSettings, err := clusters.GetAllClustersSettings()
+		clustersSettings, err := clusters.GetAllClustersSettings(userOrganization(c))
 
 		if err != nil {
 			c.Error(err)
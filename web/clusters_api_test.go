@@ -29,7 +29,7 @@ func (suite *ClustersApiTestCase) SetupTest() {
 }
 
 func (suite *ClustersApiTestCase) Test_EmptyClustersSettings() {
-	suite.mockClusterService.On("GetAllClustersSettings").Return(models.ClustersSettings{}, nil)
+	suite.mockClusterService.On("GetAllClustersSettings", "").Return(models.ClustersSettings{}, nil)
 	suite.deps.clustersService = suite.mockClusterService
 
 	app, err := NewAppWithDeps(suite.config, suite.deps)
@@ -47,7 +47,7 @@ func (suite *ClustersApiTestCase) Test_EmptyClustersSettings() {
 
 func (suite *ClustersApiTestCase) Test_ClustersSettingsWereFound() {
 	mockedClustersSettings := mockedClustersSettings()
-	suite.mockClusterService.On("GetAllClustersSettings").Return(mockedClustersSettings, nil)
+	suite.mockClusterService.On("GetAllClustersSettings", "").Return(mockedClustersSettings, nil)
 
 	suite.deps.clustersService = suite.mockClusterService
 
@@ -66,7 +66,7 @@ func (suite *ClustersApiTestCase) Test_ClustersSettingsWereFound() {
 }
 
 func (suite *ClustersApiTestCase) Test_AnErrorOccurs() {
-	suite.mockClusterService.On("GetAllClustersSettings").Return(nil, errors.New("KABOOM"))
+	suite.mockClusterService.On("GetAllClustersSettings", "").Return(nil, errors.New("KABOOM"))
 
 	suite.deps.clustersService = suite.mockClusterService
 
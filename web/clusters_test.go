@@ -332,6 +332,7 @@ func TestClustersListHandler(t *testing.T) {
 	)
 	mockClusterService.On("GetAllSIDs", mock.Anything).Return([]string{"PRD"}, nil)
 	mockClusterService.On("GetAllTags", mock.Anything).Return([]string{"tag1"}, nil)
+	mockClusterService.On("GetAllTemplateVersions").Return([]string{"1.2.0"}, nil)
 	deps := setupTestDependencies()
 	deps.clustersService = mockClusterService
 
@@ -360,6 +361,7 @@ func TestClustersListHandler(t *testing.T) {
 
 	assert.Equal(t, 200, resp.Code)
 	assert.Contains(t, minified, "Clusters")
+	assert.Regexp(t, regexp.MustCompile("<select name=template_version.*>.*1.2.0.*</select>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<td .*>.*error.*</td><td>.*other_cluster.*</td><td>.*a615a35f65627be5a757319a0741127f.*</td><td>Unknown</td><td></td>"), minified)
 	assert.Regexp(t, regexp.MustCompile("(?s)<td .*>.*fiber_manual_record.*</td><td>.*duplicated.*info.*netweaver_cluster.*</td><td>.*e27d313a674375b2066777a89ee346b9.*</td><td>Unknown</td><td></td>"), minified)
 }
@@ -377,6 +379,10 @@ func TestClusterHandlerHANA(t *testing.T) {
 		Health:        models.CheckCritical,
 		PassingCount:  2,
 		CriticalCount: 1,
+		Provisioning: &models.Provisioning{
+			Tool:            "salt",
+			TemplateVersion: "2.0.1",
+		},
 		Details: &models.HANAClusterDetails{
 			SystemReplicationMode:          "sync",
 			SystemReplicationOperationMode: "logreplay",
@@ -429,8 +435,14 @@ func TestClusterHandlerHANA(t *testing.T) {
 		},
 	}, nil)
 
+	checksService := new(services.MockChecksService)
+	checksService.On("GetClusterFacts", clusterID).Return([]*models.DiscoveredFact{
+		{CheckID: "53D035", Name: "corosync_token", Description: "Corosync token", Host: "test_node_1", Value: "30000"},
+	}, nil)
+
 	deps := setupTestDependencies()
 	deps.clustersService = clustersService
+	deps.checksService = checksService
 
 	config := setupTestConfig()
 	app, err := NewAppWithDeps(config, deps)
@@ -445,6 +457,7 @@ func TestClusterHandlerHANA(t *testing.T) {
 	app.webEngine.ServeHTTP(resp, req)
 
 	clustersService.AssertExpectations(t)
+	checksService.AssertExpectations(t)
 
 	m := minify.New()
 	m.AddFunc("text/html", html.Minify)
@@ -465,6 +478,8 @@ func TestClusterHandlerHANA(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile("<strong>Fencing type:</strong><br><span.*>external/sbd</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>HANA system replication operation mode:</strong><br><span.*>logreplay</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>CIB last written:</strong><br><span.*>Jun 30, 2021 18:11:37 UTC</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Provisioning tool:</strong><br><span.*>salt</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Template version:</strong><br><span.*>2.0.1</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>SAPHanaSR health state:</strong>.*text-danger.*"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>HANA secondary sync state:</strong><br><span.*>SFAIL</span>"), minified)
 	// Health
@@ -478,4 +493,64 @@ func TestClusterHandlerHANA(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile("<td>sbd</td><td>stonith:external/sbd</td><td>Started</td><td>active</td><td>0</td>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<td>dummy_failed</td><td>dummy</td><td>Started</td><td>failed</td><td>0</td>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<h4>Stopped resources</h4><div.*><div.*><span .*>dummy_failed</span>"), minified)
+	// Facts
+	assert.Regexp(t, regexp.MustCompile("<h3>Discovered facts</h3>.*<td>corosync_token<br><small.*>Corosync token</small></td><td>test_node_1</td><td><code>30000</code></td><td>53D035</td>"), minified)
+}
+
+func TestClusterChecksDiffHandler(t *testing.T) {
+	clusterID := "47d1190ffb4f781974c8356d7f863b03"
+
+	clustersService := new(services.MockClustersService)
+	clustersService.On("GetByID", clusterID).Return(&models.Cluster{
+		ID:          clusterID,
+		Name:        "hana_cluster",
+		ClusterType: models.ClusterTypeHANAScaleUp,
+	}, nil)
+
+	runs := []*models.ChecksRun{
+		{ID: 2, CreatedAt: time.Date(2021, time.July, 1, 10, 0, 0, 0, time.UTC)},
+		{ID: 1, CreatedAt: time.Date(2021, time.June, 30, 10, 0, 0, 0, time.UTC)},
+	}
+
+	checksService := new(services.MockChecksService)
+	checksService.On("GetChecksRunsByCluster", clusterID).Return(runs, nil)
+	checksService.On("GetChecksResultDiffByCluster", clusterID, int64(0), int64(0)).Return(&models.ChecksResultDiff{
+		Previous: runs[1],
+		Current:  runs[0],
+		NewlyFailing: []*models.CheckResultChange{
+			{CheckID: "156F64", Description: "Corosync token timeout", Host: "host1", Previous: "passing", Current: "critical"},
+		},
+		NewlyPassing: []*models.CheckResultChange{},
+		NewlyMuted: []*models.CheckResultChange{
+			{CheckID: "A1244C", Description: "Corosync consensus timeout", Host: "host2", Previous: "passing", Current: ""},
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.clustersService = clustersService
+	deps.checksService = checksService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/clusters/"+clusterID+"/checks/diff", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Regexp(t, regexp.MustCompile("<option value=1 selected>Jun 30, 2021 10:00:00 UTC</option>"), minified)
+	assert.Regexp(t, regexp.MustCompile("Newly failing</h4>.*<td>156F64</td><td>Corosync token timeout</td><td>host1</td><td>passing</td><td>critical</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("Newly passing</h4>.*There are currently no records to be shown"), minified)
+	assert.Regexp(t, regexp.MustCompile("Newly muted</h4>.*<td>A1244C</td><td>Corosync consensus timeout</td><td>host2</td><td>passing</td><td>not executed</td>"), minified)
 }
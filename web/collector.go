@@ -1,30 +1,278 @@
 package web
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
 	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
 
-// ApiCollectDataHandler handles the request to collect agent data from the API
-func ApiCollectDataHandler(collectorService services.CollectorService) gin.HandlerFunc {
+// ApiCollectDataHandler handles the request to collect agent data from the API, encoded as JSON, msgpack or protobuf
+// as told by its Content-Type
+func ApiCollectDataHandler(collectorService services.CollectorService, payloadCaptureService services.PayloadCaptureService, agentsService services.AgentsService, entitlementsService services.EntitlementsService, payloadSignaturesService services.PayloadSignaturesService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var e datapipeline.DataCollectedEvent
 
-		err := c.BindJSON(&e)
+		body, err := c.GetRawData()
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
 
-		err = collectorService.StoreEvent(&e)
+		bindErr := decodeDataCollectedEvent(c.ContentType(), body, &e)
+
+		// the payloads that cannot be decoded are captured as well, they are the most interesting ones
+		if err := payloadCaptureService.Capture(capturedAgentID(c, e.AgentID), body); err != nil {
+			log.Errorf("Could not capture the payload: %s", err)
+		}
+
+		if bindErr != nil {
+			_ = c.Error(BadRequestError(bindErr.Error()))
+			return
+		}
+
+		if err := validateDataCollectedEvent(&e); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if err := datapipeline.AdaptEvent(&e); err != nil {
+			_ = c.Error(BadRequestError(err.Error()))
+			return
+		}
+
+		if !checkAgentID(c, e.AgentID) {
+			return
+		}
+		e.Organization = agentOrganization(c)
+
+		observeCollectedEvent(&e)
+
+		if !verifyPayloadSignature(c, payloadSignaturesService, e.AgentID, body) {
+			return
+		}
+
+		status, ok := admitAgent(c, agentsService, entitlementsService, e.AgentID)
+		if !ok {
+			return
+		}
+
+		if status == models.AgentStatusPending {
+			err = collectorService.StorePendingEvent(&e)
+		} else {
+			err = collectorService.StoreEvent(&e)
+		}
+		if err != nil {
+			storeEventsError(c, err)
+			return
+		}
+
+		c.Writer.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// maxCollectBatchSize bounds the events of a batch, stored in one transaction
+const maxCollectBatchSize = 100
+
+// ApiCollectBatchDataHandler handles the request to collect several discoveries of an agent at once, as a JSON array.
+// The events are stored in one transaction, either all of them or none, and projected in order
+func ApiCollectBatchDataHandler(collectorService services.CollectorService, payloadCaptureService services.PayloadCaptureService, agentsService services.AgentsService, entitlementsService services.EntitlementsService, payloadSignaturesService services.PayloadSignaturesService) gin.HandlerFunc {
+	return collectBatchHandler(collectorService, payloadCaptureService, agentsService, entitlementsService, payloadSignaturesService, false)
+}
+
+// ApiCollectBackfillHandler handles the request of an agent to send, once back online, the full discoveries
+// collected while it was offline, each one telling when it was collected.
+// They are stored ordered by this time, and never projected over the discoveries collected after them
+func ApiCollectBackfillHandler(collectorService services.CollectorService, payloadCaptureService services.PayloadCaptureService, agentsService services.AgentsService, entitlementsService services.EntitlementsService, payloadSignaturesService services.PayloadSignaturesService) gin.HandlerFunc {
+	return collectBatchHandler(collectorService, payloadCaptureService, agentsService, entitlementsService, payloadSignaturesService, true)
+}
+
+func adaptBackfilledEvent(e *datapipeline.DataCollectedEvent) error {
+	if e.CollectedAt.IsZero() {
+		return BadRequestError("collected_at is required by the backfilled events")
+	}
+
+	if e.PayloadFormat != "" {
+		return BadRequestError("the backfilled events require the full payloads")
+	}
+
+	return datapipeline.AdaptEvent(e)
+}
+
+// collectBatchHandler stores the events of a batch as collected, or backfilled, by an agent
+func collectBatchHandler(collectorService services.CollectorService, payloadCaptureService services.PayloadCaptureService, agentsService services.AgentsService, entitlementsService services.EntitlementsService, payloadSignaturesService services.PayloadSignaturesService, backfill bool) gin.HandlerFunc {
+	adapt := datapipeline.AdaptEvent
+	if backfill {
+		adapt = adaptBackfilledEvent
+	}
+
+	return func(c *gin.Context) {
+		var events []*datapipeline.DataCollectedEvent
+
+		body, err := c.GetRawData()
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
 
+		bindErr := json.Unmarshal(body, &events)
+
+		agentID := ""
+		if len(events) > 0 && events[0] != nil {
+			agentID = events[0].AgentID
+		}
+		if err := payloadCaptureService.Capture(capturedAgentID(c, agentID), body); err != nil {
+			log.Errorf("Could not capture the payload: %s", err)
+		}
+
+		if bindErr != nil {
+			_ = c.Error(BadRequestError(fmt.Sprintf("unable to parse the JSON array of events: %s", bindErr)))
+			return
+		}
+
+		if len(events) == 0 || len(events) > maxCollectBatchSize {
+			_ = c.Error(BadRequestError(fmt.Sprintf("a batch must contain between 1 and %d events", maxCollectBatchSize)))
+			return
+		}
+
+		for i, e := range events {
+			if e == nil {
+				_ = c.Error(BadRequestError(fmt.Sprintf("event %d: must be an object", i)))
+				return
+			}
+
+			if err := validateDataCollectedEvent(e); err != nil {
+				_ = c.Error(BadRequestError(fmt.Sprintf("event %d: %s", i, err)))
+				return
+			}
+
+			if err := adapt(e); err != nil {
+				_ = c.Error(BadRequestError(fmt.Sprintf("event %d: %s", i, err)))
+				return
+			}
+
+			if e.AgentID != agentID {
+				_ = c.Error(BadRequestError(fmt.Sprintf("event %d: the events of a batch must be collected by the same agent", i)))
+				return
+			}
+		}
+
+		if !checkAgentID(c, agentID) {
+			return
+		}
+
+		for _, e := range events {
+			e.Organization = agentOrganization(c)
+			observeCollectedEvent(e)
+		}
+
+		if !verifyPayloadSignature(c, payloadSignaturesService, agentID, body) {
+			return
+		}
+
+		status, ok := admitAgent(c, agentsService, entitlementsService, agentID)
+		if !ok {
+			return
+		}
+
+		switch {
+		case backfill && status == models.AgentStatusPending:
+			err = collectorService.BackfillPendingEvents(events)
+		case backfill:
+			err = collectorService.BackfillEvents(events)
+		case status == models.AgentStatusPending:
+			err = collectorService.StorePendingEvents(events)
+		default:
+			err = collectorService.StoreEvents(events)
+		}
+		if err != nil {
+			storeEventsError(c, err)
+			return
+		}
+
 		c.Writer.WriteHeader(http.StatusAccepted)
 	}
 }
+
+func validateDataCollectedEvent(e *datapipeline.DataCollectedEvent) error {
+	if err := validateText("agent_id", e.AgentID, maxIdentifierLength); err != nil {
+		return err
+	}
+
+	if err := validateText("discovery_type", e.DiscoveryType, maxIdentifierLength); err != nil {
+		return err
+	}
+
+	if err := validatePayloadFormat(e); err != nil {
+		return err
+	}
+
+	if e.CollectedAt.After(time.Now().Add(maxCollectedAtSkew)) {
+		return BadRequestError("collected_at is in the future")
+	}
+
+	return validatePayload(e.Payload)
+}
+
+// maxCollectedAtSkew is the difference tolerated between the clocks of the agents and of the server,
+// a discovery collected in the future would never be overwritten by the next ones
+const maxCollectedAtSkew = 5 * time.Minute
+
+// validatePayloadFormat accepts the delta payloads of the current protocol version only,
+// the older versions being translated from the full payloads
+func validatePayloadFormat(e *datapipeline.DataCollectedEvent) error {
+	switch e.PayloadFormat {
+	case "":
+		return nil
+	case datapipeline.PayloadFormatDelta:
+		if e.BaseHash == "" {
+			return BadRequestError("base_hash is required by the delta payloads")
+		}
+		if e.ProtocolVersion != 0 && e.ProtocolVersion != datapipeline.ProtocolVersion {
+			return BadRequestError(fmt.Sprintf("the delta payloads require the protocol version %d", datapipeline.ProtocolVersion))
+		}
+		return nil
+	default:
+		return BadRequestError(fmt.Sprintf("unsupported payload_format %q", e.PayloadFormat))
+	}
+}
+
+// storeEventsError tells the agent the violations of the JSON schema of the discovery by its payload, if invalid,
+// and to send the full payload if a delta is not based on the last one stored
+func storeEventsError(c *gin.Context, err error) {
+	var validationErr *datapipeline.PayloadValidationError
+	if errors.As(err, &validationErr) {
+		_ = c.Error(UnprocessableEntityError(err.Error())).SetMeta(gin.H{"issues": validationErr.Issues})
+		return
+	}
+
+	if errors.Is(err, services.ErrDeltaBaseMismatch) {
+		_ = c.Error(ConflictError(err.Error()))
+		return
+	}
+
+	var quotaErr *services.IngestionQuotaExceededError
+	if errors.As(err, &quotaErr) {
+		ingestionQuotaError(c, quotaErr)
+		return
+	}
+
+	_ = c.Error(err)
+}
+
+// capturedAgentID prefers the agent authenticated in the request, if any, to the one in the payload
+func capturedAgentID(c *gin.Context, agentID string) string {
+	if authenticated, ok := c.Get(ContextAgentIDKey); ok {
+		return authenticated.(string)
+	}
+
+	return agentID
+}
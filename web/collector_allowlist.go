@@ -0,0 +1,75 @@
+package web
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+)
+
+// ParseCIDRAllowlist parses the subnets allowed to reach the collector, in CIDR notation.
+// A bare address allows that address only
+func ParseCIDRAllowlist(cidrs []string) ([]*net.IPNet, error) {
+	var allowlist []*net.IPNet
+
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if !strings.Contains(cidr, "/") {
+			ip, _ := internal.ParseIPAddress(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %s in the collector allowlist", cidr)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			allowlist = append(allowlist, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subnet %s in the collector allowlist", cidr)
+		}
+		allowlist = append(allowlist, subnet)
+	}
+
+	return allowlist, nil
+}
+
+// CollectorAllowlistMiddleware rejects with a 403 the requests coming from outside of the allowed subnets.
+// The address of the peer is checked rather than the forwarded ones, which the clients can forge.
+// An empty allowlist allows every address
+func CollectorAllowlistMiddleware(allowlist []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(allowlist) == 0 {
+			c.Next()
+			return
+		}
+
+		ip, _ := c.RemoteIP()
+		if ip != nil {
+			for _, subnet := range allowlist {
+				if subnet.Contains(ip) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		deniedCollectorRequests.Inc()
+		log.Warnf("Request %s %s from %s denied, the address is not in the collector allowlist",
+			c.Request.Method, c.Request.URL.Path, c.Request.RemoteAddr)
+		_ = c.Error(ForbiddenError("address not allowed"))
+		c.Abort()
+	}
+}
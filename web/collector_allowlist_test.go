@@ -0,0 +1,87 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestParseCIDRAllowlist(t *testing.T) {
+	allowlist, err := ParseCIDRAllowlist([]string{"10.0.0.0/16", " 192.0.2.7", "2001:db8::/32", "[fe80::1]", ""})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(allowlist))
+	assert.Equal(t, "10.0.0.0/16", allowlist[0].String())
+	assert.Equal(t, "192.0.2.7/32", allowlist[1].String())
+	assert.Equal(t, "2001:db8::/32", allowlist[2].String())
+	assert.Equal(t, "fe80::1/128", allowlist[3].String())
+
+	_, err = ParseCIDRAllowlist([]string{"10.0.0.0/33"})
+	assert.EqualError(t, err, "invalid subnet 10.0.0.0/33 in the collector allowlist")
+
+	_, err = ParseCIDRAllowlist([]string{"agents"})
+	assert.EqualError(t, err, "invalid address agents in the collector allowlist")
+}
+
+func TestCollectorAllowlist(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	config := setupTestConfig()
+	config.CollectorAllowlist = []string{"10.0.0.0/16", "2001:db8::/32"}
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte("{}"),
+	})
+
+	denied := testutil.ToFloat64(deniedCollectorRequests)
+
+	for remoteAddr, expected := range map[string]int{
+		"10.0.12.34:4321":      202,
+		"[2001:db8::12]:4321":  202,
+		"10.1.0.1:4321":        403,
+		"[2001:db9::12]:4321":  403,
+		"192.0.2.1:4321":       403,
+		"not-an-address:4321":  403,
+		"[::ffff:10.0.0.1]:80": 202,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+		req.Header.Set("Accept", "application/json")
+		// the forwarded addresses are not trusted
+		req.Header.Set("X-Forwarded-For", "10.0.0.1")
+		req.RemoteAddr = remoteAddr
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code, remoteAddr)
+	}
+
+	assert.Equal(t, denied+4, testutil.ToFloat64(deniedCollectorRequests))
+}
+
+func TestCollectorAllowlistInvalid(t *testing.T) {
+	config := setupTestConfig()
+	config.CollectorAllowlist = []string{"10.0.0.0/33"}
+
+	_, err := NewAppWithDeps(config, setupTestDependencies())
+
+	assert.Error(t, err)
+}
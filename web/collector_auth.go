@@ -0,0 +1,308 @@
+package web
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal/jwt"
+	"github.com/trento-project/trento/internal/signing"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+const (
+	// ContextAgentIDKey is the gin context key holding the ID of the agent authenticated with a JWT
+	ContextAgentIDKey string = "agent_id"
+	// ContextAgentCertificateKey is the gin context key holding the mTLS client certificate of the agent
+	ContextAgentCertificateKey string = "agent_certificate"
+	// ContextAgentOrganizationKey is the gin context key holding the organization of the authenticated agent,
+	// granted by its JWT or the subject of its client certificate
+	ContextAgentOrganizationKey string = "agent_organization"
+	// contextVerifyAgentCertificateKey tells whether the agent ID has to match the client certificate
+	contextVerifyAgentCertificateKey string = "verify_agent_certificate"
+	// contextReceivedAtKey holds the time a spooled payload was received, when replayed
+	contextReceivedAtKey string = "received_at"
+)
+
+type JSONEnrollment struct {
+	AgentID         string `json:"agent_id" binding:"required"`
+	EnrollmentToken string `json:"enrollment_token" binding:"required"`
+	// AgentSecret issued at the first enrollment of the agent, required to enroll it again
+	AgentSecret string `json:"agent_secret"`
+}
+
+type JSONEnrollmentToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Status of the agent, its data is not projected until it is approved
+	Status string `json:"status"`
+	// AgentSecret is only issued at the first enrollment, the agent has to keep it to enroll again
+	AgentSecret string `json:"agent_secret,omitempty"`
+}
+
+// ApiEnrollAgentHandler issues the JWT the agent authenticates to the collector with,
+// in exchange of the enrollment token shared by the agents and the server.
+// The JWTs issued for the enrollment token of an organization bind the agent to it.
+// The agent ID is bound to the secret issued at its first enrollment, so the enrollment token
+// does not grant the JWT of the agents enrolled already
+func ApiEnrollAgentHandler(config *Config, agentsService services.AgentsService, entitlementsService services.EntitlementsService, agentEnrollmentsService services.AgentEnrollmentsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONEnrollment
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		organization, ok := enrollmentOrganization(config, r.EnrollmentToken)
+		if !ok {
+			log.Warnf("Agent %s failed to enroll from %s, invalid enrollment token", r.AgentID, c.ClientIP())
+			_ = c.Error(UnauthorizedError("invalid enrollment token"))
+			return
+		}
+
+		status, ok := admitAgent(c, agentsService, entitlementsService, r.AgentID)
+		if !ok {
+			return
+		}
+
+		agentSecret, err := agentEnrollmentsService.Enroll(r.AgentID, r.AgentSecret)
+		if errors.Is(err, services.ErrAgentEnrolled) {
+			log.Warnf("Agent %s failed to enroll from %s, enrolled already with another secret", r.AgentID, c.ClientIP())
+			_ = c.Error(ForbiddenError(fmt.Sprintf("the agent %s is enrolled already, an admin has to reset its enrollment", r.AgentID)))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		now := time.Now()
+		claims := &jwt.Claims{
+			Subject:      r.AgentID,
+			IssuedAt:     now.Unix(),
+			ExpiresAt:    now.Add(config.JWTTTL).Unix(),
+			Organization: organization,
+		}
+
+		token, err := jwt.Sign(claims, []byte(config.JWTSecret))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		log.Infof("Agent %s enrolled, %s", r.AgentID, status)
+		c.JSON(http.StatusOK, JSONEnrollmentToken{
+			Token:       token,
+			ExpiresAt:   claims.ExpirationTime(),
+			Status:      status,
+			AgentSecret: agentSecret,
+		})
+	}
+}
+
+// enrollmentOrganization returns the organization of the enrollment token, none for the token shared by all the agents,
+// false if the token is unknown
+func enrollmentOrganization(config *Config, enrollmentToken string) (string, bool) {
+	if config.EnrollmentToken != "" && subtle.ConstantTimeCompare([]byte(enrollmentToken), []byte(config.EnrollmentToken)) == 1 {
+		return "", true
+	}
+
+	for organization, token := range config.OrganizationEnrollmentTokens {
+		if token != "" && subtle.ConstantTimeCompare([]byte(enrollmentToken), []byte(token)) == 1 {
+			return organization, true
+		}
+	}
+
+	return "", false
+}
+
+// CollectorJWTMiddleware rejects the collector requests without a valid JWT,
+// otherwise the ID and the organization of the agent are stored in the context
+func CollectorJWTMiddleware(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c)
+		if !ok {
+			_ = c.Error(UnauthorizedError("authentication required"))
+			c.Abort()
+			return
+		}
+
+		claims, err := jwt.Verify(token, []byte(secret), time.Now())
+		if err != nil {
+			_ = c.Error(UnauthorizedError(err.Error()))
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextAgentIDKey, claims.Subject)
+		c.Set(ContextAgentOrganizationKey, claims.Organization)
+		c.Next()
+	}
+}
+
+// CollectorCertificateMiddleware stores the mTLS client certificate of the agent in the context,
+// along with the organization of its subject, if any.
+// With verifyAgentID the agents can only act on behalf of the agent ID their certificate is issued to.
+// The requests also authenticated with a JWT are refused if the two organizations differ
+func CollectorCertificateMiddleware(verifyAgentID bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+			_ = c.Error(UnauthorizedError("client certificate required"))
+			c.Abort()
+			return
+		}
+
+		certificate := c.Request.TLS.PeerCertificates[0]
+		organization := certificateOrganization(certificate)
+		if granted, ok := c.Get(ContextAgentOrganizationKey); ok && granted.(string) != organization {
+			log.Warnf("Rejected a request from %s, the client certificate %s is issued to the organization %q, the JWT to %q",
+				c.ClientIP(), certificateFingerprint(certificate), organization, granted)
+			_ = c.Error(ForbiddenError("the organizations of the client certificate and of the JWT differ"))
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextAgentCertificateKey, certificate)
+		c.Set(ContextAgentOrganizationKey, organization)
+		c.Set(contextVerifyAgentCertificateKey, verifyAgentID)
+		c.Next()
+	}
+}
+
+// checkAgentID tells whether the agent authenticated in the request, if any, is the given one
+func checkAgentID(c *gin.Context, agentID string) bool {
+	if certificate, ok := agentCertificate(c); ok && c.GetBool(contextVerifyAgentCertificateKey) {
+		if !certificateIdentifies(certificate, agentID) {
+			rejectedAgentCertificates.WithLabelValues(certificateOfAnotherAgent).Inc()
+			log.Warnf("Rejected a request of agent %s from %s, the client certificate %s is issued to another agent",
+				agentID, c.ClientIP(), certificateFingerprint(certificate))
+			_ = c.Error(ForbiddenError(fmt.Sprintf("the certificate of %s cannot act on behalf of %s", certificate.Subject.CommonName, agentID)))
+			return false
+		}
+	}
+
+	authenticated, ok := c.Get(ContextAgentIDKey)
+	if !ok || strings.EqualFold(authenticated.(string), agentID) {
+		return true
+	}
+
+	_ = c.Error(ForbiddenError(fmt.Sprintf("the agent %s cannot act on behalf of %s", authenticated, agentID)))
+
+	return false
+}
+
+// admitAgent returns the approval status of the agent, the requests of the rejected agents,
+// or of the new hosts beyond the entitled ones, are refused
+func admitAgent(c *gin.Context, agentsService services.AgentsService, entitlementsService services.EntitlementsService, agentID string) (string, bool) {
+	allowed, err := entitlementsService.AllowsHost(agentID)
+	if err != nil {
+		_ = c.Error(err)
+		return "", false
+	}
+
+	if !allowed {
+		log.Warnf("Refused a request of agent %s from %s, the hosts limit of the license is reached", agentID, c.ClientIP())
+		_ = c.Error(ForbiddenError("the hosts limit of the license is reached"))
+		return "", false
+	}
+
+	status, err := agentsService.Admit(agentID)
+	if errors.Is(err, services.ErrUnregisteredAgent) {
+		log.Warnf("Refused a request of the unregistered agent %s from %s, pending approval", agentID, c.ClientIP())
+		_ = c.Error(ForbiddenError(fmt.Sprintf("the agent %s is not registered, an admin has to approve it", agentID)))
+		return "", false
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return "", false
+	}
+
+	if status == models.AgentStatusRejected {
+		log.Warnf("Refused a request of the rejected agent %s from %s", agentID, c.ClientIP())
+		_ = c.Error(ForbiddenError(fmt.Sprintf("the agent %s is rejected", agentID)))
+		return "", false
+	}
+
+	return status, true
+}
+
+// verifyPayloadSignature refuses the payloads whose signature does not match the secret of the agent,
+// protecting the deployments terminating TLS at a proxy
+func verifyPayloadSignature(c *gin.Context, payloadSignaturesService services.PayloadSignaturesService, agentID string, body []byte) bool {
+	receivedAt := time.Now()
+	if spooledAt, ok := c.Get(contextReceivedAtKey); ok {
+		receivedAt = spooledAt.(time.Time)
+	}
+
+	err := payloadSignaturesService.Verify(agentID, c.GetHeader(signing.TimestampHeader), c.GetHeader(signing.SignatureHeader), body, receivedAt)
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, signing.ErrInvalidSignature) || errors.Is(err, signing.ErrExpiredSignature) || errors.Is(err, services.ErrPayloadSignatureRequired) {
+		log.Warnf("Refused a payload of agent %s from %s: %s", agentID, c.ClientIP(), err)
+		_ = c.Error(UnauthorizedError(err.Error()))
+		return false
+	}
+
+	_ = c.Error(err)
+	return false
+}
+
+func agentCertificate(c *gin.Context) (*x509.Certificate, bool) {
+	value, ok := c.Get(ContextAgentCertificateKey)
+	if !ok {
+		return nil, false
+	}
+
+	certificate, ok := value.(*x509.Certificate)
+	return certificate, ok
+}
+
+// certificateIdentifies tells whether the certificate is issued to the agent, by its CN or a DNS SAN
+func certificateIdentifies(certificate *x509.Certificate, agentID string) bool {
+	if strings.EqualFold(certificate.Subject.CommonName, agentID) {
+		return true
+	}
+
+	for _, name := range certificate.DNSNames {
+		if strings.EqualFold(name, agentID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// certificateOrganization is the organization of the subject of the certificate, empty if none
+func certificateOrganization(certificate *x509.Certificate) string {
+	if len(certificate.Subject.Organization) == 0 {
+		return ""
+	}
+
+	return certificate.Subject.Organization[0]
+}
+
+// agentOrganization returns the organization of the agent authenticated in the request, empty if none.
+// The one stated by the payloads is never trusted
+func agentOrganization(c *gin.Context) string {
+	return c.GetString(ContextAgentOrganizationKey)
+}
+
+// certificateFingerprint is the SHA-256 hash of the DER encoded certificate
+func certificateFingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,367 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/internal/jwt"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func setupJWTTestApp(t *testing.T) *App {
+	return setupJWTTestAppWithDeps(t, setupTestDependencies())
+}
+
+func setupJWTTestAppWithDeps(t *testing.T, deps Dependencies) *App {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+	hostsService := new(services.MockHostsService)
+	hostsService.On("Heartbeat", mock.Anything).Return(nil)
+
+	deps.collectorService = collectorService
+	deps.hostsService = hostsService
+
+	config := setupTestConfig()
+	config.EnableJWT = true
+	config.JWTSecret = "secret"
+	config.JWTTTL = time.Hour
+	config.EnrollmentToken = "enrollment-token"
+	config.OrganizationEnrollmentTokens = map[string]string{"acme": "acme-enrollment-token"}
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func TestApiEnrollAgentHandler(t *testing.T) {
+	agentEnrollmentsService := new(services.MockAgentEnrollmentsService)
+	agentEnrollmentsService.On("Enroll", "agent_id", "").Return("agent-secret", nil).Once()
+	agentEnrollmentsService.On("Enroll", "agent_id", "agent-secret").Return("", nil)
+
+	deps := setupTestDependencies()
+	deps.enrollmentsService = agentEnrollmentsService
+	app := setupJWTTestAppWithDeps(t, deps)
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&JSONEnrollment{AgentID: "agent_id", EnrollmentToken: "enrollment-token"})
+	req := httptest.NewRequest("POST", "/api/enroll", bytes.NewBuffer(body))
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+
+	var enrollment JSONEnrollmentToken
+	json.Unmarshal(resp.Body.Bytes(), &enrollment)
+
+	claims, err := jwt.Verify(enrollment.Token, []byte("secret"), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "agent_id", claims.Subject)
+	assert.Empty(t, claims.Organization)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), enrollment.ExpiresAt, time.Minute)
+	assert.Equal(t, models.AgentStatusApproved, enrollment.Status)
+	assert.Equal(t, "agent-secret", enrollment.AgentSecret)
+
+	// the token of an organization binds the agent to it
+	resp = httptest.NewRecorder()
+	body, _ = json.Marshal(&JSONEnrollment{AgentID: "agent_id", EnrollmentToken: "acme-enrollment-token", AgentSecret: "agent-secret"})
+	req = httptest.NewRequest("POST", "/api/enroll", bytes.NewBuffer(body))
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+
+	enrollment = JSONEnrollmentToken{}
+	json.Unmarshal(resp.Body.Bytes(), &enrollment)
+	claims, err = jwt.Verify(enrollment.Token, []byte("secret"), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", claims.Organization)
+	// the secret is only issued once
+	assert.Empty(t, enrollment.AgentSecret)
+
+	resp = httptest.NewRecorder()
+	body, _ = json.Marshal(&JSONEnrollment{AgentID: "agent_id", EnrollmentToken: "wrong-token"})
+	req = httptest.NewRequest("POST", "/api/enroll", bytes.NewBuffer(body))
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 401, resp.Code)
+}
+
+func TestApiEnrollAgentHandlerEnrolledAgent(t *testing.T) {
+	agentEnrollmentsService := new(services.MockAgentEnrollmentsService)
+	agentEnrollmentsService.On("Enroll", "agent_id", "stolen-secret").Return("", services.ErrAgentEnrolled)
+
+	deps := setupTestDependencies()
+	deps.enrollmentsService = agentEnrollmentsService
+	app := setupJWTTestAppWithDeps(t, deps)
+
+	// the enrollment token alone does not grant the JWT of an agent enrolled already
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&JSONEnrollment{AgentID: "agent_id", EnrollmentToken: "enrollment-token", AgentSecret: "stolen-secret"})
+	req := httptest.NewRequest("POST", "/api/enroll", bytes.NewBuffer(body))
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "token")
+}
+
+func TestCollectorJWTMiddleware(t *testing.T) {
+	app := setupJWTTestApp(t)
+
+	now := time.Now()
+	validToken, _ := jwt.Sign(&jwt.Claims{Subject: "agent_id", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}, []byte("secret"))
+	expiredToken, _ := jwt.Sign(&jwt.Claims{Subject: "agent_id", IssuedAt: now.Add(-2 * time.Hour).Unix(), ExpiresAt: now.Add(-time.Hour).Unix()}, []byte("secret"))
+	otherAgentToken, _ := jwt.Sign(&jwt.Claims{Subject: "other_agent_id", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}, []byte("secret"))
+	forgedToken, _ := jwt.Sign(&jwt.Claims{Subject: "agent_id", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}, []byte("other-secret"))
+
+	collectBody, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte("{}"),
+	})
+
+	cases := []struct {
+		name         string
+		url          string
+		body         []byte
+		token        string
+		expectedCode int
+	}{
+		{"collect without token", "/api/collect", collectBody, "", 401},
+		{"collect with expired token", "/api/collect", collectBody, expiredToken, 401},
+		{"collect with forged token", "/api/collect", collectBody, forgedToken, 401},
+		{"collect on behalf of another agent", "/api/collect", collectBody, otherAgentToken, 403},
+		{"collect", "/api/collect", collectBody, validToken, 202},
+		{"heartbeat without token", "/api/hosts/agent_id/heartbeat", nil, "", 401},
+		{"heartbeat on behalf of another agent", "/api/hosts/agent_id/heartbeat", nil, otherAgentToken, 403},
+		{"heartbeat", "/api/hosts/agent_id/heartbeat", nil, validToken, 204},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", tc.url, bytes.NewBuffer(tc.body))
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			app.collectorEngine.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedCode, resp.Code)
+		})
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/ping", nil)
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+}
+
+func setupmTLSTestApp(t *testing.T, verifyAgentID bool, hostsService *services.MockHostsService) *App {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+	hostsService.On("Heartbeat", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+	deps.hostsService = hostsService
+
+	config := setupTestConfig()
+	config.EnablemTLS = true
+	config.MTLSVerifyAgentID = verifyAgentID
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func TestCollectorCertificateMiddleware(t *testing.T) {
+	agentCertificate := &x509.Certificate{Raw: []byte("agent-certificate"), Subject: pkix.Name{CommonName: "agent_id"}}
+	sanCertificate := &x509.Certificate{Raw: []byte("san-certificate"), DNSNames: []string{"other", "AGENT_ID"}}
+	otherCertificate := &x509.Certificate{Raw: []byte("other-certificate"), Subject: pkix.Name{CommonName: "other_agent_id"}}
+
+	fingerprint := sha256.Sum256(agentCertificate.Raw)
+	hostsService := new(services.MockHostsService)
+	hostsService.On("UpdateCertificateFingerprint", "agent_id", hex.EncodeToString(fingerprint[:])).Return(nil)
+
+	app := setupmTLSTestApp(t, true, hostsService)
+
+	collectBody, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte("{}"),
+	})
+
+	cases := []struct {
+		name         string
+		url          string
+		body         []byte
+		certificate  *x509.Certificate
+		expectedCode int
+	}{
+		{"collect without certificate", "/api/collect", collectBody, nil, 401},
+		{"collect on behalf of another agent", "/api/collect", collectBody, otherCertificate, 403},
+		{"collect", "/api/collect", collectBody, agentCertificate, 202},
+		{"collect with the agent in the SAN", "/api/collect", collectBody, sanCertificate, 202},
+		{"heartbeat on behalf of another agent", "/api/hosts/agent_id/heartbeat", nil, otherCertificate, 403},
+		{"heartbeat", "/api/hosts/agent_id/heartbeat", nil, agentCertificate, 204},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", tc.url, bytes.NewBuffer(tc.body))
+			if tc.certificate != nil {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{tc.certificate}}
+			}
+
+			app.collectorEngine.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedCode, resp.Code)
+		})
+	}
+
+	hostsService.AssertNumberOfCalls(t, "UpdateCertificateFingerprint", 1)
+}
+
+func TestCollectorCertificateMiddlewareSharedCertificate(t *testing.T) {
+	sharedCertificate := &x509.Certificate{Raw: []byte("shared-certificate"), Subject: pkix.Name{CommonName: "trento-agents"}}
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("UpdateCertificateFingerprint", "agent_id", mock.Anything).Return(nil)
+
+	app := setupmTLSTestApp(t, false, hostsService)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/hosts/agent_id/heartbeat", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{sharedCertificate}}
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	hostsService.AssertExpectations(t)
+}
+
+func TestCollectorCertificateMiddlewareJWTOrganization(t *testing.T) {
+	now := time.Now()
+	acmeToken, _ := jwt.Sign(&jwt.Claims{Subject: "agent_id", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix(), Organization: "acme"}, []byte("secret"))
+	acmeCertificate := &x509.Certificate{Raw: []byte("acme-certificate"), Subject: pkix.Name{CommonName: "agent_id", Organization: []string{"acme"}}}
+	globexCertificate := &x509.Certificate{Raw: []byte("globex-certificate"), Subject: pkix.Name{CommonName: "agent_id", Organization: []string{"globex"}}}
+	noOrganizationCertificate := &x509.Certificate{Raw: []byte("agent-certificate"), Subject: pkix.Name{CommonName: "agent_id"}}
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("Heartbeat", mock.Anything).Return(nil)
+	hostsService.On("UpdateCertificateFingerprint", "agent_id", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = hostsService
+
+	config := setupTestConfig()
+	config.EnableJWT = true
+	config.JWTSecret = "secret"
+	config.EnablemTLS = true
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name         string
+		certificate  *x509.Certificate
+		expectedCode int
+	}{
+		{"certificate of the organization of the JWT", acmeCertificate, 204},
+		{"certificate of another organization", globexCertificate, 403},
+		{"certificate of no organization", noOrganizationCertificate, 403},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/hosts/agent_id/heartbeat", nil)
+			req.Header.Set("Authorization", "Bearer "+acmeToken)
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{tc.certificate}}
+			app.collectorEngine.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedCode, resp.Code)
+		})
+	}
+}
+
+// the organization of the collected data is the one of the authenticated agent, whatever the payload states
+func TestCollectorAgentOrganization(t *testing.T) {
+	now := time.Now()
+	acmeToken, _ := jwt.Sign(&jwt.Claims{Subject: "agent_id", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix(), Organization: "acme"}, []byte("secret"))
+	noOrganizationToken, _ := jwt.Sign(&jwt.Claims{Subject: "agent_id", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}, []byte("secret"))
+	acmeCertificate := &x509.Certificate{Raw: []byte("acme-certificate"), Subject: pkix.Name{CommonName: "agent_id", Organization: []string{"acme"}}}
+	noOrganizationCertificate := &x509.Certificate{Raw: []byte("agent-certificate"), Subject: pkix.Name{CommonName: "agent_id"}}
+
+	body := []byte(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}, "organization": "globex"}`)
+
+	cases := []struct {
+		name                 string
+		enableJWT            bool
+		token                string
+		certificate          *x509.Certificate
+		expectedOrganization string
+	}{
+		{"JWT of an organization", true, acmeToken, nil, "acme"},
+		{"JWT of no organization", true, noOrganizationToken, nil, ""},
+		{"certificate of an organization", false, "", acmeCertificate, "acme"},
+		{"certificate of no organization", false, "", noOrganizationCertificate, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			collectorService := new(services.MockCollectorService)
+			collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+				return e.Organization == tc.expectedOrganization
+			})).Return(nil)
+			hostsService := new(services.MockHostsService)
+			hostsService.On("UpdateCertificateFingerprint", "agent_id", mock.Anything).Return(nil)
+
+			deps := setupTestDependencies()
+			deps.collectorService = collectorService
+			deps.hostsService = hostsService
+
+			config := setupTestConfig()
+			config.EnableJWT = tc.enableJWT
+			config.JWTSecret = "secret"
+			config.EnablemTLS = !tc.enableJWT
+
+			app, err := NewAppWithDeps(config, deps)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			if tc.certificate != nil {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{tc.certificate}}
+			}
+			app.collectorEngine.ServeHTTP(resp, req)
+
+			assert.Equal(t, 202, resp.Code)
+			collectorService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+// backpressureRetryAfter is suggested to the agents refused while the projection queue is saturated
+const backpressureRetryAfter = 30 * time.Second
+
+// backpressureRejections counts the collected data refused because the projection queue is saturated
+var backpressureRejections = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "backpressure_rejections_total",
+	Help:      "Collector requests refused because the queue of the events to project is saturated.",
+})
+
+// CollectorBackpressureMiddleware refuses the collected data while the events waiting to be projected
+// reach the threshold, rather than blocking the request until a projector worker is available.
+// The agents send the data again after the suggested delay. It is disabled if the threshold is 0
+func CollectorBackpressureMiddleware(collectorService services.CollectorService, threshold int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if threshold <= 0 || collectorService.QueueDepth() < threshold {
+			c.Next()
+			return
+		}
+
+		backpressureRejections.Inc()
+
+		c.Header("Retry-After", strconv.Itoa(int(backpressureRetryAfter.Seconds())))
+		_ = c.Error(TooManyRequestsError("the queue of the collected data to project is saturated"))
+		c.Abort()
+	}
+}
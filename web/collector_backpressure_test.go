@@ -0,0 +1,65 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestCollectorBackpressureMiddleware(t *testing.T) {
+	for depth, expected := range map[int]int{9: 202, 10: 429} {
+		collectorService := new(services.MockCollectorService)
+		collectorService.On("QueueDepth").Return(depth)
+		collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+		config := setupTestConfig()
+		config.CollectorQueueThreshold = 10
+
+		deps := setupTestDependencies()
+		deps.collectorService = collectorService
+
+		app, err := NewAppWithDeps(config, deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := httptest.NewRecorder()
+		body := bytes.NewBufferString(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}}`)
+		req := httptest.NewRequest("POST", "/api/collect", body)
+		req.Header.Set("Accept", "application/json")
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code)
+		if expected == 429 {
+			assert.Equal(t, "30", resp.Header().Get("Retry-After"))
+			collectorService.AssertNotCalled(t, "StoreEvent", mock.Anything)
+		}
+	}
+}
+
+func TestCollectorBackpressureMiddlewareDisabled(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}}`)
+	req := httptest.NewRequest("POST", "/api/collect", body)
+	req.Header.Set("Accept", "application/json")
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	collectorService.AssertNotCalled(t, "QueueDepth")
+}
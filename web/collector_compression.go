@@ -0,0 +1,114 @@
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultCollectorMaxBodySize bounds the size of the bodies of the collected data, once decompressed,
+	// the largest discoveries, cib and crm_mon of big clusters, are a few megabytes
+	DefaultCollectorMaxBodySize = 64 << 20
+
+	encodingGzip = "gzip"
+	encodingZstd = "zstd"
+)
+
+// collectorCompressionRatio observes the ratio between the decompressed and the compressed size of the collected data
+var collectorCompressionRatio = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "compression_ratio",
+	Help:      "Ratio between the decompressed and the compressed size of the collected data, by content encoding.",
+	Buckets:   []float64{1, 2, 4, 8, 16, 32, 64},
+}, []string{"encoding"})
+
+// oversizedCollectorBodies counts the bodies rejected because too large, the compressed ones once decompressed
+var oversizedCollectorBodies = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "oversized_bodies_total",
+	Help:      "Collector request bodies rejected because too large, once decompressed, by content encoding.",
+}, []string{"encoding"})
+
+// CollectorDecompressionMiddleware decompresses the request bodies encoded with gzip or zstd,
+// as told by their Content-Encoding, so that the handlers read them as if sent uncompressed
+func CollectorDecompressionMiddleware(maxDecompressedSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" {
+			c.Next()
+			return
+		}
+
+		compressed, err := c.GetRawData()
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		body, err := decompress(encoding, compressed, maxDecompressedSize)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if len(compressed) > 0 {
+			collectorCompressionRatio.WithLabelValues(encoding).Observe(float64(len(body)) / float64(len(compressed)))
+		}
+
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+		c.Next()
+	}
+}
+
+func decompress(encoding string, compressed []byte, maxDecompressedSize int64) ([]byte, error) {
+	var reader io.Reader
+
+	switch encoding {
+	case encodingGzip:
+		gzipReader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, BadRequestError(fmt.Sprintf("invalid gzip body: %s", err))
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case encodingZstd:
+		zstdReader, err := zstd.NewReader(bytes.NewReader(compressed), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, BadRequestError(fmt.Sprintf("invalid zstd body: %s", err))
+		}
+		defer zstdReader.Close()
+		reader = zstdReader
+	default:
+		return nil, UnsupportedMediaTypeError(fmt.Sprintf("unsupported content encoding %s, gzip and zstd are supported", encoding))
+	}
+
+	// reads one byte more than allowed to tell the bodies of the maximum size from the larger ones
+	body, err := ioutil.ReadAll(io.LimitReader(reader, maxDecompressedSize+1))
+	if err != nil {
+		return nil, BadRequestError(fmt.Sprintf("invalid %s body: %s", encoding, err))
+	}
+
+	if int64(len(body)) > maxDecompressedSize {
+		oversizedCollectorBodies.WithLabelValues(encoding).Inc()
+		return nil, PayloadTooLargeError(fmt.Sprintf("the body must be at most %d bytes once decompressed", maxDecompressedSize))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
+)
+
+func gzipBody(t *testing.T, data []byte) []byte {
+	var body bytes.Buffer
+	writer := gzip.NewWriter(&body)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return body.Bytes()
+}
+
+func zstdBody(t *testing.T, data []byte) []byte {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer encoder.Close()
+
+	return encoder.EncodeAll(data, nil)
+}
+
+func TestApiCollectDataHandlerCompressed(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return e.AgentID == "agent_id" && string(e.Payload) == `{"hostname": "host"}`
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := []byte(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {"hostname": "host"}}`)
+	for encoding, body := range map[string][]byte{
+		"gzip":     gzipBody(t, event),
+		"zstd":     zstdBody(t, event),
+		"identity": event,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", encoding)
+		req.Header.Set("Accept", "application/json")
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 202, resp.Code, encoding)
+	}
+
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 3)
+}
+
+func TestCollectorDecompressionMiddlewareInvalid(t *testing.T) {
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/api/collect", CollectorDecompressionMiddleware(64), func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		c.String(200, string(body))
+	})
+
+	post := func(encoding string, body []byte) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+		req.Header.Set("Content-Encoding", encoding)
+		req.Header.Set("Accept", "application/json")
+		engine.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	resp := post("gzip", gzipBody(t, []byte(strings.Repeat("a", 64))))
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, strings.Repeat("a", 64), resp.Body.String())
+
+	// highly compressible bodies are bounded once decompressed
+	assert.Equal(t, 413, post("gzip", gzipBody(t, []byte(strings.Repeat("a", 65)))).Code)
+	assert.Equal(t, 413, post("zstd", zstdBody(t, []byte(strings.Repeat("a", 65)))).Code)
+
+	assert.Equal(t, 400, post("gzip", []byte("not gzip")).Code)
+	assert.Equal(t, 400, post("zstd", []byte("not zstd")).Code)
+	assert.Equal(t, 415, post("br", []byte("{}")).Code)
+}
@@ -0,0 +1,47 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// collectorResponse records the response of the collector engine to the data received otherwise than over HTTP,
+// so that it is authenticated, rate limited and stored the same way
+type collectorResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func newCollectorResponse() *collectorResponse {
+	return &collectorResponse{header: make(http.Header), code: http.StatusOK}
+}
+
+func (r *collectorResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *collectorResponse) Write(body []byte) (int, error) {
+	return r.body.Write(body)
+}
+
+func (r *collectorResponse) WriteHeader(code int) {
+	r.code = code
+}
+
+// status returns the status code of the response, and the error reported by the collector if it failed
+func (r *collectorResponse) status() (int, string) {
+	if r.code < 300 {
+		return r.code, ""
+	}
+
+	var httpError struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(r.body.Bytes(), &httpError); err != nil || httpError.Error == "" {
+		return r.code, http.StatusText(r.code)
+	}
+
+	return r.code, httpError.Error
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trento-project/trento/internal/collectorpb"
+	"github.com/trento-project/trento/web/datapipeline"
+)
+
+// MIMEProtobuf is accepted along with the application/x-protobuf content type
+const MIMEProtobuf = "application/protobuf"
+
+// msgpackEvent is the msgpack encoding of the collected data, its payload stored as JSON
+type msgpackEvent struct {
+	AgentID         string      `codec:"agent_id"`
+	DiscoveryType   string      `codec:"discovery_type"`
+	Payload         interface{} `codec:"payload"`
+	ProtocolVersion int         `codec:"protocol_version"`
+	PayloadFormat   string      `codec:"payload_format"`
+	BaseHash        string      `codec:"base_hash"`
+}
+
+// decodeDataCollectedEvent decodes the collected data by its content type, JSON if not msgpack nor protobuf,
+// to cut the size and the parsing cost of the large payloads, like the ones of the clusters
+func decodeDataCollectedEvent(contentType string, body []byte, e *datapipeline.DataCollectedEvent) error {
+	var err error
+	switch contentType {
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		err = decodeMsgPackEvent(body, e)
+	case binding.MIMEPROTOBUF, MIMEProtobuf:
+		err = decodeProtobufEvent(body, e)
+	default:
+		return binding.JSON.BindBody(body, e)
+	}
+	if err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(e)
+}
+
+func decodeMsgPackEvent(body []byte, e *datapipeline.DataCollectedEvent) error {
+	handle := &codec.MsgpackHandle{}
+	handle.RawToString = true
+	handle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+
+	var event msgpackEvent
+	if err := codec.NewDecoder(bytes.NewReader(body), handle).Decode(&event); err != nil {
+		return fmt.Errorf("unable to decode the msgpack body: %w", err)
+	}
+
+	e.AgentID = event.AgentID
+	e.DiscoveryType = event.DiscoveryType
+	e.ProtocolVersion = event.ProtocolVersion
+	e.PayloadFormat = event.PayloadFormat
+	e.BaseHash = event.BaseHash
+	if event.Payload == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("unable to decode the msgpack payload: %w", err)
+	}
+	e.Payload = payload
+
+	return nil
+}
+
+func decodeProtobufEvent(body []byte, e *datapipeline.DataCollectedEvent) error {
+	var event collectorpb.DataCollectedEvent
+	if err := proto.Unmarshal(body, &event); err != nil {
+		return fmt.Errorf("unable to decode the protobuf body: %w", err)
+	}
+
+	e.AgentID = event.AgentId
+	e.DiscoveryType = event.DiscoveryType
+	if event.Payload == nil {
+		return errors.New("the payload is required")
+	}
+
+	payload, err := protojson.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("unable to decode the protobuf payload: %w", err)
+	}
+	e.Payload = payload
+
+	return nil
+}
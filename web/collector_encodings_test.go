@@ -0,0 +1,130 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/trento-project/trento/internal/collectorpb"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
+)
+
+func msgpackBody(t *testing.T, event map[string]interface{}) []byte {
+	var body []byte
+	if err := codec.NewEncoderBytes(&body, &codec.MsgpackHandle{}).Encode(event); err != nil {
+		t.Fatal(err)
+	}
+
+	return body
+}
+
+func protobufBody(t *testing.T, payload map[string]interface{}) []byte {
+	payloadStruct, err := structpb.NewStruct(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := proto.Marshal(&collectorpb.DataCollectedEvent{
+		AgentId:       "agent_id",
+		DiscoveryType: "discovery",
+		Payload:       payloadStruct,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return body
+}
+
+func TestDecodeDataCollectedEvent(t *testing.T) {
+	payload := map[string]interface{}{
+		"hostname": "host",
+		"ips":      []interface{}{"10.0.0.1"},
+		"cluster":  map[string]interface{}{"nodes": 2},
+	}
+
+	for _, contentType := range []string{"application/msgpack", "application/x-msgpack"} {
+		var e datapipeline.DataCollectedEvent
+		err := decodeDataCollectedEvent(contentType, msgpackBody(t, map[string]interface{}{
+			"agent_id":       "agent_id",
+			"discovery_type": "discovery",
+			"payload":        payload,
+		}), &e)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "agent_id", e.AgentID)
+		assert.Equal(t, "discovery", e.DiscoveryType)
+		assert.JSONEq(t, `{"hostname": "host", "ips": ["10.0.0.1"], "cluster": {"nodes": 2}}`, string(e.Payload))
+	}
+
+	for _, contentType := range []string{"application/x-protobuf", "application/protobuf"} {
+		var e datapipeline.DataCollectedEvent
+		err := decodeDataCollectedEvent(contentType, protobufBody(t, payload), &e)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "agent_id", e.AgentID)
+		assert.Equal(t, "discovery", e.DiscoveryType)
+		assert.JSONEq(t, `{"hostname": "host", "ips": ["10.0.0.1"], "cluster": {"nodes": 2}}`, string(e.Payload))
+	}
+
+	var e datapipeline.DataCollectedEvent
+	err := decodeDataCollectedEvent("", []byte(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}}`), &e)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(e.Payload))
+}
+
+func TestDecodeDataCollectedEventInvalid(t *testing.T) {
+	var e datapipeline.DataCollectedEvent
+
+	assert.Error(t, decodeDataCollectedEvent("application/msgpack", []byte(`{}`), &e))
+	assert.Error(t, decodeDataCollectedEvent("application/msgpack", msgpackBody(t, map[string]interface{}{
+		"agent_id": "agent_id",
+	}), &e))
+	assert.Error(t, decodeDataCollectedEvent("application/x-protobuf", []byte(`not protobuf`), &e))
+
+	body, _ := proto.Marshal(&collectorpb.DataCollectedEvent{AgentId: "agent_id", DiscoveryType: "discovery"})
+	assert.Error(t, decodeDataCollectedEvent("application/x-protobuf", body, &e))
+}
+
+func TestApiCollectDataHandlerEncodings(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return e.AgentID == "agent_id" && string(e.Payload) == `{"hostname":"host"}`
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := map[string]interface{}{"hostname": "host"}
+	for contentType, body := range map[string][]byte{
+		"application/msgpack": msgpackBody(t, map[string]interface{}{
+			"agent_id":       "agent_id",
+			"discovery_type": "discovery",
+			"payload":        payload,
+		}),
+		"application/x-protobuf": protobufBody(t, payload),
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", "application/json")
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 202, resp.Code, contentType)
+	}
+
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 2)
+}
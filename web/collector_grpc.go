@@ -0,0 +1,152 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/trento-project/trento/internal/collectorpb"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/internal/signing"
+)
+
+// collectorGRPCServer serves the gRPC collector passing every message to the HTTP collector as a request, so that
+// both authenticate, validate, rate limit and store the collected data the same way
+type collectorGRPCServer struct {
+	collectorpb.UnimplementedCollectorServer
+	collectorEngine http.Handler
+}
+
+// NewCollectorGRPCServer serves the gRPC collector with mTLS, in plaintext if the TLS configuration is nil
+func NewCollectorGRPCServer(collectorEngine http.Handler, tlsConfig *tls.Config) *grpc.Server {
+	var options []grpc.ServerOption
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(options...)
+	collectorpb.RegisterCollectorServer(server, &collectorGRPCServer{collectorEngine: collectorEngine})
+
+	return server
+}
+
+func (s *collectorGRPCServer) Collect(stream collectorpb.Collector_CollectServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		code, message := s.dispatch(stream.Context(), "/api/collect", req.Event, map[string]string{
+			signing.TimestampHeader: req.Timestamp,
+			signing.SignatureHeader: req.Signature,
+		})
+
+		err = stream.Send(&collectorpb.CollectResponse{Status: uint32(code), Error: message})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *collectorGRPCServer) Heartbeat(ctx context.Context, req *collectorpb.HeartbeatRequest) (*collectorpb.HeartbeatResponse, error) {
+	// the path of the HTTP collector cannot hold an empty ID
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "the agent ID is required")
+	}
+
+	resp, err := s.serve(ctx, "/api/hosts/"+url.PathEscape(req.AgentId)+"/heartbeat", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if code, message := resp.status(); code >= 300 {
+		return nil, status.Error(grpcCode(code), message)
+	}
+
+	// the request of the agent logs is passed in the header, as the HTTP collector does
+	if resp.header.Get(hosts.LogsRequestedHeader) != "" {
+		if err := grpc.SetHeader(ctx, metadata.Pairs(hosts.LogsRequestedHeader, "true")); err != nil {
+			return nil, err
+		}
+	}
+
+	return &collectorpb.HeartbeatResponse{}, nil
+}
+
+// dispatch passes the message to the HTTP collector, as sent by the peer, returning the status and the error if any
+func (s *collectorGRPCServer) dispatch(ctx context.Context, path string, body []byte, headers map[string]string) (int, string) {
+	resp, err := s.serve(ctx, path, body, headers)
+	if err != nil {
+		return http.StatusInternalServerError, err.Error()
+	}
+
+	return resp.status()
+}
+
+func (s *collectorGRPCServer) serve(ctx context.Context, path string, body []byte, headers map[string]string) (*collectorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		if value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if authorization := md.Get("authorization"); len(authorization) > 0 {
+			req.Header.Set("Authorization", authorization[0])
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			req.TLS = &tlsInfo.State
+		}
+	}
+
+	resp := newCollectorResponse()
+	s.collectorEngine.ServeHTTP(resp, req)
+
+	return resp, nil
+}
+
+func grpcCode(httpCode int) codes.Code {
+	switch httpCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/trento-project/trento/internal/collectorpb"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/services"
+)
+
+func dialCollectorGRPCServer(t *testing.T, app *App) collectorpb.CollectorClient {
+	listener := bufconn.Listen(1 << 20)
+	server := NewCollectorGRPCServer(app.collectorEngine, nil)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return collectorpb.NewCollectorClient(conn)
+}
+
+func TestCollectorGRPCServer_Collect(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := dialCollectorGRPCServer(t, app).Collect(context.Background())
+	assert.NoError(t, err)
+
+	for _, event := range []string{
+		`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}}`,
+		`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {"hostname": "host"}}`,
+	} {
+		assert.NoError(t, stream.Send(&collectorpb.CollectRequest{Event: []byte(event)}))
+
+		resp, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 202, resp.Status)
+		assert.Empty(t, resp.Error)
+	}
+
+	// the refused events are acknowledged with the error, the stream is kept
+	assert.NoError(t, stream.Send(&collectorpb.CollectRequest{Event: []byte(`not json`)}))
+
+	resp, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 400, resp.Status)
+	assert.NotEmpty(t, resp.Error)
+
+	assert.NoError(t, stream.CloseSend())
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 2)
+}
+
+func TestCollectorGRPCServer_Heartbeat(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("Heartbeat", "agent_id").Return(nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = hostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := dialCollectorGRPCServer(t, app)
+
+	_, err = client.Heartbeat(context.Background(), &collectorpb.HeartbeatRequest{AgentId: "agent_id"})
+	assert.NoError(t, err)
+	hostsService.AssertExpectations(t)
+
+	_, err = client.Heartbeat(context.Background(), &collectorpb.HeartbeatRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCollectorGRPCServer_HeartbeatLogsRequested(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("Heartbeat", "agent_id").Return(nil)
+
+	agentLogsService := new(services.MockAgentLogsService)
+	agentLogsService.On("IsRequested", "agent_id").Return(true, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = hostsService
+	deps.agentLogsService = agentLogsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var header metadata.MD
+	_, err = dialCollectorGRPCServer(t, app).Heartbeat(context.Background(), &collectorpb.HeartbeatRequest{AgentId: "agent_id"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"true"}, header.Get(hosts.LogsRequestedHeader))
+}
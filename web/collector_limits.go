@@ -0,0 +1,82 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+// ingestionQuotaRejections counts the collected data refused because its agent exceeded its daily quota
+var ingestionQuotaRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "ingestion_quota_rejections_total",
+	Help:      "Collector requests refused because the agent exceeded its daily ingestion quota, by quota.",
+}, []string{"quota"})
+
+func collectorMaxBodySize(config *Config) int64 {
+	if config.CollectorMaxBodySize > 0 {
+		return config.CollectorMaxBodySize
+	}
+
+	return DefaultCollectorMaxBodySize
+}
+
+// CollectorBodyLimitMiddleware refuses the bodies larger than the maximum size as sent, before they are read whole.
+// The compressed bodies are bounded once decompressed by CollectorDecompressionMiddleware
+func CollectorBodyLimitMiddleware(maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" {
+			encoding = "identity"
+		}
+
+		if c.Request.ContentLength > maxSize {
+			rejectOversizedBody(c, encoding, maxSize)
+			return
+		}
+
+		// reads one byte more than allowed to tell the bodies of the maximum size from the larger ones
+		body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxSize+1))
+		if err != nil {
+			_ = c.Error(BadRequestError(err.Error()))
+			c.Abort()
+			return
+		}
+
+		if int64(len(body)) > maxSize {
+			rejectOversizedBody(c, encoding, maxSize)
+			return
+		}
+
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		c.Next()
+	}
+}
+
+func rejectOversizedBody(c *gin.Context, encoding string, maxSize int64) {
+	oversizedCollectorBodies.WithLabelValues(encoding).Inc()
+
+	_ = c.Error(PayloadTooLargeError(fmt.Sprintf("the body must be at most %d bytes", maxSize)))
+	c.Abort()
+}
+
+// ingestionQuotaError refuses the data until the quota of the agent is reset
+func ingestionQuotaError(c *gin.Context, quotaErr *services.IngestionQuotaExceededError) {
+	ingestionQuotaRejections.WithLabelValues(quotaErr.Quota).Inc()
+
+	retryAfter := time.Until(quotaErr.ResetAt).Seconds()
+	c.Header("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(retryAfter)))))
+	_ = c.Error(TooManyRequestsError(quotaErr.Error()))
+}
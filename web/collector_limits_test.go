@@ -0,0 +1,80 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestCollectorBodyLimitMiddleware(t *testing.T) {
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/api/collect", CollectorBodyLimitMiddleware(64), func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		c.String(200, string(body))
+	})
+
+	post := func(body string, contentLength int64) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect", strings.NewReader(body))
+		req.ContentLength = contentLength
+		req.Header.Set("Accept", "application/json")
+		engine.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	resp := post(strings.Repeat("a", 64), 64)
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, strings.Repeat("a", 64), resp.Body.String())
+
+	assert.Equal(t, 413, post(strings.Repeat("a", 65), 65).Code)
+	// the bodies of unknown length are bounded as they are read
+	assert.Equal(t, 413, post(strings.Repeat("a", 65), -1).Code)
+}
+
+func TestApiCollectDataHandlerIngestionQuota(t *testing.T) {
+	resetAt := time.Now().Add(time.Hour)
+
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(&services.IngestionQuotaExceededError{
+		AgentID: "agent_id",
+		Quota:   services.IngestionQuotaEvents,
+		ResetAt: resetAt,
+	})
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte("{}"),
+	})
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+	req.Header.Set("Accept", "application/json")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 429, resp.Code)
+	retryAfter, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 3600, retryAfter, 5)
+}
@@ -0,0 +1,30 @@
+package web
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/trento-project/trento/web/datapipeline"
+)
+
+// collectedPayloads counts the payloads received by the collector, by discovery type and agent
+var collectedPayloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "received_payloads_total",
+	Help:      "Payloads received by the collector, by discovery type and agent.",
+}, []string{"discovery_type", "agent_id"})
+
+// collectedPayloadSize observes the size of the payloads received by the collector, the delta payloads as sent
+var collectedPayloadSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "received_payload_size_bytes",
+	Help:      "Size of the payloads received by the collector, by discovery type and agent.",
+	Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+}, []string{"discovery_type", "agent_id"})
+
+// observeCollectedEvent records an event received from its authenticated agent
+func observeCollectedEvent(e *datapipeline.DataCollectedEvent) {
+	collectedPayloads.WithLabelValues(e.DiscoveryType, e.AgentID).Inc()
+	collectedPayloadSize.WithLabelValues(e.DiscoveryType, e.AgentID).Observe(float64(len(e.Payload)))
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestCollectorMetrics(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+	collectorService.On("StoreEvents", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := collectedPayloads.WithLabelValues("host_discovery", "metrics_agent")
+	before := testutil.ToFloat64(received)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(
+		`{"agent_id": "metrics_agent", "discovery_type": "host_discovery", "payload": {"hostname": "host1"}}`,
+	))
+	app.collectorEngine.ServeHTTP(resp, req)
+	assert.Equal(t, 202, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/collect/batch", bytes.NewBufferString(`[
+		{"agent_id": "metrics_agent", "discovery_type": "host_discovery", "payload": {}},
+		{"agent_id": "metrics_agent", "discovery_type": "cloud_discovery", "payload": {}}
+	]`))
+	app.collectorEngine.ServeHTTP(resp, req)
+	assert.Equal(t, 202, resp.Code)
+
+	assert.Equal(t, before+2, testutil.ToFloat64(received))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collectedPayloads.WithLabelValues("cloud_discovery", "metrics_agent")))
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/metrics", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), `trento_collector_received_payloads_total{agent_id="metrics_agent",discovery_type="cloud_discovery"} 1`)
+	assert.Contains(t, resp.Body.String(), `trento_collector_received_payload_size_bytes_count{agent_id="metrics_agent",discovery_type="cloud_discovery"} 1`)
+}
@@ -0,0 +1,157 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/nats-io/nats.go"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal/signing"
+)
+
+const (
+	// DefaultCollectorNatsSubject the agents publish their collected data to
+	DefaultCollectorNatsSubject = "trento.collect"
+	// collectorNatsQueue the consoles subscribe with, every message being handled by one of them
+	collectorNatsQueue = "trento-web"
+)
+
+// collectorNatsHeaders are passed from the messages to the collector, as the agents would send them over HTTP.
+// The agents authenticate every message with their JWT, in the Authorization header
+var collectorNatsHeaders = []string{"Authorization", signing.TimestampHeader, signing.SignatureHeader, "Content-Encoding"}
+
+// CollectorNatsConfig authenticates the console to the NATS server, and secures the connection with TLS
+type CollectorNatsConfig struct {
+	// CredsFile holds the user JWT and the nkey seed of the console, see nats.UserCredentials
+	CredsFile string
+	// NkeySeedFile holds the nkey seed the console authenticates with, see nats.NkeyOptionFromSeed
+	NkeySeedFile string
+	// CA verifies the certificate of the server, the system CAs are used if empty
+	CA string
+	// Cert and Key the console authenticates with to the servers requiring TLS client certificates
+	Cert string
+	Key  string
+}
+
+// CollectorNatsResponse is the reply to the messages published as requests
+type CollectorNatsResponse struct {
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// collectorNatsOptions returns the options connecting to the NATS server with the credentials and TLS configured
+func collectorNatsOptions(config *CollectorNatsConfig) ([]nats.Option, error) {
+	var options []nats.Option
+	if config == nil {
+		return options, nil
+	}
+
+	if config.CredsFile != "" {
+		options = append(options, nats.UserCredentials(config.CredsFile))
+	}
+
+	if config.NkeySeedFile != "" {
+		option, err := nats.NkeyOptionFromSeed(config.NkeySeedFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, option)
+	}
+
+	if config.CA != "" {
+		options = append(options, nats.RootCAs(config.CA))
+	}
+
+	if config.Cert != "" || config.Key != "" {
+		options = append(options, nats.ClientCert(config.Cert, config.Key))
+	}
+
+	return options, nil
+}
+
+// RunCollectorNatsSubscriber stores the data published by the agents on the subject until the context is done.
+// The messages are passed to the collector engine, authenticated by the JWT of the agents as the HTTP requests are.
+// The connection to the NATS server is retried in the background, so that the console starts without it
+func RunCollectorNatsSubscriber(ctx context.Context, url string, subject string, config *CollectorNatsConfig, collectorEngine http.Handler) error {
+	options, err := collectorNatsOptions(config)
+	if err != nil {
+		return err
+	}
+
+	conn, err := nats.Connect(url, append(options,
+		nats.Name("trento-web"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Warnf("Disconnected from the NATS server: %s", err)
+			}
+		}),
+		nats.ReconnectHandler(func(_ *nats.Conn) {
+			log.Info("Reconnected to the NATS server")
+		}),
+	)...)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.QueueSubscribe(subject, collectorNatsQueue, func(msg *nats.Msg) {
+		handleCollectorNatsMessage(collectorEngine, conn.ConnectedAddr(), msg)
+	})
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	log.Infof("Collecting the data published on the NATS subject %s", subject)
+
+	<-ctx.Done()
+	log.Info("NATS collector subscriber is shutting down.")
+
+	// the messages received are handled before closing the connection
+	return conn.Drain()
+}
+
+// handleCollectorNatsMessage passes the message to the collector engine, replying with the outcome if requested.
+// The messages come from the address of the NATS server, which the collector allowlist has to allow
+func handleCollectorNatsMessage(collectorEngine http.Handler, serverAddr string, msg *nats.Msg) CollectorNatsResponse {
+	response := dispatchCollectorNatsMessage(collectorEngine, serverAddr, msg)
+	if response.Status >= 300 {
+		log.Warnf("Refused the data published on %s, status %d: %s", msg.Subject, response.Status, response.Error)
+	}
+
+	if msg.Reply != "" {
+		body, _ := json.Marshal(response)
+		if err := msg.Respond(body); err != nil {
+			log.Errorf("Failed to reply to the data published on %s: %s", msg.Subject, err)
+		}
+	}
+
+	return response
+}
+
+func dispatchCollectorNatsMessage(collectorEngine http.Handler, serverAddr string, msg *nats.Msg) CollectorNatsResponse {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/collect", bytes.NewReader(msg.Data))
+	if err != nil {
+		return CollectorNatsResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	req.RemoteAddr = serverAddr
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for _, name := range collectorNatsHeaders {
+		if value := msg.Header.Get(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp := newCollectorResponse()
+	collectorEngine.ServeHTTP(resp, req)
+
+	code, message := resp.status()
+
+	return CollectorNatsResponse{Status: code, Error: message}
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/internal/jwt"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestHandleCollectorNatsMessage(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return e.AgentID == "agent_id" && string(e.Payload) == `{"hostname": "host"}` && e.Organization == "acme"
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	config := setupTestConfig()
+	config.CollectorNatsURL = "nats://localhost:4222"
+	config.EnableJWT = true
+	config.JWTSecret = "secret"
+	config.CollectorAllowlist = []string{"10.0.0.0/8"}
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	token, _ := jwt.Sign(&jwt.Claims{Subject: "agent_id", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix(), Organization: "acme"}, []byte("secret"))
+	otherAgentToken, _ := jwt.Sign(&jwt.Claims{Subject: "other_agent_id", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}, []byte("secret"))
+	event := []byte(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {"hostname": "host"}}`)
+	serverAddr := "10.0.0.1:4222"
+
+	msg := nats.NewMsg(DefaultCollectorNatsSubject)
+	msg.Header.Set("Authorization", "Bearer "+token)
+	msg.Data = event
+	assert.Equal(t, CollectorNatsResponse{Status: 202}, handleCollectorNatsMessage(app.collectorEngine, serverAddr, msg))
+
+	compressed := nats.NewMsg(DefaultCollectorNatsSubject)
+	compressed.Header.Set("Authorization", "Bearer "+token)
+	compressed.Header.Set("Content-Encoding", "gzip")
+	compressed.Data = gzipBody(t, event)
+	assert.Equal(t, CollectorNatsResponse{Status: 202}, handleCollectorNatsMessage(app.collectorEngine, serverAddr, compressed))
+
+	invalid := nats.NewMsg(DefaultCollectorNatsSubject)
+	invalid.Header.Set("Authorization", "Bearer "+token)
+	invalid.Data = []byte(`{"agent_id": 1`)
+	response := handleCollectorNatsMessage(app.collectorEngine, serverAddr, invalid)
+	assert.Equal(t, 400, response.Status)
+	assert.NotEmpty(t, response.Error)
+
+	// the messages are authenticated as the HTTP requests are
+	anonymous := nats.NewMsg(DefaultCollectorNatsSubject)
+	anonymous.Data = event
+	assert.Equal(t, 401, handleCollectorNatsMessage(app.collectorEngine, serverAddr, anonymous).Status)
+
+	otherAgent := nats.NewMsg(DefaultCollectorNatsSubject)
+	otherAgent.Header.Set("Authorization", "Bearer "+otherAgentToken)
+	otherAgent.Data = event
+	assert.Equal(t, 403, handleCollectorNatsMessage(app.collectorEngine, serverAddr, otherAgent).Status)
+
+	// the NATS server has to be in the allowlist
+	assert.Equal(t, 403, handleCollectorNatsMessage(app.collectorEngine, "192.0.2.1:4222", msg).Status)
+
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 2)
+}
+
+func TestCollectorNatsOptions(t *testing.T) {
+	options, err := collectorNatsOptions(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, options)
+
+	options, err = collectorNatsOptions(&CollectorNatsConfig{CredsFile: "nats.creds", CA: "ca.pem", Cert: "cert.pem", Key: "key.pem"})
+	assert.NoError(t, err)
+	assert.Len(t, options, 3)
+
+	_, err = collectorNatsOptions(&CollectorNatsConfig{NkeySeedFile: "/nonexistent/nats.nk"})
+	assert.Error(t, err)
+}
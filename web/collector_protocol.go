@@ -0,0 +1,56 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/datapipeline"
+)
+
+// The capabilities of the collector the agents may rely on, in addition to the JSON events sent to /api/collect
+const (
+	CollectorCapabilityMsgPack  = "msgpack"
+	CollectorCapabilityProtobuf = "protobuf"
+	CollectorCapabilityGzip     = "gzip"
+	CollectorCapabilityZstd     = "zstd"
+	CollectorCapabilityBatch    = "batch"
+	CollectorCapabilityGRPC     = "grpc"
+	// CollectorCapabilityDelta lets the agents send the payloads as deltas of the last one stored, see the delta package
+	CollectorCapabilityDelta = "delta"
+)
+
+// JSONCollectorProtocol tells the agents which versions of the collected data the collector accepts,
+// the older ones translated to the current one, and the capabilities it has
+type JSONCollectorProtocol struct {
+	Version      int      `json:"version"`
+	MinVersion   int      `json:"min_version"`
+	Capabilities []string `json:"capabilities"`
+	// GRPCPort of the gRPC collector, 0 if disabled
+	GRPCPort int `json:"grpc_port"`
+}
+
+// ApiCollectorProtocolHandler lets the agents negotiate the protocol with the collector before sending their data,
+// so that the agents and the server can be upgraded independently
+func ApiCollectorProtocolHandler(config *Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		capabilities := []string{
+			CollectorCapabilityMsgPack,
+			CollectorCapabilityProtobuf,
+			CollectorCapabilityGzip,
+			CollectorCapabilityZstd,
+			CollectorCapabilityBatch,
+			CollectorCapabilityDelta,
+		}
+		if config.CollectorGRPCPort > 0 {
+			capabilities = append(capabilities, CollectorCapabilityGRPC)
+		}
+
+		c.JSON(http.StatusOK, &JSONCollectorProtocol{
+			Version:      datapipeline.ProtocolVersion,
+			MinVersion:   datapipeline.MinProtocolVersion,
+			Capabilities: capabilities,
+			GRPCPort:     config.CollectorGRPCPort,
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiCollectorProtocolHandler(t *testing.T) {
+	config := setupTestConfig()
+	config.CollectorGRPCPort = 8082
+
+	app, err := NewAppWithDeps(config, setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/protocol", nil)
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+
+	var protocol JSONCollectorProtocol
+	json.Unmarshal(resp.Body.Bytes(), &protocol)
+
+	assert.Equal(t, JSONCollectorProtocol{
+		Version:    datapipeline.ProtocolVersion,
+		MinVersion: datapipeline.MinProtocolVersion,
+		Capabilities: []string{
+			CollectorCapabilityMsgPack,
+			CollectorCapabilityProtobuf,
+			CollectorCapabilityGzip,
+			CollectorCapabilityZstd,
+			CollectorCapabilityBatch,
+			CollectorCapabilityDelta,
+			CollectorCapabilityGRPC,
+		},
+		GRPCPort: 8082,
+	}, protocol)
+}
+
+func TestApiCollectDataHandlerProtocolVersion(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return e.ProtocolVersion == datapipeline.ProtocolVersion
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(`{"agent_id":"agent_id","discovery_type":"discovery","payload":{}}`))
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	collectorService.AssertExpectations(t)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(`{"agent_id":"agent_id","discovery_type":"discovery","payload":{},"protocol_version":99}`))
+	req.Header.Set("Accept", "application/json")
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+	assert.Contains(t, resp.Body.String(), "unsupported protocol version 99")
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 1)
+}
+
+func TestApiCollectDataHandlerDelta(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return e.BaseHash == "stale"
+	})).Return(fmt.Errorf("event 0: %w", services.ErrDeltaBaseMismatch))
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(`{"agent_id":"agent_id","discovery_type":"discovery","payload":{},"payload_format":"delta","base_hash":"stale"}`))
+	req.Header.Set("Accept", "application/json")
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 409, resp.Code)
+	collectorService.AssertExpectations(t)
+
+	for body, message := range map[string]string{
+		`{"agent_id":"agent_id","discovery_type":"discovery","payload":{},"payload_format":"delta"}`:                                       "base_hash is required",
+		`{"agent_id":"agent_id","discovery_type":"discovery","payload":{},"payload_format":"unknown"}`:                                     "unsupported payload_format",
+		`{"agent_id":"agent_id","discovery_type":"discovery","payload":{},"payload_format":"delta","base_hash":"a","protocol_version":99}`: "require the protocol version",
+	} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(body))
+		req.Header.Set("Accept", "application/json")
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code)
+		assert.Contains(t, resp.Body.String(), message)
+	}
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 1)
+}
@@ -0,0 +1,264 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal/signing"
+)
+
+// collectorSpoolRetryInterval after which the replay of the spooled payloads is retried, if it failed
+const collectorSpoolRetryInterval = time.Minute
+
+// SpooledPayload is a collected payload stored on disk, as received, to be replayed later
+type SpooledPayload struct {
+	// AgentID the request was authenticated as, if any
+	AgentID string `json:"agent_id"`
+	// Organization of the authenticated agent, if any
+	Organization string    `json:"organization,omitempty"`
+	Body         []byte    `json:"body"`
+	Timestamp    string    `json:"timestamp"`
+	Signature    string    `json:"signature"`
+	ReceivedAt   time.Time `json:"received_at"`
+}
+
+// CollectorSpool durably stores the collected payloads in a directory, one file each, while the database
+// is not available, e.g. during the migrations. The payloads are replayed in the order they were received
+type CollectorSpool struct {
+	dir   string
+	mutex sync.Mutex
+	seq   int64
+}
+
+func NewCollectorSpool(dir string) (*CollectorSpool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &CollectorSpool{dir: dir}, nil
+}
+
+// Store writes the payload to a temporary file first, it is only visible to the replay once complete
+func (s *CollectorSpool) Store(payload *SpooledPayload) error {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.seq++
+	name := fmt.Sprintf("%020d-%06d.json", payload.ReceivedAt.UnixNano(), s.seq%1000000)
+	s.mutex.Unlock()
+
+	file, err := os.CreateTemp(s.dir, ".spool-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), filepath.Join(s.dir, name))
+}
+
+// Len returns the number of payloads waiting to be replayed
+func (s *CollectorSpool) Len() (int, error) {
+	names, err := s.names()
+	return len(names), err
+}
+
+func (s *CollectorSpool) names() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Replay hands the spooled payloads to the function, in the order they were received, removing the ones
+// it accepts. It stops at the first payload failing with a retriable error, to keep the order
+func (s *CollectorSpool) Replay(replay func(payload *SpooledPayload) (retry bool, err error)) (int, error) {
+	names, err := s.names()
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, name := range names {
+		path := filepath.Join(s.dir, name)
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return replayed, err
+		}
+
+		var payload SpooledPayload
+		if err := json.Unmarshal(content, &payload); err != nil {
+			log.Errorf("Dropped the spooled payload %s, it cannot be decoded: %s", name, err)
+		} else if retry, err := replay(&payload); err != nil {
+			if retry {
+				return replayed, err
+			}
+			log.Warnf("Dropped the spooled payload %s of agent %s, it was refused: %s", name, payload.AgentID, err)
+		} else {
+			replayed++
+		}
+
+		if err := os.Remove(path); err != nil {
+			return replayed, err
+		}
+	}
+
+	return replayed, nil
+}
+
+// SpoolCollectDataHandler accepts the collected payloads into the spool, only checking that they are well-formed
+// and sent by the authenticated agent. The rest of the checks are done replaying them
+func SpoolCollectDataHandler(spool *CollectorSpool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var e struct {
+			AgentID string `json:"agent_id" binding:"required"`
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if err := json.Unmarshal(body, &e); err != nil || e.AgentID == "" {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if !checkAgentID(c, e.AgentID) {
+			return
+		}
+
+		payload := &SpooledPayload{
+			AgentID:      e.AgentID,
+			Organization: agentOrganization(c),
+			Body:         body,
+			Timestamp:    c.GetHeader(signing.TimestampHeader),
+			Signature:    c.GetHeader(signing.SignatureHeader),
+			ReceivedAt:   time.Now(),
+		}
+		if err := spool.Store(payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Writer.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// newSpoolReplayEngine handles the spooled payloads as the collector would, the agents being already authenticated
+func newSpoolReplayEngine(collectHandlers ...gin.HandlerFunc) *gin.Engine {
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	engine.Use(ErrorHandler)
+	engine.Use(spooledPayloadMiddleware)
+	engine.POST("/api/collect", collectHandlers...)
+
+	return engine
+}
+
+// spoolReplayError is the refusal of a replayed payload by the collector
+type spoolReplayError struct {
+	status  int
+	message string
+}
+
+func (e *spoolReplayError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.status, e.message)
+}
+
+// replaySpooledPayload passes the payload to the replay engine, the server errors are retried
+func replaySpooledPayload(ctx context.Context, engine *gin.Engine, payload *SpooledPayload) (bool, error) {
+	ctx = context.WithValue(ctx, spooledPayloadKey{}, payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/api/collect", bytes.NewReader(payload.Body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(signing.TimestampHeader, payload.Timestamp)
+	req.Header.Set(signing.SignatureHeader, payload.Signature)
+
+	resp := newCollectorResponse()
+	engine.ServeHTTP(resp, req)
+
+	code, message := resp.status()
+	if code < 300 {
+		return false, nil
+	}
+
+	return code >= 500, &spoolReplayError{status: code, message: message}
+}
+
+type spooledPayloadKey struct{}
+
+// spooledPayloadMiddleware restores the identity the replayed payload was received with
+func spooledPayloadMiddleware(c *gin.Context) {
+	if payload, ok := c.Request.Context().Value(spooledPayloadKey{}).(*SpooledPayload); ok {
+		c.Set(ContextAgentIDKey, payload.AgentID)
+		c.Set(ContextAgentOrganizationKey, payload.Organization)
+		c.Set(contextReceivedAtKey, payload.ReceivedAt)
+	}
+	c.Next()
+}
+
+// ReplayCollectorSpool replays the spooled payloads until none is left, retrying periodically on failures
+func ReplayCollectorSpool(ctx context.Context, spool *CollectorSpool, engine *gin.Engine) {
+	for {
+		replayed, err := spool.Replay(func(payload *SpooledPayload) (bool, error) {
+			return replaySpooledPayload(ctx, engine, payload)
+		})
+		if replayed > 0 {
+			log.Infof("Replayed %d spooled payloads", replayed)
+		}
+		if err == nil {
+			return
+		}
+
+		log.Errorf("Failed to replay the spooled payloads, retrying in %s: %s", collectorSpoolRetryInterval, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(collectorSpoolRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/internal/signing"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestCollectorSpool(t *testing.T) {
+	spool, err := NewCollectorSpool(t.TempDir())
+	assert.NoError(t, err)
+
+	receivedAt := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	for _, agentID := range []string{"agent1", "agent2", "agent3"} {
+		assert.NoError(t, spool.Store(&SpooledPayload{AgentID: agentID, Body: []byte(`{}`), ReceivedAt: receivedAt}))
+	}
+
+	length, err := spool.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, length)
+
+	// the retriable failures stop the replay, to keep the order
+	var replayed []string
+	count, err := spool.Replay(func(payload *SpooledPayload) (bool, error) {
+		if payload.AgentID == "agent2" {
+			return true, os.ErrDeadlineExceeded
+		}
+		replayed = append(replayed, payload.AgentID)
+		return false, nil
+	})
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"agent1"}, replayed)
+
+	// the refused payloads are dropped
+	count, err = spool.Replay(func(payload *SpooledPayload) (bool, error) {
+		if payload.AgentID == "agent2" {
+			return false, os.ErrInvalid
+		}
+		replayed = append(replayed, payload.AgentID)
+		return false, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"agent1", "agent3"}, replayed)
+
+	length, err = spool.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, length)
+}
+
+func TestUpgradeWindowSpoolsCollectedData(t *testing.T) {
+	config := setupTestConfig()
+	config.CollectorSpoolDir = t.TempDir()
+
+	spool, err := NewCollectorSpool(config.CollectorSpoolDir)
+	assert.NoError(t, err)
+
+	engine, err := newUpgradeWindowEngine(config, spool)
+	assert.NoError(t, err)
+
+	body := []byte(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}}`)
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+	req.Header.Set(signing.TimestampHeader, "1647079200")
+	req.Header.Set(signing.SignatureHeader, "sha256=valid")
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/hosts/agent_id/heartbeat", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 503, resp.Code)
+	assert.Equal(t, "30", resp.Header().Get("Retry-After"))
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(`not json`))
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+
+	// once started, the console replays the spooled data
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+	payloadSignaturesService := new(services.MockPayloadSignaturesService)
+	payloadSignaturesService.On("Verify", "agent_id", "1647079200", "sha256=valid", body, mock.MatchedBy(func(receivedAt time.Time) bool {
+		return time.Since(receivedAt) < time.Minute
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+	deps.signaturesService = payloadSignaturesService
+	deps.settingsService = newMaintenanceSettingsService(&models.MaintenanceMode{})
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ReplayCollectorSpool(context.Background(), spool, app.spoolReplayEngine)
+
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 1)
+	payloadSignaturesService.AssertExpectations(t)
+
+	length, err := spool.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, length)
+}
+
+func TestReplayCollectorSpoolAgentOrganization(t *testing.T) {
+	spool, err := NewCollectorSpool(t.TempDir())
+	assert.NoError(t, err)
+
+	body := []byte(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}, "organization": "globex"}`)
+	assert.NoError(t, spool.Store(&SpooledPayload{AgentID: "agent_id", Organization: "acme", Body: body, ReceivedAt: time.Now()}))
+
+	// the organization the agent was authenticated with when the payload was spooled
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return e.Organization == "acme"
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+	deps.settingsService = newMaintenanceSettingsService(&models.MaintenanceMode{})
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ReplayCollectorSpool(context.Background(), spool, app.spoolReplayEngine)
+
+	collectorService.AssertExpectations(t)
+}
+
+func TestReplaySpooledPayloadRefused(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(errors.New("database unavailable"))
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+	deps.settingsService = newMaintenanceSettingsService(&models.MaintenanceMode{})
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the invalid payloads are dropped, with the error of the collector
+	retry, err := replaySpooledPayload(context.Background(), app.spoolReplayEngine, &SpooledPayload{AgentID: "agent_id", Body: []byte(`not json`)})
+	assert.False(t, retry)
+	var replayErr *spoolReplayError
+	assert.ErrorAs(t, err, &replayErr)
+	assert.Equal(t, 400, replayErr.status)
+	assert.Equal(t, "status 400: invalid character 'o' in literal null (expecting 'u')", err.Error())
+
+	// the server errors are retried
+	body := []byte(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}}`)
+	retry, err = replaySpooledPayload(context.Background(), app.spoolReplayEngine, &SpooledPayload{AgentID: "agent_id", Body: body})
+	assert.True(t, retry)
+	assert.ErrorAs(t, err, &replayErr)
+	assert.Equal(t, 500, replayErr.status)
+}
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/internal/signing"
 	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
 
@@ -37,3 +40,314 @@ func TestApiCollectDataHandler(t *testing.T) {
 
 	assert.Equal(t, 202, resp.Code)
 }
+
+func TestApiCollectDataHandlerCapturesInvalidPayloads(t *testing.T) {
+	payloadCaptureService := new(services.MockPayloadCaptureService)
+	payloadCaptureService.On("Capture", "", []byte(`{"agent_id": 1`)).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.payloadCaptureService = payloadCaptureService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(`{"agent_id": 1`))
+	req.Header.Set("Accept", "application/json")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+	payloadCaptureService.AssertExpectations(t)
+}
+
+func TestApiCollectDataHandlerInvalidPayload(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(&datapipeline.PayloadValidationError{
+		DiscoveryType: "host_discovery",
+		Issues:        []*models.PayloadValidationIssue{{Location: "/hostname", Message: "expected string, but got number"}},
+	})
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "host_discovery",
+		Payload:       []byte(`{"hostname":42}`),
+	})
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+	req.Header.Set("Accept", "application/json")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 422, resp.Code)
+	assert.JSONEq(t, `{
+		"error": "invalid host_discovery payload: /hostname: expected string, but got number",
+		"issues": [{"location": "/hostname", "message": "expected string, but got number"}]
+	}`, resp.Body.String())
+}
+
+func TestApiCollectDataHandlerAgentApproval(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StorePendingEvent", mock.Anything).Return(nil)
+
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("Admit", "pending").Return(models.AgentStatusPending, nil)
+	agentsService.On("Admit", "rejected").Return(models.AgentStatusRejected, nil)
+	agentsService.On("Admit", "unregistered").Return(models.AgentStatusPending, services.ErrUnregisteredAgent)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+	deps.agentsService = agentsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collect := func(agentID string) int {
+		resp := httptest.NewRecorder()
+		body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+			AgentID:       agentID,
+			DiscoveryType: "discovery",
+			Payload:       []byte("{}"),
+		})
+		req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+		req.Header.Set("Accept", "application/json")
+
+		app.collectorEngine.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	// the events of the pending agents are stored, not projected
+	assert.Equal(t, 202, collect("pending"))
+	collectorService.AssertNumberOfCalls(t, "StorePendingEvent", 1)
+
+	assert.Equal(t, 403, collect("rejected"))
+	collectorService.AssertNotCalled(t, "StoreEvent", mock.Anything)
+
+	// the data of the unknown agents is refused when the auto-registration is disabled
+	assert.Equal(t, 403, collect("unregistered"))
+	collectorService.AssertNumberOfCalls(t, "StorePendingEvent", 1)
+}
+
+func TestApiCollectDataHandlerHostsLimit(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+
+	entitlementsService := new(services.MockEntitlementsService)
+	entitlementsService.On("AllowsHost", "agent_id").Return(false, nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+	deps.entitlementsService = entitlementsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte("{}"),
+	})
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+	req.Header.Set("Accept", "application/json")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+	collectorService.AssertNotCalled(t, "StoreEvent", mock.Anything)
+}
+
+func TestApiCollectDataHandlerPayloadSignature(t *testing.T) {
+	body := []byte(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}}`)
+
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+	payloadSignaturesService := new(services.MockPayloadSignaturesService)
+	payloadSignaturesService.On("Verify", "agent_id", "1647079200", "sha256=valid", body, mock.Anything).Return(nil)
+	payloadSignaturesService.On("Verify", "agent_id", "1647079200", "sha256=invalid", body, mock.Anything).Return(signing.ErrInvalidSignature)
+	payloadSignaturesService.On("Verify", "agent_id", "", "", body, mock.Anything).Return(services.ErrPayloadSignatureRequired)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+	deps.signaturesService = payloadSignaturesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for signature, code := range map[string]int{"sha256=valid": 202, "sha256=invalid": 401, "": 401} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+		req.Header.Set("Accept", "application/json")
+		if signature != "" {
+			req.Header.Set(signing.TimestampHeader, "1647079200")
+			req.Header.Set(signing.SignatureHeader, signature)
+		}
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, code, resp.Code, signature)
+	}
+
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 1)
+}
+
+func TestApiCollectBatchDataHandler(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvents", mock.MatchedBy(func(events []*datapipeline.DataCollectedEvent) bool {
+		return len(events) == 2 && events[0].DiscoveryType == "host_discovery" && events[1].DiscoveryType == "cloud_discovery"
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`[
+		{"agent_id": "agent_id", "discovery_type": "host_discovery", "payload": {}},
+		{"agent_id": "agent_id", "discovery_type": "cloud_discovery", "payload": {}}
+	]`)
+	req := httptest.NewRequest("POST", "/api/collect/batch", body)
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	collectorService.AssertExpectations(t)
+}
+
+func TestApiCollectBatchDataHandlerInvalid(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, body := range []string{
+		`{"agent_id": "agent_id", "discovery_type": "host_discovery", "payload": {}}`,
+		`[]`,
+		`[null]`,
+		`[{"agent_id": "agent_id", "discovery_type": "host_discovery", "payload": null}]`,
+		`[
+			{"agent_id": "agent_id", "discovery_type": "host_discovery", "payload": {}},
+			{"agent_id": "another_agent_id", "discovery_type": "host_discovery", "payload": {}}
+		]`,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect/batch", bytes.NewBufferString(body))
+		req.Header.Set("Accept", "application/json")
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code, body)
+	}
+
+	collectorService.AssertNotCalled(t, "StoreEvents", mock.Anything)
+}
+
+func TestApiCollectBatchDataHandlerAgentApproval(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StorePendingEvents", mock.Anything).Return(nil)
+
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("Admit", "pending").Return(models.AgentStatusPending, nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+	deps.agentsService = agentsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`[{"agent_id": "pending", "discovery_type": "host_discovery", "payload": {}}]`)
+	req := httptest.NewRequest("POST", "/api/collect/batch", body)
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	collectorService.AssertNumberOfCalls(t, "StorePendingEvents", 1)
+	collectorService.AssertNotCalled(t, "StoreEvents", mock.Anything)
+}
+
+func TestApiCollectBackfillHandler(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("BackfillEvents", mock.MatchedBy(func(events []*datapipeline.DataCollectedEvent) bool {
+		return len(events) == 2 &&
+			events[0].CollectedAt.Equal(time.Date(2021, 10, 2, 10, 0, 0, 0, time.UTC)) &&
+			events[1].CollectedAt.Equal(time.Date(2021, 10, 2, 9, 0, 0, 0, time.UTC))
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`[
+		{"agent_id": "agent_id", "discovery_type": "host_discovery", "payload": {}, "collected_at": "2021-10-02T10:00:00Z"},
+		{"agent_id": "agent_id", "discovery_type": "host_discovery", "payload": {}, "collected_at": "2021-10-02T09:00:00Z"}
+	]`)
+	req := httptest.NewRequest("POST", "/api/collect/backfill", body)
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	collectorService.AssertExpectations(t)
+}
+
+func TestApiCollectBackfillHandlerInvalid(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	for _, body := range []string{
+		`[{"agent_id": "agent_id", "discovery_type": "host_discovery", "payload": {}}]`,
+		`[{"agent_id": "agent_id", "discovery_type": "host_discovery", "payload": {}, "collected_at": "` + future + `"}]`,
+		`[{"agent_id": "agent_id", "discovery_type": "host_discovery", "payload": {}, "collected_at": "2021-10-02T10:00:00Z", "payload_format": "delta", "base_hash": "hash"}]`,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect/backfill", bytes.NewBufferString(body))
+		req.Header.Set("Accept", "application/json")
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code, body)
+	}
+
+	collectorService.AssertNotCalled(t, "BackfillEvents", mock.Anything)
+}
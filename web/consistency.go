@@ -0,0 +1,124 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+var consistencyCheckInterval = 10 * time.Minute
+
+// agentsReprojector projects again the latest events of the agents, restoring their read models
+type agentsReprojector interface {
+	Reproject(ctx context.Context, agentIDs []string) error
+}
+
+// ConsistencyChecker periodically cross-checks the projected read models, looking for the resources
+// they refer to but are missing, e.g. after a projection failed or the events were collected out of order
+type ConsistencyChecker struct {
+	consistencyService services.ConsistencyService
+}
+
+func NewConsistencyChecker(consistencyService services.ConsistencyService) *ConsistencyChecker {
+	return &ConsistencyChecker{consistencyService: consistencyService}
+}
+
+func (c *ConsistencyChecker) Start(ctx context.Context) {
+	log.Infof("Starting consistency checker")
+
+	internal.Repeat("web.consistency_checker", c.check, consistencyCheckInterval, ctx)
+}
+
+func (c *ConsistencyChecker) check() {
+	inconsistencies, err := c.consistencyService.Check()
+	if err != nil {
+		log.Errorf("Error while checking the consistency of the read models: %s", err)
+		return
+	}
+
+	for _, i := range inconsistencies {
+		log.Warnf("Inconsistency detected: %s %s, referenced by the %v of the agents %v", i.Kind, i.ResourceID, i.ReferencedBy, i.AgentIDs)
+	}
+}
+
+// ApiListInconsistenciesHandler godoc
+// @Summary List the inconsistencies found in the projected read models by the last check
+// @Produce json
+// @Success 200 {object} []models.Inconsistency
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/inconsistencies [get]
+func ApiListInconsistenciesHandler(consistencyService services.ConsistencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inconsistencies, err := consistencyService.GetAll(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, inconsistencies)
+	}
+}
+
+// ApiFixInconsistencyHandler godoc
+// @Summary Fix an inconsistency projecting again the latest events of the agents involved, returns the inconsistencies left
+// @Produce json
+// @Param kind path string true "Kind of the inconsistency"
+// @Param resource_id path string true "ID of the missing resource"
+// @Success 200 {object} []models.Inconsistency
+// @Failure 404 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/inconsistencies/{kind}/{resource_id}/fix [post]
+func ApiFixInconsistencyHandler(consistencyService services.ConsistencyService, reprojector agentsReprojector, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inconsistencies, err := consistencyService.GetAll(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		var inconsistency *models.Inconsistency
+		for _, i := range inconsistencies {
+			if i.Kind == c.Param("kind") && i.ResourceID == c.Param("resource_id") {
+				inconsistency = i
+			}
+		}
+
+		if inconsistency == nil {
+			_ = c.Error(NotFoundError("inconsistency not found"))
+			return
+		}
+
+		if !inconsistency.Fixable {
+			_ = c.Error(BadRequestError("the events of the agents are not stored anymore"))
+			return
+		}
+
+		if err := reprojector.Reproject(c.Request.Context(), inconsistency.AgentIDs); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionInconsistencyFixed, models.AuditResourceInconsistency,
+			inconsistency.Kind+"/"+inconsistency.ResourceID, inconsistency, nil)
+
+		left, err := consistencyService.Check()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if left == nil {
+			left = []*models.Inconsistency{}
+		}
+
+		c.JSON(http.StatusOK, left)
+	}
+}
@@ -0,0 +1,139 @@
+package web
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type agentsReprojectorStub struct {
+	reprojected [][]string
+}
+
+func (r *agentsReprojectorStub) Reproject(_ context.Context, agentIDs []string) error {
+	r.reprojected = append(r.reprojected, agentIDs)
+	return nil
+}
+
+func inconsistenciesFixture() []*models.Inconsistency {
+	return []*models.Inconsistency{
+		{
+			Kind:         models.InconsistencyClusterMissing,
+			ResourceID:   "cluster1",
+			ReferencedBy: []string{"hosts"},
+			AgentIDs:     []string{"agent1", "agent2"},
+			Fixable:      true,
+			DetectedAt:   time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			Kind:         models.InconsistencyHostMissing,
+			ResourceID:   "agent3",
+			ReferencedBy: []string{"sapsystems"},
+			AgentIDs:     []string{"agent3"},
+			Fixable:      false,
+			DetectedAt:   time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestApiListInconsistenciesHandler(t *testing.T) {
+	consistencyService := new(services.MockConsistencyService)
+	consistencyService.On("GetAll", "").Return(inconsistenciesFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.consistencyService = consistencyService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/pipeline/inconsistencies", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"kind": "cluster_missing",
+		"resource_id": "cluster1",
+		"referenced_by": ["hosts"],
+		"agent_ids": ["agent1", "agent2"],
+		"fixable": true,
+		"detected_at": "2022-03-12T10:00:00Z"
+	}, {
+		"kind": "host_missing",
+		"resource_id": "agent3",
+		"referenced_by": ["sapsystems"],
+		"agent_ids": ["agent3"],
+		"fixable": false,
+		"detected_at": "2022-03-12T10:00:00Z"
+	}]`, resp.Body.String())
+}
+
+func TestApiFixInconsistencyHandler(t *testing.T) {
+	inconsistencies := inconsistenciesFixture()
+
+	consistencyService := new(services.MockConsistencyService)
+	consistencyService.On("GetAll", "").Return(inconsistencies, nil)
+	consistencyService.On("Check").Return(inconsistencies[1:], nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionInconsistencyFixed &&
+			entry.ResourceType == models.AuditResourceInconsistency &&
+			entry.ResourceID == "cluster_missing/cluster1"
+	})).Return(nil)
+
+	reprojector := &agentsReprojectorStub{}
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/pipeline/inconsistencies/:kind/:resource_id/fix", ApiFixInconsistencyHandler(consistencyService, reprojector, auditService))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/pipeline/inconsistencies/cluster_missing/cluster1/fix", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, [][]string{{"agent1", "agent2"}}, reprojector.reprojected)
+	assert.Contains(t, resp.Body.String(), `"resource_id":"agent3"`)
+	assert.NotContains(t, resp.Body.String(), `"resource_id":"cluster1"`)
+	auditService.AssertExpectations(t)
+
+	for url, expected := range map[string]int{
+		"/pipeline/inconsistencies/host_missing/agent3/fix":    400,
+		"/pipeline/inconsistencies/host_missing/agent9/fix":    404,
+		"/pipeline/inconsistencies/cluster_missing/agent3/fix": 404,
+	} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("POST", url, nil)
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code, url)
+	}
+	assert.Len(t, reprojector.reprojected, 1)
+}
+
+func TestApiInconsistenciesHandlersViewer(t *testing.T) {
+	deps := setupTestDependencies()
+	deps.usersService = newMockedUsersService(models.UserRoleViewer)
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/pipeline/inconsistencies", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+const costReportDateLayout = "2006-01-02"
+
+// ApiGetCostReportHandler godoc
+// @Summary Report the monitored hosts and HANA instances, and their uptime, grouped by the values of the tags of a key
+// @Description The resources are grouped by the value of their tags of the form <key>:<value>, those without any in an empty valued group.
+// @Description The period defaults to the previous month, the to date being excluded.
+// @Description The resources of the organization of the user only, if any
+// @Produce json
+// @Produce text/csv
+// @Param tag_key query string true "Tag key, e.g. cost-center"
+// @Param from query string false "First day of the period, YYYY-MM-DD"
+// @Param to query string false "Day after the period, YYYY-MM-DD"
+// @Param format query string false "json or csv, json by default"
+// @Success 200 {object} models.CostReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /reports/costs [get]
+func ApiGetCostReportHandler(costReportService services.CostReportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tagKey := c.Query("tag_key")
+		if tagKey == "" {
+			_ = c.Error(BadRequestError("tag_key is required"))
+			return
+		}
+
+		if err := validateText("tag_key", tagKey, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" {
+			_ = c.Error(BadRequestError("format must be either json or csv"))
+			return
+		}
+
+		now := time.Now().UTC()
+		thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+		from, err := parseCostReportDate(c, "from", thisMonth.AddDate(0, -1, 0))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		to, err := parseCostReportDate(c, "to", thisMonth)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !from.Before(to) {
+			_ = c.Error(BadRequestError("from must be before to"))
+			return
+		}
+
+		report, err := costReportService.GetCostReport(userOrganization(c), tagKey, from, to)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if format == "json" {
+			c.JSON(http.StatusOK, report)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="costs-%s-%s.csv"`,
+			from.Format(costReportDateLayout), to.Format(costReportDateLayout)))
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+		writeCostReportCSV(c, report)
+	}
+}
+
+func parseCostReportDate(c *gin.Context, param string, defaultDate time.Time) (time.Time, error) {
+	value := c.Query(param)
+	if value == "" {
+		return defaultDate, nil
+	}
+
+	date, err := time.Parse(costReportDateLayout, value)
+	if err != nil {
+		return time.Time{}, BadRequestError(fmt.Sprintf("%s must be a YYYY-MM-DD date", param))
+	}
+
+	return date, nil
+}
+
+func writeCostReportCSV(c *gin.Context, report *models.CostReport) {
+	w := csv.NewWriter(c.Writer)
+
+	_ = w.Write([]string{report.TagKey, "hosts", "host_uptime_hours", "hana_instances", "hana_instance_uptime_hours"})
+	for _, g := range report.Groups {
+		_ = w.Write([]string{
+			g.Value,
+			strconv.Itoa(g.Hosts),
+			strconv.FormatFloat(g.HostUptimeHours, 'f', 2, 64),
+			strconv.Itoa(g.HANAInstances),
+			strconv.FormatFloat(g.HANAInstanceUptimeHours, 'f', 2, 64),
+		})
+	}
+
+	w.Flush()
+}
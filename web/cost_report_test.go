@@ -0,0 +1,118 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func costReportFixture() *models.CostReport {
+	return &models.CostReport{
+		TagKey: "cost-center",
+		From:   time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC),
+		Groups: []*models.CostReportGroup{
+			{Value: "", HANAInstances: 1, HANAInstanceUptimeHours: 5},
+			{Value: "4711", Hosts: 1, HostUptimeHours: 20.5, HANAInstances: 2, HANAInstanceUptimeHours: 41},
+		},
+	}
+}
+
+func TestApiGetCostReportHandler(t *testing.T) {
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	costReportService := new(services.MockCostReportService)
+	costReportService.On("GetCostReport", "", "cost-center", from, to).Return(costReportFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.costReportService = costReportService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/reports/costs?tag_key=cost-center&from=2022-03-01&to=2022-04-01", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"tag_key": "cost-center",
+		"from": "2022-03-01T00:00:00Z",
+		"to": "2022-04-01T00:00:00Z",
+		"groups": [
+			{"value": "", "hosts": 0, "host_uptime_hours": 0, "hana_instances": 1, "hana_instance_uptime_hours": 5},
+			{"value": "4711", "hosts": 1, "host_uptime_hours": 20.5, "hana_instances": 2, "hana_instance_uptime_hours": 41}
+		]
+	}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/reports/costs?tag_key=cost-center&from=2022-03-01&to=2022-04-01&format=csv", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "text/csv", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="costs-2022-03-01-2022-04-01.csv"`, resp.Header().Get("Content-Disposition"))
+	assert.Equal(t, "cost-center,hosts,host_uptime_hours,hana_instances,hana_instance_uptime_hours\n"+
+		",0,0.00,1,5.00\n"+
+		"4711,1,20.50,2,41.00\n", resp.Body.String())
+}
+
+func TestApiGetCostReportHandlerPreviousMonth(t *testing.T) {
+	now := time.Now().UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	costReportService := new(services.MockCostReportService)
+	costReportService.On("GetCostReport", "", "owner", thisMonth.AddDate(0, -1, 0), thisMonth).Return(costReportFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.costReportService = costReportService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/reports/costs?tag_key=owner", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	costReportService.AssertExpectations(t)
+}
+
+func TestApiGetCostReportHandlerInvalid(t *testing.T) {
+	costReportService := new(services.MockCostReportService)
+
+	deps := setupTestDependencies()
+	deps.costReportService = costReportService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{
+		"",
+		"tag_key=owner&format=xml",
+		"tag_key=owner&from=March",
+		"tag_key=owner&from=2022-04-01&to=2022-03-01",
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/reports/costs?"+query, nil)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code, query)
+	}
+
+	costReportService.AssertNotCalled(t, "GetCostReport", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,92 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	SessionCSRFTokenKey string = "csrf_token"
+	// CSRFTokenHeader carries the CSRF token in the requests of the frontend and in every response,
+	// where the layout render picks it up
+	CSRFTokenHeader string = "X-CSRF-Token"
+	// CSRFTokenFormField carries the CSRF token in the HTML forms
+	CSRFTokenFormField string = "csrf_token"
+)
+
+// CSRFMiddleware rejects the state changing requests of logged in users without the CSRF token of their session.
+// The requests authenticated with an API key or a personal access token are exempted,
+// as browsers never send bearer tokens on their own.
+// It must be used after the AuthMiddleware
+func CSRFMiddleware(c *gin.Context) {
+	if bearerAuthenticated(c) {
+		c.Next()
+		return
+	}
+
+	// nothing to forge before logging in
+	if _, ok := c.Get(ContextUserKey); !ok {
+		c.Next()
+		return
+	}
+
+	token, err := sessionCSRFToken(c)
+	if err != nil {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+
+	c.Header(CSRFTokenHeader, token)
+
+	if isSafeMethod(c.Request.Method) {
+		c.Next()
+		return
+	}
+
+	sent := c.GetHeader(CSRFTokenHeader)
+	if sent == "" {
+		sent = c.PostForm(CSRFTokenFormField)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+		log.Warnf("Request %s %s from %s rejected, invalid CSRF token", c.Request.Method, c.Request.URL.Path, c.ClientIP())
+		_ = c.Error(ForbiddenError("invalid CSRF token"))
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// sessionCSRFToken returns the CSRF token of the session, generating it on first use
+func sessionCSRFToken(c *gin.Context) (string, error) {
+	session := sessions.Default(c)
+
+	if token, ok := session.Get(SessionCSRFTokenKey).(string); ok && token != "" {
+		return token, nil
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	session.Set(SessionCSRFTokenKey, token)
+	if err := session.Save(); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		header      string
+		form        string
+		contentType string
+		expected    int
+	}{
+		{"missing token", "", "", "application/json", 403},
+		{"invalid token", "wrong-token", "", "application/json", 403},
+		{"token in the header", testCSRFToken, "", "application/json", 404},
+		{"token in the form", "", testCSRFToken, "application/x-www-form-urlencoded", 404},
+	} {
+		hostsService := new(services.MockHostsService)
+		hostsService.On("GetByID", "host1").Return(nil, nil)
+
+		deps := setupTestDependencies()
+		deps.hostsService = hostsService
+
+		app, err := NewAppWithDeps(setupTestConfig(), deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body := "{}"
+		if tc.form != "" {
+			body = url.Values{CSRFTokenFormField: {tc.form}}.Encode()
+		}
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/hosts/host1/tags", strings.NewReader(body))
+		if tc.header != "" {
+			req.Header.Set(CSRFTokenHeader, tc.header)
+		}
+		req.Header.Set("Content-Type", tc.contentType)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, tc.name)
+	}
+}
+
+func TestCSRFTokenRendered(t *testing.T) {
+	subscriptionsService := new(services.MockSubscriptionsService)
+	subscriptionsService.On("GetPremiumData").Return(&models.PremiumData{}, nil)
+
+	deps := setupTestDependencies()
+	deps.subscriptionsService = subscriptionsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/about", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, testCSRFToken, resp.Header().Get(CSRFTokenHeader))
+	assert.Contains(t, resp.Body.String(), `<meta name="csrf-token" content="test-csrf-token">`)
+	assert.Contains(t, resp.Body.String(), `<input type="hidden" name="csrf_token" value="test-csrf-token">`)
+}
@@ -0,0 +1,225 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+const (
+	HealthSummaryWidget         = "health_summary"
+	ExpiringSubscriptionsWidget = "expiring_subscriptions"
+	RecentAlertsWidget          = "recent_alerts"
+	PipelineStatusWidget        = "pipeline_status"
+	FavoriteResourcesWidget     = "favorite_resources"
+	AddressConflictsWidget      = "address_conflicts"
+
+	subscriptionsExpirationWindow = 30 * 24 * time.Hour
+)
+
+// DashboardWidgetRegistry holds the widgets which can be placed on the landing page, in their default order
+type DashboardWidgetRegistry []*models.DashboardWidget
+
+// InitDashboardWidgetRegistry initializes the DashboardWidgetRegistry
+func InitDashboardWidgetRegistry() DashboardWidgetRegistry {
+	return DashboardWidgetRegistry{
+		{ID: HealthSummaryWidget, Title: "At a glance", DataURL: "/api/sapsystems/health"},
+		{ID: FavoriteResourcesWidget, Title: "My resources", DataURL: "/api/favorites"},
+		{ID: RecentAlertsWidget, Title: "Recent alerts", DataURL: "/api/dashboard/alerts"},
+		{ID: AddressConflictsWidget, Title: "Address conflicts", DataURL: "/api/conflicts"},
+		{ID: ExpiringSubscriptionsWidget, Title: "Expiring subscriptions", DataURL: "/api/dashboard/subscriptions"},
+		{ID: PipelineStatusWidget, Title: "Data pipeline status", DataURL: "/api/dashboard/pipeline"},
+	}
+}
+
+func (r DashboardWidgetRegistry) IDs() []string {
+	var ids []string
+	for _, widget := range r {
+		ids = append(ids, widget.ID)
+	}
+
+	return ids
+}
+
+func (r DashboardWidgetRegistry) Has(id string) bool {
+	return internal.Contains(r.IDs(), id)
+}
+
+// ApiDashboardWidgetsHandler godoc
+// @Summary List the widgets available for the landing page
+// @Produce json
+// @Success 200 {object} []models.DashboardWidget
+// @Router /dashboard/widgets [get]
+func ApiDashboardWidgetsHandler(registry DashboardWidgetRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, registry)
+	}
+}
+
+// ApiGetDashboardLayoutHandler godoc
+// @Summary Get the landing page layout of the current user
+// @Produce json
+// @Success 200 {object} models.DashboardLayout
+// @Failure 500 {object} map[string]string
+// @Router /dashboard/layout [get]
+func ApiGetDashboardLayoutHandler(registry DashboardWidgetRegistry, preferencesService services.PreferencesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		widgets, err := preferencesService.GetDashboardLayout(sessionUserID(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if widgets == nil {
+			widgets = registry.IDs()
+		}
+
+		// widgets which are not registered anymore are silently dropped
+		layout := models.DashboardLayout{Widgets: []string{}}
+		for _, w := range widgets {
+			if registry.Has(w) {
+				layout.Widgets = append(layout.Widgets, w)
+			}
+		}
+
+		c.JSON(http.StatusOK, layout)
+	}
+}
+
+// ApiUpdateDashboardLayoutHandler godoc
+// @Summary Arrange the landing page widgets of the current user
+// @Accept json
+// @Produce json
+// @Param Body body models.DashboardLayout true "The ordered list of widgets to display"
+// @Success 200 {object} models.DashboardLayout
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dashboard/layout [put]
+func ApiUpdateDashboardLayoutHandler(registry DashboardWidgetRegistry, preferencesService services.PreferencesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var layout models.DashboardLayout
+
+		err := c.BindJSON(&layout)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		seen := make(map[string]bool)
+		for _, w := range layout.Widgets {
+			if !registry.Has(w) {
+				_ = c.Error(BadRequestError("unknown widget: " + w))
+				return
+			}
+			if seen[w] {
+				_ = c.Error(BadRequestError("duplicated widget: " + w))
+				return
+			}
+			seen[w] = true
+		}
+
+		err = preferencesService.SaveDashboardLayout(sessionUserID(c), layout.Widgets)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, &layout)
+	}
+}
+
+// ApiDashboardAlertsHandler godoc
+// @Summary List the hosts and clusters currently in critical or warning state
+// @Produce json
+// @Success 200 {object} []models.ResourceAlert
+// @Failure 500 {object} map[string]string
+// @Router /dashboard/alerts [get]
+func ApiDashboardAlertsHandler(hostsService services.HostsService, clustersService services.ClustersService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		alerts := []*models.ResourceAlert{}
+
+		hosts, err := hostsService.GetAll(&services.HostsFilter{
+			Health:       []string{models.HostHealthCritical, models.HostHealthWarning},
+			Organization: userOrganization(c),
+		}, nil)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		for _, h := range hosts {
+			alerts = append(alerts, &models.ResourceAlert{
+				ResourceType: models.TagHostResourceType,
+				ID:           h.ID,
+				Name:         h.Name,
+				Health:       h.Health,
+			})
+		}
+
+		clusters, err := clustersService.GetAll(&services.ClustersFilter{
+			Health:       []string{models.CheckCritical, models.CheckWarning},
+			Organization: userOrganization(c),
+		}, nil)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		for _, cl := range clusters {
+			alerts = append(alerts, &models.ResourceAlert{
+				ResourceType: models.TagClusterResourceType,
+				ID:           cl.ID,
+				Name:         cl.Name,
+				Health:       cl.Health,
+			})
+		}
+
+		c.JSON(http.StatusOK, alerts)
+	}
+}
+
+// ApiDashboardSubscriptionsHandler godoc
+// @Summary List the subscriptions expiring within the next 30 days
+// @Produce json
+// @Success 200 {object} []models.ExpiringSubscription
+// @Failure 500 {object} map[string]string
+// @Router /dashboard/subscriptions [get]
+func ApiDashboardSubscriptionsHandler(subscriptionsService services.SubscriptionsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subs, err := subscriptionsService.GetExpiringSubscriptions(time.Now().Add(subscriptionsExpirationWindow))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if subs == nil {
+			c.JSON(http.StatusOK, []*models.ExpiringSubscription{})
+			return
+		}
+
+		c.JSON(http.StatusOK, subs)
+	}
+}
+
+// ApiDashboardPipelineHandler godoc
+// @Summary Get the status of the data pipeline
+// @Produce json
+// @Success 200 {object} models.PipelineStatus
+// @Failure 500 {object} map[string]string
+// @Router /dashboard/pipeline [get]
+func ApiDashboardPipelineHandler(collectorService services.CollectorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := collectorService.GetPipelineStatus()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, status)
+	}
+}
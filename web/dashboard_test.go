@@ -0,0 +1,125 @@
+package web
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiGetDashboardLayoutHandlerDefault(t *testing.T) {
+	preferencesService := new(services.MockPreferencesService)
+	preferencesService.On("GetDashboardLayout", testUser).Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.preferencesService = preferencesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/dashboard/layout", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"widgets":["health_summary","favorite_resources","recent_alerts","address_conflicts","expiring_subscriptions","pipeline_status"]}`, resp.Body.String())
+}
+
+func TestApiGetDashboardLayoutHandlerCustomized(t *testing.T) {
+	preferencesService := new(services.MockPreferencesService)
+	preferencesService.On("GetDashboardLayout", testUser).Return(
+		[]string{"pipeline_status", "removed_widget", "health_summary"}, nil)
+
+	deps := setupTestDependencies()
+	deps.preferencesService = preferencesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/dashboard/layout", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"widgets":["pipeline_status","health_summary"]}`, resp.Body.String())
+}
+
+func TestApiUpdateDashboardLayoutHandler(t *testing.T) {
+	preferencesService := new(services.MockPreferencesService)
+	preferencesService.On(
+		"SaveDashboardLayout", testUser, []string{"recent_alerts", "health_summary"}).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.preferencesService = preferencesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := strings.NewReader(`{"widgets":["recent_alerts","health_summary"]}`)
+	req := httptest.NewRequest("PUT", "/api/dashboard/layout", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	preferencesService.AssertExpectations(t)
+
+	for _, invalidBody := range []string{
+		`{"widgets":["unknown"]}`,
+		`{"widgets":["health_summary","health_summary"]}`,
+		`{"foo":"bar"}`,
+	} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("PUT", "/api/dashboard/layout", strings.NewReader(invalidBody))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code)
+	}
+
+	preferencesService.AssertNumberOfCalls(t, "SaveDashboardLayout", 1)
+}
+
+func TestApiDashboardAlertsHandler(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetAll", mock.Anything, mock.Anything).Return(models.HostList{
+		{ID: "host1", Name: "host1", Health: models.HostHealthCritical},
+	}, nil)
+
+	clustersService := new(services.MockClustersService)
+	clustersService.On("GetAll", mock.Anything, mock.Anything).Return(models.ClusterList{
+		{ID: "cluster1", Name: "cluster1", Health: models.CheckWarning},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = hostsService
+	deps.clustersService = clustersService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/dashboard/alerts", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[
+		{"resource_type":"hosts","id":"host1","name":"host1","health":"critical"},
+		{"resource_type":"clusters","id":"cluster1","name":"cluster1","health":"warning"}
+	]`, resp.Body.String())
+}
@@ -0,0 +1,301 @@
+package datapipeline
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// backlogBatchSize is the number of events loaded and projected at once
+var backlogBatchSize = 100
+
+// latestEventsOrder sorts the events of every agent and discovery type from the last collected,
+// the events stored before their collection time was recorded coming last
+const latestEventsOrder = "data_collected_events.agent_id, data_collected_events.discovery_type, " +
+	"data_collected_events.collected_at DESC NULLS LAST, data_collected_events.id DESC"
+
+// backlogPriority is the order in which the discovery types are projected,
+// the hosts first as every other page refers to them
+var backlogPriority = []string{
+	HostDiscovery,
+	CloudDiscovery,
+	ClusterDiscovery,
+	SAPsystemDiscovery,
+	SubscriptionDiscovery,
+	KubernetesDiscovery,
+}
+
+// BacklogProjector projects, on startup, the events which were collected but not projected,
+// e.g. because the web server was stopped before the projectors caught up
+type BacklogProjector struct {
+	db                 *gorm.DB
+	projectorsRegistry ProjectorRegistry
+	mutex              sync.Mutex
+	progress           models.ProjectionBacklog
+}
+
+type backlogEvent struct {
+	ID            int64
+	AgentID       string
+	DiscoveryType string
+}
+
+func NewBacklogProjector(db *gorm.DB, projectorsRegistry ProjectorRegistry) *BacklogProjector {
+	return &BacklogProjector{
+		db:                 db,
+		projectorsRegistry: projectorsRegistry,
+	}
+}
+
+// Run projects the backlog in batches.
+// The discoveries are full snapshots, so only the latest event of every agent and discovery type is projected,
+// the hosts first. The events collected meanwhile are left to the projectors worker pool
+func (b *BacklogProjector) Run(ctx context.Context) error {
+	startedAt := time.Now()
+	b.updateProgress(func(p *models.ProjectionBacklog) {
+		p.InProgress = true
+		p.StartedAt = &startedAt
+	})
+	defer b.updateProgress(func(p *models.ProjectionBacklog) {
+		finishedAt := time.Now()
+		p.InProgress = false
+		p.FinishedAt = &finishedAt
+	})
+
+	events, superseded, err := b.findBacklog("")
+	if err != nil {
+		log.Errorf("Could not detect the backlog of events to project: %s", err)
+		return err
+	}
+
+	b.updateProgress(func(p *models.ProjectionBacklog) {
+		p.Total = int64(len(events))
+		p.Superseded = superseded
+	})
+
+	if len(events) == 0 {
+		log.Info("No backlog of events to project")
+		return nil
+	}
+
+	log.Infof("Projecting a backlog of %d events, %d superseded events skipped", len(events), superseded)
+
+	for start := 0; start < len(events); start += backlogBatchSize {
+		end := start + backlogBatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+
+		if err := b.projectEvents(ctx, events[start:end]); err != nil {
+			log.Errorf("Projection of the backlog interrupted: %s", err)
+			return err
+		}
+
+		b.updateProgress(func(p *models.ProjectionBacklog) {
+			p.Projected = int64(end)
+		})
+		log.Infof("Projected %d/%d backlog events", end, len(events))
+	}
+
+	log.Infof("Backlog projected in %s", time.Since(startedAt).Round(time.Millisecond))
+
+	return nil
+}
+
+// ProjectAgent projects the latest unprojected events of an agent,
+// which are held back until the agent is approved
+func (b *BacklogProjector) ProjectAgent(ctx context.Context, agentID string) error {
+	events, _, err := b.findBacklog(agentID)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Projecting %d events of the approved agent %s", len(events), agentID)
+
+	return b.projectEvents(ctx, events)
+}
+
+// Reproject projects again the latest event of every agent and discovery type, whether it was projected
+// already or not, restoring the read models of the agents in case they are inconsistent
+func (b *BacklogProjector) Reproject(ctx context.Context, agentIDs []string) error {
+	var events []backlogEvent
+	err := b.db.Model(&DataCollectedEvent{}).
+		Joins("LEFT JOIN agents ON agents.id = data_collected_events.agent_id").
+		Select("DISTINCT ON (data_collected_events.agent_id, data_collected_events.discovery_type) "+
+			"data_collected_events.id, data_collected_events.agent_id, data_collected_events.discovery_type").
+		Where("data_collected_events.agent_id IN ?", agentIDs).
+		Where("agents.status IS NULL OR agents.status = ?", models.AgentStatusApproved).
+		Order(latestEventsOrder).
+		Scan(&events).
+		Error
+	if err != nil {
+		return err
+	}
+
+	sortByPriority(events)
+
+	log.Infof("Projecting again %d events of the agents %s", len(events), strings.Join(agentIDs, ", "))
+
+	return b.projectEvents(ctx, events)
+}
+
+// Progress returns a snapshot of the progress of the backlog projection
+func (b *BacklogProjector) Progress() *models.ProjectionBacklog {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	progress := b.progress
+	return &progress
+}
+
+func (b *BacklogProjector) updateProgress(update func(p *models.ProjectionBacklog)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	update(&b.progress)
+}
+
+// findBacklog returns the latest unprojected event of every agent and discovery type, in priority order,
+// and the number of the older unprojected events they supersede.
+// An event is unprojected when a projector handling its discovery type has projected neither it
+// nor a later event of the same agent and discovery type, the projectors being compared one by one.
+// The backlog is limited to the given agent, if any
+func (b *BacklogProjector) findBacklog(agentID string) ([]backlogEvent, int64, error) {
+	var lastEventID *int64
+	err := b.db.Model(&DataCollectedEvent{}).Select("max(id)").Scan(&lastEventID).Error
+	if err != nil || lastEventID == nil {
+		return nil, 0, err
+	}
+
+	// the events unprojected by several projectors are counted once
+	var count int64
+	err = b.db.Table("(?) AS unprojected", b.unprojectedEvents(agentID).
+		Select("DISTINCT data_collected_events.id").
+		Where("data_collected_events.id <= ?", *lastEventID)).
+		Count(&count).
+		Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var latest []backlogEvent
+	err = b.unprojectedEvents(agentID).
+		Select("DISTINCT ON (data_collected_events.agent_id, data_collected_events.discovery_type) " +
+			"data_collected_events.id, data_collected_events.agent_id, data_collected_events.discovery_type").
+		Order(latestEventsOrder).
+		Scan(&latest).
+		Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The events collected after the startup are projected by the worker pool,
+	// along with the ones they supersede
+	events := []backlogEvent{}
+	for _, event := range latest {
+		if event.ID <= *lastEventID {
+			events = append(events, event)
+		}
+	}
+
+	sortByPriority(events)
+
+	return events, count - int64(len(events)), nil
+}
+
+// unprojectedEvents are the events still to project by each projector of the registry handling their discovery type,
+// an event being repeated for every such projector. It leaves out the events of the agents pending approval or rejected
+func (b *BacklogProjector) unprojectedEvents(agentID string) *gorm.DB {
+	var projectorIDs, discoveryTypes []string
+	for _, registered := range b.projectorsRegistry {
+		p := unwrapProjector(registered)
+		if p == nil {
+			continue
+		}
+
+		for _, discoveryType := range p.discoveryTypes() {
+			projectorIDs = append(projectorIDs, p.ID)
+			discoveryTypes = append(discoveryTypes, discoveryType)
+		}
+	}
+
+	db := b.db.Model(&DataCollectedEvent{}).
+		Joins("JOIN unnest(?::text[], ?::text[]) AS handled(projector_id, discovery_type) "+
+			"ON handled.discovery_type = data_collected_events.discovery_type", pq.StringArray(projectorIDs), pq.StringArray(discoveryTypes)).
+		Joins("LEFT JOIN projected_discoveries ON projected_discoveries.projector_id = handled.projector_id "+
+			"AND projected_discoveries.agent_id = data_collected_events.agent_id "+
+			"AND projected_discoveries.discovery_type = data_collected_events.discovery_type").
+		Joins("LEFT JOIN agents ON agents.id = data_collected_events.agent_id").
+		Where("data_collected_events.id > COALESCE(projected_discoveries.last_projected_event_id, 0)").
+		Where("agents.status IS NULL OR agents.status = ?", models.AgentStatusApproved)
+
+	if agentID != "" {
+		db = db.Where("data_collected_events.agent_id = ?", agentID)
+	}
+
+	return db
+}
+
+func (b *BacklogProjector) projectEvents(ctx context.Context, batch []backlogEvent) error {
+	return projectEvents(ctx, b.db, b.projectorsRegistry, batch)
+}
+
+// projectEvents loads and projects the events in the given order
+func projectEvents(ctx context.Context, db *gorm.DB, projectorsRegistry ProjectorRegistry, batch []backlogEvent) error {
+	var ids []int64
+	for _, event := range batch {
+		ids = append(ids, event.ID)
+	}
+
+	var loaded []*DataCollectedEvent
+	if err := db.Where("id IN ?", ids).Find(&loaded).Error; err != nil {
+		return err
+	}
+
+	byID := make(map[int64]*DataCollectedEvent)
+	for _, event := range loaded {
+		byID[event.ID] = event
+	}
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if event, ok := byID[id]; ok {
+			for _, projector := range projectorsRegistry {
+				projector.Project(event)
+			}
+		}
+	}
+
+	return nil
+}
+
+func sortByPriority(events []backlogEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		pi, pj := discoveryTypePriority(events[i].DiscoveryType), discoveryTypePriority(events[j].DiscoveryType)
+		if pi != pj {
+			return pi < pj
+		}
+		return events[i].ID > events[j].ID
+	})
+}
+
+func discoveryTypePriority(discoveryType string) int {
+	for i, t := range backlogPriority {
+		if t == discoveryType {
+			return i
+		}
+	}
+
+	return len(backlogPriority)
+}
@@ -0,0 +1,175 @@
+package datapipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type BacklogProjectorTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestBacklogProjectorTestSuite(t *testing.T) {
+	suite.Run(t, new(BacklogProjectorTestSuite))
+}
+
+func (suite *BacklogProjectorTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &DataCollectedEvent{}, &entities.Agent{})
+}
+
+func (suite *BacklogProjectorTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, DataCollectedEvent{}, entities.Agent{})
+}
+
+func (suite *BacklogProjectorTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+}
+
+func (suite *BacklogProjectorTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *BacklogProjectorTestSuite) createEvent(id int64, agentID string, discoveryType string) {
+	suite.tx.Create(&DataCollectedEvent{ID: id, AgentID: agentID, DiscoveryType: discoveryType, Payload: []byte("{}")})
+}
+
+// recordingProjector records the IDs of the events it projects
+func (suite *BacklogProjectorTestSuite) recordingProjector(id string, projected *[]int64, discoveryTypes ...string) *projector {
+	p := NewProjector(id, suite.tx)
+	for _, discoveryType := range discoveryTypes {
+		p.AddHandler(discoveryType, func(event *DataCollectedEvent, _ *gorm.DB) error {
+			*projected = append(*projected, event.ID)
+			return nil
+		})
+	}
+
+	return p
+}
+
+func (suite *BacklogProjectorTestSuite) TestBacklogProjector_Run() {
+	// agent1 is up to date, agent2 has never been projected
+	suite.createEvent(1, "agent1", HostDiscovery)
+	suite.createEvent(2, "agent1", ClusterDiscovery)
+	suite.createEvent(3, "agent2", ClusterDiscovery)
+	suite.createEvent(4, "agent2", HostDiscovery)
+	suite.createEvent(5, "agent2", ClusterDiscovery)
+	suite.createEvent(6, "agent2", HostDiscovery)
+	// agent3 has been projected up to its event 7
+	suite.createEvent(7, "agent3", HostDiscovery)
+	suite.createEvent(8, "agent3", SAPsystemDiscovery)
+	suite.tx.Create(&ProjectedDiscovery{ProjectorID: "hosts", AgentID: "agent1", DiscoveryType: HostDiscovery, LastProjectedEventID: 1})
+	suite.tx.Create(&ProjectedDiscovery{ProjectorID: "hosts", AgentID: "agent1", DiscoveryType: ClusterDiscovery, LastProjectedEventID: 2})
+	suite.tx.Create(&ProjectedDiscovery{ProjectorID: "hosts", AgentID: "agent3", DiscoveryType: HostDiscovery, LastProjectedEventID: 7})
+
+	var projected []int64
+	projector := suite.recordingProjector("hosts", &projected, HostDiscovery, ClusterDiscovery, SAPsystemDiscovery)
+
+	backlogProjector := NewBacklogProjector(suite.tx, ProjectorRegistry{projector})
+	suite.NoError(backlogProjector.Run(context.Background()))
+
+	// the hosts first, the superseded events 3 and 4 are skipped
+	suite.Equal([]int64{6, 5, 8}, projected)
+
+	progress := backlogProjector.Progress()
+	suite.False(progress.InProgress)
+	suite.Equal(int64(3), progress.Total)
+	suite.Equal(int64(3), progress.Projected)
+	suite.Equal(int64(2), progress.Superseded)
+	suite.NotNil(progress.StartedAt)
+	suite.NotNil(progress.FinishedAt)
+}
+
+func (suite *BacklogProjectorTestSuite) TestBacklogProjector_RunNoBacklog() {
+	suite.createEvent(1, "agent1", HostDiscovery)
+	suite.createEvent(2, "agent1", KubernetesDiscovery)
+	suite.tx.Create(&ProjectedDiscovery{ProjectorID: "hosts", AgentID: "agent1", DiscoveryType: HostDiscovery, LastProjectedEventID: 1})
+
+	// the event of the discovery type no projector handles is not part of the backlog
+	var projected []int64
+	projector := suite.recordingProjector("hosts", &projected, HostDiscovery)
+
+	backlogProjector := NewBacklogProjector(suite.tx, ProjectorRegistry{projector, new(MockProjector)})
+	suite.NoError(backlogProjector.Run(context.Background()))
+
+	suite.Empty(projected)
+	suite.Equal(int64(0), backlogProjector.Progress().Total)
+}
+
+func (suite *BacklogProjectorTestSuite) TestBacklogProjector_RunDivergingProjectors() {
+	suite.createEvent(1, "agent1", HostDiscovery)
+	suite.createEvent(2, "agent1", HostDiscovery)
+	suite.createEvent(3, "agent2", HostDiscovery)
+	// the hosts projector is up to date, the telemetry one is behind on agent1 and never projected agent2
+	suite.tx.Create(&ProjectedDiscovery{ProjectorID: "hosts", AgentID: "agent1", DiscoveryType: HostDiscovery, LastProjectedEventID: 2})
+	suite.tx.Create(&ProjectedDiscovery{ProjectorID: "hosts", AgentID: "agent2", DiscoveryType: HostDiscovery, LastProjectedEventID: 3})
+	suite.tx.Create(&ProjectedDiscovery{ProjectorID: "host_telemetry", AgentID: "agent1", DiscoveryType: HostDiscovery, LastProjectedEventID: 1})
+
+	var hosts, telemetry []int64
+	hostsProjector := suite.recordingProjector("hosts", &hosts, HostDiscovery)
+	telemetryProjector := suite.recordingProjector("host_telemetry", &telemetry, HostDiscovery)
+
+	backlogProjector := NewBacklogProjector(suite.tx, ProjectorRegistry{hostsProjector, telemetryProjector})
+	suite.NoError(backlogProjector.Run(context.Background()))
+
+	suite.ElementsMatch([]int64{2, 3}, telemetry)
+	suite.Equal(int64(2), backlogProjector.Progress().Total)
+	suite.Equal(int64(0), backlogProjector.Progress().Superseded)
+}
+
+func (suite *BacklogProjectorTestSuite) TestBacklogProjector_PendingAgents() {
+	suite.createEvent(1, "approved", HostDiscovery)
+	suite.createEvent(2, "pending", HostDiscovery)
+	suite.createEvent(3, "pending", ClusterDiscovery)
+	suite.createEvent(4, "rejected", HostDiscovery)
+	suite.tx.Create(&entities.Agent{ID: "approved", Status: models.AgentStatusApproved})
+	suite.tx.Create(&entities.Agent{ID: "pending", Status: models.AgentStatusPending})
+	suite.tx.Create(&entities.Agent{ID: "rejected", Status: models.AgentStatusRejected})
+
+	var projected []int64
+	projector := suite.recordingProjector("hosts", &projected, HostDiscovery, ClusterDiscovery)
+
+	backlogProjector := NewBacklogProjector(suite.tx, ProjectorRegistry{projector})
+	suite.NoError(backlogProjector.Run(context.Background()))
+	suite.Equal([]int64{1}, projected)
+
+	// once approved, the events held back are projected
+	suite.tx.Model(&entities.Agent{}).Where("id", "pending").Update("status", models.AgentStatusApproved)
+	projected = nil
+
+	suite.NoError(backlogProjector.ProjectAgent(context.Background(), "pending"))
+	suite.Equal([]int64{2, 3}, projected)
+	suite.Equal(int64(1), backlogProjector.Progress().Projected)
+}
+
+func (suite *BacklogProjectorTestSuite) TestBacklogProjector_Reproject() {
+	suite.createEvent(1, "agent1", HostDiscovery)
+	suite.createEvent(2, "agent1", ClusterDiscovery)
+	suite.createEvent(3, "agent1", HostDiscovery)
+	suite.createEvent(4, "agent2", HostDiscovery)
+	suite.createEvent(5, "rejected", HostDiscovery)
+	suite.tx.Create(&entities.Agent{ID: "rejected", Status: models.AgentStatusRejected})
+	// the events were projected already
+	suite.tx.Create(&Subscription{ProjectorID: "hosts", AgentID: "agent1", LastProjectedEventID: 3})
+
+	var projected []int64
+	projector := new(MockProjector)
+	projector.On("Project", mock.Anything).Run(func(args mock.Arguments) {
+		projected = append(projected, args.Get(0).(*DataCollectedEvent).ID)
+	}).Return(nil)
+
+	backlogProjector := NewBacklogProjector(suite.tx, ProjectorRegistry{projector})
+	suite.NoError(backlogProjector.Reproject(context.Background(), []string{"agent1", "rejected"}))
+
+	suite.Equal([]int64{3, 2}, projected)
+}
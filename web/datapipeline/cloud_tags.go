@@ -0,0 +1,118 @@
+package datapipeline
+
+import (
+	"sort"
+
+	"github.com/mitchellh/mapstructure"
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal/cloud"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// cloudTags maps the tags of the cloud resource to the reserved namespace of the Trento tags
+func cloudTags(provider string, metadata interface{}) []string {
+	tags := make(map[string]string)
+
+	switch provider {
+	case cloud.Azure:
+		var azureMetadata cloud.AzureMetadata
+		if err := mapstructure.Decode(metadata, &azureMetadata); err != nil {
+			log.Errorf("can't decode azure metadata: %s", err)
+			return nil
+		}
+
+		for _, t := range azureMetadata.Compute.TagsList {
+			tags[t["name"]] = t["value"]
+		}
+	case cloud.Aws:
+		var awsMetadata cloud.AwsMetadata
+		if err := mapstructure.Decode(metadata, &awsMetadata); err != nil {
+			log.Errorf("can't decode aws metadata: %s", err)
+			return nil
+		}
+
+		tags = awsMetadata.Tags
+	}
+
+	var values []string
+	for key, value := range tags {
+		if key == "" {
+			continue
+		}
+		values = append(values, models.CloudTag(key, value))
+	}
+	sort.Strings(values)
+
+	return values
+}
+
+// syncCloudTags replaces the cloud tags of the resource, the tags added from the console are kept
+func syncCloudTags(db *gorm.DB, resourceType string, resourceID string, values []string) error {
+	var current []string
+	err := db.Model(&models.Tag{}).
+		Where("resource_type = ? AND resource_id = ? AND value LIKE ?", resourceType, resourceID, models.CloudTagPrefix+"%").
+		Pluck("value", &current).
+		Error
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool)
+	for _, v := range values {
+		wanted[v] = true
+	}
+
+	changed := false
+	for _, v := range current {
+		if wanted[v] {
+			delete(wanted, v)
+			continue
+		}
+
+		err := db.Delete(&models.Tag{Value: v, ResourceType: resourceType, ResourceID: resourceID}).Error
+		if err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	for v := range wanted {
+		err := db.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.Tag{Value: v, ResourceType: resourceType, ResourceID: resourceID}).
+			Error
+		if err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return RefreshSearchDocument(db, resourceType, resourceID)
+}
+
+// syncClusterCloudTags tags the cluster with the cloud tags of all of its nodes
+func syncClusterCloudTags(db *gorm.DB, clusterID string) error {
+	if clusterID == "" {
+		return nil
+	}
+
+	var values []string
+	err := db.Model(&models.Tag{}).
+		Distinct("value").
+		Where("resource_type = ? AND value LIKE ?", models.TagHostResourceType, models.CloudTagPrefix+"%").
+		Where("resource_id IN (?)", db.Model(&entities.Host{}).Select("agent_id").Where("cluster_id = ?", clusterID)).
+		Order("value").
+		Pluck("value", &values).
+		Error
+	if err != nil {
+		return err
+	}
+
+	return syncCloudTags(db, models.TagClusterResourceType, clusterID, values)
+}
@@ -0,0 +1,30 @@
+package datapipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/internal/cloud"
+)
+
+func TestCloudTags(t *testing.T) {
+	azureMetadata := map[string]interface{}{
+		"compute": map[string]interface{}{
+			"tagsList": []interface{}{
+				map[string]interface{}{"name": "workspace", "value": "xdemo"},
+				map[string]interface{}{"name": "CostCenter", "value": "sap-4711"},
+			},
+		},
+	}
+	assert.Equal(t, []string{"cloud:CostCenter=sap-4711", "cloud:workspace=xdemo"}, cloudTags(cloud.Azure, azureMetadata))
+
+	awsMetadata := map[string]interface{}{
+		"instanceId": "i-0123456789abcdef0",
+		"tags":       map[string]interface{}{"Environment": "production", "": "ignored"},
+	}
+	assert.Equal(t, []string{"cloud:Environment=production"}, cloudTags(cloud.Aws, awsMetadata))
+
+	assert.Empty(t, cloudTags(cloud.Aws, map[string]interface{}{"instanceId": "i-0123456789abcdef0"}))
+	assert.Empty(t, cloudTags(cloud.Gcp, map[string]interface{}{}))
+	assert.Empty(t, cloudTags("", nil))
+}
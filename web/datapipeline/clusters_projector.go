@@ -24,6 +24,7 @@ const (
 func NewClustersProjector(db *gorm.DB) *projector {
 	clusterProjector := NewProjector("clusters", db)
 	clusterProjector.AddHandler(ClusterDiscovery, clustersProjector_ClusterDiscoveryHandler)
+	clusterProjector.AddReadModels(&entities.Cluster{})
 
 	return clusterProjector
 }
@@ -50,6 +51,7 @@ func clustersProjector_ClusterDiscoveryHandler(event *DataCollectedEvent, db *go
 		log.Errorf("can't transform data: %s", err)
 		return err
 	}
+	clusterReadModel.Organization = event.Organization
 
 	discoveredHealth, err := computeDiscoveredHealth(clusterReadModel)
 	if err != nil {
@@ -76,6 +78,8 @@ func transformClusterData(cluster *cluster.Cluster) (*entities.Cluster, error) {
 	clusterDetail, _ := parseClusterDetails(cluster)
 	log.Debugf("%s", clusterDetail)
 
+	provisioningTool, provisioningTemplateVersion := parseClusterProvisioning(cluster)
+
 	return &entities.Cluster{
 		ID:          cluster.Id,
 		Name:        cluster.Name,
@@ -85,9 +89,29 @@ func transformClusterData(cluster *cluster.Cluster) (*entities.Cluster, error) {
 		ResourcesNumber: cluster.Crmmon.Summary.Resources.Number,
 		HostsNumber:     cluster.Crmmon.Summary.Nodes.Number,
 		Details:         (datatypes.JSON)(clusterDetail),
+
+		ProvisioningTool:            provisioningTool,
+		ProvisioningTemplateVersion: provisioningTemplateVersion,
 	}, nil
 }
 
+// parseClusterProvisioning returns the deployment tool and template version
+// set by the deployment automation as cluster properties
+func parseClusterProvisioning(c *cluster.Cluster) (string, string) {
+	var tool, templateVersion string
+
+	for _, p := range c.Cib.Configuration.CrmConfig.ClusterProperties {
+		switch p.Name {
+		case "provisioning-tool":
+			tool = p.Value
+		case "provisioning-template-version":
+			templateVersion = p.Value
+		}
+	}
+
+	return tool, templateVersion
+}
+
 // detectClusterType returns the cluster type based on the cluster resources
 func detectClusterType(cluster *cluster.Cluster) string {
 	var hasSapHanaTopology, hasSAPHanaController, hasSAPHana bool
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/trento-project/trento/internal/cluster"
+	"github.com/trento-project/trento/internal/cluster/cib"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
@@ -235,6 +236,20 @@ func TestTransformClusterData_Unknown(t *testing.T) {
 		}, clusterOut)
 }
 
+func TestParseClusterProvisioning(t *testing.T) {
+	var c cluster.Cluster
+	c.Cib.Configuration.CrmConfig.ClusterProperties = []cib.Attribute{
+		{Id: "cib-bootstrap-options-stonith-enabled", Name: "stonith-enabled", Value: "true"},
+		{Id: "cib-bootstrap-options-provisioning-tool", Name: "provisioning-tool", Value: "salt"},
+		{Id: "cib-bootstrap-options-provisioning-template-version", Name: "provisioning-template-version", Value: "2.1.0"},
+	}
+
+	tool, templateVersion := parseClusterProvisioning(&c)
+
+	assert.Equal(t, "salt", tool)
+	assert.Equal(t, "2.1.0", templateVersion)
+}
+
 func TestParseHANAStatus_Primary(t *testing.T) {
 	node := &entities.HANAClusterNode{
 		Attributes: map[string]string{
@@ -12,12 +12,39 @@ const (
 	HostDiscovery         = "host_discovery"
 	SubscriptionDiscovery = "subscription_discovery"
 	CloudDiscovery        = "cloud_discovery"
+	KubernetesDiscovery   = "kubernetes_discovery"
 )
 
+// DiscoveryTypes are the discoveries run by the agents
+var DiscoveryTypes = []string{
+	HostDiscovery,
+	CloudDiscovery,
+	ClusterDiscovery,
+	SAPsystemDiscovery,
+	SubscriptionDiscovery,
+	KubernetesDiscovery,
+}
+
+// PayloadFormatDelta is the format of the payloads sent as a JSON merge patch of the last payload stored
+// of the agent discovery, see the delta package. The payloads are full if their format is empty
+const PayloadFormatDelta = "delta"
+
 type DataCollectedEvent struct {
 	ID            int64
 	CreatedAt     time.Time
 	AgentID       string         `json:"agent_id" binding:"required"`
 	DiscoveryType string         `json:"discovery_type" binding:"required"`
 	Payload       datatypes.JSON `json:"payload" binding:"required"`
+	// CollectedAt is when the agent ran the discovery, the time it was received if not sent.
+	// The events are projected in this order, an older discovery never overwriting a newer one
+	CollectedAt time.Time `json:"collected_at,omitempty" gorm:"index"`
+	// Organization of the authenticated agent, isolating the landscapes served by the same console.
+	// It is bound by the collector to the identity of the agent, never read from the payload
+	Organization string `json:"-" gorm:"index"`
+	// ProtocolVersion the payload was collected with, the events are stored once translated to the current one
+	ProtocolVersion int `json:"protocol_version,omitempty" gorm:"-"`
+	// PayloadFormat of the payload as collected, the events are stored with the full payload once reconstructed
+	PayloadFormat string `json:"payload_format,omitempty" gorm:"-"`
+	// BaseHash of the payload a delta was computed from, as returned by delta.Hash
+	BaseHash string `json:"base_hash,omitempty" gorm:"-"`
 }
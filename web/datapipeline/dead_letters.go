@@ -0,0 +1,182 @@
+package datapipeline
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+// DeadLetter is an event a projector failed to project, kept until it is requeued successfully
+type DeadLetter struct {
+	ID            int64
+	ProjectorID   string `gorm:"uniqueIndex:idx_dead_letters_projector_event"`
+	EventID       int64  `gorm:"uniqueIndex:idx_dead_letters_projector_event"`
+	AgentID       string `gorm:"index"`
+	DiscoveryType string
+	Error         string
+	// Attempts to project the event, the first one included
+	Attempts int
+	FailedAt time.Time
+}
+
+func (d *DeadLetter) ToModel() *models.DeadLetter {
+	return &models.DeadLetter{
+		ID:            d.ID,
+		ProjectorID:   d.ProjectorID,
+		EventID:       d.EventID,
+		AgentID:       d.AgentID,
+		DiscoveryType: d.DiscoveryType,
+		Error:         d.Error,
+		Attempts:      d.Attempts,
+		FailedAt:      d.FailedAt,
+	}
+}
+
+var (
+	// ErrDeadLetterEventPruned is reported requeuing a dead letter whose event was pruned meanwhile
+	ErrDeadLetterEventPruned = errors.New("the event of the dead letter was pruned")
+	// ErrDeadLetterProjectorDisabled is reported requeuing a dead letter of a disabled projector
+	ErrDeadLetterProjectorDisabled = errors.New("the projector is disabled")
+)
+
+// DeadLetter records the failure of the projection of the event, counting the attempts if it failed already
+func (p *projector) DeadLetter(dataCollectedEvent *DataCollectedEvent, projectionErr error) {
+	err := p.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "projector_id"}, {Name: "event_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"error":     projectionErr.Error(),
+			"failed_at": time.Now(),
+			"attempts":  gorm.Expr("dead_letters.attempts + 1"),
+		}),
+	}).Create(&DeadLetter{
+		ProjectorID:   p.ID,
+		EventID:       dataCollectedEvent.ID,
+		AgentID:       dataCollectedEvent.AgentID,
+		DiscoveryType: dataCollectedEvent.DiscoveryType,
+		Error:         projectionErr.Error(),
+		Attempts:      1,
+		FailedAt:      time.Now(),
+	}).Error
+	if err != nil {
+		log.Errorf("Could not record the dead letter of the event %d of the projector %s: %s", dataCollectedEvent.ID, p.ID, err)
+		return
+	}
+
+	log.Errorf("Projector: %s failed to project the event: %d, recorded as a dead letter: %s", p.ID, dataCollectedEvent.ID, projectionErr)
+}
+
+// ListDeadLetters returns the dead letters of the projector, of all of them if empty, the oldest events first.
+// Only the dead letters of the agents whose host belongs to the organization are returned unless empty
+func (m *ProjectorsManager) ListDeadLetters(projectorID string, organization string) ([]*models.DeadLetter, error) {
+	var deadLetters []*DeadLetter
+
+	db := m.db.Order("event_id, projector_id")
+	if projectorID != "" {
+		db = db.Where("projector_id = ?", projectorID)
+	}
+
+	if organization != "" {
+		hosts := m.db.Session(&gorm.Session{NewDB: true}).Model(&entities.Host{}).Select("agent_id").Where("organization = ?", organization)
+		db = db.Where("agent_id IN (?)", hosts)
+	}
+
+	if err := db.Find(&deadLetters).Error; err != nil {
+		return nil, err
+	}
+
+	letters := []*models.DeadLetter{}
+	for _, d := range deadLetters {
+		letters = append(letters, d.ToModel())
+	}
+
+	return letters, nil
+}
+
+// RequeueDeadLetter projects again the event of the dead letter, returning nil if the dead letter does not exist
+func (m *ProjectorsManager) RequeueDeadLetter(id int64) (*models.DeadLetterRequeue, error) {
+	var deadLetters []*DeadLetter
+	if err := m.db.Where("id = ?", id).Limit(1).Find(&deadLetters).Error; err != nil {
+		return nil, err
+	}
+
+	if len(deadLetters) == 0 {
+		return nil, nil
+	}
+
+	return m.requeue(deadLetters)
+}
+
+// RequeueDeadLetters projects again the events of the dead letters of the projector, of all of them if empty
+func (m *ProjectorsManager) RequeueDeadLetters(projectorID string) (*models.DeadLetterRequeue, error) {
+	var deadLetters []*DeadLetter
+
+	db := m.db.Order("event_id, projector_id")
+	if projectorID != "" {
+		db = db.Where("projector_id = ?", projectorID)
+	}
+
+	if err := db.Find(&deadLetters).Error; err != nil {
+		return nil, err
+	}
+
+	return m.requeue(deadLetters)
+}
+
+// requeue projects again the events of the dead letters, in order. The dead letters are removed once projected,
+// the ones failing again are returned with their new error, as are the ones of the disabled projectors, kept.
+// The dead letters of the projectors no longer registered, or whose event was pruned, are removed
+func (m *ProjectorsManager) requeue(deadLetters []*DeadLetter) (*models.DeadLetterRequeue, error) {
+	result := &models.DeadLetterRequeue{Failed: []*models.DeadLetter{}}
+
+	for _, deadLetter := range deadLetters {
+		p := m.find(deadLetter.ProjectorID)
+		if p == nil {
+			log.Warnf("Discarding the dead letter %d of the projector %s, no longer registered", deadLetter.ID, deadLetter.ProjectorID)
+			if err := m.db.Delete(deadLetter).Error; err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if m.isDisabled(p) {
+			deadLetter.Error = ErrDeadLetterProjectorDisabled.Error()
+			result.Failed = append(result.Failed, deadLetter.ToModel())
+			continue
+		}
+
+		var events []*DataCollectedEvent
+		if err := m.db.Where("id = ?", deadLetter.EventID).Limit(1).Find(&events).Error; err != nil {
+			return nil, err
+		}
+
+		if len(events) == 0 {
+			if err := m.db.Delete(deadLetter).Error; err != nil {
+				return nil, err
+			}
+			deadLetter.Error = ErrDeadLetterEventPruned.Error()
+			result.Failed = append(result.Failed, deadLetter.ToModel())
+			continue
+		}
+
+		if err := p.projector.Project(events[0]); err != nil {
+			if err := m.db.First(deadLetter, deadLetter.ID).Error; err != nil {
+				return nil, err
+			}
+			result.Failed = append(result.Failed, deadLetter.ToModel())
+			continue
+		}
+
+		if err := m.db.Delete(deadLetter).Error; err != nil {
+			return nil, err
+		}
+		result.Requeued++
+	}
+
+	return result, nil
+}
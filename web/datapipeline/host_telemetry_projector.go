@@ -14,6 +14,7 @@ func NewHostTelemetryProjector(db *gorm.DB) *projector {
 
 	telemetryProjector.AddHandler(HostDiscovery, hostTelemetryProjector_HostDiscoveryHandler)
 	telemetryProjector.AddHandler(CloudDiscovery, hostTelemetryProjector_CloudDiscoveryHandler)
+	telemetryProjector.AddReadModels(&entities.HostTelemetry{})
 
 	return telemetryProjector
 }
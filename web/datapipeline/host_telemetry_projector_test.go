@@ -26,11 +26,11 @@ func TestHostTelemetryProjectorTestSuite(t *testing.T) {
 func (suite *HostTelemetryProjectorTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&Subscription{}, &entities.HostTelemetry{})
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &entities.HostTelemetry{})
 }
 
 func (suite *HostTelemetryProjectorTestSuite) TearDownSuite() {
-	suite.db.Migrator().DropTable(Subscription{}, entities.HostTelemetry{})
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, entities.HostTelemetry{})
 }
 
 func (suite *HostTelemetryProjectorTestSuite) SetupTest() {
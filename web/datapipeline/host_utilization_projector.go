@@ -0,0 +1,80 @@
+package datapipeline
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func NewHostUtilizationProjector(db *gorm.DB) *projector {
+	utilizationProjector := NewProjector("host_utilization", db)
+
+	utilizationProjector.AddHandler(HostDiscovery, hostUtilizationProjector_HostDiscoveryHandler)
+	utilizationProjector.AddReadModels(&entities.HostUtilizationSnapshot{})
+
+	return utilizationProjector
+}
+
+// hostUtilizationProjector_HostDiscoveryHandler folds the utilization sample of the discovery
+// into the snapshot of its time bucket and drops the snapshots past the retention period
+func hostUtilizationProjector_HostDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+	decoder := getPayloadDecoder(dataCollectedEvent.Payload)
+
+	var discoveredHost hosts.DiscoveredHost
+	if err := decoder.Decode(&discoveredHost); err != nil {
+		log.Errorf("can't decode data: %s", err)
+		return err
+	}
+
+	utilization := discoveredHost.Utilization
+	if utilization == nil {
+		return nil
+	}
+
+	bucketStart := dataCollectedEvent.CreatedAt.UTC().Truncate(models.HostUtilizationBucket)
+
+	snapshot := entities.HostUtilizationSnapshot{
+		AgentID:          dataCollectedEvent.AgentID,
+		BucketStart:      bucketStart,
+		SamplesCount:     1,
+		CPUPercentAvg:    utilization.CPUPercent,
+		CPUPercentMax:    utilization.CPUPercent,
+		MemoryPercentAvg: utilization.MemoryPercent,
+		MemoryPercentMax: utilization.MemoryPercent,
+		DiskPercentMax:   utilization.DiskPercent,
+	}
+
+	// The right hand side of the assignments refers to the values before the update
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "agent_id"},
+			{Name: "bucket_start"},
+		},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"samples_count":      gorm.Expr("host_utilization_snapshots.samples_count + 1"),
+			"cpu_percent_avg":    runningAverage("cpu_percent_avg"),
+			"cpu_percent_max":    gorm.Expr("GREATEST(host_utilization_snapshots.cpu_percent_max, EXCLUDED.cpu_percent_max)"),
+			"memory_percent_avg": runningAverage("memory_percent_avg"),
+			"memory_percent_max": gorm.Expr("GREATEST(host_utilization_snapshots.memory_percent_max, EXCLUDED.memory_percent_max)"),
+			"disk_percent_max":   gorm.Expr("GREATEST(host_utilization_snapshots.disk_percent_max, EXCLUDED.disk_percent_max)"),
+			"updated_at":         gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(&snapshot).Error
+	if err != nil {
+		return err
+	}
+
+	return db.
+		Where("agent_id = ? AND bucket_start < ?", dataCollectedEvent.AgentID, bucketStart.AddDate(0, 0, -models.HostUtilizationRetentionDays)).
+		Delete(&entities.HostUtilizationSnapshot{}).
+		Error
+}
+
+func runningAverage(column string) clause.Expr {
+	return gorm.Expr(
+		"(host_utilization_snapshots." + column + " * host_utilization_snapshots.samples_count + EXCLUDED." + column + ") / (host_utilization_snapshots.samples_count + 1)",
+	)
+}
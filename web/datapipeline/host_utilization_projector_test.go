@@ -0,0 +1,103 @@
+package datapipeline
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/agent/discovery/mocks"
+	"github.com/trento-project/trento/internal/hosts"
+	_ "github.com/trento-project/trento/test"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+type HostUtilizationProjectorTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestHostUtilizationProjectorTestSuite(t *testing.T) {
+	suite.Run(t, new(HostUtilizationProjectorTestSuite))
+}
+
+func (suite *HostUtilizationProjectorTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &entities.HostUtilizationSnapshot{})
+}
+
+func (suite *HostUtilizationProjectorTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, entities.HostUtilizationSnapshot{})
+}
+
+func (suite *HostUtilizationProjectorTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+}
+
+func (suite *HostUtilizationProjectorTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (s *HostUtilizationProjectorTestSuite) projectUtilization(at time.Time, utilization *hosts.HostUtilization) {
+	discoveredHost := mocks.NewDiscoveredHostMock()
+	discoveredHost.Utilization = utilization
+
+	requestBody, _ := json.Marshal(discoveredHost)
+
+	err := hostUtilizationProjector_HostDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: HostDiscovery,
+		Payload:       requestBody,
+		CreatedAt:     at,
+	}, s.tx)
+	s.NoError(err)
+}
+
+func (s *HostUtilizationProjectorTestSuite) Test_HostDiscoveryHandler() {
+	bucket := time.Date(2021, 10, 17, 10, 0, 0, 0, time.UTC)
+
+	s.projectUtilization(bucket.Add(5*time.Minute), &hosts.HostUtilization{CPUPercent: 10, MemoryPercent: 40, DiskPercent: 70})
+	s.projectUtilization(bucket.Add(10*time.Minute), &hosts.HostUtilization{CPUPercent: 30, MemoryPercent: 50, DiskPercent: 60})
+	s.projectUtilization(bucket.Add(70*time.Minute), &hosts.HostUtilization{CPUPercent: 5, MemoryPercent: 45, DiskPercent: 71})
+
+	var snapshots []entities.HostUtilizationSnapshot
+	s.tx.Order("bucket_start").Find(&snapshots)
+
+	s.Len(snapshots, 2)
+	s.Equal(bucket, snapshots[0].BucketStart.UTC())
+	s.Equal(2, snapshots[0].SamplesCount)
+	s.Equal(20.0, snapshots[0].CPUPercentAvg)
+	s.Equal(30.0, snapshots[0].CPUPercentMax)
+	s.Equal(45.0, snapshots[0].MemoryPercentAvg)
+	s.Equal(50.0, snapshots[0].MemoryPercentMax)
+	s.Equal(70.0, snapshots[0].DiskPercentMax)
+
+	s.Equal(bucket.Add(time.Hour), snapshots[1].BucketStart.UTC())
+	s.Equal(1, snapshots[1].SamplesCount)
+	s.Equal(5.0, snapshots[1].CPUPercentAvg)
+}
+
+func (s *HostUtilizationProjectorTestSuite) Test_HostDiscoveryHandlerRetention() {
+	now := time.Date(2021, 10, 17, 10, 0, 0, 0, time.UTC)
+
+	s.projectUtilization(now.AddDate(0, 0, -31), &hosts.HostUtilization{CPUPercent: 10})
+	s.projectUtilization(now.AddDate(0, 0, -29), &hosts.HostUtilization{CPUPercent: 10})
+	s.projectUtilization(now, &hosts.HostUtilization{CPUPercent: 10})
+
+	var count int64
+	s.tx.Model(&entities.HostUtilizationSnapshot{}).Count(&count)
+	s.EqualValues(2, count)
+}
+
+func (s *HostUtilizationProjectorTestSuite) Test_HostDiscoveryHandlerWithoutUtilization() {
+	s.projectUtilization(time.Now(), nil)
+
+	var count int64
+	s.tx.Model(&entities.HostUtilizationSnapshot{}).Count(&count)
+	s.EqualValues(0, count)
+}
@@ -2,15 +2,16 @@ package datapipeline
 
 import (
 	"encoding/json"
-	"net"
 
 	"github.com/mitchellh/mapstructure"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/trento-project/trento/internal"
 	"github.com/trento-project/trento/internal/cloud"
 	"github.com/trento-project/trento/internal/cluster"
 	"github.com/trento-project/trento/internal/hosts"
 	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -22,6 +23,9 @@ func NewHostsProjector(db *gorm.DB) *projector {
 	hostsProjector.AddHandler(HostDiscovery, hostsProjector_HostDiscoveryHandler)
 	hostsProjector.AddHandler(CloudDiscovery, hostsProjector_CloudDiscoveryHandler)
 	hostsProjector.AddHandler(ClusterDiscovery, hostsProjector_ClusterDiscoveryHandler)
+	hostsProjector.AddReadModels(&entities.Host{})
+	// stored when the agent authenticates
+	hostsProjector.PreserveColumns(&entities.Host{}, "agent_id", "certificate_fingerprint")
 
 	return hostsProjector
 }
@@ -36,11 +40,24 @@ func hostsProjector_HostDiscoveryHandler(dataCollectedEvent *DataCollectedEvent,
 	}
 
 	host := entities.Host{
-		AgentID:      dataCollectedEvent.AgentID,
-		SSHAddress:   discoveredHost.SSHAddress,
-		Name:         discoveredHost.HostName,
-		IPAddresses:  filterIPAddresses(discoveredHost.HostIpAddresses),
-		AgentVersion: discoveredHost.AgentVersion,
+		AgentID:       dataCollectedEvent.AgentID,
+		SSHAddress:    discoveredHost.SSHAddress,
+		Name:          discoveredHost.HostName,
+		IPAddresses:   filterIPAddresses(discoveredHost.HostIpAddresses),
+		AgentVersion:  discoveredHost.AgentVersion,
+		CPUCount:      discoveredHost.CPUCount,
+		SocketCount:   discoveredHost.SocketCount,
+		CoreCount:     discoveredHost.CoreCount,
+		TotalMemoryMB: discoveredHost.TotalMemoryMB,
+		Hypervisor:    discoveredHost.Hypervisor,
+		Ephemeral:     discoveredHost.Ephemeral,
+		AgentProfile:  discoveredHost.Profile,
+		Organization:  dataCollectedEvent.Organization,
+	}
+
+	if discoveredHost.Provisioning != nil {
+		host.ProvisioningTool = discoveredHost.Provisioning.Tool
+		host.ProvisioningTemplateVersion = discoveredHost.Provisioning.TemplateVersion
 	}
 
 	return storeHost(db, host,
@@ -48,6 +65,16 @@ func hostsProjector_HostDiscoveryHandler(dataCollectedEvent *DataCollectedEvent,
 		"ip_addresses",
 		"agent_version",
 		"ssh_address",
+		"cpu_count",
+		"socket_count",
+		"core_count",
+		"total_memory_mb",
+		"hypervisor",
+		"ephemeral",
+		"agent_profile",
+		"provisioning_tool",
+		"provisioning_template_version",
+		"organization",
 	)
 }
 
@@ -71,9 +98,24 @@ func hostsProjector_CloudDiscoveryHandler(dataCollectedEvent *DataCollectedEvent
 		AgentID:       dataCollectedEvent.AgentID,
 		CloudProvider: discoveredCloud.Provider,
 		CloudData:     (datatypes.JSON)(jsonCloudData),
+		Organization:  dataCollectedEvent.Organization,
+	}
+
+	if err := storeHost(db, host, "cloud_provider", "cloud_data", "organization"); err != nil {
+		return err
+	}
+
+	tags := cloudTags(discoveredCloud.Provider, discoveredCloud.Metadata)
+	if err := syncCloudTags(db, models.TagHostResourceType, dataCollectedEvent.AgentID, tags); err != nil {
+		return err
+	}
+
+	clusterID, err := hostClusterID(db, dataCollectedEvent.AgentID)
+	if err != nil {
+		return err
 	}
 
-	return storeHost(db, host, "cloud_provider", "cloud_data")
+	return syncClusterCloudTags(db, clusterID)
 }
 
 func hostsProjector_ClusterDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
@@ -85,14 +127,39 @@ func hostsProjector_ClusterDiscoveryHandler(dataCollectedEvent *DataCollectedEve
 		return err
 	}
 
+	previousClusterID, err := hostClusterID(db, dataCollectedEvent.AgentID)
+	if err != nil {
+		return err
+	}
+
 	host := entities.Host{
-		AgentID:     dataCollectedEvent.AgentID,
-		ClusterID:   discoveredCluster.Id,
-		ClusterName: discoveredCluster.Name,
-		ClusterType: detectClusterType(&discoveredCluster),
+		AgentID:      dataCollectedEvent.AgentID,
+		ClusterID:    discoveredCluster.Id,
+		ClusterName:  discoveredCluster.Name,
+		ClusterType:  detectClusterType(&discoveredCluster),
+		Organization: dataCollectedEvent.Organization,
+	}
+
+	if err := storeHost(db, host, "cluster_id", "cluster_name", "cluster_type", "organization"); err != nil {
+		return err
+	}
+
+	// the cloud tags of the cluster are the ones of its nodes, also of the cluster the host left
+	if previousClusterID != discoveredCluster.Id {
+		if err := syncClusterCloudTags(db, previousClusterID); err != nil {
+			return err
+		}
 	}
 
-	return storeHost(db, host, "cluster_id", "cluster_name", "cluster_type")
+	return syncClusterCloudTags(db, discoveredCluster.Id)
+}
+
+// hostClusterID is empty if the host is not a cluster node or it is not projected yet
+func hostClusterID(db *gorm.DB, agentID string) (string, error) {
+	var host entities.Host
+	err := db.Select("cluster_id").Where("agent_id = ?", agentID).Limit(1).Find(&host).Error
+
+	return host.ClusterID, err
 }
 
 func storeHost(db *gorm.DB, host entities.Host, updateColumns ...string) error {
@@ -104,16 +171,17 @@ func storeHost(db *gorm.DB, host entities.Host, updateColumns ...string) error {
 	}).Create(&host).Error
 }
 
-// filterIPAddresses filters out non-IPv4, loopback or invalid IP addresses
+// filterIPAddresses filters out the loopback, link-local or invalid IP addresses,
+// the IPv6 ones are stored in their canonical form
 func filterIPAddresses(ipAddresses []string) []string {
 	var filtered []string
 	for _, ipAddress := range ipAddresses {
-		ip := net.ParseIP(ipAddress)
-		if ip == nil || ip.IsLoopback() || ip.To4() == nil {
+		ip, _ := internal.ParseIPAddress(ipAddress)
+		if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
 			continue
 		}
 
-		filtered = append(filtered, ipAddress)
+		filtered = append(filtered, ip.String())
 	}
 	return filtered
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/agent/discovery/mocks"
+	"github.com/trento-project/trento/internal/hosts"
 	_ "github.com/trento-project/trento/test"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
@@ -26,11 +27,11 @@ func TestHostsProjectorTestSuite(t *testing.T) {
 func (suite *HostsProjectorTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&Subscription{}, &entities.Host{})
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &entities.Host{}, &models.Tag{}, &entities.SearchDocument{})
 }
 
 func (suite *HostsProjectorTestSuite) TearDownSuite() {
-	suite.db.Migrator().DropTable(Subscription{}, entities.Host{})
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, entities.Host{}, models.Tag{}, entities.SearchDocument{})
 }
 
 func (suite *HostsProjectorTestSuite) SetupTest() {
@@ -52,14 +53,23 @@ func (s *HostsProjectorTestSuite) Test_HostDiscoveryHandler() {
 		AgentID:       "agent_id",
 		DiscoveryType: HostDiscovery,
 		Payload:       requestBody,
+		Organization:  "acme",
 	}, s.tx)
 
 	var projectedHost entities.Host
 	s.tx.First(&projectedHost)
 
 	s.Equal(discoveredHostMock.HostName, projectedHost.Name)
+	s.Equal("acme", projectedHost.Organization)
 	s.EqualValues(discoveredHostMock.HostIpAddresses, projectedHost.IPAddresses)
 	s.Equal(discoveredHostMock.AgentVersion, projectedHost.AgentVersion)
+	s.Equal(discoveredHostMock.CPUCount, projectedHost.CPUCount)
+	s.Equal(discoveredHostMock.SocketCount, projectedHost.SocketCount)
+	s.Equal(discoveredHostMock.CoreCount, projectedHost.CoreCount)
+	s.Equal(discoveredHostMock.TotalMemoryMB, projectedHost.TotalMemoryMB)
+	s.Equal(discoveredHostMock.Hypervisor, projectedHost.Hypervisor)
+	s.Equal("terraform", projectedHost.ProvisioningTool)
+	s.Equal("1.2.0", projectedHost.ProvisioningTemplateVersion)
 
 	s.Equal("", projectedHost.CloudProvider)
 	s.Equal("", projectedHost.ClusterID)
@@ -67,6 +77,29 @@ func (s *HostsProjectorTestSuite) Test_HostDiscoveryHandler() {
 	s.Equal("", projectedHost.ClusterType)
 }
 
+// Test_HostDiscoveryHandlerBasicProfile tests the projection of the minimal host information sent by the basic agents
+func (s *HostsProjectorTestSuite) Test_HostDiscoveryHandlerBasicProfile() {
+	requestBody, _ := json.Marshal(hosts.DiscoveredHost{
+		HostName:     "availability-only",
+		AgentVersion: "1.0.0",
+		Profile:      hosts.AgentProfileBasic,
+	})
+
+	hostsProjector_HostDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: HostDiscovery,
+		Payload:       requestBody,
+	}, s.tx)
+
+	var projectedHost entities.Host
+	s.tx.First(&projectedHost)
+
+	s.Equal("availability-only", projectedHost.Name)
+	s.Equal(hosts.AgentProfileBasic, projectedHost.AgentProfile)
+	s.True(projectedHost.ToModel().IsBasic())
+}
+
 // Test_CloudDiscoveryHandler tests the loudDiscoveryHandler function execution on a CloudDiscovery published by an agent
 func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler() {
 	discoveredCloudMock := mocks.NewDiscoveredCloudMock()
@@ -78,12 +111,14 @@ func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler() {
 		AgentID:       "agent_id",
 		DiscoveryType: CloudDiscovery,
 		Payload:       requestBody,
+		Organization:  "acme",
 	}, s.tx)
 
 	var projectedHost entities.Host
 	s.tx.First(&projectedHost)
 
 	s.Equal(discoveredCloudMock.Provider, projectedHost.CloudProvider)
+	s.Equal("acme", projectedHost.Organization)
 
 	s.Equal("", projectedHost.Name)
 	s.Equal("", projectedHost.ClusterID)
@@ -104,6 +139,45 @@ func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler() {
 		SKU:             "gen2",
 		AdminUsername:   "cloudadmin",
 	}, projectedAzureCloudData)
+
+	var tags []string
+	s.tx.Model(&models.Tag{}).Where("resource_type = ? AND resource_id = ?", models.TagHostResourceType, "agent_id").Pluck("value", &tags)
+	s.Equal([]string{"cloud:workspace=theworkspace"}, tags)
+}
+
+// Test_CloudDiscoveryHandlerSyncsTags tests the cloud tags of the host, and of its cluster, follow the ones of the cloud resource
+func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandlerSyncsTags() {
+	s.tx.Create(&[]entities.Host{
+		{AgentID: "agent_id", ClusterID: "cluster_id"},
+		{AgentID: "other_agent_id", ClusterID: "cluster_id"},
+	})
+	s.tx.Create(&[]models.Tag{
+		{Value: "production", ResourceType: models.TagHostResourceType, ResourceID: "agent_id"},
+		{Value: "cloud:workspace=oldworkspace", ResourceType: models.TagHostResourceType, ResourceID: "agent_id"},
+		{Value: "cloud:CostCenter=sap-4711", ResourceType: models.TagHostResourceType, ResourceID: "other_agent_id"},
+		{Value: "cloud:workspace=oldworkspace", ResourceType: models.TagClusterResourceType, ResourceID: "cluster_id"},
+		{Value: "hana", ResourceType: models.TagClusterResourceType, ResourceID: "cluster_id"},
+	})
+
+	requestBody, _ := json.Marshal(mocks.NewDiscoveredCloudMock())
+
+	err := hostsProjector_CloudDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: CloudDiscovery,
+		Payload:       requestBody,
+	}, s.tx)
+	s.NoError(err)
+
+	tagsOf := func(resourceType string, resourceID string) []string {
+		var tags []string
+		s.tx.Model(&models.Tag{}).Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).Order("value").Pluck("value", &tags)
+		return tags
+	}
+
+	s.Equal([]string{"cloud:workspace=theworkspace", "production"}, tagsOf(models.TagHostResourceType, "agent_id"))
+	s.Equal([]string{"cloud:CostCenter=sap-4711"}, tagsOf(models.TagHostResourceType, "other_agent_id"))
+	s.Equal([]string{"cloud:CostCenter=sap-4711", "cloud:workspace=theworkspace", "hana"}, tagsOf(models.TagClusterResourceType, "cluster_id"))
 }
 
 func (s *HostsProjectorTestSuite) Test_parseAzureCloudData_Empty() {
@@ -121,12 +195,14 @@ func (s *HostsProjectorTestSuite) Test_ClusterDiscoveryHandler() {
 		AgentID:       "agent_id",
 		DiscoveryType: ClusterDiscovery,
 		Payload:       requestBody,
+		Organization:  "acme",
 	}, s.tx)
 
 	var projectedHost entities.Host
 	s.tx.First(&projectedHost)
 
 	s.Equal("47d1190ffb4f781974c8356d7f863b03", projectedHost.ClusterID)
+	s.Equal("acme", projectedHost.Organization)
 	s.Equal("hana_cluster", projectedHost.ClusterName)
 	s.Equal(models.ClusterTypeHANAScaleUp, projectedHost.ClusterType)
 
@@ -178,8 +254,11 @@ func (s *HostsProjectorTestSuite) Test_filterIPAddresses() {
 		"10.1.74.5",
 		"::1",
 		"fe80::6245:bdff:fe8b:5896",
+		"fe80::1%eth0",
+		"2001:DB8:0:0::1",
+		"fd00::5",
 		"not_valid",
 	}
 
-	s.EqualValues([]string{"10.1.74.5"}, filterIPAddresses(ipAddresses))
+	s.EqualValues([]string{"10.1.74.5", "2001:db8::1", "fd00::5"}, filterIPAddresses(ipAddresses))
 }
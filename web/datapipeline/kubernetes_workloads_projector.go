@@ -0,0 +1,58 @@
+package datapipeline
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal/kubernetes"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+func NewKubernetesWorkloadsProjector(db *gorm.DB) *projector {
+	workloadsProjector := NewProjector("kubernetes_workloads", db)
+
+	workloadsProjector.AddHandler(KubernetesDiscovery, kubernetesWorkloadsProjector_KubernetesDiscoveryHandler)
+	workloadsProjector.AddReadModels(&entities.KubernetesWorkload{})
+
+	return workloadsProjector
+}
+
+// kubernetesWorkloadsProjector_KubernetesDiscoveryHandler replaces the workloads of the host,
+// so that the pods rescheduled on other nodes are removed
+func kubernetesWorkloadsProjector_KubernetesDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+	decoder := getPayloadDecoder(dataCollectedEvent.Payload)
+
+	var discoveredWorkloads kubernetes.Workloads
+	if err := decoder.Decode(&discoveredWorkloads); err != nil {
+		log.Errorf("can't decode data: %s", err)
+		return err
+	}
+
+	var workloadEntities []entities.KubernetesWorkload
+
+	for _, workload := range discoveredWorkloads {
+		workloadEntities = append(workloadEntities, entities.KubernetesWorkload{
+			AgentID:        dataCollectedEvent.AgentID,
+			Namespace:      workload.Namespace,
+			Pod:            workload.Pod,
+			Node:           workload.Node,
+			SID:            workload.SID,
+			InstanceNumber: workload.InstanceNumber,
+			Type:           workload.Type,
+			Component:      workload.Component,
+			Image:          workload.Image,
+			Phase:          workload.Phase,
+		})
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("agent_id", dataCollectedEvent.AgentID).Delete(&entities.KubernetesWorkload{}).Error; err != nil {
+			return err
+		}
+		if len(workloadEntities) > 0 {
+			return tx.Create(&workloadEntities).Error
+		}
+
+		return nil
+	})
+}
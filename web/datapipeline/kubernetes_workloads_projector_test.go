@@ -0,0 +1,105 @@
+package datapipeline
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	_ "github.com/trento-project/trento/test"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type KubernetesWorkloadsProjectorTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestKubernetesWorkloadsProjectorTestSuite(t *testing.T) {
+	suite.Run(t, new(KubernetesWorkloadsProjectorTestSuite))
+}
+
+func (suite *KubernetesWorkloadsProjectorTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &entities.KubernetesWorkload{})
+}
+
+func (suite *KubernetesWorkloadsProjectorTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, entities.KubernetesWorkload{})
+}
+
+func (suite *KubernetesWorkloadsProjectorTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+
+	suite.tx.Create(&entities.KubernetesWorkload{
+		AgentID:   "779cdd70-e9e2-58ca-b18a-bf3eb3f71244",
+		Namespace: "sap-ha1",
+		Pod:       "ha1-rescheduled-0",
+		SID:       "HA1",
+	})
+	suite.tx.Create(&entities.KubernetesWorkload{
+		AgentID:   "879cdd70-e9e2-58ca-b18a-bf3eb3f71244",
+		Namespace: "sap-ha1",
+		Pod:       "ha1-di-1",
+		SID:       "HA1",
+	})
+}
+
+func (suite *KubernetesWorkloadsProjectorTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func loadKubernetesDiscoveryFixture() *DataCollectedEvent {
+	jsonFile, err := os.Open("./test/fixtures/discovery/kubernetes/expected_published_kubernetes_discovery.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+	var dataCollectedEvent *DataCollectedEvent
+	json.Unmarshal(byteValue, &dataCollectedEvent)
+
+	return dataCollectedEvent
+}
+
+func (suite *KubernetesWorkloadsProjectorTestSuite) Test_KubernetesDiscoveryHandler() {
+	err := kubernetesWorkloadsProjector_KubernetesDiscoveryHandler(loadKubernetesDiscoveryFixture(), suite.tx)
+	suite.NoError(err)
+
+	var projectedWorkloads []entities.KubernetesWorkload
+	suite.tx.Where("agent_id", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").Order("pod").Find(&projectedWorkloads)
+
+	suite.Equal(2, len(projectedWorkloads))
+	suite.Equal("ha1-ascs-0", projectedWorkloads[0].Pod)
+	suite.Equal("sap-ha1", projectedWorkloads[0].Namespace)
+	suite.Equal("vmhana01", projectedWorkloads[0].Node)
+	suite.Equal("HA1", projectedWorkloads[0].SID)
+	suite.Equal("00", projectedWorkloads[0].InstanceNumber)
+	suite.Equal("application", projectedWorkloads[0].Type)
+	suite.Equal("ASCS", projectedWorkloads[0].Component)
+	suite.Equal("registry.example.com/sap/ascs:7.53", projectedWorkloads[0].Image)
+	suite.Equal("Running", projectedWorkloads[0].Phase)
+	suite.Equal("ha1-di-0", projectedWorkloads[1].Pod)
+	suite.Equal("Pending", projectedWorkloads[1].Phase)
+}
+
+func (suite *KubernetesWorkloadsProjectorTestSuite) Test_KubernetesDiscoveryHandlerDelete() {
+	dataCollectedEvent := loadKubernetesDiscoveryFixture()
+	kubernetesWorkloadsProjector_KubernetesDiscoveryHandler(dataCollectedEvent, suite.tx)
+
+	dataCollectedEvent.Payload = datatypes.JSON([]byte(`[]`))
+	err := kubernetesWorkloadsProjector_KubernetesDiscoveryHandler(dataCollectedEvent, suite.tx)
+	suite.NoError(err)
+
+	var count int64
+	suite.tx.Table("kubernetes_workloads").Where("agent_id", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").Count(&count)
+	suite.Equal(int64(0), count)
+
+	suite.tx.Table("kubernetes_workloads").Where("agent_id", "879cdd70-e9e2-58ca-b18a-bf3eb3f71244").Count(&count)
+	suite.Equal(int64(1), count)
+}
@@ -0,0 +1,119 @@
+package datapipeline
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/trento-project/trento/web/models"
+)
+
+// maxPayloadValidationIssues are reported, the first ones in the order of their location
+const maxPayloadValidationIssues = 20
+
+//go:embed schemas
+var schemasFS embed.FS
+
+// payloadSchemas are the JSON schemas of the discovery payloads, by discovery type,
+// only what the projectors rely on is checked and the unknown properties are allowed
+var payloadSchemas = mustCompilePayloadSchemas()
+
+// PayloadValidationError lists the violations of the JSON schema of the discovery by its payload
+type PayloadValidationError struct {
+	DiscoveryType string
+	Issues        []*models.PayloadValidationIssue
+}
+
+func (e *PayloadValidationError) Error() string {
+	var issues []string
+	for _, i := range e.Issues {
+		if i.Location == "" {
+			issues = append(issues, i.Message)
+			continue
+		}
+		issues = append(issues, fmt.Sprintf("%s: %s", i.Location, i.Message))
+	}
+
+	return fmt.Sprintf("invalid %s payload: %s", e.DiscoveryType, strings.Join(issues, ", "))
+}
+
+// ValidatePayload validates the payload against the JSON schema of its discovery type,
+// the payloads of the discovery types without schema are not validated.
+// The error is a PayloadValidationError if the payload is not valid
+func ValidatePayload(discoveryType string, payload []byte) error {
+	schema, ok := payloadSchemas[discoveryType]
+	if !ok {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return &PayloadValidationError{
+			DiscoveryType: discoveryType,
+			Issues:        []*models.PayloadValidationIssue{{Location: "", Message: err.Error()}},
+		}
+	}
+
+	err := schema.Validate(document)
+
+	var validationError *jsonschema.ValidationError
+	if !errors.As(err, &validationError) {
+		return err
+	}
+
+	var issues []*models.PayloadValidationIssue
+	collectPayloadValidationIssues(validationError, &issues)
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Location < issues[j].Location
+	})
+	if len(issues) > maxPayloadValidationIssues {
+		issues = issues[:maxPayloadValidationIssues]
+	}
+
+	return &PayloadValidationError{DiscoveryType: discoveryType, Issues: issues}
+}
+
+// collectPayloadValidationIssues keeps the innermost errors, the others just tell the schema was not matched
+func collectPayloadValidationIssues(err *jsonschema.ValidationError, issues *[]*models.PayloadValidationIssue) {
+	if len(err.Causes) == 0 {
+		*issues = append(*issues, &models.PayloadValidationIssue{Location: err.InstanceLocation, Message: err.Message})
+		return
+	}
+
+	for _, cause := range err.Causes {
+		collectPayloadValidationIssues(cause, issues)
+	}
+}
+
+func mustCompilePayloadSchemas() map[string]*jsonschema.Schema {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+
+	schemas := make(map[string]*jsonschema.Schema)
+	for _, discoveryType := range []string{
+		HostDiscovery, ClusterDiscovery, SAPsystemDiscovery, CloudDiscovery, SubscriptionDiscovery, KubernetesDiscovery,
+	} {
+		name := "schemas/" + discoveryType + ".json"
+
+		content, err := schemasFS.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+
+		if err := compiler.AddResource(name, bytes.NewReader(content)); err != nil {
+			panic(err)
+		}
+
+		schemas[discoveryType] = compiler.MustCompile(name)
+	}
+
+	return schemas
+}
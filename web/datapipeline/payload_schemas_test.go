@@ -0,0 +1,65 @@
+package datapipeline
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/web/models"
+)
+
+func TestValidatePayloadPublishedDiscoveries(t *testing.T) {
+	for _, fixture := range []string{
+		"azure/expected_published_cloud_discovery.json",
+		"cluster/expected_published_cluster_discovery.json",
+		"host/expected_published_host_discovery.json",
+		"kubernetes/expected_published_kubernetes_discovery.json",
+		"sap_system/expected_published_sap_system_discovery_application.json",
+		"sap_system/expected_published_sap_system_discovery_database.json",
+		"subscriptions/expected_published_subscriptions_discovery.json",
+	} {
+		content, err := ioutil.ReadFile("./test/fixtures/discovery/" + fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var event DataCollectedEvent
+		if err := json.Unmarshal(content, &event); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.NoError(t, ValidatePayload(event.DiscoveryType, event.Payload), fixture)
+	}
+}
+
+func TestValidatePayloadEmptyDiscoveries(t *testing.T) {
+	assert.NoError(t, ValidatePayload(SAPsystemDiscovery, []byte(`[]`)))
+	assert.NoError(t, ValidatePayload(SubscriptionDiscovery, []byte(`[]`)))
+	assert.NoError(t, ValidatePayload(KubernetesDiscovery, []byte(`[]`)))
+}
+
+func TestValidatePayloadUnknownDiscoveryType(t *testing.T) {
+	assert.NoError(t, ValidatePayload("some_discovery", []byte(`{"any":"thing"}`)))
+}
+
+func TestValidatePayloadInvalid(t *testing.T) {
+	err := ValidatePayload(HostDiscovery, []byte(`{"ip_addresses":["10.74.1.10",42],"cpu_count":-1}`))
+
+	var validationError *PayloadValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, HostDiscovery, validationError.DiscoveryType)
+	assert.Equal(t, []*models.PayloadValidationIssue{
+		{Location: "", Message: "missing properties: 'hostname'"},
+		{Location: "/cpu_count", Message: "must be >= 0 but found -1"},
+		{Location: "/ip_addresses/1", Message: "expected string, but got number"},
+	}, validationError.Issues)
+	assert.EqualError(t, err, "invalid host_discovery payload: missing properties: 'hostname', "+
+		"/cpu_count: must be >= 0 but found -1, /ip_addresses/1: expected string, but got number")
+
+	err = ValidatePayload(SAPsystemDiscovery, []byte(`{"SID":"PRD"}`))
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, []*models.PayloadValidationIssue{
+		{Location: "", Message: "expected array or null, but got object"},
+	}, validationError.Issues)
+}
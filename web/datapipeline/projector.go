@@ -3,6 +3,8 @@ package datapipeline
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"sort"
 
 	log "github.com/sirupsen/logrus"
 	"gorm.io/datatypes"
@@ -15,12 +17,67 @@ type Projector interface {
 	Project(dataCollectedEvent *DataCollectedEvent) error
 }
 
+// RetryableProjector is a projector whose failures can be retried before being recorded as dead letters
+type RetryableProjector interface {
+	Projector
+	// TryProject projects the event as Project does, without recording its failure as a dead letter
+	TryProject(dataCollectedEvent *DataCollectedEvent) error
+	// DeadLetter records the failure of the projection of the event as a dead letter
+	DeadLetter(dataCollectedEvent *DataCollectedEvent, projectionErr error)
+}
+
+// WrappingProjector is a projector decorating another one, e.g. to switch it off or to delay it
+type WrappingProjector interface {
+	Projector
+	Unwrap() Projector
+}
+
+// unwrapProjector returns the projector a projector of the registry wraps, nil if none, e.g. a mock
+func unwrapProjector(p Projector) *projector {
+	for {
+		switch wrapped := p.(type) {
+		case *projector:
+			return wrapped
+		case WrappingProjector:
+			p = wrapped.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// TryProjectEvent projects the event without recording its failure as a dead letter, if the projector allows it
+func TryProjectEvent(projector Projector, dataCollectedEvent *DataCollectedEvent) error {
+	if retryable, ok := projector.(RetryableProjector); ok {
+		return retryable.TryProject(dataCollectedEvent)
+	}
+
+	return projector.Project(dataCollectedEvent)
+}
+
+// DeadLetterEvent records the failure of the projection of the event as a dead letter, if the projector allows it
+func DeadLetterEvent(projector Projector, dataCollectedEvent *DataCollectedEvent, projectionErr error) {
+	if retryable, ok := projector.(RetryableProjector); ok {
+		retryable.DeadLetter(dataCollectedEvent, projectionErr)
+	}
+}
+
 type ProjectorHandler func(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error
 
 type projector struct {
 	ID       string
 	db       *gorm.DB
 	handlers map[string]ProjectorHandler
+	// readModels are the entities whose tables are projected by the projector only, cleared when replaying the events
+	readModels []interface{}
+	// preservedColumns are written outside of the projections, restored once the events are replayed
+	preservedColumns []preservedColumns
+}
+
+type preservedColumns struct {
+	readModel interface{}
+	key       string
+	columns   []string
 }
 
 func NewProjector(ID string, db *gorm.DB) *projector {
@@ -36,13 +93,47 @@ func (p *projector) AddHandler(discoveryType string, handler ProjectorHandler) {
 	p.handlers[discoveryType] = handler
 }
 
+// AddReadModels registers the entities whose tables are projected by the projector only
+func (p *projector) AddReadModels(readModels ...interface{}) {
+	p.readModels = append(p.readModels, readModels...)
+}
+
+// PreserveColumns registers the columns of a read model not written by the projector,
+// identified by the key column, to be kept when replaying the events
+func (p *projector) PreserveColumns(readModel interface{}, key string, columns ...string) {
+	p.preservedColumns = append(p.preservedColumns, preservedColumns{readModel: readModel, key: key, columns: columns})
+}
+
+// discoveryTypes returns the sorted discovery types the projector handles
+func (p *projector) discoveryTypes() []string {
+	types := []string{}
+	for discoveryType := range p.handlers {
+		types = append(types, discoveryType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
 // Project processes the data collected event and calls the registered handlers
 // By updating the subscription with the LastProjectedEventID, it leverages the PostgresSQL implicit lock
-// to enforce linearizability if a specific agent tries to use the same projector concurrently
+// to enforce linearizability if a specific agent tries to use the same projector concurrently.
+// The events failing to be projected are recorded as dead letters, to be requeued
 func (p *projector) Project(dataCollectedEvent *DataCollectedEvent) error {
+	err := p.TryProject(dataCollectedEvent)
+	if err != nil {
+		p.DeadLetter(dataCollectedEvent, err)
+	}
+
+	return err
+}
+
+// TryProject projects the event, recovering the panics of the handlers as errors
+func (p *projector) TryProject(dataCollectedEvent *DataCollectedEvent) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Error("Projector panicked. Recovered. ", r)
+			err = fmt.Errorf("projector panicked: %v", r)
 		}
 	}()
 
@@ -59,15 +150,30 @@ func (p *projector) Project(dataCollectedEvent *DataCollectedEvent) error {
 		var subscription Subscription
 		tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(&Subscription{ProjectorID: p.ID, AgentID: dataCollectedEvent.AgentID}).First(&subscription)
 
+		// the requeued events do not move the subscription backwards
+		lastProjectedEventID := dataCollectedEvent.ID
+		if subscription.LastProjectedEventID > lastProjectedEventID {
+			lastProjectedEventID = subscription.LastProjectedEventID
+		}
+
 		tx.Clauses(clause.OnConflict{
 			UpdateAll: true,
 		}).Create(&Subscription{
 			ProjectorID:          p.ID,
 			AgentID:              dataCollectedEvent.AgentID,
-			LastProjectedEventID: dataCollectedEvent.ID,
+			LastProjectedEventID: lastProjectedEventID,
 		})
 
-		err := handler(dataCollectedEvent, tx)
+		stale, err := p.trackDiscovery(tx, dataCollectedEvent)
+		if err != nil {
+			return err
+		}
+		if stale {
+			log.Infof("Projector: %s already projected a discovery collected after the event: %d. Discarding it", p.ID, dataCollectedEvent.ID)
+			return nil
+		}
+
+		err = handler(dataCollectedEvent, tx)
 		if err != nil {
 			return err
 		}
@@ -76,6 +182,42 @@ func (p *projector) Project(dataCollectedEvent *DataCollectedEvent) error {
 	})
 }
 
+// trackDiscovery records the event as the last one projected for the discovery type of the agent,
+// telling whether it is stale: a discovery of the agent collected after it was projected already.
+// The events with no collection time are never stale
+func (p *projector) trackDiscovery(tx *gorm.DB, dataCollectedEvent *DataCollectedEvent) (bool, error) {
+	var projected ProjectedDiscovery
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(&ProjectedDiscovery{ProjectorID: p.ID, AgentID: dataCollectedEvent.AgentID, DiscoveryType: dataCollectedEvent.DiscoveryType}).
+		Limit(1).
+		Find(&projected)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	collectedAt := dataCollectedEvent.CollectedAt
+	stale := result.RowsAffected > 0 && !collectedAt.IsZero() && collectedAt.Before(projected.CollectedAt)
+
+	tracked := &ProjectedDiscovery{
+		ProjectorID:          p.ID,
+		AgentID:              dataCollectedEvent.AgentID,
+		DiscoveryType:        dataCollectedEvent.DiscoveryType,
+		CollectedAt:          projected.CollectedAt,
+		LastProjectedEventID: projected.LastProjectedEventID,
+	}
+	if !stale && !collectedAt.IsZero() {
+		tracked.CollectedAt = collectedAt
+	}
+	// the requeued events do not move the progress backwards
+	if dataCollectedEvent.ID > tracked.LastProjectedEventID {
+		tracked.LastProjectedEventID = dataCollectedEvent.ID
+	}
+
+	err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(tracked).Error
+
+	return stale, err
+}
+
 func getPayloadDecoder(payload datatypes.JSON) *json.Decoder {
 	data, _ := payload.MarshalJSON()
 	decoder := json.NewDecoder(bytes.NewReader(data))
@@ -10,7 +10,11 @@ func InitProjectorsRegistry(db *gorm.DB) ProjectorRegistry {
 		NewClustersProjector(db),
 		NewHostsProjector(db),
 		NewHostTelemetryProjector(db),
+		NewHostUtilizationProjector(db),
 		NewSlesSubscriptionsProjector(db),
 		NewSAPSystemsProjector(db),
+		NewKubernetesWorkloadsProjector(db),
+		// last, to index the resources projected by the projectors above
+		NewSearchIndexProjector(db),
 	}
 }
@@ -23,11 +23,11 @@ func TestProjectorTestSuite(t *testing.T) {
 func (suite *ProjectorTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&Subscription{})
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &DeadLetter{})
 }
 
 func (suite *ProjectorTestSuite) TearDownSuite() {
-	suite.db.Migrator().DropTable(Subscription{})
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, DeadLetter{})
 }
 
 func (suite *ProjectorTestSuite) SetupTest() {
@@ -139,3 +139,32 @@ func (suite *ProjectorTestSuite) TestProjector_Concurrency() {
 
 	suite.Equal(int64(2), subscription.LastProjectedEventID)
 }
+
+// TestProjector_Project_StaleDiscovery tests that a discovery collected before the last one projected is discarded
+func (suite *ProjectorTestSuite) TestProjector_Project_StaleDiscovery() {
+	projector := NewProjector("dummy_projector", suite.tx)
+	var projected []int64
+	handler := func(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+		projected = append(projected, dataCollectedEvent.ID)
+		return nil
+	}
+
+	projector.AddHandler("dummy_discovery_type", handler)
+
+	collectedAt := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	suite.NoError(projector.Project(&DataCollectedEvent{ID: 1, DiscoveryType: "dummy_discovery_type", AgentID: "345", CollectedAt: collectedAt}))
+	suite.NoError(projector.Project(&DataCollectedEvent{ID: 2, DiscoveryType: "dummy_discovery_type", AgentID: "345", CollectedAt: collectedAt.Add(-time.Hour)}))
+	suite.NoError(projector.Project(&DataCollectedEvent{ID: 3, DiscoveryType: "dummy_discovery_type", AgentID: "345", CollectedAt: collectedAt.Add(time.Hour)}))
+
+	suite.Equal([]int64{1, 3}, projected)
+
+	var subscription Subscription
+	suite.tx.First(&subscription)
+	suite.Equal(int64(3), subscription.LastProjectedEventID)
+
+	var projectedDiscovery ProjectedDiscovery
+	suite.tx.First(&projectedDiscovery)
+	suite.Equal("dummy_discovery_type", projectedDiscovery.DiscoveryType)
+	suite.True(collectedAt.Add(time.Hour).Equal(projectedDiscovery.CollectedAt))
+	suite.Equal(int64(3), projectedDiscovery.LastProjectedEventID)
+}
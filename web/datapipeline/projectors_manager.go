@@ -0,0 +1,279 @@
+package datapipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// DisabledProjector is a projector disabled at runtime, kept disabled across the restarts
+type DisabledProjector struct {
+	ProjectorID string `gorm:"primaryKey"`
+	DisabledAt  time.Time
+	DisabledBy  string
+}
+
+// ProjectorsManager enables and disables the registered projectors at runtime.
+// While a projector is disabled the events are still stored, but its subscriptions do not move forward,
+// so that it catches up on the latest events of every agent and discovery type once enabled again
+type ProjectorsManager struct {
+	db         *gorm.DB
+	mutex      sync.RWMutex
+	projectors []*switchableProjector
+	registry   ProjectorRegistry
+}
+
+type switchableProjector struct {
+	projector *projector
+	manager   *ProjectorsManager
+	disabled  *DisabledProjector
+}
+
+// Project skips the events while the projector is disabled
+func (s *switchableProjector) Project(dataCollectedEvent *DataCollectedEvent) error {
+	if s.manager.isDisabled(s) {
+		log.Debugf("Projector: %s is disabled. Skipping event: %d", s.projector.ID, dataCollectedEvent.ID)
+		return nil
+	}
+
+	return s.projector.Project(dataCollectedEvent)
+}
+
+// TryProject skips the events while the projector is disabled
+func (s *switchableProjector) TryProject(dataCollectedEvent *DataCollectedEvent) error {
+	if s.manager.isDisabled(s) {
+		log.Debugf("Projector: %s is disabled. Skipping event: %d", s.projector.ID, dataCollectedEvent.ID)
+		return nil
+	}
+
+	return s.projector.TryProject(dataCollectedEvent)
+}
+
+func (s *switchableProjector) Unwrap() Projector {
+	return s.projector
+}
+
+func (s *switchableProjector) DeadLetter(dataCollectedEvent *DataCollectedEvent, projectionErr error) {
+	s.projector.DeadLetter(dataCollectedEvent, projectionErr)
+}
+
+// NewProjectorsManager wraps the projectors of the registry, the other implementations being always enabled
+func NewProjectorsManager(db *gorm.DB, projectorsRegistry ProjectorRegistry) *ProjectorsManager {
+	m := &ProjectorsManager{db: db}
+
+	for _, p := range projectorsRegistry {
+		concrete, ok := p.(*projector)
+		if !ok {
+			m.registry = append(m.registry, p)
+			continue
+		}
+
+		switchable := &switchableProjector{projector: concrete, manager: m}
+		m.projectors = append(m.projectors, switchable)
+		m.registry = append(m.registry, switchable)
+	}
+
+	return m
+}
+
+// Registry returns the projectors to run, in the order of the wrapped registry
+func (m *ProjectorsManager) Registry() ProjectorRegistry {
+	return m.registry
+}
+
+// Load restores the projectors disabled before the startup
+func (m *ProjectorsManager) Load() error {
+	var disabled []*DisabledProjector
+	if err := m.db.Find(&disabled).Error; err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, d := range disabled {
+		if p := m.find(d.ProjectorID); p != nil {
+			p.disabled = d
+			log.Warnf("Projector %s was disabled by %s, its events are not projected", d.ProjectorID, d.DisabledBy)
+		}
+	}
+
+	return nil
+}
+
+// List returns the registered projectors, with the number of events collected while disabled
+func (m *ProjectorsManager) List() ([]*models.RegisteredProjector, error) {
+	projectors := []*models.RegisteredProjector{}
+
+	for _, p := range m.projectors {
+		projector, err := m.describe(p)
+		if err != nil {
+			return nil, err
+		}
+		projectors = append(projectors, projector)
+	}
+
+	return projectors, nil
+}
+
+// Disable stops the projection of the events by a projector, returning nil if the projector is not registered
+func (m *ProjectorsManager) Disable(projectorID string, actor string) (*models.RegisteredProjector, error) {
+	p := m.find(projectorID)
+	if p == nil {
+		return nil, nil
+	}
+
+	if !m.isDisabled(p) {
+		disabled := &DisabledProjector{ProjectorID: projectorID, DisabledAt: time.Now(), DisabledBy: actor}
+		if err := m.db.Clauses(clause.OnConflict{DoNothing: true}).Create(disabled).Error; err != nil {
+			return nil, err
+		}
+
+		m.mutex.Lock()
+		p.disabled = disabled
+		m.mutex.Unlock()
+
+		log.Warnf("Projector %s disabled by %s", projectorID, actor)
+	}
+
+	return m.describe(p)
+}
+
+// Enable resumes the projection of the events by a projector, returning nil if the projector is not registered.
+// The events collected meanwhile are left to CatchUp
+func (m *ProjectorsManager) Enable(projectorID string) (*models.RegisteredProjector, error) {
+	p := m.find(projectorID)
+	if p == nil {
+		return nil, nil
+	}
+
+	if m.isDisabled(p) {
+		if err := m.db.Delete(&DisabledProjector{ProjectorID: projectorID}).Error; err != nil {
+			return nil, err
+		}
+
+		m.mutex.Lock()
+		p.disabled = nil
+		m.mutex.Unlock()
+
+		log.Infof("Projector %s enabled", projectorID)
+	}
+
+	return m.describe(p)
+}
+
+// CatchUp projects, with the given projector only, the latest event of every agent and discovery type
+// collected since its last projection, e.g. while it was disabled
+func (m *ProjectorsManager) CatchUp(ctx context.Context, projectorID string) error {
+	p := m.find(projectorID)
+	if p == nil || m.isDisabled(p) {
+		return nil
+	}
+
+	var events []backlogEvent
+	err := m.pendingEvents(p).
+		Select("DISTINCT ON (data_collected_events.agent_id, data_collected_events.discovery_type) " +
+			"data_collected_events.id, data_collected_events.agent_id, data_collected_events.discovery_type").
+		Order(latestEventsOrder).
+		Scan(&events).
+		Error
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	sortByPriority(events)
+
+	log.Infof("Projector %s catching up on %d events", projectorID, len(events))
+
+	return projectEvents(ctx, m.db, ProjectorRegistry{&catchingUpProjector{p.projector, m.db}}, events)
+}
+
+// catchingUpProjector leaves out the events superseded by the ones of the same discovery type
+// the worker pool projected meanwhile
+type catchingUpProjector struct {
+	projector *projector
+	db        *gorm.DB
+}
+
+func (c *catchingUpProjector) Project(dataCollectedEvent *DataCollectedEvent) error {
+	var lastProjectedEventID int64
+	err := c.db.Model(&ProjectedDiscovery{}).
+		Select("last_projected_event_id").
+		Where(&ProjectedDiscovery{ProjectorID: c.projector.ID, AgentID: dataCollectedEvent.AgentID, DiscoveryType: dataCollectedEvent.DiscoveryType}).
+		Scan(&lastProjectedEventID).
+		Error
+	if err != nil {
+		return err
+	}
+
+	if lastProjectedEventID >= dataCollectedEvent.ID {
+		return nil
+	}
+
+	return c.projector.Project(dataCollectedEvent)
+}
+
+// pendingEvents are the events handled by the projector and collected since its last projection of the same
+// agent and discovery type, leaving out the ones of the agents pending approval or rejected
+func (m *ProjectorsManager) pendingEvents(p *switchableProjector) *gorm.DB {
+	return m.db.Model(&DataCollectedEvent{}).
+		Joins("LEFT JOIN projected_discoveries ON projected_discoveries.projector_id = ? "+
+			"AND projected_discoveries.agent_id = data_collected_events.agent_id "+
+			"AND projected_discoveries.discovery_type = data_collected_events.discovery_type", p.projector.ID).
+		Joins("LEFT JOIN agents ON agents.id = data_collected_events.agent_id").
+		Where("data_collected_events.discovery_type IN ?", p.projector.discoveryTypes()).
+		Where("data_collected_events.id > COALESCE(projected_discoveries.last_projected_event_id, 0)").
+		Where("agents.status IS NULL OR agents.status = ?", models.AgentStatusApproved)
+}
+
+func (m *ProjectorsManager) describe(p *switchableProjector) (*models.RegisteredProjector, error) {
+	m.mutex.RLock()
+	disabled := p.disabled
+	m.mutex.RUnlock()
+
+	projector := &models.RegisteredProjector{
+		ID:             p.projector.ID,
+		DiscoveryTypes: p.projector.discoveryTypes(),
+		Enabled:        disabled == nil,
+	}
+
+	if disabled == nil {
+		return projector, nil
+	}
+
+	projector.DisabledAt = &disabled.DisabledAt
+	projector.DisabledBy = disabled.DisabledBy
+
+	if err := m.pendingEvents(p).Count(&projector.PendingEvents).Error; err != nil {
+		return nil, err
+	}
+
+	return projector, nil
+}
+
+func (m *ProjectorsManager) find(projectorID string) *switchableProjector {
+	for _, p := range m.projectors {
+		if p.projector.ID == projectorID {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (m *ProjectorsManager) isDisabled(p *switchableProjector) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return p.disabled != nil
+}
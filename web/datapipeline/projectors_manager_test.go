@@ -0,0 +1,233 @@
+package datapipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+func TestProjectorsManagerRegistry(t *testing.T) {
+	projector := NewProjector("dummy_projector", nil)
+	projector.AddHandler(HostDiscovery, func(*DataCollectedEvent, *gorm.DB) error { return nil })
+	projector.AddHandler(CloudDiscovery, func(*DataCollectedEvent, *gorm.DB) error { return nil })
+	other := new(MockProjector)
+
+	manager := NewProjectorsManager(nil, ProjectorRegistry{projector, other})
+
+	registry := manager.Registry()
+	assert.Len(t, registry, 2)
+	assert.IsType(t, &switchableProjector{}, registry[0])
+	assert.Equal(t, other, registry[1])
+
+	projectors, err := manager.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.RegisteredProjector{
+		{ID: "dummy_projector", DiscoveryTypes: []string{CloudDiscovery, HostDiscovery}, Enabled: true},
+	}, projectors)
+}
+
+type ProjectorsManagerTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestProjectorsManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(ProjectorsManagerTestSuite))
+}
+
+func (suite *ProjectorsManagerTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &DataCollectedEvent{}, &entities.Agent{}, &DisabledProjector{}, &DeadLetter{})
+}
+
+func (suite *ProjectorsManagerTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, DataCollectedEvent{}, entities.Agent{}, DisabledProjector{}, DeadLetter{})
+}
+
+func (suite *ProjectorsManagerTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+}
+
+func (suite *ProjectorsManagerTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *ProjectorsManagerTestSuite) createEvent(id int64, agentID string, discoveryType string) *DataCollectedEvent {
+	event := &DataCollectedEvent{ID: id, AgentID: agentID, DiscoveryType: discoveryType, Payload: []byte("{}")}
+	suite.tx.Create(event)
+
+	return event
+}
+
+func (suite *ProjectorsManagerTestSuite) TestProjectorsManager_DisableAndEnable() {
+	var projected []int64
+	projector := NewProjector("dummy_projector", suite.tx)
+	projector.AddHandler(HostDiscovery, func(event *DataCollectedEvent, _ *gorm.DB) error {
+		projected = append(projected, event.ID)
+		return nil
+	})
+
+	manager := NewProjectorsManager(suite.tx, ProjectorRegistry{projector})
+	registry := manager.Registry()
+
+	registry[0].Project(suite.createEvent(1, "agent1", HostDiscovery))
+	suite.Equal([]int64{1}, projected)
+
+	disabled, err := manager.Disable("dummy_projector", "admin")
+	suite.NoError(err)
+	suite.False(disabled.Enabled)
+	suite.Equal("admin", disabled.DisabledBy)
+
+	// the events are stored but not projected
+	registry[0].Project(suite.createEvent(2, "agent1", HostDiscovery))
+	registry[0].Project(suite.createEvent(3, "agent1", HostDiscovery))
+	registry[0].Project(suite.createEvent(4, "agent2", HostDiscovery))
+	suite.createEvent(5, "agent2", ClusterDiscovery)
+	suite.Equal([]int64{1}, projected)
+
+	projectors, err := manager.List()
+	suite.NoError(err)
+	suite.Equal(int64(3), projectors[0].PendingEvents)
+
+	// the projector is kept disabled across the restarts
+	restarted := NewProjectorsManager(suite.tx, ProjectorRegistry{projector})
+	suite.NoError(restarted.Load())
+	projectors, err = restarted.List()
+	suite.NoError(err)
+	suite.False(projectors[0].Enabled)
+
+	enabled, err := manager.Enable("dummy_projector")
+	suite.NoError(err)
+	suite.True(enabled.Enabled)
+
+	// the latest event of every agent is projected
+	suite.NoError(manager.CatchUp(context.Background(), "dummy_projector"))
+	suite.ElementsMatch([]int64{1, 3, 4}, projected)
+
+	var count int64
+	suite.tx.Model(&DisabledProjector{}).Count(&count)
+	suite.Equal(int64(0), count)
+}
+
+func (suite *ProjectorsManagerTestSuite) TestProjectorsManager_CatchUpMultipleDiscoveryTypes() {
+	var projected []int64
+	projector := NewProjector("dummy_projector", suite.tx)
+	for _, discoveryType := range []string{HostDiscovery, CloudDiscovery, ClusterDiscovery} {
+		projector.AddHandler(discoveryType, func(event *DataCollectedEvent, _ *gorm.DB) error {
+			projected = append(projected, event.ID)
+			return nil
+		})
+	}
+
+	manager := NewProjectorsManager(suite.tx, ProjectorRegistry{projector})
+	registry := manager.Registry()
+
+	registry[0].Project(suite.createEvent(1, "agent1", HostDiscovery))
+
+	_, err := manager.Disable("dummy_projector", "admin")
+	suite.NoError(err)
+
+	registry[0].Project(suite.createEvent(2, "agent1", CloudDiscovery))
+	registry[0].Project(suite.createEvent(3, "agent1", ClusterDiscovery))
+	registry[0].Project(suite.createEvent(4, "agent1", HostDiscovery))
+
+	projectors, err := manager.List()
+	suite.NoError(err)
+	suite.Equal(int64(3), projectors[0].PendingEvents)
+
+	_, err = manager.Enable("dummy_projector")
+	suite.NoError(err)
+
+	// projected by the worker pool before the catch up, it supersedes the host discovery only
+	registry[0].Project(suite.createEvent(5, "agent1", HostDiscovery))
+
+	suite.NoError(manager.CatchUp(context.Background(), "dummy_projector"))
+	suite.Equal([]int64{1, 5, 2, 3}, projected)
+}
+
+func (suite *ProjectorsManagerTestSuite) TestProjectorsManager_CatchUpUpToDate() {
+	var projected []int64
+	projector := NewProjector("dummy_projector", suite.tx)
+	projector.AddHandler(HostDiscovery, func(event *DataCollectedEvent, _ *gorm.DB) error {
+		projected = append(projected, event.ID)
+		return nil
+	})
+
+	suite.createEvent(1, "agent1", HostDiscovery)
+	suite.createEvent(2, "pending", HostDiscovery)
+	suite.tx.Create(&entities.Agent{ID: "pending", Status: models.AgentStatusPending})
+	// projected by the worker pool meanwhile
+	suite.tx.Create(&Subscription{ProjectorID: "dummy_projector", AgentID: "agent1", LastProjectedEventID: 1})
+	suite.tx.Create(&ProjectedDiscovery{ProjectorID: "dummy_projector", AgentID: "agent1", DiscoveryType: HostDiscovery, LastProjectedEventID: 1})
+
+	manager := NewProjectorsManager(suite.tx, ProjectorRegistry{projector})
+	suite.NoError(manager.CatchUp(context.Background(), "dummy_projector"))
+	suite.Empty(projected)
+
+	unknown, err := manager.Disable("unknown", "admin")
+	suite.NoError(err)
+	suite.Nil(unknown)
+}
+
+func (suite *ProjectorsManagerTestSuite) TestProjectorsManager_DeadLetters() {
+	failing := true
+	var projected []int64
+	projector := NewProjector("dummy_projector", suite.tx)
+	projector.AddHandler(HostDiscovery, func(event *DataCollectedEvent, _ *gorm.DB) error {
+		if failing {
+			return errors.New("kaboom")
+		}
+		projected = append(projected, event.ID)
+		return nil
+	})
+
+	manager := NewProjectorsManager(suite.tx, ProjectorRegistry{projector})
+	registry := manager.Registry()
+
+	event := suite.createEvent(1, "agent1", HostDiscovery)
+	suite.Error(registry[0].Project(event))
+	suite.Error(registry[0].Project(event))
+	pruned := suite.createEvent(2, "agent2", HostDiscovery)
+	suite.Error(registry[0].Project(pruned))
+	suite.tx.Delete(pruned)
+
+	deadLetters, err := manager.ListDeadLetters("dummy_projector", "")
+	suite.NoError(err)
+	suite.Len(deadLetters, 2)
+	suite.Equal(int64(1), deadLetters[0].EventID)
+	suite.Equal("agent1", deadLetters[0].AgentID)
+	suite.Equal(2, deadLetters[0].Attempts)
+	suite.Equal("kaboom", deadLetters[0].Error)
+
+	// failing again, the dead letter is kept
+	result, err := manager.RequeueDeadLetter(deadLetters[0].ID)
+	suite.NoError(err)
+	suite.Equal(0, result.Requeued)
+	suite.Len(result.Failed, 1)
+	suite.Equal(3, result.Failed[0].Attempts)
+
+	failing = false
+	result, err = manager.RequeueDeadLetters("")
+	suite.NoError(err)
+	suite.Equal(1, result.Requeued)
+	suite.Len(result.Failed, 1)
+	suite.Equal(ErrDeadLetterEventPruned.Error(), result.Failed[0].Error)
+	suite.Equal([]int64{1}, projected)
+
+	deadLetters, err = manager.ListDeadLetters("", "")
+	suite.NoError(err)
+	suite.Empty(deadLetters)
+
+	unknown, err := manager.RequeueDeadLetter(42)
+	suite.NoError(err)
+	suite.Nil(unknown)
+}
@@ -0,0 +1,56 @@
+package datapipeline
+
+import (
+	"fmt"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	// ProtocolVersion is the version of the collected data the projectors handle.
+	// It is raised along with every change of the payloads, with an adapter translating the payloads
+	// of the previous version if the change breaks the projectors
+	ProtocolVersion = 1
+	// MinProtocolVersion is the oldest version of the collected data the collector translates
+	MinProtocolVersion = 1
+)
+
+// ProtocolAdapter translates the payload of a discovery from a protocol version to the next one
+type ProtocolAdapter func(discoveryType string, payload datatypes.JSON) (datatypes.JSON, error)
+
+// protocolAdapters translate the payloads of the version of their key to the next one
+var protocolAdapters = map[int]ProtocolAdapter{}
+
+// AdaptEvent translates the payload of an event collected by an older agent to the current protocol version,
+// the events not telling their version are of version 1, the one of the agents predating the versioning
+func AdaptEvent(e *DataCollectedEvent) error {
+	return adaptEvent(e, ProtocolVersion, protocolAdapters)
+}
+
+func adaptEvent(e *DataCollectedEvent, currentVersion int, adapters map[int]ProtocolAdapter) error {
+	version := e.ProtocolVersion
+	if version == 0 {
+		version = 1
+	}
+
+	if version < MinProtocolVersion || version > currentVersion {
+		return fmt.Errorf("unsupported protocol version %d, the collector supports the versions from %d to %d", version, MinProtocolVersion, currentVersion)
+	}
+
+	for ; version < currentVersion; version++ {
+		adapter, ok := adapters[version]
+		if !ok {
+			continue
+		}
+
+		payload, err := adapter(e.DiscoveryType, e.Payload)
+		if err != nil {
+			return fmt.Errorf("unable to translate the %s payload of protocol version %d: %w", e.DiscoveryType, version, err)
+		}
+		e.Payload = payload
+	}
+
+	e.ProtocolVersion = currentVersion
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package datapipeline
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+)
+
+func TestAdaptEventCurrentVersion(t *testing.T) {
+	event := &DataCollectedEvent{DiscoveryType: HostDiscovery, Payload: datatypes.JSON(`{"hostname":"host1"}`)}
+
+	assert.NoError(t, AdaptEvent(event))
+	assert.Equal(t, ProtocolVersion, event.ProtocolVersion)
+	assert.JSONEq(t, `{"hostname":"host1"}`, string(event.Payload))
+}
+
+func TestAdaptEventUnsupportedVersion(t *testing.T) {
+	event := &DataCollectedEvent{DiscoveryType: HostDiscovery, Payload: datatypes.JSON(`{}`), ProtocolVersion: ProtocolVersion + 1}
+
+	assert.EqualError(t, AdaptEvent(event), "unsupported protocol version 2, the collector supports the versions from 1 to 1")
+}
+
+func TestAdaptEventOlderVersion(t *testing.T) {
+	adapters := map[int]ProtocolAdapter{
+		1: func(discoveryType string, payload datatypes.JSON) (datatypes.JSON, error) {
+			assert.Equal(t, HostDiscovery, discoveryType)
+			return datatypes.JSON(`{"hostname":"host1","version":2}`), nil
+		},
+		3: func(discoveryType string, payload datatypes.JSON) (datatypes.JSON, error) {
+			return datatypes.JSON(string(payload[:len(payload)-1]) + `,"adapted":true}`), nil
+		},
+	}
+
+	event := &DataCollectedEvent{DiscoveryType: HostDiscovery, Payload: datatypes.JSON(`{"hostname":"host1"}`)}
+
+	assert.NoError(t, adaptEvent(event, 4, adapters))
+	assert.Equal(t, 4, event.ProtocolVersion)
+	assert.JSONEq(t, `{"hostname":"host1","version":2,"adapted":true}`, string(event.Payload))
+
+	event = &DataCollectedEvent{DiscoveryType: HostDiscovery, Payload: datatypes.JSON(`{"hostname":"host1"}`), ProtocolVersion: 2}
+
+	assert.NoError(t, adaptEvent(event, 4, adapters))
+	assert.JSONEq(t, `{"hostname":"host1","adapted":true}`, string(event.Payload))
+}
+
+func TestAdaptEventAdapterError(t *testing.T) {
+	adapters := map[int]ProtocolAdapter{
+		1: func(discoveryType string, payload datatypes.JSON) (datatypes.JSON, error) {
+			return nil, errors.New("missing hostname")
+		},
+	}
+
+	event := &DataCollectedEvent{DiscoveryType: HostDiscovery, Payload: datatypes.JSON(`{}`)}
+
+	assert.EqualError(t, adaptEvent(event, 2, adapters), "unable to translate the host_discovery payload of protocol version 1: missing hostname")
+}
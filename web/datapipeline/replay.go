@@ -0,0 +1,199 @@
+package datapipeline
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/models"
+)
+
+// ReplayResult reports the events projected again by the projectors
+type ReplayResult struct {
+	Projectors []string
+	Events     int64
+	// Projections failed, recorded as dead letters
+	Failed int64
+}
+
+// ReplayEvents clears the read models of the projectors, of all of them if none is given, and projects again
+// all the stored events, in the order they were stored, e.g. to rebuild the read models once their schema changed.
+// The subscriptions and the dead letters of the projectors are cleared as well,
+// while the columns of the read models written outside of the projections are restored afterwards.
+// It is meant to be run while the web server is stopped, the events collected meanwhile being not projected
+func ReplayEvents(ctx context.Context, db *gorm.DB, projectorsRegistry ProjectorRegistry, projectorIDs []string) (*ReplayResult, error) {
+	projectors, err := selectProjectors(projectorsRegistry, projectorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplayResult{}
+	for _, p := range projectors {
+		result.Projectors = append(result.Projectors, p.ID)
+	}
+
+	preserved, err := savePreservedColumns(db, projectors)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := clearProjections(db, projectors); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Read models of the projectors %v cleared, replaying the events", result.Projectors)
+
+	// the preserved columns are restored even if the replay is interrupted
+	replayErr := replayEvents(ctx, db, projectors, result)
+	if err := restorePreservedColumns(db, preserved); err != nil {
+		return nil, err
+	}
+	if replayErr != nil {
+		return nil, replayErr
+	}
+
+	return result, nil
+}
+
+func replayEvents(ctx context.Context, db *gorm.DB, projectors []*projector, result *ReplayResult) error {
+	var lastEventID int64
+	for {
+		var events []*DataCollectedEvent
+		err := db.Model(&DataCollectedEvent{}).
+			Select("data_collected_events.*").
+			Joins("LEFT JOIN agents ON agents.id = data_collected_events.agent_id").
+			Where("data_collected_events.id > ?", lastEventID).
+			Where("agents.status IS NULL OR agents.status = ?", models.AgentStatusApproved).
+			Order("data_collected_events.id").
+			Limit(backlogBatchSize).
+			Find(&events).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			break
+		}
+
+		for _, event := range events {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			for _, p := range projectors {
+				if err := p.Project(event); err != nil {
+					result.Failed++
+				}
+			}
+
+			result.Events++
+			lastEventID = event.ID
+		}
+
+		log.Infof("Replayed %d events", result.Events)
+	}
+
+	return nil
+}
+
+// selectProjectors returns the registered projectors with the given IDs, all of them if none is given
+func selectProjectors(projectorsRegistry ProjectorRegistry, projectorIDs []string) ([]*projector, error) {
+	registered := make(map[string]*projector)
+	for _, registeredProjector := range projectorsRegistry {
+		if p, ok := registeredProjector.(*projector); ok {
+			registered[p.ID] = p
+		}
+	}
+
+	for _, projectorID := range projectorIDs {
+		if _, ok := registered[projectorID]; !ok {
+			return nil, fmt.Errorf("unknown projector: %s", projectorID)
+		}
+	}
+
+	// in the order of the registry, the search index last
+	selected := []*projector{}
+	for _, registeredProjector := range projectorsRegistry {
+		p, ok := registeredProjector.(*projector)
+		if ok && (len(projectorIDs) == 0 || internal.Contains(projectorIDs, p.ID)) {
+			selected = append(selected, p)
+		}
+	}
+
+	return selected, nil
+}
+
+// clearProjections deletes the read models, the subscriptions and the dead letters of the projectors
+func clearProjections(db *gorm.DB, projectors []*projector) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, p := range projectors {
+			for _, readModel := range p.readModels {
+				if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(readModel).Error; err != nil {
+					return err
+				}
+			}
+
+			for _, projection := range []interface{}{&Subscription{}, &ProjectedDiscovery{}, &DeadLetter{}} {
+				if err := tx.Where("projector_id = ?", p.ID).Delete(projection).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+type preservedRows struct {
+	preservedColumns
+	rows []map[string]interface{}
+}
+
+// savePreservedColumns reads the preserved columns of the read models of the projectors
+func savePreservedColumns(db *gorm.DB, projectors []*projector) ([]preservedRows, error) {
+	var saved []preservedRows
+	for _, p := range projectors {
+		for _, preserved := range p.preservedColumns {
+			var rows []map[string]interface{}
+			err := db.Model(preserved.readModel).
+				Select(append([]string{preserved.key}, preserved.columns...)).
+				Find(&rows).
+				Error
+			if err != nil {
+				return nil, err
+			}
+
+			saved = append(saved, preservedRows{preservedColumns: preserved, rows: rows})
+		}
+	}
+
+	return saved, nil
+}
+
+// restorePreservedColumns writes back the preserved columns of the rows projected again by the replay
+func restorePreservedColumns(db *gorm.DB, saved []preservedRows) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, preserved := range saved {
+			for _, row := range preserved.rows {
+				values := make(map[string]interface{})
+				for _, column := range preserved.columns {
+					values[column] = row[column]
+				}
+
+				err := tx.Model(preserved.readModel).
+					Where(map[string]interface{}{preserved.key: row[preserved.key]}).
+					UpdateColumns(values).
+					Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,132 @@
+package datapipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+func TestSelectProjectors(t *testing.T) {
+	hosts := NewProjector("hosts", nil)
+	clusters := NewProjector("clusters", nil)
+	registry := ProjectorRegistry{hosts, new(MockProjector), clusters}
+
+	projectors, err := selectProjectors(registry, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*projector{hosts, clusters}, projectors)
+
+	projectors, err = selectProjectors(registry, []string{"clusters", "hosts"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*projector{hosts, clusters}, projectors)
+
+	_, err = selectProjectors(registry, []string{"hosts", "unknown"})
+	assert.EqualError(t, err, "unknown projector: unknown")
+}
+
+type ReplayTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestReplayTestSuite(t *testing.T) {
+	suite.Run(t, new(ReplayTestSuite))
+}
+
+func (suite *ReplayTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &DeadLetter{}, &DataCollectedEvent{}, &entities.Agent{}, &entities.Host{}, &entities.Cluster{})
+}
+
+func (suite *ReplayTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, DeadLetter{}, DataCollectedEvent{}, entities.Agent{}, entities.Host{}, entities.Cluster{})
+}
+
+func (suite *ReplayTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+}
+
+func (suite *ReplayTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *ReplayTestSuite) createEvent(id int64, agentID string, discoveryType string) {
+	suite.tx.Create(&DataCollectedEvent{ID: id, AgentID: agentID, DiscoveryType: discoveryType, Payload: []byte("{}")})
+}
+
+func (suite *ReplayTestSuite) TestReplayEvents() {
+	suite.createEvent(1, "agent1", HostDiscovery)
+	suite.createEvent(2, "agent2", HostDiscovery)
+	suite.createEvent(3, "agent1", HostDiscovery)
+	suite.createEvent(4, "pending", HostDiscovery)
+	suite.createEvent(5, "agent2", ClusterDiscovery)
+	suite.tx.Create(&entities.Agent{ID: "pending", Status: models.AgentStatusPending})
+
+	// stale read models and cursors
+	suite.tx.Create(&entities.Host{AgentID: "removed", Name: "stale"})
+	suite.tx.Create(&entities.Host{AgentID: "agent1", Name: "stale", CertificateFingerprint: "fingerprint1"})
+	suite.tx.Create(&entities.Cluster{ID: "cluster1", Name: "kept"})
+	suite.tx.Create(&Subscription{ProjectorID: "dummy_hosts", AgentID: "agent1", LastProjectedEventID: 3})
+	suite.tx.Create(&Subscription{ProjectorID: "dummy_clusters", AgentID: "agent2", LastProjectedEventID: 5})
+	suite.tx.Create(&DeadLetter{ProjectorID: "dummy_hosts", EventID: 1, AgentID: "agent1", DiscoveryType: HostDiscovery, Attempts: 1})
+
+	var projected []int64
+	hostsProjector := NewProjector("dummy_hosts", suite.tx)
+	hostsProjector.AddReadModels(&entities.Host{})
+	hostsProjector.PreserveColumns(&entities.Host{}, "agent_id", "certificate_fingerprint")
+	hostsProjector.AddHandler(HostDiscovery, func(event *DataCollectedEvent, db *gorm.DB) error {
+		projected = append(projected, event.ID)
+		if event.ID == 2 {
+			return errors.New("kaboom")
+		}
+		return db.Save(&entities.Host{AgentID: event.AgentID, Name: event.AgentID}).Error
+	})
+	clustersProjector := NewProjector("dummy_clusters", suite.tx)
+	clustersProjector.AddReadModels(&entities.Cluster{})
+	clustersProjector.AddHandler(ClusterDiscovery, func(event *DataCollectedEvent, _ *gorm.DB) error {
+		suite.Fail("the clusters projector is not replayed")
+		return nil
+	})
+
+	result, err := ReplayEvents(context.Background(), suite.tx, ProjectorRegistry{hostsProjector, clustersProjector}, []string{"dummy_hosts"})
+	suite.NoError(err)
+	suite.Equal(&ReplayResult{Projectors: []string{"dummy_hosts"}, Events: 4, Failed: 1}, result)
+
+	// the events of the agents pending approval are not replayed
+	suite.Equal([]int64{1, 2, 3}, projected)
+
+	var hosts []entities.Host
+	suite.tx.Order("agent_id").Find(&hosts)
+	suite.Len(hosts, 1)
+	suite.Equal("agent1", hosts[0].AgentID)
+	suite.Equal("agent1", hosts[0].Name)
+	// the fingerprint is not part of the events
+	suite.Equal("fingerprint1", hosts[0].CertificateFingerprint)
+
+	var clusters int64
+	suite.tx.Model(&entities.Cluster{}).Count(&clusters)
+	suite.Equal(int64(1), clusters)
+
+	// the subscriptions of the projectors not replayed are kept
+	var subscriptions []Subscription
+	suite.tx.Order("projector_id").Find(&subscriptions)
+	suite.Len(subscriptions, 2)
+	suite.Equal("dummy_clusters", subscriptions[0].ProjectorID)
+	suite.Equal(int64(5), subscriptions[0].LastProjectedEventID)
+	suite.Equal("dummy_hosts", subscriptions[1].ProjectorID)
+	suite.Equal(int64(3), subscriptions[1].LastProjectedEventID)
+
+	var deadLetters []DeadLetter
+	suite.tx.Find(&deadLetters)
+	suite.Len(deadLetters, 1)
+	suite.Equal(int64(2), deadLetters[0].EventID)
+	suite.Equal(1, deadLetters[0].Attempts)
+}
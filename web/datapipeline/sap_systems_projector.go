@@ -15,6 +15,7 @@ func NewSAPSystemsProjector(db *gorm.DB) *projector {
 	SAPSystemsProjector := NewProjector("sapsystems", db)
 
 	SAPSystemsProjector.AddHandler(SAPsystemDiscovery, SAPSystemsProjector_SAPSystemsDiscoveryHandler)
+	SAPSystemsProjector.AddReadModels(&entities.SAPSystemInstance{})
 
 	return SAPSystemsProjector
 }
@@ -38,6 +39,7 @@ func SAPSystemsProjector_SAPSystemsDiscoveryHandler(dataCollectedEvent *DataColl
 	for _, s := range discoveredSAPSystems {
 		var sapSystemType, dbHost, dbName, dbAddress string
 		var tenants []string
+		var globalAllocationLimitMB int64
 
 		switch s.Type {
 		case 1:
@@ -45,6 +47,7 @@ func SAPSystemsProjector_SAPSystemsDiscoveryHandler(dataCollectedEvent *DataColl
 				tenants = append(tenants, tenant.Database)
 			}
 			sapSystemType = models.SAPSystemTypeDatabase
+			globalAllocationLimitMB = s.GlobalAllocationLimitMB
 		case 2:
 			sapSystemType = models.SAPSystemTypeApplication
 			dbHost = fmt.Sprint(s.Profile["SAPDBHOST"])
@@ -61,14 +64,16 @@ func SAPSystemsProjector_SAPSystemsDiscoveryHandler(dataCollectedEvent *DataColl
 		var instances []entities.SAPSystemInstance
 		for _, i := range s.Instances {
 			instance := entities.SAPSystemInstance{
-				AgentID:   dataCollectedEvent.AgentID,
-				ID:        s.Id,
-				SID:       s.SID,
-				Type:      sapSystemType,
-				Tenants:   tenants,
-				DBHost:    dbHost,
-				DBName:    dbName,
-				DBAddress: dbAddress,
+				AgentID:                 dataCollectedEvent.AgentID,
+				ID:                      s.Id,
+				SID:                     s.SID,
+				Type:                    sapSystemType,
+				Tenants:                 tenants,
+				DBHost:                  dbHost,
+				DBName:                  dbName,
+				DBAddress:               dbAddress,
+				GlobalAllocationLimitMB: globalAllocationLimitMB,
+				Organization:            dataCollectedEvent.Organization,
 			}
 
 			var features string
@@ -102,7 +107,7 @@ func SAPSystemsProjector_SAPSystemsDiscoveryHandler(dataCollectedEvent *DataColl
 			"id", "sid", "type", "features", "instance_number",
 			"system_replication", "system_replication_status",
 			"sap_hostname", "start_priority", "http_port", "https_port", "status",
-			"tenants", "db_host", "db_name", "db_address")
+			"tenants", "db_host", "db_name", "db_address", "global_allocation_limit_mb", "organization")
 		if err != nil {
 			return err
 		}
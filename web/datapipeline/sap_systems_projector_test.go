@@ -26,11 +26,11 @@ func TestSAPSystemsProjectorTestSuite(t *testing.T) {
 func (suite *SAPSystemsProjectorTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&Subscription{}, &entities.SAPSystemInstance{})
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &entities.SAPSystemInstance{})
 }
 
 func (suite *SAPSystemsProjectorTestSuite) TearDownSuite() {
-	suite.db.Migrator().DropTable(Subscription{}, entities.SAPSystemInstance{})
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, entities.SAPSystemInstance{})
 }
 
 func (suite *SAPSystemsProjectorTestSuite) SetupTest() {
@@ -68,6 +68,7 @@ func (s *SAPSystemsProjectorTestSuite) Test_SAPSystemDiscoveryHandler_Database()
 	s.Equal("0.3", projectedSAPSystemInstance.StartPriority)
 	s.Equal(50013, projectedSAPSystemInstance.HttpPort)
 	s.Equal(50014, projectedSAPSystemInstance.HttpsPort)
+	s.EqualValues(65536, projectedSAPSystemInstance.GlobalAllocationLimitMB)
 }
 
 // Test_SAPSystemDiscoveryHandler_Database_Obsolete tests that old discovered SAP system instances
@@ -0,0 +1,203 @@
+package datapipeline
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal/cluster"
+	"github.com/trento-project/trento/internal/sapsystem"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchConfiguration is the text search configuration of the search documents and queries,
+// simple as names, SIDs and IP addresses are not words of a language to stem
+const SearchConfiguration = "simple"
+
+// searchDocumentExpression weights the name above the other terms, and the terms above the tags
+const searchDocumentExpression = "setweight(to_tsvector('" + SearchConfiguration + "', name), 'A') || " +
+	"setweight(to_tsvector('" + SearchConfiguration + "', terms), 'B') || " +
+	"setweight(to_tsvector('" + SearchConfiguration + "', COALESCE((" +
+	"SELECT string_agg(value, ' ') FROM tags " +
+	"WHERE tags.resource_type = search_documents.resource_type AND tags.resource_id = search_documents.resource_id" +
+	"), '')), 'C')"
+
+// NewSearchIndexProjector indexes the resources the other projectors store, it must be the last of the registry,
+// as the projectors of an event run in order
+func NewSearchIndexProjector(db *gorm.DB) *projector {
+	searchIndexProjector := NewProjector("search_index", db)
+
+	searchIndexProjector.AddHandler(HostDiscovery, searchIndexProjector_HostDiscoveryHandler)
+	searchIndexProjector.AddHandler(ClusterDiscovery, searchIndexProjector_ClusterDiscoveryHandler)
+	searchIndexProjector.AddHandler(SAPsystemDiscovery, searchIndexProjector_SAPSystemsDiscoveryHandler)
+	searchIndexProjector.AddReadModels(&entities.SearchDocument{})
+
+	return searchIndexProjector
+}
+
+func searchIndexProjector_HostDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+	return IndexHost(db, dataCollectedEvent.AgentID)
+}
+
+func searchIndexProjector_ClusterDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+	decoder := getPayloadDecoder(dataCollectedEvent.Payload)
+
+	var discoveredCluster cluster.Cluster
+	if err := decoder.Decode(&discoveredCluster); err != nil {
+		log.Errorf("can't decode data: %s", err)
+		return err
+	}
+
+	// the host is indexed by the name of its cluster
+	if err := IndexHost(db, dataCollectedEvent.AgentID); err != nil {
+		return err
+	}
+
+	if !discoveredCluster.DC || discoveredCluster.Id == "" {
+		return nil
+	}
+
+	return IndexCluster(db, discoveredCluster.Id)
+}
+
+func searchIndexProjector_SAPSystemsDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+	decoder := getPayloadDecoder(dataCollectedEvent.Payload)
+
+	var discoveredSAPSystems sapsystem.SAPSystemsList
+	if err := decoder.Decode(&discoveredSAPSystems); err != nil {
+		log.Errorf("can't decode data: %s", err)
+		return err
+	}
+
+	// the host is indexed by the SIDs of its instances
+	if err := IndexHost(db, dataCollectedEvent.AgentID); err != nil {
+		return err
+	}
+
+	for _, s := range discoveredSAPSystems {
+		if err := IndexSAPSystem(db, s.Id); err != nil {
+			return err
+		}
+	}
+
+	return deleteObsoleteSAPSystemDocuments(db)
+}
+
+// IndexHost indexes the host by its name, IP addresses, cluster and SIDs of the instances running on it.
+// The hosts not projected yet, e.g. of which only the SAP systems are discovered so far, are not indexed
+func IndexHost(db *gorm.DB, agentID string) error {
+	var host entities.Host
+	result := db.Where("agent_id = ?", agentID).Limit(1).Find(&host)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	var sids []string
+	err := db.Model(&entities.SAPSystemInstance{}).
+		Where("agent_id = ?", agentID).
+		Distinct().
+		Pluck("sid", &sids).
+		Error
+	if err != nil {
+		return err
+	}
+
+	terms := append([]string{host.AgentID, host.SSHAddress, host.ClusterName}, host.IPAddresses...)
+
+	return indexSearchDocument(db, models.TagHostResourceType, agentID, host.Name, append(terms, sids...)...)
+}
+
+// IndexCluster indexes the cluster by its name and the SID it manages
+func IndexCluster(db *gorm.DB, clusterID string) error {
+	var cluster entities.Cluster
+	result := db.Where("id = ?", clusterID).Limit(1).Find(&cluster)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	return indexSearchDocument(db, models.TagClusterResourceType, clusterID, cluster.Name,
+		cluster.ID, cluster.SID, cluster.ClusterType)
+}
+
+// IndexSAPSystem indexes the SAP system, or the database, by its SID, tenants and the hostnames of its instances
+func IndexSAPSystem(db *gorm.DB, id string) error {
+	var instances []entities.SAPSystemInstance
+	err := db.Where("id = ?", id).Find(&instances).Error
+	if err != nil || len(instances) == 0 {
+		return err
+	}
+
+	resourceType := models.TagSAPSystemResourceType
+	if instances[0].Type == models.SAPSystemTypeDatabase {
+		resourceType = models.TagDatabaseResourceType
+	}
+
+	terms := []string{id, instances[0].DBName}
+	for _, instance := range instances {
+		terms = append(terms, instance.SAPHostname)
+		terms = append(terms, instance.Tenants...)
+	}
+
+	return indexSearchDocument(db, resourceType, id, instances[0].SID, terms...)
+}
+
+// deleteObsoleteSAPSystemDocuments removes the documents of the SAP systems none of the instances of is left
+func deleteObsoleteSAPSystemDocuments(db *gorm.DB) error {
+	return db.
+		Where("resource_type IN ?", []string{models.TagSAPSystemResourceType, models.TagDatabaseResourceType}).
+		Where("NOT EXISTS (SELECT 1 FROM sap_system_instances WHERE sap_system_instances.id = search_documents.resource_id)").
+		Delete(&entities.SearchDocument{}).
+		Error
+}
+
+// DeleteSearchDocument removes the resource from the index
+func DeleteSearchDocument(db *gorm.DB, resourceType string, resourceID string) error {
+	return db.
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Delete(&entities.SearchDocument{}).
+		Error
+}
+
+// RefreshSearchDocument recomputes the document of the resource, e.g. once its tags changed
+func RefreshSearchDocument(db *gorm.DB, resourceType string, resourceID string) error {
+	return db.Exec("UPDATE search_documents SET document = "+searchDocumentExpression+
+		" WHERE resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Error
+}
+
+func indexSearchDocument(db *gorm.DB, resourceType string, resourceID string, name string, terms ...string) error {
+	document := entities.SearchDocument{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Name:         name,
+		Terms:        searchTerms(terms),
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "resource_type"}, {Name: "resource_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "terms", "updated_at"}),
+	}).Create(&document).Error
+	if err != nil {
+		return err
+	}
+
+	return RefreshSearchDocument(db, resourceType, resourceID)
+}
+
+// searchTerms joins the distinct non empty terms
+func searchTerms(terms []string) string {
+	seen := make(map[string]bool)
+	var distinct []string
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		distinct = append(distinct, term)
+	}
+
+	return strings.Join(distinct, " ")
+}
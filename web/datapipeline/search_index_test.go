@@ -0,0 +1,142 @@
+package datapipeline
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/cluster"
+	"github.com/trento-project/trento/internal/sapsystem"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type SearchIndexProjectorTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestSearchIndexProjectorTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchIndexProjectorTestSuite))
+}
+
+func (suite *SearchIndexProjectorTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{}, &models.Tag{}, &entities.SearchDocument{})
+}
+
+func (suite *SearchIndexProjectorTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(entities.Host{}, entities.Cluster{}, entities.SAPSystemInstance{}, models.Tag{}, entities.SearchDocument{})
+}
+
+func (suite *SearchIndexProjectorTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+
+	suite.tx.Create(&entities.Host{
+		AgentID:     "agent_id",
+		Name:        "hana01",
+		IPAddresses: []string{"10.74.1.10"},
+		ClusterName: "hana_cluster",
+	})
+	suite.tx.Create(&entities.Cluster{ID: "cluster_id", Name: "hana_cluster", SID: "PRD", ClusterType: models.ClusterTypeHANAScaleUp})
+	suite.tx.Create(&entities.SAPSystemInstance{
+		ID:             "sap_system_id",
+		AgentID:        "agent_id",
+		InstanceNumber: "00",
+		SID:            "PRD",
+		Type:           models.SAPSystemTypeDatabase,
+		SAPHostname:    "hana01",
+		Tenants:        []string{"PRD"},
+	})
+	suite.tx.Create(&models.Tag{ResourceType: models.TagHostResourceType, ResourceID: "agent_id", Value: "production"})
+}
+
+func (suite *SearchIndexProjectorTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *SearchIndexProjectorTestSuite) matches(resourceType string, resourceID string, query string) bool {
+	var count int64
+	suite.tx.Model(&entities.SearchDocument{}).
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Where("document @@ to_tsquery('simple', ?)", query).
+		Count(&count)
+
+	return count == 1
+}
+
+func (suite *SearchIndexProjectorTestSuite) TestSearchIndexProjector_HostDiscovery() {
+	err := searchIndexProjector_HostDiscoveryHandler(&DataCollectedEvent{AgentID: "agent_id", DiscoveryType: HostDiscovery}, suite.tx)
+	suite.NoError(err)
+
+	var document entities.SearchDocument
+	suite.tx.First(&document)
+	suite.Equal(models.TagHostResourceType, document.ResourceType)
+	suite.Equal("hana01", document.Name)
+	suite.Equal("agent_id hana_cluster 10.74.1.10 PRD", document.Terms)
+
+	suite.True(suite.matches(models.TagHostResourceType, "agent_id", "'hana':*"))
+	suite.True(suite.matches(models.TagHostResourceType, "agent_id", "'10.74':*"))
+	suite.True(suite.matches(models.TagHostResourceType, "agent_id", "'prd':*"))
+	suite.True(suite.matches(models.TagHostResourceType, "agent_id", "'prod':*"))
+	suite.False(suite.matches(models.TagHostResourceType, "agent_id", "'s4h':*"))
+
+	// the hosts not projected yet are not indexed
+	err = searchIndexProjector_HostDiscoveryHandler(&DataCollectedEvent{AgentID: "other_agent_id", DiscoveryType: HostDiscovery}, suite.tx)
+	suite.NoError(err)
+
+	var count int64
+	suite.tx.Model(&entities.SearchDocument{}).Count(&count)
+	suite.EqualValues(1, count)
+}
+
+func (suite *SearchIndexProjectorTestSuite) TestSearchIndexProjector_ClusterDiscovery() {
+	payload, _ := json.Marshal(&cluster.Cluster{Id: "cluster_id", Name: "hana_cluster", DC: true})
+
+	err := searchIndexProjector_ClusterDiscoveryHandler(&DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: ClusterDiscovery,
+		Payload:       payload,
+	}, suite.tx)
+	suite.NoError(err)
+
+	suite.True(suite.matches(models.TagClusterResourceType, "cluster_id", "'hana_cluster':*"))
+	suite.True(suite.matches(models.TagClusterResourceType, "cluster_id", "'prd':*"))
+	suite.True(suite.matches(models.TagHostResourceType, "agent_id", "'hana_cluster':*"))
+}
+
+func (suite *SearchIndexProjectorTestSuite) TestSearchIndexProjector_SAPSystemsDiscovery() {
+	payload, _ := json.Marshal(sapsystem.SAPSystemsList{&sapsystem.SAPSystem{Id: "sap_system_id", SID: "PRD"}})
+	event := &DataCollectedEvent{AgentID: "agent_id", DiscoveryType: SAPsystemDiscovery, Payload: payload}
+
+	suite.NoError(searchIndexProjector_SAPSystemsDiscoveryHandler(event, suite.tx))
+
+	suite.True(suite.matches(models.TagDatabaseResourceType, "sap_system_id", "'prd':*"))
+	suite.True(suite.matches(models.TagHostResourceType, "agent_id", "'prd':*"))
+
+	// the SAP systems no longer discovered are removed from the index
+	suite.tx.Where("id = ?", "sap_system_id").Delete(&entities.SAPSystemInstance{})
+	payload, _ = json.Marshal(sapsystem.SAPSystemsList{})
+	event.Payload = payload
+
+	suite.NoError(searchIndexProjector_SAPSystemsDiscoveryHandler(event, suite.tx))
+
+	var count int64
+	suite.tx.Model(&entities.SearchDocument{}).Where("resource_type = ?", models.TagDatabaseResourceType).Count(&count)
+	suite.EqualValues(0, count)
+	suite.False(suite.matches(models.TagHostResourceType, "agent_id", "'prd':*"))
+}
+
+func (suite *SearchIndexProjectorTestSuite) TestRefreshSearchDocument() {
+	suite.NoError(IndexHost(suite.tx, "agent_id"))
+	suite.False(suite.matches(models.TagHostResourceType, "agent_id", "'sap_prod':*"))
+
+	suite.tx.Create(&models.Tag{ResourceType: models.TagHostResourceType, ResourceID: "agent_id", Value: "sap_prod"})
+	suite.NoError(RefreshSearchDocument(suite.tx, models.TagHostResourceType, "agent_id"))
+
+	suite.True(suite.matches(models.TagHostResourceType, "agent_id", "'sap_prod':*"))
+}
@@ -12,6 +12,7 @@ func NewSlesSubscriptionsProjector(db *gorm.DB) *projector {
 	subsProjector := NewProjector("sles_subscriptions", db)
 
 	subsProjector.AddHandler(SubscriptionDiscovery, subsProjector_SubscriptionDiscoveryHandler)
+	subsProjector.AddReadModels(&entities.SlesSubscription{})
 
 	return subsProjector
 }
@@ -28,11 +28,11 @@ func TestSlesSubscriptionsProjectorTestSuite(t *testing.T) {
 func (suite *SlesSubscriptionsProjectorTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&Subscription{}, &entities.SlesSubscription{})
+	suite.db.AutoMigrate(&Subscription{}, &ProjectedDiscovery{}, &entities.SlesSubscription{})
 }
 
 func (suite *SlesSubscriptionsProjectorTestSuite) TearDownSuite() {
-	suite.db.Migrator().DropTable(Subscription{}, entities.SlesSubscription{})
+	suite.db.Migrator().DropTable(Subscription{}, ProjectedDiscovery{}, entities.SlesSubscription{})
 }
 
 func (suite *SlesSubscriptionsProjectorTestSuite) SetupTest() {
@@ -9,3 +9,14 @@ type Subscription struct {
 	ProjectorID          string `gorm:"primaryKey"`
 	UpdatedAt            time.Time
 }
+
+// ProjectedDiscovery is the progress of a projector on a discovery type of an agent: the last event projected,
+// which tells the events it still has to project, and when the last discovery projected was collected,
+// the older discoveries, e.g. backfilled, are not projected over it
+type ProjectedDiscovery struct {
+	ProjectorID          string `gorm:"primaryKey"`
+	AgentID              string `gorm:"primaryKey"`
+	DiscoveryType        string `gorm:"primaryKey"`
+	CollectedAt          time.Time
+	LastProjectedEventID int64
+}
@@ -2,8 +2,11 @@ package datapipeline
 
 import (
 	"context"
+	"math"
+	"math/rand"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sync/semaphore"
 )
@@ -12,15 +15,53 @@ import (
 var workersNumber int64 = 100
 var drainTimeout = time.Second * 5
 
+// ProjectorsQueueSize is the number of events waiting for a worker, the collector blocking beyond
+const ProjectorsQueueSize = 1000
+
+const (
+	DefaultRetryAttempts   = 3
+	DefaultRetryBackoff    = 100 * time.Millisecond
+	DefaultRetryMaxBackoff = 5 * time.Second
+)
+
+// RetryPolicy of the projections failing in the worker pool, e.g. because of transient database errors.
+// The backoff doubles at every retry up to MaxBackoff, with a random jitter of up to half of it.
+// The events are recorded as dead letters once the retries are exhausted
+type RetryPolicy struct {
+	// Attempts after the first one, no retry if 0
+	Attempts int
+	Backoff  time.Duration
+	// No cap if 0, but the one of time.Duration
+	MaxBackoff time.Duration
+}
+
+// ProjectionRetries counts the retries of the projections failing in the worker pool, by discovery type
+var ProjectionRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "trento",
+	Subsystem: "projectors",
+	Name:      "retries_total",
+	Help:      "Retries of the projections failing in the projectors worker pool, by discovery type.",
+}, []string{"discovery_type"})
+
+// ExhaustedProjectionRetries counts the projections recorded as dead letters once their retries are exhausted
+var ExhaustedProjectionRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "trento",
+	Subsystem: "projectors",
+	Name:      "retries_exhausted_total",
+	Help:      "Projections recorded as dead letters once their retries are exhausted, by discovery type.",
+}, []string{"discovery_type"})
+
 type ProjectorsWorkerPool struct {
 	ch                 chan *DataCollectedEvent
 	projectorsRegistry ProjectorRegistry
+	retryPolicy        RetryPolicy
 }
 
-func NewProjectorsWorkerPool(projectorsRegistry ProjectorRegistry) *ProjectorsWorkerPool {
+func NewProjectorsWorkerPool(projectorsRegistry ProjectorRegistry, retryPolicy RetryPolicy) *ProjectorsWorkerPool {
 	return &ProjectorsWorkerPool{
 		projectorsRegistry: projectorsRegistry,
-		ch:                 make(chan *DataCollectedEvent),
+		ch:                 make(chan *DataCollectedEvent, ProjectorsQueueSize),
+		retryPolicy:        retryPolicy,
 	}
 }
 
@@ -41,7 +82,7 @@ func (p *ProjectorsWorkerPool) Run(ctx context.Context) {
 			go func() {
 				defer sem.Release(1)
 				for _, projector := range p.projectorsRegistry {
-					projector.Project(event)
+					p.project(ctx, projector, event)
 				}
 			}()
 		case <-ctx.Done():
@@ -59,7 +100,62 @@ func (p *ProjectorsWorkerPool) Run(ctx context.Context) {
 	}
 }
 
+// project retries the failing projections with a backoff, recording them as dead letters once the retries
+// are exhausted or the pool is shutting down
+func (p *ProjectorsWorkerPool) project(ctx context.Context, projector Projector, event *DataCollectedEvent) {
+	err := TryProjectEvent(projector, event)
+
+	for attempt := 1; err != nil && attempt <= p.retryPolicy.Attempts; attempt++ {
+		backoff := p.retryPolicy.backoff(attempt)
+		log.Warnf("Projection of the event: %d failed, retrying in %s (%d/%d): %s", event.ID, backoff, attempt, p.retryPolicy.Attempts, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			DeadLetterEvent(projector, event, err)
+			return
+		}
+
+		ProjectionRetries.WithLabelValues(event.DiscoveryType).Inc()
+		err = TryProjectEvent(projector, event)
+	}
+
+	if err != nil {
+		if p.retryPolicy.Attempts > 0 {
+			ExhaustedProjectionRetries.WithLabelValues(event.DiscoveryType).Inc()
+		}
+		DeadLetterEvent(projector, event, err)
+	}
+}
+
+// backoff before the attempt, starting from 1
+func (r RetryPolicy) backoff(attempt int) time.Duration {
+	// the uncapped backoff stops doubling before overflowing
+	ceiling := r.MaxBackoff
+	if ceiling == 0 {
+		ceiling = math.MaxInt64 / 2
+	}
+
+	backoff := r.Backoff
+	for i := 1; i < attempt && backoff < ceiling; i++ {
+		backoff *= 2
+	}
+	if r.MaxBackoff > 0 && backoff > r.MaxBackoff {
+		backoff = r.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
 // GetChannel returns the channel used by the worker pool
 func (p *ProjectorsWorkerPool) GetChannel() chan *DataCollectedEvent {
 	return p.ch
 }
+
+// QueueDepth returns the number of events waiting for a worker
+func (p *ProjectorsWorkerPool) QueueDepth() int {
+	return len(p.ch)
+}
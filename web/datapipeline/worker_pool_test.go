@@ -2,10 +2,12 @@ package datapipeline
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -27,7 +29,7 @@ func TestProjectorWorkersPool(t *testing.T) {
 		projector,
 	}
 
-	projectorsWorkersPool := NewProjectorsWorkerPool(projectorRegistry)
+	projectorsWorkersPool := NewProjectorsWorkerPool(projectorRegistry, RetryPolicy{})
 	ctx, cancel := context.WithCancel(context.Background())
 	go projectorsWorkersPool.Run(ctx)
 
@@ -56,7 +58,7 @@ func TestProjectorWorkersPool_BoundedParallelism(t *testing.T) {
 		projector,
 	}
 
-	projectorsWorkersPool := NewProjectorsWorkerPool(projectorRegistry)
+	projectorsWorkersPool := NewProjectorsWorkerPool(projectorRegistry, RetryPolicy{})
 	ctx, cancel := context.WithCancel(context.Background())
 	go projectorsWorkersPool.Run(ctx)
 
@@ -102,7 +104,7 @@ func TestProjectorWorkersPool_Drain(t *testing.T) {
 		projector,
 	}
 
-	projectorsWorkersPool := NewProjectorsWorkerPool(projectorRegistry)
+	projectorsWorkersPool := NewProjectorsWorkerPool(projectorRegistry, RetryPolicy{})
 
 	ctx, cancel := context.WithCancel(context.Background())
 	go projectorsWorkersPool.Run(ctx)
@@ -122,3 +124,140 @@ func TestProjectorWorkersPool_Drain(t *testing.T) {
 	assert.True(t, done1)
 	assert.True(t, done2)
 }
+
+// TestProjectorWorkersPool_QueueDepth tests that the events wait in the queue until a worker picks them up.
+func TestProjectorWorkersPool_QueueDepth(t *testing.T) {
+	projectorsWorkersPool := NewProjectorsWorkerPool([]Projector{}, RetryPolicy{})
+
+	ch := projectorsWorkersPool.GetChannel()
+	ch <- &DataCollectedEvent{}
+	ch <- &DataCollectedEvent{}
+
+	assert.Equal(t, 2, projectorsWorkersPool.QueueDepth())
+	assert.Equal(t, ProjectorsQueueSize, cap(ch))
+}
+
+// flakyProjector fails the first projections of every event
+type flakyProjector struct {
+	mutex       sync.Mutex
+	failures    int
+	attempts    map[int64]int
+	deadLetters map[int64]error
+	done        chan struct{}
+}
+
+func newFlakyProjector(failures int) *flakyProjector {
+	return &flakyProjector{
+		failures:    failures,
+		attempts:    map[int64]int{},
+		deadLetters: map[int64]error{},
+		done:        make(chan struct{}, 10),
+	}
+}
+
+func (p *flakyProjector) Project(event *DataCollectedEvent) error {
+	err := p.TryProject(event)
+	if err != nil {
+		p.DeadLetter(event, err)
+	}
+
+	return err
+}
+
+func (p *flakyProjector) TryProject(event *DataCollectedEvent) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.attempts[event.ID]++
+	if p.attempts[event.ID] <= p.failures {
+		return errors.New("connection reset by peer")
+	}
+
+	p.done <- struct{}{}
+	return nil
+}
+
+func (p *flakyProjector) DeadLetter(event *DataCollectedEvent, projectionErr error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.deadLetters[event.ID] = projectionErr
+	p.done <- struct{}{}
+}
+
+// TestProjectorWorkersPool_Retry tests that the failing projections are retried, and recorded as dead letters
+// only once the retries are exhausted
+func TestProjectorWorkersPool_Retry(t *testing.T) {
+	workersNumber = 2
+	retryPolicy := RetryPolicy{Attempts: 2, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	recovered := newFlakyProjector(2)
+	exhausted := newFlakyProjector(3)
+
+	retries := testutil.ToFloat64(ProjectionRetries.WithLabelValues("retry_discovery"))
+	exhaustedRetries := testutil.ToFloat64(ExhaustedProjectionRetries.WithLabelValues("retry_discovery"))
+
+	projectorsWorkersPool := NewProjectorsWorkerPool([]Projector{recovered, exhausted}, retryPolicy)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go projectorsWorkersPool.Run(ctx)
+
+	projectorsWorkersPool.GetChannel() <- &DataCollectedEvent{ID: 1, DiscoveryType: "retry_discovery"}
+
+	<-recovered.done
+	<-exhausted.done
+
+	assert.Equal(t, 3, recovered.attempts[1])
+	assert.Empty(t, recovered.deadLetters)
+	assert.Equal(t, 3, exhausted.attempts[1])
+	assert.EqualError(t, exhausted.deadLetters[1], "connection reset by peer")
+
+	assert.Equal(t, retries+4, testutil.ToFloat64(ProjectionRetries.WithLabelValues("retry_discovery")))
+	assert.Equal(t, exhaustedRetries+1, testutil.ToFloat64(ExhaustedProjectionRetries.WithLabelValues("retry_discovery")))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	retryPolicy := RetryPolicy{Attempts: 5, Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for attempt, expected := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+	} {
+		backoff := retryPolicy.backoff(attempt)
+		assert.GreaterOrEqual(t, backoff, expected/2)
+		assert.LessOrEqual(t, backoff, expected)
+	}
+
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.backoff(1))
+}
+
+func TestRetryPolicyBackoffUncapped(t *testing.T) {
+	retryPolicy := RetryPolicy{Attempts: 5, Backoff: 100 * time.Millisecond}
+
+	for attempt, expected := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		5: 1600 * time.Millisecond,
+	} {
+		backoff := retryPolicy.backoff(attempt)
+		assert.GreaterOrEqual(t, backoff, expected/2)
+		assert.LessOrEqual(t, backoff, expected)
+	}
+}
+
+func TestRetryPolicyBackoffHighAttempt(t *testing.T) {
+	for _, retryPolicy := range []RetryPolicy{
+		{Attempts: 1000, Backoff: 100 * time.Millisecond},
+		{Attempts: 1000, Backoff: 100 * time.Millisecond, MaxBackoff: time.Minute},
+	} {
+		backoff := retryPolicy.backoff(1000)
+		assert.Greater(t, backoff, time.Duration(0))
+		if retryPolicy.MaxBackoff > 0 {
+			assert.LessOrEqual(t, backoff, retryPolicy.MaxBackoff)
+		}
+	}
+}
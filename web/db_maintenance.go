@@ -0,0 +1,152 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+var dbMaintenanceInterval = 6 * time.Hour
+
+// DBMaintenanceAdvisor periodically inspects the Trento database and, if enabled,
+// runs the safe maintenance operations it recommends
+type DBMaintenanceAdvisor struct {
+	dbMaintenanceService services.DBMaintenanceService
+	autoVacuum           bool
+}
+
+func NewDBMaintenanceAdvisor(dbMaintenanceService services.DBMaintenanceService, autoVacuum bool) *DBMaintenanceAdvisor {
+	return &DBMaintenanceAdvisor{
+		dbMaintenanceService: dbMaintenanceService,
+		autoVacuum:           autoVacuum,
+	}
+}
+
+func (a *DBMaintenanceAdvisor) Start(ctx context.Context) {
+	log.Infof("Starting database maintenance advisor")
+
+	internal.Repeat("web.db_maintenance_advisor", a.inspect, dbMaintenanceInterval, ctx)
+}
+
+func (a *DBMaintenanceAdvisor) inspect() {
+	report, err := a.dbMaintenanceService.Inspect()
+	if err != nil {
+		log.Errorf("Error while inspecting the database: %s", err)
+		return
+	}
+
+	log.Debugf("Database inspected, %d recommendations found", len(report.Recommendations))
+
+	if !a.autoVacuum {
+		return
+	}
+
+	for _, recommendation := range report.Recommendations {
+		if !recommendation.Runnable {
+			continue
+		}
+
+		log.Infof("Running database maintenance: %s", recommendation.Action)
+		if err := a.dbMaintenanceService.RunMaintenance(recommendation.Target); err != nil {
+			log.Errorf("Error while running database maintenance on %s: %s", recommendation.Target, err)
+		}
+	}
+}
+
+func NewDBMaintenanceHandler(dbMaintenanceService services.DBMaintenanceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := dbMaintenanceService.GetLatestReport()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.HTML(http.StatusOK, "db_maintenance.html.tmpl", gin.H{
+			"Report": report,
+		})
+	}
+}
+
+// ApiGetDBMaintenanceReportHandler godoc
+// @Summary Retrieve the latest maintenance recommendations for the Trento database
+// @Produce json
+// @Success 200 {object} models.DBMaintenanceReport
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /database/maintenance [get]
+func ApiGetDBMaintenanceReportHandler(dbMaintenanceService services.DBMaintenanceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := dbMaintenanceService.GetLatestReport()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if report == nil {
+			_ = c.Error(NotFoundError("the database has not been inspected yet"))
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// ApiInspectDBHandler godoc
+// @Summary Inspect the Trento database and refresh the maintenance recommendations
+// @Produce json
+// @Success 200 {object} models.DBMaintenanceReport
+// @Failure 500 {object} map[string]string
+// @Router /database/maintenance/inspect [post]
+func ApiInspectDBHandler(dbMaintenanceService services.DBMaintenanceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := dbMaintenanceService.Inspect()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// ApiRunDBMaintenanceHandler godoc
+// @Summary Run the recommended maintenance on a table of the Trento database
+// @Param table path string true "Table name"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /database/maintenance/tables/{table} [post]
+func ApiRunDBMaintenanceHandler(dbMaintenanceService services.DBMaintenanceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		table := c.Param("table")
+
+		report, err := dbMaintenanceService.GetLatestReport()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		var recommendation *models.DBRecommendation
+		if report != nil {
+			recommendation = report.GetRunnableRecommendation(table)
+		}
+		if recommendation == nil {
+			_ = c.Error(NotFoundError("no maintenance recommended for this table"))
+			return
+		}
+
+		if err := dbMaintenanceService.RunMaintenance(table); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
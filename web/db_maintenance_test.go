@@ -0,0 +1,246 @@
+package web
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/html"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func dbMaintenanceReportFixture() *models.DBMaintenanceReport {
+	return &models.DBMaintenanceReport{
+		InspectedAt: time.Date(2022, time.January, 20, 10, 0, 0, 0, time.UTC),
+		Recommendations: []*models.DBRecommendation{
+			{
+				Kind:     models.DBRecommendationBloatedTable,
+				Target:   "hosts",
+				Message:  "The table has 20000 dead rows out of 30000 live ones, it has never been vacuumed",
+				Action:   `VACUUM ANALYZE "hosts"`,
+				Runnable: true,
+			},
+			{
+				Kind:    models.DBRecommendationUnusedIndex,
+				Target:  "idx_hosts_name",
+				Message: "The index on table hosts has never been used and takes 2048 kB",
+				Action:  `DROP INDEX "idx_hosts_name"`,
+			},
+		},
+	}
+}
+
+func TestDBMaintenanceAdvisor(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("Inspect").Return(dbMaintenanceReportFixture(), nil)
+	dbMaintenanceService.On("RunMaintenance", "hosts").Return(nil)
+
+	NewDBMaintenanceAdvisor(dbMaintenanceService, true).inspect()
+
+	dbMaintenanceService.AssertExpectations(t)
+	dbMaintenanceService.AssertNumberOfCalls(t, "RunMaintenance", 1)
+}
+
+func TestDBMaintenanceAdvisorWithoutAutoVacuum(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("Inspect").Return(dbMaintenanceReportFixture(), nil)
+
+	NewDBMaintenanceAdvisor(dbMaintenanceService, false).inspect()
+
+	dbMaintenanceService.AssertExpectations(t)
+	dbMaintenanceService.AssertNotCalled(t, "RunMaintenance", "hosts")
+}
+
+func TestDBMaintenanceHandler(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("GetLatestReport").Return(dbMaintenanceReportFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.dbMaintenanceService = dbMaintenanceService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/database", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, minified, "Last inspected at Jan 20, 2022 10:00:00 UTC")
+	assert.Regexp(t, regexp.MustCompile(`<td>bloated_table</td><td>hosts</td>.*<td><code>VACUUM ANALYZE "hosts"</code></td><td><button .*data-table=hosts>Run</button></td>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>unused_index</td><td>idx_hosts_name</td>.*<td><code>DROP INDEX "idx_hosts_name"</code></td><td></td>`), minified)
+}
+
+func TestDBMaintenanceHandlerNotInspected(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("GetLatestReport").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.dbMaintenanceService = dbMaintenanceService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/database", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), "The database has not been inspected yet")
+}
+
+func TestApiGetDBMaintenanceReportHandler(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("GetLatestReport").Return(dbMaintenanceReportFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.dbMaintenanceService = dbMaintenanceService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/database/maintenance", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"inspected_at": "2022-01-20T10:00:00Z",
+		"recommendations": [
+			{"kind": "bloated_table", "target": "hosts", "message": "The table has 20000 dead rows out of 30000 live ones, it has never been vacuumed", "action": "VACUUM ANALYZE \"hosts\"", "runnable": true},
+			{"kind": "unused_index", "target": "idx_hosts_name", "message": "The index on table hosts has never been used and takes 2048 kB", "action": "DROP INDEX \"idx_hosts_name\"", "runnable": false}
+		]
+	}`, resp.Body.String())
+}
+
+func TestApiGetDBMaintenanceReportHandlerNotInspected(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("GetLatestReport").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.dbMaintenanceService = dbMaintenanceService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/database/maintenance", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiInspectDBHandler(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("Inspect").Return(dbMaintenanceReportFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.dbMaintenanceService = dbMaintenanceService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/database/maintenance/inspect", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	dbMaintenanceService.AssertExpectations(t)
+}
+
+func TestApiRunDBMaintenanceHandler(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("GetLatestReport").Return(dbMaintenanceReportFixture(), nil)
+	dbMaintenanceService.On("RunMaintenance", "hosts").Return(nil)
+
+	deps := setupTestDependencies()
+	deps.dbMaintenanceService = dbMaintenanceService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/database/maintenance/tables/hosts", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	dbMaintenanceService.AssertExpectations(t)
+}
+
+func TestApiRunDBMaintenanceHandlerNotRecommended(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("GetLatestReport").Return(dbMaintenanceReportFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.dbMaintenanceService = dbMaintenanceService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/database/maintenance/tables/idx_hosts_name", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+	dbMaintenanceService.AssertNotCalled(t, "RunMaintenance", "idx_hosts_name")
+}
+
+func TestDBMaintenanceHandlerViewer(t *testing.T) {
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("GetLatestReport").Return(dbMaintenanceReportFixture(), nil)
+
+	deps := setupTestDependencies()
+	deps.dbMaintenanceService = dbMaintenanceService
+	deps.usersService = newMockedUsersService(models.UserRoleViewer)
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/database", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	body := resp.Body.String()
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, body, `<meta name="user-permissions" content="">`)
+	assert.Contains(t, body, "bloated_table")
+	assert.NotContains(t, body, "data-table=")
+	assert.NotContains(t, body, `href="/audit"`)
+	assert.NotContains(t, body, `href="/pipeline"`)
+}
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
+)
+
+type AddressConflict struct {
+	Kind       string `gorm:"primaryKey"`
+	Address    string `gorm:"primaryKey"`
+	Owners     datatypes.JSON
+	DetectedAt time.Time
+}
+
+func (c *AddressConflict) ToModel() (*models.AddressConflict, error) {
+	var owners []*models.AddressConflictOwner
+	if err := json.Unmarshal(c.Owners, &owners); err != nil {
+		return nil, err
+	}
+
+	return &models.AddressConflict{
+		Kind:       c.Kind,
+		Address:    c.Address,
+		Owners:     owners,
+		DetectedAt: c.DetectedAt,
+	}, nil
+}
@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// Agent records the approval of the agents, the data of the agents not approved yet is not projected
+type Agent struct {
+	ID         string `gorm:"primaryKey"`
+	Status     string `gorm:"index;not null"`
+	CreatedAt  time.Time
+	ReviewedAt *time.Time
+	ReviewedBy string
+}
+
+func (a *Agent) ToModel() *models.Agent {
+	return &models.Agent{
+		ID:         a.ID,
+		Status:     a.Status,
+		EnrolledAt: a.CreatedAt,
+		ReviewedAt: a.ReviewedAt,
+		ReviewedBy: a.ReviewedBy,
+	}
+}
@@ -0,0 +1,10 @@
+package entities
+
+import "time"
+
+// AgentEnrollment binds an agent ID to the secret issued at its first enrollment, only its hash is stored
+type AgentEnrollment struct {
+	AgentID    string `gorm:"primaryKey"`
+	SecretHash string `gorm:"not null"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/trento-project/trento/web/models"
+)
+
+// AgentInfo is the registration the agent reported when it last started
+type AgentInfo struct {
+	AgentID       string `gorm:"primaryKey"`
+	Version       string
+	Discoveries   pq.StringArray `gorm:"type:text[]"`
+	OSName        string
+	OSVersion     string
+	KernelVersion string
+	Arch          string
+	UpdatedAt     time.Time
+}
+
+func (a *AgentInfo) ToModel() *models.AgentInfo {
+	return &models.AgentInfo{
+		Version:       a.Version,
+		Discoveries:   a.Discoveries,
+		OSName:        a.OSName,
+		OSVersion:     a.OSVersion,
+		KernelVersion: a.KernelVersion,
+		Arch:          a.Arch,
+		RegisteredAt:  a.UpdatedAt,
+	}
+}
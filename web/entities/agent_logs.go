@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// AgentLogs is the latest request of the logs of an agent, with the excerpt it shipped in response
+type AgentLogs struct {
+	AgentID     string `gorm:"primaryKey"`
+	RequestedAt time.Time
+	RequestedBy string
+	ReceivedAt  *time.Time
+	Truncated   bool
+	Logs        []byte
+}
+
+func (l *AgentLogs) ToModel() *models.AgentLogs {
+	return &models.AgentLogs{
+		AgentID:     l.AgentID,
+		RequestedAt: l.RequestedAt,
+		RequestedBy: l.RequestedBy,
+		ReceivedAt:  l.ReceivedAt,
+		Truncated:   l.Truncated,
+		Logs:        string(l.Logs),
+	}
+}
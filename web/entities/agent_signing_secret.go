@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// AgentSigningSecret is encrypted when a credentials key is configured, it must be read back to verify the signatures
+type AgentSigningSecret struct {
+	AgentID   string `gorm:"primaryKey"`
+	Secret    string `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+func (s *AgentSigningSecret) ToModel() *models.AgentSigningSecret {
+	return &models.AgentSigningSecret{
+		AgentID:   s.AgentID,
+		CreatedAt: s.CreatedAt,
+	}
+}
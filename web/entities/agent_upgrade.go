@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// AgentVersion is a release of the agent the hosts can be upgraded to
+type AgentVersion struct {
+	Version   string `gorm:"primaryKey"`
+	URL       string
+	Checksum  string
+	CreatedAt time.Time
+	CreatedBy string
+}
+
+func (v *AgentVersion) ToModel() *models.AgentVersion {
+	return &models.AgentVersion{
+		Version:   v.Version,
+		URL:       v.URL,
+		Checksum:  v.Checksum,
+		CreatedAt: v.CreatedAt,
+		CreatedBy: v.CreatedBy,
+	}
+}
+
+// AgentUpgrade is the latest upgrade requested to an agent, delivered over its channel
+type AgentUpgrade struct {
+	AgentID string `gorm:"primaryKey"`
+	Version string
+	Status  string `gorm:"index"`
+	// MessageID of the upgrade sent to the agent, the agent acknowledges
+	MessageID   string `gorm:"index"`
+	Error       string
+	RequestedAt time.Time
+	RequestedBy string
+	UpdatedAt   time.Time
+}
+
+func (u *AgentUpgrade) ToModel() *models.AgentUpgrade {
+	return &models.AgentUpgrade{
+		AgentID:     u.AgentID,
+		Version:     u.Version,
+		Status:      u.Status,
+		Error:       u.Error,
+		RequestedAt: u.RequestedAt,
+		RequestedBy: u.RequestedBy,
+		UpdatedAt:   u.UpdatedAt,
+	}
+}
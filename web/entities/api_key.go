@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type ApiKey struct {
+	ID     int64  `gorm:"primaryKey"`
+	Name   string `gorm:"not null"`
+	Prefix string `gorm:"not null"`
+	// Only the SHA-256 hash of the key is stored
+	KeyHash      string `gorm:"uniqueIndex;not null"`
+	Scope        string `gorm:"not null"`
+	Organization string
+	CreatedAt    time.Time
+	LastUsedAt   *time.Time
+	RevokedAt    *time.Time
+}
+
+func (k *ApiKey) ToModel() *models.ApiKey {
+	return &models.ApiKey{
+		ID:           k.ID,
+		Name:         k.Name,
+		Prefix:       k.Prefix,
+		Scope:        k.Scope,
+		Organization: k.Organization,
+		CreatedAt:    k.CreatedAt,
+		LastUsedAt:   k.LastUsedAt,
+		RevokedAt:    k.RevokedAt,
+	}
+}
@@ -0,0 +1,69 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type AuditEntry struct {
+	ID               int64     `gorm:"primaryKey"`
+	CreatedAt        time.Time `gorm:"index"`
+	Actor            string    `gorm:"index"`
+	ImpersonatedUser string
+	Action           string
+	ResourceType     string `gorm:"index:idx_audit_entries_resource"`
+	ResourceID       string `gorm:"index:idx_audit_entries_resource"`
+	Before           datatypes.JSON
+	After            datatypes.JSON
+	Organization     string `gorm:"index"`
+}
+
+func NewAuditEntry(entry *models.AuditEntry) (*AuditEntry, error) {
+	before, err := json.Marshal(entry.Before)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := json.Marshal(entry.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditEntry{
+		Actor:            entry.Actor,
+		ImpersonatedUser: entry.ImpersonatedUser,
+		Action:           entry.Action,
+		ResourceType:     entry.ResourceType,
+		ResourceID:       entry.ResourceID,
+		Before:           datatypes.JSON(before),
+		After:            datatypes.JSON(after),
+		Organization:     entry.Organization,
+	}, nil
+}
+
+func (e *AuditEntry) ToModel() (*models.AuditEntry, error) {
+	entry := &models.AuditEntry{
+		ID:               e.ID,
+		Time:             e.CreatedAt,
+		Actor:            e.Actor,
+		ImpersonatedUser: e.ImpersonatedUser,
+		Action:           e.Action,
+		ResourceType:     e.ResourceType,
+		ResourceID:       e.ResourceID,
+		Organization:     e.Organization,
+	}
+
+	if err := json.Unmarshal(e.Before, &entry.Before); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(e.After, &entry.After); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type HostBaseline struct {
+	Role      string `gorm:"primaryKey"`
+	AgentID   string `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+func (b *HostBaseline) ToModel() *models.HostBaseline {
+	return &models.HostBaseline{
+		Role:      b.Role,
+		AgentID:   b.AgentID,
+		UpdatedAt: b.UpdatedAt,
+	}
+}
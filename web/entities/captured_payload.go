@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type CapturedPayload struct {
+	ID        int64     `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
+	AgentID   string    `gorm:"index"`
+	Size      int
+	Truncated bool
+	Body      []byte
+}
+
+func (p *CapturedPayload) ToModel() *models.CapturedPayload {
+	return &models.CapturedPayload{
+		ID:        p.ID,
+		AgentID:   p.AgentID,
+		CreatedAt: p.CreatedAt,
+		Size:      p.Size,
+		Truncated: p.Truncated,
+		Body:      string(p.Body),
+	}
+}
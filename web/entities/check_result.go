@@ -18,6 +18,7 @@ type ChecksResult struct {
 func (c *ChecksResult) ToModel() (*models.ChecksResult, error) {
 	var checkResult models.ChecksResult
 	checkResult.ID = c.GroupID
+	checkResult.CreatedAt = c.CreatedAt
 	err := json.Unmarshal(c.Payload, &checkResult)
 
 	return &checkResult, err
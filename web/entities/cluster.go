@@ -19,6 +19,11 @@ type Cluster struct {
 	UpdatedAt       time.Time
 	Hosts           []*Host        `gorm:"foreignkey:cluster_id"`
 	Details         datatypes.JSON `json:"payload" binding:"required"`
+	// Provisioning metadata stored in the pacemaker cluster properties
+	ProvisioningTool            string
+	ProvisioningTemplateVersion string
+	// Organization of the agent of the designated controller
+	Organization string `gorm:"index"`
 }
 
 type HANAClusterDetails struct {
@@ -76,6 +81,7 @@ func (c *Cluster) ToModel() *models.Cluster {
 		HostsNumber:     c.HostsNumber,
 		Health:          health,
 		Tags:            tags,
+		Provisioning:    provisioningToModel(c.ProvisioningTool, c.ProvisioningTemplateVersion),
 	}
 }
 
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
+)
+
+type DBMaintenanceReport struct {
+	ID              int64 `gorm:"primaryKey;autoIncrement"`
+	Recommendations datatypes.JSON
+	CreatedAt       time.Time
+}
+
+func (r *DBMaintenanceReport) ToModel() (*models.DBMaintenanceReport, error) {
+	var recommendations []*models.DBRecommendation
+	if err := json.Unmarshal(r.Recommendations, &recommendations); err != nil {
+		return nil, err
+	}
+
+	return &models.DBMaintenanceReport{
+		InspectedAt:     r.CreatedAt,
+		Recommendations: recommendations,
+	}, nil
+}
@@ -0,0 +1,10 @@
+package entities
+
+import "time"
+
+type Favorite struct {
+	UserID       string `gorm:"primaryKey"`
+	ResourceType string `gorm:"primaryKey"`
+	ResourceID   string `gorm:"primaryKey"`
+	CreatedAt    time.Time
+}
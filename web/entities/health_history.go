@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// HealthHistoryRecord is written every time the health of a resource changes
+type HealthHistoryRecord struct {
+	ID           int64     `gorm:"primaryKey"`
+	ResourceType string    `gorm:"index:idx_health_history_resource_recorded_at;not null"`
+	ResourceID   string    `gorm:"index:idx_health_history_resource_recorded_at;not null"`
+	Health       string    `gorm:"not null"`
+	RecordedAt   time.Time `gorm:"index:idx_health_history_resource_recorded_at;not null"`
+}
+
+func (r *HealthHistoryRecord) ToModel() *models.HealthRecord {
+	return &models.HealthRecord{
+		ResourceType: r.ResourceType,
+		ResourceID:   r.ResourceID,
+		Health:       r.Health,
+		Since:        r.RecordedAt,
+	}
+}
@@ -19,16 +19,57 @@ type Host struct {
 	ClusterType        string
 	SAPSystemInstances SAPSystemInstances `gorm:"foreignkey:AgentID"`
 	AgentVersion       string
-	Heartbeat          *HostHeartbeat    `gorm:"foreignKey:AgentID"`
-	Subscription       *SlesSubscription `gorm:"foreignKey:AgentID"`
-	Tags               []*models.Tag     `gorm:"polymorphic:Resource;polymorphicValue:hosts"`
-	UpdatedAt          time.Time
-	CloudData          datatypes.JSON
+	CPUCount           int
+	SocketCount        int
+	CoreCount          int
+	TotalMemoryMB      int
+	Hypervisor         string
+	Ephemeral          bool
+	AgentProfile       string
+	// Provisioning metadata reported by the agent
+	ProvisioningTool            string
+	ProvisioningTemplateVersion string
+	// Organization the host belongs to, as reported by its agent, visible to the users of the organization only
+	Organization string `gorm:"index"`
+	// CertificateFingerprint is the SHA-256 fingerprint of the mTLS client certificate the agent last authenticated with
+	CertificateFingerprint string
+	Heartbeat              *HostHeartbeat    `gorm:"foreignKey:AgentID"`
+	Subscription           *SlesSubscription `gorm:"foreignKey:AgentID"`
+	AgentInfo              *AgentInfo        `gorm:"foreignKey:AgentID"`
+	AgentUpgrade           *AgentUpgrade     `gorm:"foreignKey:AgentID"`
+	Tags                   []*models.Tag     `gorm:"polymorphic:Resource;polymorphicValue:hosts"`
+	UpdatedAt              time.Time
+	CloudData              datatypes.JSON
 }
 
 type HostHeartbeat struct {
 	AgentID   string `gorm:"primaryKey"`
 	UpdatedAt time.Time
+	// Health marked by the stale hosts detection, passing again as soon as a heartbeat is received.
+	// It is empty until the host is first marked
+	Health string
+	// HealthChangedAt is when the host was marked with its current health
+	HealthChangedAt *time.Time
+}
+
+// HostHeartbeatPeriod is a period of time in which a host has been continuously sending heartbeats
+type HostHeartbeatPeriod struct {
+	ID        int64
+	AgentID   string `gorm:"index"`
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// HostAvailabilityRollup is the time a host has been sending heartbeats during an hour, or a day,
+// the heartbeat periods being pruned once rolled up
+type HostAvailabilityRollup struct {
+	AgentID     string    `gorm:"primaryKey"`
+	Granularity string    `gorm:"primaryKey"`
+	StartedAt   time.Time `gorm:"primaryKey"`
+	// Covered is the time, in seconds, the host has been sending heartbeats
+	Covered int64
+	// Tracked is the time, in seconds, since the first heartbeat of the host
+	Tracked int64
 }
 
 type AzureCloudData struct {
@@ -49,16 +90,31 @@ func (h *Host) ToModel() *models.Host {
 		tags = append(tags, tag.Value)
 	}
 
+	var agentInfo *models.AgentInfo
+	if h.AgentInfo != nil {
+		agentInfo = h.AgentInfo.ToModel()
+	}
+
+	var agentUpgrade *models.AgentUpgrade
+	if h.AgentUpgrade != nil {
+		agentUpgrade = h.AgentUpgrade.ToModel()
+	}
+
 	return &models.Host{
-		ID:            h.AgentID,
-		Name:          h.Name,
-		IPAddresses:   h.IPAddresses,
-		CloudProvider: h.CloudProvider,
-		ClusterID:     h.ClusterID,
-		ClusterName:   h.ClusterName,
-		ClusterType:   h.ClusterType,
-		AgentVersion:  h.AgentVersion,
-		Tags:          tags,
-		SAPSystems:    h.SAPSystemInstances.ToModel(),
+		ID:                     h.AgentID,
+		Name:                   h.Name,
+		IPAddresses:            h.IPAddresses,
+		CloudProvider:          h.CloudProvider,
+		ClusterID:              h.ClusterID,
+		ClusterName:            h.ClusterName,
+		ClusterType:            h.ClusterType,
+		AgentVersion:           h.AgentVersion,
+		Tags:                   tags,
+		SAPSystems:             h.SAPSystemInstances.ToModel(),
+		AgentProfile:           h.AgentProfile,
+		Provisioning:           provisioningToModel(h.ProvisioningTool, h.ProvisioningTemplateVersion),
+		CertificateFingerprint: h.CertificateFingerprint,
+		AgentInfo:              agentInfo,
+		AgentUpgrade:           agentUpgrade,
 	}
 }
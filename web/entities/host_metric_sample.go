@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// HostMetricSample is a resources usage sample pushed by the agent, kept for a short period of time
+type HostMetricSample struct {
+	AgentID       string    `gorm:"primaryKey"`
+	SampledAt     time.Time `gorm:"primaryKey;index"`
+	CPUPercent    float64
+	MemoryPercent float64
+	DiskPercent   float64
+}
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// HostUtilizationSnapshot aggregates the utilization samples reported by a host within a time bucket
+type HostUtilizationSnapshot struct {
+	AgentID          string    `gorm:"primaryKey"`
+	BucketStart      time.Time `gorm:"primaryKey"`
+	SamplesCount     int
+	CPUPercentAvg    float64
+	CPUPercentMax    float64
+	MemoryPercentAvg float64
+	MemoryPercentMax float64
+	DiskPercentMax   float64
+	UpdatedAt        time.Time
+}
+
+func (s *HostUtilizationSnapshot) ToModel() *models.HostUtilizationSnapshot {
+	return &models.HostUtilizationSnapshot{
+		Time:             s.BucketStart,
+		SamplesCount:     s.SamplesCount,
+		CPUPercentAvg:    s.CPUPercentAvg,
+		CPUPercentMax:    s.CPUPercentMax,
+		MemoryPercentAvg: s.MemoryPercentAvg,
+		MemoryPercentMax: s.MemoryPercentMax,
+		DiskPercentMax:   s.DiskPercentMax,
+	}
+}
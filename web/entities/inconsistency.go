@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type Inconsistency struct {
+	Kind         string         `gorm:"primaryKey"`
+	ResourceID   string         `gorm:"primaryKey"`
+	ReferencedBy pq.StringArray `gorm:"type:text[]"`
+	AgentIDs     pq.StringArray `gorm:"type:text[]"`
+	Fixable      bool
+	DetectedAt   time.Time
+}
+
+func (i *Inconsistency) ToModel() *models.Inconsistency {
+	return &models.Inconsistency{
+		Kind:         i.Kind,
+		ResourceID:   i.ResourceID,
+		ReferencedBy: i.ReferencedBy,
+		AgentIDs:     i.AgentIDs,
+		Fixable:      i.Fixable,
+		DetectedAt:   i.DetectedAt,
+	}
+}
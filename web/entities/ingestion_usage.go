@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+// IngestionUsage is the data stored of an agent in a day, UTC, to enforce the daily ingestion quotas
+type IngestionUsage struct {
+	AgentID string    `gorm:"primaryKey"`
+	Day     time.Time `gorm:"primaryKey;type:date"`
+	Events  int64
+	Bytes   int64
+}
@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type KubernetesWorkload struct {
+	AgentID        string `gorm:"primaryKey"`
+	Namespace      string `gorm:"primaryKey"`
+	Pod            string `gorm:"primaryKey"`
+	Node           string
+	SID            string `gorm:"column:sid;index"`
+	InstanceNumber string
+	Type           string
+	Component      string
+	Image          string
+	Phase          string
+	Host           *Host `gorm:"foreignKey:AgentID"`
+	UpdatedAt      time.Time
+}
+
+func (w *KubernetesWorkload) ToModel() *models.KubernetesWorkload {
+	workload := &models.KubernetesWorkload{
+		Namespace:      w.Namespace,
+		Pod:            w.Pod,
+		Node:           w.Node,
+		SID:            w.SID,
+		InstanceNumber: w.InstanceNumber,
+		Type:           w.Type,
+		Component:      w.Component,
+		Image:          w.Image,
+		Phase:          w.Phase,
+		HostID:         w.AgentID,
+	}
+
+	if w.Host != nil {
+		workload.Hostname = w.Host.Name
+	}
+
+	return workload
+}
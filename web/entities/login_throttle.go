@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type LoginThrottle struct {
+	Type          string `gorm:"primaryKey"`
+	Subject       string `gorm:"primaryKey"`
+	Failures      int    `gorm:"not null"`
+	LastFailureAt time.Time
+	BlockedUntil  time.Time `gorm:"index"`
+	Locked        bool      `gorm:"not null"`
+}
+
+func (t *LoginThrottle) ToModel() *models.LoginThrottle {
+	return &models.LoginThrottle{
+		Type:          t.Type,
+		Subject:       t.Subject,
+		Failures:      t.Failures,
+		LastFailureAt: t.LastFailureAt,
+		BlockedUntil:  t.BlockedUntil,
+		Locked:        t.Locked,
+	}
+}
@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+// PayloadDigest is the hash of the last payload stored of a discovery of an agent,
+// the identical payloads received afterwards not being stored again
+type PayloadDigest struct {
+	AgentID       string `gorm:"primaryKey"`
+	DiscoveryType string `gorm:"primaryKey"`
+	Hash          string
+	// StoredAt is when the payload was last stored, LastSeenAt when it was last received
+	StoredAt   time.Time
+	LastSeenAt time.Time
+	// Skipped are the identical payloads received since it was stored
+	Skipped int64
+}
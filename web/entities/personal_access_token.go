@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type PersonalAccessToken struct {
+	ID int64 `gorm:"primaryKey"`
+	// The tokens are deleted along with their user
+	UserID int64  `gorm:"index;not null"`
+	User   User   `gorm:"constraint:OnDelete:CASCADE"`
+	Name   string `gorm:"not null"`
+	Prefix string `gorm:"not null"`
+	// Only the SHA-256 hash of the token is stored
+	TokenHash  string         `gorm:"uniqueIndex;not null"`
+	Scopes     pq.StringArray `gorm:"type:text[];not null"`
+	ExpiresAt  time.Time      `gorm:"not null"`
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+func (t *PersonalAccessToken) ToModel() *models.PersonalAccessToken {
+	return &models.PersonalAccessToken{
+		ID:         t.ID,
+		UserID:     t.UserID,
+		Username:   t.User.Username,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.ExpiresAt,
+		CreatedAt:  t.CreatedAt,
+		LastUsedAt: t.LastUsedAt,
+		RevokedAt:  t.RevokedAt,
+	}
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "github.com/trento-project/trento/web/models"
+
+// provisioningToModel returns nil when there is no provisioning metadata
+func provisioningToModel(tool string, templateVersion string) *models.Provisioning {
+	if tool == "" && templateVersion == "" {
+		return nil
+	}
+
+	return &models.Provisioning{
+		Tool:            tool,
+		TemplateVersion: templateVersion,
+	}
+}
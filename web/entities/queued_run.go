@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/trento-project/trento/web/models"
+)
+
+type QueuedRun struct {
+	ClusterID string         `gorm:"primaryKey"`
+	Targets   pq.StringArray `gorm:"type:text[]"`
+	State     string
+	QueuedAt  time.Time
+	StartedAt *time.Time
+}
+
+func (r *QueuedRun) ToModel() *models.QueuedRun {
+	return &models.QueuedRun{
+		ClusterID: r.ClusterID,
+		Targets:   r.Targets,
+		State:     r.State,
+		QueuedAt:  r.QueuedAt,
+		StartedAt: r.StartedAt,
+	}
+}
@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type RejectedPayload struct {
+	ID            int64     `gorm:"primaryKey"`
+	CreatedAt     time.Time `gorm:"index"`
+	AgentID       string    `gorm:"index"`
+	DiscoveryType string
+	Issues        datatypes.JSON
+	Size          int
+	Truncated     bool
+	Body          []byte
+}
+
+func (p *RejectedPayload) ToModel() *models.RejectedPayload {
+	var issues []*models.PayloadValidationIssue
+	_ = json.Unmarshal(p.Issues, &issues)
+
+	return &models.RejectedPayload{
+		ID:            p.ID,
+		AgentID:       p.AgentID,
+		DiscoveryType: p.DiscoveryType,
+		CreatedAt:     p.CreatedAt,
+		Issues:        issues,
+		Size:          p.Size,
+		Truncated:     p.Truncated,
+		Payload:       string(p.Body),
+	}
+}
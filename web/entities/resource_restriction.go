@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type ResourceRestriction struct {
+	ResourceType string `gorm:"primaryKey"`
+	ResourceID   string `gorm:"primaryKey"`
+	Permission   string `gorm:"primaryKey"`
+	Role         string
+	Usernames    pq.StringArray `gorm:"type:text[]"`
+	UpdatedAt    time.Time
+}
+
+func (r *ResourceRestriction) ToModel() *models.ResourceRestriction {
+	return &models.ResourceRestriction{
+		ResourceType: r.ResourceType,
+		ResourceID:   r.ResourceID,
+		Permission:   r.Permission,
+		Role:         r.Role,
+		Usernames:    r.Usernames,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
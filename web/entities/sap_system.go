@@ -26,10 +26,13 @@ type SAPSystemInstance struct {
 	DBHost                  string
 	DBName                  string
 	DBAddress               string
+	GlobalAllocationLimitMB int64
 	Tenants                 pq.StringArray `gorm:"type:text[]"`
 	Host                    *Host          `gorm:"foreignKey:AgentID"`
 	UpdatedAt               time.Time
 	Tags                    []*models.Tag `gorm:"foreignKey:ResourceID"`
+	// Organization of the agent of the host, not to be confused with the tenant databases of HANA
+	Organization string `gorm:"index"`
 }
 
 type SAPSystemInstances []*SAPSystemInstance
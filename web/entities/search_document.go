@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// SearchDocument indexes a resource for the full-text search.
+// Document is computed by the database from the name, the terms and the tags of the resource
+type SearchDocument struct {
+	ResourceType string `gorm:"primaryKey"`
+	ResourceID   string `gorm:"primaryKey"`
+	Name         string
+	Terms        string
+	Document     string `gorm:"type:tsvector;index:,type:gin;->"`
+	UpdatedAt    time.Time
+}
@@ -1,6 +1,31 @@
 package entities
 
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
 type Settings struct {
-	InstallationID string `gorm:"primaryKey"`
-	EulaAccepted   bool
+	InstallationID          string `gorm:"primaryKey"`
+	EulaAccepted            bool
+	RunnerMaxConcurrentRuns int `gorm:"default:4"`
+	RunnerMaxRunsPerTarget  int `gorm:"default:1"`
+	// the raw payload capture is inactive until an expiration is set
+	PayloadCaptureSamplingPercentage int
+	PayloadCaptureAgentID            string
+	PayloadCaptureMaxSizeBytes       int `gorm:"default:65536"`
+	PayloadCaptureExpiresAt          *time.Time
+	MaintenanceEnabled               bool
+	MaintenanceMessage               string
+	MaintenancePauseCollection       bool
+	MaintenanceSince                 *time.Time
+	MaintenanceEnabledBy             string
+	LogSamplingRules                 datatypes.JSON
+	ReadOnlyEnabled                  bool
+	ReadOnlyReason                   string
+	ReadOnlySince                    *time.Time
+	ReadOnlyEnabledBy                string
+	UsageAnalyticsEnabled            bool
+	AgentSettings                    datatypes.JSON
 }
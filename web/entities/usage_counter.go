@@ -0,0 +1,10 @@
+package entities
+
+import "time"
+
+// UsageCounter counts the uses of a route of the console in a day
+type UsageCounter struct {
+	Day   time.Time `gorm:"primaryKey;type:date"`
+	Route string    `gorm:"primaryKey"`
+	Count int64
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+type User struct {
+	ID           int64  `gorm:"primaryKey"`
+	Username     string `gorm:"uniqueIndex;not null"`
+	PasswordHash string `gorm:"not null"`
+	// The users created before the roles were introduced were all administrators
+	Role         string `gorm:"not null;default:admin"`
+	Organization string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (u *User) ToModel() *models.User {
+	return &models.User{
+		ID:           u.ID,
+		Username:     u.Username,
+		Role:         u.Role,
+		CreatedAt:    u.CreatedAt,
+		Organization: u.Organization,
+	}
+}
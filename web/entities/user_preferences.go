@@ -0,0 +1,13 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type UserPreferences struct {
+	UserID          string         `gorm:"primaryKey"`
+	DashboardLayout pq.StringArray `gorm:"type:text[]"`
+	UpdatedAt       time.Time
+}
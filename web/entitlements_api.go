@@ -0,0 +1,27 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+// ApiGetEntitlementsHandler godoc
+// @Summary Retrieve the entitlements of the installation, their usage and the warnings about them
+// @Produce json
+// @Success 200 {object} models.Entitlements
+// @Failure 500 {object} map[string]string
+// @Router /entitlements [get]
+func ApiGetEntitlementsHandler(entitlementsService services.EntitlementsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entitlements, err := entitlementsService.GetEntitlements()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, entitlements)
+	}
+}
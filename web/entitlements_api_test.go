@@ -0,0 +1,48 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiGetEntitlementsHandler(t *testing.T) {
+	entitlementsService := new(services.MockEntitlementsService)
+	entitlementsService.On("GetEntitlements").Return(&models.Entitlements{
+		Source:         models.EntitlementsSourceLicense,
+		Customer:       "ACME",
+		HostsLimit:     10,
+		MonitoredHosts: 9,
+		PremiumChecks:  true,
+		Enforcement:    models.EntitlementsEnforcementSoft,
+		Warnings:       []string{models.EntitlementsWarningHostsLimitApproaching},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.entitlementsService = entitlementsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/entitlements", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"source": "license",
+		"customer": "ACME",
+		"expires_at": null,
+		"hosts_limit": 10,
+		"monitored_hosts": 9,
+		"premium_checks": true,
+		"enforcement": "soft",
+		"warnings": ["hosts_limit_approaching"]
+	}`, resp.Body.String())
+}
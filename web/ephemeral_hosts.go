@@ -0,0 +1,40 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/services"
+)
+
+var ephemeralHostsReaperInterval = 1 * time.Minute
+
+// EphemeralHostsReaper periodically removes the ephemeral hosts that stopped sending heartbeats,
+// like the auto-scaled application servers that were scaled in
+type EphemeralHostsReaper struct {
+	hostsService services.HostsService
+}
+
+func NewEphemeralHostsReaper(hostsService services.HostsService) *EphemeralHostsReaper {
+	return &EphemeralHostsReaper{hostsService: hostsService}
+}
+
+func (r *EphemeralHostsReaper) Start(ctx context.Context) {
+	log.Infof("Starting ephemeral hosts reaper")
+
+	internal.Repeat("web.ephemeral_hosts_reaper", r.reap, ephemeralHostsReaperInterval, ctx)
+}
+
+func (r *EphemeralHostsReaper) reap() {
+	deleted, err := r.hostsService.DeleteExpiredEphemeral()
+	if err != nil {
+		log.Errorf("Error while removing the expired ephemeral hosts: %s", err)
+	}
+
+	for _, id := range deleted {
+		log.Infof("Ephemeral host %s removed after its silence window expired", id)
+	}
+}
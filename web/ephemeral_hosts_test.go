@@ -0,0 +1,16 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestEphemeralHostsReaper(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("DeleteExpiredEphemeral").Return([]string{"1"}, nil)
+
+	NewEphemeralHostsReaper(hostsService).reap()
+
+	hostsService.AssertExpectations(t)
+}
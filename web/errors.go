@@ -70,6 +70,30 @@ func BadRequestError(msg string) *HttpError {
 	}
 }
 
+func UnauthorizedError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusUnauthorized,
+		"error.html.tmpl",
+	}
+}
+
+func ConflictError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusConflict,
+		"error.html.tmpl",
+	}
+}
+
+func ForbiddenError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusForbidden,
+		"error.html.tmpl",
+	}
+}
+
 func InternalServerError(msg string) *HttpError {
 	return &HttpError{
 		msg,
@@ -77,3 +101,59 @@ func InternalServerError(msg string) *HttpError {
 		"error.html.tmpl",
 	}
 }
+
+func TooManyRequestsError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusTooManyRequests,
+		"error.html.tmpl",
+	}
+}
+
+func PayloadTooLargeError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusRequestEntityTooLarge,
+		"error.html.tmpl",
+	}
+}
+
+func UnsupportedMediaTypeError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusUnsupportedMediaType,
+		"error.html.tmpl",
+	}
+}
+
+func UnprocessableEntityError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusUnprocessableEntity,
+		"error.html.tmpl",
+	}
+}
+
+func PreconditionRequiredError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusPreconditionRequired,
+		"error.html.tmpl",
+	}
+}
+
+func GatewayTimeoutError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusGatewayTimeout,
+		"error.html.tmpl",
+	}
+}
+
+func ServiceUnavailableError(msg string) *HttpError {
+	return &HttpError{
+		msg,
+		http.StatusServiceUnavailable,
+		"error.html.tmpl",
+	}
+}
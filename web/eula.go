@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
 
@@ -14,7 +15,7 @@ func EulaShowHandler() gin.HandlerFunc {
 	}
 }
 
-func EulaAcceptHandler(settings services.SettingsService) gin.HandlerFunc {
+func EulaAcceptHandler(settings services.SettingsService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		err := settings.AcceptEula()
 		if err != nil {
@@ -22,6 +23,7 @@ func EulaAcceptHandler(settings services.SettingsService) gin.HandlerFunc {
 			c.HTML(http.StatusInternalServerError, "error.html.tmpl", gin.H{"Error": "There was an error accepting the EULA. Please try again."})
 			return
 		}
+		recordAudit(c, auditService, models.AuditActionEulaAccepted, models.AuditResourceSettings, "eula", nil, nil)
 		c.Redirect(http.StatusFound, "/")
 	}
 }
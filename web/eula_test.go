@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
 
@@ -31,6 +32,8 @@ func TestEulaAcceptHandler(t *testing.T) {
 	mockedSettingsService.On("AcceptEula").Return(nil)
 	mockedSettingsService.On("IsEulaAccepted").Return(false, nil)
 	mockedSettingsService.On("InitializeIdentifier").Return(uuid.MustParse("59fd8017-b7fd-477b-9ebe-b658c558f3e9"), nil)
+	mockedSettingsService.On("GetLogSamplingRules").Return([]*models.LogSamplingRule{}, nil)
+	mockedSettingsService.On("GetReadOnlyMode").Return(&models.ReadOnlyMode{}, nil).Maybe()
 	deps := setupTestDependencies()
 	deps.settingsService = mockedSettingsService
 	config := setupTestConfig()
@@ -41,6 +44,7 @@ func TestEulaAcceptHandler(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 	req := httptest.NewRequest("POST", "/accept-eula", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 	app.webEngine.ServeHTTP(resp, req)
 
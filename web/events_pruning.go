@@ -0,0 +1,42 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/services"
+)
+
+var eventsPruningInterval = time.Hour
+
+// EventsPruningJob periodically removes the collected events older than the retention,
+// the latest one of every agent discovery being kept
+type EventsPruningJob struct {
+	collectorService services.CollectorService
+	retention        time.Duration
+}
+
+func NewEventsPruningJob(collectorService services.CollectorService, retention time.Duration) *EventsPruningJob {
+	return &EventsPruningJob{collectorService: collectorService, retention: retention}
+}
+
+func (j *EventsPruningJob) Start(ctx context.Context) {
+	log.Infof("Starting events pruning job")
+
+	internal.Repeat("web.events_pruning", j.prune, eventsPruningInterval, ctx)
+}
+
+func (j *EventsPruningJob) prune() {
+	pruned, err := j.collectorService.PruneEvents(j.retention)
+	if err != nil {
+		log.Errorf("Error while pruning the collected events: %s", err)
+		return
+	}
+
+	if pruned > 0 {
+		log.Infof("%d collected events older than %s pruned", pruned, j.retention)
+	}
+}
@@ -0,0 +1,27 @@
+package web
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestEventsPruningJob(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("PruneEvents", 30*24*time.Hour).Return(int64(3), nil)
+
+	NewEventsPruningJob(collectorService, 30*24*time.Hour).prune()
+
+	collectorService.AssertExpectations(t)
+}
+
+func TestEventsPruningJobError(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("PruneEvents", 30*24*time.Hour).Return(int64(0), errors.New("kaboom"))
+
+	NewEventsPruningJob(collectorService, 30*24*time.Hour).prune()
+
+	collectorService.AssertExpectations(t)
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MenuItem is an entry added to the sidebar by an extension
+type MenuItem struct {
+	Label string
+	URL   string
+	// Icon is the name of an EOS icon
+	Icon string
+	// Permission required to see the entry, e.g. settings:write, shown to every user if empty
+	Permission string
+}
+
+// Extension adds routes and menu items to the console, letting the downstream distributions embedding it
+// extend it without forking. Their own templates can be added to the templates override directory
+type Extension struct {
+	Name      string
+	MenuItems []MenuItem
+	// RegisterRoutes adds the routes of the extension, after the ones of the console.
+	// The routes of the web engine require a logged in user, the ones of the API group are also rate limited
+	// and the changes refused in maintenance mode
+	RegisterRoutes func(webEngine *gin.Engine, apiGroup *gin.RouterGroup)
+}
+
+var extensions []Extension
+
+// RegisterExtension adds an extension to the apps created afterwards, it is meant to be called from an init function
+func RegisterExtension(extension Extension) {
+	extensions = append(extensions, extension)
+}
+
+func extensionsMenuItems() []MenuItem {
+	var menuItems []MenuItem
+	for _, extension := range extensions {
+		menuItems = append(menuItems, extension.MenuItems...)
+	}
+
+	return menuItems
+}
+
+func registerExtensionsRoutes(webEngine *gin.Engine, apiGroup *gin.RouterGroup) {
+	for _, extension := range extensions {
+		if extension.RegisterRoutes != nil {
+			extension.RegisterRoutes(webEngine, apiGroup)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensions(t *testing.T) {
+	defer func(registered []Extension) { extensions = registered }(extensions)
+
+	RegisterExtension(Extension{
+		Name: "appliance",
+		MenuItems: []MenuItem{
+			{Label: "Appliance", URL: "/appliance", Icon: "dns"},
+			{Label: "Appliance settings", URL: "/appliance/settings", Permission: "appliance:write"},
+		},
+		RegisterRoutes: func(webEngine *gin.Engine, apiGroup *gin.RouterGroup) {
+			webEngine.GET("/appliance", func(c *gin.Context) {
+				c.HTML(http.StatusOK, "appliance.html.tmpl", gin.H{"Name": "appliance"})
+			})
+			apiGroup.GET("/appliance", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"name": "appliance"})
+			})
+		},
+	})
+
+	overrideDir := t.TempDir()
+	if err := os.MkdirAll(path.Join(overrideDir, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(path.Join(overrideDir, "templates", "appliance.html.tmpl"),
+		[]byte(`{{ define "content" }}<h1>The {{ .Name }} page</h1>{{ end }}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := setupTestConfig()
+	config.TemplatesOverrideDir = overrideDir
+
+	app, err := NewAppWithDeps(config, setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/appliance", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), "<h1>The appliance page</h1>")
+	assert.Contains(t, resp.Body.String(), `href="/appliance"`)
+	assert.NotContains(t, resp.Body.String(), `href="/appliance/settings"`)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/appliance", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"name": "appliance"}`, resp.Body.String())
+}
@@ -0,0 +1,158 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// ApiListFavoritesHandler godoc
+// @Summary List the favorite resources of the current user
+// @Description The resources of the organization of the user only, if any
+// @Produce json
+// @Success 200 {object} []models.Favorite
+// @Failure 500 {object} map[string]string
+// @Router /favorites [get]
+func ApiListFavoritesHandler(favoritesService services.FavoritesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		favorites, err := favoritesService.GetAll(sessionUserID(c), userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if favorites == nil {
+			c.JSON(http.StatusOK, []*models.Favorite{})
+			return
+		}
+
+		c.JSON(http.StatusOK, favorites)
+	}
+}
+
+// ApiCreateFavoriteHandler godoc
+// @Summary Add a resource to the favorites of the current user
+// @Accept json
+// @Produce json
+// @Param Body body models.Favorite true "The resource to pin"
+// @Success 201 {object} models.Favorite
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /favorites [post]
+func ApiCreateFavoriteHandler(
+	favoritesService services.FavoritesService,
+	hostsService services.HostsService,
+	clustersService services.ClustersService,
+	sapSystemsService services.SAPSystemsService,
+	organizationsService services.OrganizationsService,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r models.Favorite
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		var found bool
+		switch r.ResourceType {
+		case models.TagHostResourceType:
+			host, err := hostsService.GetByID(r.ResourceID)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			found = host != nil
+		case models.TagClusterResourceType:
+			cluster, err := clustersService.GetByID(r.ResourceID)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			found = cluster != nil
+		case models.TagSAPSystemResourceType, models.TagDatabaseResourceType:
+			sapSystem, err := sapSystemsService.GetByID(r.ResourceID)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			found = sapSystem != nil
+		default:
+			_ = c.Error(BadRequestError("unknown resource type"))
+			return
+		}
+
+		if !found {
+			_ = c.Error(NotFoundError("could not find resource"))
+			return
+		}
+
+		visible, err := resourceVisible(c, organizationsService, r.ResourceType, r.ResourceID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !visible {
+			_ = c.Error(NotFoundError("could not find resource"))
+			return
+		}
+
+		err = favoritesService.Create(sessionUserID(c), r.ResourceType, r.ResourceID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, &r)
+	}
+}
+
+// ApiDeleteFavoriteHandler godoc
+// @Summary Remove a resource from the favorites of the current user
+// @Produce json
+// @Param resource_type path string true "Resource type"
+// @Param id path string true "Resource id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /favorites/{resource_type}/{id} [delete]
+func ApiDeleteFavoriteHandler(favoritesService services.FavoritesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := favoritesService.Delete(sessionUserID(c), c.Param("resource_type"), c.Param("id"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// getFavorites returns the ids of the resources of the given type pinned by the current user
+func getFavorites(c *gin.Context, favoritesService services.FavoritesService, resourceType string) ([]string, error) {
+	return favoritesService.GetAllByResourceType(sessionUserID(c), resourceType)
+}
+
+func markFavoriteHosts(hosts models.HostList, favorites []string) {
+	for _, h := range hosts {
+		h.Favorite = internal.Contains(favorites, h.ID)
+	}
+}
+
+func markFavoriteClusters(clusters models.ClusterList, favorites []string) {
+	for _, cl := range clusters {
+		cl.Favorite = internal.Contains(favorites, cl.ID)
+	}
+}
+
+func markFavoriteSAPSystems(sapSystems models.SAPSystemList, favorites []string) {
+	for _, s := range sapSystems {
+		s.Favorite = internal.Contains(favorites, s.ID)
+	}
+}
@@ -0,0 +1,176 @@
+package web
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiListFavoritesHandler(t *testing.T) {
+	favoritesService := new(services.MockFavoritesService)
+	favoritesService.On("GetAll", testUser, "").Return([]*models.Favorite{
+		{ResourceType: "hosts", ResourceID: "host1", Name: "host1name"},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.favoritesService = favoritesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/favorites", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{"resource_type":"hosts","resource_id":"host1","name":"host1name"}]`, resp.Body.String())
+}
+
+func TestApiCreateFavoriteHandler(t *testing.T) {
+	favoritesService := new(services.MockFavoritesService)
+	favoritesService.On("Create", testUser, "clusters", "cluster1").Return(nil)
+
+	clustersService := new(services.MockClustersService)
+	clustersService.On("GetByID", "cluster1").Return(&models.Cluster{ID: "cluster1"}, nil)
+
+	deps := setupTestDependencies()
+	deps.favoritesService = favoritesService
+	deps.clustersService = clustersService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/favorites", strings.NewReader(`{"resource_type":"clusters","resource_id":"cluster1"}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 201, resp.Code)
+	favoritesService.AssertExpectations(t)
+}
+
+func TestApiCreateFavoriteHandlerNotFound(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "unknown").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = hostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/favorites", strings.NewReader(`{"resource_type":"hosts","resource_id":"unknown"}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiCreateFavoriteHandlerOtherOrganization(t *testing.T) {
+	favoritesService := new(services.MockFavoritesService)
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "host1").Return(&models.Host{ID: "host1"}, nil)
+
+	organizationsService := new(services.MockOrganizationsService)
+	organizationsService.On("GetResourceOrganization", models.TagHostResourceType, "host1").Return("globex", true, nil)
+
+	deps := setupTestDependencies()
+	deps.favoritesService = favoritesService
+	deps.hostsService = hostsService
+	deps.organizationsService = organizationsService
+	deps.usersService = newMockedOrganizationUsersService("acme")
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/favorites", strings.NewReader(`{"resource_type":"hosts","resource_id":"host1"}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+	favoritesService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApiCreateFavoriteHandlerUnknownType(t *testing.T) {
+	deps := setupTestDependencies()
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/favorites", strings.NewReader(`{"resource_type":"pets","resource_id":"dog"}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestApiDeleteFavoriteHandler(t *testing.T) {
+	favoritesService := new(services.MockFavoritesService)
+	favoritesService.On("Delete", testUser, "sapsystems", "sapsystem1").Return(nil)
+
+	deps := setupTestDependencies()
+	deps.favoritesService = favoritesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/favorites/sapsystems/sapsystem1", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	favoritesService.AssertExpectations(t)
+}
+
+func TestApiListFavoritesHandlerApiKey(t *testing.T) {
+	apiKeysService := new(services.MockApiKeysService)
+	apiKeysService.On("Authenticate", "read-key").Return(&models.ApiKey{ID: 1, Name: "dashboard", Scope: models.ApiKeyScopeRead}, nil)
+
+	// every API key has its own favorites, not the ones of the anonymous user
+	favoritesService := new(services.MockFavoritesService)
+	favoritesService.On("GetAll", "api-key:dashboard", "").Return([]*models.Favorite{}, nil)
+
+	deps := setupTestDependencies()
+	deps.apiKeysService = apiKeysService
+	deps.favoritesService = favoritesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/favorites", nil)
+	req.Header.Set("Authorization", "Bearer read-key")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	favoritesService.AssertExpectations(t)
+}
@@ -0,0 +1,118 @@
+import React, { useState, useEffect } from 'react';
+import { get, put } from 'axios';
+
+import { logError } from '@lib/log';
+import { getWidgetComponent } from './Widgets';
+
+const Widget = ({ widget }) => {
+  const [data, setData] = useState(null);
+
+  useEffect(() => {
+    get(widget.data_url)
+      .then(({ data }) => setData(data || []))
+      .catch((error) => {
+        logError(error);
+        setData([]);
+      });
+  }, [widget.data_url]);
+
+  const Component = getWidgetComponent(widget.id);
+  if (!Component) {
+    return null;
+  }
+
+  return data === null ? (
+    <div>Loading...</div>
+  ) : (
+    <div className="dashboard-widget">
+      {widget.id !== 'health_summary' && <h5>{widget.title}</h5>}
+      <Component data={data} />
+    </div>
+  );
+};
+
+const move = (list, index, offset) => {
+  const target = index + offset;
+  if (target < 0 || target >= list.length) {
+    return list;
+  }
+  const moved = [...list];
+  [moved[index], moved[target]] = [moved[target], moved[index]];
+  return moved;
+};
+
+const Dashboard = () => {
+  const [widgets, setWidgets] = useState([]);
+  const [layout, setLayout] = useState([]);
+  const [editing, setEditing] = useState(false);
+
+  useEffect(() => {
+    Promise.all([get('/api/dashboard/widgets'), get('/api/dashboard/layout')])
+      .then(([{ data: available }, { data: current }]) => {
+        setWidgets(available);
+        setLayout(current.widgets);
+      })
+      .catch(logError);
+  }, []);
+
+  const saveLayout = (newLayout) => {
+    put('/api/dashboard/layout', { widgets: newLayout })
+      .then(({ data }) => setLayout(data.widgets))
+      .catch(logError);
+  };
+
+  const byId = (id) => widgets.find((w) => w.id === id);
+  const hidden = widgets.filter(({ id }) => !layout.includes(id));
+
+  return (
+    <div>
+      <div className="d-flex justify-content-end">
+        <button
+          className="btn btn-secondary btn-sm"
+          onClick={() => setEditing(!editing)}
+        >
+          {editing ? 'Done' : 'Customize'}
+        </button>
+      </div>
+      {layout.map((id, index) => (
+        <div key={id}>
+          {editing && (
+            <div className="btn-group btn-group-sm mt-2">
+              <button
+                className="btn btn-light"
+                onClick={() => saveLayout(move(layout, index, -1))}
+              >
+                <i className="eos-icons eos-18">keyboard_arrow_up</i>
+              </button>
+              <button
+                className="btn btn-light"
+                onClick={() => saveLayout(move(layout, index, 1))}
+              >
+                <i className="eos-icons eos-18">keyboard_arrow_down</i>
+              </button>
+              <button
+                className="btn btn-light"
+                onClick={() => saveLayout(layout.filter((w) => w !== id))}
+              >
+                <i className="eos-icons eos-18">close</i>
+              </button>
+            </div>
+          )}
+          {byId(id) && <Widget widget={byId(id)} />}
+        </div>
+      ))}
+      {editing &&
+        hidden.map(({ id, title }) => (
+          <button
+            key={id}
+            className="btn btn-light btn-sm mr-2 mt-2"
+            onClick={() => saveLayout([...layout, id])}
+          >
+            <i className="eos-icons eos-18">add</i> {title}
+          </button>
+        ))}
+    </div>
+  );
+};
+
+export default Dashboard;
@@ -0,0 +1,99 @@
+import React from 'react';
+
+import HealthSummary from '@components/HealthSummary';
+
+const EmptyWidget = () => <p className="text-muted">Nothing to report</p>;
+
+const RecentAlerts = ({ data }) =>
+  data.length === 0 ? (
+    <EmptyWidget />
+  ) : (
+    <ul className="no-list-style">
+      {data.map(({ resource_type, id, name, health }) => (
+        <li key={`${resource_type}-${id}`}>
+          <i
+            className={`eos-icons eos-18 ${
+              health === 'critical' ? 'text-danger' : 'text-warning'
+            }`}
+          >
+            {health === 'critical' ? 'error' : 'warning'}
+          </i>{' '}
+          <a href={`/${resource_type}/${id}`}>{name}</a>
+        </li>
+      ))}
+    </ul>
+  );
+
+const ExpiringSubscriptions = ({ data }) =>
+  data.length === 0 ? (
+    <EmptyWidget />
+  ) : (
+    <ul className="no-list-style">
+      {data.map(({ host_id, host_name, id, expires_at }) => (
+        <li key={`${host_id}-${id}`}>
+          <a href={`/hosts/${host_id}`}>{host_name}</a>: {id} expires on{' '}
+          {new Date(expires_at).toLocaleDateString()}
+        </li>
+      ))}
+    </ul>
+  );
+
+const AddressConflicts = ({ data }) =>
+  data.length === 0 ? (
+    <EmptyWidget />
+  ) : (
+    <ul className="no-list-style">
+      {data.map(({ kind, address, owners }) => (
+        <li key={`${kind}-${address}`}>
+          <i className="eos-icons eos-18 text-warning">warning</i>{' '}
+          {kind === 'ip_address' ? 'IP address' : 'Hostname'}{' '}
+          <strong>{address}</strong> is claimed by{' '}
+          {owners.map(({ resource_type, id, name }, index) => (
+            <React.Fragment key={`${resource_type}-${id}`}>
+              {index > 0 && ', '}
+              <a href={`/${resource_type}/${id}`}>{name}</a>
+            </React.Fragment>
+          ))}
+        </li>
+      ))}
+    </ul>
+  );
+
+const MyResources = ({ data }) =>
+  data.length === 0 ? (
+    <EmptyWidget />
+  ) : (
+    <ul className="no-list-style">
+      {data.map(({ resource_type, resource_id, name }) => (
+        <li key={`${resource_type}-${resource_id}`}>
+          <i className="eos-icons eos-18">star</i>{' '}
+          <a href={`/${resource_type}/${resource_id}`}>{name}</a>
+        </li>
+      ))}
+    </ul>
+  );
+
+const formatTime = (time) =>
+  time && !time.startsWith('0001') ? new Date(time).toLocaleString() : 'never';
+
+const PipelineStatus = ({ data }) => (
+  <dl className="row">
+    <dt className="col-sm-4">Collected events</dt>
+    <dd className="col-sm-8">{data.events_count}</dd>
+    <dt className="col-sm-4">Last collection</dt>
+    <dd className="col-sm-8">{formatTime(data.last_collected_at)}</dd>
+    <dt className="col-sm-4">Last projection</dt>
+    <dd className="col-sm-8">{formatTime(data.last_projected_at)}</dd>
+  </dl>
+);
+
+const widgetComponents = {
+  health_summary: HealthSummary,
+  favorite_resources: MyResources,
+  recent_alerts: RecentAlerts,
+  address_conflicts: AddressConflicts,
+  expiring_subscriptions: ExpiringSubscriptions,
+  pipeline_status: PipelineStatus,
+};
+
+export const getWidgetComponent = (id) => widgetComponents[id];
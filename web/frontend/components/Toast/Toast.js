@@ -31,3 +31,14 @@ export const showErrorToast = ({ title = 'OH NOES', content = '' }) =>
       <Toast.Body className="trento-toast-body">{content}</Toast.Body>
     </Toast>
   ));
+
+export const showWarningToast = ({ title = 'Warning', content = '' }) =>
+  toast(({ closeToast }) => (
+    <Toast onClose={closeToast}>
+      <Toast.Header>
+        <i className="eos-icons eos-18 text-warning">warning</i>
+        <strong className="mr-auto">{title}</strong>
+      </Toast.Header>
+      <Toast.Body className="trento-toast-body">{content}</Toast.Body>
+    </Toast>
+  ));
@@ -1 +1 @@
-export { showSuccessToast, showErrorToast } from './Toast';
+export { showSuccessToast, showErrorToast, showWarningToast } from './Toast';
@@ -0,0 +1,26 @@
+/* eslint-disable no-undef */
+$(() => {
+  const requestButton = document.getElementById('agent-logs-request');
+  if (!requestButton) {
+    return;
+  }
+
+  requestButton.addEventListener('click', () => {
+    requestButton.disabled = true;
+
+    fetch(
+      `/api/hosts/${encodeURIComponent(requestButton.dataset.hostId)}/logs/request`,
+      { method: 'POST' }
+    )
+      .then((res) => {
+        if (!res.ok) {
+          throw new Error(res.statusText);
+        }
+        window.location.reload();
+      })
+      .catch((e) => {
+        requestButton.disabled = false;
+        console.error(e);
+      });
+  });
+});
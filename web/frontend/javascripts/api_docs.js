@@ -0,0 +1,25 @@
+/* eslint-disable no-undef */
+$(() => {
+  const tokenKey = 'trento-api-token';
+  const tokenInput = document.getElementById('api-token');
+  const container = document.getElementById('swagger-ui');
+
+  tokenInput.value = sessionStorage.getItem(tokenKey) || '';
+
+  document.getElementById('api-token-save').addEventListener('click', () => {
+    sessionStorage.setItem(tokenKey, tokenInput.value);
+  });
+
+  SwaggerUIBundle({
+    url: container.getAttribute('data-spec-url'),
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    requestInterceptor: (request) => {
+      const token = sessionStorage.getItem(tokenKey);
+      if (token) {
+        request.headers.Authorization = `Bearer ${token}`;
+      }
+      return request;
+    },
+  });
+});
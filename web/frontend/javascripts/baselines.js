@@ -0,0 +1,46 @@
+/* eslint-disable no-undef */
+$(() => {
+  function send(method, url, body, elm) {
+    elm.disabled = true;
+
+    fetch(url, {
+      method,
+      headers: { 'Content-Type': 'application/json' },
+      body: body && JSON.stringify(body),
+    })
+      .then((res) => {
+        if (!res.ok) {
+          throw new Error(res.statusText);
+        }
+        window.location.reload();
+      })
+      .catch((e) => {
+        elm.disabled = false;
+        console.error(e);
+      });
+  }
+
+  document.querySelectorAll('.baseline-delete').forEach((button) => {
+    button.addEventListener('click', () =>
+      send(
+        'DELETE',
+        `/api/baselines/${encodeURIComponent(button.dataset.role)}`,
+        null,
+        button
+      )
+    );
+  });
+
+  const saveForm = document.getElementById('baseline-save');
+  if (saveForm) {
+    const saveButton = saveForm.querySelector('button');
+    saveButton.addEventListener('click', () =>
+      send(
+        'PUT',
+        `/api/baselines/${encodeURIComponent(saveForm.dataset.role)}`,
+        { agent_id: saveForm.querySelector('[name=agent_id]').value },
+        saveButton
+      )
+    );
+  }
+});
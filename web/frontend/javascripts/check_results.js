@@ -8,6 +8,47 @@ import Dropdown, { DropdownItem } from '@components/Dropdown';
 
 const clusterId = window.location.pathname.split('/').pop();
 
+// The results older than this are flagged as outdated, e.g. when the runner
+// could not reach the checks backend for a while
+const staleResultsAge = 60 * 60 * 1000;
+
+const timeAgo = (date) => {
+  const seconds = Math.max(0, Math.floor((Date.now() - date) / 1000));
+  const units = [
+    ['day', 24 * 60 * 60],
+    ['hour', 60 * 60],
+    ['minute', 60],
+  ];
+
+  for (const [unit, length] of units) {
+    const count = Math.floor(seconds / length);
+    if (count > 0) {
+      return `${count} ${unit}${count > 1 ? 's' : ''} ago`;
+    }
+  }
+
+  return 'just now';
+};
+
+const LastUpdated = ({ updatedAt }) => {
+  if (!updatedAt) {
+    return null;
+  }
+
+  const date = new Date(updatedAt);
+  const stale = Date.now() - date > staleResultsAge;
+
+  return (
+    <span
+      className={classNames('checks-last-updated', { 'text-warning': stale })}
+      title={date.toLocaleString()}
+    >
+      Last updated {timeAgo(date)}
+      {stale && ', the results might be outdated'}
+    </span>
+  );
+};
+
 const toggleFilter = (filter, selectedFilters) =>
   selectedFilters.includes(filter)
     ? selectedFilters.filter((string) => string !== filter)
@@ -17,6 +58,7 @@ const ClustersChecks = ({ clusterId }) => {
   const [results, setResults] = useState([]);
   const [hosts, setHosts] = useState({});
   const [filters, setFilters] = useState([]);
+  const [updatedAt, setUpdatedAt] = useState(null);
 
   const displayedResults = results.filter(({ hosts }) => {
     if (filters.length === 0) {
@@ -31,12 +73,14 @@ const ClustersChecks = ({ clusterId }) => {
     get(`/api/clusters/${clusterId}/results`).then(({ data }) => {
       setResults(data.checks);
       setHosts(data.hosts);
+      setUpdatedAt(data.updated_at);
     });
   }, []);
 
   return (
     <div>
       <div className="checks-filters-row">
+        <LastUpdated updatedAt={updatedAt} />
         <Dropdown multi label="Filter the checks list">
           <DropdownItem
             className={classNames({ selected: filters.includes('warning') })}
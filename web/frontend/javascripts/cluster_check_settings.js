@@ -13,7 +13,11 @@ import { logError } from '@lib/log';
 import { toggle, hasOne, remove } from '@lib/lists';
 import Checkbox from '@components/Checkbox';
 import { AccordionToggle } from '@components/Accordion';
-import { showSuccessToast, showErrorToast } from '@components/Toast';
+import {
+  showSuccessToast,
+  showErrorToast,
+  showWarningToast,
+} from '@components/Toast';
 
 const clusterId = window.location.pathname.split('/').pop();
 
@@ -28,11 +32,26 @@ const mergeConnectionSettings = (hostnames, connectionSettings) =>
     {}
   );
 
+const actionLabels = {
+  tag_created: 'Tag added',
+  tag_deleted: 'Tag removed',
+  checks_settings_saved: 'Settings saved',
+};
+
+const describeConflicts = (conflicts) => {
+  const actors = [...new Set(conflicts.map(({ actor }) => actor))];
+  return `Your settings were saved over the ones saved meanwhile by ${actors.join(
+    ', '
+  )}. Check the change history to review them.`;
+};
+
 const SettingsButton = () => {
   const [modalOpen, setModalOpen] = useState(false);
   const [checksCatalog, setChecksCatalog] = useState([]);
   const [selectedChecks, setSelectedChecks] = useState([]);
   const [settings, setSettings] = useState({});
+  const [version, setVersion] = useState(null);
+  const [history, setHistory] = useState([]);
   const [loading, setLoading] = useState(false);
 
   useEffect(() => {
@@ -55,6 +74,7 @@ const SettingsButton = () => {
           hostnames,
           connection_settings: connectionSettings,
           selected_checks: selectedChecks,
+          version: settingsVersion,
         } = data;
         const newSettings = mergeConnectionSettings(
           hostnames,
@@ -62,6 +82,7 @@ const SettingsButton = () => {
         );
         setSettings(newSettings);
         setSelectedChecks(selectedChecks);
+        setVersion(settingsVersion);
         setLoading(false);
       })
       .catch((error) => {
@@ -73,18 +94,34 @@ const SettingsButton = () => {
           content: 'Error fetching the checks data, please refresh.',
         });
       });
+
+    get(`/api/clusters/${clusterId}/history?per_page=10`)
+      .then(({ data }) => setHistory(data || []))
+      .catch((error) => {
+        logError(error);
+        setHistory([]);
+      });
   }, [modalOpen]);
 
   const submit = useCallback(() => {
     const payload = {
       selected_checks: selectedChecks,
       connection_settings: settings,
+      version,
     };
     setLoading(true);
     post(`/api/checks/${clusterId}/settings`, payload)
-      .then(() => {
+      .then(({ data: { version: savedVersion, conflicts } }) => {
+        setVersion(savedVersion);
         setLoading(false);
         setModalOpen(false);
+        if (conflicts && conflicts.length > 0) {
+          showWarningToast({
+            title: 'Concurrent changes',
+            content: describeConflicts(conflicts),
+          });
+          return;
+        }
         showSuccessToast({
           content: 'Cluster settings successfully saved.',
         });
@@ -96,7 +133,7 @@ const SettingsButton = () => {
           content: 'Error saving the checks settings, please retry',
         });
       });
-  }, [selectedChecks, settings]);
+  }, [selectedChecks, settings, version]);
 
   return (
     <Fragment>
@@ -205,6 +242,37 @@ const SettingsButton = () => {
               </Card>
             ))}
           </Accordion>
+          <h6>Change history</h6>
+          <Accordion>
+            <Card>
+              <Card.Header>
+                Latest changes to the cluster
+                <AccordionToggle className="float-right" eventKey="history" />
+              </Card.Header>
+              <Accordion.Collapse eventKey="history">
+                <Card.Body className="card-check-selection">
+                  <Table>
+                    <thead>
+                      <tr>
+                        <th>Time</th>
+                        <th>User</th>
+                        <th>Change</th>
+                      </tr>
+                    </thead>
+                    <tbody>
+                      {history.map(({ id, time, actor, action }) => (
+                        <tr key={id}>
+                          <td>{new Date(time).toLocaleString()}</td>
+                          <td>{actor}</td>
+                          <td>{actionLabels[action] || action}</td>
+                        </tr>
+                      ))}
+                    </tbody>
+                  </Table>
+                </Card.Body>
+              </Accordion.Collapse>
+            </Card>
+          </Accordion>
         </Modal.Body>
         <Modal.Footer>
           <Button
@@ -226,7 +294,13 @@ const SettingsButton = () => {
   );
 };
 
-ReactDOM.render(
-  <SettingsButton clusterId={clusterId} />,
-  document.getElementById('cluster-settings-button')
+// the button is only rendered for the users allowed to change the settings
+const settingsButtonContainer = document.getElementById(
+  'cluster-settings-button'
 );
+if (settingsButtonContainer) {
+  ReactDOM.render(
+    <SettingsButton clusterId={clusterId} />,
+    settingsButtonContainer
+  );
+}
@@ -0,0 +1,34 @@
+/* eslint-disable no-undef */
+$(() => {
+  function post(url, elm) {
+    elm.disabled = true;
+
+    fetch(url, { method: 'POST' })
+      .then((res) => {
+        if (!res.ok) {
+          throw new Error(res.statusText);
+        }
+        window.location.reload();
+      })
+      .catch((e) => {
+        elm.disabled = false;
+        console.error(e);
+      });
+  }
+
+  const inspectButton = document.getElementById('db-inspect');
+  if (inspectButton) {
+    inspectButton.addEventListener('click', () =>
+      post('/api/database/maintenance/inspect', inspectButton)
+    );
+  }
+
+  document.querySelectorAll('.db-maintenance-run').forEach((elm) => {
+    elm.addEventListener('click', () =>
+      post(
+        `/api/database/maintenance/tables/${elm.getAttribute('data-table')}`,
+        elm
+      )
+    );
+  });
+});
@@ -0,0 +1,35 @@
+/* eslint-disable no-undef */
+$(() => {
+  function toggleFavorite(elm) {
+    const resourceType = elm.getAttribute('data-resource-type');
+    const resourceId = elm.getAttribute('data-resource-id');
+    const isFavorite = elm.getAttribute('data-favorite') === 'true';
+
+    const request = isFavorite
+      ? fetch(`/api/favorites/${resourceType}/${resourceId}`, {
+          method: 'DELETE',
+        })
+      : fetch('/api/favorites', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({
+            resource_type: resourceType,
+            resource_id: resourceId,
+          }),
+        });
+
+    request
+      .then((res) => {
+        if (!res.ok) {
+          throw new Error(res.statusText);
+        }
+        elm.setAttribute('data-favorite', String(!isFavorite));
+        elm.textContent = isFavorite ? 'star_border' : 'star';
+      })
+      .catch((e) => console.error(e));
+  }
+
+  document.querySelectorAll('.favorite-toggle').forEach((elm) => {
+    elm.addEventListener('click', () => toggleFavorite(elm));
+  });
+});
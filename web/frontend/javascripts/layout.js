@@ -1,7 +1,156 @@
+// the CSRF token of the session is sent along with every state changing request
+const csrfToken = () => $('meta[name="csrf-token"]').attr('content') || '';
+
+// the permissions of the logged in user, to hide the actions the user cannot perform
+const userPermissions = () =>
+  ($('meta[name="user-permissions"]').attr('content') || '')
+    .split(' ')
+    .filter((permission) => permission);
+
+window.userCan = (permission) => userPermissions().includes(permission);
+
+const isSafeMethod = (method) =>
+  ['GET', 'HEAD', 'OPTIONS'].includes((method || 'GET').toUpperCase());
+
+const originalFetch = window.fetch;
+window.fetch = function (resource, init) {
+  const url = new URL(resource.url || resource, window.location.href);
+  const method = (init && init.method) || resource.method;
+
+  if (url.origin !== window.location.origin || isSafeMethod(method)) {
+    return originalFetch(resource, init);
+  }
+
+  const headers = new Headers((init && init.headers) || resource.headers);
+  headers.set('X-CSRF-Token', csrfToken());
+
+  return originalFetch(resource, { ...init, headers });
+};
+
+$.ajaxSetup({
+  beforeSend: function (xhr, settings) {
+    if (!settings.crossDomain && !isSafeMethod(settings.type)) {
+      xhr.setRequestHeader('X-CSRF-Token', csrfToken());
+    }
+  },
+});
+
+// shows the progress of the projection of the backlog collected before the startup, until it is done
+const backlogPollInterval = 5000;
+
+const pollProjectionBacklog = (banner, wasInProgress) => {
+  $.getJSON('/api/pipeline/backlog')
+    .done(({ in_progress, total, projected }) => {
+      if (in_progress) {
+        banner
+          .find('.projection-backlog-progress')
+          .text(`${projected}/${total} events projected`);
+        banner.removeClass('d-none');
+        setTimeout(() => pollProjectionBacklog(banner, true), backlogPollInterval);
+      } else if (wasInProgress) {
+        banner
+          .removeClass('alert-info')
+          .addClass('alert-success')
+          .text('The data is up to date, reload the page to see it.');
+      }
+    })
+    .fail(() => banner.addClass('d-none'));
+};
+
+// warns about the limits of the license approaching or exceeded
+const entitlementsWarnings = {
+  hosts_limit_approaching: ({ monitored_hosts, hosts_limit }) =>
+    `${monitored_hosts} of the ${hosts_limit} hosts of the license are monitored.`,
+  hosts_limit_exceeded: ({ monitored_hosts, hosts_limit, enforcement }) =>
+    `${monitored_hosts} hosts are monitored, beyond the ${hosts_limit} of the license.` +
+    (enforcement === 'hard' ? ' The new hosts are refused.' : ''),
+  license_expiring: ({ expires_at }) =>
+    `The license expires on ${new Date(expires_at).toLocaleDateString()}.`,
+  license_expired: () =>
+    'The license is expired, the premium checks are disabled.',
+};
+
+const showEntitlementsWarnings = (banner) => {
+  $.getJSON('/api/entitlements').done((entitlements) => {
+    const warnings = (entitlements.warnings || [])
+      .filter((warning) => entitlementsWarnings[warning])
+      .map((warning) => entitlementsWarnings[warning](entitlements));
+
+    if (warnings.length) {
+      banner.text(warnings.join(' ')).removeClass('d-none');
+    }
+  });
+};
+
+// tells that the changes are refused while the console is in maintenance mode
+const showMaintenanceMode = (banner) => {
+  $.getJSON('/api/maintenance').done(({ enabled, message, pause_collection }) => {
+    if (!enabled) {
+      return;
+    }
+
+    const text =
+      'The console is in maintenance mode, the changes are refused.' +
+      (pause_collection ? ' The data collection is paused.' : '') +
+      (message ? ` ${message}` : '');
+    banner.text(text).removeClass('d-none');
+  });
+};
+
+// tells that the changes are not allowed while the console is in read-only mode
+const showReadOnlyMode = (banner) => {
+  $.getJSON('/api/read-only').done(({ enabled, reason }) => {
+    if (!enabled) {
+      return;
+    }
+
+    const text =
+      'The console is in read-only mode, the changes are not allowed.' +
+      (reason ? ` ${reason}` : '');
+    banner.text(text).removeClass('d-none');
+  });
+};
+
 $(document).ready(function () {
+  const maintenanceBanner = $('#maintenance-banner');
+  if (maintenanceBanner.length) {
+    showMaintenanceMode(maintenanceBanner);
+  }
+
+  const readOnlyBanner = $('#read-only-banner');
+  if (readOnlyBanner.length) {
+    showReadOnlyMode(readOnlyBanner);
+  }
+
+  const entitlementsBanner = $('#entitlements-banner');
+  if (entitlementsBanner.length) {
+    showEntitlementsWarnings(entitlementsBanner);
+  }
+
+  $('#impersonation-banner .stop-impersonation').click(function () {
+    $.ajax({ url: '/api/impersonation', type: 'DELETE' })
+      .done(() => window.location.reload())
+      .fail(() => window.location.reload());
+  });
+
+  const backlogBanner = $('#projection-backlog-banner');
+  if (backlogBanner.length) {
+    pollProjectionBacklog(backlogBanner, false);
+  }
+
   // enable bootstrap tooltips
   $('[data-toggle="tooltip"]').tooltip();
 
   let now = new Date();
   $('#last_update').html(now.toLocaleString());
+
+  $('form[method="POST"], form[method="post"]').each(function () {
+    if ($(this).find('input[name="csrf_token"]').length === 0) {
+      $('<input>', {
+        type: 'hidden',
+        name: 'csrf_token',
+        value: csrfToken(),
+      }).appendTo(this);
+    }
+  });
 });
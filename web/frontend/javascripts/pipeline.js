@@ -0,0 +1,86 @@
+/* eslint-disable no-undef */
+$(() => {
+  function send(method, url, body, elm) {
+    elm.disabled = true;
+
+    fetch(url, {
+      method,
+      headers: { 'Content-Type': 'application/json' },
+      body: body && JSON.stringify(body),
+    })
+      .then((res) => {
+        if (!res.ok) {
+          throw new Error(res.statusText);
+        }
+        window.location.reload();
+      })
+      .catch((e) => {
+        elm.disabled = false;
+        console.error(e);
+      });
+  }
+
+  document.querySelectorAll('.agent-review').forEach((button) => {
+    button.addEventListener('click', () =>
+      send(
+        'POST',
+        `/api/agents/${encodeURIComponent(button.dataset.agentId)}/${
+          button.dataset.action
+        }`,
+        null,
+        button
+      )
+    );
+  });
+
+  document.querySelectorAll('.projector-toggle').forEach((button) => {
+    button.addEventListener('click', () =>
+      send(
+        'POST',
+        `/api/pipeline/projectors/${encodeURIComponent(
+          button.dataset.projectorId
+        )}/${button.dataset.action}`,
+        null,
+        button
+      )
+    );
+  });
+
+  document.querySelectorAll('.inconsistency-fix').forEach((button) => {
+    button.addEventListener('click', () =>
+      send(
+        'POST',
+        `/api/pipeline/inconsistencies/${encodeURIComponent(
+          button.dataset.kind
+        )}/${encodeURIComponent(button.dataset.resourceId)}/fix`,
+        null,
+        button
+      )
+    );
+  });
+
+  const startButton = document.getElementById('payload-capture-start');
+  startButton.addEventListener('click', () => {
+    const value = (name) =>
+      document.querySelector(`#payload-capture [name="${name}"]`).value;
+
+    send(
+      'PUT',
+      '/api/pipeline/capture',
+      {
+        sampling_percentage: parseInt(value('sampling_percentage'), 10) || 0,
+        agent_id: value('agent_id'),
+        max_size_bytes: parseInt(value('max_size_bytes'), 10) || 0,
+        duration_minutes: parseInt(value('duration_minutes'), 10) || 0,
+      },
+      startButton
+    );
+  });
+
+  const stopButton = document.getElementById('payload-capture-stop');
+  if (stopButton) {
+    stopButton.addEventListener('click', () =>
+      send('DELETE', '/api/pipeline/capture', null, stopButton)
+    );
+  }
+});
@@ -2,8 +2,13 @@
 $(() => {
   function initTags() {
     const inputs = document.querySelectorAll('.tags-input');
+    const canEditTags = window.userCan('tags:write');
 
     inputs.forEach((elm) => {
+      if (!canEditTags) {
+        elm.setAttribute('readonly', '');
+      }
+
       const tagify = new Tagify(elm, {
         whitelist: [],
         editTags: false,
@@ -18,7 +23,11 @@ $(() => {
       });
 
       // Add tags by clicking on the input
-      tagify.DOM.scope.addEventListener('click', (_e) => tagify.addEmptyTag());
+      if (canEditTags) {
+        tagify.DOM.scope.addEventListener('click', (_e) =>
+          tagify.addEmptyTag()
+        );
+      }
 
       const resourceType = elm.getAttribute('data-resource-type');
       const resourceId = elm.getAttribute('data-resource-id');
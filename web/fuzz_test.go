@@ -0,0 +1,161 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// The fuzz targets serve the handlers without the Recovery middleware, so that a panic fails them.
+// The mocks only accept the values the database can store, any other one panicking as an unexpected call.
+// The tests run from the root of the repository, so the corpora are kept in test/fixtures/fuzz instead of
+// testdata/fuzz, as raw request bodies. The inputs failing `make fuzz` are to be added there as regressions
+
+func storableText(value string) bool {
+	return utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+}
+
+func storableIdentifier(value string) bool {
+	return storableText(value) && len(value) <= maxIdentifierLength
+}
+
+// addFuzzCorpus seeds the fuzz target with the inputs in test/fixtures/fuzz/<name of the target>
+func addFuzzCorpus(f *testing.F, add func(input []byte)) {
+	dir := path.Join("test/fixtures/fuzz", f.Name())
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		input, err := os.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			f.Fatal(err)
+		}
+		add(input)
+	}
+}
+
+func fuzzEngine() *gin.Engine {
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+
+	return engine
+}
+
+func serveFuzzed(t *testing.T, engine *gin.Engine, method string, target string, body []byte) {
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	engine.ServeHTTP(resp, req)
+
+	if resp.Code >= 500 {
+		t.Fatalf("%s %s answered %d: %s", method, target, resp.Code, resp.Body.String())
+	}
+}
+
+func fuzzedAgentsService() services.AgentsService {
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("Admit", mock.MatchedBy(storableIdentifier)).Return(models.AgentStatusApproved, nil)
+
+	return agentsService
+}
+
+func FuzzApiCollectDataHandler(f *testing.F) {
+	addFuzzCorpus(f, func(input []byte) { f.Add(input) })
+	f.Add([]byte(`{"agent_id": "779cdd70-e9e2-58ca-b18a-bf3eb3f71244", "discovery_type": "host_discovery", "payload": {"hostname": "host1"}}`))
+	f.Add([]byte(`{"agent_id": "779cdd70-e9e2-58ca-b18a-bf3eb3f71244", "discovery_type": "sap_system_discovery", "payload": []}`))
+	f.Add([]byte(`{"agent_id": "agent1", "discovery_type": "host_discovery", "payload": null}`))
+	f.Add([]byte(`{"agent_id": 1}`))
+
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return storableIdentifier(e.AgentID) && storableIdentifier(e.DiscoveryType) && validatePayload(e.Payload) == nil
+	})).Return(nil)
+
+	engine := fuzzEngine()
+	engine.POST("/api/collect", ApiCollectDataHandler(collectorService, newMockedPayloadCaptureService(), fuzzedAgentsService(), newMockedEntitlementsService(), newMockedPayloadSignaturesService()))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		serveFuzzed(t, engine, "POST", "/api/collect", body)
+	})
+}
+
+func FuzzApiHostHeartbeatHandler(f *testing.F) {
+	addFuzzCorpus(f, func(input []byte) { f.Add(string(input)) })
+	f.Add("779cdd70-e9e2-58ca-b18a-bf3eb3f71244")
+	f.Add("agent\x00")
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("Heartbeat", mock.MatchedBy(storableIdentifier)).Return(nil)
+
+	engine := fuzzEngine()
+	engine.POST("/api/hosts/:id/heartbeat", ApiHostHeartbeatHandler(hostsService, fuzzedAgentsService(), newMockedEntitlementsService(), newMockedAgentLogsService()))
+
+	f.Fuzz(func(t *testing.T, agentID string) {
+		serveFuzzed(t, engine, "POST", "/api/hosts/"+url.PathEscape(agentID)+"/heartbeat", nil)
+	})
+}
+
+func FuzzApiHostCreateTagHandler(f *testing.F) {
+	addFuzzCorpus(f, func(input []byte) { f.Add(input) })
+	f.Add([]byte(`{"tag": "production"}`))
+	f.Add([]byte(`{"tag": ""}`))
+	f.Add([]byte(`["production"]`))
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "host1").Return(&models.Host{ID: "host1"}, nil)
+
+	tagsService := new(services.MockTagsService)
+	tagsService.On("Create", mock.MatchedBy(storableIdentifier), models.TagHostResourceType, "host1").Return(nil)
+
+	engine := fuzzEngine()
+	engine.POST("/api/hosts/:id/tags", ApiHostCreateTagHandler(hostsService, tagsService, newMockedAuditService()))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		serveFuzzed(t, engine, "POST", "/api/hosts/host1/tags", body)
+	})
+}
+
+func FuzzApiCreateChecksCatalogHandler(f *testing.F) {
+	addFuzzCorpus(f, func(input []byte) { f.Add(input) })
+	f.Add([]byte(`[{"id": "1.1.1", "name": "check 1", "group": "group 1", "description": "description 1", "premium": true}]`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(`{"id": "1.1.1"}`))
+
+	checksService := new(services.MockChecksService)
+	checksService.On("GetChecksCatalog").Return(models.ChecksCatalog{}, nil)
+	checksService.On("CreateChecksCatalog", mock.MatchedBy(func(catalog models.ChecksCatalog) bool {
+		for _, check := range catalog {
+			for _, value := range []string{check.Name, check.Group, check.Description, check.Remediation, check.Implementation, check.Labels} {
+				if !storableText(value) {
+					return false
+				}
+			}
+
+			if !storableIdentifier(check.ID) {
+				return false
+			}
+		}
+
+		return true
+	})).Return(nil)
+
+	engine := fuzzEngine()
+	engine.PUT("/api/checks/catalog", ApiCreateChecksCatalogHandler(checksService, newMockedAuditService()))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		serveFuzzed(t, engine, "PUT", "/api/checks/catalog", body)
+	})
+}
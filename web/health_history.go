@@ -0,0 +1,91 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/services"
+)
+
+var healthHistoryRecorderInterval = 1 * time.Minute
+
+// HealthHistoryRecorder periodically stores the health of the resources that changed,
+// the hosts health depends on the heartbeats and changes without any event to project
+type HealthHistoryRecorder struct {
+	healthHistoryService services.HealthHistoryService
+}
+
+func NewHealthHistoryRecorder(healthHistoryService services.HealthHistoryService) *HealthHistoryRecorder {
+	return &HealthHistoryRecorder{healthHistoryService: healthHistoryService}
+}
+
+func (r *HealthHistoryRecorder) Start(ctx context.Context) {
+	log.Infof("Starting health history recorder")
+
+	internal.Repeat("web.health_history_recorder", r.record, healthHistoryRecorderInterval, ctx)
+}
+
+func (r *HealthHistoryRecorder) record() {
+	if err := r.healthHistoryService.RecordCurrent(); err != nil {
+		log.Errorf("Error while recording the health history: %s", err)
+	}
+}
+
+// ApiResourceHealthHandler godoc
+// @Summary Retrieve the health of a host, cluster, SAP system or database, as it was at the given time
+// @Produce json
+// @Param id path string true "Resource id"
+// @Param at query string false "RFC 3339 timestamp, now by default"
+// @Success 200 {object} models.HealthRecord
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/health [get]
+// @Router /clusters/{id}/health [get]
+// @Router /sapsystems/{id}/health [get]
+// @Router /databases/{id}/health [get]
+// The resource id is read from the idParam path parameter
+func ApiResourceHealthHandler(resourceType string, idParam string, healthHistoryService services.HealthHistoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param(idParam)
+
+		at, err := parseAtQuery(c)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		record, err := healthHistoryService.GetAt(resourceType, id, at)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if record == nil {
+			_ = c.Error(NotFoundError("no health recorded at the given time"))
+			return
+		}
+
+		c.JSON(http.StatusOK, record)
+	}
+}
+
+// parseAtQuery returns the time of the at query parameter, now if missing
+func parseAtQuery(c *gin.Context) (time.Time, error) {
+	value := c.Query("at")
+	if value == "" {
+		return time.Now(), nil
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, BadRequestError("at must be an RFC 3339 timestamp")
+	}
+
+	return at, nil
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiResourceHealthHandler(t *testing.T) {
+	at := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+	healthHistoryService := new(services.MockHealthHistoryService)
+	healthHistoryService.On("GetAt", models.HealthResourceCluster, "cluster1", at).Return(&models.HealthRecord{
+		ResourceType: models.HealthResourceCluster,
+		ResourceID:   "cluster1",
+		Health:       models.CheckCritical,
+		Since:        time.Date(2022, time.March, 12, 9, 30, 0, 0, time.UTC),
+	}, nil)
+	healthHistoryService.On("GetAt", models.HealthResourceHost, "host1", at).Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.healthHistoryService = healthHistoryService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/clusters/cluster1/health?at=2022-03-12T10:00:00Z", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"resource_type": "cluster",
+		"resource_id": "cluster1",
+		"health": "critical",
+		"since": "2022-03-12T09:30:00Z"
+	}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/hosts/host1/health?at=2022-03-12T10:00:00Z", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/hosts/host1/health?at=yesterday", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestApiSAPSystemsHealthSummaryHandlerAt(t *testing.T) {
+	at := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+	healthSummaryService := new(services.MockHealthSummaryService)
+	healthSummaryService.On("GetHealthSummaryAt", "", at).Return(models.HealthSummary{
+		{ID: "sap_system_id", SID: "HA1", SAPSystemHealth: models.HealthSummaryHealthCritical},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.healthSummaryService = healthSummaryService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/sapsystems/health?at=2022-03-12T10:00:00Z", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"sapsystem_health":"critical"`)
+	healthSummaryService.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// The audit entries are the events of the changes made by the users, their sequential IDs ordering the
+// changes regardless of the clocks of the servers and browsers involved, and versioning the resources
+
+// lastChangeID returns the ID of the latest change of a kind to a resource, 0 if it was never changed
+func lastChangeID(auditService services.AuditService, action string, resourceType string, resourceID string) (int64, error) {
+	entries, err := auditService.GetAll(&services.AuditFilter{
+		Actions:      []string{action},
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+	}, &services.Page{Number: 1, Size: 1})
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+
+	return entries[0].ID, nil
+}
+
+// changesSince returns the changes of a kind to a resource made after the given one, the most recent first
+func changesSince(auditService services.AuditService, action string, resourceType string, resourceID string, id int64) ([]*models.AuditEntry, error) {
+	return auditService.GetAll(&services.AuditFilter{
+		Actions:      []string{action},
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		AfterID:      id,
+	}, nil)
+}
+
+// ApiResourceHistoryHandler godoc
+// @Summary List the changes made by the users to the tags and settings of a host, cluster, SAP system or database, the most recent first
+// @Produce json
+// @Param id path string true "Resource id"
+// @Param page query int false "Page number"
+// @Param per_page query int false "Entries per page"
+// @Success 200 {object} []models.AuditEntry
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/history [get]
+// @Router /clusters/{id}/history [get]
+// @Router /sapsystems/{id}/history [get]
+// @Router /databases/{id}/history [get]
+// The resource id is read from the idParam path parameter
+func ApiResourceHistoryHandler(resourceType string, idParam string, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := auditService.GetAll(&services.AuditFilter{
+			ResourceType: resourceType,
+			ResourceID:   c.Param(idParam),
+		}, auditPageFromQuery(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, entries)
+	}
+}
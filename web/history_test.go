@@ -0,0 +1,123 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiResourceHistoryHandler(t *testing.T) {
+	auditService := new(services.MockAuditService)
+	auditService.On("GetAll", &services.AuditFilter{
+		ResourceType: models.TagClusterResourceType,
+		ResourceID:   "cluster1",
+	}, &services.Page{Number: 1, Size: 10}).Return([]*models.AuditEntry{
+		{
+			ID:           2,
+			Time:         time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC),
+			Actor:        "admin",
+			Action:       models.AuditActionTagCreated,
+			ResourceType: models.TagClusterResourceType,
+			ResourceID:   "cluster1",
+			After:        map[string]interface{}{"tag": "prod"},
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/clusters/cluster1/history?per_page=10", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"id": 2,
+		"time": "2022-03-12T10:00:00Z",
+		"actor": "admin",
+		"action": "tag_created",
+		"resource_type": "clusters",
+		"resource_id": "cluster1",
+		"before": null,
+		"after": {"tag": "prod"}
+	}]`, resp.Body.String())
+}
+
+func TestApiCheckCreateSettingsByIdHandlerConflicts(t *testing.T) {
+	checksService := new(services.MockChecksService)
+	checksService.On("GetSelectedChecksById", "cluster1").Return(models.SelectedChecks{}, nil)
+	checksService.On("GetConnectionSettingsById", "cluster1").Return(map[string]models.ConnectionSettings{}, nil)
+	checksService.On("CreateSelectedChecks", "cluster1", []string{"ABCDEF"}).Return(nil)
+
+	concurrentChange := &models.AuditEntry{
+		ID:           8,
+		Actor:        "other-admin",
+		Action:       models.AuditActionChecksSettingsSaved,
+		ResourceType: models.TagClusterResourceType,
+		ResourceID:   "cluster1",
+	}
+
+	settingsFilter := func(afterID int64) *services.AuditFilter {
+		return &services.AuditFilter{
+			Actions:      []string{models.AuditActionChecksSettingsSaved},
+			ResourceType: models.TagClusterResourceType,
+			ResourceID:   "cluster1",
+			AfterID:      afterID,
+		}
+	}
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+	auditService.On("GetAll", settingsFilter(5), (*services.Page)(nil)).Return([]*models.AuditEntry{concurrentChange}, nil)
+	auditService.On("GetAll", settingsFilter(8), (*services.Page)(nil)).Return([]*models.AuditEntry{}, nil)
+	auditService.On("GetAll", settingsFilter(0), &services.Page{Number: 1, Size: 1}).Return([]*models.AuditEntry{{ID: 9}}, nil)
+
+	deps := setupTestDependencies()
+	deps.checksService = checksService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for baseVersion, expectedConflicts := range map[int64][]*models.AuditEntry{
+		5: {concurrentChange},
+		8: nil,
+	} {
+		version := baseVersion
+		body, _ := json.Marshal(&JSONChecksSettings{
+			SelectedChecks:     []string{"ABCDEF"},
+			ConnectionSettings: map[string]string{},
+			Version:            &version,
+		})
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/checks/cluster1/settings", bytes.NewBuffer(body))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		app.webEngine.ServeHTTP(resp, req)
+
+		var saved JSONChecksSettings
+		json.Unmarshal(resp.Body.Bytes(), &saved)
+
+		// the last save wins, the overwritten changes are reported
+		assert.Equal(t, 201, resp.Code)
+		assert.Equal(t, int64(9), *saved.Version)
+		assert.Equal(t, expectedConflicts, saved.Conflicts)
+	}
+
+	checksService.AssertNumberOfCalls(t, "CreateSelectedChecks", 2)
+}
@@ -0,0 +1,156 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+const (
+	// maxHostMetricsSamples bounds the samples pushed at once by an agent
+	maxHostMetricsSamples = 360
+	// hostMetricsClockSkew is how far in the future the samples may be, the clocks of the hosts drifting
+	hostMetricsClockSkew = 5 * time.Minute
+	// hostMetricsPagePeriod is the period of time of the recent utilization shown in the host details
+	hostMetricsPagePeriod = time.Hour
+)
+
+// ApiPushHostMetricsHandler stores the resources usage samples taken by the agent since its last push
+func ApiPushHostMetricsHandler(agentsService services.AgentsService, entitlementsService services.EntitlementsService, hostMetricsService services.HostMetricsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agentID := c.Param("id")
+
+		if err := validateText("agent ID", agentID, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !checkAgentID(c, agentID) {
+			return
+		}
+
+		var samples []*hosts.MetricsSample
+		if err := c.BindJSON(&samples); err != nil {
+			_ = c.Error(BadRequestError("unable to parse the JSON array of samples"))
+			return
+		}
+
+		if err := validateMetricsSamples(samples); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		status, ok := admitAgent(c, agentsService, entitlementsService, agentID)
+		if !ok {
+			return
+		}
+
+		// the hosts of the agents pending approval are not shown yet
+		if status == models.AgentStatusPending {
+			c.JSON(http.StatusNoContent, gin.H{})
+			return
+		}
+
+		if err := hostMetricsService.Store(agentID, samples); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Writer.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func validateMetricsSamples(samples []*hosts.MetricsSample) error {
+	if len(samples) == 0 || len(samples) > maxHostMetricsSamples {
+		return BadRequestError(fmt.Sprintf("a push must contain between 1 and %d samples", maxHostMetricsSamples))
+	}
+
+	now := time.Now()
+	for i, sample := range samples {
+		if sample == nil || sample.Time.IsZero() {
+			return BadRequestError(fmt.Sprintf("sample %d: time is required", i))
+		}
+
+		if sample.Time.After(now.Add(hostMetricsClockSkew)) || sample.Time.Before(now.Add(-models.HostMetricsRetention)) {
+			return BadRequestError(fmt.Sprintf("sample %d: time must be within the last %s", i, models.HostMetricsRetention))
+		}
+
+		for _, percent := range []float64{sample.CPUPercent, sample.MemoryPercent, sample.DiskPercent} {
+			if percent < 0 || percent > 100 {
+				return BadRequestError(fmt.Sprintf("sample %d: the usage must be a percentage", i))
+			}
+		}
+	}
+
+	return nil
+}
+
+// ApiHostMetricsHandler godoc
+// @Summary Get the recent CPU, memory and disk usage of a host as sparklines, from the samples pushed by its agent
+// @Produce json
+// @Param id path string true "Host id"
+// @Param minutes query int false "Period of time, in minutes, to get the usage of (1 to 1440, default 60)"
+// @Param points query int false "Number of points of the sparklines (1 to 360, default 60)"
+// @Success 200 {object} models.HostMetricsSparkline
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/metrics [get]
+func ApiHostMetricsHandler(hostsService services.HostsService, hostMetricsService services.HostMetricsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		minutes, err := boundedQueryInt(c, "minutes", int(hostMetricsPagePeriod.Minutes()), int(models.HostMetricsRetention.Minutes()))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		points, err := boundedQueryInt(c, "points", models.HostMetricsSparklinePoints, maxHostMetricsSamples)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		host, err := hostsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		sparkline, err := hostMetricsService.GetSparkline(id, time.Duration(minutes)*time.Minute, points)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sparkline)
+	}
+}
+
+// boundedQueryInt returns the query parameter, between 1 and max, or its default value if not given
+func boundedQueryInt(c *gin.Context, name string, defaultValue int, max int) (int, error) {
+	query := c.Query(name)
+	if query == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(query)
+	if err != nil || value < 1 || value > max {
+		return 0, BadRequestError(fmt.Sprintf("invalid %s: %s", name, query))
+	}
+
+	return value, nil
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiPushHostMetricsHandler(t *testing.T) {
+	sampledAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+
+	hostMetricsService := new(services.MockHostMetricsService)
+	hostMetricsService.On("Store", "agent1", mock.MatchedBy(func(samples []*hosts.MetricsSample) bool {
+		return len(samples) == 1 && samples[0].Time.Equal(sampledAt) && samples[0].CPUPercent == 12.5
+	})).Return(nil).Once()
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/hosts/:id/metrics", ApiPushHostMetricsHandler(newMockedAgentsService(), newMockedEntitlementsService(), hostMetricsService))
+
+	for _, body := range []string{
+		`{}`,
+		`[]`,
+		`[{"cpu_percent": 10}]`,
+		fmt.Sprintf(`[{"time": %q, "cpu_percent": 101}]`, sampledAt.Format(time.RFC3339)),
+		fmt.Sprintf(`[{"time": %q, "cpu_percent": 10}]`, sampledAt.Add(time.Hour).Format(time.RFC3339)),
+		fmt.Sprintf(`[{"time": %q, "cpu_percent": 10}]`, sampledAt.Add(-48*time.Hour).Format(time.RFC3339)),
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/hosts/agent1/metrics", bytes.NewBufferString(body))
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code, body)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/hosts/agent1/metrics", bytes.NewBufferString(fmt.Sprintf(
+		`[{"time": %q, "cpu_percent": 12.5, "memory_percent": 50, "disk_percent": 71}]`, sampledAt.Format(time.RFC3339))))
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	hostMetricsService.AssertExpectations(t)
+}
+
+func TestApiHostMetricsHandler(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "1").Return(&models.Host{ID: "1", Name: "host1"}, nil)
+	hostsService.On("GetByID", "unknown").Return(nil, nil)
+
+	cpu := 12.5
+	hostMetricsService := new(services.MockHostMetricsService)
+	hostMetricsService.On("GetSparkline", "1", 30*time.Minute, 2).Return(&models.HostMetricsSparkline{
+		Since:       time.Date(2022, 3, 10, 8, 0, 0, 0, time.UTC),
+		StepSeconds: 900,
+		CPU:         []*float64{nil, &cpu},
+		Memory:      []*float64{nil, &cpu},
+		Disk:        []*float64{nil, &cpu},
+	}, nil)
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.GET("/hosts/:id/metrics", ApiHostMetricsHandler(hostsService, hostMetricsService))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts/1/metrics?minutes=30&points=2", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"since": "2022-03-10T08:00:00Z",
+		"step_seconds": 900,
+		"cpu": [null, 12.5],
+		"memory": [null, 12.5],
+		"disk": [null, 12.5]
+	}`, resp.Body.String())
+
+	for url, code := range map[string]int{
+		"/hosts/1/metrics?minutes=0":    400,
+		"/hosts/1/metrics?minutes=1441": 400,
+		"/hosts/1/metrics?points=abc":   400,
+		"/hosts/unknown/metrics":        404,
+	} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", url, nil)
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, code, resp.Code, url)
+	}
+}
@@ -1,15 +1,27 @@
 package web
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/trento-project/trento/internal/hosts"
 	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
 
+const (
+	// hostUtilizationPageDays is the period of time, in days, of the utilization trends shown in the host details
+	hostUtilizationPageDays = 7
+	// hostTimelinePageDays is the period of time, in days, of the timeline shown in the host details
+	hostTimelinePageDays = 7
+)
+
 func NewHostsHealthContainer(hostList models.HostList) *HealthContainer {
 	h := &HealthContainer{}
 	for _, host := range hostList {
@@ -25,14 +37,23 @@ func NewHostsHealthContainer(hostList models.HostList) *HealthContainer {
 	return h
 }
 
-func NewHostListHandler(hostsService services.HostsService) gin.HandlerFunc {
+func NewHostListHandler(hostsService services.HostsService, favoritesService services.FavoritesService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		query := c.Request.URL.Query()
 
+		favorites, err := getFavorites(c, favoritesService, models.TagHostResourceType)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		hostsFilter := &services.HostsFilter{
-			SIDs:   query["sids"],
-			Health: query["health"],
-			Tags:   query["tags"],
+			SIDs:             query["sids"],
+			Health:           query["health"],
+			Tags:             query["tags"],
+			TemplateVersions: query["template_version"],
+			Pinned:           favorites,
+			Organization:     userOrganization(c),
 		}
 
 		pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -54,6 +75,7 @@ func NewHostListHandler(hostsService services.HostsService) gin.HandlerFunc {
 			_ = c.Error(err)
 			return
 		}
+		markFavoriteHosts(paginatedHostList, favorites)
 
 		hostList, err := hostsService.GetAll(hostsFilter, nil)
 		if err != nil {
@@ -73,37 +95,224 @@ func NewHostListHandler(hostsService services.HostsService) gin.HandlerFunc {
 			return
 		}
 
+		filterTemplateVersions, err := hostsService.GetAllTemplateVersions()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		pagination := NewPagination(len(hostList), pageNumber, pageSize)
 
 		hContainer := NewHostsHealthContainer(hostList)
 		hContainer.Layout = "horizontal"
 
 		c.HTML(http.StatusOK, "hosts.html.tmpl", gin.H{
-			"Hosts":           paginatedHostList,
-			"AppliedFilters":  query,
-			"FilterSIDs":      filterSIDs,
-			"FilterTags":      filterTags,
-			"Pagination":      pagination,
-			"HealthContainer": hContainer,
+			"Hosts":                  paginatedHostList,
+			"AppliedFilters":         query,
+			"FilterSIDs":             filterSIDs,
+			"FilterTags":             filterTags,
+			"FilterTemplateVersions": filterTemplateVersions,
+			"Pagination":             pagination,
+			"HealthContainer":        hContainer,
 		})
 	}
 }
 
-func ApiHostHeartbeatHandler(hostService services.HostsService) gin.HandlerFunc {
+func ApiHostHeartbeatHandler(hostService services.HostsService, agentsService services.AgentsService, entitlementsService services.EntitlementsService, agentLogsService services.AgentLogsService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		agentID := c.Param("id")
 
+		if err := validateText("agent ID", agentID, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !checkAgentID(c, agentID) {
+			return
+		}
+
+		status, ok := admitAgent(c, agentsService, entitlementsService, agentID)
+		if !ok {
+			return
+		}
+
+		// the hosts of the agents pending approval are not shown yet
+		if status == models.AgentStatusPending {
+			c.JSON(http.StatusNoContent, gin.H{})
+			return
+		}
+
 		err := hostService.Heartbeat(agentID)
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
 
+		if certificate, ok := agentCertificate(c); ok {
+			err = hostService.UpdateCertificateFingerprint(agentID, certificateFingerprint(certificate))
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+
+		logsRequested, err := agentLogsService.IsRequested(agentID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if logsRequested {
+			c.Header(hosts.LogsRequestedHeader, "true")
+		}
+
 		c.JSON(http.StatusNoContent, gin.H{})
 	}
 }
 
-func NewHostHandler(hostsService services.HostsService, subsService services.SubscriptionsService, monitoringURL string) gin.HandlerFunc {
+// maxRegisteredDiscoveries bounds the discoveries an agent registers with, the agents run a handful of them
+const maxRegisteredDiscoveries = 64
+
+func ApiHostRegisterHandler(agentsService services.AgentsService, entitlementsService services.EntitlementsService, agentUpgradesService services.AgentUpgradesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agentID := c.Param("id")
+
+		if err := validateText("agent ID", agentID, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !checkAgentID(c, agentID) {
+			return
+		}
+
+		var registration hosts.AgentRegistration
+		if err := c.ShouldBindJSON(&registration); err != nil {
+			_ = c.Error(BadRequestError("invalid registration: " + err.Error()))
+			return
+		}
+
+		if err := validateRegistration(&registration); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		// the registration of the agents pending approval is recorded, to help reviewing them
+		if _, ok := admitAgent(c, agentsService, entitlementsService, agentID); !ok {
+			return
+		}
+
+		if err := agentsService.Register(agentID, &registration); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		// the agent upgraded registers again once restarted
+		if err := agentUpgradesService.Complete(agentID, registration.Version); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+func validateRegistration(registration *hosts.AgentRegistration) error {
+	if registration.Version == "" {
+		return BadRequestError("the agent version is required")
+	}
+
+	if len(registration.Discoveries) > maxRegisteredDiscoveries {
+		return BadRequestError(fmt.Sprintf("at most %d discoveries can be registered", maxRegisteredDiscoveries))
+	}
+
+	for _, field := range []struct{ name, value string }{
+		{"version", registration.Version},
+		{"OS name", registration.OSName},
+		{"OS version", registration.OSVersion},
+		{"kernel version", registration.KernelVersion},
+		{"architecture", registration.Arch},
+	} {
+		if err := validateText(field.name, field.value, maxIdentifierLength); err != nil {
+			return err
+		}
+	}
+
+	for _, discovery := range registration.Discoveries {
+		if err := validateText("discovery", discovery, maxIdentifierLength); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApiDecommissionHostHandler godoc
+// @Summary Decommission a retired host, removing it with all the data of its agent, the collected events included
+// @Description A first request without the confirmation token is refused, returning the token to confirm the decommission with
+// @Produce json
+// @Param id path string true "Host id"
+// @Param confirmation_token query string false "Token returned by the request without it"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 428 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id} [delete]
+func ApiDecommissionHostHandler(hostsService services.HostsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		host, err := hostsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		token := decommissionToken(host)
+		if subtle.ConstantTimeCompare([]byte(c.Query("confirmation_token")), []byte(token)) != 1 {
+			_ = c.Error(PreconditionRequiredError(fmt.Sprintf("confirm the decommission of the host %s with the confirmation token", host.Name))).
+				SetMeta(gin.H{"confirmation_token": token})
+			return
+		}
+
+		host, err = hostsService.Decommission(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionHostDecommissioned, models.TagHostResourceType, id, host, nil)
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// decommissionToken guards against the accidental decommissions, it is not a secret
+func decommissionToken(host *models.Host) string {
+	sum := sha256.Sum256([]byte("decommission:" + host.ID + ":" + host.Name))
+	return hex.EncodeToString(sum[:8])
+}
+
+func NewHostHandler(
+	hostsService services.HostsService,
+	subsService services.SubscriptionsService,
+	availabilityService services.AvailabilityService,
+	hostUtilizationService services.HostUtilizationService,
+	addressConflictsService services.AddressConflictsService,
+	timelineService services.TimelineService,
+	agentLogsService services.AgentLogsService,
+	hostMetricsService services.HostMetricsService,
+	monitoringURL string,
+) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 
@@ -123,13 +332,208 @@ func NewHostHandler(hostsService services.HostsService, subsService services.Sub
 			return
 		}
 
-		jobsState, _ := hostsService.GetExportersState(host.Name)
+		availability, err := availabilityService.GetHostAvailability(id, models.AvailabilityWindows)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		utilization, err := hostUtilizationService.GetHostUtilization(id, hostUtilizationPageDays)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		conflicts, err := addressConflictsService.GetAll(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		timeline, err := timelineService.GetHostTimeline(id, hostTimelinePageDays)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		var hostConflicts []*models.AddressConflict
+		for _, conflict := range conflicts {
+			if conflict.Involves(models.TagHostResourceType, id) {
+				hostConflicts = append(hostConflicts, conflict)
+			}
+		}
+
+		agentLogs, err := agentLogsService.Get(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recentUtilization, err := hostMetricsService.GetSparkline(id, hostMetricsPagePeriod, models.HostMetricsSparklinePoints)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		// The basic agents are not required to run the exporters
+		var jobsState map[string]string
+		if !host.IsBasic() {
+			jobsState, _ = hostsService.GetExportersState(host.Name)
+		}
 
 		c.HTML(http.StatusOK, "host.html.tmpl", gin.H{
-			"Host":           &host,
-			"Subscriptions":  subs,
-			"MonitoringURL":  monitoringURL,
-			"ExportersState": jobsState,
+			"Host":              &host,
+			"Subscriptions":     subs,
+			"Availability":      availability,
+			"Utilization":       utilization,
+			"RecentUtilization": recentUtilization,
+			"Timeline":          timeline,
+			"AddressConflicts":  hostConflicts,
+			"MonitoringURL":     monitoringURL,
+			"ExportersState":    jobsState,
+			"AgentLogs":         agentLogs,
 		})
 	}
 }
+
+// ApiHostAvailabilityHandler godoc
+// @Summary Get the availability percentage of a host, based on its heartbeats
+// @Produce json
+// @Param id path string true "Host id"
+// @Param days query []int false "Periods of time, in days, to compute the availability over (7, 30 or 90)" collectionFormat(multi)
+// @Success 200 {object} []models.Availability
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/availability [get]
+func ApiHostAvailabilityHandler(hostsService services.HostsService, availabilityService services.AvailabilityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		days := models.AvailabilityWindows
+		if queryDays := c.QueryArray("days"); len(queryDays) > 0 {
+			days = nil
+			for _, d := range queryDays {
+				window, err := strconv.Atoi(d)
+				if err != nil || !models.IsAvailabilityWindow(window) {
+					_ = c.Error(BadRequestError(fmt.Sprintf("invalid availability window: %s", d)))
+					return
+				}
+				days = append(days, window)
+			}
+		}
+
+		host, err := hostsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		availability, err := availabilityService.GetHostAvailability(id, days)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if availability == nil {
+			availability = []*models.Availability{}
+		}
+
+		c.JSON(http.StatusOK, availability)
+	}
+}
+
+// ApiHostUtilizationHandler godoc
+// @Summary Get the CPU, memory and disk utilization snapshots of a host, aggregated hourly
+// @Produce json
+// @Param id path string true "Host id"
+// @Param days query int false "Period of time, in days, to get the snapshots of (1 to 30, default 7)"
+// @Success 200 {object} []models.HostUtilizationSnapshot
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/utilization [get]
+func ApiHostUtilizationHandler(hostsService services.HostsService, hostUtilizationService services.HostUtilizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		days := hostUtilizationPageDays
+		if queryDays := c.Query("days"); queryDays != "" {
+			var err error
+			days, err = strconv.Atoi(queryDays)
+			if err != nil || days < 1 || days > models.HostUtilizationRetentionDays {
+				_ = c.Error(BadRequestError(fmt.Sprintf("invalid number of days: %s", queryDays)))
+				return
+			}
+		}
+
+		host, err := hostsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		utilization, err := hostUtilizationService.GetHostUtilization(id, days)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, utilization)
+	}
+}
+
+// ApiHostTimelineHandler godoc
+// @Summary Get the heartbeat transitions, checks results changes, configuration changes and fencing events of a host, the most recent first
+// @Produce json
+// @Param id path string true "Host id"
+// @Param days query int false "Period of time, in days, to get the events of (1 to 90, default 7)"
+// @Success 200 {object} []models.TimelineEvent
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/timeline [get]
+func ApiHostTimelineHandler(hostsService services.HostsService, timelineService services.TimelineService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		days := hostTimelinePageDays
+		if queryDays := c.Query("days"); queryDays != "" {
+			var err error
+			days, err = strconv.Atoi(queryDays)
+			if err != nil || days < 1 || days > models.TimelineMaxDays {
+				_ = c.Error(BadRequestError(fmt.Sprintf("invalid number of days: %s", queryDays)))
+				return
+			}
+		}
+
+		host, err := hostsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		timeline, err := timelineService.GetHostTimeline(id, days)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, timeline)
+	}
+}
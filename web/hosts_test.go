@@ -1,15 +1,21 @@
 package web
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/tdewolff/minify/v2"
 	"github.com/tdewolff/minify/v2/html"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/version"
 	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
@@ -57,6 +63,10 @@ func hostListFixture() models.HostList {
 			AgentVersion: "v1",
 			Tags:         []string{"tag2"},
 			Health:       "warning",
+			Provisioning: &models.Provisioning{
+				Tool:            "terraform",
+				TemplateVersion: "1.2.0",
+			},
 		},
 		{
 			ID:            "1",
@@ -95,6 +105,7 @@ func TestHostListNextHandler(t *testing.T) {
 	mockHostsService.On("GetCount").Return(3, nil)
 	mockHostsService.On("GetAllSIDs", mock.Anything).Return([]string{"PRD", "QAS", "DEV"}, nil)
 	mockHostsService.On("GetAllTags", mock.Anything).Return([]string{"tag1", "tag2", "tag3"}, nil)
+	mockHostsService.On("GetAllTemplateVersions").Return([]string{"1.1.0", "1.2.0"}, nil)
 
 	deps := setupTestDependencies()
 	deps.hostsService = mockHostsService
@@ -126,9 +137,175 @@ func TestHostListNextHandler(t *testing.T) {
 	assert.Contains(t, minified, "Hosts")
 
 	assert.Regexp(t, regexp.MustCompile("<select name=sids.*>.*PRD.*QAS.*DEV.*</select>"), minified)
-	assert.Regexp(t, regexp.MustCompile(".*check_circle.*<td .*>.*host1.*</td><td>192.168.1.1</td><td>.*azure.*</td><td>.*databases/sap_system_id_1.*PRD.*</td><td>v1</td><td .*>.*<input.*value=tag1.*>.*</td>"), minified)
-	assert.Regexp(t, regexp.MustCompile(".*warning.*<td .*>.*host2.*</td><td>192.168.1.2</td><td>.*aws.*</td><td>.*sapsystems/sap_system_id_2.*QAS.*</td><td>v1</td><td .*>.*<input.*value=tag2.*>.*</td>"), minified)
-	assert.Regexp(t, regexp.MustCompile(".*error.*<td .*>.*host3.*</td><td>192.168.1.3</td><td>.*gcp.*</td><td>.*sapsystems/sap_system_id_3.*DEV.*</td><td>v1</td><td .*>.*<input.*value=tag3.*>.*</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<select name=template_version.*>.*1.1.0.*1.2.0.*</select>"), minified)
+	assert.Regexp(t, regexp.MustCompile(".*check_circle.*<td .*>.*host1.*</td><td>192.168.1.1</td><td>.*azure.*</td><td>.*databases/sap_system_id_1.*PRD.*</td><td class=tn-agent-version>v1</td><td .*>.*<input.*value=tag1.*>.*</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile(".*warning.*<td .*>.*host2.*</td><td>192.168.1.2</td><td>.*aws.*</td><td>.*sapsystems/sap_system_id_2.*QAS.*</td><td class=tn-agent-version>v1</td><td .*>.*<input.*value=tag2.*>.*</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile(".*error.*<td .*>.*host3.*</td><td>192.168.1.3</td><td>.*gcp.*</td><td>.*sapsystems/sap_system_id_3.*DEV.*</td><td class=tn-agent-version>v1</td><td .*>.*<input.*value=tag3.*>.*</td>"), minified)
+}
+
+func TestHostListHandlerAgentVersionDrift(t *testing.T) {
+	serverVersion := version.Version
+	version.Version = "v1"
+	defer func() { version.Version = serverVersion }()
+
+	hosts := hostListFixture()
+	hosts[1].AgentInfo = &models.AgentInfo{Version: "v0.9"}
+	hosts[2].AgentUpgrade = &models.AgentUpgrade{Version: "v1.1", Status: models.AgentUpgradeStatusInProgress, RequestedBy: "admin"}
+
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("GetAll", mock.Anything, mock.Anything).Return(hosts, nil)
+	mockHostsService.On("GetCount").Return(3, nil)
+	mockHostsService.On("GetAllSIDs", mock.Anything).Return([]string{}, nil)
+	mockHostsService.On("GetAllTags", mock.Anything).Return([]string{}, nil)
+	mockHostsService.On("GetAllTemplateVersions").Return([]string{}, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts", nil)
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Regexp(t, regexp.MustCompile("host1.*<td class=tn-agent-version>v1</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("host2.*<td class=tn-agent-version>v0.9 <span .*>drift</span></td>"), minified)
+	assert.Equal(t, 1, strings.Count(minified, ">drift</span>"))
+	assert.Regexp(t, regexp.MustCompile("host3.*<td class=tn-agent-version>v1 <span .*Upgrade to v1.1 requested by admin.*>in_progress</span></td>"), minified)
+}
+
+func TestApiHostRegister(t *testing.T) {
+	registration := &hosts.AgentRegistration{
+		Version:     "1.1.0",
+		Discoveries: []string{"host_discovery", "cloud_discovery"},
+		OSName:      "sles",
+		OSVersion:   "15.4",
+		Arch:        "x86_64",
+	}
+
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("Admit", "agent_id").Return(models.AgentStatusPending, nil)
+	agentsService.On("Register", "agent_id", registration).Return(nil)
+
+	agentUpgradesService := new(services.MockAgentUpgradesService)
+	agentUpgradesService.On("Complete", "agent_id", "1.1.0").Return(nil)
+
+	deps := setupTestDependencies()
+	deps.agentsService = agentsService
+	deps.agentUpgradesService = agentUpgradesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(registration)
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/hosts/agent_id/register", bytes.NewBuffer(body))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	agentsService.AssertExpectations(t)
+	agentUpgradesService.AssertExpectations(t)
+}
+
+func TestApiHostRegisterInvalid(t *testing.T) {
+	agentsService := new(services.MockAgentsService)
+
+	deps := setupTestDependencies()
+	deps.agentsService = agentsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, body := range []string{
+		`not json`,
+		`{"discoveries": ["host_discovery"]}`,
+		`{"version": "1.1.0", "os_name": "sles\u0000"}`,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/hosts/agent_id/register", bytes.NewBufferString(body))
+		req.Header.Set("Accept", "application/json")
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code, body)
+	}
+	agentsService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+}
+
+func TestApiDecommissionHostHandler(t *testing.T) {
+	host := &models.Host{ID: "1", Name: "host1"}
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("GetByID", "1").Return(host, nil)
+	hostsService.On("GetByID", "unknown").Return(nil, nil)
+	hostsService.On("Decommission", "1").Return(host, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = hostsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serve := func(url string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("DELETE", url, nil)
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := serve("/api/hosts/unknown")
+	assert.Equal(t, 404, resp.Code)
+
+	resp = serve("/api/hosts/1")
+	assert.Equal(t, 428, resp.Code)
+
+	var body map[string]string
+	err = json.Unmarshal(resp.Body.Bytes(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := body["confirmation_token"]
+	assert.NotEmpty(t, token)
+
+	resp = serve("/api/hosts/1?confirmation_token=wrong")
+	assert.Equal(t, 428, resp.Code)
+	hostsService.AssertNotCalled(t, "Decommission", mock.Anything)
+
+	resp = serve("/api/hosts/1?confirmation_token=" + token)
+	assert.Equal(t, 204, resp.Code)
+	hostsService.AssertCalled(t, "Decommission", "1")
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionHostDecommissioned && entry.ResourceID == "1"
+	}))
 }
 
 func TestApiHostHeartbeat(t *testing.T) {
@@ -152,6 +329,56 @@ func TestApiHostHeartbeat(t *testing.T) {
 	app.collectorEngine.ServeHTTP(resp, req)
 
 	assert.Equal(t, 204, resp.Code)
+	assert.Empty(t, resp.Header().Get(hosts.LogsRequestedHeader))
+}
+
+func TestApiHostHeartbeatLogsRequested(t *testing.T) {
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("Heartbeat", "agent_id").Return(nil)
+
+	agentLogsService := new(services.MockAgentLogsService)
+	agentLogsService.On("IsRequested", "agent_id").Return(true, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+	deps.agentLogsService = agentLogsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/hosts/agent_id/heartbeat", nil)
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	assert.Equal(t, "true", resp.Header().Get(hosts.LogsRequestedHeader))
+}
+
+func TestApiHostHeartbeatPendingAgent(t *testing.T) {
+	mockHostsService := new(services.MockHostsService)
+
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("Admit", "agent_id").Return(models.AgentStatusPending, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+	deps.agentsService = agentsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/hosts/agent_id/heartbeat", nil)
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	mockHostsService.AssertNotCalled(t, "Heartbeat", mock.Anything)
 }
 
 func TestHostHandler(t *testing.T) {
@@ -184,12 +411,90 @@ func TestHostHandler(t *testing.T) {
 
 	subscriptionsMocks.On("GetHostSubscriptions", "2").Return(subscriptionsList, nil)
 	subscriptionsMocks.On("IsTrentoPremium").Return(true, nil)
-	mockHostsService.On("GetByID", "2").Return(hostListFixture()[1], nil)
+	host := hostListFixture()[1]
+	host.AgentInfo = &models.AgentInfo{
+		Version:       "v1",
+		Discoveries:   []string{"host_discovery", "cloud_discovery"},
+		OSName:        "sles",
+		OSVersion:     "15.4",
+		KernelVersion: "5.14.21",
+		Arch:          "x86_64",
+	}
+	healthChangedAt := time.Date(2022, 3, 10, 8, 31, 0, 0, time.UTC)
+	host.HealthChangedAt = &healthChangedAt
+	mockHostsService.On("GetByID", "2").Return(host, nil)
 	mockHostsService.On("GetExportersState", "host2").Return(exportersState, nil)
 
+	availabilityMocks := new(services.MockAvailabilityService)
+	availabilityMocks.On("GetHostAvailability", "2", models.AvailabilityWindows).Return([]*models.Availability{
+		{Days: 7, Percentage: 100},
+		{Days: 30, Percentage: 99.5},
+		{Days: 90, Percentage: 98.123},
+	}, nil)
+
+	utilizationMocks := new(services.MockHostUtilizationService)
+	utilizationMocks.On("GetHostUtilization", "2", 7).Return(models.HostUtilization{
+		{CPUPercentAvg: 10, CPUPercentMax: 35, MemoryPercentAvg: 40, MemoryPercentMax: 42, DiskPercentMax: 70},
+		{CPUPercentAvg: 20, CPUPercentMax: 25, MemoryPercentAvg: 41, MemoryPercentMax: 41, DiskPercentMax: 71.25},
+	}, nil)
+
+	addressConflictsMocks := new(services.MockAddressConflictsService)
+	addressConflictsMocks.On("GetAll", "").Return([]*models.AddressConflict{
+		{
+			Kind:    models.AddressConflictIPAddress,
+			Address: "10.0.0.2",
+			Owners: []*models.AddressConflictOwner{
+				{ResourceType: models.TagHostResourceType, ID: "2", Name: "host2"},
+				{ResourceType: models.TagHostResourceType, ID: "3", Name: "host3"},
+			},
+		},
+		{
+			Kind:    models.AddressConflictHostname,
+			Address: "sapnwp",
+			Owners: []*models.AddressConflictOwner{
+				{ResourceType: models.TagSAPSystemResourceType, ID: "sys1", Name: "NWP"},
+				{ResourceType: models.TagSAPSystemResourceType, ID: "sys2", Name: "NWQ"},
+			},
+		},
+	}, nil)
+
+	timelineMocks := new(services.MockTimelineService)
+	timelineMocks.On("GetHostTimeline", "2", 7).Return([]*models.TimelineEvent{
+		{
+			Time:     time.Date(2022, 3, 10, 8, 30, 0, 0, time.UTC),
+			Kind:     models.TimelineEventHeartbeat,
+			Severity: models.TimelineSeverityCritical,
+			Summary:  "Heartbeats stopped",
+		},
+	}, nil)
+
+	logsReceivedAt := time.Date(2022, 3, 10, 8, 35, 0, 0, time.UTC)
+	agentLogsMocks := new(services.MockAgentLogsService)
+	agentLogsMocks.On("Get", "2").Return(&models.AgentLogs{
+		AgentID:     "2",
+		RequestedAt: time.Date(2022, 3, 10, 8, 34, 0, 0, time.UTC),
+		RequestedBy: "admin",
+		ReceivedAt:  &logsReceivedAt,
+		Logs:        "level=error msg=\"Error while sending the heartbeat\"",
+	}, nil)
+
 	deps := setupTestDependencies()
 	deps.subscriptionsService = subscriptionsMocks
 	deps.hostsService = mockHostsService
+	deps.availabilityService = availabilityMocks
+	deps.hostUtilizationService = utilizationMocks
+	deps.addressConflictsService = addressConflictsMocks
+	deps.timelineService = timelineMocks
+	deps.agentLogsService = agentLogsMocks
+
+	previous, cpu, memory, disk := 10.0, 12.5, 50.0, 71.0
+	hostMetricsMocks := new(services.MockHostMetricsService)
+	hostMetricsMocks.On("GetSparkline", "2", hostMetricsPagePeriod, models.HostMetricsSparklinePoints).Return(&models.HostMetricsSparkline{
+		CPU:    []*float64{&previous, nil, &cpu},
+		Memory: []*float64{&previous, nil, &memory},
+		Disk:   []*float64{&previous, nil, &disk},
+	}, nil)
+	deps.hostMetricsService = hostMetricsMocks
 
 	config := setupTestConfig()
 	app, err := NewAppWithDeps(config, deps)
@@ -218,11 +523,33 @@ func TestHostHandler(t *testing.T) {
 	assert.Contains(t, minified, "Host details")
 
 	assert.Regexp(t, regexp.MustCompile("<span.*>host2</span>"), minified)
+	assert.NotContains(t, minified, ">ephemeral</span>")
 	assert.Regexp(t, regexp.MustCompile("<a.*sapsystems/sap_system_id_2.*>QAS</a>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<span.*>v1</span>"), minified)
-	assert.Regexp(t, regexp.MustCompile("<td>Trento agent</td><td><span.*>not running</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Operating system:</strong><br><span.*>sles 15.4 \\(x86_64\\)</span>.*<strong>Kernel version:</strong><br><span.*>5.14.21</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Discoveries:</strong><br><span.*>host_discovery, cloud_discovery</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Provisioning tool:</strong><br><span.*>terraform</span>.*<strong>Template version:</strong><br><span.*>1.2.0</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>Trento agent</td><td><span class="?badge badge-pill badge-warning"?>missing heartbeats</span>\s*<span class="?text-muted"?>since Mar 10, 2022 08:31:00 UTC</span>`), minified)
 	assert.Regexp(t, regexp.MustCompile("<td>Node exporter</td><td><span.*>running</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<td>Other exporter</td><td><span.*>not running</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td>Last 7 days</td><td>100.00%</td></tr><tr><td>Last 30 days</td><td>99.50%</td></tr><tr><td>Last 90 days</td><td>98.12%</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>CPU</td><td>20.0%</td><td>35.0%</td><td><svg class="?sparkline"?`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>Memory</td><td>41.0%</td><td>42.0%</td>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>Disk</td><td>71.2%</td><td>71.2%</td>`), minified)
+	assert.Contains(t, minified, "Recent resource utilization")
+	assert.Regexp(t, regexp.MustCompile(`<td>CPU</td><td>12.5%</td><td><svg class="?sparkline"?`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>Memory</td><td>50.0%</td><td><svg`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>Mar 10, 2022 08:30:00 UTC</td><td><span class="?badge badge-pill badge-danger"?>heartbeat</span></td><td>Heartbeats stopped</td>`), minified)
+
+	// Agent logs
+	assert.Regexp(t, regexp.MustCompile(`<button[^>]*id=agent-logs-request data-host-id=2>Request agent logs</button>`), minified)
+	assert.Contains(t, minified, "Requested by admin, shipped at Mar 10, 2022 08:35:00 UTC")
+	assert.Contains(t, minified, `<pre class="agent-logs border rounded p-2">level=error msg=&#34;Error while sending the heartbeat&#34;</pre>`)
+
+	// Address conflicts
+	assert.Contains(t, minified, "Address conflicts detected")
+	assert.Regexp(t, regexp.MustCompile(`IP address <strong>10.0.0.2</strong> is claimed by\s*<a href=/hosts/2>host2</a>, <a href=/hosts/3>host3</a>`), minified)
+	assert.NotContains(t, minified, "sapnwp")
 
 	// Subscriptions
 	assert.Regexp(t, regexp.MustCompile(
@@ -258,7 +585,9 @@ func TestHostHandlerAzure(t *testing.T) {
 
 	subscriptionsMocks.On("GetHostSubscriptions", "1").Return(subscriptionsList, nil)
 	subscriptionsMocks.On("IsTrentoPremium").Return(true, nil)
-	mockHostsService.On("GetByID", "1").Return(hostListFixture()[0], nil)
+	host := hostListFixture()[0]
+	host.Ephemeral = true
+	mockHostsService.On("GetByID", "1").Return(host, nil)
 	mockHostsService.On("GetExportersState", "host1").Return(make(map[string]string), nil)
 
 	deps := setupTestDependencies()
@@ -292,6 +621,7 @@ func TestHostHandlerAzure(t *testing.T) {
 	assert.Contains(t, minified, "Host details")
 
 	assert.Regexp(t, regexp.MustCompile("<span.*>host1</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile(`<span class="?badge badge-pill badge-secondary"?[^>]*>ephemeral</span>`), minified)
 	assert.Regexp(t, regexp.MustCompile("<a.*sapsystems/sap_system_id_1.*>PRD</a>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<span.*>v1</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<span.*>running</span>"), minified)
@@ -305,6 +635,45 @@ func TestHostHandlerAzure(t *testing.T) {
 			"<td>Registered</td><td></td><td></td><td></td>"), minified)
 }
 
+func TestHostHandlerBasic(t *testing.T) {
+	subscriptionsMocks := new(services.MockSubscriptionsService)
+	mockHostsService := new(services.MockHostsService)
+
+	subscriptionsMocks.On("GetHostSubscriptions", "1").Return([]*models.SlesSubscription{}, nil)
+	host := hostListFixture()[0]
+	host.AgentProfile = "basic"
+	mockHostsService.On("GetByID", "1").Return(host, nil)
+
+	deps := setupTestDependencies()
+	deps.subscriptionsService = subscriptionsMocks
+	deps.hostsService = mockHostsService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts/1", nil)
+	req.Header.Set("Accept", "text/html")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Regexp(t, regexp.MustCompile(`<span class="badge badge-pill badge-light"[^>]*>basic</span>`), minified)
+	assert.NotContains(t, minified, "SAP Systems:")
+	assert.NotContains(t, minified, "Cluster:")
+	mockHostsService.AssertNotCalled(t, "GetExportersState", mock.Anything)
+}
+
 func TestHostHandler404Error(t *testing.T) {
 	subscriptionsMocks := new(services.MockSubscriptionsService)
 	mockHostsService := new(services.MockHostsService)
@@ -351,3 +720,212 @@ func TestHostHandler404Error(t *testing.T) {
 	assert.Equal(t, 404, resp.Code)
 	assert.Contains(t, resp.Body.String(), "Not Found")
 }
+
+func TestApiHostAvailabilityHandler(t *testing.T) {
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("GetByID", "1").Return(hostListFixture()[0], nil)
+
+	mockAvailabilityService := new(services.MockAvailabilityService)
+	mockAvailabilityService.On("GetHostAvailability", "1", []int{7, 90}).Return([]*models.Availability{
+		{Days: 7, Percentage: 100},
+		{Days: 90, Percentage: 99.5},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+	deps.availabilityService = mockAvailabilityService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/1/availability?days=7&days=90", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{"days":7,"percentage":100},{"days":90,"percentage":99.5}]`, resp.Body.String())
+}
+
+func TestApiHostAvailabilityHandlerInvalidWindow(t *testing.T) {
+	deps := setupTestDependencies()
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/1/availability?days=15", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestApiHostAvailabilityHandler404(t *testing.T) {
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("GetByID", "unknown").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/unknown/availability", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiHostUtilizationHandler(t *testing.T) {
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("GetByID", "1").Return(hostListFixture()[0], nil)
+
+	mockHostUtilizationService := new(services.MockHostUtilizationService)
+	mockHostUtilizationService.On("GetHostUtilization", "1", 30).Return(models.HostUtilization{
+		{
+			Time:             time.Date(2021, 10, 17, 10, 0, 0, 0, time.UTC),
+			SamplesCount:     2,
+			CPUPercentAvg:    10,
+			CPUPercentMax:    15,
+			MemoryPercentAvg: 40,
+			MemoryPercentMax: 42,
+			DiskPercentMax:   70,
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+	deps.hostUtilizationService = mockHostUtilizationService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/1/utilization?days=30", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"time": "2021-10-17T10:00:00Z",
+		"samples_count": 2,
+		"cpu_percent_avg": 10,
+		"cpu_percent_max": 15,
+		"memory_percent_avg": 40,
+		"memory_percent_max": 42,
+		"disk_percent_max": 70
+	}]`, resp.Body.String())
+}
+
+func TestApiHostUtilizationHandlerInvalidDays(t *testing.T) {
+	app, err := NewAppWithDeps(setupTestConfig(), setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, days := range []string{"0", "31", "a"} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/hosts/1/utilization?days="+days, nil)
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code)
+	}
+}
+
+func TestApiHostUtilizationHandler404(t *testing.T) {
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("GetByID", "unknown").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/unknown/utilization", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiHostTimelineHandler(t *testing.T) {
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("GetByID", "1").Return(hostListFixture()[0], nil)
+
+	mockTimelineService := new(services.MockTimelineService)
+	mockTimelineService.On("GetHostTimeline", "1", 30).Return([]*models.TimelineEvent{
+		{
+			Time:     time.Date(2022, 3, 10, 8, 30, 0, 0, time.UTC),
+			Kind:     models.TimelineEventCheckResult,
+			Severity: models.TimelineSeverityCritical,
+			Summary:  "Check 156F64 changed from passing to critical",
+			Details:  map[string]string{"check_id": "156F64", "previous": "passing", "current": "critical"},
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+	deps.timelineService = mockTimelineService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/1/timeline?days=30", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"time": "2022-03-10T08:30:00Z",
+		"kind": "check_result",
+		"severity": "critical",
+		"summary": "Check 156F64 changed from passing to critical",
+		"details": {"check_id": "156F64", "previous": "passing", "current": "critical"}
+	}]`, resp.Body.String())
+}
+
+func TestApiHostTimelineHandlerInvalidDays(t *testing.T) {
+	app, err := NewAppWithDeps(setupTestConfig(), setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, days := range []string{"0", "91", "a"} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/hosts/1/timeline?days="+days, nil)
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code)
+	}
+}
+
+func TestApiHostTimelineHandler404(t *testing.T) {
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("GetByID", "unknown").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/unknown/timeline", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
@@ -0,0 +1,205 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+const (
+	SessionImpersonatedUserKey string = "impersonated_user"
+	// SessionImpersonationExpiresKey holds the unix time the impersonation ends at
+	SessionImpersonationExpiresKey string = "impersonation_expires_at"
+	// ContextImpersonationKey is the gin context key holding the *models.Impersonation in progress
+	ContextImpersonationKey string = "impersonation"
+)
+
+// impersonationTTL is the strict limit of an impersonation, it cannot be extended but only started again
+var impersonationTTL = 30 * time.Minute
+
+// ImpersonationMiddleware lets the admins act as the user they are impersonating, with its role and preferences.
+// The impersonations expire, and end when the impersonator is no longer an admin
+// or the impersonated user no longer belongs to the organization of the impersonator, if any.
+// It must be used after the AuthMiddleware
+func ImpersonationMiddleware(usersService services.UsersService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get(ContextUserKey)
+		impersonator, ok := value.(*models.User)
+		if !ok || bearerAuthenticated(c) {
+			c.Next()
+			return
+		}
+
+		session := sessions.Default(c)
+		username, ok := session.Get(SessionImpersonatedUserKey).(string)
+		if !ok || username == "" {
+			c.Next()
+			return
+		}
+
+		expiresAt, _ := session.Get(SessionImpersonationExpiresKey).(int64)
+		if !impersonator.HasRole(models.UserRoleAdmin) || time.Now().Unix() >= expiresAt {
+			log.Infof("The impersonation of %s by %s ended", username, impersonator.Username)
+			if err := endImpersonation(c); err != nil {
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+			recordAudit(c, auditService, models.AuditActionImpersonationStopped, models.AuditResourceUser, username, nil, nil)
+			c.Next()
+			return
+		}
+
+		user, err := usersService.GetByUsername(username)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		// The impersonated user might have been deleted, or moved to another organization, meanwhile
+		if user == nil || !canImpersonate(impersonator, user) {
+			if err := endImpersonation(c); err != nil {
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Set(ContextUserKey, user)
+		c.Set(ContextImpersonationKey, &models.Impersonation{
+			Impersonator: impersonator.Username,
+			Username:     user.Username,
+			ExpiresAt:    time.Unix(expiresAt, 0),
+		})
+		c.Next()
+	}
+}
+
+func endImpersonation(c *gin.Context) error {
+	session := sessions.Default(c)
+	session.Delete(SessionImpersonatedUserKey)
+	session.Delete(SessionImpersonationExpiresKey)
+
+	return session.Save()
+}
+
+// canImpersonate tells whether the user belongs to the organization of the impersonator,
+// the impersonators without one impersonating anybody
+func canImpersonate(impersonator *models.User, user *models.User) bool {
+	return impersonator.Organization == "" || impersonator.Organization == user.Organization
+}
+
+// requestImpersonation returns the impersonation in progress, if any
+func requestImpersonation(c *gin.Context) *models.Impersonation {
+	value, _ := c.Get(ContextImpersonationKey)
+	impersonation, _ := value.(*models.Impersonation)
+
+	return impersonation
+}
+
+// ApiStartImpersonationHandler godoc
+// @Summary Impersonate a user, acting with its role and preferences for a limited time
+// @Produce json
+// @Param id path int true "User id"
+// @Success 200 {object} models.Impersonation
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/impersonate [post]
+func ApiStartImpersonationHandler(usersService services.UsersService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if requestImpersonation(c) != nil {
+			_ = c.Error(BadRequestError("an impersonation is already in progress"))
+			return
+		}
+
+		if bearerAuthenticated(c) {
+			_ = c.Error(BadRequestError("API keys and personal access tokens cannot impersonate users"))
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			_ = c.Error(NotFoundError("user not found"))
+			return
+		}
+
+		user, err := usersService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if user == nil {
+			_ = c.Error(NotFoundError("user not found"))
+			return
+		}
+
+		value, _ := c.Get(ContextUserKey)
+		if impersonatorUser, ok := value.(*models.User); ok && !canImpersonate(impersonatorUser, user) {
+			_ = c.Error(NotFoundError("user not found"))
+			return
+		}
+
+		impersonator := requestActor(c)
+		if user.Username == impersonator {
+			_ = c.Error(BadRequestError("users cannot impersonate themselves"))
+			return
+		}
+
+		impersonation := &models.Impersonation{
+			Impersonator: impersonator,
+			Username:     user.Username,
+			ExpiresAt:    time.Now().Add(impersonationTTL).Truncate(time.Second),
+		}
+
+		session := sessions.Default(c)
+		session.Set(SessionImpersonatedUserKey, user.Username)
+		session.Set(SessionImpersonationExpiresKey, impersonation.ExpiresAt.Unix())
+		if err := session.Save(); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		log.Infof("%s started impersonating %s until %s", impersonator, user.Username, impersonation.ExpiresAt)
+		recordAudit(c, auditService, models.AuditActionImpersonationStarted, models.AuditResourceUser, user.Username, nil, impersonation)
+
+		c.JSON(http.StatusOK, impersonation)
+	}
+}
+
+// ApiStopImpersonationHandler godoc
+// @Summary Stop the impersonation in progress, going back to act as the impersonator
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /impersonation [delete]
+func ApiStopImpersonationHandler(auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		impersonation := requestImpersonation(c)
+		if impersonation == nil {
+			_ = c.Error(BadRequestError("no impersonation in progress"))
+			return
+		}
+
+		if err := endImpersonation(c); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		log.Infof("%s stopped impersonating %s", impersonation.Impersonator, impersonation.Username)
+		recordAudit(c, auditService, models.AuditActionImpersonationStopped, models.AuditResourceUser, impersonation.Username, nil, nil)
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,209 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func setupImpersonationTestApp(t *testing.T, auditService services.AuditService) *App {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByUsername", testUser).Return(&models.User{ID: 1, Username: testUser, Role: models.UserRoleAdmin}, nil)
+	usersService.On("GetByUsername", "bob").Return(&models.User{ID: 2, Username: "bob", Role: models.UserRoleViewer}, nil)
+	usersService.On("GetByID", int64(1)).Return(&models.User{ID: 1, Username: testUser, Role: models.UserRoleAdmin}, nil)
+	usersService.On("GetByID", int64(2)).Return(&models.User{ID: 2, Username: "bob", Role: models.UserRoleViewer}, nil)
+	usersService.On("GetByID", int64(3)).Return(nil, nil)
+
+	subscriptionsService := new(services.MockSubscriptionsService)
+	subscriptionsService.On("GetPremiumData").Return(&models.PremiumData{}, nil)
+
+	deps := setupTestDependencies()
+	deps.usersService = usersService
+	deps.subscriptionsService = subscriptionsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func serveWithSession(app *App, method string, url string, sessionCookie string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(method, url, nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	if sessionCookie != "" {
+		req.Header.Set("Cookie", sessionCookie)
+	}
+	app.webEngine.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func meFromResponse(t *testing.T, resp *httptest.ResponseRecorder) *models.UserPermissions {
+	var me models.UserPermissions
+	if err := json.Unmarshal(resp.Body.Bytes(), &me); err != nil {
+		t.Fatal(err)
+	}
+
+	return &me
+}
+
+func TestImpersonation(t *testing.T) {
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	app := setupImpersonationTestApp(t, auditService)
+
+	resp := serveWithSession(app, "POST", "/api/users/2/impersonate", "")
+	assert.Equal(t, 200, resp.Code)
+	sessionCookie := resp.Header().Get("Set-Cookie")
+	assert.NotEmpty(t, sessionCookie)
+
+	var impersonation models.Impersonation
+	json.Unmarshal(resp.Body.Bytes(), &impersonation)
+	assert.Equal(t, testUser, impersonation.Impersonator)
+	assert.Equal(t, "bob", impersonation.Username)
+	assert.WithinDuration(t, time.Now().Add(impersonationTTL), impersonation.ExpiresAt, time.Minute)
+
+	resp = serveWithSession(app, "GET", "/api/me", sessionCookie)
+	assert.Equal(t, 200, resp.Code)
+	me := meFromResponse(t, resp)
+	assert.Equal(t, "bob", me.Username)
+	assert.Equal(t, models.UserRoleViewer, me.Role)
+	assert.Equal(t, testUser, me.Impersonation.Impersonator)
+
+	// the admin acts with the role of the impersonated user
+	resp = serveWithSession(app, "GET", "/api/users", sessionCookie)
+	assert.Equal(t, 403, resp.Code)
+
+	resp = serveWithSession(app, "GET", "/about", sessionCookie)
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), "are impersonating <strong>bob</strong>")
+
+	resp = serveWithSession(app, "DELETE", "/api/impersonation", sessionCookie)
+	assert.Equal(t, 204, resp.Code)
+	sessionCookie = resp.Header().Get("Set-Cookie")
+
+	resp = serveWithSession(app, "GET", "/api/me", sessionCookie)
+	me = meFromResponse(t, resp)
+	assert.Equal(t, testUser, me.Username)
+	assert.Nil(t, me.Impersonation)
+
+	resp = serveWithSession(app, "DELETE", "/api/impersonation", sessionCookie)
+	assert.Equal(t, 400, resp.Code)
+
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionImpersonationStarted && entry.Actor == testUser &&
+			entry.ResourceID == "bob" && entry.ImpersonatedUser == ""
+	}))
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionImpersonationStopped && entry.Actor == testUser &&
+			entry.ImpersonatedUser == "bob"
+	}))
+}
+
+func TestImpersonationExpired(t *testing.T) {
+	defaultTTL := impersonationTTL
+	impersonationTTL = -time.Second
+	defer func() { impersonationTTL = defaultTTL }()
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	app := setupImpersonationTestApp(t, auditService)
+
+	resp := serveWithSession(app, "POST", "/api/users/2/impersonate", "")
+	assert.Equal(t, 200, resp.Code)
+
+	resp = serveWithSession(app, "GET", "/api/me", resp.Header().Get("Set-Cookie"))
+	me := meFromResponse(t, resp)
+	assert.Equal(t, testUser, me.Username)
+	assert.Nil(t, me.Impersonation)
+
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionImpersonationStopped && entry.ResourceID == "bob"
+	}))
+}
+
+func TestApiStartImpersonationHandlerInvalid(t *testing.T) {
+	app := setupImpersonationTestApp(t, newMockedAuditService())
+
+	resp := serveWithSession(app, "POST", "/api/users/1/impersonate", "")
+	assert.Equal(t, 400, resp.Code)
+
+	resp = serveWithSession(app, "POST", "/api/users/3/impersonate", "")
+	assert.Equal(t, 404, resp.Code)
+
+	resp = serveWithSession(app, "POST", "/api/users/2/impersonate", "")
+	assert.Equal(t, 200, resp.Code)
+
+	// the impersonated viewer is not allowed to start another impersonation
+	resp = serveWithSession(app, "POST", "/api/users/2/impersonate", resp.Header().Get("Set-Cookie"))
+	assert.Equal(t, 403, resp.Code)
+}
+
+func TestImpersonationOtherOrganization(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByUsername", testUser).Return(&models.User{ID: 1, Username: testUser, Role: models.UserRoleAdmin, Organization: "acme"}, nil)
+	usersService.On("GetByID", int64(2)).Return(&models.User{ID: 2, Username: "bob", Role: models.UserRoleViewer, Organization: "acme"}, nil)
+	usersService.On("GetByID", int64(3)).Return(&models.User{ID: 3, Username: "carol", Role: models.UserRoleViewer, Organization: "globex"}, nil)
+	usersService.On("GetByID", int64(4)).Return(&models.User{ID: 4, Username: "root", Role: models.UserRoleAdmin}, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.usersService = usersService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string]int{
+		"/api/users/2/impersonate": 200,
+		"/api/users/3/impersonate": 404,
+		"/api/users/4/impersonate": 404,
+	} {
+		resp := serveWithSession(app, "POST", path, "")
+		assert.Equal(t, expected, resp.Code, path)
+	}
+}
+
+func TestImpersonationMovedToOtherOrganization(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByUsername", testUser).Return(&models.User{ID: 1, Username: testUser, Role: models.UserRoleAdmin, Organization: "acme"}, nil)
+	usersService.On("GetByID", int64(2)).Return(&models.User{ID: 2, Username: "bob", Role: models.UserRoleViewer, Organization: "acme"}, nil)
+	usersService.On("GetByUsername", "bob").Return(&models.User{ID: 2, Username: "bob", Role: models.UserRoleViewer, Organization: "globex"}, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.usersService = usersService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := serveWithSession(app, "POST", "/api/users/2/impersonate", "")
+	assert.Equal(t, 200, resp.Code)
+
+	resp = serveWithSession(app, "GET", "/api/me", resp.Header().Get("Set-Cookie"))
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, testUser, meFromResponse(t, resp).Username)
+	assert.Nil(t, meFromResponse(t, resp).Impersonation)
+}
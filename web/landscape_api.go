@@ -0,0 +1,27 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/services"
+)
+
+// ApiLandscapeGraphHandler godoc
+// @Summary Retrieve the landscape topology as a graph of SAP systems, databases, clusters and hosts
+// @Description The landscape of the organization of the user, if any
+// @Produce json
+// @Success 200 {object} models.LandscapeGraph
+// @Failure 500 {object} map[string]string
+// @Router /landscape/graph [get]
+func ApiLandscapeGraphHandler(landscapeService services.LandscapeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		graph, err := landscapeService.GetGraph(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, graph)
+	}
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiLandscapeGraphHandler(t *testing.T) {
+	landscapeService := new(services.MockLandscapeService)
+	landscapeService.On("GetGraph", "").Return(&models.LandscapeGraph{
+		Nodes: []*models.LandscapeNode{
+			{ID: "host:hana01", Type: models.LandscapeNodeHost, Label: "hana01", Health: models.HostHealthPassing},
+			{ID: "cluster:hana_cluster", Type: models.LandscapeNodeCluster, Label: "hana_cluster", Health: models.CheckPassing},
+		},
+		Edges: []*models.LandscapeEdge{
+			{Source: "host:hana01", Target: "cluster:hana_cluster", Kind: models.LandscapeEdgeClusterMember},
+		},
+		GeneratedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.landscapeService = landscapeService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/landscape/graph", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"nodes": [
+			{"id": "host:hana01", "type": "host", "label": "hana01", "health": "passing"},
+			{"id": "cluster:hana_cluster", "type": "cluster", "label": "hana_cluster", "health": "passing"}
+		],
+		"edges": [
+			{"source": "host:hana01", "target": "cluster:hana_cluster", "kind": "cluster_member"}
+		],
+		"generated_at": "2022-01-01T00:00:00Z"
+	}`, resp.Body.String())
+}
@@ -2,6 +2,7 @@ package web
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"io/fs"
@@ -11,7 +12,9 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/version"
+	"github.com/trento-project/trento/web/models"
 
+	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/render"
 
 	"github.com/gomarkdown/markdown"
@@ -25,6 +28,10 @@ type LayoutRender struct {
 	root      string   // the root template is separate because it has to be parsed first
 	blocks    []string // blocks are used by the root template and can be redefined in user templates
 	templates map[string]*template.Template
+	// when hot reload is enabled, the templates are parsed again from the FS at every render
+	hotReload   bool
+	templatesFS fs.FS
+	patterns    []string
 }
 
 type LayoutData struct {
@@ -33,6 +40,15 @@ type LayoutData struct {
 	Version   string
 	Flavor    string
 	Submenu   Submenu
+	// CSRFToken of the session, taken from the response header set by the CSRFMiddleware
+	CSRFToken string
+	// Permissions of the logged in user, passed along by the LayoutUserMiddleware.
+	// They are also added to the content, when it is a gin.H
+	Permissions models.Permissions
+	// Impersonation in progress, shown in a banner on every page
+	Impersonation *models.Impersonation
+	// MenuItems added to the sidebar by the extensions
+	MenuItems []MenuItem
 	Content   interface{}
 }
 
@@ -58,6 +74,17 @@ type LayoutHTML struct {
 
 func (r LayoutHTML) Render(w http.ResponseWriter) error {
 	r.WriteContentType(w)
+	if data, ok := r.Data.(LayoutData); ok {
+		data.CSRFToken = w.Header().Get(CSRFTokenHeader)
+		if lw, ok := w.(*layoutResponseWriter); ok {
+			data.Permissions = lw.user.Permissions()
+			data.Impersonation = lw.impersonation
+			if content, ok := data.Content.(gin.H); ok {
+				data.Content = withPermissions(content, data.Permissions)
+			}
+		}
+		r.Data = data
+	}
 	tmpl, ok := r.Templates[r.TemplateName]
 	if !ok {
 		err := fmt.Errorf("template %s not found", r.TemplateName)
@@ -76,6 +103,33 @@ func (r LayoutHTML) Render(w http.ResponseWriter) error {
 	return err
 }
 
+// layoutResponseWriter carries the logged in user to the layout render, which has no access to the request context
+type layoutResponseWriter struct {
+	gin.ResponseWriter
+	user          *models.User
+	impersonation *models.Impersonation
+}
+
+// LayoutUserMiddleware passes the logged in user to the layout, to hide the actions the user cannot perform.
+// It must be used after the AuthMiddleware
+func LayoutUserMiddleware(c *gin.Context) {
+	value, _ := c.Get(ContextUserKey)
+	if user, ok := value.(*models.User); ok {
+		c.Writer = &layoutResponseWriter{ResponseWriter: c.Writer, user: user, impersonation: requestImpersonation(c)}
+	}
+
+	c.Next()
+}
+
+func withPermissions(content gin.H, permissions models.Permissions) gin.H {
+	copied := gin.H{"Permissions": permissions}
+	for key, value := range content {
+		copied[key] = value
+	}
+
+	return copied
+}
+
 func (r LayoutHTML) WriteContentType(w http.ResponseWriter) {
 	header := w.Header()
 	if val := header["Content-Type"]; len(val) == 0 {
@@ -111,12 +165,34 @@ func NewLayoutRender(templatesFS fs.FS, templates ...string) *LayoutRender {
 	return r
 }
 
+// NewHotReloadLayoutRender is like NewLayoutRender, but the templates are parsed again at every render,
+// so that changes on disk are picked up without restarting. It is meant for development only.
+func NewHotReloadLayoutRender(templatesFS fs.FS, templates ...string) *LayoutRender {
+	r := NewLayoutRender(templatesFS, templates...)
+	r.hotReload = true
+	r.templatesFS = templatesFS
+	r.patterns = templates
+
+	return r
+}
+
 // Instance returns a render.HTML instance with the associated named Template
 func (r *LayoutRender) Instance(name string, data interface{}) render.Render {
 	r.data.Content = data
 
+	templates := r.templates
+	if r.hotReload {
+		reloaded := &LayoutRender{
+			root:      r.root,
+			blocks:    r.blocks,
+			templates: map[string]*template.Template{},
+		}
+		reloaded.addGlobFromFS(r.templatesFS, r.patterns...)
+		templates = reloaded.templates
+	}
+
 	return LayoutHTML{
-		Templates:    r.templates,
+		Templates:    templates,
 		TemplateName: name,
 		Data:         r.data,
 	}
@@ -154,9 +230,11 @@ func (r *LayoutRender) addFileFromFS(templatesFS fs.FS, file string) {
 		"sum": func(a int, b int) int {
 			return a + b
 		},
-		"markdown": markdownToHTML,
-		"split":    strings.Split,
-		"script":   script,
+		"markdown":  markdownToHTML,
+		"split":     strings.Split,
+		"script":    script,
+		"sparkline": sparkline,
+		"json":      toJSON,
 	})
 	patterns := append([]string{r.root, file}, r.blocks...)
 	tmpl = template.Must(tmpl.ParseFS(templatesFS, patterns...))
@@ -169,6 +247,35 @@ func script(filename string) template.HTML {
 	return template.HTML(scriptTag)
 }
 
+// sparkline renders a small inline SVG chart of a series of percentages
+func sparkline(values []float64) template.HTML {
+	if len(values) < 2 {
+		return template.HTML("<span class='text-muted'>Not enough data</span>")
+	}
+
+	var points []string
+	for i, v := range values {
+		points = append(points, fmt.Sprintf("%d,%.1f", i, 100-v))
+	}
+
+	svg := fmt.Sprintf(
+		"<svg class=\"sparkline\" width=\"200\" height=\"30\" viewBox=\"0 0 %d 100\" preserveAspectRatio=\"none\">"+
+			"<polyline fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" vector-effect=\"non-scaling-stroke\" points=\"%s\"/></svg>",
+		len(values)-1, strings.Join(points, " "),
+	)
+	return template.HTML(svg)
+}
+
+// toJSON renders a value as compact JSON, for the raw data shown in the pages
+func toJSON(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(b)
+}
+
 func markdownToHTML(md string) template.HTML {
 	extensions := parser.CommonExtensions | parser.AutoHeadingIDs
 	markdownParser := parser.NewWithExtensions(extensions)
@@ -2,7 +2,9 @@ package web
 
 import (
 	"html/template"
+	"net/http/httptest"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -16,3 +18,33 @@ This is a _test_
 	expected := template.HTML("<h1 id=\"heading\">Heading</h1>\n\n<p>This is a <em>test</em></p>\n")
 	assert.Equal(t, expected, output)
 }
+
+func Test_sparkline(t *testing.T) {
+	expected := template.HTML(`<svg class="sparkline" width="200" height="30" viewBox="0 0 2 100" preserveAspectRatio="none">` +
+		`<polyline fill="none" stroke="currentColor" stroke-width="2" vector-effect="non-scaling-stroke" points="0,90.0 1,50.0 2,25.5"/></svg>`)
+	assert.Equal(t, expected, sparkline([]float64{10, 50, 74.5}))
+
+	assert.Contains(t, sparkline([]float64{10}), "Not enough data")
+}
+
+func TestHotReloadLayoutRender(t *testing.T) {
+	templatesFS := fstest.MapFS{
+		"templates/layout.html.tmpl":       {Data: []byte(`{{ template "content" .Content }}`)},
+		"templates/blocks/block.html.tmpl": {Data: []byte(`{{ define "block" }}{{ end }}`)},
+		"templates/page.html.tmpl":         {Data: []byte(`{{ define "content" }}before {{ . }}{{ end }}`)},
+	}
+
+	r := NewHotReloadLayoutRender(templatesFS, "templates/*.tmpl")
+
+	resp := httptest.NewRecorder()
+	err := r.Instance("page.html.tmpl", "reload").Render(resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "before reload", resp.Body.String())
+
+	templatesFS["templates/page.html.tmpl"] = &fstest.MapFile{Data: []byte(`{{ define "content" }}after {{ . }}{{ end }}`)}
+
+	resp = httptest.NewRecorder()
+	err = r.Instance("page.html.tmpl", "reload").Render(resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "after reload", resp.Body.String())
+}
@@ -0,0 +1,181 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// LogSamplingSummaryInterval at which the requests left out of the logs are counted in a summary line
+const LogSamplingSummaryInterval = time.Minute
+
+// LogSampler tells which successful requests are logged, following the sampling rules of their route.
+// The rules are changed at runtime, and the requests left out counted to be summarized periodically
+type LogSampler struct {
+	mutex  sync.Mutex
+	routes map[string]*sampledRoute
+}
+
+type sampledRoute struct {
+	rule     *models.LogSamplingRule
+	requests int64
+	logged   int64
+}
+
+func NewLogSampler() *LogSampler {
+	return &LogSampler{routes: make(map[string]*sampledRoute)}
+}
+
+func (s *LogSampler) SetRules(rules []*models.LogSamplingRule) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	routes := make(map[string]*sampledRoute)
+	for _, rule := range rules {
+		key := rule.Method + " " + rule.Route
+		routes[key] = &sampledRoute{rule: rule}
+		// the counts of the routes still sampled are kept for the next summary
+		if previous, ok := s.routes[key]; ok {
+			routes[key].requests, routes[key].logged = previous.requests, previous.logged
+		}
+	}
+
+	s.routes = routes
+}
+
+func (s *LogSampler) Rules() []*models.LogSamplingRule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rules := []*models.LogSamplingRule{}
+	for _, route := range s.routes {
+		rules = append(rules, route.rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Route != rules[j].Route {
+			return rules[i].Route < rules[j].Route
+		}
+		return rules[i].Method < rules[j].Method
+	})
+
+	return rules
+}
+
+// Sample tells whether a successful request to the route is logged. The requests are logged evenly,
+// e.g. every tenth one with a rate of 0.1
+func (s *LogSampler) Sample(method string, route string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sampled, ok := s.routes[method+" "+route]
+	if !ok {
+		return true
+	}
+
+	sampled.requests++
+	if int64(float64(sampled.requests)*sampled.rule.Rate) <= sampled.logged {
+		return false
+	}
+
+	sampled.logged++
+	return true
+}
+
+// Summarize logs how many requests were left out of the logs since the last summary, for every route
+func (s *LogSampler) Summarize(logger *log.Logger) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key, sampled := range s.routes {
+		if suppressed := sampled.requests - sampled.logged; suppressed > 0 {
+			logger.WithFields(log.Fields{
+				"route":      key,
+				"requests":   sampled.requests,
+				"suppressed": suppressed,
+			}).Info("HTTP requests left out of the logs")
+		}
+		sampled.requests, sampled.logged = 0, 0
+	}
+}
+
+// Run summarizes the requests left out of the logs every interval, until the context is done
+func (s *LogSampler) Run(ctx context.Context, interval time.Duration, logger *log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.Summarize(logger)
+			return
+		case <-ticker.C:
+			s.Summarize(logger)
+		}
+	}
+}
+
+// ApiGetLogSamplingRulesHandler godoc
+// @Summary Retrieve the sampling rules of the request logs
+// @Produce json
+// @Success 200 {array} models.LogSamplingRule
+// @Router /logging/sampling [get]
+func ApiGetLogSamplingRulesHandler(logSampler *LogSampler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, logSampler.Rules())
+	}
+}
+
+// ApiUpdateLogSamplingRulesHandler godoc
+// @Summary Replace the sampling rules of the request logs, taking effect right away
+// @Description Only the given share of the successful requests to the routes is logged, the failed ones always are.
+// @Description The requests left out are counted in a summary line every minute
+// @Accept json
+// @Produce json
+// @Param Body body []models.LogSamplingRule true "The sampling rules"
+// @Success 200 {array} models.LogSamplingRule
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /logging/sampling [put]
+func ApiUpdateLogSamplingRulesHandler(logSampler *LogSampler, settingsService services.SettingsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body []models.LogSamplingRule
+
+		err := c.BindJSON(&body)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		rules := []*models.LogSamplingRule{}
+		for i := range body {
+			if err := validateText("route", body[i].Route, maxIdentifierLength); err != nil {
+				_ = c.Error(err)
+				return
+			}
+			rules = append(rules, &body[i])
+		}
+
+		previous := logSampler.Rules()
+
+		err = settingsService.SaveLogSamplingRules(rules)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		logSampler.SetRules(rules)
+
+		recordAudit(c, auditService, models.AuditActionLogSamplingSaved, models.AuditResourceSettings, "log_sampling",
+			previous, logSampler.Rules())
+
+		c.JSON(http.StatusOK, logSampler.Rules())
+	}
+}
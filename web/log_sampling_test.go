@@ -0,0 +1,149 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestLogSampler(t *testing.T) {
+	sampler := NewLogSampler()
+	sampler.SetRules([]*models.LogSamplingRule{
+		{Method: "POST", Route: "/api/collect", Rate: 0.25},
+		{Method: "POST", Route: "/api/hosts/:id/heartbeat", Rate: 0},
+	})
+
+	logged := 0
+	for i := 0; i < 8; i++ {
+		if sampler.Sample("POST", "/api/collect") {
+			logged++
+		}
+		assert.False(t, sampler.Sample("POST", "/api/hosts/:id/heartbeat"))
+	}
+
+	assert.Equal(t, 2, logged)
+	assert.True(t, sampler.Sample("GET", "/api/collect"))
+	assert.True(t, sampler.Sample("GET", "/api/hosts"))
+
+	logger, hook := test.NewNullLogger()
+	sampler.Summarize(logger)
+
+	assert.Len(t, hook.AllEntries(), 2)
+	for _, entry := range hook.AllEntries() {
+		switch entry.Data["route"] {
+		case "POST /api/collect":
+			assert.Equal(t, int64(8), entry.Data["requests"])
+			assert.Equal(t, int64(6), entry.Data["suppressed"])
+		case "POST /api/hosts/:id/heartbeat":
+			assert.Equal(t, int64(8), entry.Data["requests"])
+			assert.Equal(t, int64(8), entry.Data["suppressed"])
+		default:
+			t.Errorf("unexpected summary of %v", entry.Data["route"])
+		}
+	}
+
+	// the counts are reset after every summary
+	hook.Reset()
+	sampler.Summarize(logger)
+	assert.Empty(t, hook.AllEntries())
+}
+
+func TestLogHandlerSampling(t *testing.T) {
+	sampler := NewLogSampler()
+	sampler.SetRules([]*models.LogSamplingRule{{Method: "GET", Route: "/status/:code", Rate: 0}})
+
+	logger, hook := test.NewNullLogger()
+	engine := NewNamedEngine("test", nil)
+	engine.Use(NewLogHandler("test", logger, sampler))
+	engine.GET("/status/:code", func(c *gin.Context) {
+		if c.Param("code") == "500" {
+			c.Status(500)
+			return
+		}
+		c.Status(200)
+	})
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status/200", nil))
+	assert.Empty(t, hook.AllEntries())
+
+	// the errors are always logged
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status/500", nil))
+	assert.Len(t, hook.AllEntries(), 1)
+}
+
+func TestApiUpdateLogSamplingRulesHandler(t *testing.T) {
+	rules := []*models.LogSamplingRule{{Method: "POST", Route: "/api/collect", Rate: 0.1}}
+
+	settingsService := new(services.MockSettingsService)
+	settingsService.On("InitializeIdentifier").Return(uuid.MustParse("59fd8017-b7fd-477b-9ebe-b658c558f3e9"), nil)
+	settingsService.On("IsEulaAccepted").Return(true, nil).Maybe()
+	settingsService.On("GetMaintenanceMode").Return(&models.MaintenanceMode{}, nil)
+	settingsService.On("GetLogSamplingRules").Return([]*models.LogSamplingRule{}, nil)
+	settingsService.On("GetReadOnlyMode").Return(&models.ReadOnlyMode{}, nil).Maybe()
+	settingsService.On("SaveLogSamplingRules", rules).Return(nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionLogSamplingSaved
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`[{"method": "POST", "route": "/api/collect", "rate": 0.1}]`)
+	req := httptest.NewRequest("PUT", "/api/logging/sampling", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	settingsService.AssertExpectations(t)
+	auditService.AssertExpectations(t)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/logging/sampling", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{"method": "POST", "route": "/api/collect", "rate": 0.1}]`, resp.Body.String())
+	assert.False(t, deps.logSampler.Sample("POST", "/api/collect"))
+}
+
+func TestApiUpdateLogSamplingRulesHandler_Invalid(t *testing.T) {
+	deps := setupTestDependencies()
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, body := range []string{
+		`[{"method": "POST", "route": "/api/collect", "rate": 2}]`,
+		`[{"route": "/api/collect", "rate": 0.5}]`,
+		`[null]`,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/api/logging/sampling", bytes.NewBufferString(body))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code, body)
+	}
+}
@@ -7,12 +7,17 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func NewLogHandler(instance string, logger *log.Logger) gin.HandlerFunc {
+// NewLogHandler logs the requests, the successful ones following the rules of the sampler, if any
+func NewLogHandler(instance string, logger *log.Logger, sampler *LogSampler) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		c.Next()
 		stop := time.Since(start)
 
+		if sampler != nil && c.Writer.Status() < 400 && !sampler.Sample(c.Request.Method, c.FullPath()) {
+			return
+		}
+
 		var level log.Level
 
 		switch true {
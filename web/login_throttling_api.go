@@ -0,0 +1,63 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// ApiListLockoutsHandler godoc
+// @Summary List the users and addresses locked out after too many failed logins
+// @Produce json
+// @Success 200 {object} []models.LoginThrottle
+// @Failure 500 {object} map[string]string
+// @Router /lockouts [get]
+func ApiListLockoutsHandler(loginThrottlingService services.LoginThrottlingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lockouts, err := loginThrottlingService.GetLockouts()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, lockouts)
+	}
+}
+
+// ApiUnlockHandler godoc
+// @Summary Unlock a user, or address, forgetting its failed logins
+// @Produce json
+// @Param type path string true "user or address"
+// @Param subject path string true "Username or address"
+// @Success 200 {object} models.LoginThrottle
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /lockouts/{type}/{subject} [delete]
+func ApiUnlockHandler(loginThrottlingService services.LoginThrottlingService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		throttleType := c.Param("type")
+		if !models.IsValidLoginThrottleType(throttleType) {
+			_ = c.Error(BadRequestError("the type must be user or address"))
+			return
+		}
+
+		throttle, err := loginThrottlingService.Unlock(throttleType, c.Param("subject"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if throttle == nil {
+			_ = c.Error(NotFoundError("no failed logins recorded"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionLoginUnlocked, models.AuditResourceLoginThrottle, throttle.Subject, throttle, nil)
+
+		c.JSON(http.StatusOK, throttle)
+	}
+}
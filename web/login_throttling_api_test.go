@@ -0,0 +1,89 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiLockoutsHandlers(t *testing.T) {
+	lockout := &models.LoginThrottle{
+		Type:          models.LoginThrottleUser,
+		Subject:       "bob",
+		Failures:      10,
+		LastFailureAt: time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC),
+		BlockedUntil:  time.Date(2022, time.March, 12, 10, 15, 0, 0, time.UTC),
+		Locked:        true,
+	}
+
+	loginThrottlingService := new(services.MockLoginThrottlingService)
+	loginThrottlingService.On("GetLockouts").Return([]*models.LoginThrottle{lockout}, nil)
+	loginThrottlingService.On("Unlock", models.LoginThrottleUser, "bob").Return(lockout, nil)
+	loginThrottlingService.On("Unlock", models.LoginThrottleAddress, "192.0.2.1").Return(nil, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.loginThrottlingService = loginThrottlingService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/lockouts", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"type": "user",
+		"subject": "bob",
+		"failures": 10,
+		"last_failure_at": "2022-03-12T10:00:00Z",
+		"blocked_until": "2022-03-12T10:15:00Z",
+		"locked": true
+	}]`, resp.Body.String())
+
+	for url, expected := range map[string]int{
+		"/api/lockouts/user/bob":          200,
+		"/api/lockouts/address/192.0.2.1": 404,
+		"/api/lockouts/group/admins":      400,
+	} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("DELETE", url, nil)
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code, url)
+	}
+
+	auditService.AssertNumberOfCalls(t, "Record", 1)
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionLoginUnlocked && entry.Actor == testUser && entry.ResourceID == "bob"
+	}))
+}
+
+func TestApiLockoutsHandlersForbidden(t *testing.T) {
+	deps := setupTestDependencies()
+	deps.usersService = newMockedUsersService(models.UserRoleOperator)
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/lockouts", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+}
@@ -0,0 +1,157 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// maintenanceRetryAfter is suggested to the clients refused during the maintenance
+const maintenanceRetryAfter = 5 * time.Minute
+
+// maintenanceAllowedRoutes keep working in maintenance mode, to leave it
+var maintenanceAllowedRoutes = map[string]bool{
+	"PUT /api/maintenance":      true,
+	"DELETE /api/impersonation": true,
+}
+
+type JSONMaintenanceMode struct {
+	Enabled         bool   `json:"enabled"`
+	Message         string `json:"message"`
+	PauseCollection bool   `json:"pause_collection"`
+}
+
+// MaintenanceModeMiddleware refuses the changes while the console is in maintenance mode
+func MaintenanceModeMiddleware(settingsService services.SettingsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isSafeMethod(c.Request.Method) || maintenanceAllowedRoutes[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		if refuseDuringMaintenance(c, settingsService, func(m *models.MaintenanceMode) bool { return m.Enabled }) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CollectorMaintenanceModeMiddleware refuses the collected data while the collection is paused by the maintenance mode,
+// the agents sending it again later
+func CollectorMaintenanceModeMiddleware(settingsService services.SettingsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if refuseDuringMaintenance(c, settingsService, func(m *models.MaintenanceMode) bool { return m.Enabled && m.PauseCollection }) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func refuseDuringMaintenance(c *gin.Context, settingsService services.SettingsService, refused func(m *models.MaintenanceMode) bool) bool {
+	maintenanceMode, err := settingsService.GetMaintenanceMode()
+	if err != nil {
+		_ = c.Error(err)
+		c.Abort()
+		return true
+	}
+
+	if !refused(maintenanceMode) {
+		return false
+	}
+
+	message := "the console is in maintenance mode"
+	if maintenanceMode.Message != "" {
+		message += ": " + maintenanceMode.Message
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(maintenanceRetryAfter.Seconds())))
+	_ = c.Error(ServiceUnavailableError(message))
+	c.Abort()
+
+	return true
+}
+
+// ApiGetMaintenanceModeHandler godoc
+// @Summary Retrieve the maintenance mode of the console
+// @Produce json
+// @Success 200 {object} models.MaintenanceMode
+// @Failure 500 {object} map[string]string
+// @Router /maintenance [get]
+func ApiGetMaintenanceModeHandler(settingsService services.SettingsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maintenanceMode, err := settingsService.GetMaintenanceMode()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, maintenanceMode)
+	}
+}
+
+// ApiUpdateMaintenanceModeHandler godoc
+// @Summary Put the console in maintenance mode, or take it out of it
+// @Description In maintenance mode the changes are refused with 503 Service Unavailable,
+// @Description and the collected data as well if the collection is paused
+// @Accept json
+// @Produce json
+// @Param Body body JSONMaintenanceMode true "The maintenance mode"
+// @Success 200 {object} models.MaintenanceMode
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /maintenance [put]
+func ApiUpdateMaintenanceModeHandler(settingsService services.SettingsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONMaintenanceMode
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if err := validateText("message", r.Message, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		previous, err := settingsService.GetMaintenanceMode()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		maintenanceMode := &models.MaintenanceMode{
+			Enabled:         r.Enabled,
+			Message:         r.Message,
+			PauseCollection: r.PauseCollection,
+		}
+
+		if r.Enabled {
+			maintenanceMode.Since, maintenanceMode.EnabledBy = previous.Since, previous.EnabledBy
+			if !previous.Enabled {
+				now := time.Now()
+				maintenanceMode.Since = &now
+				maintenanceMode.EnabledBy = requestActor(c)
+			}
+		}
+
+		err = settingsService.SaveMaintenanceMode(maintenanceMode)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionMaintenanceModeSaved, models.AuditResourceSettings, "maintenance",
+			previous, maintenanceMode)
+
+		c.JSON(http.StatusOK, maintenanceMode)
+	}
+}
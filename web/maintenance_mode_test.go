@@ -0,0 +1,173 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func newMaintenanceSettingsService(maintenanceMode *models.MaintenanceMode) *services.MockSettingsService {
+	settingsService := new(services.MockSettingsService)
+	settingsService.On("InitializeIdentifier").Return(uuid.MustParse("59fd8017-b7fd-477b-9ebe-b658c558f3e9"), nil)
+	settingsService.On("IsEulaAccepted").Return(true, nil).Maybe()
+	settingsService.On("GetMaintenanceMode").Return(maintenanceMode, nil)
+	settingsService.On("GetLogSamplingRules").Return([]*models.LogSamplingRule{}, nil)
+	settingsService.On("GetReadOnlyMode").Return(&models.ReadOnlyMode{}, nil).Maybe()
+
+	return settingsService
+}
+
+func TestMaintenanceModeMiddleware(t *testing.T) {
+	settingsService := newMaintenanceSettingsService(&models.MaintenanceMode{Enabled: true, Message: "Upgrading"})
+	settingsService.On("GetRunnerSettings").Return(&models.RunnerSettings{MaxConcurrentRuns: 4}, nil)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/runner/settings", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("PUT", "/api/runner/settings", bytes.NewBufferString(`{"max_concurrent_runs": 10}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 503, resp.Code)
+	assert.Equal(t, "300", resp.Header().Get("Retry-After"))
+	assert.Contains(t, resp.Body.String(), "the console is in maintenance mode: Upgrading")
+	settingsService.AssertNotCalled(t, "SaveRunnerSettings", mock.Anything)
+}
+
+func TestCollectorMaintenanceModeMiddleware(t *testing.T) {
+	for _, pauseCollection := range []bool{false, true} {
+		collectorService := new(services.MockCollectorService)
+		collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+		deps := setupTestDependencies()
+		deps.settingsService = newMaintenanceSettingsService(&models.MaintenanceMode{Enabled: true, PauseCollection: pauseCollection})
+		deps.collectorService = collectorService
+
+		app, err := NewAppWithDeps(setupTestConfig(), deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := httptest.NewRecorder()
+		body := bytes.NewBufferString(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}}`)
+		req := httptest.NewRequest("POST", "/api/collect", body)
+		req.Header.Set("Accept", "application/json")
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		if pauseCollection {
+			assert.Equal(t, 503, resp.Code)
+			collectorService.AssertNotCalled(t, "StoreEvent", mock.Anything)
+		} else {
+			assert.Equal(t, 202, resp.Code)
+		}
+	}
+}
+
+func TestApiGetMaintenanceModeHandler(t *testing.T) {
+	since := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	deps := setupTestDependencies()
+	deps.settingsService = newMaintenanceSettingsService(&models.MaintenanceMode{
+		Enabled:   true,
+		Message:   "Upgrading",
+		Since:     &since,
+		EnabledBy: "admin",
+	})
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/maintenance", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"enabled": true,
+		"message": "Upgrading",
+		"pause_collection": false,
+		"since": "2022-03-01T10:00:00Z",
+		"enabled_by": "admin"
+	}`, resp.Body.String())
+}
+
+func TestApiUpdateMaintenanceModeHandler(t *testing.T) {
+	settingsService := newMaintenanceSettingsService(&models.MaintenanceMode{})
+	settingsService.On("SaveMaintenanceMode", mock.MatchedBy(func(m *models.MaintenanceMode) bool {
+		return m.Enabled && m.PauseCollection && m.Message == "Upgrading" && m.Since != nil && m.EnabledBy != ""
+	})).Return(nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionMaintenanceModeSaved && e.ResourceID == "maintenance"
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"enabled": true, "message": "Upgrading", "pause_collection": true}`)
+	req := httptest.NewRequest("PUT", "/api/maintenance", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	settingsService.AssertExpectations(t)
+	auditService.AssertExpectations(t)
+}
+
+func TestApiUpdateMaintenanceModeHandler_Disable(t *testing.T) {
+	since := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	settingsService := newMaintenanceSettingsService(&models.MaintenanceMode{Enabled: true, Since: &since, EnabledBy: "admin"})
+	settingsService.On("SaveMaintenanceMode", &models.MaintenanceMode{}).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the maintenance mode can be left while enabled
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/api/maintenance", bytes.NewBufferString(`{"enabled": false}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	settingsService.AssertExpectations(t)
+}
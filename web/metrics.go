@@ -0,0 +1,102 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/services"
+)
+
+const (
+	certificateRevoked        = "revoked"
+	certificateOfAnotherAgent = "agent_id_mismatch"
+)
+
+// rejectedAgentCertificates counts the collector requests rejected because of the client certificate of the agent
+var rejectedAgentCertificates = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "rejected_certificates_total",
+	Help:      "Agent client certificates rejected by the collector, by reason.",
+}, []string{"reason"})
+
+// deniedCollectorRequests counts the collector requests denied because the address is not in the allowlist
+var deniedCollectorRequests = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "denied_requests_total",
+	Help:      "Collector requests denied because the address of the client is not in the allowlist.",
+})
+
+// NewMetricsRegistry registers the metrics of the web server, exposed in the Prometheus format
+func NewMetricsRegistry(backlogProjector *datapipeline.BacklogProjector, projectorsWorkerPool *datapipeline.ProjectorsWorkerPool) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	backlogGauge := func(name string, help string, value func() float64) prometheus.GaugeFunc {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "trento",
+			Subsystem: "projection_backlog",
+			Name:      name,
+			Help:      help,
+		}, value)
+	}
+
+	registry.MustRegister(
+		rejectedAgentCertificates,
+		deniedCollectorRequests,
+		collectorCompressionRatio,
+		oversizedCollectorBodies,
+		ingestionQuotaRejections,
+		backpressureRejections,
+		collectedPayloads,
+		collectedPayloadSize,
+		services.CollectedEvents,
+		services.StoredPayloadSize,
+		datapipeline.ProjectionRetries,
+		datapipeline.ExhaustedProjectionRetries,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "trento",
+			Subsystem: "projectors",
+			Name:      "queue_depth",
+			Help:      "Events collected waiting for a projector worker.",
+		}, func() float64 {
+			return float64(projectorsWorkerPool.QueueDepth())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "trento",
+			Subsystem: "projectors",
+			Name:      "queue_capacity",
+			Help:      "Events collected which can wait for a projector worker before the collector blocks.",
+		}, func() float64 {
+			return datapipeline.ProjectorsQueueSize
+		}),
+		backlogGauge("in_progress", "Whether the backlog of events collected before the startup is being projected.", func() float64 {
+			if backlogProjector.Progress().InProgress {
+				return 1
+			}
+			return 0
+		}),
+		backlogGauge("events", "Events of the backlog to project.", func() float64 {
+			return float64(backlogProjector.Progress().Total)
+		}),
+		backlogGauge("projected_events", "Events of the backlog projected so far.", func() float64 {
+			return float64(backlogProjector.Progress().Projected)
+		}),
+		backlogGauge("superseded_events", "Events of the backlog skipped, superseded by a later event of the same agent and discovery type.", func() float64 {
+			return float64(backlogProjector.Progress().Superseded)
+		}),
+	)
+
+	return registry
+}
+
+// ApiMetricsHandler godoc
+// @Summary Get the metrics of the web server, in the Prometheus text format
+// @Produce plain
+// @Success 200 {string} string
+// @Router /metrics [get]
+func ApiMetricsHandler(registry *prometheus.Registry) gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
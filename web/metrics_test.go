@@ -0,0 +1,27 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiMetricsHandler(t *testing.T) {
+	app, err := NewAppWithDeps(setupTestConfig(), setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/metrics", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), "trento_projection_backlog_in_progress 0")
+	assert.Contains(t, resp.Body.String(), "trento_projection_backlog_events 0")
+	assert.Contains(t, resp.Body.String(), "trento_projection_backlog_projected_events 0")
+	assert.Contains(t, resp.Body.String(), "trento_projection_backlog_superseded_events 0")
+	assert.Contains(t, resp.Body.String(), "trento_projectors_queue_depth 0")
+	assert.Contains(t, resp.Body.String(), "trento_projectors_queue_capacity 1000")
+}
@@ -2,9 +2,11 @@ package web
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/web/chaos"
 	"github.com/trento-project/trento/web/services"
 )
 
@@ -28,3 +30,23 @@ func EulaMiddleware(premiumDetection services.PremiumDetectionService) gin.Handl
 		c.Next()
 	}
 }
+
+// ChaosTimeoutMiddleware randomly makes the requests time out, according to the chaos configuration
+func ChaosTimeoutMiddleware(injector *chaos.Injector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !injector.ShouldTimeoutChecksResults() {
+			c.Next()
+			return
+		}
+
+		log.Debugf("Chaos: injecting a timeout on %s", c.Request.URL.Path)
+
+		select {
+		case <-time.After(injector.ChecksResultsTimeout()):
+		case <-c.Request.Context().Done():
+		}
+
+		_ = c.Error(GatewayTimeoutError("chaos: injected timeout"))
+		c.Abort()
+	}
+}
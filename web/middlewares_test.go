@@ -3,9 +3,13 @@ package web
 import (
 	"errors"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/web/chaos"
 	"github.com/trento-project/trento/web/services"
 )
 
@@ -67,3 +71,29 @@ func TestEulaMiddlewareError(t *testing.T) {
 
 	assert.Equal(t, 500, resp.Code)
 }
+
+func TestChaosTimeoutMiddleware(t *testing.T) {
+	checksService := new(services.MockChecksService)
+
+	deps := setupTestDependencies()
+	deps.checksService = checksService
+	config := setupTestConfig()
+	config.ChaosConfig = &chaos.Config{
+		ChecksResultsTimeoutRate: 1,
+		ChecksResultsTimeout:     time.Millisecond,
+	}
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/checks/cluster1/results", strings.NewReader("{}"))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Accept", "application/json")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 504, resp.Code)
+	checksService.AssertNotCalled(t, "CreateChecksResult", mock.Anything)
+}
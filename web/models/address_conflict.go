@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	AddressConflictIPAddress = "ip_address"
+	AddressConflictHostname  = "hostname"
+)
+
+// AddressConflictOwner is a resource claiming an address: a host, a cluster holding it as a virtual IP
+// or a SAP system using it as virtual hostname
+type AddressConflictOwner struct {
+	ResourceType string `json:"resource_type"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// AddressConflict is an IP address or hostname claimed by more than one resource
+type AddressConflict struct {
+	Kind       string                  `json:"kind"`
+	Address    string                  `json:"address"`
+	Owners     []*AddressConflictOwner `json:"owners"`
+	DetectedAt time.Time               `json:"detected_at"`
+}
+
+func (c *AddressConflict) Involves(resourceType string, id string) bool {
+	for _, o := range c.Owners {
+		if o.ResourceType == resourceType && o.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
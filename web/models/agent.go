@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+const (
+	// AgentStatusPending agents sent data, which is not projected until an admin approves them
+	AgentStatusPending  = "pending"
+	AgentStatusApproved = "approved"
+	// AgentStatusRejected agents are refused by the collector
+	AgentStatusRejected = "rejected"
+)
+
+type Agent struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	// EnrolledAt is the first time the agent contacted the collector
+	EnrolledAt time.Time `json:"enrolled_at"`
+	// ReviewedAt and ReviewedBy are empty until an admin approves or rejects the agent
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	ReviewedBy string     `json:"reviewed_by"`
+}
+
+func IsValidAgentStatus(status string) bool {
+	return status == AgentStatusPending || status == AgentStatusApproved || status == AgentStatusRejected
+}
+
+// AgentInfo is the registration the agent reported when it last started
+type AgentInfo struct {
+	Version       string    `json:"version"`
+	Discoveries   []string  `json:"discoveries"`
+	OSName        string    `json:"os_name"`
+	OSVersion     string    `json:"os_version"`
+	KernelVersion string    `json:"kernel_version"`
+	Arch          string    `json:"arch"`
+	RegisteredAt  time.Time `json:"registered_at"`
+}
+
+// AgentOverview is the activity of the agent of a host, for the fleet health dashboards
+type AgentOverview struct {
+	AgentID  string `json:"agent_id"`
+	Hostname string `json:"hostname"`
+	// Version is empty until the agent registers
+	Version string `json:"version"`
+	// LastSeenAt is when the last heartbeat of the agent was received, nil if none
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	// Heartbeat is the health of the host according to the heartbeats of its agent
+	Heartbeat string `json:"heartbeat"`
+	// Payloads are the payloads stored by discovery type
+	Payloads map[string]int64 `json:"payloads"`
+	// Errors are the payloads recently rejected as invalid by discovery type
+	Errors map[string]int64 `json:"errors"`
+}
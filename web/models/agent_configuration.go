@@ -0,0 +1,22 @@
+package models
+
+// AgentSettings are the discovery settings shared by the agents, pulled with their configuration
+type AgentSettings struct {
+	// DiscoveryPeriods in seconds by discovery type, the agents keep their own period if not set
+	DiscoveryPeriods map[string]int `json:"discovery_periods"`
+	// DisabledDiscoveries are not run by the agents
+	DisabledDiscoveries []string `json:"disabled_discoveries"`
+}
+
+// AgentConfiguration is the configuration an agent is desired to run with
+type AgentConfiguration struct {
+	AgentID            string         `json:"agent_id"`
+	DiscoveryPeriods   map[string]int `json:"discovery_periods"`
+	EnabledDiscoveries []string       `json:"enabled_discoveries"`
+	// ClusterID of the host, empty if not clustered or not discovered yet
+	ClusterID string `json:"cluster_id,omitempty"`
+	// SelectedChecks of the cluster of the host
+	SelectedChecks []string `json:"selected_checks"`
+	// Revision changes along with the configuration, so that the agents apply it only once
+	Revision string `json:"revision"`
+}
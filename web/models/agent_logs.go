@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// AgentLogs is an excerpt of the recent logs of an agent, shipped on demand for the troubleshooting
+type AgentLogs struct {
+	AgentID     string    `json:"agent_id"`
+	RequestedAt time.Time `json:"requested_at"`
+	RequestedBy string    `json:"requested_by"`
+	// ReceivedAt is nil while the agent has not shipped its logs yet
+	ReceivedAt *time.Time `json:"received_at"`
+	Truncated  bool       `json:"truncated"`
+	Logs       string     `json:"logs"`
+}
+
+// IsPending tells whether the logs were requested and not shipped yet
+func (l *AgentLogs) IsPending() bool {
+	return l.ReceivedAt == nil
+}
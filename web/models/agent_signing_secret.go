@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// AgentSigningSecret is shared by an agent and the server, the agent signing its payloads with it
+type AgentSigningSecret struct {
+	AgentID string `json:"agent_id"`
+	// Secret is only returned when generated
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
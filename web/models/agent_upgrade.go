@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	// AgentUpgradeStatusPending is the status of the upgrades not delivered yet, the agent being disconnected
+	AgentUpgradeStatusPending = "pending"
+	// AgentUpgradeStatusSent is the status of the upgrades sent to the agent, not acknowledged yet
+	AgentUpgradeStatusSent = "sent"
+	// AgentUpgradeStatusInProgress is the status of the upgrades the agent acknowledged, until it registers again
+	AgentUpgradeStatusInProgress = "in_progress"
+	// AgentUpgradeStatusCompleted is the status of the upgrades once the agent registers with the version requested
+	AgentUpgradeStatusCompleted = "completed"
+	// AgentUpgradeStatusFailed is the status of the upgrades the agent acknowledged with an error
+	AgentUpgradeStatusFailed = "failed"
+)
+
+// AgentVersion is a release of the agent the hosts can be upgraded to
+type AgentVersion struct {
+	Version string `json:"version" binding:"required"`
+	// URL the agent downloads the release from
+	URL string `json:"url" binding:"required"`
+	// Checksum is the SHA-256 of the release, hex encoded, the agent verifies once downloaded
+	Checksum  string    `json:"checksum" binding:"required"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+// AgentUpgrade is the latest upgrade requested to the agent of a host
+type AgentUpgrade struct {
+	AgentID     string    `json:"agent_id"`
+	Version     string    `json:"version"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
+	RequestedBy string    `json:"requested_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsActive tells whether the upgrade is yet to be done
+func (u *AgentUpgrade) IsActive() bool {
+	return u.Status != AgentUpgradeStatusCompleted && u.Status != AgentUpgradeStatusFailed
+}
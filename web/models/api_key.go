@@ -0,0 +1,50 @@
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// ApiKeyScopeRead keys are only allowed to read, they act as viewers
+	ApiKeyScopeRead = "read"
+	// ApiKeyScopeWrite keys are also allowed to change the resources, they act as operators
+	ApiKeyScopeWrite = "write"
+)
+
+type ApiKey struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// Prefix is the beginning of the key, to tell the keys apart without disclosing them
+	Prefix string `json:"prefix"`
+	Scope  string `json:"scope"`
+	// Organization the key is restricted to, the one of the user who created it, none if empty
+	Organization string     `json:"organization,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	// Key is only returned once, when the key is created
+	Key string `json:"key,omitempty"`
+}
+
+// Role returns the role of the users the key is granted the permissions of
+func (k *ApiKey) Role() string {
+	if k.Scope == ApiKeyScopeWrite {
+		return UserRoleOperator
+	}
+
+	return UserRoleViewer
+}
+
+// Allows tells whether the key scope allows requests with the given HTTP method
+func (k *ApiKey) Allows(method string) bool {
+	if k.Scope == ApiKeyScopeWrite {
+		return true
+	}
+
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func IsValidApiKeyScope(scope string) bool {
+	return scope == ApiKeyScopeRead || scope == ApiKeyScopeWrite
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+const (
+	AuditActionTagCreated           = "tag_created"
+	AuditActionTagDeleted           = "tag_deleted"
+	AuditActionChecksSettingsSaved  = "checks_settings_saved"
+	AuditActionChecksCatalogSaved   = "checks_catalog_saved"
+	AuditActionRunnerSettingsSaved  = "runner_settings_saved"
+	AuditActionEulaAccepted         = "eula_accepted"
+	AuditActionUserCreated          = "user_created"
+	AuditActionUserRoleChanged      = "user_role_changed"
+	AuditActionUserOrgChanged       = "user_organization_changed"
+	AuditActionUserDeleted          = "user_deleted"
+	AuditActionApiKeyCreated        = "api_key_created"
+	AuditActionApiKeyRevoked        = "api_key_revoked"
+	AuditActionPayloadCaptureSaved  = "payload_capture_saved"
+	AuditActionAgentApproved        = "agent_approved"
+	AuditActionAgentRejected        = "agent_rejected"
+	AuditActionImpersonationStarted = "impersonation_started"
+	AuditActionImpersonationStopped = "impersonation_stopped"
+	AuditActionLoginLockedOut       = "login_locked_out"
+	AuditActionLoginUnlocked        = "login_unlocked"
+	AuditActionInconsistencyFixed   = "inconsistency_fixed"
+	AuditActionRestrictionSaved     = "resource_restriction_saved"
+	AuditActionRestrictionDeleted   = "resource_restriction_deleted"
+	AuditActionBaselineSaved        = "baseline_saved"
+	AuditActionBaselineDeleted      = "baseline_deleted"
+
+	AuditActionPersonalAccessTokenCreated = "personal_access_token_created"
+	AuditActionPersonalAccessTokenRevoked = "personal_access_token_revoked"
+	AuditActionMaintenanceModeSaved       = "maintenance_mode_saved"
+	AuditActionAgentSecretRotated         = "agent_secret_rotated"
+	AuditActionAgentSecretDeleted         = "agent_secret_deleted"
+	AuditActionAgentEnrollmentReset       = "agent_enrollment_reset"
+	AuditActionLogSamplingSaved           = "log_sampling_saved"
+	AuditActionReadOnlyModeSaved          = "read_only_mode_saved"
+	AuditActionUsageAnalyticsSaved        = "usage_analytics_saved"
+	AuditActionSAPSystemDeleted           = "sap_system_deleted"
+	AuditActionHostDecommissioned         = "host_decommissioned"
+	AuditActionProjectorDisabled          = "projector_disabled"
+	AuditActionProjectorEnabled           = "projector_enabled"
+	AuditActionAgentLogsRequested         = "agent_logs_requested"
+	AuditActionAgentSettingsSaved         = "agent_settings_saved"
+	AuditActionAgentVersionSaved          = "agent_version_saved"
+	AuditActionAgentUpgradeRequested      = "agent_upgrade_requested"
+	AuditActionDeadLettersRequeued        = "dead_letters_requeued"
+
+	AuditResourceChecksCatalog = "checks_catalog"
+	AuditResourceSettings      = "settings"
+	AuditResourceUser          = "users"
+	AuditResourceApiKey        = "api_keys"
+	AuditResourceAgent         = "agents"
+	AuditResourceLoginThrottle = "login_throttles"
+	AuditResourceInconsistency = "inconsistencies"
+	AuditResourceRestriction   = "resource_restrictions"
+	AuditResourceBaseline      = "baselines"
+
+	AuditResourcePersonalAccessToken = "personal_access_tokens"
+	AuditResourceProjector           = "projectors"
+	AuditResourceAgentVersion        = "agent_versions"
+	AuditResourceDeadLetter          = "dead_letters"
+)
+
+// AuditEntry records a change made by a user, with the state of the changed resource before and after it
+type AuditEntry struct {
+	ID    int64     `json:"id"`
+	Time  time.Time `json:"time"`
+	Actor string    `json:"actor"`
+	// ImpersonatedUser the actor was impersonating when making the change, if any
+	ImpersonatedUser string      `json:"impersonated_user,omitempty"`
+	Action           string      `json:"action"`
+	ResourceType     string      `json:"resource_type"`
+	ResourceID       string      `json:"resource_id"`
+	Before           interface{} `json:"before"`
+	After            interface{} `json:"after"`
+	// Organization of the actor, the entries being visible to the users of the organization only
+	Organization string `json:"organization,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// AvailabilityWindows are the periods of time, in days, the availability is computed over
+var AvailabilityWindows = []int{7, 30, 90}
+
+type Availability struct {
+	Days       int     `json:"days"`
+	Percentage float64 `json:"percentage"`
+}
+
+func IsAvailabilityWindow(days int) bool {
+	for _, d := range AvailabilityWindows {
+		if d == days {
+			return true
+		}
+	}
+
+	return false
+}
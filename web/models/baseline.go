@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	// DifferenceChanged settings have another value than on the golden host
+	DifferenceChanged = "changed"
+	// DifferenceMissing settings are only found on the golden host
+	DifferenceMissing = "missing"
+	// DifferenceUnexpected settings are not found on the golden host
+	DifferenceUnexpected = "unexpected"
+)
+
+// HostBaseline makes a host the golden one of the hosts tagged with the role,
+// their configuration being compared against it to catch the drift
+type HostBaseline struct {
+	Role      string    `json:"role"`
+	AgentID   string    `json:"agent_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ConfigurationDifference is a setting collected by the discovery differing from the golden host.
+// The values are JSON encoded, empty when the setting is missing
+type ConfigurationDifference struct {
+	DiscoveryType string `json:"discovery_type"`
+	Path          string `json:"path"`
+	Kind          string `json:"kind"`
+	Expected      string `json:"expected"`
+	Actual        string `json:"actual"`
+}
+
+type HostDrift struct {
+	AgentID     string                     `json:"agent_id"`
+	HostName    string                     `json:"hostname"`
+	Differences []*ConfigurationDifference `json:"differences"`
+}
+
+// BaselineReport compares the hosts of a role against its golden host
+type BaselineReport struct {
+	Baseline       *HostBaseline `json:"baseline"`
+	GoldenHostName string        `json:"golden_hostname"`
+	Hosts          []*HostDrift  `json:"hosts"`
+}
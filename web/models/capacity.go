@@ -0,0 +1,57 @@
+package models
+
+// MemoryAllocation is the memory a HANA instance is allowed to allocate on a host
+type MemoryAllocation struct {
+	SAPSystemID       string `json:"sap_system_id"`
+	SID               string `json:"sid"`
+	InstanceNumber    string `json:"instance_number"`
+	AllocatedMemoryMB int64  `json:"allocated_memory_mb"`
+	// Default is true when no global allocation limit is configured and the HANA default one applies
+	Default bool `json:"default"`
+}
+
+type HostCapacity struct {
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	Hypervisor        string              `json:"hypervisor"`
+	Sockets           int                 `json:"sockets"`
+	Cores             int                 `json:"cores"`
+	Threads           int                 `json:"threads"`
+	PhysicalMemoryMB  int64               `json:"physical_memory_mb"`
+	AllocatedMemoryMB int64               `json:"allocated_memory_mb"`
+	Allocations       []*MemoryAllocation `json:"allocations"`
+	OverCommitted     bool                `json:"over_committed"`
+}
+
+// SAPSystemCapacity compares the memory allocated by the instances of a HANA database
+// to the physical memory of the hosts running them
+type SAPSystemCapacity struct {
+	ID                string `json:"id"`
+	SID               string `json:"sid"`
+	HostsCount        int    `json:"hosts_count"`
+	PhysicalMemoryMB  int64  `json:"physical_memory_mb"`
+	AllocatedMemoryMB int64  `json:"allocated_memory_mb"`
+	OverCommitted     bool   `json:"over_committed"`
+}
+
+type CapacityOverview struct {
+	Hosts      []*HostCapacity      `json:"hosts"`
+	SAPSystems []*SAPSystemCapacity `json:"sap_systems"`
+}
+
+// AllocatedPercentage returns the allocated memory relative to the physical one, 0 if the latter is unknown
+func (h *HostCapacity) AllocatedPercentage() float64 {
+	return allocatedPercentage(h.AllocatedMemoryMB, h.PhysicalMemoryMB)
+}
+
+func (s *SAPSystemCapacity) AllocatedPercentage() float64 {
+	return allocatedPercentage(s.AllocatedMemoryMB, s.PhysicalMemoryMB)
+}
+
+func allocatedPercentage(allocated int64, physical int64) float64 {
+	if physical == 0 {
+		return 0
+	}
+
+	return float64(allocated) / float64(physical) * 100
+}
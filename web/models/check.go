@@ -19,6 +19,24 @@ type Check struct {
 	Selected       bool   `json:"selected,omitempty" mapstructure:"selected,omitempty"`
 	Result         string `json:"result,omitempty" mapstructure:"result,omitempty"`
 	Msg            string `json:"msg,omitempty" mapstructure:"msg,omitempty"`
+	// Facts are the values the check declares to discover, reported by the runner alongside the result
+	Facts []*CheckFact `json:"facts,omitempty" mapstructure:"facts,omitempty"`
+	// FactValues are the values of the facts discovered on a host, keyed by the fact name
+	FactValues map[string]string `json:"fact_values,omitempty" mapstructure:"factvalues,omitempty"`
+}
+
+type CheckFact struct {
+	Name        string `json:"name" mapstructure:"name"`
+	Description string `json:"description,omitempty" mapstructure:"description,omitempty"`
+}
+
+// DiscoveredFact is the value of a fact discovered on a host by the last checks execution
+type DiscoveredFact struct {
+	CheckID     string `json:"check_id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Host        string `json:"host"`
+	Value       string `json:"value"`
 }
 
 type GroupedChecks struct {
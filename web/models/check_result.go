@@ -1,5 +1,10 @@
 package models
 
+import (
+	"sort"
+	"time"
+)
+
 const (
 	CheckPassing   string = "passing"
 	CheckWarning   string = "warning"
@@ -9,15 +14,18 @@ const (
 )
 
 type ChecksResult struct {
-	ID     string                   `json:"-"`
-	Hosts  map[string]*HostState    `json:"hosts,omitempty"`
-	Checks map[string]*ChecksByHost `json:"checks,omitempty"`
+	ID        string                   `json:"-"`
+	CreatedAt time.Time                `json:"-"`
+	Hosts     map[string]*HostState    `json:"hosts,omitempty"`
+	Checks    map[string]*ChecksByHost `json:"checks,omitempty"`
 }
 
 // Simplifed stuct consumed by the frontend
 type ChecksResultAsList struct {
 	Hosts  map[string]*HostState `json:"hosts,omitempty"`
 	Checks []*ChecksByHost       `json:"checks,omitempty"`
+	// UpdatedAt is when the runner stored the results, so that the outdated ones can be told apart
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 }
 
 // The ChecksByHost struct stores the checks list, but the results are grouped by hosts
@@ -85,3 +93,90 @@ func (a *AggregatedCheckData) String() string {
 
 	return CheckUndefined
 }
+
+// ChecksRun identifies a single execution of the checks on a cluster
+type ChecksRun struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CheckResultChange is the result change of a check on a given host between two runs
+type CheckResultChange struct {
+	CheckID     string `json:"check_id"`
+	Description string `json:"description,omitempty"`
+	Host        string `json:"host"`
+	Previous    string `json:"previous"`
+	Current     string `json:"current"`
+}
+
+type ChecksResultDiff struct {
+	Previous     *ChecksRun           `json:"previous"`
+	Current      *ChecksRun           `json:"current"`
+	NewlyFailing []*CheckResultChange `json:"newly_failing"`
+	NewlyPassing []*CheckResultChange `json:"newly_passing"`
+	NewlyMuted   []*CheckResultChange `json:"newly_muted"`
+}
+
+func isFailing(result string) bool {
+	return result == CheckWarning || result == CheckCritical
+}
+
+// A check is muted when it is skipped or not executed at all
+func isMuted(result string) bool {
+	return result == CheckSkipped || result == ""
+}
+
+// Diff compares the results with the ones of a previous run, per check and host
+func (c *ChecksResult) Diff(previous *ChecksResult) *ChecksResultDiff {
+	diff := &ChecksResultDiff{
+		NewlyFailing: []*CheckResultChange{},
+		NewlyPassing: []*CheckResultChange{},
+		NewlyMuted:   []*CheckResultChange{},
+	}
+
+	results := make(map[string]map[string][2]string)
+	collect := func(checksResult *ChecksResult, index int) {
+		for checkID, check := range checksResult.Checks {
+			if _, ok := results[checkID]; !ok {
+				results[checkID] = make(map[string][2]string)
+			}
+			for host, hostResult := range check.Hosts {
+				r := results[checkID][host]
+				r[index] = hostResult.Result
+				results[checkID][host] = r
+			}
+		}
+	}
+	collect(previous, 0)
+	collect(c, 1)
+
+	for checkID, hosts := range results {
+		for host, r := range hosts {
+			change := &CheckResultChange{CheckID: checkID, Host: host, Previous: r[0], Current: r[1]}
+
+			switch {
+			case isFailing(r[1]) && !isFailing(r[0]):
+				diff.NewlyFailing = append(diff.NewlyFailing, change)
+			case r[1] == CheckPassing && isFailing(r[0]):
+				diff.NewlyPassing = append(diff.NewlyPassing, change)
+			case isMuted(r[1]) && !isMuted(r[0]):
+				diff.NewlyMuted = append(diff.NewlyMuted, change)
+			}
+		}
+	}
+
+	for _, changes := range [][]*CheckResultChange{diff.NewlyFailing, diff.NewlyPassing, diff.NewlyMuted} {
+		sortCheckResultChanges(changes)
+	}
+
+	return diff
+}
+
+func sortCheckResultChanges(changes []*CheckResultChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].CheckID != changes[j].CheckID {
+			return changes[i].CheckID < changes[j].CheckID
+		}
+		return changes[i].Host < changes[j].Host
+	})
+}
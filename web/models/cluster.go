@@ -34,6 +34,9 @@ type Cluster struct {
 	// TODO: this is frontend specific, should be removed
 	HasDuplicatedName bool
 	Details           interface{}
+	Favorite          bool
+	// Provisioning is nil if the cluster was not deployed by a known automation
+	Provisioning *Provisioning
 }
 
 type ClusterList []*Cluster
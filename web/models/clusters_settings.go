@@ -10,6 +10,8 @@ type HostConnection struct {
 	Name    string `json:"name"`
 	Address string `json:"address"`
 	User    string `json:"user"`
+	// CloudProvider the host runs on, empty if none was detected
+	CloudProvider string `json:"cloud_provider"`
 }
 
 type ClustersSettings []*ClusterSettings
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CostReport aggregates the monitored resources and their uptime over a period of time,
+// grouped by the values of the tags of a key, e.g. the cost-center:4711 tag of the cost-center key
+type CostReport struct {
+	TagKey string             `json:"tag_key"`
+	From   time.Time          `json:"from"`
+	To     time.Time          `json:"to"`
+	Groups []*CostReportGroup `json:"groups"`
+}
+
+// CostReportGroup is empty valued for the resources without any tag of the key.
+// The resources tagged with several values of the key are accounted in each of their groups
+type CostReportGroup struct {
+	Value string `json:"value"`
+	// Hosts sending heartbeats during the period
+	Hosts           int     `json:"hosts"`
+	HostUptimeHours float64 `json:"host_uptime_hours"`
+	// HANAInstances running on the hosts sending heartbeats during the period, grouped by the tags of their database
+	HANAInstances           int     `json:"hana_instances"`
+	HANAInstanceUptimeHours float64 `json:"hana_instance_uptime_hours"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+type DashboardWidget struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	DataURL string `json:"data_url"`
+}
+
+type DashboardLayout struct {
+	Widgets []string `json:"widgets" binding:"required"`
+}
+
+type PipelineStatus struct {
+	EventsCount     int64     `json:"events_count"`
+	LastCollectedAt time.Time `json:"last_collected_at"`
+	LastProjectedAt time.Time `json:"last_projected_at"`
+}
+
+// ProjectorStatus is the position of a projector in the stream of events of an agent.
+// Projectors only move forward on the discovery types they handle, so the last projected event
+// is expected to lag behind the last received one.
+type ProjectorStatus struct {
+	ProjectorID          string    `json:"projector_id"`
+	AgentID              string    `json:"agent_id"`
+	LastProjectedEventID int64     `json:"last_projected_event_id"`
+	LastEventID          int64     `json:"last_event_id"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// ProjectionBacklog is the progress of the projection of the events collected, but not projected yet,
+// when the web server started
+type ProjectionBacklog struct {
+	InProgress bool `json:"in_progress"`
+	// Total events to project, the latest of every agent and discovery type
+	Total     int64 `json:"total"`
+	Projected int64 `json:"projected"`
+	// Superseded events are skipped, a later event of the same agent and discovery type replacing their state
+	Superseded int64      `json:"superseded"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// RegisteredProjector is a projector of the registry, which can be disabled at runtime
+type RegisteredProjector struct {
+	ID             string     `json:"id"`
+	DiscoveryTypes []string   `json:"discovery_types"`
+	Enabled        bool       `json:"enabled"`
+	DisabledAt     *time.Time `json:"disabled_at"`
+	DisabledBy     string     `json:"disabled_by,omitempty"`
+	// PendingEvents collected while the projector is disabled, the latest of which are projected once enabled again
+	PendingEvents int64 `json:"pending_events"`
+}
+
+// DeadLetter is an event a projector failed to project, until it is requeued successfully
+type DeadLetter struct {
+	ID            int64     `json:"id"`
+	ProjectorID   string    `json:"projector_id"`
+	EventID       int64     `json:"event_id"`
+	AgentID       string    `json:"agent_id"`
+	DiscoveryType string    `json:"discovery_type"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+	FailedAt      time.Time `json:"failed_at"`
+}
+
+// DeadLetterRequeue is the outcome of the requeue of dead letters
+type DeadLetterRequeue struct {
+	// Requeued are the dead letters projected, and removed
+	Requeued int `json:"requeued"`
+	// Failed are the dead letters failing again, or whose event was pruned
+	Failed []*DeadLetter `json:"failed"`
+}
+
+type ResourceAlert struct {
+	ResourceType string `json:"resource_type"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Health       string `json:"health"`
+}
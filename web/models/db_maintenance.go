@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+const (
+	DBRecommendationBloatedTable     string = "bloated_table"
+	DBRecommendationMissingStats     string = "missing_statistics"
+	DBRecommendationUnusedIndex      string = "unused_index"
+	DBRecommendationLongRunningQuery string = "long_running_query"
+)
+
+// DBRecommendation is a maintenance suggestion about an object of the Trento database.
+// Runnable recommendations are safe to be executed by Trento itself.
+type DBRecommendation struct {
+	Kind     string `json:"kind"`
+	Target   string `json:"target"`
+	Message  string `json:"message"`
+	Action   string `json:"action"`
+	Runnable bool   `json:"runnable"`
+}
+
+type DBMaintenanceReport struct {
+	InspectedAt     time.Time           `json:"inspected_at"`
+	Recommendations []*DBRecommendation `json:"recommendations"`
+}
+
+// GetRunnableRecommendation returns the runnable recommendation targeting the given table, nil if there is none
+func (r *DBMaintenanceReport) GetRunnableRecommendation(table string) *DBRecommendation {
+	for _, recommendation := range r.Recommendations {
+		if recommendation.Runnable && recommendation.Target == table {
+			return recommendation
+		}
+	}
+
+	return nil
+}
+
+type SchemaColumn struct {
+	Name     string `json:"name"`
+	DataType string `json:"data_type"`
+}
+
+type SchemaTable struct {
+	Name    string          `json:"name"`
+	Columns []*SchemaColumn `json:"columns"`
+}
+
+// SchemaInfo describes the database server and the tables of the Trento schema, as migrated
+type SchemaInfo struct {
+	ServerVersion string         `json:"server_version"`
+	Tables        []*SchemaTable `json:"tables"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	// EntitlementsEnforcementSoft only warns when the limits are exceeded
+	EntitlementsEnforcementSoft = "soft"
+	// EntitlementsEnforcementHard refuses the agents of the new hosts beyond the hosts limit,
+	// the hosts monitored already are never refused
+	EntitlementsEnforcementHard = "hard"
+
+	// EntitlementsSourceLicense entitlements are read from the license file
+	EntitlementsSourceLicense = "license"
+	// EntitlementsSourceSubscription entitlements are detected from the SLES for SAP subscriptions of the hosts
+	EntitlementsSourceSubscription = "subscription"
+
+	EntitlementsWarningHostsLimitApproaching = "hosts_limit_approaching"
+	EntitlementsWarningHostsLimitExceeded    = "hosts_limit_exceeded"
+	EntitlementsWarningLicenseExpiring       = "license_expiring"
+	EntitlementsWarningLicenseExpired        = "license_expired"
+)
+
+type Entitlements struct {
+	Source    string     `json:"source"`
+	Customer  string     `json:"customer,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	// HostsLimit is 0 when the number of monitored hosts is not limited
+	HostsLimit     int    `json:"hosts_limit"`
+	MonitoredHosts int    `json:"monitored_hosts"`
+	PremiumChecks  bool   `json:"premium_checks"`
+	Enforcement    string `json:"enforcement"`
+	// Warnings are shown in a banner, empty when the entitlements are in good standing
+	Warnings []string `json:"warnings"`
+}
+
+func IsValidEntitlementsEnforcement(enforcement string) bool {
+	return enforcement == EntitlementsEnforcementSoft || enforcement == EntitlementsEnforcementHard
+}
@@ -0,0 +1,7 @@
+package models
+
+type Favorite struct {
+	ResourceType string `json:"resource_type" binding:"required"`
+	ResourceID   string `json:"resource_id" binding:"required"`
+	Name         string `json:"name"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	HealthResourceHost      = "host"
+	HealthResourceCluster   = "cluster"
+	HealthResourceSAPSystem = "sap_system"
+	HealthResourceDatabase  = "database"
+)
+
+// HealthRecord is the health of a resource since the time it changed to it
+type HealthRecord struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	Health       string    `json:"health"`
+	Since        time.Time `json:"since"`
+}
+
+// HealthSnapshot holds the health of the resources at a point in time, by resource type and ID
+type HealthSnapshot map[string]map[string]*HealthRecord
+
+// Get returns nil if the health of the resource was not known at the time
+func (s HealthSnapshot) Get(resourceType string, id string) *HealthRecord {
+	return s[resourceType][id]
+}
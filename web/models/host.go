@@ -1,7 +1,12 @@
 package models
 
 import (
+	"strings"
+	"time"
+
 	"github.com/trento-project/trento/internal/cloud"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/version"
 )
 
 const (
@@ -16,18 +21,33 @@ const (
 )
 
 type Host struct {
-	ID            string
-	Name          string
-	Health        string
-	IPAddresses   []string
-	CloudProvider string
-	ClusterID     string
-	ClusterName   string
-	ClusterType   string
-	SAPSystems    []*SAPSystem
-	AgentVersion  string
-	Tags          []string
-	CloudData     interface{}
+	ID     string
+	Name   string
+	Health string
+	// HealthChangedAt is since when the host has its health, nil if the host was not marked yet
+	HealthChangedAt *time.Time
+	IPAddresses     []string
+	CloudProvider   string
+	ClusterID       string
+	ClusterName     string
+	ClusterType     string
+	SAPSystems      []*SAPSystem
+	AgentVersion    string
+	Tags            []string
+	CloudData       interface{}
+	Favorite        bool
+	// Ephemeral hosts are expected to go away, like auto-scaled application servers
+	Ephemeral bool
+	// AgentProfile is empty for the agents running the full discovery
+	AgentProfile string
+	// Provisioning is nil if the host was not deployed by a known automation
+	Provisioning *Provisioning
+	// CertificateFingerprint of the mTLS client certificate of the agent, empty without mTLS
+	CertificateFingerprint string
+	// AgentInfo is nil if the agent never registered
+	AgentInfo *AgentInfo
+	// AgentUpgrade is nil if no upgrade was ever requested to the agent
+	AgentUpgrade *AgentUpgrade
 }
 
 type AzureCloudData struct {
@@ -43,6 +63,32 @@ type AzureCloudData struct {
 
 type HostList []*Host
 
+// IsBasic tells whether the host is tracked for availability only, without SAP or cluster discovery
+func (h *Host) IsBasic() bool {
+	return h.AgentProfile == hosts.AgentProfileBasic
+}
+
+// RunningAgentVersion is the version the agent registered with, the discovered one if it never registered
+func (h *Host) RunningAgentVersion() string {
+	if h.AgentInfo != nil && h.AgentInfo.Version != "" {
+		return h.AgentInfo.Version
+	}
+	return h.AgentVersion
+}
+
+// HasAgentVersionDrift tells whether the agent runs a version other than the one of the server
+func (h *Host) HasAgentVersionDrift() bool {
+	return isAgentVersionDrift(h.RunningAgentVersion(), version.Version)
+}
+
+// isAgentVersionDrift ignores the unknown versions, like the ones of the development builds
+func isAgentVersionDrift(agentVersion string, serverVersion string) bool {
+	if agentVersion == "" || serverVersion == "" {
+		return false
+	}
+	return strings.TrimPrefix(agentVersion, "v") != strings.TrimPrefix(serverVersion, "v")
+}
+
 func (h *Host) PrettyProvider() string {
 	switch h.CloudProvider {
 	case cloud.Azure:
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+const (
+	// HostMetricsRetention is how long the resources usage samples pushed by the agents are kept
+	HostMetricsRetention = 24 * time.Hour
+	// HostMetricsSparklinePoints is the default number of points of the sparklines
+	HostMetricsSparklinePoints = 60
+)
+
+// HostMetricsSparkline is the resources usage of a host over a recent period of time, averaged over points
+// of the same duration, oldest first. The points without samples are null
+type HostMetricsSparkline struct {
+	Since       time.Time  `json:"since"`
+	StepSeconds int        `json:"step_seconds"`
+	CPU         []*float64 `json:"cpu"`
+	Memory      []*float64 `json:"memory"`
+	Disk        []*float64 `json:"disk"`
+}
+
+// HasSamples tells whether any point of the sparkline has samples
+func (s *HostMetricsSparkline) HasSamples() bool {
+	for _, value := range s.CPU {
+		if value != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *HostMetricsSparkline) CPUValues() []float64 {
+	return presentValues(s.CPU)
+}
+
+func (s *HostMetricsSparkline) MemoryValues() []float64 {
+	return presentValues(s.Memory)
+}
+
+func (s *HostMetricsSparkline) DiskValues() []float64 {
+	return presentValues(s.Disk)
+}
+
+// Latest returns the last point with samples, nil if there are none
+func (s *HostMetricsSparkline) Latest() *HostMetricsPoint {
+	for i := len(s.CPU) - 1; i >= 0; i-- {
+		if s.CPU[i] != nil {
+			return &HostMetricsPoint{CPUPercent: *s.CPU[i], MemoryPercent: *s.Memory[i], DiskPercent: *s.Disk[i]}
+		}
+	}
+	return nil
+}
+
+type HostMetricsPoint struct {
+	CPUPercent    float64
+	MemoryPercent float64
+	DiskPercent   float64
+}
+
+func presentValues(points []*float64) []float64 {
+	var values []float64
+	for _, value := range points {
+		if value != nil {
+			values = append(values, *value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+const (
+	// HostUtilizationBucket is the period of time the utilization samples are aggregated over
+	HostUtilizationBucket = time.Hour
+	// HostUtilizationRetentionDays is how long the utilization snapshots are kept
+	HostUtilizationRetentionDays = 30
+)
+
+type HostUtilizationSnapshot struct {
+	Time             time.Time `json:"time"`
+	SamplesCount     int       `json:"samples_count"`
+	CPUPercentAvg    float64   `json:"cpu_percent_avg"`
+	CPUPercentMax    float64   `json:"cpu_percent_max"`
+	MemoryPercentAvg float64   `json:"memory_percent_avg"`
+	MemoryPercentMax float64   `json:"memory_percent_max"`
+	DiskPercentMax   float64   `json:"disk_percent_max"`
+}
+
+// HostUtilization is a time series of snapshots, oldest first
+type HostUtilization []*HostUtilizationSnapshot
+
+func (u HostUtilization) CPU() []float64 {
+	var values []float64
+	for _, s := range u {
+		values = append(values, s.CPUPercentAvg)
+	}
+	return values
+}
+
+func (u HostUtilization) Memory() []float64 {
+	var values []float64
+	for _, s := range u {
+		values = append(values, s.MemoryPercentAvg)
+	}
+	return values
+}
+
+func (u HostUtilization) Disk() []float64 {
+	var values []float64
+	for _, s := range u {
+		values = append(values, s.DiskPercentMax)
+	}
+	return values
+}
+
+// Latest returns the most recent snapshot, nil if there are none
+func (u HostUtilization) Latest() *HostUtilizationSnapshot {
+	if len(u) == 0 {
+		return nil
+	}
+	return u[len(u)-1]
+}
+
+// Peak returns the highest CPU, memory and disk usage of the series
+func (u HostUtilization) Peak() *HostUtilizationSnapshot {
+	peak := &HostUtilizationSnapshot{}
+	for _, s := range u {
+		if s.CPUPercentMax > peak.CPUPercentMax {
+			peak.CPUPercentMax = s.CPUPercentMax
+		}
+		if s.MemoryPercentMax > peak.MemoryPercentMax {
+			peak.MemoryPercentMax = s.MemoryPercentMax
+		}
+		if s.DiskPercentMax > peak.DiskPercentMax {
+			peak.DiskPercentMax = s.DiskPercentMax
+		}
+	}
+	return peak
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	// InconsistencyClusterMissing hosts belong to a cluster which is not projected
+	InconsistencyClusterMissing = "cluster_missing"
+	// InconsistencyHostMissing SAP instances, subscriptions or workloads run on a host which is not projected
+	InconsistencyHostMissing = "host_missing"
+)
+
+// Inconsistency is a resource the read models refer to, but which is missing.
+// Projecting again the latest events of the agents involved restores it, as long as they are stored
+type Inconsistency struct {
+	Kind string `json:"kind"`
+	// ResourceID is the ID of the missing cluster or host
+	ResourceID string `json:"resource_id"`
+	// ReferencedBy are the types of the resources referring to the missing one
+	ReferencedBy []string `json:"referenced_by"`
+	AgentIDs     []string `json:"agent_ids"`
+	// Fixable tells whether events of the agents are stored, to be projected again
+	Fixable    bool      `json:"fixable"`
+	DetectedAt time.Time `json:"detected_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	LandscapeNodeSAPSystem = "sap_system"
+	LandscapeNodeDatabase  = "database"
+	LandscapeNodeCluster   = "cluster"
+	LandscapeNodeHost      = "host"
+
+	// LandscapeEdgeAttachedDatabase links a SAP system to the database it uses
+	LandscapeEdgeAttachedDatabase = "attached_database"
+	// LandscapeEdgeInstance links a SAP system or a database to the hosts running its instances
+	LandscapeEdgeInstance = "instance"
+	// LandscapeEdgeClusterMember links a host to its cluster
+	LandscapeEdgeClusterMember = "cluster_member"
+	// LandscapeEdgeSystemReplication links the HANA primary host to the secondary ones
+	LandscapeEdgeSystemReplication = "system_replication"
+)
+
+type LandscapeGraph struct {
+	Nodes       []*LandscapeNode `json:"nodes"`
+	Edges       []*LandscapeEdge `json:"edges"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+type LandscapeNode struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Label      string            `json:"label"`
+	Health     string            `json:"health"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type LandscapeEdge struct {
+	Source     string            `json:"source"`
+	Target     string            `json:"target"`
+	Kind       string            `json:"kind"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// LogSamplingRule logs only a share of the successful requests to a route, e.g. the heartbeats flooding the logs
+// at scale. The failed requests are always logged
+type LogSamplingRule struct {
+	Method string `json:"method" binding:"required"`
+	// Route as registered, e.g. /api/hosts/:id/heartbeat
+	Route string `json:"route" binding:"required"`
+	// Rate is the share of the successful requests logged, 0 suppressing them
+	Rate float64 `json:"rate" binding:"min=0,max=1"`
+}
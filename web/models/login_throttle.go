@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	LoginThrottleUser    = "user"
+	LoginThrottleAddress = "address"
+)
+
+// LoginThrottle tracks the failed logins of a user, or from an address, holding back the next attempts
+type LoginThrottle struct {
+	// Type is either LoginThrottleUser or LoginThrottleAddress, Subject being the username or the address
+	Type          string    `json:"type"`
+	Subject       string    `json:"subject"`
+	Failures      int       `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	// BlockedUntil is the time before which the logins are rejected without checking the credentials
+	BlockedUntil time.Time `json:"blocked_until"`
+	// Locked is set once the failures reach the limit, the logins being blocked for the whole lockout duration
+	Locked bool `json:"locked"`
+}
+
+func IsValidLoginThrottleType(throttleType string) bool {
+	return throttleType == LoginThrottleUser || throttleType == LoginThrottleAddress
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// MaintenanceMode puts the console in read-only mode, e.g. during the upgrades.
+// The changes are refused, and the collected data as well if the collection is paused
+type MaintenanceMode struct {
+	Enabled         bool   `json:"enabled"`
+	Message         string `json:"message"`
+	PauseCollection bool   `json:"pause_collection"`
+	// Since and EnabledBy are set when the mode is enabled
+	Since     *time.Time `json:"since,omitempty"`
+	EnabledBy string     `json:"enabled_by,omitempty"`
+}
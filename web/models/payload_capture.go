@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// PayloadCaptureSettings select the collected payloads stored verbatim, to debug the projectors.
+// The capture stops by itself once expired
+type PayloadCaptureSettings struct {
+	// SamplingPercentage of the payloads of all the agents to capture
+	SamplingPercentage int `json:"sampling_percentage"`
+	// AgentID whose payloads are all captured, regardless of the sampling
+	AgentID string `json:"agent_id"`
+	// MaxSizeBytes of a captured payload, longer payloads are truncated
+	MaxSizeBytes int        `json:"max_size_bytes"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+}
+
+// IsActive tells whether any payload is to be captured at the given time
+func (s *PayloadCaptureSettings) IsActive(now time.Time) bool {
+	if s.ExpiresAt == nil || !now.Before(*s.ExpiresAt) {
+		return false
+	}
+
+	return s.SamplingPercentage > 0 || s.AgentID != ""
+}
+
+type CapturedPayload struct {
+	ID        int64     `json:"id"`
+	AgentID   string    `json:"agent_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Size of the payload as received, before the truncation
+	Size      int    `json:"size"`
+	Truncated bool   `json:"truncated"`
+	Body      string `json:"body"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+const (
+	TokenScopeHostsRead      = "hosts:read"
+	TokenScopeClustersRead   = "clusters:read"
+	TokenScopeSAPSystemsRead = "sapsystems:read"
+	TokenScopeTagsRead       = "tags:read"
+	TokenScopeTagsWrite      = "tags:write"
+	TokenScopeCatalogRead    = "catalog:read"
+	TokenScopeCatalogWrite   = "catalog:write"
+	TokenScopeReportsRead    = "reports:read"
+)
+
+// TokenScopes are the API capabilities the personal access tokens can be granted
+var TokenScopes = []string{
+	TokenScopeHostsRead,
+	TokenScopeClustersRead,
+	TokenScopeSAPSystemsRead,
+	TokenScopeTagsRead,
+	TokenScopeTagsWrite,
+	TokenScopeCatalogRead,
+	TokenScopeCatalogWrite,
+	TokenScopeReportsRead,
+}
+
+// PersonalAccessToken lets a user call the API with a bearer token, acting as the user
+// but only on the routes covered by the scopes of the token, until it expires
+type PersonalAccessToken struct {
+	ID       int64  `json:"id"`
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	// Prefix is the beginning of the token, to tell the tokens apart without disclosing them
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	// Token is only returned once, when the token is created
+	Token string `json:"token,omitempty"`
+}
+
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsValidTokenScope(scope string) bool {
+	for _, s := range TokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,7 @@
+package models
+
+// Provisioning describes the automation that deployed a host or a cluster, like terraform and salt
+type Provisioning struct {
+	Tool            string
+	TemplateVersion string
+}
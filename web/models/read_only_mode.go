@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ReadOnlyMode freezes the console, e.g. during the audits or the incidents. Unlike the maintenance mode,
+// the changes are forbidden rather than to be retried, and the data collection is never affected
+type ReadOnlyMode struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+	// Since and EnabledBy are set when the mode is enabled
+	Since     *time.Time `json:"since,omitempty"`
+	EnabledBy string     `json:"enabled_by,omitempty"`
+}
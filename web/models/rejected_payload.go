@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// PayloadValidationIssue is a violation of the JSON schema of a discovery
+type PayloadValidationIssue struct {
+	// Location of the invalid value in the payload, as a JSON pointer
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+// RejectedPayload is the payload of a discovery refused as not valid against its JSON schema
+type RejectedPayload struct {
+	ID            int64                     `json:"id"`
+	AgentID       string                    `json:"agent_id"`
+	DiscoveryType string                    `json:"discovery_type"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	Issues        []*PayloadValidationIssue `json:"issues"`
+	Size          int                       `json:"size"`
+	Truncated     bool                      `json:"truncated"`
+	Payload       string                    `json:"payload"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// restrictablePermissions are the permissions which can be restricted on the resources of the given types
+var restrictablePermissions = map[string][]string{
+	PermissionTagsWrite:   {TagHostResourceType, TagClusterResourceType, TagSAPSystemResourceType, TagDatabaseResourceType},
+	PermissionChecksWrite: {TagClusterResourceType},
+}
+
+// ResourceRestriction narrows down who is granted a permission on a resource, e.g. locking the tags
+// of the production systems to the admins. The users need either the role or to be listed,
+// besides being granted the permission in the first place
+type ResourceRestriction struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	Permission   string    `json:"permission"`
+	Role         string    `json:"role,omitempty"`
+	Usernames    []string  `json:"usernames"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Allows tells whether the user is allowed to use the restricted permission
+func (r *ResourceRestriction) Allows(user *User) bool {
+	if r.Role != "" && user.HasRole(r.Role) {
+		return true
+	}
+
+	for _, username := range r.Usernames {
+		if username == user.Username {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsRestrictablePermission(permission string, resourceType string) bool {
+	for _, t := range restrictablePermissions[permission] {
+		if t == resourceType {
+			return true
+		}
+	}
+
+	return false
+}
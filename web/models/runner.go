@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	QueuedRunStateQueued  = "queued"
+	QueuedRunStateRunning = "running"
+)
+
+// RunnerSettings bound the checks executions run at the same time by the runner,
+// globally and per target host. A zero value means no limit.
+type RunnerSettings struct {
+	MaxConcurrentRuns int `json:"max_concurrent_runs" binding:"min=0"`
+	MaxRunsPerTarget  int `json:"max_runs_per_target" binding:"min=0"`
+}
+
+// QueuedRun is a checks execution waiting for or holding a runner slot
+type QueuedRun struct {
+	ClusterID string     `json:"cluster_id" binding:"required"`
+	Targets   []string   `json:"targets"`
+	State     string     `json:"state" binding:"required,oneof=queued running"`
+	QueuedAt  time.Time  `json:"queued_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+}
@@ -15,18 +15,21 @@ const (
 )
 
 type SAPSystem struct {
-	ID               string
-	SID              string
-	Type             string
-	Instances        []*SAPSystemInstance
-	AttachedDatabase *SAPSystem
-	DBName           string
-	DBHost           string
-	DBAddress        string
-	Health           string
-	Tags             []string
+	ID        string
+	SID       string
+	Type      string
+	Instances []*SAPSystemInstance
+	// KubernetesWorkloads are the pods running components of the system on Kubernetes nodes
+	KubernetesWorkloads []*KubernetesWorkload
+	AttachedDatabase    *SAPSystem
+	DBName              string
+	DBHost              string
+	DBAddress           string
+	Health              string
+	Tags                []string
 	// TODO: this is frontend specific, should be removed
 	HasDuplicatedSID bool
+	Favorite         bool
 }
 
 type SAPSystemInstance struct {
@@ -48,6 +51,22 @@ type SAPSystemInstance struct {
 	Hostname                string
 }
 
+// KubernetesWorkload is a SAP component running in a pod, Node is the Kubernetes node
+// and HostID the Trento host running on it
+type KubernetesWorkload struct {
+	Namespace      string
+	Pod            string
+	Node           string
+	SID            string
+	InstanceNumber string
+	Type           string
+	Component      string
+	Image          string
+	Phase          string
+	HostID         string
+	Hostname       string
+}
+
 type SAPSystemList []*SAPSystem
 
 func (s SAPSystem) GetAllInstances() []*SAPSystemInstance {
@@ -0,0 +1,10 @@
+package models
+
+// SearchResult is a resource matching a search, the best matches having the highest rank
+type SearchResult struct {
+	// ResourceType is one of hosts, clusters, sapsystems or databases
+	ResourceType string  `json:"resource_type"`
+	ID           string  `json:"id" gorm:"column:resource_id"`
+	Name         string  `json:"name"`
+	Rank         float64 `json:"rank"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type SlesSubscription struct {
 	ID                 string
 	Version            string
@@ -15,3 +17,10 @@ type PremiumData struct {
 	IsPremium     bool
 	Sles4SapCount int
 }
+
+type ExpiringSubscription struct {
+	HostID    string    `json:"host_id"`
+	HostName  string    `json:"host_name"`
+	ID        string    `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 const (
 	TagHostResourceType      = "hosts"
 	TagClusterResourceType   = "clusters"
@@ -7,8 +9,20 @@ const (
 	TagDatabaseResourceType  = "databases"
 )
 
+// CloudTagPrefix is the reserved namespace of the tags mapped from the tags of the cloud resources,
+// e.g. cloud:CostCenter=sap-4711. They are kept in sync by the projection and cannot be changed from the console
+const CloudTagPrefix = "cloud:"
+
 type Tag struct {
 	Value        string `gorm:"primaryKey"`
 	ResourceID   string `gorm:"primaryKey"`
 	ResourceType string `gorm:"primaryKey"`
 }
+
+func CloudTag(key string, value string) string {
+	return CloudTagPrefix + key + "=" + value
+}
+
+func IsCloudTag(tag string) bool {
+	return strings.HasPrefix(tag, CloudTagPrefix)
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	TimelineEventHeartbeat    = "heartbeat"
+	TimelineEventCheckResult  = "check_result"
+	TimelineEventConfigChange = "config_change"
+	TimelineEventFencing      = "fencing"
+	TimelineEventAudit        = "audit"
+
+	TimelineSeverityInfo     = "info"
+	TimelineSeverityPassing  = "passing"
+	TimelineSeverityWarning  = "warning"
+	TimelineSeverityCritical = "critical"
+
+	// TimelineMaxDays is the longest period of time, in days, a timeline can be requested for
+	TimelineMaxDays = 90
+)
+
+// TimelineEvent is something that happened to a resource, as shown in its timeline
+type TimelineEvent struct {
+	Time     time.Time         `json:"time"`
+	Kind     string            `json:"kind"`
+	Severity string            `json:"severity"`
+	Summary  string            `json:"summary"`
+	Details  map[string]string `json:"details,omitempty"`
+}
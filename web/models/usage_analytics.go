@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// UsageAnalyticsSettings opt in to counting the uses of the pages and of the API routes of the console.
+// Only the routes are counted, per day, neither the users nor the parameters are recorded
+type UsageAnalyticsSettings struct {
+	Enabled bool `json:"enabled"`
+}
+
+type RouteUsage struct {
+	// Route as registered, e.g. GET /hosts/:id
+	Route      string    `json:"route"`
+	Count      int64     `json:"count"`
+	LastUsedOn time.Time `json:"last_used_on"`
+}
+
+// UsageReport tells how many times the routes were used since the given day, the most used first
+type UsageReport struct {
+	Enabled bool          `json:"enabled"`
+	Since   time.Time     `json:"since"`
+	Routes  []*RouteUsage `json:"routes"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+const (
+	UserRoleViewer   = "viewer"
+	UserRoleOperator = "operator"
+	UserRoleAdmin    = "admin"
+)
+
+// userRoleLevels sorts the roles, every role is granted the permissions of the lower ones
+var userRoleLevels = map[string]int{
+	UserRoleViewer:   1,
+	UserRoleOperator: 2,
+	UserRoleAdmin:    3,
+}
+
+const (
+	PermissionTagsWrite     = "tags:write"
+	PermissionChecksWrite   = "checks:write"
+	PermissionChecksRun     = "checks:run"
+	PermissionSettingsWrite = "settings:write"
+	PermissionUsersWrite    = "users:write"
+	PermissionAuditRead     = "audit:read"
+)
+
+// permissionRoles are the lowest roles granted every permission
+var permissionRoles = []struct {
+	permission string
+	role       string
+}{
+	{PermissionTagsWrite, UserRoleOperator},
+	{PermissionChecksWrite, UserRoleOperator},
+	{PermissionChecksRun, UserRoleOperator},
+	{PermissionSettingsWrite, UserRoleAdmin},
+	{PermissionUsersWrite, UserRoleAdmin},
+	{PermissionAuditRead, UserRoleAdmin},
+}
+
+type User struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	// Organization the user is restricted to, the users of no organization see all of them
+	Organization string `json:"organization,omitempty"`
+}
+
+// HasRole tells whether the user is granted the permissions of the given role
+func (u *User) HasRole(role string) bool {
+	level, ok := userRoleLevels[role]
+	return ok && userRoleLevels[u.Role] >= level
+}
+
+// Permissions returns the actions the user is allowed to perform
+func (u *User) Permissions() Permissions {
+	permissions := Permissions{}
+	for _, p := range permissionRoles {
+		if u.HasRole(p.role) {
+			permissions = append(permissions, p.permission)
+		}
+	}
+
+	return permissions
+}
+
+func IsValidUserRole(role string) bool {
+	_, ok := userRoleLevels[role]
+	return ok
+}
+
+// Permissions are used to hide the actions the user cannot perform,
+// the authorization is still enforced by the roles required by the API
+type Permissions []string
+
+func (p Permissions) Can(permission string) bool {
+	for _, granted := range p {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+type UserPermissions struct {
+	Username    string      `json:"username"`
+	Role        string      `json:"role"`
+	Permissions Permissions `json:"permissions"`
+	// Organization the resources are restricted to, if any
+	Organization string `json:"organization,omitempty"`
+	// Impersonation in progress, the username and role are the ones of the impersonated user
+	Impersonation *Impersonation `json:"impersonation,omitempty"`
+}
+
+// Impersonation of a user by an admin, who acts with the role and preferences of the user
+// to reproduce the issues it experiences, until it is stopped or it expires
+type Impersonation struct {
+	Impersonator string    `json:"impersonator"`
+	Username     string    `json:"username"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
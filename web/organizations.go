@@ -0,0 +1,131 @@
+package web
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// organizationResources tells, by the path segment preceding a route parameter, the type of the resource
+// of an organization the parameter identifies
+var organizationResources = map[string]string{
+	"hosts":      models.TagHostResourceType,
+	"agents":     models.TagHostResourceType,
+	"clusters":   models.TagClusterResourceType,
+	"checks":     models.TagClusterResourceType,
+	"sapsystems": models.TagSAPSystemResourceType,
+	"databases":  models.TagDatabaseResourceType,
+}
+
+// resourceTypes are the types of the resources belonging to an organization
+var resourceTypes = []string{
+	models.TagHostResourceType, models.TagClusterResourceType, models.TagSAPSystemResourceType, models.TagDatabaseResourceType,
+}
+
+// resourceTypeSegment is the route parameter giving the type of the resource identified by the next one
+const resourceTypeSegment = ":resource_type"
+
+// unscopedSegments precede the route parameters not identifying a resource of an organization
+var unscopedSegments = []string{
+	"api", "assets", "baselines", "captures", "dead-letters", "discoveries", "docs", "favorites", "inconsistencies",
+	"keys", "lockouts", "projectors", "restrictions", "static", "tables", "tags", "tokens", "users",
+	":id", ":kind", ":type",
+}
+
+// OrganizationScopeMiddleware answers the requests of the users of an organization for the hosts, clusters and
+// SAP systems of another one as if they did not exist. The route parameters are told apart by the path segment
+// preceding them, the ones not known to identify a resource or not are refused. It must be used after the AuthMiddleware
+func OrganizationScopeMiddleware(organizationsService services.OrganizationsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userOrganization(c) == "" {
+			c.Next()
+			return
+		}
+
+		segments := strings.Split(c.FullPath(), "/")
+		for i := 1; i < len(segments); i++ {
+			if !isRouteParam(segments[i]) {
+				continue
+			}
+
+			resourceType, known := routeParamResourceType(c, segments[i-1])
+			if !known {
+				_ = c.Error(NotFoundError("resource not found"))
+				c.Abort()
+				return
+			}
+
+			if resourceType == "" {
+				continue
+			}
+
+			visible, err := resourceVisible(c, organizationsService, resourceType, c.Param(segments[i][1:]))
+			if err != nil {
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+
+			if !visible {
+				_ = c.Error(NotFoundError("resource not found"))
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func isRouteParam(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")
+}
+
+// routeParamResourceType returns the type of the resource identified by the route parameter following the segment,
+// empty if none, and whether the segment is known
+func routeParamResourceType(c *gin.Context, previousSegment string) (string, bool) {
+	if resourceType, ok := organizationResources[previousSegment]; ok {
+		return resourceType, true
+	}
+
+	if previousSegment == resourceTypeSegment {
+		resourceType := c.Param(resourceTypeSegment[1:])
+		// the unknown types are left to the handler
+		if internal.Contains(resourceTypes, resourceType) {
+			return resourceType, true
+		}
+		return "", true
+	}
+
+	return "", internal.Contains(unscopedSegments, previousSegment)
+}
+
+// resourceVisible tells whether the resource belongs to the organization of the user, if any.
+// The resources not found are left to the caller
+func resourceVisible(c *gin.Context, organizationsService services.OrganizationsService, resourceType string, resourceID string) (bool, error) {
+	organization := userOrganization(c)
+	if organization == "" {
+		return true, nil
+	}
+
+	resourceOrganization, found, err := organizationsService.GetResourceOrganization(resourceType, resourceID)
+	if err != nil {
+		return false, err
+	}
+
+	return !found || resourceOrganization == organization, nil
+}
+
+// userOrganization returns the organization the logged in user is restricted to, empty if none
+func userOrganization(c *gin.Context) string {
+	value, _ := c.Get(ContextUserKey)
+	if user, ok := value.(*models.User); ok {
+		return user.Organization
+	}
+
+	return ""
+}
@@ -0,0 +1,221 @@
+package web
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestOrganizationScopeMiddleware(t *testing.T) {
+	organizationsService := new(services.MockOrganizationsService)
+	organizationsService.On("GetResourceOrganization", models.TagHostResourceType, "host1").Return("acme", true, nil)
+	organizationsService.On("GetResourceOrganization", models.TagHostResourceType, "unknown").Return("", false, nil)
+	organizationsService.On("GetResourceOrganization", models.TagClusterResourceType, "cluster1").Return("", true, nil)
+	organizationsService.On("GetResourceOrganization", models.TagClusterResourceType, "cluster2").Return("acme", true, nil)
+
+	for _, tc := range []struct {
+		organization string
+		path         string
+		expected     int
+	}{
+		{"acme", "/hosts/host1", 200},
+		{"acme", "/api/hosts/host1/metrics", 200},
+		{"globex", "/hosts/host1", 404},
+		{"globex", "/api/hosts/host1/metrics", 404},
+		// left to the handler
+		{"globex", "/hosts/unknown", 200},
+		{"acme", "/api/clusters/cluster1/results", 404},
+		{"", "/hosts/host1", 200},
+		{"", "/api/clusters/cluster1/results", 200},
+		{"globex", "/api/tags", 200},
+		{"acme", "/api/checks/cluster1/settings", 404},
+		{"acme", "/api/checks/cluster2/settings", 200},
+		{"acme", "/api/restrictions/hosts/host1/write", 200},
+		{"globex", "/api/restrictions/hosts/host1/write", 404},
+		{"globex", "/api/restrictions/unknown/host1/write", 200},
+		// neither known to identify a resource or not
+		{"acme", "/api/unknown/host1", 404},
+		{"", "/api/unknown/host1", 200},
+	} {
+		engine := gin.New()
+		engine.Use(ErrorHandler)
+		engine.Use(func(c *gin.Context) {
+			c.Set(ContextUserKey, &models.User{Username: "user", Organization: tc.organization})
+		})
+		engine.Use(OrganizationScopeMiddleware(organizationsService))
+		ok := func(c *gin.Context) { c.Status(200) }
+		engine.GET("/hosts/:id", ok)
+		engine.GET("/api/hosts/:id/metrics", ok)
+		engine.GET("/api/clusters/:cluster_id/results", ok)
+		engine.GET("/api/tags", ok)
+		engine.GET("/api/checks/:id/settings", ok)
+		engine.GET("/api/restrictions/:resource_type/:id/:permission", ok)
+		engine.GET("/api/unknown/:id", ok)
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set("Accept", "application/json")
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, "%s on %s", tc.organization, tc.path)
+	}
+}
+
+// TestOrganizationScopeRoutes makes sure that every route parameter is known to identify a resource or not
+func TestOrganizationScopeRoutes(t *testing.T) {
+	app, err := NewAppWithDeps(setupTestConfig(), setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, route := range app.webEngine.Routes() {
+		segments := strings.Split(route.Path, "/")
+		for i := 1; i < len(segments); i++ {
+			if !isRouteParam(segments[i]) {
+				continue
+			}
+
+			_, known := routeParamResourceType(&gin.Context{}, segments[i-1])
+			assert.True(t, known, "%s %s", route.Method, route.Path)
+		}
+	}
+}
+
+func TestOrganizationScopedListings(t *testing.T) {
+	landscapeService := new(services.MockLandscapeService)
+	landscapeService.On("GetGraph", "acme").Return(&models.LandscapeGraph{}, nil)
+	searchService := new(services.MockSearchService)
+	searchService.On("Search", "hana", "acme", mock.Anything).Return([]*models.SearchResult{}, nil)
+	capacityService := new(services.MockCapacityService)
+	capacityService.On("GetCapacityOverview", "acme").Return(&models.CapacityOverview{}, nil)
+	healthSummaryService := new(services.MockHealthSummaryService)
+	healthSummaryService.On("GetHealthSummary", "acme").Return(models.HealthSummary{}, nil)
+	clustersService := new(services.MockClustersService)
+	clustersService.On("GetAllClustersSettings", "acme").Return(models.ClustersSettings{}, nil)
+	addressConflictsService := new(services.MockAddressConflictsService)
+	addressConflictsService.On("GetAll", "acme").Return([]*models.AddressConflict{}, nil)
+	costReportService := new(services.MockCostReportService)
+	costReportService.On("GetCostReport", "acme", "owner", mock.Anything, mock.Anything).Return(&models.CostReport{}, nil)
+	prometheusService := new(services.MockPrometheusService)
+	prometheusService.On("GetHttpSDTargets", "acme").Return(models.PrometheusTargetsList{}, nil)
+	favoritesService := new(services.MockFavoritesService)
+	favoritesService.On("GetAll", testUser, "acme").Return([]*models.Favorite{}, nil)
+	tagsService := new(services.MockTagsService)
+	tagsService.On("GetAll", "acme").Return([]string{}, nil)
+	runsQueueService := new(services.MockRunsQueueService)
+	runsQueueService.On("GetAll", "acme").Return([]*models.QueuedRun{}, nil)
+
+	deps := setupTestDependencies()
+	deps.usersService = newMockedOrganizationUsersService("acme")
+	deps.landscapeService = landscapeService
+	deps.searchService = searchService
+	deps.capacityService = capacityService
+	deps.healthSummaryService = healthSummaryService
+	deps.clustersService = clustersService
+	deps.addressConflictsService = addressConflictsService
+	deps.costReportService = costReportService
+	deps.prometheusService = prometheusService
+	deps.favoritesService = favoritesService
+	deps.tagsService = tagsService
+	deps.runsQueueService = runsQueueService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		"/api/landscape/graph",
+		"/api/search?q=hana",
+		"/api/capacity",
+		"/api/sapsystems/health",
+		"/api/clusters/settings",
+		"/api/conflicts",
+		"/api/reports/costs?tag_key=owner",
+		"/api/prometheus/targets",
+		"/api/favorites",
+		"/api/tags",
+		"/api/runs/queue",
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 200, resp.Code, path)
+	}
+
+	for _, m := range []*mock.Mock{
+		&landscapeService.Mock, &searchService.Mock, &capacityService.Mock, &healthSummaryService.Mock, &clustersService.Mock,
+		&addressConflictsService.Mock, &costReportService.Mock, &prometheusService.Mock, &favoritesService.Mock,
+		&tagsService.Mock, &runsQueueService.Mock,
+	} {
+		m.AssertExpectations(t)
+	}
+}
+
+func TestOrganizationScopedAdminListings(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByUsername", testUser).Return(&models.User{ID: 1, Username: testUser, Role: models.UserRoleAdmin, Organization: "acme"}, nil)
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("GetAll", "", "acme").Return([]*models.Agent{}, nil)
+	agentsService.On("GetAll", models.AgentStatusPending, "acme").Return([]*models.Agent{}, nil)
+	auditService := new(services.MockAuditService)
+	auditService.On("GetAll", &services.AuditFilter{Organization: "acme"}, mock.Anything).Return([]*models.AuditEntry{}, nil)
+	auditService.On("GetCount", &services.AuditFilter{Organization: "acme"}).Return(0, nil)
+	auditService.On("GetAllActors", "acme").Return([]string{}, nil)
+	payloadCaptureService := new(services.MockPayloadCaptureService)
+	payloadCaptureService.On("GetSettings").Return(&models.PayloadCaptureSettings{}, nil)
+	payloadCaptureService.On("GetAll", "", "acme").Return([]*models.CapturedPayload{}, nil)
+	payloadCaptureService.On("GetByID", int64(7), "acme").Return(nil, nil)
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("GetRejectedPayloads", "", "acme").Return([]*models.RejectedPayload{}, nil)
+	collectorService.On("GetPipelineStatus").Return(&models.PipelineStatus{}, nil)
+	collectorService.On("GetProjectorsStatus").Return([]*models.ProjectorStatus{}, nil)
+	consistencyService := new(services.MockConsistencyService)
+	consistencyService.On("GetAll", "acme").Return([]*models.Inconsistency{}, nil)
+
+	deps := setupTestDependencies()
+	deps.usersService = usersService
+	deps.agentsService = agentsService
+	deps.auditService = auditService
+	deps.payloadCaptureService = payloadCaptureService
+	deps.collectorService = collectorService
+	deps.consistencyService = consistencyService
+	deps.projectorsManager = datapipeline.NewProjectorsManager(nil, datapipeline.InitProjectorsRegistry(nil))
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, code := range map[string]int{
+		"/api/agents":                   200,
+		"/api/audit":                    200,
+		"/audit":                        200,
+		"/api/pipeline/captures":        200,
+		"/api/pipeline/captures/7":      404,
+		"/api/pipeline/rejected":        200,
+		"/api/pipeline/inconsistencies": 200,
+		"/pipeline":                     200,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, code, resp.Code, path)
+	}
+
+	for _, m := range []*mock.Mock{
+		&agentsService.Mock, &auditService.Mock, &payloadCaptureService.Mock, &collectorService.Mock, &consistencyService.Mock,
+	} {
+		m.AssertExpectations(t)
+	}
+}
@@ -0,0 +1,55 @@
+package web
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+)
+
+// overlayFS serves the files of the upper FS in place of the ones of the lower FS,
+// the directories listing the files of both
+type overlayFS struct {
+	upper fs.FS
+	lower fs.FS
+}
+
+func newOverlayFS(upper fs.FS, lower fs.FS) fs.FS {
+	return &overlayFS{upper: upper, lower: lower}
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	file, err := o.upper.Open(name)
+	if err == nil {
+		return file, nil
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return o.lower.Open(name)
+}
+
+func (o *overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	upperEntries, upperErr := fs.ReadDir(o.upper, name)
+	lowerEntries, lowerErr := fs.ReadDir(o.lower, name)
+	if upperErr != nil && lowerErr != nil {
+		return nil, lowerErr
+	}
+
+	entries := make(map[string]fs.DirEntry)
+	for _, entry := range lowerEntries {
+		entries[entry.Name()] = entry
+	}
+	for _, entry := range upperEntries {
+		entries[entry.Name()] = entry
+	}
+
+	var merged []fs.DirEntry
+	for _, entry := range entries {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+
+	return merged, nil
+}
@@ -0,0 +1,37 @@
+package web
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlayFS(t *testing.T) {
+	overlay := newOverlayFS(
+		fstest.MapFS{
+			"templates/footer.html.tmpl": {Data: []byte("overridden")},
+			"templates/extra.html.tmpl":  {Data: []byte("extra")},
+		},
+		fstest.MapFS{
+			"templates/footer.html.tmpl": {Data: []byte("default")},
+			"templates/hosts.html.tmpl":  {Data: []byte("hosts")},
+		},
+	)
+
+	content, err := fs.ReadFile(overlay, "templates/footer.html.tmpl")
+	assert.NoError(t, err)
+	assert.Equal(t, "overridden", string(content))
+
+	content, err = fs.ReadFile(overlay, "templates/hosts.html.tmpl")
+	assert.NoError(t, err)
+	assert.Equal(t, "hosts", string(content))
+
+	files, err := fs.Glob(overlay, "templates/*.tmpl")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"templates/extra.html.tmpl", "templates/footer.html.tmpl", "templates/hosts.html.tmpl"}, files)
+
+	_, err = fs.ReadFile(overlay, "templates/missing.html.tmpl")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -0,0 +1,176 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type JSONPersonalAccessTokenCreation struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"required,min=1,max=365"`
+}
+
+// ApiListOwnPersonalAccessTokensHandler godoc
+// @Summary Retrieve the personal access tokens of the logged in user, revoked and expired ones included
+// @Produce json
+// @Success 200 {array} models.PersonalAccessToken
+// @Failure 500 {object} map[string]string
+// @Router /me/tokens [get]
+func ApiListOwnPersonalAccessTokensHandler(personalAccessTokensService services.PersonalAccessTokensService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		personalAccessTokens, err := personalAccessTokensService.GetByUser(requestUserID(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, personalAccessTokens)
+	}
+}
+
+// ApiCreatePersonalAccessTokenHandler godoc
+// @Summary Create a personal access token of the logged in user, to be sent as a bearer token
+// @Description The token acts as the user, on the routes covered by its scopes only, until it expires.
+// @Description The token is only returned in this response, it cannot be retrieved afterwards
+// @Accept json
+// @Produce json
+// @Param Body body JSONPersonalAccessTokenCreation true "The personal access token"
+// @Success 201 {object} models.PersonalAccessToken
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /me/tokens [post]
+func ApiCreatePersonalAccessTokenHandler(personalAccessTokensService services.PersonalAccessTokensService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if bearerAuthenticated(c) {
+			_ = c.Error(BadRequestError("personal access tokens can only be created by logged in users"))
+			return
+		}
+
+		// the tokens would outlive the impersonation
+		if requestImpersonation(c) != nil {
+			_ = c.Error(BadRequestError("personal access tokens cannot be created while impersonating a user"))
+			return
+		}
+
+		var r JSONPersonalAccessTokenCreation
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		for _, scope := range r.Scopes {
+			if !models.IsValidTokenScope(scope) {
+				_ = c.Error(BadRequestError(fmt.Sprintf("invalid scope: %s", scope)))
+				return
+			}
+		}
+
+		expiresAt := time.Now().Add(time.Duration(r.ExpiresInDays) * 24 * time.Hour)
+
+		personalAccessToken, err := personalAccessTokensService.Create(requestUserID(c), r.Name, r.Scopes, expiresAt)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		// the token itself is never recorded
+		recordAudit(c, auditService, models.AuditActionPersonalAccessTokenCreated, models.AuditResourcePersonalAccessToken,
+			strconv.FormatInt(personalAccessToken.ID, 10), nil, &r)
+
+		c.JSON(http.StatusCreated, personalAccessToken)
+	}
+}
+
+// ApiRevokeOwnPersonalAccessTokenHandler godoc
+// @Summary Revoke a personal access token of the logged in user
+// @Produce json
+// @Param id path int true "Personal access token id"
+// @Success 200 {object} models.PersonalAccessToken
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /me/tokens/{id} [delete]
+func ApiRevokeOwnPersonalAccessTokenHandler(personalAccessTokensService services.PersonalAccessTokensService, auditService services.AuditService) gin.HandlerFunc {
+	return revokePersonalAccessToken(personalAccessTokensService, auditService, true)
+}
+
+// ApiListPersonalAccessTokensHandler godoc
+// @Summary Retrieve the personal access tokens of every user, revoked and expired ones included
+// @Produce json
+// @Success 200 {array} models.PersonalAccessToken
+// @Failure 500 {object} map[string]string
+// @Router /tokens [get]
+func ApiListPersonalAccessTokensHandler(personalAccessTokensService services.PersonalAccessTokensService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		personalAccessTokens, err := personalAccessTokensService.GetAll()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, personalAccessTokens)
+	}
+}
+
+// ApiRevokePersonalAccessTokenHandler godoc
+// @Summary Revoke the personal access token of any user
+// @Produce json
+// @Param id path int true "Personal access token id"
+// @Success 200 {object} models.PersonalAccessToken
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tokens/{id} [delete]
+func ApiRevokePersonalAccessTokenHandler(personalAccessTokensService services.PersonalAccessTokensService, auditService services.AuditService) gin.HandlerFunc {
+	return revokePersonalAccessToken(personalAccessTokensService, auditService, false)
+}
+
+// revokePersonalAccessToken answers 404 for the tokens of other users when ownOnly is set,
+// not to disclose their existence
+func revokePersonalAccessToken(personalAccessTokensService services.PersonalAccessTokensService, auditService services.AuditService, ownOnly bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			_ = c.Error(NotFoundError("personal access token not found"))
+			return
+		}
+
+		personalAccessToken, err := personalAccessTokensService.Get(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if personalAccessToken == nil || (ownOnly && personalAccessToken.UserID != requestUserID(c)) {
+			_ = c.Error(NotFoundError("personal access token not found"))
+			return
+		}
+
+		personalAccessToken, err = personalAccessTokensService.Revoke(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionPersonalAccessTokenRevoked, models.AuditResourcePersonalAccessToken, c.Param("id"), nil, nil)
+
+		c.JSON(http.StatusOK, personalAccessToken)
+	}
+}
+
+// requestUserID returns the id of the logged in user, 0 for the API keys which are not bound to any
+func requestUserID(c *gin.Context) int64 {
+	value, _ := c.Get(ContextUserKey)
+	if user, ok := value.(*models.User); ok {
+		return user.ID
+	}
+
+	return 0
+}
@@ -0,0 +1,210 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func setupPersonalAccessTokensApiTestApp(t *testing.T, tokensService services.PersonalAccessTokensService) *App {
+	deps := setupTestDependencies()
+	deps.tokensService = tokensService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func TestApiListOwnPersonalAccessTokensHandler(t *testing.T) {
+	tokensService := new(services.MockPersonalAccessTokensService)
+	tokensService.On("GetByUser", int64(1)).Return([]*models.PersonalAccessToken{
+		{
+			ID:        1,
+			UserID:    1,
+			Username:  testUser,
+			Name:      "ci",
+			Prefix:    "trento_pat_abcdef",
+			Scopes:    []string{models.TokenScopeCatalogWrite},
+			ExpiresAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}, nil)
+
+	app := setupPersonalAccessTokensApiTestApp(t, tokensService)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/me/tokens", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"id": 1,
+		"user_id": 1,
+		"username": "test-user",
+		"name": "ci",
+		"prefix": "trento_pat_abcdef",
+		"scopes": ["catalog:write"],
+		"expires_at": "2023-01-01T00:00:00Z",
+		"created_at": "2022-01-01T00:00:00Z",
+		"last_used_at": null,
+		"revoked_at": null
+	}]`, resp.Body.String())
+}
+
+func TestApiCreatePersonalAccessTokenHandler(t *testing.T) {
+	tokensService := new(services.MockPersonalAccessTokensService)
+	tokensService.On("Create", int64(1), "ci", []string{models.TokenScopeCatalogWrite}, mock.MatchedBy(func(expiresAt time.Time) bool {
+		return time.Until(expiresAt) > 29*24*time.Hour && time.Until(expiresAt) <= 30*24*time.Hour
+	})).Return(&models.PersonalAccessToken{
+		ID: 2, UserID: 1, Name: "ci", Prefix: "trento_pat_abcdef", Scopes: []string{models.TokenScopeCatalogWrite}, Token: "trento_pat_abcdefghij",
+	}, nil)
+
+	app := setupPersonalAccessTokensApiTestApp(t, tokensService)
+
+	for _, tc := range []struct {
+		body     string
+		expected int
+	}{
+		{`{"name": "ci", "scopes": ["catalog:write"], "expires_in_days": 30}`, 201},
+		{`{"name": "ci", "scopes": ["users:write"], "expires_in_days": 30}`, 400},
+		{`{"name": "ci", "scopes": [], "expires_in_days": 30}`, 400},
+		{`{"name": "ci", "scopes": ["catalog:write"], "expires_in_days": 366}`, 400},
+		{`{"name": "ci", "scopes": ["catalog:write"]}`, 400},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/me/tokens", bytes.NewBufferString(tc.body))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, tc.body)
+		if resp.Code == 201 {
+			assert.Contains(t, resp.Body.String(), `"token":"trento_pat_abcdefghij"`)
+		}
+	}
+
+	tokensService.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestApiRevokeOwnPersonalAccessTokenHandler(t *testing.T) {
+	revokedAt := time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	tokensService := new(services.MockPersonalAccessTokensService)
+	tokensService.On("Get", int64(1)).Return(&models.PersonalAccessToken{ID: 1, UserID: 1}, nil)
+	tokensService.On("Get", int64(2)).Return(&models.PersonalAccessToken{ID: 2, UserID: 2}, nil)
+	tokensService.On("Get", int64(3)).Return(nil, nil)
+	tokensService.On("Revoke", int64(1)).Return(&models.PersonalAccessToken{ID: 1, UserID: 1, RevokedAt: &revokedAt}, nil)
+
+	app := setupPersonalAccessTokensApiTestApp(t, tokensService)
+
+	for url, expected := range map[string]int{
+		"/api/me/tokens/1": 200,
+		"/api/me/tokens/2": 404,
+		"/api/me/tokens/3": 404,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("DELETE", url, nil)
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code, url)
+	}
+
+	tokensService.AssertNotCalled(t, "Revoke", int64(2))
+}
+
+func TestApiRevokePersonalAccessTokenHandler(t *testing.T) {
+	tokensService := new(services.MockPersonalAccessTokensService)
+	tokensService.On("Get", int64(2)).Return(&models.PersonalAccessToken{ID: 2, UserID: 2}, nil)
+	tokensService.On("Revoke", int64(2)).Return(&models.PersonalAccessToken{ID: 2, UserID: 2}, nil)
+
+	app := setupPersonalAccessTokensApiTestApp(t, tokensService)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/tokens/2", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+}
+
+func TestAuthMiddlewarePersonalAccessToken(t *testing.T) {
+	for _, tc := range []struct {
+		token    string
+		method   string
+		path     string
+		expected int
+	}{
+		{"trento_pat_hosts", "GET", "/api/hosts/host1/timeline", 404},
+		{"trento_pat_hosts", "POST", "/api/hosts/host1/tags", 403},
+		{"trento_pat_tags", "POST", "/api/hosts/host1/tags", 404},
+		{"trento_pat_tags", "GET", "/api/tags", 403},
+		// the scopes do not grant more than the role of the user
+		{"trento_pat_catalog", "PUT", "/api/checks/catalog", 403},
+		{"trento_pat_hosts", "GET", "/api/me/tokens", 403},
+		{"trento_pat_hosts", "GET", "/api/users", 403},
+		{"trento_pat_expired", "GET", "/api/hosts/host1/timeline", 401},
+	} {
+		tokensService := new(services.MockPersonalAccessTokensService)
+		tokensService.On("Authenticate", "trento_pat_hosts").Return(
+			&models.PersonalAccessToken{ID: 1, Scopes: []string{models.TokenScopeHostsRead}},
+			&models.User{ID: 1, Username: "alice", Role: models.UserRoleAdmin}, nil)
+		tokensService.On("Authenticate", "trento_pat_tags").Return(
+			&models.PersonalAccessToken{ID: 2, Scopes: []string{models.TokenScopeTagsWrite}},
+			&models.User{ID: 1, Username: "alice", Role: models.UserRoleOperator}, nil)
+		tokensService.On("Authenticate", "trento_pat_catalog").Return(
+			&models.PersonalAccessToken{ID: 3, Scopes: []string{models.TokenScopeCatalogWrite}},
+			&models.User{ID: 2, Username: "bob", Role: models.UserRoleViewer}, nil)
+		tokensService.On("Authenticate", "trento_pat_expired").Return(nil, nil, nil)
+
+		hostsService := new(services.MockHostsService)
+		hostsService.On("GetByID", "host1").Return(nil, nil)
+
+		deps := setupTestDependencies()
+		deps.store = cookie.NewStore([]byte("secret"))
+		deps.tokensService = tokensService
+		deps.hostsService = hostsService
+
+		app, err := NewAppWithDeps(setupTestConfig(), deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader("{}"))
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, "%s %s %s", tc.token, tc.method, tc.path)
+	}
+}
+
+func TestTokenRouteScopes(t *testing.T) {
+	app := setupPersonalAccessTokensApiTestApp(t, new(services.MockPersonalAccessTokensService))
+
+	routes := make(map[string]bool)
+	for _, route := range app.webEngine.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+
+	for route, scope := range tokenRouteScopes {
+		assert.True(t, routes[route], route)
+		assert.True(t, models.IsValidTokenScope(scope), scope)
+	}
+}
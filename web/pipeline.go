@@ -0,0 +1,423 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type JSONPayloadCapture struct {
+	SamplingPercentage int    `json:"sampling_percentage" binding:"min=0,max=100"`
+	AgentID            string `json:"agent_id"`
+	MaxSizeBytes       int    `json:"max_size_bytes" binding:"min=0,max=1048576"`
+	// DurationMinutes after which the capture stops by itself
+	DurationMinutes int `json:"duration_minutes" binding:"required,min=1,max=1440"`
+}
+
+// projectorsRegistryManager disables and enables the registered projectors at runtime
+type projectorsRegistryManager interface {
+	List() ([]*models.RegisteredProjector, error)
+	Disable(projectorID string, actor string) (*models.RegisteredProjector, error)
+	Enable(projectorID string) (*models.RegisteredProjector, error)
+	CatchUp(ctx context.Context, projectorID string) error
+	ListDeadLetters(projectorID string, organization string) ([]*models.DeadLetter, error)
+	RequeueDeadLetter(id int64) (*models.DeadLetterRequeue, error)
+	RequeueDeadLetters(projectorID string) (*models.DeadLetterRequeue, error)
+}
+
+func NewPipelineHandler(collectorService services.CollectorService, payloadCaptureService services.PayloadCaptureService, agentsService services.AgentsService, consistencyService services.ConsistencyService, projectorsManager projectorsRegistryManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := collectorService.GetPipelineStatus()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		projectorsStatus, err := collectorService.GetProjectorsStatus()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		captureSettings, err := payloadCaptureService.GetSettings()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		agentID := c.Query("agent_id")
+		capturedPayloads, err := payloadCaptureService.GetAll(agentID, userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		pendingAgents, err := agentsService.GetAll(models.AgentStatusPending, userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		inconsistencies, err := consistencyService.GetAll(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		registeredProjectors, err := projectorsManager.List()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.HTML(http.StatusOK, "pipeline.html.tmpl", gin.H{
+			"Projectors":       registeredProjectors,
+			"PendingAgents":    pendingAgents,
+			"Inconsistencies":  inconsistencies,
+			"Status":           status,
+			"ProjectorsStatus": projectorsStatus,
+			"CaptureSettings":  captureSettings,
+			"CaptureActive":    captureSettings.IsActive(time.Now()),
+			"CapturedPayloads": capturedPayloads,
+			"AgentID":          agentID,
+		})
+	}
+}
+
+// ApiGetPayloadCaptureHandler godoc
+// @Summary Retrieve the settings of the raw payload capture
+// @Produce json
+// @Success 200 {object} models.PayloadCaptureSettings
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/capture [get]
+func ApiGetPayloadCaptureHandler(payloadCaptureService services.PayloadCaptureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, err := payloadCaptureService.GetSettings()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+// ApiStartPayloadCaptureHandler godoc
+// @Summary Start capturing the raw collected payloads, of a sampled percentage of them and/or of a specific agent
+// @Accept json
+// @Produce json
+// @Param Body body JSONPayloadCapture true "What to capture and for how long"
+// @Success 200 {object} models.PayloadCaptureSettings
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/capture [put]
+func ApiStartPayloadCaptureHandler(payloadCaptureService services.PayloadCaptureService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONPayloadCapture
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if r.SamplingPercentage == 0 && r.AgentID == "" {
+			_ = c.Error(BadRequestError("either a sampling percentage or an agent is required"))
+			return
+		}
+
+		expiresAt := time.Now().Add(time.Duration(r.DurationMinutes) * time.Minute)
+		settings := &models.PayloadCaptureSettings{
+			SamplingPercentage: r.SamplingPercentage,
+			AgentID:            r.AgentID,
+			MaxSizeBytes:       r.MaxSizeBytes,
+			ExpiresAt:          &expiresAt,
+		}
+
+		savePayloadCaptureSettings(c, payloadCaptureService, auditService, settings)
+	}
+}
+
+// ApiStopPayloadCaptureHandler godoc
+// @Summary Stop capturing the raw collected payloads, the captured ones are kept until they expire
+// @Produce json
+// @Success 200 {object} models.PayloadCaptureSettings
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/capture [delete]
+func ApiStopPayloadCaptureHandler(payloadCaptureService services.PayloadCaptureService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, err := payloadCaptureService.GetSettings()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		stopped := *settings
+		stopped.ExpiresAt = nil
+
+		savePayloadCaptureSettings(c, payloadCaptureService, auditService, &stopped)
+	}
+}
+
+func savePayloadCaptureSettings(c *gin.Context, payloadCaptureService services.PayloadCaptureService, auditService services.AuditService, settings *models.PayloadCaptureSettings) {
+	previousSettings, err := payloadCaptureService.GetSettings()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = payloadCaptureService.SaveSettings(settings)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	recordAudit(c, auditService, models.AuditActionPayloadCaptureSaved, models.AuditResourceSettings, "payload_capture",
+		previousSettings, settings)
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// ApiListCapturedPayloadsHandler godoc
+// @Summary List the captured raw payloads, the most recent first
+// @Produce json
+// @Param agent_id query string false "Filter by agent"
+// @Success 200 {object} []models.CapturedPayload
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/captures [get]
+func ApiListCapturedPayloadsHandler(payloadCaptureService services.PayloadCaptureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payloads, err := payloadCaptureService.GetAll(c.Query("agent_id"), userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, payloads)
+	}
+}
+
+// ApiListRejectedPayloadsHandler godoc
+// @Summary List the payloads rejected as not valid against the JSON schema of their discovery, the most recent first
+// @Produce json
+// @Param agent_id query string false "Filter by agent"
+// @Success 200 {object} []models.RejectedPayload
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/rejected [get]
+func ApiListRejectedPayloadsHandler(collectorService services.CollectorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payloads, err := collectorService.GetRejectedPayloads(c.Query("agent_id"), userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, payloads)
+	}
+}
+
+// ApiGetCapturedPayloadHandler godoc
+// @Summary Retrieve a captured raw payload
+// @Produce json
+// @Param id path int true "Captured payload id"
+// @Success 200 {object} models.CapturedPayload
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/captures/{id} [get]
+func ApiGetCapturedPayloadHandler(payloadCaptureService services.PayloadCaptureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			_ = c.Error(NotFoundError("captured payload not found"))
+			return
+		}
+
+		payload, err := payloadCaptureService.GetByID(id, userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if payload == nil {
+			_ = c.Error(NotFoundError("captured payload not found"))
+			return
+		}
+
+		c.JSON(http.StatusOK, payload)
+	}
+}
+
+// ApiGetProjectionBacklogHandler godoc
+// @Summary Get the progress of the projection of the events collected before the startup
+// @Produce json
+// @Success 200 {object} models.ProjectionBacklog
+// @Router /pipeline/backlog [get]
+func ApiGetProjectionBacklogHandler(backlogProjector *datapipeline.BacklogProjector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, backlogProjector.Progress())
+	}
+}
+
+// ApiListProjectorsHandler godoc
+// @Summary Retrieve the registered projectors, and whether they are enabled
+// @Produce json
+// @Success 200 {array} models.RegisteredProjector
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/projectors [get]
+func ApiListProjectorsHandler(projectorsManager projectorsRegistryManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		projectors, err := projectorsManager.List()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, projectors)
+	}
+}
+
+// ApiDisableProjectorHandler godoc
+// @Summary Disable a projector, the events are still stored and projected once it is enabled again
+// @Produce json
+// @Param id path string true "Projector id"
+// @Success 200 {object} models.RegisteredProjector
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/projectors/{id}/disable [post]
+func ApiDisableProjectorHandler(projectorsManager projectorsRegistryManager, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		projector, err := projectorsManager.Disable(id, requestActor(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if projector == nil {
+			_ = c.Error(NotFoundError("projector not found"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionProjectorDisabled, models.AuditResourceProjector, id, nil, projector)
+
+		c.JSON(http.StatusOK, projector)
+	}
+}
+
+// ApiEnableProjectorHandler godoc
+// @Summary Enable a projector, which catches up on the latest events collected while it was disabled
+// @Produce json
+// @Param id path string true "Projector id"
+// @Success 200 {object} models.RegisteredProjector
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/projectors/{id}/enable [post]
+func ApiEnableProjectorHandler(projectorsManager projectorsRegistryManager, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		projector, err := projectorsManager.Enable(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if projector == nil {
+			_ = c.Error(NotFoundError("projector not found"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionProjectorEnabled, models.AuditResourceProjector, id, nil, projector)
+
+		// the projector is enabled anyway, the next discoveries of the agents are projected
+		if err := projectorsManager.CatchUp(context.Background(), id); err != nil {
+			log.Errorf("Could not catch up on the events of the projector %s: %s", id, err)
+		}
+
+		c.JSON(http.StatusOK, projector)
+	}
+}
+
+// ApiListDeadLettersHandler godoc
+// @Summary List the events the projectors failed to project, the oldest first
+// @Produce json
+// @Param projector query string false "Filter by projector"
+// @Success 200 {array} models.DeadLetter
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/dead-letters [get]
+func ApiListDeadLettersHandler(projectorsManager projectorsRegistryManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deadLetters, err := projectorsManager.ListDeadLetters(c.Query("projector"), userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, deadLetters)
+	}
+}
+
+// ApiRequeueDeadLettersHandler godoc
+// @Summary Project again the events the projectors failed to project, once the cause is fixed
+// @Produce json
+// @Param projector query string false "Requeue the dead letters of this projector only"
+// @Success 200 {object} models.DeadLetterRequeue
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/dead-letters/requeue [post]
+func ApiRequeueDeadLettersHandler(projectorsManager projectorsRegistryManager, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		projectorID := c.Query("projector")
+
+		result, err := projectorsManager.RequeueDeadLetters(projectorID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionDeadLettersRequeued, models.AuditResourceDeadLetter, projectorID, nil, result)
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// ApiRequeueDeadLetterHandler godoc
+// @Summary Project again an event a projector failed to project, once the cause is fixed
+// @Produce json
+// @Param id path int true "Dead letter id"
+// @Success 200 {object} models.DeadLetterRequeue
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pipeline/dead-letters/{id}/requeue [post]
+func ApiRequeueDeadLetterHandler(projectorsManager projectorsRegistryManager, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			_ = c.Error(NotFoundError("dead letter not found"))
+			return
+		}
+
+		result, err := projectorsManager.RequeueDeadLetter(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if result == nil {
+			_ = c.Error(NotFoundError("dead letter not found"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionDeadLettersRequeued, models.AuditResourceDeadLetter, c.Param("id"), nil, result)
+
+		c.JSON(http.StatusOK, result)
+	}
+}
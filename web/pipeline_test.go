@@ -0,0 +1,432 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/html"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestPipelineHandler(t *testing.T) {
+	collectedAt := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := time.Now().Add(time.Hour)
+
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("GetPipelineStatus").Return(&models.PipelineStatus{EventsCount: 42, LastCollectedAt: collectedAt}, nil)
+	collectorService.On("GetProjectorsStatus").Return([]*models.ProjectorStatus{
+		{ProjectorID: "hosts", AgentID: "agent1", LastProjectedEventID: 40, LastEventID: 42, UpdatedAt: collectedAt},
+	}, nil)
+
+	payloadCaptureService := new(services.MockPayloadCaptureService)
+	payloadCaptureService.On("GetSettings").Return(&models.PayloadCaptureSettings{
+		SamplingPercentage: 10,
+		AgentID:            "agent1",
+		MaxSizeBytes:       1024,
+		ExpiresAt:          &expiresAt,
+	}, nil)
+	payloadCaptureService.On("GetAll", "agent1", "").Return([]*models.CapturedPayload{
+		{ID: 7, AgentID: "agent1", CreatedAt: collectedAt, Size: 2048, Truncated: true, Body: `{"agent_id":"agent1"}`},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+	deps.payloadCaptureService = payloadCaptureService
+	deps.projectorsManager = datapipeline.NewProjectorsManager(nil, datapipeline.InitProjectorsRegistry(nil))
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/pipeline?agent_id=agent1", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, minified, "42 events collected, the last one at Mar 01, 2022 10:00:00 UTC")
+	assert.Regexp(t, regexp.MustCompile(`<td>hosts</td><td>agent1</td><td>40</td><td>42</td>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>sapsystems</td><td><span .*>sap_system_discovery</span></td><td><span .*>Enabled</span></td><td></td><td .*><button .*data-projector-id=sapsystems data-action=disable>Disable</button>`), minified)
+	assert.Regexp(t, regexp.MustCompile(`Capturing 10% of the payloads and the payloads of agent agent1,\s+up to 1024 bytes each`), minified)
+	assert.Regexp(t, regexp.MustCompile(`<td>2048 bytes <span class="badge badge-pill badge-warning">truncated</span></td><td><details><summary><a href=/api/pipeline/captures/7>#7</a></summary><pre>{&#34;agent_id&#34;:&#34;agent1&#34;}</pre>`), minified)
+}
+
+func TestApiStartPayloadCaptureHandler(t *testing.T) {
+	payloadCaptureService := new(services.MockPayloadCaptureService)
+	payloadCaptureService.On("GetSettings").Return(&models.PayloadCaptureSettings{MaxSizeBytes: 65536}, nil)
+	payloadCaptureService.On("SaveSettings", mock.Anything).Return(nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.payloadCaptureService = payloadCaptureService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		body     string
+		expected int
+	}{
+		{`{"agent_id": "agent1", "max_size_bytes": 1024}`, 400},
+		{`{"duration_minutes": 60}`, 400},
+		{`{"sampling_percentage": 101, "duration_minutes": 60}`, 400},
+		{`{"sampling_percentage": 10, "agent_id": "agent1", "max_size_bytes": 1024, "duration_minutes": 60}`, 200},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/api/pipeline/capture", bytes.NewBufferString(tc.body))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, tc.body)
+	}
+
+	payloadCaptureService.AssertNumberOfCalls(t, "SaveSettings", 1)
+	saved := payloadCaptureService.Calls[len(payloadCaptureService.Calls)-1].Arguments.Get(0).(*models.PayloadCaptureSettings)
+	assert.Equal(t, 10, saved.SamplingPercentage)
+	assert.Equal(t, "agent1", saved.AgentID)
+	assert.Equal(t, 1024, saved.MaxSizeBytes)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *saved.ExpiresAt, time.Minute)
+
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionPayloadCaptureSaved && entry.After == saved
+	}))
+}
+
+func TestApiStopPayloadCaptureHandler(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	payloadCaptureService := new(services.MockPayloadCaptureService)
+	payloadCaptureService.On("GetSettings").Return(&models.PayloadCaptureSettings{AgentID: "agent1", MaxSizeBytes: 1024, ExpiresAt: &expiresAt}, nil)
+	payloadCaptureService.On("SaveSettings", &models.PayloadCaptureSettings{AgentID: "agent1", MaxSizeBytes: 1024}).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.payloadCaptureService = payloadCaptureService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/pipeline/capture", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	payloadCaptureService.AssertExpectations(t)
+}
+
+func TestApiGetCapturedPayloadHandler(t *testing.T) {
+	payloadCaptureService := new(services.MockPayloadCaptureService)
+	payloadCaptureService.On("GetByID", int64(7), "").Return(&models.CapturedPayload{ID: 7, AgentID: "agent1", Body: "not json"}, nil)
+	payloadCaptureService.On("GetByID", int64(8), "").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.payloadCaptureService = payloadCaptureService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/pipeline/captures/7", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	var payload models.CapturedPayload
+	err = json.Unmarshal(resp.Body.Bytes(), &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "not json", payload.Body)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/pipeline/captures/8", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiGetProjectionBacklogHandler(t *testing.T) {
+	app, err := NewAppWithDeps(setupTestConfig(), setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/pipeline/backlog", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"in_progress": false,
+		"total": 0,
+		"projected": 0,
+		"superseded": 0,
+		"started_at": null,
+		"finished_at": null
+	}`, resp.Body.String())
+}
+
+func TestApiListRejectedPayloadsHandler(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("GetRejectedPayloads", "agent1", "").Return([]*models.RejectedPayload{
+		{
+			ID:            3,
+			AgentID:       "agent1",
+			DiscoveryType: "host_discovery",
+			Issues:        []*models.PayloadValidationIssue{{Location: "", Message: "missing properties: 'hostname'"}},
+			Size:          2,
+			Payload:       "{}",
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/pipeline/rejected?agent_id=agent1", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	var payloads []*models.RejectedPayload
+	err = json.Unmarshal(resp.Body.Bytes(), &payloads)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Len(t, payloads, 1)
+	assert.Equal(t, "host_discovery", payloads[0].DiscoveryType)
+	assert.Equal(t, "missing properties: 'hostname'", payloads[0].Issues[0].Message)
+	collectorService.AssertExpectations(t)
+}
+
+type projectorsRegistryManagerStub struct {
+	projectors  []*models.RegisteredProjector
+	caughtUp    []string
+	deadLetters []*models.DeadLetter
+}
+
+func (s *projectorsRegistryManagerStub) List() ([]*models.RegisteredProjector, error) {
+	return s.projectors, nil
+}
+
+func (s *projectorsRegistryManagerStub) Disable(projectorID string, actor string) (*models.RegisteredProjector, error) {
+	for _, p := range s.projectors {
+		if p.ID == projectorID {
+			disabledAt := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+			p.Enabled, p.DisabledAt, p.DisabledBy = false, &disabledAt, actor
+			return p, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *projectorsRegistryManagerStub) Enable(projectorID string) (*models.RegisteredProjector, error) {
+	for _, p := range s.projectors {
+		if p.ID == projectorID {
+			p.Enabled, p.DisabledAt, p.DisabledBy = true, nil, ""
+			return p, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *projectorsRegistryManagerStub) CatchUp(_ context.Context, projectorID string) error {
+	s.caughtUp = append(s.caughtUp, projectorID)
+	return nil
+}
+
+func (s *projectorsRegistryManagerStub) ListDeadLetters(projectorID string, _ string) ([]*models.DeadLetter, error) {
+	deadLetters := []*models.DeadLetter{}
+	for _, d := range s.deadLetters {
+		if projectorID == "" || d.ProjectorID == projectorID {
+			deadLetters = append(deadLetters, d)
+		}
+	}
+
+	return deadLetters, nil
+}
+
+// RequeueDeadLetter projects the dead letters successfully
+func (s *projectorsRegistryManagerStub) RequeueDeadLetter(id int64) (*models.DeadLetterRequeue, error) {
+	for i, d := range s.deadLetters {
+		if d.ID == id {
+			s.deadLetters = append(s.deadLetters[:i], s.deadLetters[i+1:]...)
+			return &models.DeadLetterRequeue{Requeued: 1, Failed: []*models.DeadLetter{}}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *projectorsRegistryManagerStub) RequeueDeadLetters(projectorID string) (*models.DeadLetterRequeue, error) {
+	deadLetters, _ := s.ListDeadLetters(projectorID, "")
+	for _, d := range deadLetters {
+		s.RequeueDeadLetter(d.ID)
+	}
+
+	return &models.DeadLetterRequeue{Requeued: len(deadLetters), Failed: []*models.DeadLetter{}}, nil
+}
+
+func TestApiListProjectorsHandler(t *testing.T) {
+	projectorsManager := &projectorsRegistryManagerStub{projectors: []*models.RegisteredProjector{
+		{ID: "hosts", DiscoveryTypes: []string{"host_discovery"}, Enabled: true},
+	}}
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.GET("/pipeline/projectors", ApiListProjectorsHandler(projectorsManager))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/pipeline/projectors", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"id": "hosts",
+		"discovery_types": ["host_discovery"],
+		"enabled": true,
+		"disabled_at": null,
+		"pending_events": 0
+	}]`, resp.Body.String())
+}
+
+func TestApiDisableAndEnableProjectorHandlers(t *testing.T) {
+	projectorsManager := &projectorsRegistryManagerStub{projectors: []*models.RegisteredProjector{
+		{ID: "hosts", DiscoveryTypes: []string{"host_discovery"}, Enabled: true},
+	}}
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionProjectorDisabled && e.ResourceID == "hosts"
+	})).Return(nil).Once()
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionProjectorEnabled && e.ResourceID == "hosts"
+	})).Return(nil).Once()
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.POST("/pipeline/projectors/:id/disable", ApiDisableProjectorHandler(projectorsManager, auditService))
+	engine.POST("/pipeline/projectors/:id/enable", ApiEnableProjectorHandler(projectorsManager, auditService))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/pipeline/projectors/hosts/disable", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.False(t, projectorsManager.projectors[0].Enabled)
+	assert.Empty(t, projectorsManager.caughtUp)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/pipeline/projectors/hosts/enable", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.True(t, projectorsManager.projectors[0].Enabled)
+	assert.Equal(t, []string{"hosts"}, projectorsManager.caughtUp)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/pipeline/projectors/unknown/disable", nil)
+	req.Header.Set("Accept", "application/json")
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+	auditService.AssertExpectations(t)
+}
+
+func TestApiDeadLettersHandlers(t *testing.T) {
+	failedAt := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	projectorsManager := &projectorsRegistryManagerStub{deadLetters: []*models.DeadLetter{
+		{ID: 1, ProjectorID: "hosts", EventID: 10, AgentID: "agent1", DiscoveryType: "host_discovery", Error: "kaboom", Attempts: 1, FailedAt: failedAt},
+		{ID: 2, ProjectorID: "clusters", EventID: 11, AgentID: "agent1", DiscoveryType: "ha_cluster_discovery", Error: "kaboom", Attempts: 2, FailedAt: failedAt},
+		{ID: 3, ProjectorID: "clusters", EventID: 12, AgentID: "agent2", DiscoveryType: "ha_cluster_discovery", Error: "kaboom", Attempts: 1, FailedAt: failedAt},
+	}}
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionDeadLettersRequeued && e.ResourceType == models.AuditResourceDeadLetter
+	})).Return(nil).Twice()
+
+	engine := gin.New()
+	engine.Use(ErrorHandler)
+	engine.GET("/pipeline/dead-letters", ApiListDeadLettersHandler(projectorsManager))
+	engine.POST("/pipeline/dead-letters/requeue", ApiRequeueDeadLettersHandler(projectorsManager, auditService))
+	engine.POST("/pipeline/dead-letters/:id/requeue", ApiRequeueDeadLetterHandler(projectorsManager, auditService))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/pipeline/dead-letters?projector=hosts", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"id": 1,
+		"projector_id": "hosts",
+		"event_id": 10,
+		"agent_id": "agent1",
+		"discovery_type": "host_discovery",
+		"error": "kaboom",
+		"attempts": 1,
+		"failed_at": "2022-03-01T10:00:00Z"
+	}]`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/pipeline/dead-letters/1/requeue", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"requeued": 1, "failed": []}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/pipeline/dead-letters/1/requeue", nil)
+	req.Header.Set("Accept", "application/json")
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/pipeline/dead-letters/requeue?projector=clusters", nil)
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"requeued": 2, "failed": []}`, resp.Body.String())
+	assert.Empty(t, projectorsManager.deadLetters)
+	auditService.AssertExpectations(t)
+}
@@ -18,13 +18,14 @@ type Targets struct {
 
 // ApiGetPrometheusHttpSdTargets godoc
 // @Summary Get prometheus HTTP SD targets
+// @Description The hosts of the organization of the user only, if any
 // @Produce json
 // @Success 200 {object} TargetsList
 // @Error 500
 // @Router /prometheus/targets [get]
 func ApiGetPrometheusHttpSdTargets(s services.PrometheusService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		targetsList, err := s.GetHttpSDTargets()
+		targetsList, err := s.GetHttpSDTargets(userOrganization(c))
 		if err != nil {
 			c.Error(err)
 			return
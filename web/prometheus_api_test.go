@@ -27,7 +27,7 @@ func TestGetHttpSDTargets(t *testing.T) {
 		},
 	}
 	mockPrometheusService := new(services.MockPrometheusService)
-	mockPrometheusService.On("GetHttpSDTargets").Return(targets, nil)
+	mockPrometheusService.On("GetHttpSDTargets", "").Return(targets, nil)
 
 	deps := setupTestDependencies()
 	deps.prometheusService = mockPrometheusService
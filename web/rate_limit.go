@@ -0,0 +1,136 @@
+package web
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// rateLimitedClientTTL after which the clients not seen anymore are forgotten
+const rateLimitedClientTTL = 10 * time.Minute
+
+// RateLimitConfig sets the requests per second allowed to every client, and the bursts above it.
+// A rate of 0 disables the limit
+type RateLimitConfig struct {
+	CollectorRate  float64
+	CollectorBurst int
+	APIRate        float64
+	APIBurst       int
+}
+
+// RateLimiter keeps a token bucket for every client
+type RateLimiter struct {
+	limit     rate.Limit
+	burst     int
+	mutex     sync.Mutex
+	clients   map[string]*rateLimitedClient
+	lastSweep time.Time
+}
+
+type rateLimitedClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		limit:     rate.Limit(requestsPerSecond),
+		burst:     burst,
+		clients:   make(map[string]*rateLimitedClient),
+		lastSweep: time.Now(),
+	}
+}
+
+// Reserve takes a token from the bucket of the client.
+// It returns 0 if the request is allowed, otherwise the time to wait before retrying
+func (l *RateLimiter) Reserve(clientKey string) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	client, ok := l.clients[clientKey]
+	if !ok {
+		client = &rateLimitedClient{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[clientKey] = client
+	}
+	client.lastSeen = now
+
+	reservation := client.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+	}
+
+	return delay
+}
+
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitedClientTTL {
+		return
+	}
+
+	for key, client := range l.clients {
+		if now.Sub(client.lastSeen) >= rateLimitedClientTTL {
+			delete(l.clients, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimitMiddleware rejects with 429 the requests of the clients exceeding the limit,
+// telling them when to retry
+func RateLimitMiddleware(limiter *RateLimiter, clientKey func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := clientKey(c)
+
+		delay := limiter.Reserve(key)
+		if delay > 0 {
+			log.Warnf("Request %s %s from %s rejected, rate limit exceeded", c.Request.Method, c.Request.URL.Path, key)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			_ = c.Error(TooManyRequestsError("rate limit exceeded"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// collectorClientKey identifies an agent by its ID if authenticated with a JWT,
+// by the common name of its certificate if authenticated with mTLS, by the address of the peer otherwise.
+// The forwarded addresses are ignored, the clients could forge them to get a bucket per request
+func collectorClientKey(c *gin.Context) string {
+	if agentID, ok := c.Get(ContextAgentIDKey); ok {
+		return "agent:" + agentID.(string)
+	}
+
+	if c.Request.TLS != nil && len(c.Request.TLS.PeerCertificates) > 0 {
+		return "cn:" + c.Request.TLS.PeerCertificates[0].Subject.CommonName
+	}
+
+	return "ip:" + remoteAddress(c)
+}
+
+// apiClientKey identifies the logged in users and the API keys by their name, the other clients by the address of the peer
+func apiClientKey(c *gin.Context) string {
+	if value, ok := c.Get(ContextUserKey); ok {
+		if user, ok := value.(*models.User); ok {
+			return "user:" + user.Username
+		}
+	}
+
+	return "ip:" + remoteAddress(c)
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(1, 2)
+
+	assert.Equal(t, time.Duration(0), limiter.Reserve("client1"))
+	assert.Equal(t, time.Duration(0), limiter.Reserve("client1"))
+
+	delay := limiter.Reserve("client1")
+	assert.Greater(t, delay, time.Duration(0))
+	assert.LessOrEqual(t, delay, time.Second)
+
+	// every client has its own bucket
+	assert.Equal(t, time.Duration(0), limiter.Reserve("client2"))
+}
+
+func TestCollectorRateLimit(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	config := setupTestConfig()
+	config.RateLimitConfig = &RateLimitConfig{CollectorRate: 0.1, CollectorBurst: 2}
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte("{}"),
+	})
+
+	for i, expected := range []int{202, 202, 429} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+		req.Header.Set("Accept", "application/json")
+		// the forged addresses do not get a bucket of their own
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i))
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code)
+		if expected == 429 {
+			assert.Equal(t, "10", resp.Header().Get("Retry-After"))
+		}
+	}
+
+	// the heartbeats of the agent are limited as well
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/hosts/agent_id/heartbeat", nil)
+	req.Header.Set("Accept", "application/json")
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 429, resp.Code)
+}
+
+func TestApiRateLimit(t *testing.T) {
+	tagsService := new(services.MockTagsService)
+	tagsService.On("GetAll", "").Return([]string{"tag1"}, nil)
+
+	deps := setupTestDependencies()
+	deps.tagsService = tagsService
+
+	config := setupTestConfig()
+	config.RateLimitConfig = &RateLimitConfig{APIRate: 1, APIBurst: 1}
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []int{200, 429} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/tags", nil)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code)
+	}
+}
+
+func TestRateLimitClientKeys(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/tags", nil)
+	c.Request.RemoteAddr = "192.0.2.1:1234"
+	c.Request.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+	assert.Equal(t, "ip:192.0.2.1", apiClientKey(c))
+	assert.Equal(t, "ip:192.0.2.1", collectorClientKey(c))
+
+	c.Set(ContextUserKey, &models.User{Username: "admin"})
+	c.Set(ContextAgentIDKey, "agent1")
+
+	assert.Equal(t, "user:admin", apiClientKey(c))
+	assert.Equal(t, "agent:agent1", collectorClientKey(c))
+}
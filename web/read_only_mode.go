@@ -0,0 +1,131 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// readOnlyAllowedRoutes keep working in read-only mode, to log in and out, and to leave it
+var readOnlyAllowedRoutes = map[string]bool{
+	"POST /login":               true,
+	"POST /logout":              true,
+	"PUT /api/read-only":        true,
+	"DELETE /api/impersonation": true,
+}
+
+type JSONReadOnlyMode struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// ReadOnlyModeMiddleware forbids the changes, from the pages and the API, while the console is in read-only mode.
+// The collector is served by another engine, hence the data collection is not affected
+func ReadOnlyModeMiddleware(settingsService services.SettingsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isSafeMethod(c.Request.Method) || readOnlyAllowedRoutes[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		readOnlyMode, err := settingsService.GetReadOnlyMode()
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if !readOnlyMode.Enabled {
+			c.Next()
+			return
+		}
+
+		message := "the console is in read-only mode, the changes are not allowed"
+		if readOnlyMode.Reason != "" {
+			message += ": " + readOnlyMode.Reason
+		}
+
+		_ = c.Error(ForbiddenError(message))
+		c.Abort()
+	}
+}
+
+// ApiGetReadOnlyModeHandler godoc
+// @Summary Retrieve the read-only mode of the console
+// @Produce json
+// @Success 200 {object} models.ReadOnlyMode
+// @Failure 500 {object} map[string]string
+// @Router /read-only [get]
+func ApiGetReadOnlyModeHandler(settingsService services.SettingsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		readOnlyMode, err := settingsService.GetReadOnlyMode()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, readOnlyMode)
+	}
+}
+
+// ApiUpdateReadOnlyModeHandler godoc
+// @Summary Put the console in read-only mode, or take it out of it
+// @Description In read-only mode the changes are refused with 403 Forbidden, the data collection goes on
+// @Accept json
+// @Produce json
+// @Param Body body JSONReadOnlyMode true "The read-only mode"
+// @Success 200 {object} models.ReadOnlyMode
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /read-only [put]
+func ApiUpdateReadOnlyModeHandler(settingsService services.SettingsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONReadOnlyMode
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if err := validateText("reason", r.Reason, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		previous, err := settingsService.GetReadOnlyMode()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		readOnlyMode := &models.ReadOnlyMode{
+			Enabled: r.Enabled,
+			Reason:  r.Reason,
+		}
+
+		if r.Enabled {
+			readOnlyMode.Since, readOnlyMode.EnabledBy = previous.Since, previous.EnabledBy
+			if !previous.Enabled {
+				now := time.Now()
+				readOnlyMode.Since = &now
+				readOnlyMode.EnabledBy = requestActor(c)
+			}
+		}
+
+		err = settingsService.SaveReadOnlyMode(readOnlyMode)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionReadOnlyModeSaved, models.AuditResourceSettings, "read_only",
+			previous, readOnlyMode)
+
+		c.JSON(http.StatusOK, readOnlyMode)
+	}
+}
@@ -0,0 +1,156 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func newReadOnlySettingsService(readOnlyMode *models.ReadOnlyMode) *services.MockSettingsService {
+	settingsService := new(services.MockSettingsService)
+	settingsService.On("InitializeIdentifier").Return(uuid.MustParse("59fd8017-b7fd-477b-9ebe-b658c558f3e9"), nil)
+	settingsService.On("IsEulaAccepted").Return(true, nil).Maybe()
+	settingsService.On("GetMaintenanceMode").Return(&models.MaintenanceMode{}, nil).Maybe()
+	settingsService.On("GetLogSamplingRules").Return([]*models.LogSamplingRule{}, nil)
+	settingsService.On("GetReadOnlyMode").Return(readOnlyMode, nil)
+
+	return settingsService
+}
+
+func TestReadOnlyModeMiddleware(t *testing.T) {
+	settingsService := newReadOnlySettingsService(&models.ReadOnlyMode{Enabled: true, Reason: "Quarterly audit"})
+	settingsService.On("GetRunnerSettings").Return(&models.RunnerSettings{MaxConcurrentRuns: 4}, nil)
+
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/runner/settings", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("PUT", "/api/runner/settings", bytes.NewBufferString(`{"max_concurrent_runs": 10}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+	assert.Contains(t, resp.Body.String(), "the console is in read-only mode, the changes are not allowed: Quarterly audit")
+	settingsService.AssertNotCalled(t, "SaveRunnerSettings", mock.Anything)
+
+	// the data collection goes on
+	resp = httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {}}`)
+	req = httptest.NewRequest("POST", "/api/collect", body)
+	req.Header.Set("Accept", "application/json")
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 1)
+}
+
+func TestApiGetReadOnlyModeHandler(t *testing.T) {
+	since := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	deps := setupTestDependencies()
+	deps.settingsService = newReadOnlySettingsService(&models.ReadOnlyMode{
+		Enabled:   true,
+		Reason:    "Quarterly audit",
+		Since:     &since,
+		EnabledBy: "admin",
+	})
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/read-only", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"enabled": true,
+		"reason": "Quarterly audit",
+		"since": "2022-03-01T10:00:00Z",
+		"enabled_by": "admin"
+	}`, resp.Body.String())
+}
+
+func TestApiUpdateReadOnlyModeHandler(t *testing.T) {
+	settingsService := newReadOnlySettingsService(&models.ReadOnlyMode{})
+	settingsService.On("SaveReadOnlyMode", mock.MatchedBy(func(m *models.ReadOnlyMode) bool {
+		return m.Enabled && m.Reason == "Quarterly audit" && m.Since != nil && m.EnabledBy != ""
+	})).Return(nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionReadOnlyModeSaved && e.ResourceID == "read_only"
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"enabled": true, "reason": "Quarterly audit"}`)
+	req := httptest.NewRequest("PUT", "/api/read-only", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	settingsService.AssertExpectations(t)
+	auditService.AssertExpectations(t)
+}
+
+func TestApiUpdateReadOnlyModeHandler_Disable(t *testing.T) {
+	since := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	settingsService := newReadOnlySettingsService(&models.ReadOnlyMode{Enabled: true, Since: &since, EnabledBy: "admin"})
+	settingsService.On("SaveReadOnlyMode", &models.ReadOnlyMode{}).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the read-only mode can be left while enabled
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/api/read-only", bytes.NewBufferString(`{"enabled": false}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	settingsService.AssertExpectations(t)
+}
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxIdentifierLength bounds the IDs, names and tags received, stored in indexed columns
+const maxIdentifierLength = 255
+
+// validateText rejects the strings the database cannot store: not valid UTF-8 or containing NUL characters
+func validateText(field string, value string, maxLength int) error {
+	if !utf8.ValidString(value) || strings.ContainsRune(value, 0) {
+		return BadRequestError(fmt.Sprintf("%s must be valid UTF-8 text", field))
+	}
+
+	if maxLength > 0 && len(value) > maxLength {
+		return BadRequestError(fmt.Sprintf("%s must be at most %d bytes long", field, maxLength))
+	}
+
+	return nil
+}
+
+// validatePayload rejects the payloads of the collected events the projectors cannot handle.
+// They are JSON objects or arrays, null being decoded as an empty discovery which would delete the resources,
+// and cannot contain escaped NUL characters or invalid UTF-8, both rejected by jsonb
+func validatePayload(payload []byte) error {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return BadRequestError("payload must be a JSON object or array")
+	}
+
+	if !utf8.Valid(payload) || containsEscapedNUL(payload) {
+		return BadRequestError("payload must be valid UTF-8 text")
+	}
+
+	return nil
+}
+
+// containsEscapedNUL looks for a \u0000 escape sequence, not preceded by an escaped backslash
+func containsEscapedNUL(data []byte) bool {
+	for i := 0; i < len(data); i++ {
+		if data[i] != '\\' {
+			continue
+		}
+
+		if i+1 < len(data) && data[i+1] == 'u' && bytes.HasPrefix(data[i+2:], []byte("0000")) {
+			return true
+		}
+
+		// skips the escaped character, e.g. the second backslash of \\u0000
+		i++
+	}
+
+	return false
+}
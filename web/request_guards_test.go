@@ -0,0 +1,32 @@
+package web
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateText(t *testing.T) {
+	assert.NoError(t, validateText("tag", "production", maxIdentifierLength))
+	assert.NoError(t, validateText("description", strings.Repeat("a", 1024), 0))
+	assert.EqualError(t, validateText("tag", "prod\x00", maxIdentifierLength), "tag must be valid UTF-8 text")
+	assert.EqualError(t, validateText("tag", "prod\xff", maxIdentifierLength), "tag must be valid UTF-8 text")
+	assert.EqualError(t, validateText("tag", strings.Repeat("a", 256), maxIdentifierLength), "tag must be at most 255 bytes long")
+}
+
+func TestValidatePayload(t *testing.T) {
+	for payload, valid := range map[string]bool{
+		`{"hostname": "host1"}`:          true,
+		` [{"sid": "HA1"}]`:              true,
+		`{"hostname": "\\u0000"}`:        true,
+		`{"hostname": "\\\u0000"}`:       false,
+		`{"hostname": "\u0000"}`:         false,
+		`{"hostname": "` + "\xff" + `"}`: false,
+		`null`:                           false,
+		`"host1"`:                        false,
+		``:                               false,
+	} {
+		assert.Equal(t, valid, validatePayload([]byte(payload)) == nil, payload)
+	}
+}
@@ -0,0 +1,166 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type JSONResourceRestriction struct {
+	Role      string   `json:"role" binding:"omitempty,oneof=admin operator viewer"`
+	Usernames []string `json:"usernames"`
+}
+
+// RequireResourcePermission enforces the restriction of the permission on the resource of the request, if any.
+// It must be used after the role granting the permission is required
+func RequireResourcePermission(resourceRestrictionsService services.ResourceRestrictionsService, permission string, resourceType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		restriction, err := resourceRestrictionsService.Get(resourceType, id, permission)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if restriction == nil {
+			c.Next()
+			return
+		}
+
+		value, _ := c.Get(ContextUserKey)
+		user, ok := value.(*models.User)
+		if !ok || !restriction.Allows(user) {
+			_ = c.Error(ForbiddenError(fmt.Sprintf("the %s permission is restricted on %s %s", permission, resourceType, id)))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ApiListResourceRestrictionsHandler godoc
+// @Summary Retrieve the permissions restricted on specific resources
+// @Produce json
+// @Success 200 {array} models.ResourceRestriction
+// @Failure 500 {object} map[string]string
+// @Router /restrictions [get]
+func ApiListResourceRestrictionsHandler(resourceRestrictionsService services.ResourceRestrictionsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		restrictions, err := resourceRestrictionsService.GetAll()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, restrictions)
+	}
+}
+
+// ApiSaveResourceRestrictionHandler godoc
+// @Summary Restrict a permission on a resource to the users with the given role, or listed
+// @Description The tags:write permission can be restricted on hosts, clusters, sapsystems and databases,
+// @Description the checks:write one on clusters
+// @Accept json
+// @Produce json
+// @Param resource_type path string true "Resource type"
+// @Param id path string true "Resource id"
+// @Param permission path string true "Permission"
+// @Param Body body JSONResourceRestriction true "The users allowed"
+// @Success 200 {object} models.ResourceRestriction
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /restrictions/{resource_type}/{id}/{permission} [put]
+func ApiSaveResourceRestrictionHandler(resourceRestrictionsService services.ResourceRestrictionsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resourceType := c.Param("resource_type")
+		id := c.Param("id")
+		permission := c.Param("permission")
+
+		if !models.IsRestrictablePermission(permission, resourceType) {
+			_ = c.Error(BadRequestError(fmt.Sprintf("the %s permission cannot be restricted on %s", permission, resourceType)))
+			return
+		}
+
+		if err := validateText("id", id, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		var r JSONResourceRestriction
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if r.Role == "" && len(r.Usernames) == 0 {
+			_ = c.Error(BadRequestError("either a role or some usernames are required"))
+			return
+		}
+
+		previous, err := resourceRestrictionsService.Get(resourceType, id, permission)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		restriction, err := resourceRestrictionsService.Save(&models.ResourceRestriction{
+			ResourceType: resourceType,
+			ResourceID:   id,
+			Permission:   permission,
+			Role:         r.Role,
+			Usernames:    r.Usernames,
+		})
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionRestrictionSaved, models.AuditResourceRestriction,
+			resourceType+"/"+id+"/"+permission, previous, restriction)
+
+		c.JSON(http.StatusOK, restriction)
+	}
+}
+
+// ApiDeleteResourceRestrictionHandler godoc
+// @Summary Lift the restriction of a permission on a resource
+// @Produce json
+// @Param resource_type path string true "Resource type"
+// @Param id path string true "Resource id"
+// @Param permission path string true "Permission"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /restrictions/{resource_type}/{id}/{permission} [delete]
+func ApiDeleteResourceRestrictionHandler(resourceRestrictionsService services.ResourceRestrictionsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resourceType := c.Param("resource_type")
+		id := c.Param("id")
+		permission := c.Param("permission")
+
+		restriction, err := resourceRestrictionsService.Delete(resourceType, id, permission)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if restriction == nil {
+			_ = c.Error(NotFoundError("restriction not found"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionRestrictionDeleted, models.AuditResourceRestriction,
+			resourceType+"/"+id+"/"+permission, restriction, nil)
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
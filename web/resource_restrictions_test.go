@@ -0,0 +1,202 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestRequireResourcePermission(t *testing.T) {
+	restrictionsService := new(services.MockResourceRestrictionsService)
+	restrictionsService.On("Get", models.TagClusterResourceType, "cluster1", models.PermissionTagsWrite).Return(&models.ResourceRestriction{
+		ResourceType: models.TagClusterResourceType,
+		ResourceID:   "cluster1",
+		Permission:   models.PermissionTagsWrite,
+		Role:         models.UserRoleAdmin,
+		Usernames:    []string{"alice"},
+	}, nil)
+	restrictionsService.On("Get", models.TagClusterResourceType, "cluster2", models.PermissionTagsWrite).Return(nil, nil)
+
+	for _, tc := range []struct {
+		user      *models.User
+		clusterID string
+		expected  int
+	}{
+		{&models.User{Username: "bob", Role: models.UserRoleOperator}, "cluster1", 403},
+		{&models.User{Username: "alice", Role: models.UserRoleOperator}, "cluster1", 200},
+		{&models.User{Username: "carol", Role: models.UserRoleAdmin}, "cluster1", 200},
+		{&models.User{Username: "bob", Role: models.UserRoleOperator}, "cluster2", 200},
+	} {
+		engine := gin.New()
+		engine.Use(ErrorHandler)
+		engine.Use(func(c *gin.Context) { c.Set(ContextUserKey, tc.user) })
+		engine.POST("/clusters/:id/tags", RequireResourcePermission(restrictionsService, models.PermissionTagsWrite, models.TagClusterResourceType), func(c *gin.Context) {
+			c.Status(200)
+		})
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/clusters/"+tc.clusterID+"/tags", nil)
+		req.Header.Set("Accept", "application/json")
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, "%s on %s", tc.user.Username, tc.clusterID)
+	}
+}
+
+func TestResourceRestrictionsEnforced(t *testing.T) {
+	restrictionsService := new(services.MockResourceRestrictionsService)
+	restrictionsService.On("Get", mock.Anything, "cluster1", mock.Anything).Return(&models.ResourceRestriction{
+		Role: models.UserRoleAdmin,
+	}, nil)
+
+	tagsService := new(services.MockTagsService)
+	checksService := new(services.MockChecksService)
+
+	deps := setupTestDependencies()
+	deps.usersService = newMockedUsersService(models.UserRoleOperator)
+	deps.restrictionsService = restrictionsService
+	deps.tagsService = tagsService
+	deps.checksService = checksService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range []struct {
+		method string
+		url    string
+		body   string
+	}{
+		{"POST", "/api/clusters/cluster1/tags", `{"tag": "production"}`},
+		{"DELETE", "/api/clusters/cluster1/tags/production", ``},
+		{"POST", "/api/checks/cluster1/settings", `{"selected_checks": ["1.1.1"], "connection_settings": {}}`},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(r.method, r.url, bytes.NewBufferString(r.body))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 403, resp.Code, r.url)
+	}
+
+	tagsService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	tagsService.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	checksService.AssertNotCalled(t, "CreateSelectedChecks", mock.Anything, mock.Anything)
+}
+
+func TestApiResourceRestrictionsHandlers(t *testing.T) {
+	restriction := &models.ResourceRestriction{
+		ResourceType: models.TagClusterResourceType,
+		ResourceID:   "cluster1",
+		Permission:   models.PermissionChecksWrite,
+		Role:         models.UserRoleAdmin,
+		Usernames:    []string{"alice"},
+		UpdatedAt:    time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC),
+	}
+
+	restrictionsService := new(services.MockResourceRestrictionsService)
+	restrictionsService.On("GetAll").Return([]*models.ResourceRestriction{restriction}, nil)
+	restrictionsService.On("Get", models.TagClusterResourceType, "cluster1", models.PermissionChecksWrite).Return(nil, nil)
+	restrictionsService.On("Save", &models.ResourceRestriction{
+		ResourceType: models.TagClusterResourceType,
+		ResourceID:   "cluster1",
+		Permission:   models.PermissionChecksWrite,
+		Role:         models.UserRoleAdmin,
+		Usernames:    []string{"alice"},
+	}).Return(restriction, nil)
+	restrictionsService.On("Delete", models.TagClusterResourceType, "cluster1", models.PermissionChecksWrite).Return(restriction, nil)
+	restrictionsService.On("Delete", models.TagHostResourceType, "host1", models.PermissionTagsWrite).Return(nil, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.restrictionsService = restrictionsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/restrictions", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"resource_type": "clusters",
+		"resource_id": "cluster1",
+		"permission": "checks:write",
+		"role": "admin",
+		"usernames": ["alice"],
+		"updated_at": "2022-03-12T10:00:00Z"
+	}]`, resp.Body.String())
+
+	for _, tc := range []struct {
+		url      string
+		body     string
+		expected int
+	}{
+		{"/api/restrictions/clusters/cluster1/checks:write", `{"role": "admin", "usernames": ["alice"]}`, 200},
+		{"/api/restrictions/hosts/host1/checks:write", `{"role": "admin"}`, 400},
+		{"/api/restrictions/clusters/cluster1/users:write", `{"role": "admin"}`, 400},
+		{"/api/restrictions/clusters/cluster1/checks:write", `{"role": "root"}`, 400},
+		{"/api/restrictions/clusters/cluster1/checks:write", `{}`, 400},
+	} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("PUT", tc.url, bytes.NewBufferString(tc.body))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, tc.body)
+	}
+
+	for url, expected := range map[string]int{
+		"/api/restrictions/clusters/cluster1/checks:write": 204,
+		"/api/restrictions/hosts/host1/tags:write":         404,
+	} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("DELETE", url, nil)
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code, url)
+	}
+
+	auditService.AssertNumberOfCalls(t, "Record", 2)
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionRestrictionSaved && entry.ResourceID == "clusters/cluster1/checks:write"
+	}))
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionRestrictionDeleted && entry.ResourceID == "clusters/cluster1/checks:write"
+	}))
+}
+
+func TestApiResourceRestrictionsHandlersForbidden(t *testing.T) {
+	deps := setupTestDependencies()
+	deps.usersService = newMockedUsersService(models.UserRoleOperator)
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/restrictions", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// ApiGetRunnerSettingsHandler godoc
+// @Summary Retrieve the concurrency limits of the checks executions
+// @Produce json
+// @Success 200 {object} models.RunnerSettings
+// @Failure 500 {object} map[string]string
+// @Router /runner/settings [get]
+func ApiGetRunnerSettingsHandler(settingsService services.SettingsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runnerSettings, err := settingsService.GetRunnerSettings()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, runnerSettings)
+	}
+}
+
+// ApiUpdateRunnerSettingsHandler godoc
+// @Summary Update the concurrency limits of the checks executions, 0 means no limit
+// @Accept json
+// @Produce json
+// @Param Body body models.RunnerSettings true "Maximum number of executions, globally and per target host"
+// @Success 200 {object} models.RunnerSettings
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /runner/settings [put]
+func ApiUpdateRunnerSettingsHandler(settingsService services.SettingsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var runnerSettings models.RunnerSettings
+
+		err := c.BindJSON(&runnerSettings)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		previousSettings, err := settingsService.GetRunnerSettings()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		err = settingsService.SaveRunnerSettings(&runnerSettings)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionRunnerSettingsSaved, models.AuditResourceSettings, "runner",
+			previousSettings, &runnerSettings)
+
+		c.JSON(http.StatusOK, &runnerSettings)
+	}
+}
+
+// ApiGetRunsQueueHandler godoc
+// @Summary List the checks executions running or waiting for a runner slot
+// @Produce json
+// @Success 200 {object} []models.QueuedRun
+// @Failure 500 {object} map[string]string
+// @Router /runs/queue [get]
+func ApiGetRunsQueueHandler(runsQueueService services.RunsQueueService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runs, err := runsQueueService.GetAll(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, runs)
+	}
+}
+
+// ApiUpdateRunsQueueHandler godoc
+// @Summary Replace the queue of checks executions, reported by the runner on every change
+// @Accept json
+// @Param Body body []models.QueuedRun true "Running and queued checks executions"
+// @Success 204 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /runs/queue [put]
+func ApiUpdateRunsQueueHandler(runsQueueService services.RunsQueueService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var runs []*models.QueuedRun
+
+		err := c.BindJSON(&runs)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		err = runsQueueService.Replace(runs)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,193 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiGetRunnerSettingsHandler(t *testing.T) {
+	settingsService := newMockedSettingsService().(*services.MockSettingsService)
+	settingsService.On("GetRunnerSettings").Return(&models.RunnerSettings{
+		MaxConcurrentRuns: 4,
+		MaxRunsPerTarget:  1,
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/runner/settings", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"max_concurrent_runs": 4, "max_runs_per_target": 1}`, resp.Body.String())
+}
+
+func TestApiUpdateRunnerSettingsHandler(t *testing.T) {
+	settingsService := newMockedSettingsService().(*services.MockSettingsService)
+	settingsService.On("GetRunnerSettings").Return(&models.RunnerSettings{MaxConcurrentRuns: 5}, nil)
+	settingsService.On("SaveRunnerSettings", &models.RunnerSettings{
+		MaxConcurrentRuns: 10,
+		MaxRunsPerTarget:  0,
+	}).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"max_concurrent_runs": 10, "max_runs_per_target": 0}`)
+	req := httptest.NewRequest("PUT", "/api/runner/settings", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	settingsService.AssertCalled(t, "SaveRunnerSettings", &models.RunnerSettings{
+		MaxConcurrentRuns: 10,
+		MaxRunsPerTarget:  0,
+	})
+}
+
+func TestApiUpdateRunnerSettingsHandler_Invalid(t *testing.T) {
+	settingsService := newMockedSettingsService().(*services.MockSettingsService)
+
+	deps := setupTestDependencies()
+	deps.settingsService = settingsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"max_concurrent_runs": -1, "max_runs_per_target": 1}`)
+	req := httptest.NewRequest("PUT", "/api/runner/settings", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+	settingsService.AssertNotCalled(t, "SaveRunnerSettings", mock.Anything)
+}
+
+func TestApiGetRunsQueueHandler(t *testing.T) {
+	startedAt := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	runsQueueService := new(services.MockRunsQueueService)
+	runsQueueService.On("GetAll", "").Return([]*models.QueuedRun{
+		{
+			ClusterID: "cluster1",
+			Targets:   []string{"192.168.1.1", "192.168.1.2"},
+			State:     models.QueuedRunStateRunning,
+			QueuedAt:  startedAt,
+			StartedAt: &startedAt,
+		},
+		{
+			ClusterID: "cluster2",
+			Targets:   []string{"192.168.1.2"},
+			State:     models.QueuedRunStateQueued,
+			QueuedAt:  startedAt,
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.runsQueueService = runsQueueService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/runs/queue", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"cluster_id": "cluster1",
+		"targets": ["192.168.1.1", "192.168.1.2"],
+		"state": "running",
+		"queued_at": "2022-03-01T10:00:00Z",
+		"started_at": "2022-03-01T10:00:00Z"
+	}, {
+		"cluster_id": "cluster2",
+		"targets": ["192.168.1.2"],
+		"state": "queued",
+		"queued_at": "2022-03-01T10:00:00Z"
+	}]`, resp.Body.String())
+}
+
+func TestApiUpdateRunsQueueHandler(t *testing.T) {
+	runsQueueService := new(services.MockRunsQueueService)
+	runsQueueService.On("Replace", []*models.QueuedRun{
+		{
+			ClusterID: "cluster1",
+			Targets:   []string{"192.168.1.1"},
+			State:     models.QueuedRunStateQueued,
+			QueuedAt:  time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.runsQueueService = runsQueueService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`[{
+		"cluster_id": "cluster1",
+		"targets": ["192.168.1.1"],
+		"state": "queued",
+		"queued_at": "2022-03-01T10:00:00Z"
+	}]`)
+	req := httptest.NewRequest("PUT", "/api/runs/queue", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	runsQueueService.AssertExpectations(t)
+}
+
+func TestApiUpdateRunsQueueHandler_Invalid(t *testing.T) {
+	runsQueueService := new(services.MockRunsQueueService)
+
+	deps := setupTestDependencies()
+	deps.runsQueueService = runsQueueService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`[{"cluster_id": "cluster1", "state": "done"}]`)
+	req := httptest.NewRequest("PUT", "/api/runs/queue", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+	runsQueueService.AssertNotCalled(t, "Replace", mock.Anything)
+}
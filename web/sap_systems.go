@@ -9,13 +9,21 @@ import (
 	"github.com/trento-project/trento/web/services"
 )
 
-func NewSAPSystemListHandler(sapSystemsService services.SAPSystemsService) gin.HandlerFunc {
+func NewSAPSystemListHandler(sapSystemsService services.SAPSystemsService, favoritesService services.FavoritesService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		query := c.Request.URL.Query()
 
+		favorites, err := getFavorites(c, favoritesService, models.TagSAPSystemResourceType)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		tagsFilter := &services.SAPSystemFilter{
-			Tags: query["tags"],
-			SIDs: query["sids"],
+			Tags:         query["tags"],
+			SIDs:         query["sids"],
+			Pinned:       favorites,
+			Organization: userOrganization(c),
 		}
 
 		pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -37,6 +45,7 @@ func NewSAPSystemListHandler(sapSystemsService services.SAPSystemsService) gin.H
 			_ = c.Error(err)
 			return
 		}
+		markFavoriteSAPSystems(paginatedSapSystems, favorites)
 
 		sapSystems, err := sapSystemsService.GetAllApplications(tagsFilter, nil)
 		if err != nil {
@@ -69,13 +78,21 @@ func NewSAPSystemListHandler(sapSystemsService services.SAPSystemsService) gin.H
 	}
 }
 
-func NewHANADatabaseListHandler(sapSystemsService services.SAPSystemsService) gin.HandlerFunc {
+func NewHANADatabaseListHandler(sapSystemsService services.SAPSystemsService, favoritesService services.FavoritesService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		query := c.Request.URL.Query()
 
+		favorites, err := getFavorites(c, favoritesService, models.TagDatabaseResourceType)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		tagsFilter := &services.SAPSystemFilter{
-			Tags: query["tags"],
-			SIDs: query["sids"],
+			Tags:         query["tags"],
+			SIDs:         query["sids"],
+			Pinned:       favorites,
+			Organization: userOrganization(c),
 		}
 
 		pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -97,6 +114,7 @@ func NewHANADatabaseListHandler(sapSystemsService services.SAPSystemsService) gi
 			_ = c.Error(err)
 			return
 		}
+		markFavoriteSAPSystems(paginatedDatabases, favorites)
 
 		databases, err := sapSystemsService.GetAllDatabases(tagsFilter, nil)
 		if err != nil {
@@ -129,7 +147,11 @@ func NewHANADatabaseListHandler(sapSystemsService services.SAPSystemsService) gi
 	}
 }
 
-func NewSAPResourceHandler(hostsService services.HostsService, sapSystemsService services.SAPSystemsService) gin.HandlerFunc {
+func NewSAPResourceHandler(
+	hostsService services.HostsService,
+	sapSystemsService services.SAPSystemsService,
+	availabilityService services.AvailabilityService,
+) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 
@@ -150,11 +172,75 @@ func NewSAPResourceHandler(hostsService services.HostsService, sapSystemsService
 			return
 		}
 
+		availability, err := availabilityService.GetSAPSystemAvailability(id, models.AvailabilityWindows)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		c.HTML(http.StatusOK, "sap_system.html.tmpl", gin.H{
 			"SAPSystem":      sapSystem,
 			"Hosts":          hosts,
+			"Availability":   availability,
 			"HideSAPSystems": true,
 			"HideTags":       true,
 		})
 	}
 }
+
+// ApiDeleteSAPSystemHandler godoc
+// @Summary Remove a SAP system, with its instances, tags and favorites, leaving its hosts untouched
+// @Produce json
+// @Param id path string true "SAP system id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /sapsystems/{id} [delete]
+func ApiDeleteSAPSystemHandler(sapSystemsService services.SAPSystemsService, auditService services.AuditService) gin.HandlerFunc {
+	return apiDeleteSAPSystemHandler(sapSystemsService, auditService, models.SAPSystemTypeApplication, models.TagSAPSystemResourceType, "SAP system")
+}
+
+// ApiDeleteDatabaseHandler godoc
+// @Summary Remove a HANA database, with its instances, tags and favorites, leaving its hosts untouched
+// @Produce json
+// @Param id path string true "Database id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /databases/{id} [delete]
+func ApiDeleteDatabaseHandler(sapSystemsService services.SAPSystemsService, auditService services.AuditService) gin.HandlerFunc {
+	return apiDeleteSAPSystemHandler(sapSystemsService, auditService, models.SAPSystemTypeDatabase, models.TagDatabaseResourceType, "database")
+}
+
+func apiDeleteSAPSystemHandler(sapSystemsService services.SAPSystemsService, auditService services.AuditService, sapSystemType string, resourceType string, label string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		// the SAP systems are not deleted through the databases route and vice versa
+		sapSystem, err := sapSystemsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if sapSystem == nil || sapSystem.Type != sapSystemType {
+			_ = c.Error(NotFoundError("could not find " + label))
+			return
+		}
+
+		sapSystem, err = sapSystemsService.Delete(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if sapSystem == nil {
+			_ = c.Error(NotFoundError("could not find " + label))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionSAPSystemDeleted, resourceType, id, sapSystem, nil)
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
@@ -4,19 +4,36 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
 
 // ApiSAPSystemsHealthSummaryHandler godoc
 // @Summary Retrieve SAP Systems Health Summary
+// @Description With the at parameter, the health is the one the SAP systems had at the time.
+// @Description The SAP systems of the organization of the user only, if any
 // @Accept json
 // @Produce json
+// @Param at query string false "RFC 3339 timestamp"
 // @Success 200 {object} models.HealthSummary
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /sapsystems/health [get]
 func ApiSAPSystemsHealthSummaryHandler(healthSummaryService services.HealthSummaryService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		healthSummary, err := healthSummaryService.GetHealthSummary()
+		var healthSummary models.HealthSummary
+		var err error
+
+		if c.Query("at") == "" {
+			healthSummary, err = healthSummaryService.GetHealthSummary(userOrganization(c))
+		} else {
+			at, parseErr := parseAtQuery(c)
+			if parseErr != nil {
+				c.Error(parseErr)
+				return
+			}
+			healthSummary, err = healthSummaryService.GetHealthSummaryAt(userOrganization(c), at)
+		}
 		if err != nil {
 			c.Error(err)
 			return
@@ -174,6 +174,20 @@ func TestSAPResourceHandler(t *testing.T) {
 				StartPriority:  "0.5",
 			},
 		},
+		KubernetesWorkloads: []*models.KubernetesWorkload{
+			{
+				Namespace:      "sap-prd",
+				Pod:            "prd-di-0",
+				Node:           "node1",
+				SID:            "PRD",
+				InstanceNumber: "01",
+				Type:           models.SAPSystemTypeApplication,
+				Component:      "DIALOG",
+				Image:          "registry.example.com/sap/di:7.53",
+				Phase:          "Running",
+				HostID:         "node1_id",
+			},
+		},
 	}, nil)
 	hostsService.On("GetAllBySAPSystemID", "sap_system_id").Return(models.HostList{
 		{
@@ -218,8 +232,11 @@ func TestSAPResourceHandler(t *testing.T) {
 	assert.Contains(t, responseBody, "PRD")
 	// Layout
 	assert.Regexp(t, regexp.MustCompile("<tr><td>netweaver01</td><td>00</td><td>MESSAGESERVER\\|ENQUE</td><td>50013</td><td>50014</td><td>0.5</td><td><span.*primary.*>SAPControl-GREEN</span></td></tr>"), responseBody)
+	// Kubernetes workloads
+	assert.Contains(t, responseBody, "Kubernetes workloads")
+	assert.Regexp(t, regexp.MustCompile("<tr><td>sap-prd</td><td>prd-di-0</td><td>01</td><td>DIALOG</td><td><a href=/hosts/node1_id>node1</a></td><td>registry.example.com/sap/di:7.53</td><td><span.*primary.*>Running</span></td></tr>"), responseBody)
 	// Host
-	assert.Regexp(t, regexp.MustCompile("<tr><td>.*check_circle.*</td><td .*><a href=/hosts/netweaver01>netweaver01</a></td><td>192.168.10.10</td><td>azure</td><td><a href=/clusters/cluster_id>netweaver</a></td><td>v0</td></tr>"), responseBody)
+	assert.Regexp(t, regexp.MustCompile("<tr><td>.*check_circle.*</td><td .*><a href=/hosts/netweaver01>netweaver01</a></td><td>192.168.10.10</td><td>azure</td><td><a href=/clusters/cluster_id>netweaver</a></td><td class=tn-agent-version>v0</td></tr>"), responseBody)
 }
 
 func TestSAPResourceHandler404Error(t *testing.T) {
@@ -262,3 +279,78 @@ func minifyHtml(input string) string {
 	}
 	return minified
 }
+
+func TestApiDeleteSAPSystemHandlers(t *testing.T) {
+	sapSystem := &models.SAPSystem{ID: "sapsystem1", SID: "HA1", Type: models.SAPSystemTypeApplication}
+	database := &models.SAPSystem{ID: "database1", SID: "PRD", Type: models.SAPSystemTypeDatabase}
+
+	sapSystemsService := new(services.MockSAPSystemsService)
+	sapSystemsService.On("GetByID", "sapsystem1").Return(sapSystem, nil)
+	sapSystemsService.On("GetByID", "database1").Return(database, nil)
+	sapSystemsService.On("GetByID", "unknown").Return(nil, nil)
+	sapSystemsService.On("Delete", "sapsystem1").Return(sapSystem, nil)
+	sapSystemsService.On("Delete", "database1").Return(database, nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.sapSystemsService = sapSystemsService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		url      string
+		expected int
+	}{
+		{"/api/sapsystems/unknown", 404},
+		{"/api/sapsystems/database1", 404},
+		{"/api/databases/sapsystem1", 404},
+		{"/api/sapsystems/sapsystem1", 204},
+		{"/api/databases/database1", 204},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("DELETE", tc.url, nil)
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, tc.url)
+	}
+
+	sapSystemsService.AssertNumberOfCalls(t, "Delete", 2)
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionSAPSystemDeleted &&
+			entry.ResourceType == models.TagSAPSystemResourceType && entry.ResourceID == "sapsystem1"
+	}))
+	auditService.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionSAPSystemDeleted &&
+			entry.ResourceType == models.TagDatabaseResourceType && entry.ResourceID == "database1"
+	}))
+}
+
+func TestApiDeleteSAPSystemHandlerForbidden(t *testing.T) {
+	sapSystemsService := new(services.MockSAPSystemsService)
+
+	deps := setupTestDependencies()
+	deps.usersService = newMockedUsersService(models.UserRoleOperator)
+	deps.sapSystemsService = sapSystemsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/sapsystems/sapsystem1", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+	sapSystemsService.AssertNotCalled(t, "Delete", mock.Anything)
+}
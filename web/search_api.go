@@ -0,0 +1,56 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/services"
+)
+
+const (
+	searchDefaultLimit = 20
+	searchMaxLimit     = 100
+)
+
+// ApiSearchHandler godoc
+// @Summary Search the hosts, clusters, SAP systems and databases
+// @Description Matches the names, SIDs, IP addresses and tags starting with each of the words of the query, the best matches first.
+// @Description The resources of the organization of the user only, if any
+// @Produce json
+// @Param q query string true "Words to search"
+// @Param limit query int false "Maximum number of results, up to 100, 20 by default"
+// @Success 200 {array} models.SearchResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /search [get]
+func ApiSearchHandler(searchService services.SearchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			_ = c.Error(BadRequestError("q is required"))
+			return
+		}
+
+		if err := validateText("q", query, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(searchDefaultLimit)))
+		if err != nil || limit < 1 || limit > searchMaxLimit {
+			_ = c.Error(BadRequestError("limit must be a number between 1 and 100"))
+			return
+		}
+
+		results, err := searchService.Search(query, userOrganization(c), limit)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
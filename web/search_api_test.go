@@ -0,0 +1,42 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiSearchHandler(t *testing.T) {
+	searchService := new(services.MockSearchService)
+	searchService.On("Search", "hana 10.74", "", 5).Return([]*models.SearchResult{
+		{ResourceType: models.TagHostResourceType, ID: "agent_id", Name: "hana01", Rank: 0.6},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.searchService = searchService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/search?q=hana+10.74&limit=5", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{"resource_type": "hosts", "id": "agent_id", "name": "hana01", "rank": 0.6}]`, resp.Body.String())
+
+	for _, path := range []string{"/api/search", "/api/search?q=+", "/api/search?q=hana&limit=1000"} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code, path)
+	}
+}
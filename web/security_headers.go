@@ -0,0 +1,54 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultHSTSMaxAge is how long the browsers keep reaching the web service over HTTPS only
+const DefaultHSTSMaxAge = 365 * 24 * time.Hour
+
+// issueCollectorOrigin serves the feedback form embedded in the header
+const issueCollectorOrigin = "https://jira.suse.com"
+
+// DefaultContentSecurityPolicy allows the embedded frontend assets, the inline scripts and styles of the
+// templates, the issue collector and the Grafana panels embedded from the given URL, if any
+func DefaultContentSecurityPolicy(grafanaURL string) string {
+	frameSources := []string{"'self'", issueCollectorOrigin}
+	if parsed, err := url.Parse(grafanaURL); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		frameSources = append(frameSources, parsed.Scheme+"://"+parsed.Host)
+	}
+
+	return strings.Join([]string{
+		"default-src 'self'",
+		"script-src 'self' 'unsafe-inline' " + issueCollectorOrigin,
+		"style-src 'self' 'unsafe-inline' " + issueCollectorOrigin,
+		"img-src 'self' data: " + issueCollectorOrigin,
+		"font-src 'self' data:",
+		"frame-src " + strings.Join(frameSources, " "),
+		"frame-ancestors 'self'",
+		"object-src 'none'",
+		"base-uri 'self'",
+		"form-action 'self'",
+	}, "; ")
+}
+
+// SecurityHeadersMiddleware sets the headers restricting what the browsers do with the pages.
+// The HSTS header is left out with a max age of 0, the browsers ignoring it over plain HTTP anyway
+func SecurityHeadersMiddleware(contentSecurityPolicy string, hstsMaxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Security-Policy", contentSecurityPolicy)
+		c.Header("X-Frame-Options", "SAMEORIGIN")
+		c.Header("Referrer-Policy", "strict-origin-when-cross-origin")
+		c.Header("X-Content-Type-Options", "nosniff")
+		if hstsMaxAge > 0 {
+			c.Header("Strict-Transport-Security", fmt.Sprintf("max-age=%d; includeSubDomains", int64(hstsMaxAge.Seconds())))
+		}
+
+		c.Next()
+	}
+}
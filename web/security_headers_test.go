@@ -0,0 +1,60 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultContentSecurityPolicy(t *testing.T) {
+	assert.Contains(t, DefaultContentSecurityPolicy("http://grafana.example.com:3000/grafana"),
+		"frame-src 'self' https://jira.suse.com http://grafana.example.com:3000;")
+	assert.Contains(t, DefaultContentSecurityPolicy(""), "frame-src 'self' https://jira.suse.com;")
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	config := setupTestConfig()
+	config.HSTSMaxAge = 24 * time.Hour
+
+	app, err := NewAppWithDeps(config, setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/ping", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, DefaultContentSecurityPolicy(config.GrafanaConfig.BaseUrl()), resp.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "SAMEORIGIN", resp.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "strict-origin-when-cross-origin", resp.Header().Get("Referrer-Policy"))
+	assert.Equal(t, "nosniff", resp.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "max-age=86400; includeSubDomains", resp.Header().Get("Strict-Transport-Security"))
+
+	// the headers are set on the errors as well
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/not-found", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, "SAMEORIGIN", resp.Header().Get("X-Frame-Options"))
+}
+
+func TestSecurityHeadersMiddlewareCustomPolicy(t *testing.T) {
+	config := setupTestConfig()
+	config.ContentSecurityPolicy = "default-src 'self' https://assets.example.com"
+
+	app, err := NewAppWithDeps(config, setupTestDependencies())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/ping", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, "default-src 'self' https://assets.example.com", resp.Header().Get("Content-Security-Policy"))
+	assert.Empty(t, resp.Header().Get("Strict-Transport-Security"))
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=AddressConflictsService --inpackage --filename=address_conflicts_mock.go
+
+type AddressConflictsService interface {
+	Analyze() ([]*models.AddressConflict, error)
+	// GetAll returns the stored conflicts, the ones among the resources of the organization only if not empty
+	GetAll(organization string) ([]*models.AddressConflict, error)
+}
+
+type addressConflictsService struct {
+	db *gorm.DB
+}
+
+func NewAddressConflictsService(db *gorm.DB) *addressConflictsService {
+	return &addressConflictsService{db: db}
+}
+
+// addressClaims collects, per address, the distinct resources claiming it
+type addressClaims map[string]map[string]*models.AddressConflictOwner
+
+func (c addressClaims) claim(address string, owner *models.AddressConflictOwner) {
+	if _, ok := c[address]; !ok {
+		c[address] = make(map[string]*models.AddressConflictOwner)
+	}
+
+	c[address][owner.ResourceType+"/"+owner.ID] = owner
+}
+
+func (c addressClaims) conflicts(kind string) []*models.AddressConflict {
+	var conflicts []*models.AddressConflict
+
+	for address, owners := range c {
+		if len(owners) < 2 {
+			continue
+		}
+
+		conflict := &models.AddressConflict{
+			Kind:    kind,
+			Address: address,
+		}
+		for _, owner := range owners {
+			conflict.Owners = append(conflict.Owners, owner)
+		}
+		sort.Slice(conflict.Owners, func(i, j int) bool {
+			if conflict.Owners[i].ResourceType != conflict.Owners[j].ResourceType {
+				return conflict.Owners[i].ResourceType < conflict.Owners[j].ResourceType
+			}
+			return conflict.Owners[i].Name < conflict.Owners[j].Name
+		})
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	return conflicts
+}
+
+// Analyze looks for IP addresses and hostnames claimed by more than one host, cluster virtual IP
+// or SAP virtual hostname, and stores them replacing the previous findings.
+// A cluster virtual IP reported by the node currently holding it is not a conflict.
+func (s *addressConflictsService) Analyze() ([]*models.AddressConflict, error) {
+	var hosts []*entities.Host
+	err := s.db.Preload("SAPSystemInstances").Find(&hosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []*entities.Cluster
+	err = s.db.Find(&clusters).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ipClaims := make(addressClaims)
+	hostnameClaims := make(addressClaims)
+	clustersVirtualIPs := make(map[string][]string)
+
+	for _, c := range clusters {
+		virtualIPs, err := clusterVirtualIPs(c)
+		if err != nil {
+			return nil, err
+		}
+
+		clustersVirtualIPs[c.ID] = virtualIPs
+		for _, ip := range virtualIPs {
+			ipClaims.claim(ip, &models.AddressConflictOwner{
+				ResourceType: models.TagClusterResourceType,
+				ID:           c.ID,
+				Name:         c.Name,
+				Organization: c.Organization,
+			})
+		}
+	}
+
+	for _, h := range hosts {
+		hostOwner := &models.AddressConflictOwner{
+			ResourceType: models.TagHostResourceType,
+			ID:           h.AgentID,
+			Name:         h.Name,
+			Organization: h.Organization,
+		}
+
+		for _, ip := range h.IPAddresses {
+			if !isConflictingIP(ip) {
+				continue
+			}
+			ip = internal.NormalizeIPAddress(ip)
+			if h.ClusterID != "" && internal.Contains(clustersVirtualIPs[h.ClusterID], ip) {
+				continue
+			}
+
+			ipClaims.claim(ip, hostOwner)
+		}
+
+		hostname := strings.ToLower(h.Name)
+		if hostname != "" {
+			hostnameClaims.claim(hostname, hostOwner)
+		}
+
+		for _, i := range h.SAPSystemInstances {
+			virtualHostname := strings.ToLower(i.SAPHostname)
+			if virtualHostname == "" || virtualHostname == hostname {
+				continue
+			}
+
+			resourceType := models.TagSAPSystemResourceType
+			if i.Type == models.SAPSystemTypeDatabase {
+				resourceType = models.TagDatabaseResourceType
+			}
+
+			hostnameClaims.claim(virtualHostname, &models.AddressConflictOwner{
+				ResourceType: resourceType,
+				ID:           i.ID,
+				Name:         i.SID,
+				Organization: i.Organization,
+			})
+		}
+	}
+
+	conflicts := append(ipClaims.conflicts(models.AddressConflictIPAddress),
+		hostnameClaims.conflicts(models.AddressConflictHostname)...)
+	sortAddressConflicts(conflicts)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return storeAddressConflicts(tx, conflicts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+func (s *addressConflictsService) GetAll(organization string) ([]*models.AddressConflict, error) {
+	var conflictEntities []*entities.AddressConflict
+
+	err := s.db.Order("kind, address").Find(&conflictEntities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []*models.AddressConflict{}
+	for _, c := range conflictEntities {
+		conflict, err := c.ToModel()
+		if err != nil {
+			return nil, err
+		}
+
+		if organization != "" {
+			// the resources of the other organizations are neither shown nor conflicting
+			var owners []*models.AddressConflictOwner
+			for _, owner := range conflict.Owners {
+				if owner.Organization == organization {
+					owners = append(owners, owner)
+				}
+			}
+			if len(owners) < 2 {
+				continue
+			}
+			conflict.Owners = owners
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	return conflicts, nil
+}
+
+// storeAddressConflicts replaces the stored conflicts, keeping the detection time of the ones still present
+func storeAddressConflicts(tx *gorm.DB, conflicts []*models.AddressConflict) error {
+	var previous []*entities.AddressConflict
+	if err := tx.Find(&previous).Error; err != nil {
+		return err
+	}
+
+	detectedAt := make(map[string]*entities.AddressConflict)
+	for _, p := range previous {
+		detectedAt[p.Kind+"/"+p.Address] = p
+	}
+
+	if err := tx.Where("1 = 1").Delete(&entities.AddressConflict{}).Error; err != nil {
+		return err
+	}
+
+	now := timeNow()
+	for _, c := range conflicts {
+		c.DetectedAt = now
+		if p, ok := detectedAt[c.Kind+"/"+c.Address]; ok {
+			c.DetectedAt = p.DetectedAt
+		}
+
+		owners, err := json.Marshal(c.Owners)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(&entities.AddressConflict{
+			Kind:       c.Kind,
+			Address:    c.Address,
+			Owners:     owners,
+			DetectedAt: c.DetectedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func clusterVirtualIPs(cluster *entities.Cluster) ([]string, error) {
+	if len(cluster.Details) == 0 {
+		return nil, nil
+	}
+
+	var details entities.HANAClusterDetails
+	if err := json.Unmarshal(cluster.Details, &details); err != nil {
+		return nil, err
+	}
+
+	var virtualIPs []string
+	for _, n := range details.Nodes {
+		for _, ip := range n.VirtualIPs {
+			// the same IPv6 address can be written in different forms
+			if normalized := internal.NormalizeIPAddress(ip); normalized != "" {
+				ip = normalized
+			}
+			if !internal.Contains(virtualIPs, ip) {
+				virtualIPs = append(virtualIPs, ip)
+			}
+		}
+	}
+
+	return virtualIPs, nil
+}
+
+// isConflictingIP excludes the addresses which are expected to be repeated across hosts
+func isConflictingIP(address string) bool {
+	ip, _ := internal.ParseIPAddress(address)
+	if ip == nil {
+		return false
+	}
+
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsUnspecified()
+}
+
+func sortAddressConflicts(conflicts []*models.AddressConflict) {
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Kind != conflicts[j].Kind {
+			return conflicts[i].Kind < conflicts[j].Kind
+		}
+		return conflicts[i].Address < conflicts[j].Address
+	})
+}
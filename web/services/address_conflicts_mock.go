@@ -0,0 +1,59 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockAddressConflictsService is an autogenerated mock type for the AddressConflictsService type
+type MockAddressConflictsService struct {
+	mock.Mock
+}
+
+// Analyze provides a mock function with given fields:
+func (_m *MockAddressConflictsService) Analyze() ([]*models.AddressConflict, error) {
+	ret := _m.Called()
+
+	var r0 []*models.AddressConflict
+	if rf, ok := ret.Get(0).(func() []*models.AddressConflict); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AddressConflict)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: organization
+func (_m *MockAddressConflictsService) GetAll(organization string) ([]*models.AddressConflict, error) {
+	ret := _m.Called(organization)
+
+	var r0 []*models.AddressConflict
+	if rf, ok := ret.Get(0).(func(string) []*models.AddressConflict); ok {
+		r0 = rf(organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AddressConflict)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type AddressConflictsServiceTestSuite struct {
+	suite.Suite
+	db                      *gorm.DB
+	tx                      *gorm.DB
+	addressConflictsService *addressConflictsService
+}
+
+func TestAddressConflictsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AddressConflictsServiceTestSuite))
+}
+
+func (suite *AddressConflictsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{}, &entities.AddressConflict{})
+}
+
+func (suite *AddressConflictsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{}, &entities.AddressConflict{})
+}
+
+func (suite *AddressConflictsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.addressConflictsService = NewAddressConflictsService(suite.tx)
+
+	suite.tx.Create(&entities.Cluster{
+		ID:      "cluster1",
+		Name:    "hana_cluster",
+		Details: datatypes.JSON(`{"nodes":[{"name":"hana01","virtual_ips":["10.0.0.100"]},{"name":"hana02","virtual_ips":[]}]}`),
+	})
+	suite.tx.Create(&entities.Cluster{
+		ID:   "cluster2",
+		Name: "netweaver_cluster",
+	})
+	suite.tx.Create(&[]entities.Host{
+		{
+			AgentID:     "1",
+			Name:        "hana01",
+			ClusterID:   "cluster1",
+			IPAddresses: pq.StringArray{"127.0.0.1", "fe80::1", "10.0.0.1", "10.0.0.100"},
+		},
+		{
+			AgentID:     "2",
+			Name:        "hana02",
+			ClusterID:   "cluster1",
+			IPAddresses: pq.StringArray{"127.0.0.1", "fe80::1", "10.0.0.2"},
+		},
+		{
+			AgentID:     "3",
+			Name:        "app01",
+			IPAddresses: pq.StringArray{"10.0.0.100", "10.0.0.3"},
+		},
+		{
+			AgentID:     "4",
+			Name:        "HANA02",
+			IPAddresses: pq.StringArray{"10.0.0.3"},
+		},
+	})
+	suite.tx.Create(&[]entities.SAPSystemInstance{
+		{ID: "sys1", AgentID: "3", SID: "NWP", Type: models.SAPSystemTypeApplication, InstanceNumber: "00", SAPHostname: "sapnwp"},
+		{ID: "sys1", AgentID: "3", SID: "NWP", Type: models.SAPSystemTypeApplication, InstanceNumber: "01", SAPHostname: "app01"},
+		{ID: "sys2", AgentID: "4", SID: "NWQ", Type: models.SAPSystemTypeApplication, InstanceNumber: "00", SAPHostname: "SAPNWP"},
+	})
+}
+
+func (suite *AddressConflictsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *AddressConflictsServiceTestSuite) TestAddressConflictsService_Analyze() {
+	now := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+	defer func() { timeNow = time.Now }()
+
+	conflicts, err := suite.addressConflictsService.Analyze()
+	suite.NoError(err)
+
+	expected := []*models.AddressConflict{
+		{
+			Kind:    models.AddressConflictHostname,
+			Address: "hana02",
+			Owners: []*models.AddressConflictOwner{
+				{ResourceType: models.TagHostResourceType, ID: "4", Name: "HANA02"},
+				{ResourceType: models.TagHostResourceType, ID: "2", Name: "hana02"},
+			},
+			DetectedAt: now,
+		},
+		{
+			Kind:    models.AddressConflictHostname,
+			Address: "sapnwp",
+			Owners: []*models.AddressConflictOwner{
+				{ResourceType: models.TagSAPSystemResourceType, ID: "sys1", Name: "NWP"},
+				{ResourceType: models.TagSAPSystemResourceType, ID: "sys2", Name: "NWQ"},
+			},
+			DetectedAt: now,
+		},
+		{
+			Kind:    models.AddressConflictIPAddress,
+			Address: "10.0.0.100",
+			Owners: []*models.AddressConflictOwner{
+				{ResourceType: models.TagClusterResourceType, ID: "cluster1", Name: "hana_cluster"},
+				{ResourceType: models.TagHostResourceType, ID: "3", Name: "app01"},
+			},
+			DetectedAt: now,
+		},
+		{
+			Kind:    models.AddressConflictIPAddress,
+			Address: "10.0.0.3",
+			Owners: []*models.AddressConflictOwner{
+				{ResourceType: models.TagHostResourceType, ID: "4", Name: "HANA02"},
+				{ResourceType: models.TagHostResourceType, ID: "3", Name: "app01"},
+			},
+			DetectedAt: now,
+		},
+	}
+	suite.Equal(expected, conflicts)
+
+	stored, err := suite.addressConflictsService.GetAll("")
+	suite.NoError(err)
+	suite.Equal(len(expected), len(stored))
+	for i := range expected {
+		suite.Equal(expected[i].Kind, stored[i].Kind)
+		suite.Equal(expected[i].Address, stored[i].Address)
+		suite.Equal(expected[i].Owners, stored[i].Owners)
+		suite.True(now.Equal(stored[i].DetectedAt))
+	}
+}
+
+func (suite *AddressConflictsServiceTestSuite) TestAddressConflictsService_AnalyzeKeepsDetectionTime() {
+	firstDetection := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		return firstDetection
+	}
+	defer func() { timeNow = time.Now }()
+
+	_, err := suite.addressConflictsService.Analyze()
+	suite.NoError(err)
+
+	// Solve the IP conflict of app01 with HANA02
+	suite.tx.Model(&entities.Host{}).Where("agent_id", "4").Update("ip_addresses", pq.StringArray{"10.0.0.4"})
+
+	timeNow = func() time.Time {
+		return firstDetection.Add(time.Hour)
+	}
+
+	conflicts, err := suite.addressConflictsService.Analyze()
+	suite.NoError(err)
+	suite.Equal(3, len(conflicts))
+	for _, c := range conflicts {
+		suite.NotEqual("10.0.0.3", c.Address)
+		suite.True(firstDetection.Equal(c.DetectedAt))
+	}
+
+	stored, err := suite.addressConflictsService.GetAll("")
+	suite.NoError(err)
+	suite.Equal(3, len(stored))
+}
+
+func (suite *AddressConflictsServiceTestSuite) TestAddressConflictsService_GetAllOrganization() {
+	suite.tx.Model(&entities.Host{}).Where("agent_id IN ?", []string{"3", "4"}).Update("organization", "acme")
+	suite.tx.Model(&entities.SAPSystemInstance{}).Where("agent_id IN ?", []string{"3", "4"}).Update("organization", "acme")
+
+	_, err := suite.addressConflictsService.Analyze()
+	suite.NoError(err)
+
+	conflicts, err := suite.addressConflictsService.GetAll("acme")
+	suite.NoError(err)
+	suite.Len(conflicts, 2)
+	suite.Equal("sapnwp", conflicts[0].Address)
+	suite.Equal("10.0.0.3", conflicts[1].Address)
+	suite.Equal([]*models.AddressConflictOwner{
+		{ResourceType: models.TagHostResourceType, ID: "4", Name: "HANA02", Organization: "acme"},
+		{ResourceType: models.TagHostResourceType, ID: "3", Name: "app01", Organization: "acme"},
+	}, conflicts[1].Owners)
+
+	conflicts, err = suite.addressConflictsService.GetAll("other")
+	suite.NoError(err)
+	suite.Empty(conflicts)
+}
+
+func (suite *AddressConflictsServiceTestSuite) TestAddressConflictsService_GetAllEmpty() {
+	conflicts, err := suite.addressConflictsService.GetAll("")
+	suite.NoError(err)
+	suite.Equal([]*models.AddressConflict{}, conflicts)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"encoding/json"
+
+	"github.com/trento-project/trento/internal/delta"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+)
+
+//go:generate mockery --name=AgentConfigurationService --inpackage --filename=agent_configuration_mock.go
+
+// AgentConfigurationService generates the configuration the agents pull, from the agent settings
+// and the checks selected for the cluster of their host
+type AgentConfigurationService interface {
+	GetByAgentID(agentID string) (*models.AgentConfiguration, error)
+}
+
+type agentConfigurationService struct {
+	settingsService SettingsService
+	hostsService    HostsService
+	checksService   ChecksService
+}
+
+func NewAgentConfigurationService(settingsService SettingsService, hostsService HostsService, checksService ChecksService) *agentConfigurationService {
+	return &agentConfigurationService{
+		settingsService: settingsService,
+		hostsService:    hostsService,
+		checksService:   checksService,
+	}
+}
+
+// GetByAgentID returns the configuration of the agent, without checks if its host is not discovered yet
+func (s *agentConfigurationService) GetByAgentID(agentID string) (*models.AgentConfiguration, error) {
+	agentSettings, err := s.settingsService.GetAgentSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	disabled := make(map[string]bool)
+	for _, discoveryType := range agentSettings.DisabledDiscoveries {
+		disabled[discoveryType] = true
+	}
+
+	configuration := &models.AgentConfiguration{
+		AgentID:            agentID,
+		DiscoveryPeriods:   map[string]int{},
+		EnabledDiscoveries: []string{},
+		SelectedChecks:     []string{},
+	}
+
+	for _, discoveryType := range datapipeline.DiscoveryTypes {
+		if disabled[discoveryType] {
+			continue
+		}
+
+		configuration.EnabledDiscoveries = append(configuration.EnabledDiscoveries, discoveryType)
+		if period, ok := agentSettings.DiscoveryPeriods[discoveryType]; ok {
+			configuration.DiscoveryPeriods[discoveryType] = period
+		}
+	}
+
+	host, err := s.hostsService.GetByID(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if host != nil && host.ClusterID != "" {
+		selectedChecks, err := s.checksService.GetSelectedChecksById(host.ClusterID)
+		if err != nil {
+			return nil, err
+		}
+
+		configuration.ClusterID = host.ClusterID
+		configuration.SelectedChecks = append(configuration.SelectedChecks, selectedChecks.SelectedChecks...)
+	}
+
+	encoded, err := json.Marshal(configuration)
+	if err != nil {
+		return nil, err
+	}
+	configuration.Revision = delta.Hash(encoded)
+
+	return configuration, nil
+}
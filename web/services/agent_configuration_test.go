@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/web/models"
+)
+
+func TestAgentConfigurationService_GetByAgentID(t *testing.T) {
+	settingsService := new(MockSettingsService)
+	hostsService := new(MockHostsService)
+	checksService := new(MockChecksService)
+
+	settingsService.On("GetAgentSettings").Return(&models.AgentSettings{
+		DiscoveryPeriods:    map[string]int{"ha_cluster_discovery": 30, "kubernetes_discovery": 120},
+		DisabledDiscoveries: []string{"kubernetes_discovery"},
+	}, nil)
+	hostsService.On("GetByID", "agent1").Return(&models.Host{ID: "agent1", ClusterID: "cluster1"}, nil)
+	hostsService.On("GetByID", "agent2").Return(nil, nil)
+	checksService.On("GetSelectedChecksById", "cluster1").Return(models.SelectedChecks{
+		ID:             "cluster1",
+		SelectedChecks: []string{"ABCDEF", "123456"},
+	}, nil)
+
+	service := NewAgentConfigurationService(settingsService, hostsService, checksService)
+
+	configuration, err := service.GetByAgentID("agent1")
+	assert.NoError(t, err)
+	assert.Equal(t, "agent1", configuration.AgentID)
+	assert.Equal(t, map[string]int{"ha_cluster_discovery": 30}, configuration.DiscoveryPeriods)
+	assert.Equal(t, []string{
+		"host_discovery",
+		"cloud_discovery",
+		"ha_cluster_discovery",
+		"sap_system_discovery",
+		"subscription_discovery",
+	}, configuration.EnabledDiscoveries)
+	assert.Equal(t, "cluster1", configuration.ClusterID)
+	assert.Equal(t, []string{"ABCDEF", "123456"}, configuration.SelectedChecks)
+	assert.NotEmpty(t, configuration.Revision)
+
+	// not discovered yet
+	other, err := service.GetByAgentID("agent2")
+	assert.NoError(t, err)
+	assert.Empty(t, other.ClusterID)
+	assert.Empty(t, other.SelectedChecks)
+	assert.NotEqual(t, configuration.Revision, other.Revision)
+
+	again, err := service.GetByAgentID("agent1")
+	assert.NoError(t, err)
+	assert.Equal(t, configuration.Revision, again.Revision)
+}
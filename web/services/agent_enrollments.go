@@ -0,0 +1,90 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/entities"
+)
+
+// ErrAgentEnrolled is returned enrolling an agent ID again without the secret issued at its first enrollment
+var ErrAgentEnrolled = errors.New("the agent is enrolled already, with another secret")
+
+const enrollmentSecretSize = 32
+
+//go:generate mockery --name=AgentEnrollmentsService --inpackage --filename=agent_enrollments_mock.go
+
+// AgentEnrollmentsService binds the agent IDs to the secret issued at their first enrollment,
+// so that the enrollment token shared by the agents does not grant the JWT of any agent
+type AgentEnrollmentsService interface {
+	// Enroll returns the secret issued to the agent, only at its first enrollment, empty afterwards.
+	// The next enrollments of the agent ID are refused unless they present it
+	Enroll(agentID string, secret string) (string, error)
+	// Reset forgets the secret of the agent, a new one is issued at its next enrollment.
+	// It returns false if the agent is not enrolled
+	Reset(agentID string) (bool, error)
+}
+
+type agentEnrollmentsService struct {
+	db *gorm.DB
+}
+
+func NewAgentEnrollmentsService(db *gorm.DB) *agentEnrollmentsService {
+	return &agentEnrollmentsService{db: db}
+}
+
+func (s *agentEnrollmentsService) Enroll(agentID string, secret string) (string, error) {
+	var enrollments []entities.AgentEnrollment
+	err := s.db.Where("agent_id = ?", agentID).Limit(1).Find(&enrollments).Error
+	if err != nil {
+		return "", err
+	}
+
+	if len(enrollments) > 0 {
+		if subtle.ConstantTimeCompare([]byte(hashEnrollmentSecret(secret)), []byte(enrollments[0].SecretHash)) != 1 {
+			return "", ErrAgentEnrolled
+		}
+		return "", nil
+	}
+
+	random := make([]byte, enrollmentSecretSize)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	issued := base64.RawURLEncoding.EncodeToString(random)
+
+	enrollment := entities.AgentEnrollment{AgentID: agentID, SecretHash: hashEnrollmentSecret(issued), CreatedAt: time.Now()}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&enrollment)
+	if result.Error != nil {
+		return "", result.Error
+	}
+
+	// enrolled concurrently by another request
+	if result.RowsAffected == 0 {
+		return "", ErrAgentEnrolled
+	}
+
+	return issued, nil
+}
+
+func (s *agentEnrollmentsService) Reset(agentID string) (bool, error) {
+	result := s.db.Where("agent_id = ?", agentID).Delete(&entities.AgentEnrollment{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func hashEnrollmentSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
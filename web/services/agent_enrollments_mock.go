@@ -0,0 +1,52 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockAgentEnrollmentsService is an autogenerated mock type for the AgentEnrollmentsService type
+type MockAgentEnrollmentsService struct {
+	mock.Mock
+}
+
+// Enroll provides a mock function with given fields: agentID, secret
+func (_m *MockAgentEnrollmentsService) Enroll(agentID string, secret string) (string, error) {
+	ret := _m.Called(agentID, secret)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(agentID, secret)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(agentID, secret)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reset provides a mock function with given fields: agentID
+func (_m *MockAgentEnrollmentsService) Reset(agentID string) (bool, error) {
+	ret := _m.Called(agentID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(agentID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+type AgentEnrollmentsServiceTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestAgentEnrollmentsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AgentEnrollmentsServiceTestSuite))
+}
+
+func (suite *AgentEnrollmentsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.AgentEnrollment{})
+}
+
+func (suite *AgentEnrollmentsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.AgentEnrollment{})
+}
+
+func (suite *AgentEnrollmentsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+}
+
+func (suite *AgentEnrollmentsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *AgentEnrollmentsServiceTestSuite) TestEnroll() {
+	agentEnrollmentsService := NewAgentEnrollmentsService(suite.tx)
+
+	secret, err := agentEnrollmentsService.Enroll("agent1", "")
+	suite.NoError(err)
+	suite.NotEmpty(secret)
+
+	var stored entities.AgentEnrollment
+	suite.tx.First(&stored, "agent_id = ?", "agent1")
+	suite.NotEqual(secret, stored.SecretHash)
+
+	// the secret is only issued once
+	renewed, err := agentEnrollmentsService.Enroll("agent1", secret)
+	suite.NoError(err)
+	suite.Empty(renewed)
+
+	_, err = agentEnrollmentsService.Enroll("agent1", "")
+	suite.Equal(ErrAgentEnrolled, err)
+	_, err = agentEnrollmentsService.Enroll("agent1", "another-secret")
+	suite.Equal(ErrAgentEnrolled, err)
+
+	other, err := agentEnrollmentsService.Enroll("agent2", "")
+	suite.NoError(err)
+	suite.NotEqual(secret, other)
+}
+
+func (suite *AgentEnrollmentsServiceTestSuite) TestReset() {
+	agentEnrollmentsService := NewAgentEnrollmentsService(suite.tx)
+
+	secret, _ := agentEnrollmentsService.Enroll("agent1", "")
+
+	reset, err := agentEnrollmentsService.Reset("agent1")
+	suite.NoError(err)
+	suite.True(reset)
+
+	reset, err = agentEnrollmentsService.Reset("agent1")
+	suite.NoError(err)
+	suite.False(reset)
+
+	issued, err := agentEnrollmentsService.Enroll("agent1", "")
+	suite.NoError(err)
+	suite.NotEmpty(issued)
+	suite.NotEqual(secret, issued)
+}
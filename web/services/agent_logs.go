@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	// MaxAgentLogsSizeBytes caps the size of the logs shipped by an agent, the oldest lines are dropped first
+	MaxAgentLogsSizeBytes = 256 << 10
+	// agentLogsRequestTimeout after which the agents no longer ship the logs requested
+	agentLogsRequestTimeout = 10 * time.Minute
+	// agentLogsRetention after which the requested logs are dropped
+	agentLogsRetention = 24 * time.Hour
+)
+
+//go:generate mockery --name=AgentLogsService --inpackage --filename=agent_logs_mock.go
+
+// AgentLogsService requests the recent logs of the agents from the console, the agents ship them
+// once they are told so in the response to their heartbeat. Only the latest logs of every agent are kept
+type AgentLogsService interface {
+	// Request asks the agent to ship its logs, replacing the ones shipped before
+	Request(agentID string, actor string) (*models.AgentLogs, error)
+	// IsRequested tells whether the agent is expected to ship its logs
+	IsRequested(agentID string) (bool, error)
+	// Store the logs shipped by the agent, returning false if they were not requested
+	Store(agentID string, logs []byte) (bool, error)
+	// Get returns the latest logs requested to the agent, nil if none or expired
+	Get(agentID string) (*models.AgentLogs, error)
+}
+
+type agentLogsService struct {
+	db *gorm.DB
+}
+
+func NewAgentLogsService(db *gorm.DB) *agentLogsService {
+	return &agentLogsService{db: db}
+}
+
+func (s *agentLogsService) Request(agentID string, actor string) (*models.AgentLogs, error) {
+	now := timeNow()
+	logs := &entities.AgentLogs{
+		AgentID:     agentID,
+		RequestedAt: now,
+		RequestedBy: actor,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "agent_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"requested_at", "requested_by", "received_at", "truncated", "logs"}),
+		}).Create(logs).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("requested_at < ?", now.Add(-agentLogsRetention)).Delete(&entities.AgentLogs{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return logs.ToModel(), nil
+}
+
+func (s *agentLogsService) IsRequested(agentID string) (bool, error) {
+	var count int64
+	err := s.pending(agentID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *agentLogsService) Store(agentID string, logs []byte) (bool, error) {
+	truncated := false
+	if len(logs) > MaxAgentLogsSizeBytes {
+		logs = logs[len(logs)-MaxAgentLogsSizeBytes:]
+		truncated = true
+	}
+
+	result := s.pending(agentID).Updates(map[string]interface{}{
+		"received_at": timeNow(),
+		"truncated":   truncated,
+		"logs":        logs,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (s *agentLogsService) Get(agentID string) (*models.AgentLogs, error) {
+	var logs entities.AgentLogs
+
+	now := timeNow()
+	err := s.db.Where("agent_id = ? AND requested_at >= ?", agentID, now.Add(-agentLogsRetention)).
+		First(&logs).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the agent did not ship its logs in time, it is not going to
+	if logs.ReceivedAt == nil && logs.RequestedAt.Before(now.Add(-agentLogsRequestTimeout)) {
+		return nil, nil
+	}
+
+	return logs.ToModel(), nil
+}
+
+// pending are the logs requested to the agent and not shipped yet
+func (s *agentLogsService) pending(agentID string) *gorm.DB {
+	return s.db.Model(&entities.AgentLogs{}).
+		Where("agent_id = ? AND received_at IS NULL AND requested_at >= ?", agentID, timeNow().Add(-agentLogsRequestTimeout))
+}
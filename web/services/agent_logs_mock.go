@@ -0,0 +1,101 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockAgentLogsService is an autogenerated mock type for the AgentLogsService type
+type MockAgentLogsService struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: agentID
+func (_m *MockAgentLogsService) Get(agentID string) (*models.AgentLogs, error) {
+	ret := _m.Called(agentID)
+
+	var r0 *models.AgentLogs
+	if rf, ok := ret.Get(0).(func(string) *models.AgentLogs); ok {
+		r0 = rf(agentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AgentLogs)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsRequested provides a mock function with given fields: agentID
+func (_m *MockAgentLogsService) IsRequested(agentID string) (bool, error) {
+	ret := _m.Called(agentID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(agentID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Request provides a mock function with given fields: agentID, actor
+func (_m *MockAgentLogsService) Request(agentID string, actor string) (*models.AgentLogs, error) {
+	ret := _m.Called(agentID, actor)
+
+	var r0 *models.AgentLogs
+	if rf, ok := ret.Get(0).(func(string, string) *models.AgentLogs); ok {
+		r0 = rf(agentID, actor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AgentLogs)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(agentID, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: agentID, logs
+func (_m *MockAgentLogsService) Store(agentID string, logs []byte) (bool, error) {
+	ret := _m.Called(agentID, logs)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, []byte) bool); ok {
+		r0 = rf(agentID, logs)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte) error); ok {
+		r1 = rf(agentID, logs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
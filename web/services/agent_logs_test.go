@@ -0,0 +1,133 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+type AgentLogsServiceTestSuite struct {
+	suite.Suite
+	db               *gorm.DB
+	tx               *gorm.DB
+	agentLogsService *agentLogsService
+	now              time.Time
+}
+
+func TestAgentLogsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AgentLogsServiceTestSuite))
+}
+
+func (suite *AgentLogsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.AgentLogs{})
+}
+
+func (suite *AgentLogsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.AgentLogs{})
+}
+
+func (suite *AgentLogsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.agentLogsService = NewAgentLogsService(suite.tx)
+
+	suite.now = time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return suite.now }
+}
+
+func (suite *AgentLogsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *AgentLogsServiceTestSuite) TestAgentLogsService_RequestAndStore() {
+	stored, err := suite.agentLogsService.Store("agent1", []byte("not requested"))
+	suite.NoError(err)
+	suite.False(stored)
+
+	requested, err := suite.agentLogsService.Request("agent1", "admin")
+	suite.NoError(err)
+	suite.True(requested.IsPending())
+	suite.Equal("admin", requested.RequestedBy)
+
+	isRequested, err := suite.agentLogsService.IsRequested("agent1")
+	suite.NoError(err)
+	suite.True(isRequested)
+	isRequested, err = suite.agentLogsService.IsRequested("agent2")
+	suite.NoError(err)
+	suite.False(isRequested)
+
+	stored, err = suite.agentLogsService.Store("agent1", []byte("line1\nline2\n"))
+	suite.NoError(err)
+	suite.True(stored)
+
+	isRequested, err = suite.agentLogsService.IsRequested("agent1")
+	suite.NoError(err)
+	suite.False(isRequested)
+
+	logs, err := suite.agentLogsService.Get("agent1")
+	suite.NoError(err)
+	suite.False(logs.IsPending())
+	suite.Equal("line1\nline2\n", logs.Logs)
+	suite.False(logs.Truncated)
+
+	// a new request replaces the logs shipped before
+	requested, err = suite.agentLogsService.Request("agent1", "other")
+	suite.NoError(err)
+	logs, err = suite.agentLogsService.Get("agent1")
+	suite.NoError(err)
+	suite.Equal(requested, logs)
+	suite.Empty(logs.Logs)
+}
+
+func (suite *AgentLogsServiceTestSuite) TestAgentLogsService_Truncated() {
+	_, err := suite.agentLogsService.Request("agent1", "admin")
+	suite.NoError(err)
+
+	stored, err := suite.agentLogsService.Store("agent1", []byte("first\n"+strings.Repeat("x", MaxAgentLogsSizeBytes)))
+	suite.NoError(err)
+	suite.True(stored)
+
+	logs, err := suite.agentLogsService.Get("agent1")
+	suite.NoError(err)
+	suite.True(logs.Truncated)
+	suite.Len(logs.Logs, MaxAgentLogsSizeBytes)
+	suite.NotContains(logs.Logs, "first")
+}
+
+func (suite *AgentLogsServiceTestSuite) TestAgentLogsService_Expired() {
+	_, err := suite.agentLogsService.Request("agent1", "admin")
+	suite.NoError(err)
+
+	suite.now = suite.now.Add(agentLogsRequestTimeout + time.Second)
+
+	isRequested, err := suite.agentLogsService.IsRequested("agent1")
+	suite.NoError(err)
+	suite.False(isRequested)
+
+	stored, err := suite.agentLogsService.Store("agent1", []byte("late"))
+	suite.NoError(err)
+	suite.False(stored)
+
+	logs, err := suite.agentLogsService.Get("agent1")
+	suite.NoError(err)
+	suite.Nil(logs)
+
+	_, err = suite.agentLogsService.Request("agent2", "admin")
+	suite.NoError(err)
+	suite.NoError(suite.tx.Model(&entities.AgentLogs{}).Where("agent_id = ?", "agent2").Update("received_at", suite.now).Error)
+
+	suite.now = suite.now.Add(agentLogsRetention + time.Second)
+	_, err = suite.agentLogsService.Request("agent3", "admin")
+	suite.NoError(err)
+
+	var count int64
+	suite.tx.Model(&entities.AgentLogs{}).Count(&count)
+	suite.Equal(int64(1), count)
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+//go:generate mockery --name=AgentUpgradesService --inpackage --filename=agent_upgrades_mock.go
+
+// AgentUpgradesService records the releases of the agent and tracks the upgrades of the agents to them.
+// The upgrades are delivered over the channel of the agents, as soon as they are connected
+type AgentUpgradesService interface {
+	// GetVersions returns the releases of the agent, the latest recorded first
+	GetVersions() ([]*models.AgentVersion, error)
+	// GetVersion returns nil if the release was not recorded
+	GetVersion(version string) (*models.AgentVersion, error)
+	// SaveVersion records a release of the agent, replacing the one with the same version
+	SaveVersion(version *models.AgentVersion) error
+	// Request marks the agents for the upgrade to the given version, replacing their previous upgrade
+	Request(agentIDs []string, version string, actor string) ([]*models.AgentUpgrade, error)
+	// GetAll returns the latest upgrade of every agent
+	GetAll() ([]*models.AgentUpgrade, error)
+	// GetPending returns the upgrade yet to be delivered to the agent, nil if none
+	GetPending(agentID string) (*models.AgentUpgrade, error)
+	// MarkSent records the message the pending upgrade is delivered with, for the agent to acknowledge it
+	MarkSent(agentID string, messageID string) error
+	// MarkPending delivers the upgrade again on the next connection of the agent, the message not being sent
+	MarkPending(agentID string, messageID string) error
+	// Acknowledge records the outcome the agent reported for the upgrade message, the other messages being ignored
+	Acknowledge(agentID string, messageID string, errorMessage string) error
+	// Complete marks the upgrade of the agent done once it registers with the version requested
+	Complete(agentID string, version string) error
+}
+
+type agentUpgradesService struct {
+	db *gorm.DB
+}
+
+func NewAgentUpgradesService(db *gorm.DB) *agentUpgradesService {
+	return &agentUpgradesService{db: db}
+}
+
+func (s *agentUpgradesService) GetVersions() ([]*models.AgentVersion, error) {
+	var versions []*entities.AgentVersion
+	if err := s.db.Order("created_at DESC, version").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	result := []*models.AgentVersion{}
+	for _, v := range versions {
+		result = append(result, v.ToModel())
+	}
+
+	return result, nil
+}
+
+func (s *agentUpgradesService) GetVersion(version string) (*models.AgentVersion, error) {
+	var agentVersion entities.AgentVersion
+	err := s.db.Where("version = ?", version).First(&agentVersion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return agentVersion.ToModel(), nil
+}
+
+func (s *agentUpgradesService) SaveVersion(version *models.AgentVersion) error {
+	agentVersion := &entities.AgentVersion{
+		Version:   version.Version,
+		URL:       version.URL,
+		Checksum:  version.Checksum,
+		CreatedAt: timeNow(),
+		CreatedBy: version.CreatedBy,
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(agentVersion).Error; err != nil {
+		return err
+	}
+	version.CreatedAt = agentVersion.CreatedAt
+
+	return nil
+}
+
+func (s *agentUpgradesService) Request(agentIDs []string, version string, actor string) ([]*models.AgentUpgrade, error) {
+	now := timeNow()
+	upgrades := []*entities.AgentUpgrade{}
+	for _, agentID := range agentIDs {
+		upgrades = append(upgrades, &entities.AgentUpgrade{
+			AgentID:     agentID,
+			Version:     version,
+			Status:      models.AgentUpgradeStatusPending,
+			RequestedAt: now,
+			RequestedBy: actor,
+			UpdatedAt:   now,
+		})
+	}
+
+	if len(upgrades) > 0 {
+		err := s.db.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "agent_id"}},
+			DoUpdates: clause.AssignmentColumns(
+				[]string{"version", "status", "message_id", "error", "requested_at", "requested_by", "updated_at"}),
+		}).Create(&upgrades).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := []*models.AgentUpgrade{}
+	for _, u := range upgrades {
+		result = append(result, u.ToModel())
+	}
+
+	return result, nil
+}
+
+func (s *agentUpgradesService) GetAll() ([]*models.AgentUpgrade, error) {
+	var upgrades []*entities.AgentUpgrade
+	if err := s.db.Order("requested_at DESC, agent_id").Find(&upgrades).Error; err != nil {
+		return nil, err
+	}
+
+	result := []*models.AgentUpgrade{}
+	for _, u := range upgrades {
+		result = append(result, u.ToModel())
+	}
+
+	return result, nil
+}
+
+func (s *agentUpgradesService) GetPending(agentID string) (*models.AgentUpgrade, error) {
+	var upgrade entities.AgentUpgrade
+	err := s.db.Where("agent_id = ? AND status = ?", agentID, models.AgentUpgradeStatusPending).First(&upgrade).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return upgrade.ToModel(), nil
+}
+
+func (s *agentUpgradesService) MarkSent(agentID string, messageID string) error {
+	return s.db.Model(&entities.AgentUpgrade{}).
+		Where("agent_id = ? AND status = ?", agentID, models.AgentUpgradeStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.AgentUpgradeStatusSent,
+			"message_id": messageID,
+			"updated_at": timeNow(),
+		}).
+		Error
+}
+
+func (s *agentUpgradesService) MarkPending(agentID string, messageID string) error {
+	return s.db.Model(&entities.AgentUpgrade{}).
+		Where("agent_id = ? AND message_id = ? AND status = ?", agentID, messageID, models.AgentUpgradeStatusSent).
+		Updates(map[string]interface{}{
+			"status":     models.AgentUpgradeStatusPending,
+			"updated_at": timeNow(),
+		}).
+		Error
+}
+
+func (s *agentUpgradesService) Acknowledge(agentID string, messageID string, errorMessage string) error {
+	status := models.AgentUpgradeStatusInProgress
+	if errorMessage != "" {
+		status = models.AgentUpgradeStatusFailed
+	}
+
+	return s.db.Model(&entities.AgentUpgrade{}).
+		Where("agent_id = ? AND message_id = ? AND status = ?", agentID, messageID, models.AgentUpgradeStatusSent).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"error":      errorMessage,
+			"updated_at": timeNow(),
+		}).
+		Error
+}
+
+func (s *agentUpgradesService) Complete(agentID string, version string) error {
+	return s.db.Model(&entities.AgentUpgrade{}).
+		Where("agent_id = ? AND version = ? AND status IN ?", agentID, version,
+			[]string{models.AgentUpgradeStatusSent, models.AgentUpgradeStatusInProgress}).
+		Updates(map[string]interface{}{
+			"status":     models.AgentUpgradeStatusCompleted,
+			"updated_at": timeNow(),
+		}).
+		Error
+}
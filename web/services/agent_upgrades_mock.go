@@ -0,0 +1,198 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockAgentUpgradesService is an autogenerated mock type for the AgentUpgradesService type
+type MockAgentUpgradesService struct {
+	mock.Mock
+}
+
+// Acknowledge provides a mock function with given fields: agentID, messageID, errorMessage
+func (_m *MockAgentUpgradesService) Acknowledge(agentID string, messageID string, errorMessage string) error {
+	ret := _m.Called(agentID, messageID, errorMessage)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(agentID, messageID, errorMessage)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Complete provides a mock function with given fields: agentID, version
+func (_m *MockAgentUpgradesService) Complete(agentID string, version string) error {
+	ret := _m.Called(agentID, version)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(agentID, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields:
+func (_m *MockAgentUpgradesService) GetAll() ([]*models.AgentUpgrade, error) {
+	ret := _m.Called()
+
+	var r0 []*models.AgentUpgrade
+	if rf, ok := ret.Get(0).(func() []*models.AgentUpgrade); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AgentUpgrade)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPending provides a mock function with given fields: agentID
+func (_m *MockAgentUpgradesService) GetPending(agentID string) (*models.AgentUpgrade, error) {
+	ret := _m.Called(agentID)
+
+	var r0 *models.AgentUpgrade
+	if rf, ok := ret.Get(0).(func(string) *models.AgentUpgrade); ok {
+		r0 = rf(agentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AgentUpgrade)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVersion provides a mock function with given fields: version
+func (_m *MockAgentUpgradesService) GetVersion(version string) (*models.AgentVersion, error) {
+	ret := _m.Called(version)
+
+	var r0 *models.AgentVersion
+	if rf, ok := ret.Get(0).(func(string) *models.AgentVersion); ok {
+		r0 = rf(version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AgentVersion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVersions provides a mock function with given fields:
+func (_m *MockAgentUpgradesService) GetVersions() ([]*models.AgentVersion, error) {
+	ret := _m.Called()
+
+	var r0 []*models.AgentVersion
+	if rf, ok := ret.Get(0).(func() []*models.AgentVersion); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AgentVersion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkPending provides a mock function with given fields: agentID, messageID
+func (_m *MockAgentUpgradesService) MarkPending(agentID string, messageID string) error {
+	ret := _m.Called(agentID, messageID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(agentID, messageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkSent provides a mock function with given fields: agentID, messageID
+func (_m *MockAgentUpgradesService) MarkSent(agentID string, messageID string) error {
+	ret := _m.Called(agentID, messageID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(agentID, messageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Request provides a mock function with given fields: agentIDs, version, actor
+func (_m *MockAgentUpgradesService) Request(agentIDs []string, version string, actor string) ([]*models.AgentUpgrade, error) {
+	ret := _m.Called(agentIDs, version, actor)
+
+	var r0 []*models.AgentUpgrade
+	if rf, ok := ret.Get(0).(func([]string, string, string) []*models.AgentUpgrade); ok {
+		r0 = rf(agentIDs, version, actor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AgentUpgrade)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string, string, string) error); ok {
+		r1 = rf(agentIDs, version, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveVersion provides a mock function with given fields: version
+func (_m *MockAgentUpgradesService) SaveVersion(version *models.AgentVersion) error {
+	ret := _m.Called(version)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.AgentVersion) error); ok {
+		r0 = rf(version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type AgentUpgradesServiceTestSuite struct {
+	suite.Suite
+	db                   *gorm.DB
+	tx                   *gorm.DB
+	agentUpgradesService *agentUpgradesService
+	now                  time.Time
+}
+
+func TestAgentUpgradesServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AgentUpgradesServiceTestSuite))
+}
+
+func (suite *AgentUpgradesServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.AgentVersion{}, &entities.AgentUpgrade{})
+}
+
+func (suite *AgentUpgradesServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.AgentVersion{}, &entities.AgentUpgrade{})
+}
+
+func (suite *AgentUpgradesServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.agentUpgradesService = NewAgentUpgradesService(suite.tx)
+
+	suite.now = time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return suite.now }
+}
+
+func (suite *AgentUpgradesServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *AgentUpgradesServiceTestSuite) TestAgentUpgradesService_Versions() {
+	suite.NoError(suite.agentUpgradesService.SaveVersion(&models.AgentVersion{Version: "1.0.0", URL: "https://example.com/1.0.0", Checksum: "abc"}))
+	suite.now = suite.now.Add(time.Hour)
+	suite.NoError(suite.agentUpgradesService.SaveVersion(&models.AgentVersion{Version: "1.1.0", URL: "https://example.com/1.1.0", Checksum: "def", CreatedBy: "admin"}))
+
+	versions, err := suite.agentUpgradesService.GetVersions()
+	suite.NoError(err)
+	suite.Len(versions, 2)
+	suite.Equal("1.1.0", versions[0].Version)
+	suite.Equal("admin", versions[0].CreatedBy)
+
+	version, err := suite.agentUpgradesService.GetVersion("1.0.0")
+	suite.NoError(err)
+	suite.Equal("https://example.com/1.0.0", version.URL)
+
+	version, err = suite.agentUpgradesService.GetVersion("2.0.0")
+	suite.NoError(err)
+	suite.Nil(version)
+}
+
+func (suite *AgentUpgradesServiceTestSuite) TestAgentUpgradesService_Lifecycle() {
+	upgrades, err := suite.agentUpgradesService.Request([]string{"agent1", "agent2"}, "1.1.0", "admin")
+	suite.NoError(err)
+	suite.Len(upgrades, 2)
+	suite.Equal(models.AgentUpgradeStatusPending, upgrades[0].Status)
+
+	pending, err := suite.agentUpgradesService.GetPending("agent1")
+	suite.NoError(err)
+	suite.Equal("1.1.0", pending.Version)
+
+	suite.NoError(suite.agentUpgradesService.MarkSent("agent1", "message1"))
+	pending, err = suite.agentUpgradesService.GetPending("agent1")
+	suite.NoError(err)
+	suite.Nil(pending)
+
+	// the agent being connected meanwhile, the upgrade is delivered again on its next connection
+	suite.NoError(suite.agentUpgradesService.MarkSent("agent2", "message2"))
+	suite.NoError(suite.agentUpgradesService.MarkPending("agent2", "message2"))
+	pending, err = suite.agentUpgradesService.GetPending("agent2")
+	suite.NoError(err)
+	suite.NotNil(pending)
+
+	// the acknowledgements of the other messages are ignored
+	suite.NoError(suite.agentUpgradesService.Acknowledge("agent1", "other", "failed"))
+	suite.NoError(suite.agentUpgradesService.Acknowledge("agent1", "message1", ""))
+	suite.NoError(suite.agentUpgradesService.Complete("agent1", "1.0.0"))
+
+	upgrades, err = suite.agentUpgradesService.GetAll()
+	suite.NoError(err)
+	suite.Equal(models.AgentUpgradeStatusInProgress, upgrades[0].Status)
+
+	suite.NoError(suite.agentUpgradesService.Complete("agent1", "1.1.0"))
+
+	upgrades, err = suite.agentUpgradesService.GetAll()
+	suite.NoError(err)
+	suite.Equal("agent1", upgrades[0].AgentID)
+	suite.Equal(models.AgentUpgradeStatusCompleted, upgrades[0].Status)
+	suite.Equal(models.AgentUpgradeStatusPending, upgrades[1].Status)
+
+	// requested again, the previous outcome is reset
+	suite.NoError(suite.agentUpgradesService.MarkSent("agent2", "message3"))
+	suite.NoError(suite.agentUpgradesService.Acknowledge("agent2", "message3", "checksum mismatch"))
+	upgrades, err = suite.agentUpgradesService.Request([]string{"agent2"}, "1.1.0", "admin")
+	suite.NoError(err)
+	suite.Equal(models.AgentUpgradeStatusPending, upgrades[0].Status)
+	suite.Empty(upgrades[0].Error)
+}
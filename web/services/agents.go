@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+//go:generate mockery --name=AgentsService --inpackage --filename=agents_mock.go
+
+// ErrUnregisteredAgent is returned admitting the agents pending approval when the auto-registration is disabled
+var ErrUnregisteredAgent = errors.New("the agent is not registered, an admin has to approve it")
+
+type AgentsService interface {
+	// Admit returns the status of the agent, recording the agents contacting the collector for the first time.
+	// The agents are approved right away when the approval is not required, as are the ones of the hosts known already.
+	// When the unknown agents are rejected, the pending ones get ErrUnregisteredAgent until an admin approves them
+	Admit(agentID string) (string, error)
+	// GetAll returns the agents with the given status, all of them if empty,
+	// of the organization only unless empty
+	GetAll(status string, organization string) ([]*models.Agent, error)
+	// Approve returns nil if the agent does not exist
+	Approve(agentID string, reviewer string) (*models.Agent, error)
+	// Reject returns nil if the agent does not exist
+	Reject(agentID string, reviewer string) (*models.Agent, error)
+	// Register records the version, discoveries and OS the agent reported, replacing its previous registration
+	Register(agentID string, registration *hosts.AgentRegistration) error
+}
+
+type agentsService struct {
+	db                  *gorm.DB
+	requireApproval     bool
+	rejectUnknownAgents bool
+}
+
+// NewAgentsService disables the auto-registration of the agents if rejectUnknownAgents,
+// their data being refused until an admin approves them rather than held back
+func NewAgentsService(db *gorm.DB, requireApproval bool, rejectUnknownAgents bool) *agentsService {
+	return &agentsService{db: db, requireApproval: requireApproval, rejectUnknownAgents: rejectUnknownAgents}
+}
+
+func (s *agentsService) Admit(agentID string) (string, error) {
+	if !s.requireApproval && !s.rejectUnknownAgents {
+		return models.AgentStatusApproved, nil
+	}
+
+	status, err := s.admit(agentID)
+	if err != nil {
+		return "", err
+	}
+
+	if status == models.AgentStatusPending && s.rejectUnknownAgents {
+		return status, ErrUnregisteredAgent
+	}
+
+	return status, nil
+}
+
+func (s *agentsService) admit(agentID string) (string, error) {
+
+	var agents []entities.Agent
+	if err := s.db.Where("id = ?", agentID).Limit(1).Find(&agents).Error; err != nil {
+		return "", err
+	}
+	if len(agents) > 0 {
+		return agents[0].Status, nil
+	}
+
+	// the agents enrolled before the approval was required are not held back
+	var known int64
+	if err := s.db.Model(&entities.Host{}).Where("agent_id = ?", agentID).Count(&known).Error; err != nil {
+		return "", err
+	}
+
+	agent := entities.Agent{ID: agentID, Status: models.AgentStatusPending}
+	if known > 0 {
+		agent.Status = models.AgentStatusApproved
+	}
+
+	err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&agent).Error
+	if err != nil {
+		return "", err
+	}
+
+	return agent.Status, nil
+}
+
+func (s *agentsService) GetAll(status string, organization string) ([]*models.Agent, error) {
+	var agents []entities.Agent
+
+	db := s.db.Scopes(agentOrganizationScope(organization, "id")).Order("created_at DESC")
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if err := db.Find(&agents).Error; err != nil {
+		return nil, err
+	}
+
+	result := []*models.Agent{}
+	for _, agent := range agents {
+		result = append(result, agent.ToModel())
+	}
+
+	return result, nil
+}
+
+func (s *agentsService) Approve(agentID string, reviewer string) (*models.Agent, error) {
+	return s.review(agentID, models.AgentStatusApproved, reviewer)
+}
+
+func (s *agentsService) Reject(agentID string, reviewer string) (*models.Agent, error) {
+	return s.review(agentID, models.AgentStatusRejected, reviewer)
+}
+
+func (s *agentsService) review(agentID string, status string, reviewer string) (*models.Agent, error) {
+	var agent entities.Agent
+
+	result := s.db.Where("id = ?", agentID).Limit(1).Find(&agent)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+
+	now := timeNow()
+	agent.Status = status
+	agent.ReviewedAt = &now
+	agent.ReviewedBy = reviewer
+
+	if err := s.db.Save(&agent).Error; err != nil {
+		return nil, err
+	}
+
+	return agent.ToModel(), nil
+}
+
+func (s *agentsService) Register(agentID string, registration *hosts.AgentRegistration) error {
+	info := entities.AgentInfo{
+		AgentID:       agentID,
+		Version:       registration.Version,
+		Discoveries:   registration.Discoveries,
+		OSName:        registration.OSName,
+		OSVersion:     registration.OSVersion,
+		KernelVersion: registration.KernelVersion,
+		Arch:          registration.Arch,
+	}
+
+	return s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&info).Error
+}
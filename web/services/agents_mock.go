@@ -0,0 +1,119 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	hosts "github.com/trento-project/trento/internal/hosts"
+
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockAgentsService is an autogenerated mock type for the AgentsService type
+type MockAgentsService struct {
+	mock.Mock
+}
+
+// Admit provides a mock function with given fields: agentID
+func (_m *MockAgentsService) Admit(agentID string) (string, error) {
+	ret := _m.Called(agentID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(agentID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Approve provides a mock function with given fields: agentID, reviewer
+func (_m *MockAgentsService) Approve(agentID string, reviewer string) (*models.Agent, error) {
+	ret := _m.Called(agentID, reviewer)
+
+	var r0 *models.Agent
+	if rf, ok := ret.Get(0).(func(string, string) *models.Agent); ok {
+		r0 = rf(agentID, reviewer)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Agent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(agentID, reviewer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: status, organization
+func (_m *MockAgentsService) GetAll(status string, organization string) ([]*models.Agent, error) {
+	ret := _m.Called(status, organization)
+
+	var r0 []*models.Agent
+	if rf, ok := ret.Get(0).(func(string, string) []*models.Agent); ok {
+		r0 = rf(status, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Agent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(status, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Register provides a mock function with given fields: agentID, registration
+func (_m *MockAgentsService) Register(agentID string, registration *hosts.AgentRegistration) error {
+	ret := _m.Called(agentID, registration)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *hosts.AgentRegistration) error); ok {
+		r0 = rf(agentID, registration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reject provides a mock function with given fields: agentID, reviewer
+func (_m *MockAgentsService) Reject(agentID string, reviewer string) (*models.Agent, error) {
+	ret := _m.Called(agentID, reviewer)
+
+	var r0 *models.Agent
+	if rf, ok := ret.Get(0).(func(string, string) *models.Agent); ok {
+		r0 = rf(agentID, reviewer)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Agent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(agentID, reviewer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
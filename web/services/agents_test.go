@@ -0,0 +1,180 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type AgentsServiceTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestAgentsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AgentsServiceTestSuite))
+}
+
+func (suite *AgentsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Agent{}, &entities.Host{}, &entities.AgentInfo{})
+}
+
+func (suite *AgentsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.Agent{}, &entities.Host{}, &entities.AgentInfo{})
+}
+
+func (suite *AgentsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+}
+
+func (suite *AgentsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *AgentsServiceTestSuite) TestAgentsService_AdmitWithoutApproval() {
+	agentsService := NewAgentsService(suite.tx, false, false)
+
+	status, err := agentsService.Admit("agent1")
+	suite.NoError(err)
+	suite.Equal(models.AgentStatusApproved, status)
+
+	var count int64
+	suite.tx.Model(&entities.Agent{}).Count(&count)
+	suite.Equal(int64(0), count)
+}
+
+func (suite *AgentsServiceTestSuite) TestAgentsService_Admit() {
+	agentsService := NewAgentsService(suite.tx, true, false)
+	suite.tx.Create(&entities.Host{AgentID: "known"})
+
+	status, err := agentsService.Admit("new")
+	suite.NoError(err)
+	suite.Equal(models.AgentStatusPending, status)
+
+	status, err = agentsService.Admit("known")
+	suite.NoError(err)
+	suite.Equal(models.AgentStatusApproved, status)
+
+	pending, err := agentsService.GetAll(models.AgentStatusPending, "")
+	suite.NoError(err)
+	suite.Len(pending, 1)
+	suite.Equal("new", pending[0].ID)
+
+	all, err := agentsService.GetAll("", "")
+	suite.NoError(err)
+	suite.Len(all, 2)
+}
+
+func (suite *AgentsServiceTestSuite) TestAgentsService_AdmitRejectUnknownAgents() {
+	agentsService := NewAgentsService(suite.tx, false, true)
+	suite.tx.Create(&entities.Host{AgentID: "known"})
+
+	status, err := agentsService.Admit("new")
+	suite.ErrorIs(err, ErrUnregisteredAgent)
+	suite.Equal(models.AgentStatusPending, status)
+
+	status, err = agentsService.Admit("known")
+	suite.NoError(err)
+	suite.Equal(models.AgentStatusApproved, status)
+
+	// the unknown agents are listed for review, and admitted once approved
+	pending, err := agentsService.GetAll(models.AgentStatusPending, "")
+	suite.NoError(err)
+	suite.Len(pending, 1)
+	suite.Equal("new", pending[0].ID)
+
+	_, err = agentsService.Approve("new", "admin")
+	suite.NoError(err)
+
+	status, err = agentsService.Admit("new")
+	suite.NoError(err)
+	suite.Equal(models.AgentStatusApproved, status)
+}
+
+func (suite *AgentsServiceTestSuite) TestAgentsService_GetAllOrganization() {
+	agentsService := NewAgentsService(suite.tx, true, false)
+	suite.tx.Create(&entities.Agent{ID: "agent1", Status: models.AgentStatusPending})
+	suite.tx.Create(&entities.Agent{ID: "agent2", Status: models.AgentStatusPending})
+	suite.tx.Create(&entities.Agent{ID: "agent3", Status: models.AgentStatusPending})
+	suite.tx.Create(&entities.Host{AgentID: "agent1", Organization: "acme"})
+	suite.tx.Create(&entities.Host{AgentID: "agent2", Organization: "globex"})
+
+	agents, err := agentsService.GetAll(models.AgentStatusPending, "acme")
+	suite.NoError(err)
+	suite.Len(agents, 1)
+	suite.Equal("agent1", agents[0].ID)
+
+	agents, err = agentsService.GetAll("", "")
+	suite.NoError(err)
+	suite.Len(agents, 3)
+}
+
+func (suite *AgentsServiceTestSuite) TestAgentsService_Review() {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	agentsService := NewAgentsService(suite.tx, true, false)
+	agentsService.Admit("agent1")
+	agentsService.Admit("agent2")
+
+	approved, err := agentsService.Approve("agent1", "admin")
+	suite.NoError(err)
+	suite.Equal(models.AgentStatusApproved, approved.Status)
+	suite.Equal("admin", approved.ReviewedBy)
+	suite.Equal(now, approved.ReviewedAt.UTC())
+
+	rejected, err := agentsService.Reject("agent2", "admin")
+	suite.NoError(err)
+	suite.Equal(models.AgentStatusRejected, rejected.Status)
+
+	status, err := agentsService.Admit("agent2")
+	suite.NoError(err)
+	suite.Equal(models.AgentStatusRejected, status)
+
+	unknown, err := agentsService.Approve("unknown", "admin")
+	suite.NoError(err)
+	suite.Nil(unknown)
+}
+
+func (suite *AgentsServiceTestSuite) TestAgentsService_Register() {
+	agentsService := NewAgentsService(suite.tx, false, false)
+
+	err := agentsService.Register("agent1", &hosts.AgentRegistration{
+		Version:     "1.0.0",
+		Discoveries: []string{"host_discovery", "cloud_discovery"},
+		OSName:      "sles",
+		OSVersion:   "15.3",
+		Arch:        "x86_64",
+	})
+	suite.NoError(err)
+
+	err = agentsService.Register("agent1", &hosts.AgentRegistration{
+		Version:       "1.1.0",
+		Discoveries:   []string{"host_discovery"},
+		OSName:        "sles",
+		OSVersion:     "15.4",
+		KernelVersion: "5.14.21",
+		Arch:          "x86_64",
+	})
+	suite.NoError(err)
+
+	var infos []entities.AgentInfo
+	suite.tx.Find(&infos)
+	suite.Len(infos, 1)
+
+	info := infos[0].ToModel()
+	suite.Equal("1.1.0", info.Version)
+	suite.Equal([]string{"host_discovery"}, info.Discoveries)
+	suite.Equal("15.4", info.OSVersion)
+	suite.Equal("5.14.21", info.KernelVersion)
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	apiKeyPrefix       = "trento_"
+	apiKeyRandomBytes  = 32
+	apiKeyPrefixLength = len(apiKeyPrefix) + 6
+)
+
+// ApiKeyLastUsedResolution bounds how often the last usage of a key is written to the database,
+// so that the keys used by automation do not cause a write on every request
+var ApiKeyLastUsedResolution = time.Minute
+
+//go:generate mockery --name=ApiKeysService --inpackage --filename=api_keys_mock.go
+
+type ApiKeysService interface {
+	// Create returns the new key, the only time it can be read in clear.
+	// The key is restricted to the resources of the organization, to none if empty
+	Create(name string, scope string, organization string) (*models.ApiKey, error)
+	// GetAll returns the keys of the organization, all of them if empty
+	GetAll(organization string) ([]*models.ApiKey, error)
+	// Revoke returns nil if the key does not exist or belongs to another organization than the given one, if any
+	Revoke(id int64, organization string) (*models.ApiKey, error)
+	// Authenticate returns nil if the key does not exist or it is revoked,
+	// otherwise it records the key usage
+	Authenticate(key string) (*models.ApiKey, error)
+}
+
+type apiKeysService struct {
+	db *gorm.DB
+}
+
+func NewApiKeysService(db *gorm.DB) *apiKeysService {
+	return &apiKeysService{db: db}
+}
+
+func (s *apiKeysService) Create(name string, scope string, organization string) (*models.ApiKey, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+
+	if !models.IsValidApiKeyScope(scope) {
+		return nil, fmt.Errorf("invalid scope: %s", scope)
+	}
+
+	random := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	apiKey := entities.ApiKey{
+		Name:         name,
+		Prefix:       key[:apiKeyPrefixLength],
+		KeyHash:      hashApiKey(key),
+		Scope:        scope,
+		Organization: organization,
+	}
+
+	err := s.db.Create(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+
+	created := apiKey.ToModel()
+	created.Key = key
+
+	return created, nil
+}
+
+func (s *apiKeysService) GetAll(organization string) ([]*models.ApiKey, error) {
+	var apiKeys []entities.ApiKey
+
+	err := s.db.
+		Scopes(organizationScope(organization, "organization")).
+		Order("created_at DESC, id DESC").
+		Find(&apiKeys).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeyList := []*models.ApiKey{}
+	for _, apiKey := range apiKeys {
+		apiKeyList = append(apiKeyList, apiKey.ToModel())
+	}
+
+	return apiKeyList, nil
+}
+
+func (s *apiKeysService) Revoke(id int64, organization string) (*models.ApiKey, error) {
+	var apiKey entities.ApiKey
+
+	err := s.db.Scopes(organizationScope(organization, "organization")).First(&apiKey, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Revoking twice keeps the original revocation time
+	if apiKey.RevokedAt == nil {
+		now := timeNow()
+		err = s.db.Model(&apiKey).Update("revoked_at", now).Error
+		if err != nil {
+			return nil, err
+		}
+		apiKey.RevokedAt = &now
+	}
+
+	return apiKey.ToModel(), nil
+}
+
+func (s *apiKeysService) Authenticate(key string) (*models.ApiKey, error) {
+	var apiKey entities.ApiKey
+
+	err := s.db.Where("key_hash = ? AND revoked_at IS NULL", hashApiKey(key)).First(&apiKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := timeNow()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= ApiKeyLastUsedResolution {
+		err = s.db.Model(&apiKey).Update("last_used_at", now).Error
+		if err != nil {
+			return nil, err
+		}
+		apiKey.LastUsedAt = &now
+	}
+
+	return apiKey.ToModel(), nil
+}
+
+func hashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
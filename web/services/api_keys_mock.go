@@ -0,0 +1,105 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockApiKeysService is an autogenerated mock type for the ApiKeysService type
+type MockApiKeysService struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: key
+func (_m *MockApiKeysService) Authenticate(key string) (*models.ApiKey, error) {
+	ret := _m.Called(key)
+
+	var r0 *models.ApiKey
+	if rf, ok := ret.Get(0).(func(string) *models.ApiKey); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: name, scope, organization
+func (_m *MockApiKeysService) Create(name string, scope string, organization string) (*models.ApiKey, error) {
+	ret := _m.Called(name, scope, organization)
+
+	var r0 *models.ApiKey
+	if rf, ok := ret.Get(0).(func(string, string, string) *models.ApiKey); ok {
+		r0 = rf(name, scope, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(name, scope, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: organization
+func (_m *MockApiKeysService) GetAll(organization string) ([]*models.ApiKey, error) {
+	ret := _m.Called(organization)
+
+	var r0 []*models.ApiKey
+	if rf, ok := ret.Get(0).(func(string) []*models.ApiKey); ok {
+		r0 = rf(organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: id, organization
+func (_m *MockApiKeysService) Revoke(id int64, organization string) (*models.ApiKey, error) {
+	ret := _m.Called(id, organization)
+
+	var r0 *models.ApiKey
+	if rf, ok := ret.Get(0).(func(int64, string) *models.ApiKey); ok {
+		r0 = rf(id, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, string) error); ok {
+		r1 = rf(id, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type ApiKeysServiceTestSuite struct {
+	suite.Suite
+	db             *gorm.DB
+	tx             *gorm.DB
+	apiKeysService *apiKeysService
+}
+
+func TestApiKeysServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ApiKeysServiceTestSuite))
+}
+
+func (suite *ApiKeysServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.ApiKey{})
+}
+
+func (suite *ApiKeysServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.ApiKey{})
+}
+
+func (suite *ApiKeysServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.apiKeysService = NewApiKeysService(suite.tx)
+}
+
+func (suite *ApiKeysServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *ApiKeysServiceTestSuite) TestApiKeysService_CreateAndAuthenticate() {
+	created, err := suite.apiKeysService.Create("runner", models.ApiKeyScopeWrite, "")
+	suite.NoError(err)
+	suite.Equal("runner", created.Name)
+	suite.Equal(models.ApiKeyScopeWrite, created.Scope)
+	suite.True(strings.HasPrefix(created.Key, created.Prefix))
+	suite.Nil(created.LastUsedAt)
+
+	var apiKey entities.ApiKey
+	suite.tx.First(&apiKey)
+	suite.NotContains(apiKey.KeyHash, created.Key)
+
+	authenticated, err := suite.apiKeysService.Authenticate(created.Key)
+	suite.NoError(err)
+	suite.Equal(created.ID, authenticated.ID)
+	suite.Empty(authenticated.Key)
+	suite.NotNil(authenticated.LastUsedAt)
+
+	authenticated, err = suite.apiKeysService.Authenticate("trento_wrong")
+	suite.NoError(err)
+	suite.Nil(authenticated)
+}
+
+func (suite *ApiKeysServiceTestSuite) TestApiKeysService_CreateInvalid() {
+	_, err := suite.apiKeysService.Create("", models.ApiKeyScopeRead, "")
+	suite.Error(err)
+
+	_, err = suite.apiKeysService.Create("runner", "admin", "")
+	suite.Error(err)
+}
+
+func (suite *ApiKeysServiceTestSuite) TestApiKeysService_AuthenticateLastUsed() {
+	created, _ := suite.apiKeysService.Create("runner", models.ApiKeyScopeRead, "")
+
+	firstUse := time.Date(2022, time.March, 10, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return firstUse }
+	suite.apiKeysService.Authenticate(created.Key)
+
+	// Usages within the resolution are not recorded
+	timeNow = func() time.Time { return firstUse.Add(30 * time.Second) }
+	authenticated, err := suite.apiKeysService.Authenticate(created.Key)
+	suite.NoError(err)
+	suite.True(firstUse.Equal(*authenticated.LastUsedAt))
+
+	timeNow = func() time.Time { return firstUse.Add(2 * time.Minute) }
+	suite.apiKeysService.Authenticate(created.Key)
+
+	var apiKey entities.ApiKey
+	suite.tx.First(&apiKey, created.ID)
+	suite.True(firstUse.Add(2 * time.Minute).Equal(*apiKey.LastUsedAt))
+}
+
+func (suite *ApiKeysServiceTestSuite) TestApiKeysService_Revoke() {
+	created, _ := suite.apiKeysService.Create("runner", models.ApiKeyScopeWrite, "")
+	suite.apiKeysService.Create("dashboard", models.ApiKeyScopeRead, "")
+
+	revoked, err := suite.apiKeysService.Revoke(created.ID, "")
+	suite.NoError(err)
+	suite.NotNil(revoked.RevokedAt)
+
+	authenticated, err := suite.apiKeysService.Authenticate(created.Key)
+	suite.NoError(err)
+	suite.Nil(authenticated)
+
+	revoked, err = suite.apiKeysService.Revoke(-1, "")
+	suite.NoError(err)
+	suite.Nil(revoked)
+
+	apiKeys, err := suite.apiKeysService.GetAll("")
+	suite.NoError(err)
+	suite.Equal(2, len(apiKeys))
+}
+
+func (suite *ApiKeysServiceTestSuite) TestApiKeysService_Organization() {
+	created, err := suite.apiKeysService.Create("runner", models.ApiKeyScopeWrite, "acme")
+	suite.NoError(err)
+	suite.Equal("acme", created.Organization)
+	suite.apiKeysService.Create("dashboard", models.ApiKeyScopeRead, "")
+
+	authenticated, err := suite.apiKeysService.Authenticate(created.Key)
+	suite.NoError(err)
+	suite.Equal("acme", authenticated.Organization)
+
+	apiKeys, err := suite.apiKeysService.GetAll("acme")
+	suite.NoError(err)
+	suite.Equal(1, len(apiKeys))
+	suite.Equal("runner", apiKeys[0].Name)
+
+	revoked, err := suite.apiKeysService.Revoke(created.ID, "globex")
+	suite.NoError(err)
+	suite.Nil(revoked)
+
+	revoked, err = suite.apiKeysService.Revoke(created.ID, "acme")
+	suite.NoError(err)
+	suite.NotNil(revoked.RevokedAt)
+}
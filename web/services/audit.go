@@ -0,0 +1,131 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+//go:generate mockery --name=AuditService --inpackage --filename=audit_mock.go
+
+// AuditService records who changed what, so that the changes made through the console and the API can be reviewed
+type AuditService interface {
+	Record(entry *models.AuditEntry) error
+	// GetAll returns the entries matching the filter, the most recent first
+	GetAll(filter *AuditFilter, page *Page) ([]*models.AuditEntry, error)
+	GetCount(filter *AuditFilter) (int, error)
+	// GetAllActors returns the actors of the entries of the organization, of all of them if empty
+	GetAllActors(organization string) ([]string, error)
+}
+
+type AuditFilter struct {
+	Actors       []string
+	Actions      []string
+	ResourceType string
+	ResourceID   string
+	Since        time.Time
+	// AfterID only keeps the entries recorded after the given one, the IDs being sequential
+	AfterID int64
+	// Organization only keeps the entries of the users of the organization, unless empty
+	Organization string
+}
+
+type auditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *auditService {
+	return &auditService{db: db}
+}
+
+func (s *auditService) Record(entry *models.AuditEntry) error {
+	auditEntry, err := entities.NewAuditEntry(entry)
+	if err != nil {
+		return err
+	}
+	auditEntry.CreatedAt = timeNow()
+
+	return s.db.Create(auditEntry).Error
+}
+
+func (s *auditService) GetAll(filter *AuditFilter, page *Page) ([]*models.AuditEntry, error) {
+	var auditEntries []entities.AuditEntry
+
+	err := s.filter(filter).
+		Scopes(Paginate(page)).
+		Order("created_at DESC, id DESC").
+		Find(&auditEntries).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*models.AuditEntry{}
+	for _, e := range auditEntries {
+		entry, err := e.ToModel()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (s *auditService) GetCount(filter *AuditFilter) (int, error) {
+	var count int64
+	err := s.filter(filter).Model(&entities.AuditEntry{}).Count(&count).Error
+
+	return int(count), err
+}
+
+func (s *auditService) GetAllActors(organization string) ([]string, error) {
+	var actors []string
+
+	err := s.db.Model(&entities.AuditEntry{}).
+		Scopes(organizationScope(organization, "organization")).
+		Distinct().
+		Order("actor").
+		Pluck("actor", &actors).
+		Error
+
+	return actors, err
+}
+
+func (s *auditService) filter(filter *AuditFilter) *gorm.DB {
+	db := s.db
+	if filter == nil {
+		return db
+	}
+
+	db = db.Scopes(organizationScope(filter.Organization, "organization"))
+
+	if len(filter.Actors) > 0 {
+		db = db.Where("actor IN ?", filter.Actors)
+	}
+
+	if len(filter.Actions) > 0 {
+		db = db.Where("action IN ?", filter.Actions)
+	}
+
+	if filter.ResourceType != "" {
+		db = db.Where("resource_type = ?", filter.ResourceType)
+	}
+
+	if filter.ResourceID != "" {
+		db = db.Where("resource_id = ?", filter.ResourceID)
+	}
+
+	if !filter.Since.IsZero() {
+		db = db.Where("created_at >= ?", filter.Since)
+	}
+
+	if filter.AfterID > 0 {
+		db = db.Where("id > ?", filter.AfterID)
+	}
+
+	return db
+}
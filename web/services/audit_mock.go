@@ -0,0 +1,94 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockAuditService is an autogenerated mock type for the AuditService type
+type MockAuditService struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: filter, page
+func (_m *MockAuditService) GetAll(filter *AuditFilter, page *Page) ([]*models.AuditEntry, error) {
+	ret := _m.Called(filter, page)
+
+	var r0 []*models.AuditEntry
+	if rf, ok := ret.Get(0).(func(*AuditFilter, *Page) []*models.AuditEntry); ok {
+		r0 = rf(filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AuditEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*AuditFilter, *Page) error); ok {
+		r1 = rf(filter, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllActors provides a mock function with given fields: organization
+func (_m *MockAuditService) GetAllActors(organization string) ([]string, error) {
+	ret := _m.Called(organization)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCount provides a mock function with given fields: filter
+func (_m *MockAuditService) GetCount(filter *AuditFilter) (int, error) {
+	ret := _m.Called(filter)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(*AuditFilter) int); ok {
+		r0 = rf(filter)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*AuditFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Record provides a mock function with given fields: entry
+func (_m *MockAuditService) Record(entry *models.AuditEntry) error {
+	ret := _m.Called(entry)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.AuditEntry) error); ok {
+		r0 = rf(entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
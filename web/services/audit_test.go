@@ -0,0 +1,117 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type AuditServiceTestSuite struct {
+	suite.Suite
+	db           *gorm.DB
+	tx           *gorm.DB
+	auditService *auditService
+}
+
+func TestAuditServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditServiceTestSuite))
+}
+
+func (suite *AuditServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.AuditEntry{})
+}
+
+func (suite *AuditServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.AuditEntry{})
+}
+
+func (suite *AuditServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.auditService = NewAuditService(suite.tx)
+
+	start := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	for i, entry := range []*models.AuditEntry{
+		{Actor: "admin", Action: models.AuditActionTagCreated, ResourceType: models.TagHostResourceType, ResourceID: "host1", After: map[string]string{"tag": "prod"}},
+		{Actor: "operator", Action: models.AuditActionTagDeleted, ResourceType: models.TagHostResourceType, ResourceID: "host1", Before: map[string]string{"tag": "prod"}},
+		{Actor: "admin", Action: models.AuditActionUserCreated, ResourceType: models.AuditResourceUser, ResourceID: "2", After: map[string]string{"role": "viewer"}},
+	} {
+		timeNow = func() time.Time { return start.Add(time.Duration(i) * time.Minute) }
+		suite.NoError(suite.auditService.Record(entry))
+	}
+}
+
+func (suite *AuditServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *AuditServiceTestSuite) TestAuditService_GetAll() {
+	entries, err := suite.auditService.GetAll(nil, nil)
+	suite.NoError(err)
+	suite.Equal(3, len(entries))
+	suite.Equal(models.AuditActionUserCreated, entries[0].Action)
+	suite.Equal(map[string]interface{}{"role": "viewer"}, entries[0].After)
+	suite.Nil(entries[0].Before)
+
+	entries, err = suite.auditService.GetAll(nil, &Page{Number: 2, Size: 2})
+	suite.NoError(err)
+	suite.Equal(1, len(entries))
+	suite.Equal(models.AuditActionTagCreated, entries[0].Action)
+}
+
+func (suite *AuditServiceTestSuite) TestAuditService_GetAllFiltered() {
+	filter := &AuditFilter{
+		Actors:       []string{"admin"},
+		ResourceType: models.TagHostResourceType,
+		ResourceID:   "host1",
+	}
+
+	entries, err := suite.auditService.GetAll(filter, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(entries))
+	suite.Equal(models.AuditActionTagCreated, entries[0].Action)
+
+	count, err := suite.auditService.GetCount(&AuditFilter{Actions: []string{models.AuditActionTagCreated, models.AuditActionTagDeleted}})
+	suite.NoError(err)
+	suite.Equal(2, count)
+
+	all, err := suite.auditService.GetAll(nil, nil)
+	suite.NoError(err)
+	entries, err = suite.auditService.GetAll(&AuditFilter{AfterID: all[2].ID}, nil)
+	suite.NoError(err)
+	suite.Equal(2, len(entries))
+	suite.Equal(models.AuditActionTagDeleted, entries[1].Action)
+}
+
+func (suite *AuditServiceTestSuite) TestAuditService_GetAllOrganization() {
+	suite.NoError(suite.auditService.Record(&models.AuditEntry{
+		Actor: "jane", Action: models.AuditActionTagCreated, ResourceType: models.TagHostResourceType, ResourceID: "host2", Organization: "acme",
+	}))
+
+	entries, err := suite.auditService.GetAll(&AuditFilter{Organization: "acme"}, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(entries))
+	suite.Equal("jane", entries[0].Actor)
+	suite.Equal("acme", entries[0].Organization)
+
+	count, err := suite.auditService.GetCount(&AuditFilter{Organization: "acme"})
+	suite.NoError(err)
+	suite.Equal(1, count)
+
+	actors, err := suite.auditService.GetAllActors("acme")
+	suite.NoError(err)
+	suite.Equal([]string{"jane"}, actors)
+}
+
+func (suite *AuditServiceTestSuite) TestAuditService_GetAllActors() {
+	actors, err := suite.auditService.GetAllActors("")
+	suite.NoError(err)
+	suite.Equal([]string{"admin", "operator"}, actors)
+}
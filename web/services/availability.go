@@ -0,0 +1,555 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var timeNow = time.Now
+
+const (
+	RollupGranularityHour = "hour"
+	RollupGranularityDay  = "day"
+)
+
+// rollupDelay leaves out of the rollups the last minutes, whose heartbeats may be still on their way
+const rollupDelay = 5 * time.Minute
+
+// MinHeartbeatPeriodsRetention keeps the heartbeat periods shown in the timeline of the hosts
+const MinHeartbeatPeriodsRetention = 7 * 24 * time.Hour
+
+// HourlyRollupsRetention is the time the hourly rollups are kept, the daily ones being kept forever
+const HourlyRollupsRetention = 91 * 24 * time.Hour
+
+//go:generate mockery --name=AvailabilityService --inpackage --filename=availability_mock.go
+
+type AvailabilityService interface {
+	GetHostAvailability(agentID string, days []int) ([]*models.Availability, error)
+	GetSAPSystemAvailability(id string, days []int) ([]*models.Availability, error)
+	// Rollup aggregates the heartbeat periods of the hosts in hourly and daily rollups
+	Rollup() error
+	// Prune removes the heartbeat periods already rolled up and older than the retention,
+	// and the hourly rollups older than HourlyRollupsRetention. It returns the number of periods removed
+	Prune(retention time.Duration) (int64, error)
+}
+
+type availabilityService struct {
+	db *gorm.DB
+}
+
+func NewAvailabilityService(db *gorm.DB) *availabilityService {
+	return &availabilityService{db: db}
+}
+
+type period struct {
+	start time.Time
+	end   time.Time
+}
+
+// rollup is the time covered by the heartbeats, and tracked since the first of them, in a bucket of time
+type rollup struct {
+	start   time.Time
+	end     time.Time
+	covered time.Duration
+	tracked time.Duration
+}
+
+// hostHeartbeats are the rollups of a host and its heartbeat periods not rolled up yet
+type hostHeartbeats struct {
+	hourly  []rollup
+	daily   []rollup
+	periods []period
+}
+
+// watermark is the end of the last hourly rollup, the time after being accounted from the periods.
+// It is zero if the heartbeats of the host have not been rolled up yet
+func (h *hostHeartbeats) watermark() time.Time {
+	if len(h.hourly) == 0 {
+		return time.Time{}
+	}
+	return h.hourly[len(h.hourly)-1].end
+}
+
+// rollups returns the daily rollups of the days ending before the one of boundary, and the hourly rollups afterwards
+func (h *hostHeartbeats) rollups(boundary time.Time) []rollup {
+	day := boundary.Truncate(24 * time.Hour)
+
+	var rollups []rollup
+	for _, r := range h.daily {
+		if !r.end.After(day) {
+			rollups = append(rollups, r)
+		}
+	}
+	for _, r := range h.hourly {
+		if !r.start.Before(day) {
+			rollups = append(rollups, r)
+		}
+	}
+
+	return rollups
+}
+
+// heartbeats of a host, or of all the hosts of a SAP system. The time before the watermark
+// is accounted from the rollups, the one after from the heartbeat periods
+type heartbeats struct {
+	rollups   []rollup
+	watermark time.Time
+	periods   []period
+	// seenSince is the start of the time accounted from the periods, the watermark or the first heartbeat
+	seenSince time.Time
+}
+
+// intersectHeartbeats returns the heartbeats of all the hosts at the same time, nil if any of them never sent one.
+// The rollups of the same bucket are intersected taking the lowest covered and tracked times,
+// an approximation of the time the hosts were sending heartbeats together
+func intersectHeartbeats(hosts []*hostHeartbeats) *heartbeats {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	var boundary, watermark, seenSince time.Time
+	var rolledUp int
+	for _, h := range hosts {
+		if len(h.hourly) == 0 && len(h.periods) == 0 {
+			return nil
+		}
+
+		if len(h.hourly) == 0 {
+			if h.periods[0].start.After(seenSince) {
+				seenSince = h.periods[0].start
+			}
+			continue
+		}
+
+		rolledUp++
+		if h.hourly[0].start.After(boundary) {
+			boundary = h.hourly[0].start
+		}
+		if watermark.IsZero() || h.watermark().Before(watermark) {
+			watermark = h.watermark()
+		}
+	}
+
+	if watermark.After(seenSince) {
+		seenSince = watermark
+	}
+
+	result := &heartbeats{watermark: watermark, seenSince: seenSince}
+
+	if rolledUp == len(hosts) {
+		type intersection struct {
+			rollup
+			hosts int
+		}
+
+		buckets := make(map[time.Time]*intersection)
+		for _, h := range hosts {
+			for _, r := range h.rollups(boundary) {
+				b, ok := buckets[r.start]
+				if !ok {
+					buckets[r.start] = &intersection{rollup: r, hosts: 1}
+					continue
+				}
+
+				b.hosts++
+				if r.covered < b.covered {
+					b.covered = r.covered
+				}
+				if r.tracked < b.tracked {
+					b.tracked = r.tracked
+				}
+			}
+		}
+
+		for _, b := range buckets {
+			if b.hosts == len(hosts) {
+				result.rollups = append(result.rollups, b.rollup)
+			}
+		}
+		sort.Slice(result.rollups, func(i, j int) bool {
+			return result.rollups[i].start.Before(result.rollups[j].start)
+		})
+	}
+
+	for i, h := range hosts {
+		if i == 0 {
+			result.periods = h.periods
+		} else {
+			result.periods = intersectPeriods(result.periods, h.periods)
+		}
+	}
+
+	return result
+}
+
+// coverage returns the time covered by the heartbeats, and the time tracked since the first of them, between from and to.
+// The rollups partially in the range are accounted in proportion
+func (h *heartbeats) coverage(from time.Time, to time.Time) (time.Duration, time.Duration) {
+	var covered, tracked time.Duration
+
+	end := to
+	if h.watermark.Before(end) {
+		end = h.watermark
+	}
+
+	for _, r := range h.rollups {
+		overlap := coveredDuration([]period{{start: r.start, end: r.end}}, from, end)
+		if overlap <= 0 {
+			continue
+		}
+
+		ratio := float64(overlap) / float64(r.end.Sub(r.start))
+		covered += time.Duration(float64(r.covered) * ratio)
+		tracked += time.Duration(float64(r.tracked) * ratio)
+	}
+
+	start := from
+	if h.seenSince.After(start) {
+		start = h.seenSince
+	}
+
+	if start.Before(to) {
+		covered += coveredDuration(h.periods, start, to)
+		tracked += to.Sub(start)
+	}
+
+	return covered, tracked
+}
+
+// GetHostAvailability returns the percentage of time the host has been sending heartbeats
+// over the last given days. The time before the first heartbeat of the host is not taken into account.
+// It returns nil if the host never sent a heartbeat.
+func (s *availabilityService) GetHostAvailability(agentID string, days []int) ([]*models.Availability, error) {
+	h, err := getHostHeartbeats(s.db, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	hb := intersectHeartbeats([]*hostHeartbeats{h})
+	if hb == nil {
+		return nil, nil
+	}
+
+	return computeAvailability(hb, days), nil
+}
+
+// GetSAPSystemAvailability returns the percentage of time all the hosts running the SAP system instances
+// have been sending heartbeats over the last given days.
+// It returns nil if any of the hosts never sent a heartbeat.
+func (s *availabilityService) GetSAPSystemAvailability(id string, days []int) ([]*models.Availability, error) {
+	var agentIDs []string
+
+	err := s.db.Model(&entities.SAPSystemInstance{}).
+		Distinct("agent_id").
+		Where("id", id).
+		Pluck("agent_id", &agentIDs).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []*hostHeartbeats
+	for _, agentID := range agentIDs {
+		h, err := getHostHeartbeats(s.db, agentID)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, h)
+	}
+
+	hb := intersectHeartbeats(hosts)
+	if hb == nil {
+		return nil, nil
+	}
+
+	return computeAvailability(hb, days), nil
+}
+
+// getHostHeartbeats returns the rollups of a host and its heartbeat periods after them, sorted and without overlaps
+func getHostHeartbeats(db *gorm.DB, agentID string) (*hostHeartbeats, error) {
+	var entries []entities.HostAvailabilityRollup
+
+	err := db.
+		Where("agent_id", agentID).
+		Order("started_at").
+		Find(&entries).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	h := &hostHeartbeats{}
+	for _, e := range entries {
+		r := rollup{
+			start:   e.StartedAt,
+			covered: time.Duration(e.Covered) * time.Second,
+			tracked: time.Duration(e.Tracked) * time.Second,
+		}
+
+		if e.Granularity == RollupGranularityDay {
+			r.end = r.start.Add(24 * time.Hour)
+			h.daily = append(h.daily, r)
+		} else {
+			r.end = r.start.Add(time.Hour)
+			h.hourly = append(h.hourly, r)
+		}
+	}
+
+	h.periods, err = getPeriods(db, agentID, h.watermark())
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// getPeriods returns the heartbeat periods of a host ending after the given time, sorted and without overlaps
+func getPeriods(db *gorm.DB, agentID string, after time.Time) ([]period, error) {
+	var heartbeatPeriods []entities.HostHeartbeatPeriod
+
+	query := db.Where("agent_id", agentID)
+	if !after.IsZero() {
+		query = query.Where("ended_at > ?", after)
+	}
+
+	err := query.
+		Order("started_at").
+		Find(&heartbeatPeriods).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var periods []period
+	for _, p := range heartbeatPeriods {
+		periods = append(periods, period{start: p.StartedAt, end: p.EndedAt})
+	}
+
+	return mergePeriods(periods), nil
+}
+
+func computeAvailability(hb *heartbeats, days []int) []*models.Availability {
+	now := timeNow()
+
+	var availability []*models.Availability
+	for _, d := range days {
+		percentage := 0.0
+		if covered, tracked := hb.coverage(now.AddDate(0, 0, -d), now); tracked > 0 {
+			percentage = float64(covered) / float64(tracked) * 100
+		}
+
+		availability = append(availability, &models.Availability{
+			Days:       d,
+			Percentage: percentage,
+		})
+	}
+
+	return availability
+}
+
+// Rollup aggregates the heartbeat periods of every host, from the last rollup of the host,
+// or its first heartbeat, up to the last complete hour. A day is rolled up once all its hours are
+func (s *availabilityService) Rollup() error {
+	var agentIDs []string
+
+	err := s.db.Model(&entities.HostHeartbeatPeriod{}).
+		Distinct("agent_id").
+		Pluck("agent_id", &agentIDs).
+		Error
+	if err != nil {
+		return err
+	}
+
+	until := timeNow().Add(-rollupDelay).Truncate(time.Hour)
+	for _, agentID := range agentIDs {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			return rollupHost(tx, agentID, until)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rollupHost(tx *gorm.DB, agentID string, until time.Time) error {
+	h, err := getHostHeartbeats(tx, agentID)
+	if err != nil {
+		return err
+	}
+
+	from := h.watermark()
+	var firstSeen time.Time
+	if from.IsZero() {
+		if len(h.periods) == 0 {
+			return nil
+		}
+		firstSeen = h.periods[0].start
+		from = firstSeen.Truncate(time.Hour)
+	}
+
+	var hourly []entities.HostAvailabilityRollup
+	for start := from; start.Before(until); start = start.Add(time.Hour) {
+		end := start.Add(time.Hour)
+
+		trackedSince := start
+		if firstSeen.After(start) {
+			trackedSince = firstSeen
+		}
+
+		hourly = append(hourly, entities.HostAvailabilityRollup{
+			AgentID:     agentID,
+			Granularity: RollupGranularityHour,
+			StartedAt:   start,
+			Covered:     int64(coveredDuration(h.periods, start, end).Seconds()),
+			Tracked:     int64(end.Sub(trackedSince).Seconds()),
+		})
+	}
+
+	if len(hourly) == 0 {
+		return nil
+	}
+
+	err = tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&hourly, 500).Error
+	if err != nil {
+		return err
+	}
+
+	for day := from.Truncate(24 * time.Hour); !day.Add(24 * time.Hour).After(until); day = day.Add(24 * time.Hour) {
+		daily := entities.HostAvailabilityRollup{
+			AgentID:     agentID,
+			Granularity: RollupGranularityDay,
+			StartedAt:   day,
+		}
+
+		err := tx.Model(&entities.HostAvailabilityRollup{}).
+			Select("COALESCE(SUM(covered), 0) AS covered, COALESCE(SUM(tracked), 0) AS tracked").
+			Where("agent_id = ? AND granularity = ?", agentID, RollupGranularityHour).
+			Where("started_at >= ? AND started_at < ?", day, day.Add(24*time.Hour)).
+			Scan(&daily).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&daily).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Prune keeps the heartbeat periods for at least MinHeartbeatPeriodsRetention, and the last hourly rollup
+// of every host, the watermark the next rollups start from
+func (s *availabilityService) Prune(retention time.Duration) (int64, error) {
+	if retention < MinHeartbeatPeriodsRetention {
+		retention = MinHeartbeatPeriodsRetention
+	}
+	now := timeNow()
+
+	lastRollup := s.db.Table("host_availability_rollups AS last").
+		Select("MAX(last.started_at)").
+		Where("last.agent_id = host_heartbeat_periods.agent_id AND last.granularity = ?", RollupGranularityHour)
+
+	result := s.db.
+		Where("ended_at < ?", now.Add(-retention)).
+		Where("ended_at <= (?)", lastRollup).
+		Delete(&entities.HostHeartbeatPeriod{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	lastRollup = s.db.Table("host_availability_rollups AS last").
+		Select("MAX(last.started_at)").
+		Where("last.agent_id = host_availability_rollups.agent_id AND last.granularity = ?", RollupGranularityHour)
+
+	err := s.db.
+		Where("granularity = ? AND started_at < ?", RollupGranularityHour, now.Add(-HourlyRollupsRetention).Truncate(24*time.Hour)).
+		Where("started_at < (?)", lastRollup).
+		Delete(&entities.HostAvailabilityRollup{}).
+		Error
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
+
+func mergePeriods(periods []period) []period {
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].start.Before(periods[j].start)
+	})
+
+	var merged []period
+	for _, p := range periods {
+		last := len(merged) - 1
+		if last >= 0 && !p.start.After(merged[last].end) {
+			if p.end.After(merged[last].end) {
+				merged[last].end = p.end
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+
+	return merged
+}
+
+// intersectPeriods returns the periods of time contained in both lists, which must be merged
+func intersectPeriods(a []period, b []period) []period {
+	var intersection []period
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start := a[i].start
+		if b[j].start.After(start) {
+			start = b[j].start
+		}
+
+		end := a[i].end
+		if b[j].end.Before(end) {
+			end = b[j].end
+		}
+
+		if start.Before(end) {
+			intersection = append(intersection, period{start: start, end: end})
+		}
+
+		if a[i].end.Before(b[j].end) {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return intersection
+}
+
+func coveredDuration(periods []period, from time.Time, to time.Time) time.Duration {
+	var covered time.Duration
+
+	for _, p := range periods {
+		start := p.start
+		if from.After(start) {
+			start = from
+		}
+
+		end := p.end
+		if to.Before(end) {
+			end = to
+		}
+
+		if start.Before(end) {
+			covered += end.Sub(start)
+		}
+	}
+
+	return covered
+}
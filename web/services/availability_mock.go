@@ -0,0 +1,96 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+
+	time "time"
+)
+
+// MockAvailabilityService is an autogenerated mock type for the AvailabilityService type
+type MockAvailabilityService struct {
+	mock.Mock
+}
+
+// GetHostAvailability provides a mock function with given fields: agentID, days
+func (_m *MockAvailabilityService) GetHostAvailability(agentID string, days []int) ([]*models.Availability, error) {
+	ret := _m.Called(agentID, days)
+
+	var r0 []*models.Availability
+	if rf, ok := ret.Get(0).(func(string, []int) []*models.Availability); ok {
+		r0 = rf(agentID, days)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Availability)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []int) error); ok {
+		r1 = rf(agentID, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSAPSystemAvailability provides a mock function with given fields: id, days
+func (_m *MockAvailabilityService) GetSAPSystemAvailability(id string, days []int) ([]*models.Availability, error) {
+	ret := _m.Called(id, days)
+
+	var r0 []*models.Availability
+	if rf, ok := ret.Get(0).(func(string, []int) []*models.Availability); ok {
+		r0 = rf(id, days)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Availability)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []int) error); ok {
+		r1 = rf(id, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Prune provides a mock function with given fields: retention
+func (_m *MockAvailabilityService) Prune(retention time.Duration) (int64, error) {
+	ret := _m.Called(retention)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(time.Duration) int64); ok {
+		r0 = rf(retention)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Duration) error); ok {
+		r1 = rf(retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Rollup provides a mock function with given fields:
+func (_m *MockAvailabilityService) Rollup() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+var availabilityNow = time.Date(2021, time.July, 31, 0, 0, 0, 0, time.UTC)
+
+func daysAgo(days int) time.Time {
+	return availabilityNow.AddDate(0, 0, -days)
+}
+
+func TestMergePeriods(t *testing.T) {
+	periods := mergePeriods([]period{
+		{start: daysAgo(5), end: daysAgo(3)},
+		{start: daysAgo(10), end: daysAgo(8)},
+		{start: daysAgo(4), end: daysAgo(2)},
+	})
+
+	assert.Equal(t, []period{
+		{start: daysAgo(10), end: daysAgo(8)},
+		{start: daysAgo(5), end: daysAgo(2)},
+	}, periods)
+}
+
+func TestIntersectPeriods(t *testing.T) {
+	a := []period{
+		{start: daysAgo(10), end: daysAgo(6)},
+		{start: daysAgo(4), end: daysAgo(0)},
+	}
+	b := []period{
+		{start: daysAgo(8), end: daysAgo(3)},
+		{start: daysAgo(2), end: daysAgo(1)},
+	}
+
+	assert.Equal(t, []period{
+		{start: daysAgo(8), end: daysAgo(6)},
+		{start: daysAgo(4), end: daysAgo(3)},
+		{start: daysAgo(2), end: daysAgo(1)},
+	}, intersectPeriods(a, b))
+}
+
+func TestComputeAvailability(t *testing.T) {
+	timeNow = func() time.Time {
+		return availabilityNow
+	}
+	defer func() { timeNow = time.Now }()
+
+	periods := []period{
+		{start: daysAgo(60), end: daysAgo(30)},
+		{start: daysAgo(7), end: daysAgo(0)},
+	}
+
+	availability := computeAvailability(&heartbeats{periods: periods, seenSince: daysAgo(60)}, models.AvailabilityWindows)
+
+	assert.Equal(t, []*models.Availability{
+		{Days: 7, Percentage: 100},
+		{Days: 30, Percentage: float64(7) / 30 * 100},
+		{Days: 90, Percentage: float64(37) / 60 * 100},
+	}, availability)
+}
+
+func TestComputeAvailabilityRollups(t *testing.T) {
+	timeNow = func() time.Time {
+		return availabilityNow
+	}
+	defer func() { timeNow = time.Now }()
+
+	var rollups []rollup
+	for d := 60; d > 7; d-- {
+		r := rollup{start: daysAgo(d), end: daysAgo(d - 1), tracked: 24 * time.Hour}
+		if d > 30 {
+			r.covered = 24 * time.Hour
+		}
+		rollups = append(rollups, r)
+	}
+
+	hb := &heartbeats{
+		rollups:   rollups,
+		watermark: daysAgo(7),
+		periods:   []period{{start: daysAgo(7), end: daysAgo(0)}},
+		seenSince: daysAgo(7),
+	}
+
+	assert.Equal(t, []*models.Availability{
+		{Days: 7, Percentage: 100},
+		{Days: 30, Percentage: float64(7) / 30 * 100},
+		{Days: 90, Percentage: float64(37) / 60 * 100},
+	}, computeAvailability(hb, models.AvailabilityWindows))
+}
+
+func TestHeartbeatsCoveragePartialRollup(t *testing.T) {
+	hb := &heartbeats{
+		rollups:   []rollup{{start: daysAgo(2), end: daysAgo(1), covered: 12 * time.Hour, tracked: 24 * time.Hour}},
+		watermark: daysAgo(1),
+		seenSince: daysAgo(1),
+	}
+
+	covered, tracked := hb.coverage(daysAgo(2).Add(18*time.Hour), daysAgo(0))
+
+	assert.Equal(t, 3*time.Hour, covered)
+	assert.Equal(t, 30*time.Hour, tracked)
+}
+
+func TestHostHeartbeatsRollups(t *testing.T) {
+	h := &hostHeartbeats{
+		daily: []rollup{
+			{start: daysAgo(3), end: daysAgo(2)},
+			{start: daysAgo(2), end: daysAgo(1)},
+		},
+		hourly: []rollup{
+			{start: daysAgo(2).Add(10 * time.Hour), end: daysAgo(2).Add(11 * time.Hour)},
+			{start: daysAgo(1), end: daysAgo(1).Add(time.Hour)},
+		},
+	}
+
+	assert.Equal(t, daysAgo(1).Add(time.Hour), h.watermark())
+	assert.Equal(t, []rollup{
+		{start: daysAgo(3), end: daysAgo(2)},
+		{start: daysAgo(2).Add(10 * time.Hour), end: daysAgo(2).Add(11 * time.Hour)},
+		{start: daysAgo(1), end: daysAgo(1).Add(time.Hour)},
+	}, h.rollups(h.hourly[0].start))
+}
+
+func TestIntersectHeartbeats(t *testing.T) {
+	hour := func(h int) time.Time {
+		return daysAgo(1).Add(time.Duration(h) * time.Hour)
+	}
+
+	a := &hostHeartbeats{
+		hourly: []rollup{
+			{start: hour(0), end: hour(1), covered: time.Hour, tracked: time.Hour},
+			{start: hour(1), end: hour(2), covered: 30 * time.Minute, tracked: time.Hour},
+			{start: hour(2), end: hour(3), covered: time.Hour, tracked: time.Hour},
+		},
+		periods: []period{{start: hour(3), end: hour(5)}},
+	}
+	b := &hostHeartbeats{
+		hourly: []rollup{
+			{start: hour(1), end: hour(2), covered: 45 * time.Minute, tracked: 45 * time.Minute},
+		},
+		periods: []period{{start: hour(2), end: hour(4)}},
+	}
+
+	assert.Equal(t, &heartbeats{
+		rollups:   []rollup{{start: hour(1), end: hour(2), covered: 30 * time.Minute, tracked: 45 * time.Minute}},
+		watermark: hour(2),
+		periods:   []period{{start: hour(3), end: hour(4)}},
+		seenSince: hour(2),
+	}, intersectHeartbeats([]*hostHeartbeats{a, b}))
+}
+
+func TestIntersectHeartbeatsNotRolledUp(t *testing.T) {
+	a := &hostHeartbeats{
+		hourly:  []rollup{{start: daysAgo(2), end: daysAgo(2).Add(time.Hour), covered: time.Hour, tracked: time.Hour}},
+		periods: []period{{start: daysAgo(2).Add(time.Hour), end: daysAgo(0)}},
+	}
+	b := &hostHeartbeats{
+		periods: []period{{start: daysAgo(1), end: daysAgo(0)}},
+	}
+
+	assert.Equal(t, &heartbeats{
+		watermark: daysAgo(2).Add(time.Hour),
+		periods:   []period{{start: daysAgo(1), end: daysAgo(0)}},
+		seenSince: daysAgo(1),
+	}, intersectHeartbeats([]*hostHeartbeats{a, b}))
+
+	assert.Nil(t, intersectHeartbeats([]*hostHeartbeats{a, {}}))
+}
+
+type AvailabilityServiceTestSuite struct {
+	suite.Suite
+	db                  *gorm.DB
+	tx                  *gorm.DB
+	availabilityService *availabilityService
+}
+
+func TestAvailabilityServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AvailabilityServiceTestSuite))
+}
+
+func (suite *AvailabilityServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.HostHeartbeatPeriod{}, &entities.HostAvailabilityRollup{}, &entities.SAPSystemInstance{})
+}
+
+func (suite *AvailabilityServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.HostHeartbeatPeriod{}, &entities.HostAvailabilityRollup{}, &entities.SAPSystemInstance{})
+}
+
+func (suite *AvailabilityServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.availabilityService = NewAvailabilityService(suite.tx)
+
+	timeNow = func() time.Time {
+		return availabilityNow
+	}
+
+	suite.tx.Create(&[]entities.HostHeartbeatPeriod{
+		{AgentID: "host1", StartedAt: daysAgo(30), EndedAt: daysAgo(0)},
+		{AgentID: "host2", StartedAt: daysAgo(30), EndedAt: daysAgo(10)},
+		{AgentID: "host2", StartedAt: daysAgo(5), EndedAt: daysAgo(0)},
+	})
+	suite.tx.Create(&[]entities.SAPSystemInstance{
+		{ID: "sapsystem1", AgentID: "host1", InstanceNumber: "00"},
+		{ID: "sapsystem1", AgentID: "host2", InstanceNumber: "01"},
+	})
+}
+
+func (suite *AvailabilityServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *AvailabilityServiceTestSuite) TestAvailabilityService_GetHostAvailability() {
+	availability, err := suite.availabilityService.GetHostAvailability("host2", []int{7, 30})
+
+	suite.NoError(err)
+	suite.Equal([]*models.Availability{
+		{Days: 7, Percentage: float64(5) / 7 * 100},
+		{Days: 30, Percentage: float64(25) / 30 * 100},
+	}, availability)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestAvailabilityService_GetHostAvailabilityNoHeartbeats() {
+	availability, err := suite.availabilityService.GetHostAvailability("other", []int{7})
+
+	suite.NoError(err)
+	suite.Nil(availability)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestAvailabilityService_GetSAPSystemAvailability() {
+	availability, err := suite.availabilityService.GetSAPSystemAvailability("sapsystem1", []int{30})
+
+	suite.NoError(err)
+	suite.Equal([]*models.Availability{
+		{Days: 30, Percentage: float64(25) / 30 * 100},
+	}, availability)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestAvailabilityService_Rollup() {
+	suite.NoError(suite.availabilityService.Rollup())
+	// rolling up again does not duplicate the rollups
+	suite.NoError(suite.availabilityService.Rollup())
+
+	var hourly []entities.HostAvailabilityRollup
+	suite.tx.Where("agent_id = ? AND granularity = ?", "host2", RollupGranularityHour).Order("started_at").Find(&hourly)
+	// up to the last complete hour
+	suite.Len(hourly, 30*24-1)
+	suite.Equal(daysAgo(30), hourly[0].StartedAt.UTC())
+	suite.Equal(int64(3600), hourly[0].Covered)
+	suite.Equal(int64(0), hourly[24*22].Covered)
+	suite.Equal(int64(3600), hourly[24*22].Tracked)
+
+	var daily []entities.HostAvailabilityRollup
+	suite.tx.Where("agent_id = ? AND granularity = ?", "host2", RollupGranularityDay).Order("started_at").Find(&daily)
+	suite.Len(daily, 29)
+	suite.Equal(int64(24*3600), daily[0].Covered)
+	suite.Equal(int64(0), daily[20].Covered)
+	suite.Equal(int64(24*3600), daily[20].Tracked)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestAvailabilityService_Prune() {
+	suite.NoError(suite.availabilityService.Rollup())
+
+	pruned, err := suite.availabilityService.Prune(24 * time.Hour)
+	suite.NoError(err)
+	// the periods of the last 7 days are kept anyway
+	suite.Equal(int64(1), pruned)
+
+	var count int64
+	suite.tx.Model(&entities.HostHeartbeatPeriod{}).Where("agent_id", "host2").Count(&count)
+	suite.Equal(int64(1), count)
+
+	availability, err := suite.availabilityService.GetHostAvailability("host2", []int{7, 30})
+	suite.NoError(err)
+	suite.Equal([]*models.Availability{
+		{Days: 7, Percentage: float64(5) / 7 * 100},
+		{Days: 30, Percentage: float64(25) / 30 * 100},
+	}, availability)
+
+	availability, err = suite.availabilityService.GetSAPSystemAvailability("sapsystem1", []int{30})
+	suite.NoError(err)
+	suite.Equal([]*models.Availability{
+		{Days: 30, Percentage: float64(25) / 30 * 100},
+	}, availability)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestAvailabilityService_PruneNotRolledUp() {
+	pruned, err := suite.availabilityService.Prune(MinHeartbeatPeriodsRetention)
+	suite.NoError(err)
+	suite.Equal(int64(0), pruned)
+}
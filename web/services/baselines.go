@@ -0,0 +1,292 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+// comparedDiscoveries are the discoveries whose settings are compared against the golden host,
+// leaving out the ones identifying the hosts or changing all the time
+var comparedDiscoveries = []struct {
+	discoveryType string
+	compared      func(path string) bool
+}{
+	{datapipeline.HostDiscovery, func(path string) bool {
+		for _, excluded := range []string{"hostname", "ip_addresses", "ssh_address", "utilization"} {
+			if path == excluded || strings.HasPrefix(path, excluded+"/") {
+				return false
+			}
+		}
+		return true
+	}},
+	{datapipeline.SubscriptionDiscovery, func(path string) bool {
+		return true
+	}},
+	// the instances are specific to every host, only the profiles of the SAP systems are compared
+	{datapipeline.SAPsystemDiscovery, func(path string) bool {
+		parts := strings.SplitN(path, "/", 3)
+		return len(parts) == 3 && parts[1] == "Profile"
+	}},
+}
+
+// elementKeys identify the elements of the arrays in the payloads, their position being used otherwise
+var elementKeys = []string{"identifier", "SID"}
+
+//go:generate mockery --name=BaselinesService --inpackage --filename=baselines_mock.go
+
+type BaselinesService interface {
+	GetAll() ([]*models.HostBaseline, error)
+	// Save makes the host the golden one of the role, replacing the previous one
+	Save(role string, agentID string) (*models.HostBaseline, error)
+	// Delete returns nil if the role has no golden host
+	Delete(role string) (*models.HostBaseline, error)
+	// GetReport compares the hosts tagged with the role against its golden host, it returns nil if the role has none
+	GetReport(role string) (*models.BaselineReport, error)
+}
+
+type baselinesService struct {
+	db *gorm.DB
+}
+
+func NewBaselinesService(db *gorm.DB) *baselinesService {
+	return &baselinesService{db: db}
+}
+
+func (s *baselinesService) GetAll() ([]*models.HostBaseline, error) {
+	var baselines []entities.HostBaseline
+	err := s.db.Order("role").Find(&baselines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*models.HostBaseline{}
+	for _, b := range baselines {
+		result = append(result, b.ToModel())
+	}
+
+	return result, nil
+}
+
+func (s *baselinesService) get(role string) (*models.HostBaseline, error) {
+	var baselines []entities.HostBaseline
+	err := s.db.Where("role = ?", role).Limit(1).Find(&baselines).Error
+	if err != nil || len(baselines) == 0 {
+		return nil, err
+	}
+
+	return baselines[0].ToModel(), nil
+}
+
+func (s *baselinesService) Save(role string, agentID string) (*models.HostBaseline, error) {
+	baseline := entities.HostBaseline{Role: role, AgentID: agentID}
+
+	err := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&baseline).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return baseline.ToModel(), nil
+}
+
+func (s *baselinesService) Delete(role string) (*models.HostBaseline, error) {
+	baseline, err := s.get(role)
+	if err != nil || baseline == nil {
+		return nil, err
+	}
+
+	err = s.db.Where("role = ?", role).Delete(&entities.HostBaseline{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return baseline, nil
+}
+
+func (s *baselinesService) GetReport(role string) (*models.BaselineReport, error) {
+	baseline, err := s.get(role)
+	if err != nil || baseline == nil {
+		return nil, err
+	}
+
+	var hosts []entities.Host
+	err = s.db.
+		Select("agent_id, name").
+		Where("agent_id = ? OR agent_id IN (?)", baseline.AgentID, s.db.Model(&models.Tag{}).
+			Select("resource_id").
+			Where("resource_type = ? AND value = ?", models.TagHostResourceType, role)).
+		Order("name, agent_id").
+		Find(&hosts).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var agentIDs []string
+	for _, h := range hosts {
+		agentIDs = append(agentIDs, h.AgentID)
+	}
+
+	settings, err := s.collectedSettings(agentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BaselineReport{Baseline: baseline, Hosts: []*models.HostDrift{}}
+	for _, h := range hosts {
+		if h.AgentID == baseline.AgentID {
+			report.GoldenHostName = h.Name
+			continue
+		}
+
+		report.Hosts = append(report.Hosts, &models.HostDrift{
+			AgentID:     h.AgentID,
+			HostName:    h.Name,
+			Differences: compareSettings(settings[baseline.AgentID], settings[h.AgentID]),
+		})
+	}
+
+	return report, nil
+}
+
+// collectedSettings returns the compared settings of the latest discoveries of the agents,
+// keyed by agent, discovery type and path
+func (s *baselinesService) collectedSettings(agentIDs []string) (map[string]map[string]map[string]string, error) {
+	var discoveryTypes []string
+	for _, d := range comparedDiscoveries {
+		discoveryTypes = append(discoveryTypes, d.discoveryType)
+	}
+
+	var events []*datapipeline.DataCollectedEvent
+	err := s.db.
+		Select("DISTINCT ON (agent_id, discovery_type) *").
+		Where("agent_id IN ? AND discovery_type IN ?", agentIDs, discoveryTypes).
+		Order("agent_id, discovery_type, id DESC").
+		Find(&events).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]map[string]map[string]string)
+	for _, e := range events {
+		var payload interface{}
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			return nil, err
+		}
+
+		leaves := make(map[string]string)
+		flattenPayload("", payload, leaves)
+
+		if _, ok := settings[e.AgentID]; !ok {
+			settings[e.AgentID] = make(map[string]map[string]string)
+		}
+		settings[e.AgentID][e.DiscoveryType] = leaves
+	}
+
+	return settings, nil
+}
+
+// compareSettings returns the differences of the host from the golden one, sorted by discovery and path
+func compareSettings(golden map[string]map[string]string, host map[string]map[string]string) []*models.ConfigurationDifference {
+	differences := []*models.ConfigurationDifference{}
+
+	for _, d := range comparedDiscoveries {
+		expected, actual := golden[d.discoveryType], host[d.discoveryType]
+
+		paths := make(map[string]bool)
+		for path := range expected {
+			paths[path] = true
+		}
+		for path := range actual {
+			paths[path] = true
+		}
+
+		var sorted []string
+		for path := range paths {
+			if d.compared(path) {
+				sorted = append(sorted, path)
+			}
+		}
+		sort.Strings(sorted)
+
+		for _, path := range sorted {
+			e, inGolden := expected[path]
+			a, inHost := actual[path]
+
+			var kind string
+			switch {
+			case !inHost:
+				kind = models.DifferenceMissing
+			case !inGolden:
+				kind = models.DifferenceUnexpected
+			case e != a:
+				kind = models.DifferenceChanged
+			default:
+				continue
+			}
+
+			differences = append(differences, &models.ConfigurationDifference{
+				DiscoveryType: d.discoveryType,
+				Path:          path,
+				Kind:          kind,
+				Expected:      e,
+				Actual:        a,
+			})
+		}
+	}
+
+	return differences
+}
+
+// flattenPayload collects the JSON encoded leaves of the payload, keyed by their path
+func flattenPayload(path string, value interface{}, leaves map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flattenPayload(joinPath(path, key), child, leaves)
+		}
+	case []interface{}:
+		seen := make(map[string]bool)
+		for i, child := range v {
+			key := elementKey(child, i)
+			if seen[key] {
+				key = fmt.Sprintf("%s#%d", key, i)
+			}
+			seen[key] = true
+
+			flattenPayload(joinPath(path, key), child, leaves)
+		}
+	default:
+		encoded, _ := json.Marshal(v)
+		leaves[path] = string(encoded)
+	}
+}
+
+func elementKey(element interface{}, position int) string {
+	if object, ok := element.(map[string]interface{}); ok {
+		for _, key := range elementKeys {
+			if id, ok := object[key].(string); ok && id != "" {
+				return id
+			}
+		}
+	}
+
+	return fmt.Sprint(position)
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "/" + key
+}
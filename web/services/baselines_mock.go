@@ -0,0 +1,105 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockBaselinesService is an autogenerated mock type for the BaselinesService type
+type MockBaselinesService struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: role
+func (_m *MockBaselinesService) Delete(role string) (*models.HostBaseline, error) {
+	ret := _m.Called(role)
+
+	var r0 *models.HostBaseline
+	if rf, ok := ret.Get(0).(func(string) *models.HostBaseline); ok {
+		r0 = rf(role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.HostBaseline)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields:
+func (_m *MockBaselinesService) GetAll() ([]*models.HostBaseline, error) {
+	ret := _m.Called()
+
+	var r0 []*models.HostBaseline
+	if rf, ok := ret.Get(0).(func() []*models.HostBaseline); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.HostBaseline)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReport provides a mock function with given fields: role
+func (_m *MockBaselinesService) GetReport(role string) (*models.BaselineReport, error) {
+	ret := _m.Called(role)
+
+	var r0 *models.BaselineReport
+	if rf, ok := ret.Get(0).(func(string) *models.BaselineReport); ok {
+		r0 = rf(role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BaselineReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: role, agentID
+func (_m *MockBaselinesService) Save(role string, agentID string) (*models.HostBaseline, error) {
+	ret := _m.Called(role, agentID)
+
+	var r0 *models.HostBaseline
+	if rf, ok := ret.Get(0).(func(string, string) *models.HostBaseline); ok {
+		r0 = rf(role, agentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.HostBaseline)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(role, agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type BaselinesServiceTestSuite struct {
+	suite.Suite
+	db               *gorm.DB
+	tx               *gorm.DB
+	baselinesService *baselinesService
+}
+
+func TestBaselinesServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(BaselinesServiceTestSuite))
+}
+
+func (suite *BaselinesServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.HostBaseline{}, &entities.Host{}, &models.Tag{}, &datapipeline.DataCollectedEvent{})
+}
+
+func (suite *BaselinesServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.HostBaseline{}, &entities.Host{}, &models.Tag{}, &datapipeline.DataCollectedEvent{})
+}
+
+func (suite *BaselinesServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.baselinesService = NewBaselinesService(suite.tx)
+
+	suite.tx.Create(&entities.HostBaseline{Role: "app-server", AgentID: "1"})
+	suite.tx.Create(&[]entities.Host{
+		{AgentID: "1", Name: "netweaver01"},
+		{AgentID: "2", Name: "netweaver02"},
+		{AgentID: "3", Name: "netweaver03"},
+		{AgentID: "4", Name: "hana01"},
+	})
+	suite.tx.Create(&[]models.Tag{
+		{Value: "app-server", ResourceID: "2", ResourceType: models.TagHostResourceType},
+		{Value: "app-server", ResourceID: "3", ResourceType: models.TagHostResourceType},
+		{Value: "app-server", ResourceID: "4", ResourceType: models.TagClusterResourceType},
+	})
+	suite.tx.Create(&[]datapipeline.DataCollectedEvent{
+		{AgentID: "1", DiscoveryType: datapipeline.HostDiscovery, Payload: datatypes.JSON(`{"hostname": "netweaver01", "os_version": "15-SP2"}`)},
+		{AgentID: "1", DiscoveryType: datapipeline.HostDiscovery, Payload: datatypes.JSON(`{"hostname": "netweaver01", "os_version": "15-SP3"}`)},
+		{AgentID: "2", DiscoveryType: datapipeline.HostDiscovery, Payload: datatypes.JSON(`{"hostname": "netweaver02", "os_version": "15-SP3"}`)},
+		{AgentID: "3", DiscoveryType: datapipeline.HostDiscovery, Payload: datatypes.JSON(`{"hostname": "netweaver03", "os_version": "15-SP2"}`)},
+		{AgentID: "1", DiscoveryType: datapipeline.SAPsystemDiscovery, Payload: datatypes.JSON(`[{"SID": "HA1", "Profile": {"rdisp/wp_no_dia": "10"}}]`)},
+		{AgentID: "2", DiscoveryType: datapipeline.SAPsystemDiscovery, Payload: datatypes.JSON(`[{"SID": "HA1", "Profile": {"rdisp/wp_no_dia": "10"}}]`)},
+	})
+}
+
+func (suite *BaselinesServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *BaselinesServiceTestSuite) TestBaselinesService_GetReport() {
+	report, err := suite.baselinesService.GetReport("app-server")
+	suite.NoError(err)
+	suite.Equal("1", report.Baseline.AgentID)
+	suite.Equal("netweaver01", report.GoldenHostName)
+	suite.Equal([]*models.HostDrift{
+		{
+			AgentID:     "2",
+			HostName:    "netweaver02",
+			Differences: []*models.ConfigurationDifference{},
+		},
+		{
+			AgentID:  "3",
+			HostName: "netweaver03",
+			Differences: []*models.ConfigurationDifference{
+				{
+					DiscoveryType: datapipeline.HostDiscovery,
+					Path:          "os_version",
+					Kind:          models.DifferenceChanged,
+					Expected:      `"15-SP3"`,
+					Actual:        `"15-SP2"`,
+				},
+				{
+					DiscoveryType: datapipeline.SAPsystemDiscovery,
+					Path:          "HA1/Profile/rdisp/wp_no_dia",
+					Kind:          models.DifferenceMissing,
+					Expected:      `"10"`,
+				},
+			},
+		},
+	}, report.Hosts)
+
+	report, err = suite.baselinesService.GetReport("db-server")
+	suite.NoError(err)
+	suite.Nil(report)
+}
+
+func (suite *BaselinesServiceTestSuite) TestBaselinesService_SaveAndDelete() {
+	baseline, err := suite.baselinesService.Save("app-server", "2")
+	suite.NoError(err)
+	suite.Equal("2", baseline.AgentID)
+
+	_, err = suite.baselinesService.Save("db-server", "4")
+	suite.NoError(err)
+
+	baselines, err := suite.baselinesService.GetAll()
+	suite.NoError(err)
+	suite.Len(baselines, 2)
+	suite.Equal("app-server", baselines[0].Role)
+	suite.Equal("2", baselines[0].AgentID)
+
+	deleted, err := suite.baselinesService.Delete("db-server")
+	suite.NoError(err)
+	suite.Equal("4", deleted.AgentID)
+
+	deleted, err = suite.baselinesService.Delete("db-server")
+	suite.NoError(err)
+	suite.Nil(deleted)
+}
+
+func TestCompareSettings(t *testing.T) {
+	golden := make(map[string]string)
+	flattenPayload("", map[string]interface{}{
+		"hostname":     "netweaver01",
+		"cpu_count":    float64(4),
+		"ip_addresses": []interface{}{"10.1.1.4"},
+		"provisioning": map[string]interface{}{"tool": "terraform", "template_version": "1.2.0"},
+	}, golden)
+
+	host := make(map[string]string)
+	flattenPayload("", map[string]interface{}{
+		"hostname":     "netweaver02",
+		"cpu_count":    float64(2),
+		"ip_addresses": []interface{}{"10.1.1.5"},
+		"provisioning": map[string]interface{}{"tool": "terraform"},
+		"hypervisor":   "kvm",
+	}, host)
+
+	golden2 := make(map[string]string)
+	flattenPayload("", []interface{}{
+		map[string]interface{}{"identifier": "SLES_SAP", "version": "15.3"},
+		map[string]interface{}{"identifier": "sle-module-basesystem", "version": "15.3"},
+	}, golden2)
+
+	host2 := make(map[string]string)
+	flattenPayload("", []interface{}{
+		map[string]interface{}{"identifier": "sle-module-basesystem", "version": "15.3"},
+		map[string]interface{}{"identifier": "SLES_SAP", "version": "15.2"},
+	}, host2)
+
+	differences := compareSettings(
+		map[string]map[string]string{datapipeline.HostDiscovery: golden, datapipeline.SubscriptionDiscovery: golden2},
+		map[string]map[string]string{datapipeline.HostDiscovery: host, datapipeline.SubscriptionDiscovery: host2},
+	)
+
+	assert.Equal(t, []*models.ConfigurationDifference{
+		{DiscoveryType: datapipeline.HostDiscovery, Path: "cpu_count", Kind: models.DifferenceChanged, Expected: "4", Actual: "2"},
+		{DiscoveryType: datapipeline.HostDiscovery, Path: "hypervisor", Kind: models.DifferenceUnexpected, Actual: `"kvm"`},
+		{DiscoveryType: datapipeline.HostDiscovery, Path: "provisioning/template_version", Kind: models.DifferenceMissing, Expected: `"1.2.0"`},
+		{DiscoveryType: datapipeline.SubscriptionDiscovery, Path: "SLES_SAP/version", Kind: models.DifferenceChanged, Expected: `"15.3"`, Actual: `"15.2"`},
+	}, differences)
+}
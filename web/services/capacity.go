@@ -0,0 +1,122 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	// HANA allows by default 90% of the first 64 GB of physical memory and 97% of the rest to be allocated
+	hanaDefaultLimitThresholdMB int64 = 64 * 1024
+	hanaDefaultLimitLowRatio          = 0.90
+	hanaDefaultLimitHighRatio         = 0.97
+)
+
+//go:generate mockery --name=CapacityService --inpackage --filename=capacity_mock.go
+
+type CapacityService interface {
+	GetCapacityOverview(organization string) (*models.CapacityOverview, error)
+}
+
+type capacityService struct {
+	hostsRepository HostsRepository
+}
+
+func NewCapacityService(hostsRepository HostsRepository) *capacityService {
+	return &capacityService{hostsRepository: hostsRepository}
+}
+
+// GetCapacityOverview aggregates, per host and per HANA database, the memory the HANA instances
+// are allowed to allocate against the physical memory, flagging the over-commits.
+// The hosts of the organization only are aggregated, all of them if empty
+func (s *capacityService) GetCapacityOverview(organization string) (*models.CapacityOverview, error) {
+	hosts, err := s.hostsRepository.GetAll(&HostsFilter{Organization: organization}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &models.CapacityOverview{
+		Hosts:      []*models.HostCapacity{},
+		SAPSystems: []*models.SAPSystemCapacity{},
+	}
+	sapSystems := make(map[string]*models.SAPSystemCapacity)
+
+	for _, h := range hosts {
+		hostCapacity := newHostCapacity(&h)
+		overview.Hosts = append(overview.Hosts, hostCapacity)
+
+		for _, allocation := range hostCapacity.Allocations {
+			sapSystem, ok := sapSystems[allocation.SAPSystemID]
+			if !ok {
+				sapSystem = &models.SAPSystemCapacity{
+					ID:  allocation.SAPSystemID,
+					SID: allocation.SID,
+				}
+				sapSystems[allocation.SAPSystemID] = sapSystem
+				overview.SAPSystems = append(overview.SAPSystems, sapSystem)
+			}
+
+			sapSystem.HostsCount++
+			sapSystem.PhysicalMemoryMB += hostCapacity.PhysicalMemoryMB
+			sapSystem.AllocatedMemoryMB += allocation.AllocatedMemoryMB
+		}
+	}
+
+	for _, sapSystem := range overview.SAPSystems {
+		sapSystem.OverCommitted = sapSystem.PhysicalMemoryMB > 0 && sapSystem.AllocatedMemoryMB > sapSystem.PhysicalMemoryMB
+	}
+
+	sort.SliceStable(overview.SAPSystems, func(i, j int) bool {
+		return overview.SAPSystems[i].SID < overview.SAPSystems[j].SID
+	})
+
+	return overview, nil
+}
+
+func newHostCapacity(host *entities.Host) *models.HostCapacity {
+	hostCapacity := &models.HostCapacity{
+		ID:               host.AgentID,
+		Name:             host.Name,
+		Hypervisor:       host.Hypervisor,
+		Sockets:          host.SocketCount,
+		Cores:            host.CoreCount,
+		Threads:          host.CPUCount,
+		PhysicalMemoryMB: int64(host.TotalMemoryMB),
+		Allocations:      []*models.MemoryAllocation{},
+	}
+
+	for _, instance := range host.SAPSystemInstances {
+		if instance.Type != models.SAPSystemTypeDatabase {
+			continue
+		}
+
+		allocation := &models.MemoryAllocation{
+			SAPSystemID:       instance.ID,
+			SID:               instance.SID,
+			InstanceNumber:    instance.InstanceNumber,
+			AllocatedMemoryMB: instance.GlobalAllocationLimitMB,
+		}
+		if allocation.AllocatedMemoryMB == 0 {
+			allocation.Default = true
+			allocation.AllocatedMemoryMB = hanaDefaultAllocationLimit(hostCapacity.PhysicalMemoryMB)
+		}
+
+		hostCapacity.Allocations = append(hostCapacity.Allocations, allocation)
+		hostCapacity.AllocatedMemoryMB += allocation.AllocatedMemoryMB
+	}
+
+	hostCapacity.OverCommitted = hostCapacity.PhysicalMemoryMB > 0 && hostCapacity.AllocatedMemoryMB > hostCapacity.PhysicalMemoryMB
+
+	return hostCapacity
+}
+
+func hanaDefaultAllocationLimit(physicalMemoryMB int64) int64 {
+	if physicalMemoryMB <= hanaDefaultLimitThresholdMB {
+		return int64(float64(physicalMemoryMB) * hanaDefaultLimitLowRatio)
+	}
+
+	return int64(float64(hanaDefaultLimitThresholdMB)*hanaDefaultLimitLowRatio +
+		float64(physicalMemoryMB-hanaDefaultLimitThresholdMB)*hanaDefaultLimitHighRatio)
+}
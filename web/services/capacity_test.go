@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+func TestHanaDefaultAllocationLimit(t *testing.T) {
+	assert.EqualValues(t, 29491, hanaDefaultAllocationLimit(32*1024))
+	assert.EqualValues(t, 58982, hanaDefaultAllocationLimit(64*1024))
+	assert.EqualValues(t, 249692, hanaDefaultAllocationLimit(256*1024))
+}
+
+func TestCapacityService_GetCapacityOverview(t *testing.T) {
+	repository := new(MockHostsRepository)
+	repository.On("GetAll", &HostsFilter{Organization: "acme"}, (*Page)(nil)).Return([]entities.Host{
+		{
+			AgentID:       "1",
+			Name:          "hana01",
+			Hypervisor:    "kvm",
+			SocketCount:   2,
+			CoreCount:     16,
+			CPUCount:      32,
+			TotalMemoryMB: 256 * 1024,
+			SAPSystemInstances: entities.SAPSystemInstances{
+				{ID: "prd", SID: "PRD", InstanceNumber: "00", Type: models.SAPSystemTypeDatabase, GlobalAllocationLimitMB: 200 * 1024},
+				{ID: "qas", SID: "QAS", InstanceNumber: "10", Type: models.SAPSystemTypeDatabase, GlobalAllocationLimitMB: 100 * 1024},
+			},
+		},
+		{
+			AgentID:       "2",
+			Name:          "hana02",
+			TotalMemoryMB: 256 * 1024,
+			SAPSystemInstances: entities.SAPSystemInstances{
+				{ID: "prd", SID: "PRD", InstanceNumber: "00", Type: models.SAPSystemTypeDatabase},
+			},
+		},
+		{
+			AgentID:       "3",
+			Name:          "app01",
+			TotalMemoryMB: 64 * 1024,
+			SAPSystemInstances: entities.SAPSystemInstances{
+				{ID: "ha1", SID: "HA1", InstanceNumber: "00", Type: models.SAPSystemTypeApplication},
+			},
+		},
+	}, nil)
+
+	capacityService := NewCapacityService(repository)
+	overview, err := capacityService.GetCapacityOverview("acme")
+	assert.NoError(t, err)
+
+	assert.Equal(t, &models.CapacityOverview{
+		Hosts: []*models.HostCapacity{
+			{
+				ID:                "1",
+				Name:              "hana01",
+				Hypervisor:        "kvm",
+				Sockets:           2,
+				Cores:             16,
+				Threads:           32,
+				PhysicalMemoryMB:  256 * 1024,
+				AllocatedMemoryMB: 300 * 1024,
+				Allocations: []*models.MemoryAllocation{
+					{SAPSystemID: "prd", SID: "PRD", InstanceNumber: "00", AllocatedMemoryMB: 200 * 1024},
+					{SAPSystemID: "qas", SID: "QAS", InstanceNumber: "10", AllocatedMemoryMB: 100 * 1024},
+				},
+				OverCommitted: true,
+			},
+			{
+				ID:                "2",
+				Name:              "hana02",
+				PhysicalMemoryMB:  256 * 1024,
+				AllocatedMemoryMB: 249692,
+				Allocations: []*models.MemoryAllocation{
+					{SAPSystemID: "prd", SID: "PRD", InstanceNumber: "00", AllocatedMemoryMB: 249692, Default: true},
+				},
+			},
+			{
+				ID:               "3",
+				Name:             "app01",
+				PhysicalMemoryMB: 64 * 1024,
+				Allocations:      []*models.MemoryAllocation{},
+			},
+		},
+		SAPSystems: []*models.SAPSystemCapacity{
+			{ID: "prd", SID: "PRD", HostsCount: 2, PhysicalMemoryMB: 512 * 1024, AllocatedMemoryMB: 200*1024 + 249692},
+			{ID: "qas", SID: "QAS", HostsCount: 1, PhysicalMemoryMB: 256 * 1024, AllocatedMemoryMB: 100 * 1024},
+		},
+	}, overview)
+}
@@ -3,14 +3,12 @@ package services
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
 
-	"gorm.io/datatypes"
-	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
-
-	"github.com/trento-project/trento/web/datapipeline"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
 )
 
 const (
@@ -30,8 +28,11 @@ type ChecksService interface {
 	GetLastExecutionByGroup() ([]*models.ChecksResult, error)
 	GetChecksResultByCluster(clusterId string) (*models.ChecksResult, error)
 	GetChecksResultAndMetadataByCluster(clusterId string) (*models.ChecksResultAsList, error)
+	GetClusterFacts(clusterId string) ([]*models.DiscoveredFact, error)
 	GetAggregatedChecksResultByHost(clusterId string) (map[string]*models.AggregatedCheckData, error)
 	GetAggregatedChecksResultByCluster(clusterId string) (*models.AggregatedCheckData, error)
+	GetChecksRunsByCluster(clusterId string) ([]*models.ChecksRun, error)
+	GetChecksResultDiffByCluster(clusterId string, previousRunId int64, currentRunId int64) (*models.ChecksResultDiff, error)
 	// Selected checks services
 	GetSelectedChecksById(id string) (models.SelectedChecks, error)
 	CreateSelectedChecks(id string, selectedChecksList []string) error
@@ -42,14 +43,19 @@ type ChecksService interface {
 }
 
 type checksService struct {
-	db                      *gorm.DB
-	premiumDetectionService PremiumDetectionService
+	repository              ChecksRepository
+	premiumDetectionService PremiumEntitlement
+	// credentialsCipher encrypts the connection settings, stored in plaintext if nil
+	credentialsCipher CredentialsCipher
+	healthStrategy    HealthStrategy
 }
 
-func NewChecksService(db *gorm.DB, premiumDetectionService PremiumDetectionService) *checksService {
+func NewChecksService(repository ChecksRepository, premiumDetectionService PremiumEntitlement, credentialsCipher CredentialsCipher, healthStrategy HealthStrategy) *checksService {
 	return &checksService{
-		db:                      db,
+		repository:              repository,
 		premiumDetectionService: premiumDetectionService,
+		credentialsCipher:       credentialsCipher,
+		healthStrategy:          healthStrategy,
 	}
 }
 
@@ -58,20 +64,11 @@ Checks catalog services
 */
 
 func (c *checksService) GetChecksCatalog() (models.ChecksCatalog, error) {
-	var checksEntity entities.CheckList
 	isPremiumActive, _ := c.premiumDetectionService.IsPremiumActive()
 
-	var result *gorm.DB
-	qb := c.db.Order("payload->>'name'")
-
-	if isPremiumActive {
-		result = qb.Find(&checksEntity)
-	} else {
-		result = qb.Find(&checksEntity, datatypes.JSONQuery("payload").Equals(false, "premium"))
-	}
-
-	if result.Error != nil {
-		return nil, result.Error
+	checksEntity, err := c.repository.GetCatalog(isPremiumActive)
+	if err != nil {
+		return nil, err
 	}
 
 	return checksEntity.ToModel()
@@ -109,11 +106,8 @@ func (c *checksService) CreateChecksCatalogEntry(check *models.Check) error {
 	}
 
 	checkEntity := entities.Check{ID: check.ID, Payload: checkJson}
-	result := c.db.Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(&checkEntity)
 
-	return result.Error
+	return c.repository.SaveCatalogEntry(&checkEntity)
 }
 
 func (c *checksService) CreateChecksCatalog(checkList models.ChecksCatalog) error {
@@ -127,16 +121,7 @@ func (c *checksService) CreateChecksCatalog(checkList models.ChecksCatalog) erro
 		checkEntityList = append(checkEntityList, &entities.Check{ID: check.ID, Payload: checkJson})
 	}
 
-	result := c.db.Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(&checkEntityList)
-
-	if result.Error != nil {
-		return result.Error
-	}
-
-	// Remove old not updated checks
-	return c.db.Not(&checkEntityList).Delete(entities.CheckList{}).Error
+	return c.repository.ReplaceCatalog(checkEntityList)
 }
 
 /*
@@ -150,7 +135,7 @@ func (c *checksService) CreateChecksResult(checksResult *models.ChecksResult) er
 	}
 
 	event := entities.ChecksResult{GroupID: checksResult.ID, Payload: jsonData}
-	err = c.db.Create(&event).Error
+	err = c.repository.CreateChecksResult(&event)
 	if err != nil {
 		return err
 	}
@@ -161,8 +146,7 @@ func (c *checksService) CreateChecksResult(checksResult *models.ChecksResult) er
 		return err
 	}
 
-	err = datapipeline.ProjectHealth(
-		c.db, checksResult.ID, partialChecksHealth, aggregatedHealth.String())
+	err = c.repository.ProjectHealth(checksResult.ID, c.healthStrategy.ChecksHealth(aggregatedHealth))
 	if err != nil {
 		return err
 	}
@@ -171,11 +155,7 @@ func (c *checksService) CreateChecksResult(checksResult *models.ChecksResult) er
 }
 
 func (c *checksService) GetLastExecutionByGroup() ([]*models.ChecksResult, error) {
-	var checksResults []entities.ChecksResult
-
-	err := c.db.Where("(group_id, created_at) IN (?)", c.db.Model(&entities.ChecksResult{}).
-		Select("group_id, max(created_at)").
-		Group("group_id")).Order("id").Find(&checksResults).Error
+	checksResults, err := c.repository.GetLastChecksResultsByGroup()
 	if err != nil {
 		return nil, err
 	}
@@ -190,11 +170,9 @@ func (c *checksService) GetLastExecutionByGroup() ([]*models.ChecksResult, error
 }
 
 func (c *checksService) GetChecksResultByCluster(clusterId string) (*models.ChecksResult, error) {
-	var checksResult entities.ChecksResult
-	result := c.db.Where("group_id", clusterId).Last(&checksResult)
-
-	if result.Error != nil {
-		return nil, result.Error
+	checksResult, err := c.repository.GetLastChecksResult(clusterId)
+	if err != nil {
+		return nil, err
 	}
 
 	return checksResult.ToModel()
@@ -213,6 +191,7 @@ func (c *checksService) GetChecksResultAndMetadataByCluster(clusterId string) (*
 
 	resultSet := &models.ChecksResultAsList{}
 	resultSet.Hosts = cResultByCluster.Hosts
+	resultSet.UpdatedAt = &cResultByCluster.CreatedAt
 	resultSet.Checks = []*models.ChecksByHost{}
 
 	for _, checkMeta := range checkList {
@@ -233,6 +212,58 @@ func (c *checksService) GetChecksResultAndMetadataByCluster(clusterId string) (*
 	return resultSet, nil
 }
 
+// GetClusterFacts returns the facts discovered on the cluster hosts by the last checks execution,
+// described by the catalog. The facts of the checks no longer in the catalog are left out
+func (c *checksService) GetClusterFacts(clusterId string) ([]*models.DiscoveredFact, error) {
+	facts := []*models.DiscoveredFact{}
+
+	cResultByCluster, err := c.GetChecksResultByCluster(clusterId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return facts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	checkList, err := c.GetChecksCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, checkMeta := range checkList {
+		checkByHost, ok := cResultByCluster.Checks[checkMeta.ID]
+		if !ok {
+			continue
+		}
+
+		for _, fact := range checkMeta.Facts {
+			for hostName, host := range checkByHost.Hosts {
+				value, ok := host.FactValues[fact.Name]
+				if !ok {
+					continue
+				}
+
+				facts = append(facts, &models.DiscoveredFact{
+					CheckID:     checkMeta.ID,
+					Name:        fact.Name,
+					Description: fact.Description,
+					Host:        hostName,
+					Value:       value,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(facts, func(i, j int) bool {
+		if facts[i].Name != facts[j].Name {
+			return facts[i].Name < facts[j].Name
+		}
+		return facts[i].Host < facts[j].Host
+	})
+
+	return facts, nil
+}
+
 func (c *checksService) GetAggregatedChecksResultByHost(clusterId string) (map[string]*models.AggregatedCheckData, error) {
 	cResultByCluster, err := c.GetChecksResultByCluster(clusterId)
 	if err != nil {
@@ -251,6 +282,84 @@ func (c *checksService) GetAggregatedChecksResultByCluster(clusterId string) (*m
 	return cResultByCluster.GetAggregatedChecksResultByCluster(), nil
 }
 
+func (c *checksService) GetChecksRunsByCluster(clusterId string) ([]*models.ChecksRun, error) {
+	return c.repository.GetChecksRuns(clusterId)
+}
+
+// GetChecksResultDiffByCluster compares two check runs of a cluster.
+// If the run ids are 0, the last run is compared with the one before it.
+// It returns nil if any of the runs cannot be found.
+func (c *checksService) GetChecksResultDiffByCluster(clusterId string, previousRunId int64, currentRunId int64) (*models.ChecksResultDiff, error) {
+	if previousRunId == 0 || currentRunId == 0 {
+		runs, err := c.GetChecksRunsByCluster(clusterId)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(runs) < 2 {
+			return nil, nil
+		}
+
+		if currentRunId == 0 {
+			currentRunId = runs[0].ID
+		}
+		if previousRunId == 0 {
+			previousRunId = runs[1].ID
+		}
+	}
+
+	checksResults, err := c.repository.GetChecksResults(clusterId, []int64{previousRunId, currentRunId})
+	if err != nil {
+		return nil, err
+	}
+
+	var previous, current *entities.ChecksResult
+	for i, r := range checksResults {
+		if r.ID == previousRunId {
+			previous = &checksResults[i]
+		}
+		if r.ID == currentRunId {
+			current = &checksResults[i]
+		}
+	}
+
+	if previous == nil || current == nil {
+		return nil, nil
+	}
+
+	previousModel, err := previous.ToModel()
+	if err != nil {
+		return nil, err
+	}
+
+	currentModel, err := current.ToModel()
+	if err != nil {
+		return nil, err
+	}
+
+	diff := currentModel.Diff(previousModel)
+	diff.Previous = &models.ChecksRun{ID: previous.ID, CreatedAt: previous.CreatedAt}
+	diff.Current = &models.ChecksRun{ID: current.ID, CreatedAt: current.CreatedAt}
+
+	checkList, err := c.GetChecksCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make(map[string]string)
+	for _, check := range checkList {
+		descriptions[check.ID] = check.Description
+	}
+
+	for _, changes := range [][]*models.CheckResultChange{diff.NewlyFailing, diff.NewlyPassing, diff.NewlyMuted} {
+		for _, change := range changes {
+			change.Description = descriptions[change.CheckID]
+		}
+	}
+
+	return diff, nil
+}
+
 /*
 Selected checks services
 */
@@ -266,13 +375,11 @@ func (c *checksService) GetSelectedChecksById(id string) (models.SelectedChecks,
 		return selectedChecks, err
 	}
 
-	err = c.db.Where("id", id).First(&selectedChecks).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return selectedChecks, nil
-		}
+	stored, err := c.repository.GetSelectedChecks(id)
+	if err != nil || stored == nil {
 		return selectedChecks, err
 	}
+	selectedChecks = *stored
 
 	set := make(map[string]struct{})
 	filteredChecks := []string{}
@@ -298,11 +405,7 @@ func (c *checksService) CreateSelectedChecks(id string, selectedChecksList []str
 		SelectedChecks: selectedChecksList,
 	}
 
-	result := c.db.Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(&selectedChecks)
-
-	return result.Error
+	return c.repository.SaveSelectedChecks(&selectedChecks)
 }
 
 /*
@@ -310,24 +413,28 @@ Checks connection user services
 */
 
 func (c *checksService) GetConnectionSettingsByNode(node string) (models.ConnectionSettings, error) {
-	var connUser models.ConnectionSettings
+	connUser, err := c.repository.GetConnectionSettingsByNode(node)
+	if err != nil {
+		return connUser, err
+	}
 
-	result := c.db.Where("node", node).First(&connUser)
+	connUser.User, err = c.decryptCredentials(connUser.User)
 
-	return connUser, result.Error
+	return connUser, err
 }
 
 func (c *checksService) GetConnectionSettingsById(id string) (map[string]models.ConnectionSettings, error) {
-	var connUsersList []models.ConnectionSettings
-
-	result := c.db.Where("id", id).Find(&connUsersList)
-
-	if result.Error != nil {
-		return nil, result.Error
+	connUsersList, err := c.repository.GetConnectionSettings(id)
+	if err != nil {
+		return nil, err
 	}
 
 	connUsersMap := make(map[string]models.ConnectionSettings)
 	for _, user := range connUsersList {
+		user.User, err = c.decryptCredentials(user.User)
+		if err != nil {
+			return nil, err
+		}
 		connUsersMap[user.Node] = user
 	}
 
@@ -335,15 +442,67 @@ func (c *checksService) GetConnectionSettingsById(id string) (map[string]models.
 }
 
 func (c *checksService) CreateConnectionSettings(id, node, user string) error {
+	encrypted, err := c.encryptCredentials(user)
+	if err != nil {
+		return err
+	}
+
 	connUser := models.ConnectionSettings{
 		ID:   id,
 		Node: node,
-		User: user,
+		User: encrypted,
+	}
+
+	return c.repository.SaveConnectionSettings(&connUser)
+}
+
+// EncryptConnectionSettings encrypts the connection settings stored in plaintext before the cipher was configured,
+// returning how many were encrypted
+func (c *checksService) EncryptConnectionSettings() (int, error) {
+	if c.credentialsCipher == nil {
+		return 0, nil
+	}
+
+	connUsersList, err := c.repository.GetAllConnectionSettings()
+	if err != nil {
+		return 0, err
+	}
+
+	encrypted := 0
+	for _, connUser := range connUsersList {
+		if IsEncryptedCredential(connUser.User) {
+			continue
+		}
+
+		connUser.User, err = c.credentialsCipher.Encrypt(connUser.User)
+		if err != nil {
+			return encrypted, err
+		}
+
+		if err := c.repository.SaveConnectionSettings(&connUser); err != nil {
+			return encrypted, err
+		}
+		encrypted++
 	}
 
-	result := c.db.Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(&connUser)
+	return encrypted, nil
+}
+
+func (c *checksService) encryptCredentials(value string) (string, error) {
+	if c.credentialsCipher == nil {
+		return value, nil
+	}
+
+	return c.credentialsCipher.Encrypt(value)
+}
+
+func (c *checksService) decryptCredentials(value string) (string, error) {
+	if c.credentialsCipher == nil {
+		if IsEncryptedCredential(value) {
+			return "", fmt.Errorf("the connection settings are encrypted but no credentials key is configured")
+		}
+		return value, nil
+	}
 
-	return result.Error
+	return c.credentialsCipher.Decrypt(value)
 }
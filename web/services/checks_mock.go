@@ -220,6 +220,75 @@ func (_m *MockChecksService) GetChecksResultByCluster(clusterId string) (*models
 	return r0, r1
 }
 
+// GetChecksResultDiffByCluster provides a mock function with given fields: clusterId, previousRunId, currentRunId
+func (_m *MockChecksService) GetChecksResultDiffByCluster(clusterId string, previousRunId int64, currentRunId int64) (*models.ChecksResultDiff, error) {
+	ret := _m.Called(clusterId, previousRunId, currentRunId)
+
+	var r0 *models.ChecksResultDiff
+	if rf, ok := ret.Get(0).(func(string, int64, int64) *models.ChecksResultDiff); ok {
+		r0 = rf(clusterId, previousRunId, currentRunId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ChecksResultDiff)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int64, int64) error); ok {
+		r1 = rf(clusterId, previousRunId, currentRunId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChecksRunsByCluster provides a mock function with given fields: clusterId
+func (_m *MockChecksService) GetChecksRunsByCluster(clusterId string) ([]*models.ChecksRun, error) {
+	ret := _m.Called(clusterId)
+
+	var r0 []*models.ChecksRun
+	if rf, ok := ret.Get(0).(func(string) []*models.ChecksRun); ok {
+		r0 = rf(clusterId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ChecksRun)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(clusterId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetClusterFacts provides a mock function with given fields: clusterId
+func (_m *MockChecksService) GetClusterFacts(clusterId string) ([]*models.DiscoveredFact, error) {
+	ret := _m.Called(clusterId)
+
+	var r0 []*models.DiscoveredFact
+	if rf, ok := ret.Get(0).(func(string) []*models.DiscoveredFact); ok {
+		r0 = rf(clusterId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.DiscoveredFact)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(clusterId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetConnectionSettingsById provides a mock function with given fields: id
 func (_m *MockChecksService) GetConnectionSettingsById(id string) (map[string]models.ConnectionSettings, error) {
 	ret := _m.Called(id)
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+//go:generate mockery --name=ChecksRepository --inpackage --filename=checks_repository_mock.go
+
+// ChecksRepository is the storage of the checks catalog, results and settings
+type ChecksRepository interface {
+	// Checks catalog
+	GetCatalog(includePremium bool) (entities.CheckList, error)
+	SaveCatalogEntry(check *entities.Check) error
+	// ReplaceCatalog saves the given checks and removes the ones not included
+	ReplaceCatalog(checkList entities.CheckList) error
+	// Checks results
+	CreateChecksResult(checksResult *entities.ChecksResult) error
+	// GetLastChecksResult returns gorm.ErrRecordNotFound if the group has no results
+	GetLastChecksResult(groupID string) (*entities.ChecksResult, error)
+	GetLastChecksResultsByGroup() ([]entities.ChecksResult, error)
+	GetChecksResults(groupID string, ids []int64) ([]entities.ChecksResult, error)
+	GetChecksRuns(groupID string) ([]*models.ChecksRun, error)
+	ProjectHealth(groupID string, health string) error
+	// Selected checks
+	GetSelectedChecks(id string) (*models.SelectedChecks, error)
+	SaveSelectedChecks(selectedChecks *models.SelectedChecks) error
+	// Connection settings
+	GetConnectionSettings(id string) ([]models.ConnectionSettings, error)
+	GetConnectionSettingsByNode(node string) (models.ConnectionSettings, error)
+	GetAllConnectionSettings() ([]models.ConnectionSettings, error)
+	SaveConnectionSettings(connectionSettings *models.ConnectionSettings) error
+}
+
+type checksRepository struct {
+	db *gorm.DB
+}
+
+func NewChecksRepository(db *gorm.DB) *checksRepository {
+	return &checksRepository{db: db}
+}
+
+func (r *checksRepository) GetCatalog(includePremium bool) (entities.CheckList, error) {
+	var checksEntity entities.CheckList
+
+	var result *gorm.DB
+	qb := r.db.Order("payload->>'name'")
+
+	if includePremium {
+		result = qb.Find(&checksEntity)
+	} else {
+		result = qb.Find(&checksEntity, datatypes.JSONQuery("payload").Equals(false, "premium"))
+	}
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return checksEntity, nil
+}
+
+func (r *checksRepository) SaveCatalogEntry(check *entities.Check) error {
+	return r.db.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(check).Error
+}
+
+func (r *checksRepository) ReplaceCatalog(checkList entities.CheckList) error {
+	result := r.db.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(&checkList)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// Remove old not updated checks
+	return r.db.Not(&checkList).Delete(entities.CheckList{}).Error
+}
+
+func (r *checksRepository) CreateChecksResult(checksResult *entities.ChecksResult) error {
+	return r.db.Create(checksResult).Error
+}
+
+func (r *checksRepository) GetLastChecksResult(groupID string) (*entities.ChecksResult, error) {
+	var checksResult entities.ChecksResult
+
+	err := r.db.Where("group_id", groupID).Last(&checksResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &checksResult, nil
+}
+
+func (r *checksRepository) GetLastChecksResultsByGroup() ([]entities.ChecksResult, error) {
+	var checksResults []entities.ChecksResult
+
+	err := r.db.Where("(group_id, created_at) IN (?)", r.db.Model(&entities.ChecksResult{}).
+		Select("group_id, max(created_at)").
+		Group("group_id")).Order("id").Find(&checksResults).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return checksResults, nil
+}
+
+func (r *checksRepository) GetChecksResults(groupID string, ids []int64) ([]entities.ChecksResult, error) {
+	var checksResults []entities.ChecksResult
+
+	err := r.db.
+		Where("group_id = ? AND id IN ?", groupID, ids).
+		Find(&checksResults).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return checksResults, nil
+}
+
+func (r *checksRepository) GetChecksRuns(groupID string) ([]*models.ChecksRun, error) {
+	var runs []*models.ChecksRun
+
+	err := r.db.Model(&entities.ChecksResult{}).
+		Select("id, created_at").
+		Where("group_id", groupID).
+		Order("id DESC").
+		Scan(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (r *checksRepository) ProjectHealth(groupID string, health string) error {
+	return datapipeline.ProjectHealth(r.db, groupID, partialChecksHealth, health)
+}
+
+// GetSelectedChecks returns nil if no checks were ever selected
+func (r *checksRepository) GetSelectedChecks(id string) (*models.SelectedChecks, error) {
+	var selectedChecks models.SelectedChecks
+
+	err := r.db.Where("id", id).First(&selectedChecks).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &selectedChecks, nil
+}
+
+func (r *checksRepository) SaveSelectedChecks(selectedChecks *models.SelectedChecks) error {
+	return r.db.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(selectedChecks).Error
+}
+
+func (r *checksRepository) GetConnectionSettings(id string) ([]models.ConnectionSettings, error) {
+	var connUsersList []models.ConnectionSettings
+
+	err := r.db.Where("id", id).Find(&connUsersList).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return connUsersList, nil
+}
+
+func (r *checksRepository) GetConnectionSettingsByNode(node string) (models.ConnectionSettings, error) {
+	var connUser models.ConnectionSettings
+
+	err := r.db.Where("node", node).First(&connUser).Error
+
+	return connUser, err
+}
+
+func (r *checksRepository) GetAllConnectionSettings() ([]models.ConnectionSettings, error) {
+	var connUsersList []models.ConnectionSettings
+
+	err := r.db.Find(&connUsersList).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return connUsersList, nil
+}
+
+func (r *checksRepository) SaveConnectionSettings(connectionSettings *models.ConnectionSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(connectionSettings).Error
+}
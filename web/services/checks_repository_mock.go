@@ -0,0 +1,304 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	entities "github.com/trento-project/trento/web/entities"
+
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockChecksRepository is an autogenerated mock type for the ChecksRepository type
+type MockChecksRepository struct {
+	mock.Mock
+}
+
+// CreateChecksResult provides a mock function with given fields: checksResult
+func (_m *MockChecksRepository) CreateChecksResult(checksResult *entities.ChecksResult) error {
+	ret := _m.Called(checksResult)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*entities.ChecksResult) error); ok {
+		r0 = rf(checksResult)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllConnectionSettings provides a mock function with given fields:
+func (_m *MockChecksRepository) GetAllConnectionSettings() ([]models.ConnectionSettings, error) {
+	ret := _m.Called()
+
+	var r0 []models.ConnectionSettings
+	if rf, ok := ret.Get(0).(func() []models.ConnectionSettings); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ConnectionSettings)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCatalog provides a mock function with given fields: includePremium
+func (_m *MockChecksRepository) GetCatalog(includePremium bool) (entities.CheckList, error) {
+	ret := _m.Called(includePremium)
+
+	var r0 entities.CheckList
+	if rf, ok := ret.Get(0).(func(bool) entities.CheckList); ok {
+		r0 = rf(includePremium)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(entities.CheckList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(bool) error); ok {
+		r1 = rf(includePremium)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChecksResults provides a mock function with given fields: groupID, ids
+func (_m *MockChecksRepository) GetChecksResults(groupID string, ids []int64) ([]entities.ChecksResult, error) {
+	ret := _m.Called(groupID, ids)
+
+	var r0 []entities.ChecksResult
+	if rf, ok := ret.Get(0).(func(string, []int64) []entities.ChecksResult); ok {
+		r0 = rf(groupID, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.ChecksResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []int64) error); ok {
+		r1 = rf(groupID, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChecksRuns provides a mock function with given fields: groupID
+func (_m *MockChecksRepository) GetChecksRuns(groupID string) ([]*models.ChecksRun, error) {
+	ret := _m.Called(groupID)
+
+	var r0 []*models.ChecksRun
+	if rf, ok := ret.Get(0).(func(string) []*models.ChecksRun); ok {
+		r0 = rf(groupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ChecksRun)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(groupID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetConnectionSettings provides a mock function with given fields: id
+func (_m *MockChecksRepository) GetConnectionSettings(id string) ([]models.ConnectionSettings, error) {
+	ret := _m.Called(id)
+
+	var r0 []models.ConnectionSettings
+	if rf, ok := ret.Get(0).(func(string) []models.ConnectionSettings); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ConnectionSettings)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetConnectionSettingsByNode provides a mock function with given fields: node
+func (_m *MockChecksRepository) GetConnectionSettingsByNode(node string) (models.ConnectionSettings, error) {
+	ret := _m.Called(node)
+
+	var r0 models.ConnectionSettings
+	if rf, ok := ret.Get(0).(func(string) models.ConnectionSettings); ok {
+		r0 = rf(node)
+	} else {
+		r0 = ret.Get(0).(models.ConnectionSettings)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(node)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLastChecksResult provides a mock function with given fields: groupID
+func (_m *MockChecksRepository) GetLastChecksResult(groupID string) (*entities.ChecksResult, error) {
+	ret := _m.Called(groupID)
+
+	var r0 *entities.ChecksResult
+	if rf, ok := ret.Get(0).(func(string) *entities.ChecksResult); ok {
+		r0 = rf(groupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entities.ChecksResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(groupID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLastChecksResultsByGroup provides a mock function with given fields:
+func (_m *MockChecksRepository) GetLastChecksResultsByGroup() ([]entities.ChecksResult, error) {
+	ret := _m.Called()
+
+	var r0 []entities.ChecksResult
+	if rf, ok := ret.Get(0).(func() []entities.ChecksResult); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.ChecksResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSelectedChecks provides a mock function with given fields: id
+func (_m *MockChecksRepository) GetSelectedChecks(id string) (*models.SelectedChecks, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.SelectedChecks
+	if rf, ok := ret.Get(0).(func(string) *models.SelectedChecks); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SelectedChecks)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProjectHealth provides a mock function with given fields: groupID, health
+func (_m *MockChecksRepository) ProjectHealth(groupID string, health string) error {
+	ret := _m.Called(groupID, health)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(groupID, health)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceCatalog provides a mock function with given fields: checkList
+func (_m *MockChecksRepository) ReplaceCatalog(checkList entities.CheckList) error {
+	ret := _m.Called(checkList)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entities.CheckList) error); ok {
+		r0 = rf(checkList)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveCatalogEntry provides a mock function with given fields: check
+func (_m *MockChecksRepository) SaveCatalogEntry(check *entities.Check) error {
+	ret := _m.Called(check)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*entities.Check) error); ok {
+		r0 = rf(check)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveConnectionSettings provides a mock function with given fields: connectionSettings
+func (_m *MockChecksRepository) SaveConnectionSettings(connectionSettings *models.ConnectionSettings) error {
+	ret := _m.Called(connectionSettings)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.ConnectionSettings) error); ok {
+		r0 = rf(connectionSettings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveSelectedChecks provides a mock function with given fields: selectedChecks
+func (_m *MockChecksRepository) SaveSelectedChecks(selectedChecks *models.SelectedChecks) error {
+	ret := _m.Called(selectedChecks)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.SelectedChecks) error); ok {
+		r0 = rf(selectedChecks)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/test/helpers"
 
@@ -50,6 +51,37 @@ func TestAggregatedCheckDataString(t *testing.T) {
 
 }
 
+func TestChecksResultDiff(t *testing.T) {
+	previous := &models.ChecksResult{
+		Checks: map[string]*models.ChecksByHost{
+			"check1": {Hosts: map[string]*models.Check{"host1": {Result: models.CheckPassing}, "host2": {Result: models.CheckCritical}}},
+			"check2": {Hosts: map[string]*models.Check{"host1": {Result: models.CheckWarning}, "host2": {Result: models.CheckPassing}}},
+			"check3": {Hosts: map[string]*models.Check{"host1": {Result: models.CheckPassing}}},
+		},
+	}
+	current := &models.ChecksResult{
+		Checks: map[string]*models.ChecksByHost{
+			"check1": {Hosts: map[string]*models.Check{"host1": {Result: models.CheckWarning}, "host2": {Result: models.CheckPassing}}},
+			"check2": {Hosts: map[string]*models.Check{"host1": {Result: models.CheckCritical}, "host2": {Result: models.CheckSkipped}}},
+			"check4": {Hosts: map[string]*models.Check{"host1": {Result: models.CheckCritical}}},
+		},
+	}
+
+	diff := current.Diff(previous)
+
+	assert.Equal(t, []*models.CheckResultChange{
+		{CheckID: "check1", Host: "host1", Previous: models.CheckPassing, Current: models.CheckWarning},
+		{CheckID: "check4", Host: "host1", Previous: "", Current: models.CheckCritical},
+	}, diff.NewlyFailing)
+	assert.Equal(t, []*models.CheckResultChange{
+		{CheckID: "check1", Host: "host2", Previous: models.CheckCritical, Current: models.CheckPassing},
+	}, diff.NewlyPassing)
+	assert.Equal(t, []*models.CheckResultChange{
+		{CheckID: "check2", Host: "host2", Previous: models.CheckPassing, Current: models.CheckSkipped},
+		{CheckID: "check3", Host: "host1", Previous: models.CheckPassing, Current: ""},
+	}, diff.NewlyMuted)
+}
+
 type ChecksServiceTestSuite struct {
 	suite.Suite
 	db               *gorm.DB
@@ -86,7 +118,7 @@ func (suite *ChecksServiceTestSuite) TearDownSuite() {
 
 func (suite *ChecksServiceTestSuite) SetupTest() {
 	suite.tx = suite.db.Begin()
-	suite.checksService = NewChecksService(suite.tx, suite.premiumDetection)
+	suite.checksService = NewChecksService(NewChecksRepository(suite.tx), suite.premiumDetection, nil, DefaultHealthStrategy{})
 }
 
 func (suite *ChecksServiceTestSuite) TearDownTest() {
@@ -338,6 +370,7 @@ func (suite *ChecksServiceTestSuite) TestChecksService_GetChecksResultByCluster(
 	resultsStored.ID = "group1"
 
 	suite.tx.Where("group_id", "group1").Last(&checksResultEntity)
+	resultsStored.CreatedAt = checksResultEntity.CreatedAt
 
 	json.Unmarshal(checksResultEntity.Payload, &resultsStored)
 	suite.NoError(err)
@@ -394,6 +427,7 @@ func (suite *ChecksServiceTestSuite) TestChecksService_CreateChecksResult() {
 	resultsStored.ID = "group1"
 
 	suite.tx.Where("group_id", "group1").Last(&checksResultEntity)
+	resultsStored.CreatedAt = checksResultEntity.CreatedAt
 
 	json.Unmarshal(checksResultEntity.Payload, &resultsStored)
 	suite.NoError(err)
@@ -443,10 +477,53 @@ func (suite *ChecksServiceTestSuite) TestChecksService_GetAggregatedChecksResult
 	suite.Equal(expectedResults, results)
 }
 
+func (suite *ChecksServiceTestSuite) TestChecksService_GetChecksRunsByCluster() {
+	runs, err := suite.checksService.GetChecksRunsByCluster("group1")
+
+	suite.NoError(err)
+	suite.Equal(2, len(runs))
+	suite.Greater(runs[0].ID, runs[1].ID)
+
+	runs, err = suite.checksService.GetChecksRunsByCluster("other")
+
+	suite.NoError(err)
+	suite.Empty(runs)
+}
+
+func (suite *ChecksServiceTestSuite) TestChecksService_GetChecksResultDiffByCluster() {
+	diff, err := suite.checksService.GetChecksResultDiffByCluster("group1", 0, 0)
+
+	suite.NoError(err)
+	suite.Greater(diff.Current.ID, diff.Previous.ID)
+	suite.Empty(diff.NewlyFailing)
+	suite.Empty(diff.NewlyMuted)
+	suite.Equal([]*models.CheckResultChange{
+		{CheckID: "check1", Description: "description1", Host: "host1", Previous: models.CheckCritical, Current: models.CheckPassing},
+		{CheckID: "check1", Description: "description1", Host: "host2", Previous: models.CheckCritical, Current: models.CheckPassing},
+	}, diff.NewlyPassing)
+
+	reversed, err := suite.checksService.GetChecksResultDiffByCluster("group1", diff.Current.ID, diff.Previous.ID)
+
+	suite.NoError(err)
+	suite.Equal(2, len(reversed.NewlyFailing))
+	suite.Empty(reversed.NewlyPassing)
+}
+
+func (suite *ChecksServiceTestSuite) TestChecksService_GetChecksResultDiffByClusterNotFound() {
+	diff, err := suite.checksService.GetChecksResultDiffByCluster("group2", 0, 0)
+
+	suite.NoError(err)
+	suite.Nil(diff)
+}
+
 func (suite *ChecksServiceTestSuite) TestChecksService_GetChecksResultAndMetadataByCluster() {
 	results, err := suite.checksService.GetChecksResultAndMetadataByCluster("group1")
 
+	var checksResultEntity entities.ChecksResult
+	suite.tx.Where("group_id", "group1").Last(&checksResultEntity)
+
 	expectedResults := &models.ChecksResultAsList{
+		UpdatedAt: &checksResultEntity.CreatedAt,
 		Hosts: map[string]*models.HostState{
 			"host1": &models.HostState{
 				Reachable: true,
@@ -600,3 +677,203 @@ func (suite *ChecksServiceTestSuite) TestChecksService_CreateConnectionSettings(
 	suite.NoError(err)
 	suite.Equal(expectedValue, data)
 }
+
+func checksCatalogEntitiesFixture() entities.CheckList {
+	var checkList entities.CheckList
+	for _, check := range []*models.Check{
+		{ID: "check1", Name: "check 1", Description: "description 1"},
+		{ID: "check2", Name: "check 2", Description: "description 2"},
+	} {
+		payload, _ := json.Marshal(check)
+		checkList = append(checkList, &entities.Check{ID: check.ID, Payload: payload})
+	}
+
+	return checkList
+}
+
+func TestChecksService_GetSelectedChecksById(t *testing.T) {
+	premiumDetection := new(MockPremiumDetectionService)
+	premiumDetection.On("IsPremiumActive").Return(false, nil)
+
+	repository := new(MockChecksRepository)
+	repository.On("GetCatalog", false).Return(checksCatalogEntitiesFixture(), nil)
+	repository.On("GetSelectedChecks", "cluster1").Return(&models.SelectedChecks{
+		ID:             "cluster1",
+		SelectedChecks: []string{"check1", "removed"},
+	}, nil)
+	repository.On("GetSelectedChecks", "cluster2").Return(nil, nil)
+
+	checksService := NewChecksService(repository, premiumDetection, nil, DefaultHealthStrategy{})
+
+	selectedChecks, err := checksService.GetSelectedChecksById("cluster1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.SelectedChecks{ID: "cluster1", SelectedChecks: []string{"check1"}}, selectedChecks)
+
+	selectedChecks, err = checksService.GetSelectedChecksById("cluster2")
+	assert.NoError(t, err)
+	assert.Equal(t, models.SelectedChecks{ID: "", SelectedChecks: []string{}}, selectedChecks)
+}
+
+func TestChecksService_CreateChecksResult(t *testing.T) {
+	checksResult := &models.ChecksResult{
+		ID: "cluster1",
+		Checks: map[string]*models.ChecksByHost{
+			"check1": {Hosts: map[string]*models.Check{"host1": {Result: models.CheckWarning}}},
+		},
+	}
+	payload, _ := json.Marshal(checksResult)
+
+	repository := new(MockChecksRepository)
+	repository.On("CreateChecksResult", &entities.ChecksResult{GroupID: "cluster1", Payload: payload}).Return(nil)
+	repository.On("GetLastChecksResult", "cluster1").Return(&entities.ChecksResult{GroupID: "cluster1", Payload: payload}, nil)
+	repository.On("ProjectHealth", "cluster1", models.CheckWarning).Return(nil)
+
+	checksService := NewChecksService(repository, nil, nil, DefaultHealthStrategy{})
+
+	assert.NoError(t, checksService.CreateChecksResult(checksResult))
+	repository.AssertExpectations(t)
+}
+
+func TestChecksService_CreateChecksResultHealthStrategy(t *testing.T) {
+	checksResult := &models.ChecksResult{
+		ID: "cluster1",
+		Checks: map[string]*models.ChecksByHost{
+			"check1": {Hosts: map[string]*models.Check{"host1": {Result: models.CheckWarning}}},
+		},
+	}
+	payload, _ := json.Marshal(checksResult)
+
+	repository := new(MockChecksRepository)
+	repository.On("CreateChecksResult", &entities.ChecksResult{GroupID: "cluster1", Payload: payload}).Return(nil)
+	repository.On("GetLastChecksResult", "cluster1").Return(&entities.ChecksResult{GroupID: "cluster1", Payload: payload}, nil)
+	repository.On("ProjectHealth", "cluster1", models.CheckCritical).Return(nil)
+
+	healthStrategy := new(MockHealthStrategy)
+	healthStrategy.On("ChecksHealth", &models.AggregatedCheckData{WarningCount: 1}).Return(models.CheckCritical)
+
+	checksService := NewChecksService(repository, nil, nil, healthStrategy)
+
+	assert.NoError(t, checksService.CreateChecksResult(checksResult))
+	repository.AssertExpectations(t)
+	healthStrategy.AssertExpectations(t)
+}
+
+func TestChecksService_GetClusterFacts(t *testing.T) {
+	var catalog entities.CheckList
+	for _, check := range []*models.Check{
+		{ID: "check1", Facts: []*models.CheckFact{{Name: "corosync_token", Description: "Corosync token"}}},
+		{ID: "check2", Facts: []*models.CheckFact{{Name: "sbd_devices"}}},
+		{ID: "check3"},
+	} {
+		payload, _ := json.Marshal(check)
+		catalog = append(catalog, &entities.Check{ID: check.ID, Payload: payload})
+	}
+
+	payload, _ := json.Marshal(&models.ChecksResult{
+		Checks: map[string]*models.ChecksByHost{
+			"check1": {Hosts: map[string]*models.Check{
+				"host2": {Result: models.CheckPassing, FactValues: map[string]string{"corosync_token": "30000"}},
+				"host1": {Result: models.CheckCritical, FactValues: map[string]string{"corosync_token": "5000"}},
+			}},
+			"check2": {Hosts: map[string]*models.Check{
+				"host1": {Result: models.CheckWarning},
+			}},
+			"check3": {Hosts: map[string]*models.Check{
+				"host1": {Result: models.CheckPassing, FactValues: map[string]string{"undeclared": "value"}},
+			}},
+			"removed": {Hosts: map[string]*models.Check{
+				"host1": {Result: models.CheckPassing, FactValues: map[string]string{"corosync_token": "1"}},
+			}},
+		},
+	})
+
+	premiumDetection := new(MockPremiumDetectionService)
+	premiumDetection.On("IsPremiumActive").Return(false, nil)
+
+	repository := new(MockChecksRepository)
+	repository.On("GetCatalog", false).Return(catalog, nil)
+	repository.On("GetLastChecksResult", "cluster1").Return(&entities.ChecksResult{GroupID: "cluster1", Payload: payload}, nil)
+	repository.On("GetLastChecksResult", "cluster2").Return(nil, gorm.ErrRecordNotFound)
+
+	checksService := NewChecksService(repository, premiumDetection, nil, DefaultHealthStrategy{})
+
+	facts, err := checksService.GetClusterFacts("cluster1")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.DiscoveredFact{
+		{CheckID: "check1", Name: "corosync_token", Description: "Corosync token", Host: "host1", Value: "5000"},
+		{CheckID: "check1", Name: "corosync_token", Description: "Corosync token", Host: "host2", Value: "30000"},
+	}, facts)
+
+	facts, err = checksService.GetClusterFacts("cluster2")
+	assert.NoError(t, err)
+	assert.Empty(t, facts)
+}
+
+func TestChecksService_GetChecksResultDiffByClusterNotEnoughRuns(t *testing.T) {
+	repository := new(MockChecksRepository)
+	repository.On("GetChecksRuns", "cluster1").Return([]*models.ChecksRun{{ID: 1}}, nil)
+
+	checksService := NewChecksService(repository, nil, nil, DefaultHealthStrategy{})
+	diff, err := checksService.GetChecksResultDiffByCluster("cluster1", 0, 0)
+
+	assert.NoError(t, err)
+	assert.Nil(t, diff)
+	repository.AssertNotCalled(t, "GetChecksResults", mock.Anything, mock.Anything)
+}
+
+func TestChecksService_ConnectionSettingsEncryption(t *testing.T) {
+	envelopeCipher, _ := NewEnvelopeCipher(credentialsKeyFixture(1))
+
+	var stored models.ConnectionSettings
+	repository := new(MockChecksRepository)
+	repository.On("SaveConnectionSettings", mock.AnythingOfType("*models.ConnectionSettings")).Run(func(args mock.Arguments) {
+		stored = *args.Get(0).(*models.ConnectionSettings)
+	}).Return(nil)
+
+	checksService := NewChecksService(repository, nil, envelopeCipher, DefaultHealthStrategy{})
+
+	assert.NoError(t, checksService.CreateConnectionSettings("cluster1", "node1", "hacluster"))
+	assert.True(t, IsEncryptedCredential(stored.User))
+
+	repository.On("GetConnectionSettingsByNode", "node1").Return(stored, nil)
+	repository.On("GetConnectionSettings", "cluster1").Return([]models.ConnectionSettings{
+		stored,
+		{ID: "cluster1", Node: "node2", User: "root"},
+	}, nil)
+
+	connUser, err := checksService.GetConnectionSettingsByNode("node1")
+	assert.NoError(t, err)
+	assert.Equal(t, "hacluster", connUser.User)
+
+	connUsers, err := checksService.GetConnectionSettingsById("cluster1")
+	assert.NoError(t, err)
+	assert.Equal(t, "hacluster", connUsers["node1"].User)
+	assert.Equal(t, "root", connUsers["node2"].User)
+
+	_, err = NewChecksService(repository, nil, nil, DefaultHealthStrategy{}).GetConnectionSettingsByNode("node1")
+	assert.Error(t, err)
+}
+
+func TestChecksService_EncryptConnectionSettings(t *testing.T) {
+	envelopeCipher, _ := NewEnvelopeCipher(credentialsKeyFixture(1))
+	encrypted, _ := envelopeCipher.Encrypt("hacluster")
+
+	repository := new(MockChecksRepository)
+	repository.On("GetAllConnectionSettings").Return([]models.ConnectionSettings{
+		{ID: "cluster1", Node: "node1", User: encrypted},
+		{ID: "cluster1", Node: "node2", User: "root"},
+	}, nil)
+	repository.On("SaveConnectionSettings", mock.MatchedBy(func(c *models.ConnectionSettings) bool {
+		user, err := envelopeCipher.Decrypt(c.User)
+		return c.Node == "node2" && IsEncryptedCredential(c.User) && err == nil && user == "root"
+	})).Return(nil).Once()
+
+	count, err := NewChecksService(repository, nil, envelopeCipher, DefaultHealthStrategy{}).EncryptConnectionSettings()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	repository.AssertExpectations(t)
+
+	count, err = NewChecksService(repository, nil, nil, DefaultHealthStrategy{}).EncryptConnectionSettings()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
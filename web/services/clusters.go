@@ -2,14 +2,11 @@ package services
 
 import (
 	"encoding/json"
-	"errors"
 
-	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/internal/cloud"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
-	"gorm.io/gorm"
 )
 
 //go:generate mockery --name=ClustersService --inpackage --filename=clusters_mock.go
@@ -22,7 +19,8 @@ type ClustersService interface {
 	GetAllClusterTypes() ([]string, error)
 	GetAllSIDs() ([]string, error)
 	GetAllTags() ([]string, error)
-	GetAllClustersSettings() (models.ClustersSettings, error)
+	GetAllTemplateVersions() ([]string, error)
+	GetAllClustersSettings(organization string) (models.ClustersSettings, error)
 	GetClusterSettingsByID(id string) (*models.ClusterSettings, error)
 }
 
@@ -33,59 +31,30 @@ type ClustersFilter struct {
 	SIDs        []string
 	Tags        []string
 	Health      []string
+	// TemplateVersions are the versions of the template the clusters were provisioned from
+	TemplateVersions []string
+	// Pinned clusters are listed before the others
+	Pinned []string
+	// Organization the clusters belong to, all of them if empty
+	Organization string
 }
 
 type clustersService struct {
-	db            *gorm.DB
-	checksService ChecksService
+	repository     ClustersRepository
+	checksService  ChecksService
+	healthStrategy HealthStrategy
 }
 
-func NewClustersService(db *gorm.DB, checksService ChecksService) *clustersService {
+func NewClustersService(repository ClustersRepository, checksService ChecksService, healthStrategy HealthStrategy) *clustersService {
 	return &clustersService{
-		db:            db,
-		checksService: checksService,
+		repository:     repository,
+		checksService:  checksService,
+		healthStrategy: healthStrategy,
 	}
 }
 
 func (s *clustersService) GetAll(filter *ClustersFilter, page *Page) (models.ClusterList, error) {
-	var clusters []entities.Cluster
-
-	db := s.db.Preload("Health").Preload("Tags").Scopes(Paginate(page))
-
-	if filter != nil {
-		if len(filter.ID) > 0 {
-			db = db.Where("id IN (?)", filter.ID)
-		}
-
-		if len(filter.Name) > 0 {
-			db = db.Where("name IN (?)", filter.Name)
-		}
-
-		if len(filter.ClusterType) > 0 {
-			db = db.Where("cluster_type IN (?)", filter.ClusterType)
-		}
-
-		if len(filter.SIDs) > 0 {
-			db = db.Where("sid IN (?)", filter.SIDs)
-		}
-
-		if len(filter.Tags) > 0 {
-			db = db.Where("id IN (?)", s.db.Model(&models.Tag{}).
-				Select("resource_id").
-				Where("resource_type = ?", models.TagClusterResourceType).
-				Where("value IN ?", filter.Tags),
-			)
-		}
-
-		if len(filter.Health) > 0 {
-			db = db.Where("id IN (?)", s.db.Model(&entities.HealthState{}).
-				Select("id").
-				Where("health IN ?", filter.Health),
-			)
-		}
-	}
-
-	err := db.Order("name").Order("id").Find(&clusters).Error
+	clusters, err := s.repository.GetAll(filter, page)
 	if err != nil {
 		return nil, err
 	}
@@ -101,16 +70,8 @@ func (s *clustersService) GetAll(filter *ClustersFilter, page *Page) (models.Clu
 }
 
 func (s *clustersService) GetByID(clusterID string) (*models.Cluster, error) {
-	var cluster entities.Cluster
-
-	err := s.db.
-		Preload("Hosts").
-		Where("id = ?", clusterID).First(&cluster).Error
-
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
+	cluster, err := s.repository.GetByID(clusterID)
+	if err != nil || cluster == nil {
 		return nil, err
 	}
 
@@ -137,86 +98,32 @@ func (s *clustersService) GetByID(clusterID string) (*models.Cluster, error) {
 }
 
 func (s *clustersService) GetCount() (int, error) {
-	var count int64
-	err := s.db.Model(&entities.Cluster{}).Count(&count).Error
-
-	return int(count), err
+	return s.repository.GetCount()
 }
 
 func (s *clustersService) GetAllClusterNames() ([]string, error) {
-	var clusterNames []string
-
-	err := s.db.Model(&entities.Cluster{}).
-		Distinct().
-		Order("name").
-		Pluck("name", &clusterNames).
-		Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	return clusterNames, nil
+	return s.repository.GetAllClusterNames()
 }
 
 func (s *clustersService) GetAllClusterTypes() ([]string, error) {
-	var clusterTypes []string
-
-	err := s.db.Model(&entities.Cluster{}).
-		Distinct().
-		Pluck("cluster_type", &clusterTypes).
-		Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	return clusterTypes, nil
+	return s.repository.GetAllClusterTypes()
 }
 
 func (s *clustersService) GetAllSIDs() ([]string, error) {
-	var sids pq.StringArray
-
-	err := s.db.Model(&entities.Cluster{}).
-		Distinct().
-		Where("sid IS NOT NULL AND sid <> ''").
-		Order("sid").
-		Pluck("sid", &sids).
-		Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	return []string(sids), nil
+	return s.repository.GetAllSIDs()
 }
 
 func (s *clustersService) GetAllTags() ([]string, error) {
-	var tags []string
-
-	err := s.db.
-		Model(&models.Tag{}).
-		Where("resource_type = ?", models.TagClusterResourceType).
-		Distinct().
-		Pluck("value", &tags).
-		Error
-
-	if err != nil {
-		return nil, err
-
-	}
-
-	return tags, nil
+	return s.repository.GetAllTags()
 }
 
-func (s *clustersService) GetAllClustersSettings() (models.ClustersSettings, error) {
-	var clusters []*entities.Cluster
-
-	err := s.db.
-		Preload("Hosts").
-		Find(&clusters).
-		Error
+func (s *clustersService) GetAllTemplateVersions() ([]string, error) {
+	return s.repository.GetAllTemplateVersions()
+}
 
+// GetAllClustersSettings returns the settings of the clusters of the organization, of all of them if empty
+func (s *clustersService) GetAllClustersSettings(organization string) (models.ClustersSettings, error) {
+	clusters, err := s.repository.GetAllWithHosts(organization)
 	if err != nil {
 		return nil, err
 	}
@@ -249,22 +156,12 @@ func getDefaultUserName(host *entities.Host) (string, error) {
 	}
 }
 func (s *clustersService) GetClusterSettingsByID(id string) (*models.ClusterSettings, error) {
-	var cluster entities.Cluster
-
-	err := s.db.
-		Preload("Hosts").
-		Where("id = ?", id).
-		First(&cluster).
-		Error
-
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
+	cluster, err := s.repository.GetByID(id)
+	if err != nil || cluster == nil {
 		return nil, err
 	}
 
-	return s.loadSettings(&cluster)
+	return s.loadSettings(cluster)
 }
 
 func (s *clustersService) loadSettings(cluster *entities.Cluster) (*models.ClusterSettings, error) {
@@ -298,9 +195,10 @@ func (s *clustersService) loadSettings(cluster *entities.Cluster) (*models.Clust
 		}
 
 		hosts = append(hosts, &models.HostConnection{
-			Name:    host.Name,
-			Address: host.SSHAddress,
-			User:    username,
+			Name:          host.Name,
+			Address:       host.SSHAddress,
+			User:          username,
+			CloudProvider: host.CloudProvider,
 		})
 	}
 
@@ -353,7 +251,7 @@ func (s *clustersService) enrichClusterNodes(nodes []*models.HANAClusterNode, cl
 		}
 
 		if _, ok := checkResults[node.Name]; ok {
-			node.Health = checkResults[node.Name].String()
+			node.Health = s.healthStrategy.ChecksHealth(checkResults[node.Name])
 		}
 	}
 }
@@ -81,13 +81,13 @@ func (_m *MockClustersService) GetAllClusterTypes() ([]string, error) {
 	return r0, r1
 }
 
-// GetAllClustersSettings provides a mock function with given fields:
-func (_m *MockClustersService) GetAllClustersSettings() (models.ClustersSettings, error) {
-	ret := _m.Called()
+// GetAllClustersSettings provides a mock function with given fields: organization
+func (_m *MockClustersService) GetAllClustersSettings(organization string) (models.ClustersSettings, error) {
+	ret := _m.Called(organization)
 
 	var r0 models.ClustersSettings
-	if rf, ok := ret.Get(0).(func() models.ClustersSettings); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(string) models.ClustersSettings); ok {
+		r0 = rf(organization)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(models.ClustersSettings)
@@ -95,8 +95,8 @@ func (_m *MockClustersService) GetAllClustersSettings() (models.ClustersSettings
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(organization)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -150,6 +150,29 @@ func (_m *MockClustersService) GetAllTags() ([]string, error) {
 	return r0, r1
 }
 
+// GetAllTemplateVersions provides a mock function with given fields:
+func (_m *MockClustersService) GetAllTemplateVersions() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: _a0
 func (_m *MockClustersService) GetByID(_a0 string) (*models.Cluster, error) {
 	ret := _m.Called(_a0)
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=ClustersRepository --inpackage --filename=clusters_repository_mock.go
+
+// ClustersRepository is the storage of the clusters aggregate
+type ClustersRepository interface {
+	GetAll(*ClustersFilter, *Page) ([]entities.Cluster, error)
+	GetAllWithHosts(organization string) ([]*entities.Cluster, error)
+	GetByID(string) (*entities.Cluster, error)
+	GetCount() (int, error)
+	GetAllClusterNames() ([]string, error)
+	GetAllClusterTypes() ([]string, error)
+	GetAllSIDs() ([]string, error)
+	GetAllTags() ([]string, error)
+	GetAllTemplateVersions() ([]string, error)
+}
+
+type clustersRepository struct {
+	db *gorm.DB
+}
+
+func NewClustersRepository(db *gorm.DB) *clustersRepository {
+	return &clustersRepository{db: db}
+}
+
+func (r *clustersRepository) GetAll(filter *ClustersFilter, page *Page) ([]entities.Cluster, error) {
+	var clusters []entities.Cluster
+
+	var pinned []string
+	if filter != nil {
+		pinned = filter.Pinned
+	}
+
+	db := r.db.Preload("Health").Preload("Tags").Scopes(Paginate(page), OrderPinnedFirst("id", pinned, "name, id"))
+
+	if filter != nil {
+		if len(filter.ID) > 0 {
+			db = db.Where("id IN (?)", filter.ID)
+		}
+
+		if len(filter.Name) > 0 {
+			db = db.Where("name IN (?)", filter.Name)
+		}
+
+		if len(filter.ClusterType) > 0 {
+			db = db.Where("cluster_type IN (?)", filter.ClusterType)
+		}
+
+		if len(filter.SIDs) > 0 {
+			db = db.Where("sid IN (?)", filter.SIDs)
+		}
+
+		if len(filter.Tags) > 0 {
+			db = db.Where("id IN (?)", r.db.Model(&models.Tag{}).
+				Select("resource_id").
+				Where("resource_type = ?", models.TagClusterResourceType).
+				Where("value IN ?", filter.Tags),
+			)
+		}
+
+		if len(filter.TemplateVersions) > 0 {
+			db = db.Where("provisioning_template_version IN ?", filter.TemplateVersions)
+		}
+
+		if len(filter.Health) > 0 {
+			db = db.Where("id IN (?)", r.db.Model(&entities.HealthState{}).
+				Select("id").
+				Where("health IN ?", filter.Health),
+			)
+		}
+
+		db = db.Scopes(organizationScope(filter.Organization, "organization"))
+	}
+
+	err := db.Find(&clusters).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+// GetAllWithHosts returns the clusters of the organization with their hosts, all of them if empty
+func (r *clustersRepository) GetAllWithHosts(organization string) ([]*entities.Cluster, error) {
+	var clusters []*entities.Cluster
+
+	err := r.db.
+		Preload("Hosts").
+		Scopes(organizationScope(organization, "organization")).
+		Find(&clusters).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+// GetByID returns the cluster with its hosts, nil if it does not exist
+func (r *clustersRepository) GetByID(id string) (*entities.Cluster, error) {
+	var cluster entities.Cluster
+
+	err := r.db.
+		Preload("Hosts").
+		Where("id = ?", id).
+		First(&cluster).
+		Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &cluster, nil
+}
+
+func (r *clustersRepository) GetCount() (int, error) {
+	var count int64
+	err := r.db.Model(&entities.Cluster{}).Count(&count).Error
+
+	return int(count), err
+}
+
+func (r *clustersRepository) GetAllClusterNames() ([]string, error) {
+	var clusterNames []string
+
+	err := r.db.Model(&entities.Cluster{}).
+		Distinct().
+		Order("name").
+		Pluck("name", &clusterNames).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterNames, nil
+}
+
+func (r *clustersRepository) GetAllClusterTypes() ([]string, error) {
+	var clusterTypes []string
+
+	err := r.db.Model(&entities.Cluster{}).
+		Distinct().
+		Pluck("cluster_type", &clusterTypes).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterTypes, nil
+}
+
+func (r *clustersRepository) GetAllSIDs() ([]string, error) {
+	var sids pq.StringArray
+
+	err := r.db.Model(&entities.Cluster{}).
+		Distinct().
+		Where("sid IS NOT NULL AND sid <> ''").
+		Order("sid").
+		Pluck("sid", &sids).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []string(sids), nil
+}
+
+func (r *clustersRepository) GetAllTags() ([]string, error) {
+	var tags []string
+
+	err := r.db.
+		Model(&models.Tag{}).
+		Where("resource_type = ?", models.TagClusterResourceType).
+		Distinct().
+		Pluck("value", &tags).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *clustersRepository) GetAllTemplateVersions() ([]string, error) {
+	var versions []string
+
+	err := r.db.Model(&entities.Cluster{}).
+		Distinct().
+		Where("provisioning_template_version <> ''").
+		Order("provisioning_template_version").
+		Pluck("provisioning_template_version", &versions).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
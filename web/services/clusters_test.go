@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
@@ -44,7 +46,7 @@ func (suite *ClustersServiceTestSuite) TearDownSuite() {
 func (suite *ClustersServiceTestSuite) SetupTest() {
 	suite.tx = suite.db.Begin()
 	suite.checksService = new(MockChecksService)
-	suite.clustersService = NewClustersService(suite.tx, suite.checksService)
+	suite.clustersService = NewClustersService(NewClustersRepository(suite.tx), suite.checksService, DefaultHealthStrategy{})
 }
 
 func (suite *ClustersServiceTestSuite) TearDownTest() {
@@ -331,10 +333,10 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetAllClustersSetting
 	mockPremiumDetection := new(MockPremiumDetectionService)
 
 	tx := suite.tx.Raw("TRUNCATE TABLE clusters")
-	checksService := NewChecksService(tx, mockPremiumDetection)
-	suite.clustersService = NewClustersService(tx, checksService)
+	checksService := NewChecksService(NewChecksRepository(tx), mockPremiumDetection, nil, DefaultHealthStrategy{})
+	suite.clustersService = NewClustersService(NewClustersRepository(tx), checksService, DefaultHealthStrategy{})
 
-	clustersSettings, err := suite.clustersService.GetAllClustersSettings()
+	clustersSettings, err := suite.clustersService.GetAllClustersSettings("")
 	suite.NoError(err)
 	suite.Empty(clustersSettings)
 
@@ -377,7 +379,7 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetAllClustersSetting
 		},
 	}, nil)
 
-	clustersSettings, err := suite.clustersService.GetAllClustersSettings()
+	clustersSettings, err := suite.clustersService.GetAllClustersSettings("")
 	suite.NoError(err)
 	suite.NotEmpty(clustersSettings)
 	suite.Len(clustersSettings, 3)
@@ -410,15 +412,31 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetAllClustersSetting
 			SelectedChecks: []string{},
 			Hosts: []*models.HostConnection{
 				{
-					Name:    "host3",
-					Address: "10.74.2.12",
-					User:    "cloudadmin",
+					Name:          "host3",
+					Address:       "10.74.2.12",
+					User:          "cloudadmin",
+					CloudProvider: "azure",
 				},
 			},
 		},
 	}, clustersSettings)
 }
 
+func (suite *ClustersServiceTestSuite) TestClustersService_GetAllClustersSettingsOrganization() {
+	suite.tx.Model(&entities.Cluster{}).Where("id = ?", "2").Update("organization", "acme")
+
+	suite.checksService.On("GetSelectedChecksById", "2").Return(models.SelectedChecks{
+		ID:             "2",
+		SelectedChecks: []string{},
+	}, nil)
+	suite.checksService.On("GetConnectionSettingsById", "2").Return(map[string]models.ConnectionSettings{}, nil)
+
+	clustersSettings, err := suite.clustersService.GetAllClustersSettings("acme")
+	suite.NoError(err)
+	suite.Len(clustersSettings, 1)
+	suite.Equal("2", clustersSettings[0].ID)
+}
+
 func (suite *ClustersServiceTestSuite) TestClustersService_GetClusterSettings() {
 	suite.checksService.On("GetSelectedChecksById", "1").Return(models.SelectedChecks{
 		ID:             "1",
@@ -451,3 +469,96 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetClusterSettings_No
 	suite.NoError(err)
 	suite.Nil(clusterSettings)
 }
+
+func TestClustersService_GetByID(t *testing.T) {
+	details, _ := json.Marshal(&entities.HANAClusterDetails{
+		Nodes: []*entities.HANAClusterNode{{Name: "host1"}, {Name: "host2"}},
+	})
+
+	repository := new(MockClustersRepository)
+	repository.On("GetByID", "1").Return(&entities.Cluster{
+		ID:          "1",
+		Name:        "cluster1",
+		ClusterType: models.ClusterTypeHANAScaleUp,
+		Details:     details,
+		Hosts: []*entities.Host{
+			{AgentID: "agent1", Name: "host1", IPAddresses: pq.StringArray{"10.74.1.10"}},
+			{AgentID: "agent2", Name: "host2", IPAddresses: pq.StringArray{"10.74.1.11"}},
+		},
+	}, nil)
+
+	checksService := new(MockChecksService)
+	checksService.On("GetAggregatedChecksResultByHost", "1").Return(map[string]*models.AggregatedCheckData{
+		"host1": {CriticalCount: 1},
+	}, nil)
+	checksService.On("GetAggregatedChecksResultByCluster", "1").Return(&models.AggregatedCheckData{
+		PassingCount:  2,
+		CriticalCount: 1,
+	}, nil)
+
+	clustersService := NewClustersService(repository, checksService, DefaultHealthStrategy{})
+	cluster, err := clustersService.GetByID("1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cluster.PassingCount)
+	assert.Equal(t, 1, cluster.CriticalCount)
+
+	nodes := cluster.Details.(*models.HANAClusterDetails).Nodes
+	assert.Equal(t, "agent1", nodes[0].HostID)
+	assert.Equal(t, []string{"10.74.1.10"}, nodes[0].IPAddresses)
+	assert.Equal(t, models.CheckCritical, nodes[0].Health)
+	assert.Equal(t, "agent2", nodes[1].HostID)
+	assert.Equal(t, models.CheckUndefined, nodes[1].Health)
+}
+
+func TestClustersService_GetByIDHealthStrategy(t *testing.T) {
+	details, _ := json.Marshal(&entities.HANAClusterDetails{
+		Nodes: []*entities.HANAClusterNode{{Name: "host1"}},
+	})
+
+	repository := new(MockClustersRepository)
+	repository.On("GetByID", "1").Return(&entities.Cluster{
+		ID:          "1",
+		ClusterType: models.ClusterTypeHANAScaleUp,
+		Details:     details,
+		Hosts:       []*entities.Host{{AgentID: "agent1", Name: "host1"}},
+	}, nil)
+
+	checksService := new(MockChecksService)
+	checksService.On("GetAggregatedChecksResultByHost", "1").Return(map[string]*models.AggregatedCheckData{
+		"host1": {WarningCount: 3},
+	}, nil)
+	checksService.On("GetAggregatedChecksResultByCluster", "1").Return(&models.AggregatedCheckData{
+		WarningCount: 3,
+	}, nil)
+
+	healthStrategy := new(MockHealthStrategy)
+	healthStrategy.On("ChecksHealth", &models.AggregatedCheckData{WarningCount: 3}).Return(models.CheckCritical)
+
+	clustersService := NewClustersService(repository, checksService, healthStrategy)
+	cluster, err := clustersService.GetByID("1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.CheckCritical, cluster.Details.(*models.HANAClusterDetails).Nodes[0].Health)
+	healthStrategy.AssertExpectations(t)
+}
+
+func TestClustersService_GetAllDuplicatedNames(t *testing.T) {
+	repository := new(MockClustersRepository)
+	repository.On("GetAll", (*ClustersFilter)(nil), (*Page)(nil)).Return([]entities.Cluster{
+		{ID: "1", Name: "cluster"},
+		{ID: "2", Name: "cluster"},
+		{ID: "3", Name: "other"},
+	}, nil)
+
+	checksService := new(MockChecksService)
+	checksService.On("GetAggregatedChecksResultByCluster", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+	clustersService := NewClustersService(repository, checksService, DefaultHealthStrategy{})
+	clusters, err := clustersService.GetAll(nil, nil)
+
+	assert.NoError(t, err)
+	assert.True(t, clusters[0].HasDuplicatedName)
+	assert.True(t, clusters[1].HasDuplicatedName)
+	assert.False(t, clusters[2].HasDuplicatedName)
+}
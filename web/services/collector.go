@@ -1,29 +1,567 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal/delta"
 	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxRejectedPayloads are kept, the oldest ones are dropped first
+	maxRejectedPayloads = 500
+	// rejectedPayloadsRetention after which the rejected payloads are dropped
+	rejectedPayloadsRetention = 7 * 24 * time.Hour
+	// payloadDedupRefresh is the time after which an unchanged payload is stored and projected again,
+	// for the read models to recover from the changes not coming from the agents
+	payloadDedupRefresh = time.Hour
+	// ingestionUsageRetention after which the daily ingestion usage of the agents is dropped
+	ingestionUsageRetention = 7 * 24 * time.Hour
 )
 
+const (
+	IngestionQuotaEvents = "events"
+	IngestionQuotaBytes  = "bytes"
+)
+
+// ErrDeltaBaseMismatch is returned when the payload a delta was computed from is not the last one stored
+// of the agent discovery, the agent is to send the full payload instead
+var ErrDeltaBaseMismatch = errors.New("the delta payload is not based on the last payload stored, the full payload is required")
+
+// IngestionQuota bounds the events, and their payload bytes, stored every day, UTC, for each agent,
+// so that a single agent does not fill the events table. The unchanged payloads, not stored, do not count.
+// A limit is disabled if 0
+type IngestionQuota struct {
+	DailyEvents int64
+	DailyBytes  int64
+}
+
+// IngestionQuotaExceededError is returned when storing the events would exceed the daily quota of their agent,
+// none of them being stored
+type IngestionQuotaExceededError struct {
+	AgentID string
+	// Quota exceeded, IngestionQuotaEvents or IngestionQuotaBytes
+	Quota string
+	// ResetAt is when the agent can send data again, the start of the next day
+	ResetAt time.Time
+}
+
+func (e *IngestionQuotaExceededError) Error() string {
+	return fmt.Sprintf("agent %s exceeded its daily quota of %s, until %s", e.AgentID, e.Quota, e.ResetAt.Format(time.RFC3339))
+}
+
 //go:generate mockery --name=CollectorService --inpackage --filename=collector_mock.go
+
+// CollectorService stores the collected events once their payload is validated against the JSON schema
+// of their discovery. The invalid payloads are recorded and a datapipeline.PayloadValidationError is returned.
+// The payloads identical to the last one stored of the agent discovery are neither stored nor projected,
+// only the time they were last seen is recorded.
+// The delta payloads are applied to the last payload stored of the agent discovery before the validation,
+// ErrDeltaBaseMismatch is returned if they were computed from another one.
+// An IngestionQuotaExceededError is returned once an agent exceeds its daily quota
 type CollectorService interface {
 	StoreEvent(dataCollected *datapipeline.DataCollectedEvent) error
+	// StorePendingEvent stores the event without projecting it, as its agent is pending approval
+	StorePendingEvent(dataCollected *datapipeline.DataCollectedEvent) error
+	// StoreEvents stores the events in one transaction, then projects them in order
+	StoreEvents(dataCollected []*datapipeline.DataCollectedEvent) error
+	// StorePendingEvents stores the events in one transaction without projecting them
+	StorePendingEvents(dataCollected []*datapipeline.DataCollectedEvent) error
+	// BackfillEvents stores the full payloads collected while the agent was offline, in one transaction
+	// and ordered by the time they were collected, then projects them in this order.
+	// The payloads are not compared to the last one stored, nor are they the base of the delta payloads
+	BackfillEvents(dataCollected []*datapipeline.DataCollectedEvent) error
+	// BackfillPendingEvents stores the backfilled events without projecting them
+	BackfillPendingEvents(dataCollected []*datapipeline.DataCollectedEvent) error
+	GetPipelineStatus() (*models.PipelineStatus, error)
+	GetProjectorsStatus() ([]*models.ProjectorStatus, error)
+	// GetRejectedPayloads returns the payloads rejected as invalid, the most recent first, optionally of a single agent.
+	// Only the payloads of the agents of the organization are returned unless empty
+	GetRejectedPayloads(agentID string, organization string) ([]*models.RejectedPayload, error)
+	// QueueDepth returns the number of events stored, waiting to be projected
+	QueueDepth() int
+	// PruneEvents removes the events stored before the retention, but the latest one of every agent discovery,
+	// which the read models are projected again from. It returns the number of events removed
+	PruneEvents(retention time.Duration) (int64, error)
 }
 
 type collectorService struct {
 	db                *gorm.DB
 	projectorsChannel chan *datapipeline.DataCollectedEvent
+	quota             IngestionQuota
 }
 
-func NewCollectorService(db *gorm.DB, projectorsChannel chan *datapipeline.DataCollectedEvent) *collectorService {
-	return &collectorService{db: db, projectorsChannel: projectorsChannel}
+func NewCollectorService(db *gorm.DB, projectorsChannel chan *datapipeline.DataCollectedEvent, quota IngestionQuota) *collectorService {
+	return &collectorService{db: db, projectorsChannel: projectorsChannel, quota: quota}
 }
 
 func (c *collectorService) StoreEvent(collectedData *datapipeline.DataCollectedEvent) error {
-	if err := c.db.Create(collectedData).Error; err != nil {
+	if err := c.reconstruct([]*datapipeline.DataCollectedEvent{collectedData}); err != nil {
+		return err
+	}
+
+	if err := c.validate(collectedData); err != nil {
+		return err
+	}
+
+	stored, err := c.store([]*datapipeline.DataCollectedEvent{collectedData}, false)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range stored {
+		c.projectorsChannel <- event
+	}
+
+	return nil
+}
+
+func (c *collectorService) StorePendingEvent(collectedData *datapipeline.DataCollectedEvent) error {
+	if err := c.reconstruct([]*datapipeline.DataCollectedEvent{collectedData}); err != nil {
+		return err
+	}
+
+	if err := c.validate(collectedData); err != nil {
+		return err
+	}
+
+	_, err := c.store([]*datapipeline.DataCollectedEvent{collectedData}, false)
+	return err
+}
+
+func (c *collectorService) StoreEvents(collectedData []*datapipeline.DataCollectedEvent) error {
+	stored, err := c.storeEvents(collectedData)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range stored {
+		c.projectorsChannel <- event
+	}
+
+	return nil
+}
+
+func (c *collectorService) StorePendingEvents(collectedData []*datapipeline.DataCollectedEvent) error {
+	_, err := c.storeEvents(collectedData)
+	return err
+}
+
+func (c *collectorService) storeEvents(collectedData []*datapipeline.DataCollectedEvent) ([]*datapipeline.DataCollectedEvent, error) {
+	if err := c.reconstruct(collectedData); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateAll(collectedData); err != nil {
+		return nil, err
+	}
+
+	return c.store(collectedData, false)
+}
+
+func (c *collectorService) BackfillEvents(collectedData []*datapipeline.DataCollectedEvent) error {
+	stored, err := c.backfill(collectedData)
+	if err != nil {
 		return err
 	}
-	c.projectorsChannel <- collectedData
+
+	for _, event := range stored {
+		c.projectorsChannel <- event
+	}
+
+	return nil
+}
+
+func (c *collectorService) BackfillPendingEvents(collectedData []*datapipeline.DataCollectedEvent) error {
+	_, err := c.backfill(collectedData)
+	return err
+}
+
+func (c *collectorService) backfill(collectedData []*datapipeline.DataCollectedEvent) ([]*datapipeline.DataCollectedEvent, error) {
+	if err := c.validateAll(collectedData); err != nil {
+		return nil, err
+	}
+
+	sorted := make([]*datapipeline.DataCollectedEvent, len(collectedData))
+	copy(sorted, collectedData)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CollectedAt.Before(sorted[j].CollectedAt)
+	})
+
+	return c.store(sorted, true)
+}
+
+// validateAll validates every event, none of the events is stored if any of them is invalid,
+// all the invalid ones are recorded
+func (c *collectorService) validateAll(collectedData []*datapipeline.DataCollectedEvent) error {
+	var validationErr error
+	for i, event := range collectedData {
+		if err := c.validate(event); err != nil && validationErr == nil {
+			validationErr = fmt.Errorf("event %d: %w", i, err)
+		}
+	}
+
+	return validationErr
+}
+
+// store saves the events in one transaction and returns the ones stored. The unchanged payloads are skipped,
+// unless backfilled, and the events not telling when they were collected are given the current time
+func (c *collectorService) store(collectedData []*datapipeline.DataCollectedEvent, backfill bool) ([]*datapipeline.DataCollectedEvent, error) {
+	var stored, unchanged []*datapipeline.DataCollectedEvent
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		stored, unchanged = nil, nil
+		now := timeNow()
+
+		for _, event := range collectedData {
+			if event.CollectedAt.IsZero() {
+				event.CollectedAt = now
+			}
+
+			if backfill {
+				stored = append(stored, event)
+				continue
+			}
+
+			skip, err := skipUnchangedPayload(tx, event)
+			if err != nil {
+				return err
+			}
+			if skip {
+				unchanged = append(unchanged, event)
+			} else {
+				stored = append(stored, event)
+			}
+		}
+
+		if err := c.countIngestion(tx, stored); err != nil {
+			return err
+		}
+
+		// one insert per event, so that the IDs follow the order of the events
+		for _, event := range stored {
+			if err := tx.Create(event).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range stored {
+		countCollectedEvent(event, CollectedEventStored)
+	}
+	for _, event := range unchanged {
+		countCollectedEvent(event, CollectedEventUnchanged)
+	}
+
+	return stored, nil
+}
+
+// countIngestion adds the events to the usage of the day of their agents,
+// returning an IngestionQuotaExceededError if any of them exceeds its quota
+func (c *collectorService) countIngestion(tx *gorm.DB, events []*datapipeline.DataCollectedEvent) error {
+	usages := make(map[string]*entities.IngestionUsage)
+	var agentIDs []string
+
+	now := timeNow().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, event := range events {
+		usage, ok := usages[event.AgentID]
+		if !ok {
+			usage = &entities.IngestionUsage{AgentID: event.AgentID, Day: day}
+			usages[event.AgentID] = usage
+			agentIDs = append(agentIDs, event.AgentID)
+		}
+		usage.Events++
+		usage.Bytes += int64(len(event.Payload))
+	}
+
+	for _, agentID := range agentIDs {
+		usage := usages[agentID]
+
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "agent_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"events": gorm.Expr("ingestion_usages.events + ?", usage.Events),
+				"bytes":  gorm.Expr("ingestion_usages.bytes + ?", usage.Bytes),
+			}),
+		}).Create(usage).Error
+		if err != nil {
+			return err
+		}
+
+		var total entities.IngestionUsage
+		if err := tx.Where("agent_id = ? AND day = ?", agentID, day).First(&total).Error; err != nil {
+			return err
+		}
+
+		quota := ""
+		if c.quota.DailyEvents > 0 && total.Events > c.quota.DailyEvents {
+			quota = IngestionQuotaEvents
+		} else if c.quota.DailyBytes > 0 && total.Bytes > c.quota.DailyBytes {
+			quota = IngestionQuotaBytes
+		}
+		if quota != "" {
+			return &IngestionQuotaExceededError{AgentID: agentID, Quota: quota, ResetAt: day.AddDate(0, 0, 1)}
+		}
+	}
+
+	if len(agentIDs) == 0 {
+		return nil
+	}
+
+	return tx.Where("agent_id IN ? AND day < ?", agentIDs, day.Add(-ingestionUsageRetention)).
+		Delete(&entities.IngestionUsage{}).
+		Error
+}
+
+// skipUnchangedPayload tells whether the payload is identical to the last one stored of the agent discovery,
+// in the last payloadDedupRefresh, recording the time it was seen. The digest of the payload is saved otherwise
+func skipUnchangedPayload(tx *gorm.DB, event *datapipeline.DataCollectedEvent) (bool, error) {
+	hash := delta.Hash(event.Payload)
+	now := timeNow()
+
+	var digest entities.PayloadDigest
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("agent_id = ? AND discovery_type = ?", event.AgentID, event.DiscoveryType).
+		Limit(1).
+		Find(&digest)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	if result.RowsAffected > 0 && digest.Hash == hash && now.Sub(digest.StoredAt) < payloadDedupRefresh {
+		err := tx.Model(&digest).Updates(map[string]interface{}{
+			"last_seen_at": now,
+			"skipped":      gorm.Expr("skipped + 1"),
+		}).Error
+
+		return err == nil, err
+	}
+
+	err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&entities.PayloadDigest{
+		AgentID:       event.AgentID,
+		DiscoveryType: event.DiscoveryType,
+		Hash:          hash,
+		StoredAt:      now,
+		LastSeenAt:    now,
+	}).Error
+
+	return false, err
+}
+
+// reconstruct replaces the delta payloads with the full ones, in order, a delta being based
+// on the previous event of the same agent discovery if any, on the last payload stored otherwise
+func (c *collectorService) reconstruct(collectedData []*datapipeline.DataCollectedEvent) error {
+	previous := make(map[string][]byte)
+
+	for i, event := range collectedData {
+		key := event.AgentID + "/" + event.DiscoveryType
+
+		if event.PayloadFormat == datapipeline.PayloadFormatDelta {
+			base, ok := previous[key]
+			if !ok {
+				var err error
+				base, err = c.lastStoredPayload(event.AgentID, event.DiscoveryType)
+				if err != nil {
+					return err
+				}
+			}
+
+			if base == nil || delta.Hash(base) != event.BaseHash {
+				return fmt.Errorf("event %d: %w", i, ErrDeltaBaseMismatch)
+			}
+
+			payload, err := delta.Apply(base, event.Payload)
+			if err != nil {
+				return fmt.Errorf("event %d: %w", i, err)
+			}
+
+			event.Payload = payload
+			event.PayloadFormat = ""
+			event.BaseHash = ""
+		}
+
+		previous[key] = event.Payload
+	}
 
 	return nil
 }
+
+// lastStoredPayload returns the payload of the last event collected of the agent discovery, nil if none
+func (c *collectorService) lastStoredPayload(agentID string, discoveryType string) ([]byte, error) {
+	var events []datapipeline.DataCollectedEvent
+	err := c.db.Where("agent_id = ? AND discovery_type = ?", agentID, discoveryType).
+		Order("collected_at DESC NULLS LAST, id DESC").
+		Limit(1).
+		Find(&events).
+		Error
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+
+	return events[0].Payload, nil
+}
+
+// GetPipelineStatus returns an overview of the collected events and of the last time they were projected
+func (c *collectorService) GetPipelineStatus() (*models.PipelineStatus, error) {
+	var status models.PipelineStatus
+	var lastCollectedAt, lastProjectedAt *time.Time
+
+	err := c.db.Model(&datapipeline.DataCollectedEvent{}).Count(&status.EventsCount).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.db.Model(&datapipeline.DataCollectedEvent{}).Select("max(created_at)").Scan(&lastCollectedAt).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// the unchanged payloads are not stored
+	var lastSeenAt *time.Time
+	err = c.db.Model(&entities.PayloadDigest{}).Select("max(last_seen_at)").Scan(&lastSeenAt).Error
+	if err != nil {
+		return nil, err
+	}
+	if lastSeenAt != nil && (lastCollectedAt == nil || lastSeenAt.After(*lastCollectedAt)) {
+		lastCollectedAt = lastSeenAt
+	}
+
+	err = c.db.Model(&datapipeline.Subscription{}).Select("max(updated_at)").Scan(&lastProjectedAt).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if lastCollectedAt != nil {
+		status.LastCollectedAt = *lastCollectedAt
+	}
+	if lastProjectedAt != nil {
+		status.LastProjectedAt = *lastProjectedAt
+	}
+
+	return &status, nil
+}
+
+// GetProjectorsStatus returns, for each projector and agent, the last projected event
+// compared to the last event received from the agent
+func (c *collectorService) GetProjectorsStatus() ([]*models.ProjectorStatus, error) {
+	projectorsStatus := []*models.ProjectorStatus{}
+
+	err := c.db.Model(&datapipeline.Subscription{}).
+		Select("subscriptions.projector_id, subscriptions.agent_id, subscriptions.last_projected_event_id, subscriptions.updated_at, events.last_event_id").
+		Joins("JOIN (?) AS events ON events.agent_id = subscriptions.agent_id", c.db.Model(&datapipeline.DataCollectedEvent{}).
+			Select("agent_id, max(id) AS last_event_id").
+			Group("agent_id"),
+		).
+		Order("subscriptions.projector_id, subscriptions.agent_id").
+		Scan(&projectorsStatus).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return projectorsStatus, nil
+}
+
+func (c *collectorService) GetRejectedPayloads(agentID string, organization string) ([]*models.RejectedPayload, error) {
+	var rejectedPayloads []entities.RejectedPayload
+
+	db := c.db.Scopes(agentOrganizationScope(organization, "agent_id")).Where("created_at >= ?", timeNow().Add(-rejectedPayloadsRetention))
+	if agentID != "" {
+		db = db.Where("agent_id = ?", agentID)
+	}
+
+	err := db.Order("id DESC").Find(&rejectedPayloads).Error
+	if err != nil {
+		return nil, err
+	}
+
+	payloads := []*models.RejectedPayload{}
+	for _, p := range rejectedPayloads {
+		payloads = append(payloads, p.ToModel())
+	}
+
+	return payloads, nil
+}
+
+// validate records the payload not valid against the JSON schema of its discovery,
+// to troubleshoot the agents sending it
+func (c *collectorService) validate(event *datapipeline.DataCollectedEvent) error {
+	err := datapipeline.ValidatePayload(event.DiscoveryType, event.Payload)
+
+	var validationErr *datapipeline.PayloadValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	countCollectedEvent(event, CollectedEventInvalid)
+
+	if err := c.recordRejectedPayload(event, validationErr); err != nil {
+		log.Errorf("Could not record the rejected payload: %s", err)
+	}
+
+	return validationErr
+}
+
+func (c *collectorService) recordRejectedPayload(event *datapipeline.DataCollectedEvent, validationErr *datapipeline.PayloadValidationError) error {
+	issues, err := json.Marshal(validationErr.Issues)
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	rejected := &entities.RejectedPayload{
+		CreatedAt:     now,
+		AgentID:       event.AgentID,
+		DiscoveryType: event.DiscoveryType,
+		Issues:        issues,
+		Size:          len(event.Payload),
+		Body:          event.Payload,
+	}
+	if len(event.Payload) > MaxPayloadCaptureSizeBytes {
+		rejected.Body = event.Payload[:MaxPayloadCaptureSizeBytes]
+		rejected.Truncated = true
+	}
+
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rejected).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("created_at < ? OR id <= ?", now.Add(-rejectedPayloadsRetention), rejected.ID-maxRejectedPayloads).
+			Delete(&entities.RejectedPayload{}).
+			Error
+	})
+}
+
+func (c *collectorService) PruneEvents(retention time.Duration) (int64, error) {
+	latest := c.db.Model(&datapipeline.DataCollectedEvent{}).
+		Select("DISTINCT ON (agent_id, discovery_type) id").
+		Order("agent_id, discovery_type, collected_at DESC NULLS LAST, id DESC")
+
+	result := c.db.
+		Where("created_at < ?", timeNow().Add(-retention)).
+		Where("id NOT IN (?)", latest).
+		Delete(&datapipeline.DataCollectedEvent{})
+
+	return result.RowsAffected, result.Error
+}
+
+func (c *collectorService) QueueDepth() int {
+	return len(c.projectorsChannel)
+}
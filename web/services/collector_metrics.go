@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/trento-project/trento/web/datapipeline"
+)
+
+const (
+	CollectedEventStored    = "stored"
+	CollectedEventUnchanged = "unchanged"
+	CollectedEventInvalid   = "invalid"
+)
+
+// CollectedEvents counts the events handled by the CollectorService, by discovery type, agent and outcome:
+// stored, unchanged, neither stored nor projected, or invalid
+var CollectedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "events_total",
+	Help:      "Events handled by the collector, by discovery type, agent and outcome.",
+}, []string{"discovery_type", "agent_id", "outcome"})
+
+// StoredPayloadSize observes the size of the payloads stored, the delta payloads once reconstructed
+var StoredPayloadSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "trento",
+	Subsystem: "collector",
+	Name:      "stored_payload_size_bytes",
+	Help:      "Size of the payloads stored by the collector, by discovery type and agent.",
+	Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+}, []string{"discovery_type", "agent_id"})
+
+func countCollectedEvent(event *datapipeline.DataCollectedEvent, outcome string) {
+	CollectedEvents.WithLabelValues(event.DiscoveryType, event.AgentID, outcome).Inc()
+	if outcome == CollectedEventStored {
+		StoredPayloadSize.WithLabelValues(event.DiscoveryType, event.AgentID).Observe(float64(len(event.Payload)))
+	}
+}
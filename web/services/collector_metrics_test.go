@@ -0,0 +1,22 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/datapipeline"
+)
+
+func TestCountCollectedEvent(t *testing.T) {
+	event := &datapipeline.DataCollectedEvent{AgentID: "metrics_agent", DiscoveryType: "host_discovery", Payload: []byte(`{"hostname":"host1"}`)}
+
+	countCollectedEvent(event, CollectedEventStored)
+	countCollectedEvent(event, CollectedEventStored)
+	countCollectedEvent(event, CollectedEventUnchanged)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(CollectedEvents.WithLabelValues("host_discovery", "metrics_agent", CollectedEventStored)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(CollectedEvents.WithLabelValues("host_discovery", "metrics_agent", CollectedEventUnchanged)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(CollectedEvents.WithLabelValues("host_discovery", "metrics_agent", CollectedEventInvalid)))
+}
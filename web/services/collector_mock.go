@@ -5,6 +5,10 @@ package services
 import (
 	mock "github.com/stretchr/testify/mock"
 	datapipeline "github.com/trento-project/trento/web/datapipeline"
+
+	models "github.com/trento-project/trento/web/models"
+
+	time "time"
 )
 
 // MockCollectorService is an autogenerated mock type for the CollectorService type
@@ -12,6 +16,138 @@ type MockCollectorService struct {
 	mock.Mock
 }
 
+// BackfillEvents provides a mock function with given fields: dataCollected
+func (_m *MockCollectorService) BackfillEvents(dataCollected []*datapipeline.DataCollectedEvent) error {
+	ret := _m.Called(dataCollected)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*datapipeline.DataCollectedEvent) error); ok {
+		r0 = rf(dataCollected)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// BackfillPendingEvents provides a mock function with given fields: dataCollected
+func (_m *MockCollectorService) BackfillPendingEvents(dataCollected []*datapipeline.DataCollectedEvent) error {
+	ret := _m.Called(dataCollected)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*datapipeline.DataCollectedEvent) error); ok {
+		r0 = rf(dataCollected)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPipelineStatus provides a mock function with given fields:
+func (_m *MockCollectorService) GetPipelineStatus() (*models.PipelineStatus, error) {
+	ret := _m.Called()
+
+	var r0 *models.PipelineStatus
+	if rf, ok := ret.Get(0).(func() *models.PipelineStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PipelineStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProjectorsStatus provides a mock function with given fields:
+func (_m *MockCollectorService) GetProjectorsStatus() ([]*models.ProjectorStatus, error) {
+	ret := _m.Called()
+
+	var r0 []*models.ProjectorStatus
+	if rf, ok := ret.Get(0).(func() []*models.ProjectorStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ProjectorStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRejectedPayloads provides a mock function with given fields: agentID, organization
+func (_m *MockCollectorService) GetRejectedPayloads(agentID string, organization string) ([]*models.RejectedPayload, error) {
+	ret := _m.Called(agentID, organization)
+
+	var r0 []*models.RejectedPayload
+	if rf, ok := ret.Get(0).(func(string, string) []*models.RejectedPayload); ok {
+		r0 = rf(agentID, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.RejectedPayload)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(agentID, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PruneEvents provides a mock function with given fields: retention
+func (_m *MockCollectorService) PruneEvents(retention time.Duration) (int64, error) {
+	ret := _m.Called(retention)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(time.Duration) int64); ok {
+		r0 = rf(retention)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Duration) error); ok {
+		r1 = rf(retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueueDepth provides a mock function with given fields:
+func (_m *MockCollectorService) QueueDepth() int {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
 // StoreEvent provides a mock function with given fields: dataCollected
 func (_m *MockCollectorService) StoreEvent(dataCollected *datapipeline.DataCollectedEvent) error {
 	ret := _m.Called(dataCollected)
@@ -25,3 +161,45 @@ func (_m *MockCollectorService) StoreEvent(dataCollected *datapipeline.DataColle
 
 	return r0
 }
+
+// StoreEvents provides a mock function with given fields: dataCollected
+func (_m *MockCollectorService) StoreEvents(dataCollected []*datapipeline.DataCollectedEvent) error {
+	ret := _m.Called(dataCollected)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*datapipeline.DataCollectedEvent) error); ok {
+		r0 = rf(dataCollected)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StorePendingEvent provides a mock function with given fields: dataCollected
+func (_m *MockCollectorService) StorePendingEvent(dataCollected *datapipeline.DataCollectedEvent) error {
+	ret := _m.Called(dataCollected)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*datapipeline.DataCollectedEvent) error); ok {
+		r0 = rf(dataCollected)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StorePendingEvents provides a mock function with given fields: dataCollected
+func (_m *MockCollectorService) StorePendingEvents(dataCollected []*datapipeline.DataCollectedEvent) error {
+	ret := _m.Called(dataCollected)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*datapipeline.DataCollectedEvent) error); ok {
+		r0 = rf(dataCollected)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -2,10 +2,13 @@ package services
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/delta"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 	"gorm.io/gorm"
 )
@@ -25,11 +28,11 @@ func TestCollectorServiceTestSuite(t *testing.T) {
 func (suite *CollectorServiceTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&datapipeline.DataCollectedEvent{})
+	suite.db.AutoMigrate(&datapipeline.DataCollectedEvent{}, &entities.RejectedPayload{}, &entities.PayloadDigest{}, &entities.IngestionUsage{})
 }
 
 func (suite *CollectorServiceTestSuite) TearDownSuite() {
-	suite.db.Migrator().DropTable(models.Tag{})
+	suite.db.Migrator().DropTable(models.Tag{}, entities.RejectedPayload{}, entities.PayloadDigest{}, entities.IngestionUsage{})
 }
 
 func (suite *CollectorServiceTestSuite) SetupTest() {
@@ -37,11 +40,12 @@ func (suite *CollectorServiceTestSuite) SetupTest() {
 
 	ch := make(chan *datapipeline.DataCollectedEvent, 1)
 	suite.ch = ch
-	suite.collectorService = NewCollectorService(suite.tx, ch)
+	suite.collectorService = NewCollectorService(suite.tx, ch, IngestionQuota{})
 }
 
 func (suite *CollectorServiceTestSuite) TearDownTest() {
 	suite.tx.Rollback()
+	timeNow = time.Now
 }
 
 func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEvent() {
@@ -60,3 +64,332 @@ func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEvent() {
 	suite.EqualValues(eventFromChannel.DiscoveryType, eventFromDB.DiscoveryType)
 	suite.EqualValues(eventFromChannel.Payload, eventFromDB.Payload)
 }
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEventUnchangedPayload() {
+	now := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	err := suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "test_discovery_type",
+		Payload:       []byte(`{"a":1,"b":2}`),
+	})
+	suite.NoError(err)
+	<-suite.ch
+
+	now = now.Add(10 * time.Minute)
+	err = suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "test_discovery_type",
+		Payload:       []byte(`{"b": 2, "a": 1}`),
+	})
+	suite.NoError(err)
+	suite.Len(suite.ch, 0)
+
+	var count int64
+	suite.tx.Model(&datapipeline.DataCollectedEvent{}).Count(&count)
+	suite.EqualValues(1, count)
+
+	var digest entities.PayloadDigest
+	suite.tx.First(&digest)
+	suite.EqualValues(1, digest.Skipped)
+	suite.Equal(now, digest.LastSeenAt.UTC())
+
+	// stored again once the refresh is due
+	now = now.Add(payloadDedupRefresh)
+	err = suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "test_discovery_type",
+		Payload:       []byte(`{"a":1,"b":2}`),
+	})
+	suite.NoError(err)
+	<-suite.ch
+
+	suite.tx.Model(&datapipeline.DataCollectedEvent{}).Count(&count)
+	suite.EqualValues(2, count)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEventDelta() {
+	base := []byte(`{"hostname":"host1","cpu_count":2}`)
+	err := suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "host_discovery",
+		Payload:       base,
+	})
+	suite.NoError(err)
+	<-suite.ch
+
+	err = suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "host_discovery",
+		Payload:       []byte(`{"cpu_count":4}`),
+		PayloadFormat: datapipeline.PayloadFormatDelta,
+		BaseHash:      delta.Hash(base),
+	})
+	suite.NoError(err)
+
+	event := <-suite.ch
+	suite.JSONEq(`{"hostname":"host1","cpu_count":4}`, string(event.Payload))
+	suite.Empty(event.PayloadFormat)
+
+	var stored datapipeline.DataCollectedEvent
+	suite.tx.Last(&stored)
+	suite.JSONEq(`{"hostname":"host1","cpu_count":4}`, string(stored.Payload))
+
+	// based on the previous payload, not the last one stored
+	err = suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "host_discovery",
+		Payload:       []byte(`{"cpu_count":8}`),
+		PayloadFormat: datapipeline.PayloadFormatDelta,
+		BaseHash:      delta.Hash(base),
+	})
+	suite.ErrorIs(err, ErrDeltaBaseMismatch)
+
+	// no payload stored for the discovery
+	err = suite.collectorService.StoreEvents([]*datapipeline.DataCollectedEvent{
+		{AgentID: "agent_id", DiscoveryType: "cloud_discovery", Payload: []byte(`{"Provider":"azure"}`)},
+		{
+			AgentID:       "agent_id",
+			DiscoveryType: "cloud_discovery",
+			Payload:       []byte(`{"Metadata":null}`),
+			PayloadFormat: datapipeline.PayloadFormatDelta,
+			BaseHash:      delta.Hash([]byte(`{"Provider":"azure"}`)),
+		},
+		{
+			AgentID:       "other_agent_id",
+			DiscoveryType: "cloud_discovery",
+			Payload:       []byte(`{}`),
+			PayloadFormat: datapipeline.PayloadFormatDelta,
+			BaseHash:      delta.Hash([]byte(`{"Provider":"azure"}`)),
+		},
+	})
+	suite.ErrorIs(err, ErrDeltaBaseMismatch)
+	suite.Len(suite.ch, 0)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEvents() {
+	ch := make(chan *datapipeline.DataCollectedEvent, 2)
+	collectorService := NewCollectorService(suite.tx, ch, IngestionQuota{})
+
+	err := collectorService.StoreEvents([]*datapipeline.DataCollectedEvent{
+		{AgentID: "agent_id", DiscoveryType: "host_discovery", Payload: []byte(`{"hostname":"host1"}`)},
+		{AgentID: "agent_id", DiscoveryType: "cloud_discovery", Payload: []byte(`{"Provider":"azure"}`)},
+	})
+	suite.NoError(err)
+
+	first, second := <-ch, <-ch
+	suite.Equal("host_discovery", first.DiscoveryType)
+	suite.Equal("cloud_discovery", second.DiscoveryType)
+	suite.Less(first.ID, second.ID)
+
+	var count int64
+	suite.tx.Model(&datapipeline.DataCollectedEvent{}).Count(&count)
+	suite.EqualValues(2, count)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_BackfillEvents() {
+	now := time.Date(2021, 10, 2, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	suite.NoError(suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "host_discovery",
+		Payload:       []byte(`{"hostname":"current"}`),
+	}))
+	live := <-suite.ch
+	suite.True(now.Equal(live.CollectedAt))
+
+	ch := make(chan *datapipeline.DataCollectedEvent, 3)
+	collectorService := NewCollectorService(suite.tx, ch, IngestionQuota{})
+
+	err := collectorService.BackfillEvents([]*datapipeline.DataCollectedEvent{
+		{AgentID: "agent_id", DiscoveryType: "host_discovery", Payload: []byte(`{"hostname":"previous"}`), CollectedAt: now.Add(-time.Hour)},
+		{AgentID: "agent_id", DiscoveryType: "host_discovery", Payload: []byte(`{"hostname":"oldest"}`), CollectedAt: now.Add(-3 * time.Hour)},
+		{AgentID: "agent_id", DiscoveryType: "host_discovery", Payload: []byte(`{"hostname":"older"}`), CollectedAt: now.Add(-2 * time.Hour)},
+	})
+	suite.NoError(err)
+
+	first, second, third := <-ch, <-ch, <-ch
+	suite.JSONEq(`{"hostname":"oldest"}`, string(first.Payload))
+	suite.JSONEq(`{"hostname":"older"}`, string(second.Payload))
+	suite.JSONEq(`{"hostname":"previous"}`, string(third.Payload))
+	suite.Less(live.ID, first.ID)
+	suite.Less(first.ID, second.ID)
+	suite.Less(second.ID, third.ID)
+
+	// the delta payloads are still based on the last payload collected
+	payload, err := collectorService.lastStoredPayload("agent_id", "host_discovery")
+	suite.NoError(err)
+	suite.JSONEq(`{"hostname":"current"}`, string(payload))
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEventsIngestionQuota() {
+	now := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	ch := make(chan *datapipeline.DataCollectedEvent, 2)
+	collectorService := NewCollectorService(suite.tx, ch, IngestionQuota{DailyEvents: 1})
+
+	err := collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID: "agent_id", DiscoveryType: "host_discovery", Payload: []byte(`{"hostname":"host1"}`),
+	})
+	suite.NoError(err)
+
+	err = collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID: "agent_id", DiscoveryType: "cloud_discovery", Payload: []byte(`{"Provider":"azure"}`),
+	})
+	var quotaErr *IngestionQuotaExceededError
+	suite.ErrorAs(err, &quotaErr)
+	suite.Equal(IngestionQuotaEvents, quotaErr.Quota)
+	suite.Equal(time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC), quotaErr.ResetAt)
+
+	// the refused events are not counted
+	var usage entities.IngestionUsage
+	suite.tx.Where("agent_id = ?", "agent_id").First(&usage)
+	suite.EqualValues(1, usage.Events)
+	suite.Len(ch, 1)
+
+	// the quota is reset the next day
+	now = now.AddDate(0, 0, 1)
+	err = collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID: "agent_id", DiscoveryType: "cloud_discovery", Payload: []byte(`{"Provider":"azure"}`),
+	})
+	suite.NoError(err)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_StorePendingEventsRollback() {
+	err := suite.collectorService.StorePendingEvents([]*datapipeline.DataCollectedEvent{
+		{AgentID: "agent_id", DiscoveryType: "host_discovery", Payload: []byte(`{"hostname":"host1"}`)},
+		{AgentID: "agent_id", DiscoveryType: "test_discovery_type", Payload: []byte("not json")},
+	})
+	suite.Error(err)
+
+	var count int64
+	suite.tx.Model(&datapipeline.DataCollectedEvent{}).Count(&count)
+	suite.EqualValues(0, count)
+	suite.Empty(suite.ch)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEventInvalidPayload() {
+	err := suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "host_discovery",
+		Payload:       []byte(`{"hostname":42}`),
+	})
+
+	var validationErr *datapipeline.PayloadValidationError
+	suite.ErrorAs(err, &validationErr)
+	suite.Equal([]*models.PayloadValidationIssue{
+		{Location: "/hostname", Message: "expected string, but got number"},
+	}, validationErr.Issues)
+
+	var count int64
+	suite.tx.Model(&datapipeline.DataCollectedEvent{}).Count(&count)
+	suite.EqualValues(0, count)
+	suite.Empty(suite.ch)
+
+	rejected, err := suite.collectorService.GetRejectedPayloads("agent_id", "")
+	suite.NoError(err)
+	suite.Len(rejected, 1)
+	suite.Equal("host_discovery", rejected[0].DiscoveryType)
+	suite.Equal(validationErr.Issues, rejected[0].Issues)
+	suite.Equal(`{"hostname":42}`, rejected[0].Payload)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEventsInvalidPayload() {
+	err := suite.collectorService.StoreEvents([]*datapipeline.DataCollectedEvent{
+		{AgentID: "agent_id", DiscoveryType: "host_discovery", Payload: []byte(`{"hostname":"host1"}`)},
+		{AgentID: "agent_id", DiscoveryType: "sap_system_discovery", Payload: []byte(`[{"SID":"PRD"}]`)},
+		{AgentID: "agent_id", DiscoveryType: "cloud_discovery", Payload: []byte(`{}`)},
+	})
+	suite.EqualError(err, "event 1: invalid sap_system_discovery payload: /0: missing properties: 'Type'")
+
+	var count int64
+	suite.tx.Model(&datapipeline.DataCollectedEvent{}).Count(&count)
+	suite.EqualValues(0, count)
+	suite.Empty(suite.ch)
+
+	rejected, err := suite.collectorService.GetRejectedPayloads("", "")
+	suite.NoError(err)
+	suite.Len(rejected, 2)
+	suite.Equal("cloud_discovery", rejected[0].DiscoveryType)
+	suite.Equal("sap_system_discovery", rejected[1].DiscoveryType)
+
+	rejected, err = suite.collectorService.GetRejectedPayloads("other_agent_id", "")
+	suite.NoError(err)
+	suite.Empty(rejected)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_PruneEvents() {
+	now := time.Date(2021, 10, 2, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	suite.tx.Create(&[]datapipeline.DataCollectedEvent{
+		{ID: 1, AgentID: "agent_id", DiscoveryType: "host_discovery", Payload: []byte("{}"), CreatedAt: now.Add(-48 * time.Hour), CollectedAt: now.Add(-48 * time.Hour)},
+		// backfilled, collected before the previous event
+		{ID: 2, AgentID: "agent_id", DiscoveryType: "host_discovery", Payload: []byte("{}"), CreatedAt: now.Add(-47 * time.Hour), CollectedAt: now.Add(-72 * time.Hour)},
+		{ID: 3, AgentID: "agent_id", DiscoveryType: "cloud_discovery", Payload: []byte("{}"), CreatedAt: now.Add(-48 * time.Hour), CollectedAt: now.Add(-48 * time.Hour)},
+		{ID: 4, AgentID: "agent_id", DiscoveryType: "cloud_discovery", Payload: []byte("{}"), CreatedAt: now.Add(-time.Hour), CollectedAt: now.Add(-time.Hour)},
+	})
+
+	pruned, err := suite.collectorService.PruneEvents(24 * time.Hour)
+	suite.NoError(err)
+	suite.EqualValues(2, pruned)
+
+	var ids []int64
+	suite.tx.Model(&datapipeline.DataCollectedEvent{}).Order("id").Pluck("id", &ids)
+	suite.Equal([]int64{1, 4}, ids)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_GetPipelineStatus() {
+	suite.tx.AutoMigrate(&datapipeline.Subscription{})
+
+	status, err := suite.collectorService.GetPipelineStatus()
+	suite.NoError(err)
+	suite.EqualValues(0, status.EventsCount)
+	suite.True(status.LastCollectedAt.IsZero())
+
+	suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "test_discovery_type",
+		Payload:       []byte("{}"),
+	})
+	<-suite.ch
+
+	status, err = suite.collectorService.GetPipelineStatus()
+	suite.NoError(err)
+	suite.EqualValues(1, status.EventsCount)
+	suite.False(status.LastCollectedAt.IsZero())
+	suite.True(status.LastProjectedAt.IsZero())
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_GetProjectorsStatus() {
+	suite.tx.AutoMigrate(&datapipeline.Subscription{})
+
+	for i := 0; i < 2; i++ {
+		suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+			AgentID:       "agent_id",
+			DiscoveryType: "test_discovery_type",
+			Payload:       []byte("{}"),
+		})
+		<-suite.ch
+	}
+
+	var events []datapipeline.DataCollectedEvent
+	suite.tx.Order("id").Find(&events)
+
+	suite.tx.Create(&datapipeline.Subscription{
+		ProjectorID:          "test_projector",
+		AgentID:              "agent_id",
+		LastProjectedEventID: events[0].ID,
+	})
+
+	projectorsStatus, err := suite.collectorService.GetProjectorsStatus()
+	suite.NoError(err)
+	suite.Len(projectorsStatus, 1)
+	suite.Equal("test_projector", projectorsStatus[0].ProjectorID)
+	suite.Equal("agent_id", projectorsStatus[0].AgentID)
+	suite.Equal(events[0].ID, projectorsStatus[0].LastProjectedEventID)
+	suite.Equal(events[1].ID, projectorsStatus[0].LastEventID)
+}
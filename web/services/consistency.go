@@ -0,0 +1,233 @@
+package services
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+// hostReferences are the read models referring to the hosts, by the type of their resources
+var hostReferences = []struct {
+	resourceType string
+	entity       interface{}
+}{
+	{"sapsystems", &entities.SAPSystemInstance{}},
+	{"subscriptions", &entities.SlesSubscription{}},
+	{"kubernetes_workloads", &entities.KubernetesWorkload{}},
+}
+
+//go:generate mockery --name=ConsistencyService --inpackage --filename=consistency_mock.go
+
+type ConsistencyService interface {
+	// Check cross-checks the read models and stores the inconsistencies found, replacing the previous ones
+	Check() ([]*models.Inconsistency, error)
+	// GetAll returns the inconsistencies involving the agents of the organization, all of them if empty
+	GetAll(organization string) ([]*models.Inconsistency, error)
+}
+
+type consistencyService struct {
+	db *gorm.DB
+}
+
+func NewConsistencyService(db *gorm.DB) *consistencyService {
+	return &consistencyService{db: db}
+}
+
+func (s *consistencyService) Check() ([]*models.Inconsistency, error) {
+	clustersMissing, err := s.findClustersMissing()
+	if err != nil {
+		return nil, err
+	}
+
+	hostsMissing, err := s.findHostsMissing()
+	if err != nil {
+		return nil, err
+	}
+
+	inconsistencies := append(clustersMissing, hostsMissing...)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return storeInconsistencies(tx, inconsistencies)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return inconsistencies, nil
+}
+
+// findClustersMissing looks for the hosts belonging to a cluster which is not projected,
+// e.g. because the discovery of its designated coordinator failed. Any of the nodes may become the DC,
+// so the events of all of them are projected again
+func (s *consistencyService) findClustersMissing() ([]*models.Inconsistency, error) {
+	var references []struct {
+		ClusterID string
+		AgentID   string
+	}
+	err := s.db.Model(&entities.Host{}).
+		Select("hosts.cluster_id, hosts.agent_id").
+		Joins("LEFT JOIN clusters ON clusters.id = hosts.cluster_id").
+		Where("hosts.cluster_id <> '' AND clusters.id IS NULL").
+		Order("hosts.cluster_id, hosts.agent_id").
+		Scan(&references).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var inconsistencies []*models.Inconsistency
+	byCluster := make(map[string]*models.Inconsistency)
+	for _, reference := range references {
+		inconsistency, ok := byCluster[reference.ClusterID]
+		if !ok {
+			inconsistency = &models.Inconsistency{
+				Kind:         models.InconsistencyClusterMissing,
+				ResourceID:   reference.ClusterID,
+				ReferencedBy: []string{models.TagHostResourceType},
+			}
+			byCluster[reference.ClusterID] = inconsistency
+			inconsistencies = append(inconsistencies, inconsistency)
+		}
+		inconsistency.AgentIDs = append(inconsistency.AgentIDs, reference.AgentID)
+	}
+
+	return inconsistencies, s.setFixable(inconsistencies, datapipeline.ClusterDiscovery)
+}
+
+// findHostsMissing looks for the read models of the agents whose host is not projected
+func (s *consistencyService) findHostsMissing() ([]*models.Inconsistency, error) {
+	var inconsistencies []*models.Inconsistency
+	byAgent := make(map[string]*models.Inconsistency)
+
+	for _, reference := range hostReferences {
+		var agentIDs []string
+		err := s.db.Model(reference.entity).
+			Distinct("agent_id").
+			Where("agent_id NOT IN (?)", s.db.Model(&entities.Host{}).Select("agent_id")).
+			Order("agent_id").
+			Pluck("agent_id", &agentIDs).
+			Error
+		if err != nil {
+			return nil, err
+		}
+
+		for _, agentID := range agentIDs {
+			inconsistency, ok := byAgent[agentID]
+			if !ok {
+				inconsistency = &models.Inconsistency{
+					Kind:       models.InconsistencyHostMissing,
+					ResourceID: agentID,
+					AgentIDs:   []string{agentID},
+				}
+				byAgent[agentID] = inconsistency
+				inconsistencies = append(inconsistencies, inconsistency)
+			}
+			inconsistency.ReferencedBy = append(inconsistency.ReferencedBy, reference.resourceType)
+		}
+	}
+
+	sort.Slice(inconsistencies, func(i, j int) bool {
+		return inconsistencies[i].ResourceID < inconsistencies[j].ResourceID
+	})
+
+	return inconsistencies, s.setFixable(inconsistencies, datapipeline.HostDiscovery)
+}
+
+// setFixable flags the inconsistencies whose agents have stored events of the discovery restoring them
+func (s *consistencyService) setFixable(inconsistencies []*models.Inconsistency, discoveryType string) error {
+	if len(inconsistencies) == 0 {
+		return nil
+	}
+
+	var agentIDs []string
+	for _, inconsistency := range inconsistencies {
+		agentIDs = append(agentIDs, inconsistency.AgentIDs...)
+	}
+
+	var withEvents []string
+	err := s.db.Model(&datapipeline.DataCollectedEvent{}).
+		Distinct("agent_id").
+		Where("agent_id IN ? AND discovery_type = ?", agentIDs, discoveryType).
+		Pluck("agent_id", &withEvents).
+		Error
+	if err != nil {
+		return err
+	}
+
+	for _, inconsistency := range inconsistencies {
+		for _, agentID := range inconsistency.AgentIDs {
+			for _, withEvent := range withEvents {
+				if agentID == withEvent {
+					inconsistency.Fixable = true
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *consistencyService) GetAll(organization string) ([]*models.Inconsistency, error) {
+	var inconsistencyEntities []*entities.Inconsistency
+
+	db := s.db
+	if organization != "" {
+		hosts := s.db.Session(&gorm.Session{NewDB: true}).Model(&entities.Host{}).Select("agent_id").Where("organization = ?", organization)
+		db = db.Where("agent_ids && ARRAY(?)", hosts)
+	}
+
+	err := db.Order("kind, resource_id").Find(&inconsistencyEntities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	inconsistencies := []*models.Inconsistency{}
+	for _, i := range inconsistencyEntities {
+		inconsistencies = append(inconsistencies, i.ToModel())
+	}
+
+	return inconsistencies, nil
+}
+
+// storeInconsistencies replaces the stored inconsistencies, keeping the detection time of the ones still present
+func storeInconsistencies(tx *gorm.DB, inconsistencies []*models.Inconsistency) error {
+	var previous []*entities.Inconsistency
+	if err := tx.Find(&previous).Error; err != nil {
+		return err
+	}
+
+	detectedAt := make(map[string]*entities.Inconsistency)
+	for _, p := range previous {
+		detectedAt[p.Kind+"/"+p.ResourceID] = p
+	}
+
+	if err := tx.Where("1 = 1").Delete(&entities.Inconsistency{}).Error; err != nil {
+		return err
+	}
+
+	now := timeNow()
+	for _, i := range inconsistencies {
+		i.DetectedAt = now
+		if p, ok := detectedAt[i.Kind+"/"+i.ResourceID]; ok {
+			i.DetectedAt = p.DetectedAt
+		}
+
+		err := tx.Create(&entities.Inconsistency{
+			Kind:         i.Kind,
+			ResourceID:   i.ResourceID,
+			ReferencedBy: i.ReferencedBy,
+			AgentIDs:     i.AgentIDs,
+			Fixable:      i.Fixable,
+			DetectedAt:   i.DetectedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockConsistencyService is an autogenerated mock type for the ConsistencyService type
+type MockConsistencyService struct {
+	mock.Mock
+}
+
+// Check provides a mock function with given fields:
+func (_m *MockConsistencyService) Check() ([]*models.Inconsistency, error) {
+	ret := _m.Called()
+
+	var r0 []*models.Inconsistency
+	if rf, ok := ret.Get(0).(func() []*models.Inconsistency); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Inconsistency)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: organization
+func (_m *MockConsistencyService) GetAll(organization string) ([]*models.Inconsistency, error) {
+	ret := _m.Called(organization)
+
+	var r0 []*models.Inconsistency
+	if rf, ok := ret.Get(0).(func(string) []*models.Inconsistency); ok {
+		r0 = rf(organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Inconsistency)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
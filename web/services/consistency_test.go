@@ -0,0 +1,114 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type ConsistencyServiceTestSuite struct {
+	suite.Suite
+	db                 *gorm.DB
+	tx                 *gorm.DB
+	consistencyService *consistencyService
+}
+
+func TestConsistencyServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ConsistencyServiceTestSuite))
+}
+
+func (suite *ConsistencyServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{}, &entities.SlesSubscription{},
+		&entities.KubernetesWorkload{}, &datapipeline.DataCollectedEvent{}, &entities.Inconsistency{})
+}
+
+func (suite *ConsistencyServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{}, &entities.SlesSubscription{},
+		&entities.KubernetesWorkload{}, &datapipeline.DataCollectedEvent{}, &entities.Inconsistency{})
+}
+
+func (suite *ConsistencyServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.consistencyService = NewConsistencyService(suite.tx)
+
+	suite.tx.Create(&entities.Cluster{ID: "cluster1", Name: "hana_cluster"})
+	suite.tx.Create(&[]entities.Host{
+		{AgentID: "1", Name: "hana01", ClusterID: "cluster1"},
+		{AgentID: "2", Name: "netweaver01", ClusterID: "cluster2"},
+		{AgentID: "3", Name: "netweaver02", ClusterID: "cluster2"},
+	})
+	suite.tx.Create(&[]entities.SAPSystemInstance{
+		{ID: "sap1", AgentID: "1", SID: "PRD", InstanceNumber: "00"},
+		{ID: "sap2", AgentID: "4", SID: "HA1", InstanceNumber: "10"},
+		{ID: "sap2", AgentID: "4", SID: "HA1", InstanceNumber: "11"},
+	})
+	suite.tx.Create(&entities.SlesSubscription{AgentID: "4", ID: "SLES_SAP"})
+	suite.tx.Create(&entities.SlesSubscription{AgentID: "5", ID: "SLES_SAP"})
+	suite.tx.Create(&[]datapipeline.DataCollectedEvent{
+		{AgentID: "3", DiscoveryType: datapipeline.ClusterDiscovery, Payload: datatypes.JSON(`{}`)},
+		{AgentID: "4", DiscoveryType: datapipeline.HostDiscovery, Payload: datatypes.JSON(`{}`)},
+		{AgentID: "5", DiscoveryType: datapipeline.SubscriptionDiscovery, Payload: datatypes.JSON(`{}`)},
+	})
+}
+
+func (suite *ConsistencyServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *ConsistencyServiceTestSuite) TestConsistencyService_Check() {
+	detectedAt := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return detectedAt }
+
+	inconsistencies, err := suite.consistencyService.Check()
+	suite.NoError(err)
+	suite.Equal([]*models.Inconsistency{
+		{
+			Kind:         models.InconsistencyClusterMissing,
+			ResourceID:   "cluster2",
+			ReferencedBy: []string{"hosts"},
+			AgentIDs:     []string{"2", "3"},
+			Fixable:      true,
+			DetectedAt:   detectedAt,
+		},
+		{
+			Kind:         models.InconsistencyHostMissing,
+			ResourceID:   "4",
+			ReferencedBy: []string{"sapsystems", "subscriptions"},
+			AgentIDs:     []string{"4"},
+			Fixable:      true,
+			DetectedAt:   detectedAt,
+		},
+		{
+			Kind:         models.InconsistencyHostMissing,
+			ResourceID:   "5",
+			ReferencedBy: []string{"subscriptions"},
+			AgentIDs:     []string{"5"},
+			Fixable:      false,
+			DetectedAt:   detectedAt,
+		},
+	}, inconsistencies)
+
+	// the detection time of the inconsistencies still present is kept
+	suite.tx.Create(&entities.Host{AgentID: "4", Name: "hana02"})
+	timeNow = func() time.Time { return detectedAt.Add(time.Hour) }
+
+	_, err = suite.consistencyService.Check()
+	suite.NoError(err)
+
+	stored, err := suite.consistencyService.GetAll("")
+	suite.NoError(err)
+	suite.Equal(2, len(stored))
+	suite.Equal("cluster2", stored[0].ResourceID)
+	suite.Equal(detectedAt, stored[0].DetectedAt.UTC())
+	suite.Equal("5", stored[1].ResourceID)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+// CostReportTagSeparator separates the key from the value of the tags the cost reports are grouped by
+const CostReportTagSeparator = ":"
+
+//go:generate mockery --name=CostReportService --inpackage --filename=cost_report_mock.go
+
+type CostReportService interface {
+	// GetCostReport accounts the uptime of the hosts, and of the HANA instances running on them,
+	// from the heartbeats sent in the period, to being excluded. The hosts of the organization only are accounted,
+	// all of them if empty
+	GetCostReport(organization string, tagKey string, from time.Time, to time.Time) (*models.CostReport, error)
+}
+
+type costReportService struct {
+	db *gorm.DB
+}
+
+func NewCostReportService(db *gorm.DB) *costReportService {
+	return &costReportService{db: db}
+}
+
+func (s *costReportService) GetCostReport(organization string, tagKey string, from time.Time, to time.Time) (*models.CostReport, error) {
+	uptimes, err := s.getUptimes(organization, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	hostGroups, err := s.getTagGroups(models.TagHostResourceType, tagKey)
+	if err != nil {
+		return nil, err
+	}
+
+	databaseGroups, err := s.getTagGroups(models.TagDatabaseResourceType, tagKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []entities.SAPSystemInstance
+	err = s.db.
+		Select("id, agent_id, instance_number").
+		Where("type = ?", models.SAPSystemTypeDatabase).
+		Scopes(organizationScope(organization, "organization")).
+		Find(&instances).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*models.CostReportGroup)
+	group := func(value string) *models.CostReportGroup {
+		if _, ok := groups[value]; !ok {
+			groups[value] = &models.CostReportGroup{Value: value}
+		}
+		return groups[value]
+	}
+
+	for agentID, uptime := range uptimes {
+		for _, value := range groupValues(hostGroups, agentID) {
+			g := group(value)
+			g.Hosts++
+			g.HostUptimeHours += uptime.Hours()
+		}
+	}
+
+	for _, instance := range instances {
+		uptime, ok := uptimes[instance.AgentID]
+		if !ok {
+			continue
+		}
+
+		for _, value := range groupValues(databaseGroups, instance.ID) {
+			g := group(value)
+			g.HANAInstances++
+			g.HANAInstanceUptimeHours += uptime.Hours()
+		}
+	}
+
+	report := &models.CostReport{
+		TagKey: tagKey,
+		From:   from,
+		To:     to,
+		Groups: []*models.CostReportGroup{},
+	}
+	for _, g := range groups {
+		report.Groups = append(report.Groups, g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Value < report.Groups[j].Value
+	})
+
+	return report, nil
+}
+
+// getUptimes returns the time the hosts have been sending heartbeats during the period,
+// leaving out the ones which did not send any. The time already rolled up is accounted from the rollups
+func (s *costReportService) getUptimes(organization string, from time.Time, to time.Time) (map[string]time.Duration, error) {
+	var agentIDs []string
+
+	err := s.db.Model(&entities.Host{}).
+		Scopes(organizationScope(organization, "organization")).
+		Pluck("agent_id", &agentIDs).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	uptimes := make(map[string]time.Duration)
+	for _, agentID := range agentIDs {
+		h, err := getHostHeartbeats(s.db, agentID)
+		if err != nil {
+			return nil, err
+		}
+
+		hb := intersectHeartbeats([]*hostHeartbeats{h})
+		if hb == nil {
+			continue
+		}
+
+		if uptime, _ := hb.coverage(from, to); uptime > 0 {
+			uptimes[agentID] = uptime
+		}
+	}
+
+	return uptimes, nil
+}
+
+// getTagGroups returns the values of the tags of the key, by resource
+func (s *costReportService) getTagGroups(resourceType string, tagKey string) (map[string][]string, error) {
+	var tags []models.Tag
+
+	prefix := tagKey + CostReportTagSeparator
+	err := s.db.
+		Where("resource_type = ? AND starts_with(value, ?)", resourceType, prefix).
+		Order("value").
+		Find(&tags).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]string)
+	for _, t := range tags {
+		value := strings.TrimSpace(strings.TrimPrefix(t.Value, prefix))
+		groups[t.ResourceID] = append(groups[t.ResourceID], value)
+	}
+
+	return groups, nil
+}
+
+func groupValues(groups map[string][]string, resourceID string) []string {
+	if values, ok := groups[resourceID]; ok {
+		return values
+	}
+
+	return []string{""}
+}
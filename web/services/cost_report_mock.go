@@ -0,0 +1,38 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+
+	time "time"
+)
+
+// MockCostReportService is an autogenerated mock type for the CostReportService type
+type MockCostReportService struct {
+	mock.Mock
+}
+
+// GetCostReport provides a mock function with given fields: organization, tagKey, from, to
+func (_m *MockCostReportService) GetCostReport(organization string, tagKey string, from time.Time, to time.Time) (*models.CostReport, error) {
+	ret := _m.Called(organization, tagKey, from, to)
+
+	var r0 *models.CostReport
+	if rf, ok := ret.Get(0).(func(string, string, time.Time, time.Time) *models.CostReport); ok {
+		r0 = rf(organization, tagKey, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CostReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, time.Time, time.Time) error); ok {
+		r1 = rf(organization, tagKey, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
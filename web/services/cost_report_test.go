@@ -0,0 +1,117 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+var (
+	costReportFrom = time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	costReportTo   = time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type CostReportServiceTestSuite struct {
+	suite.Suite
+	db                *gorm.DB
+	tx                *gorm.DB
+	costReportService *costReportService
+}
+
+func TestCostReportServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CostReportServiceTestSuite))
+}
+
+func (suite *CostReportServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Host{}, &entities.HostHeartbeatPeriod{}, &entities.HostAvailabilityRollup{}, &entities.SAPSystemInstance{}, &models.Tag{})
+}
+
+func (suite *CostReportServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.Host{}, &entities.HostHeartbeatPeriod{}, &entities.HostAvailabilityRollup{}, &entities.SAPSystemInstance{}, &models.Tag{})
+}
+
+func (suite *CostReportServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.costReportService = NewCostReportService(suite.tx)
+
+	suite.tx.Create(&[]entities.Host{
+		{AgentID: "host1", Name: "hana01"},
+		{AgentID: "host2", Name: "hana02"},
+		{AgentID: "host3", Name: "app01"},
+		{AgentID: "host4", Name: "app02"},
+	})
+	suite.tx.Create(&[]entities.HostHeartbeatPeriod{
+		// overlapping the beginning of the period
+		{AgentID: "host1", StartedAt: costReportFrom.Add(-24 * time.Hour), EndedAt: costReportFrom.Add(10 * time.Hour)},
+		{AgentID: "host1", StartedAt: costReportFrom.Add(20 * time.Hour), EndedAt: costReportFrom.Add(30 * time.Hour)},
+		{AgentID: "host2", StartedAt: costReportFrom.Add(48 * time.Hour), EndedAt: costReportFrom.Add(53 * time.Hour)},
+		{AgentID: "host3", StartedAt: costReportTo.Add(-4 * time.Hour), EndedAt: costReportTo.Add(24 * time.Hour)},
+		// out of the period
+		{AgentID: "host4", StartedAt: costReportTo, EndedAt: costReportTo.Add(24 * time.Hour)},
+		// of a host no longer monitored
+		{AgentID: "host5", StartedAt: costReportFrom, EndedAt: costReportTo},
+	})
+	suite.tx.Create(&[]entities.SAPSystemInstance{
+		{ID: "db1", AgentID: "host1", Type: models.SAPSystemTypeDatabase, InstanceNumber: "00"},
+		{ID: "db1", AgentID: "host1", Type: models.SAPSystemTypeDatabase, InstanceNumber: "01"},
+		{ID: "db2", AgentID: "host2", Type: models.SAPSystemTypeDatabase, InstanceNumber: "00"},
+		{ID: "app1", AgentID: "host3", Type: models.SAPSystemTypeApplication, InstanceNumber: "00"},
+	})
+	suite.tx.Create(&[]models.Tag{
+		{Value: "cost-center:4711", ResourceID: "host1", ResourceType: models.TagHostResourceType},
+		{Value: "cost-center:4712", ResourceID: "host2", ResourceType: models.TagHostResourceType},
+		{Value: "cost-center:4712", ResourceID: "host3", ResourceType: models.TagHostResourceType},
+		{Value: "cost-center:4711", ResourceID: "db1", ResourceType: models.TagDatabaseResourceType},
+		{Value: "owner:finance", ResourceID: "host1", ResourceType: models.TagHostResourceType},
+		{Value: "production", ResourceID: "host2", ResourceType: models.TagHostResourceType},
+	})
+}
+
+func (suite *CostReportServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *CostReportServiceTestSuite) TestCostReportService_GetCostReport() {
+	report, err := suite.costReportService.GetCostReport("", "cost-center", costReportFrom, costReportTo)
+	suite.NoError(err)
+
+	suite.Equal(&models.CostReport{
+		TagKey: "cost-center",
+		From:   costReportFrom,
+		To:     costReportTo,
+		Groups: []*models.CostReportGroup{
+			{Value: "", HANAInstances: 1, HANAInstanceUptimeHours: 5},
+			{Value: "4711", Hosts: 1, HostUptimeHours: 20, HANAInstances: 2, HANAInstanceUptimeHours: 40},
+			{Value: "4712", Hosts: 2, HostUptimeHours: 9},
+		},
+	}, report)
+}
+
+func (suite *CostReportServiceTestSuite) TestCostReportService_GetCostReportUntagged() {
+	report, err := suite.costReportService.GetCostReport("", "owner", costReportFrom, costReportTo)
+	suite.NoError(err)
+
+	suite.Equal([]*models.CostReportGroup{
+		{Value: "", Hosts: 2, HostUptimeHours: 9, HANAInstances: 3, HANAInstanceUptimeHours: 45},
+		{Value: "finance", Hosts: 1, HostUptimeHours: 20},
+	}, report.Groups)
+}
+
+func (suite *CostReportServiceTestSuite) TestCostReportService_GetCostReportOrganization() {
+	suite.tx.Model(&entities.Host{}).Where("agent_id = ?", "host1").Update("organization", "acme")
+	suite.tx.Model(&entities.SAPSystemInstance{}).Where("agent_id = ?", "host1").Update("organization", "acme")
+
+	report, err := suite.costReportService.GetCostReport("acme", "cost-center", costReportFrom, costReportTo)
+	suite.NoError(err)
+
+	suite.Equal([]*models.CostReportGroup{
+		{Value: "4711", Hosts: 1, HostUptimeHours: 20, HANAInstances: 2, HANAInstanceUptimeHours: 40},
+	}, report.Groups)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const (
+	// encryptedCredentialsPrefix tells the encrypted credentials apart from the ones stored in plaintext,
+	// before a key was configured
+	encryptedCredentialsPrefix = "enc:v1:"
+	// CredentialsKeySize of the AES-256 key encrypting the data keys
+	CredentialsKeySize = 32
+)
+
+// CredentialsCipher encrypts the credentials stored in the database
+type CredentialsCipher interface {
+	Encrypt(plaintext string) (string, error)
+	// Decrypt returns the credentials stored in plaintext as they are
+	Decrypt(value string) (string, error)
+}
+
+// envelopeCipher encrypts every value with a random data key, stored along with it encrypted by the key
+// of the configuration, which never encrypts the credentials themselves
+type envelopeCipher struct {
+	keyEncryption cipher.AEAD
+}
+
+func NewEnvelopeCipher(key []byte) (*envelopeCipher, error) {
+	if len(key) != CredentialsKeySize {
+		return nil, fmt.Errorf("the credentials key must be %d bytes long", CredentialsKeySize)
+	}
+
+	keyEncryption, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envelopeCipher{keyEncryption: keyEncryption}, nil
+}
+
+func (e *envelopeCipher) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, CredentialsKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	dataEncryption, err := newAESGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	encryptedKey, err := seal(e.keyEncryption, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataEncryption, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedCredentialsPrefix +
+		base64.RawStdEncoding.EncodeToString(encryptedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+func (e *envelopeCipher) Decrypt(value string) (string, error) {
+	if !IsEncryptedCredential(value) {
+		return value, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, encryptedCredentialsPrefix), ":")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("malformed encrypted credentials")
+	}
+
+	encryptedKey, err := base64.RawStdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := open(e.keyEncryption, encryptedKey)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt the data key, was the credentials key changed? %w", err)
+	}
+
+	dataEncryption, err := newAESGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataEncryption, ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+func IsEncryptedCredential(value string) bool {
+	return strings.HasPrefix(value, encryptedCredentialsPrefix)
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal prepends the random nonce to the ciphertext
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed encrypted credentials")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
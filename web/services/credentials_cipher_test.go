@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func credentialsKeyFixture(b byte) []byte {
+	key := make([]byte, CredentialsKeySize)
+	for i := range key {
+		key[i] = b
+	}
+	return key
+}
+
+func TestEnvelopeCipher(t *testing.T) {
+	envelopeCipher, err := NewEnvelopeCipher(credentialsKeyFixture(1))
+	assert.NoError(t, err)
+
+	encrypted, err := envelopeCipher.Encrypt("hacluster")
+	assert.NoError(t, err)
+	assert.True(t, IsEncryptedCredential(encrypted))
+	assert.NotContains(t, encrypted, "hacluster")
+
+	decrypted, err := envelopeCipher.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "hacluster", decrypted)
+
+	// every value has its own data key
+	other, err := envelopeCipher.Encrypt("hacluster")
+	assert.NoError(t, err)
+	assert.NotEqual(t, encrypted, other)
+}
+
+func TestEnvelopeCipherPlaintext(t *testing.T) {
+	envelopeCipher, _ := NewEnvelopeCipher(credentialsKeyFixture(1))
+
+	decrypted, err := envelopeCipher.Decrypt("root")
+	assert.NoError(t, err)
+	assert.Equal(t, "root", decrypted)
+}
+
+func TestEnvelopeCipherWrongKey(t *testing.T) {
+	envelopeCipher, _ := NewEnvelopeCipher(credentialsKeyFixture(1))
+	encrypted, _ := envelopeCipher.Encrypt("hacluster")
+
+	otherCipher, _ := NewEnvelopeCipher(credentialsKeyFixture(2))
+	_, err := otherCipher.Decrypt(encrypted)
+	assert.Error(t, err)
+
+	_, err = envelopeCipher.Decrypt(encryptedCredentialsPrefix + "malformed")
+	assert.Error(t, err)
+}
+
+func TestNewEnvelopeCipherInvalidKey(t *testing.T) {
+	_, err := NewEnvelopeCipher([]byte("short"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// A table is considered bloated when it has more dead tuples than this threshold
+	// and they represent at least deadTuplesRatioThreshold of its live ones
+	deadTuplesThreshold      int64   = 10000
+	deadTuplesRatioThreshold float64 = 0.2
+	// Unused indexes smaller than this are not worth the noise
+	unusedIndexSizeThreshold  int64 = 1 << 20
+	longRunningQueryThreshold       = 5 * time.Minute
+)
+
+//go:generate mockery --name=DBMaintenanceService --inpackage --filename=db_maintenance_mock.go
+
+type DBMaintenanceService interface {
+	Inspect() (*models.DBMaintenanceReport, error)
+	GetLatestReport() (*models.DBMaintenanceReport, error)
+	RunMaintenance(table string) error
+	GetSchemaInfo() (*models.SchemaInfo, error)
+}
+
+type dbMaintenanceService struct {
+	db *gorm.DB
+}
+
+func NewDBMaintenanceService(db *gorm.DB) *dbMaintenanceService {
+	return &dbMaintenanceService{db: db}
+}
+
+type tableStats struct {
+	Relname          string
+	NLiveTup         int64
+	NDeadTup         int64
+	LastVacuum       *time.Time
+	LastAutovacuum   *time.Time
+	LastAnalyze      *time.Time
+	LastAutoanalyze  *time.Time
+	NModSinceAnalyze int64
+}
+
+type indexStats struct {
+	Relname      string
+	Indexrelname string
+	Size         int64
+}
+
+type schemaColumn struct {
+	TableName  string
+	ColumnName string
+	DataType   string
+}
+
+type queryStats struct {
+	Pid      int64
+	Duration float64
+	Query    string
+}
+
+// Inspect looks for bloated tables, unused indexes and long-running queries on the Trento schema
+// and stores the resulting recommendations as the latest report
+func (s *dbMaintenanceService) Inspect() (*models.DBMaintenanceReport, error) {
+	recommendations := []*models.DBRecommendation{}
+
+	tablesRecommendations, err := s.inspectTables()
+	if err != nil {
+		return nil, err
+	}
+	recommendations = append(recommendations, tablesRecommendations...)
+
+	indexesRecommendations, err := s.inspectIndexes()
+	if err != nil {
+		return nil, err
+	}
+	recommendations = append(recommendations, indexesRecommendations...)
+
+	queriesRecommendations, err := s.inspectQueries()
+	if err != nil {
+		return nil, err
+	}
+	recommendations = append(recommendations, queriesRecommendations...)
+
+	jsonRecommendations, err := json.Marshal(recommendations)
+	if err != nil {
+		return nil, err
+	}
+
+	report := entities.DBMaintenanceReport{
+		Recommendations: jsonRecommendations,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id < ?", report.ID).Delete(&entities.DBMaintenanceReport{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report.ToModel()
+}
+
+// GetLatestReport returns the last stored report, nil if the database was never inspected
+func (s *dbMaintenanceService) GetLatestReport() (*models.DBMaintenanceReport, error) {
+	var report entities.DBMaintenanceReport
+
+	err := s.db.Order("id DESC").First(&report).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return report.ToModel()
+}
+
+// RunMaintenance vacuums and analyzes a table of the Trento schema
+func (s *dbMaintenanceService) RunMaintenance(table string) error {
+	var count int64
+
+	err := s.db.Table("pg_stat_user_tables").
+		Where("schemaname = current_schema() AND relname = ?", table).
+		Count(&count).
+		Error
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("table %s not found", table)
+	}
+
+	return s.db.Exec("VACUUM ANALYZE " + pq.QuoteIdentifier(table)).Error
+}
+
+// GetSchemaInfo returns the server version and the columns of the tables of the Trento schema
+func (s *dbMaintenanceService) GetSchemaInfo() (*models.SchemaInfo, error) {
+	var info models.SchemaInfo
+
+	err := s.db.Raw("SHOW server_version").Scan(&info.ServerVersion).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []schemaColumn
+	err = s.db.Table("information_schema.columns").
+		Select("table_name, column_name, data_type").
+		Where("table_schema = current_schema()").
+		Order("table_name, ordinal_position").
+		Scan(&columns).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	info.Tables = []*models.SchemaTable{}
+	for _, c := range columns {
+		if len(info.Tables) == 0 || info.Tables[len(info.Tables)-1].Name != c.TableName {
+			info.Tables = append(info.Tables, &models.SchemaTable{Name: c.TableName})
+		}
+
+		table := info.Tables[len(info.Tables)-1]
+		table.Columns = append(table.Columns, &models.SchemaColumn{
+			Name:     c.ColumnName,
+			DataType: c.DataType,
+		})
+	}
+
+	return &info, nil
+}
+
+func (s *dbMaintenanceService) inspectTables() ([]*models.DBRecommendation, error) {
+	var stats []tableStats
+
+	err := s.db.Table("pg_stat_user_tables").
+		Select("relname, n_live_tup, n_dead_tup, last_vacuum, last_autovacuum, last_analyze, last_autoanalyze, n_mod_since_analyze").
+		Where("schemaname = current_schema()").
+		Order("relname").
+		Scan(&stats).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := []*models.DBRecommendation{}
+	for _, t := range stats {
+		action := fmt.Sprintf("VACUUM ANALYZE %s", pq.QuoteIdentifier(t.Relname))
+
+		switch {
+		case t.NDeadTup > deadTuplesThreshold && float64(t.NDeadTup) >= deadTuplesRatioThreshold*float64(t.NLiveTup):
+			recommendations = append(recommendations, &models.DBRecommendation{
+				Kind:     models.DBRecommendationBloatedTable,
+				Target:   t.Relname,
+				Message:  fmt.Sprintf("The table has %d dead rows out of %d live ones, %s", t.NDeadTup, t.NLiveTup, lastVacuumMessage(t)),
+				Action:   action,
+				Runnable: true,
+			})
+		case t.NLiveTup > 0 && t.LastAnalyze == nil && t.LastAutoanalyze == nil:
+			recommendations = append(recommendations, &models.DBRecommendation{
+				Kind:     models.DBRecommendationMissingStats,
+				Target:   t.Relname,
+				Message:  fmt.Sprintf("The table has never been analyzed, %d rows changed since its creation", t.NModSinceAnalyze),
+				Action:   action,
+				Runnable: true,
+			})
+		}
+	}
+
+	return recommendations, nil
+}
+
+func lastVacuumMessage(t tableStats) string {
+	last := t.LastVacuum
+	if t.LastAutovacuum != nil && (last == nil || t.LastAutovacuum.After(*last)) {
+		last = t.LastAutovacuum
+	}
+
+	if last == nil {
+		return "it has never been vacuumed"
+	}
+
+	return fmt.Sprintf("last vacuumed at %s", last.Format(time.RFC3339))
+}
+
+func (s *dbMaintenanceService) inspectIndexes() ([]*models.DBRecommendation, error) {
+	var stats []indexStats
+
+	err := s.db.Table("pg_stat_user_indexes AS s").
+		Select("s.relname, s.indexrelname, pg_relation_size(s.indexrelid) AS size").
+		Joins("JOIN pg_index AS i ON i.indexrelid = s.indexrelid").
+		Where("s.schemaname = current_schema() AND s.idx_scan = 0 AND NOT i.indisunique AND NOT i.indisprimary").
+		Where("pg_relation_size(s.indexrelid) > ?", unusedIndexSizeThreshold).
+		Order("s.indexrelname").
+		Scan(&stats).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := []*models.DBRecommendation{}
+	for _, i := range stats {
+		recommendations = append(recommendations, &models.DBRecommendation{
+			Kind:    models.DBRecommendationUnusedIndex,
+			Target:  i.Indexrelname,
+			Message: fmt.Sprintf("The index on table %s has never been used and takes %d kB", i.Relname, i.Size/1024),
+			Action:  fmt.Sprintf("DROP INDEX %s", pq.QuoteIdentifier(i.Indexrelname)),
+		})
+	}
+
+	return recommendations, nil
+}
+
+func (s *dbMaintenanceService) inspectQueries() ([]*models.DBRecommendation, error) {
+	var stats []queryStats
+
+	err := s.db.Table("pg_stat_activity").
+		Select("pid, EXTRACT(EPOCH FROM now() - query_start) AS duration, query").
+		Where("datname = current_database() AND state <> 'idle' AND pid <> pg_backend_pid()").
+		Where("now() - query_start > ?::interval", fmt.Sprintf("%d seconds", int(longRunningQueryThreshold.Seconds()))).
+		Order("query_start").
+		Scan(&stats).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := []*models.DBRecommendation{}
+	for _, q := range stats {
+		recommendations = append(recommendations, &models.DBRecommendation{
+			Kind:    models.DBRecommendationLongRunningQuery,
+			Target:  fmt.Sprintf("%d", q.Pid),
+			Message: fmt.Sprintf("The query has been running for %s: %s", time.Duration(q.Duration*float64(time.Second)).Round(time.Second), q.Query),
+			Action:  fmt.Sprintf("SELECT pg_cancel_backend(%d)", q.Pid),
+		})
+	}
+
+	return recommendations, nil
+}
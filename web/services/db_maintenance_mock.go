@@ -0,0 +1,96 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockDBMaintenanceService is an autogenerated mock type for the DBMaintenanceService type
+type MockDBMaintenanceService struct {
+	mock.Mock
+}
+
+// GetLatestReport provides a mock function with given fields:
+func (_m *MockDBMaintenanceService) GetLatestReport() (*models.DBMaintenanceReport, error) {
+	ret := _m.Called()
+
+	var r0 *models.DBMaintenanceReport
+	if rf, ok := ret.Get(0).(func() *models.DBMaintenanceReport); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DBMaintenanceReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSchemaInfo provides a mock function with given fields:
+func (_m *MockDBMaintenanceService) GetSchemaInfo() (*models.SchemaInfo, error) {
+	ret := _m.Called()
+
+	var r0 *models.SchemaInfo
+	if rf, ok := ret.Get(0).(func() *models.SchemaInfo); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SchemaInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Inspect provides a mock function with given fields:
+func (_m *MockDBMaintenanceService) Inspect() (*models.DBMaintenanceReport, error) {
+	ret := _m.Called()
+
+	var r0 *models.DBMaintenanceReport
+	if rf, ok := ret.Get(0).(func() *models.DBMaintenanceReport); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DBMaintenanceReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunMaintenance provides a mock function with given fields: table
+func (_m *MockDBMaintenanceService) RunMaintenance(table string) error {
+	ret := _m.Called(table)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(table)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type DBMaintenanceServiceTestSuite struct {
+	suite.Suite
+	db                   *gorm.DB
+	tx                   *gorm.DB
+	dbMaintenanceService *dbMaintenanceService
+}
+
+func TestDBMaintenanceServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(DBMaintenanceServiceTestSuite))
+}
+
+func (suite *DBMaintenanceServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.DBMaintenanceReport{})
+}
+
+func (suite *DBMaintenanceServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.DBMaintenanceReport{})
+}
+
+func (suite *DBMaintenanceServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.dbMaintenanceService = NewDBMaintenanceService(suite.tx)
+}
+
+func (suite *DBMaintenanceServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *DBMaintenanceServiceTestSuite) TestDBMaintenanceService_GetLatestReportNotInspected() {
+	report, err := suite.dbMaintenanceService.GetLatestReport()
+	suite.NoError(err)
+	suite.Nil(report)
+}
+
+func (suite *DBMaintenanceServiceTestSuite) TestDBMaintenanceService_Inspect() {
+	first, err := suite.dbMaintenanceService.Inspect()
+	suite.NoError(err)
+	suite.NotNil(first.Recommendations)
+
+	second, err := suite.dbMaintenanceService.Inspect()
+	suite.NoError(err)
+
+	latest, err := suite.dbMaintenanceService.GetLatestReport()
+	suite.NoError(err)
+	suite.Equal(second.InspectedAt.UnixNano(), latest.InspectedAt.UnixNano())
+	suite.Equal(second.Recommendations, latest.Recommendations)
+
+	var count int64
+	suite.tx.Model(&entities.DBMaintenanceReport{}).Count(&count)
+	suite.Equal(int64(1), count)
+}
+
+func (suite *DBMaintenanceServiceTestSuite) TestDBMaintenanceService_RunMaintenance() {
+	// VACUUM cannot run inside a transaction block
+	dbMaintenanceService := NewDBMaintenanceService(suite.db)
+
+	suite.NoError(dbMaintenanceService.RunMaintenance("db_maintenance_reports"))
+}
+
+func (suite *DBMaintenanceServiceTestSuite) TestDBMaintenanceService_RunMaintenanceUnknownTable() {
+	suite.Error(suite.dbMaintenanceService.RunMaintenance("unknown; DROP TABLE db_maintenance_reports"))
+}
+
+func (suite *DBMaintenanceServiceTestSuite) TestDBMaintenanceService_GetSchemaInfo() {
+	info, err := suite.dbMaintenanceService.GetSchemaInfo()
+	suite.NoError(err)
+	suite.NotEmpty(info.ServerVersion)
+
+	var reportsTable *models.SchemaTable
+	for _, table := range info.Tables {
+		if table.Name == "db_maintenance_reports" {
+			reportsTable = table
+		}
+	}
+
+	suite.NotNil(reportsTable)
+	suite.Equal(&models.SchemaColumn{Name: "id", DataType: "bigint"}, reportsTable.Columns[0])
+}
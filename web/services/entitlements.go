@@ -0,0 +1,181 @@
+package services
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	// hostsLimitWarningPercentage is the usage of the hosts limit from which a warning is shown
+	hostsLimitWarningPercentage = 90
+	// licenseExpiryWarning is the time before the license expires from which a warning is shown
+	licenseExpiryWarning = 30 * 24 * time.Hour
+)
+
+//go:generate mockery --name=EntitlementsService --inpackage --filename=entitlements_mock.go
+
+type EntitlementsService interface {
+	GetEntitlements() (*models.Entitlements, error)
+	// IsPremiumActive tells whether the premium checks are available
+	IsPremiumActive() (bool, error)
+	// AllowsHost tells whether the host of the agent can be monitored
+	AllowsHost(agentID string) (bool, error)
+}
+
+// PremiumEntitlement tells whether the premium checks are available
+type PremiumEntitlement interface {
+	IsPremiumActive() (bool, error)
+}
+
+// EntitlementsPolicy reads the entitlements from LicenseFile, if any, otherwise they are detected
+// from the subscriptions, without limits
+type EntitlementsPolicy struct {
+	LicenseFile string
+	Enforcement string
+}
+
+// license is the content of the license file
+type license struct {
+	Customer      string     `json:"customer"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	HostsLimit    int        `json:"hosts_limit"`
+	PremiumChecks bool       `json:"premium_checks"`
+}
+
+type entitlementsService struct {
+	policy           EntitlementsPolicy
+	hostsService     HostsService
+	premiumDetection PremiumDetectionService
+}
+
+func NewEntitlementsService(policy EntitlementsPolicy, hostsService HostsService, premiumDetection PremiumDetectionService) *entitlementsService {
+	return &entitlementsService{
+		policy:           policy,
+		hostsService:     hostsService,
+		premiumDetection: premiumDetection,
+	}
+}
+
+// GetEntitlements reads the license file on every call, so that a renewed license takes effect without a restart
+func (s *entitlementsService) GetEntitlements() (*models.Entitlements, error) {
+	entitlements, err := s.getLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	monitoredHosts, err := s.hostsService.GetCount()
+	if err != nil {
+		return nil, err
+	}
+	entitlements.MonitoredHosts = monitoredHosts
+
+	entitlements.Warnings = []string{}
+	if entitlements.HostsLimit > 0 {
+		switch {
+		case monitoredHosts > entitlements.HostsLimit:
+			entitlements.Warnings = append(entitlements.Warnings, models.EntitlementsWarningHostsLimitExceeded)
+		case monitoredHosts*100 >= entitlements.HostsLimit*hostsLimitWarningPercentage:
+			entitlements.Warnings = append(entitlements.Warnings, models.EntitlementsWarningHostsLimitApproaching)
+		}
+	}
+
+	if entitlements.ExpiresAt != nil {
+		switch {
+		case timeNow().After(*entitlements.ExpiresAt):
+			entitlements.Warnings = append(entitlements.Warnings, models.EntitlementsWarningLicenseExpired)
+		case timeNow().Add(licenseExpiryWarning).After(*entitlements.ExpiresAt):
+			entitlements.Warnings = append(entitlements.Warnings, models.EntitlementsWarningLicenseExpiring)
+		}
+	}
+
+	return entitlements, nil
+}
+
+// getLimits returns the entitlements without the usage, the premium checks are disabled once the license expires
+func (s *entitlementsService) getLimits() (*models.Entitlements, error) {
+	if s.policy.LicenseFile == "" {
+		premium, err := s.premiumDetection.IsPremiumActive()
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.Entitlements{
+			Source:        models.EntitlementsSourceSubscription,
+			PremiumChecks: premium,
+			Enforcement:   s.enforcement(),
+		}, nil
+	}
+
+	content, err := ioutil.ReadFile(s.policy.LicenseFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var l license
+	if err := json.Unmarshal(content, &l); err != nil {
+		return nil, err
+	}
+
+	expired := l.ExpiresAt != nil && timeNow().After(*l.ExpiresAt)
+
+	return &models.Entitlements{
+		Source:        models.EntitlementsSourceLicense,
+		Customer:      l.Customer,
+		ExpiresAt:     l.ExpiresAt,
+		HostsLimit:    l.HostsLimit,
+		PremiumChecks: l.PremiumChecks && !expired,
+		Enforcement:   s.enforcement(),
+	}, nil
+}
+
+func (s *entitlementsService) enforcement() string {
+	if s.policy.Enforcement == "" {
+		return models.EntitlementsEnforcementSoft
+	}
+
+	return s.policy.Enforcement
+}
+
+func (s *entitlementsService) IsPremiumActive() (bool, error) {
+	entitlements, err := s.getLimits()
+	if err != nil {
+		return false, err
+	}
+
+	return entitlements.PremiumChecks, nil
+}
+
+// AllowsHost only refuses, with the hard enforcement, the new hosts once the hosts limit is reached
+func (s *entitlementsService) AllowsHost(agentID string) (bool, error) {
+	if s.enforcement() != models.EntitlementsEnforcementHard {
+		return true, nil
+	}
+
+	entitlements, err := s.getLimits()
+	if err != nil {
+		return false, err
+	}
+
+	if entitlements.HostsLimit == 0 {
+		return true, nil
+	}
+
+	monitoredHosts, err := s.hostsService.GetCount()
+	if err != nil {
+		return false, err
+	}
+
+	if monitoredHosts < entitlements.HostsLimit {
+		return true, nil
+	}
+
+	host, err := s.hostsService.GetByID(agentID)
+	if err != nil {
+		return false, err
+	}
+
+	return host != nil, nil
+}
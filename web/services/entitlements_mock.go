@@ -0,0 +1,78 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockEntitlementsService is an autogenerated mock type for the EntitlementsService type
+type MockEntitlementsService struct {
+	mock.Mock
+}
+
+// AllowsHost provides a mock function with given fields: agentID
+func (_m *MockEntitlementsService) AllowsHost(agentID string) (bool, error) {
+	ret := _m.Called(agentID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(agentID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEntitlements provides a mock function with given fields:
+func (_m *MockEntitlementsService) GetEntitlements() (*models.Entitlements, error) {
+	ret := _m.Called()
+
+	var r0 *models.Entitlements
+	if rf, ok := ret.Get(0).(func() *models.Entitlements); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Entitlements)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsPremiumActive provides a mock function with given fields:
+func (_m *MockEntitlementsService) IsPremiumActive() (bool, error) {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/web/models"
+)
+
+func writeLicenseFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "license.json")
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestEntitlementsService_GetEntitlementsFromSubscription(t *testing.T) {
+	hostsService := new(MockHostsService)
+	hostsService.On("GetCount").Return(42, nil)
+	premiumDetection := new(MockPremiumDetectionService)
+	premiumDetection.On("IsPremiumActive").Return(true, nil)
+
+	entitlementsService := NewEntitlementsService(EntitlementsPolicy{}, hostsService, premiumDetection)
+
+	entitlements, err := entitlementsService.GetEntitlements()
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Entitlements{
+		Source:         models.EntitlementsSourceSubscription,
+		MonitoredHosts: 42,
+		PremiumChecks:  true,
+		Enforcement:    models.EntitlementsEnforcementSoft,
+		Warnings:       []string{},
+	}, entitlements)
+}
+
+func TestEntitlementsService_GetEntitlementsFromLicense(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { timeNow = time.Now }()
+
+	hostsService := new(MockHostsService)
+	hostsService.On("GetCount").Return(9, nil)
+
+	file := writeLicenseFile(t, `{"customer": "ACME", "expires_at": "2022-03-15T00:00:00Z", "hosts_limit": 10, "premium_checks": true}`)
+	entitlementsService := NewEntitlementsService(EntitlementsPolicy{LicenseFile: file, Enforcement: models.EntitlementsEnforcementHard}, hostsService, nil)
+
+	entitlements, err := entitlementsService.GetEntitlements()
+	assert.NoError(t, err)
+	assert.Equal(t, models.EntitlementsSourceLicense, entitlements.Source)
+	assert.Equal(t, "ACME", entitlements.Customer)
+	assert.Equal(t, 10, entitlements.HostsLimit)
+	assert.True(t, entitlements.PremiumChecks)
+	assert.Equal(t, models.EntitlementsEnforcementHard, entitlements.Enforcement)
+	assert.Equal(t, []string{
+		models.EntitlementsWarningHostsLimitApproaching,
+		models.EntitlementsWarningLicenseExpiring,
+	}, entitlements.Warnings)
+
+	// the premium checks are disabled once the license expires
+	timeNow = func() time.Time { return time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC) }
+
+	premium, err := entitlementsService.IsPremiumActive()
+	assert.NoError(t, err)
+	assert.False(t, premium)
+
+	entitlements, err = entitlementsService.GetEntitlements()
+	assert.NoError(t, err)
+	assert.Contains(t, entitlements.Warnings, models.EntitlementsWarningLicenseExpired)
+}
+
+func TestEntitlementsService_GetEntitlementsInvalidLicense(t *testing.T) {
+	file := writeLicenseFile(t, `not a license`)
+	entitlementsService := NewEntitlementsService(EntitlementsPolicy{LicenseFile: file}, nil, nil)
+
+	_, err := entitlementsService.GetEntitlements()
+	assert.Error(t, err)
+}
+
+func TestEntitlementsService_AllowsHost(t *testing.T) {
+	hostsService := new(MockHostsService)
+	hostsService.On("GetCount").Return(10, nil)
+	hostsService.On("GetByID", "monitored").Return(&models.Host{ID: "monitored"}, nil)
+	hostsService.On("GetByID", "new").Return(nil, nil)
+
+	file := writeLicenseFile(t, `{"hosts_limit": 10}`)
+
+	soft := NewEntitlementsService(EntitlementsPolicy{LicenseFile: file, Enforcement: models.EntitlementsEnforcementSoft}, hostsService, nil)
+	allowed, err := soft.AllowsHost("new")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	hard := NewEntitlementsService(EntitlementsPolicy{LicenseFile: file, Enforcement: models.EntitlementsEnforcementHard}, hostsService, nil)
+	allowed, err = hard.AllowsHost("monitored")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = hard.AllowsHost("new")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=FavoritesService --inpackage --filename=favorites_mock.go
+
+type FavoritesService interface {
+	GetAll(userID string, organization string) ([]*models.Favorite, error)
+	GetAllByResourceType(userID string, resourceType string) ([]string, error)
+	Create(userID string, resourceType string, resourceID string) error
+	Delete(userID string, resourceType string, resourceID string) error
+}
+
+type favoritesService struct {
+	db *gorm.DB
+}
+
+func NewFavoritesService(db *gorm.DB) *favoritesService {
+	return &favoritesService{db: db}
+}
+
+// GetAll returns the favorite resources of the user, with their display names resolved.
+// The resources of the organization only are returned, all of them if empty
+func (s *favoritesService) GetAll(userID string, organization string) ([]*models.Favorite, error) {
+	var favorites []entities.Favorite
+
+	err := s.db.
+		Where("user_id", userID).
+		Order("resource_type, created_at").
+		Find(&favorites).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := s.getNames(favorites, organization)
+	if err != nil {
+		return nil, err
+	}
+
+	var favoriteList []*models.Favorite
+	for _, f := range favorites {
+		name, ok := names[f.ResourceType][f.ResourceID]
+		if !ok {
+			// the resource is gone, but the favorite is kept in case it comes back
+			continue
+		}
+
+		favoriteList = append(favoriteList, &models.Favorite{
+			ResourceType: f.ResourceType,
+			ResourceID:   f.ResourceID,
+			Name:         name,
+		})
+	}
+
+	return favoriteList, nil
+}
+
+func (s *favoritesService) GetAllByResourceType(userID string, resourceType string) ([]string, error) {
+	var ids []string
+
+	err := s.db.
+		Model(&entities.Favorite{}).
+		Where("user_id", userID).
+		Where("resource_type", resourceType).
+		Pluck("resource_id", &ids).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (s *favoritesService) Create(userID string, resourceType string, resourceID string) error {
+	favorite := entities.Favorite{
+		UserID:       userID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+	}
+
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&favorite).Error
+}
+
+func (s *favoritesService) Delete(userID string, resourceType string, resourceID string) error {
+	favorite := entities.Favorite{
+		UserID:       userID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+	}
+
+	return s.db.Delete(&favorite).Error
+}
+
+// getNames resolves the names of the favorite resources of the organization, indexed by resource type and id
+func (s *favoritesService) getNames(favorites []entities.Favorite, organization string) (map[string]map[string]string, error) {
+	ids := make(map[string][]string)
+	for _, f := range favorites {
+		ids[f.ResourceType] = append(ids[f.ResourceType], f.ResourceID)
+	}
+
+	names := make(map[string]map[string]string)
+	for resourceType, resourceIDs := range ids {
+		var rows []struct {
+			ID   string
+			Name string
+		}
+
+		var db *gorm.DB
+		switch resourceType {
+		case models.TagHostResourceType:
+			db = s.db.Model(&entities.Host{}).Select("agent_id AS id, name").Where("agent_id IN ?", resourceIDs)
+		case models.TagClusterResourceType:
+			db = s.db.Model(&entities.Cluster{}).Select("id, name").Where("id IN ?", resourceIDs)
+		case models.TagSAPSystemResourceType, models.TagDatabaseResourceType:
+			db = s.db.Model(&entities.SAPSystemInstance{}).Select("DISTINCT id, sid AS name").Where("id IN ?", resourceIDs)
+		default:
+			continue
+		}
+
+		if err := db.Scopes(organizationScope(organization, "organization")).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		names[resourceType] = make(map[string]string)
+		for _, row := range rows {
+			names[resourceType][row.ID] = row.Name
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,87 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockFavoritesService is an autogenerated mock type for the FavoritesService type
+type MockFavoritesService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: userID, resourceType, resourceID
+func (_m *MockFavoritesService) Create(userID string, resourceType string, resourceID string) error {
+	ret := _m.Called(userID, resourceType, resourceID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(userID, resourceType, resourceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: userID, resourceType, resourceID
+func (_m *MockFavoritesService) Delete(userID string, resourceType string, resourceID string) error {
+	ret := _m.Called(userID, resourceType, resourceID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(userID, resourceType, resourceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: userID, organization
+func (_m *MockFavoritesService) GetAll(userID string, organization string) ([]*models.Favorite, error) {
+	ret := _m.Called(userID, organization)
+
+	var r0 []*models.Favorite
+	if rf, ok := ret.Get(0).(func(string, string) []*models.Favorite); ok {
+		r0 = rf(userID, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Favorite)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(userID, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllByResourceType provides a mock function with given fields: userID, resourceType
+func (_m *MockFavoritesService) GetAllByResourceType(userID string, resourceType string) ([]string, error) {
+	ret := _m.Called(userID, resourceType)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
+		r0 = rf(userID, resourceType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(userID, resourceType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
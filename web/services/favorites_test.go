@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type FavoritesServiceTestSuite struct {
+	suite.Suite
+	db               *gorm.DB
+	tx               *gorm.DB
+	favoritesService *favoritesService
+}
+
+func TestFavoritesServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(FavoritesServiceTestSuite))
+}
+
+func (suite *FavoritesServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Favorite{}, &entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{})
+}
+
+func (suite *FavoritesServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.Favorite{}, &entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{})
+}
+
+func (suite *FavoritesServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.favoritesService = NewFavoritesService(suite.tx)
+
+	suite.tx.Create(&entities.Host{AgentID: "host1", Name: "host1name"})
+	suite.tx.Create(&entities.Cluster{ID: "cluster1", Name: "cluster1name"})
+	suite.tx.Create(&entities.SAPSystemInstance{ID: "sapsystem1", SID: "HA1", AgentID: "host1", InstanceNumber: "00"})
+}
+
+func (suite *FavoritesServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *FavoritesServiceTestSuite) TestFavoritesService_CreateAndGetAll() {
+	suite.NoError(suite.favoritesService.Create("user", models.TagHostResourceType, "host1"))
+	suite.NoError(suite.favoritesService.Create("user", models.TagHostResourceType, "host1"))
+	suite.NoError(suite.favoritesService.Create("user", models.TagClusterResourceType, "cluster1"))
+	suite.NoError(suite.favoritesService.Create("user", models.TagSAPSystemResourceType, "sapsystem1"))
+	suite.NoError(suite.favoritesService.Create("user", models.TagClusterResourceType, "gone"))
+	suite.NoError(suite.favoritesService.Create("other", models.TagHostResourceType, "host1"))
+
+	favorites, err := suite.favoritesService.GetAll("user", "")
+	suite.NoError(err)
+	suite.ElementsMatch([]*models.Favorite{
+		{ResourceType: models.TagHostResourceType, ResourceID: "host1", Name: "host1name"},
+		{ResourceType: models.TagClusterResourceType, ResourceID: "cluster1", Name: "cluster1name"},
+		{ResourceType: models.TagSAPSystemResourceType, ResourceID: "sapsystem1", Name: "HA1"},
+	}, favorites)
+}
+
+func (suite *FavoritesServiceTestSuite) TestFavoritesService_GetAllOrganization() {
+	suite.tx.Model(&entities.Cluster{}).Where("id = ?", "cluster1").Update("organization", "acme")
+
+	suite.NoError(suite.favoritesService.Create("user", models.TagHostResourceType, "host1"))
+	suite.NoError(suite.favoritesService.Create("user", models.TagClusterResourceType, "cluster1"))
+
+	favorites, err := suite.favoritesService.GetAll("user", "acme")
+	suite.NoError(err)
+	suite.Equal([]*models.Favorite{
+		{ResourceType: models.TagClusterResourceType, ResourceID: "cluster1", Name: "cluster1name"},
+	}, favorites)
+}
+
+func (suite *FavoritesServiceTestSuite) TestFavoritesService_GetAllByResourceType() {
+	suite.NoError(suite.favoritesService.Create("user", models.TagClusterResourceType, "cluster1"))
+	suite.NoError(suite.favoritesService.Create("user", models.TagClusterResourceType, "cluster2"))
+	suite.NoError(suite.favoritesService.Create("user", models.TagHostResourceType, "host1"))
+
+	ids, err := suite.favoritesService.GetAllByResourceType("user", models.TagClusterResourceType)
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"cluster1", "cluster2"}, ids)
+
+	ids, err = suite.favoritesService.GetAllByResourceType("other", models.TagClusterResourceType)
+	suite.NoError(err)
+	suite.Empty(ids)
+}
+
+func (suite *FavoritesServiceTestSuite) TestFavoritesService_Delete() {
+	suite.NoError(suite.favoritesService.Create("user", models.TagHostResourceType, "host1"))
+	suite.NoError(suite.favoritesService.Create("other", models.TagHostResourceType, "host1"))
+
+	suite.NoError(suite.favoritesService.Delete("user", models.TagHostResourceType, "host1"))
+
+	ids, err := suite.favoritesService.GetAllByResourceType("user", models.TagHostResourceType)
+	suite.NoError(err)
+	suite.Empty(ids)
+
+	ids, err = suite.favoritesService.GetAllByResourceType("other", models.TagHostResourceType)
+	suite.NoError(err)
+	suite.Equal([]string{"host1"}, ids)
+}
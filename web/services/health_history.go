@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+//go:generate mockery --name=HealthHistoryService --inpackage --filename=health_history_mock.go
+
+// HealthHistoryService keeps the health of the hosts, clusters, SAP systems and databases over time,
+// so that the landscape can be inspected as it was at a past point in time
+type HealthHistoryService interface {
+	// RecordCurrent stores the health of the resources that changed since the last record
+	RecordCurrent() error
+	GetAllAt(at time.Time) (models.HealthSnapshot, error)
+	// GetAt returns nil if the health of the resource was not known at the time
+	GetAt(resourceType string, id string, at time.Time) (*models.HealthRecord, error)
+}
+
+type healthHistoryService struct {
+	db                *gorm.DB
+	sapSystemsService SAPSystemsService
+	clustersService   ClustersService
+	hostsService      HostsService
+}
+
+func NewHealthHistoryService(db *gorm.DB, sapSystemsService SAPSystemsService, clustersService ClustersService, hostsService HostsService) *healthHistoryService {
+	return &healthHistoryService{
+		db:                db,
+		sapSystemsService: sapSystemsService,
+		clustersService:   clustersService,
+		hostsService:      hostsService,
+	}
+}
+
+func (s *healthHistoryService) RecordCurrent() error {
+	current, err := s.currentHealths()
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	last, err := s.GetAllAt(now)
+	if err != nil {
+		return err
+	}
+
+	var records []entities.HealthHistoryRecord
+	for resourceType, healths := range current {
+		for id, health := range healths {
+			if record := last.Get(resourceType, id); record != nil && record.Health == health {
+				continue
+			}
+
+			records = append(records, entities.HealthHistoryRecord{
+				ResourceType: resourceType,
+				ResourceID:   id,
+				Health:       health,
+				RecordedAt:   now,
+			})
+		}
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	return s.db.Create(&records).Error
+}
+
+func (s *healthHistoryService) GetAllAt(at time.Time) (models.HealthSnapshot, error) {
+	var records []entities.HealthHistoryRecord
+
+	err := s.db.
+		Select("DISTINCT ON (resource_type, resource_id) *").
+		Where("recorded_at <= ?", at).
+		Order("resource_type, resource_id, recorded_at DESC").
+		Find(&records).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := models.HealthSnapshot{}
+	for _, record := range records {
+		if _, ok := snapshot[record.ResourceType]; !ok {
+			snapshot[record.ResourceType] = make(map[string]*models.HealthRecord)
+		}
+		snapshot[record.ResourceType][record.ResourceID] = record.ToModel()
+	}
+
+	return snapshot, nil
+}
+
+func (s *healthHistoryService) GetAt(resourceType string, id string, at time.Time) (*models.HealthRecord, error) {
+	var record entities.HealthHistoryRecord
+
+	err := s.db.
+		Where("resource_type = ? AND resource_id = ? AND recorded_at <= ?", resourceType, id, at).
+		Order("recorded_at DESC").
+		First(&record).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return record.ToModel(), nil
+}
+
+// currentHealths returns the health computed right now, by resource type and ID
+func (s *healthHistoryService) currentHealths() (map[string]map[string]string, error) {
+	current := map[string]map[string]string{
+		models.HealthResourceHost:      {},
+		models.HealthResourceCluster:   {},
+		models.HealthResourceSAPSystem: {},
+		models.HealthResourceDatabase:  {},
+	}
+
+	hosts, err := s.hostsService.GetAll(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range hosts {
+		health := host.Health
+		if health == models.HostHealthUnknown {
+			health = models.HealthSummaryHealthUnknown
+		}
+		current[models.HealthResourceHost][host.ID] = health
+	}
+
+	clusters, err := s.clustersService.GetAll(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, cluster := range clusters {
+		current[models.HealthResourceCluster][cluster.ID] = cluster.Health
+	}
+
+	sapSystems, err := s.sapSystemsService.GetAllApplications(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, sapSystem := range sapSystems {
+		current[models.HealthResourceSAPSystem][sapSystem.ID] = sapSystem.Health
+	}
+
+	databases, err := s.sapSystemsService.GetAllDatabases(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, database := range databases {
+		current[models.HealthResourceDatabase][database.ID] = database.Health
+	}
+
+	return current, nil
+}
@@ -0,0 +1,75 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+
+	time "time"
+)
+
+// MockHealthHistoryService is an autogenerated mock type for the HealthHistoryService type
+type MockHealthHistoryService struct {
+	mock.Mock
+}
+
+// GetAllAt provides a mock function with given fields: at
+func (_m *MockHealthHistoryService) GetAllAt(at time.Time) (models.HealthSnapshot, error) {
+	ret := _m.Called(at)
+
+	var r0 models.HealthSnapshot
+	if rf, ok := ret.Get(0).(func(time.Time) models.HealthSnapshot); ok {
+		r0 = rf(at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.HealthSnapshot)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAt provides a mock function with given fields: resourceType, id, at
+func (_m *MockHealthHistoryService) GetAt(resourceType string, id string, at time.Time) (*models.HealthRecord, error) {
+	ret := _m.Called(resourceType, id, at)
+
+	var r0 *models.HealthRecord
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) *models.HealthRecord); ok {
+		r0 = rf(resourceType, id, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.HealthRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(resourceType, id, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordCurrent provides a mock function with given fields:
+func (_m *MockHealthHistoryService) RecordCurrent() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
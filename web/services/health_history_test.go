@@ -0,0 +1,113 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type HealthHistoryServiceTestSuite struct {
+	suite.Suite
+	db                *gorm.DB
+	tx                *gorm.DB
+	sapSystemsService *MockSAPSystemsService
+	clustersService   *MockClustersService
+	service           *healthHistoryService
+}
+
+func TestHealthHistoryServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthHistoryServiceTestSuite))
+}
+
+func (suite *HealthHistoryServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.HealthHistoryRecord{})
+}
+
+func (suite *HealthHistoryServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.HealthHistoryRecord{})
+}
+
+func (suite *HealthHistoryServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.sapSystemsService = new(MockSAPSystemsService)
+	suite.clustersService = new(MockClustersService)
+	suite.service = NewHealthHistoryService(suite.tx, suite.sapSystemsService, suite.clustersService, new(MockHostsService))
+
+	suite.sapSystemsService.On("GetAllApplications", mock.Anything, mock.Anything).Return(models.SAPSystemList{
+		{ID: "sap_system1", Health: models.SAPSystemHealthPassing},
+	}, nil)
+	suite.sapSystemsService.On("GetAllDatabases", mock.Anything, mock.Anything).Return(models.SAPSystemList{}, nil)
+	suite.clustersService.On("GetAll", mock.Anything, mock.Anything).Return(models.ClusterList{
+		{ID: "cluster1", Health: models.CheckPassing},
+	}, nil)
+}
+
+func (suite *HealthHistoryServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *HealthHistoryServiceTestSuite) recordAt(at time.Time, hostHealth string) {
+	timeNow = func() time.Time { return at }
+
+	hostsService := new(MockHostsService)
+	hostsService.On("GetAll", mock.Anything, mock.Anything).Return(models.HostList{
+		{ID: "host1", Health: hostHealth},
+	}, nil)
+	suite.service.hostsService = hostsService
+
+	suite.NoError(suite.service.RecordCurrent())
+}
+
+func (suite *HealthHistoryServiceTestSuite) TestHealthHistoryService_RecordCurrentOnlyChanges() {
+	start := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+	suite.recordAt(start, models.HostHealthPassing)
+	suite.recordAt(start.Add(time.Minute), models.HostHealthPassing)
+	suite.recordAt(start.Add(2*time.Minute), models.HostHealthCritical)
+	suite.recordAt(start.Add(3*time.Minute), models.HostHealthUnknown)
+
+	var count int64
+	suite.tx.Model(&entities.HealthHistoryRecord{}).Count(&count)
+	// 2 records for the cluster and the SAP system, plus the 3 changes of the host
+	suite.Equal(int64(5), count)
+
+	record, err := suite.service.GetAt(models.HealthResourceHost, "host1", start.Add(150*time.Second))
+	suite.NoError(err)
+	suite.Equal(models.HostHealthCritical, record.Health)
+	suite.True(start.Add(2 * time.Minute).Equal(record.Since))
+
+	record, err = suite.service.GetAt(models.HealthResourceHost, "host1", start.Add(time.Hour))
+	suite.NoError(err)
+	suite.Equal(models.HealthSummaryHealthUnknown, record.Health)
+
+	record, err = suite.service.GetAt(models.HealthResourceHost, "host1", start.Add(-time.Second))
+	suite.NoError(err)
+	suite.Nil(record)
+}
+
+func (suite *HealthHistoryServiceTestSuite) TestHealthHistoryService_GetAllAt() {
+	start := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+	suite.recordAt(start, models.HostHealthPassing)
+	suite.recordAt(start.Add(time.Minute), models.HostHealthWarning)
+
+	snapshot, err := suite.service.GetAllAt(start.Add(30 * time.Second))
+	suite.NoError(err)
+	suite.Equal(models.HostHealthPassing, snapshot.Get(models.HealthResourceHost, "host1").Health)
+	suite.Equal(models.CheckPassing, snapshot.Get(models.HealthResourceCluster, "cluster1").Health)
+	suite.Equal(models.SAPSystemHealthPassing, snapshot.Get(models.HealthResourceSAPSystem, "sap_system1").Health)
+	suite.Nil(snapshot.Get(models.HealthResourceDatabase, "database1"))
+
+	snapshot, err = suite.service.GetAllAt(start.Add(time.Minute))
+	suite.NoError(err)
+	suite.Equal(models.HostHealthWarning, snapshot.Get(models.HealthResourceHost, "host1").Health)
+}
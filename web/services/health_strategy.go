@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	HealthStrategyDefault  = "default"
+	HealthStrategyWeighted = "weighted"
+)
+
+//go:generate mockery --name=HealthStrategy --inpackage --filename=health_strategy_mock.go
+
+// HealthStrategy computes the health of the resources from the results of their checks, and aggregates
+// the health of groups of resources, e.g. of the instances of a SAP system or of its hosts.
+// The healths are passing, warning, critical, or unknown if empty
+type HealthStrategy interface {
+	// ChecksHealth is the health of a cluster, or of one of its nodes, undefined if no check has a result
+	ChecksHealth(results *models.AggregatedCheckData) string
+	// Aggregate is the health of a group of resources given the health of each of them, passing if empty
+	Aggregate(healths ...string) string
+}
+
+// DefaultHealthStrategy is as bad as the worst result: a single critical check, or resource, is critical.
+// The unknown resources are worse than the passing ones only
+type DefaultHealthStrategy struct{}
+
+func (DefaultHealthStrategy) ChecksHealth(results *models.AggregatedCheckData) string {
+	return results.String()
+}
+
+func (DefaultHealthStrategy) Aggregate(healths ...string) string {
+	health := models.HealthSummaryHealthPassing
+	for _, h := range healths {
+		switch {
+		case h == models.HealthSummaryHealthCritical:
+			return models.HealthSummaryHealthCritical
+		case h == models.HealthSummaryHealthWarning:
+			health = models.HealthSummaryHealthWarning
+		case health == models.HealthSummaryHealthPassing && isUnknownHealth(h):
+			health = models.HealthSummaryHealthUnknown
+		}
+	}
+
+	return health
+}
+
+// WeightedHealthStrategy scores the critical and warning results, or resources, by their weight.
+// The score reaching CriticalThreshold is critical, WarningThreshold warning,
+// e.g. with weights 10 and 1 and thresholds 20 and 3 it takes 2 critical checks to be critical
+type WeightedHealthStrategy struct {
+	CriticalWeight    int
+	WarningWeight     int
+	CriticalThreshold int
+	WarningThreshold  int
+}
+
+func (s WeightedHealthStrategy) Validate() error {
+	if s.CriticalWeight < 0 || s.WarningWeight < 0 {
+		return fmt.Errorf("the health weights cannot be negative")
+	}
+
+	if s.WarningThreshold < 1 || s.CriticalThreshold < s.WarningThreshold {
+		return fmt.Errorf("the health warning threshold must be at least 1, and the critical one at least the warning one")
+	}
+
+	return nil
+}
+
+func (s WeightedHealthStrategy) ChecksHealth(results *models.AggregatedCheckData) string {
+	if results.CriticalCount+results.WarningCount+results.PassingCount == 0 {
+		return models.CheckUndefined
+	}
+
+	return s.health(results.CriticalCount, results.WarningCount, false)
+}
+
+func (s WeightedHealthStrategy) Aggregate(healths ...string) string {
+	var critical, warning int
+	var unknown bool
+	for _, h := range healths {
+		switch {
+		case h == models.HealthSummaryHealthCritical:
+			critical++
+		case h == models.HealthSummaryHealthWarning:
+			warning++
+		case isUnknownHealth(h):
+			unknown = true
+		}
+	}
+
+	return s.health(critical, warning, unknown)
+}
+
+func (s WeightedHealthStrategy) health(critical int, warning int, unknown bool) string {
+	score := critical*s.CriticalWeight + warning*s.WarningWeight
+
+	switch {
+	case score >= s.CriticalThreshold:
+		return models.HealthSummaryHealthCritical
+	case score >= s.WarningThreshold:
+		return models.HealthSummaryHealthWarning
+	case unknown:
+		return models.HealthSummaryHealthUnknown
+	default:
+		return models.HealthSummaryHealthPassing
+	}
+}
+
+// isUnknownHealth tells the unknown health, empty for the hosts,
+// the undefined health of the clusters without checks results is not worse than passing
+func isUnknownHealth(health string) bool {
+	return health == models.HealthSummaryHealthUnknown || health == models.HostHealthUnknown
+}
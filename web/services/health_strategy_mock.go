@@ -0,0 +1,47 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockHealthStrategy is an autogenerated mock type for the HealthStrategy type
+type MockHealthStrategy struct {
+	mock.Mock
+}
+
+// Aggregate provides a mock function with given fields: healths
+func (_m *MockHealthStrategy) Aggregate(healths ...string) string {
+	_va := make([]interface{}, len(healths))
+	for _i := range healths {
+		_va[_i] = healths[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(...string) string); ok {
+		r0 = rf(healths...)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// ChecksHealth provides a mock function with given fields: results
+func (_m *MockHealthStrategy) ChecksHealth(results *models.AggregatedCheckData) string {
+	ret := _m.Called(results)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(*models.AggregatedCheckData) string); ok {
+		r0 = rf(results)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/web/models"
+)
+
+func TestDefaultHealthStrategyChecksHealth(t *testing.T) {
+	strategy := DefaultHealthStrategy{}
+
+	assert.Equal(t, models.CheckCritical, strategy.ChecksHealth(&models.AggregatedCheckData{PassingCount: 5, WarningCount: 2, CriticalCount: 1}))
+	assert.Equal(t, models.CheckWarning, strategy.ChecksHealth(&models.AggregatedCheckData{PassingCount: 5, WarningCount: 2}))
+	assert.Equal(t, models.CheckPassing, strategy.ChecksHealth(&models.AggregatedCheckData{PassingCount: 5}))
+	assert.Equal(t, models.CheckUndefined, strategy.ChecksHealth(&models.AggregatedCheckData{}))
+}
+
+func TestDefaultHealthStrategyAggregate(t *testing.T) {
+	strategy := DefaultHealthStrategy{}
+
+	assert.Equal(t, models.HealthSummaryHealthPassing, strategy.Aggregate())
+	assert.Equal(t, models.HealthSummaryHealthPassing, strategy.Aggregate("passing", "passing"))
+	assert.Equal(t, models.HealthSummaryHealthUnknown, strategy.Aggregate("passing", ""))
+	assert.Equal(t, models.HealthSummaryHealthWarning, strategy.Aggregate("unknown", "warning", "passing"))
+	assert.Equal(t, models.HealthSummaryHealthCritical, strategy.Aggregate("warning", "critical", "unknown"))
+}
+
+func TestWeightedHealthStrategyChecksHealth(t *testing.T) {
+	strategy := WeightedHealthStrategy{
+		CriticalWeight:    10,
+		WarningWeight:     1,
+		CriticalThreshold: 20,
+		WarningThreshold:  3,
+	}
+
+	assert.Equal(t, models.CheckCritical, strategy.ChecksHealth(&models.AggregatedCheckData{CriticalCount: 2}))
+	assert.Equal(t, models.CheckCritical, strategy.ChecksHealth(&models.AggregatedCheckData{CriticalCount: 1, WarningCount: 10}))
+	assert.Equal(t, models.CheckWarning, strategy.ChecksHealth(&models.AggregatedCheckData{CriticalCount: 1}))
+	assert.Equal(t, models.CheckWarning, strategy.ChecksHealth(&models.AggregatedCheckData{WarningCount: 3}))
+	assert.Equal(t, models.CheckPassing, strategy.ChecksHealth(&models.AggregatedCheckData{PassingCount: 5, WarningCount: 2}))
+	assert.Equal(t, models.CheckUndefined, strategy.ChecksHealth(&models.AggregatedCheckData{}))
+}
+
+func TestWeightedHealthStrategyAggregate(t *testing.T) {
+	strategy := WeightedHealthStrategy{
+		CriticalWeight:    10,
+		WarningWeight:     1,
+		CriticalThreshold: 20,
+		WarningThreshold:  3,
+	}
+
+	assert.Equal(t, models.HealthSummaryHealthPassing, strategy.Aggregate())
+	assert.Equal(t, models.HealthSummaryHealthPassing, strategy.Aggregate("passing", "warning", "warning"))
+	assert.Equal(t, models.HealthSummaryHealthUnknown, strategy.Aggregate("passing", "warning", "unknown"))
+	assert.Equal(t, models.HealthSummaryHealthWarning, strategy.Aggregate("critical", "unknown"))
+	assert.Equal(t, models.HealthSummaryHealthCritical, strategy.Aggregate("critical", "critical", "passing"))
+}
+
+func TestWeightedHealthStrategyValidate(t *testing.T) {
+	assert.NoError(t, WeightedHealthStrategy{CriticalWeight: 10, WarningWeight: 1, CriticalThreshold: 10, WarningThreshold: 1}.Validate())
+	assert.NoError(t, WeightedHealthStrategy{CriticalWeight: 0, WarningWeight: 1, CriticalThreshold: 1, WarningThreshold: 1}.Validate())
+	assert.Error(t, WeightedHealthStrategy{CriticalWeight: -1, WarningWeight: 1, CriticalThreshold: 10, WarningThreshold: 1}.Validate())
+	assert.Error(t, WeightedHealthStrategy{CriticalWeight: 10, WarningWeight: 1, CriticalThreshold: 10, WarningThreshold: 0}.Validate())
+	assert.Error(t, WeightedHealthStrategy{CriticalWeight: 10, WarningWeight: 1, CriticalThreshold: 2, WarningThreshold: 3}.Validate())
+}
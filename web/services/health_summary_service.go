@@ -1,39 +1,70 @@
 package services
 
 import (
+	"time"
+
 	"github.com/trento-project/trento/web/models"
 )
 
 //go:generate mockery --name=HealthSummaryService --inpackage --filename=health_summary_service_mock.go
 type HealthSummaryService interface {
-	GetHealthSummary() (models.HealthSummary, error)
+	// GetHealthSummary returns the health of the SAP systems of the organization, of all of them if empty
+	GetHealthSummary(organization string) (models.HealthSummary, error)
+	// GetHealthSummaryAt returns the health the SAP systems had at the given time,
+	// the systems not known yet at the time are left out
+	GetHealthSummaryAt(organization string, at time.Time) (models.HealthSummary, error)
 }
 
 type healthSummaryService struct {
-	sapSystemsService SAPSystemsService
-	hostsService      HostsService
-	clustersService   ClustersService
+	sapSystemsService    SAPSystemsService
+	hostsService         HostsService
+	clustersService      ClustersService
+	healthHistoryService HealthHistoryService
+	healthStrategy       HealthStrategy
 }
 
 func NewHealthSummaryService(sapSystemsService SAPSystemsService,
 	clustersService ClustersService,
-	hostsService HostsService) HealthSummaryService {
+	hostsService HostsService,
+	healthHistoryService HealthHistoryService,
+	healthStrategy HealthStrategy) HealthSummaryService {
 	return &healthSummaryService{
-		sapSystemsService: sapSystemsService,
-		clustersService:   clustersService,
-		hostsService:      hostsService,
+		sapSystemsService:    sapSystemsService,
+		clustersService:      clustersService,
+		hostsService:         hostsService,
+		healthHistoryService: healthHistoryService,
+		healthStrategy:       healthStrategy,
+	}
+}
+
+func (s *healthSummaryService) GetHealthSummary(organization string) (models.HealthSummary, error) {
+	return s.getHealthSummary(organization, nil)
+}
+
+func (s *healthSummaryService) GetHealthSummaryAt(organization string, at time.Time) (models.HealthSummary, error) {
+	snapshot, err := s.healthHistoryService.GetAllAt(at)
+	if err != nil {
+		return nil, err
 	}
+
+	return s.getHealthSummary(organization, snapshot)
 }
 
-func (s *healthSummaryService) GetHealthSummary() (models.HealthSummary, error) {
+// getHealthSummary computes the summary with the health of the resources in the snapshot, if any,
+// rather than the current one
+func (s *healthSummaryService) getHealthSummary(organization string, snapshot models.HealthSnapshot) (models.HealthSummary, error) {
 	var healthSummary models.HealthSummary
 
-	sapSystems, err := s.sapSystemsService.GetAllApplications(nil, nil)
+	sapSystems, err := s.sapSystemsService.GetAllApplications(&SAPSystemFilter{Organization: organization}, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, sapSystem := range sapSystems {
+		if snapshot != nil && snapshot.Get(models.HealthResourceSAPSystem, sapSystem.ID) == nil {
+			continue
+		}
+
 		var hostIDs []string
 		var clusterIDs []string
 
@@ -55,19 +86,47 @@ func (s *healthSummaryService) GetHealthSummary() (models.HealthSummary, error)
 			return nil, err
 		}
 
+		if snapshot != nil {
+			applyHealthSnapshot(snapshot, sapSystem, hosts, clusters)
+		}
+
 		healthSummary = append(healthSummary, models.SAPSystemHealthSummary{
 			ID:              sapSystem.ID,
 			SID:             sapSystem.SID,
 			SAPSystemHealth: computeSAPSystemHealth(sapSystem),
 			DatabaseHealth:  computeSAPSystemHealth(sapSystem.AttachedDatabase),
-			ClustersHealth:  computeAggregatedClustersHealth(clusters),
-			HostsHealth:     computeAggregatedHostsHealth(hosts),
+			ClustersHealth:  s.aggregateClustersHealth(clusters),
+			HostsHealth:     s.aggregateHostsHealth(withoutBasicHosts(hosts)),
 		})
 	}
 
 	return healthSummary, nil
 }
 
+// applyHealthSnapshot replaces the current health of the resources with the one in the snapshot,
+// the resources not in the snapshot are reported as unknown
+func applyHealthSnapshot(snapshot models.HealthSnapshot, sapSystem *models.SAPSystem, hosts models.HostList, clusters models.ClusterList) {
+	healthAt := func(resourceType string, id string) string {
+		if record := snapshot.Get(resourceType, id); record != nil {
+			return record.Health
+		}
+		return models.HealthSummaryHealthUnknown
+	}
+
+	sapSystem.Health = healthAt(models.HealthResourceSAPSystem, sapSystem.ID)
+	if sapSystem.AttachedDatabase != nil {
+		sapSystem.AttachedDatabase.Health = healthAt(models.HealthResourceDatabase, sapSystem.AttachedDatabase.ID)
+	}
+
+	for _, host := range hosts {
+		host.Health = healthAt(models.HealthResourceHost, host.ID)
+	}
+
+	for _, cluster := range clusters {
+		cluster.Health = healthAt(models.HealthResourceCluster, cluster.ID)
+	}
+}
+
 func computeSAPSystemHealth(sapsystem *models.SAPSystem) string {
 	if sapsystem == nil {
 		return models.HealthSummaryHealthUnknown
@@ -85,56 +144,36 @@ func computeSAPSystemHealth(sapsystem *models.SAPSystem) string {
 	}
 }
 
-func computeAggregatedClustersHealth(clusters []*models.Cluster) string {
+// aggregateClustersHealth is unknown without clusters, e.g. for the SAP systems not running in a HANA cluster
+func (s *healthSummaryService) aggregateClustersHealth(clusters []*models.Cluster) string {
 	if len(clusters) == 0 {
 		return models.HealthSummaryHealthUnknown
 	}
 
-	var hasWarningCluster, hasUnknownCluster bool
-
+	var healths []string
 	for _, c := range clusters {
-		switch c.Health {
-		case models.CheckCritical:
-			return models.HealthSummaryHealthCritical
-		case models.CheckWarning:
-			hasWarningCluster = true
-		case models.HealthSummaryHealthUnknown:
-			hasUnknownCluster = true
-		}
-	}
-
-	if hasWarningCluster {
-		return models.HealthSummaryHealthWarning
-	}
-
-	if hasUnknownCluster {
-		return models.HealthSummaryHealthUnknown
+		healths = append(healths, c.Health)
 	}
 
-	return models.HealthSummaryHealthPassing
+	return s.healthStrategy.Aggregate(healths...)
 }
 
-func computeAggregatedHostsHealth(hosts []*models.Host) string {
-	var hasWarningHost, hasUnknownHost bool
-
-	for _, h := range hosts {
-		switch h.Health {
-		case models.HostHealthCritical:
-			return models.HealthSummaryHealthCritical
-		case models.HostHealthWarning:
-			hasWarningHost = true
-		case models.HostHealthUnknown:
-			hasUnknownHost = true
+// withoutBasicHosts leaves out the hosts tracked for availability only, which are not part of the SAP rollups
+func withoutBasicHosts(hosts models.HostList) models.HostList {
+	var filtered models.HostList
+	for _, host := range hosts {
+		if !host.IsBasic() {
+			filtered = append(filtered, host)
 		}
 	}
+	return filtered
+}
 
-	if hasWarningHost {
-		return models.HealthSummaryHealthWarning
-	}
-
-	if hasUnknownHost {
-		return models.HealthSummaryHealthUnknown
+func (s *healthSummaryService) aggregateHostsHealth(hosts []*models.Host) string {
+	var healths []string
+	for _, h := range hosts {
+		healths = append(healths, h.Health)
 	}
 
-	return models.HealthSummaryHealthPassing
+	return s.healthStrategy.Aggregate(healths...)
 }
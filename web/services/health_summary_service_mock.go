@@ -5,6 +5,8 @@ package services
 import (
 	mock "github.com/stretchr/testify/mock"
 	models "github.com/trento-project/trento/web/models"
+
+	time "time"
 )
 
 // MockHealthSummaryService is an autogenerated mock type for the HealthSummaryService type
@@ -12,13 +14,36 @@ type MockHealthSummaryService struct {
 	mock.Mock
 }
 
-// GetHealthSummary provides a mock function with given fields:
-func (_m *MockHealthSummaryService) GetHealthSummary() (models.HealthSummary, error) {
-	ret := _m.Called()
+// GetHealthSummary provides a mock function with given fields: organization
+func (_m *MockHealthSummaryService) GetHealthSummary(organization string) (models.HealthSummary, error) {
+	ret := _m.Called(organization)
+
+	var r0 models.HealthSummary
+	if rf, ok := ret.Get(0).(func(string) models.HealthSummary); ok {
+		r0 = rf(organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.HealthSummary)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHealthSummaryAt provides a mock function with given fields: organization, at
+func (_m *MockHealthSummaryService) GetHealthSummaryAt(organization string, at time.Time) (models.HealthSummary, error) {
+	ret := _m.Called(organization, at)
 
 	var r0 models.HealthSummary
-	if rf, ok := ret.Get(0).(func() models.HealthSummary); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(string, time.Time) models.HealthSummary); ok {
+		r0 = rf(organization, at)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(models.HealthSummary)
@@ -26,8 +51,8 @@ func (_m *MockHealthSummaryService) GetHealthSummary() (models.HealthSummary, er
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(organization, at)
 	} else {
 		r1 = ret.Error(1)
 	}
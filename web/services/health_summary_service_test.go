@@ -2,6 +2,7 @@ package services
 
 import (
 	"testing"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -66,10 +67,15 @@ func (suite *HealthSummaryServiceTestSuite) TestGetHealthSummary() {
 		{
 			ID:     "netweaver01",
 			Health: models.HostHealthPassing,
+		},
+		{
+			ID:           "availability_only",
+			Health:       models.HostHealthCritical,
+			AgentProfile: "basic",
 		}}, nil)
 
-	healthSummaryService := NewHealthSummaryService(sapSystemsService, clustersService, hostsService)
-	healthSummary, _ := healthSummaryService.GetHealthSummary()
+	healthSummaryService := NewHealthSummaryService(sapSystemsService, clustersService, hostsService, new(MockHealthHistoryService), DefaultHealthStrategy{})
+	healthSummary, _ := healthSummaryService.GetHealthSummary("acme")
 
 	suite.EqualValues(models.HealthSummary{{
 		ID: "application_id", SID: "HA1",
@@ -78,4 +84,91 @@ func (suite *HealthSummaryServiceTestSuite) TestGetHealthSummary() {
 		DatabaseHealth:  models.HealthSummaryHealthPassing,
 		HostsHealth:     models.HealthSummaryHealthWarning,
 	}}, healthSummary)
+	sapSystemsService.AssertCalled(suite.T(), "GetAllApplications", &SAPSystemFilter{Organization: "acme"}, (*Page)(nil))
+}
+
+func (suite *HealthSummaryServiceTestSuite) TestGetHealthSummaryAt() {
+	at := time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+	sapSystemsService := new(MockSAPSystemsService)
+	clustersService := new(MockClustersService)
+	hostsService := new(MockHostsService)
+	healthHistoryService := new(MockHealthHistoryService)
+
+	sapSystemsService.On("GetAllApplications", mock.Anything, mock.Anything).Return(models.SAPSystemList{
+		{
+			ID:     "application_id",
+			SID:    "HA1",
+			Health: models.SAPSystemHealthPassing,
+			Instances: []*models.SAPSystemInstance{
+				{HostID: "netweaver01", ClusterID: "hana_cluster"},
+			},
+			AttachedDatabase: &models.SAPSystem{ID: "database_id", Health: models.SAPSystemHealthPassing},
+		},
+		{
+			ID:     "new_application_id",
+			SID:    "HA2",
+			Health: models.SAPSystemHealthPassing,
+		},
+	}, nil)
+	clustersService.On("GetAll", mock.Anything, mock.Anything).Return(models.ClusterList{
+		{ID: "hana_cluster", Health: models.CheckPassing},
+	}, nil)
+	hostsService.On("GetAll", mock.Anything, mock.Anything).Return(models.HostList{
+		{ID: "netweaver01", Health: models.HostHealthPassing},
+	}, nil)
+	healthHistoryService.On("GetAllAt", at).Return(models.HealthSnapshot{
+		models.HealthResourceSAPSystem: {
+			"application_id": {Health: models.SAPSystemHealthCritical},
+		},
+		models.HealthResourceHost: {
+			"netweaver01": {Health: models.HostHealthWarning},
+		},
+	}, nil)
+
+	healthSummaryService := NewHealthSummaryService(sapSystemsService, clustersService, hostsService, healthHistoryService, DefaultHealthStrategy{})
+	healthSummary, err := healthSummaryService.GetHealthSummaryAt("", at)
+
+	suite.NoError(err)
+	suite.EqualValues(models.HealthSummary{{
+		ID: "application_id", SID: "HA1",
+		SAPSystemHealth: models.HealthSummaryHealthCritical,
+		ClustersHealth:  models.HealthSummaryHealthUnknown,
+		DatabaseHealth:  models.HealthSummaryHealthUnknown,
+		HostsHealth:     models.HealthSummaryHealthWarning,
+	}}, healthSummary)
+}
+
+func (suite *HealthSummaryServiceTestSuite) TestGetHealthSummaryHealthStrategy() {
+	sapSystemsService := new(MockSAPSystemsService)
+	clustersService := new(MockClustersService)
+	hostsService := new(MockHostsService)
+	healthStrategy := new(MockHealthStrategy)
+
+	sapSystemsService.On("GetAllApplications", mock.Anything, mock.Anything).Return(models.SAPSystemList{
+		{
+			ID:     "application_id",
+			SID:    "HA1",
+			Health: models.SAPSystemHealthPassing,
+			Instances: []*models.SAPSystemInstance{
+				{HostID: "netweaver01", ClusterID: "hana_cluster"},
+			},
+		},
+	}, nil)
+	clustersService.On("GetAll", mock.Anything, mock.Anything).Return(models.ClusterList{
+		{ID: "hana_cluster", Health: models.CheckWarning},
+	}, nil)
+	hostsService.On("GetAll", mock.Anything, mock.Anything).Return(models.HostList{
+		{ID: "netweaver01", Health: models.HostHealthPassing},
+	}, nil)
+	healthStrategy.On("Aggregate", models.CheckWarning).Return(models.HealthSummaryHealthCritical)
+	healthStrategy.On("Aggregate", models.HostHealthPassing).Return(models.HealthSummaryHealthWarning)
+
+	healthSummaryService := NewHealthSummaryService(sapSystemsService, clustersService, hostsService, new(MockHealthHistoryService), healthStrategy)
+	healthSummary, err := healthSummaryService.GetHealthSummary("")
+
+	suite.NoError(err)
+	suite.Equal(models.HealthSummaryHealthCritical, healthSummary[0].ClustersHealth)
+	suite.Equal(models.HealthSummaryHealthWarning, healthSummary[0].HostsHealth)
+	healthStrategy.AssertExpectations(suite.T())
 }
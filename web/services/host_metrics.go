@@ -0,0 +1,110 @@
+package services
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=HostMetricsService --inpackage --filename=host_metrics_mock.go
+
+// HostMetricsService stores the resources usage samples pushed by the agents, finer grained than the utilization
+// reported with the host discovery, and returns them as sparklines
+type HostMetricsService interface {
+	// Store keeps the samples, the ones already stored being ignored, and drops the samples past the retention period
+	Store(agentID string, samples []*hosts.MetricsSample) error
+	GetSparkline(agentID string, period time.Duration, points int) (*models.HostMetricsSparkline, error)
+}
+
+type hostMetricsService struct {
+	db *gorm.DB
+}
+
+func NewHostMetricsService(db *gorm.DB) *hostMetricsService {
+	return &hostMetricsService{db: db}
+}
+
+func (s *hostMetricsService) Store(agentID string, samples []*hosts.MetricsSample) error {
+	var rows []entities.HostMetricSample
+	for _, sample := range samples {
+		rows = append(rows, entities.HostMetricSample{
+			AgentID:       agentID,
+			SampledAt:     sample.Time.UTC(),
+			CPUPercent:    sample.CPUPercent,
+			MemoryPercent: sample.MemoryPercent,
+			DiskPercent:   sample.DiskPercent,
+		})
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if len(rows) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.
+			Where("agent_id = ? AND sampled_at < ?", agentID, timeNow().Add(-models.HostMetricsRetention)).
+			Delete(&entities.HostMetricSample{}).
+			Error
+	})
+}
+
+type hostMetricsBucket struct {
+	Bucket        int
+	CPUPercent    float64
+	MemoryPercent float64
+	DiskPercent   float64
+}
+
+// GetSparkline averages the samples of the given period of time over the given number of points
+func (s *hostMetricsService) GetSparkline(agentID string, period time.Duration, points int) (*models.HostMetricsSparkline, error) {
+	step := period / time.Duration(points)
+	if step < time.Second {
+		step = time.Second
+	}
+	since := timeNow().Add(-step * time.Duration(points)).Truncate(step)
+
+	var buckets []hostMetricsBucket
+	err := s.db.Model(&entities.HostMetricSample{}).
+		Select("FLOOR(EXTRACT(EPOCH FROM sampled_at - ?) / ?)::int AS bucket, "+
+			"AVG(cpu_percent) AS cpu_percent, AVG(memory_percent) AS memory_percent, MAX(disk_percent) AS disk_percent",
+			since, step.Seconds()).
+		Where("agent_id = ? AND sampled_at >= ?", agentID, since).
+		Group("bucket").
+		Scan(&buckets).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return newHostMetricsSparkline(since, step, points, buckets), nil
+}
+
+func newHostMetricsSparkline(since time.Time, step time.Duration, points int, buckets []hostMetricsBucket) *models.HostMetricsSparkline {
+	sparkline := &models.HostMetricsSparkline{
+		Since:       since,
+		StepSeconds: int(step.Seconds()),
+		CPU:         make([]*float64, points),
+		Memory:      make([]*float64, points),
+		Disk:        make([]*float64, points),
+	}
+
+	for _, b := range buckets {
+		// the samples of the current step, past the last point, are left out until it is complete
+		if b.Bucket < 0 || b.Bucket >= points {
+			continue
+		}
+
+		cpu, memory, disk := b.CPUPercent, b.MemoryPercent, b.DiskPercent
+		sparkline.CPU[b.Bucket] = &cpu
+		sparkline.Memory[b.Bucket] = &memory
+		sparkline.Disk[b.Bucket] = &disk
+	}
+
+	return sparkline
+}
@@ -0,0 +1,54 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	hosts "github.com/trento-project/trento/internal/hosts"
+
+	models "github.com/trento-project/trento/web/models"
+
+	time "time"
+)
+
+// MockHostMetricsService is an autogenerated mock type for the HostMetricsService type
+type MockHostMetricsService struct {
+	mock.Mock
+}
+
+// GetSparkline provides a mock function with given fields: agentID, period, points
+func (_m *MockHostMetricsService) GetSparkline(agentID string, period time.Duration, points int) (*models.HostMetricsSparkline, error) {
+	ret := _m.Called(agentID, period, points)
+
+	var r0 *models.HostMetricsSparkline
+	if rf, ok := ret.Get(0).(func(string, time.Duration, int) *models.HostMetricsSparkline); ok {
+		r0 = rf(agentID, period, points)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.HostMetricsSparkline)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, time.Duration, int) error); ok {
+		r1 = rf(agentID, period, points)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: agentID, samples
+func (_m *MockHostMetricsService) Store(agentID string, samples []*hosts.MetricsSample) error {
+	ret := _m.Called(agentID, samples)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []*hosts.MetricsSample) error); ok {
+		r0 = rf(agentID, samples)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+type HostMetricsServiceTestSuite struct {
+	suite.Suite
+	db                 *gorm.DB
+	tx                 *gorm.DB
+	hostMetricsService *hostMetricsService
+}
+
+func TestHostMetricsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(HostMetricsServiceTestSuite))
+}
+
+func (suite *HostMetricsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.HostMetricSample{})
+}
+
+func (suite *HostMetricsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.HostMetricSample{})
+}
+
+func (suite *HostMetricsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.hostMetricsService = NewHostMetricsService(suite.tx)
+
+	timeNow = func() time.Time {
+		return availabilityNow
+	}
+}
+
+func (suite *HostMetricsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func metricsSample(ago time.Duration, cpu float64, memory float64, disk float64) *hosts.MetricsSample {
+	return &hosts.MetricsSample{
+		Time:            availabilityNow.Add(-ago),
+		HostUtilization: hosts.HostUtilization{CPUPercent: cpu, MemoryPercent: memory, DiskPercent: disk},
+	}
+}
+
+func (suite *HostMetricsServiceTestSuite) TestHostMetricsService_Store() {
+	suite.tx.Create(&entities.HostMetricSample{AgentID: "1", SampledAt: availabilityNow.Add(-25 * time.Hour)})
+	suite.tx.Create(&entities.HostMetricSample{AgentID: "2", SampledAt: availabilityNow.Add(-25 * time.Hour)})
+
+	samples := []*hosts.MetricsSample{
+		metricsSample(2*time.Minute, 10, 20, 30),
+		metricsSample(time.Minute, 15, 25, 30),
+	}
+	suite.NoError(suite.hostMetricsService.Store("1", samples))
+	// pushed again by the agent, e.g. on a timeout
+	suite.NoError(suite.hostMetricsService.Store("1", samples))
+
+	var count int64
+	suite.tx.Model(&entities.HostMetricSample{}).Where("agent_id = ?", "1").Count(&count)
+	suite.EqualValues(2, count)
+	suite.tx.Model(&entities.HostMetricSample{}).Where("agent_id = ?", "2").Count(&count)
+	suite.EqualValues(1, count)
+}
+
+func (suite *HostMetricsServiceTestSuite) TestHostMetricsService_GetSparkline() {
+	suite.NoError(suite.hostMetricsService.Store("1", []*hosts.MetricsSample{
+		metricsSample(50*time.Minute, 10, 20, 30),
+		metricsSample(49*time.Minute, 20, 30, 40),
+		metricsSample(5*time.Minute, 50, 60, 70),
+		metricsSample(2*time.Hour, 90, 90, 90),
+	}))
+	suite.NoError(suite.hostMetricsService.Store("2", []*hosts.MetricsSample{
+		metricsSample(5*time.Minute, 99, 99, 99),
+	}))
+
+	sparkline, err := suite.hostMetricsService.GetSparkline("1", time.Hour, 6)
+	suite.NoError(err)
+	suite.Equal(600, sparkline.StepSeconds)
+	suite.Len(sparkline.CPU, 6)
+	suite.Equal([]float64{15, 50}, sparkline.CPUValues())
+	suite.Equal([]float64{25, 60}, sparkline.MemoryValues())
+	suite.Equal([]float64{40, 70}, sparkline.DiskValues())
+}
+
+func TestNewHostMetricsSparkline(t *testing.T) {
+	since := time.Date(2022, 3, 10, 8, 0, 0, 0, time.UTC)
+
+	sparkline := newHostMetricsSparkline(since, time.Minute, 3, []hostMetricsBucket{
+		{Bucket: 0, CPUPercent: 10, MemoryPercent: 20, DiskPercent: 30},
+		{Bucket: 2, CPUPercent: 40, MemoryPercent: 50, DiskPercent: 60},
+		{Bucket: 3, CPUPercent: 99, MemoryPercent: 99, DiskPercent: 99},
+	})
+
+	assert.Equal(t, since, sparkline.Since)
+	assert.Equal(t, 60, sparkline.StepSeconds)
+	assert.Len(t, sparkline.CPU, 3)
+	assert.Nil(t, sparkline.CPU[1])
+	assert.Equal(t, []float64{10, 40}, sparkline.CPUValues())
+	assert.True(t, sparkline.HasSamples())
+	assert.Equal(t, 50.0, sparkline.Latest().MemoryPercent)
+
+	empty := newHostMetricsSparkline(since, time.Minute, 3, nil)
+	assert.False(t, empty.HasSamples())
+	assert.Nil(t, empty.Latest())
+}
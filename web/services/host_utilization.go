@@ -0,0 +1,42 @@
+package services
+
+import (
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=HostUtilizationService --inpackage --filename=host_utilization_mock.go
+
+type HostUtilizationService interface {
+	GetHostUtilization(agentID string, days int) (models.HostUtilization, error)
+}
+
+type hostUtilizationService struct {
+	db *gorm.DB
+}
+
+func NewHostUtilizationService(db *gorm.DB) *hostUtilizationService {
+	return &hostUtilizationService{db: db}
+}
+
+// GetHostUtilization returns the utilization snapshots of the host over the last given days, oldest first
+func (s *hostUtilizationService) GetHostUtilization(agentID string, days int) (models.HostUtilization, error) {
+	var snapshots []entities.HostUtilizationSnapshot
+
+	err := s.db.
+		Where("agent_id = ? AND bucket_start >= ?", agentID, timeNow().AddDate(0, 0, -days)).
+		Order("bucket_start").
+		Find(&snapshots).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	utilization := models.HostUtilization{}
+	for _, s := range snapshots {
+		utilization = append(utilization, s.ToModel())
+	}
+
+	return utilization, nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+type HostUtilizationServiceTestSuite struct {
+	suite.Suite
+	db                     *gorm.DB
+	tx                     *gorm.DB
+	hostUtilizationService *hostUtilizationService
+}
+
+func TestHostUtilizationServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(HostUtilizationServiceTestSuite))
+}
+
+func (suite *HostUtilizationServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.HostUtilizationSnapshot{})
+}
+
+func (suite *HostUtilizationServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.HostUtilizationSnapshot{})
+}
+
+func (suite *HostUtilizationServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.hostUtilizationService = NewHostUtilizationService(suite.tx)
+
+	timeNow = func() time.Time {
+		return availabilityNow
+	}
+}
+
+func (suite *HostUtilizationServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *HostUtilizationServiceTestSuite) TestHostUtilizationService_GetHostUtilization() {
+	suite.tx.Create(&[]entities.HostUtilizationSnapshot{
+		{AgentID: "1", BucketStart: daysAgo(1), SamplesCount: 2, CPUPercentAvg: 20, MemoryPercentAvg: 40, DiskPercentMax: 70},
+		{AgentID: "1", BucketStart: daysAgo(3), SamplesCount: 1, CPUPercentAvg: 10, MemoryPercentAvg: 30, DiskPercentMax: 65},
+		{AgentID: "1", BucketStart: daysAgo(10), SamplesCount: 1, CPUPercentAvg: 90},
+		{AgentID: "2", BucketStart: daysAgo(1), SamplesCount: 1, CPUPercentAvg: 50},
+	})
+
+	utilization, err := suite.hostUtilizationService.GetHostUtilization("1", 7)
+	suite.NoError(err)
+	suite.Len(utilization, 2)
+	suite.Equal(daysAgo(3), utilization[0].Time.UTC())
+	suite.Equal([]float64{10, 20}, utilization.CPU())
+	suite.Equal([]float64{30, 40}, utilization.Memory())
+	suite.Equal([]float64{65, 70}, utilization.Disk())
+}
+
+func (suite *HostUtilizationServiceTestSuite) TestHostUtilizationService_GetHostUtilizationEmpty() {
+	utilization, err := suite.hostUtilizationService.GetHostUtilization("1", 7)
+	suite.NoError(err)
+	suite.Empty(utilization)
+}
@@ -2,23 +2,24 @@ package services
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
-	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/internal"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
-	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 
 	prometheusModel "github.com/prometheus/common/model"
 )
 
 const HeartbeatTreshold = internal.HeartbeatInterval * 2
 
+const (
+	DefaultStaleHostWarningThreshold  = HeartbeatTreshold
+	DefaultStaleHostCriticalThreshold = time.Minute
+)
+
 var timeSince = time.Since
 
 //go:generate mockery --name=HostsService --inpackage --filename=hosts_mock.go
@@ -29,8 +30,58 @@ type HostsService interface {
 	GetCount() (int, error)
 	GetAllSIDs() ([]string, error)
 	GetAllTags() ([]string, error)
+	GetAllTemplateVersions() ([]string, error)
+	// GetAgentsOverview returns the activity of the agents of the hosts of the organization, all of them if empty
+	GetAgentsOverview(organization string) ([]*models.AgentOverview, error)
 	Heartbeat(agentID string) error
+	// UpdateCertificateFingerprint stores the fingerprint of the mTLS client certificate the agent authenticated with
+	UpdateCertificateFingerprint(agentID string, fingerprint string) error
 	GetExportersState(hostname string) (map[string]string, error)
+	// DeleteExpiredEphemeral removes the ephemeral hosts silent for longer than the policy TTL,
+	// returning their IDs
+	DeleteExpiredEphemeral() ([]string, error)
+	// Decommission removes the retired host with all the data of its agent, the collected events included.
+	// It returns nil if the host does not exist
+	Decommission(agentID string) (*models.Host, error)
+	// MarkStaleHosts marks the hosts missing heartbeats as warning, or critical, according to the stale hosts policy,
+	// returning the new health of the hosts whose health changed
+	MarkStaleHosts() (map[string]string, error)
+}
+
+// EphemeralHostsPolicy identifies the hosts that come and go, like auto-scaled application servers.
+// They are not reported as critical when they stop sending heartbeats, and they are removed after the TTL.
+// Hosts are ephemeral when their agent is started with the ephemeral flag or they are tagged with Tag.
+type EphemeralHostsPolicy struct {
+	Tag string
+	// Hosts are never removed if TTL is 0
+	TTL time.Duration
+}
+
+// StaleHostsPolicy is the time without heartbeats after which a host is marked as degraded, reported as warning,
+// and then as critical. The default thresholds are used if 0
+type StaleHostsPolicy struct {
+	WarningThreshold  time.Duration
+	CriticalThreshold time.Duration
+}
+
+// health of a host whose last heartbeat was received the given time ago
+func (p StaleHostsPolicy) health(silence time.Duration) string {
+	warningThreshold, criticalThreshold := p.WarningThreshold, p.CriticalThreshold
+	if warningThreshold == 0 {
+		warningThreshold = DefaultStaleHostWarningThreshold
+	}
+	if criticalThreshold == 0 {
+		criticalThreshold = DefaultStaleHostCriticalThreshold
+	}
+
+	switch {
+	case silence > criticalThreshold:
+		return models.HostHealthCritical
+	case silence > warningThreshold:
+		return models.HostHealthWarning
+	default:
+		return models.HostHealthPassing
+	}
 }
 
 type HostsFilter struct {
@@ -38,72 +89,60 @@ type HostsFilter struct {
 	SIDs   []string
 	Tags   []string
 	Health []string
+	// TemplateVersions are the versions of the template the hosts were provisioned from
+	TemplateVersions []string
+	// Pinned hosts are listed before the others
+	Pinned []string
+	// Organization the hosts belong to, all of them if empty
+	Organization string
 }
 
 type hostsService struct {
-	db                *gorm.DB
+	repository        HostsRepository
 	prometheusService PrometheusService
+	ephemeralPolicy   EphemeralHostsPolicy
+	stalePolicy       StaleHostsPolicy
 }
 
-func NewHostsService(db *gorm.DB, promService PrometheusService) *hostsService {
-	return &hostsService{db, promService}
+func NewHostsService(repository HostsRepository, promService PrometheusService, ephemeralPolicy EphemeralHostsPolicy, stalePolicy StaleHostsPolicy) *hostsService {
+	return &hostsService{repository, promService, ephemeralPolicy, stalePolicy}
 }
 
 func (s *hostsService) GetAll(filter *HostsFilter, page *Page) (models.HostList, error) {
-	var hosts []entities.Host
-	var healthFilteredHosts []string
+	repositoryFilter := filter
 
 	// Filter the hosts by Health
 	if filter != nil && len(filter.Health) > 0 {
-		var heartbeats []entities.HostHeartbeat
+		heartbeats, err := s.repository.GetAllHeartbeats()
+		if err != nil {
+			return nil, err
+		}
 
-		err := s.db.Find(&heartbeats).Error
+		ephemeralIDs, err := s.repository.GetAllEphemeralIDs(s.ephemeralPolicy.Tag)
 		if err != nil {
 			return nil, err
 		}
 
+		var healthFilteredHosts []string
 		for _, hearbeat := range heartbeats {
-			hearbeatHealth := computeHearbeatHealth(&hearbeat)
-			if internal.Contains(filter.Health, hearbeatHealth) {
+			hearbeatHealth := computeHearbeatHealth(&hearbeat, internal.Contains(ephemeralIDs, hearbeat.AgentID), s.stalePolicy)
+			if internal.Contains(filter.Health, hearbeatHealth) &&
+				(len(filter.ID) == 0 || internal.Contains(filter.ID, hearbeat.AgentID)) {
 				healthFilteredHosts = append(healthFilteredHosts, hearbeat.AgentID)
 			}
 		}
-	}
-
-	db := s.db.
-		Model(&entities.Host{}).
-		Scopes(Paginate(page)).
-		Preload("Tags").
-		Preload("Heartbeat").
-		Preload("SAPSystemInstances").
-		Preload("SAPSystemInstances.Host")
-
-	if filter != nil {
-		if len(filter.ID) > 0 {
-			db = db.Where("agent_id IN (?)", filter.ID)
-		}
 
-		if len(filter.SIDs) > 0 {
-			db = db.Where("agent_id IN (?)", s.db.Model(&entities.SAPSystemInstance{}).
-				Select("agent_id").
-				Where("sid IN ?", filter.SIDs),
-			)
-		}
-
-		if len(filter.Tags) > 0 {
-			db = db.Where("agent_id IN (?)", s.db.Model(&models.Tag{}).
-				Select("resource_id").
-				Where("resource_type = ?", models.TagHostResourceType).
-				Where("value IN ?", filter.Tags),
-			)
+		if len(healthFilteredHosts) == 0 {
+			return nil, nil
 		}
 
-		if len(filter.Health) > 0 {
-			db = db.Where("agent_id IN (?)", healthFilteredHosts)
-		}
+		f := *filter
+		f.ID = healthFilteredHosts
+		f.Health = nil
+		repositoryFilter = &f
 	}
 
-	err := db.Order("name").Find(&hosts).Error
+	hosts, err := s.repository.GetAll(repositoryFilter, page)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +150,8 @@ func (s *hostsService) GetAll(filter *HostsFilter, page *Page) (models.HostList,
 	var hostList models.HostList
 	for _, h := range hosts {
 		host := h.ToModel()
-		host.Health = computeHealth(&h)
+		host.Ephemeral = s.isEphemeral(&h)
+		host.Health, host.HealthChangedAt = computeHealth(&h, host.Ephemeral, s.stalePolicy)
 		hostList = append(hostList, host)
 	}
 
@@ -119,24 +159,14 @@ func (s *hostsService) GetAll(filter *HostsFilter, page *Page) (models.HostList,
 }
 
 func (s *hostsService) GetByID(id string) (*models.Host, error) {
-	var host entities.Host
-	err := s.db.
-		Where("agent_id = ?", id).
-		Preload("Heartbeat").
-		Preload("SAPSystemInstances").
-		First(&host).
-		Error
-
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
+	host, err := s.repository.GetByID(id)
+	if err != nil || host == nil {
 		return nil, err
 	}
 
-	hostHealth := computeHealth(&host)
 	modeledHost := host.ToModel()
-	modeledHost.Health = hostHealth
+	modeledHost.Ephemeral = s.isEphemeral(host)
+	modeledHost.Health, modeledHost.HealthChangedAt = computeHealth(host, modeledHost.Ephemeral, s.stalePolicy)
 
 	if modeledHost.CloudProvider == "azure" {
 		var cloudData models.AzureCloudData
@@ -148,17 +178,7 @@ func (s *hostsService) GetByID(id string) (*models.Host, error) {
 }
 
 func (s *hostsService) GetAllBySAPSystemID(id string) (models.HostList, error) {
-	var hosts []entities.Host
-
-	err := s.db.
-		Order("name").
-		Preload("Heartbeat").
-		Preload("SAPSystemInstances").
-		Joins("JOIN sap_system_instances ON sap_system_instances.agent_id = hosts.agent_id").
-		Where("sap_system_instances.id = ?", id).
-		Find(&hosts).
-		Error
-
+	hosts, err := s.repository.GetAllBySAPSystemID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +186,8 @@ func (s *hostsService) GetAllBySAPSystemID(id string) (models.HostList, error) {
 	var hostList models.HostList
 	for _, h := range hosts {
 		host := h.ToModel()
-		host.Health = computeHealth(&h)
+		host.Ephemeral = s.isEphemeral(&h)
+		host.Health, host.HealthChangedAt = computeHealth(&h, host.Ephemeral, s.stalePolicy)
 
 		hostList = append(hostList, host)
 	}
@@ -175,59 +196,158 @@ func (s *hostsService) GetAllBySAPSystemID(id string) (models.HostList, error) {
 }
 
 func (s *hostsService) GetCount() (int, error) {
-	var count int64
-	err := s.db.Model(&entities.Host{}).Count(&count).Error
-
-	return int(count), err
+	return s.repository.GetCount()
 }
 
 func (s *hostsService) GetAllSIDs() ([]string, error) {
-	var sids pq.StringArray
+	return s.repository.GetAllSIDs()
+}
 
-	err := s.db.
-		Model(&entities.Host{}).
-		Order("sap_system_instances.sid").
-		Joins("JOIN sap_system_instances ON sap_system_instances.agent_id = hosts.agent_id AND sid IS NOT NULL").
-		Distinct().
-		Pluck("sap_system_instances.sid", &sids).
-		Error
+func (s *hostsService) GetAllTags() ([]string, error) {
+	return s.repository.GetAllTags()
+}
+
+func (s *hostsService) GetAllTemplateVersions() ([]string, error) {
+	return s.repository.GetAllTemplateVersions()
+}
 
+func (s *hostsService) GetAgentsOverview(organization string) ([]*models.AgentOverview, error) {
+	hosts, activities, err := s.repository.GetAgentsActivity(organization)
 	if err != nil {
 		return nil, err
 	}
 
-	return []string(sids), nil
+	overviews := []*models.AgentOverview{}
+	byAgentID := make(map[string]*models.AgentOverview)
+	for _, h := range hosts {
+		overview := &models.AgentOverview{
+			AgentID:   h.AgentID,
+			Hostname:  h.Name,
+			Heartbeat: computeHearbeatHealth(h.Heartbeat, s.isEphemeral(&h), s.stalePolicy),
+			Payloads:  make(map[string]int64),
+			Errors:    make(map[string]int64),
+		}
+		if h.Heartbeat != nil {
+			lastSeenAt := h.Heartbeat.UpdatedAt
+			overview.LastSeenAt = &lastSeenAt
+		}
+		if h.AgentInfo != nil {
+			overview.Version = h.AgentInfo.Version
+		}
+
+		overviews = append(overviews, overview)
+		byAgentID[h.AgentID] = overview
+	}
+
+	for _, activity := range activities {
+		overview, ok := byAgentID[activity.AgentID]
+		if !ok {
+			continue
+		}
+		if activity.Payloads > 0 {
+			overview.Payloads[activity.DiscoveryType] += activity.Payloads
+		}
+		if activity.Errors > 0 {
+			overview.Errors[activity.DiscoveryType] += activity.Errors
+		}
+	}
+
+	return overviews, nil
 }
 
-func (s *hostsService) GetAllTags() ([]string, error) {
-	var tags []string
+func (s *hostsService) Heartbeat(agentID string) error {
+	return s.repository.Heartbeat(agentID)
+}
+
+func (s *hostsService) UpdateCertificateFingerprint(agentID string, fingerprint string) error {
+	return s.repository.UpdateCertificateFingerprint(agentID, fingerprint)
+}
+
+func (s *hostsService) DeleteExpiredEphemeral() ([]string, error) {
+	if s.ephemeralPolicy.TTL == 0 {
+		return nil, nil
+	}
 
-	err := s.db.
-		Model(&models.Tag{}).
-		Order("value").
-		Where("resource_type = ?", models.TagHostResourceType).
-		Distinct().
-		Pluck("value", &tags).
-		Error
+	ephemeralIDs, err := s.repository.GetAllEphemeralIDs(s.ephemeralPolicy.Tag)
+	if err != nil || len(ephemeralIDs) == 0 {
+		return nil, err
+	}
 
+	heartbeats, err := s.repository.GetAllHeartbeats()
 	if err != nil {
 		return nil, err
 	}
 
-	return tags, nil
+	var deleted []string
+	for _, heartbeat := range heartbeats {
+		if !internal.Contains(ephemeralIDs, heartbeat.AgentID) || timeSince(heartbeat.UpdatedAt) <= s.ephemeralPolicy.TTL {
+			continue
+		}
+
+		if err := s.repository.Delete(heartbeat.AgentID); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, heartbeat.AgentID)
+	}
+
+	return deleted, nil
 }
 
-func (s *hostsService) Heartbeat(agentID string) error {
-	heartbeat := &entities.HostHeartbeat{
-		AgentID: agentID,
+func (s *hostsService) MarkStaleHosts() (map[string]string, error) {
+	heartbeats, err := s.repository.GetAllHeartbeats()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]string)
+	for _, heartbeat := range heartbeats {
+		health := s.stalePolicy.health(timeSince(heartbeat.UpdatedAt))
+		if health == heartbeat.Health {
+			continue
+		}
+
+		// a heartbeat received meanwhile marks the host as passing already
+		marked, err := s.repository.MarkHeartbeatHealth(heartbeat.AgentID, heartbeat.UpdatedAt, health)
+		if err != nil {
+			return changed, err
+		}
+		if marked {
+			changed[heartbeat.AgentID] = health
+		}
+	}
+
+	return changed, nil
+}
+
+func (s *hostsService) Decommission(agentID string) (*models.Host, error) {
+	host, err := s.repository.GetByID(agentID)
+	if err != nil || host == nil {
+		return nil, err
+	}
+
+	if err := s.repository.Purge(agentID); err != nil {
+		return nil, err
+	}
+
+	return host.ToModel(), nil
+}
+
+func (s *hostsService) isEphemeral(host *entities.Host) bool {
+	if host.Ephemeral {
+		return true
+	}
+
+	if s.ephemeralPolicy.Tag == "" {
+		return false
+	}
+
+	for _, tag := range host.Tags {
+		if tag.Value == s.ephemeralPolicy.Tag {
+			return true
+		}
 	}
 
-	return s.db.Clauses(clause.OnConflict{
-		Columns: []clause.Column{
-			{Name: "agent_id"},
-		},
-		DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
-	}).Create(heartbeat).Error
+	return false
 }
 
 func initJobsStates() map[string]string {
@@ -268,18 +388,32 @@ func (s *hostsService) GetExportersState(hostname string) (map[string]string, er
 	return jobsState, nil
 }
 
-func computeHealth(host *entities.Host) string {
-	return computeHearbeatHealth(host.Heartbeat)
+// computeHealth returns the health of the host, and since when it has it, if it was marked already
+func computeHealth(host *entities.Host, ephemeral bool, stalePolicy StaleHostsPolicy) (string, *time.Time) {
+	health := computeHearbeatHealth(host.Heartbeat, ephemeral, stalePolicy)
+	if host.Heartbeat == nil || host.Heartbeat.Health == "" {
+		return health, nil
+	}
+
+	return health, host.Heartbeat.HealthChangedAt
 }
 
-func computeHearbeatHealth(hearbeat *entities.HostHeartbeat) string {
+// computeHearbeatHealth is the health marked by the stale hosts detection, computed according to the policy
+// if the host was not marked yet. The silent ephemeral hosts are reported as unknown rather than degraded,
+// as they are expected to go away
+func computeHearbeatHealth(hearbeat *entities.HostHeartbeat, ephemeral bool, stalePolicy StaleHostsPolicy) string {
 	if hearbeat == nil {
 		return models.HostHealthUnknown
 	}
 
-	if timeSince(hearbeat.UpdatedAt) > HeartbeatTreshold {
-		return models.HostHealthCritical
+	health := hearbeat.Health
+	if health == "" {
+		health = stalePolicy.health(timeSince(hearbeat.UpdatedAt))
+	}
+
+	if ephemeral && health != models.HostHealthPassing {
+		return models.HostHealthUnknown
 	}
 
-	return models.HostHealthPassing
+	return health
 }
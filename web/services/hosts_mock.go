@@ -12,6 +12,75 @@ type MockHostsService struct {
 	mock.Mock
 }
 
+// Decommission provides a mock function with given fields: agentID
+func (_m *MockHostsService) Decommission(agentID string) (*models.Host, error) {
+	ret := _m.Called(agentID)
+
+	var r0 *models.Host
+	if rf, ok := ret.Get(0).(func(string) *models.Host); ok {
+		r0 = rf(agentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Host)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteExpiredEphemeral provides a mock function with given fields:
+func (_m *MockHostsService) DeleteExpiredEphemeral() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAgentsOverview provides a mock function with given fields: organization
+func (_m *MockHostsService) GetAgentsOverview(organization string) ([]*models.AgentOverview, error) {
+	ret := _m.Called(organization)
+
+	var r0 []*models.AgentOverview
+	if rf, ok := ret.Get(0).(func(string) []*models.AgentOverview); ok {
+		r0 = rf(organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AgentOverview)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAll provides a mock function with given fields: _a0, _a1
 func (_m *MockHostsService) GetAll(_a0 *HostsFilter, _a1 *Page) (models.HostList, error) {
 	ret := _m.Called(_a0, _a1)
@@ -104,6 +173,29 @@ func (_m *MockHostsService) GetAllTags() ([]string, error) {
 	return r0, r1
 }
 
+// GetAllTemplateVersions provides a mock function with given fields:
+func (_m *MockHostsService) GetAllTemplateVersions() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: _a0
 func (_m *MockHostsService) GetByID(_a0 string) (*models.Host, error) {
 	ret := _m.Called(_a0)
@@ -184,3 +276,40 @@ func (_m *MockHostsService) Heartbeat(agentID string) error {
 
 	return r0
 }
+
+// MarkStaleHosts provides a mock function with given fields:
+func (_m *MockHostsService) MarkStaleHosts() (map[string]string, error) {
+	ret := _m.Called()
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func() map[string]string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCertificateFingerprint provides a mock function with given fields: agentID, fingerprint
+func (_m *MockHostsService) UpdateCertificateFingerprint(agentID string, fingerprint string) error {
+	ret := _m.Called(agentID, fingerprint)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(agentID, fingerprint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
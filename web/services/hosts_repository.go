@@ -0,0 +1,434 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=HostsRepository --inpackage --filename=hosts_repository_mock.go
+
+// HostsRepository is the storage of the hosts aggregate
+type HostsRepository interface {
+	// GetAll filters the hosts by ID, SIDs, tags and template versions, the health filter is resolved by the service
+	GetAll(*HostsFilter, *Page) ([]entities.Host, error)
+	GetByID(string) (*entities.Host, error)
+	GetAllBySAPSystemID(string) ([]entities.Host, error)
+	GetCount() (int, error)
+	GetAllSIDs() ([]string, error)
+	GetAllTags() ([]string, error)
+	GetAllTemplateVersions() ([]string, error)
+	GetAllHeartbeats() ([]entities.HostHeartbeat, error)
+	// GetAgentsActivity returns the hosts of the organization, all of them if empty, with the heartbeat
+	// and the registration of their agent, and the payloads of the agents stored and rejected by discovery type
+	GetAgentsActivity(organization string) ([]entities.Host, []AgentDiscoveryActivity, error)
+	Heartbeat(agentID string) error
+	// MarkHeartbeatHealth marks the health of a host, unless a heartbeat was received since lastHeartbeat.
+	// It returns whether the host was marked
+	MarkHeartbeatHealth(agentID string, lastHeartbeat time.Time, health string) (bool, error)
+	// UpdateCertificateFingerprint is a no-op until the host has been discovered
+	UpdateCertificateFingerprint(agentID string, fingerprint string) error
+	// GetAllEphemeralIDs returns the hosts flagged as ephemeral by their agent or tagged with the given tag
+	GetAllEphemeralIDs(tag string) ([]string, error)
+	// Delete removes the host and the data discovered on it
+	Delete(agentID string) error
+	// Purge removes the host with all the data of its agent, the collected events and its approval included
+	Purge(agentID string) error
+}
+
+// AgentDiscoveryActivity counts the payloads of a discovery of an agent
+type AgentDiscoveryActivity struct {
+	AgentID       string
+	DiscoveryType string
+	// Payloads stored
+	Payloads int64
+	// Errors are the payloads rejected as invalid
+	Errors int64
+}
+
+type hostsRepository struct {
+	db *gorm.DB
+}
+
+func NewHostsRepository(db *gorm.DB) *hostsRepository {
+	return &hostsRepository{db: db}
+}
+
+func (r *hostsRepository) GetAll(filter *HostsFilter, page *Page) ([]entities.Host, error) {
+	var hosts []entities.Host
+
+	var pinned []string
+	if filter != nil {
+		pinned = filter.Pinned
+	}
+
+	db := r.db.
+		Model(&entities.Host{}).
+		Scopes(Paginate(page), OrderPinnedFirst("agent_id", pinned, "name")).
+		Preload("Tags").
+		Preload("Heartbeat").
+		Preload("AgentInfo").
+		Preload("AgentUpgrade").
+		Preload("SAPSystemInstances").
+		Preload("SAPSystemInstances.Host")
+
+	if filter != nil {
+		if len(filter.ID) > 0 {
+			db = db.Where("agent_id IN (?)", filter.ID)
+		}
+
+		if len(filter.SIDs) > 0 {
+			db = db.Where("agent_id IN (?)", r.db.Model(&entities.SAPSystemInstance{}).
+				Select("agent_id").
+				Where("sid IN ?", filter.SIDs),
+			)
+		}
+
+		if len(filter.Tags) > 0 {
+			db = db.Where("agent_id IN (?)", r.db.Model(&models.Tag{}).
+				Select("resource_id").
+				Where("resource_type = ?", models.TagHostResourceType).
+				Where("value IN ?", filter.Tags),
+			)
+		}
+
+		if len(filter.TemplateVersions) > 0 {
+			db = db.Where("provisioning_template_version IN ?", filter.TemplateVersions)
+		}
+
+		db = db.Scopes(organizationScope(filter.Organization, "organization"))
+	}
+
+	err := db.Find(&hosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+}
+
+// GetByID returns nil if the host does not exist
+func (r *hostsRepository) GetByID(id string) (*entities.Host, error) {
+	var host entities.Host
+	err := r.db.
+		Where("agent_id = ?", id).
+		Preload("Tags").
+		Preload("Heartbeat").
+		Preload("AgentInfo").
+		Preload("AgentUpgrade").
+		Preload("SAPSystemInstances").
+		First(&host).
+		Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &host, nil
+}
+
+func (r *hostsRepository) GetAllBySAPSystemID(id string) ([]entities.Host, error) {
+	var hosts []entities.Host
+
+	err := r.db.
+		Order("name").
+		Preload("Tags").
+		Preload("Heartbeat").
+		Preload("AgentInfo").
+		Preload("AgentUpgrade").
+		Preload("SAPSystemInstances").
+		Joins("JOIN sap_system_instances ON sap_system_instances.agent_id = hosts.agent_id").
+		Where("sap_system_instances.id = ?", id).
+		Find(&hosts).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+}
+
+func (r *hostsRepository) GetCount() (int, error) {
+	var count int64
+	err := r.db.Model(&entities.Host{}).Count(&count).Error
+
+	return int(count), err
+}
+
+func (r *hostsRepository) GetAllSIDs() ([]string, error) {
+	var sids pq.StringArray
+
+	err := r.db.
+		Model(&entities.Host{}).
+		Order("sap_system_instances.sid").
+		Joins("JOIN sap_system_instances ON sap_system_instances.agent_id = hosts.agent_id AND sid IS NOT NULL").
+		Distinct().
+		Pluck("sap_system_instances.sid", &sids).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []string(sids), nil
+}
+
+func (r *hostsRepository) GetAllTags() ([]string, error) {
+	var tags []string
+
+	err := r.db.
+		Model(&models.Tag{}).
+		Order("value").
+		Where("resource_type = ?", models.TagHostResourceType).
+		Distinct().
+		Pluck("value", &tags).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *hostsRepository) GetAllTemplateVersions() ([]string, error) {
+	var versions []string
+
+	err := r.db.
+		Model(&entities.Host{}).
+		Where("provisioning_template_version <> ''").
+		Order("provisioning_template_version").
+		Distinct().
+		Pluck("provisioning_template_version", &versions).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (r *hostsRepository) GetAllHeartbeats() ([]entities.HostHeartbeat, error) {
+	var heartbeats []entities.HostHeartbeat
+
+	err := r.db.Find(&heartbeats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return heartbeats, nil
+}
+
+func (r *hostsRepository) GetAgentsActivity(organization string) ([]entities.Host, []AgentDiscoveryActivity, error) {
+	var hosts []entities.Host
+
+	db := r.db.Preload("Tags").Preload("Heartbeat").Preload("AgentInfo").Order("name")
+	if organization != "" {
+		db = db.Where("organization = ?", organization)
+	}
+
+	if err := db.Find(&hosts).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if len(hosts) == 0 {
+		return hosts, nil, nil
+	}
+
+	agentIDs := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		agentIDs = append(agentIDs, host.AgentID)
+	}
+
+	var stored []AgentDiscoveryActivity
+	err := r.db.Model(&datapipeline.DataCollectedEvent{}).
+		Select("agent_id, discovery_type, count(*) AS payloads").
+		Where("agent_id IN ?", agentIDs).
+		Group("agent_id, discovery_type").
+		Scan(&stored).
+		Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rejected []AgentDiscoveryActivity
+	err = r.db.Model(&entities.RejectedPayload{}).
+		Select("agent_id, discovery_type, count(*) AS errors").
+		Where("agent_id IN ?", agentIDs).
+		Group("agent_id, discovery_type").
+		Scan(&rejected).
+		Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hosts, append(stored, rejected...), nil
+}
+
+func (r *hostsRepository) Heartbeat(agentID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		heartbeat := &entities.HostHeartbeat{
+			AgentID:         agentID,
+			UpdatedAt:       now,
+			Health:          models.HostHealthPassing,
+			HealthChangedAt: &now,
+		}
+
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "agent_id"},
+			},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("EXCLUDED.updated_at")},
+				{Column: clause.Column{Name: "health"}, Value: models.HostHealthPassing},
+				{Column: clause.Column{Name: "health_changed_at"}, Value: gorm.Expr(
+					"CASE WHEN host_heartbeats.health = ? THEN host_heartbeats.health_changed_at ELSE EXCLUDED.updated_at END",
+					models.HostHealthPassing)},
+			},
+		}).Create(heartbeat).Error
+		if err != nil {
+			return err
+		}
+
+		return recordHeartbeatPeriod(tx, agentID, heartbeat.UpdatedAt)
+	})
+}
+
+func (r *hostsRepository) MarkHeartbeatHealth(agentID string, lastHeartbeat time.Time, health string) (bool, error) {
+	result := r.db.Model(&entities.HostHeartbeat{}).
+		Where("agent_id = ? AND updated_at = ?", agentID, lastHeartbeat).
+		UpdateColumns(map[string]interface{}{
+			"health":            health,
+			"health_changed_at": time.Now(),
+		})
+
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *hostsRepository) UpdateCertificateFingerprint(agentID string, fingerprint string) error {
+	return r.db.Model(&entities.Host{}).
+		Where("agent_id = ? AND certificate_fingerprint IS DISTINCT FROM ?", agentID, fingerprint).
+		UpdateColumn("certificate_fingerprint", fingerprint).
+		Error
+}
+
+func (r *hostsRepository) GetAllEphemeralIDs(tag string) ([]string, error) {
+	var ids []string
+
+	db := r.db.Model(&entities.Host{}).Where("ephemeral")
+	if tag != "" {
+		db = db.Or("agent_id IN (?)", r.db.Model(&models.Tag{}).
+			Select("resource_id").
+			Where("resource_type = ? AND value = ?", models.TagHostResourceType, tag),
+		)
+	}
+
+	err := db.Order("agent_id").Pluck("agent_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *hostsRepository) Delete(agentID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteHost(tx, agentID)
+	})
+}
+
+func (r *hostsRepository) Purge(agentID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteHost(tx, agentID); err != nil {
+			return err
+		}
+
+		for _, entity := range []interface{}{
+			&datapipeline.DataCollectedEvent{},
+			&entities.RejectedPayload{},
+			&entities.CapturedPayload{},
+			&entities.AgentSigningSecret{},
+			&entities.AgentEnrollment{},
+		} {
+			if err := tx.Where("agent_id = ?", agentID).Delete(entity).Error; err != nil {
+				return err
+			}
+		}
+
+		err := tx.
+			Where("resource_type = ? AND resource_id = ?", models.TagHostResourceType, agentID).
+			Delete(&entities.Favorite{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		// a decommissioned agent coming back is reviewed again
+		return tx.Where("id = ?", agentID).Delete(&entities.Agent{}).Error
+	})
+}
+
+func deleteHost(tx *gorm.DB, agentID string) error {
+	for _, entity := range []interface{}{
+		&entities.HostHeartbeat{},
+		&entities.HostHeartbeatPeriod{},
+		&entities.HostAvailabilityRollup{},
+		&entities.AgentInfo{},
+		&entities.AgentUpgrade{},
+		// the identical payloads are stored again, for the host to come back
+		&entities.PayloadDigest{},
+		&entities.SAPSystemInstance{},
+		&entities.SlesSubscription{},
+		&entities.KubernetesWorkload{},
+		&entities.HostUtilizationSnapshot{},
+		&entities.HostTelemetry{},
+		&entities.Host{},
+	} {
+		if err := tx.Where("agent_id = ?", agentID).Delete(entity).Error; err != nil {
+			return err
+		}
+	}
+
+	err := tx.
+		Where("resource_type = ? AND resource_id = ?", models.TagHostResourceType, agentID).
+		Delete(&models.Tag{}).
+		Error
+	if err != nil {
+		return err
+	}
+
+	return datapipeline.DeleteSearchDocument(tx, models.TagHostResourceType, agentID)
+}
+
+// recordHeartbeatPeriod extends the last heartbeat period of the host,
+// or starts a new one if the host stopped sending heartbeats in the meantime
+func recordHeartbeatPeriod(db *gorm.DB, agentID string, at time.Time) error {
+	var period entities.HostHeartbeatPeriod
+
+	result := db.Where("agent_id", agentID).Order("ended_at DESC").Limit(1).Find(&period)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 && at.Sub(period.EndedAt) <= HeartbeatTreshold {
+		return db.Model(&period).Update("ended_at", at).Error
+	}
+
+	return db.Create(&entities.HostHeartbeatPeriod{
+		AgentID:   agentID,
+		StartedAt: at,
+		EndedAt:   at,
+	}).Error
+}
@@ -0,0 +1,329 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	entities "github.com/trento-project/trento/web/entities"
+
+	time "time"
+)
+
+// MockHostsRepository is an autogenerated mock type for the HostsRepository type
+type MockHostsRepository struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: agentID
+func (_m *MockHostsRepository) Delete(agentID string) error {
+	ret := _m.Called(agentID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(agentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAgentsActivity provides a mock function with given fields: organization
+func (_m *MockHostsRepository) GetAgentsActivity(organization string) ([]entities.Host, []AgentDiscoveryActivity, error) {
+	ret := _m.Called(organization)
+
+	var r0 []entities.Host
+	if rf, ok := ret.Get(0).(func(string) []entities.Host); ok {
+		r0 = rf(organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Host)
+		}
+	}
+
+	var r1 []AgentDiscoveryActivity
+	if rf, ok := ret.Get(1).(func(string) []AgentDiscoveryActivity); ok {
+		r1 = rf(organization)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]AgentDiscoveryActivity)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(organization)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *MockHostsRepository) GetAll(_a0 *HostsFilter, _a1 *Page) ([]entities.Host, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []entities.Host
+	if rf, ok := ret.Get(0).(func(*HostsFilter, *Page) []entities.Host); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Host)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*HostsFilter, *Page) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllBySAPSystemID provides a mock function with given fields: _a0
+func (_m *MockHostsRepository) GetAllBySAPSystemID(_a0 string) ([]entities.Host, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []entities.Host
+	if rf, ok := ret.Get(0).(func(string) []entities.Host); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Host)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllEphemeralIDs provides a mock function with given fields: tag
+func (_m *MockHostsRepository) GetAllEphemeralIDs(tag string) ([]string, error) {
+	ret := _m.Called(tag)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(tag)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(tag)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllHeartbeats provides a mock function with given fields:
+func (_m *MockHostsRepository) GetAllHeartbeats() ([]entities.HostHeartbeat, error) {
+	ret := _m.Called()
+
+	var r0 []entities.HostHeartbeat
+	if rf, ok := ret.Get(0).(func() []entities.HostHeartbeat); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.HostHeartbeat)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllSIDs provides a mock function with given fields:
+func (_m *MockHostsRepository) GetAllSIDs() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllTags provides a mock function with given fields:
+func (_m *MockHostsRepository) GetAllTags() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllTemplateVersions provides a mock function with given fields:
+func (_m *MockHostsRepository) GetAllTemplateVersions() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: _a0
+func (_m *MockHostsRepository) GetByID(_a0 string) (*entities.Host, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *entities.Host
+	if rf, ok := ret.Get(0).(func(string) *entities.Host); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entities.Host)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCount provides a mock function with given fields:
+func (_m *MockHostsRepository) GetCount() (int, error) {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Heartbeat provides a mock function with given fields: agentID
+func (_m *MockHostsRepository) Heartbeat(agentID string) error {
+	ret := _m.Called(agentID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(agentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkHeartbeatHealth provides a mock function with given fields: agentID, lastHeartbeat, health
+func (_m *MockHostsRepository) MarkHeartbeatHealth(agentID string, lastHeartbeat time.Time, health string) (bool, error) {
+	ret := _m.Called(agentID, lastHeartbeat, health)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, time.Time, string) bool); ok {
+		r0 = rf(agentID, lastHeartbeat, health)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, time.Time, string) error); ok {
+		r1 = rf(agentID, lastHeartbeat, health)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: agentID
+func (_m *MockHostsRepository) Purge(agentID string) error {
+	ret := _m.Called(agentID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(agentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCertificateFingerprint provides a mock function with given fields: agentID, fingerprint
+func (_m *MockHostsRepository) UpdateCertificateFingerprint(agentID string, fingerprint string) error {
+	ret := _m.Called(agentID, fingerprint)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(agentID, fingerprint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 	mock "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/datapipeline"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 	"gorm.io/gorm"
@@ -89,7 +91,10 @@ func TestHostsServiceTestSuite(t *testing.T) {
 func (suite *HostsServiceTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&entities.Host{}, &entities.HostHeartbeat{}, &entities.SAPSystemInstance{}, &models.Tag{})
+	suite.db.AutoMigrate(&entities.Host{}, &entities.HostHeartbeat{}, &entities.HostHeartbeatPeriod{}, &entities.HostAvailabilityRollup{}, &entities.SAPSystemInstance{}, &models.Tag{},
+		&entities.SlesSubscription{}, &entities.KubernetesWorkload{}, &entities.HostUtilizationSnapshot{}, &entities.HostTelemetry{}, &entities.SearchDocument{}, &entities.AgentInfo{}, &entities.AgentUpgrade{}, &entities.PayloadDigest{},
+		&datapipeline.DataCollectedEvent{}, &entities.RejectedPayload{}, &entities.CapturedPayload{}, &entities.AgentSigningSecret{}, &entities.AgentEnrollment{},
+		&entities.Favorite{}, &entities.Agent{})
 	hosts := hostsFixtures()
 	err := suite.db.Create(&hosts).Error
 	suite.NoError(err)
@@ -98,14 +103,31 @@ func (suite *HostsServiceTestSuite) SetupSuite() {
 func (suite *HostsServiceTestSuite) TearDownSuite() {
 	suite.db.Migrator().DropTable(&entities.Host{},
 		&entities.HostHeartbeat{},
+		&entities.HostHeartbeatPeriod{},
+		&entities.HostAvailabilityRollup{},
 		&entities.SAPSystemInstance{},
-		&models.Tag{})
+		&models.Tag{},
+		&entities.SlesSubscription{},
+		&entities.KubernetesWorkload{},
+		&entities.HostUtilizationSnapshot{},
+		&entities.HostTelemetry{},
+		&entities.SearchDocument{},
+		&entities.AgentInfo{},
+		&entities.AgentUpgrade{},
+		&entities.PayloadDigest{},
+		&datapipeline.DataCollectedEvent{},
+		&entities.RejectedPayload{},
+		&entities.CapturedPayload{},
+		&entities.AgentSigningSecret{},
+		&entities.AgentEnrollment{},
+		&entities.Favorite{},
+		&entities.Agent{})
 }
 
 func (suite *HostsServiceTestSuite) SetupTest() {
 	suite.tx = suite.db.Begin()
 	suite.prometheusService = new(MockPrometheusService)
-	suite.hostsService = NewHostsService(suite.tx, suite.prometheusService)
+	suite.hostsService = NewHostsService(NewHostsRepository(suite.tx), suite.prometheusService, EphemeralHostsPolicy{Tag: "ephemeral", TTL: time.Hour}, StaleHostsPolicy{})
 }
 
 func (suite *HostsServiceTestSuite) TearDownTest() {
@@ -196,11 +218,118 @@ func (suite *HostsServiceTestSuite) TestHostsService_GetAll_Filters() {
 	suite.Equal("1", hosts[0].ID)
 }
 
+func (suite *HostsServiceTestSuite) TestHostsService_GetAll_OrganizationFilter() {
+	suite.tx.Model(&entities.Host{}).Where("agent_id = ?", "2").Update("organization", "acme")
+
+	hosts, err := suite.hostsService.GetAll(&HostsFilter{Organization: "acme"}, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(hosts))
+	suite.Equal("2", hosts[0].ID)
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_GetAgentsOverview() {
+	suite.tx.Create(&entities.AgentInfo{AgentID: "1", Version: "1.1.0"})
+	suite.tx.Create(&[]datapipeline.DataCollectedEvent{
+		{AgentID: "1", DiscoveryType: "host_discovery", Payload: []byte("{}")},
+		{AgentID: "1", DiscoveryType: "host_discovery", Payload: []byte("{}")},
+		{AgentID: "1", DiscoveryType: "cloud_discovery", Payload: []byte("{}")},
+		{AgentID: "unknown", DiscoveryType: "host_discovery", Payload: []byte("{}")},
+	})
+	suite.tx.Create(&entities.RejectedPayload{AgentID: "1", DiscoveryType: "cloud_discovery", Issues: []byte("[]")})
+
+	overviews, err := suite.hostsService.GetAgentsOverview("")
+	suite.NoError(err)
+	suite.Equal(2, len(overviews))
+
+	suite.Equal("1", overviews[0].AgentID)
+	suite.Equal("host1", overviews[0].Hostname)
+	suite.Equal("1.1.0", overviews[0].Version)
+	suite.NotNil(overviews[0].LastSeenAt)
+	suite.Equal(map[string]int64{"host_discovery": 2, "cloud_discovery": 1}, overviews[0].Payloads)
+	suite.Equal(map[string]int64{"cloud_discovery": 1}, overviews[0].Errors)
+
+	suite.Equal("2", overviews[1].AgentID)
+	suite.Equal("", overviews[1].Version)
+	suite.Empty(overviews[1].Payloads)
+
+	suite.tx.Model(&entities.Host{}).Where("agent_id = ?", "2").Update("organization", "acme")
+
+	overviews, err = suite.hostsService.GetAgentsOverview("acme")
+	suite.NoError(err)
+	suite.Equal(1, len(overviews))
+	suite.Equal("2", overviews[0].AgentID)
+}
+
 func (suite *HostsServiceTestSuite) TestHostsService_GetByID() {
 	host, _ := suite.hostsService.GetByID("1")
 	suite.Equal("host1", host.Name)
 }
 
+func (suite *HostsServiceTestSuite) TestHostsService_GetByIDAgentInfo() {
+	suite.tx.Create(&entities.AgentInfo{AgentID: "1", Version: "1.1.0", OSName: "sles"})
+	suite.tx.Create(&entities.AgentUpgrade{AgentID: "1", Version: "1.2.0", Status: models.AgentUpgradeStatusSent})
+
+	host, err := suite.hostsService.GetByID("1")
+	suite.NoError(err)
+	suite.Equal("1.1.0", host.AgentInfo.Version)
+	suite.Equal("1.1.0", host.RunningAgentVersion())
+	suite.Equal(models.AgentUpgradeStatusSent, host.AgentUpgrade.Status)
+
+	host, err = suite.hostsService.GetByID("2")
+	suite.NoError(err)
+	suite.Nil(host.AgentInfo)
+	suite.Nil(host.AgentUpgrade)
+	suite.Equal("stable", host.RunningAgentVersion())
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_Decommission() {
+	suite.tx.Create(&entities.AgentInfo{AgentID: "1", Version: "1.1.0"})
+	suite.tx.Create(&[]datapipeline.DataCollectedEvent{
+		{AgentID: "1", DiscoveryType: "host_discovery", Payload: []byte("{}")},
+		{AgentID: "2", DiscoveryType: "host_discovery", Payload: []byte("{}")},
+	})
+	suite.tx.Create(&entities.RejectedPayload{AgentID: "1", DiscoveryType: "host_discovery"})
+	suite.tx.Create(&entities.CapturedPayload{AgentID: "1", Body: []byte("{}")})
+	suite.tx.Create(&entities.Favorite{UserID: "1", ResourceType: models.TagHostResourceType, ResourceID: "1"})
+	suite.tx.Create(&entities.Agent{ID: "1", Status: models.AgentStatusApproved})
+
+	host, err := suite.hostsService.Decommission("1")
+	suite.NoError(err)
+	suite.Equal("host1", host.Name)
+
+	for _, entity := range []interface{}{
+		&entities.Host{},
+		&entities.HostHeartbeat{},
+		&entities.SAPSystemInstance{},
+		&entities.AgentInfo{},
+		&datapipeline.DataCollectedEvent{},
+		&entities.RejectedPayload{},
+		&entities.CapturedPayload{},
+	} {
+		var count int64
+		suite.tx.Model(entity).Where("agent_id = ?", "1").Count(&count)
+		suite.EqualValues(0, count, "%T", entity)
+	}
+
+	var count int64
+	suite.tx.Model(&models.Tag{}).Where("resource_id = ?", "1").Count(&count)
+	suite.EqualValues(0, count)
+	suite.tx.Model(&entities.Favorite{}).Count(&count)
+	suite.EqualValues(0, count)
+	suite.tx.Model(&entities.Agent{}).Count(&count)
+	suite.EqualValues(0, count)
+
+	// the other hosts are left untouched
+	suite.tx.Model(&datapipeline.DataCollectedEvent{}).Where("agent_id = ?", "2").Count(&count)
+	suite.EqualValues(1, count)
+	suite.tx.Model(&entities.Host{}).Count(&count)
+	suite.EqualValues(1, count)
+
+	host, err = suite.hostsService.Decommission("1")
+	suite.NoError(err)
+	suite.Nil(host)
+}
+
 func (suite *HostsServiceTestSuite) TestHostsService_GetByID_NotFound() {
 	host, err := suite.hostsService.GetByID("13")
 	suite.NoError(err)
@@ -237,23 +366,120 @@ func (suite *HostsServiceTestSuite) TestHostsService_Heartbeat() {
 	var heartbeat entities.HostHeartbeat
 	suite.tx.First(&heartbeat)
 	suite.Equal("1", heartbeat.AgentID)
+
+	err = suite.hostsService.Heartbeat("1")
+	suite.NoError(err)
+
+	var periods []entities.HostHeartbeatPeriod
+	suite.tx.Where("agent_id", "1").Find(&periods)
+	suite.Equal(1, len(periods))
+	suite.False(periods[0].EndedAt.Before(periods[0].StartedAt))
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_HeartbeatNewPeriod() {
+	lastSeen := time.Now().Add(-HeartbeatTreshold * 2)
+	suite.tx.Create(&entities.HostHeartbeatPeriod{
+		AgentID:   "1",
+		StartedAt: lastSeen.Add(-time.Hour),
+		EndedAt:   lastSeen,
+	})
+
+	err := suite.hostsService.Heartbeat("1")
+	suite.NoError(err)
+
+	var periods []entities.HostHeartbeatPeriod
+	suite.tx.Where("agent_id", "1").Order("started_at").Find(&periods)
+	suite.Equal(2, len(periods))
+	suite.WithinDuration(lastSeen, periods[0].EndedAt, time.Millisecond)
+	suite.Equal(periods[1].StartedAt, periods[1].EndedAt)
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_UpdateCertificateFingerprint() {
+	err := suite.hostsService.UpdateCertificateFingerprint("1", "fingerprint")
+	suite.NoError(err)
+
+	// not discovered yet
+	err = suite.hostsService.UpdateCertificateFingerprint("unknown", "fingerprint")
+	suite.NoError(err)
+
+	host, _ := suite.hostsService.GetByID("1")
+	suite.Equal("fingerprint", host.CertificateFingerprint)
+
+	var count int64
+	suite.tx.Model(&entities.Host{}).Where("agent_id", "unknown").Count(&count)
+	suite.Equal(int64(0), count)
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_DeleteExpiredEphemeral() {
+	timeSince = func(_ time.Time) time.Duration {
+		return 2 * time.Hour
+	}
+
+	suite.tx.Model(&entities.Host{}).Where("agent_id", "2").Update("ephemeral", true)
+
+	host, _ := suite.hostsService.GetByID("2")
+	suite.True(host.Ephemeral)
+	suite.Equal(models.HostHealthUnknown, host.Health)
+
+	deleted, err := suite.hostsService.DeleteExpiredEphemeral()
+	suite.NoError(err)
+	suite.Equal([]string{"2"}, deleted)
+
+	var count int64
+	suite.tx.Model(&entities.Host{}).Where("agent_id", "2").Count(&count)
+	suite.Equal(int64(0), count)
+	suite.tx.Model(&entities.SAPSystemInstance{}).Where("agent_id", "2").Count(&count)
+	suite.Equal(int64(0), count)
+	suite.tx.Model(&models.Tag{}).Where("resource_id", "2").Count(&count)
+	suite.Equal(int64(0), count)
+
+	suite.tx.Create(&models.Tag{Value: "ephemeral", ResourceID: "1", ResourceType: models.TagHostResourceType})
+
+	ids, err := NewHostsRepository(suite.tx).GetAllEphemeralIDs("ephemeral")
+	suite.NoError(err)
+	suite.Equal([]string{"1"}, ids)
 }
 
 func (suite *HostsServiceTestSuite) TestHostsService_computeHealth() {
 	host := hostsFixtures()[0]
+	stalePolicy := StaleHostsPolicy{WarningThreshold: time.Minute, CriticalThreshold: time.Hour}
 
 	timeSince = func(_ time.Time) time.Duration {
 		return time.Duration(0)
 	}
-	suite.Equal(models.HostHealthPassing, computeHealth(&host))
+	health, _ := computeHealth(&host, false, stalePolicy)
+	suite.Equal(models.HostHealthPassing, health)
+	health, _ = computeHealth(&host, true, stalePolicy)
+	suite.Equal(models.HostHealthPassing, health)
+
+	timeSince = func(_ time.Time) time.Duration {
+		return 2 * time.Minute
+	}
+	health, _ = computeHealth(&host, false, stalePolicy)
+	suite.Equal(models.HostHealthWarning, health)
+	health, _ = computeHealth(&host, true, stalePolicy)
+	suite.Equal(models.HostHealthUnknown, health)
 
 	timeSince = func(_ time.Time) time.Duration {
-		return time.Duration(HeartbeatTreshold + 1)
+		return 2 * time.Hour
 	}
-	suite.Equal(models.HostHealthCritical, computeHealth(&host))
+	health, _ = computeHealth(&host, false, stalePolicy)
+	suite.Equal(models.HostHealthCritical, health)
+	health, _ = computeHealth(&host, false, StaleHostsPolicy{})
+	suite.Equal(models.HostHealthCritical, health)
+
+	// the health marked by the stale hosts detection takes precedence
+	changedAt := time.Now()
+	host.Heartbeat.Health = models.HostHealthWarning
+	host.Heartbeat.HealthChangedAt = &changedAt
+	health, since := computeHealth(&host, false, stalePolicy)
+	suite.Equal(models.HostHealthWarning, health)
+	suite.Equal(&changedAt, since)
 
 	host.Heartbeat = nil
-	suite.Equal(models.HostHealthUnknown, computeHealth(&host))
+	health, since = computeHealth(&host, false, stalePolicy)
+	suite.Equal(models.HostHealthUnknown, health)
+	suite.Nil(since)
 }
 
 func (suite *HostsServiceTestSuite) TestHostsService_GetExportersState() {
@@ -300,3 +526,189 @@ func (suite *HostsServiceTestSuite) TestHostsService_GetExportersState_Unknown()
 
 	suite.Equal(expectedStates, states)
 }
+
+func TestHostsService_GetAllHealthFilter(t *testing.T) {
+	timeSince = func(updatedAt time.Time) time.Duration {
+		return time.Since(updatedAt)
+	}
+
+	repository := new(MockHostsRepository)
+	repository.On("GetAllHeartbeats").Return([]entities.HostHeartbeat{
+		{AgentID: "1", UpdatedAt: time.Now()},
+		{AgentID: "2", UpdatedAt: time.Now().Add(-time.Hour)},
+		{AgentID: "3", UpdatedAt: time.Now()},
+	}, nil)
+	repository.On("GetAllEphemeralIDs", "").Return([]string{}, nil)
+	repository.On("GetAll", &HostsFilter{ID: []string{"1"}, Tags: []string{"tag1"}}, (*Page)(nil)).Return([]entities.Host{
+		{AgentID: "1", Name: "host1", Heartbeat: &entities.HostHeartbeat{AgentID: "1", UpdatedAt: time.Now()}},
+	}, nil)
+
+	hostsService := NewHostsService(repository, nil, EphemeralHostsPolicy{}, StaleHostsPolicy{})
+	hosts, err := hostsService.GetAll(&HostsFilter{
+		ID:     []string{"1", "2"},
+		Tags:   []string{"tag1"},
+		Health: []string{models.HostHealthPassing},
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 1)
+	assert.Equal(t, "1", hosts[0].ID)
+	assert.Equal(t, models.HostHealthPassing, hosts[0].Health)
+	repository.AssertExpectations(t)
+}
+
+func TestHostsService_GetAllHealthFilterNoMatch(t *testing.T) {
+	repository := new(MockHostsRepository)
+	repository.On("GetAllHeartbeats").Return([]entities.HostHeartbeat{}, nil)
+	repository.On("GetAllEphemeralIDs", "").Return([]string{}, nil)
+
+	hostsService := NewHostsService(repository, nil, EphemeralHostsPolicy{}, StaleHostsPolicy{})
+	hosts, err := hostsService.GetAll(&HostsFilter{
+		Health: []string{models.HostHealthCritical},
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, hosts)
+	repository.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+}
+
+func TestHostsService_GetByIDNotFound(t *testing.T) {
+	repository := new(MockHostsRepository)
+	repository.On("GetByID", "unknown").Return(nil, nil)
+
+	hostsService := NewHostsService(repository, nil, EphemeralHostsPolicy{}, StaleHostsPolicy{})
+	host, err := hostsService.GetByID("unknown")
+
+	assert.NoError(t, err)
+	assert.Nil(t, host)
+}
+
+func TestHostsService_GetAllHealthFilterEphemeral(t *testing.T) {
+	timeSince = func(updatedAt time.Time) time.Duration {
+		return time.Since(updatedAt)
+	}
+
+	repository := new(MockHostsRepository)
+	repository.On("GetAllHeartbeats").Return([]entities.HostHeartbeat{
+		{AgentID: "1", UpdatedAt: time.Now().Add(-time.Hour)},
+		{AgentID: "2", UpdatedAt: time.Now().Add(-time.Hour)},
+	}, nil)
+	repository.On("GetAllEphemeralIDs", "ephemeral").Return([]string{"2"}, nil)
+	repository.On("GetAll", &HostsFilter{ID: []string{"1"}}, (*Page)(nil)).Return([]entities.Host{
+		{AgentID: "1", Name: "host1", Heartbeat: &entities.HostHeartbeat{AgentID: "1", UpdatedAt: time.Now().Add(-time.Hour)}},
+	}, nil)
+
+	hostsService := NewHostsService(repository, nil, EphemeralHostsPolicy{Tag: "ephemeral"}, StaleHostsPolicy{})
+	hosts, err := hostsService.GetAll(&HostsFilter{
+		Health: []string{models.HostHealthCritical},
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 1)
+	assert.Equal(t, "1", hosts[0].ID)
+	repository.AssertExpectations(t)
+}
+
+func TestHostsService_DeleteExpiredEphemeral(t *testing.T) {
+	timeSince = func(updatedAt time.Time) time.Duration {
+		return time.Since(updatedAt)
+	}
+
+	repository := new(MockHostsRepository)
+	repository.On("GetAllEphemeralIDs", "ephemeral").Return([]string{"2", "3"}, nil)
+	repository.On("GetAllHeartbeats").Return([]entities.HostHeartbeat{
+		{AgentID: "1", UpdatedAt: time.Now().Add(-2 * time.Hour)},
+		{AgentID: "2", UpdatedAt: time.Now().Add(-2 * time.Hour)},
+		{AgentID: "3", UpdatedAt: time.Now().Add(-time.Minute)},
+	}, nil)
+	repository.On("Delete", "2").Return(nil)
+
+	hostsService := NewHostsService(repository, nil, EphemeralHostsPolicy{Tag: "ephemeral", TTL: time.Hour}, StaleHostsPolicy{})
+	deleted, err := hostsService.DeleteExpiredEphemeral()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2"}, deleted)
+	repository.AssertExpectations(t)
+	repository.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestHostsService_DeleteExpiredEphemeralDisabled(t *testing.T) {
+	repository := new(MockHostsRepository)
+
+	hostsService := NewHostsService(repository, nil, EphemeralHostsPolicy{Tag: "ephemeral"}, StaleHostsPolicy{})
+	deleted, err := hostsService.DeleteExpiredEphemeral()
+
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+	repository.AssertNotCalled(t, "GetAllEphemeralIDs", mock.Anything)
+}
+
+func TestHostsService_MarkStaleHosts(t *testing.T) {
+	repository := new(MockHostsRepository)
+	now := time.Now()
+
+	timeSince = func(updatedAt time.Time) time.Duration {
+		return now.Sub(updatedAt)
+	}
+
+	repository.On("GetAllHeartbeats").Return([]entities.HostHeartbeat{
+		{AgentID: "1", UpdatedAt: now, Health: models.HostHealthPassing},
+		{AgentID: "2", UpdatedAt: now.Add(-time.Minute), Health: models.HostHealthPassing},
+		{AgentID: "3", UpdatedAt: now.Add(-time.Hour), Health: models.HostHealthWarning},
+		{AgentID: "4", UpdatedAt: now.Add(-time.Hour), Health: models.HostHealthCritical},
+		{AgentID: "5", UpdatedAt: now.Add(-time.Minute), Health: models.HostHealthPassing},
+	}, nil)
+	repository.On("MarkHeartbeatHealth", "2", now.Add(-time.Minute), models.HostHealthWarning).Return(true, nil)
+	repository.On("MarkHeartbeatHealth", "3", now.Add(-time.Hour), models.HostHealthCritical).Return(true, nil)
+	// a heartbeat was received meanwhile
+	repository.On("MarkHeartbeatHealth", "5", now.Add(-time.Minute), models.HostHealthWarning).Return(false, nil)
+
+	hostsService := NewHostsService(repository, nil, EphemeralHostsPolicy{}, StaleHostsPolicy{
+		WarningThreshold:  30 * time.Second,
+		CriticalThreshold: 10 * time.Minute,
+	})
+	changed, err := hostsService.MarkStaleHosts()
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"2": models.HostHealthWarning, "3": models.HostHealthCritical}, changed)
+	repository.AssertExpectations(t)
+	repository.AssertNumberOfCalls(t, "MarkHeartbeatHealth", 3)
+}
+
+func TestHostsService_GetAgentsOverviewHeartbeat(t *testing.T) {
+	now := time.Now()
+	timeSince = func(updatedAt time.Time) time.Duration {
+		return now.Sub(updatedAt)
+	}
+	defer func() { timeSince = time.Since }()
+
+	repository := new(MockHostsRepository)
+	repository.On("GetAgentsActivity", "").Return([]entities.Host{
+		{AgentID: "1", Name: "host1", Heartbeat: &entities.HostHeartbeat{AgentID: "1", UpdatedAt: now}, AgentInfo: &entities.AgentInfo{AgentID: "1", Version: "1.1.0"}},
+		{AgentID: "2", Name: "host2", Heartbeat: &entities.HostHeartbeat{AgentID: "2", UpdatedAt: now.Add(-time.Hour)}},
+		{AgentID: "3", Name: "host3"},
+	}, []AgentDiscoveryActivity{
+		{AgentID: "1", DiscoveryType: "host_discovery", Payloads: 2},
+		{AgentID: "1", DiscoveryType: "host_discovery", Errors: 1},
+		{AgentID: "2", DiscoveryType: "cloud_discovery", Payloads: 1},
+	}, nil)
+
+	hostsService := NewHostsService(repository, nil, EphemeralHostsPolicy{}, StaleHostsPolicy{})
+	overviews, err := hostsService.GetAgentsOverview("")
+
+	assert.NoError(t, err)
+	assert.Len(t, overviews, 3)
+
+	assert.Equal(t, models.HostHealthPassing, overviews[0].Heartbeat)
+	assert.Equal(t, "1.1.0", overviews[0].Version)
+	assert.Equal(t, map[string]int64{"host_discovery": 2}, overviews[0].Payloads)
+	assert.Equal(t, map[string]int64{"host_discovery": 1}, overviews[0].Errors)
+
+	assert.Equal(t, models.HostHealthCritical, overviews[1].Heartbeat)
+	assert.Equal(t, map[string]int64{"cloud_discovery": 1}, overviews[1].Payloads)
+	assert.Empty(t, overviews[1].Errors)
+
+	assert.Equal(t, models.HostHealthUnknown, overviews[2].Heartbeat)
+	assert.Nil(t, overviews[2].LastSeenAt)
+	repository.AssertExpectations(t)
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// LandscapeGraphTTL is the time the landscape graph is served from the cache before being computed again
+var LandscapeGraphTTL = 30 * time.Second
+
+//go:generate mockery --name=LandscapeService --inpackage --filename=landscape_mock.go
+type LandscapeService interface {
+	// GetGraph returns the landscape of the organization, the whole one if empty
+	GetGraph(organization string) (*models.LandscapeGraph, error)
+}
+
+type landscapeService struct {
+	sapSystemsService SAPSystemsService
+	clustersService   ClustersService
+	hostsService      HostsService
+	mu                sync.Mutex
+	// graphs by organization
+	graphs map[string]*models.LandscapeGraph
+}
+
+func NewLandscapeService(sapSystemsService SAPSystemsService,
+	clustersService ClustersService,
+	hostsService HostsService) *landscapeService {
+	return &landscapeService{
+		sapSystemsService: sapSystemsService,
+		clustersService:   clustersService,
+		hostsService:      hostsService,
+		graphs:            make(map[string]*models.LandscapeGraph),
+	}
+}
+
+// GetGraph returns the topology of the landscape of the organization, of the whole one if empty:
+// SAP systems, databases, clusters and hosts as nodes, the relations among them,
+// including the HANA system replication, as edges
+func (s *landscapeService) GetGraph(organization string) (*models.LandscapeGraph, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if graph, ok := s.graphs[organization]; ok && timeNow().Sub(graph.GeneratedAt) < LandscapeGraphTTL {
+		return graph, nil
+	}
+
+	graph, err := s.buildGraph(organization)
+	if err != nil {
+		return nil, err
+	}
+	s.graphs[organization] = graph
+
+	return graph, nil
+}
+
+func (s *landscapeService) buildGraph(organization string) (*models.LandscapeGraph, error) {
+	builder := newLandscapeGraphBuilder()
+
+	hosts, err := s.hostsService.GetAll(&HostsFilter{Organization: organization}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, host := range hosts {
+		builder.addNode(landscapeNodeID(models.LandscapeNodeHost, host.ID), models.LandscapeNodeHost, host.Name, host.Health, nil)
+	}
+
+	clusters, err := s.clustersService.GetAll(&ClustersFilter{Organization: organization}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cluster := range clusters {
+		builder.addNode(landscapeNodeID(models.LandscapeNodeCluster, cluster.ID), models.LandscapeNodeCluster, cluster.Name, cluster.Health,
+			map[string]string{"cluster_type": cluster.ClusterType})
+	}
+
+	for _, host := range hosts {
+		if host.ClusterID == "" {
+			continue
+		}
+		builder.addEdge(landscapeNodeID(models.LandscapeNodeHost, host.ID), landscapeNodeID(models.LandscapeNodeCluster, host.ClusterID),
+			models.LandscapeEdgeClusterMember, nil)
+	}
+
+	for _, cluster := range clusters {
+		if cluster.ClusterType != models.ClusterTypeHANAScaleUp {
+			continue
+		}
+
+		if err := s.addSystemReplication(builder, cluster.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	databases, err := s.sapSystemsService.GetAllDatabases(&SAPSystemFilter{Organization: organization}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, database := range databases {
+		builder.addSAPSystem(database, models.LandscapeNodeDatabase)
+	}
+
+	applications, err := s.sapSystemsService.GetAllApplications(&SAPSystemFilter{Organization: organization}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, application := range applications {
+		builder.addSAPSystem(application, models.LandscapeNodeSAPSystem)
+
+		if application.AttachedDatabase != nil {
+			builder.addEdge(landscapeNodeID(models.LandscapeNodeSAPSystem, application.ID),
+				landscapeNodeID(models.LandscapeNodeDatabase, application.AttachedDatabase.ID),
+				models.LandscapeEdgeAttachedDatabase, map[string]string{"db_name": application.DBName})
+		}
+	}
+
+	return builder.build(), nil
+}
+
+func (s *landscapeService) addSystemReplication(builder *landscapeGraphBuilder, clusterID string) error {
+	cluster, err := s.clustersService.GetByID(clusterID)
+	if err != nil || cluster == nil {
+		return err
+	}
+
+	details, ok := cluster.Details.(*models.HANAClusterDetails)
+	if !ok {
+		return nil
+	}
+
+	var primary *models.HANAClusterNode
+	for _, node := range details.Nodes {
+		if node.HANAStatus == models.HANAStatusPrimary {
+			primary = node
+			break
+		}
+	}
+
+	if primary == nil {
+		return nil
+	}
+
+	for _, node := range details.Nodes {
+		if node == primary || node.HostID == "" {
+			continue
+		}
+
+		builder.addEdge(landscapeNodeID(models.LandscapeNodeHost, primary.HostID), landscapeNodeID(models.LandscapeNodeHost, node.HostID),
+			models.LandscapeEdgeSystemReplication, map[string]string{
+				"mode":           details.SystemReplicationMode,
+				"operation_mode": details.SystemReplicationOperationMode,
+				"sync_state":     details.SecondarySyncState,
+				"status":         node.HANAStatus,
+			})
+	}
+
+	return nil
+}
+
+func landscapeNodeID(nodeType string, id string) string {
+	return nodeType + ":" + id
+}
+
+// landscapeGraphBuilder drops the duplicated edges and the ones pointing to unknown nodes
+type landscapeGraphBuilder struct {
+	nodes        []*models.LandscapeNode
+	edges        []*models.LandscapeEdge
+	nodeIDs      map[string]bool
+	edgeIDs      map[string]bool
+	pendingEdges []*models.LandscapeEdge
+}
+
+func newLandscapeGraphBuilder() *landscapeGraphBuilder {
+	return &landscapeGraphBuilder{
+		nodes:   []*models.LandscapeNode{},
+		edges:   []*models.LandscapeEdge{},
+		nodeIDs: make(map[string]bool),
+		edgeIDs: make(map[string]bool),
+	}
+}
+
+func (b *landscapeGraphBuilder) addNode(id string, nodeType string, label string, health string, attributes map[string]string) {
+	if b.nodeIDs[id] {
+		return
+	}
+	b.nodeIDs[id] = true
+
+	if health == "" {
+		health = models.HealthSummaryHealthUnknown
+	}
+
+	b.nodes = append(b.nodes, &models.LandscapeNode{
+		ID:         id,
+		Type:       nodeType,
+		Label:      label,
+		Health:     health,
+		Attributes: attributes,
+	})
+}
+
+func (b *landscapeGraphBuilder) addEdge(source string, target string, kind string, attributes map[string]string) {
+	b.pendingEdges = append(b.pendingEdges, &models.LandscapeEdge{
+		Source:     source,
+		Target:     target,
+		Kind:       kind,
+		Attributes: attributes,
+	})
+}
+
+func (b *landscapeGraphBuilder) addSAPSystem(sapSystem *models.SAPSystem, nodeType string) {
+	nodeID := landscapeNodeID(nodeType, sapSystem.ID)
+	b.addNode(nodeID, nodeType, sapSystem.SID, sapSystem.Health, nil)
+
+	for _, instance := range sapSystem.Instances {
+		b.addEdge(nodeID, landscapeNodeID(models.LandscapeNodeHost, instance.HostID), models.LandscapeEdgeInstance,
+			map[string]string{"instance_number": instance.InstanceNumber})
+	}
+}
+
+func (b *landscapeGraphBuilder) build() *models.LandscapeGraph {
+	for _, edge := range b.pendingEdges {
+		if !b.nodeIDs[edge.Source] || !b.nodeIDs[edge.Target] {
+			continue
+		}
+
+		edgeID := edge.Kind + "|" + edge.Source + "|" + edge.Target
+		if b.edgeIDs[edgeID] {
+			continue
+		}
+		b.edgeIDs[edgeID] = true
+
+		b.edges = append(b.edges, edge)
+	}
+
+	return &models.LandscapeGraph{
+		Nodes:       b.nodes,
+		Edges:       b.edges,
+		GeneratedAt: timeNow(),
+	}
+}
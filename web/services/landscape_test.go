@@ -0,0 +1,144 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+func setupLandscapeMocks() (*MockSAPSystemsService, *MockClustersService, *MockHostsService) {
+	sapSystemsService := new(MockSAPSystemsService)
+	clustersService := new(MockClustersService)
+	hostsService := new(MockHostsService)
+
+	hostsService.On("GetAll", mock.Anything, mock.Anything).Return(models.HostList{
+		{ID: "hana01", Name: "hana01", Health: models.HostHealthPassing, ClusterID: "hana_cluster"},
+		{ID: "hana02", Name: "hana02", Health: models.HostHealthUnknown, ClusterID: "hana_cluster"},
+		{ID: "netweaver01", Name: "netweaver01", Health: models.HostHealthWarning},
+	}, nil)
+
+	clustersService.On("GetAll", mock.Anything, mock.Anything).Return(models.ClusterList{
+		{ID: "hana_cluster", Name: "hana_cluster", ClusterType: models.ClusterTypeHANAScaleUp, Health: models.CheckPassing},
+	}, nil)
+	clustersService.On("GetByID", "hana_cluster").Return(&models.Cluster{
+		ID:          "hana_cluster",
+		ClusterType: models.ClusterTypeHANAScaleUp,
+		Details: &models.HANAClusterDetails{
+			SystemReplicationMode:          "sync",
+			SystemReplicationOperationMode: "logreplay",
+			SecondarySyncState:             "SOK",
+			Nodes: models.ClusterNodes{
+				{HostID: "hana02", HANAStatus: models.HANAStatusSecondary},
+				{HostID: "hana01", HANAStatus: models.HANAStatusPrimary},
+			},
+		},
+	}, nil)
+
+	sapSystemsService.On("GetAllDatabases", mock.Anything, mock.Anything).Return(models.SAPSystemList{
+		{
+			ID:     "database_id",
+			SID:    "PRD",
+			Health: models.SAPSystemHealthPassing,
+			Instances: []*models.SAPSystemInstance{
+				{HostID: "hana01", InstanceNumber: "00"},
+				{HostID: "hana02", InstanceNumber: "00"},
+			},
+		},
+	}, nil)
+	sapSystemsService.On("GetAllApplications", mock.Anything, mock.Anything).Return(models.SAPSystemList{
+		{
+			ID:     "application_id",
+			SID:    "HA1",
+			Health: models.SAPSystemHealthWarning,
+			DBName: "PRD",
+			Instances: []*models.SAPSystemInstance{
+				{HostID: "netweaver01", InstanceNumber: "00"},
+				{HostID: "netweaver01", InstanceNumber: "01"},
+				{HostID: "unknown_host", InstanceNumber: "02"},
+			},
+			AttachedDatabase: &models.SAPSystem{ID: "database_id"},
+		},
+	}, nil)
+
+	return sapSystemsService, clustersService, hostsService
+}
+
+func TestLandscapeServiceGetGraph(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	landscapeService := NewLandscapeService(setupLandscapeMocks())
+
+	graph, err := landscapeService.GetGraph("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*models.LandscapeNode{
+		{ID: "host:hana01", Type: models.LandscapeNodeHost, Label: "hana01", Health: models.HostHealthPassing},
+		{ID: "host:hana02", Type: models.LandscapeNodeHost, Label: "hana02", Health: models.HealthSummaryHealthUnknown},
+		{ID: "host:netweaver01", Type: models.LandscapeNodeHost, Label: "netweaver01", Health: models.HostHealthWarning},
+		{ID: "cluster:hana_cluster", Type: models.LandscapeNodeCluster, Label: "hana_cluster", Health: models.CheckPassing,
+			Attributes: map[string]string{"cluster_type": models.ClusterTypeHANAScaleUp}},
+		{ID: "database:database_id", Type: models.LandscapeNodeDatabase, Label: "PRD", Health: models.SAPSystemHealthPassing},
+		{ID: "sap_system:application_id", Type: models.LandscapeNodeSAPSystem, Label: "HA1", Health: models.SAPSystemHealthWarning},
+	}, graph.Nodes)
+
+	assert.Equal(t, []*models.LandscapeEdge{
+		{Source: "host:hana01", Target: "cluster:hana_cluster", Kind: models.LandscapeEdgeClusterMember},
+		{Source: "host:hana02", Target: "cluster:hana_cluster", Kind: models.LandscapeEdgeClusterMember},
+		{Source: "host:hana01", Target: "host:hana02", Kind: models.LandscapeEdgeSystemReplication,
+			Attributes: map[string]string{"mode": "sync", "operation_mode": "logreplay", "sync_state": "SOK", "status": models.HANAStatusSecondary}},
+		{Source: "database:database_id", Target: "host:hana01", Kind: models.LandscapeEdgeInstance,
+			Attributes: map[string]string{"instance_number": "00"}},
+		{Source: "database:database_id", Target: "host:hana02", Kind: models.LandscapeEdgeInstance,
+			Attributes: map[string]string{"instance_number": "00"}},
+		{Source: "sap_system:application_id", Target: "host:netweaver01", Kind: models.LandscapeEdgeInstance,
+			Attributes: map[string]string{"instance_number": "00"}},
+		{Source: "sap_system:application_id", Target: "database:database_id", Kind: models.LandscapeEdgeAttachedDatabase,
+			Attributes: map[string]string{"db_name": "PRD"}},
+	}, graph.Edges)
+
+	assert.Equal(t, now, graph.GeneratedAt)
+}
+
+func TestLandscapeServiceGetGraphCached(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	sapSystemsService, clustersService, hostsService := setupLandscapeMocks()
+	landscapeService := NewLandscapeService(sapSystemsService, clustersService, hostsService)
+
+	first, _ := landscapeService.GetGraph("")
+
+	now = now.Add(LandscapeGraphTTL - time.Second)
+	second, _ := landscapeService.GetGraph("")
+
+	assert.Same(t, first, second)
+	hostsService.AssertNumberOfCalls(t, "GetAll", 1)
+
+	now = now.Add(time.Second)
+	third, _ := landscapeService.GetGraph("")
+
+	assert.NotSame(t, first, third)
+	hostsService.AssertNumberOfCalls(t, "GetAll", 2)
+}
+
+func TestLandscapeServiceGetGraphOrganization(t *testing.T) {
+	sapSystemsService, clustersService, hostsService := setupLandscapeMocks()
+	landscapeService := NewLandscapeService(sapSystemsService, clustersService, hostsService)
+
+	whole, _ := landscapeService.GetGraph("")
+	acme, _ := landscapeService.GetGraph("acme")
+
+	// cached by organization
+	assert.NotSame(t, whole, acme)
+	hostsService.AssertCalled(t, "GetAll", &HostsFilter{Organization: "acme"}, (*Page)(nil))
+	clustersService.AssertCalled(t, "GetAll", &ClustersFilter{Organization: "acme"}, (*Page)(nil))
+	sapSystemsService.AssertCalled(t, "GetAllDatabases", &SAPSystemFilter{Organization: "acme"}, (*Page)(nil))
+	sapSystemsService.AssertCalled(t, "GetAllApplications", &SAPSystemFilter{Organization: "acme"}, (*Page)(nil))
+}
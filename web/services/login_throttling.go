@@ -0,0 +1,201 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+// LoginThrottlingPolicy holds back the logins of the users, and from the addresses, failing to authenticate
+type LoginThrottlingPolicy struct {
+	// MaxFailures in a row lock the user, or address, out for LockoutDuration, 0 disables the throttling
+	MaxFailures int
+	// Backoff is the delay imposed after the first failure, doubling at every following one
+	Backoff time.Duration
+	// LockoutDuration is also the time after which the failures are forgotten
+	LockoutDuration time.Duration
+}
+
+//go:generate mockery --name=LoginThrottlingService --inpackage --filename=login_throttling_mock.go
+
+type LoginThrottlingService interface {
+	// BlockedUntil returns the time before which the logins of the user, or from the address, are rejected,
+	// the zero time if they are allowed
+	BlockedUntil(username string, address string) (time.Time, error)
+	// RecordFailure returns the throttles of the user and of the address updated with the failed login
+	RecordFailure(username string, address string) ([]*models.LoginThrottle, error)
+	// RecordSuccess forgets the failures of the user. The ones of the address are kept,
+	// otherwise logging in with a valid account would reset them while guessing the passwords of the others
+	RecordSuccess(username string) error
+	// GetLockouts returns the users and addresses currently locked out, the most recent first
+	GetLockouts() ([]*models.LoginThrottle, error)
+	// Unlock returns nil if the user, or address, was not throttled
+	Unlock(throttleType string, subject string) (*models.LoginThrottle, error)
+}
+
+type loginThrottlingService struct {
+	db     *gorm.DB
+	policy LoginThrottlingPolicy
+}
+
+func NewLoginThrottlingService(db *gorm.DB, policy LoginThrottlingPolicy) *loginThrottlingService {
+	return &loginThrottlingService{db: db, policy: policy}
+}
+
+func (s *loginThrottlingService) BlockedUntil(username string, address string) (time.Time, error) {
+	if s.policy.MaxFailures <= 0 {
+		return time.Time{}, nil
+	}
+
+	var throttles []entities.LoginThrottle
+	err := s.db.
+		Where("(type = ? AND subject = ?) OR (type = ? AND subject = ?)",
+			models.LoginThrottleUser, username, models.LoginThrottleAddress, address).
+		Where("blocked_until > ?", timeNow()).
+		Find(&throttles).
+		Error
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var blockedUntil time.Time
+	for _, throttle := range throttles {
+		if throttle.BlockedUntil.After(blockedUntil) {
+			blockedUntil = throttle.BlockedUntil
+		}
+	}
+
+	return blockedUntil, nil
+}
+
+func (s *loginThrottlingService) RecordFailure(username string, address string) ([]*models.LoginThrottle, error) {
+	if s.policy.MaxFailures <= 0 {
+		return nil, nil
+	}
+
+	var throttles []*models.LoginThrottle
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// always locked in the same order, not to deadlock with the concurrent logins
+		for _, key := range [][2]string{
+			{models.LoginThrottleUser, username},
+			{models.LoginThrottleAddress, address},
+		} {
+			throttle, err := s.recordFailure(tx, key[0], key[1])
+			if err != nil {
+				return err
+			}
+			throttles = append(throttles, throttle)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return throttles, nil
+}
+
+func (s *loginThrottlingService) recordFailure(tx *gorm.DB, throttleType string, subject string) (*models.LoginThrottle, error) {
+	now := timeNow()
+
+	// the throttle is created beforehand, so that the concurrent failures wait for each other on its lock
+	// rather than all finding it missing and overwriting each other's count
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entities.LoginThrottle{Type: throttleType, Subject: subject}).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []entities.LoginThrottle
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("type = ? AND subject = ?", throttleType, subject).
+		Limit(1).
+		Find(&stored).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	throttle := entities.LoginThrottle{Type: throttleType, Subject: subject}
+	// the failures are forgotten once the lockout duration passes without new ones
+	if len(stored) > 0 && now.Sub(stored[0].LastFailureAt) < s.policy.LockoutDuration {
+		throttle = stored[0]
+	}
+
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	if throttle.Failures >= s.policy.MaxFailures {
+		throttle.Locked = true
+		throttle.BlockedUntil = now.Add(s.policy.LockoutDuration)
+	} else {
+		throttle.BlockedUntil = now.Add(s.backoff(throttle.Failures))
+	}
+
+	err = tx.Save(&throttle).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return throttle.ToModel(), nil
+}
+
+// backoff doubles at every failure, never exceeding the lockout duration
+func (s *loginThrottlingService) backoff(failures int) time.Duration {
+	backoff := s.policy.Backoff
+	for i := 1; i < failures && backoff < s.policy.LockoutDuration; i++ {
+		backoff *= 2
+	}
+
+	if backoff > s.policy.LockoutDuration {
+		return s.policy.LockoutDuration
+	}
+
+	return backoff
+}
+
+func (s *loginThrottlingService) RecordSuccess(username string) error {
+	return s.db.
+		Where("type = ? AND subject = ?", models.LoginThrottleUser, username).
+		Delete(&entities.LoginThrottle{}).
+		Error
+}
+
+func (s *loginThrottlingService) GetLockouts() ([]*models.LoginThrottle, error) {
+	var throttles []entities.LoginThrottle
+	err := s.db.
+		Where("locked AND blocked_until > ?", timeNow()).
+		Order("blocked_until DESC").
+		Find(&throttles).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	lockouts := []*models.LoginThrottle{}
+	for _, throttle := range throttles {
+		lockouts = append(lockouts, throttle.ToModel())
+	}
+
+	return lockouts, nil
+}
+
+func (s *loginThrottlingService) Unlock(throttleType string, subject string) (*models.LoginThrottle, error) {
+	var throttles []entities.LoginThrottle
+	err := s.db.Where("type = ? AND subject = ?", throttleType, subject).Limit(1).Find(&throttles).Error
+	if err != nil || len(throttles) == 0 {
+		return nil, err
+	}
+
+	err = s.db.Where("type = ? AND subject = ?", throttleType, subject).Delete(&entities.LoginThrottle{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return throttles[0].ToModel(), nil
+}
@@ -0,0 +1,119 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+
+	time "time"
+)
+
+// MockLoginThrottlingService is an autogenerated mock type for the LoginThrottlingService type
+type MockLoginThrottlingService struct {
+	mock.Mock
+}
+
+// BlockedUntil provides a mock function with given fields: username, address
+func (_m *MockLoginThrottlingService) BlockedUntil(username string, address string) (time.Time, error) {
+	ret := _m.Called(username, address)
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func(string, string) time.Time); ok {
+		r0 = rf(username, address)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(username, address)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLockouts provides a mock function with given fields:
+func (_m *MockLoginThrottlingService) GetLockouts() ([]*models.LoginThrottle, error) {
+	ret := _m.Called()
+
+	var r0 []*models.LoginThrottle
+	if rf, ok := ret.Get(0).(func() []*models.LoginThrottle); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.LoginThrottle)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordFailure provides a mock function with given fields: username, address
+func (_m *MockLoginThrottlingService) RecordFailure(username string, address string) ([]*models.LoginThrottle, error) {
+	ret := _m.Called(username, address)
+
+	var r0 []*models.LoginThrottle
+	if rf, ok := ret.Get(0).(func(string, string) []*models.LoginThrottle); ok {
+		r0 = rf(username, address)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.LoginThrottle)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(username, address)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordSuccess provides a mock function with given fields: username
+func (_m *MockLoginThrottlingService) RecordSuccess(username string) error {
+	ret := _m.Called(username)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Unlock provides a mock function with given fields: throttleType, subject
+func (_m *MockLoginThrottlingService) Unlock(throttleType string, subject string) (*models.LoginThrottle, error) {
+	ret := _m.Called(throttleType, subject)
+
+	var r0 *models.LoginThrottle
+	if rf, ok := ret.Get(0).(func(string, string) *models.LoginThrottle); ok {
+		r0 = rf(throttleType, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LoginThrottle)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(throttleType, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type LoginThrottlingServiceTestSuite struct {
+	suite.Suite
+	db                     *gorm.DB
+	tx                     *gorm.DB
+	loginThrottlingService *loginThrottlingService
+	now                    time.Time
+}
+
+func TestLoginThrottlingServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(LoginThrottlingServiceTestSuite))
+}
+
+func (suite *LoginThrottlingServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.LoginThrottle{})
+}
+
+func (suite *LoginThrottlingServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.LoginThrottle{})
+}
+
+func (suite *LoginThrottlingServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.loginThrottlingService = NewLoginThrottlingService(suite.tx, LoginThrottlingPolicy{
+		MaxFailures:     3,
+		Backoff:         time.Second,
+		LockoutDuration: 15 * time.Minute,
+	})
+
+	suite.now = time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return suite.now }
+}
+
+func (suite *LoginThrottlingServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *LoginThrottlingServiceTestSuite) TestLoginThrottlingService_Backoff() {
+	throttles, err := suite.loginThrottlingService.RecordFailure("admin", "192.0.2.1")
+	suite.NoError(err)
+	suite.Equal(2, len(throttles))
+	suite.Equal(models.LoginThrottleUser, throttles[0].Type)
+	suite.Equal(models.LoginThrottleAddress, throttles[1].Type)
+	suite.Equal(suite.now.Add(time.Second), throttles[0].BlockedUntil.UTC())
+
+	blockedUntil, err := suite.loginThrottlingService.BlockedUntil("admin", "198.51.100.1")
+	suite.NoError(err)
+	suite.Equal(suite.now.Add(time.Second), blockedUntil.UTC())
+
+	// the backoff doubles at every failure
+	suite.now = suite.now.Add(time.Second)
+	throttles, err = suite.loginThrottlingService.RecordFailure("admin", "198.51.100.1")
+	suite.NoError(err)
+	suite.Equal(2, throttles[0].Failures)
+	suite.Equal(suite.now.Add(2*time.Second), throttles[0].BlockedUntil.UTC())
+	suite.Equal(1, throttles[1].Failures)
+
+	suite.now = suite.now.Add(2 * time.Second)
+	blockedUntil, err = suite.loginThrottlingService.BlockedUntil("admin", "203.0.113.1")
+	suite.NoError(err)
+	suite.True(blockedUntil.IsZero())
+
+	suite.NoError(suite.loginThrottlingService.RecordSuccess("admin"))
+	throttles, err = suite.loginThrottlingService.RecordFailure("admin", "192.0.2.1")
+	suite.NoError(err)
+	suite.Equal(1, throttles[0].Failures)
+	// the failures from the address are kept
+	suite.Equal(2, throttles[1].Failures)
+}
+
+func (suite *LoginThrottlingServiceTestSuite) TestLoginThrottlingService_Lockout() {
+	for i := 0; i < 3; i++ {
+		suite.now = suite.now.Add(time.Minute)
+		_, err := suite.loginThrottlingService.RecordFailure("admin", "192.0.2.1")
+		suite.NoError(err)
+	}
+
+	lockouts, err := suite.loginThrottlingService.GetLockouts()
+	suite.NoError(err)
+	suite.Equal(2, len(lockouts))
+	suite.True(lockouts[0].Locked)
+	suite.Equal(suite.now.Add(15*time.Minute), lockouts[0].BlockedUntil.UTC())
+
+	unlocked, err := suite.loginThrottlingService.Unlock(models.LoginThrottleUser, "admin")
+	suite.NoError(err)
+	suite.Equal("admin", unlocked.Subject)
+
+	unlocked, err = suite.loginThrottlingService.Unlock(models.LoginThrottleUser, "admin")
+	suite.NoError(err)
+	suite.Nil(unlocked)
+
+	// the failures are forgotten once the lockout is over
+	suite.now = suite.now.Add(15 * time.Minute)
+	lockouts, err = suite.loginThrottlingService.GetLockouts()
+	suite.NoError(err)
+	suite.Equal(0, len(lockouts))
+
+	throttles, err := suite.loginThrottlingService.RecordFailure("admin", "192.0.2.1")
+	suite.NoError(err)
+	suite.Equal(1, throttles[1].Failures)
+	suite.False(throttles[1].Locked)
+}
+
+func (suite *LoginThrottlingServiceTestSuite) TestLoginThrottlingService_ConcurrentFailures() {
+	// the concurrent transactions cannot run within the one of the test
+	loginThrottlingService := NewLoginThrottlingService(suite.db, LoginThrottlingPolicy{
+		MaxFailures:     100,
+		Backoff:         time.Second,
+		LockoutDuration: 15 * time.Minute,
+	})
+	defer suite.db.Where("subject IN ?", []string{"concurrent", "192.0.2.9"}).Delete(&entities.LoginThrottle{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := loginThrottlingService.RecordFailure("concurrent", "192.0.2.9")
+			suite.NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	var throttles []entities.LoginThrottle
+	suite.db.Where("subject IN ?", []string{"concurrent", "192.0.2.9"}).Find(&throttles)
+	suite.Equal(2, len(throttles))
+	for _, throttle := range throttles {
+		suite.Equal(10, throttle.Failures)
+	}
+}
+
+func (suite *LoginThrottlingServiceTestSuite) TestLoginThrottlingService_Disabled() {
+	loginThrottlingService := NewLoginThrottlingService(suite.tx, LoginThrottlingPolicy{})
+
+	throttles, err := loginThrottlingService.RecordFailure("admin", "192.0.2.1")
+	suite.NoError(err)
+	suite.Nil(throttles)
+
+	blockedUntil, err := loginThrottlingService.BlockedUntil("admin", "192.0.2.1")
+	suite.NoError(err)
+	suite.True(blockedUntil.IsZero())
+}
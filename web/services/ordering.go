@@ -0,0 +1,23 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderPinnedFirst sorts the rows whose column is one of the pinned values before the others,
+// keeping the given order within each of the two groups
+func OrderPinnedFirst(column string, pinned []string, order string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(pinned) == 0 {
+			return db.Order(order)
+		}
+
+		return db.Clauses(clause.OrderBy{
+			Expression: clause.Expr{
+				SQL:  column + " IN (?) DESC, " + order,
+				Vars: []interface{}{pinned},
+			},
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+//go:generate mockery --name=OrganizationsService --inpackage --filename=organizations_mock.go
+
+// OrganizationsService tells the organization of the resources, the hosts, clusters and SAP systems
+// being visible to the users of the organization of their agents only
+type OrganizationsService interface {
+	// GetResourceOrganization returns the organization of the host, cluster, SAP system or database,
+	// false if it does not exist
+	GetResourceOrganization(resourceType string, resourceID string) (string, bool, error)
+}
+
+type organizationsService struct {
+	db *gorm.DB
+}
+
+func NewOrganizationsService(db *gorm.DB) *organizationsService {
+	return &organizationsService{db: db}
+}
+
+func (s *organizationsService) GetResourceOrganization(resourceType string, resourceID string) (string, bool, error) {
+	var db *gorm.DB
+
+	switch resourceType {
+	case models.TagHostResourceType:
+		db = s.db.Model(&entities.Host{}).Where("agent_id = ?", resourceID)
+	case models.TagClusterResourceType:
+		db = s.db.Model(&entities.Cluster{}).Where("id = ?", resourceID)
+	case models.TagSAPSystemResourceType:
+		db = s.db.Model(&entities.SAPSystemInstance{}).Where("id = ? AND type = ?", resourceID, models.SAPSystemTypeApplication)
+	case models.TagDatabaseResourceType:
+		db = s.db.Model(&entities.SAPSystemInstance{}).Where("id = ? AND type = ?", resourceID, models.SAPSystemTypeDatabase)
+	default:
+		return "", false, fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+
+	var organizations []string
+	if err := db.Limit(1).Pluck("organization", &organizations).Error; err != nil {
+		return "", false, err
+	}
+
+	if len(organizations) == 0 {
+		return "", false, nil
+	}
+
+	return organizations[0], true, nil
+}
+
+// organizationScope restricts the query on the hosts, clusters or SAP system instances, carrying the organization
+// of their agents in the given column, to the ones of the organization. All of them are kept if empty
+func organizationScope(organization string, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organization == "" {
+			return db
+		}
+
+		return db.Where(column+" = ?", organization)
+	}
+}
+
+// resourceOrganizationScope restricts the query to the rows referencing, by type and ID, a host, cluster,
+// SAP system or database of the organization. All of them are kept if empty
+func resourceOrganizationScope(organization string, resourceTypeColumn string, resourceIDColumn string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organization == "" {
+			return db
+		}
+
+		newDB := db.Session(&gorm.Session{NewDB: true})
+		hosts := newDB.Model(&entities.Host{}).Select("agent_id").Where("organization = ?", organization)
+		clusters := newDB.Model(&entities.Cluster{}).Select("id").Where("organization = ?", organization)
+		sapSystems := newDB.Model(&entities.SAPSystemInstance{}).Select("id").Where("organization = ?", organization)
+
+		return db.Where(
+			"(("+resourceTypeColumn+" = ? AND "+resourceIDColumn+" IN (?)) OR "+
+				"("+resourceTypeColumn+" = ? AND "+resourceIDColumn+" IN (?)) OR "+
+				"("+resourceTypeColumn+" IN ? AND "+resourceIDColumn+" IN (?)))",
+			models.TagHostResourceType, hosts,
+			models.TagClusterResourceType, clusters,
+			[]string{models.TagSAPSystemResourceType, models.TagDatabaseResourceType}, sapSystems)
+	}
+}
+
+// agentOrganizationScope restricts the query to the rows of the agents, in the given column, whose host belongs
+// to the organization. The agents not having reported their host yet are only visible when empty
+func agentOrganizationScope(organization string, agentIDColumn string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organization == "" {
+			return db
+		}
+
+		hosts := db.Session(&gorm.Session{NewDB: true}).Model(&entities.Host{}).Select("agent_id").Where("organization = ?", organization)
+
+		return db.Where(agentIDColumn+" IN (?)", hosts)
+	}
+}
@@ -0,0 +1,38 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockOrganizationsService is an autogenerated mock type for the OrganizationsService type
+type MockOrganizationsService struct {
+	mock.Mock
+}
+
+// GetResourceOrganization provides a mock function with given fields: resourceType, resourceID
+func (_m *MockOrganizationsService) GetResourceOrganization(resourceType string, resourceID string) (string, bool, error) {
+	ret := _m.Called(resourceType, resourceID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(resourceType, resourceID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string, string) bool); ok {
+		r1 = rf(resourceType, resourceID)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, string) error); ok {
+		r2 = rf(resourceType, resourceID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
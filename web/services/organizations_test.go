@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type OrganizationsServiceTestSuite struct {
+	suite.Suite
+	db                   *gorm.DB
+	tx                   *gorm.DB
+	organizationsService *organizationsService
+}
+
+func TestOrganizationsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(OrganizationsServiceTestSuite))
+}
+
+func (suite *OrganizationsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{})
+}
+
+func (suite *OrganizationsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{})
+}
+
+func (suite *OrganizationsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.organizationsService = NewOrganizationsService(suite.tx)
+
+	suite.tx.Create(&entities.Host{AgentID: "host1", Organization: "acme"})
+	suite.tx.Create(&entities.Cluster{ID: "cluster1"})
+	suite.tx.Create(&entities.SAPSystemInstance{ID: "sapsystem1", AgentID: "host1", InstanceNumber: "00", Type: models.SAPSystemTypeApplication, Organization: "acme"})
+	suite.tx.Create(&entities.SAPSystemInstance{ID: "database1", AgentID: "host1", InstanceNumber: "10", Type: models.SAPSystemTypeDatabase, Organization: "globex"})
+}
+
+func (suite *OrganizationsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *OrganizationsServiceTestSuite) TestOrganizationsService_GetResourceOrganization() {
+	for _, tc := range []struct {
+		resourceType string
+		resourceID   string
+		organization string
+		found        bool
+	}{
+		{models.TagHostResourceType, "host1", "acme", true},
+		{models.TagHostResourceType, "unknown", "", false},
+		{models.TagClusterResourceType, "cluster1", "", true},
+		{models.TagSAPSystemResourceType, "sapsystem1", "acme", true},
+		{models.TagSAPSystemResourceType, "database1", "", false},
+		{models.TagDatabaseResourceType, "database1", "globex", true},
+	} {
+		organization, found, err := suite.organizationsService.GetResourceOrganization(tc.resourceType, tc.resourceID)
+		suite.NoError(err)
+		suite.Equal(tc.organization, organization, tc.resourceID)
+		suite.Equal(tc.found, found, tc.resourceID)
+	}
+
+	_, _, err := suite.organizationsService.GetResourceOrganization("unknown", "host1")
+	suite.Error(err)
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	// MaxPayloadCaptureSizeBytes caps the size of every captured payload, whatever the settings
+	MaxPayloadCaptureSizeBytes = 1 << 20
+	// maxCapturedPayloads are kept, the oldest ones are dropped first
+	maxCapturedPayloads = 500
+	// capturedPayloadsRetention after which the captured payloads are dropped
+	capturedPayloadsRetention = 24 * time.Hour
+	// payloadCaptureSettingsTTL is how long the settings are cached, as they are checked on every collected payload
+	payloadCaptureSettingsTTL = 10 * time.Second
+)
+
+// captureSample returns a number in [0, 100) to sample the payloads with
+var captureSample = func() int {
+	return rand.Intn(100)
+}
+
+//go:generate mockery --name=PayloadCaptureService --inpackage --filename=payload_capture_mock.go
+
+// PayloadCaptureService stores the raw collected payloads, before they are decoded, to debug the projectors
+type PayloadCaptureService interface {
+	GetSettings() (*models.PayloadCaptureSettings, error)
+	SaveSettings(settings *models.PayloadCaptureSettings) error
+	// Capture stores the payload if the capture is active and selects it
+	Capture(agentID string, payload []byte) error
+	// GetAll returns the captured payloads, the most recent first, optionally of a single agent.
+	// Only the payloads of the agents of the organization are returned unless empty
+	GetAll(agentID string, organization string) ([]*models.CapturedPayload, error)
+	GetByID(id int64, organization string) (*models.CapturedPayload, error)
+}
+
+type payloadCaptureService struct {
+	db               *gorm.DB
+	settingsMutex    sync.Mutex
+	settings         *models.PayloadCaptureSettings
+	settingsLoadedAt time.Time
+}
+
+func NewPayloadCaptureService(db *gorm.DB) *payloadCaptureService {
+	return &payloadCaptureService{db: db}
+}
+
+func (s *payloadCaptureService) GetSettings() (*models.PayloadCaptureSettings, error) {
+	var settings entities.Settings
+	err := s.db.First(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PayloadCaptureSettings{
+		SamplingPercentage: settings.PayloadCaptureSamplingPercentage,
+		AgentID:            settings.PayloadCaptureAgentID,
+		MaxSizeBytes:       settings.PayloadCaptureMaxSizeBytes,
+		ExpiresAt:          settings.PayloadCaptureExpiresAt,
+	}, nil
+}
+
+func (s *payloadCaptureService) SaveSettings(settings *models.PayloadCaptureSettings) error {
+	err := s.db.Model(&entities.Settings{}).Where("1 = 1").Updates(map[string]interface{}{
+		"payload_capture_sampling_percentage": settings.SamplingPercentage,
+		"payload_capture_agent_id":            settings.AgentID,
+		"payload_capture_max_size_bytes":      settings.MaxSizeBytes,
+		"payload_capture_expires_at":          settings.ExpiresAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+	s.settings = nil
+
+	return nil
+}
+
+func (s *payloadCaptureService) Capture(agentID string, payload []byte) error {
+	settings, err := s.cachedSettings()
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	if !settings.IsActive(now) {
+		return nil
+	}
+
+	if !strings.EqualFold(settings.AgentID, agentID) && captureSample() >= settings.SamplingPercentage {
+		return nil
+	}
+
+	maxSize := settings.MaxSizeBytes
+	if maxSize <= 0 || maxSize > MaxPayloadCaptureSizeBytes {
+		maxSize = MaxPayloadCaptureSizeBytes
+	}
+
+	captured := &entities.CapturedPayload{
+		CreatedAt: now,
+		AgentID:   agentID,
+		Size:      len(payload),
+		Body:      payload,
+	}
+	if len(payload) > maxSize {
+		captured.Body = payload[:maxSize]
+		captured.Truncated = true
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(captured).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("created_at < ? OR id <= ?", now.Add(-capturedPayloadsRetention), captured.ID-maxCapturedPayloads).
+			Delete(&entities.CapturedPayload{}).
+			Error
+	})
+}
+
+func (s *payloadCaptureService) GetAll(agentID string, organization string) ([]*models.CapturedPayload, error) {
+	var capturedPayloads []entities.CapturedPayload
+
+	db := s.db.Scopes(agentOrganizationScope(organization, "agent_id")).Where("created_at >= ?", timeNow().Add(-capturedPayloadsRetention))
+	if agentID != "" {
+		db = db.Where("agent_id = ?", agentID)
+	}
+
+	err := db.Order("id DESC").Find(&capturedPayloads).Error
+	if err != nil {
+		return nil, err
+	}
+
+	payloads := []*models.CapturedPayload{}
+	for _, p := range capturedPayloads {
+		payloads = append(payloads, p.ToModel())
+	}
+
+	return payloads, nil
+}
+
+func (s *payloadCaptureService) GetByID(id int64, organization string) (*models.CapturedPayload, error) {
+	var capturedPayload entities.CapturedPayload
+
+	err := s.db.Scopes(agentOrganizationScope(organization, "agent_id")).Where("created_at >= ?", timeNow().Add(-capturedPayloadsRetention)).
+		First(&capturedPayload, id).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return capturedPayload.ToModel(), nil
+}
+
+// cachedSettings spares a query for every collected payload,
+// the settings saved by another instance of the web server are picked up once the cache expires
+func (s *payloadCaptureService) cachedSettings() (*models.PayloadCaptureSettings, error) {
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+
+	if s.settings != nil && timeNow().Sub(s.settingsLoadedAt) < payloadCaptureSettingsTTL {
+		return s.settings, nil
+	}
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	s.settings = settings
+	s.settingsLoadedAt = timeNow()
+
+	return settings, nil
+}
@@ -0,0 +1,110 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockPayloadCaptureService is an autogenerated mock type for the PayloadCaptureService type
+type MockPayloadCaptureService struct {
+	mock.Mock
+}
+
+// Capture provides a mock function with given fields: agentID, payload
+func (_m *MockPayloadCaptureService) Capture(agentID string, payload []byte) error {
+	ret := _m.Called(agentID, payload)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []byte) error); ok {
+		r0 = rf(agentID, payload)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: agentID, organization
+func (_m *MockPayloadCaptureService) GetAll(agentID string, organization string) ([]*models.CapturedPayload, error) {
+	ret := _m.Called(agentID, organization)
+
+	var r0 []*models.CapturedPayload
+	if rf, ok := ret.Get(0).(func(string, string) []*models.CapturedPayload); ok {
+		r0 = rf(agentID, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.CapturedPayload)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(agentID, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: id, organization
+func (_m *MockPayloadCaptureService) GetByID(id int64, organization string) (*models.CapturedPayload, error) {
+	ret := _m.Called(id, organization)
+
+	var r0 *models.CapturedPayload
+	if rf, ok := ret.Get(0).(func(int64, string) *models.CapturedPayload); ok {
+		r0 = rf(id, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CapturedPayload)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, string) error); ok {
+		r1 = rf(id, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSettings provides a mock function with given fields:
+func (_m *MockPayloadCaptureService) GetSettings() (*models.PayloadCaptureSettings, error) {
+	ret := _m.Called()
+
+	var r0 *models.PayloadCaptureSettings
+	if rf, ok := ret.Get(0).(func() *models.PayloadCaptureSettings); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PayloadCaptureSettings)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveSettings provides a mock function with given fields: settings
+func (_m *MockPayloadCaptureService) SaveSettings(settings *models.PayloadCaptureSettings) error {
+	ret := _m.Called(settings)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.PayloadCaptureSettings) error); ok {
+		r0 = rf(settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type PayloadCaptureServiceTestSuite struct {
+	suite.Suite
+	db                    *gorm.DB
+	tx                    *gorm.DB
+	payloadCaptureService *payloadCaptureService
+	now                   time.Time
+}
+
+func TestPayloadCaptureServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PayloadCaptureServiceTestSuite))
+}
+
+func (suite *PayloadCaptureServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Settings{}, &entities.CapturedPayload{})
+}
+
+func (suite *PayloadCaptureServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.Settings{}, &entities.CapturedPayload{})
+}
+
+func (suite *PayloadCaptureServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.tx.Create(&entities.Settings{InstallationID: "59fd8017-b7fd-477b-9ebe-b658c558f3e9"})
+	suite.payloadCaptureService = NewPayloadCaptureService(suite.tx)
+
+	suite.now = time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return suite.now }
+	captureSample = func() int { return 50 }
+}
+
+func (suite *PayloadCaptureServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *PayloadCaptureServiceTestSuite) saveSettings(settings *models.PayloadCaptureSettings) {
+	suite.NoError(suite.payloadCaptureService.SaveSettings(settings))
+}
+
+func (suite *PayloadCaptureServiceTestSuite) TestPayloadCaptureService_Settings() {
+	settings, err := suite.payloadCaptureService.GetSettings()
+	suite.NoError(err)
+	suite.Equal(65536, settings.MaxSizeBytes)
+	suite.False(settings.IsActive(suite.now))
+
+	expiresAt := suite.now.Add(time.Hour)
+	suite.saveSettings(&models.PayloadCaptureSettings{SamplingPercentage: 10, AgentID: "agent1", MaxSizeBytes: 1024, ExpiresAt: &expiresAt})
+
+	settings, err = suite.payloadCaptureService.GetSettings()
+	suite.NoError(err)
+	suite.Equal(10, settings.SamplingPercentage)
+	suite.Equal("agent1", settings.AgentID)
+	suite.Equal(1024, settings.MaxSizeBytes)
+	suite.True(settings.IsActive(suite.now))
+	suite.False(settings.IsActive(expiresAt))
+}
+
+func (suite *PayloadCaptureServiceTestSuite) TestPayloadCaptureService_CaptureInactive() {
+	suite.NoError(suite.payloadCaptureService.Capture("agent1", []byte(`{"agent_id":"agent1"}`)))
+
+	expiresAt := suite.now
+	suite.saveSettings(&models.PayloadCaptureSettings{SamplingPercentage: 100, MaxSizeBytes: 1024, ExpiresAt: &expiresAt})
+	suite.NoError(suite.payloadCaptureService.Capture("agent1", []byte(`{"agent_id":"agent1"}`)))
+
+	payloads, err := suite.payloadCaptureService.GetAll("", "")
+	suite.NoError(err)
+	suite.Equal(0, len(payloads))
+}
+
+func (suite *PayloadCaptureServiceTestSuite) TestPayloadCaptureService_CaptureSelection() {
+	expiresAt := suite.now.Add(time.Hour)
+	suite.saveSettings(&models.PayloadCaptureSettings{SamplingPercentage: 40, AgentID: "agent1", MaxSizeBytes: 1024, ExpiresAt: &expiresAt})
+
+	suite.NoError(suite.payloadCaptureService.Capture("agent1", []byte(`{"agent_id":"agent1"}`)))
+	suite.NoError(suite.payloadCaptureService.Capture("agent2", []byte(`{"agent_id":"agent2"}`)))
+
+	captureSample = func() int { return 39 }
+	suite.NoError(suite.payloadCaptureService.Capture("agent3", []byte(`not json`)))
+
+	payloads, err := suite.payloadCaptureService.GetAll("", "")
+	suite.NoError(err)
+	suite.Equal(2, len(payloads))
+	suite.Equal("agent3", payloads[0].AgentID)
+	suite.Equal("not json", payloads[0].Body)
+	suite.Equal("agent1", payloads[1].AgentID)
+
+	payloads, err = suite.payloadCaptureService.GetAll("agent1", "")
+	suite.NoError(err)
+	suite.Equal(1, len(payloads))
+
+	payload, err := suite.payloadCaptureService.GetByID(payloads[0].ID, "")
+	suite.NoError(err)
+	suite.Equal(`{"agent_id":"agent1"}`, payload.Body)
+	suite.Equal(suite.now, payload.CreatedAt.UTC())
+}
+
+func (suite *PayloadCaptureServiceTestSuite) TestPayloadCaptureService_CaptureTruncated() {
+	expiresAt := suite.now.Add(time.Hour)
+	suite.saveSettings(&models.PayloadCaptureSettings{AgentID: "agent1", MaxSizeBytes: 8, ExpiresAt: &expiresAt})
+
+	suite.NoError(suite.payloadCaptureService.Capture("agent1", []byte(`{"agent_id":"agent1"}`)))
+
+	payloads, err := suite.payloadCaptureService.GetAll("agent1", "")
+	suite.NoError(err)
+	suite.Equal(1, len(payloads))
+	suite.Equal(`{"agent_`, payloads[0].Body)
+	suite.Equal(21, payloads[0].Size)
+	suite.True(payloads[0].Truncated)
+}
+
+func (suite *PayloadCaptureServiceTestSuite) TestPayloadCaptureService_CaptureExpiry() {
+	expiresAt := suite.now.Add(48 * time.Hour)
+	suite.saveSettings(&models.PayloadCaptureSettings{AgentID: "agent1", MaxSizeBytes: 1024, ExpiresAt: &expiresAt})
+
+	suite.NoError(suite.payloadCaptureService.Capture("agent1", []byte(`old`)))
+	old, err := suite.payloadCaptureService.GetAll("agent1", "")
+	suite.NoError(err)
+
+	suite.now = suite.now.Add(25 * time.Hour)
+	suite.NoError(suite.payloadCaptureService.Capture("agent1", []byte(`new`)))
+
+	payloads, err := suite.payloadCaptureService.GetAll("agent1", "")
+	suite.NoError(err)
+	suite.Equal(1, len(payloads))
+	suite.Equal("new", payloads[0].Body)
+
+	payload, err := suite.payloadCaptureService.GetByID(old[0].ID, "")
+	suite.NoError(err)
+	suite.Nil(payload)
+
+	var count int64
+	suite.tx.Model(&entities.CapturedPayload{}).Count(&count)
+	suite.Equal(int64(1), count)
+}
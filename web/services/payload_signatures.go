@@ -0,0 +1,122 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/internal/signing"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+// ErrPayloadSignatureRequired is returned verifying the payloads of the agents without a secret,
+// when the signature is required
+var ErrPayloadSignatureRequired = errors.New("the payloads must be signed, the agent has no signing secret")
+
+const signingSecretSize = 32
+
+//go:generate mockery --name=PayloadSignaturesService --inpackage --filename=payload_signatures_mock.go
+
+// PayloadSignaturesService manages the secrets the agents sign their payloads with, see the signing package
+type PayloadSignaturesService interface {
+	// Rotate generates a new secret for the agent, replacing the previous one, it is only returned once
+	Rotate(agentID string) (*models.AgentSigningSecret, error)
+	// Delete returns nil if the agent has no secret
+	Delete(agentID string) (*models.AgentSigningSecret, error)
+	// Verify checks the signature of the payload of the agent. The payloads of the agents without a secret
+	// are not checked, unless the signature is required. The timestamp is checked against the time the payload was received
+	Verify(agentID string, timestamp string, signature string, body []byte, receivedAt time.Time) error
+}
+
+type payloadSignaturesService struct {
+	db               *gorm.DB
+	requireSignature bool
+	// credentialsCipher encrypts the secrets, stored in plaintext if nil
+	credentialsCipher CredentialsCipher
+}
+
+func NewPayloadSignaturesService(db *gorm.DB, requireSignature bool, credentialsCipher CredentialsCipher) *payloadSignaturesService {
+	return &payloadSignaturesService{db: db, requireSignature: requireSignature, credentialsCipher: credentialsCipher}
+}
+
+func (s *payloadSignaturesService) Rotate(agentID string) (*models.AgentSigningSecret, error) {
+	random := make([]byte, signingSecretSize)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(random)
+
+	stored := secret
+	if s.credentialsCipher != nil {
+		var err error
+		if stored, err = s.credentialsCipher.Encrypt(secret); err != nil {
+			return nil, err
+		}
+	}
+
+	signingSecret := entities.AgentSigningSecret{AgentID: agentID, Secret: stored, CreatedAt: time.Now()}
+	err := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&signingSecret).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := signingSecret.ToModel()
+	result.Secret = secret
+
+	return result, nil
+}
+
+func (s *payloadSignaturesService) get(agentID string) (*entities.AgentSigningSecret, error) {
+	var secrets []entities.AgentSigningSecret
+	err := s.db.Where("agent_id = ?", agentID).Limit(1).Find(&secrets).Error
+	if err != nil || len(secrets) == 0 {
+		return nil, err
+	}
+
+	return &secrets[0], nil
+}
+
+func (s *payloadSignaturesService) Delete(agentID string) (*models.AgentSigningSecret, error) {
+	signingSecret, err := s.get(agentID)
+	if err != nil || signingSecret == nil {
+		return nil, err
+	}
+
+	err = s.db.Where("agent_id = ?", agentID).Delete(&entities.AgentSigningSecret{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return signingSecret.ToModel(), nil
+}
+
+func (s *payloadSignaturesService) Verify(agentID string, timestamp string, signature string, body []byte, receivedAt time.Time) error {
+	signingSecret, err := s.get(agentID)
+	if err != nil {
+		return err
+	}
+
+	if signingSecret == nil {
+		if s.requireSignature {
+			return ErrPayloadSignatureRequired
+		}
+		return nil
+	}
+
+	secret := signingSecret.Secret
+	if IsEncryptedCredential(secret) {
+		if s.credentialsCipher == nil {
+			return errors.New("the signing secret is encrypted but no credentials key is configured")
+		}
+		if secret, err = s.credentialsCipher.Decrypt(secret); err != nil {
+			return err
+		}
+	}
+
+	return signing.Verify(secret, timestamp, signature, body, receivedAt)
+}
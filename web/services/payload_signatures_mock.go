@@ -0,0 +1,75 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+
+	time "time"
+)
+
+// MockPayloadSignaturesService is an autogenerated mock type for the PayloadSignaturesService type
+type MockPayloadSignaturesService struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: agentID
+func (_m *MockPayloadSignaturesService) Delete(agentID string) (*models.AgentSigningSecret, error) {
+	ret := _m.Called(agentID)
+
+	var r0 *models.AgentSigningSecret
+	if rf, ok := ret.Get(0).(func(string) *models.AgentSigningSecret); ok {
+		r0 = rf(agentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AgentSigningSecret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Rotate provides a mock function with given fields: agentID
+func (_m *MockPayloadSignaturesService) Rotate(agentID string) (*models.AgentSigningSecret, error) {
+	ret := _m.Called(agentID)
+
+	var r0 *models.AgentSigningSecret
+	if rf, ok := ret.Get(0).(func(string) *models.AgentSigningSecret); ok {
+		r0 = rf(agentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AgentSigningSecret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Verify provides a mock function with given fields: agentID, timestamp, signature, body, receivedAt
+func (_m *MockPayloadSignaturesService) Verify(agentID string, timestamp string, signature string, body []byte, receivedAt time.Time) error {
+	ret := _m.Called(agentID, timestamp, signature, body, receivedAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, []byte, time.Time) error); ok {
+		r0 = rf(agentID, timestamp, signature, body, receivedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
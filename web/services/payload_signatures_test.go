@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/signing"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+type PayloadSignaturesServiceTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestPayloadSignaturesServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PayloadSignaturesServiceTestSuite))
+}
+
+func (suite *PayloadSignaturesServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.AgentSigningSecret{})
+}
+
+func (suite *PayloadSignaturesServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.AgentSigningSecret{})
+}
+
+func (suite *PayloadSignaturesServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+}
+
+func (suite *PayloadSignaturesServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *PayloadSignaturesServiceTestSuite) TestRotateAndVerify() {
+	envelopeCipher, _ := NewEnvelopeCipher(credentialsKeyFixture(1))
+	payloadSignaturesService := NewPayloadSignaturesService(suite.tx, false, envelopeCipher)
+	body := []byte(`{"agent_id": "agent1"}`)
+
+	// the payloads of the agents without a secret are not checked
+	suite.NoError(payloadSignaturesService.Verify("agent1", "", "", body, time.Now()))
+
+	signingSecret, err := payloadSignaturesService.Rotate("agent1")
+	suite.NoError(err)
+	suite.Equal("agent1", signingSecret.AgentID)
+	suite.NotEmpty(signingSecret.Secret)
+
+	var stored entities.AgentSigningSecret
+	suite.tx.First(&stored, "agent_id = ?", "agent1")
+	suite.True(IsEncryptedCredential(stored.Secret))
+
+	timestamp, signature := signing.Sign(signingSecret.Secret, time.Now(), body)
+	suite.NoError(payloadSignaturesService.Verify("agent1", timestamp, signature, body, time.Now()))
+	suite.Equal(signing.ErrInvalidSignature, payloadSignaturesService.Verify("agent1", "", "", body, time.Now()))
+
+	rotated, err := payloadSignaturesService.Rotate("agent1")
+	suite.NoError(err)
+	suite.NotEqual(signingSecret.Secret, rotated.Secret)
+	suite.Equal(signing.ErrInvalidSignature, payloadSignaturesService.Verify("agent1", timestamp, signature, body, time.Now()))
+}
+
+func (suite *PayloadSignaturesServiceTestSuite) TestRequiredSignature() {
+	payloadSignaturesService := NewPayloadSignaturesService(suite.tx, true, nil)
+
+	suite.Equal(ErrPayloadSignatureRequired, payloadSignaturesService.Verify("agent1", "", "", nil, time.Now()))
+}
+
+func (suite *PayloadSignaturesServiceTestSuite) TestDelete() {
+	payloadSignaturesService := NewPayloadSignaturesService(suite.tx, false, nil)
+
+	_, err := payloadSignaturesService.Rotate("agent1")
+	suite.NoError(err)
+
+	deleted, err := payloadSignaturesService.Delete("agent1")
+	suite.NoError(err)
+	suite.Equal("agent1", deleted.AgentID)
+	suite.Empty(deleted.Secret)
+
+	deleted, err = payloadSignaturesService.Delete("agent1")
+	suite.NoError(err)
+	suite.Nil(deleted)
+
+	suite.NoError(payloadSignaturesService.Verify("agent1", "", "", nil, time.Now()))
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	// PersonalAccessTokenPrefix tells the personal access tokens apart from the API keys
+	PersonalAccessTokenPrefix       = "trento_pat_"
+	personalAccessTokenRandomBytes  = 32
+	personalAccessTokenPrefixLength = len(PersonalAccessTokenPrefix) + 6
+)
+
+//go:generate mockery --name=PersonalAccessTokensService --inpackage --filename=personal_access_tokens_mock.go
+
+type PersonalAccessTokensService interface {
+	// Create returns the new token, the only time it can be read in clear
+	Create(userID int64, name string, scopes []string, expiresAt time.Time) (*models.PersonalAccessToken, error)
+	GetAll() ([]*models.PersonalAccessToken, error)
+	GetByUser(userID int64) ([]*models.PersonalAccessToken, error)
+	// Get returns nil if the token does not exist
+	Get(id int64) (*models.PersonalAccessToken, error)
+	// Revoke returns nil if the token does not exist
+	Revoke(id int64) (*models.PersonalAccessToken, error)
+	// Authenticate returns nil if the token does not exist, it is revoked or expired,
+	// otherwise it records the token usage and returns the user owning it
+	Authenticate(token string) (*models.PersonalAccessToken, *models.User, error)
+}
+
+type personalAccessTokensService struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokensService(db *gorm.DB) *personalAccessTokensService {
+	return &personalAccessTokensService{db: db}
+}
+
+func (s *personalAccessTokensService) Create(userID int64, name string, scopes []string, expiresAt time.Time) (*models.PersonalAccessToken, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	for _, scope := range scopes {
+		if !models.IsValidTokenScope(scope) {
+			return nil, fmt.Errorf("invalid scope: %s", scope)
+		}
+	}
+
+	random := make([]byte, personalAccessTokenRandomBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	token := PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	personalAccessToken := entities.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    token[:personalAccessTokenPrefixLength],
+		TokenHash: hashApiKey(token),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+
+	err := s.db.Create(&personalAccessToken).Error
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.Get(personalAccessToken.ID)
+	if err != nil {
+		return nil, err
+	}
+	created.Token = token
+
+	return created, nil
+}
+
+func (s *personalAccessTokensService) GetAll() ([]*models.PersonalAccessToken, error) {
+	return s.find(s.db)
+}
+
+func (s *personalAccessTokensService) GetByUser(userID int64) ([]*models.PersonalAccessToken, error) {
+	return s.find(s.db.Where("user_id = ?", userID))
+}
+
+func (s *personalAccessTokensService) find(db *gorm.DB) ([]*models.PersonalAccessToken, error) {
+	var personalAccessTokens []entities.PersonalAccessToken
+
+	err := db.Preload("User").Order("created_at DESC, id DESC").Find(&personalAccessTokens).Error
+	if err != nil {
+		return nil, err
+	}
+
+	personalAccessTokenList := []*models.PersonalAccessToken{}
+	for _, personalAccessToken := range personalAccessTokens {
+		personalAccessTokenList = append(personalAccessTokenList, personalAccessToken.ToModel())
+	}
+
+	return personalAccessTokenList, nil
+}
+
+func (s *personalAccessTokensService) get(id int64) (*entities.PersonalAccessToken, error) {
+	var personalAccessToken entities.PersonalAccessToken
+
+	err := s.db.Preload("User").First(&personalAccessToken, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &personalAccessToken, nil
+}
+
+func (s *personalAccessTokensService) Get(id int64) (*models.PersonalAccessToken, error) {
+	personalAccessToken, err := s.get(id)
+	if err != nil || personalAccessToken == nil {
+		return nil, err
+	}
+
+	return personalAccessToken.ToModel(), nil
+}
+
+func (s *personalAccessTokensService) Revoke(id int64) (*models.PersonalAccessToken, error) {
+	personalAccessToken, err := s.get(id)
+	if err != nil || personalAccessToken == nil {
+		return nil, err
+	}
+
+	// Revoking twice keeps the original revocation time
+	if personalAccessToken.RevokedAt == nil {
+		now := timeNow()
+		err = s.db.Model(personalAccessToken).Update("revoked_at", now).Error
+		if err != nil {
+			return nil, err
+		}
+		personalAccessToken.RevokedAt = &now
+	}
+
+	return personalAccessToken.ToModel(), nil
+}
+
+func (s *personalAccessTokensService) Authenticate(token string) (*models.PersonalAccessToken, *models.User, error) {
+	var personalAccessToken entities.PersonalAccessToken
+
+	now := timeNow()
+	err := s.db.
+		Preload("User").
+		Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", hashApiKey(token), now).
+		First(&personalAccessToken).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if personalAccessToken.LastUsedAt == nil || now.Sub(*personalAccessToken.LastUsedAt) >= ApiKeyLastUsedResolution {
+		err = s.db.Model(&personalAccessToken).Update("last_used_at", now).Error
+		if err != nil {
+			return nil, nil, err
+		}
+		personalAccessToken.LastUsedAt = &now
+	}
+
+	return personalAccessToken.ToModel(), personalAccessToken.User.ToModel(), nil
+}
@@ -0,0 +1,162 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+
+	time "time"
+)
+
+// MockPersonalAccessTokensService is an autogenerated mock type for the PersonalAccessTokensService type
+type MockPersonalAccessTokensService struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: token
+func (_m *MockPersonalAccessTokensService) Authenticate(token string) (*models.PersonalAccessToken, *models.User, error) {
+	ret := _m.Called(token)
+
+	var r0 *models.PersonalAccessToken
+	if rf, ok := ret.Get(0).(func(string) *models.PersonalAccessToken); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PersonalAccessToken)
+		}
+	}
+
+	var r1 *models.User
+	if rf, ok := ret.Get(1).(func(string) *models.User); ok {
+		r1 = rf(token)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.User)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Create provides a mock function with given fields: userID, name, scopes, expiresAt
+func (_m *MockPersonalAccessTokensService) Create(userID int64, name string, scopes []string, expiresAt time.Time) (*models.PersonalAccessToken, error) {
+	ret := _m.Called(userID, name, scopes, expiresAt)
+
+	var r0 *models.PersonalAccessToken
+	if rf, ok := ret.Get(0).(func(int64, string, []string, time.Time) *models.PersonalAccessToken); ok {
+		r0 = rf(userID, name, scopes, expiresAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PersonalAccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, string, []string, time.Time) error); ok {
+		r1 = rf(userID, name, scopes, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: id
+func (_m *MockPersonalAccessTokensService) Get(id int64) (*models.PersonalAccessToken, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.PersonalAccessToken
+	if rf, ok := ret.Get(0).(func(int64) *models.PersonalAccessToken); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PersonalAccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields:
+func (_m *MockPersonalAccessTokensService) GetAll() ([]*models.PersonalAccessToken, error) {
+	ret := _m.Called()
+
+	var r0 []*models.PersonalAccessToken
+	if rf, ok := ret.Get(0).(func() []*models.PersonalAccessToken); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PersonalAccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUser provides a mock function with given fields: userID
+func (_m *MockPersonalAccessTokensService) GetByUser(userID int64) ([]*models.PersonalAccessToken, error) {
+	ret := _m.Called(userID)
+
+	var r0 []*models.PersonalAccessToken
+	if rf, ok := ret.Get(0).(func(int64) []*models.PersonalAccessToken); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PersonalAccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: id
+func (_m *MockPersonalAccessTokensService) Revoke(id int64) (*models.PersonalAccessToken, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.PersonalAccessToken
+	if rf, ok := ret.Get(0).(func(int64) *models.PersonalAccessToken); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PersonalAccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokensServiceTestSuite struct {
+	suite.Suite
+	db                          *gorm.DB
+	tx                          *gorm.DB
+	personalAccessTokensService *personalAccessTokensService
+	alice                       entities.User
+	bob                         entities.User
+}
+
+func TestPersonalAccessTokensServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PersonalAccessTokensServiceTestSuite))
+}
+
+func (suite *PersonalAccessTokensServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.User{}, &entities.PersonalAccessToken{})
+}
+
+func (suite *PersonalAccessTokensServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.PersonalAccessToken{}, &entities.User{})
+}
+
+func (suite *PersonalAccessTokensServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.personalAccessTokensService = NewPersonalAccessTokensService(suite.tx)
+
+	suite.alice = entities.User{Username: "alice", PasswordHash: "hash", Role: models.UserRoleOperator}
+	suite.bob = entities.User{Username: "bob", PasswordHash: "hash", Role: models.UserRoleViewer}
+	suite.tx.Create(&suite.alice)
+	suite.tx.Create(&suite.bob)
+}
+
+func (suite *PersonalAccessTokensServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *PersonalAccessTokensServiceTestSuite) TestPersonalAccessTokensService_CreateAndAuthenticate() {
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	created, err := suite.personalAccessTokensService.Create(suite.alice.ID, "ci", []string{models.TokenScopeCatalogWrite}, expiresAt)
+	suite.NoError(err)
+	suite.Equal("ci", created.Name)
+	suite.Equal("alice", created.Username)
+	suite.Equal([]string{models.TokenScopeCatalogWrite}, created.Scopes)
+	suite.True(expiresAt.Equal(created.ExpiresAt))
+	suite.True(strings.HasPrefix(created.Token, PersonalAccessTokenPrefix))
+	suite.True(strings.HasPrefix(created.Token, created.Prefix))
+
+	var personalAccessToken entities.PersonalAccessToken
+	suite.tx.First(&personalAccessToken)
+	suite.NotContains(personalAccessToken.TokenHash, created.Token)
+
+	authenticated, user, err := suite.personalAccessTokensService.Authenticate(created.Token)
+	suite.NoError(err)
+	suite.Equal(created.ID, authenticated.ID)
+	suite.Empty(authenticated.Token)
+	suite.NotNil(authenticated.LastUsedAt)
+	suite.Equal("alice", user.Username)
+	suite.Equal(models.UserRoleOperator, user.Role)
+
+	authenticated, user, err = suite.personalAccessTokensService.Authenticate(PersonalAccessTokenPrefix + "wrong")
+	suite.NoError(err)
+	suite.Nil(authenticated)
+	suite.Nil(user)
+}
+
+func (suite *PersonalAccessTokensServiceTestSuite) TestPersonalAccessTokensService_CreateInvalid() {
+	expiresAt := time.Now().Add(time.Hour)
+
+	_, err := suite.personalAccessTokensService.Create(suite.alice.ID, "", []string{models.TokenScopeHostsRead}, expiresAt)
+	suite.Error(err)
+
+	_, err = suite.personalAccessTokensService.Create(suite.alice.ID, "ci", []string{}, expiresAt)
+	suite.Error(err)
+
+	_, err = suite.personalAccessTokensService.Create(suite.alice.ID, "ci", []string{"users:write"}, expiresAt)
+	suite.Error(err)
+}
+
+func (suite *PersonalAccessTokensServiceTestSuite) TestPersonalAccessTokensService_AuthenticateExpired() {
+	created, _ := suite.personalAccessTokensService.Create(suite.alice.ID, "ci", []string{models.TokenScopeHostsRead}, time.Now().Add(time.Hour))
+
+	timeNow = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	authenticated, _, err := suite.personalAccessTokensService.Authenticate(created.Token)
+	suite.NoError(err)
+	suite.Nil(authenticated)
+}
+
+func (suite *PersonalAccessTokensServiceTestSuite) TestPersonalAccessTokensService_Revoke() {
+	expiresAt := time.Now().Add(time.Hour)
+	created, _ := suite.personalAccessTokensService.Create(suite.alice.ID, "ci", []string{models.TokenScopeHostsRead}, expiresAt)
+	suite.personalAccessTokensService.Create(suite.bob.ID, "dashboard", []string{models.TokenScopeHostsRead}, expiresAt)
+
+	revoked, err := suite.personalAccessTokensService.Revoke(created.ID)
+	suite.NoError(err)
+	suite.NotNil(revoked.RevokedAt)
+
+	authenticated, _, err := suite.personalAccessTokensService.Authenticate(created.Token)
+	suite.NoError(err)
+	suite.Nil(authenticated)
+
+	revoked, err = suite.personalAccessTokensService.Revoke(-1)
+	suite.NoError(err)
+	suite.Nil(revoked)
+
+	personalAccessTokens, err := suite.personalAccessTokensService.GetAll()
+	suite.NoError(err)
+	suite.Equal(2, len(personalAccessTokens))
+
+	personalAccessTokens, err = suite.personalAccessTokensService.GetByUser(suite.bob.ID)
+	suite.NoError(err)
+	suite.Equal(1, len(personalAccessTokens))
+	suite.Equal("dashboard", personalAccessTokens[0].Name)
+}
+
+func (suite *PersonalAccessTokensServiceTestSuite) TestPersonalAccessTokensService_DeletedWithUser() {
+	created, _ := suite.personalAccessTokensService.Create(suite.alice.ID, "ci", []string{models.TokenScopeHostsRead}, time.Now().Add(time.Hour))
+
+	suite.tx.Delete(&suite.alice)
+
+	authenticated, _, err := suite.personalAccessTokensService.Authenticate(created.Token)
+	suite.NoError(err)
+	suite.Nil(authenticated)
+}
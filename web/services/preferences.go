@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=PreferencesService --inpackage --filename=preferences_mock.go
+
+type PreferencesService interface {
+	GetDashboardLayout(userID string) ([]string, error)
+	SaveDashboardLayout(userID string, widgets []string) error
+}
+
+type preferencesService struct {
+	db *gorm.DB
+}
+
+func NewPreferencesService(db *gorm.DB) *preferencesService {
+	return &preferencesService{db: db}
+}
+
+// GetDashboardLayout returns the ordered list of widgets of the user, nil if the user never customized it
+func (s *preferencesService) GetDashboardLayout(userID string) ([]string, error) {
+	var preferences entities.UserPreferences
+
+	err := s.db.Where("user_id", userID).First(&preferences).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return preferences.DashboardLayout, nil
+}
+
+func (s *preferencesService) SaveDashboardLayout(userID string, widgets []string) error {
+	preferences := entities.UserPreferences{
+		UserID:          userID,
+		DashboardLayout: widgets,
+	}
+
+	return s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "user_id"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"dashboard_layout", "updated_at"}),
+	}).Create(&preferences).Error
+}
@@ -0,0 +1,47 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockPreferencesService is an autogenerated mock type for the PreferencesService type
+type MockPreferencesService struct {
+	mock.Mock
+}
+
+// GetDashboardLayout provides a mock function with given fields: userID
+func (_m *MockPreferencesService) GetDashboardLayout(userID string) ([]string, error) {
+	ret := _m.Called(userID)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveDashboardLayout provides a mock function with given fields: userID, widgets
+func (_m *MockPreferencesService) SaveDashboardLayout(userID string, widgets []string) error {
+	ret := _m.Called(userID, widgets)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(userID, widgets)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+type PreferencesServiceTestSuite struct {
+	suite.Suite
+	db                 *gorm.DB
+	tx                 *gorm.DB
+	preferencesService *preferencesService
+}
+
+func TestPreferencesServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PreferencesServiceTestSuite))
+}
+
+func (suite *PreferencesServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(entities.UserPreferences{})
+}
+
+func (suite *PreferencesServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(entities.UserPreferences{})
+}
+
+func (suite *PreferencesServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.preferencesService = NewPreferencesService(suite.tx)
+}
+
+func (suite *PreferencesServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *PreferencesServiceTestSuite) TestPreferencesService_GetDashboardLayoutNotCustomized() {
+	layout, err := suite.preferencesService.GetDashboardLayout("user")
+
+	suite.NoError(err)
+	suite.Nil(layout)
+}
+
+func (suite *PreferencesServiceTestSuite) TestPreferencesService_SaveDashboardLayout() {
+	err := suite.preferencesService.SaveDashboardLayout("user", []string{"widget1", "widget2"})
+	suite.NoError(err)
+
+	err = suite.preferencesService.SaveDashboardLayout("user", []string{"widget2"})
+	suite.NoError(err)
+
+	layout, err := suite.preferencesService.GetDashboardLayout("user")
+	suite.NoError(err)
+	suite.Equal([]string{"widget2"}, layout)
+
+	layout, err = suite.preferencesService.GetDashboardLayout("other")
+	suite.NoError(err)
+	suite.Nil(layout)
+}
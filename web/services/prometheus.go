@@ -2,7 +2,8 @@ package services
 
 import (
 	"context"
-	"fmt"
+	"net"
+	"strconv"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -11,6 +12,7 @@ import (
 	"gorm.io/gorm"
 
 	prometheusModel "github.com/prometheus/common/model"
+	internalHosts "github.com/trento-project/trento/internal/hosts"
 	prometheusInternal "github.com/trento-project/trento/internal/prometheus"
 )
 
@@ -21,7 +23,8 @@ const (
 
 //go:generate mockery --name=PrometheusService --inpackage --filename=prometheus_mock.go
 type PrometheusService interface {
-	GetHttpSDTargets() (models.PrometheusTargetsList, error)
+	// GetHttpSDTargets returns the node exporters of the hosts of the organization, of all of them if empty
+	GetHttpSDTargets(organization string) (models.PrometheusTargetsList, error)
 	Query(query string, ts time.Time) (prometheusModel.Value, error)
 }
 
@@ -34,18 +37,23 @@ func NewPrometheusService(db *gorm.DB, promApi prometheusInternal.PrometheusAPI)
 	return &prometheusService{db, promApi}
 }
 
-func (p *prometheusService) GetHttpSDTargets() (models.PrometheusTargetsList, error) {
+func (p *prometheusService) GetHttpSDTargets(organization string) (models.PrometheusTargetsList, error) {
 	var targetsList models.PrometheusTargetsList
 	var hosts []entities.Host
 
-	err := p.db.Find(&hosts).Error
+	// The basic agents are not required to run the node exporter
+	err := p.db.
+		Where("agent_profile IS DISTINCT FROM ?", internalHosts.AgentProfileBasic).
+		Scopes(organizationScope(organization, "organization")).
+		Find(&hosts).
+		Error
 	if err != nil {
 		return targetsList, err
 	}
 
 	for _, host := range hosts {
 		targets := &models.PrometheusTargets{
-			Targets: []string{fmt.Sprintf("%s:%d", host.SSHAddress, nodeExporterPort)},
+			Targets: []string{net.JoinHostPort(host.SSHAddress, strconv.Itoa(nodeExporterPort))},
 			Labels: map[string]string{
 				"agentID":       host.AgentID,
 				"hostname":      host.Name,
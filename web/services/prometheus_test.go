@@ -31,7 +31,13 @@ func targetsFixtures() []entities.Host {
 		{
 			AgentID:    "3",
 			Name:       "host3",
-			SSHAddress: "192.168.1.3",
+			SSHAddress: "2001:db8::3",
+		},
+		{
+			AgentID:      "4",
+			Name:         "host4",
+			SSHAddress:   "192.168.1.4",
+			AgentProfile: "basic",
 		},
 	}
 }
@@ -72,7 +78,7 @@ func (suite *PrometheusServiceTestSuite) TearDownTest() {
 }
 
 func (suite *PrometheusServiceTestSuite) TestPrometheusService_GetHttpSDTargets() {
-	targets, err := suite.prometheusService.GetHttpSDTargets()
+	targets, err := suite.prometheusService.GetHttpSDTargets("")
 	suite.NoError(err)
 
 	suite.ElementsMatch(models.PrometheusTargetsList{
@@ -85,12 +91,26 @@ func (suite *PrometheusServiceTestSuite) TestPrometheusService_GetHttpSDTargets(
 			Labels:  map[string]string{"agentID": "2", "hostname": "host2", "exporter_name": "Node Exporter"},
 		},
 		&models.PrometheusTargets{
-			Targets: []string{"192.168.1.3:9100"},
+			Targets: []string{"[2001:db8::3]:9100"},
 			Labels:  map[string]string{"agentID": "3", "hostname": "host3", "exporter_name": "Node Exporter"},
 		},
 	}, targets)
 }
 
+func (suite *PrometheusServiceTestSuite) TestPrometheusService_GetHttpSDTargetsOrganization() {
+	suite.tx.Model(&entities.Host{}).Where("agent_id = ?", "2").Update("organization", "acme")
+
+	targets, err := suite.prometheusService.GetHttpSDTargets("acme")
+	suite.NoError(err)
+
+	suite.Equal(models.PrometheusTargetsList{
+		&models.PrometheusTargets{
+			Targets: []string{"192.168.1.2:9100"},
+			Labels:  map[string]string{"agentID": "2", "hostname": "host2", "exporter_name": "Node Exporter"},
+		},
+	}, targets)
+}
+
 func (suite *PrometheusServiceTestSuite) TestPrometheusService_Query() {
 	cTime := time.Now()
 	expectedResult := prometheusModel.Vector{
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+//go:generate mockery --name=ResourceRestrictionsService --inpackage --filename=resource_restrictions_mock.go
+
+type ResourceRestrictionsService interface {
+	GetAll() ([]*models.ResourceRestriction, error)
+	// Get returns nil if the permission is not restricted on the resource
+	Get(resourceType string, resourceID string, permission string) (*models.ResourceRestriction, error)
+	// Save replaces the restriction of the permission on the resource, if any
+	Save(restriction *models.ResourceRestriction) (*models.ResourceRestriction, error)
+	// Delete returns nil if the permission was not restricted on the resource
+	Delete(resourceType string, resourceID string, permission string) (*models.ResourceRestriction, error)
+}
+
+type resourceRestrictionsService struct {
+	db *gorm.DB
+}
+
+func NewResourceRestrictionsService(db *gorm.DB) *resourceRestrictionsService {
+	return &resourceRestrictionsService{db: db}
+}
+
+func (s *resourceRestrictionsService) GetAll() ([]*models.ResourceRestriction, error) {
+	var restrictions []entities.ResourceRestriction
+	err := s.db.Order("resource_type, resource_id, permission").Find(&restrictions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*models.ResourceRestriction{}
+	for _, r := range restrictions {
+		result = append(result, r.ToModel())
+	}
+
+	return result, nil
+}
+
+func (s *resourceRestrictionsService) Get(resourceType string, resourceID string, permission string) (*models.ResourceRestriction, error) {
+	var restrictions []entities.ResourceRestriction
+	err := s.db.
+		Where("resource_type = ? AND resource_id = ? AND permission = ?", resourceType, resourceID, permission).
+		Limit(1).
+		Find(&restrictions).
+		Error
+	if err != nil || len(restrictions) == 0 {
+		return nil, err
+	}
+
+	return restrictions[0].ToModel(), nil
+}
+
+func (s *resourceRestrictionsService) Save(restriction *models.ResourceRestriction) (*models.ResourceRestriction, error) {
+	usernames := restriction.Usernames
+	if usernames == nil {
+		usernames = []string{}
+	}
+
+	entity := entities.ResourceRestriction{
+		ResourceType: restriction.ResourceType,
+		ResourceID:   restriction.ResourceID,
+		Permission:   restriction.Permission,
+		Role:         restriction.Role,
+		Usernames:    usernames,
+	}
+
+	err := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&entity).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.ToModel(), nil
+}
+
+func (s *resourceRestrictionsService) Delete(resourceType string, resourceID string, permission string) (*models.ResourceRestriction, error) {
+	restriction, err := s.Get(resourceType, resourceID, permission)
+	if err != nil || restriction == nil {
+		return nil, err
+	}
+
+	err = s.db.
+		Where("resource_type = ? AND resource_id = ? AND permission = ?", resourceType, resourceID, permission).
+		Delete(&entities.ResourceRestriction{}).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return restriction, nil
+}
@@ -0,0 +1,105 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockResourceRestrictionsService is an autogenerated mock type for the ResourceRestrictionsService type
+type MockResourceRestrictionsService struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: resourceType, resourceID, permission
+func (_m *MockResourceRestrictionsService) Delete(resourceType string, resourceID string, permission string) (*models.ResourceRestriction, error) {
+	ret := _m.Called(resourceType, resourceID, permission)
+
+	var r0 *models.ResourceRestriction
+	if rf, ok := ret.Get(0).(func(string, string, string) *models.ResourceRestriction); ok {
+		r0 = rf(resourceType, resourceID, permission)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ResourceRestriction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(resourceType, resourceID, permission)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: resourceType, resourceID, permission
+func (_m *MockResourceRestrictionsService) Get(resourceType string, resourceID string, permission string) (*models.ResourceRestriction, error) {
+	ret := _m.Called(resourceType, resourceID, permission)
+
+	var r0 *models.ResourceRestriction
+	if rf, ok := ret.Get(0).(func(string, string, string) *models.ResourceRestriction); ok {
+		r0 = rf(resourceType, resourceID, permission)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ResourceRestriction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(resourceType, resourceID, permission)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields:
+func (_m *MockResourceRestrictionsService) GetAll() ([]*models.ResourceRestriction, error) {
+	ret := _m.Called()
+
+	var r0 []*models.ResourceRestriction
+	if rf, ok := ret.Get(0).(func() []*models.ResourceRestriction); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ResourceRestriction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: restriction
+func (_m *MockResourceRestrictionsService) Save(restriction *models.ResourceRestriction) (*models.ResourceRestriction, error) {
+	ret := _m.Called(restriction)
+
+	var r0 *models.ResourceRestriction
+	if rf, ok := ret.Get(0).(func(*models.ResourceRestriction) *models.ResourceRestriction); ok {
+		r0 = rf(restriction)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ResourceRestriction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*models.ResourceRestriction) error); ok {
+		r1 = rf(restriction)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
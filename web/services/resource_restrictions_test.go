@@ -0,0 +1,106 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type ResourceRestrictionsServiceTestSuite struct {
+	suite.Suite
+	db                          *gorm.DB
+	tx                          *gorm.DB
+	resourceRestrictionsService *resourceRestrictionsService
+}
+
+func TestResourceRestrictionsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ResourceRestrictionsServiceTestSuite))
+}
+
+func (suite *ResourceRestrictionsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.ResourceRestriction{})
+}
+
+func (suite *ResourceRestrictionsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.ResourceRestriction{})
+}
+
+func (suite *ResourceRestrictionsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.resourceRestrictionsService = NewResourceRestrictionsService(suite.tx)
+
+	suite.tx.Create(&[]entities.ResourceRestriction{
+		{ResourceType: models.TagClusterResourceType, ResourceID: "cluster1", Permission: models.PermissionTagsWrite, Role: models.UserRoleAdmin, Usernames: []string{}},
+		{ResourceType: models.TagClusterResourceType, ResourceID: "cluster1", Permission: models.PermissionChecksWrite, Usernames: []string{"alice"}},
+	})
+}
+
+func (suite *ResourceRestrictionsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *ResourceRestrictionsServiceTestSuite) TestResourceRestrictionsService_GetAll() {
+	restrictions, err := suite.resourceRestrictionsService.GetAll()
+	suite.NoError(err)
+	suite.Len(restrictions, 2)
+	suite.Equal(models.PermissionChecksWrite, restrictions[0].Permission)
+	suite.Equal([]string{"alice"}, restrictions[0].Usernames)
+	suite.Equal(models.PermissionTagsWrite, restrictions[1].Permission)
+	suite.Equal(models.UserRoleAdmin, restrictions[1].Role)
+}
+
+func (suite *ResourceRestrictionsServiceTestSuite) TestResourceRestrictionsService_Get() {
+	restriction, err := suite.resourceRestrictionsService.Get(models.TagClusterResourceType, "cluster1", models.PermissionTagsWrite)
+	suite.NoError(err)
+	suite.Equal(models.UserRoleAdmin, restriction.Role)
+
+	restriction, err = suite.resourceRestrictionsService.Get(models.TagClusterResourceType, "cluster2", models.PermissionTagsWrite)
+	suite.NoError(err)
+	suite.Nil(restriction)
+}
+
+func (suite *ResourceRestrictionsServiceTestSuite) TestResourceRestrictionsService_Save() {
+	restriction, err := suite.resourceRestrictionsService.Save(&models.ResourceRestriction{
+		ResourceType: models.TagClusterResourceType,
+		ResourceID:   "cluster1",
+		Permission:   models.PermissionTagsWrite,
+		Usernames:    []string{"bob"},
+	})
+	suite.NoError(err)
+	suite.Equal("", restriction.Role)
+	suite.Equal([]string{"bob"}, restriction.Usernames)
+
+	_, err = suite.resourceRestrictionsService.Save(&models.ResourceRestriction{
+		ResourceType: models.TagSAPSystemResourceType,
+		ResourceID:   "sapsystem1",
+		Permission:   models.PermissionTagsWrite,
+		Role:         models.UserRoleAdmin,
+	})
+	suite.NoError(err)
+
+	restrictions, _ := suite.resourceRestrictionsService.GetAll()
+	suite.Len(restrictions, 3)
+
+	saved, _ := suite.resourceRestrictionsService.Get(models.TagClusterResourceType, "cluster1", models.PermissionTagsWrite)
+	suite.Equal("", saved.Role)
+	suite.Equal([]string{"bob"}, saved.Usernames)
+}
+
+func (suite *ResourceRestrictionsServiceTestSuite) TestResourceRestrictionsService_Delete() {
+	restriction, err := suite.resourceRestrictionsService.Delete(models.TagClusterResourceType, "cluster1", models.PermissionTagsWrite)
+	suite.NoError(err)
+	suite.Equal(models.UserRoleAdmin, restriction.Role)
+
+	restriction, err = suite.resourceRestrictionsService.Delete(models.TagClusterResourceType, "cluster1", models.PermissionTagsWrite)
+	suite.NoError(err)
+	suite.Nil(restriction)
+
+	restrictions, _ := suite.resourceRestrictionsService.GetAll()
+	suite.Len(restrictions, 1)
+}
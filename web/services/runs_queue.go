@@ -0,0 +1,70 @@
+package services
+
+import (
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=RunsQueueService --inpackage --filename=runs_queue_mock.go
+
+// RunsQueueService stores the queue of checks executions reported by the runner
+type RunsQueueService interface {
+	// GetAll returns the queued runs of the clusters of the organization, of all of them if empty
+	GetAll(organization string) ([]*models.QueuedRun, error)
+	// Replace stores the given queue, the runner reports it as a whole on every change
+	Replace(runs []*models.QueuedRun) error
+}
+
+type runsQueueService struct {
+	db *gorm.DB
+}
+
+func NewRunsQueueService(db *gorm.DB) *runsQueueService {
+	return &runsQueueService{db: db}
+}
+
+func (s *runsQueueService) GetAll(organization string) ([]*models.QueuedRun, error) {
+	var queuedRuns []*entities.QueuedRun
+
+	db := s.db
+	if organization != "" {
+		clusters := s.db.Session(&gorm.Session{NewDB: true}).Model(&entities.Cluster{}).Select("id").Where("organization = ?", organization)
+		db = db.Where("cluster_id IN (?)", clusters)
+	}
+
+	err := db.Order("started_at IS NULL, started_at, queued_at, cluster_id").Find(&queuedRuns).Error
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []*models.QueuedRun{}
+	for _, r := range queuedRuns {
+		runs = append(runs, r.ToModel())
+	}
+
+	return runs, nil
+}
+
+func (s *runsQueueService) Replace(runs []*models.QueuedRun) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&entities.QueuedRun{}).Error; err != nil {
+			return err
+		}
+
+		for _, r := range runs {
+			err := tx.Create(&entities.QueuedRun{
+				ClusterID: r.ClusterID,
+				Targets:   r.Targets,
+				State:     r.State,
+				QueuedAt:  r.QueuedAt,
+				StartedAt: r.StartedAt,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,50 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockRunsQueueService is an autogenerated mock type for the RunsQueueService type
+type MockRunsQueueService struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: organization
+func (_m *MockRunsQueueService) GetAll(organization string) ([]*models.QueuedRun, error) {
+	ret := _m.Called(organization)
+
+	var r0 []*models.QueuedRun
+	if rf, ok := ret.Get(0).(func(string) []*models.QueuedRun); ok {
+		r0 = rf(organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.QueuedRun)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Replace provides a mock function with given fields: runs
+func (_m *MockRunsQueueService) Replace(runs []*models.QueuedRun) error {
+	ret := _m.Called(runs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*models.QueuedRun) error); ok {
+		r0 = rf(runs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
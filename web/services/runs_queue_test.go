@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type RunsQueueServiceTestSuite struct {
+	suite.Suite
+	db               *gorm.DB
+	tx               *gorm.DB
+	runsQueueService *runsQueueService
+}
+
+func TestRunsQueueServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(RunsQueueServiceTestSuite))
+}
+
+func (suite *RunsQueueServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.QueuedRun{}, &entities.Cluster{})
+}
+
+func (suite *RunsQueueServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.QueuedRun{}, &entities.Cluster{})
+}
+
+func (suite *RunsQueueServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.runsQueueService = NewRunsQueueService(suite.tx)
+}
+
+func (suite *RunsQueueServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *RunsQueueServiceTestSuite) TestRunsQueueService_Replace() {
+	queuedAt := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	startedAt := queuedAt.Add(time.Minute)
+
+	err := suite.runsQueueService.Replace([]*models.QueuedRun{
+		{ClusterID: "old", State: models.QueuedRunStateRunning, QueuedAt: queuedAt, StartedAt: &startedAt},
+	})
+	suite.NoError(err)
+
+	err = suite.runsQueueService.Replace([]*models.QueuedRun{
+		{ClusterID: "cluster2", Targets: []string{"10.0.0.3"}, State: models.QueuedRunStateQueued, QueuedAt: queuedAt},
+		{ClusterID: "cluster1", Targets: []string{"10.0.0.1", "10.0.0.2"}, State: models.QueuedRunStateRunning, QueuedAt: queuedAt, StartedAt: &startedAt},
+	})
+	suite.NoError(err)
+
+	runs, err := suite.runsQueueService.GetAll("")
+	suite.NoError(err)
+	suite.Equal(2, len(runs))
+
+	suite.Equal("cluster1", runs[0].ClusterID)
+	suite.Equal([]string{"10.0.0.1", "10.0.0.2"}, runs[0].Targets)
+	suite.Equal(models.QueuedRunStateRunning, runs[0].State)
+	suite.True(startedAt.Equal(*runs[0].StartedAt))
+	suite.Equal("cluster2", runs[1].ClusterID)
+	suite.Equal(models.QueuedRunStateQueued, runs[1].State)
+	suite.Nil(runs[1].StartedAt)
+}
+
+func (suite *RunsQueueServiceTestSuite) TestRunsQueueService_GetAllEmpty() {
+	runs, err := suite.runsQueueService.GetAll("")
+	suite.NoError(err)
+	suite.Equal([]*models.QueuedRun{}, runs)
+}
+
+func (suite *RunsQueueServiceTestSuite) TestRunsQueueService_GetAllOrganization() {
+	suite.tx.Create(&entities.Cluster{ID: "cluster1", Organization: "acme"})
+	suite.tx.Create(&entities.Cluster{ID: "cluster2", Organization: "globex"})
+
+	err := suite.runsQueueService.Replace([]*models.QueuedRun{
+		{ClusterID: "cluster1", State: models.QueuedRunStateQueued},
+		{ClusterID: "cluster2", State: models.QueuedRunStateQueued},
+	})
+	suite.NoError(err)
+
+	runs, err := suite.runsQueueService.GetAll("acme")
+	suite.NoError(err)
+	suite.Equal(1, len(runs))
+	suite.Equal("cluster1", runs[0].ClusterID)
+}
@@ -3,10 +3,13 @@ package services
 import (
 	"errors"
 	"fmt"
-	"net"
+	"sort"
+	"time"
 
 	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/datapipeline"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 	"gorm.io/gorm"
@@ -23,19 +26,30 @@ type SAPSystemsService interface {
 	GetAllDatabasesSIDs() ([]string, error)
 	GetAllApplicationsTags() ([]string, error)
 	GetAllDatabasesTags() ([]string, error)
+	// Delete removes the instances of the SAP system, or database, with its tags, favorites and search document,
+	// the hosts are left untouched. It returns nil if the SAP system does not exist
+	Delete(ID string) (*models.SAPSystem, error)
+	// DeleteStale removes the SAP systems, and databases, none of the hosts of the instances of
+	// sent a heartbeat within the given period
+	DeleteStale(silence time.Duration) ([]*models.SAPSystem, error)
 }
 
 type SAPSystemFilter struct {
 	Tags []string
 	SIDs []string
+	// Pinned SAP systems are listed before the others
+	Pinned []string
+	// Organization the SAP systems belong to, all of them if empty
+	Organization string
 }
 
 type sapSystemsService struct {
-	db *gorm.DB
+	db             *gorm.DB
+	healthStrategy HealthStrategy
 }
 
-func NewSAPSystemsService(db *gorm.DB) *sapSystemsService {
-	return &sapSystemsService{db}
+func NewSAPSystemsService(db *gorm.DB, healthStrategy HealthStrategy) *sapSystemsService {
+	return &sapSystemsService{db: db, healthStrategy: healthStrategy}
 }
 
 func (s *sapSystemsService) GetAllApplications(filter *SAPSystemFilter, page *Page) (models.SAPSystemList, error) {
@@ -62,7 +76,35 @@ func (s *sapSystemsService) GetByID(ID string) (*models.SAPSystem, error) {
 		return nil, nil
 	}
 
-	return instances.ToModel()[0], nil
+	sapSystem := instances.ToModel()[0]
+
+	if err := s.attachKubernetesWorkloads(sapSystem); err != nil {
+		return nil, err
+	}
+
+	return sapSystem, nil
+}
+
+// attachKubernetesWorkloads adds the pods labelled with the SID of the system,
+// Kubernetes workloads are not bound to a system ID
+func (s *sapSystemsService) attachKubernetesWorkloads(sapSystem *models.SAPSystem) error {
+	var workloads []*entities.KubernetesWorkload
+
+	err := s.db.
+		Preload("Host").
+		Where("sid = ? AND type = ?", sapSystem.SID, sapSystem.Type).
+		Order("namespace, pod").
+		Find(&workloads).
+		Error
+	if err != nil {
+		return err
+	}
+
+	for _, w := range workloads {
+		sapSystem.KubernetesWorkloads = append(sapSystem.KubernetesWorkloads, w.ToModel())
+	}
+
+	return nil
 }
 
 func (s *sapSystemsService) GetApplicationsCount() (int, error) {
@@ -163,14 +205,95 @@ func (s *sapSystemsService) GetAllDatabasesTags() ([]string, error) {
 	return tags, nil
 }
 
+func (s *sapSystemsService) Delete(ID string) (*models.SAPSystem, error) {
+	var sapSystem *models.SAPSystem
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var instances entities.SAPSystemInstances
+		if err := tx.Where("id = ?", ID).Order("sid, instance_number").Find(&instances).Error; err != nil {
+			return err
+		}
+		if len(instances) == 0 {
+			return nil
+		}
+
+		sapSystem = instances.ToModel()[0]
+		resourceType := models.TagSAPSystemResourceType
+		if sapSystem.Type == models.SAPSystemTypeDatabase {
+			resourceType = models.TagDatabaseResourceType
+		}
+
+		if err := tx.Where("id = ?", ID).Delete(&entities.SAPSystemInstance{}).Error; err != nil {
+			return err
+		}
+
+		for _, entity := range []interface{}{&models.Tag{}, &entities.Favorite{}} {
+			err := tx.
+				Where("resource_type = ? AND resource_id = ?", resourceType, ID).
+				Delete(entity).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return datapipeline.DeleteSearchDocument(tx, resourceType, ID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sapSystem, nil
+}
+
+func (s *sapSystemsService) DeleteStale(silence time.Duration) ([]*models.SAPSystem, error) {
+	var ids []string
+
+	alive := s.db.
+		Model(&entities.SAPSystemInstance{}).
+		Select("sap_system_instances.id").
+		Joins("JOIN host_heartbeats ON host_heartbeats.agent_id = sap_system_instances.agent_id").
+		Where("host_heartbeats.updated_at > ?", timeNow().Add(-silence))
+
+	err := s.db.
+		Model(&entities.SAPSystemInstance{}).
+		Where("id NOT IN (?)", alive).
+		Distinct().
+		Order("id").
+		Pluck("id", &ids).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []*models.SAPSystem
+	for _, id := range ids {
+		sapSystem, err := s.Delete(id)
+		if err != nil {
+			return deleted, err
+		}
+		if sapSystem != nil {
+			deleted = append(deleted, sapSystem)
+		}
+	}
+
+	return deleted, nil
+}
+
 func (s *sapSystemsService) getAllByType(sapSystemType string, tagResourceType string, filter *SAPSystemFilter, page *Page) (models.SAPSystemList, error) {
 	var instances entities.SAPSystemInstances
 
+	var pinned []string
+	if filter != nil {
+		pinned = filter.Pinned
+	}
+
 	paginationSubQuery := s.db.
-		Distinct("id,sid").
+		Select("id, sid").
+		Group("id, sid").
 		Where("type = ?", sapSystemType).
-		Scopes(Paginate(page)).
-		Order("sid").
+		Scopes(Paginate(page), OrderPinnedFirst("id", pinned, "sid")).
 		Table("sap_system_instances")
 
 	db := s.db.
@@ -180,6 +303,8 @@ func (s *sapSystemsService) getAllByType(sapSystemType string, tagResourceType s
 		Order("sid, instance_number, system_replication, id")
 
 	if filter != nil {
+		paginationSubQuery = paginationSubQuery.Scopes(organizationScope(filter.Organization, "organization"))
+
 		if len(filter.SIDs) > 0 {
 			db = db.Where("sid IN (?)", filter.SIDs)
 		}
@@ -204,6 +329,12 @@ func (s *sapSystemsService) getAllByType(sapSystemType string, tagResourceType s
 		return nil, err
 	}
 
+	if len(pinned) > 0 {
+		sort.SliceStable(sapSystemList, func(i, j int) bool {
+			return internal.Contains(pinned, sapSystemList[i].ID) && !internal.Contains(pinned, sapSystemList[j].ID)
+		})
+	}
+
 	return sapSystemList, nil
 }
 
@@ -214,12 +345,13 @@ func (s *sapSystemsService) getAttachedDatabase(dbName string, dbAddress string)
 		Model(&entities.SAPSystemInstance{}).
 		Joins("JOIN hosts ON sap_system_instances.agent_id = hosts.agent_id")
 
-	ip := net.ParseIP(dbAddress)
-	if ip.To4() == nil {
+	ip, _ := internal.ParseIPAddress(dbAddress)
+	if ip == nil {
 		return nil, fmt.Errorf("received database address is not valid: %s", dbAddress)
 	}
 
-	db = db.Where("hosts.ip_addresses && ?", pq.Array([]string{dbAddress}))
+	// the addresses of the hosts are stored in their canonical form
+	db = db.Where("hosts.ip_addresses && ?", pq.Array([]string{ip.String()}))
 
 	err := db.Where("tenants && ?", pq.Array([]string{dbName})).
 		Select("id").
@@ -285,15 +417,10 @@ func (s *sapSystemsService) attachDatabase(sapSystem *models.SAPSystem) error {
 }
 
 func (s *sapSystemsService) computeHealth(sapSystem *models.SAPSystem) {
-	sapSystem.Health = models.SAPSystemHealthPassing
+	var healths []string
 	for _, sapInstance := range sapSystem.GetAllInstances() {
-		switch {
-		case sapInstance.Health() == models.SAPSystemHealthCritical:
-			sapSystem.Health = models.SAPSystemHealthCritical
-		case sapSystem.Health != models.SAPSystemHealthCritical && sapInstance.Health() == models.SAPSystemHealthWarning:
-			sapSystem.Health = models.SAPSystemHealthWarning
-		case sapSystem.Health == models.SAPSystemHealthPassing && sapInstance.Health() == models.SAPSystemHealthUnknown:
-			sapSystem.Health = models.SAPSystemHealthUnknown
-		}
+		healths = append(healths, sapInstance.Health())
 	}
+
+	sapSystem.Health = s.healthStrategy.Aggregate(healths...)
 }
@@ -5,6 +5,8 @@ package services
 import (
 	mock "github.com/stretchr/testify/mock"
 	models "github.com/trento-project/trento/web/models"
+
+	time "time"
 )
 
 // MockSAPSystemsService is an autogenerated mock type for the SAPSystemsService type
@@ -12,6 +14,52 @@ type MockSAPSystemsService struct {
 	mock.Mock
 }
 
+// Delete provides a mock function with given fields: ID
+func (_m *MockSAPSystemsService) Delete(ID string) (*models.SAPSystem, error) {
+	ret := _m.Called(ID)
+
+	var r0 *models.SAPSystem
+	if rf, ok := ret.Get(0).(func(string) *models.SAPSystem); ok {
+		r0 = rf(ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SAPSystem)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteStale provides a mock function with given fields: silence
+func (_m *MockSAPSystemsService) DeleteStale(silence time.Duration) ([]*models.SAPSystem, error) {
+	ret := _m.Called(silence)
+
+	var r0 []*models.SAPSystem
+	if rf, ok := ret.Get(0).(func(time.Duration) []*models.SAPSystem); ok {
+		r0 = rf(silence)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SAPSystem)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Duration) error); ok {
+		r1 = rf(silence)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllApplications provides a mock function with given fields: filter, page
 func (_m *MockSAPSystemsService) GetAllApplications(filter *SAPSystemFilter, page *Page) (models.SAPSystemList, error) {
 	ret := _m.Called(filter, page)
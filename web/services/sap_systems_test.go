@@ -2,6 +2,7 @@ package services
 
 import (
 	"testing"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/suite"
@@ -100,25 +101,38 @@ func TestSAPSystemsServiceTestSuite(t *testing.T) {
 func (suite *SAPSystemsServiceTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&entities.SAPSystemInstance{}, &entities.Host{}, &models.Tag{})
+	suite.db.AutoMigrate(&entities.SAPSystemInstance{}, &entities.Host{}, &models.Tag{}, &entities.KubernetesWorkload{},
+		&entities.HostHeartbeat{}, &entities.Favorite{}, &entities.SearchDocument{})
 	sapSystemInstances := sapSystemsFixtures()
 	err := suite.db.Create(&sapSystemInstances).Error
 	suite.NoError(err)
+
+	workloads := []entities.KubernetesWorkload{
+		{AgentID: "1", Namespace: "sap-ha1", Pod: "ha1-di-0", Node: "node1", SID: "HA1", InstanceNumber: "01", Type: "application", Component: "DIALOG", Phase: "Running"},
+		{AgentID: "1", Namespace: "sap-ha1", Pod: "ha1-hdb-0", Node: "node1", SID: "HA1", InstanceNumber: "10", Type: "database", Phase: "Running"},
+	}
+	err = suite.db.Create(&workloads).Error
+	suite.NoError(err)
 }
 
 func (suite *SAPSystemsServiceTestSuite) TearDownSuite() {
 	suite.db.Migrator().DropTable(&entities.SAPSystemInstance{},
 		&entities.Host{},
-		&models.Tag{})
+		&models.Tag{},
+		&entities.KubernetesWorkload{},
+		&entities.HostHeartbeat{},
+		&entities.Favorite{},
+		&entities.SearchDocument{})
 }
 
 func (suite *SAPSystemsServiceTestSuite) SetupTest() {
 	suite.tx = suite.db.Begin()
-	suite.sapSystemsService = NewSAPSystemsService(suite.tx)
+	suite.sapSystemsService = NewSAPSystemsService(suite.tx, DefaultHealthStrategy{})
 }
 
 func (suite *SAPSystemsServiceTestSuite) TearDownTest() {
 	suite.tx.Rollback()
+	timeNow = time.Now
 }
 
 func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllApplications() {
@@ -282,6 +296,20 @@ func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetByID() {
 
 	suite.Equal("sap_system_1", sapSystem.ID)
 	suite.Equal("HA1", sapSystem.SID)
+	suite.Equal([]*models.KubernetesWorkload{
+		{
+			Namespace:      "sap-ha1",
+			Pod:            "ha1-di-0",
+			Node:           "node1",
+			SID:            "HA1",
+			InstanceNumber: "01",
+			Type:           "application",
+			Component:      "DIALOG",
+			Phase:          "Running",
+			HostID:         "1",
+			Hostname:       "apphost",
+		},
+	}, sapSystem.KubernetesWorkloads)
 }
 
 func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetByID_NotFound() {
@@ -306,3 +334,53 @@ func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllDatabasesSI
 	suite.NoError(err)
 	suite.Equal([]string{"PRD"}, sids)
 }
+
+func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_Delete() {
+	suite.tx.Create(&entities.Favorite{UserID: "1", ResourceType: models.TagDatabaseResourceType, ResourceID: "sap_system_2"})
+	suite.tx.Create(&entities.SearchDocument{ResourceType: models.TagDatabaseResourceType, ResourceID: "sap_system_2", Name: "PRD"})
+
+	sapSystem, err := suite.sapSystemsService.Delete("sap_system_2")
+	suite.NoError(err)
+	suite.Equal("PRD", sapSystem.SID)
+	suite.Equal(models.SAPSystemTypeDatabase, sapSystem.Type)
+
+	var count int64
+	suite.tx.Model(&entities.SAPSystemInstance{}).Where("id = ?", "sap_system_2").Count(&count)
+	suite.EqualValues(0, count)
+	suite.tx.Model(&models.Tag{}).Where("resource_id = ?", "sap_system_2").Count(&count)
+	suite.EqualValues(0, count)
+	suite.tx.Model(&entities.Favorite{}).Count(&count)
+	suite.EqualValues(0, count)
+	suite.tx.Model(&entities.SearchDocument{}).Count(&count)
+	suite.EqualValues(0, count)
+
+	// the hosts and the other SAP systems are left untouched
+	suite.tx.Model(&entities.Host{}).Count(&count)
+	suite.EqualValues(3, count)
+	suite.tx.Model(&models.Tag{}).Where("resource_id = ?", "sap_system_1").Count(&count)
+	suite.EqualValues(1, count)
+
+	sapSystem, err = suite.sapSystemsService.Delete("sap_system_2")
+	suite.NoError(err)
+	suite.Nil(sapSystem)
+}
+
+func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_DeleteStale() {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	suite.tx.Create(&[]entities.HostHeartbeat{
+		{AgentID: "1", UpdatedAt: now.Add(-2 * time.Hour)},
+		{AgentID: "2", UpdatedAt: now.Add(-2 * time.Hour)},
+		{AgentID: "3", UpdatedAt: now.Add(-time.Minute)},
+	})
+
+	deleted, err := suite.sapSystemsService.DeleteStale(time.Hour)
+	suite.NoError(err)
+	suite.Len(deleted, 1)
+	suite.Equal("sap_system_1", deleted[0].ID)
+
+	var ids []string
+	suite.tx.Model(&entities.SAPSystemInstance{}).Distinct().Pluck("id", &ids)
+	suite.Equal([]string{"sap_system_2"}, ids)
+}
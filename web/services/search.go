@@ -0,0 +1,125 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+// maxSearchWords bounds the words of a search, each one being a prefix to match
+const maxSearchWords = 10
+
+//go:generate mockery --name=SearchService --inpackage --filename=search_mock.go
+
+// SearchService looks up the hosts, clusters, SAP systems and databases in the full-text index
+// maintained by the search index projector
+type SearchService interface {
+	// Search returns the resources of the organization, all of them if empty, matching all the words of the query,
+	// as prefixes, the best matches first
+	Search(query string, organization string, limit int) ([]*models.SearchResult, error)
+	// IndexMissing indexes the resources projected before the search index existed, returning how many
+	IndexMissing() (int, error)
+}
+
+type searchService struct {
+	db *gorm.DB
+}
+
+func NewSearchService(db *gorm.DB) *searchService {
+	return &searchService{db: db}
+}
+
+func (s *searchService) Search(query string, organization string, limit int) ([]*models.SearchResult, error) {
+	results := []*models.SearchResult{}
+
+	tsQuery := prefixTSQuery(query)
+	if tsQuery == "" {
+		return results, nil
+	}
+
+	err := s.db.Model(&entities.SearchDocument{}).
+		Select("resource_type, resource_id, name, ts_rank(document, to_tsquery(?, ?)) AS rank", datapipeline.SearchConfiguration, tsQuery).
+		Where("document @@ to_tsquery(?, ?)", datapipeline.SearchConfiguration, tsQuery).
+		Scopes(resourceOrganizationScope(organization, "resource_type", "resource_id")).
+		Order("rank DESC, name, resource_type, resource_id").
+		Limit(limit).
+		Scan(&results).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *searchService) IndexMissing() (int, error) {
+	indexed := 0
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, missing := range []struct {
+			model  interface{}
+			column string
+			types  []string
+			index  func(db *gorm.DB, id string) error
+		}{
+			{&entities.Host{}, "hosts.agent_id", []string{models.TagHostResourceType}, datapipeline.IndexHost},
+			{&entities.Cluster{}, "clusters.id", []string{models.TagClusterResourceType}, datapipeline.IndexCluster},
+			{&entities.SAPSystemInstance{}, "sap_system_instances.id",
+				[]string{models.TagSAPSystemResourceType, models.TagDatabaseResourceType}, datapipeline.IndexSAPSystem},
+		} {
+			var ids []string
+			err := tx.Model(missing.model).
+				Distinct().
+				Where("NOT EXISTS (SELECT 1 FROM search_documents WHERE resource_type IN ? AND resource_id = "+missing.column+")", missing.types).
+				Pluck(missing.column, &ids).
+				Error
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				if err := missing.index(tx, id); err != nil {
+					return err
+				}
+			}
+			indexed += len(ids)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return indexed, nil
+}
+
+// prefixTSQuery matches all the words of the query as prefixes, e.g. "hana 10.0" becomes 'hana':* & '10.0':*.
+// The characters of the tsquery syntax are dropped, the words being quoted
+func prefixTSQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		word = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(".-_/", r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, word)
+
+		if word == "" {
+			continue
+		}
+
+		terms = append(terms, "'"+word+"':*")
+		if len(terms) == maxSearchWords {
+			break
+		}
+	}
+
+	return strings.Join(terms, " & ")
+}
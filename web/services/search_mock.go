@@ -0,0 +1,57 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockSearchService is an autogenerated mock type for the SearchService type
+type MockSearchService struct {
+	mock.Mock
+}
+
+// IndexMissing provides a mock function with given fields:
+func (_m *MockSearchService) IndexMissing() (int, error) {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: query, organization, limit
+func (_m *MockSearchService) Search(query string, organization string, limit int) ([]*models.SearchResult, error) {
+	ret := _m.Called(query, organization, limit)
+
+	var r0 []*models.SearchResult
+	if rf, ok := ret.Get(0).(func(string, string, int) []*models.SearchResult); ok {
+		r0 = rf(query, organization, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SearchResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(query, organization, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
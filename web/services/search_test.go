@@ -0,0 +1,110 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type SearchServiceTestSuite struct {
+	suite.Suite
+	db            *gorm.DB
+	tx            *gorm.DB
+	searchService *searchService
+}
+
+func TestSearchServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchServiceTestSuite))
+}
+
+func (suite *SearchServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Host{}, &entities.Cluster{}, &entities.SAPSystemInstance{}, &models.Tag{}, &entities.SearchDocument{})
+}
+
+func (suite *SearchServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(entities.Host{}, entities.Cluster{}, entities.SAPSystemInstance{}, models.Tag{}, entities.SearchDocument{})
+}
+
+func (suite *SearchServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.searchService = NewSearchService(suite.tx)
+
+	suite.tx.Create(&[]entities.Host{
+		{AgentID: "agent_1", Name: "hana01", IPAddresses: []string{"10.74.1.10"}},
+		{AgentID: "agent_2", Name: "netweaver01", IPAddresses: []string{"10.74.2.10"}, Organization: "acme"},
+	})
+	suite.tx.Create(&entities.Cluster{ID: "cluster_id", Name: "hana_cluster", SID: "PRD"})
+	suite.tx.Create(&entities.SAPSystemInstance{ID: "sap_system_id", AgentID: "agent_2", InstanceNumber: "00", SID: "NWP", Type: models.SAPSystemTypeApplication, Organization: "acme"})
+}
+
+func (suite *SearchServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *SearchServiceTestSuite) TestSearchService_Search() {
+	indexed, err := suite.searchService.IndexMissing()
+	suite.NoError(err)
+	suite.Equal(4, indexed)
+
+	indexed, err = suite.searchService.IndexMissing()
+	suite.NoError(err)
+	suite.Equal(0, indexed)
+
+	results, err := suite.searchService.Search("hana", "", 10)
+	suite.NoError(err)
+	suite.Len(results, 2)
+	suite.ElementsMatch([]string{"agent_1", "cluster_id"}, []string{results[0].ID, results[1].ID})
+
+	// the name outranks the other terms
+	results, err = suite.searchService.Search("NWP", "", 10)
+	suite.NoError(err)
+	suite.Len(results, 2)
+	suite.Equal(models.TagSAPSystemResourceType, results[0].ResourceType)
+	suite.Equal("agent_2", results[1].ID)
+	suite.Greater(results[0].Rank, results[1].Rank)
+
+	results, err = suite.searchService.Search("10.74 net", "", 10)
+	suite.NoError(err)
+	suite.Len(results, 1)
+	suite.Equal("agent_2", results[0].ID)
+
+	results, err = suite.searchService.Search("10.74", "", 1)
+	suite.NoError(err)
+	suite.Len(results, 1)
+
+	results, err = suite.searchService.Search("&|!", "", 10)
+	suite.NoError(err)
+	suite.Empty(results)
+}
+
+func (suite *SearchServiceTestSuite) TestSearchService_SearchOrganization() {
+	_, err := suite.searchService.IndexMissing()
+	suite.NoError(err)
+
+	results, err := suite.searchService.Search("10.74", "acme", 10)
+	suite.NoError(err)
+	suite.Len(results, 1)
+	suite.Equal("agent_2", results[0].ID)
+
+	results, err = suite.searchService.Search("NWP", "acme", 10)
+	suite.NoError(err)
+	suite.Len(results, 2)
+
+	results, err = suite.searchService.Search("hana", "acme", 10)
+	suite.NoError(err)
+	suite.Empty(results)
+}
+
+func TestPrefixTSQuery(t *testing.T) {
+	assert.Equal(t, "'hana':* & '10.74':*", prefixTSQuery("HANA  10.74"))
+	assert.Equal(t, "'hana-01':* & 'prd':*", prefixTSQuery("hana-01 'PRD':*"))
+	assert.Equal(t, "", prefixTSQuery("& | !() "))
+	assert.Equal(t, "'a':* & 'b':* & 'c':* & 'd':* & 'e':* & 'f':* & 'g':* & 'h':* & 'i':* & 'j':*", prefixTSQuery("a b c d e f g h i j k l"))
+}
@@ -1,10 +1,13 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
 
 	"github.com/google/uuid"
 	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -15,6 +18,16 @@ type SettingsService interface {
 	InitializeIdentifier() (uuid.UUID, error)
 	IsEulaAccepted() (bool, error)
 	AcceptEula() error
+	GetRunnerSettings() (*models.RunnerSettings, error)
+	SaveRunnerSettings(settings *models.RunnerSettings) error
+	GetMaintenanceMode() (*models.MaintenanceMode, error)
+	SaveMaintenanceMode(maintenanceMode *models.MaintenanceMode) error
+	GetReadOnlyMode() (*models.ReadOnlyMode, error)
+	SaveReadOnlyMode(readOnlyMode *models.ReadOnlyMode) error
+	GetLogSamplingRules() ([]*models.LogSamplingRule, error)
+	SaveLogSamplingRules(rules []*models.LogSamplingRule) error
+	GetAgentSettings() (*models.AgentSettings, error)
+	SaveAgentSettings(agentSettings *models.AgentSettings) error
 }
 
 type settingsService struct {
@@ -66,3 +79,132 @@ func (s *settingsService) AcceptEula() error {
 		DoUpdates: clause.AssignmentColumns([]string{"eula_accepted"}),
 	}).Create(&settings).Error
 }
+
+func (s *settingsService) GetRunnerSettings() (*models.RunnerSettings, error) {
+	var settings entities.Settings
+	err := s.db.First(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RunnerSettings{
+		MaxConcurrentRuns: settings.RunnerMaxConcurrentRuns,
+		MaxRunsPerTarget:  settings.RunnerMaxRunsPerTarget,
+	}, nil
+}
+
+func (s *settingsService) SaveRunnerSettings(runnerSettings *models.RunnerSettings) error {
+	return s.db.Model(&entities.Settings{}).Where("1 = 1").Updates(map[string]interface{}{
+		"runner_max_concurrent_runs": runnerSettings.MaxConcurrentRuns,
+		"runner_max_runs_per_target": runnerSettings.MaxRunsPerTarget,
+	}).Error
+}
+
+func (s *settingsService) GetMaintenanceMode() (*models.MaintenanceMode, error) {
+	var settings entities.Settings
+	err := s.db.First(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MaintenanceMode{
+		Enabled:         settings.MaintenanceEnabled,
+		Message:         settings.MaintenanceMessage,
+		PauseCollection: settings.MaintenancePauseCollection,
+		Since:           settings.MaintenanceSince,
+		EnabledBy:       settings.MaintenanceEnabledBy,
+	}, nil
+}
+
+func (s *settingsService) SaveMaintenanceMode(maintenanceMode *models.MaintenanceMode) error {
+	return s.db.Model(&entities.Settings{}).Where("1 = 1").Updates(map[string]interface{}{
+		"maintenance_enabled":          maintenanceMode.Enabled,
+		"maintenance_message":          maintenanceMode.Message,
+		"maintenance_pause_collection": maintenanceMode.PauseCollection,
+		"maintenance_since":            maintenanceMode.Since,
+		"maintenance_enabled_by":       maintenanceMode.EnabledBy,
+	}).Error
+}
+
+func (s *settingsService) GetReadOnlyMode() (*models.ReadOnlyMode, error) {
+	var settings entities.Settings
+	err := s.db.First(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ReadOnlyMode{
+		Enabled:   settings.ReadOnlyEnabled,
+		Reason:    settings.ReadOnlyReason,
+		Since:     settings.ReadOnlySince,
+		EnabledBy: settings.ReadOnlyEnabledBy,
+	}, nil
+}
+
+func (s *settingsService) SaveReadOnlyMode(readOnlyMode *models.ReadOnlyMode) error {
+	return s.db.Model(&entities.Settings{}).Where("1 = 1").Updates(map[string]interface{}{
+		"read_only_enabled":    readOnlyMode.Enabled,
+		"read_only_reason":     readOnlyMode.Reason,
+		"read_only_since":      readOnlyMode.Since,
+		"read_only_enabled_by": readOnlyMode.EnabledBy,
+	}).Error
+}
+
+func (s *settingsService) GetLogSamplingRules() ([]*models.LogSamplingRule, error) {
+	var settings entities.Settings
+	err := s.db.First(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []*models.LogSamplingRule{}
+	if len(settings.LogSamplingRules) == 0 {
+		return rules, nil
+	}
+
+	if err := json.Unmarshal(settings.LogSamplingRules, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (s *settingsService) SaveLogSamplingRules(rules []*models.LogSamplingRule) error {
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Model(&entities.Settings{}).Where("1 = 1").Update("log_sampling_rules", datatypes.JSON(encoded)).Error
+}
+
+func (s *settingsService) GetAgentSettings() (*models.AgentSettings, error) {
+	var settings entities.Settings
+	err := s.db.First(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	agentSettings := &models.AgentSettings{
+		DiscoveryPeriods:    map[string]int{},
+		DisabledDiscoveries: []string{},
+	}
+	if len(settings.AgentSettings) == 0 {
+		return agentSettings, nil
+	}
+
+	if err := json.Unmarshal(settings.AgentSettings, agentSettings); err != nil {
+		return nil, err
+	}
+
+	return agentSettings, nil
+}
+
+func (s *settingsService) SaveAgentSettings(agentSettings *models.AgentSettings) error {
+	encoded, err := json.Marshal(agentSettings)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Model(&entities.Settings{}).Where("1 = 1").Update("agent_settings", datatypes.JSON(encoded)).Error
+}
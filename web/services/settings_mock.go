@@ -3,8 +3,10 @@
 package services
 
 import (
-	uuid "github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+
+	uuid "github.com/google/uuid"
 )
 
 // MockSettingsService is an autogenerated mock type for the SettingsService type
@@ -26,6 +28,121 @@ func (_m *MockSettingsService) AcceptEula() error {
 	return r0
 }
 
+// GetAgentSettings provides a mock function with given fields:
+func (_m *MockSettingsService) GetAgentSettings() (*models.AgentSettings, error) {
+	ret := _m.Called()
+
+	var r0 *models.AgentSettings
+	if rf, ok := ret.Get(0).(func() *models.AgentSettings); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AgentSettings)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLogSamplingRules provides a mock function with given fields:
+func (_m *MockSettingsService) GetLogSamplingRules() ([]*models.LogSamplingRule, error) {
+	ret := _m.Called()
+
+	var r0 []*models.LogSamplingRule
+	if rf, ok := ret.Get(0).(func() []*models.LogSamplingRule); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.LogSamplingRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMaintenanceMode provides a mock function with given fields:
+func (_m *MockSettingsService) GetMaintenanceMode() (*models.MaintenanceMode, error) {
+	ret := _m.Called()
+
+	var r0 *models.MaintenanceMode
+	if rf, ok := ret.Get(0).(func() *models.MaintenanceMode); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.MaintenanceMode)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReadOnlyMode provides a mock function with given fields:
+func (_m *MockSettingsService) GetReadOnlyMode() (*models.ReadOnlyMode, error) {
+	ret := _m.Called()
+
+	var r0 *models.ReadOnlyMode
+	if rf, ok := ret.Get(0).(func() *models.ReadOnlyMode); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ReadOnlyMode)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRunnerSettings provides a mock function with given fields:
+func (_m *MockSettingsService) GetRunnerSettings() (*models.RunnerSettings, error) {
+	ret := _m.Called()
+
+	var r0 *models.RunnerSettings
+	if rf, ok := ret.Get(0).(func() *models.RunnerSettings); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RunnerSettings)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // InitializeIdentifier provides a mock function with given fields:
 func (_m *MockSettingsService) InitializeIdentifier() (uuid.UUID, error) {
 	ret := _m.Called()
@@ -69,3 +186,73 @@ func (_m *MockSettingsService) IsEulaAccepted() (bool, error) {
 
 	return r0, r1
 }
+
+// SaveAgentSettings provides a mock function with given fields: agentSettings
+func (_m *MockSettingsService) SaveAgentSettings(agentSettings *models.AgentSettings) error {
+	ret := _m.Called(agentSettings)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.AgentSettings) error); ok {
+		r0 = rf(agentSettings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveLogSamplingRules provides a mock function with given fields: rules
+func (_m *MockSettingsService) SaveLogSamplingRules(rules []*models.LogSamplingRule) error {
+	ret := _m.Called(rules)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*models.LogSamplingRule) error); ok {
+		r0 = rf(rules)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveMaintenanceMode provides a mock function with given fields: maintenanceMode
+func (_m *MockSettingsService) SaveMaintenanceMode(maintenanceMode *models.MaintenanceMode) error {
+	ret := _m.Called(maintenanceMode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.MaintenanceMode) error); ok {
+		r0 = rf(maintenanceMode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveReadOnlyMode provides a mock function with given fields: readOnlyMode
+func (_m *MockSettingsService) SaveReadOnlyMode(readOnlyMode *models.ReadOnlyMode) error {
+	ret := _m.Called(readOnlyMode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.ReadOnlyMode) error); ok {
+		r0 = rf(readOnlyMode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveRunnerSettings provides a mock function with given fields: settings
+func (_m *MockSettingsService) SaveRunnerSettings(settings *models.RunnerSettings) error {
+	ret := _m.Called(settings)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.RunnerSettings) error); ok {
+		r0 = rf(settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
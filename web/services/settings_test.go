@@ -2,10 +2,12 @@ package services
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
 	"gorm.io/gorm"
 )
 
@@ -67,3 +69,127 @@ func (suite *SettingsServiceTestSuite) TestSettingsService_DetectsTrentoAlreadyI
 	suite.NoError(err)
 	suite.EqualValues(dummyInstallationID, installationID.String())
 }
+
+func (suite *SettingsServiceTestSuite) TestSettingsService_RunnerSettings() {
+	_, err := suite.settingsService.InitializeIdentifier()
+	suite.NoError(err)
+
+	runnerSettings, err := suite.settingsService.GetRunnerSettings()
+	suite.NoError(err)
+	suite.Equal(&models.RunnerSettings{MaxConcurrentRuns: 4, MaxRunsPerTarget: 1}, runnerSettings)
+
+	err = suite.settingsService.SaveRunnerSettings(&models.RunnerSettings{MaxConcurrentRuns: 0, MaxRunsPerTarget: 2})
+	suite.NoError(err)
+
+	runnerSettings, err = suite.settingsService.GetRunnerSettings()
+	suite.NoError(err)
+	suite.Equal(&models.RunnerSettings{MaxConcurrentRuns: 0, MaxRunsPerTarget: 2}, runnerSettings)
+}
+
+func (suite *SettingsServiceTestSuite) TestSettingsService_MaintenanceMode() {
+	_, err := suite.settingsService.InitializeIdentifier()
+	suite.NoError(err)
+
+	maintenanceMode, err := suite.settingsService.GetMaintenanceMode()
+	suite.NoError(err)
+	suite.Equal(&models.MaintenanceMode{}, maintenanceMode)
+
+	since := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	enabled := &models.MaintenanceMode{
+		Enabled:         true,
+		Message:         "Upgrading to 1.0",
+		PauseCollection: true,
+		Since:           &since,
+		EnabledBy:       "admin",
+	}
+	err = suite.settingsService.SaveMaintenanceMode(enabled)
+	suite.NoError(err)
+
+	maintenanceMode, err = suite.settingsService.GetMaintenanceMode()
+	suite.NoError(err)
+	suite.Equal(enabled.Enabled, maintenanceMode.Enabled)
+	suite.Equal(enabled.Message, maintenanceMode.Message)
+	suite.Equal(enabled.PauseCollection, maintenanceMode.PauseCollection)
+	suite.Equal(enabled.EnabledBy, maintenanceMode.EnabledBy)
+	suite.True(since.Equal(*maintenanceMode.Since))
+
+	err = suite.settingsService.SaveMaintenanceMode(&models.MaintenanceMode{})
+	suite.NoError(err)
+
+	maintenanceMode, err = suite.settingsService.GetMaintenanceMode()
+	suite.NoError(err)
+	suite.Equal(&models.MaintenanceMode{}, maintenanceMode)
+}
+
+func (suite *SettingsServiceTestSuite) TestSettingsService_ReadOnlyMode() {
+	_, err := suite.settingsService.InitializeIdentifier()
+	suite.NoError(err)
+
+	readOnlyMode, err := suite.settingsService.GetReadOnlyMode()
+	suite.NoError(err)
+	suite.Equal(&models.ReadOnlyMode{}, readOnlyMode)
+
+	since := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	enabled := &models.ReadOnlyMode{
+		Enabled:   true,
+		Reason:    "Quarterly audit",
+		Since:     &since,
+		EnabledBy: "admin",
+	}
+	err = suite.settingsService.SaveReadOnlyMode(enabled)
+	suite.NoError(err)
+
+	readOnlyMode, err = suite.settingsService.GetReadOnlyMode()
+	suite.NoError(err)
+	suite.Equal(enabled.Enabled, readOnlyMode.Enabled)
+	suite.Equal(enabled.Reason, readOnlyMode.Reason)
+	suite.Equal(enabled.EnabledBy, readOnlyMode.EnabledBy)
+	suite.True(since.Equal(*readOnlyMode.Since))
+
+	err = suite.settingsService.SaveReadOnlyMode(&models.ReadOnlyMode{})
+	suite.NoError(err)
+
+	readOnlyMode, err = suite.settingsService.GetReadOnlyMode()
+	suite.NoError(err)
+	suite.Equal(&models.ReadOnlyMode{}, readOnlyMode)
+}
+
+func (suite *SettingsServiceTestSuite) TestSettingsService_LogSamplingRules() {
+	_, err := suite.settingsService.InitializeIdentifier()
+	suite.NoError(err)
+
+	rules, err := suite.settingsService.GetLogSamplingRules()
+	suite.NoError(err)
+	suite.Empty(rules)
+
+	saved := []*models.LogSamplingRule{
+		{Method: "POST", Route: "/api/hosts/:id/heartbeat", Rate: 0},
+		{Method: "POST", Route: "/api/collect", Rate: 0.1},
+	}
+	err = suite.settingsService.SaveLogSamplingRules(saved)
+	suite.NoError(err)
+
+	rules, err = suite.settingsService.GetLogSamplingRules()
+	suite.NoError(err)
+	suite.Equal(saved, rules)
+}
+
+func (suite *SettingsServiceTestSuite) TestSettingsService_AgentSettings() {
+	_, err := suite.settingsService.InitializeIdentifier()
+	suite.NoError(err)
+
+	agentSettings, err := suite.settingsService.GetAgentSettings()
+	suite.NoError(err)
+	suite.Equal(&models.AgentSettings{DiscoveryPeriods: map[string]int{}, DisabledDiscoveries: []string{}}, agentSettings)
+
+	saved := &models.AgentSettings{
+		DiscoveryPeriods:    map[string]int{"ha_cluster_discovery": 30},
+		DisabledDiscoveries: []string{"kubernetes_discovery"},
+	}
+	err = suite.settingsService.SaveAgentSettings(saved)
+	suite.NoError(err)
+
+	agentSettings, err = suite.settingsService.GetAgentSettings()
+	suite.NoError(err)
+	suite.Equal(saved, agentSettings)
+}
@@ -1,6 +1,10 @@
 package services
 
 import (
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
 	"github.com/trento-project/trento/web/entities"
@@ -9,6 +13,8 @@ import (
 
 const (
 	SlesIdentifier string = "SLES_SAP"
+
+	subscriptionTimeLayout = "2006-01-02 15:04:05 MST"
 )
 
 //go:generate mockery --name=SubscriptionsService --inpackage --filename=subscriptions_mock.go
@@ -16,6 +22,7 @@ type SubscriptionsService interface {
 	IsTrentoPremium() (bool, error)
 	GetPremiumData() (*models.PremiumData, error)
 	GetHostSubscriptions(host string) ([]*models.SlesSubscription, error)
+	GetExpiringSubscriptions(before time.Time) ([]*models.ExpiringSubscription, error)
 }
 
 type subscriptionsService struct {
@@ -68,3 +75,49 @@ func (s *subscriptionsService) GetHostSubscriptions(id string) ([]*models.SlesSu
 
 	return subModels, nil
 }
+
+// GetExpiringSubscriptions returns the subscriptions expiring before the given time, sorted by expiration
+func (s *subscriptionsService) GetExpiringSubscriptions(before time.Time) ([]*models.ExpiringSubscription, error) {
+	var rows []struct {
+		entities.SlesSubscription
+		HostName string
+	}
+
+	err := s.db.
+		Model(&entities.SlesSubscription{}).
+		Select("sles_subscriptions.*, hosts.name AS host_name").
+		Joins("JOIN hosts ON hosts.agent_id = sles_subscriptions.agent_id").
+		Where("sles_subscriptions.expires_at <> ''").
+		Find(&rows).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	var expiring []*models.ExpiringSubscription
+	for _, row := range rows {
+		expiresAt, err := time.Parse(subscriptionTimeLayout, row.ExpiresAt)
+		if err != nil {
+			log.Warnf("could not parse the expiration date of subscription %s: %s", row.ID, err)
+			continue
+		}
+
+		if expiresAt.After(before) {
+			continue
+		}
+
+		expiring = append(expiring, &models.ExpiringSubscription{
+			HostID:    row.AgentID,
+			HostName:  row.HostName,
+			ID:        row.ID,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].ExpiresAt.Before(expiring[j].ExpiresAt)
+	})
+
+	return expiring, nil
+}
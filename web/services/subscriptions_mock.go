@@ -5,6 +5,8 @@ package services
 import (
 	mock "github.com/stretchr/testify/mock"
 	models "github.com/trento-project/trento/web/models"
+
+	time "time"
 )
 
 // MockSubscriptionsService is an autogenerated mock type for the SubscriptionsService type
@@ -12,6 +14,29 @@ type MockSubscriptionsService struct {
 	mock.Mock
 }
 
+// GetExpiringSubscriptions provides a mock function with given fields: before
+func (_m *MockSubscriptionsService) GetExpiringSubscriptions(before time.Time) ([]*models.ExpiringSubscription, error) {
+	ret := _m.Called(before)
+
+	var r0 []*models.ExpiringSubscription
+	if rf, ok := ret.Get(0).(func(time.Time) []*models.ExpiringSubscription); ok {
+		r0 = rf(before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ExpiringSubscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetHostSubscriptions provides a mock function with given fields: host
 func (_m *MockSubscriptionsService) GetHostSubscriptions(host string) ([]*models.SlesSubscription, error) {
 	ret := _m.Called(host)
@@ -2,6 +2,7 @@ package services
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/test/helpers"
@@ -139,3 +140,26 @@ func (suite *SubscriptionServiceTestSuite) TestSubscriptionService_GetHostSubscr
 	suite.ElementsMatch(expectedSubs, subs)
 	suite.NoError(err)
 }
+
+func (suite *SubscriptionServiceTestSuite) TestSubscriptionService_GetExpiringSubscriptions() {
+	subs, err := suite.subsService.GetExpiringSubscriptions(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+
+	expectedSubs := []*models.ExpiringSubscription{
+		{
+			HostID:    "1",
+			HostName:  "host1",
+			ID:        "SLES_SAP",
+			ExpiresAt: time.Date(2024, 3, 20, 9, 55, 32, 0, time.UTC),
+		},
+	}
+	suite.NoError(err)
+	suite.Equal(len(expectedSubs), len(subs))
+	suite.Equal(expectedSubs[0].HostID, subs[0].HostID)
+	suite.Equal(expectedSubs[0].HostName, subs[0].HostName)
+	suite.Equal(expectedSubs[0].ID, subs[0].ID)
+	suite.True(expectedSubs[0].ExpiresAt.Equal(subs[0].ExpiresAt))
+
+	subs, err = suite.subsService.GetExpiringSubscriptions(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	suite.NoError(err)
+	suite.Empty(subs)
+}
@@ -37,19 +37,20 @@ func (_m *MockTagsService) Delete(value string, resourceType string, resourceId
 	return r0
 }
 
-// GetAll provides a mock function with given fields: resourceTypeFilter
-func (_m *MockTagsService) GetAll(resourceTypeFilter ...string) ([]string, error) {
+// GetAll provides a mock function with given fields: organization, resourceTypeFilter
+func (_m *MockTagsService) GetAll(organization string, resourceTypeFilter ...string) ([]string, error) {
 	_va := make([]interface{}, len(resourceTypeFilter))
 	for _i := range resourceTypeFilter {
 		_va[_i] = resourceTypeFilter[_i]
 	}
 	var _ca []interface{}
+	_ca = append(_ca, organization)
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
 	var r0 []string
-	if rf, ok := ret.Get(0).(func(...string) []string); ok {
-		r0 = rf(resourceTypeFilter...)
+	if rf, ok := ret.Get(0).(func(string, ...string) []string); ok {
+		r0 = rf(organization, resourceTypeFilter...)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]string)
@@ -57,8 +58,8 @@ func (_m *MockTagsService) GetAll(resourceTypeFilter ...string) ([]string, error
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(...string) error); ok {
-		r1 = rf(resourceTypeFilter...)
+	if rf, ok := ret.Get(1).(func(string, ...string) error); ok {
+		r1 = rf(organization, resourceTypeFilter...)
 	} else {
 		r1 = ret.Error(1)
 	}
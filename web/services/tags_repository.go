@@ -0,0 +1,83 @@
+package services
+
+import (
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=TagsRepository --inpackage --filename=tags_repository_mock.go
+
+// TagsRepository is the storage of the tags of the resources
+type TagsRepository interface {
+	// GetAll returns the distinct tag values of the given resource types, of any resource type if none is given,
+	// of the resources of the organization only unless empty
+	GetAll(organization string, resourceTypes ...string) ([]string, error)
+	GetAllByResource(resourceType string, resourceId string) ([]string, error)
+	Create(tag *models.Tag) error
+	Delete(tag *models.Tag) error
+}
+
+type tagsRepository struct {
+	db *gorm.DB
+}
+
+func NewTagsRepository(db *gorm.DB) *tagsRepository {
+	return &tagsRepository{db: db}
+}
+
+func (r *tagsRepository) GetAll(organization string, resourceTypes ...string) ([]string, error) {
+	db := r.db.Model(&models.Tag{}).Scopes(resourceOrganizationScope(organization, "resource_type", "resource_id"))
+	if len(resourceTypes) > 0 {
+		db = db.Where("resource_type IN ?", resourceTypes)
+	}
+
+	return getTags(db)
+}
+
+func (r *tagsRepository) GetAllByResource(resourceType string, resourceId string) ([]string, error) {
+	db := r.db.Where("resource_type", resourceType)
+	db = db.Where("resource_id", resourceId)
+
+	return getTags(db)
+}
+
+// Create and Delete refresh the search document of the resource, indexed by its tags as well
+func (r *tagsRepository) Create(tag *models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tag).Error; err != nil {
+			return err
+		}
+
+		return datapipeline.RefreshSearchDocument(tx, tag.ResourceType, tag.ResourceID)
+	})
+}
+
+func (r *tagsRepository) Delete(tag *models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(tag).Error; err != nil {
+			return err
+		}
+
+		return datapipeline.RefreshSearchDocument(tx, tag.ResourceType, tag.ResourceID)
+	})
+}
+
+func getTags(db *gorm.DB) ([]string, error) {
+	var tags []models.Tag
+	result := db.
+		Distinct("value").
+		Order("value").
+		Find(&tags)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var tagStrings []string
+	for _, t := range tags {
+		tagStrings = append(tagStrings, t.Value)
+	}
+
+	return tagStrings, nil
+}
@@ -0,0 +1,94 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockTagsRepository is an autogenerated mock type for the TagsRepository type
+type MockTagsRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: tag
+func (_m *MockTagsRepository) Create(tag *models.Tag) error {
+	ret := _m.Called(tag)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Tag) error); ok {
+		r0 = rf(tag)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: tag
+func (_m *MockTagsRepository) Delete(tag *models.Tag) error {
+	ret := _m.Called(tag)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Tag) error); ok {
+		r0 = rf(tag)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: organization, resourceTypes
+func (_m *MockTagsRepository) GetAll(organization string, resourceTypes ...string) ([]string, error) {
+	_va := make([]interface{}, len(resourceTypes))
+	for _i := range resourceTypes {
+		_va[_i] = resourceTypes[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, organization)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, ...string) []string); ok {
+		r0 = rf(organization, resourceTypes...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, ...string) error); ok {
+		r1 = rf(organization, resourceTypes...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllByResource provides a mock function with given fields: resourceType, resourceId
+func (_m *MockTagsRepository) GetAllByResource(resourceType string, resourceId string) ([]string, error) {
+	ret := _m.Called(resourceType, resourceId)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
+		r0 = rf(resourceType, resourceId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(resourceType, resourceId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
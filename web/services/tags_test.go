@@ -3,8 +3,10 @@ package services
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 
 	"gorm.io/gorm"
@@ -24,17 +26,17 @@ func TestTagsServiceTestSuite(t *testing.T) {
 func (suite *TagsServiceTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(models.Tag{})
+	suite.db.AutoMigrate(models.Tag{}, entities.SearchDocument{}, entities.Host{}, entities.Cluster{}, entities.SAPSystemInstance{})
 	loadTagsFixtures(suite.db)
 }
 
 func (suite *TagsServiceTestSuite) TearDownSuite() {
-	suite.db.Migrator().DropTable(models.Tag{})
+	suite.db.Migrator().DropTable(models.Tag{}, entities.SearchDocument{}, entities.Host{}, entities.Cluster{}, entities.SAPSystemInstance{})
 }
 
 func (suite *TagsServiceTestSuite) SetupTest() {
 	suite.tx = suite.db.Begin()
-	suite.tagsService = NewTagsService(suite.tx)
+	suite.tagsService = NewTagsService(NewTagsRepository(suite.tx))
 }
 
 func (suite *TagsServiceTestSuite) TearDownTest() {
@@ -65,17 +67,28 @@ func loadTagsFixtures(db *gorm.DB) {
 }
 
 func (suite *TagsServiceTestSuite) TestTagsService_GetAll() {
-	tags, _ := suite.tagsService.GetAll()
+	tags, _ := suite.tagsService.GetAll("")
 
 	suite.ElementsMatch([]string{"tag1", "tag2", "tag3"}, tags)
 }
 
 func (suite *TagsServiceTestSuite) TestTagsService_GetAll_Filter() {
-	tags, _ := suite.tagsService.GetAll(models.TagClusterResourceType, models.TagHostResourceType)
+	tags, _ := suite.tagsService.GetAll("", models.TagClusterResourceType, models.TagHostResourceType)
 
 	suite.ElementsMatch([]string{"tag2", "tag3"}, tags)
 }
 
+func (suite *TagsServiceTestSuite) TestTagsService_GetAll_Organization() {
+	suite.tx.Create(&entities.Host{AgentID: "suse", Organization: "acme"})
+	suite.tx.Create(&entities.Cluster{ID: "cluster_id", Organization: "globex"})
+
+	tags, _ := suite.tagsService.GetAll("acme")
+	suite.ElementsMatch([]string{"tag3"}, tags)
+
+	tags, _ = suite.tagsService.GetAll("acme", models.TagClusterResourceType)
+	suite.Empty(tags)
+}
+
 func (suite *TagsServiceTestSuite) TestTagsService_GetAllByResource() {
 	tags, _ := suite.tagsService.GetAllByResource(models.TagHostResourceType, "suse")
 
@@ -117,3 +130,13 @@ func (suite *TagsServiceTestSuite) TestTagsService_Delete() {
 			Value:        "tag3",
 		}}, tags)
 }
+
+func TestTagsService_Create(t *testing.T) {
+	repository := new(MockTagsRepository)
+	repository.On("Create", &models.Tag{Value: "tag1", ResourceType: models.TagHostResourceType, ResourceID: "host1"}).Return(nil)
+
+	tagsService := NewTagsService(repository)
+
+	assert.NoError(t, tagsService.Create("tag1", models.TagHostResourceType, "host1"))
+	repository.AssertExpectations(t)
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/trento-project/trento/internal/cluster"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+// timelineDiscoveryTypes are the discoveries whose changes are reported as configuration changes
+var timelineDiscoveryTypes = []string{
+	datapipeline.HostDiscovery,
+	datapipeline.ClusterDiscovery,
+	datapipeline.SAPsystemDiscovery,
+	datapipeline.CloudDiscovery,
+	datapipeline.SubscriptionDiscovery,
+	datapipeline.KubernetesDiscovery,
+}
+
+//go:generate mockery --name=TimelineService --inpackage --filename=timeline_mock.go
+
+type TimelineService interface {
+	GetHostTimeline(agentID string, days int) ([]*models.TimelineEvent, error)
+}
+
+type timelineService struct {
+	db *gorm.DB
+}
+
+func NewTimelineService(db *gorm.DB) *timelineService {
+	return &timelineService{db: db}
+}
+
+// GetHostTimeline merges the heartbeat transitions, the checks results changes, the configuration changes,
+// the fencing events and the changes made by the users to a host over the last given days, the most recent first
+func (s *timelineService) GetHostTimeline(agentID string, days int) ([]*models.TimelineEvent, error) {
+	since := timeNow().AddDate(0, 0, -days)
+	events := []*models.TimelineEvent{}
+
+	heartbeatEvents, err := s.getHeartbeatEvents(agentID, since)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, heartbeatEvents...)
+
+	configEvents, err := s.getConfigChangeEvents(agentID, since)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, configEvents...)
+
+	auditEvents, err := s.getAuditEvents(agentID, since)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, auditEvents...)
+
+	var host entities.Host
+	err = s.db.Where("agent_id", agentID).First(&host).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if host.ClusterID != "" {
+		checkEvents, err := s.getCheckResultEvents(host.ClusterID, host.Name, since)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, checkEvents...)
+
+		fencingEvents, err := s.getFencingEvents(host.ClusterID, host.Name, since)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fencingEvents...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.After(events[j].Time)
+	})
+
+	return events, nil
+}
+
+func (s *timelineService) getHeartbeatEvents(agentID string, since time.Time) ([]*models.TimelineEvent, error) {
+	var periods []entities.HostHeartbeatPeriod
+
+	err := s.db.
+		Where("agent_id = ? AND ended_at >= ?", agentID, since).
+		Order("started_at").
+		Find(&periods).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var firstPeriod entities.HostHeartbeatPeriod
+	err = s.db.
+		Where("agent_id", agentID).
+		Order("started_at").
+		Limit(1).
+		Find(&firstPeriod).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	// the periods rolled up may have been pruned, the first one left not being the first heartbeat
+	var rolledUp int64
+	err = s.db.Model(&entities.HostAvailabilityRollup{}).
+		Where("agent_id = ? AND covered > 0 AND started_at < ?", agentID, firstPeriod.StartedAt.Truncate(time.Hour)).
+		Count(&rolledUp).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	firstPeriodID := firstPeriod.ID
+	if rolledUp > 0 {
+		firstPeriodID = 0
+	}
+
+	events := []*models.TimelineEvent{}
+	for _, p := range periods {
+		if !p.StartedAt.Before(since) {
+			summary := "Heartbeats resumed"
+			if p.ID == firstPeriodID {
+				summary = "First heartbeat received"
+			}
+
+			events = append(events, &models.TimelineEvent{
+				Time:     p.StartedAt,
+				Kind:     models.TimelineEventHeartbeat,
+				Severity: models.TimelineSeverityPassing,
+				Summary:  summary,
+			})
+		}
+
+		// The last period of a host still sending heartbeats keeps being extended
+		if timeNow().Sub(p.EndedAt) > HeartbeatTreshold {
+			events = append(events, &models.TimelineEvent{
+				Time:     p.EndedAt,
+				Kind:     models.TimelineEventHeartbeat,
+				Severity: models.TimelineSeverityCritical,
+				Summary:  "Heartbeats stopped",
+			})
+		}
+	}
+
+	return events, nil
+}
+
+// getConfigChangeEvents compares every discovery with the previous one of the same type,
+// the first discovery of each type is not a change
+func (s *timelineService) getConfigChangeEvents(agentID string, since time.Time) ([]*models.TimelineEvent, error) {
+	events := []*models.TimelineEvent{}
+
+	for _, discoveryType := range timelineDiscoveryTypes {
+		var discoveries []datapipeline.DataCollectedEvent
+
+		err := s.db.
+			Where("agent_id = ? AND discovery_type = ? AND created_at >= ?", agentID, discoveryType, since).
+			Order("created_at").
+			Find(&discoveries).
+			Error
+		if err != nil {
+			return nil, err
+		}
+
+		if len(discoveries) == 0 {
+			continue
+		}
+
+		var previous datapipeline.DataCollectedEvent
+		err = s.db.
+			Where("agent_id = ? AND discovery_type = ? AND created_at < ?", agentID, discoveryType, since).
+			Order("created_at DESC").
+			First(&previous).
+			Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		for _, discovery := range discoveries {
+			if previous.ID != 0 && !jsonEqual(previous.Payload, discovery.Payload) {
+				events = append(events, &models.TimelineEvent{
+					Time:     discovery.CreatedAt,
+					Kind:     models.TimelineEventConfigChange,
+					Severity: models.TimelineSeverityInfo,
+					Summary:  "Discovered configuration changed",
+					Details:  map[string]string{"discovery_type": discoveryType},
+				})
+			}
+			previous = discovery
+		}
+	}
+
+	return events, nil
+}
+
+func (s *timelineService) getAuditEvents(agentID string, since time.Time) ([]*models.TimelineEvent, error) {
+	var auditEntries []entities.AuditEntry
+
+	err := s.db.
+		Where("resource_type = ? AND resource_id = ? AND created_at >= ?", models.TagHostResourceType, agentID, since).
+		Order("created_at").
+		Find(&auditEntries).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*models.TimelineEvent{}
+	for _, e := range auditEntries {
+		action := strings.ReplaceAll(e.Action, "_", " ")
+
+		events = append(events, &models.TimelineEvent{
+			Time:     e.CreatedAt,
+			Kind:     models.TimelineEventAudit,
+			Severity: models.TimelineSeverityInfo,
+			Summary:  fmt.Sprintf("%s%s by %s", strings.ToUpper(action[:1]), action[1:], e.Actor),
+			Details: map[string]string{
+				"actor":  e.Actor,
+				"action": e.Action,
+			},
+		})
+	}
+
+	return events, nil
+}
+
+func (s *timelineService) getCheckResultEvents(clusterID string, hostname string, since time.Time) ([]*models.TimelineEvent, error) {
+	var checksResults []entities.ChecksResult
+
+	err := s.db.
+		Where("group_id = ? AND created_at >= ?", clusterID, since).
+		Order("created_at").
+		Find(&checksResults).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(checksResults) == 0 {
+		return []*models.TimelineEvent{}, nil
+	}
+
+	var previous entities.ChecksResult
+	err = s.db.
+		Where("group_id = ? AND created_at < ?", clusterID, since).
+		Order("created_at DESC").
+		First(&previous).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		previous, checksResults = checksResults[0], checksResults[1:]
+	} else if err != nil {
+		return nil, err
+	}
+
+	previousModel, err := previous.ToModel()
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*models.TimelineEvent{}
+	for _, checksResult := range checksResults {
+		currentModel, err := checksResult.ToModel()
+		if err != nil {
+			return nil, err
+		}
+
+		diff := currentModel.Diff(previousModel)
+		for _, change := range append(append(diff.NewlyFailing, diff.NewlyPassing...), diff.NewlyMuted...) {
+			if change.Host != hostname {
+				continue
+			}
+
+			severity := change.Current
+			if isMutedResult(change.Current) {
+				severity = models.TimelineSeverityInfo
+			}
+
+			events = append(events, &models.TimelineEvent{
+				Time:     checksResult.CreatedAt,
+				Kind:     models.TimelineEventCheckResult,
+				Severity: severity,
+				Summary:  fmt.Sprintf("Check %s changed from %s to %s", change.CheckID, resultLabel(change.Previous), resultLabel(change.Current)),
+				Details: map[string]string{
+					"check_id": change.CheckID,
+					"previous": change.Previous,
+					"current":  change.Current,
+				},
+			})
+		}
+
+		previousModel = currentModel
+	}
+
+	return events, nil
+}
+
+// getFencingEvents follows the state of the host cluster node as reported by the designated controller
+func (s *timelineService) getFencingEvents(clusterID string, hostname string, since time.Time) ([]*models.TimelineEvent, error) {
+	var discoveries []datapipeline.DataCollectedEvent
+
+	err := s.db.
+		Where("discovery_type = ? AND payload->>'Id' = ? AND created_at >= ?", datapipeline.ClusterDiscovery, clusterID, since).
+		Order("created_at").
+		Find(&discoveries).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*models.TimelineEvent{}
+	// The node is assumed to be up when the period starts
+	wasUp := true
+
+	for _, discovery := range discoveries {
+		var c cluster.Cluster
+		if err := json.Unmarshal(discovery.Payload, &c); err != nil || !c.DC {
+			continue
+		}
+
+		for _, node := range c.Crmmon.Nodes {
+			if node.Name != hostname {
+				continue
+			}
+
+			isUp := node.Online && !node.Unclean
+			switch {
+			case wasUp && !isUp && node.Unclean:
+				events = append(events, &models.TimelineEvent{
+					Time:     discovery.CreatedAt,
+					Kind:     models.TimelineEventFencing,
+					Severity: models.TimelineSeverityCritical,
+					Summary:  "Cluster node unclean, it is going to be fenced",
+				})
+			case wasUp && !isUp:
+				events = append(events, &models.TimelineEvent{
+					Time:     discovery.CreatedAt,
+					Kind:     models.TimelineEventFencing,
+					Severity: models.TimelineSeverityWarning,
+					Summary:  "Cluster node went offline",
+				})
+			case !wasUp && isUp:
+				events = append(events, &models.TimelineEvent{
+					Time:     discovery.CreatedAt,
+					Kind:     models.TimelineEventFencing,
+					Severity: models.TimelineSeverityPassing,
+					Summary:  "Cluster node back online",
+				})
+			}
+			wasUp = isUp
+		}
+	}
+
+	return events, nil
+}
+
+func isMutedResult(result string) bool {
+	return result == models.CheckSkipped || result == ""
+}
+
+func resultLabel(result string) string {
+	if result == "" {
+		return "not executed"
+	}
+
+	return result
+}
+
+func jsonEqual(a []byte, b []byte) bool {
+	var decodedA, decodedB interface{}
+	if json.Unmarshal(a, &decodedA) != nil || json.Unmarshal(b, &decodedB) != nil {
+		return string(a) == string(b)
+	}
+
+	return reflect.DeepEqual(decodedA, decodedB)
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var timelineNow = time.Date(2022, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+func timelineHoursAgo(hours int) time.Time {
+	return timelineNow.Add(-time.Duration(hours) * time.Hour)
+}
+
+func timelineChecksPayload(result string) datatypes.JSON {
+	payload, _ := json.Marshal(models.ChecksResult{
+		Checks: map[string]*models.ChecksByHost{
+			"check1": {Hosts: map[string]*models.Check{"node01": {Result: result}, "node02": {Result: models.CheckPassing}}},
+		},
+	})
+	return payload
+}
+
+func timelineClusterPayload(dc bool, online bool, unclean bool) datatypes.JSON {
+	return datatypes.JSON(`{"Id": "cluster1", "DC": ` + boolString(dc) + `, "Crmmon": {"Nodes": [` +
+		`{"Name": "node01", "Online": ` + boolString(online) + `, "Unclean": ` + boolString(unclean) + `},` +
+		`{"Name": "node02", "Online": true}]}}`)
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+type TimelineServiceTestSuite struct {
+	suite.Suite
+	db              *gorm.DB
+	tx              *gorm.DB
+	timelineService *timelineService
+}
+
+func TestTimelineServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TimelineServiceTestSuite))
+}
+
+func (suite *TimelineServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Host{}, &entities.HostHeartbeatPeriod{}, &entities.HostAvailabilityRollup{}, &entities.ChecksResult{}, &datapipeline.DataCollectedEvent{}, &entities.AuditEntry{})
+}
+
+func (suite *TimelineServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.Host{}, &entities.HostHeartbeatPeriod{}, &entities.HostAvailabilityRollup{}, &entities.ChecksResult{}, &datapipeline.DataCollectedEvent{}, &entities.AuditEntry{})
+}
+
+func (suite *TimelineServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.timelineService = NewTimelineService(suite.tx)
+
+	timeNow = func() time.Time {
+		return timelineNow
+	}
+
+	suite.tx.Create(&entities.Host{AgentID: "agent1", Name: "node01", ClusterID: "cluster1"})
+	suite.tx.Create(&[]entities.HostHeartbeatPeriod{
+		{AgentID: "agent1", StartedAt: timelineHoursAgo(24 * 30), EndedAt: timelineHoursAgo(24 * 10)},
+		{AgentID: "agent1", StartedAt: timelineHoursAgo(48), EndedAt: timelineHoursAgo(30)},
+		{AgentID: "agent1", StartedAt: timelineHoursAgo(20), EndedAt: timelineNow},
+	})
+	suite.tx.Create(&[]entities.ChecksResult{
+		{GroupID: "cluster1", CreatedAt: timelineHoursAgo(24 * 10), Payload: timelineChecksPayload(models.CheckPassing)},
+		{GroupID: "cluster1", CreatedAt: timelineHoursAgo(10), Payload: timelineChecksPayload(models.CheckCritical)},
+		{GroupID: "cluster1", CreatedAt: timelineHoursAgo(9), Payload: timelineChecksPayload(models.CheckCritical)},
+	})
+	suite.tx.Create(&[]datapipeline.DataCollectedEvent{
+		{AgentID: "agent1", DiscoveryType: datapipeline.HostDiscovery, CreatedAt: timelineHoursAgo(24 * 10), Payload: datatypes.JSON(`{"os_version": "15.2"}`)},
+		{AgentID: "agent1", DiscoveryType: datapipeline.HostDiscovery, CreatedAt: timelineHoursAgo(8), Payload: datatypes.JSON(`{"os_version": "15.2"}`)},
+		{AgentID: "agent1", DiscoveryType: datapipeline.HostDiscovery, CreatedAt: timelineHoursAgo(7), Payload: datatypes.JSON(`{"os_version": "15.3"}`)},
+		{AgentID: "agent2", DiscoveryType: datapipeline.ClusterDiscovery, CreatedAt: timelineHoursAgo(6), Payload: timelineClusterPayload(true, false, true)},
+		{AgentID: "agent1", DiscoveryType: datapipeline.ClusterDiscovery, CreatedAt: timelineHoursAgo(5), Payload: timelineClusterPayload(false, true, false)},
+		{AgentID: "agent2", DiscoveryType: datapipeline.ClusterDiscovery, CreatedAt: timelineHoursAgo(4), Payload: timelineClusterPayload(true, true, false)},
+	})
+	suite.tx.Create(&[]entities.AuditEntry{
+		{CreatedAt: timelineHoursAgo(3), Actor: "admin", Action: models.AuditActionTagCreated, ResourceType: models.TagHostResourceType, ResourceID: "agent1", Before: datatypes.JSON("null"), After: datatypes.JSON(`{"tag": "prod"}`)},
+		{CreatedAt: timelineHoursAgo(2), Actor: "admin", Action: models.AuditActionTagCreated, ResourceType: models.TagClusterResourceType, ResourceID: "agent1", Before: datatypes.JSON("null"), After: datatypes.JSON(`{"tag": "prod"}`)},
+	})
+}
+
+func (suite *TimelineServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *TimelineServiceTestSuite) TestTimelineService_GetHostTimeline() {
+	events, err := suite.timelineService.GetHostTimeline("agent1", 7)
+	suite.NoError(err)
+
+	var summaries []string
+	for _, event := range events {
+		summaries = append(summaries, event.Kind+": "+event.Summary)
+	}
+
+	suite.Equal([]string{
+		"audit: Tag created by admin",
+		"fencing: Cluster node back online",
+		"fencing: Cluster node unclean, it is going to be fenced",
+		"config_change: Discovered configuration changed",
+		"check_result: Check check1 changed from passing to critical",
+		"heartbeat: Heartbeats resumed",
+		"heartbeat: Heartbeats stopped",
+		"heartbeat: Heartbeats resumed",
+	}, summaries)
+
+	suite.Equal(models.TimelineSeverityCritical, events[4].Severity)
+	suite.Equal(map[string]string{
+		"check_id": "check1",
+		"previous": models.CheckPassing,
+		"current":  models.CheckCritical,
+	}, events[4].Details)
+	suite.True(events[3].Time.Equal(timelineHoursAgo(7)))
+}
+
+func (suite *TimelineServiceTestSuite) TestTimelineService_GetHostTimelineFirstHeartbeat() {
+	events, err := suite.timelineService.GetHostTimeline("agent1", 60)
+	suite.NoError(err)
+
+	last := events[len(events)-1]
+	suite.Equal(models.TimelineEventHeartbeat, last.Kind)
+	suite.Equal("First heartbeat received", last.Summary)
+}
+
+func (suite *TimelineServiceTestSuite) TestTimelineService_GetHostTimelineUnknownHost() {
+	events, err := suite.timelineService.GetHostTimeline("unknown", 7)
+	suite.NoError(err)
+	suite.Empty(events)
+}
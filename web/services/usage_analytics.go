@@ -0,0 +1,181 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	// usageCountersRetention after which the daily counters are dropped
+	usageCountersRetention = 90 * 24 * time.Hour
+	// usageAnalyticsSettingsTTL is how long the settings are cached, as they are checked on every request
+	usageAnalyticsSettingsTTL = 10 * time.Second
+)
+
+//go:generate mockery --name=UsageAnalyticsService --inpackage --filename=usage_analytics_mock.go
+
+// UsageAnalyticsService counts the uses of the routes of the console, if opted in, to tell which features are used
+type UsageAnalyticsService interface {
+	GetSettings() (*models.UsageAnalyticsSettings, error)
+	SaveSettings(settings *models.UsageAnalyticsSettings) error
+	// Count counts a use of the route if the analytics are enabled, in memory until flushed
+	Count(route string) error
+	// Flush adds the counts kept in memory to the stored ones
+	Flush() error
+	// GetReport returns the uses of the routes in the last days, including today
+	GetReport(days int) (*models.UsageReport, error)
+}
+
+type usageAnalyticsService struct {
+	db               *gorm.DB
+	settingsMutex    sync.Mutex
+	settings         *models.UsageAnalyticsSettings
+	settingsLoadedAt time.Time
+	countsMutex      sync.Mutex
+	counts           map[usageKey]int64
+}
+
+type usageKey struct {
+	day   time.Time
+	route string
+}
+
+func NewUsageAnalyticsService(db *gorm.DB) *usageAnalyticsService {
+	return &usageAnalyticsService{db: db, counts: make(map[usageKey]int64)}
+}
+
+func (s *usageAnalyticsService) GetSettings() (*models.UsageAnalyticsSettings, error) {
+	var settings entities.Settings
+	err := s.db.First(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UsageAnalyticsSettings{Enabled: settings.UsageAnalyticsEnabled}, nil
+}
+
+func (s *usageAnalyticsService) SaveSettings(settings *models.UsageAnalyticsSettings) error {
+	err := s.db.Model(&entities.Settings{}).Where("1 = 1").
+		Update("usage_analytics_enabled", settings.Enabled).
+		Error
+	if err != nil {
+		return err
+	}
+
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+	s.settings = nil
+
+	return nil
+}
+
+func (s *usageAnalyticsService) Count(route string) error {
+	settings, err := s.cachedSettings()
+	if err != nil {
+		return err
+	}
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	s.countsMutex.Lock()
+	defer s.countsMutex.Unlock()
+	s.counts[usageKey{day: usageDay(timeNow()), route: route}]++
+
+	return nil
+}
+
+func (s *usageAnalyticsService) Flush() error {
+	s.countsMutex.Lock()
+	counts := s.counts
+	s.counts = make(map[usageKey]int64)
+	s.countsMutex.Unlock()
+
+	var counters []entities.UsageCounter
+	for key, count := range counts {
+		counters = append(counters, entities.UsageCounter{Day: key.day, Route: key.route, Count: count})
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if len(counters) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "day"}, {Name: "route"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("usage_counters.count + excluded.count")}),
+			}).Create(&counters).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("day < ?", usageDay(timeNow().Add(-usageCountersRetention))).
+			Delete(&entities.UsageCounter{}).
+			Error
+	})
+}
+
+func (s *usageAnalyticsService) GetReport(days int) (*models.UsageReport, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	since := usageDay(timeNow()).AddDate(0, 0, 1-days)
+
+	var routes []*models.RouteUsage
+	err = s.db.Model(&entities.UsageCounter{}).
+		Select("route, SUM(count) AS count, MAX(day) AS last_used_on").
+		Where("day >= ?", since).
+		Group("route").
+		Scan(&routes).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Count != routes[j].Count {
+			return routes[i].Count > routes[j].Count
+		}
+		return routes[i].Route < routes[j].Route
+	})
+
+	if routes == nil {
+		routes = []*models.RouteUsage{}
+	}
+
+	return &models.UsageReport{Enabled: settings.Enabled, Since: since, Routes: routes}, nil
+}
+
+// cachedSettings spares a query for every request,
+// the settings saved by another instance of the web server are picked up once the cache expires
+func (s *usageAnalyticsService) cachedSettings() (*models.UsageAnalyticsSettings, error) {
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+
+	if s.settings != nil && timeNow().Sub(s.settingsLoadedAt) < usageAnalyticsSettingsTTL {
+		return s.settings, nil
+	}
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	s.settings = settings
+	s.settingsLoadedAt = timeNow()
+
+	return settings, nil
+}
+
+func usageDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,101 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockUsageAnalyticsService is an autogenerated mock type for the UsageAnalyticsService type
+type MockUsageAnalyticsService struct {
+	mock.Mock
+}
+
+// Count provides a mock function with given fields: route
+func (_m *MockUsageAnalyticsService) Count(route string) error {
+	ret := _m.Called(route)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(route)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Flush provides a mock function with given fields:
+func (_m *MockUsageAnalyticsService) Flush() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetReport provides a mock function with given fields: days
+func (_m *MockUsageAnalyticsService) GetReport(days int) (*models.UsageReport, error) {
+	ret := _m.Called(days)
+
+	var r0 *models.UsageReport
+	if rf, ok := ret.Get(0).(func(int) *models.UsageReport); ok {
+		r0 = rf(days)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UsageReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSettings provides a mock function with given fields:
+func (_m *MockUsageAnalyticsService) GetSettings() (*models.UsageAnalyticsSettings, error) {
+	ret := _m.Called()
+
+	var r0 *models.UsageAnalyticsSettings
+	if rf, ok := ret.Get(0).(func() *models.UsageAnalyticsSettings); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UsageAnalyticsSettings)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveSettings provides a mock function with given fields: settings
+func (_m *MockUsageAnalyticsService) SaveSettings(settings *models.UsageAnalyticsSettings) error {
+	ret := _m.Called(settings)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.UsageAnalyticsSettings) error); ok {
+		r0 = rf(settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type UsageAnalyticsServiceTestSuite struct {
+	suite.Suite
+	db                    *gorm.DB
+	tx                    *gorm.DB
+	usageAnalyticsService *usageAnalyticsService
+	now                   time.Time
+}
+
+func TestUsageAnalyticsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(UsageAnalyticsServiceTestSuite))
+}
+
+func (suite *UsageAnalyticsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.Settings{}, &entities.UsageCounter{})
+}
+
+func (suite *UsageAnalyticsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.Settings{}, &entities.UsageCounter{})
+}
+
+func (suite *UsageAnalyticsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.tx.Create(&entities.Settings{InstallationID: "59fd8017-b7fd-477b-9ebe-b658c558f3e9"})
+	suite.usageAnalyticsService = NewUsageAnalyticsService(suite.tx)
+
+	suite.now = time.Date(2022, time.March, 12, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return suite.now }
+}
+
+func (suite *UsageAnalyticsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+	timeNow = time.Now
+}
+
+func (suite *UsageAnalyticsServiceTestSuite) TestUsageAnalyticsService_NotCountedUnlessEnabled() {
+	suite.NoError(suite.usageAnalyticsService.Count("GET /hosts"))
+	suite.NoError(suite.usageAnalyticsService.Flush())
+
+	report, err := suite.usageAnalyticsService.GetReport(30)
+	suite.NoError(err)
+	suite.False(report.Enabled)
+	suite.Empty(report.Routes)
+}
+
+func (suite *UsageAnalyticsServiceTestSuite) TestUsageAnalyticsService_Report() {
+	suite.NoError(suite.usageAnalyticsService.SaveSettings(&models.UsageAnalyticsSettings{Enabled: true}))
+
+	suite.NoError(suite.usageAnalyticsService.Count("GET /hosts"))
+	suite.NoError(suite.usageAnalyticsService.Count("GET /hosts"))
+	suite.NoError(suite.usageAnalyticsService.Count("GET /clusters"))
+	suite.NoError(suite.usageAnalyticsService.Flush())
+
+	suite.now = suite.now.AddDate(0, 0, 1)
+	suite.NoError(suite.usageAnalyticsService.Count("GET /clusters"))
+	suite.NoError(suite.usageAnalyticsService.Count("GET /clusters"))
+	suite.NoError(suite.usageAnalyticsService.Flush())
+
+	// flushing the counts of a day twice adds them up
+	suite.NoError(suite.usageAnalyticsService.Count("GET /hosts"))
+	suite.NoError(suite.usageAnalyticsService.Flush())
+
+	report, err := suite.usageAnalyticsService.GetReport(30)
+	suite.NoError(err)
+	suite.True(report.Enabled)
+	suite.Equal(time.Date(2022, time.February, 12, 0, 0, 0, 0, time.UTC), report.Since)
+	suite.Len(report.Routes, 2)
+	suite.Equal("GET /clusters", report.Routes[0].Route)
+	suite.EqualValues(3, report.Routes[0].Count)
+	suite.Equal("GET /hosts", report.Routes[1].Route)
+	suite.EqualValues(3, report.Routes[1].Count)
+	suite.True(report.Routes[1].LastUsedOn.Equal(time.Date(2022, time.March, 13, 0, 0, 0, 0, time.UTC)))
+
+	report, err = suite.usageAnalyticsService.GetReport(1)
+	suite.NoError(err)
+	suite.Len(report.Routes, 2)
+	suite.EqualValues(2, report.Routes[0].Count)
+	suite.EqualValues(1, report.Routes[1].Count)
+}
+
+func (suite *UsageAnalyticsServiceTestSuite) TestUsageAnalyticsService_Retention() {
+	suite.tx.Create(&entities.UsageCounter{Day: time.Date(2021, time.November, 1, 0, 0, 0, 0, time.UTC), Route: "GET /hosts", Count: 5})
+
+	suite.NoError(suite.usageAnalyticsService.Flush())
+
+	var count int64
+	suite.tx.Model(&entities.UsageCounter{}).Count(&count)
+	suite.EqualValues(0, count)
+}
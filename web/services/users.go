@@ -0,0 +1,260 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+var (
+	// ErrLastAdmin is returned when an operation would leave no administrators to manage the users
+	ErrLastAdmin = errors.New("at least one admin user is required")
+
+	// dummyPasswordHash is compared when the user does not exist,
+	// so that unknown and known users take the same time to be rejected
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+//go:generate mockery --name=UsersService --inpackage --filename=users_mock.go
+
+type UsersService interface {
+	// Authenticate returns nil if the credentials are not valid
+	Authenticate(username string, password string) (*models.User, error)
+	// Create restricts the user to the resources of the organization, to none if empty
+	Create(username string, password string, role string, organization string) (*models.User, error)
+	// Bootstrap creates the given admin user only if there are no users at all
+	Bootstrap(username string, password string) error
+	// GetAll returns the users of the organization, all of them if empty
+	GetAll(organization string) ([]*models.User, error)
+	GetByUsername(username string) (*models.User, error)
+	// GetByID returns nil if the user does not exist
+	GetByID(id int64) (*models.User, error)
+	UpdateRole(id int64, role string) (*models.User, error)
+	// UpdateOrganization restricts the user to the resources of the organization, to all of them if empty.
+	// It returns nil if the user does not exist
+	UpdateOrganization(id int64, organization string) (*models.User, error)
+	Delete(id int64) error
+}
+
+type usersService struct {
+	db *gorm.DB
+}
+
+func NewUsersService(db *gorm.DB) *usersService {
+	return &usersService{db: db}
+}
+
+func (s *usersService) Authenticate(username string, password string) (*models.User, error) {
+	var user entities.User
+
+	err := s.db.Where("username", username).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dummyPasswordHashOnce.Do(func() {
+				dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+			})
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user.ToModel(), nil
+}
+
+func (s *usersService) Create(username string, password string, role string, organization string) (*models.User, error) {
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("username and password cannot be empty")
+	}
+
+	if !models.IsValidUserRole(role) {
+		return nil, fmt.Errorf("invalid role: %s", role)
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entities.User{
+		Username:     username,
+		PasswordHash: string(passwordHash),
+		Role:         role,
+		Organization: organization,
+	}
+
+	err = s.db.Create(&user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return user.ToModel(), nil
+}
+
+func (s *usersService) Bootstrap(username string, password string) error {
+	var count int64
+
+	err := s.db.Model(&entities.User{}).Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = s.Create(username, password, models.UserRoleAdmin, "")
+
+	return err
+}
+
+func (s *usersService) GetAll(organization string) ([]*models.User, error) {
+	var users []entities.User
+
+	err := s.db.
+		Scopes(organizationScope(organization, "organization")).
+		Order("username").
+		Find(&users).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	userList := []*models.User{}
+	for _, user := range users {
+		userList = append(userList, user.ToModel())
+	}
+
+	return userList, nil
+}
+
+func (s *usersService) GetByUsername(username string) (*models.User, error) {
+	var user entities.User
+
+	err := s.db.Where("username", username).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user.ToModel(), nil
+}
+
+func (s *usersService) GetByID(id int64) (*models.User, error) {
+	var user entities.User
+
+	err := s.db.First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user.ToModel(), nil
+}
+
+// UpdateRole returns nil if the user does not exist
+func (s *usersService) UpdateRole(id int64, role string) (*models.User, error) {
+	if !models.IsValidUserRole(role) {
+		return nil, fmt.Errorf("invalid role: %s", role)
+	}
+
+	var user entities.User
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&user, id).Error
+		if err != nil {
+			return err
+		}
+
+		if user.Role == models.UserRoleAdmin && role != models.UserRoleAdmin {
+			if err := checkOtherAdmins(tx, id); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&user).Update("role", role).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user.ToModel(), nil
+}
+
+func (s *usersService) UpdateOrganization(id int64, organization string) (*models.User, error) {
+	var user entities.User
+
+	err := s.db.First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&user).Update("organization", organization).Error; err != nil {
+		return nil, err
+	}
+
+	return user.ToModel(), nil
+}
+
+func (s *usersService) Delete(id int64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user entities.User
+
+		err := tx.First(&user, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if user.Role == models.UserRoleAdmin {
+			if err := checkOtherAdmins(tx, id); err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&user).Error
+	})
+}
+
+// checkOtherAdmins returns ErrLastAdmin if the given user is the only administrator left
+func checkOtherAdmins(tx *gorm.DB, id int64) error {
+	var count int64
+
+	err := tx.Model(&entities.User{}).Where("role = ? AND id <> ?", models.UserRoleAdmin, id).Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrLastAdmin
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package services
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	models "github.com/trento-project/trento/web/models"
+)
+
+// MockUsersService is an autogenerated mock type for the UsersService type
+type MockUsersService struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: username, password
+func (_m *MockUsersService) Authenticate(username string, password string) (*models.User, error) {
+	ret := _m.Called(username, password)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(string, string) *models.User); ok {
+		r0 = rf(username, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(username, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Bootstrap provides a mock function with given fields: username, password
+func (_m *MockUsersService) Bootstrap(username string, password string) error {
+	ret := _m.Called(username, password)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(username, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Create provides a mock function with given fields: username, password, role, organization
+func (_m *MockUsersService) Create(username string, password string, role string, organization string) (*models.User, error) {
+	ret := _m.Called(username, password, role, organization)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *models.User); ok {
+		r0 = rf(username, password, role, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(username, password, role, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *MockUsersService) Delete(id int64) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: organization
+func (_m *MockUsersService) GetAll(organization string) ([]*models.User, error) {
+	ret := _m.Called(organization)
+
+	var r0 []*models.User
+	if rf, ok := ret.Get(0).(func(string) []*models.User); ok {
+		r0 = rf(organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: id
+func (_m *MockUsersService) GetByID(id int64) (*models.User, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(int64) *models.User); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUsername provides a mock function with given fields: username
+func (_m *MockUsersService) GetByUsername(username string) (*models.User, error) {
+	ret := _m.Called(username)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
+		r0 = rf(username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateOrganization provides a mock function with given fields: id, organization
+func (_m *MockUsersService) UpdateOrganization(id int64, organization string) (*models.User, error) {
+	ret := _m.Called(id, organization)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(int64, string) *models.User); ok {
+		r0 = rf(id, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, string) error); ok {
+		r1 = rf(id, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateRole provides a mock function with given fields: id, role
+func (_m *MockUsersService) UpdateRole(id int64, role string) (*models.User, error) {
+	ret := _m.Called(id, role)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(int64, string) *models.User); ok {
+		r0 = rf(id, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, string) error); ok {
+		r1 = rf(id, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
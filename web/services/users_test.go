@@ -0,0 +1,183 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type UsersServiceTestSuite struct {
+	suite.Suite
+	db           *gorm.DB
+	tx           *gorm.DB
+	usersService *usersService
+}
+
+func TestUsersServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(UsersServiceTestSuite))
+}
+
+func (suite *UsersServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.User{})
+}
+
+func (suite *UsersServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.User{})
+}
+
+func (suite *UsersServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.usersService = NewUsersService(suite.tx)
+}
+
+func (suite *UsersServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_CreateAndAuthenticate() {
+	created, err := suite.usersService.Create("admin", "secret", models.UserRoleOperator, "")
+	suite.NoError(err)
+	suite.Equal("admin", created.Username)
+	suite.Equal(models.UserRoleOperator, created.Role)
+
+	var user entities.User
+	suite.tx.First(&user)
+	suite.NotEqual("secret", user.PasswordHash)
+
+	authenticated, err := suite.usersService.Authenticate("admin", "secret")
+	suite.NoError(err)
+	suite.Equal(created.ID, authenticated.ID)
+
+	authenticated, err = suite.usersService.Authenticate("admin", "wrong")
+	suite.NoError(err)
+	suite.Nil(authenticated)
+
+	authenticated, err = suite.usersService.Authenticate("unknown", "secret")
+	suite.NoError(err)
+	suite.Nil(authenticated)
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_CreateEmptyPassword() {
+	_, err := suite.usersService.Create("admin", "", models.UserRoleViewer, "")
+	suite.EqualError(err, "username and password cannot be empty")
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_CreateInvalidRole() {
+	_, err := suite.usersService.Create("admin", "secret", "superuser", "")
+	suite.EqualError(err, "invalid role: superuser")
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_Bootstrap() {
+	suite.NoError(suite.usersService.Bootstrap("admin", "secret"))
+	suite.NoError(suite.usersService.Bootstrap("other", "secret"))
+
+	var usernames []string
+	suite.tx.Model(&entities.User{}).Pluck("username", &usernames)
+	suite.Equal([]string{"admin"}, usernames)
+
+	user, err := suite.usersService.GetByUsername("admin")
+	suite.NoError(err)
+	suite.Equal(models.UserRoleAdmin, user.Role)
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_GetAll() {
+	suite.usersService.Create("viewer", "secret", models.UserRoleViewer, "")
+	suite.usersService.Create("admin", "secret", models.UserRoleAdmin, "")
+
+	users, err := suite.usersService.GetAll("")
+	suite.NoError(err)
+	suite.Equal(2, len(users))
+	suite.Equal("admin", users[0].Username)
+	suite.Equal("viewer", users[1].Username)
+	suite.Equal(models.UserRoleViewer, users[1].Role)
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_GetAllOrganization() {
+	suite.usersService.Create("viewer", "secret", models.UserRoleViewer, "acme")
+	suite.usersService.Create("admin", "secret", models.UserRoleAdmin, "")
+
+	users, err := suite.usersService.GetAll("acme")
+	suite.NoError(err)
+	suite.Equal(1, len(users))
+	suite.Equal("viewer", users[0].Username)
+	suite.Equal("acme", users[0].Organization)
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_GetByUsernameNotFound() {
+	user, err := suite.usersService.GetByUsername("unknown")
+	suite.NoError(err)
+	suite.Nil(user)
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_GetByID() {
+	created, _ := suite.usersService.Create("viewer", "secret", models.UserRoleViewer, "")
+
+	user, err := suite.usersService.GetByID(created.ID)
+	suite.NoError(err)
+	suite.Equal("viewer", user.Username)
+
+	user, err = suite.usersService.GetByID(created.ID + 1)
+	suite.NoError(err)
+	suite.Nil(user)
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_UpdateRole() {
+	admin, _ := suite.usersService.Create("admin", "secret", models.UserRoleAdmin, "")
+	viewer, _ := suite.usersService.Create("viewer", "secret", models.UserRoleViewer, "")
+
+	updated, err := suite.usersService.UpdateRole(viewer.ID, models.UserRoleOperator)
+	suite.NoError(err)
+	suite.Equal(models.UserRoleOperator, updated.Role)
+
+	_, err = suite.usersService.UpdateRole(admin.ID, models.UserRoleViewer)
+	suite.Equal(ErrLastAdmin, err)
+
+	_, err = suite.usersService.UpdateRole(viewer.ID, models.UserRoleAdmin)
+	suite.NoError(err)
+
+	updated, err = suite.usersService.UpdateRole(admin.ID, models.UserRoleViewer)
+	suite.NoError(err)
+	suite.Equal(models.UserRoleViewer, updated.Role)
+
+	updated, err = suite.usersService.UpdateRole(-1, models.UserRoleViewer)
+	suite.NoError(err)
+	suite.Nil(updated)
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_UpdateOrganization() {
+	user, _ := suite.usersService.Create("user", "secret", models.UserRoleViewer, "")
+
+	updated, err := suite.usersService.UpdateOrganization(user.ID, "acme")
+	suite.NoError(err)
+	suite.Equal("acme", updated.Organization)
+
+	found, _ := suite.usersService.GetByUsername("user")
+	suite.Equal("acme", found.Organization)
+
+	updated, err = suite.usersService.UpdateOrganization(user.ID, "")
+	suite.NoError(err)
+	suite.Empty(updated.Organization)
+
+	updated, err = suite.usersService.UpdateOrganization(-1, "acme")
+	suite.NoError(err)
+	suite.Nil(updated)
+}
+
+func (suite *UsersServiceTestSuite) TestUsersService_Delete() {
+	admin, _ := suite.usersService.Create("admin", "secret", models.UserRoleAdmin, "")
+	viewer, _ := suite.usersService.Create("viewer", "secret", models.UserRoleViewer, "")
+
+	suite.Equal(ErrLastAdmin, suite.usersService.Delete(admin.ID))
+	suite.NoError(suite.usersService.Delete(viewer.ID))
+	suite.NoError(suite.usersService.Delete(viewer.ID))
+
+	var usernames []string
+	suite.tx.Model(&entities.User{}).Pluck("username", &usernames)
+	suite.Equal([]string{"admin"}, usernames)
+}
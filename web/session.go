@@ -0,0 +1,50 @@
+package web
+
+import (
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	gorillaSessions "github.com/gorilla/sessions"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	SessionUserKey string = "user"
+	// anonymousUser owns the preferences stored while nobody is logged in
+	anonymousUser string = "anonymous"
+)
+
+// sessionUserID returns the identifier of the authenticated user, set by the AuthMiddleware: the impersonated one
+// during an impersonation, the owner of the personal access token, or the API key acting as a user.
+// It falls back to the user bound to the current session
+func sessionUserID(c *gin.Context) string {
+	value, _ := c.Get(ContextUserKey)
+	if user, ok := value.(*models.User); ok && user.Username != "" {
+		return user.Username
+	}
+
+	session := sessions.Default(c)
+
+	userID, ok := session.Get(SessionUserKey).(string)
+	if !ok || userID == "" {
+		return anonymousUser
+	}
+
+	return userID
+}
+
+// renewSession discards the values and the ID of the session, a new one being issued when it is saved,
+// so that a session planted in the browser before the login is not the one authenticated (session fixation)
+func renewSession(session sessions.Session) {
+	session.Clear()
+
+	// the stores keeping the sessions server side, like Redis, generate a new ID for the sessions saved without one
+	if s, ok := session.(storedSession); ok {
+		s.Session().ID = ""
+	}
+}
+
+// storedSession exposes the session of the store behind the one of gin
+type storedSession interface {
+	Session() *gorillaSessions.Session
+}
@@ -0,0 +1,74 @@
+package web
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-contrib/sessions/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// redisSessionStoreIdleConnections is the size of the pool of connections to Redis
+const redisSessionStoreIdleConnections = 10
+
+type SessionConfig struct {
+	// Secrets the sessions are signed with. The first one signs the new sessions,
+	// the others are only used to verify the existing ones, so that a secret can be rotated
+	// without logging out the users
+	Secrets []string
+	// Sessions are kept in Redis instead of cookies when RedisAddress is set,
+	// so that they are shared by all the instances of the server
+	RedisAddress  string
+	RedisPassword string
+}
+
+// NewSessionStore creates the store of the sessions of the users
+func NewSessionStore(config *SessionConfig) (sessions.Store, error) {
+	if config == nil {
+		config = &SessionConfig{}
+	}
+
+	keyPairs, err := sessionKeyPairs(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.RedisAddress == "" {
+		return cookie.NewStore(keyPairs...), nil
+	}
+
+	store, err := redis.NewStore(redisSessionStoreIdleConnections, "tcp", config.RedisAddress, config.RedisPassword, keyPairs...)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the Redis session store at %s: %w", config.RedisAddress, err)
+	}
+
+	return store, nil
+}
+
+// sessionKeyPairs returns the authentication keys of the secrets, without encryption keys
+func sessionKeyPairs(config *SessionConfig) ([][]byte, error) {
+	var keyPairs [][]byte
+
+	for _, secret := range config.Secrets {
+		if secret == "" {
+			continue
+		}
+		keyPairs = append(keyPairs, []byte(secret), nil)
+	}
+
+	if len(keyPairs) > 0 {
+		return keyPairs, nil
+	}
+
+	log.Warn("No session secret configured, the sessions are signed with a random one and will not survive a restart")
+
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return [][]byte{secret, nil}, nil
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSessionTestEngine(t *testing.T, config *SessionConfig) *gin.Engine {
+	store, err := NewSessionStore(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := gin.New()
+	engine.Use(sessions.Sessions("session", store))
+	engine.GET("/set", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set("value", "some-value")
+		_ = session.Save()
+	})
+	engine.GET("/get", func(c *gin.Context) {
+		value, _ := sessions.Default(c).Get("value").(string)
+		c.String(http.StatusOK, value)
+	})
+
+	return engine
+}
+
+func TestSessionStoreSecretRotation(t *testing.T) {
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/set", nil)
+	newSessionTestEngine(t, &SessionConfig{Secrets: []string{"old-secret"}}).ServeHTTP(resp, req)
+	sessionCookie := resp.Header().Get("Set-Cookie")
+
+	cases := []struct {
+		secrets       []string
+		expectedValue string
+	}{
+		{[]string{"new-secret", "old-secret"}, "some-value"},
+		{[]string{"new-secret"}, ""},
+		{nil, ""},
+	}
+
+	for _, tc := range cases {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/get", nil)
+		req.Header.Set("Cookie", sessionCookie)
+		newSessionTestEngine(t, &SessionConfig{Secrets: tc.secrets}).ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expectedValue, resp.Body.String())
+	}
+}
+
+func TestSessionStoreRedisUnreachable(t *testing.T) {
+	_, err := NewSessionStore(&SessionConfig{
+		Secrets:      []string{"secret"},
+		RedisAddress: "127.0.0.1:1",
+	})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+)
+
+const smokeTestAgentID = "779cdd70-e9e2-58ca-b18a-bf3eb3f71244"
+
+// SmokeTestSuite boots the whole application against a real database and drives it through
+// the collector API, to catch the regressions spanning multiple modules that the mocks hide
+type SmokeTestSuite struct {
+	suite.Suite
+	app    *App
+	cancel context.CancelFunc
+}
+
+func TestSmokeTestSuite(t *testing.T) {
+	suite.Run(t, new(SmokeTestSuite))
+}
+
+func (suite *SmokeTestSuite) SetupSuite() {
+	dbConfig, db := helpers.SetupSmokeTestDatabase(suite.T())
+
+	if err := MigrateDB(db); err != nil {
+		suite.T().Fatal(err)
+	}
+
+	config := setupTestConfig()
+	config.DBConfig = dbConfig
+
+	app, err := NewAppWithDeps(config, NewDependencies(config, db, nil))
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	suite.app = app
+
+	var ctx context.Context
+	ctx, suite.cancel = context.WithCancel(context.Background())
+	go app.projectorWorkersPool.Run(ctx)
+
+	for _, fixture := range []string{
+		"host/expected_published_host_discovery.json",
+		"cluster/expected_published_cluster_discovery.json",
+		"sap_system/expected_published_sap_system_discovery_database.json",
+	} {
+		payload, err := ioutil.ReadFile("../test/fixtures/discovery/" + fixture)
+		if err != nil {
+			suite.T().Fatal(err)
+		}
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/collect", strings.NewReader(string(payload)))
+		req.Header.Set("Content-Type", "application/json")
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		suite.Equal(202, resp.Code, fixture)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/hosts/"+smokeTestAgentID+"/heartbeat", nil)
+	app.collectorEngine.ServeHTTP(resp, req)
+	suite.Equal(204, resp.Code)
+}
+
+func (suite *SmokeTestSuite) TearDownSuite() {
+	if suite.cancel != nil {
+		suite.cancel()
+	}
+}
+
+// eventuallyContains waits for the projections to show up in the given page
+func (suite *SmokeTestSuite) eventuallyContains(url string, expected ...string) {
+	suite.Eventually(func() bool {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", url, nil)
+		suite.app.webEngine.ServeHTTP(resp, req)
+
+		if resp.Code != 200 {
+			return false
+		}
+
+		for _, e := range expected {
+			if !strings.Contains(resp.Body.String(), e) {
+				return false
+			}
+		}
+
+		return true
+	}, 10*time.Second, 100*time.Millisecond, "%s does not contain %v", url, expected)
+}
+
+func (suite *SmokeTestSuite) TestHostsPage() {
+	suite.eventuallyContains("/hosts", "thehostnamewherethediscoveryhappened", "/hosts/"+smokeTestAgentID)
+}
+
+func (suite *SmokeTestSuite) TestHostPage() {
+	suite.eventuallyContains("/hosts/"+smokeTestAgentID, "thehostnamewherethediscoveryhappened", "10.1.1.4")
+}
+
+func (suite *SmokeTestSuite) TestClustersPage() {
+	suite.eventuallyContains("/clusters", "hana_cluster")
+}
+
+func (suite *SmokeTestSuite) TestDatabasesPage() {
+	suite.eventuallyContains("/databases", "PRD")
+}
+
+func (suite *SmokeTestSuite) TestHostAvailabilityAPI() {
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/"+smokeTestAgentID+"/availability?days=7", nil)
+	suite.app.webEngine.ServeHTTP(resp, req)
+
+	suite.Equal(200, resp.Code)
+	assert.Contains(suite.T(), resp.Body.String(), `"days":7`)
+}
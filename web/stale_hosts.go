@@ -0,0 +1,37 @@
+package web
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/services"
+)
+
+// StaleHostsDetector periodically marks the hosts missing heartbeats as degraded, or critical,
+// so that their health is reported even if nobody looks at them
+type StaleHostsDetector struct {
+	hostsService services.HostsService
+}
+
+func NewStaleHostsDetector(hostsService services.HostsService) *StaleHostsDetector {
+	return &StaleHostsDetector{hostsService: hostsService}
+}
+
+func (d *StaleHostsDetector) Start(ctx context.Context) {
+	log.Infof("Starting stale hosts detector")
+
+	internal.Repeat("web.stale_hosts_detector", d.detect, internal.HeartbeatInterval, ctx)
+}
+
+func (d *StaleHostsDetector) detect() {
+	changed, err := d.hostsService.MarkStaleHosts()
+	if err != nil {
+		log.Errorf("Error while marking the stale hosts: %s", err)
+	}
+
+	for id, health := range changed {
+		log.Infof("Host %s marked as %s by the missing heartbeats", id, health)
+	}
+}
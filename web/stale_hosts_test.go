@@ -0,0 +1,17 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestStaleHostsDetector(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("MarkStaleHosts").Return(map[string]string{"1": models.HostHealthWarning}, nil)
+
+	NewStaleHostsDetector(hostsService).detect()
+
+	hostsService.AssertExpectations(t)
+}
@@ -0,0 +1,41 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/services"
+)
+
+var staleSAPSystemsReaperInterval = 10 * time.Minute
+
+// StaleSAPSystemsReaper periodically removes the SAP systems, and databases, whose instances all run on hosts
+// that stopped sending heartbeats, like the decommissioned ones
+type StaleSAPSystemsReaper struct {
+	sapSystemsService services.SAPSystemsService
+	ttl               time.Duration
+}
+
+func NewStaleSAPSystemsReaper(sapSystemsService services.SAPSystemsService, ttl time.Duration) *StaleSAPSystemsReaper {
+	return &StaleSAPSystemsReaper{sapSystemsService: sapSystemsService, ttl: ttl}
+}
+
+func (r *StaleSAPSystemsReaper) Start(ctx context.Context) {
+	log.Infof("Starting stale SAP systems reaper")
+
+	internal.Repeat("web.stale_sap_systems_reaper", r.reap, staleSAPSystemsReaperInterval, ctx)
+}
+
+func (r *StaleSAPSystemsReaper) reap() {
+	deleted, err := r.sapSystemsService.DeleteStale(r.ttl)
+	if err != nil {
+		log.Errorf("Error while removing the stale SAP systems: %s", err)
+	}
+
+	for _, sapSystem := range deleted {
+		log.Infof("%s %s (%s) removed after the hosts of its instances were silent for %s", sapSystem.Type, sapSystem.SID, sapSystem.ID, r.ttl)
+	}
+}
@@ -0,0 +1,18 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestStaleSAPSystemsReaper(t *testing.T) {
+	sapSystemsService := new(services.MockSAPSystemsService)
+	sapSystemsService.On("DeleteStale", 24*time.Hour).Return([]*models.SAPSystem{{ID: "1", SID: "PRD", Type: models.SAPSystemTypeApplication}}, nil)
+
+	NewStaleSAPSystemsReaper(sapSystemsService, 24*time.Hour).reap()
+
+	sapSystemsService.AssertExpectations(t)
+}
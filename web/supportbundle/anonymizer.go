@@ -0,0 +1,124 @@
+package supportbundle
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ipv4Pattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	// Too permissive on its own, the matches are validated with net.ParseIP
+	ipv6Pattern = regexp.MustCompile(`(?i)[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}`)
+)
+
+type AnonymizerConfig struct {
+	// Replace the given hostnames
+	Hostnames bool
+	// Replace every IPv4 and IPv6 address
+	IPAddresses bool
+	// Additional sensitive terms to replace, like domains, cluster names or SIDs
+	Terms []string
+}
+
+// Anonymizer replaces hostnames, IP addresses and custom terms with pseudonyms.
+// The same value always gets the same pseudonym, so the relations between the entries of the bundle are preserved.
+type Anonymizer struct {
+	config       AnonymizerConfig
+	pseudonyms   map[string]string
+	counters     map[string]int
+	termsPattern *regexp.Regexp
+}
+
+func NewAnonymizer(config AnonymizerConfig) *Anonymizer {
+	a := &Anonymizer{
+		config:     config,
+		pseudonyms: make(map[string]string),
+		counters:   make(map[string]int),
+	}
+
+	for _, term := range config.Terms {
+		a.register(term, "term")
+	}
+	a.compileTerms()
+
+	return a
+}
+
+// AddHostnames registers the hostnames known to Trento, they are ignored if hostnames anonymization is disabled
+func (a *Anonymizer) AddHostnames(hostnames ...string) {
+	if !a.config.Hostnames {
+		return
+	}
+
+	for _, hostname := range hostnames {
+		a.register(hostname, "host")
+	}
+	a.compileTerms()
+}
+
+func (a *Anonymizer) Anonymize(text string) string {
+	if a.termsPattern != nil {
+		text = a.termsPattern.ReplaceAllStringFunc(text, func(match string) string {
+			return a.pseudonyms[strings.ToLower(match)]
+		})
+	}
+
+	if a.config.IPAddresses {
+		text = ipv4Pattern.ReplaceAllStringFunc(text, a.anonymizeIP)
+		text = ipv6Pattern.ReplaceAllStringFunc(text, a.anonymizeIP)
+	}
+
+	return text
+}
+
+func (a *Anonymizer) anonymizeIP(match string) string {
+	ip := net.ParseIP(match)
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+		return match
+	}
+
+	return a.register(ip.String(), "ip")
+}
+
+// register returns the pseudonym of a value, creating it if needed
+func (a *Anonymizer) register(value string, kind string) string {
+	key := strings.ToLower(value)
+	if pseudonym, ok := a.pseudonyms[key]; ok {
+		return pseudonym
+	}
+
+	a.counters[kind]++
+	pseudonym := fmt.Sprintf("%s-%d", kind, a.counters[kind])
+	a.pseudonyms[key] = pseudonym
+
+	return pseudonym
+}
+
+// compileTerms builds a single case-insensitive pattern out of the registered hostnames and terms,
+// longest first so that a value is not partially replaced by a shorter one it contains
+func (a *Anonymizer) compileTerms() {
+	var terms []string
+	for value, pseudonym := range a.pseudonyms {
+		if strings.HasPrefix(pseudonym, "ip-") || strings.TrimSpace(value) == "" {
+			continue
+		}
+		terms = append(terms, regexp.QuoteMeta(value))
+	}
+
+	if len(terms) == 0 {
+		a.termsPattern = nil
+		return
+	}
+
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i]) != len(terms[j]) {
+			return len(terms[i]) > len(terms[j])
+		}
+		return terms[i] < terms[j]
+	})
+
+	a.termsPattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join(terms, "|") + `)\b`)
+}
@@ -0,0 +1,41 @@
+package supportbundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizeHostnames(t *testing.T) {
+	anonymizer := NewAnonymizer(AnonymizerConfig{Hostnames: true})
+	anonymizer.AddHostnames("vmhana01", "vmhana01-backup", "vmhana02")
+
+	assert.Equal(
+		t,
+		"host-1 is in sync with host-3, host-2 is a backup of host-1.example.com. vmhana011 is unknown",
+		anonymizer.Anonymize("vmhana01 is in sync with vmhana02, VMHANA01-backup is a backup of vmhana01.example.com. vmhana011 is unknown"),
+	)
+}
+
+func TestAnonymizeHostnamesDisabled(t *testing.T) {
+	anonymizer := NewAnonymizer(AnonymizerConfig{})
+	anonymizer.AddHostnames("vmhana01")
+
+	assert.Equal(t, "vmhana01 10.1.1.4", anonymizer.Anonymize("vmhana01 10.1.1.4"))
+}
+
+func TestAnonymizeIPAddresses(t *testing.T) {
+	anonymizer := NewAnonymizer(AnonymizerConfig{IPAddresses: true})
+
+	assert.Equal(
+		t,
+		"ip-1, ip-2 and ip-1 again, ip-3 and ip-3, 127.0.0.1 ::1 at 2021-10-17 15:04:05 on aa:bb:cc:dd:ee:ff",
+		anonymizer.Anonymize("10.1.1.4, 10.1.1.5 and 10.1.1.4 again, fe80::1 and FE80:0:0:0:0:0:0:1, 127.0.0.1 ::1 at 2021-10-17 15:04:05 on aa:bb:cc:dd:ee:ff"),
+	)
+}
+
+func TestAnonymizeTerms(t *testing.T) {
+	anonymizer := NewAnonymizer(AnonymizerConfig{Terms: []string{"ACME", "hana_cluster"}})
+
+	assert.Equal(t, "term-1 owns term-2 at term-1 corp", anonymizer.Anonymize("ACME owns hana_cluster at acme corp"))
+}
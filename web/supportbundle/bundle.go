@@ -0,0 +1,239 @@
+// Package supportbundle gathers the information needed to troubleshoot a Trento installation
+// into an archive that customers can attach to their support cases
+package supportbundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trento-project/trento/version"
+	"github.com/trento-project/trento/web/services"
+)
+
+const maskedValue = "********"
+
+var secretKeyPattern = regexp.MustCompile(`(?i)password|secret|token|credential`)
+
+var journalctlCommand = defaultJournalctlCommand
+
+// defaultJournalctlCommand builds the command reading the recent logs of a systemd unit
+func defaultJournalctlCommand(unit string, lines int) *exec.Cmd {
+	return exec.Command("journalctl", "--unit", unit, "--lines", strconv.Itoa(lines), "--no-pager", "--output", "short-iso")
+}
+
+type Options struct {
+	// Configuration settings of the web application, the secrets are masked
+	Settings map[string]interface{}
+	// Log file to read the recent logs from. If empty, they are read from the journal of LogUnit
+	LogFile string
+	LogUnit string
+	// Number of log lines to include
+	LogLines int
+	// Anonymizer applied to every entry of the bundle, nil disables the anonymization
+	Anonymizer *Anonymizer
+}
+
+type Collector struct {
+	collectorService     services.CollectorService
+	dbMaintenanceService services.DBMaintenanceService
+	hostsRepository      services.HostsRepository
+	options              Options
+}
+
+func NewCollector(
+	collectorService services.CollectorService,
+	dbMaintenanceService services.DBMaintenanceService,
+	hostsRepository services.HostsRepository,
+	options Options,
+) *Collector {
+	return &Collector{
+		collectorService:     collectorService,
+		dbMaintenanceService: dbMaintenanceService,
+		hostsRepository:      hostsRepository,
+		options:              options,
+	}
+}
+
+type entry struct {
+	name    string
+	collect func() ([]byte, error)
+}
+
+// Collect writes a gzipped tarball with the bundle entries to w.
+// An entry failing to be collected does not make the whole bundle fail,
+// the errors are reported in the errors.txt entry instead.
+func (c *Collector) Collect(w io.Writer) error {
+	if c.options.Anonymizer != nil {
+		if err := c.registerHostnames(); err != nil {
+			return fmt.Errorf("could not load the hostnames to anonymize: %w", err)
+		}
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	var errors []string
+	for _, e := range []entry{
+		{"version.txt", c.collectVersion},
+		{"config.json", c.collectConfig},
+		{"logs.txt", c.collectLogs},
+		{"pipeline_status.json", c.collectPipelineStatus},
+		{"schema.json", c.collectSchema},
+	} {
+		content, err := e.collect()
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %s", e.name, err))
+			continue
+		}
+
+		if err := c.writeEntry(tarWriter, e.name, content); err != nil {
+			return err
+		}
+	}
+
+	if len(errors) > 0 {
+		if err := c.writeEntry(tarWriter, "errors.txt", []byte(strings.Join(errors, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+func (c *Collector) registerHostnames() error {
+	hosts, err := c.hostsRepository.GetAll(nil, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, host := range hosts {
+		c.options.Anonymizer.AddHostnames(host.Name)
+	}
+
+	return nil
+}
+
+func (c *Collector) writeEntry(tarWriter *tar.Writer, name string, content []byte) error {
+	if c.options.Anonymizer != nil {
+		content = []byte(c.options.Anonymizer.Anonymize(string(content)))
+	}
+
+	err := tarWriter.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tarWriter.Write(content)
+	return err
+}
+
+func (c *Collector) collectVersion() ([]byte, error) {
+	return []byte(fmt.Sprintf("Trento %s version %s\nbuilt with %s %s/%s\n", version.Flavor, version.Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)), nil
+}
+
+func (c *Collector) collectConfig() ([]byte, error) {
+	return json.MarshalIndent(MaskSecrets(c.options.Settings), "", "  ")
+}
+
+func (c *Collector) collectLogs() ([]byte, error) {
+	if c.options.LogFile == "" {
+		return journalctlCommand(c.options.LogUnit, c.options.LogLines).Output()
+	}
+
+	file, err := os.Open(c.options.LogFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return tail(file, c.options.LogLines)
+}
+
+func (c *Collector) collectPipelineStatus() ([]byte, error) {
+	status, err := c.collectorService.GetPipelineStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	projectorsStatus, err := c.collectorService.GetProjectorsStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"overview":   status,
+		"projectors": projectorsStatus,
+	}, "", "  ")
+}
+
+func (c *Collector) collectSchema() ([]byte, error) {
+	info, err := c.dbMaintenanceService.GetSchemaInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(info, "", "  ")
+}
+
+// MaskSecrets returns a copy of the settings where the values of the keys looking like secrets are masked
+func MaskSecrets(settings map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(settings))
+
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			masked[key] = MaskSecrets(v)
+		default:
+			if secretKeyPattern.MatchString(key) && fmt.Sprint(value) != "" {
+				masked[key] = maskedValue
+			} else {
+				masked[key] = value
+			}
+		}
+	}
+
+	return masked
+}
+
+// tail returns the last n lines read from r
+func tail(r io.Reader, n int) ([]byte, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return []byte{}, nil
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
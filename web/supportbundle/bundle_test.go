@@ -0,0 +1,159 @@
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func readBundle(t *testing.T, data []byte) map[string]string {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := make(map[string]string)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			break
+		}
+
+		content, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[header.Name] = string(content)
+	}
+
+	return entries
+}
+
+func writeLogFile(t *testing.T, content string) string {
+	logFile := filepath.Join(t.TempDir(), "trento-web.log")
+	if err := ioutil.WriteFile(logFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return logFile
+}
+
+func TestCollect(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("GetPipelineStatus").Return(&models.PipelineStatus{EventsCount: 3}, nil)
+	collectorService.On("GetProjectorsStatus").Return([]*models.ProjectorStatus{
+		{ProjectorID: "hosts_projector", AgentID: "agent_id", LastProjectedEventID: 2, LastEventID: 3},
+	}, nil)
+
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("GetSchemaInfo").Return(&models.SchemaInfo{
+		ServerVersion: "14.1",
+		Tables: []*models.SchemaTable{
+			{Name: "hosts", Columns: []*models.SchemaColumn{{Name: "agent_id", DataType: "text"}}},
+		},
+	}, nil)
+
+	hostsRepository := new(services.MockHostsRepository)
+	hostsRepository.On("GetAll", (*services.HostsFilter)(nil), (*services.Page)(nil)).Return([]entities.Host{
+		{AgentID: "agent_id", Name: "vmhana01"},
+	}, nil)
+
+	collector := NewCollector(collectorService, dbMaintenanceService, hostsRepository, Options{
+		Settings: map[string]interface{}{
+			"db-host":     "vmhana01",
+			"db-password": "postgres",
+		},
+		LogFile:    writeLogFile(t, "first line\nvmhana01 connected from 10.1.1.4\nlast line\n"),
+		LogLines:   2,
+		Anonymizer: NewAnonymizer(AnonymizerConfig{Hostnames: true, IPAddresses: true}),
+	})
+
+	var data bytes.Buffer
+	assert.NoError(t, collector.Collect(&data))
+
+	entries := readBundle(t, data.Bytes())
+
+	assert.Contains(t, entries["version.txt"], "Trento")
+	assert.JSONEq(t, `{"db-host":"host-1","db-password":"********"}`, entries["config.json"])
+	assert.Equal(t, "host-1 connected from ip-1\nlast line\n", entries["logs.txt"])
+	assert.JSONEq(t, `{
+		"overview": {"events_count": 3, "last_collected_at": "0001-01-01T00:00:00Z", "last_projected_at": "0001-01-01T00:00:00Z"},
+		"projectors": [{"projector_id": "hosts_projector", "agent_id": "agent_id", "last_projected_event_id": 2, "last_event_id": 3, "updated_at": "0001-01-01T00:00:00Z"}]
+	}`, entries["pipeline_status.json"])
+	assert.JSONEq(t, `{"server_version": "14.1", "tables": [{"name": "hosts", "columns": [{"name": "agent_id", "data_type": "text"}]}]}`, entries["schema.json"])
+	assert.NotContains(t, entries, "errors.txt")
+}
+
+func TestCollectPartialFailures(t *testing.T) {
+	journalctlCommand = func(unit string, lines int) *exec.Cmd {
+		return exec.Command("false")
+	}
+	defer func() {
+		journalctlCommand = defaultJournalctlCommand
+	}()
+
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("GetPipelineStatus").Return(nil, errors.New("kaboom"))
+
+	dbMaintenanceService := new(services.MockDBMaintenanceService)
+	dbMaintenanceService.On("GetSchemaInfo").Return(&models.SchemaInfo{ServerVersion: "14.1"}, nil)
+
+	collector := NewCollector(collectorService, dbMaintenanceService, nil, Options{
+		LogUnit:  "trento-web",
+		LogLines: 10,
+	})
+
+	var data bytes.Buffer
+	assert.NoError(t, collector.Collect(&data))
+
+	entries := readBundle(t, data.Bytes())
+
+	assert.Contains(t, entries, "version.txt")
+	assert.Contains(t, entries, "schema.json")
+	assert.NotContains(t, entries, "logs.txt")
+	assert.NotContains(t, entries, "pipeline_status.json")
+	assert.Contains(t, entries["errors.txt"], "logs.txt: exit status 1")
+	assert.Contains(t, entries["errors.txt"], "pipeline_status.json: kaboom")
+}
+
+func TestMaskSecrets(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"grafana-user":     "admin",
+		"grafana-password": "********",
+		"db-password":      "",
+		"nested": map[string]interface{}{
+			"api-token": "********",
+		},
+	}, MaskSecrets(map[string]interface{}{
+		"grafana-user":     "admin",
+		"grafana-password": "secret",
+		"db-password":      "",
+		"nested": map[string]interface{}{
+			"api-token": "abc",
+		},
+	}))
+}
+
+func TestTail(t *testing.T) {
+	file, err := os.Open(writeLogFile(t, "1\n2\n3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	lines, err := tail(file, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n", string(lines))
+}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +14,15 @@ type JSONTag struct {
 	Tag string `json:"tag" binding:"required"`
 }
 
+// validateTagNamespace refuses the tags of the reserved namespace, kept in sync with the cloud resources
+func validateTagNamespace(tag string) error {
+	if models.IsCloudTag(tag) {
+		return BadRequestError(fmt.Sprintf("the tags starting with %s are reserved to the tags of the cloud resources", models.CloudTagPrefix))
+	}
+
+	return nil
+}
+
 // ApiListTag godoc
 // @Summary List all the tags in the system
 // @Accept json
@@ -26,7 +36,7 @@ func ApiListTag(tagsService services.TagsService) gin.HandlerFunc {
 		query := c.Request.URL.Query()
 		resourceTypeFilter := query["resource_type"]
 
-		tags, err := tagsService.GetAll(resourceTypeFilter...)
+		tags, err := tagsService.GetAll(userOrganization(c), resourceTypeFilter...)
 		if err != nil {
 			_ = c.Error(err)
 			return
@@ -52,7 +62,7 @@ func ApiListTag(tagsService services.TagsService) gin.HandlerFunc {
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /hosts/{id}/tags [post]
-func ApiHostCreateTagHandler(hostsService services.HostsService, tagsService services.TagsService) gin.HandlerFunc {
+func ApiHostCreateTagHandler(hostsService services.HostsService, tagsService services.TagsService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 
@@ -75,12 +85,24 @@ func ApiHostCreateTagHandler(hostsService services.HostsService, tagsService ser
 			return
 		}
 
+		if err := validateText("tag", r.Tag, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if err := validateTagNamespace(r.Tag); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		err = tagsService.Create(r.Tag, models.TagHostResourceType, id)
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
 
+		recordAudit(c, auditService, models.AuditActionTagCreated, models.TagHostResourceType, id, nil, &r)
+
 		c.JSON(http.StatusCreated, &r)
 	}
 }
@@ -93,11 +115,16 @@ func ApiHostCreateTagHandler(hostsService services.HostsService, tagsService ser
 // @Param tag path string true "Tag"
 // @Success 204 {object} map[string]interface{}
 // @Router /hosts/{id}/tags/{tag} [delete]
-func ApiHostDeleteTagHandler(hostsService services.HostsService, tagsService services.TagsService) gin.HandlerFunc {
+func ApiHostDeleteTagHandler(hostsService services.HostsService, tagsService services.TagsService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		tag := c.Param("tag")
 
+		if err := validateTagNamespace(tag); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		host, err := hostsService.GetByID(id)
 		if err != nil {
 			_ = c.Error(err)
@@ -115,6 +142,8 @@ func ApiHostDeleteTagHandler(hostsService services.HostsService, tagsService ser
 			return
 		}
 
+		recordAudit(c, auditService, models.AuditActionTagDeleted, models.TagHostResourceType, id, &JSONTag{Tag: tag}, nil)
+
 		c.JSON(http.StatusNoContent, nil)
 	}
 }
@@ -130,7 +159,7 @@ func ApiHostDeleteTagHandler(hostsService services.HostsService, tagsService ser
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /clusters/{id}/tags [post]
-func ApiClusterCreateTagHandler(clustersService services.ClustersService, tagsService services.TagsService) gin.HandlerFunc {
+func ApiClusterCreateTagHandler(clustersService services.ClustersService, tagsService services.TagsService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 
@@ -153,12 +182,24 @@ func ApiClusterCreateTagHandler(clustersService services.ClustersService, tagsSe
 			return
 		}
 
+		if err := validateText("tag", r.Tag, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if err := validateTagNamespace(r.Tag); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		err = tagsService.Create(r.Tag, models.TagClusterResourceType, id)
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
 
+		recordAudit(c, auditService, models.AuditActionTagCreated, models.TagClusterResourceType, id, nil, &r)
+
 		c.JSON(http.StatusCreated, &r)
 	}
 }
@@ -171,11 +212,16 @@ func ApiClusterCreateTagHandler(clustersService services.ClustersService, tagsSe
 // @Param tag path string true "Tag"
 // @Success 204 {object} map[string]interface{}
 // @Router /clusters/{id}/tags/{tag} [delete]
-func ApiClusterDeleteTagHandler(clustersService services.ClustersService, tagsService services.TagsService) gin.HandlerFunc {
+func ApiClusterDeleteTagHandler(clustersService services.ClustersService, tagsService services.TagsService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		tag := c.Param("tag")
 
+		if err := validateTagNamespace(tag); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		cluster, err := clustersService.GetByID(id)
 		if err != nil {
 			_ = c.Error(err)
@@ -193,6 +239,8 @@ func ApiClusterDeleteTagHandler(clustersService services.ClustersService, tagsSe
 			return
 		}
 
+		recordAudit(c, auditService, models.AuditActionTagDeleted, models.TagClusterResourceType, id, &JSONTag{Tag: tag}, nil)
+
 		c.JSON(http.StatusNoContent, nil)
 	}
 }
@@ -208,7 +256,7 @@ func ApiClusterDeleteTagHandler(clustersService services.ClustersService, tagsSe
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /sapsystems/{id}/tags [post]
-func ApiSAPSystemCreateTagHandler(sapSystemsService services.SAPSystemsService, tagsService services.TagsService) gin.HandlerFunc {
+func ApiSAPSystemCreateTagHandler(sapSystemsService services.SAPSystemsService, tagsService services.TagsService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 
@@ -231,12 +279,24 @@ func ApiSAPSystemCreateTagHandler(sapSystemsService services.SAPSystemsService,
 			return
 		}
 
+		if err := validateText("tag", r.Tag, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if err := validateTagNamespace(r.Tag); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		err = tagsService.Create(r.Tag, models.TagSAPSystemResourceType, id)
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
 
+		recordAudit(c, auditService, models.AuditActionTagCreated, models.TagSAPSystemResourceType, id, nil, &r)
+
 		c.JSON(http.StatusCreated, &r)
 	}
 }
@@ -249,11 +309,16 @@ func ApiSAPSystemCreateTagHandler(sapSystemsService services.SAPSystemsService,
 // @Param tag path string true "Tag"
 // @Success 204 {object} map[string]interface{}
 // @Router /sapsystems/{id}/tags/{tag} [delete]
-func ApiSAPSystemDeleteTagHandler(sapSystemsService services.SAPSystemsService, tagsService services.TagsService) gin.HandlerFunc {
+func ApiSAPSystemDeleteTagHandler(sapSystemsService services.SAPSystemsService, tagsService services.TagsService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		tag := c.Param("tag")
 
+		if err := validateTagNamespace(tag); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		systemList, err := sapSystemsService.GetByID(id)
 		if err != nil {
 			_ = c.Error(err)
@@ -271,6 +336,8 @@ func ApiSAPSystemDeleteTagHandler(sapSystemsService services.SAPSystemsService,
 			return
 		}
 
+		recordAudit(c, auditService, models.AuditActionTagDeleted, models.TagSAPSystemResourceType, id, &JSONTag{Tag: tag}, nil)
+
 		c.JSON(http.StatusNoContent, nil)
 	}
 }
@@ -286,7 +353,7 @@ func ApiSAPSystemDeleteTagHandler(sapSystemsService services.SAPSystemsService,
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /databases/{id}/tags [post]
-func ApiDatabaseCreateTagHandler(sapSystemsService services.SAPSystemsService, tagsService services.TagsService) gin.HandlerFunc {
+func ApiDatabaseCreateTagHandler(sapSystemsService services.SAPSystemsService, tagsService services.TagsService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 
@@ -309,12 +376,24 @@ func ApiDatabaseCreateTagHandler(sapSystemsService services.SAPSystemsService, t
 			return
 		}
 
+		if err := validateText("tag", r.Tag, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if err := validateTagNamespace(r.Tag); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		err = tagsService.Create(r.Tag, models.TagDatabaseResourceType, id)
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
 
+		recordAudit(c, auditService, models.AuditActionTagCreated, models.TagDatabaseResourceType, id, nil, &r)
+
 		c.JSON(http.StatusCreated, &r)
 	}
 }
@@ -327,11 +406,16 @@ func ApiDatabaseCreateTagHandler(sapSystemsService services.SAPSystemsService, t
 // @Param tag path string true "Tag"
 // @Success 204 {object} map[string]interface{}
 // @Router /databases/{id}/tags/{tag} [delete]
-func ApiDatabaseDeleteTagHandler(sapSystemsService services.SAPSystemsService, tagsService services.TagsService) gin.HandlerFunc {
+func ApiDatabaseDeleteTagHandler(sapSystemsService services.SAPSystemsService, tagsService services.TagsService, auditService services.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		tag := c.Param("tag")
 
+		if err := validateTagNamespace(tag); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
 		systemList, err := sapSystemsService.GetByID(id)
 		if err != nil {
 			_ = c.Error(err)
@@ -349,6 +433,8 @@ func ApiDatabaseDeleteTagHandler(sapSystemsService services.SAPSystemsService, t
 			return
 		}
 
+		recordAudit(c, auditService, models.AuditActionTagDeleted, models.TagDatabaseResourceType, id, &JSONTag{Tag: tag}, nil)
+
 		c.JSON(http.StatusNoContent, nil)
 	}
 }
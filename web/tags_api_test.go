@@ -36,9 +36,9 @@ func TestApiListTag(t *testing.T) {
 	tags = append(tags, tagsHosts...)
 
 	mockTagsService := new(services.MockTagsService)
-	mockTagsService.On("GetAll").Return(tags, nil)
-	mockTagsService.On("GetAll", "sapsystems").Return(tagsSAPSystems, nil)
-	mockTagsService.On("GetAll", "hosts").Return(tagsHosts, nil)
+	mockTagsService.On("GetAll", "").Return(tags, nil)
+	mockTagsService.On("GetAll", "", "sapsystems").Return(tagsSAPSystems, nil)
+	mockTagsService.On("GetAll", "", "hosts").Return(tagsHosts, nil)
 	deps := setupTestDependencies()
 	deps.tagsService = mockTagsService
 
@@ -165,6 +165,7 @@ func TestApiResourceTag(t *testing.T) {
 			body, _ := json.Marshal(&JSONTag{tag})
 			url := fmt.Sprintf("/api/%s/%s/tags", tc.resourceType, resourceID)
 			req := httptest.NewRequest("POST", url, bytes.NewBuffer(body))
+			req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 			app.webEngine.ServeHTTP(resp, req)
 
@@ -181,6 +182,7 @@ func TestApiResourceTag(t *testing.T) {
 			body, _ := json.Marshal(&JSONTag{tag})
 			url := fmt.Sprintf("/api/%s/%s/tags", tc.resourceType, notFoundResourceID)
 			req := httptest.NewRequest("POST", url, bytes.NewBuffer(body))
+			req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 			app.webEngine.ServeHTTP(resp, req)
 
@@ -193,6 +195,32 @@ func TestApiResourceTag(t *testing.T) {
 			invalidJSON := []byte("ABC€")
 			url := fmt.Sprintf("/api/%s/%s/tags", tc.resourceType, resourceID)
 			req := httptest.NewRequest("POST", url, bytes.NewBuffer(invalidJSON))
+			req.Header.Set(CSRFTokenHeader, testCSRFToken)
+
+			app.webEngine.ServeHTTP(resp, req)
+
+			assert.Equal(t, 400, resp.Code)
+		})
+
+		t.Run(fmt.Sprintf("Create %s cloud tag 400", tc.resourceType), func(t *testing.T) {
+			resp := httptest.NewRecorder()
+
+			body, _ := json.Marshal(&JSONTag{"cloud:workspace=xdemo"})
+			url := fmt.Sprintf("/api/%s/%s/tags", tc.resourceType, resourceID)
+			req := httptest.NewRequest("POST", url, bytes.NewBuffer(body))
+			req.Header.Set(CSRFTokenHeader, testCSRFToken)
+
+			app.webEngine.ServeHTTP(resp, req)
+
+			assert.Equal(t, 400, resp.Code)
+		})
+
+		t.Run(fmt.Sprintf("Delete %s cloud tag 400", tc.resourceType), func(t *testing.T) {
+			resp := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/%s/%s/tags/%s", tc.resourceType, resourceID, "cloud:workspace=xdemo")
+			req := httptest.NewRequest("DELETE", url, nil)
+			req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 			app.webEngine.ServeHTTP(resp, req)
 
@@ -205,6 +233,7 @@ func TestApiResourceTag(t *testing.T) {
 			body, _ := json.Marshal(&JSONTag{errorTag})
 			url := fmt.Sprintf("/api/%s/%s/tags", tc.resourceType, resourceID)
 			req := httptest.NewRequest("POST", url, bytes.NewBuffer(body))
+			req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 			app.webEngine.ServeHTTP(resp, req)
 
@@ -216,6 +245,7 @@ func TestApiResourceTag(t *testing.T) {
 
 			url := fmt.Sprintf("/api/%s/%s/tags/%s", tc.resourceType, resourceID, tag)
 			req := httptest.NewRequest("DELETE", url, nil)
+			req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 			app.webEngine.ServeHTTP(resp, req)
 
@@ -227,6 +257,7 @@ func TestApiResourceTag(t *testing.T) {
 
 			url := fmt.Sprintf("/api/%s/%s/tags/%s", tc.resourceType, notFoundResourceID, tag)
 			req := httptest.NewRequest("DELETE", url, nil)
+			req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 			app.webEngine.ServeHTTP(resp, req)
 
@@ -238,6 +269,7 @@ func TestApiResourceTag(t *testing.T) {
 
 			url := fmt.Sprintf("/api/%s/%s/tags/%s", tc.resourceType, resourceID, errorTag)
 			req := httptest.NewRequest("DELETE", url, nil)
+			req.Header.Set(CSRFTokenHeader, testCSRFToken)
 
 			app.webEngine.ServeHTTP(resp, req)
 
@@ -9,6 +9,8 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/google/uuid"
+
+	"github.com/trento-project/trento/internal/proxy"
 )
 
 type TelemetryPublisher struct {
@@ -43,9 +45,9 @@ func (tp *TelemetryPublisher) Publish(telemetryName string, installationID uuid.
 
 var telemetryServiceUrl = "https://telemetry.trento.suse.com"
 
-func NewTelemetryPublisher() Publisher {
+func NewTelemetryPublisher(proxyConfig *proxy.Config) Publisher {
 	return &TelemetryPublisher{
 		apiHost:    telemetryServiceUrl,
-		httpClient: &http.Client{},
+		httpClient: proxy.NewHTTPClient(proxyConfig, 0),
 	}
 }
@@ -0,0 +1,115 @@
+package web
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// upgradeWindowShutdownTimeout to complete the requests being spooled when the window is closed
+	upgradeWindowShutdownTimeout = 10 * time.Second
+	// upgradeRetryAfter is suggested to the clients refused during the upgrade
+	upgradeRetryAfter = 30 * time.Second
+)
+
+// UpgradeWindow serves the collector while the console is not started yet, e.g. during the migrations of the database.
+// The collected data is spooled, the rest of the requests are asked to be retried later
+type UpgradeWindow struct {
+	server *http.Server
+	done   chan error
+}
+
+func newUpgradeWindowEngine(config *Config, spool *CollectorSpool) (*gin.Engine, error) {
+	collectorAllowlist, err := ParseCIDRAllowlist(config.CollectorAllowlist)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := NewNamedEngine("upgrade", nil)
+	engine.Use(ErrorHandler)
+	engine.Use(CollectorAllowlistMiddleware(collectorAllowlist))
+	engine.GET("/api/ping", ApiPingHandler)
+	engine.NoRoute(upgradeInProgressHandler)
+
+	group := engine.Group("/api")
+	if config.EnableJWT {
+		group.Use(CollectorJWTMiddleware(config.JWTSecret))
+	}
+	if config.EnablemTLS {
+		group.Use(CollectorCertificateMiddleware(config.MTLSVerifyAgentID))
+	}
+	group.POST("/collect", CollectorBodyLimitMiddleware(collectorMaxBodySize(config)), CollectorDecompressionMiddleware(collectorMaxBodySize(config)), SpoolCollectDataHandler(spool))
+
+	return engine, nil
+}
+
+func upgradeInProgressHandler(c *gin.Context) {
+	c.Header("Retry-After", strconv.Itoa(int(upgradeRetryAfter.Seconds())))
+	_ = c.Error(ServiceUnavailableError("the console is being upgraded"))
+}
+
+// StartUpgradeWindow listens on the collector port until closed
+func StartUpgradeWindow(config *Config, spool *CollectorSpool) (*UpgradeWindow, error) {
+	engine, err := newUpgradeWindowEngine(config, spool)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{
+		Addr:           net.JoinHostPort(config.Host, strconv.Itoa(config.CollectorPort)),
+		Handler:        engine,
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+
+	if config.EnablemTLS {
+		certificateReloader, err := NewCertificateReloader(config.Cert, config.Key, config.CA, config.CRL)
+		if err != nil {
+			return nil, err
+		}
+		server.TLSConfig = certificateReloader.TLSConfig()
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	window := &UpgradeWindow{server: server, done: make(chan error, 1)}
+
+	log.Infof("Upgrade window open, the collected data is spooled in %s", config.CollectorSpoolDir)
+	go func() {
+		var err error
+		if server.TLSConfig != nil {
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			err = server.Serve(listener)
+		}
+		if err == http.ErrServerClosed {
+			err = nil
+		}
+		window.done <- err
+	}()
+
+	return window, nil
+}
+
+// Close stops spooling the collected data, once the requests in progress are completed
+func (w *UpgradeWindow) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), upgradeWindowShutdownTimeout)
+	defer cancel()
+
+	if err := w.server.Shutdown(ctx); err != nil {
+		return err
+	}
+	log.Info("Upgrade window closed")
+
+	return <-w.done
+}
@@ -0,0 +1,150 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+const (
+	// usageReportDefaultDays is the period of time, in days, of the usage report when not given
+	usageReportDefaultDays = 30
+	usageReportMaxDays     = 90
+)
+
+var usageAnalyticsFlushInterval = 1 * time.Minute
+
+// UsageAnalyticsMiddleware counts the successful requests of the users of the console per route, if opted in.
+// Only the route is counted, neither the user, nor the parameters, nor the payload.
+// The requests authenticated with an API key or a personal access token are automations, hence not counted
+func UsageAnalyticsMiddleware(usageService services.UsageAnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" || c.Writer.Status() >= http.StatusBadRequest || bearerAuthenticated(c) {
+			return
+		}
+
+		if _, ok := c.Get(ContextUserKey); !ok {
+			return
+		}
+
+		if err := usageService.Count(c.Request.Method + " " + route); err != nil {
+			log.Errorf("Error while counting the usage of %s: %s", route, err)
+		}
+	}
+}
+
+// UsageAnalyticsFlusher periodically stores the usage counted in memory
+type UsageAnalyticsFlusher struct {
+	usageService services.UsageAnalyticsService
+}
+
+func NewUsageAnalyticsFlusher(usageService services.UsageAnalyticsService) *UsageAnalyticsFlusher {
+	return &UsageAnalyticsFlusher{usageService: usageService}
+}
+
+func (f *UsageAnalyticsFlusher) Start(ctx context.Context) {
+	log.Infof("Starting usage analytics flusher")
+
+	internal.Repeat("web.usage_analytics_flusher", f.flush, usageAnalyticsFlushInterval, ctx)
+}
+
+func (f *UsageAnalyticsFlusher) flush() {
+	if err := f.usageService.Flush(); err != nil {
+		log.Errorf("Error while storing the usage analytics: %s", err)
+	}
+}
+
+// ApiGetUsageReportHandler godoc
+// @Summary Retrieve how many times the pages and the API routes of the console were used
+// @Description Counted only while the usage analytics are enabled, the counts of the last minute may not be included yet
+// @Produce json
+// @Param days query int false "Period of time, in days, up to 90, 30 by default"
+// @Success 200 {object} models.UsageReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /usage [get]
+func ApiGetUsageReportHandler(usageService services.UsageAnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(usageReportDefaultDays)))
+		if err != nil || days < 1 || days > usageReportMaxDays {
+			_ = c.Error(BadRequestError("days must be a number between 1 and 90"))
+			return
+		}
+
+		report, err := usageService.GetReport(days)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// ApiGetUsageAnalyticsSettingsHandler godoc
+// @Summary Retrieve whether the usage of the console is counted
+// @Produce json
+// @Success 200 {object} models.UsageAnalyticsSettings
+// @Failure 500 {object} map[string]string
+// @Router /usage/settings [get]
+func ApiGetUsageAnalyticsSettingsHandler(usageService services.UsageAnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, err := usageService.GetSettings()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+// ApiUpdateUsageAnalyticsSettingsHandler godoc
+// @Summary Opt in, or out, to count the usage of the console
+// @Description Disabling the usage analytics stops the counting, the usage counted so far is kept for 90 days
+// @Accept json
+// @Produce json
+// @Param Body body models.UsageAnalyticsSettings true "The usage analytics settings"
+// @Success 200 {object} models.UsageAnalyticsSettings
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /usage/settings [put]
+func ApiUpdateUsageAnalyticsSettingsHandler(usageService services.UsageAnalyticsService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var settings models.UsageAnalyticsSettings
+
+		err := c.BindJSON(&settings)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		previous, err := usageService.GetSettings()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		err = usageService.SaveSettings(&settings)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionUsageAnalyticsSaved, models.AuditResourceSettings, "usage_analytics",
+			previous, &settings)
+
+		c.JSON(http.StatusOK, &settings)
+	}
+}
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestUsageAnalyticsMiddleware(t *testing.T) {
+	usageService := new(services.MockUsageAnalyticsService)
+	usageService.On("Count", "GET /hosts/:id").Return(nil)
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set(ContextUserKey, &models.User{Username: "admin"})
+		if c.GetHeader("Authorization") != "" {
+			c.Set(ContextApiKeyKey, &models.ApiKey{})
+		}
+	})
+	engine.Use(UsageAnalyticsMiddleware(usageService))
+	engine.GET("/hosts/:id", func(c *gin.Context) {
+		if c.Param("id") == "missing" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/hosts/1", "/hosts/2", "/hosts/missing", "/unknown"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	// automations are not counted
+	req := httptest.NewRequest("GET", "/hosts/1", nil)
+	req.Header.Set("Authorization", "Bearer key")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	usageService.AssertNumberOfCalls(t, "Count", 2)
+}
+
+func TestApiGetUsageReportHandler(t *testing.T) {
+	usageService := new(services.MockUsageAnalyticsService)
+	usageService.On("Count", mock.Anything).Return(nil)
+	usageService.On("GetReport", 7).Return(&models.UsageReport{
+		Enabled: true,
+		Since:   time.Date(2022, time.March, 6, 0, 0, 0, 0, time.UTC),
+		Routes: []*models.RouteUsage{
+			{Route: "GET /hosts", Count: 12, LastUsedOn: time.Date(2022, time.March, 12, 0, 0, 0, 0, time.UTC)},
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.usageService = usageService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/usage?days=7", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"enabled": true,
+		"since": "2022-03-06T00:00:00Z",
+		"routes": [{"route": "GET /hosts", "count": 12, "last_used_on": "2022-03-12T00:00:00Z"}]
+	}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/usage?days=365", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestApiUpdateUsageAnalyticsSettingsHandler(t *testing.T) {
+	usageService := new(services.MockUsageAnalyticsService)
+	usageService.On("Count", mock.Anything).Return(nil)
+	usageService.On("GetSettings").Return(&models.UsageAnalyticsSettings{}, nil)
+	usageService.On("SaveSettings", &models.UsageAnalyticsSettings{Enabled: true}).Return(nil)
+
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionUsageAnalyticsSaved && e.ResourceID == "usage_analytics"
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.usageService = usageService
+	deps.auditService = auditService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/api/usage/settings", bytes.NewBufferString(`{"enabled": true}`))
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"enabled": true}`, resp.Body.String())
+	usageService.AssertExpectations(t)
+	auditService.AssertExpectations(t)
+}
@@ -0,0 +1,297 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type JSONUserCreation struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required,oneof=admin operator viewer"`
+	// Organization the user is restricted to, none if empty. The users created by the users of an organization
+	// are restricted to it
+	Organization string `json:"organization"`
+}
+
+type JSONUserRole struct {
+	Role string `json:"role" binding:"required,oneof=admin operator viewer"`
+}
+
+type JSONUserOrganization struct {
+	// Organization the user is restricted to, none if empty
+	Organization string `json:"organization"`
+}
+
+// ApiListUsersHandler godoc
+// @Summary Retrieve the users and their roles
+// @Description The users of the organization of the logged in user only, if any
+// @Produce json
+// @Success 200 {array} models.User
+// @Failure 500 {object} map[string]string
+// @Router /users [get]
+func ApiListUsersHandler(usersService services.UsersService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		users, err := usersService.GetAll(userOrganization(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, users)
+	}
+}
+
+// ApiMeHandler godoc
+// @Summary Retrieve the logged in user, or API key, and the actions it is allowed to perform
+// @Produce json
+// @Success 200 {object} models.UserPermissions
+// @Failure 401 {object} map[string]string
+// @Router /me [get]
+func ApiMeHandler(c *gin.Context) {
+	value, _ := c.Get(ContextUserKey)
+	user, ok := value.(*models.User)
+	if !ok {
+		_ = c.Error(UnauthorizedError("authentication required"))
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.UserPermissions{
+		Username:      user.Username,
+		Role:          user.Role,
+		Permissions:   user.Permissions(),
+		Organization:  user.Organization,
+		Impersonation: requestImpersonation(c),
+	})
+}
+
+// ApiCreateUserHandler godoc
+// @Summary Create a user with the given role
+// @Accept json
+// @Produce json
+// @Param Body body JSONUserCreation true "The user"
+// @Success 201 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users [post]
+func ApiCreateUserHandler(usersService services.UsersService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONUserCreation
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		organization := r.Organization
+		if callerOrganization := userOrganization(c); callerOrganization != "" {
+			if organization != "" && organization != callerOrganization {
+				_ = c.Error(ForbiddenError("users can only be created in your organization"))
+				return
+			}
+			organization = callerOrganization
+		}
+
+		if err := validateText("organization", organization, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		existing, err := usersService.GetByUsername(r.Username)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if existing != nil {
+			_ = c.Error(BadRequestError("user already exists"))
+			return
+		}
+
+		user, err := usersService.Create(r.Username, r.Password, r.Role, organization)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionUserCreated, models.AuditResourceUser, strconv.FormatInt(user.ID, 10), nil, user)
+
+		c.JSON(http.StatusCreated, user)
+	}
+}
+
+// ApiUpdateUserRoleHandler godoc
+// @Summary Change the role of a user
+// @Accept json
+// @Produce json
+// @Param id path int true "User id"
+// @Param Body body JSONUserRole true "The role"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/role [put]
+func ApiUpdateUserRoleHandler(usersService services.UsersService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			_ = c.Error(NotFoundError("user not found"))
+			return
+		}
+
+		var r JSONUserRole
+
+		err = c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if !requireOrganizationUser(c, usersService, id) {
+			return
+		}
+
+		user, err := usersService.UpdateRole(id, r.Role)
+		if errors.Is(err, services.ErrLastAdmin) {
+			_ = c.Error(BadRequestError(err.Error()))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if user == nil {
+			_ = c.Error(NotFoundError("user not found"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionUserRoleChanged, models.AuditResourceUser, c.Param("id"), nil, user)
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+// ApiUpdateUserOrganizationHandler godoc
+// @Summary Restrict a user to the hosts, clusters and SAP systems of an organization, or lift the restriction
+// @Accept json
+// @Produce json
+// @Param id path int true "User id"
+// @Param Body body JSONUserOrganization true "The organization, empty to lift the restriction"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/organization [put]
+func ApiUpdateUserOrganizationHandler(usersService services.UsersService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			_ = c.Error(NotFoundError("user not found"))
+			return
+		}
+
+		var r JSONUserOrganization
+
+		err = c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if err := validateText("organization", r.Organization, maxIdentifierLength); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if !requireOrganizationUser(c, usersService, id) {
+			return
+		}
+
+		if callerOrganization := userOrganization(c); callerOrganization != "" && r.Organization != callerOrganization {
+			_ = c.Error(ForbiddenError("users can only be moved to your organization"))
+			return
+		}
+
+		user, err := usersService.UpdateOrganization(id, r.Organization)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if user == nil {
+			_ = c.Error(NotFoundError("user not found"))
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionUserOrgChanged, models.AuditResourceUser, c.Param("id"), nil, user)
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+// ApiDeleteUserHandler godoc
+// @Summary Delete a user
+// @Param id path int true "User id"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [delete]
+func ApiDeleteUserHandler(usersService services.UsersService, auditService services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			_ = c.Error(NotFoundError("user not found"))
+			return
+		}
+
+		if !requireOrganizationUser(c, usersService, id) {
+			return
+		}
+
+		err = usersService.Delete(id)
+		if errors.Is(err, services.ErrLastAdmin) {
+			_ = c.Error(BadRequestError(err.Error()))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		recordAudit(c, auditService, models.AuditActionUserDeleted, models.AuditResourceUser, c.Param("id"), nil, nil)
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// requireOrganizationUser tells whether the user can be managed by the logged in user, the users of an organization
+// managing the ones of the same organization only. Otherwise the request is answered as if it did not exist
+func requireOrganizationUser(c *gin.Context, usersService services.UsersService, id int64) bool {
+	organization := userOrganization(c)
+	if organization == "" {
+		return true
+	}
+
+	user, err := usersService.GetByID(id)
+	if err != nil {
+		_ = c.Error(err)
+		return false
+	}
+
+	if user == nil || user.Organization != organization {
+		_ = c.Error(NotFoundError("user not found"))
+		return false
+	}
+
+	return true
+}
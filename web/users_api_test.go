@@ -0,0 +1,288 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func setupUsersApiTestApp(t *testing.T, usersService *services.MockUsersService) *App {
+	return setupOrganizationUsersApiTestApp(t, usersService, "")
+}
+
+// setupOrganizationUsersApiTestApp logs in the test user as an admin of the organization
+func setupOrganizationUsersApiTestApp(t *testing.T, usersService *services.MockUsersService, organization string) *App {
+	usersService.On("GetByUsername", testUser).Return(&models.User{ID: 1, Username: testUser, Role: models.UserRoleAdmin, Organization: organization}, nil)
+
+	deps := setupTestDependencies()
+	deps.usersService = usersService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func TestApiListUsersHandler(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetAll", "").Return([]*models.User{
+		{ID: 1, Username: "admin", Role: models.UserRoleAdmin, CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	app := setupUsersApiTestApp(t, usersService)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/users", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{"id": 1, "username": "admin", "role": "admin", "created_at": "2022-01-01T00:00:00Z"}]`, resp.Body.String())
+}
+
+func TestApiCreateUserHandler(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByUsername", "jane").Return(nil, nil)
+	usersService.On("Create", "jane", "secret", models.UserRoleOperator, "").Return(&models.User{
+		ID: 2, Username: "jane", Role: models.UserRoleOperator,
+	}, nil)
+
+	app := setupUsersApiTestApp(t, usersService)
+
+	resp := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"username": "jane", "password": "secret", "role": "operator"}`)
+	req := httptest.NewRequest("POST", "/api/users", body)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 201, resp.Code)
+	usersService.AssertExpectations(t)
+}
+
+func TestApiCreateUserHandlerOrganization(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByUsername", "jane").Return(nil, nil)
+	usersService.On("Create", "jane", "secret", models.UserRoleOperator, "acme").Return(&models.User{
+		ID: 2, Username: "jane", Role: models.UserRoleOperator, Organization: "acme",
+	}, nil)
+
+	app := setupOrganizationUsersApiTestApp(t, usersService, "acme")
+
+	for payload, expected := range map[string]int{
+		// stamped with the organization of the creator
+		`{"username": "jane", "password": "secret", "role": "operator"}`:                           201,
+		`{"username": "jane", "password": "secret", "role": "operator", "organization": "acme"}`:   201,
+		`{"username": "jane", "password": "secret", "role": "operator", "organization": "globex"}`: 403,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/users", bytes.NewBufferString(payload))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code, payload)
+	}
+
+	usersService.AssertNotCalled(t, "Create", "jane", "secret", models.UserRoleOperator, "globex")
+	usersService.AssertNotCalled(t, "Create", "jane", "secret", models.UserRoleOperator, "")
+}
+
+func TestApiCreateUserHandler_Invalid(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByUsername", "admin").Return(&models.User{ID: 3, Username: "admin"}, nil)
+
+	app := setupUsersApiTestApp(t, usersService)
+
+	for _, payload := range []string{
+		`{"username": "jane", "password": "secret", "role": "superuser"}`,
+		`{"username": "jane", "role": "viewer"}`,
+		`{"username": "admin", "password": "secret", "role": "viewer"}`,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/users", bytes.NewBufferString(payload))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code, payload)
+	}
+
+	usersService.AssertNotCalled(t, "Create")
+}
+
+func TestApiUpdateUserRoleHandler(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("UpdateRole", int64(2), models.UserRoleViewer).Return(&models.User{ID: 2, Username: "jane", Role: models.UserRoleViewer}, nil)
+	usersService.On("UpdateRole", int64(1), models.UserRoleViewer).Return(nil, services.ErrLastAdmin)
+	usersService.On("UpdateRole", int64(3), models.UserRoleViewer).Return(nil, nil)
+
+	app := setupUsersApiTestApp(t, usersService)
+
+	for path, expected := range map[string]int{
+		"/api/users/2/role": 200,
+		"/api/users/1/role": 400,
+		"/api/users/3/role": 404,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", path, bytes.NewBufferString(`{"role": "viewer"}`))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code, path)
+	}
+}
+
+func TestApiUpdateUserOrganizationHandler(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("UpdateOrganization", int64(2), "acme").Return(&models.User{ID: 2, Username: "jane", Organization: "acme"}, nil)
+	usersService.On("UpdateOrganization", int64(3), "acme").Return(nil, nil)
+
+	app := setupUsersApiTestApp(t, usersService)
+
+	for path, expected := range map[string]int{
+		"/api/users/2/organization": 200,
+		"/api/users/3/organization": 404,
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", path, bytes.NewBufferString(`{"organization": "acme"}`))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, expected, resp.Code, path)
+	}
+}
+
+func TestApiUpdateUserOrganizationHandlerOrganization(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByID", int64(2)).Return(&models.User{ID: 2, Username: "jane", Organization: "acme"}, nil)
+	usersService.On("GetByID", int64(3)).Return(&models.User{ID: 3, Username: "joe", Organization: "globex"}, nil)
+	usersService.On("GetByID", int64(4)).Return(&models.User{ID: 4, Username: "root"}, nil)
+	usersService.On("UpdateOrganization", int64(2), "acme").Return(&models.User{ID: 2, Username: "jane", Organization: "acme"}, nil)
+
+	app := setupOrganizationUsersApiTestApp(t, usersService, "acme")
+
+	for _, tc := range []struct {
+		path         string
+		organization string
+		expected     int
+	}{
+		{"/api/users/2/organization", "acme", 200},
+		{"/api/users/2/organization", "", 403},
+		{"/api/users/2/organization", "globex", 403},
+		{"/api/users/3/organization", "acme", 404},
+		{"/api/users/4/organization", "acme", 404},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", tc.path, bytes.NewBufferString(`{"organization": "`+tc.organization+`"}`))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expected, resp.Code, "%s to %q", tc.path, tc.organization)
+	}
+
+	usersService.AssertNumberOfCalls(t, "UpdateOrganization", 1)
+}
+
+func TestApiUsersHandlersOtherOrganization(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetAll", "acme").Return([]*models.User{}, nil)
+	usersService.On("GetByID", int64(3)).Return(&models.User{ID: 3, Username: "joe", Organization: "globex"}, nil)
+
+	app := setupOrganizationUsersApiTestApp(t, usersService, "acme")
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/users", nil)
+	app.webEngine.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+
+	for _, r := range []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"PUT", "/api/users/3/role", `{"role": "viewer"}`},
+		{"DELETE", "/api/users/3", ""},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(r.method, r.path, bytes.NewBufferString(r.body))
+		req.Header.Set(CSRFTokenHeader, testCSRFToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 404, resp.Code, r.path)
+	}
+
+	usersService.AssertNotCalled(t, "UpdateRole", int64(3), models.UserRoleViewer)
+	usersService.AssertNotCalled(t, "Delete", int64(3))
+	usersService.AssertExpectations(t)
+}
+
+func TestApiDeleteUserHandler(t *testing.T) {
+	usersService := new(services.MockUsersService)
+	usersService.On("Delete", int64(2)).Return(nil)
+	usersService.On("Delete", int64(1)).Return(services.ErrLastAdmin)
+
+	app := setupUsersApiTestApp(t, usersService)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/users/2", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("DELETE", "/api/users/1", nil)
+	req.Header.Set(CSRFTokenHeader, testCSRFToken)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+	assert.Contains(t, resp.Body.String(), "at least one admin user is required")
+}
+
+func TestApiMeHandler(t *testing.T) {
+	app := setupUsersApiTestApp(t, new(services.MockUsersService))
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/me", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{
+		"username": "test-user",
+		"role": "admin",
+		"permissions": ["tags:write", "checks:write", "checks:run", "settings:write", "users:write", "audit:read"]
+	}`, resp.Body.String())
+
+	deps := setupTestDependencies()
+	deps.usersService = newMockedUsersService(models.UserRoleViewer)
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/me", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"username": "test-user", "role": "viewer", "permissions": []}`, resp.Body.String())
+}
@@ -1,10 +1,17 @@
 package web
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	gorillaSessions "github.com/gorilla/sessions"
+	"github.com/stretchr/testify/mock"
 	"github.com/trento-project/trento/internal/grafana"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
 
@@ -12,13 +19,74 @@ func setupTestDependencies() Dependencies {
 	return Dependencies{
 		webEngine:               gin.Default(),
 		collectorEngine:         gin.Default(),
-		store:                   cookie.NewStore([]byte("secret")),
+		store:                   newAuthenticatedStore(),
+		backlogProjector:        datapipeline.NewBacklogProjector(nil, nil),
+		projectorWorkersPool:    datapipeline.NewProjectorsWorkerPool(nil, datapipeline.RetryPolicy{}),
 		settingsService:         newMockedSettingsService(),
 		subscriptionsService:    newMockedSubscriptionsService(),
 		premiumDetectionService: newMockedPremiumDetectionService(),
+		favoritesService:        newMockedFavoritesService(),
+		availabilityService:     newMockedAvailabilityService(),
+		hostUtilizationService:  newMockedHostUtilizationService(),
+		addressConflictsService: newMockedAddressConflictsService(),
+		usersService:            newMockedUsersService(models.UserRoleAdmin),
+		timelineService:         newMockedTimelineService(),
+		apiKeysService:          new(services.MockApiKeysService),
+		healthHistoryService:    new(services.MockHealthHistoryService),
+		auditService:            newMockedAuditService(),
+		payloadCaptureService:   newMockedPayloadCaptureService(),
+		agentsService:           newMockedAgentsService(),
+		entitlementsService:     newMockedEntitlementsService(),
+		loginThrottlingService:  newMockedLoginThrottlingService(),
+		consistencyService:      newMockedConsistencyService(),
+		restrictionsService:     newMockedResourceRestrictionsService(),
+		baselinesService:        new(services.MockBaselinesService),
+		tokensService:           new(services.MockPersonalAccessTokensService),
+		costReportService:       new(services.MockCostReportService),
+		signaturesService:       newMockedPayloadSignaturesService(),
+		logSampler:              NewLogSampler(),
+		usageService:            newMockedUsageAnalyticsService(),
+		searchService:           new(services.MockSearchService),
+		projectorsManager:       datapipeline.NewProjectorsManager(nil, nil),
+		agentLogsService:        newMockedAgentLogsService(),
+		agentConfigService:      new(services.MockAgentConfigurationService),
+		hostMetricsService:      newMockedHostMetricsService(),
+		agentChannelHub:         NewAgentChannelHub(),
+		agentUpgradesService:    newMockedAgentUpgradesService(),
+		organizationsService:    new(services.MockOrganizationsService),
+		enrollmentsService:      newMockedAgentEnrollmentsService(),
 	}
 }
 
+const (
+	testUser      string = "test-user"
+	testCSRFToken string = "test-csrf-token"
+)
+
+// authenticatedStore binds the new sessions to a test user,
+// so that the handlers can be tested behind the authentication middleware
+type authenticatedStore struct {
+	cookie.Store
+}
+
+func newAuthenticatedStore() authenticatedStore {
+	return authenticatedStore{cookie.NewStore([]byte("secret"))}
+}
+
+func (s authenticatedStore) Get(r *http.Request, name string) (*gorillaSessions.Session, error) {
+	return gorillaSessions.GetRegistry(r).Get(s, name)
+}
+
+func (s authenticatedStore) New(r *http.Request, name string) (*gorillaSessions.Session, error) {
+	session, err := s.Store.New(r, name)
+	if _, ok := session.Values[SessionUserKey]; !ok {
+		session.Values[SessionUserKey] = testUser
+		session.Values[SessionCSRFTokenKey] = testCSRFToken
+	}
+
+	return session, err
+}
+
 func setupTestConfig() *Config {
 	return &Config{
 		Host: "",
@@ -38,6 +106,9 @@ func newMockedSettingsService() services.SettingsService {
 	settingsService.On("InitializeIdentifier").Return(uuid.MustParse("59fd8017-b7fd-477b-9ebe-b658c558f3e9"), nil)
 	settingsService.On("AcceptEula").Return(nil)
 	settingsService.On("IsEulaAccepted").Return(true, nil)
+	settingsService.On("GetMaintenanceMode").Return(&models.MaintenanceMode{}, nil)
+	settingsService.On("GetLogSamplingRules").Return([]*models.LogSamplingRule{}, nil)
+	settingsService.On("GetReadOnlyMode").Return(&models.ReadOnlyMode{}, nil).Maybe()
 
 	return settingsService
 }
@@ -55,3 +126,157 @@ func newMockedPremiumDetectionService() services.PremiumDetectionService {
 
 	return premiumDetection
 }
+
+func newMockedFavoritesService() services.FavoritesService {
+	favoritesService := new(services.MockFavoritesService)
+	favoritesService.On("GetAllByResourceType", mock.Anything, mock.Anything).Return(nil, nil)
+
+	return favoritesService
+}
+
+func newMockedHostUtilizationService() services.HostUtilizationService {
+	hostUtilizationService := new(services.MockHostUtilizationService)
+	hostUtilizationService.On("GetHostUtilization", mock.Anything, mock.Anything).Return(models.HostUtilization{}, nil)
+
+	return hostUtilizationService
+}
+
+// newMockedUsersService grants the given role to the test user
+func newMockedUsersService(role string) services.UsersService {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByUsername", testUser).Return(&models.User{ID: 1, Username: testUser, Role: role}, nil)
+
+	return usersService
+}
+
+// newMockedOrganizationUsersService restricts the test user, granted the operator role, to the given organization
+func newMockedOrganizationUsersService(organization string) services.UsersService {
+	usersService := new(services.MockUsersService)
+	usersService.On("GetByUsername", testUser).Return(&models.User{ID: 1, Username: testUser, Role: models.UserRoleOperator, Organization: organization}, nil)
+
+	return usersService
+}
+
+func newMockedTimelineService() services.TimelineService {
+	timelineService := new(services.MockTimelineService)
+	timelineService.On("GetHostTimeline", mock.Anything, mock.Anything).Return([]*models.TimelineEvent{}, nil)
+
+	return timelineService
+}
+
+func newMockedPayloadSignaturesService() services.PayloadSignaturesService {
+	payloadSignaturesService := new(services.MockPayloadSignaturesService)
+	payloadSignaturesService.On("Verify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	return payloadSignaturesService
+}
+
+func newMockedAgentEnrollmentsService() services.AgentEnrollmentsService {
+	agentEnrollmentsService := new(services.MockAgentEnrollmentsService)
+	agentEnrollmentsService.On("Enroll", mock.Anything, mock.Anything).Return("", nil)
+
+	return agentEnrollmentsService
+}
+
+func newMockedAuditService() services.AuditService {
+	auditService := new(services.MockAuditService)
+	auditService.On("Record", mock.Anything).Return(nil)
+	auditService.On("GetAll", mock.Anything, mock.Anything).Return([]*models.AuditEntry{}, nil)
+
+	return auditService
+}
+
+func newMockedLoginThrottlingService() services.LoginThrottlingService {
+	loginThrottlingService := new(services.MockLoginThrottlingService)
+	loginThrottlingService.On("BlockedUntil", mock.Anything, mock.Anything).Return(time.Time{}, nil)
+	loginThrottlingService.On("RecordFailure", mock.Anything, mock.Anything).Return([]*models.LoginThrottle{}, nil)
+	loginThrottlingService.On("RecordSuccess", mock.Anything).Return(nil)
+
+	return loginThrottlingService
+}
+
+func newMockedConsistencyService() services.ConsistencyService {
+	consistencyService := new(services.MockConsistencyService)
+	consistencyService.On("GetAll", mock.Anything).Return([]*models.Inconsistency{}, nil)
+
+	return consistencyService
+}
+
+// newMockedResourceRestrictionsService restricts no permission
+func newMockedResourceRestrictionsService() services.ResourceRestrictionsService {
+	restrictionsService := new(services.MockResourceRestrictionsService)
+	restrictionsService.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	return restrictionsService
+}
+
+func newMockedPayloadCaptureService() services.PayloadCaptureService {
+	payloadCaptureService := new(services.MockPayloadCaptureService)
+	payloadCaptureService.On("Capture", mock.Anything, mock.Anything).Return(nil)
+
+	return payloadCaptureService
+}
+
+func newMockedAgentLogsService() services.AgentLogsService {
+	agentLogsService := new(services.MockAgentLogsService)
+	agentLogsService.On("IsRequested", mock.Anything).Return(false, nil)
+	agentLogsService.On("Get", mock.Anything).Return(nil, nil)
+
+	return agentLogsService
+}
+
+func newMockedHostMetricsService() services.HostMetricsService {
+	hostMetricsService := new(services.MockHostMetricsService)
+	hostMetricsService.On("GetSparkline", mock.Anything, mock.Anything, mock.Anything).Return(&models.HostMetricsSparkline{}, nil)
+
+	return hostMetricsService
+}
+
+// newMockedAgentUpgradesService has no upgrade to deliver
+func newMockedAgentUpgradesService() services.AgentUpgradesService {
+	agentUpgradesService := new(services.MockAgentUpgradesService)
+	agentUpgradesService.On("GetPending", mock.Anything).Return(nil, nil)
+	agentUpgradesService.On("Acknowledge", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	agentUpgradesService.On("Complete", mock.Anything, mock.Anything).Return(nil)
+
+	return agentUpgradesService
+}
+
+func newMockedUsageAnalyticsService() services.UsageAnalyticsService {
+	usageService := new(services.MockUsageAnalyticsService)
+	usageService.On("Count", mock.Anything).Return(nil)
+
+	return usageService
+}
+
+func newMockedAddressConflictsService() services.AddressConflictsService {
+	addressConflictsService := new(services.MockAddressConflictsService)
+	addressConflictsService.On("GetAll", "").Return([]*models.AddressConflict{}, nil)
+
+	return addressConflictsService
+}
+
+func newMockedAvailabilityService() services.AvailabilityService {
+	availabilityService := new(services.MockAvailabilityService)
+	availabilityService.On("GetHostAvailability", mock.Anything, mock.Anything).Return(nil, nil)
+	availabilityService.On("GetSAPSystemAvailability", mock.Anything, mock.Anything).Return(nil, nil)
+
+	return availabilityService
+}
+
+// newMockedAgentsService approves every agent
+func newMockedAgentsService() services.AgentsService {
+	agentsService := new(services.MockAgentsService)
+	agentsService.On("Admit", mock.Anything).Return(models.AgentStatusApproved, nil)
+	agentsService.On("GetAll", mock.Anything, mock.Anything).Return([]*models.Agent{}, nil)
+
+	return agentsService
+}
+
+// newMockedEntitlementsService allows every host
+func newMockedEntitlementsService() services.EntitlementsService {
+	entitlementsService := new(services.MockEntitlementsService)
+	entitlementsService.On("AllowsHost", mock.Anything).Return(true, nil)
+
+	return entitlementsService
+}